@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ExchangeHandler handles users' exchange connection API requests. Credentials are accepted
+// on connect only; responses carry the masked key suffix.
+type ExchangeHandler struct {
+	logger      *observability.Logger
+	connections *trading.ExchangeConnectionManager
+}
+
+// NewExchangeHandler creates a new exchange connection handler
+func NewExchangeHandler(logger *observability.Logger, connections *trading.ExchangeConnectionManager) *ExchangeHandler {
+	return &ExchangeHandler{
+		logger:      logger,
+		connections: connections,
+	}
+}
+
+// RegisterRoutes registers exchange connection routes behind auth, which must set the user ID
+func (h *ExchangeHandler) RegisterRoutes(router *mux.Router, auth func(http.Handler) http.Handler) {
+	exchanges := router.PathPrefix("/api/v1/exchanges").Subrouter()
+	exchanges.Use(auth)
+
+	exchanges.HandleFunc("", h.ListConnections).Methods("GET")
+	exchanges.HandleFunc("/connect", h.Connect).Methods("POST")
+	exchanges.HandleFunc("/{connectionId}", h.RevokeConnection).Methods("DELETE")
+	exchanges.HandleFunc("/{connectionId}/bots/{botId}", h.AttachBot).Methods("POST")
+}
+
+// ConnectExchangeRequest represents a request to connect an exchange account
+type ConnectExchangeRequest struct {
+	Exchange   string `json:"exchange"`
+	APIKey     string `json:"api_key"`
+	APISecret  string `json:"api_secret"`
+	Passphrase string `json:"passphrase"`
+	Label      string `json:"label"`
+
+	// Bots to trade through the connection once it is validated
+	BotIDs []string `json:"bot_ids"`
+}
+
+// Connect handles POST /api/v1/exchanges/connect
+func (h *ExchangeHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ConnectExchangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	connection, balances, err := h.connections.Connect(ctx, userID, req.Exchange, req.Label, trading.ExchangeCredentials{
+		APIKey:     req.APIKey,
+		APISecret:  req.APISecret,
+		Passphrase: req.Passphrase,
+	})
+	if err != nil {
+		if errors.Is(err, trading.ErrUnsupportedExchange) || errors.Is(err, trading.ErrInvalidExchangeCredentials) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error(ctx, "Failed to connect exchange", err, map[string]interface{}{
+			"exchange": req.Exchange,
+		})
+		http.Error(w, "Failed to connect exchange", http.StatusInternalServerError)
+		return
+	}
+
+	// The connection is kept when a bot cannot be attached; the failures are reported
+	admin := middleware.HasRole(ctx, middleware.RoleAdmin)
+	attached := make([]string, 0, len(req.BotIDs))
+	failed := make(map[string]string)
+	for _, botID := range req.BotIDs {
+		if err := h.connections.AttachBot(ctx, userID, connection.ID, botID, admin); err != nil {
+			failed[botID] = err.Error()
+			continue
+		}
+		attached = append(attached, botID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"connection":    connection,
+		"balances":      balances,
+		"attached_bots": attached,
+		"failed_bots":   failed,
+	})
+}
+
+// ListConnections handles GET /api/v1/exchanges
+func (h *ExchangeHandler) ListConnections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	connections, err := h.connections.ListConnections(ctx, userID)
+	if err != nil {
+		h.logger.Error(ctx, "Failed to list exchange connections", err, nil)
+		http.Error(w, "Failed to list exchange connections", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"connections": connections,
+		"count":       len(connections),
+	})
+}
+
+// AttachBot handles POST /api/v1/exchanges/{connectionId}/bots/{botId}
+func (h *ExchangeHandler) AttachBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	connectionID, err := uuid.Parse(vars["connectionId"])
+	if err != nil {
+		http.Error(w, "Invalid connection ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.connections.AttachBot(ctx, userID, connectionID, vars["botId"], middleware.HasRole(ctx, middleware.RoleAdmin)); err != nil {
+		switch {
+		case errors.Is(err, trading.ErrUnownedBot):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, trading.ErrExchangeConnectionNotFound), errors.Is(err, trading.ErrBotNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, trading.ErrExchangeConnectionRevoked), errors.Is(err, trading.ErrExchangeMismatch):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.Error(ctx, "Failed to attach bot to exchange connection", err, map[string]interface{}{
+				"connection_id": connectionID.String(),
+				"bot_id":        vars["botId"],
+			})
+			http.Error(w, "Failed to attach bot", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"connection_id": connectionID,
+		"bot_id":        vars["botId"],
+	})
+}
+
+// RevokeConnection handles DELETE /api/v1/exchanges/{connectionId}
+func (h *ExchangeHandler) RevokeConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	connectionID, err := uuid.Parse(mux.Vars(r)["connectionId"])
+	if err != nil {
+		http.Error(w, "Invalid connection ID", http.StatusBadRequest)
+		return
+	}
+
+	stopped, err := h.connections.RevokeConnection(ctx, userID, connectionID)
+	if err != nil {
+		if errors.Is(err, trading.ErrExchangeConnectionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.Error(ctx, "Failed to revoke exchange connection", err, map[string]interface{}{
+			"connection_id": connectionID.String(),
+		})
+		http.Error(w, "Failed to revoke exchange connection", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"connection_id": connectionID,
+		"status":        trading.ExchangeConnectionRevoked,
+		"stopped_bots":  stopped,
+	})
+}
+
+// requestUserID returns the authenticated user's ID, writing an error response when there is none
+func requestUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/trading/monitoring"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	eventStreamWriteWait  = 10 * time.Second
+	eventStreamPongWait   = 60 * time.Second
+	eventStreamPingPeriod = eventStreamPongWait * 9 / 10
+)
+
+// eventStreamCommand is a subscription change sent by a WebSocket client
+type eventStreamCommand struct {
+	Action string   `json:"action"`
+	BotIDs []string `json:"bot_ids"`
+}
+
+// RegisterEventStreamRoute registers GET /ws behind auth. Browsers can't set headers on
+// WebSocket handshakes, so a token query parameter stands in for the Authorization header.
+func (h *MonitoringHandler) RegisterEventStreamRoute(router *mux.Router, auth func(http.Handler) http.Handler) {
+	router.Handle("/ws", eventStreamToken(auth(http.HandlerFunc(h.StreamEvents)))).Methods("GET")
+}
+
+// SetEventStreamOrigins sets the browser origins allowed to open the event stream besides the
+// server's own; "*" allows any
+func (h *MonitoringHandler) SetEventStreamOrigins(origins []string) {
+	h.eventStreamOrigins = origins
+}
+
+// StreamEvents handles GET /ws, upgrading to a WebSocket that pushes bot state changes, order
+// fills and triggered risk limits. The bot_id query parameter selects the initial bots and
+// defaults to all; clients change the selection by sending subscribe and unsubscribe commands.
+func (h *MonitoringHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	botIDs := eventStreamBotIDs(r.URL.Query()["bot_id"])
+
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkEventStreamOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Warn(r.Context(), "Event stream upgrade failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer conn.Close()
+
+	sub := h.monitor.SubscribeEvents(botIDs...)
+	defer sub.Close()
+
+	h.logger.Info(r.Context(), "Event stream client connected", map[string]interface{}{
+		"remote":  r.RemoteAddr,
+		"bot_ids": botIDs,
+	})
+
+	done := make(chan struct{})
+	go h.readEventStreamCommands(conn, sub, done)
+
+	ticker := time.NewTicker(eventStreamPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			h.logger.Info(r.Context(), "Event stream client disconnected", map[string]interface{}{
+				"remote":  r.RemoteAddr,
+				"dropped": sub.Dropped(),
+			})
+			return
+		case <-r.Context().Done():
+			// Cancelled when maintenance drains streams; tell the client it may reconnect later
+			conn.SetWriteDeadline(time.Now().Add(eventStreamWriteWait))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseServiceRestart, "maintenance"))
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(eventStreamWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(eventStreamWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readEventStreamCommands applies subscription commands from the client until the connection
+// closes
+func (h *MonitoringHandler) readEventStreamCommands(conn *websocket.Conn, sub *monitoring.EventSubscription, done chan<- struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(4096)
+	conn.SetReadDeadline(time.Now().Add(eventStreamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(eventStreamPongWait))
+	})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(eventStreamPongWait))
+
+		// Malformed commands are ignored rather than closing the stream
+		var command eventStreamCommand
+		if err := json.Unmarshal(message, &command); err != nil {
+			continue
+		}
+
+		switch strings.ToLower(command.Action) {
+		case "subscribe":
+			sub.Subscribe(command.BotIDs...)
+		case "unsubscribe":
+			sub.Unsubscribe(command.BotIDs...)
+		}
+	}
+}
+
+// eventStreamBotIDs splits comma separated bot_id parameters, defaulting to all bots
+func eventStreamBotIDs(values []string) []string {
+	var botIDs []string
+	for _, value := range values {
+		for _, botID := range strings.Split(value, ",") {
+			if botID = strings.TrimSpace(botID); botID != "" {
+				botIDs = append(botIDs, botID)
+			}
+		}
+	}
+	if len(botIDs) == 0 {
+		return []string{monitoring.AllBots}
+	}
+	return botIDs
+}
+
+// checkEventStreamOrigin allows handshakes without an Origin, which only non-browser clients omit,
+// and those from the server's own origin or a configured one. CORS does not apply to WebSocket
+// handshakes, so this is what keeps other sites from streaming with a user's token.
+func (h *MonitoringHandler) checkEventStreamOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.eventStreamOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// eventStreamToken moves a token query parameter into the Authorization header when the
+// request has none
+func eventStreamToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
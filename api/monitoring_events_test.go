@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/trading/monitoring"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eventStreamTestSecret = "event-stream-secret"
+
+func newEventStreamServer(t *testing.T) (*monitoring.TradingBotMonitor, string) {
+	t.Helper()
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	monitor := monitoring.NewTradingBotMonitor(logger, nil, nil, nil)
+	handler := NewMonitoringHandler(logger, monitor)
+	handler.SetEventStreamOrigins([]string{"https://app.example.com"})
+
+	router := mux.NewRouter()
+	handler.RegisterEventStreamRoute(router, middleware.JWTWithRevocation(eventStreamTestSecret, nil))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return monitor, "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func signEventStreamToken(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(eventStreamTestSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func dialEventStream(url string, header http.Header) (*websocket.Conn, int, error) {
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	return conn, status, err
+}
+
+func TestStreamEvents_RequiresToken(t *testing.T) {
+	_, url := newEventStreamServer(t)
+
+	_, status, err := dialEventStream(url, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	_, status, err = dialEventStream(url, http.Header{"Authorization": {"Bearer not-a-token"}})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// Browsers pass the token as a query parameter
+	conn, _, err := dialEventStream(url+"?token="+signEventStreamToken(t), nil)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestStreamEvents_ChecksOrigin(t *testing.T) {
+	monitor, url := newEventStreamServer(t)
+	auth := "Bearer " + signEventStreamToken(t)
+
+	_, status, err := dialEventStream(url, http.Header{"Authorization": {auth}, "Origin": {"https://evil.example.com"}})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	conn, _, err := dialEventStream(url+"?bot_id=bot-1", http.Header{"Authorization": {auth}, "Origin": {"https://app.example.com"}})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The stream is live once the subscription is registered
+	require.Eventually(t, func() bool {
+		monitor.BotStateChanged("bot-1", "idle", "running", "")
+		conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		var event monitoring.BotEvent
+		return conn.ReadJSON(&event) == nil && event.BotID == "bot-1"
+	}, 2*time.Second, 10*time.Millisecond)
+}
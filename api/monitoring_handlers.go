@@ -13,8 +13,9 @@ import (
 
 // MonitoringHandler handles monitoring and analytics API requests
 type MonitoringHandler struct {
-	logger  *observability.Logger
-	monitor *monitoring.TradingBotMonitor
+	logger             *observability.Logger
+	monitor            *monitoring.TradingBotMonitor
+	eventStreamOrigins []string
 }
 
 // NewMonitoringHandler creates a new monitoring handler
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/gorilla/mux"
+)
+
+// ReconciliationHandler serves the reports of live order reconciliation. Reports cover every
+// user's connections, so they are limited to admins.
+type ReconciliationHandler struct {
+	logger     *observability.Logger
+	reconciler *trading.OrderReconciler
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(logger *observability.Logger, reconciler *trading.OrderReconciler) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		logger:     logger,
+		reconciler: reconciler,
+	}
+}
+
+// RegisterRoutes registers reconciliation routes behind auth, which must set the user's role
+func (h *ReconciliationHandler) RegisterRoutes(router *mux.Router, auth func(http.Handler) http.Handler) {
+	reconciliation := router.PathPrefix("/api/v1/reconciliation").Subrouter()
+	reconciliation.Use(auth, middleware.RequireRole(middleware.RoleAdmin))
+
+	reconciliation.HandleFunc("/latest", h.GetLatestReport).Methods("GET")
+}
+
+// GetLatestReport handles GET /api/v1/reconciliation/latest
+func (h *ReconciliationHandler) GetLatestReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.reconciler.LatestReport()
+	if err != nil {
+		if errors.Is(err, trading.ErrNoReconciliationReport) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.Error(r.Context(), "Failed to get reconciliation report", err, nil)
+		http.Error(w, "Failed to get reconciliation report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+)
+
+// RegisterLimitRoutes registers the routes that read and change bots' risk limits behind
+// auth, which must set the user ID and role. Traders request changes; raised limits wait for
+// an admin other than the requester to approve them.
+func (h *RiskManagementHandler) RegisterLimitRoutes(router *mux.Router, auth func(http.Handler) http.Handler) {
+	withRole := func(role string, handler http.HandlerFunc) http.Handler {
+		return auth(middleware.RequireRole(role)(handler))
+	}
+
+	router.Handle("/api/v1/risk/templates", withRole(middleware.RoleViewer, h.ListRiskTemplates)).Methods("GET")
+	router.Handle("/api/v1/risk/templates", withRole(middleware.RoleTrader, h.CreateRiskTemplate)).Methods("POST")
+
+	router.Handle("/api/v1/risk/bots/{botId}/limits", withRole(middleware.RoleViewer, h.GetBotRiskLimits)).Methods("GET")
+	router.Handle("/api/v1/risk/bots/{botId}", withRole(middleware.RoleTrader, h.UpdateBotRiskProfile)).Methods("PUT")
+	router.Handle("/api/v1/risk/bots/{botId}/template", withRole(middleware.RoleTrader, h.AssignRiskTemplate)).Methods("POST")
+
+	router.Handle("/api/v1/risk/limit-changes", withRole(middleware.RoleViewer, h.ListRiskLimitChanges)).Methods("GET")
+	router.Handle("/api/v1/risk/limit-changes/{changeId}/approve", withRole(middleware.RoleAdmin, h.ApproveRiskLimitChange)).Methods("POST")
+	router.Handle("/api/v1/risk/limit-changes/{changeId}/reject", withRole(middleware.RoleAdmin, h.RejectRiskLimitChange)).Methods("POST")
+}
+
+// ListRiskTemplates handles GET /api/v1/risk/templates
+func (h *RiskManagementHandler) ListRiskTemplates(w http.ResponseWriter, r *http.Request) {
+	templates := h.riskManager.ListRiskTemplates()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// CreateRiskTemplateRequest represents a request to create a custom risk limit template
+type CreateRiskTemplateRequest struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Limits      trading.BotRiskLimits `json:"limits"`
+}
+
+// CreateRiskTemplate handles POST /api/v1/risk/templates
+func (h *RiskManagementHandler) CreateRiskTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateRiskTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.riskManager.CreateRiskTemplate(r.Context(), req.Name, req.Description, req.Limits, userID)
+	if err != nil {
+		h.writeRiskLimitError(w, r, "Failed to create risk limit template", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// GetBotRiskLimits handles GET /api/v1/risk/bots/{botId}/limits
+func (h *RiskManagementHandler) GetBotRiskLimits(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.riskManager.GetBotRiskProfile(mux.Vars(r)["botId"])
+	if err != nil {
+		h.writeRiskLimitError(w, r, "Failed to get bot risk limits", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// UpdateBotRiskProfileRequest represents a request to update bot risk profile. Omitted limits
+// keep their current values.
+type UpdateBotRiskProfileRequest struct {
+	MaxPositionSize      *decimal.Decimal `json:"max_position_size,omitempty"`
+	StopLoss             *decimal.Decimal `json:"stop_loss,omitempty"`
+	TakeProfit           *decimal.Decimal `json:"take_profit,omitempty"`
+	MaxDrawdown          *decimal.Decimal `json:"max_drawdown,omitempty"`
+	MaxDailyLoss         *decimal.Decimal `json:"max_daily_loss,omitempty"`
+	MaxConsecutiveLosses *int             `json:"max_consecutive_losses,omitempty"`
+	RiskTolerance        *string          `json:"risk_tolerance,omitempty"`
+}
+
+// UpdateBotRiskProfile handles PUT /api/v1/risk/bots/{botId}
+func (h *RiskManagementHandler) UpdateBotRiskProfile(w http.ResponseWriter, r *http.Request) {
+	botID := mux.Vars(r)["botId"]
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateBotRiskProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.riskManager.GetBotRiskProfile(botID)
+	if err != nil {
+		h.writeRiskLimitError(w, r, "Failed to update bot risk profile", err)
+		return
+	}
+	limits := trading.BotRiskLimits{
+		MaxPositionSize:      profile.MaxPositionSize,
+		StopLoss:             profile.StopLoss,
+		TakeProfit:           profile.TakeProfit,
+		MaxDrawdown:          profile.MaxDrawdown,
+		MaxDailyLoss:         profile.MaxDailyLoss,
+		MaxConsecutiveLosses: profile.MaxConsecutiveLosses,
+		RiskTolerance:        profile.RiskTolerance,
+	}
+	if req.MaxPositionSize != nil {
+		limits.MaxPositionSize = *req.MaxPositionSize
+	}
+	if req.StopLoss != nil {
+		limits.StopLoss = *req.StopLoss
+	}
+	if req.TakeProfit != nil {
+		limits.TakeProfit = *req.TakeProfit
+	}
+	if req.MaxDrawdown != nil {
+		limits.MaxDrawdown = *req.MaxDrawdown
+	}
+	if req.MaxDailyLoss != nil {
+		limits.MaxDailyLoss = *req.MaxDailyLoss
+	}
+	if req.MaxConsecutiveLosses != nil {
+		limits.MaxConsecutiveLosses = *req.MaxConsecutiveLosses
+	}
+	if req.RiskTolerance != nil {
+		limits.RiskTolerance = trading.RiskTolerance(*req.RiskTolerance)
+	}
+
+	change, err := h.riskManager.RequestRiskLimitChange(r.Context(), botID, limits, userID)
+	if err != nil {
+		h.writeRiskLimitError(w, r, "Failed to update bot risk profile", err)
+		return
+	}
+	writeRiskLimitChange(w, change)
+}
+
+// AssignRiskTemplateRequest represents a request to assign a risk limit template to a bot
+type AssignRiskTemplateRequest struct {
+	Template string `json:"template"`
+}
+
+// AssignRiskTemplate handles POST /api/v1/risk/bots/{botId}/template
+func (h *RiskManagementHandler) AssignRiskTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AssignRiskTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.riskManager.AssignRiskTemplate(r.Context(), mux.Vars(r)["botId"], req.Template, userID)
+	if err != nil {
+		h.writeRiskLimitError(w, r, "Failed to assign risk limit template", err)
+		return
+	}
+	writeRiskLimitChange(w, change)
+}
+
+// ListRiskLimitChanges handles GET /api/v1/risk/limit-changes, filtered by the status query
+// parameter
+func (h *RiskManagementHandler) ListRiskLimitChanges(w http.ResponseWriter, r *http.Request) {
+	changes := h.riskManager.ListRiskLimitChanges(r.URL.Query().Get("status"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": changes,
+		"count":   len(changes),
+	})
+}
+
+// ReviewRiskLimitChangeRequest carries the reviewer's note on a risk limit change
+type ReviewRiskLimitChangeRequest struct {
+	Note string `json:"note"`
+}
+
+// ApproveRiskLimitChange handles POST /api/v1/risk/limit-changes/{changeId}/approve
+func (h *RiskManagementHandler) ApproveRiskLimitChange(w http.ResponseWriter, r *http.Request) {
+	h.reviewRiskLimitChange(w, r, h.riskManager.ApproveRiskLimitChange)
+}
+
+// RejectRiskLimitChange handles POST /api/v1/risk/limit-changes/{changeId}/reject
+func (h *RiskManagementHandler) RejectRiskLimitChange(w http.ResponseWriter, r *http.Request) {
+	h.reviewRiskLimitChange(w, r, h.riskManager.RejectRiskLimitChange)
+}
+
+func (h *RiskManagementHandler) reviewRiskLimitChange(w http.ResponseWriter, r *http.Request, review func(ctx context.Context, changeID string, reviewer uuid.UUID, note string) (*trading.RiskLimitChange, error)) {
+	userID, ok := requestUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// The note is optional, so an empty body is accepted
+	var req ReviewRiskLimitChangeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	change, err := review(r.Context(), mux.Vars(r)["changeId"], userID, req.Note)
+	if err != nil {
+		h.writeRiskLimitError(w, r, "Failed to review risk limit change", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(change)
+}
+
+// writeRiskLimitChange responds 202 for changes waiting for approval and 200 for applied ones
+func writeRiskLimitChange(w http.ResponseWriter, change *trading.RiskLimitChange) {
+	w.Header().Set("Content-Type", "application/json")
+	if change.Status == trading.RiskLimitChangePendingApproval {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(change)
+}
+
+// writeRiskLimitError maps risk limit errors to status codes, logging unexpected ones
+func (h *RiskManagementHandler) writeRiskLimitError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	switch {
+	case errors.Is(err, trading.ErrInvalidRiskLimits):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, trading.ErrRiskProfileNotFound),
+		errors.Is(err, trading.ErrRiskTemplateNotFound),
+		errors.Is(err, trading.ErrRiskLimitChangeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, trading.ErrRiskTemplateExists),
+		errors.Is(err, trading.ErrRiskLimitChangePending),
+		errors.Is(err, trading.ErrRiskLimitChangeReviewed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, trading.ErrRiskLimitChangeSelfReview):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		h.logger.Error(r.Context(), message, err, nil)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
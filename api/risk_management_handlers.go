@@ -7,7 +7,6 @@ import (
 	"github.com/ai-agentic-browser/internal/trading"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/gorilla/mux"
-	"github.com/shopspring/decimal"
 )
 
 // RiskManagementHandler handles risk management API requests
@@ -29,7 +28,6 @@ func (h *RiskManagementHandler) RegisterRoutes(router *mux.Router) {
 	// Risk metrics endpoints
 	router.HandleFunc("/api/v1/risk/portfolio", h.GetPortfolioRisk).Methods("GET")
 	router.HandleFunc("/api/v1/risk/bots/{botId}", h.GetBotRiskMetrics).Methods("GET")
-	router.HandleFunc("/api/v1/risk/bots/{botId}", h.UpdateBotRiskProfile).Methods("PUT")
 
 	// Risk limits endpoints
 	router.HandleFunc("/api/v1/risk/limits", h.GetRiskLimits).Methods("GET")
@@ -116,41 +114,6 @@ func (h *RiskManagementHandler) GetBotRiskMetrics(w http.ResponseWriter, r *http
 	json.NewEncoder(w).Encode(response)
 }
 
-// UpdateBotRiskProfileRequest represents a request to update bot risk profile
-type UpdateBotRiskProfileRequest struct {
-	MaxPositionSize      *decimal.Decimal `json:"max_position_size,omitempty"`
-	StopLoss             *decimal.Decimal `json:"stop_loss,omitempty"`
-	TakeProfit           *decimal.Decimal `json:"take_profit,omitempty"`
-	MaxDrawdown          *decimal.Decimal `json:"max_drawdown,omitempty"`
-	MaxDailyLoss         *decimal.Decimal `json:"max_daily_loss,omitempty"`
-	MaxConsecutiveLosses *int             `json:"max_consecutive_losses,omitempty"`
-	RiskTolerance        *string          `json:"risk_tolerance,omitempty"`
-}
-
-// UpdateBotRiskProfile handles PUT /api/v1/risk/bots/{botId}
-func (h *RiskManagementHandler) UpdateBotRiskProfile(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	vars := mux.Vars(r)
-	botID := vars["botId"]
-
-	var req UpdateBotRiskProfileRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// Implementation would update the bot's risk profile
-	h.logger.Info(ctx, "Bot risk profile update requested", map[string]interface{}{
-		"bot_id": botID,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Risk profile updated successfully",
-		"bot_id":  botID,
-	})
-}
-
 // GetRiskAlerts handles GET /api/v1/risk/alerts
 func (h *RiskManagementHandler) GetRiskAlerts(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
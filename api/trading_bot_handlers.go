@@ -1,14 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/trading"
 	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/shopspring/decimal"
 )
@@ -18,6 +26,10 @@ type TradingBotHandler struct {
 	logger          *observability.Logger
 	botEngine       *trading.TradingBotEngine
 	strategyManager *strategies.StrategyManager
+	idempotency     *middleware.IdempotencyMiddleware
+	executions      *trading.ExecutionEngine
+	candles         CandleSource
+	decoder         httputil.BodyDecoder
 }
 
 // NewTradingBotHandler creates a new trading bot handler
@@ -29,11 +41,27 @@ func NewTradingBotHandler(logger *observability.Logger, botEngine *trading.Tradi
 	}
 }
 
+// SetStrictJSON rejects create, update and order request bodies with unknown fields
+func (h *TradingBotHandler) SetStrictJSON(strict bool) {
+	h.decoder.Strict = strict
+}
+
+// SetIdempotency makes order submission replay-safe for requests carrying an Idempotency-Key
+func (h *TradingBotHandler) SetIdempotency(idempotency *middleware.IdempotencyMiddleware) {
+	h.idempotency = idempotency
+}
+
+// SetExecutionEngine enables progress reports for algorithmic orders
+func (h *TradingBotHandler) SetExecutionEngine(executions *trading.ExecutionEngine) {
+	h.executions = executions
+}
+
 // RegisterRoutes registers trading bot API routes
 func (h *TradingBotHandler) RegisterRoutes(router *mux.Router) {
 	// Bot management endpoints
 	router.HandleFunc("/api/v1/trading-bots", h.ListBots).Methods("GET")
 	router.HandleFunc("/api/v1/trading-bots", h.CreateBot).Methods("POST")
+	router.HandleFunc("/api/v1/bots/validate", h.ValidateBot).Methods("POST")
 	router.HandleFunc("/api/v1/trading-bots/{botId}", h.GetBot).Methods("GET")
 	router.HandleFunc("/api/v1/trading-bots/{botId}", h.UpdateBot).Methods("PUT")
 	router.HandleFunc("/api/v1/trading-bots/{botId}", h.DeleteBot).Methods("DELETE")
@@ -43,11 +71,15 @@ func (h *TradingBotHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/trading-bots/{botId}/stop", h.StopBot).Methods("POST")
 	router.HandleFunc("/api/v1/trading-bots/{botId}/pause", h.PauseBot).Methods("POST")
 	router.HandleFunc("/api/v1/trading-bots/{botId}/resume", h.ResumeBot).Methods("POST")
+	router.HandleFunc("/api/v1/trading-bots/{botId}/mode", h.SetBotMode).Methods("PUT")
+	router.Handle("/api/v1/trading-bots/{botId}/orders", h.idempotency.Middleware()(http.HandlerFunc(h.SubmitOrder))).Methods("POST")
 
 	// Bot monitoring endpoints
 	router.HandleFunc("/api/v1/trading-bots/{botId}/status", h.GetBotStatus).Methods("GET")
 	router.HandleFunc("/api/v1/trading-bots/{botId}/performance", h.GetBotPerformance).Methods("GET")
 	router.HandleFunc("/api/v1/trading-bots/{botId}/trades", h.GetBotTrades).Methods("GET")
+	router.HandleFunc("/api/v1/trading-bots/{botId}/schedule", h.GetBotSchedule).Methods("GET")
+	router.HandleFunc("/api/v1/executions/{orderId}", h.GetExecutionReport).Methods("GET")
 
 	// Strategy management endpoints
 	router.HandleFunc("/api/v1/trading-strategies", h.ListStrategies).Methods("GET")
@@ -71,6 +103,48 @@ type CreateBotRequest struct {
 	RiskProfile    *BotRiskProfileRequest `json:"risk_profile"`
 	Capital        *CapitalConfigRequest  `json:"capital"`
 	Enabled        bool                   `json:"enabled"`
+
+	// Execution mode: "paper" (default) or "live", with fill assumptions for paper mode
+	Mode  string                      `json:"mode"`
+	Paper *trading.PaperTradingConfig `json:"paper,omitempty"`
+
+	// Weekly windows in a timezone outside which the bot places no buys
+	Schedule *web3.TradingSchedule `json:"schedule,omitempty"`
+}
+
+// SubmitOrderRequest represents a market order submitted for a bot
+type SubmitOrderRequest struct {
+	Symbol   string          `json:"symbol"`
+	Side     string          `json:"side"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+// Validate returns validation.FieldErrors listing every invalid field of the order
+func (req *SubmitOrderRequest) Validate() error {
+	var v validation.Validator
+	v.Required("symbol", req.Symbol)
+	if v.Required("side", req.Side) {
+		v.OneOf("side", req.Side, string(trading.OrderSideBuy), string(trading.OrderSideSell))
+	}
+	v.Positive("quantity", req.Quantity)
+	return v.Err()
+}
+
+// SetBotModeRequest represents a request to change a bot's execution mode
+type SetBotModeRequest struct {
+	Mode        string `json:"mode"`
+	ConfirmLive bool   `json:"confirm_live"`
+}
+
+// botStrategyIDs maps bot strategies to the strategy manager's default strategy IDs
+var botStrategyIDs = map[trading.BotStrategy]string{
+	trading.StrategyDCA:           "dca_bot",
+	trading.StrategyGrid:          "grid_bot",
+	trading.StrategyMomentum:      "momentum_bot",
+	trading.StrategyMeanReversion: "mean_reversion_bot",
+	trading.StrategyArbitrage:     "arbitrage_bot",
+	trading.StrategyScalping:      "scalping_bot",
+	trading.StrategySwing:         "swing_bot",
 }
 
 // BotRiskProfileRequest represents risk profile configuration
@@ -93,10 +167,19 @@ type BotResponse struct {
 	Name        string                  `json:"name"`
 	Strategy    string                  `json:"strategy"`
 	State       string                  `json:"state"`
+	Mode        string                  `json:"mode"`
 	Config      *BotConfigResponse      `json:"config"`
 	Performance *BotPerformanceResponse `json:"performance"`
+	Paper       *trading.PaperAccount   `json:"paper,omitempty"`
 	CreatedAt   time.Time               `json:"created_at"`
 	UpdatedAt   time.Time               `json:"updated_at"`
+
+	// Exchange connection live orders go through and why the bot was last stopped in error
+	ConnectionID string `json:"connection_id,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+
+	// Signals the strategy would have generated over the last 24 hours, on create and update
+	DryRun []BotDryRunResponse `json:"dry_run,omitempty"`
 }
 
 // BotConfigResponse represents bot configuration response
@@ -107,6 +190,7 @@ type BotConfigResponse struct {
 	StrategyParams map[string]interface{} `json:"strategy_params"`
 	Capital        *CapitalConfigRequest  `json:"capital"`
 	Enabled        bool                   `json:"enabled"`
+	Schedule       *web3.TradingSchedule  `json:"schedule,omitempty"`
 }
 
 // BotPerformanceResponse represents bot performance response
@@ -121,6 +205,8 @@ type BotPerformanceResponse struct {
 	MaxDrawdown   decimal.Decimal `json:"max_drawdown"`
 	SharpeRatio   decimal.Decimal `json:"sharpe_ratio"`
 	LastUpdated   time.Time       `json:"last_updated"`
+
+	SuppressedBySchedule int `json:"suppressed_by_schedule"`
 }
 
 // ListBots handles GET /api/v1/trading-bots
@@ -150,19 +236,20 @@ func (h *TradingBotHandler) CreateBot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req CreateBotRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error(ctx, "Failed to decode create bot request", err, nil)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := h.decoder.Decode(r, &req); err != nil {
+		httputil.WriteRequestError(w, r, err)
 		return
 	}
 
 	// Validate request
 	if err := h.validateCreateBotRequest(&req); err != nil {
-		h.logger.Error(ctx, "Invalid create bot request", err, nil)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		httputil.WriteRequestError(w, r, err)
 		return
 	}
 
+	// Validated above
+	mode, _ := trading.ParseExecutionMode(req.Mode)
+
 	// Create bot configuration
 	botConfig := &trading.BotConfig{
 		TradingPairs:   req.TradingPairs,
@@ -173,7 +260,15 @@ func (h *TradingBotHandler) CreateBot(w http.ResponseWriter, r *http.Request) {
 			InitialBalance:       req.Capital.InitialBalance,
 			AllocationPercentage: req.Capital.AllocationPercentage,
 		},
-		Enabled: req.Enabled,
+		Enabled:  req.Enabled,
+		Mode:     mode,
+		Paper:    req.Paper,
+		Schedule: req.Schedule,
+	}
+	// Bots created by an authenticated user belong to them and can be attached to their
+	// exchange connections
+	if userID, ok := middleware.GetUserID(ctx); ok {
+		botConfig.OwnerID, _ = uuid.Parse(userID)
 	}
 
 	// Register bot with engine
@@ -186,18 +281,69 @@ func (h *TradingBotHandler) CreateBot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The bot is registered either way; without a strategy it only takes manual orders
+	if err := h.attachStrategy(bot); err != nil {
+		h.logger.Error(ctx, "Failed to attach bot strategy", err, map[string]interface{}{
+			"bot_id":   bot.ID,
+			"strategy": req.Strategy,
+		})
+	}
+	strategyType, _ := strategyTypeOf(bot.Strategy)
+
 	h.logger.Info(ctx, "Trading bot created", map[string]interface{}{
 		"bot_id":   bot.ID,
 		"strategy": req.Strategy,
 		"pairs":    req.TradingPairs,
+		"mode":     string(mode),
 	})
 
 	response := h.convertBotToResponse(bot)
+	response.DryRun = h.dryRun(ctx, strategyType, req.StrategyParams, req.Exchange, req.TradingPairs)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
+// attachStrategy attaches the strategy that drives the bot's orders, configured with the
+// bot's parameters. Strategies without a parameterized implementation share the default
+// instance.
+func (h *TradingBotHandler) attachStrategy(bot *trading.TradingBot) error {
+	strategyType, _ := strategyTypeOf(bot.Strategy)
+	strategy, err := h.strategyManager.NewStrategy(strategyType, bot.Config.StrategyParams, bot.Config.TradingPairs)
+	if err != nil {
+		strategyID, ok := botStrategyIDs[bot.Strategy]
+		if !ok {
+			return fmt.Errorf("no strategy for %s: %w", bot.Strategy, err)
+		}
+		if strategy, err = h.strategyManager.GetStrategy(strategyID); err != nil {
+			return fmt.Errorf("default strategy %s unavailable: %w", strategyID, err)
+		}
+	}
+	return h.botEngine.AttachStrategy(bot.ID, strategy)
+}
+
+// RestoreBots restores the bots persisted by the engine's bot store with their strategies
+func (h *TradingBotHandler) RestoreBots(ctx context.Context) error {
+	bots, err := h.botEngine.RestoreBots(ctx)
+	if err != nil {
+		return err
+	}
+	for _, bot := range bots {
+		if err := h.attachStrategy(bot); err != nil {
+			h.logger.Error(ctx, "Failed to attach restored bot strategy", err, map[string]interface{}{
+				"bot_id":   bot.ID,
+				"strategy": string(bot.Strategy),
+			})
+		}
+	}
+	if len(bots) > 0 {
+		h.logger.Info(ctx, "Trading bots restored", map[string]interface{}{
+			"bots": len(bots),
+		})
+	}
+	return nil
+}
+
 // GetBot handles GET /api/v1/trading-bots/{botId}
 func (h *TradingBotHandler) GetBot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -286,8 +432,16 @@ func (h *TradingBotHandler) GetBotStatus(w http.ResponseWriter, r *http.Request)
 		"state":        string(bot.State),
 		"strategy":     string(bot.Strategy),
 		"is_active":    bot.State == trading.StateRunning,
+		"last_error":   bot.LastError,
 		"last_updated": time.Now(),
 	}
+	if grid := bot.Grid(); grid != nil {
+		status["grid"] = grid
+	}
+	if dca := bot.DCA(); dca != nil {
+		status["dca"] = dca
+	}
+	status["cost_basis"] = bot.CostBasis()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
@@ -311,44 +465,133 @@ func (h *TradingBotHandler) GetBotPerformance(w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(performance)
 }
 
-// Helper methods
+// SetBotMode handles PUT /api/v1/trading-bots/{botId}/mode
+func (h *TradingBotHandler) SetBotMode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	botID := vars["botId"]
+
+	var req SetBotModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Mode == "" {
+		http.Error(w, "mode is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.botEngine.GetBot(botID); err != nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
 
-// validateCreateBotRequest validates the create bot request
-func (h *TradingBotHandler) validateCreateBotRequest(req *CreateBotRequest) error {
-	if req.Name == "" {
-		return fmt.Errorf("bot name is required")
+	if err := h.botEngine.SetBotMode(ctx, botID, trading.ExecutionMode(req.Mode), req.ConfirmLive); err != nil {
+		h.logger.Error(ctx, "Failed to change bot mode", err, map[string]interface{}{
+			"bot_id": botID,
+			"mode":   req.Mode,
+		})
+		switch {
+		case errors.Is(err, trading.ErrLiveConfirmationRequired):
+			http.Error(w, err.Error()+"; set confirm_live to true", http.StatusConflict)
+		case errors.Is(err, trading.ErrInvalidExecutionMode):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
 	}
 
-	if req.Strategy == "" {
-		return fmt.Errorf("strategy is required")
+	bot, err := h.botEngine.GetBot(botID)
+	if err != nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
+
+	response := h.convertBotToResponse(bot)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SubmitOrder handles POST /api/v1/trading-bots/{botId}/orders
+func (h *TradingBotHandler) SubmitOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	botID := vars["botId"]
+
+	var req SubmitOrderRequest
+	if err := h.decoder.Decode(r, &req); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
 	}
+	if err := req.Validate(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+	side := trading.OrderSide(req.Side)
 
-	if len(req.TradingPairs) == 0 {
-		return fmt.Errorf("at least one trading pair is required")
+	if _, err := h.botEngine.GetBot(botID); err != nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
 	}
 
-	if req.Exchange == "" {
-		return fmt.Errorf("exchange is required")
+	trade, err := h.botEngine.SubmitOrder(ctx, botID, &trading.BotOrder{
+		Symbol:   req.Symbol,
+		Side:     side,
+		Quantity: req.Quantity,
+	})
+	if err != nil {
+		h.logger.Error(ctx, "Failed to submit bot order", err, map[string]interface{}{
+			"bot_id": botID,
+			"symbol": req.Symbol,
+		})
+		if errors.Is(err, trading.ErrInsufficientPaperFunds) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
 
-	if req.Capital == nil {
-		return fmt.Errorf("capital configuration is required")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(trade)
+}
+
+// GetExecutionReport handles GET /api/v1/executions/{orderId}
+func (h *TradingBotHandler) GetExecutionReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["orderId"]
+
+	if h.executions == nil {
+		http.Error(w, "Execution engine not available", http.StatusServiceUnavailable)
+		return
 	}
 
-	if req.Capital.InitialBalance.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("initial balance must be positive")
+	report, err := h.executions.GetExecutionReport(orderID)
+	if err != nil {
+		if errors.Is(err, trading.ErrExecutionReportNotFound) {
+			http.Error(w, "Execution report not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	return nil
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
 }
 
+// Helper methods
+
 // convertBotToResponse converts a trading bot to response format
 func (h *TradingBotHandler) convertBotToResponse(bot *trading.TradingBot) BotResponse {
-	return BotResponse{
+	response := BotResponse{
 		ID:       bot.ID,
 		Name:     bot.Name,
 		Strategy: string(bot.Strategy),
 		State:    string(bot.State),
+		Mode:     string(bot.Mode),
 		Config: &BotConfigResponse{
 			TradingPairs:   bot.Config.TradingPairs,
 			Exchange:       bot.Config.Exchange,
@@ -358,12 +601,19 @@ func (h *TradingBotHandler) convertBotToResponse(bot *trading.TradingBot) BotRes
 				InitialBalance:       bot.Config.Capital.InitialBalance,
 				AllocationPercentage: bot.Config.Capital.AllocationPercentage,
 			},
-			Enabled: bot.Config.Enabled,
+			Enabled:  bot.Config.Enabled,
+			Schedule: bot.Config.Schedule,
 		},
 		Performance: h.convertPerformanceToResponse(bot.Performance),
-		CreatedAt:   time.Now(), // This should come from the bot
+		Paper:       bot.PaperAccount(),
+		CreatedAt:   bot.CreatedAt,
 		UpdatedAt:   time.Now(), // This should come from the bot
+		LastError:   bot.LastError,
 	}
+	if bot.ConnectionID != uuid.Nil {
+		response.ConnectionID = bot.ConnectionID.String()
+	}
+	return response
 }
 
 // convertPerformanceToResponse converts performance to response format
@@ -383,28 +633,118 @@ func (h *TradingBotHandler) convertPerformanceToResponse(perf *trading.BotPerfor
 		MaxDrawdown:   perf.MaxDrawdown,
 		SharpeRatio:   perf.SharpeRatio,
 		LastUpdated:   perf.LastUpdated,
+
+		SuppressedBySchedule: perf.SuppressedBySchedule,
 	}
 }
 
 // Placeholder implementations for remaining endpoints
-func (h *TradingBotHandler) UpdateBot(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
-}
-
 func (h *TradingBotHandler) DeleteBot(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Not implemented", http.StatusNotImplemented)
 }
 
+// PauseBot handles POST /api/v1/trading-bots/{botId}/pause
 func (h *TradingBotHandler) PauseBot(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+	h.setBotPaused(w, r, true)
 }
 
+// ResumeBot handles POST /api/v1/trading-bots/{botId}/resume
 func (h *TradingBotHandler) ResumeBot(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+	h.setBotPaused(w, r, false)
+}
+
+// setBotPaused pauses or resumes a started bot
+func (h *TradingBotHandler) setBotPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	ctx := r.Context()
+	botID := mux.Vars(r)["botId"]
+
+	var err error
+	if paused {
+		err = h.botEngine.PauseBot(ctx, botID)
+	} else {
+		err = h.botEngine.ResumeBot(ctx, botID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, trading.ErrBotNotFound):
+			http.Error(w, "Bot not found", http.StatusNotFound)
+		case errors.Is(err, trading.ErrBotNotActive):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.Error(ctx, "Failed to change bot pause state", err, map[string]interface{}{
+				"bot_id": botID,
+				"paused": paused,
+			})
+			http.Error(w, "Failed to change bot pause state", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	bot, _ := h.botEngine.GetBot(botID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"bot_id": botID,
+		"state":  string(bot.State),
+	})
 }
 
+// GetBotTrades handles GET /api/v1/trading-bots/{botId}/trades
 func (h *TradingBotHandler) GetBotTrades(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+	vars := mux.Vars(r)
+	botID := vars["botId"]
+
+	bot, err := h.botEngine.GetBot(botID)
+	if err != nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
+
+	trades := bot.Trades()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"bot_id": botID,
+		"mode":   string(bot.Mode),
+		"trades": trades,
+		"count":  len(trades),
+		"stats":  bot.ExecutionStats(),
+	})
+}
+
+// GetBotSchedule handles GET /api/v1/trading-bots/{botId}/schedule, projecting a DCA bot's
+// next buys. count sets how many are returned, 10 by default and at most 100.
+func (h *TradingBotHandler) GetBotSchedule(w http.ResponseWriter, r *http.Request) {
+	botID := mux.Vars(r)["botId"]
+
+	bot, err := h.botEngine.GetBot(botID)
+	if err != nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
+
+	count := 10
+	if value := r.URL.Query().Get("count"); value != "" {
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 || count > 100 {
+			http.Error(w, "count must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+	}
+
+	buys, ok := bot.DCASchedule(time.Now(), count)
+	if !ok {
+		http.Error(w, "Bot does not run a DCA strategy", http.StatusBadRequest)
+		return
+	}
+
+	// A paused bot buys nothing until it is resumed; the projection assumes it is
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"bot_id":    botID,
+		"paused":    bot.State == trading.StatePaused,
+		"schedule":  buys,
+		"positions": bot.DCA(),
+	})
 }
 
 func (h *TradingBotHandler) ListStrategies(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachStrategy(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	engine := trading.NewTradingBotEngine(logger, &trading.BotEngineConfig{MaxConcurrentBots: 10})
+	handler := NewTradingBotHandler(logger, engine, strategies.NewStrategyManager(logger))
+
+	register := func(strategy trading.BotStrategy, params map[string]interface{}) *trading.TradingBot {
+		bot, err := engine.RegisterBot(context.Background(), &trading.BotConfig{
+			Exchange:       "binance",
+			TradingPairs:   []string{"BTC/USDT"},
+			StrategyParams: params,
+			Capital:        &trading.CapitalConfig{InitialBalance: decimal.NewFromInt(1000)},
+		}, strategy)
+		require.NoError(t, err)
+		return bot
+	}
+
+	grid := register(trading.StrategyGrid, map[string]interface{}{
+		"grid_levels": 10, "grid_spacing": 0.01, "upper_bound": 1.1, "lower_bound": 0.9, "order_amount": 100.0,
+	})
+	assert.NoError(t, handler.attachStrategy(grid))
+
+	// Parameters the strategy rejects fall back to the shared default instance
+	assert.NoError(t, handler.attachStrategy(register(trading.StrategyMomentum, map[string]interface{}{"leverage": 10})))
+
+	assert.Error(t, handler.attachStrategy(register(trading.BotStrategy("martingale"), nil)), "no strategy implements it")
+
+	unregistered := &trading.TradingBot{ID: "missing", Strategy: trading.StrategyGrid, Config: grid.Config}
+	assert.ErrorIs(t, handler.attachStrategy(unregistered), trading.ErrBotNotFound)
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+)
+
+// dryRunWindow and dryRunInterval set the candles strategies are replayed over
+const (
+	dryRunWindow   = 24 * time.Hour
+	dryRunInterval = "5m"
+)
+
+// botStrategyTypes maps bot strategies to the strategy types their parameters follow
+var botStrategyTypes = map[trading.BotStrategy]strategies.StrategyType{
+	trading.StrategyDCA:           strategies.StrategyTypeDCA,
+	trading.StrategyGrid:          strategies.StrategyTypeGrid,
+	trading.StrategyMomentum:      strategies.StrategyTypeMomentum,
+	trading.StrategyMeanReversion: strategies.StrategyTypeMeanReversion,
+	trading.StrategyArbitrage:     strategies.StrategyTypeArbitrage,
+	trading.StrategyScalping:      strategies.StrategyTypeScalping,
+	trading.StrategySwing:         strategies.StrategyTypeSwing,
+}
+
+// strategyTypeOf returns the strategy type of a bot strategy, which may also be given by its
+// strategy type name such as "dca"
+func strategyTypeOf(strategy trading.BotStrategy) (strategies.StrategyType, bool) {
+	if strategyType, ok := botStrategyTypes[strategy]; ok {
+		return strategyType, true
+	}
+	for _, strategyType := range botStrategyTypes {
+		if string(strategyType) == string(strategy) {
+			return strategyType, true
+		}
+	}
+	return "", false
+}
+
+// CandleSource serves the historical candles bot strategies are dry-run against
+type CandleSource interface {
+	GetCandles(ctx context.Context, exchange, symbol, interval string, start, end time.Time) ([]realtime.Candle, error)
+}
+
+// BotDryRunResponse is the outcome of replaying a bot's strategy over one trading pair.
+// Error explains why a pair could not be evaluated; it does not make the bot invalid.
+type BotDryRunResponse struct {
+	strategies.DryRunResult
+	Error string `json:"error,omitempty"`
+}
+
+// UpdateBotRequest represents a request to change a bot's strategy parameters
+type UpdateBotRequest struct {
+	StrategyParams map[string]interface{} `json:"strategy_params"`
+}
+
+// SetCandleSource enables dry runs of bot strategies against recent candles
+func (h *TradingBotHandler) SetCandleSource(candles CandleSource) {
+	h.candles = candles
+}
+
+// ValidateBot handles POST /api/v1/bots/validate. The body is a create bot request; the
+// strategy is dry-run against the last 24 hours unless dry_run=false is passed.
+func (h *TradingBotHandler) ValidateBot(w http.ResponseWriter, r *http.Request) {
+	var req CreateBotRequest
+	if err := h.decoder.Decode(r, &req); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	if err := h.validateCreateBotRequest(&req); err != nil {
+		writeBotValidation(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"valid":  true,
+		"errors": validation.FieldErrors{},
+	}
+	if r.URL.Query().Get("dry_run") != "false" {
+		strategyType, _ := strategyTypeOf(trading.BotStrategy(req.Strategy))
+		response["dry_run"] = h.dryRun(r.Context(), strategyType, req.StrategyParams, req.Exchange, req.TradingPairs)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateBot handles PUT /api/v1/trading-bots/{botId}, validating and dry-running the new
+// strategy parameters before they replace the bot's
+func (h *TradingBotHandler) UpdateBot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	botID := mux.Vars(r)["botId"]
+
+	bot, err := h.botEngine.GetBot(botID)
+	if err != nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
+
+	var req UpdateBotRequest
+	if err := h.decoder.Decode(r, &req); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	strategyType, _ := strategyTypeOf(bot.Strategy)
+	if err := h.strategyManager.ValidateParams(strategyType, req.StrategyParams); err != nil {
+		var v validation.Validator
+		v.Merge("strategy_params.", paramFieldErrors(err))
+		httputil.WriteRequestError(w, r, v.Err())
+		return
+	}
+
+	if err := h.botEngine.UpdateStrategyParams(ctx, botID, req.StrategyParams); err != nil {
+		h.logger.Error(ctx, "Failed to update bot", err, map[string]interface{}{
+			"bot_id": botID,
+		})
+		http.Error(w, "Failed to update bot", http.StatusInternalServerError)
+		return
+	}
+
+	response := h.convertBotToResponse(bot)
+	response.DryRun = h.dryRun(ctx, strategyType, req.StrategyParams, bot.Config.Exchange, bot.Config.TradingPairs)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// dryRun replays the strategy over each trading pair's candles of the last 24 hours. Pairs
+// that cannot be evaluated report why; nil is returned without a candle source.
+func (h *TradingBotHandler) dryRun(ctx context.Context, strategyType strategies.StrategyType, params map[string]interface{}, exchange string, pairs []string) []BotDryRunResponse {
+	if h.candles == nil {
+		return nil
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-dryRunWindow)
+	results := make([]BotDryRunResponse, 0, len(pairs))
+	for _, pair := range pairs {
+		response := BotDryRunResponse{DryRunResult: strategies.DryRunResult{Strategy: strategyType, Symbol: pair, From: start, To: end}}
+
+		candles, err := h.candles.GetCandles(ctx, exchange, pair, dryRunInterval, start, end)
+		if err != nil {
+			response.Error = err.Error()
+			results = append(results, response)
+			continue
+		}
+
+		bars := make([]*strategies.MarketData, len(candles))
+		for i, candle := range candles {
+			bars[i] = &strategies.MarketData{
+				Symbol:    pair,
+				Price:     candle.Close,
+				Volume:    candle.Volume,
+				Timestamp: candle.CloseTime,
+			}
+		}
+
+		result, err := h.strategyManager.DryRun(ctx, strategyType, params, pair, bars)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.DryRunResult = *result
+		}
+		results = append(results, response)
+	}
+	return results
+}
+
+// validateCreateBotRequest validates the create bot request, including the strategy
+// parameters against the strategy's schema, and returns validation.FieldErrors listing every
+// invalid field
+func (h *TradingBotHandler) validateCreateBotRequest(req *CreateBotRequest) error {
+	var v validation.Validator
+	v.Required("name", req.Name)
+	if len(req.TradingPairs) == 0 {
+		v.Fail("trading_pairs", validation.ConstraintRequired, "at least one trading pair is required")
+	}
+	v.Required("exchange", req.Exchange)
+	if req.Capital == nil {
+		v.Fail("capital", validation.ConstraintRequired, "is required")
+	} else {
+		v.Positive("capital.initial_balance", req.Capital.InitialBalance)
+		if allocation := req.Capital.AllocationPercentage; allocation.IsNegative() || allocation.GreaterThan(decimal.NewFromInt(100)) {
+			v.Fail("capital.allocation_percentage", validation.ConstraintRange, "must be between 0 and 100")
+		}
+	}
+	if _, err := trading.ParseExecutionMode(req.Mode); err != nil {
+		v.Fail("mode", validation.ConstraintEnum, "must be paper or live")
+	}
+	if req.Paper != nil {
+		v.NotNegative("paper.slippage_bps", req.Paper.SlippageBps)
+		v.NotNegative("paper.fee_rate", req.Paper.FeeRate)
+	}
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			v.Fail("schedule", validation.ConstraintInvalid, err.Error())
+		}
+	}
+	if risk := req.RiskProfile; risk != nil {
+		v.NotNegative("risk_profile.max_position_size", risk.MaxPositionSize)
+		v.NotNegative("risk_profile.stop_loss", risk.StopLoss)
+		v.NotNegative("risk_profile.take_profit", risk.TakeProfit)
+		v.NotNegative("risk_profile.max_drawdown", risk.MaxDrawdown)
+		if risk.StopLoss.IsPositive() && risk.TakeProfit.IsPositive() && risk.TakeProfit.LessThanOrEqual(risk.StopLoss) {
+			v.Fail("risk_profile.take_profit", validation.ConstraintRange, "must be greater than stop_loss")
+		}
+	}
+
+	if !v.Required("strategy", req.Strategy) {
+		return v.Err()
+	}
+	strategyType, ok := strategyTypeOf(trading.BotStrategy(req.Strategy))
+	if !ok {
+		v.Fail("strategy", validation.ConstraintEnum, "unknown strategy")
+	} else if err := h.strategyManager.ValidateParams(strategyType, req.StrategyParams); err != nil {
+		v.Merge("strategy_params.", paramFieldErrors(err))
+	} else if _, absolute := req.StrategyParams["upper_price"]; absolute && strategyType == strategies.StrategyTypeGrid && len(req.TradingPairs) > 1 {
+		v.Fail("trading_pairs", validation.ConstraintInvalid, "a grid with upper_price and lower_price trades a single pair")
+	}
+	return v.Err()
+}
+
+// paramFieldErrors converts a strategy parameter validation error to field errors
+func paramFieldErrors(err error) error {
+	var paramErrors strategies.ParamErrors
+	if !errors.As(err, &paramErrors) {
+		return err
+	}
+	fieldErrors := make(validation.FieldErrors, len(paramErrors))
+	for i, paramError := range paramErrors {
+		constraint := paramError.Constraint
+		if constraint == "" {
+			constraint = validation.ConstraintInvalid
+		}
+		fieldErrors[i] = validation.FieldError{Field: paramError.Field, Constraint: constraint, Message: paramError.Message}
+	}
+	return fieldErrors
+}
+
+// writeBotValidation responds 422 with the invalid fields of a bot validation
+func writeBotValidation(w http.ResponseWriter, err error) {
+	var fieldErrors validation.FieldErrors
+	if !errors.As(err, &fieldErrors) {
+		fieldErrors = validation.FieldErrors{{Constraint: validation.ConstraintInvalid, Message: err.Error()}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":  false,
+		"errors": fieldErrors,
+	})
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBot(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	handler := NewTradingBotHandler(logger, nil, strategies.NewStrategyManager(logger))
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	validate := func(body string) (int, map[string]json.RawMessage) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bots/validate", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var response map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response), rec.Body.String())
+		return rec.Code, response
+	}
+
+	t.Run("valid bot", func(t *testing.T) {
+		status, response := validate(`{
+			"name": "grid",
+			"strategy": "grid_trading",
+			"trading_pairs": ["BTC/USDT"],
+			"exchange": "binance",
+			"capital": {"initial_balance": "1000"},
+			"strategy_params": {"grid_levels": 10, "grid_spacing": 0.01, "upper_bound": 1.1, "lower_bound": 0.9, "order_amount": 100}
+		}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `true`, string(response["valid"]))
+		assert.JSONEq(t, `[]`, string(response["errors"]))
+	})
+
+	t.Run("invalid bot lists every field", func(t *testing.T) {
+		status, response := validate(`{
+			"name": "momentum",
+			"strategy": "momentum",
+			"trading_pairs": ["BTC/USDT"],
+			"exchange": "binance",
+			"strategy_params": {"momentum_period": 500, "rsi_threshold_buy": 30, "stop_loss": 0.05, "take_profit": 0.05, "leverage": 10}
+		}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `false`, string(response["valid"]))
+
+		var fieldErrors validation.FieldErrors
+		require.NoError(t, json.Unmarshal(response["errors"], &fieldErrors))
+		constraints := make(map[string]string, len(fieldErrors))
+		for _, fieldErr := range fieldErrors {
+			assert.NotEmpty(t, fieldErr.Message, fieldErr.Field)
+			constraints[fieldErr.Field] = fieldErr.Constraint
+		}
+		assert.Equal(t, map[string]string{
+			"capital":                            validation.ConstraintRequired,
+			"strategy_params.momentum_period":    validation.ConstraintRange,
+			"strategy_params.rsi_threshold_sell": validation.ConstraintRequired,
+			"strategy_params.leverage":           validation.ConstraintUnknown,
+			"strategy_params.take_profit":        validation.ConstraintRange,
+		}, constraints)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		status, response := validate(`{"name": "x", "strategy": "martingale", "trading_pairs": ["BTC/USDT"], "exchange": "binance", "capital": {"initial_balance": "1000"}}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `[{"field": "strategy", "constraint": "enum", "message": "unknown strategy"}]`, string(response["errors"]))
+	})
+
+	t.Run("grid with absolute prices trades one pair", func(t *testing.T) {
+		status, response := validate(`{
+			"name": "grid",
+			"strategy": "grid",
+			"trading_pairs": ["BTC/USDT", "ETH/USDT"],
+			"exchange": "binance",
+			"capital": {"initial_balance": "1000"},
+			"strategy_params": {"grid_levels": 10, "upper_price": 110, "lower_price": 90, "order_amount": 100}
+		}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, string(response["errors"]), `"field":"trading_pairs"`)
+	})
+}
@@ -4,25 +4,38 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/ai"
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/analytics"
+	"github.com/ai-agentic-browser/internal/api"
+	"github.com/ai-agentic-browser/internal/auth"
 	"github.com/ai-agentic-browser/internal/browser"
 	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/internal/web3"
 	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/ml"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
+	gorillamux "github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -57,8 +70,27 @@ func main() {
 	perfMonitor := observability.NewPerformanceMonitor(logger)
 	defer perfMonitor.Stop()
 
+	// Prometheus collectors fed by the performance monitor, cache and AI services
+	promExporter := observability.NewPrometheusExporter("ai-agent")
+	perfMonitor.SetPrometheusExporter(promExporter)
+	if err := promExporter.RegisterDB("postgres", db.DB); err != nil {
+		log.Fatalf("Failed to register database metrics: %v", err)
+	}
+
 	// Initialize caching middleware
 	cacheMiddleware := middleware.NewCacheMiddleware(redis, logger)
+	cacheMiddleware.SetPrometheusExporter(promExporter)
+	if err := cacheMiddleware.SetRouteTTLs(cfg.Redis.ResponseCacheTTL, cfg.Redis.ResponseCacheRoutes); err != nil {
+		log.Fatalf("Failed to configure response cache: %v", err)
+	}
+	revocations := middleware.NewTokenRevocationList(redis)
+	adminAuthorizer := middleware.NewAdminAuthorizer(cfg.JWT.Secret, revocations, cfg.Security.AdminUsers)
+	cacheMiddleware.SetAdminAuthorizer(adminAuthorizer)
+	routePolicy, err := middleware.NewRoutePolicy(cfg.Security.RoutePolicy)
+	if err != nil {
+		log.Fatalf("Failed to configure route policy: %v", err)
+	}
+	routePolicy.SetAdminAuthorizer(adminAuthorizer)
 
 	logger.Info(context.Background(), "Database and caching optimizations initialized", map[string]interface{}{
 		"db_max_open_conns":      cfg.Database.MaxOpenConns,
@@ -72,13 +104,103 @@ func main() {
 	browserService := browser.NewService(db, redis, cfg.Browser, logger)
 
 	// Initialize enhanced AI components
+	completionRouter := ai.NewProviderRouter(logger, cfg.AI)
+	modelPrices, err := ai.NewModelPriceTable(cfg.AI.ModelPrices, ai.TokenPricing{PromptPer1K: cfg.AI.ChatPromptCostPer1K, CompletionPer1K: cfg.AI.ChatCompletionCostPer1K})
+	if err != nil {
+		log.Fatalf("Invalid AI model prices: %v", err)
+	}
+	usageAccountant := ai.NewUsageAccountant(logger, ai.NewRedisUsageCounters(redis), ai.NewPostgresUsageStore(db),
+		modelPrices, cfg.AI.UserMonthlyBudgetUSD, cfg.AI.UsageFlushInterval)
+	completionRouter.SetUsageAccountant(usageAccountant)
 	enhancedAI := ai.NewEnhancedAIService(logger)
+	enhancedAI.SetPrometheusExporter(promExporter)
+	enhancedAI.SetCompletionRouter(completionRouter)
+	enhancedAI.SetDecisionExecutor(ai.NewRedisDecisionExecutor(redis, "ai:decisions:execute"))
+	enhancedAI.SetTranslationProvider(ai.NewTranslationProvider(cfg.AI))
 	multiModalEngine := ai.NewMultiModalEngine(logger)
+	documentIndex := ai.NewDocumentIndex(logger, ai.NewEmbeddingProvider(cfg.AI), ai.NewPostgresDocumentStore(db),
+		cfg.AI.DocumentChunkWords, cfg.AI.DocumentChunkOverlap)
+	multiModalEngine.SetDocumentIndex(documentIndex)
 	userBehaviorEngine := ai.NewUserBehaviorLearningEngine(logger)
 	marketAdaptationEngine := ai.NewMarketAdaptationEngine(logger)
+	// Backtests read the candle cache, so only ranges it is missing reach the exchange
+	candleSource := newCachedCandleSource(logger, db, "binance")
+	marketAdaptationEngine.SetCandleSource(candleSource)
+	derivatives := newDerivativesSummarySource(logger, db, cfg.Web3)
+	marketAdaptationEngine.SetDerivativesSource(derivatives)
 	voiceInterface := ai.NewVoiceInterface(logger, nil, nil, nil)
+	voiceInterface.SetTTSProvider(ai.NewTTSProvider(cfg.AI))
+	voiceInterface.SetSpeechCache(ai.NewRedisSpeechCache(redis, cfg.AI.TTSCacheTTL))
 	conversationalAI := ai.NewConversationalAI(logger, nil, nil, nil)
+	conversationalAI.SetProviderRegistry(ai.NewProviderRegistry(logger, cfg.AI))
+	conversationalAI.SetCompletionRouter(completionRouter)
+	conversationalAI.SetConversationStore(ai.NewPostgresConversationStore(db))
+	conversationalAI.SetContextConfig(ai.ChatContextConfig{
+		Messages:         cfg.AI.ChatContextMessages,
+		SummaryMaxTokens: cfg.AI.ChatSummaryMaxTokens,
+		Pricing:          ai.TokenPricing{PromptPer1K: cfg.AI.ChatPromptCostPer1K, CompletionPer1K: cfg.AI.ChatCompletionCostPer1K},
+	})
+	if err := enhancedAI.SetModelVersionStore(context.Background(), ml.NewPostgresVersionStore(db)); err != nil {
+		logger.Warn(context.Background(), "Failed to load model versions, serving initial models", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
 	cryptoCoinAnalyzer := ai.NewCryptoCoinAnalyzer(logger)
+	cryptoCoinAnalyzer.SetBatchWorkers(cfg.AI.CryptoBatchWorkers)
+	cryptoCoinAnalyzer.SetSearchCache(ai.NewRedisSearchResultCache(redis, cfg.AI.CryptoSearchCacheTTL))
+	cryptoCoinAnalyzer.ConfigureNewsSources(cfg.AI.News)
+	cryptoCoinAnalyzer.SetDerivativesSource(derivatives)
+	conversationalAI.SetActionServices(ai.ChatActionServices{Coins: cryptoCoinAnalyzer})
+
+	// Scheduled reports run until shutdown and alert when a run keeps failing, models alert on drift
+	alertService := alerts.NewAlertService(logger, alerts.NewAlertConfig(cfg.Alerts))
+	alertService.SetPreferenceStore(alerts.NewPostgresNotificationStore(db))
+	if err := alertService.Start(); err != nil {
+		logger.Error(context.Background(), "Failed to start alert service", err)
+	}
+	enhancedAI.SetAlertService(alertService)
+	reportScheduler := ai.NewCryptoReportScheduler(logger, ai.NewPostgresCryptoReportStore(db), cryptoCoinAnalyzer, alertService, cfg.AI.Reports)
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	usageAccountant.Start(workersCtx)
+	if err := reportScheduler.Start(workersCtx); err != nil {
+		logger.Warn(context.Background(), "Failed to start report scheduler", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	// Served price predictions are resolved against cached candles to measure model accuracy
+	predictionEvaluator := ai.NewPredictionEvaluator(logger, ai.NewPostgresPredictionStore(db),
+		candleSource, cfg.AI.PredictionEvaluationInterval)
+	enhancedAI.SetPredictionEvaluator(predictionEvaluator)
+	predictionEvaluator.Start(workersCtx, "price_prediction")
+
+	// Erasure requests, created through the web3 service, delete conversations and behavior profiles here
+	privacyManager := security.NewPrivacyManager(logger, &security.PrivacyConfig{
+		EnableGDPRCompliance: true,
+		EnableRightToErasure: true,
+	}, nil)
+	privacyManager.SetErasureStore(security.NewPostgresErasureStore(db), cfg.Security.ErasureDataOwners, []byte(cfg.Security.ErasureSigningKey))
+	privacyManager.RegisterDataOwner(security.NewDeletingDataOwner(security.DataOwnerConversations, conversationalAI.DeleteUserConversations))
+	privacyManager.RegisterDataOwner(security.NewDeletingDataOwner(security.DataOwnerBehaviorProfiles, userBehaviorEngine.DeleteUserData))
+	privacyManager.StartErasureWorker(workersCtx, cfg.Security.ErasureCheckInterval)
+
+	// Behavior profile imports and resets are recorded in the audit log, archived under
+	// AUDIT_ARCHIVE_DIR/ai-agent
+	behaviorArchiveDir := ""
+	if cfg.Security.AuditArchiveDir != "" {
+		behaviorArchiveDir = filepath.Join(cfg.Security.AuditArchiveDir, "ai-agent")
+	}
+	behaviorAudit, err := security.NewServiceAuditManager(logger, cfg.Security, behaviorArchiveDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize behavior audit log: %v", err)
+	}
+	if err := behaviorAudit.Start(workersCtx); err != nil {
+		logger.Warn(context.Background(), "Failed to start behavior audit log", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	userBehaviorEngine.SetAuditor(behaviorAudit)
 
 	logger.Info(context.Background(), "AI services initialized", map[string]interface{}{
 		"enhanced_ai":       enhancedAI != nil,
@@ -87,8 +209,31 @@ func main() {
 		"conversational_ai": conversationalAI != nil,
 	})
 
+	// Analytics dashboards are shared through signed, revocable public links when a signing key
+	// is configured; the links are kept in the database
+	dashboards := analytics.NewDashboardManager(logger, &analytics.AnalyticsConfig{})
+	if err := dashboards.Start(workersCtx); err != nil {
+		logger.Warn(context.Background(), "Failed to start dashboard manager", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if cfg.Security.DashboardShareKey != "" {
+		dashboards.SetShareSigningKey([]byte(cfg.Security.DashboardShareKey))
+	}
+	if err := dashboards.SetShareStore(context.Background(), analytics.NewPostgresShareStore(db)); err != nil {
+		logger.Warn(context.Background(), "Failed to load dashboard shares", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	dashboardHandlers := api.NewAnalyticsHandlers(logger, nil)
+	dashboardHandlers.SetDashboardManager(dashboards)
+
+	// Maintenance mode is enabled through the gateway and shared through Redis
+	maintenance := middleware.NewMaintenanceMode(redis, logger, cfg.Maintenance)
+	go maintenance.Run(workersCtx)
+
 	// Create HTTP server with performance optimizations
-	handler := setupRoutes(browserService, enhancedAI, multiModalEngine, userBehaviorEngine, marketAdaptationEngine, voiceInterface, conversationalAI, cryptoCoinAnalyzer, cfg, logger, db, perfMonitor, cacheMiddleware)
+	handler := setupRoutes(browserService, enhancedAI, multiModalEngine, userBehaviorEngine, marketAdaptationEngine, voiceInterface, conversationalAI, cryptoCoinAnalyzer, reportScheduler, cfg, logger, db, perfMonitor, promExporter, cacheMiddleware, newRateLimiter(redis, cfg, logger), revocations, adminAuthorizer, middleware.NewAPIKeyAuthenticator(auth.NewAPIKeyStore(db), redis, logger, cfg.RateLimit), routePolicy, usageAccountant, documentIndex, dashboardHandlers, maintenance)
 
 	server := &http.Server{
 		Addr:           fmt.Sprintf("%s:%s", cfg.Server.Host, "8082"), // AI Agent port
@@ -123,6 +268,9 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	stopWorkers()
+	reportScheduler.Wait()
+	alertService.Stop()
 
 	logger.Info(context.Background(), "AI agent service stopped")
 }
@@ -136,11 +284,22 @@ func setupRoutes(
 	voiceInterface *ai.VoiceInterface,
 	conversationalAI *ai.ConversationalAI,
 	cryptoCoinAnalyzer *ai.CryptoCoinAnalyzer,
+	reportScheduler *ai.CryptoReportScheduler,
 	cfg *config.Config,
 	logger *observability.Logger,
 	db *database.DB,
 	perfMonitor *observability.PerformanceMonitor,
+	promExporter *observability.PrometheusExporter,
 	cacheMiddleware *middleware.CacheMiddleware,
+	rateLimiter *middleware.RateLimiter,
+	revocations *middleware.TokenRevocationList,
+	adminAuthorizer *middleware.AdminAuthorizer,
+	apiKeys *middleware.APIKeyAuthenticator,
+	routePolicy *middleware.RoutePolicy,
+	usageAccountant *ai.UsageAccountant,
+	documentIndex *ai.DocumentIndex,
+	dashboardHandlers *api.AnalyticsHandlers,
+	maintenance *middleware.MaintenanceMode,
 ) http.Handler {
 	mux := http.NewServeMux()
 
@@ -148,9 +307,13 @@ func setupRoutes(
 	handler := middleware.Recovery(logger)(
 		middleware.Logging(logger)(
 			middleware.Tracing("ai-agent")(
-				cacheMiddleware.Middleware()(
-					middleware.CORS(cfg.Security.CORSAllowedOrigins)(
-						middleware.RateLimit(cfg.RateLimit)(mux),
+				maintenance.Middleware()(
+					cacheMiddleware.Middleware()(
+						middleware.CORS(cfg.Security.CORSAllowedOrigins)(
+							rateLimiter.Middleware()(
+								middleware.Metrics(perfMonitor)(mux),
+							),
+						),
 					),
 				),
 			),
@@ -164,12 +327,15 @@ func setupRoutes(
 
 		// Check database health
 		if err := db.Health(ctx); err != nil {
-			http.Error(w, "Database unhealthy", http.StatusServiceUnavailable)
+			httputil.Error(w, r, "Database unhealthy", http.StatusServiceUnavailable)
 			return
 		}
 
-		// Get performance status
+		// Get performance status; draining pods stay healthy to orchestrators
 		healthStatus := perfMonitor.GetHealthStatus()
+		if maintenance.Enabled() {
+			healthStatus["status"] = middleware.HealthStatusMaintenance
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(healthStatus)
@@ -192,6 +358,9 @@ func setupRoutes(
 		json.NewEncoder(w).Encode(response)
 	})
 
+	// Prometheus scrape endpoint
+	mux.Handle("GET /metrics/prometheus", promExporter.Handler())
+
 	// Database metrics endpoint
 	mux.HandleFunc("GET /metrics/database", func(w http.ResponseWriter, r *http.Request) {
 		metrics := db.GetMetrics()
@@ -206,6 +375,11 @@ func setupRoutes(
 		json.NewEncoder(w).Encode(metrics)
 	})
 
+	// Admin endpoints
+	mux.Handle("POST /admin/cache/invalidate", adminAuthorizer.Middleware()(handleCacheInvalidate(cacheMiddleware, logger)))
+	mux.Handle("GET /admin/ai/usage", adminAuthorizer.Middleware()(handleUsageReport(usageAccountant, logger)))
+	mux.Handle("PUT /admin/ai/usage/budgets/{user_id}", adminAuthorizer.Middleware()(handleSetUsageBudget(usageAccountant, logger)))
+
 	// AI providers health check (simplified for new architecture)
 	mux.HandleFunc("GET /health/ai", handleAIHealth(conversationalAI, logger))
 	mux.HandleFunc("GET /health/ai/{provider}", handleProviderHealth(conversationalAI, logger))
@@ -213,19 +387,27 @@ func setupRoutes(
 	mux.HandleFunc("GET /health/ai/{provider}/models", handleProviderModels(conversationalAI, logger))
 
 	// Protected AI endpoints (enhanced)
+	decoder := httputil.BodyDecoder{Strict: cfg.Server.StrictJSON}
 	protectedMux := http.NewServeMux()
 	protectedMux.HandleFunc("POST /ai/chat", handleChat(conversationalAI, logger))
 	protectedMux.HandleFunc("POST /ai/voice/command", handleVoiceCommandSimple(voiceInterface, logger))
 	protectedMux.HandleFunc("POST /ai/conversations/start", handleStartConversationSimple(conversationalAI, logger))
+	protectedMux.HandleFunc("GET /ai/conversations", handleListConversations(conversationalAI, logger))
+	protectedMux.HandleFunc("GET /ai/conversations/{id}/messages", handleListConversationMessages(conversationalAI, logger))
+	protectedMux.HandleFunc("GET /ai/usage/me", handleMyUsage(usageAccountant, logger))
 
 	// Enhanced AI endpoints
-	protectedMux.HandleFunc("POST /ai/analyze", handleEnhancedAnalysis(enhancedAI, logger))
-	protectedMux.HandleFunc("POST /ai/predict/price", handlePricePrediction(enhancedAI, logger))
+	protectedMux.HandleFunc("POST /ai/analyze", handleEnhancedAnalysis(enhancedAI, decoder, logger))
+	protectedMux.HandleFunc("POST /ai/predict/price", handlePricePrediction(enhancedAI, decoder, logger))
 	protectedMux.HandleFunc("POST /ai/analyze/sentiment", handleSentimentAnalysis(enhancedAI, logger))
 	protectedMux.HandleFunc("POST /ai/analytics/predictive", handlePredictiveAnalytics(enhancedAI, logger))
 	protectedMux.HandleFunc("GET /ai/models/status", handleModelStatus(enhancedAI, logger))
 	protectedMux.HandleFunc("POST /ai/models/train", handleModelTraining(enhancedAI, logger))
 	protectedMux.HandleFunc("POST /ai/models/feedback", handleModelFeedback(enhancedAI, logger))
+	protectedMux.HandleFunc("POST /ai/models/{id}/versions/{version}/activate", handleActivateModelVersion(enhancedAI, logger))
+	protectedMux.HandleFunc("POST /ai/models/{id}/rollback", handleRollbackModel(enhancedAI, logger))
+	protectedMux.HandleFunc("PUT /ai/models/{id}/drift", handleSetDriftConfig(enhancedAI, logger))
+	protectedMux.HandleFunc("GET /ai/models/{id}/evaluation", handleModelEvaluation(enhancedAI, logger))
 
 	// Learning and adaptation endpoints
 	protectedMux.HandleFunc("POST /ai/learning/behavior", handleUserBehaviorLearning(enhancedAI, logger))
@@ -243,6 +425,8 @@ func setupRoutes(
 	protectedMux.HandleFunc("POST /ai/decisions/request", handleDecisionRequest(enhancedAI, logger))
 	protectedMux.HandleFunc("GET /ai/decisions/active", handleGetActiveDecisions(enhancedAI, logger))
 	protectedMux.HandleFunc("GET /ai/decisions/history", handleGetDecisionHistory(enhancedAI, logger))
+	protectedMux.HandleFunc("POST /ai/decisions/{id}/approve", handleApproveDecision(enhancedAI, logger))
+	protectedMux.HandleFunc("POST /ai/decisions/{id}/reject", handleRejectDecision(enhancedAI, logger))
 	protectedMux.HandleFunc("GET /ai/decisions/performance", handleGetDecisionPerformance(enhancedAI, logger))
 
 	// Multi-Modal AI endpoints
@@ -253,9 +437,19 @@ func setupRoutes(
 	protectedMux.HandleFunc("POST /ai/multimodal/chart", handleChartAnalysis(multiModalEngine, logger))
 	protectedMux.HandleFunc("GET /ai/multimodal/formats", handleGetSupportedFormats(multiModalEngine, logger))
 
+	// Semantic search over analyzed documents
+	protectedMux.HandleFunc("POST /ai/documents/search", handleDocumentSearch(documentIndex, logger))
+	protectedMux.HandleFunc("GET /ai/documents", handleListDocuments(documentIndex, logger))
+	protectedMux.HandleFunc("DELETE /ai/documents/{id}", handleDeleteDocument(documentIndex, logger))
+
 	// User Behavior Learning endpoints
 	protectedMux.HandleFunc("POST /ai/behavior/learn", handleLearnFromBehavior(userBehaviorEngine, logger))
 	protectedMux.HandleFunc("GET /ai/behavior/profile", handleGetUserBehaviorProfile(userBehaviorEngine, logger))
+	protectedMux.HandleFunc("DELETE /ai/behavior/profile", handleResetUserBehaviorProfile(userBehaviorEngine, logger))
+	protectedMux.HandleFunc("GET /ai/behavior/profile/export", handleExportUserBehaviorProfile(userBehaviorEngine, logger))
+	protectedMux.HandleFunc("POST /ai/behavior/profile/import", handleImportUserBehaviorProfile(userBehaviorEngine, logger))
+	protectedMux.HandleFunc("POST /ai/behavior/onboarding", handleBehaviorOnboarding(userBehaviorEngine, logger))
+	protectedMux.HandleFunc("GET /ai/behavior/cohorts", handleGetBehaviorCohorts(userBehaviorEngine, logger))
 	protectedMux.HandleFunc("GET /ai/behavior/recommendations", handleGetRecommendations(userBehaviorEngine, logger))
 	protectedMux.HandleFunc("GET /ai/behavior/history", handleGetBehaviorHistory(userBehaviorEngine, logger))
 	protectedMux.HandleFunc("PUT /ai/behavior/recommendation/{id}/status", handleUpdateRecommendationStatus(userBehaviorEngine, logger))
@@ -268,34 +462,185 @@ func setupRoutes(
 	protectedMux.HandleFunc("GET /ai/market/strategies", handleGetAdaptiveStrategies(marketAdaptationEngine, logger))
 	protectedMux.HandleFunc("POST /ai/market/strategies", handleAddAdaptiveStrategy(marketAdaptationEngine, logger))
 	protectedMux.HandleFunc("PUT /ai/market/strategies/{id}/status", handleUpdateStrategyStatus(marketAdaptationEngine, logger))
+	protectedMux.HandleFunc("POST /ai/market/strategies/{id}/backtest", handleBacktestStrategy(marketAdaptationEngine, logger))
 	protectedMux.HandleFunc("GET /ai/market/adaptation/history", handleGetMarketAdaptationHistory(marketAdaptationEngine, logger))
 	protectedMux.HandleFunc("GET /ai/market/performance/{strategy_id}", handleGetStrategyPerformanceMetrics(marketAdaptationEngine, logger))
 
 	// Crypto Coin Analyzer endpoints
+	protectedMux.HandleFunc("POST /ai/crypto/analyze/batch", handleCryptoCoinBatchAnalysis(cryptoCoinAnalyzer, logger))
 	protectedMux.HandleFunc("POST /ai/crypto/analyze/{symbol}", handleCryptoCoinAnalysis(cryptoCoinAnalyzer, logger))
 	protectedMux.HandleFunc("GET /ai/crypto/analyze/{symbol}", handleCryptoCoinAnalysis(cryptoCoinAnalyzer, logger))
 	protectedMux.HandleFunc("POST /ai/crypto/report/{symbol}", handleCryptoCoinReport(cryptoCoinAnalyzer, logger))
 	protectedMux.HandleFunc("GET /ai/crypto/report/{symbol}", handleCryptoCoinReport(cryptoCoinAnalyzer, logger))
+	protectedMux.HandleFunc("POST /ai/crypto/reports/schedules", handleCreateReportSchedule(reportScheduler, logger))
+	protectedMux.HandleFunc("GET /ai/crypto/reports/schedules", handleListReportSchedules(reportScheduler, logger))
+	protectedMux.HandleFunc("DELETE /ai/crypto/reports/schedules/{id}", handleDeleteReportSchedule(reportScheduler, logger))
+	protectedMux.HandleFunc("GET /ai/crypto/reports", handleListCryptoReports(reportScheduler, logger))
+	protectedMux.HandleFunc("GET /ai/crypto/reports/{id}", handleGetCryptoReport(reportScheduler, logger))
+
+	// Dashboard share links are managed by signed-in users and rendered without
+	// authentication, the signed token in the link being the credential
+	dashboardRoutes := gorillamux.NewRouter()
+	dashboardHandlers.RegisterShareRoutes(dashboardRoutes)
+	dashboardHandlers.RegisterPublicRoutes(dashboardRoutes)
+	mux.Handle("/api/analytics/dashboards/", middleware.JWTWithRevocation(cfg.JWT.Secret, revocations)(dashboardRoutes))
+	mux.Handle("GET /api/public/dashboards/{token}", dashboardRoutes)
 
 	// Apply JWT middleware to protected routes
-	mux.Handle("/ai/", middleware.JWT(cfg.JWT.Secret)(protectedMux))
+	// Protected routes accept a JWT or an API key with the ai:invoke scope, and need the role the
+	// route policy sets for them. Their provider calls are billed to the user and route.
+	mux.Handle("/ai/", apiKeys.Middleware(middleware.JWTWithRevocation(cfg.JWT.Secret, revocations), middleware.StaticScope(middleware.ScopeAIInvoke))(routePolicy.Middleware()(usageAttribution(usageAccountant, protectedMux))))
 
 	return handler
 }
 
+// usageAttribution attributes the provider calls of a request to its user and route, and
+// refuses requests of users over their monthly budget with 402. The chat falls back to
+// built-in replies when providers refuse, so the budget is checked before any handler runs.
+func usageAttribution(usageAccountant *ai.UsageAccountant, routes *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := middleware.GetUserID(r.Context())
+		_, pattern := routes.Handler(r)
+		ctx := ai.WithUsageAttribution(r.Context(), ai.UsageAttribution{UserID: userID, Endpoint: pattern})
+
+		if userID != "" && pattern != "" && !strings.HasPrefix(r.URL.Path, "/ai/usage/") {
+			if err := usageAccountant.CheckBudget(ctx); err != nil {
+				httputil.Error(w, r, err.Error(), http.StatusPaymentRequired)
+				return
+			}
+		}
+		routes.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// handleMyUsage returns the caller's AI usage within range, 30 days by default, grouped by
+// endpoint or model, along with their monthly budget
+func handleMyUsage(usageAccountant *ai.UsageAccountant, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		groupBy := r.URL.Query().Get("group_by")
+		if groupBy == "" {
+			groupBy = ai.UsageGroupByEndpoint
+		}
+		if groupBy == ai.UsageGroupByUser {
+			httputil.Error(w, r, "group_by must be endpoint or model", http.StatusBadRequest)
+			return
+		}
+		report, ok := usageReport(w, r, usageAccountant, groupBy, userID, logger)
+		if !ok {
+			return
+		}
+
+		budget, err := usageAccountant.Budget(r.Context(), userID)
+		if err != nil {
+			writeInternalError(w, r, logger, "Failed to load AI usage budget", err)
+			return
+		}
+		report.Budget = budget
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// handleUsageReport returns everyone's AI usage within range, 30 days by default, grouped by
+// user, endpoint or model
+func handleUsageReport(usageAccountant *ai.UsageAccountant, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		groupBy := r.URL.Query().Get("group_by")
+		if groupBy == "" {
+			groupBy = ai.UsageGroupByUser
+		}
+		report, ok := usageReport(w, r, usageAccountant, groupBy, "", logger)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// usageReport builds a usage report over the request's range, writing the error when it fails
+func usageReport(w http.ResponseWriter, r *http.Request, usageAccountant *ai.UsageAccountant, groupBy, userID string, logger *observability.Logger) (*ai.UsageReport, bool) {
+	period := 30 * 24 * time.Hour
+	if value := r.URL.Query().Get("range"); value != "" {
+		var err error
+		if period, err = analytics.ParseHistoryDuration(value); err != nil {
+			httputil.Error(w, r, "Invalid range", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	report, err := usageAccountant.Report(r.Context(), groupBy, userID, period)
+	if err != nil {
+		if errors.Is(err, ai.ErrInvalidUsageQuery) {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+		} else {
+			writeInternalError(w, r, logger, "AI usage report failed", err)
+		}
+		return nil, false
+	}
+	return report, true
+}
+
+// handleSetUsageBudget sets a user's monthly AI budget; zero removes their limit
+func handleSetUsageBudget(usageAccountant *ai.UsageAccountant, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		userID := r.PathValue("user_id")
+		if err := usageAccountant.SetBudget(r.Context(), userID, req.MonthlyLimitUSD); err != nil {
+			if errors.Is(err, ai.ErrInvalidUsageBudget) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			} else {
+				logger.Error(r.Context(), "Failed to set AI usage budget", err, map[string]interface{}{
+					"user_id": userID,
+				})
+				httputil.Error(w, r, "Failed to set AI usage budget", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		budget, err := usageAccountant.Budget(r.Context(), userID)
+		if err != nil {
+			writeInternalError(w, r, logger, "Failed to load AI usage budget", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(budget)
+	}
+}
+
 // Enhanced AI handlers
 
-func handleEnhancedAnalysis(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+func handleEnhancedAnalysis(enhancedAI *ai.EnhancedAIService, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var req ai.AIRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
 			return
 		}
 
@@ -305,8 +650,7 @@ func handleEnhancedAnalysis(enhancedAI *ai.EnhancedAIService, logger *observabil
 
 		response, err := enhancedAI.ProcessRequest(r.Context(), &req)
 		if err != nil {
-			logger.Error(r.Context(), "Enhanced AI analysis failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Enhanced AI analysis failed", err)
 			return
 		}
 
@@ -315,17 +659,21 @@ func handleEnhancedAnalysis(enhancedAI *ai.EnhancedAIService, logger *observabil
 	}
 }
 
-func handlePricePrediction(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+func handlePricePrediction(enhancedAI *ai.EnhancedAIService, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var predictionReq ai.PricePredictionRequest
-		if err := json.NewDecoder(r.Body).Decode(&predictionReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := decoder.Decode(r, &predictionReq); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := predictionReq.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
 			return
 		}
 
@@ -347,8 +695,7 @@ func handlePricePrediction(enhancedAI *ai.EnhancedAIService, logger *observabili
 
 		response, err := enhancedAI.ProcessRequest(r.Context(), aiReq)
 		if err != nil {
-			logger.Error(r.Context(), "Price prediction failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Price prediction failed", err)
 			return
 		}
 
@@ -361,13 +708,13 @@ func handleSentimentAnalysis(enhancedAI *ai.EnhancedAIService, logger *observabi
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var sentimentReq ai.SentimentRequest
 		if err := json.NewDecoder(r.Body).Decode(&sentimentReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -387,8 +734,7 @@ func handleSentimentAnalysis(enhancedAI *ai.EnhancedAIService, logger *observabi
 
 		response, err := enhancedAI.ProcessRequest(r.Context(), aiReq)
 		if err != nil {
-			logger.Error(r.Context(), "Sentiment analysis failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Sentiment analysis failed", err)
 			return
 		}
 
@@ -417,14 +763,13 @@ func handleModelTraining(enhancedAI *ai.EnhancedAIService, logger *observability
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		err := enhancedAI.TrainModel(r.Context(), req.ModelID, req.Data)
 		if err != nil {
-			logger.Error(r.Context(), "Model training failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Model training failed", err)
 			return
 		}
 
@@ -445,14 +790,13 @@ func handleModelFeedback(enhancedAI *ai.EnhancedAIService, logger *observability
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		err := enhancedAI.ProvideFeedback(r.Context(), req.ModelID, &req.Feedback)
 		if err != nil {
-			logger.Error(r.Context(), "Model feedback failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Model feedback failed", err)
 			return
 		}
 
@@ -465,17 +809,145 @@ func handleModelFeedback(enhancedAI *ai.EnhancedAIService, logger *observability
 	}
 }
 
+func handleActivateModelVersion(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, err := strconv.Atoi(r.PathValue("version"))
+		if err != nil || version <= 0 {
+			httputil.Error(w, r, "Invalid model version", http.StatusBadRequest)
+			return
+		}
+
+		activated, err := enhancedAI.ActivateModelVersion(r.Context(), r.PathValue("id"), version)
+		if err != nil {
+			writeModelVersionError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(activated)
+	}
+}
+
+func handleRollbackModel(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		activated, err := enhancedAI.RollbackModel(r.Context(), r.PathValue("id"))
+		if err != nil {
+			writeModelVersionError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(activated)
+	}
+}
+
+// handleSetDriftConfig updates the drift detection settings of a model; omitted fields keep
+// their current value
+func handleSetDriftConfig(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		modelID := r.PathValue("id")
+		config, err := enhancedAI.DriftConfig(modelID)
+		if err != nil {
+			writeModelVersionError(w, r, logger, err)
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		err = enhancedAI.SetDriftConfig(modelID, config)
+		switch {
+		case err == nil:
+		case errors.Is(err, ai.ErrInvalidDriftConfig):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			writeModelVersionError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model_id": modelID,
+			"drift":    config,
+		})
+	}
+}
+
+// handleModelEvaluation returns the accuracy of the predictions a model served within range,
+// 30 days by default
+func handleModelEvaluation(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		period := 30 * 24 * time.Hour
+		if value := r.URL.Query().Get("range"); value != "" {
+			var err error
+			if period, err = analytics.ParseHistoryDuration(value); err != nil {
+				httputil.Error(w, r, "Invalid range", http.StatusBadRequest)
+				return
+			}
+		}
+
+		evaluation, err := enhancedAI.GetModelEvaluation(r.Context(), r.PathValue("id"), period)
+		if err != nil {
+			switch {
+			case errors.Is(err, ml.ErrModelNotFound):
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			case errors.Is(err, ai.ErrInvalidEvaluationRange):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, ai.ErrEvaluationUnavailable):
+				httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+			default:
+				writeInternalError(w, r, logger, "Model evaluation failed", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(evaluation)
+	}
+}
+
+// writeInternalError reports AI provider failures as upstream errors and logs anything else,
+// responding 500 with message so provider and database details stay out of responses
+func writeInternalError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, message string, err error) {
+	switch {
+	case errors.Is(err, ai.ErrAllProvidersFailed):
+		logger.Error(r.Context(), message, err)
+		httputil.WriteError(w, r, http.StatusBadGateway, httputil.CodeAIUpstreamFailure, "The AI provider failed to respond, please retry")
+	case errors.Is(err, ai.ErrNoCompletionProvider):
+		httputil.Error(w, r, "No AI provider is configured for this request", http.StatusServiceUnavailable)
+	case errors.Is(err, ai.ErrUsageBudgetExceeded):
+		httputil.Error(w, r, err.Error(), http.StatusPaymentRequired)
+	default:
+		httputil.InternalError(w, r, logger, message, err)
+	}
+}
+
+// writeModelVersionError maps model versioning errors to HTTP status codes
+func writeModelVersionError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, err error) {
+	switch {
+	case errors.Is(err, ml.ErrModelNotFound), errors.Is(err, ml.ErrVersionNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ml.ErrModelNotVersioned), errors.Is(err, ml.ErrNoPreviousVersion):
+		httputil.Error(w, r, err.Error(), http.StatusConflict)
+	default:
+		writeInternalError(w, r, logger, "Model version switch failed", err)
+	}
+}
+
 func handlePredictiveAnalytics(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var predictiveReq ai.PredictiveRequest
 		if err := json.NewDecoder(r.Body).Decode(&predictiveReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -483,8 +955,7 @@ func handlePredictiveAnalytics(enhancedAI *ai.EnhancedAIService, logger *observa
 
 		response, err := enhancedAI.GeneratePredictiveAnalytics(r.Context(), &predictiveReq)
 		if err != nil {
-			logger.Error(r.Context(), "Predictive analytics failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Predictive analytics failed", err)
 			return
 		}
 
@@ -499,13 +970,13 @@ func handleUserBehaviorLearning(enhancedAI *ai.EnhancedAIService, logger *observ
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var behaviorData ai.UserBehaviorData
 		if err := json.NewDecoder(r.Body).Decode(&behaviorData); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -513,8 +984,7 @@ func handleUserBehaviorLearning(enhancedAI *ai.EnhancedAIService, logger *observ
 
 		err := enhancedAI.LearnFromUserBehavior(r.Context(), userID, &behaviorData)
 		if err != nil {
-			logger.Error(r.Context(), "User behavior learning failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "User behavior learning failed", err)
 			return
 		}
 
@@ -531,14 +1001,14 @@ func handleGetUserProfile(enhancedAI *ai.EnhancedAIService, logger *observabilit
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		profile, err := enhancedAI.GetUserProfile(userID)
 		if err != nil {
 			logger.Error(r.Context(), "Failed to get user profile", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
 			return
 		}
 
@@ -577,13 +1047,13 @@ func handleRequestAdaptation(enhancedAI *ai.EnhancedAIService, logger *observabi
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var adaptationReq ai.AdaptationRequest
 		if err := json.NewDecoder(r.Body).Decode(&adaptationReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -592,8 +1062,7 @@ func handleRequestAdaptation(enhancedAI *ai.EnhancedAIService, logger *observabi
 
 		err := enhancedAI.RequestModelAdaptation(&adaptationReq)
 		if err != nil {
-			logger.Error(r.Context(), "Model adaptation request failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Model adaptation request failed", err)
 			return
 		}
 
@@ -624,14 +1093,14 @@ func handleGetAdaptationHistory(enhancedAI *ai.EnhancedAIService, logger *observ
 	return func(w http.ResponseWriter, r *http.Request) {
 		modelID := r.PathValue("modelId")
 		if modelID == "" {
-			http.Error(w, "Model ID is required", http.StatusBadRequest)
+			httputil.Error(w, r, "Model ID is required", http.StatusBadRequest)
 			return
 		}
 
 		history, err := enhancedAI.GetAdaptationHistory(modelID)
 		if err != nil {
 			logger.Error(r.Context(), "Failed to get adaptation history", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
 			return
 		}
 
@@ -649,13 +1118,13 @@ func handleAdvancedNLP(enhancedAI *ai.EnhancedAIService, logger *observability.L
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := r.Context().Value("user_id").(uuid.UUID)
 		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID not found", http.StatusUnauthorized)
 			return
 		}
 
 		var nlpReq ai.NLPRequest
 		if err := json.NewDecoder(r.Body).Decode(&nlpReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -671,7 +1140,7 @@ func handleAdvancedNLP(enhancedAI *ai.EnhancedAIService, logger *observability.L
 				"user_id":    userID,
 				"request_id": nlpReq.RequestID,
 			})
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httputil.Error(w, r, "Advanced NLP processing failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -709,15 +1178,15 @@ func handleAdvancedNLP(enhancedAI *ai.EnhancedAIService, logger *observability.L
 
 func handleDecisionRequest(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		userID, ok := r.Context().Value("user_id").(uuid.UUID)
-		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
 		var decisionReq ai.DecisionRequest
 		if err := json.NewDecoder(r.Body).Decode(&decisionReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -740,7 +1209,7 @@ func handleDecisionRequest(enhancedAI *ai.EnhancedAIService, logger *observabili
 				"request_id":    decisionReq.RequestID,
 				"decision_type": decisionReq.DecisionType,
 			})
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httputil.Error(w, r, "Decision request processing failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -778,9 +1247,9 @@ func handleDecisionRequest(enhancedAI *ai.EnhancedAIService, logger *observabili
 
 func handleGetActiveDecisions(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		userID, ok := r.Context().Value("user_id").(uuid.UUID)
-		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
@@ -798,9 +1267,9 @@ func handleGetActiveDecisions(enhancedAI *ai.EnhancedAIService, logger *observab
 
 func handleGetDecisionHistory(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		userID, ok := r.Context().Value("user_id").(uuid.UUID)
-		if !ok {
-			http.Error(w, "User ID not found", http.StatusUnauthorized)
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
@@ -825,6 +1294,83 @@ func handleGetDecisionHistory(enhancedAI *ai.EnhancedAIService, logger *observab
 	}
 }
 
+func handleApproveDecision(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			Note     string          `json:"note"`
+			Quantity decimal.Decimal `json:"quantity"` // optional, overrides the recommended quantity
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+				return
+			}
+		}
+
+		record, err := enhancedAI.ApproveDecisionWithQuantity(r.Context(), r.PathValue("id"), userID, req.Note, req.Quantity)
+		if err != nil {
+			writeDecisionReviewError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(record)
+	}
+}
+
+func handleRejectDecision(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+				return
+			}
+		}
+
+		record, err := enhancedAI.RejectDecision(r.Context(), r.PathValue("id"), userID, req.Reason)
+		if err != nil {
+			writeDecisionReviewError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(record)
+	}
+}
+
+// writeDecisionReviewError maps decision approval errors to HTTP statuses
+func writeDecisionReviewError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, err error) {
+	switch {
+	case errors.Is(err, ai.ErrDecisionNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ai.ErrDecisionExpired):
+		httputil.Error(w, r, err.Error(), http.StatusGone)
+	case errors.Is(err, ai.ErrDecisionAlreadyReviewed), errors.Is(err, ai.ErrDecisionNotApprovable), errors.Is(err, ai.ErrDecisionExecuting):
+		httputil.Error(w, r, err.Error(), http.StatusConflict)
+	case errors.Is(err, ai.ErrInvalidApprovalQuantity):
+		httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ai.ErrDecisionExecutorUnavailable):
+		httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+	default:
+		writeInternalError(w, r, logger, "Decision review failed", err)
+	}
+}
+
 func handleGetDecisionPerformance(enhancedAI *ai.EnhancedAIService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		metrics := enhancedAI.GetDecisionPerformanceMetrics()
@@ -841,28 +1387,35 @@ func handleChat(conversationalAI *ai.ConversationalAI, logger *observability.Log
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req struct {
 			Message string `json:"message"`
+			Stream  bool   `json:"stream"`
+			Debug   bool   `json:"debug"` // include the context assembled for the model
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+		opts := ai.ChatOptions{Debug: req.Debug}
+
+		if req.Stream || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			streamChat(w, r, conversationalAI, userID, req.Message, opts, logger)
 			return
 		}
 
-		response, err := conversationalAI.ProcessMessage(r.Context(), userID, req.Message)
+		response, err := conversationalAI.ProcessMessageWithOptions(r.Context(), userID, req.Message, opts)
 		if err != nil {
-			logger.Error(r.Context(), "Chat request failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Chat request failed", err)
 			return
 		}
 
@@ -871,33 +1424,87 @@ func handleChat(conversationalAI *ai.ConversationalAI, logger *observability.Log
 	}
 }
 
+// streamChat writes a conversational response as Server-Sent Events. The request
+// context is handed to the AI so a client disconnect cancels generation.
+func streamChat(w http.ResponseWriter, r *http.Request, conversationalAI *ai.ConversationalAI, userID uuid.UUID, message string, opts ai.ChatOptions, logger *observability.Logger) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httputil.Error(w, r, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	chunks, err := conversationalAI.ProcessMessageStreamWithOptions(r.Context(), userID, message, opts)
+	if err != nil {
+		writeInternalError(w, r, logger, "Chat stream request failed", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	finished := false
+	for chunk := range chunks {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			logger.Error(r.Context(), "Failed to encode chat stream chunk", err)
+			continue
+		}
+
+		if chunk.Error != "" {
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+			finished = true
+		} else {
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			finished = finished || chunk.Done
+		}
+		flusher.Flush()
+	}
+
+	// The stream ended without a final event; unless the client went away, tell it so
+	if !finished && r.Context().Err() == nil {
+		fmt.Fprintf(w, "event: error\ndata: {\"done\":false,\"error\":\"stream ended unexpectedly\"}\n\n")
+		flusher.Flush()
+	}
+}
+
 func handleVoiceCommandSimple(voiceInterface *ai.VoiceInterface, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req struct {
-			Text      string `json:"text"`
-			AudioData []byte `json:"audio_data,omitempty"`
+			Text           string `json:"text"`
+			AudioData      []byte `json:"audio_data,omitempty"`
+			ResponseFormat string `json:"response_format,omitempty"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		format, err := ai.ParseResponseFormat(req.ResponseFormat)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		response, err := voiceInterface.ProcessVoiceCommand(r.Context(), userID, req.AudioData, req.Text)
+		response, err := voiceInterface.ProcessVoiceCommandWithOptions(r.Context(), userID, req.AudioData, req.Text, ai.VoiceCommandOptions{
+			ResponseFormat: format,
+		})
 		if err != nil {
-			logger.Error(r.Context(), "Voice command processing failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Voice command processing failed", err)
 			return
 		}
 
@@ -910,20 +1517,19 @@ func handleStartConversationSimple(conversationalAI *ai.ConversationalAI, logger
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		conversation, err := conversationalAI.StartConversation(r.Context(), userID)
 		if err != nil {
-			logger.Error(r.Context(), "Conversation start failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Conversation start failed", err)
 			return
 		}
 
@@ -933,71 +1539,199 @@ func handleStartConversationSimple(conversationalAI *ai.ConversationalAI, logger
 	}
 }
 
-// Health check handlers (simplified)
-
-func handleAIHealth(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+func handleListConversations(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status":    "healthy",
-			"service":   "ai-agent",
-			"timestamp": time.Now(),
-		})
-	}
-}
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
 
-func handleProviderHealth(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		provider := r.PathValue("provider")
-		if provider == "" {
-			http.Error(w, "Provider name is required", http.StatusBadRequest)
+		limit, offset := parsePageParams(r)
+		list, err := conversationalAI.ListConversations(r.Context(), userID, limit, offset)
+		if err != nil {
+			writeInternalError(w, r, logger, "Conversation listing failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"provider":  provider,
-			"status":    "healthy",
-			"timestamp": time.Now(),
-		})
+		json.NewEncoder(w).Encode(list)
 	}
 }
 
-func handleProviderHealthCheck(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+func handleListConversationMessages(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		provider := r.PathValue("provider")
-		if provider == "" {
-			http.Error(w, "Provider name is required", http.StatusBadRequest)
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":   "Health check completed",
-			"provider":  provider,
-			"status":    "healthy",
-			"timestamp": time.Now(),
-		})
-	}
+		conversationID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid conversation ID", http.StatusBadRequest)
+			return
+		}
+
+		limit, offset := parsePageParams(r)
+		list, err := conversationalAI.ListMessages(r.Context(), userID, conversationID, limit, offset)
+		if err != nil {
+			if errors.Is(err, ai.ErrConversationNotFound) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+				return
+			}
+			writeInternalError(w, r, logger, "Conversation message listing failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}
+}
+
+// parsePageParams reads ?limit and ?offset, leaving zero values for the service to default
+func parsePageParams(r *http.Request) (int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	return limit, offset
+}
+
+// handleCacheInvalidate deletes the cached responses of the paths matching the pattern
+func handleCacheInvalidate(cacheMiddleware *middleware.CacheMiddleware, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Pattern string `json:"pattern"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+		if strings.TrimSpace(req.Pattern) == "" {
+			httputil.Error(w, r, "Pattern is required", http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := cacheMiddleware.Invalidate(r.Context(), req.Pattern)
+		if err != nil {
+			logger.Error(r.Context(), "Cache invalidation failed", err, map[string]interface{}{
+				"pattern": req.Pattern,
+			})
+			httputil.Error(w, r, "Cache invalidation failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"pattern": req.Pattern,
+			"deleted": deleted,
+		})
+	}
+}
+
+// Health check handlers (simplified)
+
+func handleAIHealth(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry := conversationalAI.Providers()
+		if registry == nil {
+			httputil.Error(w, r, "No AI providers configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		response := registry.Status(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if response.Status == "unhealthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+func handleProviderHealth(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry, provider, ok := resolveProvider(w, r, conversationalAI)
+		if !ok {
+			return
+		}
+
+		status, err := registry.Health(r.Context(), provider)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+func handleProviderHealthCheck(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry, provider, ok := resolveProvider(w, r, conversationalAI)
+		if !ok {
+			return
+		}
+
+		status, err := registry.Check(r.Context(), provider)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if !status.Healthy {
+			logger.Warn(r.Context(), "AI provider health check failed", map[string]interface{}{
+				"provider": provider,
+				"error":    status.Error,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	}
 }
 
 func handleProviderModels(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		provider := r.PathValue("provider")
-		if provider == "" {
-			http.Error(w, "Provider name is required", http.StatusBadRequest)
+		registry, provider, ok := resolveProvider(w, r, conversationalAI)
+		if !ok {
+			return
+		}
+
+		models, err := registry.ListModels(r.Context(), provider)
+		if err != nil {
+			logger.Error(r.Context(), "AI provider model listing failed", err, map[string]interface{}{
+				"provider": provider,
+			})
+			httputil.WriteError(w, r, http.StatusBadGateway, httputil.CodeAIUpstreamFailure, "Failed to list the provider's models")
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"provider": provider,
-			"models":   []string{"gpt-3.5-turbo", "gpt-4"},
-			"count":    2,
+			"models":   models,
+			"count":    len(models),
 		})
 	}
 }
 
+// resolveProvider validates the {provider} path value against the registry, writing the error response itself
+func resolveProvider(w http.ResponseWriter, r *http.Request, conversationalAI *ai.ConversationalAI) (*ai.ProviderRegistry, string, bool) {
+	provider := r.PathValue("provider")
+	if provider == "" {
+		httputil.Error(w, r, "Provider name is required", http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	registry := conversationalAI.Providers()
+	if registry == nil || !registry.Has(provider) {
+		httputil.Error(w, r, fmt.Sprintf("Provider %s is not configured", provider), http.StatusNotFound)
+		return nil, "", false
+	}
+
+	return registry, provider, true
+}
+
 // Multi-Modal AI handlers
 
 func handleMultiModalAnalysis(engine *ai.MultiModalEngine, logger *observability.Logger) http.HandlerFunc {
@@ -1006,14 +1740,14 @@ func handleMultiModalAnalysis(engine *ai.MultiModalEngine, logger *observability
 
 		var req ai.MultiModalRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 		req.UserID = userID
@@ -1028,7 +1762,7 @@ func handleMultiModalAnalysis(engine *ai.MultiModalEngine, logger *observability
 			logger.Error(ctx, "Multi-modal analysis failed", err, map[string]interface{}{
 				"request_id": req.RequestID,
 			})
-			http.Error(w, "Analysis failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Analysis failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1050,13 +1784,13 @@ func handleImageAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 		// Parse multipart form
 		err := r.ParseMultipartForm(10 << 20) // 10MB max
 		if err != nil {
-			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Failed to parse form")
 			return
 		}
 
 		file, header, err := r.FormFile("image")
 		if err != nil {
-			http.Error(w, "Image file required", http.StatusBadRequest)
+			httputil.Error(w, r, "Image file required", http.StatusBadRequest)
 			return
 		}
 		defer file.Close()
@@ -1064,13 +1798,13 @@ func handleImageAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
 		// Validate image format
 		if !engine.ValidateImageFormat(header.Filename) {
-			http.Error(w, "Unsupported image format", http.StatusBadRequest)
+			httputil.Error(w, r, "Unsupported image format", http.StatusBadRequest)
 			return
 		}
 
@@ -1081,6 +1815,7 @@ func handleImageAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 			AnalyzeCharts:   r.FormValue("analyze_charts") == "true",
 			DetectObjects:   r.FormValue("detect_objects") == "true",
 			GenerateSummary: r.FormValue("generate_summary") == "true",
+			ExtractTables:   r.FormValue("extract_tables") == "true",
 		}
 
 		result, err := engine.ProcessImageFile(ctx, userID, file, header, options)
@@ -1088,7 +1823,7 @@ func handleImageAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 			logger.Error(ctx, "Image analysis failed", err, map[string]interface{}{
 				"filename": header.Filename,
 			})
-			http.Error(w, "Image analysis failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Image analysis failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1106,55 +1841,30 @@ func handleDocumentAnalysis(engine *ai.MultiModalEngine, logger *observability.L
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		// Parse multipart form
-		err := r.ParseMultipartForm(50 << 20) // 50MB max
-		if err != nil {
-			http.Error(w, "Failed to parse form", http.StatusBadRequest)
-			return
-		}
-
-		file, header, err := r.FormFile("document")
-		if err != nil {
-			http.Error(w, "Document file required", http.StatusBadRequest)
-			return
-		}
-		defer file.Close()
-
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
-		// Read file data and create request
-		data, err := io.ReadAll(file)
-		if err != nil {
-			http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		upload, ok := readMultiModalUpload(w, r, engine, "document", logger)
+		if !ok {
 			return
 		}
-
-		encodedData := base64.StdEncoding.EncodeToString(data)
+		defer upload.cleanup()
 
 		req := &ai.MultiModalRequest{
 			RequestID: uuid.New().String(),
 			UserID:    userID,
 			Type:      "document",
-			Content: []ai.MultiModalContent{
-				{
-					ID:       uuid.New().String(),
-					Type:     "document",
-					Data:     encodedData,
-					MimeType: header.Header.Get("Content-Type"),
-					Filename: header.Filename,
-					Size:     header.Size,
-				},
-			},
+			Content:   []ai.MultiModalContent{upload.content},
 			Options: ai.MultiModalOptions{
 				ExtractText:      true,
-				AnalyzeSentiment: r.FormValue("analyze_sentiment") == "true",
-				ExtractEntities:  r.FormValue("extract_entities") == "true",
-				GenerateSummary:  r.FormValue("generate_summary") == "true",
+				AnalyzeSentiment: upload.fields.Get("analyze_sentiment") == "true",
+				ExtractEntities:  upload.fields.Get("extract_entities") == "true",
+				GenerateSummary:  upload.fields.Get("generate_summary") == "true",
+				ExtractTables:    upload.fields.Get("extract_tables") == "true",
 			},
 			RequestedAt: time.Now(),
 		}
@@ -1162,9 +1872,9 @@ func handleDocumentAnalysis(engine *ai.MultiModalEngine, logger *observability.L
 		result, err := engine.ProcessMultiModalRequest(ctx, req)
 		if err != nil {
 			logger.Error(ctx, "Document analysis failed", err, map[string]interface{}{
-				"filename": header.Filename,
+				"filename": upload.content.Filename,
 			})
-			http.Error(w, "Document analysis failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Document analysis failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1172,64 +1882,133 @@ func handleDocumentAnalysis(engine *ai.MultiModalEngine, logger *observability.L
 		json.NewEncoder(w).Encode(result)
 
 		logger.Info(ctx, "Document analysis completed", map[string]interface{}{
-			"filename":        header.Filename,
+			"filename":        upload.content.Filename,
+			"size":            upload.content.Size,
+			"streamed":        upload.content.Source != nil,
 			"processing_time": result.ProcessingTime.Milliseconds(),
 		})
 	}
 }
 
-func handleAudioAnalysis(engine *ai.MultiModalEngine, logger *observability.Logger) http.HandlerFunc {
+// handleDocumentSearch returns the caller's document chunks most relevant to a natural-language
+// query, optionally within one document
+func handleDocumentSearch(documentIndex *ai.DocumentIndex, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
 
-		// Parse multipart form
-		err := r.ParseMultipartForm(50 << 20) // 50MB max
+		var req struct {
+			Query      string     `json:"query"`
+			Limit      int        `json:"limit"`
+			DocumentID *uuid.UUID `json:"document_id,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		results, err := documentIndex.Search(r.Context(), userID, req.Query, req.DocumentID, req.Limit)
 		if err != nil {
-			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			if errors.Is(err, ai.ErrInvalidDocumentSearch) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			} else {
+				writeInternalError(w, r, logger, "Document search failed", err)
+			}
 			return
 		}
 
-		file, header, err := r.FormFile("audio")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"query":   req.Query,
+			"results": results,
+			"count":   len(results),
+		})
+	}
+}
+
+// handleListDocuments returns the caller's searchable documents, newest first
+func handleListDocuments(documentIndex *ai.DocumentIndex, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
 		if err != nil {
-			http.Error(w, "Audio file required", http.StatusBadRequest)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
-		defer file.Close()
 
-		// Get user ID from context
-		userID, err := getUserIDFromContext(ctx)
+		documents, err := documentIndex.ListDocuments(r.Context(), userID)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			writeInternalError(w, r, logger, "Failed to list documents", err)
 			return
 		}
 
-		// Read file data and create request
-		data, err := io.ReadAll(file)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"documents": documents,
+			"count":     len(documents),
+		})
+	}
+}
+
+// handleDeleteDocument deletes one of the caller's documents along with its vectors
+func handleDeleteDocument(documentIndex *ai.DocumentIndex, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
+
+		documentID, err := uuid.Parse(r.PathValue("id"))
 		if err != nil {
-			http.Error(w, "Failed to read file", http.StatusInternalServerError)
+			httputil.Error(w, r, "Invalid document ID", http.StatusBadRequest)
 			return
 		}
 
-		encodedData := base64.StdEncoding.EncodeToString(data)
+		if err := documentIndex.DeleteDocument(r.Context(), userID, documentID); err != nil {
+			if errors.Is(err, ai.ErrDocumentNotFound) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			} else {
+				logger.Error(r.Context(), "Failed to delete document", err, map[string]interface{}{
+					"document_id": documentID.String(),
+				})
+				httputil.Error(w, r, "Failed to delete document", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func handleAudioAnalysis(engine *ai.MultiModalEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Get user ID from context
+		userID, err := getUserIDFromContext(ctx)
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
+
+		upload, ok := readMultiModalUpload(w, r, engine, "audio", logger)
+		if !ok {
+			return
+		}
+		defer upload.cleanup()
 
 		req := &ai.MultiModalRequest{
 			RequestID: uuid.New().String(),
 			UserID:    userID,
 			Type:      "audio",
-			Content: []ai.MultiModalContent{
-				{
-					ID:       uuid.New().String(),
-					Type:     "audio",
-					Data:     encodedData,
-					MimeType: header.Header.Get("Content-Type"),
-					Filename: header.Filename,
-					Size:     header.Size,
-				},
-			},
+			Content:   []ai.MultiModalContent{upload.content},
 			Options: ai.MultiModalOptions{
 				ProcessAudio:     true,
-				AnalyzeSentiment: r.FormValue("analyze_sentiment") == "true",
-				ExtractEntities:  r.FormValue("extract_entities") == "true",
+				AnalyzeSentiment: upload.fields.Get("analyze_sentiment") == "true",
+				ExtractEntities:  upload.fields.Get("extract_entities") == "true",
 			},
 			RequestedAt: time.Now(),
 		}
@@ -1237,9 +2016,9 @@ func handleAudioAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 		result, err := engine.ProcessMultiModalRequest(ctx, req)
 		if err != nil {
 			logger.Error(ctx, "Audio analysis failed", err, map[string]interface{}{
-				"filename": header.Filename,
+				"filename": upload.content.Filename,
 			})
-			http.Error(w, "Audio analysis failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Audio analysis failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1247,12 +2026,119 @@ func handleAudioAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 		json.NewEncoder(w).Encode(result)
 
 		logger.Info(ctx, "Audio analysis completed", map[string]interface{}{
-			"filename":        header.Filename,
+			"filename":        upload.content.Filename,
+			"size":            upload.content.Size,
+			"streamed":        upload.content.Source != nil,
 			"processing_time": result.ProcessingTime.Milliseconds(),
 		})
 	}
 }
 
+// multiModalUpload is an uploaded file ready for analysis plus the other form fields
+type multiModalUpload struct {
+	content ai.MultiModalContent
+	fields  url.Values
+	cleanup func()
+}
+
+// readMultiModalUpload reads the file in field from a multipart upload. Uploads up to the
+// engine's streaming threshold are base64-encoded into the content as before; larger ones are
+// streamed part by part to a staging file so they are never held in memory. It writes the
+// error response itself and reports whether the upload can be processed.
+func readMultiModalUpload(w http.ResponseWriter, r *http.Request, engine *ai.MultiModalEngine, field string, logger *observability.Logger) (*multiModalUpload, bool) {
+	maxSize := engine.MaxContentSize(field)
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20) // allow for multipart framing and fields
+
+	upload := &multiModalUpload{
+		content: ai.MultiModalContent{ID: uuid.New().String(), Type: field},
+		fields:  url.Values{},
+		cleanup: func() {},
+	}
+
+	if !engine.ShouldStream(r.ContentLength) {
+		if err := r.ParseMultipartForm(maxSize); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Failed to parse form")
+			return nil, false
+		}
+		file, header, err := r.FormFile(field)
+		if err != nil {
+			httputil.Error(w, r, strings.ToUpper(field[:1])+field[1:]+" file required", http.StatusBadRequest)
+			return nil, false
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			httputil.Error(w, r, "Failed to read file", http.StatusInternalServerError)
+			return nil, false
+		}
+		upload.content.Data = base64.StdEncoding.EncodeToString(data)
+		upload.content.MimeType = header.Header.Get("Content-Type")
+		upload.content.Filename = header.Filename
+		upload.content.Size = header.Size
+		upload.fields = r.MultipartForm.Value
+		return upload, true
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Failed to parse form")
+		return nil, false
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			upload.cleanup()
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Failed to parse form")
+			return nil, false
+		}
+
+		if part.FormName() != field || part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, 64<<10))
+			if err == nil {
+				upload.fields.Add(part.FormName(), string(value))
+			}
+			part.Close()
+			continue
+		}
+		if upload.content.Source != nil {
+			part.Close()
+			continue
+		}
+
+		staged, err := engine.StageContent(r.Context(), part, maxSize)
+		part.Close()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.Is(err, ai.ErrContentTooLarge) || errors.As(err, &maxBytesErr) {
+				httputil.Error(w, r, "File too large", http.StatusRequestEntityTooLarge)
+				return nil, false
+			}
+			logger.Error(r.Context(), "Failed to stage upload", err, map[string]interface{}{
+				"filename": part.FileName(),
+			})
+			httputil.Error(w, r, "Failed to read file", http.StatusInternalServerError)
+			return nil, false
+		}
+
+		upload.content.Source = staged
+		upload.content.MimeType = part.Header.Get("Content-Type")
+		upload.content.Filename = part.FileName()
+		upload.content.Size = staged.Size
+		upload.content.Metadata = map[string]interface{}{"sha256": staged.SHA256}
+		upload.cleanup = func() { staged.Remove() }
+	}
+
+	if upload.content.Source == nil {
+		httputil.Error(w, r, strings.ToUpper(field[:1])+field[1:]+" file required", http.StatusBadRequest)
+		return nil, false
+	}
+	return upload, true
+}
+
 func handleChartAnalysis(engine *ai.MultiModalEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -1260,13 +2146,13 @@ func handleChartAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 		// Parse multipart form
 		err := r.ParseMultipartForm(10 << 20) // 10MB max
 		if err != nil {
-			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Failed to parse form")
 			return
 		}
 
 		file, header, err := r.FormFile("chart")
 		if err != nil {
-			http.Error(w, "Chart image required", http.StatusBadRequest)
+			httputil.Error(w, r, "Chart image required", http.StatusBadRequest)
 			return
 		}
 		defer file.Close()
@@ -1274,13 +2160,13 @@ func handleChartAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
 		// Validate image format
 		if !engine.ValidateImageFormat(header.Filename) {
-			http.Error(w, "Unsupported image format", http.StatusBadRequest)
+			httputil.Error(w, r, "Unsupported image format", http.StatusBadRequest)
 			return
 		}
 
@@ -1297,7 +2183,7 @@ func handleChartAnalysis(engine *ai.MultiModalEngine, logger *observability.Logg
 			logger.Error(ctx, "Chart analysis failed", err, map[string]interface{}{
 				"filename": header.Filename,
 			})
-			http.Error(w, "Chart analysis failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Chart analysis failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1353,14 +2239,14 @@ func handleLearnFromBehavior(engine *ai.UserBehaviorLearningEngine, logger *obse
 
 		var event ai.BehaviorEvent
 		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 		event.UserID = userID
@@ -1381,7 +2267,7 @@ func handleLearnFromBehavior(engine *ai.UserBehaviorLearningEngine, logger *obse
 				"user_id":    userID,
 				"event_type": event.Type,
 			})
-			http.Error(w, "Learning failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Learning failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1409,7 +2295,7 @@ func handleGetUserBehaviorProfile(engine *ai.UserBehaviorLearningEngine, logger
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
@@ -1418,7 +2304,7 @@ func handleGetUserBehaviorProfile(engine *ai.UserBehaviorLearningEngine, logger
 			logger.Error(ctx, "Failed to get user profile", err, map[string]interface{}{
 				"user_id": userID,
 			})
-			http.Error(w, "Profile not found", http.StatusNotFound)
+			httputil.Error(w, r, "Profile not found", http.StatusNotFound)
 			return
 		}
 
@@ -1433,6 +2319,145 @@ func handleGetUserBehaviorProfile(engine *ai.UserBehaviorLearningEngine, logger
 	}
 }
 
+func handleExportUserBehaviorProfile(engine *ai.UserBehaviorLearningEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Get user ID from context
+		userID, err := getUserIDFromContext(ctx)
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
+
+		export, err := engine.ExportUserProfile(ctx, userID)
+		switch {
+		case errors.Is(err, ai.ErrBehaviorProfileNotFound):
+			httputil.Error(w, r, "Profile not found", http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(ctx, "Failed to export user profile", err, map[string]interface{}{
+				"user_id": userID,
+			})
+			httputil.Error(w, r, "Failed to export user profile", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="behavior-profile.json"`)
+		json.NewEncoder(w).Encode(export)
+	}
+}
+
+func handleImportUserBehaviorProfile(engine *ai.UserBehaviorLearningEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Get user ID from context
+		userID, err := getUserIDFromContext(ctx)
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
+
+		var req ai.BehaviorProfileImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		result, err := engine.ImportUserProfile(ctx, userID, req)
+		switch {
+		case errors.Is(err, ai.ErrInvalidBehaviorImport):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			logger.Error(ctx, "Failed to import user profile", err, map[string]interface{}{
+				"user_id": userID,
+			})
+			httputil.Error(w, r, "Failed to import user profile", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func handleResetUserBehaviorProfile(engine *ai.UserBehaviorLearningEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Get user ID from context
+		userID, err := getUserIDFromContext(ctx)
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
+
+		reset, err := engine.ResetUserProfile(ctx, userID)
+		if err != nil {
+			logger.Error(ctx, "Failed to reset user profile", err, map[string]interface{}{
+				"user_id": userID,
+			})
+			httputil.Error(w, r, "Failed to reset user profile", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reset)
+	}
+}
+
+func handleBehaviorOnboarding(engine *ai.UserBehaviorLearningEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Get user ID from context
+		userID, err := getUserIDFromContext(ctx)
+		if err != nil {
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
+			return
+		}
+
+		var answers ai.OnboardingAnswers
+		if err := json.NewDecoder(r.Body).Decode(&answers); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		cohort, err := engine.SetOnboardingAnswers(ctx, userID, answers)
+		switch {
+		case errors.Is(err, ai.ErrInvalidOnboarding):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			logger.Error(ctx, "Failed to assign behavior cohort", err, map[string]interface{}{
+				"user_id": userID,
+			})
+			httputil.Error(w, r, "Failed to assign behavior cohort", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"cohort": cohort,
+		})
+	}
+}
+
+func handleGetBehaviorCohorts(engine *ai.UserBehaviorLearningEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cohorts := engine.GetCohorts(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"cohorts": cohorts,
+			"count":   len(cohorts),
+		})
+	}
+}
+
 func handleGetRecommendations(engine *ai.UserBehaviorLearningEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -1440,7 +2465,7 @@ func handleGetRecommendations(engine *ai.UserBehaviorLearningEngine, logger *obs
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
@@ -1458,7 +2483,7 @@ func handleGetRecommendations(engine *ai.UserBehaviorLearningEngine, logger *obs
 			logger.Error(ctx, "Failed to get recommendations", err, map[string]interface{}{
 				"user_id": userID,
 			})
-			http.Error(w, "Failed to get recommendations", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to get recommendations", http.StatusInternalServerError)
 			return
 		}
 
@@ -1486,7 +2511,7 @@ func handleGetBehaviorHistory(engine *ai.UserBehaviorLearningEngine, logger *obs
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
@@ -1504,7 +2529,7 @@ func handleGetBehaviorHistory(engine *ai.UserBehaviorLearningEngine, logger *obs
 			logger.Error(ctx, "Failed to get behavior history", err, map[string]interface{}{
 				"user_id": userID,
 			})
-			http.Error(w, "Failed to get behavior history", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to get behavior history", http.StatusInternalServerError)
 			return
 		}
 
@@ -1532,14 +2557,14 @@ func handleUpdateRecommendationStatus(engine *ai.UserBehaviorLearningEngine, log
 		// Get user ID from context
 		userID, err := getUserIDFromContext(ctx)
 		if err != nil {
-			http.Error(w, "User ID required", http.StatusUnauthorized)
+			httputil.Error(w, r, "User ID required", http.StatusUnauthorized)
 			return
 		}
 
 		// Get recommendation ID from path
 		recommendationID := r.PathValue("id")
 		if recommendationID == "" {
-			http.Error(w, "Recommendation ID required", http.StatusBadRequest)
+			httputil.Error(w, r, "Recommendation ID required", http.StatusBadRequest)
 			return
 		}
 
@@ -1548,7 +2573,7 @@ func handleUpdateRecommendationStatus(engine *ai.UserBehaviorLearningEngine, log
 			Status string `json:"status"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -1560,7 +2585,7 @@ func handleUpdateRecommendationStatus(engine *ai.UserBehaviorLearningEngine, log
 			"expired":  true,
 		}
 		if !validStatuses[req.Status] {
-			http.Error(w, "Invalid status", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid status", http.StatusBadRequest)
 			return
 		}
 
@@ -1571,7 +2596,7 @@ func handleUpdateRecommendationStatus(engine *ai.UserBehaviorLearningEngine, log
 				"recommendation_id": recommendationID,
 				"status":            req.Status,
 			})
-			http.Error(w, "Failed to update recommendation status", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to update recommendation status", http.StatusInternalServerError)
 			return
 		}
 
@@ -1622,7 +2647,7 @@ func handleDetectMarketPatterns(engine *ai.MarketAdaptationEngine, logger *obser
 
 		var marketData map[string]interface{}
 		if err := json.NewDecoder(r.Body).Decode(&marketData); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -1631,7 +2656,7 @@ func handleDetectMarketPatterns(engine *ai.MarketAdaptationEngine, logger *obser
 			logger.Error(ctx, "Failed to detect market patterns", err, map[string]interface{}{
 				"data_points": len(marketData),
 			})
-			http.Error(w, "Pattern detection failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Pattern detection failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1673,7 +2698,7 @@ func handleGetMarketPatternsAdaptation(engine *ai.MarketAdaptationEngine, logger
 			logger.Error(ctx, "Failed to get market patterns", err, map[string]interface{}{
 				"filters": filters,
 			})
-			http.Error(w, "Failed to get market patterns", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to get market patterns", http.StatusInternalServerError)
 			return
 		}
 
@@ -1703,7 +2728,7 @@ func handleAdaptStrategies(engine *ai.MarketAdaptationEngine, logger *observabil
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -1712,7 +2737,7 @@ func handleAdaptStrategies(engine *ai.MarketAdaptationEngine, logger *observabil
 			logger.Error(ctx, "Failed to adapt strategies", err, map[string]interface{}{
 				"patterns_count": len(request.Patterns),
 			})
-			http.Error(w, "Strategy adaptation failed", http.StatusInternalServerError)
+			httputil.Error(w, r, "Strategy adaptation failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1738,7 +2763,7 @@ func handleGetAdaptiveStrategies(engine *ai.MarketAdaptationEngine, logger *obse
 		strategies, err := engine.GetAdaptiveStrategies(ctx)
 		if err != nil {
 			logger.Error(ctx, "Failed to get adaptive strategies", err, nil)
-			http.Error(w, "Failed to get adaptive strategies", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to get adaptive strategies", http.StatusInternalServerError)
 			return
 		}
 
@@ -1763,17 +2788,21 @@ func handleAddAdaptiveStrategy(engine *ai.MarketAdaptationEngine, logger *observ
 
 		var strategy ai.AdaptiveStrategy
 		if err := json.NewDecoder(r.Body).Decode(&strategy); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		err := engine.AddAdaptiveStrategy(ctx, &strategy)
+		if errors.Is(err, web3.ErrInvalidTradingSchedule) {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			logger.Error(ctx, "Failed to add adaptive strategy", err, map[string]interface{}{
 				"strategy_name": strategy.Name,
 				"strategy_type": strategy.Type,
 			})
-			http.Error(w, "Failed to add adaptive strategy", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to add adaptive strategy", http.StatusInternalServerError)
 			return
 		}
 
@@ -1801,7 +2830,7 @@ func handleUpdateStrategyStatus(engine *ai.MarketAdaptationEngine, logger *obser
 		// Get strategy ID from path
 		strategyID := r.PathValue("id")
 		if strategyID == "" {
-			http.Error(w, "Strategy ID required", http.StatusBadRequest)
+			httputil.Error(w, r, "Strategy ID required", http.StatusBadRequest)
 			return
 		}
 
@@ -1810,7 +2839,7 @@ func handleUpdateStrategyStatus(engine *ai.MarketAdaptationEngine, logger *obser
 			IsActive bool `json:"is_active"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
@@ -1820,7 +2849,7 @@ func handleUpdateStrategyStatus(engine *ai.MarketAdaptationEngine, logger *obser
 				"strategy_id": strategyID,
 				"is_active":   req.IsActive,
 			})
-			http.Error(w, "Failed to update strategy status", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to update strategy status", http.StatusInternalServerError)
 			return
 		}
 
@@ -1859,7 +2888,7 @@ func handleGetMarketAdaptationHistory(engine *ai.MarketAdaptationEngine, logger
 			logger.Error(ctx, "Failed to get adaptation history", err, map[string]interface{}{
 				"limit": limit,
 			})
-			http.Error(w, "Failed to get adaptation history", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to get adaptation history", http.StatusInternalServerError)
 			return
 		}
 
@@ -1887,7 +2916,7 @@ func handleGetStrategyPerformanceMetrics(engine *ai.MarketAdaptationEngine, logg
 		// Get strategy ID from path
 		strategyID := r.PathValue("strategy_id")
 		if strategyID == "" {
-			http.Error(w, "Strategy ID required", http.StatusBadRequest)
+			httputil.Error(w, r, "Strategy ID required", http.StatusBadRequest)
 			return
 		}
 
@@ -1896,7 +2925,7 @@ func handleGetStrategyPerformanceMetrics(engine *ai.MarketAdaptationEngine, logg
 			logger.Error(ctx, "Failed to get performance metrics", err, map[string]interface{}{
 				"strategy_id": strategyID,
 			})
-			http.Error(w, "Failed to get performance metrics", http.StatusNotFound)
+			httputil.Error(w, r, "Failed to get performance metrics", http.StatusNotFound)
 			return
 		}
 
@@ -1909,6 +2938,178 @@ func handleGetStrategyPerformanceMetrics(engine *ai.MarketAdaptationEngine, logg
 	}
 }
 
+func handleBacktestStrategy(engine *ai.MarketAdaptationEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		strategyID := r.PathValue("id")
+		if strategyID == "" {
+			httputil.Error(w, r, "Strategy ID required", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			Symbol   string    `json:"symbol"`
+			Interval string    `json:"interval"`
+			Start    time.Time `json:"start"`
+			End      time.Time `json:"end"`
+			ai.StrategyBacktestOptions
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+		if req.Symbol == "" {
+			httputil.Error(w, r, "symbol is required", http.StatusBadRequest)
+			return
+		}
+		if req.Interval == "" {
+			req.Interval = "1h"
+		}
+		if req.End.IsZero() {
+			req.End = time.Now()
+		}
+		if req.Start.IsZero() {
+			req.Start = req.End.Add(-30 * 24 * time.Hour)
+		}
+		if !req.End.After(req.Start) {
+			httputil.Error(w, r, "end must be after start", http.StatusBadRequest)
+			return
+		}
+
+		result, err := engine.BacktestStrategy(ctx, strategyID, req.Symbol, req.Interval, req.Start, req.End, req.StrategyBacktestOptions)
+		if err != nil {
+			logger.Error(ctx, "Failed to backtest strategy", err, map[string]interface{}{
+				"strategy_id": strategyID,
+				"symbol":      req.Symbol,
+			})
+			switch {
+			case errors.Is(err, ai.ErrAdaptiveStrategyNotFound):
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeStrategyNotFound, "Strategy not found")
+			case errors.Is(err, ai.ErrCandleSourceUnavailable):
+				httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+			case errors.Is(err, ai.ErrInsufficientCandles):
+				httputil.Error(w, r, err.Error(), http.StatusUnprocessableEntity)
+			default:
+				httputil.Error(w, r, "Failed to backtest strategy", http.StatusBadGateway)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+
+		logger.Info(ctx, "Strategy backtested", map[string]interface{}{
+			"strategy_id":  strategyID,
+			"symbol":       req.Symbol,
+			"candles":      result.Candles,
+			"total_trades": result.Metrics.TotalTrades,
+		})
+	}
+}
+
+// newRateLimiter creates the per-user rate limiter, with model-backed endpoints in the
+// ai-heavy class
+func newRateLimiter(redis *database.RedisClient, cfg *config.Config, logger *observability.Logger) *middleware.RateLimiter {
+	rateLimiter := middleware.NewRateLimiter(redis, logger, cfg.RateLimit, cfg.JWT.Secret)
+	rateLimiter.SetKeyPrefix("ratelimit:ai-agent:")
+	rateLimiter.SetRouteClass(middleware.RateLimitClassAIHeavy,
+		"POST /ai/analyze",
+		"POST /ai/chat",
+		"POST /ai/predict/",
+		"POST /ai/analytics/predictive",
+		"POST /ai/models/train",
+		"POST /ai/nlp/",
+		"POST /ai/decisions/",
+		"POST /ai/multimodal/",
+		"POST /ai/voice/",
+		"POST /ai/crypto/",
+		"POST /ai/market/strategies/",
+	)
+	return rateLimiter
+}
+
+// exchangeCandleSource serves historical candles for backtests and prediction evaluation from
+// the candle cache, which backfills from an exchange's REST API
+type exchangeCandleSource struct {
+	marketData interface {
+		GetCandles(ctx context.Context, exchange, symbol, interval string, start, end time.Time) ([]realtime.Candle, error)
+	}
+	exchange string
+}
+
+// newCachedCandleSource creates a candle source reading the candle cache, which backfills
+// missing ranges from the exchange
+func newCachedCandleSource(logger *observability.Logger, db *database.DB, exchange string) *exchangeCandleSource {
+	marketData := realtime.NewMarketDataService(logger, realtime.MarketDataConfig{
+		Exchanges: []realtime.ExchangeConfig{{Name: exchange}},
+	})
+	candles := realtime.NewCandleService(logger, marketData, realtime.NewPostgresCandleStore(db))
+	return &exchangeCandleSource{marketData: candles, exchange: exchange}
+}
+
+// GetCandles implements ai.CandleSource
+func (s *exchangeCandleSource) GetCandles(ctx context.Context, symbol, interval string, start, end time.Time) ([]ml.PriceData, error) {
+	candles, err := s.marketData.GetCandles(ctx, s.exchange, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]ml.PriceData, 0, len(candles))
+	for _, candle := range candles {
+		prices = append(prices, ml.PriceData{
+			Symbol:    candle.Symbol,
+			Timestamp: candle.CloseTime,
+			Open:      candle.Open,
+			High:      candle.High,
+			Low:       candle.Low,
+			Close:     candle.Close,
+			Volume:    candle.Volume,
+		})
+	}
+	return prices, nil
+}
+
+// derivativesSummarySource serves perpetual futures positioning from the funding rates and open
+// interest the web3 service collects
+type derivativesSummarySource struct {
+	collector *realtime.DerivativesCollector
+}
+
+// newDerivativesSummarySource creates a derivatives source reading the collected series. The
+// collector is never started here.
+func newDerivativesSummarySource(logger *observability.Logger, db *database.DB, cfg config.Web3Config) *derivativesSummarySource {
+	collector := realtime.NewDerivativesCollector(logger, realtime.NewPostgresDerivativesStore(db), realtime.DerivativesConfig{
+		OIChangeWindow: cfg.DerivativesOIChangeWindow,
+	})
+	return &derivativesSummarySource{collector: collector}
+}
+
+// GetDerivativesSummary implements ai.DerivativesSource. Symbols are read from their USDT
+// perpetual, so BTC and BTCUSDT both read BTCUSDT.
+func (s *derivativesSummarySource) GetDerivativesSummary(ctx context.Context, symbol string) (*ai.DerivativesSummary, error) {
+	symbol = strings.ToUpper(symbol)
+	if !strings.HasSuffix(symbol, "USDT") {
+		symbol += "USDT"
+	}
+
+	summary, err := s.collector.Summary(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	fundingRate, _ := summary.FundingRate.Float64()
+	openInterest, _ := summary.OpenInterest.Float64()
+	return &ai.DerivativesSummary{
+		FundingRate:        fundingRate,
+		OpenInterest:       openInterest,
+		OpenInterestChange: summary.OpenInterestChange,
+		Window:             summary.Window,
+		Exchanges:          summary.Exchanges,
+		UpdatedAt:          summary.UpdatedAt,
+	}, nil
+}
+
 // Crypto Coin Analyzer handlers
 
 func handleCryptoCoinAnalysis(analyzer *ai.CryptoCoinAnalyzer, logger *observability.Logger) http.HandlerFunc {
@@ -1918,14 +3119,14 @@ func handleCryptoCoinAnalysis(analyzer *ai.CryptoCoinAnalyzer, logger *observabi
 		// Get symbol from path
 		symbol := r.PathValue("symbol")
 		if symbol == "" {
-			http.Error(w, "Symbol is required", http.StatusBadRequest)
+			httputil.Error(w, r, "Symbol is required", http.StatusBadRequest)
 			return
 		}
 
 		// Validate symbol format (basic validation)
 		symbol = strings.ToUpper(strings.TrimSpace(symbol))
 		if len(symbol) < 2 || len(symbol) > 10 {
-			http.Error(w, "Invalid symbol format", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid symbol format", http.StatusBadRequest)
 			return
 		}
 
@@ -1940,7 +3141,7 @@ func handleCryptoCoinAnalysis(analyzer *ai.CryptoCoinAnalyzer, logger *observabi
 			logger.Error(ctx, "Crypto coin analysis failed", err, map[string]interface{}{
 				"symbol": symbol,
 			})
-			http.Error(w, fmt.Sprintf("Analysis failed: %v", err), http.StatusInternalServerError)
+			httputil.Error(w, r, "Crypto coin analysis failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -1948,7 +3149,7 @@ func handleCryptoCoinAnalysis(analyzer *ai.CryptoCoinAnalyzer, logger *observabi
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(report); err != nil {
 			logger.Error(ctx, "Failed to encode response", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
 
@@ -1960,6 +3161,27 @@ func handleCryptoCoinAnalysis(analyzer *ai.CryptoCoinAnalyzer, logger *observabi
 	}
 }
 
+func handleCryptoCoinBatchAnalysis(analyzer *ai.CryptoCoinAnalyzer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Symbols []string `json:"symbols"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		result, err := analyzer.AnalyzeCoins(r.Context(), req.Symbols)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
 func handleCryptoCoinReport(analyzer *ai.CryptoCoinAnalyzer, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -1967,14 +3189,14 @@ func handleCryptoCoinReport(analyzer *ai.CryptoCoinAnalyzer, logger *observabili
 		// Get symbol from path
 		symbol := r.PathValue("symbol")
 		if symbol == "" {
-			http.Error(w, "Symbol is required", http.StatusBadRequest)
+			httputil.Error(w, r, "Symbol is required", http.StatusBadRequest)
 			return
 		}
 
 		// Validate symbol format
 		symbol = strings.ToUpper(strings.TrimSpace(symbol))
 		if len(symbol) < 2 || len(symbol) > 10 {
-			http.Error(w, "Invalid symbol format", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid symbol format", http.StatusBadRequest)
 			return
 		}
 
@@ -1989,7 +3211,7 @@ func handleCryptoCoinReport(analyzer *ai.CryptoCoinAnalyzer, logger *observabili
 			logger.Error(ctx, "Crypto coin report generation failed", err, map[string]interface{}{
 				"symbol": symbol,
 			})
-			http.Error(w, fmt.Sprintf("Report generation failed: %v", err), http.StatusInternalServerError)
+			httputil.Error(w, r, "Crypto coin report generation failed", http.StatusInternalServerError)
 			return
 		}
 
@@ -2017,3 +3239,165 @@ func handleCryptoCoinReport(analyzer *ai.CryptoCoinAnalyzer, logger *observabili
 		})
 	}
 }
+
+func handleCreateReportSchedule(scheduler *ai.CryptoReportScheduler, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req ai.CreateReportScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		schedule, err := scheduler.CreateSchedule(r.Context(), userID, req)
+		if err != nil {
+			if errors.Is(err, ai.ErrInvalidReportSchedule) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeInternalError(w, r, logger, "Report schedule creation failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(schedule)
+	}
+}
+
+func handleListReportSchedules(scheduler *ai.CryptoReportScheduler, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		schedules, err := scheduler.ListSchedules(r.Context(), userID)
+		if err != nil {
+			writeInternalError(w, r, logger, "Report schedule listing failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schedules": schedules,
+		})
+	}
+}
+
+func handleDeleteReportSchedule(scheduler *ai.CryptoReportScheduler, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		scheduleID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid schedule ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := scheduler.DeleteSchedule(r.Context(), userID, scheduleID); err != nil {
+			if errors.Is(err, ai.ErrReportScheduleNotFound) {
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeStrategyNotFound, err.Error())
+				return
+			}
+			writeInternalError(w, r, logger, "Report schedule deletion failed", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func handleListCryptoReports(scheduler *ai.CryptoReportScheduler, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		from, err := parseReportDate(r.URL.Query().Get("from"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid from date", http.StatusBadRequest)
+			return
+		}
+		to, err := parseReportDate(r.URL.Query().Get("to"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid to date", http.StatusBadRequest)
+			return
+		}
+		// A bare end date includes the whole day
+		if len(r.URL.Query().Get("to")) == len(time.DateOnly) {
+			to = to.AddDate(0, 0, 1)
+		}
+
+		limit, offset := parsePageParams(r)
+		reports, err := scheduler.ListReports(r.Context(), ai.CryptoReportFilter{
+			UserID: userID,
+			Symbol: r.URL.Query().Get("symbol"),
+			From:   from,
+			To:     to,
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			writeInternalError(w, r, logger, "Report listing failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"reports": reports,
+		})
+	}
+}
+
+func handleGetCryptoReport(scheduler *ai.CryptoReportScheduler, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := getUserIDFromContext(r.Context())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		reportID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid report ID", http.StatusBadRequest)
+			return
+		}
+
+		report, err := scheduler.GetReport(r.Context(), userID, reportID)
+		if err != nil {
+			if errors.Is(err, ai.ErrReportNotFound) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+				return
+			}
+			writeInternalError(w, r, logger, "Report retrieval failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// parseReportDate accepts RFC 3339 timestamps and YYYY-MM-DD dates, returning the zero time for an empty value
+func parseReportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+// circuitProbeTimeout bounds the health check that decides whether an open circuit closes
+const circuitProbeTimeout = 5 * time.Second
+
+// circuitState is the state of a service's circuit breaker
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"    // requests are proxied
+	circuitOpen     circuitState = "open"      // requests fail fast until the cool-down ends
+	circuitHalfOpen circuitState = "half_open" // a health probe decides whether to close
+)
+
+// circuitBreaker stops proxying to a service after consecutive failures, so clients get
+// an immediate 503 instead of waiting for the upstream to time out. After the cool-down
+// the service's health endpoint is probed; the circuit closes once it reports healthy.
+type circuitBreaker struct {
+	service   string
+	healthURL string
+	logger    *observability.Logger
+	threshold int
+	cooldown  time.Duration
+
+	state               circuitState
+	consecutiveFailures int
+	requests            int64 // since the circuit last closed
+	failures            int64 // since the circuit last closed
+	openedAt            time.Time
+	lastError           string
+	lastProbe           map[string]interface{}
+	mu                  sync.Mutex
+}
+
+func newCircuitBreaker(service, healthURL string, cfg config.GatewayConfig, logger *observability.Logger) *circuitBreaker {
+	if cfg.CircuitFailureThreshold <= 0 {
+		cfg.CircuitFailureThreshold = 5
+	}
+	if cfg.CircuitCooldown <= 0 {
+		cfg.CircuitCooldown = 30 * time.Second
+	}
+	return &circuitBreaker{
+		service:   service,
+		healthURL: healthURL,
+		logger:    logger,
+		threshold: cfg.CircuitFailureThreshold,
+		cooldown:  cfg.CircuitCooldown,
+		state:     circuitClosed,
+	}
+}
+
+// Allow reports whether a request may be proxied. The first call after the cool-down of an
+// open circuit starts a health probe; requests keep failing fast until it succeeds.
+func (cb *circuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitClosed:
+		return true
+	case circuitOpen:
+		if time.Since(cb.openedAt) >= cb.cooldown {
+			cb.state = circuitHalfOpen
+			go cb.probe()
+		}
+	}
+	return false
+}
+
+// RecordSuccess records a request the service answered
+func (cb *circuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.requests++
+	cb.consecutiveFailures = 0
+}
+
+// RecordFailure records a request the service could not answer and opens the circuit
+// once the failures reach the threshold
+func (cb *circuitBreaker) RecordFailure(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.requests++
+	cb.failures++
+	cb.consecutiveFailures++
+	cb.lastError = err.Error()
+	if cb.state == circuitClosed && cb.consecutiveFailures >= cb.threshold {
+		cb.openLocked()
+	}
+}
+
+// RetryAfter returns how long until the next health probe of an open circuit
+func (cb *circuitBreaker) RetryAfter() time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if remaining := cb.cooldown - time.Since(cb.openedAt); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Snapshot returns the circuit state for /api/status
+func (cb *circuitBreaker) Snapshot() map[string]interface{} {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failureRate := float64(0)
+	if cb.requests > 0 {
+		failureRate = float64(cb.failures) / float64(cb.requests)
+	}
+	snapshot := map[string]interface{}{
+		"state":                cb.state,
+		"consecutive_failures": cb.consecutiveFailures,
+		"failure_threshold":    cb.threshold,
+		"requests":             cb.requests,
+		"failures":             cb.failures,
+		"failure_rate":         failureRate,
+		"cooldown":             cb.cooldown.String(),
+	}
+	if cb.lastError != "" {
+		snapshot["last_error"] = cb.lastError
+	}
+	if cb.state != circuitClosed {
+		snapshot["opened_at"] = cb.openedAt
+	}
+	if cb.lastProbe != nil {
+		snapshot["last_probe"] = cb.lastProbe
+	}
+	return snapshot
+}
+
+// probe checks the service's health endpoint and closes or reopens the circuit
+func (cb *circuitBreaker) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), circuitProbeTimeout)
+	defer cancel()
+	result := checkServiceHealth(ctx, cb.healthURL)
+	healthy := result["status"] == "healthy"
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.lastProbe = result
+	if !healthy {
+		cb.openLocked()
+		return
+	}
+
+	cb.state = circuitClosed
+	cb.consecutiveFailures = 0
+	cb.requests = 0
+	cb.failures = 0
+	cb.logger.Info(ctx, "Circuit closed", map[string]interface{}{
+		"service": cb.service,
+	})
+}
+
+func (cb *circuitBreaker) openLocked() {
+	reopened := cb.state == circuitHalfOpen
+	cb.state = circuitOpen
+	cb.openedAt = time.Now()
+	cb.logger.Warn(context.Background(), "Circuit opened", map[string]interface{}{
+		"service":              cb.service,
+		"consecutive_failures": cb.consecutiveFailures,
+		"last_error":           cb.lastError,
+		"reopened":             reopened,
+		"cooldown":             cb.cooldown.String(),
+	})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// circuitFixture proxies /svc/ to an upstream whose responses the test controls
+type circuitFixture struct {
+	breaker       *circuitBreaker
+	gateway       *httptest.Server
+	status        atomic.Int32 // status of upstream requests
+	healthy       atomic.Bool  // whether /health answers 200
+	maintenance   atomic.Bool  // whether responses carry the maintenance header
+	upstreamCalls atomic.Int32
+}
+
+func newCircuitFixture(t *testing.T, threshold int, cooldown time.Duration) *circuitFixture {
+	t.Helper()
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	f := &circuitFixture{}
+	f.status.Store(http.StatusOK)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			if !f.healthy.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+			return
+		}
+		f.upstreamCalls.Add(1)
+		if f.maintenance.Load() {
+			w.Header().Set(middleware.MaintenanceHeader, "true")
+		}
+		w.WriteHeader(int(f.status.Load()))
+	}))
+	t.Cleanup(upstream.Close)
+
+	target, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	cfg := config.GatewayConfig{CircuitFailureThreshold: threshold, CircuitCooldown: cooldown}
+	f.breaker = newCircuitBreaker("svc", upstream.URL+"/health", cfg, logger)
+	proxy := createProxyHandler(newServiceProxy(target, "/svc", f.breaker, cfg, logger), "/svc", f.breaker, logger)
+
+	f.gateway = httptest.NewServer(proxy)
+	t.Cleanup(f.gateway.Close)
+	return f
+}
+
+func (f *circuitFixture) get(t *testing.T) *http.Response {
+	t.Helper()
+	resp, err := http.Get(f.gateway.URL + "/svc/data")
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func (f *circuitFixture) state() circuitState {
+	return f.breaker.Snapshot()["state"].(circuitState)
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	f := newCircuitFixture(t, 3, time.Minute)
+	f.status.Store(http.StatusServiceUnavailable)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusServiceUnavailable, f.get(t).StatusCode)
+	}
+	assert.Equal(t, circuitOpen, f.state())
+	assert.Equal(t, int32(3), f.upstreamCalls.Load())
+
+	// While open, requests fail fast without reaching the upstream
+	resp := f.get(t)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, 60, retryAfter, 1)
+	assert.Equal(t, int32(3), f.upstreamCalls.Load())
+}
+
+func TestCircuitBreaker_SuccessResetsConsecutiveFailures(t *testing.T) {
+	f := newCircuitFixture(t, 3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		f.status.Store(http.StatusBadGateway)
+		f.get(t)
+		f.get(t)
+		f.status.Store(http.StatusOK)
+		f.get(t)
+	}
+	assert.Equal(t, circuitClosed, f.state())
+
+	// Client errors and services draining for maintenance are not failures
+	f.status.Store(http.StatusNotFound)
+	for i := 0; i < 3; i++ {
+		f.get(t)
+	}
+	f.status.Store(http.StatusServiceUnavailable)
+	f.maintenance.Store(true)
+	for i := 0; i < 3; i++ {
+		f.get(t)
+	}
+	assert.Equal(t, circuitClosed, f.state())
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	cooldown := 50 * time.Millisecond
+	f := newCircuitFixture(t, 2, cooldown)
+	f.status.Store(http.StatusServiceUnavailable)
+	f.get(t)
+	f.get(t)
+	require.Equal(t, circuitOpen, f.state())
+	openedAt := f.breaker.Snapshot()["opened_at"].(time.Time)
+
+	// After the cool-down the next request starts a probe; an unhealthy one reopens the circuit
+	time.Sleep(cooldown)
+	assert.Equal(t, http.StatusServiceUnavailable, f.get(t).StatusCode)
+	require.Eventually(t, func() bool {
+		snapshot := f.breaker.Snapshot()
+		return snapshot["state"] == circuitOpen && snapshot["opened_at"].(time.Time).After(openedAt)
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "unhealthy", f.breaker.Snapshot()["last_probe"].(map[string]interface{})["status"])
+	assert.Equal(t, int32(2), f.upstreamCalls.Load(), "a probing circuit must not proxy requests")
+
+	// A healthy probe closes it and requests flow again
+	f.healthy.Store(true)
+	f.status.Store(http.StatusOK)
+	time.Sleep(cooldown)
+	assert.Equal(t, http.StatusServiceUnavailable, f.get(t).StatusCode)
+	require.Eventually(t, func() bool { return f.state() == circuitClosed }, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, http.StatusOK, f.get(t).StatusCode)
+	assert.Equal(t, int32(3), f.upstreamCalls.Load())
+	snapshot := f.breaker.Snapshot()
+	assert.Equal(t, 0, snapshot["consecutive_failures"])
+	assert.Equal(t, int64(1), snapshot["requests"])
+}
@@ -5,17 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
 	"github.com/ai-agentic-browser/pkg/database"
+	httperr "github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/gorilla/websocket"
@@ -83,10 +87,42 @@ func main() {
 		}
 	}
 
+	// Market data feed for WebSocket streaming. The feed subscribes upstream to the configured
+	// symbols when it connects, so clients are limited to those.
+	if len(cfg.WebSocket.Symbols) == 0 {
+		cfg.WebSocket.Symbols = defaultStreamSymbols
+	}
+	marketData := realtime.NewMarketDataService(logger, realtime.MarketDataConfig{
+		Exchanges: []realtime.ExchangeConfig{
+			{
+				Name:     "binance",
+				WSUrl:    "wss://stream.binance.com:9443/ws",
+				Symbols:  cfg.WebSocket.Symbols,
+				Channels: []string{"ticker", "trade"},
+				Enabled:  true,
+			},
+		},
+		ReconnectDelay:  5 * time.Second,
+		PingInterval:    30 * time.Second,
+		MaxReconnects:   10,
+		BufferSize:      1000,
+		EnableHeartbeat: true,
+	})
+	if err := marketData.Start(); err != nil {
+		logger.Error(context.Background(), "Failed to start market data service", err)
+	}
+	hub := newWSHub(logger, marketData, cfg.WebSocket)
+
+	// Maintenance mode enabled here is shared with the services through Redis
+	maintenance := middleware.NewMaintenanceMode(redis, logger, cfg.Maintenance)
+	maintenanceCtx, stopMaintenance := context.WithCancel(context.Background())
+	defer stopMaintenance()
+	go maintenance.Run(maintenanceCtx)
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
-		Handler:      setupRoutes(endpoints, cfg, logger, db, redis),
+		Handler:      setupRoutes(endpoints, cfg, logger, db, redis, hub, maintenance),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
@@ -117,18 +153,28 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Hijacked WebSocket connections are not closed by Shutdown
+	hub.Close()
+	marketData.Stop()
+
 	logger.Info(context.Background(), "API Gateway stopped")
 }
 
-func setupRoutes(endpoints ServiceEndpoints, cfg *config.Config, logger *observability.Logger, db *database.DB, redis *database.RedisClient) http.Handler {
+func setupRoutes(endpoints ServiceEndpoints, cfg *config.Config, logger *observability.Logger, db *database.DB, redis *database.RedisClient, hub *wsHub, maintenance *middleware.MaintenanceMode) http.Handler {
 	mux := http.NewServeMux()
 
+	// Per-user rate limits shared across replicas through Redis
+	rateLimiter := middleware.NewRateLimiter(redis, logger, cfg.RateLimit, cfg.JWT.Secret)
+	rateLimiter.SetKeyPrefix("ratelimit:api-gateway:")
+
 	// Apply middleware
 	handler := middleware.Recovery(logger)(
 		middleware.Logging(logger)(
 			middleware.Tracing("api-gateway")(
 				middleware.CORS(cfg.Security.CORSAllowedOrigins)(
-					middleware.RateLimit(cfg.RateLimit)(mux),
+					maintenance.Middleware()(
+						rateLimiter.Middleware()(mux),
+					),
 				),
 			),
 		),
@@ -161,49 +207,121 @@ func setupRoutes(endpoints ServiceEndpoints, cfg *config.Config, logger *observa
 			health["services"].(map[string]string)["redis"] = "healthy"
 		}
 
+		// Reported with 200 so orchestrators don't restart the gateway while it drains
+		if maintenance.Enabled() {
+			health["status"] = middleware.HealthStatusMaintenance
+			health["maintenance"] = maintenance.State()
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(health)
 	})
 
-	// WebSocket endpoint for real-time communication
-	mux.HandleFunc("GET /ws", handleWebSocket(logger))
+	// Maintenance mode for the gateway and every service
+	adminAuthorizer := middleware.NewAdminAuthorizer(cfg.JWT.Secret, middleware.NewTokenRevocationList(redis), cfg.Security.AdminUsers)
+	mux.Handle("GET /admin/maintenance", adminAuthorizer.Middleware()(maintenance.StatusHandler()))
+	mux.Handle("POST /admin/maintenance/enable", adminAuthorizer.Middleware()(maintenance.EnableHandler()))
+	mux.Handle("POST /admin/maintenance/disable", adminAuthorizer.Middleware()(maintenance.DisableHandler()))
+
+	// WebSocket endpoint for real-time market data
+	mux.HandleFunc("GET /ws", handleWebSocket(hub, logger))
 
 	// API documentation endpoint
 	mux.HandleFunc("GET /api/docs", handleAPIDocs())
 
+	// One circuit breaker per upstream, probed through the service's health endpoint
+	breakers := map[string]*circuitBreaker{
+		"auth":    newCircuitBreaker("auth", endpoints.AuthService+"/health", cfg.Gateway, logger),
+		"ai":      newCircuitBreaker("ai", endpoints.AIAgent+"/health", cfg.Gateway, logger),
+		"browser": newCircuitBreaker("browser", endpoints.BrowserService+"/health", cfg.Gateway, logger),
+		"web3":    newCircuitBreaker("web3", endpoints.Web3Service+"/health", cfg.Gateway, logger),
+	}
+
 	// Service status endpoint
-	mux.HandleFunc("GET /api/status", handleServiceStatus(endpoints, logger))
+	mux.HandleFunc("GET /api/status", handleServiceStatus(endpoints, breakers, hub, maintenance, logger))
 
 	// Proxy routes to microservices
-	setupProxyRoutes(mux, endpoints, logger)
+	setupProxyRoutes(mux, endpoints, breakers, cfg.Gateway, logger)
 
 	return handler
 }
 
-func setupProxyRoutes(mux *http.ServeMux, endpoints ServiceEndpoints, logger *observability.Logger) {
+func setupProxyRoutes(mux *http.ServeMux, endpoints ServiceEndpoints, breakers map[string]*circuitBreaker, cfg config.GatewayConfig, logger *observability.Logger) {
 	// Auth service routes
 	authURL, _ := url.Parse(endpoints.AuthService)
-	authProxy := httputil.NewSingleHostReverseProxy(authURL)
-	mux.Handle("/auth/", createProxyHandler(authProxy, "/auth", logger))
+	mux.Handle("/auth/", createProxyHandler(newServiceProxy(authURL, "/auth", breakers["auth"], cfg, logger), "/auth", breakers["auth"], logger))
 
 	// AI agent routes
 	aiURL, _ := url.Parse(endpoints.AIAgent)
-	aiProxy := httputil.NewSingleHostReverseProxy(aiURL)
-	mux.Handle("/ai/", createProxyHandler(aiProxy, "/ai", logger))
+	mux.Handle("/ai/", createProxyHandler(newServiceProxy(aiURL, "/ai", breakers["ai"], cfg, logger), "/ai", breakers["ai"], logger))
 
 	// Browser service routes
 	browserURL, _ := url.Parse(endpoints.BrowserService)
-	browserProxy := httputil.NewSingleHostReverseProxy(browserURL)
-	mux.Handle("/browser/", createProxyHandler(browserProxy, "/browser", logger))
+	mux.Handle("/browser/", createProxyHandler(newServiceProxy(browserURL, "/browser", breakers["browser"], cfg, logger), "/browser", breakers["browser"], logger))
 
 	// Web3 service routes
 	web3URL, _ := url.Parse(endpoints.Web3Service)
-	web3Proxy := httputil.NewSingleHostReverseProxy(web3URL)
-	mux.Handle("/web3/", createProxyHandler(web3Proxy, "/web3", logger))
+	mux.Handle("/web3/", createProxyHandler(newServiceProxy(web3URL, "/web3", breakers["web3"], cfg, logger), "/web3", breakers["web3"], logger))
+}
+
+// newServiceProxy creates the reverse proxy of one service. Connection errors and 502, 503
+// and 504 responses other than maintenance count as failures of the service's circuit breaker.
+func newServiceProxy(target *url.URL, prefix string, breaker *circuitBreaker, cfg config.GatewayConfig, logger *observability.Logger) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	// Fail fast on unreachable services instead of waiting for the OS connect timeout
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.UpstreamDialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   cfg.UpstreamDialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	proxy.Transport = transport
+
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		// Services in maintenance are draining, not failing
+		if resp.Header.Get(middleware.MaintenanceHeader) != "" {
+			return nil
+		}
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			breaker.RecordFailure(fmt.Errorf("upstream responded %d", resp.StatusCode))
+		default:
+			breaker.RecordSuccess()
+		}
+		return nil
+	}
+
+	// Custom error handler
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		// Requests cancelled by the client say nothing about the service
+		if r.Context().Err() == nil {
+			breaker.RecordFailure(err)
+		}
+
+		logger.Error(r.Context(), "Proxy error", err, map[string]interface{}{
+			"original_path": prefix + r.URL.Path,
+			"target_path":   r.URL.Path,
+			"prefix":        prefix,
+		})
+
+		httperr.WriteError(w, r, http.StatusBadGateway, httperr.CodeServiceUnavailable, "The requested service is currently unavailable")
+	}
+
+	return proxy
 }
 
-func createProxyHandler(proxy *httputil.ReverseProxy, prefix string, logger *observability.Logger) http.HandlerFunc {
+func createProxyHandler(proxy *httputil.ReverseProxy, prefix string, breaker *circuitBreaker, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Fail fast while the service's circuit is open
+		if !breaker.Allow() {
+			retryAfter := int(breaker.RetryAfter().Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			httperr.WriteError(w, r, http.StatusServiceUnavailable, httperr.CodeCircuitOpen, "The requested service is failing and temporarily not receiving requests")
+			return
+		}
+
 		// Log the proxy request
 		logger.Info(r.Context(), "Proxying request", map[string]interface{}{
 			"method": r.Method,
@@ -212,7 +330,6 @@ func createProxyHandler(proxy *httputil.ReverseProxy, prefix string, logger *obs
 		})
 
 		// Modify the request to remove the prefix if needed
-		originalPath := r.URL.Path
 		if strings.HasPrefix(r.URL.Path, prefix) {
 			r.URL.Path = strings.TrimPrefix(r.URL.Path, prefix)
 			if r.URL.Path == "" {
@@ -221,71 +338,27 @@ func createProxyHandler(proxy *httputil.ReverseProxy, prefix string, logger *obs
 		}
 
 		// Set headers for service identification
-		r.Header.Set("X-Forwarded-For", r.RemoteAddr)
+		clientAddr := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(clientAddr); err == nil {
+			clientAddr = host
+		}
+		r.Header.Set("X-Forwarded-For", clientAddr)
 		r.Header.Set("X-Forwarded-Proto", "http")
 		r.Header.Set("X-Gateway", "agentic-browser")
 
-		// Custom error handler
-		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-			logger.Error(r.Context(), "Proxy error", err, map[string]interface{}{
-				"original_path": originalPath,
-				"target_path":   r.URL.Path,
-				"prefix":        prefix,
-			})
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadGateway)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error":   "Service unavailable",
-				"message": "The requested service is currently unavailable",
-				"code":    "SERVICE_UNAVAILABLE",
-			})
-		}
-
 		proxy.ServeHTTP(w, r)
 	}
 }
 
-func handleWebSocket(logger *observability.Logger) http.HandlerFunc {
+func handleWebSocket(hub *wsHub, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			logger.Error(r.Context(), "WebSocket upgrade failed", err)
 			return
 		}
-		defer conn.Close()
-
-		logger.Info(r.Context(), "WebSocket connection established", map[string]interface{}{
-			"remote_addr": r.RemoteAddr,
-		})
-
-		// Handle WebSocket messages
-		for {
-			messageType, message, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					logger.Error(r.Context(), "WebSocket error", err)
-				}
-				break
-			}
 
-			// Echo the message back (in a real implementation, this would handle different message types)
-			response := map[string]interface{}{
-				"type":      "echo",
-				"message":   string(message),
-				"timestamp": time.Now(),
-			}
-
-			responseData, _ := json.Marshal(response)
-			if err := conn.WriteMessage(messageType, responseData); err != nil {
-				logger.Error(r.Context(), "WebSocket write error", err)
-				break
-			}
-		}
-
-		logger.Info(r.Context(), "WebSocket connection closed", map[string]interface{}{
-			"remote_addr": r.RemoteAddr,
-		})
+		hub.serve(r.Context(), conn, r.RemoteAddr)
 	}
 }
 
@@ -313,6 +386,7 @@ func handleAPIDocs() http.HandlerFunc {
 						{"method": "POST", "path": "/ai/tasks", "description": "Create AI task"},
 						{"method": "GET", "path": "/ai/tasks/{id}", "description": "Get task status"},
 						{"method": "GET", "path": "/ai/conversations", "description": "List conversations"},
+						{"method": "GET", "path": "/ai/conversations/{id}/messages", "description": "List conversation messages"},
 					},
 				},
 				"browser": map[string]interface{}{
@@ -330,11 +404,27 @@ func handleAPIDocs() http.HandlerFunc {
 					"endpoints": []map[string]string{
 						{"method": "POST", "path": "/web3/connect-wallet", "description": "Connect wallet"},
 						{"method": "GET", "path": "/web3/balance", "description": "Get wallet balance"},
+						{"method": "POST", "path": "/web3/balance", "description": "Aggregate balances of all wallets across chains"},
 						{"method": "POST", "path": "/web3/transaction", "description": "Create transaction"},
 						{"method": "GET", "path": "/web3/prices", "description": "Get crypto prices"},
+						{"method": "GET", "path": "/web3/market/candles", "description": "Get historical OHLCV candles"},
+						{"method": "GET", "path": "/web3/market/funding/{symbol}", "description": "Get perpetual futures funding rate history"},
+						{"method": "GET", "path": "/web3/market/openinterest/{symbol}", "description": "Get perpetual futures open interest history"},
+						{"method": "GET", "path": "/web3/analytics/portfolio/{portfolio_id}/benchmark", "description": "Compare a portfolio with a BTC, ETH, 60/40 or custom basket benchmark"},
+						{"method": "GET", "path": "/web3/analytics/portfolio/{portfolio_id}/tax-report", "description": "Realized gains per tax lot for a year, as JSON or CSV"},
+						{"method": "POST", "path": "/web3/trading/portfolio/{id}/transfers", "description": "Record a transfer or airdrop into your wallets"},
+						{"method": "POST", "path": "/web3/trading/blackouts", "description": "Schedule a trading blackout (admin)"},
+						{"method": "GET", "path": "/web3/trading/blackouts", "description": "List active and upcoming trading blackouts"},
+						{"method": "GET", "path": "/web3/trading/strategies", "description": "List trading strategies with schedules and signal metrics"},
 						{"method": "POST", "path": "/web3/defi/interact", "description": "DeFi interaction"},
 					},
 				},
+				"websocket": map[string]interface{}{
+					"base_url": "/ws",
+					"endpoints": []map[string]string{
+						{"method": "GET", "path": "/ws", "description": "Stream market data for the WS_MARKET_SYMBOLS symbols; send {\"action\":\"subscribe\",\"channel\":\"ticker\",\"symbol\":\"BTCUSDT\"}"},
+					},
+				},
 			},
 		}
 
@@ -343,16 +433,22 @@ func handleAPIDocs() http.HandlerFunc {
 	}
 }
 
-func handleServiceStatus(endpoints ServiceEndpoints, logger *observability.Logger) http.HandlerFunc {
+func handleServiceStatus(endpoints ServiceEndpoints, breakers map[string]*circuitBreaker, hub *wsHub, maintenance *middleware.MaintenanceMode, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 		defer cancel()
 
+		gatewayStatus := map[string]interface{}{
+			"status":    "healthy",
+			"timestamp": time.Now(),
+			"websocket": hub.Stats(),
+		}
+		if maintenance.Enabled() {
+			gatewayStatus["status"] = middleware.HealthStatusMaintenance
+			gatewayStatus["drain"] = maintenance.DrainStatus()
+		}
 		status := map[string]interface{}{
-			"gateway": map[string]interface{}{
-				"status":    "healthy",
-				"timestamp": time.Now(),
-			},
+			"gateway":  gatewayStatus,
 			"services": make(map[string]interface{}),
 		}
 
@@ -366,6 +462,9 @@ func handleServiceStatus(endpoints ServiceEndpoints, logger *observability.Logge
 
 		for name, healthURL := range services {
 			serviceStatus := checkServiceHealth(ctx, healthURL)
+			if breaker, exists := breakers[name]; exists {
+				serviceStatus["circuit"] = breaker.Snapshot()
+			}
 			status["services"].(map[string]interface{})[name] = serviceStatus
 		}
 
@@ -395,8 +494,17 @@ func checkServiceHealth(ctx context.Context, healthURL string) map[string]interf
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusOK {
+		// Services draining for maintenance answer 200 but say so in the body
+		var body struct {
+			Status string `json:"status"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		status := "healthy"
+		if body.Status == middleware.HealthStatusMaintenance {
+			status = middleware.HealthStatusMaintenance
+		}
 		return map[string]interface{}{
-			"status":      "healthy",
+			"status":      status,
 			"status_code": resp.StatusCode,
 		}
 	}
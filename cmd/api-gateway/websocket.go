@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/gorilla/websocket"
+)
+
+const wsMaxMessageSize = 4096
+
+// defaultStreamSymbols are streamed when WS_MARKET_SYMBOLS is not set
+var defaultStreamSymbols = []string{"BTCUSDT", "ETHUSDT", "ADAUSDT"}
+
+// streamChannels maps client channel names to market update types. Only channels the feed
+// produces are listed; anything else is rejected rather than left silent.
+var streamChannels = map[string]realtime.UpdateType{
+	"ticker": realtime.UpdateTypeTicker,
+	"trade":  realtime.UpdateTypeTrade,
+}
+
+// wsRequest is a control message sent by a WebSocket client
+type wsRequest struct {
+	Action  string `json:"action"` // subscribe, unsubscribe, ping
+	Channel string `json:"channel"`
+	Symbol  string `json:"symbol"`
+}
+
+// wsMessage is a message sent to a WebSocket client
+type wsMessage struct {
+	Type      string      `json:"type"`
+	Channel   string      `json:"channel,omitempty"`
+	Symbol    string      `json:"symbol,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// wsSubscription is a single channel/symbol stream forwarded to a client
+type wsSubscription struct {
+	channel string
+	symbol  string
+	updates <-chan realtime.MarketUpdate
+}
+
+// wsHub is the registry of connected WebSocket clients
+type wsHub struct {
+	logger     *observability.Logger
+	marketData *realtime.MarketDataService
+	config     config.WebSocketConfig
+	symbols    map[string]bool // canonical symbols streamed upstream
+	clients    map[*wsClient]struct{}
+	dropped    int64
+	mu         sync.RWMutex
+}
+
+// wsClient is a single WebSocket connection and its subscriptions
+type wsClient struct {
+	hub           *wsHub
+	conn          *websocket.Conn
+	send          chan []byte
+	done          chan struct{}
+	subscriptions map[string]*wsSubscription
+	remoteAddr    string
+	mu            sync.Mutex
+	closeOnce     sync.Once
+}
+
+// newWSHub creates a client registry streaming from the given market data service
+func newWSHub(logger *observability.Logger, marketData *realtime.MarketDataService, cfg config.WebSocketConfig) *wsHub {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.SendBufferSize <= 0 {
+		cfg.SendBufferSize = 256
+	}
+
+	symbols := make(map[string]bool, len(cfg.Symbols))
+	for _, symbol := range cfg.Symbols {
+		symbols[realtime.CanonicalSymbol(symbol)] = true
+	}
+
+	return &wsHub{
+		logger:     logger,
+		marketData: marketData,
+		config:     cfg,
+		symbols:    symbols,
+		clients:    make(map[*wsClient]struct{}),
+	}
+}
+
+// Stats reports current client and subscription counts
+func (h *wsHub) Stats() map[string]interface{} {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	subscriptions := 0
+	for client := range h.clients {
+		client.mu.Lock()
+		subscriptions += len(client.subscriptions)
+		client.mu.Unlock()
+	}
+
+	return map[string]interface{}{
+		"clients":         len(h.clients),
+		"subscriptions":   subscriptions,
+		"dropped_clients": h.dropped,
+	}
+}
+
+// Close disconnects every client and releases their subscriptions
+func (h *wsHub) Close() {
+	h.mu.RLock()
+	clients := make([]*wsClient, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.close()
+	}
+}
+
+// serve runs a client connection until it disconnects
+func (h *wsHub) serve(ctx context.Context, conn *websocket.Conn, remoteAddr string) {
+	client := &wsClient{
+		hub:           h,
+		conn:          conn,
+		send:          make(chan []byte, h.config.SendBufferSize),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]*wsSubscription),
+		remoteAddr:    remoteAddr,
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	h.logger.Info(ctx, "WebSocket connection established", map[string]interface{}{
+		"remote_addr": remoteAddr,
+	})
+
+	// The connection ends when ctx is cancelled, such as at the maintenance drain deadline
+	go func() {
+		select {
+		case <-ctx.Done():
+			client.close()
+		case <-client.done:
+		}
+	}()
+
+	go client.writePump()
+	client.readPump(ctx)
+
+	h.logger.Info(ctx, "WebSocket connection closed", map[string]interface{}{
+		"remote_addr": remoteAddr,
+	})
+}
+
+// readPump handles client control messages and keeps the read deadline alive on pongs
+func (c *wsClient) readPump(ctx context.Context) {
+	defer c.close()
+
+	pongWait := c.hub.config.PingInterval * 2
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				c.hub.logger.Error(ctx, "WebSocket error", err)
+			}
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(message, &req); err != nil {
+			c.reply(wsMessage{Type: "error", Error: "invalid message format"})
+			continue
+		}
+
+		switch req.Action {
+		case "subscribe":
+			if err := c.subscribe(req.Channel, req.Symbol); err != nil {
+				c.reply(wsMessage{Type: "error", Channel: req.Channel, Symbol: req.Symbol, Error: err.Error()})
+				continue
+			}
+			c.reply(wsMessage{Type: "subscribed", Channel: req.Channel, Symbol: strings.ToUpper(req.Symbol)})
+		case "unsubscribe":
+			if err := c.unsubscribe(req.Channel, req.Symbol); err != nil {
+				c.reply(wsMessage{Type: "error", Channel: req.Channel, Symbol: req.Symbol, Error: err.Error()})
+				continue
+			}
+			c.reply(wsMessage{Type: "unsubscribed", Channel: req.Channel, Symbol: strings.ToUpper(req.Symbol)})
+		case "ping":
+			c.reply(wsMessage{Type: "pong"})
+		default:
+			c.reply(wsMessage{Type: "error", Error: fmt.Sprintf("unknown action: %s", req.Action)})
+		}
+	}
+}
+
+// writePump delivers queued messages and sends heartbeat pings; it owns closing the socket
+func (c *wsClient) writePump() {
+	ticker := time.NewTicker(c.hub.config.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.close()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(c.hub.config.WriteTimeout))
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.hub.config.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.hub.config.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe starts forwarding a channel/symbol stream to the client
+func (c *wsClient) subscribe(channel, symbol string) error {
+	updateType, ok := streamChannels[channel]
+	if !ok {
+		return fmt.Errorf("unsupported channel: %s (available: ticker, trade)", channel)
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	// Subscribing to a symbol the feed doesn't stream would succeed but never deliver
+	if !c.hub.symbols[realtime.CanonicalSymbol(symbol)] {
+		return fmt.Errorf("unsupported symbol: %s (available: %s)", symbol, strings.Join(c.hub.config.Symbols, ", "))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := channel + ":" + symbol
+	if _, exists := c.subscriptions[key]; exists {
+		return nil
+	}
+	select {
+	case <-c.done:
+		return fmt.Errorf("connection closed")
+	default:
+	}
+
+	sub := &wsSubscription{
+		channel: channel,
+		symbol:  symbol,
+		updates: c.hub.marketData.Subscribe(symbol),
+	}
+	c.subscriptions[key] = sub
+
+	go c.forward(sub, updateType)
+
+	return nil
+}
+
+// unsubscribe stops forwarding a channel/symbol stream
+func (c *wsClient) unsubscribe(channel, symbol string) error {
+	key := channel + ":" + strings.ToUpper(strings.TrimSpace(symbol))
+
+	c.mu.Lock()
+	sub, exists := c.subscriptions[key]
+	delete(c.subscriptions, key)
+	c.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("not subscribed to %s", key)
+	}
+
+	c.hub.marketData.Unsubscribe(sub.symbol, sub.updates)
+	return nil
+}
+
+// forward relays matching market updates until the subscription channel is closed
+func (c *wsClient) forward(sub *wsSubscription, updateType realtime.UpdateType) {
+	for update := range sub.updates {
+		if update.Type != updateType {
+			continue
+		}
+		c.reply(wsMessage{
+			Type:      "market_update",
+			Channel:   sub.channel,
+			Symbol:    sub.symbol,
+			Data:      update,
+			Timestamp: update.Timestamp,
+		})
+	}
+}
+
+// reply queues a message for the client, dropping the client when its buffer is full
+func (c *wsClient) reply(message wsMessage) {
+	if message.Timestamp.IsZero() {
+		message.Timestamp = time.Now()
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		c.hub.logger.Error(context.Background(), "Failed to marshal WebSocket message", err)
+		return
+	}
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.send <- data:
+	default:
+		c.hub.logger.Warn(context.Background(), "Dropping slow WebSocket client", map[string]interface{}{
+			"remote_addr": c.remoteAddr,
+			"buffered":    len(c.send),
+		})
+		c.hub.mu.Lock()
+		c.hub.dropped++
+		c.hub.mu.Unlock()
+		c.close()
+	}
+}
+
+// close stops the client, releasing its subscriptions and registry entry; the write pump then closes the socket
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.mu.Lock()
+		subscriptions := c.subscriptions
+		c.subscriptions = make(map[string]*wsSubscription)
+		c.mu.Unlock()
+
+		for _, sub := range subscriptions {
+			c.hub.marketData.Unsubscribe(sub.symbol, sub.updates)
+		}
+
+		c.hub.mu.Lock()
+		delete(c.hub.clients, c)
+		c.hub.mu.Unlock()
+	})
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHubFixture starts a hub fed by a fake Binance stream and returns the gateway URL and
+// the upstream connection used to push exchange events
+func newHubFixture(t *testing.T) (string, *websocket.Conn) {
+	t.Helper()
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+
+	upstreams := make(chan *websocket.Conn, 1)
+	exchange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		upstreams <- conn
+	}))
+	t.Cleanup(exchange.Close)
+
+	marketData := realtime.NewMarketDataService(logger, realtime.MarketDataConfig{
+		Exchanges: []realtime.ExchangeConfig{{
+			Name:     "binance",
+			WSUrl:    "ws" + strings.TrimPrefix(exchange.URL, "http"),
+			Symbols:  []string{"BTCUSDT"},
+			Channels: []string{"ticker", "trade"},
+			Enabled:  true,
+		}},
+		BufferSize: 100,
+	})
+	require.NoError(t, marketData.Start())
+
+	var upstream *websocket.Conn
+	select {
+	case upstream = <-upstreams:
+	case <-time.After(5 * time.Second):
+		t.Fatal("market data service did not connect upstream")
+	}
+	// Consume the SUBSCRIBE request
+	_, _, err := upstream.ReadMessage()
+	require.NoError(t, err)
+
+	hub := newWSHub(logger, marketData, config.WebSocketConfig{Symbols: []string{"BTCUSDT"}})
+	gateway := httptest.NewServer(handleWebSocket(hub, logger))
+	t.Cleanup(func() {
+		hub.Close()
+		gateway.Close()
+		marketData.Stop()
+		upstream.Close()
+	})
+
+	return "ws" + strings.TrimPrefix(gateway.URL, "http"), upstream
+}
+
+func dialHub(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func request(t *testing.T, conn *websocket.Conn, req wsRequest) wsMessage {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(req))
+	return readMessage(t, conn)
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message wsMessage
+	require.NoError(t, conn.ReadJSON(&message))
+	return message
+}
+
+func pushTrade(t *testing.T, upstream *websocket.Conn, price string) {
+	t.Helper()
+	event := `{"e":"trade","E":1700000000000,"s":"BTCUSDT","t":1,"p":"` + price + `","q":"0.5","T":1700000000000,"m":false}`
+	require.NoError(t, upstream.WriteMessage(websocket.TextMessage, []byte(event)))
+}
+
+func TestWSHub_RejectsUnsupportedSubscriptions(t *testing.T) {
+	url, _ := newHubFixture(t)
+	client := dialHub(t, url)
+
+	// No upstream feed produces order book or kline updates
+	for _, channel := range []string{"orderbook", "kline", "volume"} {
+		reply := request(t, client, wsRequest{Action: "subscribe", Channel: channel, Symbol: "BTCUSDT"})
+		assert.Equal(t, "error", reply.Type)
+		assert.Contains(t, reply.Error, "unsupported channel: "+channel)
+	}
+
+	reply := request(t, client, wsRequest{Action: "subscribe", Channel: "trade", Symbol: "DOGEUSDT"})
+	assert.Equal(t, "error", reply.Type)
+	assert.Contains(t, reply.Error, "unsupported symbol: DOGEUSDT")
+
+	reply = request(t, client, wsRequest{Action: "subscribe", Channel: "trade"})
+	assert.Equal(t, "error", reply.Type)
+	assert.Equal(t, "symbol is required", reply.Error)
+
+	reply = request(t, client, wsRequest{Action: "unsubscribe", Channel: "trade", Symbol: "BTCUSDT"})
+	assert.Equal(t, "error", reply.Type)
+	assert.Equal(t, "not subscribed to trade:BTCUSDT", reply.Error)
+}
+
+func TestWSHub_FansOutToSubscribers(t *testing.T) {
+	url, upstream := newHubFixture(t)
+	first, second, ticker := dialHub(t, url), dialHub(t, url), dialHub(t, url)
+
+	for _, client := range []*websocket.Conn{first, second} {
+		reply := request(t, client, wsRequest{Action: "subscribe", Channel: "trade", Symbol: "btcusdt"})
+		assert.Equal(t, "subscribed", reply.Type)
+		assert.Equal(t, "BTCUSDT", reply.Symbol)
+	}
+	reply := request(t, ticker, wsRequest{Action: "subscribe", Channel: "ticker", Symbol: "BTCUSDT"})
+	require.Equal(t, "subscribed", reply.Type)
+
+	pushTrade(t, upstream, "50000")
+	for _, client := range []*websocket.Conn{first, second} {
+		update := readMessage(t, client)
+		assert.Equal(t, "market_update", update.Type)
+		assert.Equal(t, "trade", update.Channel)
+		assert.Equal(t, "BTCUSDT", update.Symbol)
+		assert.Equal(t, "50000", update.Data.(map[string]interface{})["price"])
+	}
+
+	// Trade updates are not forwarded to a ticker subscription
+	reply = request(t, ticker, wsRequest{Action: "ping"})
+	assert.Equal(t, "pong", reply.Type)
+}
+
+func TestWSHub_Unsubscribe(t *testing.T) {
+	url, upstream := newHubFixture(t)
+	leaving, staying := dialHub(t, url), dialHub(t, url)
+
+	for _, client := range []*websocket.Conn{leaving, staying} {
+		reply := request(t, client, wsRequest{Action: "subscribe", Channel: "trade", Symbol: "BTCUSDT"})
+		require.Equal(t, "subscribed", reply.Type)
+	}
+
+	reply := request(t, leaving, wsRequest{Action: "unsubscribe", Channel: "trade", Symbol: "BTCUSDT"})
+	assert.Equal(t, "unsubscribed", reply.Type)
+
+	pushTrade(t, upstream, "51000")
+	update := readMessage(t, staying)
+	assert.Equal(t, "market_update", update.Type)
+
+	// The update reached the remaining subscriber, so none is pending for the one that left
+	reply = request(t, leaving, wsRequest{Action: "ping"})
+	assert.Equal(t, "pong", reply.Type)
+}
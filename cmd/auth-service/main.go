@@ -3,16 +3,23 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/alerts"
 	"github.com/ai-agentic-browser/internal/auth"
 	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/security"
 	"github.com/ai-agentic-browser/pkg/database"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
@@ -50,10 +57,32 @@ func main() {
 	// Initialize auth service
 	authService := auth.NewService(db, redis, cfg.JWT, logger)
 
+	// Lockouts and other login protection events are recorded in the audit log, archived under
+	// AUDIT_ARCHIVE_DIR/auth-service
+	securityAudit, err := newSecurityAudit(cfg.Security, logger)
+	if err != nil {
+		log.Fatalf("Failed to initialize security audit log: %v", err)
+	}
+	if err := securityAudit.Start(context.Background()); err != nil {
+		logger.Error(context.Background(), "Failed to start security audit log", err)
+	}
+
+	// Failed logins are throttled and locked out per account and IP address across replicas
+	loginProtector := newLoginProtector(cfg, logger, redis, securityAudit)
+	authService.SetLoginGuard(loginProtector, nil)
+
+	// Successful logins are scored against the user's login history
+	loginRiskScorer := newLoginRiskScorer(cfg, logger, redis)
+	authService.SetLoginRiskAssessor(loginRiskScorer)
+
+	// Per-user rate limits shared across replicas through Redis
+	rateLimiter := middleware.NewRateLimiter(redis, logger, cfg.RateLimit, cfg.JWT.Secret)
+	rateLimiter.SetKeyPrefix("ratelimit:auth-service:")
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
-		Handler:      setupRoutes(authService, cfg, logger, db),
+		Handler:      setupRoutes(authService, loginProtector, loginRiskScorer, cfg, logger, db, rateLimiter, middleware.NewTokenRevocationList(redis)),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
@@ -87,7 +116,7 @@ func main() {
 	logger.Info(context.Background(), "Auth service stopped")
 }
 
-func setupRoutes(authService *auth.Service, cfg *config.Config, logger *observability.Logger, db *database.DB) http.Handler {
+func setupRoutes(authService *auth.Service, loginProtector *security.LoginProtector, loginRiskScorer *security.LoginRiskScorer, cfg *config.Config, logger *observability.Logger, db *database.DB, rateLimiter *middleware.RateLimiter, revocations *middleware.TokenRevocationList) http.Handler {
 	mux := http.NewServeMux()
 
 	// Apply middleware
@@ -95,7 +124,7 @@ func setupRoutes(authService *auth.Service, cfg *config.Config, logger *observab
 		middleware.Logging(logger)(
 			middleware.Tracing("auth-service")(
 				middleware.CORS(cfg.Security.CORSAllowedOrigins)(
-					middleware.RateLimit(cfg.RateLimit)(mux),
+					rateLimiter.Middleware()(mux),
 				),
 			),
 		),
@@ -128,9 +157,25 @@ func setupRoutes(authService *auth.Service, cfg *config.Config, logger *observab
 	protectedMux.HandleFunc("PUT /auth/me", handleUpdateProfile(authService, logger))
 	protectedMux.HandleFunc("POST /auth/change-password", handleChangePassword(authService, logger))
 
-	// Apply JWT middleware to protected routes
-	mux.Handle("/auth/me", middleware.JWT(cfg.JWT.Secret)(protectedMux))
-	mux.Handle("/auth/change-password", middleware.JWT(cfg.JWT.Secret)(protectedMux))
+	// API keys are managed with a JWT session only, so a leaked key cannot mint new keys
+	protectedMux.HandleFunc("POST /auth/api-keys", handleCreateAPIKey(authService, logger))
+	protectedMux.HandleFunc("GET /auth/api-keys", handleListAPIKeys(authService, logger))
+	protectedMux.HandleFunc("DELETE /auth/api-keys/{id}", handleRevokeAPIKey(authService, logger))
+
+	// Apply JWT middleware to protected routes, rejecting revoked tokens
+	requireAuth := middleware.JWTWithRevocation(cfg.JWT.Secret, revocations)
+	mux.Handle("/auth/me", requireAuth(protectedMux))
+	mux.Handle("/auth/change-password", requireAuth(protectedMux))
+	mux.Handle("/auth/api-keys", requireAuth(protectedMux))
+	mux.Handle("/auth/api-keys/", requireAuth(protectedMux))
+
+	// Role assignment is admin only; the admin role or ADMIN_USERS membership qualifies
+	adminAuthorizer := middleware.NewAdminAuthorizer(cfg.JWT.Secret, revocations, cfg.Security.AdminUsers)
+	mux.Handle("PUT /auth/users/{id}/role", adminAuthorizer.Middleware()(handleSetUserRole(authService, logger)))
+	mux.Handle("POST /auth/lockouts/clear", adminAuthorizer.Middleware()(handleClearLockout(loginProtector, logger)))
+
+	// Users see their own login history; administrators see anyone's
+	mux.Handle("GET /security/users/{id}/login-history", requireAuth(handleGetLoginHistory(loginRiskScorer, adminAuthorizer, logger)))
 
 	return handler
 }
@@ -166,9 +211,17 @@ func handleLogin(authService *auth.Service, logger *observability.Logger) http.H
 
 		userAgent := r.Header.Get("User-Agent")
 		ipAddress := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			ipAddress = host
+		}
 
 		response, err := authService.Login(r.Context(), req, userAgent, ipAddress)
 		if err != nil {
+			var blocked *auth.LoginBlockedError
+			if errors.As(err, &blocked) {
+				writeLoginBlocked(w, blocked)
+				return
+			}
 			logger.Error(r.Context(), "Login failed", err)
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
@@ -179,6 +232,23 @@ func handleLogin(authService *auth.Service, logger *observability.Logger) http.H
 	}
 }
 
+// writeLoginBlocked tells the client whether to retry later or pass a challenge first
+func writeLoginBlocked(w http.ResponseWriter, blocked *auth.LoginBlockedError) {
+	status := http.StatusUnauthorized
+	if blocked.Decision.Action == auth.LoginActionLocked {
+		status = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(blocked.Decision.RetryAfter.Seconds()+0.5)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     blocked.Error(),
+		"action":    blocked.Decision.Action,
+		"challenge": blocked.Decision.Challenge,
+	})
+}
+
 func handleRefreshToken(authService *auth.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req auth.RefreshTokenRequest
@@ -190,6 +260,10 @@ func handleRefreshToken(authService *auth.Service, logger *observability.Logger)
 		response, err := authService.RefreshToken(r.Context(), req.RefreshToken)
 		if err != nil {
 			logger.Error(r.Context(), "Token refresh failed", err)
+			if errors.Is(err, auth.ErrRefreshTokenReused) {
+				http.Error(w, "Refresh token reuse detected, session revoked", http.StatusUnauthorized)
+				return
+			}
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
@@ -214,6 +288,15 @@ func handleLogout(authService *auth.Service, logger *observability.Logger) http.
 			return
 		}
 
+		// Also revoke the presented access token, which may belong to another session
+		if accessToken, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found && accessToken != "" {
+			if err := authService.RevokeAccessToken(r.Context(), accessToken); err != nil {
+				logger.Warn(r.Context(), "Failed to revoke access token on logout", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"message": "Logged out successfully"})
 	}
@@ -260,3 +343,274 @@ func handleChangePassword(authService *auth.Service, logger *observability.Logge
 		json.NewEncoder(w).Encode(map[string]string{"message": "Password change not implemented yet"})
 	}
 }
+
+func handleSetUserRole(authService *auth.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.SetUserRole(r.Context(), userID, req.Role); err != nil {
+			switch {
+			case errors.Is(err, auth.ErrInvalidRole):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case strings.Contains(err.Error(), "user not found"):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			default:
+				logger.Error(r.Context(), "Failed to set user role", err)
+				http.Error(w, "Failed to set user role", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"user_id": userID.String(), "role": req.Role})
+	}
+}
+
+func handleClearLockout(loginProtector *security.LoginProtector, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Email     string `json:"email"`
+			IPAddress string `json:"ip_address"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Email == "" && req.IPAddress == "" {
+			http.Error(w, "email or ip_address is required", http.StatusBadRequest)
+			return
+		}
+
+		operator, _ := middleware.GetUserID(r.Context())
+		if err := loginProtector.ClearLockout(r.Context(), req.Email, req.IPAddress, operator); err != nil {
+			logger.Error(r.Context(), "Failed to clear login lockout", err)
+			http.Error(w, "Failed to clear lockout", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": "Lockout cleared"})
+	}
+}
+
+func handleGetLoginHistory(loginRiskScorer *security.LoginRiskScorer, adminAuthorizer *middleware.AdminAuthorizer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callerID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+
+		userID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		if userID != callerID && !adminAuthorizer.IsAdmin(r) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		limit := 50
+		if value := r.URL.Query().Get("limit"); value != "" {
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+				http.Error(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		logins, err := loginRiskScorer.LoginHistory(r.Context(), userID, limit)
+		if err != nil {
+			logger.Error(r.Context(), "Failed to get login history", err)
+			http.Error(w, "Failed to get login history", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id": userID,
+			"logins":  logins,
+		})
+	}
+}
+
+func handleCreateAPIKey(authService *auth.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+
+		var req auth.CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		key, err := authService.CreateAPIKey(r.Context(), userID, req)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidAPIKeyRequest) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.Error(r.Context(), "Failed to create API key", err)
+			http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(key)
+	}
+}
+
+func handleListAPIKeys(authService *auth.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+
+		keys, err := authService.ListAPIKeys(r.Context(), userID)
+		if err != nil {
+			logger.Error(r.Context(), "Failed to list API keys", err)
+			http.Error(w, "Failed to list API keys", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"api_keys": keys,
+			"count":    len(keys),
+		})
+	}
+}
+
+func handleRevokeAPIKey(authService *auth.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUserID(w, r)
+		if !ok {
+			return
+		}
+
+		keyID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+			if errors.Is(err, auth.ErrAPIKeyNotFound) {
+				http.Error(w, "API key not found", http.StatusNotFound)
+				return
+			}
+			logger.Error(r.Context(), "Failed to revoke API key", err)
+			http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// requestUserID returns the authenticated user, writing an error response if there is none
+func requestUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// newSecurityAudit creates this service's audit log, archived in its own subdirectory of
+// AUDIT_ARCHIVE_DIR
+func newSecurityAudit(cfg config.SecurityConfig, logger *observability.Logger) (*security.AuditManager, error) {
+	archiveDir := ""
+	if cfg.AuditArchiveDir != "" {
+		archiveDir = filepath.Join(cfg.AuditArchiveDir, "auth-service")
+	}
+	return security.NewServiceAuditManager(logger, cfg, archiveDir)
+}
+
+// newLoginProtector creates the failed login protection, auditing its events and emailing
+// account owners about lockouts when SMTP is configured
+func newLoginProtector(cfg *config.Config, logger *observability.Logger, redis *database.RedisClient, audit *security.AuditManager) *security.LoginProtector {
+	protectionConfig := security.DefaultLoginProtectionConfig()
+	protectionConfig.AccountMaxFailures = cfg.Security.LoginMaxFailures
+	protectionConfig.IPMaxFailures = cfg.Security.LoginIPMaxFailures
+	protectionConfig.FailureWindow = cfg.Security.LoginFailureWindow
+	protectionConfig.LockoutDuration = cfg.Security.LoginLockoutDuration
+	protectionConfig.ChallengeAfter = cfg.Security.LoginChallengeAfter
+
+	protector := security.NewLoginProtector(logger, protectionConfig, security.NewRedisLoginAttemptStore(redis))
+	protector.SetAuditor(audit)
+	if cfg.Alerts.SMTPHost != "" {
+		protector.SetNotifier(&emailLockoutNotifier{config: cfg.Alerts, logger: logger})
+	}
+	return protector
+}
+
+// newLoginRiskScorer creates the login risk scorer with the configured weights, locating
+// logins with MaxMind when credentials are configured
+func newLoginRiskScorer(cfg *config.Config, logger *observability.Logger, redis *database.RedisClient) *security.LoginRiskScorer {
+	riskConfig := security.DefaultLoginRiskConfig()
+	riskConfig.ImpossibleTravelWeight = cfg.Security.LoginRiskImpossibleTravelWeight
+	riskConfig.NewCountryWeight = cfg.Security.LoginRiskNewCountryWeight
+	riskConfig.NewDeviceWeight = cfg.Security.LoginRiskNewDeviceWeight
+	riskConfig.UnusualHourWeight = cfg.Security.LoginRiskUnusualHourWeight
+	riskConfig.MFAThreshold = cfg.Security.LoginRiskMFAThreshold
+
+	scorer := security.NewLoginRiskScorer(logger, riskConfig, security.NewRedisLoginHistoryStore(redis))
+	if cfg.Security.GeoIPAccountID != "" && cfg.Security.GeoIPLicenseKey != "" {
+		scorer.SetGeoIPResolver(security.NewMaxMindResolver(cfg.Security.GeoIPEndpoint, cfg.Security.GeoIPAccountID, cfg.Security.GeoIPLicenseKey))
+	}
+	return scorer
+}
+
+// emailLockoutNotifier emails account owners when their account is locked
+type emailLockoutNotifier struct {
+	config config.AlertsConfig
+	logger *observability.Logger
+}
+
+func (n *emailLockoutNotifier) NotifyAccountLocked(ctx context.Context, lockout security.AccountLockout) error {
+	channel := alerts.NewEmailChannel(alerts.EmailConfig{
+		SMTPHost:    n.config.SMTPHost,
+		SMTPPort:    n.config.SMTPPort,
+		Username:    n.config.SMTPUsername,
+		Password:    n.config.SMTPPassword,
+		FromAddress: n.config.EmailFrom,
+		ToAddresses: []string{lockout.Email},
+		Enabled:     true,
+	}, n.logger)
+
+	return channel.Send(ctx, alerts.Alert{
+		ID:    uuid.New().String(),
+		Title: "Your account has been temporarily locked",
+		Message: fmt.Sprintf("After %d failed login attempts, the last from %s, your account is locked until %s. "+
+			"If this wasn't you, change your password once you can sign in again.",
+			lockout.Failures, lockout.IPAddress, lockout.LockedUntil.UTC().Format(time.RFC1123)),
+		Severity:  alerts.SeverityWarning,
+		Timestamp: time.Now(),
+		UserID:    &lockout.UserID,
+		Category:  "security",
+	})
+}
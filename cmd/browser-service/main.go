@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 )
 
 func main() {
@@ -50,10 +53,19 @@ func main() {
 	// Initialize browser service
 	browserService := browser.NewService(db, redis, cfg.Browser, logger)
 
+	// Warm the browser pool; cancelling stops its browsers
+	poolCtx, stopPool := context.WithCancel(context.Background())
+	defer stopPool()
+	browserService.StartPool(poolCtx)
+
+	// Per-user rate limits shared across replicas through Redis
+	rateLimiter := middleware.NewRateLimiter(redis, logger, cfg.RateLimit, cfg.JWT.Secret)
+	rateLimiter.SetKeyPrefix("ratelimit:browser-service:")
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, "8083"), // Browser service port
-		Handler:      setupRoutes(browserService, cfg, logger, db),
+		Handler:      setupRoutes(browserService, cfg, logger, db, rateLimiter, middleware.NewTokenRevocationList(redis)),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
@@ -83,11 +95,12 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	stopPool()
 
 	logger.Info(context.Background(), "Browser service stopped")
 }
 
-func setupRoutes(browserService *browser.Service, cfg *config.Config, logger *observability.Logger, db *database.DB) http.Handler {
+func setupRoutes(browserService *browser.Service, cfg *config.Config, logger *observability.Logger, db *database.DB, rateLimiter *middleware.RateLimiter, revocations *middleware.TokenRevocationList) http.Handler {
 	mux := http.NewServeMux()
 
 	// Apply middleware
@@ -95,7 +108,7 @@ func setupRoutes(browserService *browser.Service, cfg *config.Config, logger *ob
 		middleware.Logging(logger)(
 			middleware.Tracing("browser-service")(
 				middleware.CORS(cfg.Security.CORSAllowedOrigins)(
-					middleware.RateLimit(cfg.RateLimit)(mux),
+					rateLimiter.Middleware()(mux),
 				),
 			),
 		),
@@ -112,21 +125,34 @@ func setupRoutes(browserService *browser.Service, cfg *config.Config, logger *ob
 			return
 		}
 
+		health := map[string]interface{}{"status": "healthy"}
+		if stats := browserService.PoolStats(); stats != nil {
+			health["browser_pool"] = stats
+		}
+
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+		json.NewEncoder(w).Encode(health)
 	})
 
 	// Protected browser endpoints
 	protectedMux := http.NewServeMux()
 	protectedMux.HandleFunc("POST /browser/sessions", handleCreateSession(browserService, logger))
 	protectedMux.HandleFunc("GET /browser/sessions", handleListSessions(browserService, logger))
+	protectedMux.HandleFunc("DELETE /browser/sessions/{id}", handleCloseSession(browserService, logger))
+	protectedMux.HandleFunc("GET /browser/sessions/{id}/recording", handleGetRecording(browserService, logger))
+	protectedMux.HandleFunc("GET /browser/sessions/{id}/live", handleLiveView(browserService, logger))
 	protectedMux.HandleFunc("POST /browser/navigate", handleNavigate(browserService, logger))
 	protectedMux.HandleFunc("POST /browser/interact", handleInteract(browserService, logger))
 	protectedMux.HandleFunc("POST /browser/extract", handleExtract(browserService, logger))
+	protectedMux.HandleFunc("POST /browser/templates", handleCreateTemplate(browserService, logger))
+	protectedMux.HandleFunc("GET /browser/templates", handleListTemplates(browserService, logger))
+	protectedMux.HandleFunc("GET /browser/templates/{id}", handleGetTemplate(browserService, logger))
+	protectedMux.HandleFunc("PUT /browser/templates/{id}", handleUpdateTemplate(browserService, logger))
+	protectedMux.HandleFunc("DELETE /browser/templates/{id}", handleDeleteTemplate(browserService, logger))
 	protectedMux.HandleFunc("POST /browser/screenshot", handleScreenshot(browserService, logger))
 
 	// Apply JWT middleware to protected routes
-	mux.Handle("/browser/", middleware.JWT(cfg.JWT.Secret)(protectedMux))
+	mux.Handle("/browser/", middleware.JWTWithRevocation(cfg.JWT.Secret, revocations)(protectedMux))
 
 	return handler
 }
@@ -152,6 +178,9 @@ func handleCreateSession(browserService *browser.Service, logger *observability.
 		}
 
 		session, err := browserService.CreateSession(r.Context(), userID, req)
+		if writePoolUnavailable(w, err) {
+			return
+		}
 		if err != nil {
 			logger.Error(r.Context(), "Session creation failed", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -166,6 +195,52 @@ func handleCreateSession(browserService *browser.Service, logger *observability.
 	}
 }
 
+func handleCloseSession(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		sessionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+			return
+		}
+
+		err = browserService.CloseSession(r.Context(), userID, sessionID)
+		switch {
+		case errors.Is(err, browser.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Failed to close session", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writePoolUnavailable responds with 429 and a Retry-After header when err means no pooled
+// browser could be assigned, reporting whether it did
+func writePoolUnavailable(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, browser.ErrPoolExhausted) && !errors.Is(err, browser.ErrSessionLimitReached) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(browser.PoolRetryAfter.Seconds())))
+	http.Error(w, err.Error(), http.StatusTooManyRequests)
+	return true
+}
+
 func handleListSessions(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
@@ -180,13 +255,179 @@ func handleListSessions(browserService *browser.Service, logger *observability.L
 			return
 		}
 
-		// TODO: Implement ListSessions method in browser service
+		req := browser.SessionListRequest{
+			UserID: userID,
+			Status: browser.SessionStatus(r.URL.Query().Get("status")),
+		}
+		switch req.Status {
+		case "", browser.SessionStatusActive, browser.SessionStatusClosed, browser.SessionStatusTerminated:
+		default:
+			http.Error(w, "Invalid status filter", http.StatusBadRequest)
+			return
+		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			if v, err := strconv.Atoi(limit); err == nil {
+				req.Limit = v
+			}
+		}
+		if offset := r.URL.Query().Get("offset"); offset != "" {
+			if v, err := strconv.Atoi(offset); err == nil {
+				req.Offset = v
+			}
+		}
+
+		response, err := browserService.ListSessions(r.Context(), userID, req)
+		if err != nil {
+			logger.Error(r.Context(), "Session listing failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"user_id":  userID.String(),
-			"sessions": []interface{}{},
-			"message":  "Session listing not implemented yet",
-		})
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+func handleGetRecording(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		sessionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+			return
+		}
+
+		recording, err := browserService.GetRecording(r.Context(), userID, sessionID)
+		switch {
+		case errors.Is(err, browser.ErrSessionNotFound), errors.Is(err, browser.ErrRecordingDisabled):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Failed to get session recording", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(recording)
+	}
+}
+
+const (
+	liveViewWriteWait  = 10 * time.Second
+	liveViewPongWait   = 60 * time.Second
+	liveViewPingPeriod = liveViewPongWait * 9 / 10
+)
+
+var liveViewUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		// In production, implement proper origin checking
+		return true
+	},
+}
+
+// handleLiveView streams a session's screencast and console messages over a WebSocket. The
+// stream is read-only: messages from the viewer are discarded.
+func handleLiveView(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		sessionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+			return
+		}
+
+		var fps int
+		if v := r.URL.Query().Get("fps"); v != "" {
+			if fps, err = strconv.Atoi(v); err != nil || fps <= 0 {
+				http.Error(w, "Invalid fps", http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		events, err := browserService.WatchSession(ctx, userID, sessionID, fps)
+		switch {
+		case errors.Is(err, browser.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, browser.ErrLiveViewUnavailable):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			if writePoolUnavailable(w, err) {
+				return
+			}
+			logger.Error(r.Context(), "Failed to start session live view", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		conn, err := liveViewUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader already responded; cancel stops the stream
+			return
+		}
+		defer conn.Close()
+
+		// Read only to process control frames and notice the viewer leaving
+		go func() {
+			defer cancel()
+			conn.SetReadDeadline(time.Now().Add(liveViewPongWait))
+			conn.SetPongHandler(func(string) error {
+				return conn.SetReadDeadline(time.Now().Add(liveViewPongWait))
+			})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ticker := time.NewTicker(liveViewPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-events:
+				conn.SetWriteDeadline(time.Now().Add(liveViewWriteWait))
+				if !ok {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session closed"))
+					return
+				}
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				conn.SetWriteDeadline(time.Now().Add(liveViewWriteWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			}
+		}
 	}
 }
 
@@ -211,7 +452,16 @@ func handleNavigate(browserService *browser.Service, logger *observability.Logge
 		}
 
 		response, err := browserService.Navigate(r.Context(), sessionID, req)
-		if err != nil {
+		switch {
+		case writePoolUnavailable(w, err):
+			return
+		case errors.Is(err, browser.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, browser.ErrInvalidWaitCondition), errors.Is(err, browser.ErrInvalidRetries):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
 			logger.Error(r.Context(), "Navigation failed", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -243,7 +493,16 @@ func handleInteract(browserService *browser.Service, logger *observability.Logge
 		}
 
 		response, err := browserService.Interact(r.Context(), sessionID, req)
-		if err != nil {
+		switch {
+		case writePoolUnavailable(w, err):
+			return
+		case errors.Is(err, browser.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, browser.ErrInvalidWaitCondition), errors.Is(err, browser.ErrInvalidRetries):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
 			logger.Error(r.Context(), "Interaction failed", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -274,8 +533,35 @@ func handleExtract(browserService *browser.Service, logger *observability.Logger
 			return
 		}
 
-		response, err := browserService.Extract(r.Context(), sessionID, req)
-		if err != nil {
+		var response *browser.ExtractResponse
+		if req.TemplateID != nil {
+			userIDStr, ok := middleware.GetUserID(r.Context())
+			if !ok {
+				http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+				return
+			}
+			userID, parseErr := uuid.Parse(userIDStr)
+			if parseErr != nil {
+				http.Error(w, "Invalid user ID", http.StatusBadRequest)
+				return
+			}
+			response, err = browserService.ExtractWithTemplate(r.Context(), userID, sessionID, req)
+		} else {
+			response, err = browserService.Extract(r.Context(), sessionID, req)
+		}
+		switch {
+		case writePoolUnavailable(w, err):
+			return
+		case errors.Is(err, browser.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, browser.ErrTemplateNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, browser.ErrTemplateURLRequired), errors.Is(err, browser.ErrInvalidTemplate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
 			logger.Error(r.Context(), "Content extraction failed", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -286,6 +572,188 @@ func handleExtract(browserService *browser.Service, logger *observability.Logger
 	}
 }
 
+func handleCreateTemplate(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req browser.ExtractionTemplateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		template, err := browserService.CreateTemplate(r.Context(), userID, req)
+		switch {
+		case errors.Is(err, browser.ErrInvalidTemplate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Extraction template creation failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(template)
+	}
+}
+
+func handleListTemplates(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		templates, err := browserService.ListTemplates(r.Context(), userID)
+		if err != nil {
+			logger.Error(r.Context(), "Extraction template listing failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"templates": templates,
+			"count":     len(templates),
+		})
+	}
+}
+
+func handleGetTemplate(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		templateID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid template ID", http.StatusBadRequest)
+			return
+		}
+
+		template, err := browserService.GetTemplate(r.Context(), userID, templateID)
+		switch {
+		case errors.Is(err, browser.ErrTemplateNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Failed to get extraction template", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(template)
+	}
+}
+
+func handleUpdateTemplate(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		templateID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid template ID", http.StatusBadRequest)
+			return
+		}
+
+		var req browser.ExtractionTemplateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		template, err := browserService.UpdateTemplate(r.Context(), userID, templateID, req)
+		switch {
+		case errors.Is(err, browser.ErrInvalidTemplate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, browser.ErrTemplateNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Extraction template update failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(template)
+	}
+}
+
+func handleDeleteTemplate(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		templateID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid template ID", http.StatusBadRequest)
+			return
+		}
+
+		err = browserService.DeleteTemplate(r.Context(), userID, templateID)
+		switch {
+		case errors.Is(err, browser.ErrTemplateNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Extraction template deletion failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func handleScreenshot(browserService *browser.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sessionIDStr := r.Header.Get("X-Session-ID")
@@ -307,7 +775,13 @@ func handleScreenshot(browserService *browser.Service, logger *observability.Log
 		}
 
 		response, err := browserService.TakeScreenshot(r.Context(), sessionID, req)
-		if err != nil {
+		switch {
+		case writePoolUnavailable(w, err):
+			return
+		case errors.Is(err, browser.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
 			logger.Error(r.Context(), "Screenshot failed", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -2,21 +2,33 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/ai-agentic-browser/api"
+	"github.com/ai-agentic-browser/internal/analytics"
+	appconfig "github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/internal/security"
 	"github.com/ai-agentic-browser/internal/trading"
 	"github.com/ai-agentic-browser/internal/trading/monitoring"
 	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
 	"gopkg.in/yaml.v3"
 )
@@ -54,6 +66,9 @@ type TradingBotsConfig struct {
 		RetryAttempts             int           `yaml:"retry_attempts"`
 		PerformanceUpdateInterval time.Duration `yaml:"performance_update_interval"`
 		HealthCheckInterval       time.Duration `yaml:"health_check_interval"`
+		ExecutionReportRetention  time.Duration `yaml:"execution_report_retention"`
+		ReconciliationInterval    time.Duration `yaml:"reconciliation_interval"`
+		ReconciliationLookback    time.Duration `yaml:"reconciliation_lookback"`
 	} `yaml:"trading_bots"`
 
 	Exchanges map[string]ExchangeConfig `yaml:"exchanges"`
@@ -65,9 +80,6 @@ type ExchangeConfig struct {
 	TestnetURL string `yaml:"testnet_url"`
 	RateLimit  int    `yaml:"rate_limit"`
 	Sandbox    bool   `yaml:"sandbox"`
-	APIKey     string `yaml:"api_key"`
-	APISecret  string `yaml:"api_secret"`
-	Passphrase string `yaml:"passphrase"`
 }
 
 func main() {
@@ -107,6 +119,49 @@ func main() {
 	}
 
 	botEngine := trading.NewTradingBotEngine(logger, botEngineConfig)
+	botEngine.SetRiskManager(riskManager)
+
+	// Live market data drives paper fills and position marks
+	priceSymbols := []string{"BTCUSDT", "ETHUSDT", "BNBUSDT", "ADAUSDT", "SOLUSDT"}
+	marketDataService := realtime.NewMarketDataService(logger, realtime.MarketDataConfig{
+		Exchanges: []realtime.ExchangeConfig{
+			{
+				Name:     "binance",
+				WSUrl:    "wss://stream.binance.com:9443/ws",
+				Symbols:  priceSymbols,
+				Channels: []string{"ticker", "trade"},
+				Enabled:  true,
+			},
+		},
+		ReconnectDelay:  5 * time.Second,
+		PingInterval:    30 * time.Second,
+		MaxReconnects:   10,
+		BufferSize:      1000,
+		EnableHeartbeat: true,
+	})
+	if err := marketDataService.Start(); err != nil {
+		log.Fatalf("Failed to start market data service: %v", err)
+	}
+
+	priceFeed := trading.NewMarketDataPriceFeed(marketDataService)
+	go priceFeed.Run(ctx, priceSymbols)
+	botEngine.SetPriceFeed(priceFeed)
+
+	// Initialize execution engine for algorithmic (TWAP, iceberg) orders
+	executionEngine := trading.NewExecutionEngine(logger)
+	executionEngine.SetPriceFeed(priceFeed)
+	executionEngine.SetReportRetention(config.TradingBots.ExecutionReportRetention)
+
+	// Unusual sizes and slippage of algorithmic orders placed for a portfolio are logged as
+	// trade anomalies; no alert channels are configured for this service
+	anomalyDetector := analytics.NewAnomalyDetector(logger, &analytics.AnalyticsConfig{AnomalyDetectionSensitivity: 0.8})
+	executionEngine.SetExecutionObserver(analytics.NewTradeAnomalyMonitor(logger, anomalyDetector, nil, analytics.TradeAnomalyConfig{
+		Sensitivity:    0.8,
+		MaxSlippageBps: 100,
+	}))
+	if err := executionEngine.Start(ctx); err != nil {
+		log.Fatalf("Failed to start execution engine: %v", err)
+	}
 
 	// Initialize strategy manager
 	strategyManager := strategies.NewStrategyManager(logger)
@@ -128,6 +183,8 @@ func main() {
 		EnableDashboard:      true,
 		EnableMetricsExport:  true,
 		EnableProfiling:      false,
+
+		AlertOnOrderDiscrepancies: true,
 	}
 
 	monitor := monitoring.NewTradingBotMonitor(logger, monitoringConfig, botEngine, riskManager)
@@ -135,8 +192,128 @@ func main() {
 		log.Fatalf("Failed to start monitoring system: %v", err)
 	}
 
+	// Initialize performance monitoring with Prometheus collectors
+	perfMonitor := observability.NewPerformanceMonitor(logger)
+	defer perfMonitor.Stop()
+
+	promExporter := observability.NewPrometheusExporter("trading-bots")
+	perfMonitor.SetPrometheusExporter(promExporter)
+	if err := registerBotMetrics(promExporter, botEngine); err != nil {
+		log.Fatalf("Failed to register trading bot metrics: %v", err)
+	}
+
 	// Initialize API handlers
 	tradingBotHandler := api.NewTradingBotHandler(logger, botEngine, strategyManager)
+	tradingBotHandler.SetExecutionEngine(executionEngine)
+	tradingBotHandler.SetCandleSource(marketDataService)
+
+	// Idempotency keys for order submission and the trading blackouts scheduled through the
+	// web3 service are read from Redis when it is reachable, and decisions approved in the AI
+	// agent arrive through it
+	redisClient, err := database.NewRedisClient(appconfig.RedisConfig{
+		URL:      fmt.Sprintf("redis://%s:%d", config.Redis.Host, config.Redis.Port),
+		Password: config.Redis.Password,
+		DB:       config.Redis.DB,
+		PoolSize: 10,
+	})
+	if err != nil {
+		logger.Warn(ctx, "Redis unavailable, idempotency keys, trading blackouts and approved AI decisions disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		defer redisClient.Close()
+		tradingBotHandler.SetIdempotency(middleware.NewIdempotencyMiddleware(redisClient, logger))
+		botEngine.SetTradingCalendar(web3.NewTradingCalendar(logger, web3.NewRedisBlackoutStore(redisClient)))
+		go trading.NewDecisionConsumer(logger, botEngine, redisClient, trading.DecisionExecutionChannel).Run(ctx)
+	}
+
+	// Bots created through the API are kept in the shared database with their owners and
+	// exchange connections, DCA bots with their filled rungs and grid bots with their levels.
+	// Live bots trade through their owners' exchange connections, which also need the JWT secret
+	// and EXCHANGE_CREDENTIALS_KEY; without them bots cannot go live.
+	var exchangeHandler *api.ExchangeHandler
+	var encryption *security.EncryptionManager
+	var reconciler *trading.OrderReconciler
+	appCfg, appCfgErr := appconfig.Load()
+
+	// Key escrow exports and imports and risk limit changes share one audit log, archived under
+	// AUDIT_ARCHIVE_DIR/trading-bots
+	var securityAudit *security.AuditManager
+	if appCfgErr == nil {
+		audit, err := newSecurityAudit(appCfg.Security, logger)
+		if err != nil {
+			log.Fatalf("Failed to initialize security audit log: %v", err)
+		}
+		if err := audit.Start(ctx); err != nil {
+			logger.Error(ctx, "Failed to start security audit log", err)
+		}
+		securityAudit = audit
+	}
+
+	if appCfgErr != nil {
+		logger.Warn(ctx, "Shared database disabled, bots, DCA rungs and grid levels are not persisted and exchange connections are unavailable", map[string]interface{}{
+			"error": appCfgErr.Error(),
+		})
+	} else if db, err := database.NewPostgresDB(appCfg.Database); err != nil {
+		logger.Warn(ctx, "Shared database disabled, bots, DCA rungs and grid levels are not persisted and exchange connections are unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		defer db.Close()
+		botEngine.SetDCARungStore(trading.NewPostgresDCARungStore(db))
+		botEngine.SetGridStore(trading.NewPostgresGridStore(db))
+
+		if handler, manager, err := newExchangeHandler(appCfg, db, config.Exchanges, botEngine, securityAudit, logger); err != nil {
+			logger.Warn(ctx, "Exchange connections disabled", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			exchangeHandler = handler
+			encryption = manager
+
+			// Live orders are journaled and reconciled with the exchanges, so fills missed
+			// while the service was down are repaired and reported to the monitor
+			liveOrders := trading.NewPostgresLiveOrderStore(db)
+			botEngine.SetLiveOrderStore(liveOrders)
+			reconciler = trading.NewOrderReconciler(logger, botEngine, liveOrders)
+			reconciler.SetSchedule(config.TradingBots.ReconciliationInterval, config.TradingBots.ReconciliationLookback)
+			reconciler.AddListener(monitor)
+		}
+
+		botEngine.SetBotStore(trading.NewPostgresBotStore(db))
+		if err := tradingBotHandler.RestoreBots(ctx); err != nil {
+			logger.Warn(ctx, "Failed to restore trading bots", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if appCfgErr == nil {
+		tradingBotHandler.SetStrictJSON(appCfg.Server.StrictJSON)
+	}
+
+	// Changes of bots' risk limits are audit-logged; without the shared config there is no JWT
+	// secret to identify who requests and approves them, so they can't be changed
+	if appCfgErr == nil {
+		riskManager.SetAuditor(securityAudit)
+	}
+
+	// Maintenance mode is enabled through the gateway and shared through Redis; bots stop
+	// opening positions while it lasts but keep closing them
+	maintenanceConfig := appconfig.MaintenanceConfig{EssentialPaths: appconfig.DefaultMaintenanceEssentialPaths}
+	if appCfgErr == nil {
+		maintenanceConfig = appCfg.Maintenance
+	}
+	maintenance := middleware.NewMaintenanceMode(redisClient, logger, maintenanceConfig)
+	maintenance.OnChange(func(state middleware.MaintenanceState) {
+		if state.Enabled {
+			botEngine.HaltOpenings("maintenance: " + state.Reason)
+		} else {
+			botEngine.ResumeOpenings()
+		}
+	})
+	go maintenance.Run(ctx)
+
 	riskManagementHandler := api.NewRiskManagementHandler(logger, riskManager)
 	monitoringHandler := api.NewMonitoringHandler(logger, monitor)
 
@@ -147,13 +324,35 @@ func main() {
 	tradingBotHandler.RegisterRoutes(router)
 	riskManagementHandler.RegisterRoutes(router)
 	monitoringHandler.RegisterRoutes(router)
+	if appCfgErr == nil {
+		// Tokens revoked at logout can't change risk limits, stream bot events, or reach exchange
+		// credentials or live orders
+		jwtAuth := middleware.JWTWithRevocation(appCfg.JWT.Secret, middleware.NewTokenRevocationList(redisClient))
+		riskManagementHandler.RegisterLimitRoutes(router, jwtAuth)
+
+		// Bot state changes, fills and risk limits are pushed over WebSocket to signed-in users
+		// of the allowed origins
+		monitoringHandler.SetEventStreamOrigins(appCfg.Security.CORSAllowedOrigins)
+		monitoringHandler.RegisterEventStreamRoute(router, jwtAuth)
+		if exchangeHandler != nil {
+			exchangeHandler.RegisterRoutes(router, jwtAuth)
+			api.NewReconciliationHandler(logger, reconciler).RegisterRoutes(router, jwtAuth)
+		}
+	}
+	if encryption != nil && appCfg.Security.KeyEscrowPublicKeyFile != "" {
+		adminAuthorizer := middleware.NewAdminAuthorizer(appCfg.JWT.Secret, middleware.NewTokenRevocationList(redisClient), appCfg.Security.AdminUsers)
+		router.Handle("/admin/security/keys/escrow-export", adminAuthorizer.Middleware()(handleKeyEscrowExport(encryption, logger))).Methods("POST")
+		router.Handle("/admin/security/keys/escrow-import", adminAuthorizer.Middleware()(handleKeyEscrowImport(encryption, logger))).Methods("POST")
+	}
 
 	// Add health check endpoint
-	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
-	router.HandleFunc("/api/v1/health", healthCheckHandler).Methods("GET")
+	router.HandleFunc("/health", healthCheckHandler(maintenance)).Methods("GET")
+	router.HandleFunc("/api/v1/health", healthCheckHandler(maintenance)).Methods("GET")
 
-	// Add metrics endpoint
-	router.HandleFunc("/metrics", metricsHandler).Methods("GET")
+	// Add metrics endpoints
+	router.Handle("/metrics", promExporter.Handler()).Methods("GET")
+	router.Handle("/metrics/prometheus", promExporter.Handler()).Methods("GET")
+	router.Use(middleware.Metrics(perfMonitor))
 
 	// Setup CORS
 	c := cors.New(cors.Options{
@@ -163,7 +362,7 @@ func main() {
 		AllowCredentials: true,
 	})
 
-	handler := c.Handler(router)
+	handler := c.Handler(maintenance.Middleware()(router))
 
 	// Create HTTP server
 	server := &http.Server{
@@ -179,6 +378,10 @@ func main() {
 		log.Fatalf("Failed to start trading bot engine: %v", err)
 	}
 
+	if reconciler != nil {
+		go reconciler.Run(ctx)
+	}
+
 	// Start HTTP server in a goroutine
 	go func() {
 		logger.Info(ctx, "Starting trading bots server", map[string]interface{}{
@@ -206,6 +409,16 @@ func main() {
 		logger.Error(shutdownCtx, "Failed to stop trading bot engine", err, nil)
 	}
 
+	// Stop execution engine
+	if err := executionEngine.Stop(shutdownCtx); err != nil {
+		logger.Error(shutdownCtx, "Failed to stop execution engine", err, nil)
+	}
+
+	// Stop market data service
+	if err := marketDataService.Stop(); err != nil {
+		logger.Error(shutdownCtx, "Failed to stop market data service", err, nil)
+	}
+
 	// Stop risk management system
 	if err := riskManager.Stop(shutdownCtx); err != nil {
 		logger.Error(shutdownCtx, "Failed to stop risk manager", err, nil)
@@ -289,10 +502,60 @@ func loadConfig() (*TradingBotsConfig, error) {
 	if config.TradingBots.HealthCheckInterval == 0 {
 		config.TradingBots.HealthCheckInterval = 30 * time.Second
 	}
+	if config.TradingBots.ExecutionReportRetention == 0 {
+		config.TradingBots.ExecutionReportRetention = 24 * time.Hour
+	}
 
 	return config, nil
 }
 
+// newExchangeHandler sets up users' exchange connections: credentials are sealed with the
+// configured key and stored in Postgres, and live bot orders resolve them per order. With
+// KEY_ESCROW_PUBLIC_KEY_FILE set, the sealing key can be exported in escrow and restored from
+// it, both recorded in audit.
+func newExchangeHandler(cfg *appconfig.Config, db *database.DB, exchanges map[string]ExchangeConfig, botEngine *trading.TradingBotEngine, audit *security.AuditManager, logger *observability.Logger) (*api.ExchangeHandler, *security.EncryptionManager, error) {
+	if cfg.Security.ExchangeCredentialsKey == "" {
+		return nil, nil, fmt.Errorf("EXCHANGE_CREDENTIALS_KEY is not set")
+	}
+	key, err := hex.DecodeString(cfg.Security.ExchangeCredentialsKey)
+	if err != nil || len(key) != 32 {
+		return nil, nil, fmt.Errorf("EXCHANGE_CREDENTIALS_KEY must be 64 hex characters")
+	}
+
+	encryption := security.NewEncryptionManager(logger, &security.EncryptionConfig{
+		Algorithm:        "AES-256-GCM",
+		EncryptionAtRest: true,
+		EnableKeyEscrow:  cfg.Security.KeyEscrowPublicKeyFile != "",
+	})
+	if _, err := encryption.ImportKey(trading.ExchangeCredentialsPurpose, key); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Security.KeyEscrowPublicKeyFile != "" {
+		escrowKey, err := os.ReadFile(cfg.Security.KeyEscrowPublicKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read KEY_ESCROW_PUBLIC_KEY_FILE: %w", err)
+		}
+		if err := encryption.SetEscrowPublicKey(escrowKey); err != nil {
+			return nil, nil, err
+		}
+		encryption.SetAuditor(audit)
+	}
+
+	binanceURL := exchanges["binance"].APIURL
+	if exchanges["binance"].Sandbox {
+		binanceURL = exchanges["binance"].TestnetURL
+	}
+	if binanceURL == "" {
+		binanceURL = "https://api.binance.com"
+	}
+	connectors := []trading.ExchangeConnector{trading.NewBinanceConnector(binanceURL)}
+
+	connections := trading.NewExchangeConnectionManager(logger, trading.NewPostgresExchangeConnectionStore(db), encryption, botEngine, connectors...)
+	botEngine.SetExchangeConnectivity(connections, connectors...)
+
+	return api.NewExchangeHandler(logger, connections), encryption, nil
+}
+
 // parsePort parses a port string to integer
 func parsePort(portStr string) (int, error) {
 	var port int
@@ -306,46 +569,148 @@ func parsePort(portStr string) (int, error) {
 }
 
 // healthCheckHandler handles health check requests
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+func healthCheckHandler(maintenance *middleware.MaintenanceMode) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		response := map[string]interface{}{
+			"status":    "healthy",
+			"service":   "trading-bots",
+			"timestamp": time.Now().UTC(),
+			"version":   "1.0.0",
+		}
+		// Draining pods stay healthy to orchestrators
+		if maintenance.Enabled() {
+			response["status"] = middleware.HealthStatusMaintenance
+			response["drain"] = maintenance.DrainStatus()
+		}
 
-	response := map[string]interface{}{
-		"status":    "healthy",
-		"service":   "trading-bots",
-		"timestamp": time.Now().UTC(),
-		"version":   "1.0.0",
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
 	}
+}
+
+// handleKeyEscrowExport exports the encryption keys in a bundle only the escrow private key
+// can open, attributed to the requesting administrator
+func handleKeyEscrowExport(encryption *security.EncryptionManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator, _ := middleware.GetUserID(r.Context())
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		bundle, err := encryption.ExportKeyEscrow(r.Context(), operator)
+		if errors.Is(err, security.ErrKeyEscrowDisabled) || errors.Is(err, security.ErrEscrowKeyNotConfigured) {
+			httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Key escrow is not configured")
+			return
+		}
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Key escrow export failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(bundle)
 	}
 }
 
-// metricsHandler handles metrics requests
-func metricsHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(http.StatusOK)
+// keyEscrowImportRequest carries an escrow bundle and the escrow private key, in PEM, that opens it
+type keyEscrowImportRequest struct {
+	Bundle     *security.KeyEscrowBundle `json:"bundle"`
+	PrivateKey string                    `json:"private_key"`
+}
 
-	// Basic metrics - in production this would integrate with Prometheus
-	metrics := `# HELP trading_bots_total Total number of trading bots
-# TYPE trading_bots_total gauge
-trading_bots_total 7
+// handleKeyEscrowImport restores the keys of an escrow bundle after a loss of the key store,
+// attributed to the requesting administrator. The private key is used for this request only
+// and never stored.
+func handleKeyEscrowImport(encryption *security.EncryptionManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator, _ := middleware.GetUserID(r.Context())
 
-# HELP trading_bots_active Number of active trading bots
-# TYPE trading_bots_active gauge
-trading_bots_active 0
+		var req keyEscrowImportRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+		if req.Bundle == nil || req.PrivateKey == "" {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "bundle and private_key are required")
+			return
+		}
 
-# HELP trading_bots_errors_total Total number of trading bot errors
-# TYPE trading_bots_errors_total counter
-trading_bots_errors_total 0
+		manifest, err := encryption.ImportKeyEscrow(r.Context(), req.Bundle, []byte(req.PrivateKey), operator)
+		switch {
+		case errors.Is(err, security.ErrKeyEscrowDisabled):
+			httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Key escrow is not configured")
+		case errors.Is(err, security.ErrInvalidEscrowKey), errors.Is(err, security.ErrEscrowIntegrity):
+			httputil.WriteError(w, r, http.StatusUnprocessableEntity, httputil.CodeUnprocessable, err.Error())
+		case errors.Is(err, security.ErrEscrowKeyConflict):
+			httputil.WriteError(w, r, http.StatusConflict, httputil.CodeConflict, err.Error())
+		case err != nil:
+			httputil.InternalError(w, r, logger, "Key escrow import failed", err)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(manifest)
+		}
+	}
+}
+
+// newSecurityAudit creates this service's audit log, archived in its own subdirectory of
+// AUDIT_ARCHIVE_DIR
+func newSecurityAudit(cfg appconfig.SecurityConfig, logger *observability.Logger) (*security.AuditManager, error) {
+	archiveDir := ""
+	if cfg.AuditArchiveDir != "" {
+		archiveDir = filepath.Join(cfg.AuditArchiveDir, "trading-bots")
+	}
+	return security.NewServiceAuditManager(logger, cfg, archiveDir)
+}
 
-# HELP trading_bots_trades_total Total number of trades executed
-# TYPE trading_bots_trades_total counter
-trading_bots_trades_total 0
-`
+// registerBotMetrics exports bot counts and order totals read from the bot engine at scrape time
+func registerBotMetrics(exporter *observability.PrometheusExporter, botEngine *trading.TradingBotEngine) error {
+	orderTotal := func(count func(trading.BotExecutionStats) int) func() float64 {
+		return func() float64 {
+			total := 0
+			for _, bot := range botEngine.ListBots() {
+				total += count(bot.ExecutionStats())
+			}
+			return float64(total)
+		}
+	}
 
-	w.Write([]byte(metrics))
+	metrics := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "trading_bots_total",
+			Help: "Total number of trading bots",
+		}, func() float64 {
+			return float64(len(botEngine.ListBots()))
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "trading_bots_active",
+			Help: "Number of active trading bots",
+		}, func() float64 {
+			active := 0
+			for _, bot := range botEngine.ListBots() {
+				if bot.State == trading.StateRunning {
+					active++
+				}
+			}
+			return float64(active)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "trading_bots_errors_total",
+			Help: "Total number of failed trading bot orders",
+		}, orderTotal(func(stats trading.BotExecutionStats) int { return stats.OrdersFailed })),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "trading_bots_trades_total",
+			Help: "Total number of trades executed",
+		}, orderTotal(func(stats trading.BotExecutionStats) int { return stats.OrdersFilled })),
+	}
+
+	for _, metric := range metrics {
+		if err := exporter.RegisterCollector(metric); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Additional helper functions and middleware can be added here
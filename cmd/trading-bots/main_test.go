@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appconfig "github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleKeyEscrowImport(t *testing.T) {
+	ctx := context.Background()
+	logger := observability.NewLogger(appconfig.ObservabilityConfig{LogLevel: "error"})
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	privatePEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+
+	newEncryption := func(escrow bool) *security.EncryptionManager {
+		return security.NewEncryptionManager(logger, &security.EncryptionConfig{Algorithm: "AES-256-GCM", EnableKeyEscrow: escrow})
+	}
+	source := newEncryption(true)
+	require.NoError(t, source.SetEscrowPublicKey(publicPEM))
+	_, err = source.ImportKey("exchange_credentials", bytes.Repeat([]byte{0x01}, 32))
+	require.NoError(t, err)
+	bundle, err := source.ExportKeyEscrow(ctx, "admin-1")
+	require.NoError(t, err)
+
+	audit, err := security.NewServiceAuditManager(logger, appconfig.SecurityConfig{}, "")
+	require.NoError(t, err)
+	recovered := newEncryption(true)
+	recovered.SetAuditor(audit)
+
+	importBundle := func(encryption *security.EncryptionManager, body interface{}) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/admin/security/keys/escrow-import", bytes.NewReader(data))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "admin-2"))
+		rec := httptest.NewRecorder()
+		handleKeyEscrowImport(encryption, logger).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("missing fields", func(t *testing.T) {
+		rec := importBundle(recovered, keyEscrowImportRequest{Bundle: bundle})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("escrow disabled", func(t *testing.T) {
+		rec := importBundle(newEncryption(false), keyEscrowImportRequest{Bundle: bundle, PrivateKey: privatePEM})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("tampered bundle", func(t *testing.T) {
+		tampered := *bundle
+		tampered.Ciphertext = append([]byte(nil), bundle.Ciphertext...)
+		tampered.Ciphertext[0] ^= 0xff
+		rec := importBundle(recovered, keyEscrowImportRequest{Bundle: &tampered, PrivateKey: privatePEM})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("recovers keys", func(t *testing.T) {
+		rec := importBundle(recovered, keyEscrowImportRequest{Bundle: bundle, PrivateKey: privatePEM})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var manifest security.KeyEscrowManifest
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&manifest))
+		assert.Equal(t, bundle.Manifest.BundleID, manifest.BundleID)
+		assert.Len(t, manifest.Keys, 1)
+	})
+
+	// Failed and successful imports land in the shared audit log with the administrator's identity
+	events, err := audit.GetAuditEvents(ctx, security.AuditEventFilter{Resource: "encryption_keys"})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, "key_escrow_import", event.Action)
+		assert.Equal(t, "admin-2", event.Details["operator"])
+	}
+	assert.Equal(t, security.AuditResultFailure, events[0].Result)
+	assert.Equal(t, security.AuditResultSuccess, events[1].Result)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// HandleListApprovals lists the outstanding token approvals of the wallet in the wallet_id
+// query parameter; refresh=true rescans the chain instead of using the cached scan
+func HandleListApprovals(approvals *web3.ApprovalManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		walletID, err := uuid.Parse(r.URL.Query().Get("wallet_id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid wallet ID", http.StatusBadRequest)
+			return
+		}
+
+		resp, err := approvals.ListApprovals(r.Context(), userID, walletID, r.URL.Query().Get("refresh") == "true")
+		if err != nil {
+			if errors.Is(err, web3.ErrWalletNotFound) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+				return
+			}
+			httputil.InternalError(w, r, logger, "Approval scan failed", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// HandleRevokeApproval builds the transaction resetting an allowance to zero for the user to
+// sign in their wallet
+func HandleRevokeApproval(approvals *web3.ApprovalManager, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.RevokeApprovalRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		resp, err := approvals.BuildRevoke(r.Context(), userID, req)
+		if err != nil {
+			if writeWatchOnlyError(w, r, err) {
+				return
+			}
+			if errors.Is(err, web3.ErrWalletNotFound) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to build approval revocation", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/google/uuid"
+)
+
+// writeBridgeError maps bridge errors to responses, reporting whether it wrote one
+func writeBridgeError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var fieldErrors validation.FieldErrors
+	switch {
+	case errors.As(err, &fieldErrors):
+		httputil.WriteRequestError(w, r, err)
+	case errors.Is(err, web3.ErrWalletNotFound), errors.Is(err, web3.ErrBridgeRouteNotFound),
+		errors.Is(err, web3.ErrBridgeTransferNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, web3.ErrNoBridgeRoutes):
+		httputil.Error(w, r, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		return writeWatchOnlyError(w, r, err) || WriteScreeningError(w, r, err)
+	}
+	return true
+}
+
+// HandleBridgeQuote compares the routes moving an amount of a token from the wallet's chain to
+// another, with their fees, estimated time and output
+func HandleBridgeQuote(bridges *web3.BridgeManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		query := r.URL.Query()
+		req := web3.BridgeQuoteRequest{
+			FromToken: query.Get("from_token"),
+			ToToken:   query.Get("to_token"),
+			ToAddress: query.Get("to_address"),
+		}
+		if req.WalletID, err = uuid.Parse(query.Get("wallet_id")); err != nil {
+			httputil.Error(w, r, "Invalid wallet ID", http.StatusBadRequest)
+			return
+		}
+		if req.ToChainID, err = strconv.Atoi(query.Get("to_chain_id")); err != nil {
+			httputil.Error(w, r, "Invalid to_chain_id", http.StatusBadRequest)
+			return
+		}
+		if amount, ok := new(big.Int).SetString(query.Get("amount"), 10); ok {
+			req.Amount = amount
+		}
+		if slippage := query.Get("slippage_bps"); slippage != "" {
+			if req.SlippageBps, err = strconv.Atoi(slippage); err != nil {
+				httputil.Error(w, r, "Invalid slippage_bps", http.StatusBadRequest)
+				return
+			}
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		quote, err := bridges.Quote(r.Context(), userID, req)
+		if err != nil {
+			if writeBridgeError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to quote bridge routes", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(quote)
+	}
+}
+
+// HandleBridgeExecute starts a transfer along a quoted route and returns the transactions the
+// wallet signs for it
+func HandleBridgeExecute(bridges *web3.BridgeManager, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.BridgeExecuteRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		execution, err := bridges.Execute(r.Context(), userID, req)
+		if err != nil {
+			if writeBridgeError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to execute bridge route", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(execution)
+	}
+}
+
+// HandleListBridgeTransfers lists the user's bridge transfers, newest first
+func HandleListBridgeTransfers(bridges *web3.BridgeManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		transfers, err := bridges.ListTransfers(r.Context(), userID)
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Failed to list bridge transfers", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"transfers": transfers,
+			"count":     len(transfers),
+		})
+	}
+}
+
+// HandleGetBridgeTransfer returns a bridge transfer, polling its progress while it is active
+func HandleGetBridgeTransfer(bridges *web3.BridgeManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid transfer ID", http.StatusBadRequest)
+			return
+		}
+
+		transfer, err := bridges.GetTransfer(r.Context(), userID, id)
+		if err != nil {
+			if writeBridgeError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to get bridge transfer", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transfer)
+	}
+}
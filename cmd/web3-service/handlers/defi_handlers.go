@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
@@ -15,23 +16,25 @@ func HandleDeFiInteraction(web3Service *web3.Service, logger *observability.Logg
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 		var req web3.DeFiProtocolRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 		resp, err := web3Service.InteractWithDeFiProtocol(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "DeFi interaction failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writeWatchOnlyError(w, r, err) || writeNetworkError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "DeFi interaction failed", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -52,19 +55,10 @@ func HandleGetProtocol(defiManager *web3.DeFiProtocolManager, logger *observabil
 		protocolID := strings.TrimPrefix(r.URL.Path, "/web3/defi/protocols/")
 		protocol, err := defiManager.GetProtocol(protocolID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(protocol)
 	}
 }
-
-func HandleGetYieldOpportunities(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// This project doesn't currently expose a method; return protocols as placeholder for opportunities
-		protocols := defiManager.GetProtocols()
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]any{"protocols": protocols})
-	}
-}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/google/uuid"
+)
+
+// writeMultisigError maps multisig errors to responses, reporting whether it wrote one
+func writeMultisigError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, web3.ErrPortfolioNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, "Portfolio not found")
+	case errors.Is(err, web3.ErrMultisigPolicyNotFound), errors.Is(err, web3.ErrProposalNotFound),
+		errors.Is(err, web3.ErrWalletNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, web3.ErrNotProposalMember):
+		httputil.Error(w, r, err.Error(), http.StatusForbidden)
+	case errors.Is(err, web3.ErrProposalClosed), errors.Is(err, web3.ErrAlreadyVoted),
+		errors.Is(err, web3.ErrProposalConflict):
+		httputil.Error(w, r, err.Error(), http.StatusConflict)
+	case errors.Is(err, web3.ErrInvalidSafeSignature), errors.Is(err, web3.ErrNotSafe),
+		errors.Is(err, web3.ErrUnresolvedRecipient):
+		httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, web3.ErrSafeUnavailable), errors.Is(err, web3.ErrENSUnavailable):
+		httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+	default:
+		return writeWatchOnlyError(w, r, err) || WriteScreeningError(w, r, err)
+	}
+	return true
+}
+
+// HandleSetMultisigPolicy makes the portfolio's treasury wallet require the approval of
+// threshold members; only the portfolio owner may change it
+func HandleSetMultisigPolicy(coordinator *web3.MultisigCoordinator, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		portfolioID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.MultisigPolicyRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		policy, err := coordinator.SetPolicy(r.Context(), userID, portfolioID, req)
+		if err != nil {
+			var fieldErrors validation.FieldErrors
+			if errors.As(err, &fieldErrors) {
+				httputil.WriteRequestError(w, r, err)
+				return
+			}
+			if writeMultisigError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to set multisig policy", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+// HandleGetMultisigPolicy returns the portfolio's multisig policy to its members
+func HandleGetMultisigPolicy(coordinator *web3.MultisigCoordinator, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		portfolioID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		policy, err := coordinator.GetPolicy(r.Context(), userID, portfolioID)
+		if err != nil {
+			if writeMultisigError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to get multisig policy", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+// HandleCreateProposal proposes a transaction from a portfolio's multisig wallet
+func HandleCreateProposal(coordinator *web3.MultisigCoordinator, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.ProposalRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		proposal, err := coordinator.Propose(r.Context(), userID, req)
+		if err != nil {
+			if writeMultisigError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to create proposal", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(proposal)
+	}
+}
+
+// HandleListProposals lists the proposals of the user's multisigs, newest first, optionally
+// filtered by the portfolio_id and status query parameters
+func HandleListProposals(coordinator *web3.MultisigCoordinator, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		var filter web3.ProposalFilter
+		if portfolioID := r.URL.Query().Get("portfolio_id"); portfolioID != "" {
+			if filter.PortfolioID, err = uuid.Parse(portfolioID); err != nil {
+				httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+				return
+			}
+		}
+		filter.Status = web3.ProposalStatus(r.URL.Query().Get("status"))
+		switch filter.Status {
+		case "", web3.ProposalPending, web3.ProposalExecuted, web3.ProposalRejected, web3.ProposalExpired, web3.ProposalFailed:
+		default:
+			httputil.Error(w, r, "Invalid status", http.StatusBadRequest)
+			return
+		}
+
+		proposals, err := coordinator.ListProposals(r.Context(), userID, filter)
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Failed to list proposals", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"proposals": proposals,
+			"count":     len(proposals),
+		})
+	}
+}
+
+// HandleGetProposal returns a proposal with its votes to the members of its multisig
+func HandleGetProposal(coordinator *web3.MultisigCoordinator, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid proposal ID", http.StatusBadRequest)
+			return
+		}
+
+		proposal, err := coordinator.GetProposal(r.Context(), userID, id)
+		if err != nil {
+			if writeMultisigError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to get proposal", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(proposal)
+	}
+}
+
+// HandleApproveProposal records the user's approval; the approval reaching the threshold
+// executes the proposal
+func HandleApproveProposal(coordinator *web3.MultisigCoordinator, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return handleProposalVote(coordinator.Approve, decoder, logger)
+}
+
+// HandleRejectProposal records the user's rejection
+func HandleRejectProposal(coordinator *web3.MultisigCoordinator, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return handleProposalVote(coordinator.Reject, decoder, logger)
+}
+
+type proposalVoteFunc func(ctx context.Context, userID, id uuid.UUID, req web3.ProposalVoteRequest) (*web3.TransactionProposal, error)
+
+func handleProposalVote(vote proposalVoteFunc, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid proposal ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.ProposalVoteRequest
+		if r.ContentLength != 0 {
+			if err := decoder.Decode(r, &req); err != nil {
+				httputil.WriteRequestError(w, r, err)
+				return
+			}
+		}
+
+		proposal, err := vote(r.Context(), userID, id, req)
+		if err != nil {
+			if writeMultisigError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to record vote", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(proposal)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// HandleScreenAddress screens an address before the user sends anything to it
+func HandleScreenAddress(screener *web3.TransactionScreener, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chainID, err := strconv.Atoi(r.PathValue("chain_id"))
+		if err != nil || chainID <= 0 {
+			httputil.Error(w, r, "Invalid chain ID", http.StatusBadRequest)
+			return
+		}
+		address := r.PathValue("address")
+		if !common.IsHexAddress(address) {
+			httputil.Error(w, r, "Invalid address", http.StatusBadRequest)
+			return
+		}
+
+		screening, err := screener.ScreenAddress(r.Context(), chainID, address)
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Address screening failed", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(screening)
+	}
+}
+
+// HandleListScreeningEntries lists the operator's deny and allow list entries
+func HandleListScreeningEntries(screener *web3.TransactionScreener, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries, err := screener.ListEntries(r.Context())
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Failed to list screening entries", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries": entries,
+			"count":   len(entries),
+		})
+	}
+}
+
+// HandleAddScreeningEntry puts an address on the deny or allow list
+func HandleAddScreeningEntry(screener *web3.TransactionScreener, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		var req web3.ScreeningListEntry
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		entry, err := screener.AddListEntry(r.Context(), req, operator)
+		switch {
+		case errors.Is(err, web3.ErrInvalidScreeningEntry):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			httputil.InternalError(w, r, logger, "Failed to add screening entry", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(entry)
+	}
+}
+
+// HandleRemoveScreeningEntry takes an address off the lists of a chain, chain 0 being the
+// entries that apply to every chain
+func HandleRemoveScreeningEntry(screener *web3.TransactionScreener, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		chainID, err := strconv.Atoi(r.PathValue("chain_id"))
+		if err != nil || chainID < 0 {
+			httputil.Error(w, r, "Invalid chain ID", http.StatusBadRequest)
+			return
+		}
+
+		err = screener.RemoveListEntry(r.Context(), chainID, r.PathValue("address"), operator)
+		switch {
+		case errors.Is(err, web3.ErrInvalidScreeningEntry):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, web3.ErrScreeningEntryNotFound):
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			httputil.InternalError(w, r, logger, "Failed to remove screening entry", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// writeSigningRequestError maps signing queue errors to responses, reporting whether it wrote one
+func writeSigningRequestError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, web3.ErrSigningRequestNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, web3.ErrSigningRequestClosed):
+		httputil.Error(w, r, err.Error(), http.StatusConflict)
+	case errors.Is(err, web3.ErrInvalidSignedTransaction):
+		httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, web3.ErrBroadcastFailed):
+		httputil.Error(w, r, err.Error(), http.StatusBadGateway)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleListSigningRequests lists the user's signing requests, newest first, optionally
+// filtered by the status query parameter
+func HandleListSigningRequests(queue *web3.SigningQueue, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		status := web3.SigningRequestStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "", web3.SigningRequestPending, web3.SigningRequestBroadcast, web3.SigningRequestRejected,
+			web3.SigningRequestCancelled, web3.SigningRequestExpired:
+		default:
+			httputil.Error(w, r, "Invalid status", http.StatusBadRequest)
+			return
+		}
+
+		requests, err := queue.List(r.Context(), userID, status)
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Failed to list signing requests", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"signing_requests": requests,
+			"count":            len(requests),
+		})
+	}
+}
+
+// HandleGetSigningRequest returns one of the user's signing requests with its unsigned transaction
+func HandleGetSigningRequest(queue *web3.SigningQueue, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid signing request ID", http.StatusBadRequest)
+			return
+		}
+
+		request, err := queue.Get(r.Context(), userID, id)
+		if err != nil {
+			if writeSigningRequestError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to get signing request", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(request)
+	}
+}
+
+// HandleSubmitSignature broadcasts the signed transaction the user's wallet returned
+func HandleSubmitSignature(queue *web3.SigningQueue, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid signing request ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.SubmitSignatureRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		request, err := queue.Submit(r.Context(), userID, id, req.SignedTransaction)
+		if err != nil {
+			if writeSigningRequestError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to broadcast signed transaction", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(request)
+	}
+}
+
+// HandleCancelSigningRequest withdraws a pending signing request
+func HandleCancelSigningRequest(queue *web3.SigningQueue, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid signing request ID", http.StatusBadRequest)
+			return
+		}
+
+		request, err := queue.Cancel(r.Context(), userID, id)
+		if err != nil {
+			if writeSigningRequestError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to cancel signing request", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(request)
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+// HandleGetToken returns the metadata of a token contract, flagging tokens that aren't on the
+// curated list as unverified
+func HandleGetToken(tokens *web3.TokenRegistry, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chainID, err := strconv.Atoi(r.PathValue("chain_id"))
+		if err != nil || chainID <= 0 {
+			httputil.Error(w, r, "Invalid chain ID", http.StatusBadRequest)
+			return
+		}
+
+		token, err := tokens.Resolve(r.Context(), chainID, r.PathValue("address"))
+		if err != nil {
+			switch {
+			case errors.Is(err, web3.ErrInvalidTokenAddress), errors.Is(err, web3.ErrUnsupportedTokenChain):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, web3.ErrTokenNotFound):
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			default:
+				httputil.InternalError(w, r, logger, "Token lookup failed", err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(token)
+	}
+}
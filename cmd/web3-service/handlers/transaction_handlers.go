@@ -2,41 +2,62 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 )
 
-func HandleCreateTransaction(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
+func HandleCreateTransaction(web3Service *web3.Service, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 		var req web3.TransactionRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
 			return
 		}
 		resp, err := web3Service.CreateTransaction(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "Transaction creation failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writeWatchOnlyError(w, r, err) || writeNetworkError(w, r, err) || WriteScreeningError(w, r, err) {
+				return
+			}
+			if errors.Is(err, web3.ErrInvalidFeeTier) || errors.Is(err, web3.ErrUnresolvedRecipient) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, web3.ErrENSUnavailable) {
+				httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			httputil.InternalError(w, r, logger, "Transaction creation failed", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
+		// Transactions of multisig wallets are built once the members approve the proposal
+		if resp.Proposal != nil {
+			w.WriteHeader(http.StatusAccepted)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
 		json.NewEncoder(w).Encode(resp)
 	}
 }
@@ -45,12 +66,12 @@ func HandleListTransactions(web3Service *web3.Service, logger *observability.Log
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 		filter := web3.TransactionListFilter{}
@@ -60,6 +81,7 @@ func HandleListTransactions(web3Service *web3.Service, logger *observability.Log
 		if status := r.URL.Query().Get("status"); status != "" {
 			filter.Status = status
 		}
+		filter.Network = r.URL.Query().Get("network")
 		if page := r.URL.Query().Get("page"); page != "" {
 			if v, err := strconv.Atoi(page); err == nil { filter.Page = v }
 		}
@@ -68,8 +90,10 @@ func HandleListTransactions(web3Service *web3.Service, logger *observability.Log
 		}
 		transactions, pagination, err := web3Service.ListTransactions(r.Context(), userID, filter)
 		if err != nil {
-			logger.Error(r.Context(), "List transactions failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writeNetworkError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "List transactions failed", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -94,7 +118,7 @@ func HandleGetPrices(web3Service *web3.Service, logger *observability.Logger) ht
 		resp, err := web3Service.GetPrices(r.Context(), req)
 		if err != nil {
 			logger.Error(r.Context(), "Price retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -102,3 +126,35 @@ func HandleGetPrices(web3Service *web3.Service, logger *observability.Logger) ht
 	}
 }
 
+
+// screeningErrorResponse is the body of screening rejections, which carry the screening so
+// clients can show the user what was found
+type screeningErrorResponse struct {
+	Error     httputil.ErrorDetail       `json:"error"`
+	Screening *web3.TransactionScreening `json:"screening"`
+}
+
+// WriteScreeningError rejects transactions blocked by security screening with a 403 and the
+// TRANSACTION_BLOCKED error code, and risky transactions sent without acknowledging the risk
+// with a 422 and the RISK_ACKNOWLEDGEMENT_REQUIRED error code, reporting whether err was one
+func WriteScreeningError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var screeningErr *web3.ScreeningError
+	if !errors.As(err, &screeningErr) {
+		return false
+	}
+	status, code := http.StatusUnprocessableEntity, httputil.ErrorCode(web3.ErrCodeRiskNotAcknowledged)
+	if errors.Is(err, web3.ErrTransactionBlocked) {
+		status, code = http.StatusForbidden, httputil.ErrorCode(web3.ErrCodeTransactionBlocked)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(screeningErrorResponse{
+		Error: httputil.ErrorDetail{
+			Code:      code,
+			Message:   screeningErr.Error(),
+			RequestID: httputil.RequestID(r),
+		},
+		Screening: screeningErr.Screening,
+	})
+	return true
+}
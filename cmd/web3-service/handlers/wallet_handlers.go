@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
@@ -15,23 +17,25 @@ func HandleConnectWallet(web3Service *web3.Service, logger *observability.Logger
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 		var req web3.WalletConnectRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 		resp, err := web3Service.ConnectWallet(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "Wallet connect failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writeNetworkError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Wallet connect failed", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -43,12 +47,12 @@ func HandleListWallets(web3Service *web3.Service, logger *observability.Logger)
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 		filter := web3.WalletListFilter{}
@@ -66,20 +70,33 @@ func HandleListWallets(web3Service *web3.Service, logger *observability.Logger)
 				filter.IsPrimary = &b
 			}
 		}
+		if watchOnly := r.URL.Query().Get("watch_only"); watchOnly == "true" || watchOnly == "false" {
+			b := watchOnly == "true"
+			filter.WatchOnly = &b
+		}
+		filter.Network = r.URL.Query().Get("network")
 		if page := r.URL.Query().Get("page"); page != "" {
 			if v, err := strconv.Atoi(page); err == nil { filter.Page = v }
 		}
 		if ps := r.URL.Query().Get("page_size"); ps != "" {
 			if v, err := strconv.Atoi(ps); err == nil { filter.PageSize = v }
 		}
-		wallets, pagination, err := web3Service.ListWallets(r.Context(), userID, filter)
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			if v, err := strconv.Atoi(limit); err == nil { filter.Limit = v }
+		}
+		if offset := r.URL.Query().Get("offset"); offset != "" {
+			if v, err := strconv.Atoi(offset); err == nil { filter.Offset = v }
+		}
+		resp, err := web3Service.ListWallets(r.Context(), userID, filter)
 		if err != nil {
-			logger.Error(r.Context(), "List wallets failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writeNetworkError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "List wallets failed", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]any{"wallets": wallets, "pagination": pagination})
+		json.NewEncoder(w).Encode(resp)
 	}
 }
 
@@ -87,23 +104,38 @@ func HandleGetBalance(web3Service *web3.Service, logger *observability.Logger) h
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 		var req web3.BalanceRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+		if req.Network == "" {
+			req.Network = r.URL.Query().Get("network")
+		}
+		if req.Aggregate {
+			resp, err := web3Service.GetAggregateBalance(r.Context(), userID)
+			if err != nil {
+				httputil.InternalError(w, r, logger, "Aggregate balance retrieval failed", err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
 			return
 		}
 		resp, err := web3Service.GetBalance(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "Balance retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writeNetworkError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Balance retrieval failed", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -111,3 +143,27 @@ func HandleGetBalance(web3Service *web3.Service, logger *observability.Logger) h
 	}
 }
 
+// writeWatchOnlyError rejects signing requests on watch-only wallets with a 403 and the
+// WATCH_ONLY_WALLET error code, reporting whether err was one
+func writeWatchOnlyError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if !errors.Is(err, web3.ErrWatchOnlyWallet) {
+		return false
+	}
+	httputil.WriteError(w, r, http.StatusForbidden, web3.ErrCodeWatchOnlyWallet, err.Error())
+	return true
+}
+
+// writeNetworkError rejects requests for an invalid network with a 400, and testnet requests
+// without a configured testnet RPC with a 400 and the TESTNET_UNAVAILABLE error code,
+// reporting whether err was one
+func writeNetworkError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if errors.Is(err, web3.ErrInvalidNetwork) {
+		httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+		return true
+	}
+	if !errors.Is(err, web3.ErrTestnetUnavailable) {
+		return false
+	}
+	httputil.WriteError(w, r, http.StatusBadRequest, web3.ErrCodeTestnetUnavailable, err.Error())
+	return true
+}
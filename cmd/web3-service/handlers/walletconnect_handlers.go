@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// HandleCreateWalletConnectPairing starts a WalletConnect session and returns the pairing URI
+// for the user to scan with their wallet app
+func HandleCreateWalletConnectPairing(manager *web3.WalletConnectManager, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.WalletConnectPairingRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		session, err := manager.CreatePairing(r.Context(), userID, req.ChainIDs)
+		if err != nil {
+			if errors.Is(err, web3.ErrWalletConnectChain) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to create WalletConnect pairing", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(session)
+	}
+}
+
+// HandleListWalletConnectSessions lists the user's WalletConnect sessions
+func HandleListWalletConnectSessions(manager *web3.WalletConnectManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		sessions, err := manager.ListSessions(r.Context(), userID)
+		if err != nil {
+			httputil.InternalError(w, r, logger, "Failed to list WalletConnect sessions", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sessions": sessions,
+			"count":    len(sessions),
+		})
+	}
+}
+
+// HandleDisconnectWalletConnectSession ends a WalletConnect session
+func HandleDisconnectWalletConnectSession(manager *web3.WalletConnectManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := manager.Disconnect(r.Context(), userID, r.PathValue("topic")); err != nil {
+			if errors.Is(err, web3.ErrWalletConnectSessionNotFound) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to disconnect WalletConnect session", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleWalletConnectMessage takes a message the relay delivered on one of the user's session
+// topics and returns the session as it stands afterwards; it is null once the session ends or
+// the user declines it
+func HandleWalletConnectMessage(manager *web3.WalletConnectManager, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		var req web3.WalletConnectMessageRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		session, err := manager.HandleMessage(r.Context(), userID, req.Topic, req.Message)
+		switch {
+		case errors.Is(err, web3.ErrWalletConnectSessionNotFound):
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, web3.ErrInvalidWalletConnectMessage):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			// Signatures the wallet app returned are submitted like posted ones
+			if writeSigningRequestError(w, r, err) {
+				return
+			}
+			httputil.InternalError(w, r, logger, "Failed to handle WalletConnect message", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"session": session})
+	}
+}
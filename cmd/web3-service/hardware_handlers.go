@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/observability"
 )
 
@@ -28,7 +29,7 @@ func handleDiscoverDevices(hwService *web3.HardwareWalletService, logger *observ
 
 		devices, err := hwService.DiscoverDevices(ctx)
 		if err != nil {
-			http.Error(w, "Failed to discover devices", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to discover devices", http.StatusInternalServerError)
 			return
 		}
 
@@ -115,7 +116,7 @@ func handleIntegrationStatus(checker *web3.IntegrationChecker, logger *observabi
 
 		status, err := checker.CheckIntegrationStatus(ctx)
 		if err != nil {
-			http.Error(w, "Failed to check integration status", http.StatusInternalServerError)
+			httputil.Error(w, r, "Failed to check integration status", http.StatusInternalServerError)
 			return
 		}
 
@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
@@ -17,14 +20,19 @@ import (
 	"github.com/ai-agentic-browser/internal/ai"
 	"github.com/ai-agentic-browser/internal/alerts"
 	"github.com/ai-agentic-browser/internal/analytics"
+	"github.com/ai-agentic-browser/internal/auth"
 	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/internal/monitoring"
 	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/internal/trading"
 	"github.com/ai-agentic-browser/internal/web3"
 	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shopspring/decimal"
 )
 
@@ -56,8 +64,23 @@ func main() {
 	}
 	defer redis.Close()
 
+	// Initialize performance monitoring with Prometheus collectors
+	perfMonitor := observability.NewPerformanceMonitor(logger)
+	defer perfMonitor.Stop()
+
+	promExporter := observability.NewPrometheusExporter("web3-service")
+	perfMonitor.SetPrometheusExporter(promExporter)
+	if err := promExporter.RegisterDB("postgres", db.DB); err != nil {
+		log.Fatalf("Failed to register database metrics: %v", err)
+	}
+
 	// Initialize Web3 service
 	web3Service := web3.NewService(db, redis, cfg.Web3, logger)
+	if cfg.Web3.AlchemyAPIKey != "" {
+		nftIndexer := web3.NewAlchemyNFTIndexer(cfg.Web3.AlchemyAPIKey)
+		web3Service.SetNFTIndexer(nftIndexer)
+		web3Service.SetNFTFloorPriceSource(nftIndexer)
+	}
 
 	// Initialize enhanced Web3 service with autonomous trading
 	enhancedService, err := web3.NewEnhancedService(db, redis, cfg.Web3, logger)
@@ -68,11 +91,60 @@ func main() {
 	// Initialize autonomous trading components
 	riskAssessment := web3.NewRiskAssessmentService(enhancedService.GetClients(), logger)
 	tradingEngine := web3.NewTradingEngine(enhancedService.GetClients(), logger, riskAssessment)
+
+	// Halt autonomous trading on stale market data, excessive daily losses or operator request.
+	// A tripped kill switch survives restarts until an administrator resets it.
+	tradingAudit, err := newTradingAudit(cfg.Security, logger)
+	if err != nil {
+		log.Fatalf("Failed to initialize trading audit log: %v", err)
+	}
+	killSwitchConfig := web3.KillSwitchConfig{
+		StaleDataAfter: cfg.Web3.KillSwitchStaleData,
+		MaxDailyLoss:   cfg.Web3.KillSwitchMaxDailyLoss,
+		FlattenOnTrip:  cfg.Web3.KillSwitchFlatten,
+		CheckInterval:  cfg.Web3.KillSwitchCheckInterval,
+	}
+	if err := tradingEngine.EnableKillSwitch(context.Background(), killSwitchConfig, web3.NewRedisKillSwitchStore(redis), tradingAudit); err != nil {
+		logger.Error(context.Background(), "Failed to restore kill switch state", err)
+	}
+
+	// Transactions to denied, sanctioned or suspicious addresses are blocked or need the
+	// user's acknowledgement; the lists and cached screenings are shared through Redis
+	screener := web3.NewTransactionScreener(logger, web3.TransactionScreeningConfig{
+		CacheTTL:       cfg.Web3.ScreeningCacheTTL,
+		NewContractAge: cfg.Web3.ScreeningNewContractAge,
+	}, web3.NewRedisScreeningStore(redis))
+	screener.SetAuditor(tradingAudit)
+	if cfg.Web3.ChainalysisAPIKey != "" {
+		screener.AddProvider(web3.NewChainalysisScreeningProvider(cfg.Web3.ChainalysisAPIKey))
+	}
+	if cfg.Web3.EtherscanAPIKey != "" {
+		screener.SetContractInfoSource(web3.NewEtherscanContractSource(cfg.Web3.EtherscanAPIKey))
+	}
+	web3Service.SetTransactionScreener(screener)
+	enhancedService.SetTransactionScreener(screener)
+
+	// Strategies don't open positions during blackouts, which the trading bots share through Redis
+	tradingCalendar := web3.NewTradingCalendar(logger, web3.NewRedisBlackoutStore(redis))
+	tradingEngine.SetTradingCalendar(tradingCalendar)
+
+	// Erasure requests pseudonymize the user's trading records, which must be kept
+	privacyManager := newPrivacyManager(cfg, db, logger)
+	privacyManager.RegisterDataOwner(security.NewPseudonymizingDataOwner(security.DataOwnerTradingHistory, tradingEngine.PseudonymizeUser))
+	privacyManager.RegisterDataOwner(security.NewPseudonymizingDataOwner(security.DataOwnerAuditLog, tradingAudit.PseudonymizeUser))
 	defiManager := web3.NewDeFiProtocolManager(logger)
+	defiManager.SetYieldSources(newYieldSources(cfg.Web3)...)
+	if err := registerYieldSourceMetrics(promExporter, defiManager); err != nil {
+		log.Fatalf("Failed to register DeFi yield source metrics: %v", err)
+	}
+	web3Service.SetDeFiManager(defiManager)
 	portfolioRebalancer := web3.NewPortfolioRebalancer(logger, tradingEngine, defiManager)
+	portfolioRebalancer.SetStrategyStore(web3.NewPostgresRebalanceStrategyStore(db))
 
 	// Initialize AI components
 	voiceInterface := ai.NewVoiceInterface(logger, tradingEngine, defiManager, riskAssessment)
+	voiceInterface.SetTTSProvider(ai.NewTTSProvider(cfg.AI))
+	voiceInterface.SetSpeechCache(ai.NewRedisSpeechCache(redis, cfg.AI.TTSCacheTTL))
 	conversationalAI := ai.NewConversationalAI(logger, tradingEngine, defiManager, riskAssessment)
 
 	// Initialize real-time monitoring components
@@ -82,7 +154,21 @@ func main() {
 				Name:     "binance",
 				WSUrl:    "wss://stream.binance.com:9443/ws",
 				Symbols:  []string{"BTCUSDT", "ETHUSDT", "ADAUSDT"},
-				Channels: []string{"ticker", "trade"},
+				Channels: []string{"ticker", "trade", "orderbook"},
+				Enabled:  true,
+			},
+			{
+				Name:     "coinbase",
+				WSUrl:    "wss://ws-feed.exchange.coinbase.com",
+				Symbols:  []string{"BTC-USD", "ETH-USD", "ADA-USD"},
+				Channels: []string{"ticker", "orderbook"},
+				Enabled:  true,
+			},
+			{
+				Name:     "kraken",
+				WSUrl:    "wss://ws.kraken.com/v2",
+				Symbols:  []string{"BTC/USD", "ETH/USD", "ADA/USD"},
+				Channels: []string{"ticker", "orderbook"},
 				Enabled:  true,
 			},
 		},
@@ -93,9 +179,13 @@ func main() {
 		EnableHeartbeat: true,
 	}
 	marketDataService := realtime.NewMarketDataService(logger, marketDataConfig)
+	candleService := realtime.NewCandleService(logger, marketDataService, realtime.NewPostgresCandleStore(db))
+	derivatives := realtime.NewDerivativesCollector(logger, realtime.NewPostgresDerivativesStore(db), newDerivativesConfig(cfg.Web3))
 
 	// Initialize portfolio analytics
 	portfolioAnalytics := analytics.NewPortfolioAnalytics(logger, tradingEngine)
+	portfolioAnalytics.SetSnapshotStore(analytics.NewPostgresSnapshotStore(db))
+	portfolioAnalytics.SetBenchmarkSource(candleService)
 
 	// Initialize system monitoring
 	monitoringConfig := monitoring.MonitoringConfig{
@@ -115,16 +205,76 @@ func main() {
 	systemMonitor := monitoring.NewSystemMonitor(logger, monitoringConfig)
 
 	// Initialize alert service
-	alertConfig := alerts.AlertConfig{
-		MaxHistorySize:  1000,
-		DefaultCooldown: 5 * time.Minute,
-		EnableEmail:     true,
-		EnableWebhook:   true,
-		EnableSlack:     true,
-		EnableTelegram:  false,
-		EnablePushNotif: true,
+	alertService := alerts.NewAlertService(logger, alerts.NewAlertConfig(cfg.Alerts))
+	alertService.SetPreferenceStore(alerts.NewPostgresNotificationStore(db))
+	priceAlerts := alerts.NewPriceAlertManager(logger, alerts.NewPostgresPriceAlertStore(db), alertService, marketDataService)
+
+	// Token approvals are rated with transaction screening, and risky ones alert their owner
+	approvalManager := web3.NewApprovalManager(web3Service, web3.NewRedisApprovalCache(redis), web3.ApprovalConfig{
+		CacheTTL:       cfg.Web3.ApprovalCacheTTL,
+		LookbackBlocks: uint64(cfg.Web3.ApprovalScanBlocks),
+		AlertMinRisk:   web3.ApprovalRisk(cfg.Web3.ApprovalAlertMinRisk),
+	})
+	approvalManager.SetScreener(screener)
+	approvalManager.SetAlertService(alertService)
+
+	// Transactions are signed in the user's wallet; pending signing requests are shared through
+	// Redis and pushed to the wallet app of the user's WalletConnect session when configured
+	signingQueue := web3.NewSigningQueue(web3Service, web3.NewRedisSigningRequestStore(redis), web3.SigningConfig{
+		RequestTTL: cfg.Web3.SigningRequestTTL,
+	})
+	signingQueue.SetAuditor(tradingAudit)
+	var walletConnect *web3.WalletConnectManager
+	if cfg.Web3.WalletConnectProjectID != "" {
+		relay, err := web3.NewHTTPWalletConnectRelay(cfg.Web3.WalletConnectRelayURL, cfg.Web3.WalletConnectProjectID)
+		if err != nil {
+			log.Fatalf("Failed to initialize WalletConnect relay: %v", err)
+		}
+		walletConnect = web3.NewWalletConnectManager(web3Service, web3.NewRedisWalletConnectStore(redis), relay, web3.WalletConnectConfig{
+			Metadata: web3.WalletConnectMetadata{
+				Name:        "AI Agentic Crypto Browser",
+				Description: "AI-powered crypto trading and DeFi browser",
+				URL:         cfg.Web3.WalletConnectAppURL,
+				Icons:       []string{},
+			},
+		})
+		signingQueue.SetWalletConnect(walletConnect)
 	}
-	alertService := alerts.NewAlertService(logger, alertConfig)
+	web3Service.SetSigningQueue(signingQueue)
+
+	// Transactions of shared portfolio treasuries need their members' approvals
+	multisig := web3.NewMultisigCoordinator(web3Service, tradingEngine, web3.NewPostgresMultisigStore(db), web3.MultisigConfig{
+		ProposalTTL: cfg.Web3.MultisigProposalTTL,
+	})
+	multisig.SetAuditor(tradingAudit)
+	multisig.SetSafeTransactionService(web3.NewHTTPSafeTransactionService(cfg.Web3.SafeAPIKey))
+	web3Service.SetMultisigCoordinator(multisig)
+	enhancedService.SetMultisigCoordinator(multisig)
+
+	// Cross-chain transfers are quoted across bridges and tracked until delivered
+	bridges := web3.NewBridgeManager(web3Service, web3.NewRedisBridgeRouteCache(redis), web3.NewPostgresBridgeTransferStore(db), web3.BridgeConfig{
+		QuoteTTL:     cfg.Web3.BridgeQuoteTTL,
+		PollInterval: cfg.Web3.BridgePollInterval,
+		StuckAfter:   cfg.Web3.BridgeStuckAfter,
+	}, web3.NewLiFiBridgeProvider(cfg.Web3.LiFiAPIKey))
+	bridges.SetAlertService(alertService)
+
+	// Let chat check balances and portfolio performance, create price alerts and analyze coins
+	conversationalAI.SetActionServices(ai.ChatActionServices{
+		Balances:    web3Service,
+		PriceAlerts: priceAlerts,
+		Coins:       ai.NewCryptoCoinAnalyzer(logger),
+	})
+
+	// Watch each portfolio's order sizes, fill slippage and drawdown, alerting on anomalies
+	anomalyDetector := analytics.NewAnomalyDetector(logger, &analytics.AnalyticsConfig{
+		AnomalyDetectionSensitivity: cfg.Web3.TradeAnomalySensitivity,
+	})
+	tradeAnomalies := analytics.NewTradeAnomalyMonitor(logger, anomalyDetector, alertService, analytics.TradeAnomalyConfig{
+		Sensitivity:    cfg.Web3.TradeAnomalySensitivity,
+		MaxSlippageBps: cfg.Web3.TradeAnomalyMaxSlippageBps,
+		MaxDrawdown:    cfg.Web3.TradeAnomalyMaxDrawdown,
+	})
 
 	// Initialize hardware wallet service
 	hwService := web3.NewHardwareWalletService(logger)
@@ -165,13 +315,79 @@ func main() {
 		}
 	}()
 
-	// Store components for use in handlers
-	_ = portfolioRebalancer // Will be used in handlers
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	portfolioAnalytics.StartSampler(workersCtx, cfg.Web3.SnapshotInterval)
+	if err := portfolioRebalancer.LoadStrategies(workersCtx); err != nil {
+		logger.Error(context.Background(), "Failed to load rebalance strategies", err)
+	}
+	portfolioRebalancer.StartScheduler(workersCtx, cfg.Web3.RebalanceCheckInterval)
+
+	// Alert owners of watch-only wallets on balance changes and outgoing transactions
+	walletWatcher := web3.NewWalletWatcher(web3Service, web3.NewPostgresWatchOnlyRepository(db), alertService, web3.WalletWatchConfig{
+		PollInterval:    cfg.Web3.WatchOnlyPollInterval,
+		ChangeThreshold: cfg.Web3.WatchOnlyChangeThreshold,
+	})
+	walletWatcher.Start(workersCtx)
+	signingQueue.Start(workersCtx)
+	multisig.Start(workersCtx)
+	bridges.Start(workersCtx)
+	derivatives.Start(workersCtx)
+
+	// Maintenance mode is enabled through the gateway; strategies stop opening positions while
+	// it lasts but keep closing them
+	maintenance := middleware.NewMaintenanceMode(redis, logger, cfg.Maintenance)
+	maintenance.OnChange(func(state middleware.MaintenanceState) {
+		if state.Enabled {
+			tradingEngine.HaltOpenings("maintenance: " + state.Reason)
+		} else {
+			tradingEngine.ResumeOpenings()
+		}
+	})
+	go maintenance.Run(workersCtx)
+
+	if len(defiManager.YieldSourceStats()) > 0 {
+		defiManager.StartYieldRefresher(workersCtx, cfg.Web3.DeFiRefreshInterval)
+	}
+
+	// Close positions whose stop-loss or take-profit is reached by live prices
+	go tradingEngine.MonitorPrices(workersCtx, marketDataService, marketDataConfig.Exchanges[0].Symbols)
+	tradingEngine.SetOrderBookSource(marketDataService)
+
+	// Price rebalance previews with venue fees and order book slippage
+	orderRouter := trading.NewSmartOrderRouter(logger)
+	orderRouter.SetOrderBookSource(marketDataService)
+	if err := orderRouter.Start(workersCtx); err != nil {
+		logger.Error(context.Background(), "Failed to start smart order router", err)
+	}
+	portfolioRebalancer.SetCostEstimator(trading.NewRebalanceCostEstimator(trading.NewPortfolioOptimizer(logger), &trading.RebalanceCostModel{Router: orderRouter}))
+	go tradingEngine.MonitorKillSwitch(workersCtx)
+	if err := anomalyDetector.Start(workersCtx); err != nil {
+		logger.Error(context.Background(), "Failed to start anomaly detector", err)
+	}
+	go tradingEngine.PublishTradeEvents(workersCtx, tradeAnomalies)
+	if err := tradingAudit.Start(workersCtx); err != nil {
+		logger.Error(context.Background(), "Failed to start trading audit log", err)
+	}
+	privacyManager.StartErasureWorker(workersCtx, cfg.Security.ErasureCheckInterval)
+
+	// Evaluate user price alerts against the live ticker stream
+	if err := priceAlerts.Start(workersCtx); err != nil {
+		logger.Warn(context.Background(), "Failed to load price alerts", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	// Roles required per route, from RBAC_ROUTE_POLICY over the defaults
+	routePolicy, err := middleware.NewRoutePolicy(cfg.Security.RoutePolicy)
+	if err != nil {
+		log.Fatalf("Failed to configure route policy: %v", err)
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, "8084"), // Web3 service port
-		Handler:      setupRoutes(web3Service, enhancedService, tradingEngine, defiManager, portfolioRebalancer, voiceInterface, conversationalAI, marketDataService, portfolioAnalytics, systemMonitor, alertService, hwService, integrationChecker, cfg, logger, db),
+		Handler:      setupRoutes(web3Service, enhancedService, screener, approvalManager, signingQueue, walletConnect, multisig, bridges, tradingEngine, tradingCalendar, defiManager, portfolioRebalancer, voiceInterface, conversationalAI, marketDataService, candleService, derivatives, portfolioAnalytics, systemMonitor, alertService, tradeAnomalies, tradingAudit, privacyManager, priceAlerts, hwService, integrationChecker, cfg, logger, db, perfMonitor, promExporter, middleware.NewIdempotencyMiddleware(redis, logger), newRateLimiter(redis, cfg, logger), middleware.NewTokenRevocationList(redis), middleware.NewAPIKeyAuthenticator(auth.NewAPIKeyStore(db), redis, logger, cfg.RateLimit), routePolicy, maintenance),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
@@ -193,6 +409,7 @@ func main() {
 	<-quit
 
 	logger.Info(context.Background(), "Shutting down Web3 service...")
+	stopWorkers()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -205,23 +422,161 @@ func main() {
 	logger.Info(context.Background(), "Web3 service stopped")
 }
 
+// newRateLimiter creates the per-user rate limiter, with the AI assistant endpoints in the
+// newTradingAudit creates the trading audit log, archiving events past the hot retention when
+// an archive directory is configured
+func newTradingAudit(cfg config.SecurityConfig, logger *observability.Logger) (*security.AuditManager, error) {
+	return security.NewServiceAuditManager(logger, cfg, cfg.AuditArchiveDir)
+}
+
+// newPrivacyManager creates the privacy manager processing this service's share of erasure
+// requests, which are shared with the other services through Postgres
+func newPrivacyManager(cfg *config.Config, db *database.DB, logger *observability.Logger) *security.PrivacyManager {
+	privacyManager := security.NewPrivacyManager(logger, &security.PrivacyConfig{
+		EnableGDPRCompliance: true,
+		EnableRightToErasure: true,
+	}, nil)
+	privacyManager.SetErasureStore(security.NewPostgresErasureStore(db), cfg.Security.ErasureDataOwners, []byte(cfg.Security.ErasureSigningKey))
+	return privacyManager
+}
+
+// ai-heavy class
+// newYieldSources creates the live DeFi yield sources configured with a subgraph URL
+func newYieldSources(cfg config.Web3Config) []web3.YieldSource {
+	var sources []web3.YieldSource
+	if cfg.AaveSubgraphURL != "" {
+		sources = append(sources, web3.NewLendingSubgraphSource("aave", cfg.AaveSubgraphURL, cfg.DeFiPoolsPerProtocol))
+	}
+	if cfg.CompoundSubgraphURL != "" {
+		sources = append(sources, web3.NewLendingSubgraphSource("compound", cfg.CompoundSubgraphURL, cfg.DeFiPoolsPerProtocol))
+	}
+	if cfg.UniswapV3SubgraphURL != "" {
+		sources = append(sources, web3.NewUniswapV3Source(cfg.UniswapV3SubgraphURL, cfg.DeFiPoolsPerProtocol))
+	}
+	return sources
+}
+
+// newDerivativesConfig configures the perpetual futures funding and open interest collector
+func newDerivativesConfig(cfg config.Web3Config) realtime.DerivativesConfig {
+	return realtime.DerivativesConfig{
+		Exchanges:      cfg.DerivativesExchanges,
+		Symbols:        cfg.DerivativesSymbols,
+		PollInterval:   cfg.DerivativesPollInterval,
+		OIChangeWindow: cfg.DerivativesOIChangeWindow,
+	}
+}
+
+// registerYieldSourceMetrics exports per-protocol fetch counts and data age read from the DeFi
+// manager at scrape time
+func registerYieldSourceMetrics(exporter *observability.PrometheusExporter, defiManager *web3.DeFiProtocolManager) error {
+	stat := func(protocolID string, value func(web3.YieldSourceStats) float64) func() float64 {
+		return func() float64 {
+			return value(defiManager.YieldSourceStats()[protocolID])
+		}
+	}
+
+	var metrics []prometheus.Collector
+	for protocolID := range defiManager.YieldSourceStats() {
+		labels := prometheus.Labels{"protocol": protocolID}
+		metrics = append(metrics,
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name:        "defi_yield_fetches_total",
+				Help:        "Total number of DeFi yield source fetches",
+				ConstLabels: labels,
+			}, stat(protocolID, func(stats web3.YieldSourceStats) float64 { return float64(stats.Fetches) })),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name:        "defi_yield_fetch_failures_total",
+				Help:        "Total number of failed DeFi yield source fetches",
+				ConstLabels: labels,
+			}, stat(protocolID, func(stats web3.YieldSourceStats) float64 { return float64(stats.Failures) })),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "defi_yield_fetch_duration_seconds",
+				Help:        "Duration of the latest DeFi yield source fetch",
+				ConstLabels: labels,
+			}, stat(protocolID, func(stats web3.YieldSourceStats) float64 { return stats.LastDuration })),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "defi_yield_pools",
+				Help:        "Number of pools fetched by the latest successful DeFi yield source fetch",
+				ConstLabels: labels,
+			}, stat(protocolID, func(stats web3.YieldSourceStats) float64 { return float64(stats.Pools) })),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "defi_yield_data_age_seconds",
+				Help:        "Seconds since the latest successful DeFi yield source fetch, -1 before the first",
+				ConstLabels: labels,
+			}, stat(protocolID, func(stats web3.YieldSourceStats) float64 {
+				if stats.LastSuccess == nil {
+					return -1
+				}
+				return time.Since(*stats.LastSuccess).Seconds()
+			})),
+		)
+	}
+
+	for _, metric := range metrics {
+		if err := exporter.RegisterCollector(metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRateLimiter(redis *database.RedisClient, cfg *config.Config, logger *observability.Logger) *middleware.RateLimiter {
+	rateLimiter := middleware.NewRateLimiter(redis, logger, cfg.RateLimit, cfg.JWT.Secret)
+	rateLimiter.SetKeyPrefix("ratelimit:web3-service:")
+	rateLimiter.SetRouteClass(middleware.RateLimitClassAIHeavy,
+		"POST /web3/ai/",
+		"GET /web3/ai/market/analysis",
+	)
+	return rateLimiter
+}
+
+// web3APIKeyScope returns the scope an API key needs: ai:invoke for the AI assistant routes,
+// trading:read or trading:write for everything else
+func web3APIKeyScope(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/web3/ai/") {
+		return middleware.ScopeAIInvoke
+	}
+	return middleware.MethodScope(middleware.ScopeTradingRead, middleware.ScopeTradingWrite)(r)
+}
+
 func setupRoutes(
 	web3Service *web3.Service,
 	enhancedService *web3.EnhancedService,
+	screener *web3.TransactionScreener,
+	approvals *web3.ApprovalManager,
+	signingQueue *web3.SigningQueue,
+	walletConnect *web3.WalletConnectManager,
+	multisig *web3.MultisigCoordinator,
+	bridges *web3.BridgeManager,
 	tradingEngine *web3.TradingEngine,
+	tradingCalendar *web3.TradingCalendar,
 	defiManager *web3.DeFiProtocolManager,
 	portfolioRebalancer *web3.PortfolioRebalancer,
 	voiceInterface *ai.VoiceInterface,
 	conversationalAI *ai.ConversationalAI,
 	marketDataService *realtime.MarketDataService,
+	candleService *realtime.CandleService,
+	derivatives *realtime.DerivativesCollector,
 	portfolioAnalytics *analytics.PortfolioAnalytics,
 	systemMonitor *monitoring.SystemMonitor,
 	alertService *alerts.AlertService,
+	tradeAnomalies *analytics.TradeAnomalyMonitor,
+	tradingAudit *security.AuditManager,
+	privacyManager *security.PrivacyManager,
+	priceAlerts *alerts.PriceAlertManager,
 	hwService *web3.HardwareWalletService,
 	integrationChecker *web3.IntegrationChecker,
 	cfg *config.Config,
 	logger *observability.Logger,
 	db *database.DB,
+	perfMonitor *observability.PerformanceMonitor,
+	promExporter *observability.PrometheusExporter,
+	idempotency *middleware.IdempotencyMiddleware,
+	rateLimiter *middleware.RateLimiter,
+	revocations *middleware.TokenRevocationList,
+	apiKeys *middleware.APIKeyAuthenticator,
+	routePolicy *middleware.RoutePolicy,
+	maintenance *middleware.MaintenanceMode,
 ) http.Handler {
 	mux := http.NewServeMux()
 
@@ -230,12 +585,19 @@ func setupRoutes(
 		middleware.Logging(logger)(
 			middleware.Tracing("web3-service")(
 				middleware.CORS(cfg.Security.CORSAllowedOrigins)(
-					middleware.RateLimit(cfg.RateLimit)(mux),
+					maintenance.Middleware()(
+						rateLimiter.Middleware()(
+							middleware.Metrics(perfMonitor)(mux),
+						),
+					),
 				),
 			),
 		),
 	)
 
+	// Prometheus scrape endpoint
+	mux.Handle("GET /metrics/prometheus", promExporter.Handler())
+
 	// Health check endpoint
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -243,45 +605,92 @@ func setupRoutes(
 
 		// Check database health
 		if err := db.Health(ctx); err != nil {
-			http.Error(w, "Database unhealthy", http.StatusServiceUnavailable)
+			httputil.Error(w, r, "Database unhealthy", http.StatusServiceUnavailable)
 			return
 		}
 
+		status := "healthy"
+		if maintenance.Enabled() {
+			status = middleware.HealthStatusMaintenance
+		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+		json.NewEncoder(w).Encode(map[string]string{"status": status})
 	})
 
 	// Protected Web3 endpoints
+	decoder := httputil.BodyDecoder{Strict: cfg.Server.StrictJSON}
 	protectedMux := http.NewServeMux()
 	protectedMux.HandleFunc("POST /web3/connect-wallet", handlers.HandleConnectWallet(web3Service, logger))
 	protectedMux.HandleFunc("GET /web3/wallets", handlers.HandleListWallets(web3Service, logger))
 	protectedMux.HandleFunc("GET /web3/balance", handlers.HandleGetBalance(web3Service, logger))
-	protectedMux.HandleFunc("POST /web3/transaction", handlers.HandleCreateTransaction(web3Service, logger))
+	protectedMux.HandleFunc("POST /web3/balance", handlers.HandleGetBalance(web3Service, logger))
+	protectedMux.Handle("POST /web3/transaction", idempotency.Middleware()(handlers.HandleCreateTransaction(web3Service, decoder, logger)))
 	protectedMux.HandleFunc("GET /web3/transactions", handlers.HandleListTransactions(web3Service, logger))
 	protectedMux.HandleFunc("GET /web3/prices", handlers.HandleGetPrices(web3Service, logger))
 	protectedMux.HandleFunc("POST /web3/defi/interact", handlers.HandleDeFiInteraction(web3Service, logger))
 	protectedMux.HandleFunc("GET /web3/defi/positions", handleListDeFiPositions(web3Service, logger))
+	protectedMux.HandleFunc("GET /web3/defi/positions/{id}/analytics", handleDeFiPositionAnalytics(web3Service, logger))
+	protectedMux.HandleFunc("POST /web3/addressbook", handleAddAddressBookEntry(web3Service, logger))
+	protectedMux.HandleFunc("GET /web3/addressbook", handleListAddressBook(web3Service, logger))
+	protectedMux.HandleFunc("DELETE /web3/addressbook/{id}", handleDeleteAddressBookEntry(web3Service, logger))
+	protectedMux.HandleFunc("GET /web3/nft/holdings", handleGetNFTHoldings(web3Service, logger))
+	protectedMux.HandleFunc("GET /web3/nft/collections/{address}", handleGetNFTCollection(web3Service, logger))
 	protectedMux.HandleFunc("GET /web3/chains", handleGetSupportedChains(web3Service, logger))
+	protectedMux.HandleFunc("GET /web3/fees", handleEstimateFees(web3Service, logger))
 
 	// Enhanced Web3 endpoints
-	protectedMux.HandleFunc("POST /web3/enhanced/transaction", handleEnhancedTransaction(enhancedService, logger))
+	protectedMux.Handle("POST /web3/enhanced/transaction", idempotency.Middleware()(handleEnhancedTransaction(enhancedService, logger)))
+	protectedMux.HandleFunc("POST /web3/enhanced/simulate", handleSimulateTransaction(enhancedService, logger))
+	protectedMux.HandleFunc("GET /web3/screening/{chain_id}/{address}", handlers.HandleScreenAddress(screener, logger))
+	protectedMux.HandleFunc("GET /web3/tokens/{chain_id}/{address}", handlers.HandleGetToken(web3Service.Tokens(), logger))
+	protectedMux.HandleFunc("GET /web3/approvals", handlers.HandleListApprovals(approvals, logger))
+	protectedMux.HandleFunc("POST /web3/approvals/revoke", handlers.HandleRevokeApproval(approvals, decoder, logger))
+	protectedMux.HandleFunc("GET /web3/signing-requests", handlers.HandleListSigningRequests(signingQueue, logger))
+	protectedMux.HandleFunc("GET /web3/signing-requests/{id}", handlers.HandleGetSigningRequest(signingQueue, logger))
+	protectedMux.HandleFunc("POST /web3/signing-requests/{id}/signature", handlers.HandleSubmitSignature(signingQueue, decoder, logger))
+	protectedMux.HandleFunc("POST /web3/signing-requests/{id}/cancel", handlers.HandleCancelSigningRequest(signingQueue, logger))
+	protectedMux.HandleFunc("PUT /web3/trading/portfolio/{id}/multisig", handlers.HandleSetMultisigPolicy(multisig, decoder, logger))
+	protectedMux.HandleFunc("GET /web3/trading/portfolio/{id}/multisig", handlers.HandleGetMultisigPolicy(multisig, logger))
+	protectedMux.HandleFunc("POST /web3/proposals", handlers.HandleCreateProposal(multisig, decoder, logger))
+	protectedMux.HandleFunc("GET /web3/proposals", handlers.HandleListProposals(multisig, logger))
+	protectedMux.HandleFunc("GET /web3/proposals/{id}", handlers.HandleGetProposal(multisig, logger))
+	protectedMux.HandleFunc("POST /web3/proposals/{id}/approve", handlers.HandleApproveProposal(multisig, decoder, logger))
+	protectedMux.HandleFunc("POST /web3/proposals/{id}/reject", handlers.HandleRejectProposal(multisig, decoder, logger))
+	protectedMux.HandleFunc("GET /web3/bridge/quote", handlers.HandleBridgeQuote(bridges, logger))
+	protectedMux.HandleFunc("POST /web3/bridge/execute", handlers.HandleBridgeExecute(bridges, decoder, logger))
+	protectedMux.HandleFunc("GET /web3/bridge/transfers", handlers.HandleListBridgeTransfers(bridges, logger))
+	protectedMux.HandleFunc("GET /web3/bridge/transfers/{id}", handlers.HandleGetBridgeTransfer(bridges, logger))
+	if walletConnect != nil {
+		protectedMux.HandleFunc("POST /web3/walletconnect/pairings", handlers.HandleCreateWalletConnectPairing(walletConnect, decoder, logger))
+		protectedMux.HandleFunc("GET /web3/walletconnect/sessions", handlers.HandleListWalletConnectSessions(walletConnect, logger))
+		protectedMux.HandleFunc("DELETE /web3/walletconnect/sessions/{topic}", handlers.HandleDisconnectWalletConnectSession(walletConnect, logger))
+		protectedMux.HandleFunc("POST /web3/walletconnect/messages", handlers.HandleWalletConnectMessage(walletConnect, decoder, logger))
+	}
 
 	// Autonomous Trading endpoints
-	protectedMux.HandleFunc("POST /web3/trading/portfolio", handleCreatePortfolio(tradingEngine, logger))
+	protectedMux.Handle("POST /web3/trading/portfolio", idempotency.Middleware()(handleCreatePortfolio(tradingEngine, decoder, logger)))
 	protectedMux.HandleFunc("GET /web3/trading/portfolio/{id}", handleGetPortfolio(tradingEngine, logger))
 	protectedMux.HandleFunc("POST /web3/trading/portfolio/{id}/start", handleStartTrading(tradingEngine, logger))
 	protectedMux.HandleFunc("POST /web3/trading/portfolio/{id}/stop", handleStopTrading(tradingEngine, logger))
+	protectedMux.HandleFunc("POST /web3/trading/portfolio/{id}/transfers", handleRecordTransfer(web3Service, tradingEngine, logger))
 	protectedMux.HandleFunc("GET /web3/trading/positions/{portfolio_id}", handleGetPositions(tradingEngine, logger))
 	protectedMux.HandleFunc("POST /web3/trading/positions/{id}/close", handleClosePosition(tradingEngine, logger))
+	protectedMux.HandleFunc("PUT /web3/trading/positions/{id}/protection", handleUpdatePositionProtection(tradingEngine, logger))
+	protectedMux.HandleFunc("GET /web3/trading/killswitch", handleKillSwitchStatus(tradingEngine))
+	protectedMux.HandleFunc("GET /web3/trading/blackouts", handleListBlackouts(tradingCalendar, logger))
+	protectedMux.HandleFunc("GET /web3/trading/strategies", handleListTradingStrategies(tradingEngine))
+	protectedMux.HandleFunc("GET /web3/trading/anomalies", handleGetTradeAnomalies(tradingEngine, tradeAnomalies))
 
 	// DeFi Protocol endpoints
 	protectedMux.HandleFunc("GET /web3/defi/protocols", handlers.HandleGetProtocols(defiManager, logger))
 	protectedMux.HandleFunc("GET /web3/defi/protocols/{id}", handlers.HandleGetProtocol(defiManager, logger))
-	protectedMux.HandleFunc("GET /web3/defi/opportunities", handlers.HandleGetYieldOpportunities(defiManager, logger))
+	protectedMux.HandleFunc("GET /web3/defi/opportunities", handleGetYieldOpportunities(defiManager, logger))
+	protectedMux.HandleFunc("GET /web3/defi/sources", handleGetYieldSources(defiManager))
 
 	// Portfolio Rebalancing endpoints
 	protectedMux.HandleFunc("POST /web3/rebalance/strategy", handleCreateRebalanceStrategy(portfolioRebalancer, logger))
 	protectedMux.HandleFunc("GET /web3/rebalance/strategy/{portfolio_id}", handleGetRebalanceStrategy(portfolioRebalancer, logger))
+	protectedMux.HandleFunc("PUT /web3/rebalance/strategy/{portfolio_id}/schedule", handleSetRebalanceSchedule(portfolioRebalancer, logger))
 	protectedMux.HandleFunc("POST /web3/rebalance/execute/{portfolio_id}", handleExecuteRebalancing(portfolioRebalancer, logger))
 
 	// AI Voice Interface endpoints
@@ -296,10 +705,18 @@ func setupRoutes(
 	// Real-time Market Data endpoints
 	protectedMux.HandleFunc("GET /web3/realtime/market/status", handleMarketDataStatus(marketDataService, logger))
 	protectedMux.HandleFunc("GET /web3/realtime/market/subscribe/{symbol}", handleMarketDataSubscribe(marketDataService, logger))
+	protectedMux.HandleFunc("GET /web3/realtime/market/orderbook/{symbol}", handleMarketOrderBook(marketDataService, logger))
+	protectedMux.HandleFunc("GET /web3/market/candles", handleMarketCandles(candleService, logger))
+	protectedMux.HandleFunc("GET /web3/market/funding/{symbol}", handleMarketFunding(derivatives, logger))
+	protectedMux.HandleFunc("GET /web3/market/openinterest/{symbol}", handleMarketOpenInterest(derivatives, logger))
 
 	// Portfolio Analytics endpoints
 	protectedMux.HandleFunc("GET /web3/analytics/portfolio/{portfolio_id}", handlePortfolioAnalytics(portfolioAnalytics, logger))
 	protectedMux.HandleFunc("GET /web3/analytics/portfolio/{portfolio_id}/performance", handlePortfolioPerformance(portfolioAnalytics, logger))
+	protectedMux.HandleFunc("GET /web3/analytics/portfolio/{portfolio_id}/history", handlePortfolioHistory(portfolioAnalytics, logger))
+	protectedMux.HandleFunc("GET /web3/analytics/portfolio/{portfolio_id}/benchmark", handlePortfolioBenchmark(portfolioAnalytics, logger))
+	protectedMux.HandleFunc("GET /web3/analytics/portfolio/{portfolio_id}/tax-report", handlePortfolioTaxReport(tradingEngine, portfolioAnalytics, logger))
+	protectedMux.HandleFunc("GET /web3/analytics/portfolio/{portfolio_id}/export", handlePortfolioExport(portfolioAnalytics, logger))
 	protectedMux.HandleFunc("GET /web3/analytics/portfolio/compare", handlePortfolioComparison(portfolioAnalytics, logger))
 
 	// System Monitoring endpoints
@@ -312,6 +729,9 @@ func setupRoutes(
 	protectedMux.HandleFunc("GET /web3/alerts/active", handleGetActiveAlerts(alertService, logger))
 	protectedMux.HandleFunc("POST /web3/alerts/{alert_id}/resolve", handleResolveAlert(alertService, logger))
 	protectedMux.HandleFunc("GET /web3/alerts/subscribe/{topic}", handleAlertSubscribe(alertService, logger))
+	protectedMux.HandleFunc("POST /web3/alerts/price", handleCreatePriceAlert(priceAlerts, logger))
+	protectedMux.HandleFunc("GET /web3/alerts/price", handleListPriceAlerts(priceAlerts, logger))
+	protectedMux.HandleFunc("DELETE /web3/alerts/price/{id}", handleDeletePriceAlert(priceAlerts, logger))
 
 	// Hardware Wallet endpoints
 	protectedMux.HandleFunc("GET /web3/hardware/devices", handleGetDevices(hwService, logger))
@@ -328,7 +748,30 @@ func setupRoutes(
 	protectedMux.HandleFunc("GET /web3/integration/summary", handleIntegrationSummary(integrationChecker, logger))
 
 	// Apply JWT middleware to protected routes
-	mux.Handle("/web3/", middleware.JWT(cfg.JWT.Secret)(protectedMux))
+	// Protected routes accept a JWT or an API key with the route's scope
+	// Kill switch changes, trading schedules, screening lists and audit exports are restricted
+	// to administrators
+	adminAuthorizer := middleware.NewAdminAuthorizer(cfg.JWT.Secret, revocations, cfg.Security.AdminUsers)
+	mux.Handle("POST /web3/trading/killswitch", adminAuthorizer.Middleware()(handleTripKillSwitch(tradingEngine, logger)))
+	mux.Handle("POST /web3/trading/killswitch/reset", adminAuthorizer.Middleware()(handleResetKillSwitch(tradingEngine, logger)))
+	mux.Handle("POST /web3/trading/blackouts", adminAuthorizer.Middleware()(handleCreateBlackout(tradingCalendar, logger)))
+	mux.Handle("DELETE /web3/trading/blackouts/{id}", adminAuthorizer.Middleware()(handleDeleteBlackout(tradingCalendar, logger)))
+	mux.Handle("PUT /web3/trading/strategies/{name}/schedule", adminAuthorizer.Middleware()(handleSetStrategySchedule(tradingEngine, logger)))
+	mux.Handle("GET /web3/screening/addresses", adminAuthorizer.Middleware()(handlers.HandleListScreeningEntries(screener, logger)))
+	mux.Handle("POST /web3/screening/addresses", adminAuthorizer.Middleware()(handlers.HandleAddScreeningEntry(screener, logger)))
+	mux.Handle("DELETE /web3/screening/addresses/{chain_id}/{address}", adminAuthorizer.Middleware()(handlers.HandleRemoveScreeningEntry(screener, logger)))
+	mux.Handle("GET /security/audit/export", adminAuthorizer.Middleware()(handleExportAuditLog(tradingAudit, logger)))
+
+	// Users may request erasure of their own data; administrators of anyone's
+	jwtAuth := middleware.JWTWithRevocation(cfg.JWT.Secret, revocations)
+	mux.Handle("DELETE /privacy/users/{id}/data", jwtAuth(handleRequestErasure(privacyManager, adminAuthorizer, logger)))
+	mux.Handle("GET /privacy/erasure-requests/{id}", jwtAuth(handleGetErasureRequest(privacyManager, adminAuthorizer, logger)))
+	// Users route their own alerts
+	mux.Handle("GET /users/me/notifications", jwtAuth(handleGetNotificationPreferences(alertService, logger)))
+	mux.Handle("PUT /users/me/notifications", jwtAuth(handleUpdateNotificationPreferences(alertService, logger)))
+	// Every protected route then needs the role the route policy sets for it
+	routePolicy.SetAdminAuthorizer(adminAuthorizer)
+	mux.Handle("/web3/", apiKeys.Middleware(middleware.JWTWithRevocation(cfg.JWT.Secret, revocations), web3APIKeyScope)(routePolicy.Middleware()(protectedMux)))
 
 	return handler
 }
@@ -337,26 +780,26 @@ func handleConnectWallet(web3Service *web3.Service, logger *observability.Logger
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req web3.WalletConnectRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		response, err := web3Service.ConnectWallet(r.Context(), userID, req)
 		if err != nil {
 			logger.Error(r.Context(), "Wallet connection failed", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
 			return
 		}
 
@@ -370,13 +813,13 @@ func handleListWallets(web3Service *web3.Service, logger *observability.Logger)
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
@@ -401,19 +844,25 @@ func handleListWallets(web3Service *web3.Service, logger *observability.Logger)
 				filter.PageSize = v
 			}
 		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			if v, err := strconv.Atoi(limit); err == nil {
+				filter.Limit = v
+			}
+		}
+		if offset := r.URL.Query().Get("offset"); offset != "" {
+			if v, err := strconv.Atoi(offset); err == nil {
+				filter.Offset = v
+			}
+		}
 
-		wallets, pagination, err := web3Service.ListWallets(r.Context(), userID, filter)
+		response, err := web3Service.ListWallets(r.Context(), userID, filter)
 		if err != nil {
-			logger.Error(r.Context(), "List wallets failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "List wallets failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]any{
-			"wallets":    wallets,
-			"pagination": pagination,
-		})
+		json.NewEncoder(w).Encode(response)
 	}
 }
 
@@ -421,26 +870,25 @@ func handleGetBalance(web3Service *web3.Service, logger *observability.Logger) h
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req web3.BalanceRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		response, err := web3Service.GetBalance(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "Balance retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Balance retrieval failed", err)
 			return
 		}
 
@@ -453,26 +901,29 @@ func handleCreateTransaction(web3Service *web3.Service, logger *observability.Lo
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req web3.TransactionRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		response, err := web3Service.CreateTransaction(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "Transaction creation failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if errors.Is(err, web3.ErrInvalidFeeTier) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeInternalError(w, r, logger, "Transaction creation failed", err)
 			return
 		}
 
@@ -486,13 +937,13 @@ func handleListTransactions(web3Service *web3.Service, logger *observability.Log
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
@@ -519,8 +970,7 @@ func handleListTransactions(web3Service *web3.Service, logger *observability.Log
 
 		transactions, pagination, err := web3Service.ListTransactions(r.Context(), userID, filter)
 		if err != nil {
-			logger.Error(r.Context(), "List transactions failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "List transactions failed", err)
 			return
 		}
 
@@ -542,8 +992,7 @@ func handleGetPrices(web3Service *web3.Service, logger *observability.Logger) ht
 
 		response, err := web3Service.GetPrices(r.Context(), req)
 		if err != nil {
-			logger.Error(r.Context(), "Price retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Price retrieval failed", err)
 			return
 		}
 
@@ -556,26 +1005,25 @@ func handleDeFiInteraction(web3Service *web3.Service, logger *observability.Logg
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req web3.DeFiProtocolRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
 		response, err := web3Service.InteractWithDeFiProtocol(r.Context(), userID, req)
 		if err != nil {
-			logger.Error(r.Context(), "DeFi interaction failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "DeFi interaction failed", err)
 			return
 		}
 
@@ -588,613 +1036,1983 @@ func handleListDeFiPositions(web3Service *web3.Service, logger *observability.Lo
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		// TODO: Implement ListDeFiPositions method in Web3 service
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]any{
-			"user_id":   userID.String(),
-			"positions": []any{},
-			"message":   "DeFi position listing not implemented yet",
-		})
-	}
-}
+		filter := web3.DeFiPositionFilter{
+			Protocol:      r.URL.Query().Get("protocol"),
+			IncludeClosed: r.URL.Query().Get("include_closed") == "true",
+			Network:       r.URL.Query().Get("network"),
+		}
+
+		response, err := web3Service.ListDeFiPositions(r.Context(), userID, filter)
+		if err != nil {
+			if errors.Is(err, web3.ErrInvalidNetwork) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeInternalError(w, r, logger, "List DeFi positions failed", err)
+			return
+		}
 
-func handleGetSupportedChains(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]any{
-			"chains": web3.SupportedChains,
-		})
+		json.NewEncoder(w).Encode(response)
 	}
 }
 
-// Enhanced Web3 handlers
-func handleEnhancedTransaction(enhancedService *web3.EnhancedService, logger *observability.Logger) http.HandlerFunc {
+func handleDeFiPositionAnalytics(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		var req web3.EnhancedTransactionRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		positionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid position ID", http.StatusBadRequest)
 			return
 		}
 
-		response, err := enhancedService.CreateEnhancedTransaction(r.Context(), userID, req)
-		if err != nil {
-			logger.Error(r.Context(), "Enhanced transaction creation failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		analytics, err := web3Service.GetDeFiPositionAnalytics(r.Context(), userID, positionID)
+		switch {
+		case errors.Is(err, web3.ErrDeFiPositionNotFound):
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePositionNotFound, err.Error())
+			return
+		case errors.Is(err, web3.ErrNotLiquidityPosition):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			writeInternalError(w, r, logger, "DeFi position analytics failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(response)
+		json.NewEncoder(w).Encode(analytics)
 	}
 }
 
-// Trading Engine handlers
-func handleCreatePortfolio(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+func handleAddAddressBookEntry(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		var req struct {
-			Name           string           `json:"name"`
-			InitialBalance string           `json:"initial_balance"`
-			RiskProfile    web3.RiskProfile `json:"risk_profile"`
-		}
+		var req web3.AddressBookEntryRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
-		// Parse initial balance
-		initialBalance, err := decimal.NewFromString(req.InitialBalance)
-		if err != nil {
-			http.Error(w, "Invalid initial balance", http.StatusBadRequest)
+		entry, err := web3Service.AddAddressBookEntry(r.Context(), userID, req)
+		switch {
+		case errors.Is(err, web3.ErrInvalidAddressBookEntry):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
 			return
-		}
-
-		portfolio, err := tradingEngine.CreatePortfolio(r.Context(), userID, req.Name, initialBalance, req.RiskProfile)
-		if err != nil {
-			logger.Error(r.Context(), "Portfolio creation failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case errors.Is(err, web3.ErrAddressBookLabelExists):
+			httputil.Error(w, r, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			writeInternalError(w, r, logger, "Add address book entry failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(portfolio)
+		json.NewEncoder(w).Encode(entry)
 	}
 }
 
-func handleGetPortfolio(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+func handleListAddressBook(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/trading/portfolio/")
-		portfolioID, err := uuid.Parse(portfolioIDStr)
-		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
-		portfolio, err := tradingEngine.GetPortfolio(portfolioID)
+		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			logger.Error(r.Context(), "Portfolio retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(portfolio)
-	}
-}
+		chainID := 0
+		if chain := r.URL.Query().Get("chain_id"); chain != "" {
+			if chainID, err = strconv.Atoi(chain); err != nil {
+				httputil.Error(w, r, "Invalid chain_id", http.StatusBadRequest)
+				return
+			}
+		}
+
+		entries, err := web3Service.ListAddressBook(r.Context(), userID, chainID)
+		if err != nil {
+			writeInternalError(w, r, logger, "List address book failed", err)
+			return
+		}
 
-func handleStartTrading(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": "Trading engine is already running globally",
-			"status":  "success",
+			"entries": entries,
+			"count":   len(entries),
 		})
 	}
 }
 
-func handleStopTrading(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+func handleDeleteAddressBookEntry(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := tradingEngine.Stop(r.Context())
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			logger.Error(r.Context(), "Failed to stop trading", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": "Trading stopped successfully",
-			"status":  "success",
-		})
+		entryID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid entry ID", http.StatusBadRequest)
+			return
+		}
+
+		err = web3Service.DeleteAddressBookEntry(r.Context(), userID, entryID)
+		switch {
+		case errors.Is(err, web3.ErrAddressBookEntryNotFound):
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			writeInternalError(w, r, logger, "Delete address book entry failed", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-func handleGetPositions(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+func handleGetNFTHoldings(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/trading/positions/")
-		portfolioID, err := uuid.Parse(portfolioIDStr)
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		positions, err := tradingEngine.GetActivePositions(portfolioID)
+		chainID, err := parseNFTChainID(r)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := web3.NFTQuery{
+			Cursor:       r.URL.Query().Get("cursor"),
+			WithMetadata: r.URL.Query().Get("metadata") == "true",
+		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+				httputil.Error(w, r, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		response, err := web3Service.GetNFTs(r.Context(), userID, chainID, query)
 		if err != nil {
-			logger.Error(r.Context(), "Positions retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeNFTError(w, r, logger, "Get NFT holdings failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"portfolio_id": portfolioID.String(),
-			"positions":    positions,
-		})
+		json.NewEncoder(w).Encode(response)
 	}
 }
 
-func handleClosePosition(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+func handleGetNFTCollection(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		positionIDStr := strings.TrimPrefix(r.URL.Path, "/web3/trading/positions/")
-		positionIDStr = strings.TrimSuffix(positionIDStr, "/close")
-		positionID, err := uuid.Parse(positionIDStr)
+		chainID, err := parseNFTChainID(r)
 		if err != nil {
-			http.Error(w, "Invalid position ID", http.StatusBadRequest)
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		var req struct {
-			Reason string `json:"reason"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			req.Reason = "Manual close"
-		}
-
-		err = tradingEngine.ClosePosition(r.Context(), positionID, req.Reason)
+		collection, err := web3Service.GetNFTCollection(r.Context(), chainID, r.PathValue("address"))
 		if err != nil {
-			logger.Error(r.Context(), "Position close failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeNFTError(w, r, logger, "Get NFT collection failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":     "Position closed successfully",
-			"position_id": positionID.String(),
-			"reason":      req.Reason,
-		})
+		json.NewEncoder(w).Encode(collection)
 	}
 }
 
-// DeFi Protocol handlers
-func handleGetProtocols(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		protocols := defiManager.GetProtocols()
+// parseNFTChainID reads the chain_id query parameter, defaulting to Ethereum mainnet
+func parseNFTChainID(r *http.Request) (int, error) {
+	chain := r.URL.Query().Get("chain_id")
+	if chain == "" {
+		return 1, nil
+	}
+	chainID, err := strconv.Atoi(chain)
+	if err != nil {
+		return 0, errors.New("invalid chain_id")
+	}
+	return chainID, nil
+}
+
+// writeInternalError reports unknown portfolios and positions and trades beyond the available
+// balance with their own codes, and logs anything else, responding 500 with message so chain and
+// database details stay out of responses
+func writeInternalError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, message string, err error) {
+	switch {
+	case errors.Is(err, web3.ErrPortfolioNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, err.Error())
+	case errors.Is(err, web3.ErrPositionNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePositionNotFound, err.Error())
+	case errors.Is(err, web3.ErrInsufficientBalance):
+		httputil.WriteError(w, r, http.StatusUnprocessableEntity, httputil.CodeInsufficientFunds, err.Error())
+	default:
+		httputil.InternalError(w, r, logger, message, err)
+	}
+}
+
+// writeNFTError maps NFT tracking errors to HTTP statuses
+func writeNFTError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, message string, err error) {
+	switch {
+	case errors.Is(err, web3.ErrInvalidNFTCursor), errors.Is(err, web3.ErrInvalidNFTContract), errors.Is(err, web3.ErrUnsupportedNFTChain):
+		httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, web3.ErrNFTCollectionNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, web3.ErrNFTIndexerUnavailable):
+		httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+	default:
+		writeInternalError(w, r, logger, message, err)
+	}
+}
 
+func handleGetSupportedChains(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"protocols": protocols,
+		json.NewEncoder(w).Encode(map[string]any{
+			"chains":   web3.SupportedChains,
+			"networks": web3Service.SupportedNetworks(),
 		})
 	}
 }
 
-func handleGetProtocol(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
+func handleEstimateFees(web3Service *web3.Service, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		protocolID := strings.TrimPrefix(r.URL.Path, "/web3/defi/protocols/")
+		chainID, err := strconv.Atoi(r.URL.Query().Get("chain_id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		txType, err := web3.ParseFeeTxType(r.URL.Query().Get("tx_type"))
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-		protocol, err := defiManager.GetProtocol(protocolID)
+		estimate, err := web3Service.EstimateFees(r.Context(), chainID, txType)
 		if err != nil {
-			logger.Error(r.Context(), "Protocol retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Error(r.Context(), "Fee estimation failed", err)
+			httputil.Error(w, r, "Fee estimation failed", http.StatusBadGateway)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(protocol)
+		json.NewEncoder(w).Encode(estimate)
 	}
 }
 
-func handleGetYieldOpportunities(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
+// Enhanced Web3 handlers
+func handleEnhancedTransaction(enhancedService *web3.EnhancedService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Parse query parameters
-		minAPYStr := r.URL.Query().Get("min_apy")
-		maxRiskStr := r.URL.Query().Get("max_risk")
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
 
-		minAPY := decimal.NewFromFloat(0.01) // Default 1%
-		if minAPYStr != "" {
-			if parsed, err := decimal.NewFromString(minAPYStr); err == nil {
-				minAPY = parsed
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req web3.EnhancedTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		response, err := enhancedService.CreateEnhancedTransaction(r.Context(), userID, req)
+		if err != nil {
+			var simErr *web3.SimulationError
+			if errors.As(err, &simErr) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(map[string]any{
+					"error":      simErr.Error(),
+					"simulation": simErr.Simulation,
+				})
+				return
+			}
+			if handlers.WriteScreeningError(w, r, err) || writeWalletAccessError(w, r, err) {
+				return
+			}
+			if errors.Is(err, web3.ErrInvalidFeeTier) || errors.Is(err, web3.ErrUnresolvedRecipient) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, web3.ErrSafeUnavailable) || errors.Is(err, web3.ErrENSUnavailable) {
+				httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+				return
 			}
+			writeInternalError(w, r, logger, "Enhanced transaction creation failed", err)
+			return
 		}
 
-		maxRisk := web3.RiskLevelMedium // Default medium risk
-		if maxRiskStr != "" {
-			switch maxRiskStr {
-			case "very_low":
-				maxRisk = web3.RiskLevelVeryLow
-			case "low":
-				maxRisk = web3.RiskLevelLow
-			case "medium":
-				maxRisk = web3.RiskLevelMedium
-			case "high":
-				maxRisk = web3.RiskLevelHigh
-			case "critical":
-				maxRisk = web3.RiskLevelCritical
+		w.Header().Set("Content-Type", "application/json")
+		// Transactions of multisig wallets are built once the members approve the proposal
+		if response.Proposal != nil {
+			w.WriteHeader(http.StatusAccepted)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// writeWalletAccessError writes 404 for a missing wallet and 403 for another user's wallet,
+// reporting whether err was one of them
+func writeWalletAccessError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, web3.ErrWalletNotFound):
+		httputil.Error(w, r, err.Error(), http.StatusNotFound)
+	case errors.Is(err, web3.ErrWalletAccessDenied):
+		httputil.Error(w, r, err.Error(), http.StatusForbidden)
+	default:
+		return false
+	}
+	return true
+}
+
+func handleSimulateTransaction(enhancedService *web3.EnhancedService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req web3.SimulationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		simulation, err := enhancedService.SimulateTransaction(r.Context(), userID, req)
+		if err != nil {
+			if writeWalletAccessError(w, r, err) {
+				return
+			}
+			if errors.Is(err, web3.ErrInvalidSimulation) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
 			}
+			writeInternalError(w, r, logger, "Transaction simulation failed", err)
+			return
 		}
 
-		opportunities, err := defiManager.GetBestYieldOpportunities(r.Context(), minAPY, maxRisk)
+		// A reverting transaction is a successful simulation; the result reports the revert
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(simulation)
+	}
+}
+
+// Trading Engine handlers
+func handleCreatePortfolio(tradingEngine *web3.TradingEngine, decoder httputil.BodyDecoder, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req web3.CreatePortfolioRequest
+		if err := decoder.Decode(r, &req); err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+		initialBalance, err := req.Validate()
+		if err != nil {
+			httputil.WriteRequestError(w, r, err)
+			return
+		}
+
+		portfolio, err := tradingEngine.CreatePortfolio(r.Context(), userID, req.Name, initialBalance, req.RiskProfile)
 		if err != nil {
-			logger.Error(r.Context(), "Yield opportunities retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Portfolio creation failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"opportunities": opportunities,
-			"filters": map[string]interface{}{
-				"min_apy":  minAPY.String(),
-				"max_risk": string(maxRisk),
-			},
-		})
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(portfolio)
 	}
 }
 
-// Portfolio Rebalancing handlers
-func handleCreateRebalanceStrategy(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+func handleGetPortfolio(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct {
-			PortfolioID       string                     `json:"portfolio_id"`
-			Name              string                     `json:"name"`
-			Type              web3.RebalanceType         `json:"type"`
-			TargetAllocations map[string]decimal.Decimal `json:"target_allocations"`
+		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/trading/portfolio/")
+		portfolioID, err := uuid.Parse(portfolioIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		portfolio, err := tradingEngine.GetPortfolio(portfolioID)
+		if err != nil {
+			logger.Error(r.Context(), "Portfolio retrieval failed", err)
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(portfolio)
+	}
+}
+
+// recordTransferRequest is a transfer into one of the user's wallets held by a portfolio
+type recordTransferRequest struct {
+	Symbol      string           `json:"symbol"`
+	Quantity    decimal.Decimal  `json:"quantity"`
+	FromAddress string           `json:"from_address"`
+	ToAddress   string           `json:"to_address"`
+	CostBasis   *decimal.Decimal `json:"cost_basis,omitempty"`
+	ExecutedAt  time.Time        `json:"executed_at"`
+}
+
+func handleRecordTransfer(web3Service *web3.Service, tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		portfolioID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
 		}
+		portfolio, err := tradingEngine.GetPortfolio(portfolioID)
+		if err != nil || portfolio.UserID != userID {
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, "Portfolio not found")
+			return
+		}
+
+		var req recordTransferRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
 
-		portfolioID, err := uuid.Parse(req.PortfolioID)
+		// Only transfers into the user's wallets are recorded; tokens leaving them are sold
+		ownsTo, err := web3Service.OwnsAddress(r.Context(), userID, req.ToAddress)
 		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+			logger.Error(r.Context(), "Wallet lookup failed", err)
+			httputil.Error(w, r, "Failed to look up wallets", http.StatusInternalServerError)
+			return
+		}
+		if !ownsTo {
+			httputil.Error(w, r, "to_address must be one of your wallets", http.StatusBadRequest)
 			return
 		}
+		internal := false
+		if req.FromAddress != "" {
+			if internal, err = web3Service.OwnsAddress(r.Context(), userID, req.FromAddress); err != nil {
+				logger.Error(r.Context(), "Wallet lookup failed", err)
+				httputil.Error(w, r, "Failed to look up wallets", http.StatusInternalServerError)
+				return
+			}
+		}
 
-		strategy, err := portfolioRebalancer.CreateRebalanceStrategy(
-			r.Context(), portfolioID, req.Name, req.Type, req.TargetAllocations)
+		trade, err := tradingEngine.RecordTransfer(r.Context(), portfolioID, web3.AssetTransfer{
+			Symbol:      req.Symbol,
+			Quantity:    req.Quantity,
+			FromAddress: req.FromAddress,
+			ToAddress:   req.ToAddress,
+			CostBasis:   req.CostBasis,
+			ExecutedAt:  req.ExecutedAt,
+			Internal:    internal,
+		})
 		if err != nil {
-			logger.Error(r.Context(), "Rebalance strategy creation failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if errors.Is(err, web3.ErrInvalidTransfer) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeInternalError(w, r, logger, "Recording transfer failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(strategy)
+		json.NewEncoder(w).Encode(trade)
 	}
 }
 
-func handleGetRebalanceStrategy(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+func handleStartTrading(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/rebalance/strategy/")
-		portfolioID, err := uuid.Parse(portfolioIDStr)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Trading engine is already running globally",
+			"status":  "success",
+		})
+	}
+}
+
+func handleStopTrading(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := tradingEngine.Stop(r.Context())
 		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+			writeInternalError(w, r, logger, "Failed to stop trading", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"portfolio_id": portfolioID.String(),
-			"message":      "Rebalance strategy retrieval not implemented yet",
+			"message": "Trading stopped successfully",
+			"status":  "success",
 		})
 	}
 }
 
-func handleExecuteRebalancing(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+func handleGetPositions(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/rebalance/execute/")
+		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/trading/positions/")
 		portfolioID, err := uuid.Parse(portfolioIDStr)
 		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
 			return
 		}
 
-		err = portfolioRebalancer.RebalancePortfolio(r.Context(), portfolioID)
+		positions, err := tradingEngine.GetActivePositions(portfolioID)
 		if err != nil {
-			logger.Error(r.Context(), "Portfolio rebalancing failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Positions retrieval failed", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":      "Portfolio rebalanced successfully",
 			"portfolio_id": portfolioID.String(),
+			"positions":    positions,
 		})
 	}
 }
 
-// AI Voice Interface handlers
-func handleVoiceCommand(voiceInterface *ai.VoiceInterface, logger *observability.Logger) http.HandlerFunc {
+func handleClosePosition(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		positionIDStr := strings.TrimPrefix(r.URL.Path, "/web3/trading/positions/")
+		positionIDStr = strings.TrimSuffix(positionIDStr, "/close")
+		positionID, err := uuid.Parse(positionIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid position ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			web3.PartialClose
+			Reason string `json:"reason"`
+		}
+		// A malformed size must not close the whole position; an empty body closes it
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+		if req.Reason == "" {
+			req.Reason = "Manual close"
+		}
+
+		// A quantity or percentage closes part of the position; neither closes all of it
+		response := map[string]interface{}{
+			"message":     "Position closed successfully",
+			"position_id": positionID.String(),
+			"reason":      req.Reason,
+		}
+		if req.Quantity != nil || req.Percentage != nil {
+			var position *web3.Position
+			position, err = tradingEngine.ReducePosition(r.Context(), positionID, req.PartialClose, req.Reason)
+			if err == nil {
+				response["position"] = position
+				if position.Status == web3.PositionStatusOpen {
+					response["message"] = "Position reduced successfully"
+				}
+			}
+		} else {
+			err = tradingEngine.ClosePosition(r.Context(), positionID, req.Reason)
+		}
+		switch {
+		case errors.Is(err, web3.ErrPositionNotFound):
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePositionNotFound, err.Error())
+			return
+		case errors.Is(err, web3.ErrInvalidCloseSize):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			writeInternalError(w, r, logger, "Position close failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// handleGetTradeAnomalies lists recent anomalies of one of the caller's portfolios, or of all of them
+func handleGetTradeAnomalies(tradingEngine *web3.TradingEngine, tradeAnomalies *analytics.TradeAnomalyMonitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var query analytics.TradeAnomalyQuery
+		if value := r.URL.Query().Get("limit"); value != "" {
+			if query.Limit, err = strconv.Atoi(value); err != nil || query.Limit <= 0 {
+				httputil.Error(w, r, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if value := r.URL.Query().Get("portfolio_id"); value != "" {
+			portfolioID, err := uuid.Parse(value)
+			if err != nil {
+				httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+				return
+			}
+			portfolio, err := tradingEngine.GetPortfolio(portfolioID)
+			if err != nil || portfolio.UserID != userID {
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, "Portfolio not found")
+				return
+			}
+			query.PortfolioIDs = []string{portfolioID.String()}
+		} else {
+			for _, portfolio := range tradingEngine.ListPortfolios() {
+				if portfolio.UserID == userID {
+					query.PortfolioIDs = append(query.PortfolioIDs, portfolio.ID.String())
+				}
+			}
+		}
+
+		anomalies := tradeAnomalies.ListAnomalies(query)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"anomalies": anomalies,
+			"count":     len(anomalies),
+		})
+	}
+}
+
+func handleKillSwitchStatus(tradingEngine *web3.TradingEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tradingEngine.KillSwitchStatus())
+	}
+}
+
+func handleTripKillSwitch(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
 		var req struct {
-			Text      string `json:"text"`
-			AudioData []byte `json:"audio_data,omitempty"`
+			Reason  string `json:"reason"`
+			Flatten bool   `json:"flatten"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
 			return
 		}
+		if strings.TrimSpace(req.Reason) == "" {
+			req.Reason = "Manual halt"
+		}
 
-		response, err := voiceInterface.ProcessVoiceCommand(r.Context(), userID, req.AudioData, req.Text)
+		state, err := tradingEngine.TripKillSwitch(r.Context(), web3.KillSwitchTriggerManual, req.Reason, &userID, req.Flatten)
 		if err != nil {
-			logger.Error(r.Context(), "Voice command processing failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			// Trading is halted in this instance but will resume after a restart
+			writeInternalError(w, r, logger, "Kill switch trip was not persisted", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		json.NewEncoder(w).Encode(state)
 	}
 }
 
-func handleVoiceHistory(voiceInterface *ai.VoiceInterface, logger *observability.Logger) http.HandlerFunc {
+func handleResetKillSwitch(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		state, err := tradingEngine.ResetKillSwitch(r.Context(), userID, req.Reason)
+		switch {
+		case errors.Is(err, web3.ErrKillSwitchReasonRequired):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, web3.ErrKillSwitchNotTripped):
+			httputil.Error(w, r, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			writeInternalError(w, r, logger, "Kill switch reset failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	}
+}
+
+func handleListBlackouts(calendar *web3.TradingCalendar, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		blackouts, err := calendar.ListBlackouts(r.Context())
+		if err != nil {
+			logger.Error(r.Context(), "Failed to list trading blackouts", err)
+			httputil.Error(w, r, "Failed to list blackouts", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"blackouts": blackouts,
+			"count":     len(blackouts),
+		})
+	}
+}
+
+func handleCreateBlackout(calendar *web3.TradingCalendar, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req web3.CreateBlackoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		blackout, err := calendar.CreateBlackout(r.Context(), userID, req)
+		switch {
+		case errors.Is(err, web3.ErrInvalidBlackout):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Failed to create trading blackout", err)
+			httputil.Error(w, r, "Failed to create blackout", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(blackout)
+	}
+}
+
+func handleDeleteBlackout(calendar *web3.TradingCalendar, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		blackoutID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid blackout ID", http.StatusBadRequest)
+			return
+		}
+
+		err = calendar.DeleteBlackout(r.Context(), blackoutID)
+		switch {
+		case errors.Is(err, web3.ErrBlackoutNotFound):
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error(r.Context(), "Failed to delete trading blackout", err)
+			httputil.Error(w, r, "Failed to delete blackout", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func handleListTradingStrategies(tradingEngine *web3.TradingEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		strategies := tradingEngine.ListStrategies()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"strategies": strategies,
+			"count":      len(strategies),
+		})
+	}
+}
+
+// handleSetStrategySchedule sets the weekly trading windows of a strategy; a body of null
+// removes them
+func handleSetStrategySchedule(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var schedule *web3.TradingSchedule
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		name := r.PathValue("name")
+		err := tradingEngine.SetStrategySchedule(name, schedule)
+		switch {
+		case errors.Is(err, web3.ErrInvalidTradingSchedule):
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, web3.ErrTradingStrategyNotFound):
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeStrategyNotFound, err.Error())
+			return
+		case err != nil:
+			writeInternalError(w, r, logger, "Failed to set strategy schedule", err)
+			return
+		}
+
+		logger.Info(r.Context(), "Strategy trading schedule updated", map[string]interface{}{
+			"strategy": name,
+			"schedule": schedule,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"strategy": name,
+			"schedule": schedule,
+		})
+	}
+}
+
+// handleExportAuditLog streams the trading audit log for a time range as JSONL, including
+// archived events, with the hashes needed to verify the chain
+func handleExportAuditLog(auditManager *security.AuditManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var from time.Time
+		to := time.Now()
+		if v := query.Get("from"); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				httputil.Error(w, r, "Invalid from, expected RFC 3339", http.StatusBadRequest)
+				return
+			}
+			from = parsed
+		}
+		if v := query.Get("to"); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				httputil.Error(w, r, "Invalid to, expected RFC 3339", http.StatusBadRequest)
+				return
+			}
+			to = parsed
+		}
+		if to.Before(from) {
+			httputil.Error(w, r, "from must not be after to", http.StatusBadRequest)
+			return
+		}
+
+		format := query.Get("format")
+		if format == "" {
+			format = security.AuditExportFormatJSONL
+		}
+		if format != security.AuditExportFormatJSONL {
+			httputil.Error(w, r, "Unsupported format, expected jsonl", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"audit-%s-%s.jsonl\"", from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z")))
+		written, err := auditManager.ExportEvents(r.Context(), from, to, format, w)
+		if err != nil {
+			logger.Error(r.Context(), "Failed to export audit log", err, map[string]interface{}{
+				"from":    from,
+				"to":      to,
+				"written": written,
+			})
+			if written == 0 {
+				httputil.Error(w, r, "Failed to export audit log", http.StatusInternalServerError)
+			}
+		}
+	}
+}
+
+// handleRequestErasure starts erasure of a user's data across all services. The request is
+// processed asynchronously; its status is available from handleGetErasureRequest.
+func handleRequestErasure(privacyManager *security.PrivacyManager, adminAuthorizer *middleware.AdminAuthorizer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callerIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		callerID, err := uuid.Parse(callerIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		userID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		if userID != callerID && !adminAuthorizer.IsAdmin(r) {
+			httputil.Error(w, r, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		request, err := privacyManager.RequestErasure(r.Context(), userID, callerID)
+		if err != nil {
+			logger.Error(r.Context(), "Failed to request erasure", err, map[string]interface{}{
+				"user_id": userID.String(),
+			})
+			httputil.Error(w, r, "Failed to request erasure", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/privacy/erasure-requests/"+request.ID.String())
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(request)
+	}
+}
+
+// handleGetErasureRequest returns the per-store status of an erasure request and, once
+// completed, its signed certificate
+func handleGetErasureRequest(privacyManager *security.PrivacyManager, adminAuthorizer *middleware.AdminAuthorizer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callerIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		requestID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid erasure request ID", http.StatusBadRequest)
+			return
+		}
+
+		request, err := privacyManager.GetErasureRequest(r.Context(), requestID)
+		if errors.Is(err, security.ErrErasureRequestNotFound) {
+			httputil.Error(w, r, "Erasure request not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error(r.Context(), "Failed to get erasure request", err)
+			httputil.Error(w, r, "Failed to get erasure request", http.StatusInternalServerError)
+			return
+		}
+		// Don't reveal other users' requests
+		if request.UserID.String() != callerIDStr && request.RequestedBy.String() != callerIDStr && !adminAuthorizer.IsAdmin(r) {
+			httputil.Error(w, r, "Erasure request not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(request)
+	}
+}
+
+func handleUpdatePositionProtection(tradingEngine *web3.TradingEngine, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		positionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid position ID", http.StatusBadRequest)
+			return
+		}
+
+		var req web3.PositionProtection
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		position, err := tradingEngine.UpdatePositionProtection(r.Context(), positionID, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, web3.ErrPositionNotFound):
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePositionNotFound, err.Error())
+			case errors.Is(err, web3.ErrInvalidProtection):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			default:
+				writeInternalError(w, r, logger, "Position protection update failed", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(position)
+	}
+}
+
+// DeFi Protocol handlers
+func handleGetProtocols(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		protocols := defiManager.GetProtocols()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"protocols": protocols,
+		})
+	}
+}
+
+func handleGetProtocol(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		protocolID := strings.TrimPrefix(r.URL.Path, "/web3/defi/protocols/")
+
+		protocol, err := defiManager.GetProtocol(protocolID)
+		if err != nil {
+			logger.Error(r.Context(), "Protocol retrieval failed", err)
+			httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(protocol)
+	}
+}
+
+func handleGetYieldOpportunities(defiManager *web3.DeFiProtocolManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse query parameters
+		minAPYStr := r.URL.Query().Get("min_apy")
+		maxRiskStr := r.URL.Query().Get("max_risk")
+
+		minAPY := decimal.NewFromFloat(0.01) // Default 1%
+		if minAPYStr != "" {
+			if parsed, err := decimal.NewFromString(minAPYStr); err == nil {
+				minAPY = parsed
+			}
+		}
+
+		maxRisk := web3.RiskLevelMedium // Default medium risk
+		if maxRiskStr != "" {
+			switch maxRiskStr {
+			case "very_low":
+				maxRisk = web3.RiskLevelVeryLow
+			case "low":
+				maxRisk = web3.RiskLevelLow
+			case "medium":
+				maxRisk = web3.RiskLevelMedium
+			case "high":
+				maxRisk = web3.RiskLevelHigh
+			case "critical":
+				maxRisk = web3.RiskLevelCritical
+			}
+		}
+
+		var maxAge time.Duration
+		if maxAgeStr := r.URL.Query().Get("max_age"); maxAgeStr != "" {
+			parsed, err := parseMaxAge(maxAgeStr)
+			if err != nil {
+				httputil.Error(w, r, "Invalid max_age, expected a duration such as 10m or a number of seconds", http.StatusBadRequest)
+				return
+			}
+			maxAge = parsed
+		}
+
+		opportunities, err := defiManager.GetYieldOpportunities(r.Context(), web3.YieldOpportunityQuery{
+			MinAPY:  minAPY,
+			MaxRisk: maxRisk,
+			MaxAge:  maxAge,
+		})
+		if err != nil {
+			writeInternalError(w, r, logger, "Yield opportunities retrieval failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"opportunities": opportunities,
+			"filters": map[string]interface{}{
+				"min_apy":  minAPY.String(),
+				"max_risk": string(maxRisk),
+				"max_age":  maxAge.String(),
+			},
+		})
+	}
+}
+
+// parseMaxAge accepts a duration such as "10m" or a number of seconds
+func parseMaxAge(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		value = strconv.Itoa(seconds) + "s"
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil || maxAge <= 0 {
+		return 0, fmt.Errorf("invalid max_age %q", value)
+	}
+	return maxAge, nil
+}
+
+func handleGetYieldSources(defiManager *web3.DeFiProtocolManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sources": defiManager.YieldSourceStats(),
+		})
+	}
+}
+
+// Portfolio Rebalancing handlers
+func handleCreateRebalanceStrategy(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			PortfolioID       string                     `json:"portfolio_id"`
+			Name              string                     `json:"name"`
+			Type              web3.RebalanceType         `json:"type"`
+			TargetAllocations map[string]decimal.Decimal `json:"target_allocations"`
+			Schedule          *web3.RebalanceSchedule    `json:"schedule"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		portfolioID, err := uuid.Parse(req.PortfolioID)
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+		if req.Schedule != nil {
+			if err := req.Schedule.Validate(); err != nil {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		strategy, err := portfolioRebalancer.CreateRebalanceStrategy(
+			r.Context(), portfolioID, req.Name, req.Type, req.TargetAllocations)
+		if err != nil {
+			if errors.Is(err, web3.ErrInvalidRebalanceStrategy) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeInternalError(w, r, logger, "Rebalance strategy creation failed", err)
+			return
+		}
+		if req.Schedule != nil {
+			if strategy, err = portfolioRebalancer.SetRebalanceSchedule(r.Context(), portfolioID, req.Schedule); err != nil {
+				writeInternalError(w, r, logger, "Rebalance schedule update failed", err)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(strategy)
+	}
+}
+
+func handleGetRebalanceStrategy(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/rebalance/strategy/")
+		portfolioID, err := uuid.Parse(portfolioIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		strategy, err := portfolioRebalancer.GetRebalanceStrategy(r.Context(), portfolioID)
+		if err != nil {
+			if errors.Is(err, web3.ErrRebalanceStrategyNotFound) {
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeStrategyNotFound, err.Error())
+				return
+			}
+			writeInternalError(w, r, logger, "Rebalance strategy retrieval failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(strategy)
+	}
+}
+
+func handleExecuteRebalancing(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/rebalance/execute/")
+		portfolioID, err := uuid.Parse(portfolioIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		dryRun := false
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				httputil.Error(w, r, "Invalid dry_run", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var execution *web3.RebalanceExecution
+		message := "Portfolio rebalanced successfully"
+		if dryRun {
+			execution, err = portfolioRebalancer.PreviewRebalance(r.Context(), portfolioID)
+			message = "Rebalance preview generated"
+		} else {
+			execution, err = portfolioRebalancer.RebalancePortfolio(r.Context(), portfolioID)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, web3.ErrRebalanceStrategyNotFound):
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+			case errors.Is(err, web3.ErrRebalanceInProgress):
+				httputil.Error(w, r, err.Error(), http.StatusConflict)
+			default:
+				writeInternalError(w, r, logger, "Portfolio rebalancing failed", err)
+			}
+			return
+		}
+		if execution.Status == web3.RebalanceStatusSkipped {
+			message = "No rebalance needed"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":      message,
+			"portfolio_id": portfolioID.String(),
+			"execution":    execution,
+		})
+	}
+}
+
+func handleSetRebalanceSchedule(portfolioRebalancer *web3.PortfolioRebalancer, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		portfolioID, err := uuid.Parse(r.PathValue("portfolio_id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		var schedule web3.RebalanceSchedule
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		strategy, err := portfolioRebalancer.SetRebalanceSchedule(r.Context(), portfolioID, &schedule)
+		if err != nil {
+			switch {
+			case errors.Is(err, web3.ErrInvalidRebalanceSchedule):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, web3.ErrRebalanceStrategyNotFound):
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeStrategyNotFound, err.Error())
+			default:
+				writeInternalError(w, r, logger, "Rebalance schedule update failed", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(strategy)
+	}
+}
+
+// AI Voice Interface handlers
+func handleVoiceCommand(voiceInterface *ai.VoiceInterface, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			Text           string `json:"text"`
+			AudioData      []byte `json:"audio_data,omitempty"`
+			ResponseFormat string `json:"response_format,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		format, err := ai.ParseResponseFormat(req.ResponseFormat)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response, err := voiceInterface.ProcessVoiceCommandWithOptions(r.Context(), userID, req.AudioData, req.Text, ai.VoiceCommandOptions{
+			ResponseFormat: format,
+		})
+		if err != nil {
+			writeInternalError(w, r, logger, "Voice command processing failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+func handleVoiceHistory(voiceInterface *ai.VoiceInterface, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		history := voiceInterface.GetCommandHistory(userID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id": userID.String(),
+			"history": history,
+		})
+	}
+}
+
+// Conversational AI handlers
+func handleChatMessage(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		response, err := conversationalAI.ProcessMessage(r.Context(), userID, req.Message)
+		if err != nil {
+			writeInternalError(w, r, logger, "Chat message processing failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+func handleStartConversation(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		conversation, err := conversationalAI.StartConversation(r.Context(), userID)
+		if err != nil {
+			writeInternalError(w, r, logger, "Conversation start failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(conversation)
+	}
+}
+
+func handleMarketAnalysis(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		// Generate market analysis using conversational AI
+		response, err := conversationalAI.ProcessMessage(r.Context(), userID, "Give me a comprehensive market analysis")
+		if err != nil {
+			writeInternalError(w, r, logger, "Market analysis failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// Real-time Market Data handlers
+func handleMarketDataStatus(marketDataService *realtime.MarketDataService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := marketDataService.GetConnectionStatus()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":      "active",
+			"connections": status,
+			"timestamp":   time.Now(),
+		})
+	}
+}
+
+func handleMarketDataSubscribe(marketDataService *realtime.MarketDataService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol := strings.TrimPrefix(r.URL.Path, "/web3/realtime/market/subscribe/")
+
+		// Subscribe to the merged stream, optionally limited to ?exchange=coinbase,kraken
+		var exchanges []string
+		if filter := r.URL.Query().Get("exchange"); filter != "" {
+			exchanges = strings.Split(filter, ",")
+		}
+		updateChan := marketDataService.Subscribe(symbol, exchanges...)
+
+		// Set up Server-Sent Events
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		// Send initial connection message
+		fmt.Fprintf(w, "data: {\"type\":\"connected\",\"symbol\":\"%s\"}\n\n", symbol)
+		w.(http.Flusher).Flush()
+
+		// Stream market data updates
+		for {
+			select {
+			case update := <-updateChan:
+				data, _ := json.Marshal(update)
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				marketDataService.Unsubscribe(symbol, updateChan)
+				return
+			}
+		}
+	}
+}
+
+func handleMarketOrderBook(marketDataService *realtime.MarketDataService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.PathValue("symbol")
+
+		depth := 20
+		if value := r.URL.Query().Get("depth"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > 1000 {
+				httputil.Error(w, r, "depth must be between 1 and 1000", http.StatusBadRequest)
+				return
+			}
+			depth = parsed
+		}
+
+		var book *realtime.OrderBook
+		var err error
+		if exchange := r.URL.Query().Get("exchange"); exchange != "" {
+			book, err = marketDataService.GetExchangeOrderBook(exchange, symbol, depth)
+		} else {
+			book, err = marketDataService.GetOrderBook(symbol, depth)
+		}
+		if err != nil {
+			if errors.Is(err, realtime.ErrOrderBookUnavailable) {
+				httputil.Error(w, r, err.Error(), http.StatusNotFound)
+				return
+			}
+			writeInternalError(w, r, logger, "Failed to get order book", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(book)
+	}
+}
+
+func handleMarketCandles(candleService *realtime.CandleService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		symbol := query.Get("symbol")
+		if symbol == "" {
+			httputil.Error(w, r, "symbol is required", http.StatusBadRequest)
+			return
+		}
+		interval := query.Get("interval")
+		if interval == "" {
+			interval = "1h"
+		}
+		exchange := query.Get("exchange")
+		if exchange == "" {
+			exchange = "binance"
+		}
+
+		from, to, err := parseTimeRange(query)
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		candles, err := candleService.GetCandles(r.Context(), exchange, symbol, interval, from, to)
+		if err != nil {
+			switch {
+			case errors.Is(err, realtime.ErrInvalidCandleInterval), errors.Is(err, realtime.ErrInvalidCandleRange),
+				errors.Is(err, realtime.ErrCandlesUnsupported):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			default:
+				logger.Error(r.Context(), "Failed to get candles", err)
+				httputil.Error(w, r, "Failed to get candles", http.StatusBadGateway)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"exchange": exchange,
+			"symbol":   symbol,
+			"interval": interval,
+			"candles":  candles,
+		})
+	}
+}
+
+func handleMarketFunding(derivatives *realtime.DerivativesCollector, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.PathValue("symbol")
+		from, to, err := parseTimeRange(r.URL.Query())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rates, err := derivatives.GetFundingRates(r.Context(), r.URL.Query().Get("exchange"), symbol, from, to)
+		if err != nil {
+			writeDerivativesError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbol":        symbol,
+			"funding_rates": rates,
+			"count":         len(rates),
+		})
+	}
+}
+
+func handleMarketOpenInterest(derivatives *realtime.DerivativesCollector, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.PathValue("symbol")
+		from, to, err := parseTimeRange(r.URL.Query())
+		if err != nil {
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		points, err := derivatives.GetOpenInterest(r.Context(), r.URL.Query().Get("exchange"), symbol, from, to)
+		if err != nil {
+			writeDerivativesError(w, r, logger, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbol":        symbol,
+			"open_interest": points,
+			"count":         len(points),
+		})
+	}
+}
+
+func writeDerivativesError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, err error) {
+	switch {
+	case errors.Is(err, realtime.ErrDerivativesUnsupported), errors.Is(err, realtime.ErrInvalidDerivativesRange):
+		httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Error(r.Context(), "Failed to load derivatives history", err)
+		httputil.Error(w, r, "Failed to load derivatives history", http.StatusInternalServerError)
+	}
+}
+
+// parseTimeRange reads the optional from and to query parameters, given as RFC3339 timestamps
+// or Unix milliseconds
+func parseTimeRange(query url.Values) (time.Time, time.Time, error) {
+	var from, to time.Time
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			*param.target = time.UnixMilli(ms)
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%s must be an RFC3339 timestamp or Unix milliseconds", param.name)
+		}
+		*param.target = parsed
+	}
+	return from, to, nil
+}
+
+// Portfolio Analytics handlers
+func handlePortfolioAnalytics(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/analytics/portfolio/")
+		portfolioID, err := uuid.Parse(portfolioIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		metrics, err := portfolioAnalytics.GetPortfolioMetrics(r.Context(), portfolioID)
+		if err != nil {
+			writeInternalError(w, r, logger, "Portfolio analytics retrieval failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(metrics)
+	}
+}
+
+func handlePortfolioPerformance(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/analytics/portfolio/")
+		portfolioIDStr = strings.TrimSuffix(portfolioIDStr, "/performance")
+		portfolioID, err := uuid.Parse(portfolioIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+
+		metrics, err := portfolioAnalytics.GetPortfolioMetrics(r.Context(), portfolioID)
+		if err != nil {
+			writeInternalError(w, r, logger, "Portfolio performance retrieval failed", err)
 			return
 		}
 
-		history := voiceInterface.GetCommandHistory(userID)
-
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"user_id": userID.String(),
-			"history": history,
+			"portfolio_id":  portfolioID.String(),
+			"performance":   metrics.Performance,
+			"risk_metrics":  metrics.RiskMetrics,
+			"sharpe_ratio":  metrics.SharpeRatio,
+			"sortino_ratio": metrics.SortinoRatio,
+			"max_drawdown":  metrics.MaxDrawdown,
+			"volatility":    metrics.Volatility,
 		})
 	}
 }
 
-// Conversational AI handlers
-func handleChatMessage(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+func handlePortfolioHistory(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		userIDStr, ok := middleware.GetUserID(r.Context())
-		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
-			return
-		}
-
-		userID, err := uuid.Parse(userIDStr)
+		portfolioID, err := uuid.Parse(r.PathValue("portfolio_id"))
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
 			return
 		}
 
-		var req struct {
-			Message string `json:"message"`
+		historyRange := 7 * 24 * time.Hour
+		if value := r.URL.Query().Get("range"); value != "" {
+			if historyRange, err = analytics.ParseHistoryDuration(value); err != nil {
+				httputil.Error(w, r, "Invalid range", http.StatusBadRequest)
+				return
+			}
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
+		interval := time.Hour
+		if value := r.URL.Query().Get("interval"); value != "" {
+			if interval, err = analytics.ParseHistoryDuration(value); err != nil {
+				httputil.Error(w, r, "Invalid interval", http.StatusBadRequest)
+				return
+			}
 		}
 
-		response, err := conversationalAI.ProcessMessage(r.Context(), userID, req.Message)
+		history, err := portfolioAnalytics.GetValueHistory(r.Context(), portfolioID, historyRange, interval)
 		if err != nil {
-			logger.Error(r.Context(), "Chat message processing failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			switch {
+			case errors.Is(err, analytics.ErrInvalidHistoryQuery):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, analytics.ErrHistoryUnavailable):
+				httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+			case strings.Contains(err.Error(), "portfolio not found"):
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, err.Error())
+			default:
+				writeInternalError(w, r, logger, "Portfolio history retrieval failed", err)
+			}
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		json.NewEncoder(w).Encode(history)
 	}
 }
 
-func handleStartConversation(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+func handlePortfolioBenchmark(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		userIDStr, ok := middleware.GetUserID(r.Context())
-		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		portfolioID, err := uuid.Parse(r.PathValue("portfolio_id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
 			return
 		}
 
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
-			return
+		benchmark := r.URL.Query().Get("benchmark")
+		if benchmark == "" {
+			benchmark = "btc"
+		}
+		historyRange := 90 * 24 * time.Hour
+		if value := r.URL.Query().Get("range"); value != "" {
+			if historyRange, err = analytics.ParseHistoryDuration(value); err != nil {
+				httputil.Error(w, r, "Invalid range", http.StatusBadRequest)
+				return
+			}
 		}
 
-		conversation, err := conversationalAI.StartConversation(r.Context(), userID)
+		report, err := portfolioAnalytics.GetBenchmarkComparison(r.Context(), portfolioID, benchmark, historyRange)
 		if err != nil {
-			logger.Error(r.Context(), "Conversation start failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			switch {
+			case errors.Is(err, analytics.ErrInvalidBenchmark), errors.Is(err, analytics.ErrInvalidHistoryQuery):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, analytics.ErrHistoryUnavailable), errors.Is(err, analytics.ErrBenchmarkUnavailable):
+				httputil.Error(w, r, err.Error(), http.StatusServiceUnavailable)
+			case strings.Contains(err.Error(), "portfolio not found"):
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, err.Error())
+			default:
+				writeInternalError(w, r, logger, "Portfolio benchmark comparison failed", err)
+			}
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(conversation)
+		json.NewEncoder(w).Encode(report)
 	}
 }
 
-func handleMarketAnalysis(conversationalAI *ai.ConversationalAI, logger *observability.Logger) http.HandlerFunc {
+func handlePortfolioTaxReport(tradingEngine *web3.TradingEngine, portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userIDStr, ok := middleware.GetUserID(r.Context())
 		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
 			return
 		}
 
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
 			return
 		}
 
-		// Generate market analysis using conversational AI
-		response, err := conversationalAI.ProcessMessage(r.Context(), userID, "Give me a comprehensive market analysis")
+		portfolioID, err := uuid.Parse(r.PathValue("portfolio_id"))
 		if err != nil {
-			logger.Error(r.Context(), "Market analysis failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
+			return
+		}
+		portfolio, err := tradingEngine.GetPortfolio(portfolioID)
+		if err != nil || portfolio.UserID != userID {
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, "Portfolio not found")
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	}
-}
-
-// Real-time Market Data handlers
-func handleMarketDataStatus(marketDataService *realtime.MarketDataService, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		status := marketDataService.GetConnectionStatus()
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status":      "active",
-			"connections": status,
-			"timestamp":   time.Now(),
-		})
-	}
-}
-
-func handleMarketDataSubscribe(marketDataService *realtime.MarketDataService, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		symbol := strings.TrimPrefix(r.URL.Path, "/web3/realtime/market/subscribe/")
-
-		// Subscribe to market data updates
-		updateChan := marketDataService.Subscribe(symbol)
-
-		// Set up Server-Sent Events
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-
-		// Send initial connection message
-		fmt.Fprintf(w, "data: {\"type\":\"connected\",\"symbol\":\"%s\"}\n\n", symbol)
-		w.(http.Flusher).Flush()
-
-		// Stream market data updates
-		for {
-			select {
-			case update := <-updateChan:
-				data, _ := json.Marshal(update)
-				fmt.Fprintf(w, "data: %s\n\n", data)
-				w.(http.Flusher).Flush()
-			case <-r.Context().Done():
-				marketDataService.Unsubscribe(symbol, updateChan)
+		year := time.Now().UTC().Year()
+		if value := r.URL.Query().Get("year"); value != "" {
+			if year, err = strconv.Atoi(value); err != nil {
+				httputil.Error(w, r, "Invalid year", http.StatusBadRequest)
 				return
 			}
 		}
-	}
-}
-
-// Portfolio Analytics handlers
-func handlePortfolioAnalytics(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/analytics/portfolio/")
-		portfolioID, err := uuid.Parse(portfolioIDStr)
+		method, err := web3.ParseLotMethod(r.URL.Query().Get("method"))
 		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if format != "" && format != "json" && format != "csv" {
+			httputil.Error(w, r, "Invalid format, expected json or csv", http.StatusBadRequest)
 			return
 		}
 
-		metrics, err := portfolioAnalytics.GetPortfolioMetrics(r.Context(), portfolioID)
+		report, err := portfolioAnalytics.GetTaxReport(r.Context(), portfolioID, year, method)
 		if err != nil {
-			logger.Error(r.Context(), "Portfolio analytics retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			switch {
+			case errors.Is(err, analytics.ErrInvalidTaxYear):
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+			case strings.Contains(err.Error(), "portfolio not found"):
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, err.Error())
+			default:
+				writeInternalError(w, r, logger, "Tax report generation failed", err)
+			}
+			return
+		}
+
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.FileName()))
+			if err := report.WriteCSV(w); err != nil {
+				logger.Error(r.Context(), "Tax report write failed", err, map[string]interface{}{
+					"portfolio_id": portfolioID.String(),
+				})
+			}
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(metrics)
+		json.NewEncoder(w).Encode(report)
 	}
 }
 
-func handlePortfolioPerformance(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
+func handlePortfolioExport(portfolioAnalytics *analytics.PortfolioAnalytics, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		portfolioIDStr := strings.TrimPrefix(r.URL.Path, "/web3/analytics/portfolio/")
-		portfolioIDStr = strings.TrimSuffix(portfolioIDStr, "/performance")
-		portfolioID, err := uuid.Parse(portfolioIDStr)
+		portfolioID, err := uuid.Parse(r.PathValue("portfolio_id"))
 		if err != nil {
-			http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+			httputil.Error(w, r, "Invalid portfolio ID", http.StatusBadRequest)
 			return
 		}
 
-		metrics, err := portfolioAnalytics.GetPortfolioMetrics(r.Context(), portfolioID)
+		format, err := analytics.ParseExportFormat(r.URL.Query().Get("format"))
 		if err != nil {
-			logger.Error(r.Context(), "Portfolio performance retrieval failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httputil.Error(w, r, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"portfolio_id":  portfolioID.String(),
-			"performance":   metrics.Performance,
-			"risk_metrics":  metrics.RiskMetrics,
-			"sharpe_ratio":  metrics.SharpeRatio,
-			"sortino_ratio": metrics.SortinoRatio,
-			"max_drawdown":  metrics.MaxDrawdown,
-			"volatility":    metrics.Volatility,
-		})
+		export, err := portfolioAnalytics.GetPortfolioExport(r.Context(), portfolioID)
+		if err != nil {
+			if strings.Contains(err.Error(), "portfolio not found") {
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodePortfolioNotFound, err.Error())
+				return
+			}
+			writeInternalError(w, r, logger, "Portfolio export failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName(format)))
+		if err := export.Write(w, format); err != nil {
+			// Headers and part of the file are already sent, so the download is left truncated
+			logger.Error(r.Context(), "Portfolio export write failed", err, map[string]interface{}{
+				"portfolio_id": portfolioID.String(),
+				"format":       string(format),
+			})
+		}
 	}
 }
 
@@ -1202,7 +3020,7 @@ func handlePortfolioComparison(portfolioAnalytics *analytics.PortfolioAnalytics,
 	return func(w http.ResponseWriter, r *http.Request) {
 		portfolioIDsStr := r.URL.Query().Get("portfolio_ids")
 		if portfolioIDsStr == "" {
-			http.Error(w, "portfolio_ids parameter required", http.StatusBadRequest)
+			httputil.Error(w, r, "portfolio_ids parameter required", http.StatusBadRequest)
 			return
 		}
 
@@ -1212,7 +3030,7 @@ func handlePortfolioComparison(portfolioAnalytics *analytics.PortfolioAnalytics,
 		for _, idStr := range portfolioIDStrs {
 			id, err := uuid.Parse(strings.TrimSpace(idStr))
 			if err != nil {
-				http.Error(w, fmt.Sprintf("Invalid portfolio ID: %s", idStr), http.StatusBadRequest)
+				httputil.Error(w, r, fmt.Sprintf("Invalid portfolio ID: %s", idStr), http.StatusBadRequest)
 				return
 			}
 			portfolioIDs = append(portfolioIDs, id)
@@ -1220,8 +3038,7 @@ func handlePortfolioComparison(portfolioAnalytics *analytics.PortfolioAnalytics,
 
 		comparison, err := portfolioAnalytics.GetPortfolioComparison(r.Context(), portfolioIDs)
 		if err != nil {
-			logger.Error(r.Context(), "Portfolio comparison failed", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeInternalError(w, r, logger, "Portfolio comparison failed", err)
 			return
 		}
 
@@ -1270,6 +3087,9 @@ func handleSystemStatus(systemMonitor *monitoring.SystemMonitor, logger *observa
 }
 
 // Alert Management handlers
+
+// handleGetAlerts lists recent alerts; with missed=true only the caller's alerts that their
+// notification preferences suppressed or are holding back for quiet hours
 func handleGetAlerts(alertService *alerts.AlertService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		limitStr := r.URL.Query().Get("limit")
@@ -1280,7 +3100,22 @@ func handleGetAlerts(alertService *alerts.AlertService, logger *observability.Lo
 			}
 		}
 
-		alertList := alertService.GetAlerts(limit)
+		var alertList []alerts.Alert
+		if r.URL.Query().Get("missed") == "true" {
+			userIDStr, ok := middleware.GetUserID(r.Context())
+			if !ok {
+				httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+				return
+			}
+			userID, err := uuid.Parse(userIDStr)
+			if err != nil {
+				httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+				return
+			}
+			alertList = alertService.GetMissedAlerts(userID, limit)
+		} else {
+			alertList = alertService.GetAlerts(limit)
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -1311,7 +3146,7 @@ func handleResolveAlert(alertService *alerts.AlertService, logger *observability
 		err := alertService.ResolveAlert(alertID)
 		if err != nil {
 			logger.Error(r.Context(), "Alert resolution failed", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeAlertNotFound, err.Error())
 			return
 		}
 
@@ -1323,6 +3158,181 @@ func handleResolveAlert(alertService *alerts.AlertService, logger *observability
 	}
 }
 
+// handleGetNotificationPreferences returns the caller's notification preferences, or the
+// defaults when they never set any
+func handleGetNotificationPreferences(alertService *alerts.AlertService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		prefs, err := alertService.GetNotificationPreferences(r.Context(), userID)
+		if err != nil {
+			logger.Error(r.Context(), "Failed to get notification preferences", err)
+			httputil.Error(w, r, "Failed to get notification preferences", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(prefs)
+	}
+}
+
+// handleUpdateNotificationPreferences replaces the caller's notification preferences.
+// Categories left out stay enabled.
+func handleUpdateNotificationPreferences(alertService *alerts.AlertService, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			Channels   alerts.NotificationChannels `json:"channels"`
+			QuietHours *alerts.QuietHours          `json:"quiet_hours"`
+			Categories map[string]bool             `json:"categories"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		prefs := &alerts.NotificationPreferences{
+			UserID:     userID,
+			Channels:   req.Channels,
+			QuietHours: req.QuietHours,
+			Categories: req.Categories,
+		}
+		if err := alertService.UpdateNotificationPreferences(r.Context(), prefs); err != nil {
+			if errors.Is(err, alerts.ErrInvalidNotificationPreferences) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.Error(r.Context(), "Failed to update notification preferences", err)
+			httputil.Error(w, r, "Failed to update notification preferences", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(prefs)
+	}
+}
+
+func handleCreatePriceAlert(priceAlerts *alerts.PriceAlertManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req alerts.CreatePriceAlertRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+			return
+		}
+
+		rule, err := priceAlerts.CreateRule(r.Context(), userID, req)
+		if err != nil {
+			if errors.Is(err, alerts.ErrInvalidPriceAlert) {
+				httputil.Error(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.Error(r.Context(), "Price alert creation failed", err)
+			httputil.Error(w, r, "Failed to create price alert", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rule)
+	}
+}
+
+func handleListPriceAlerts(priceAlerts *alerts.PriceAlertManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		rules, err := priceAlerts.ListRules(r.Context(), userID)
+		if err != nil {
+			logger.Error(r.Context(), "Listing price alerts failed", err)
+			httputil.Error(w, r, "Failed to list price alerts", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"alerts": rules,
+			"count":  len(rules),
+		})
+	}
+}
+
+func handleDeletePriceAlert(priceAlerts *alerts.PriceAlertManager, logger *observability.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := middleware.GetUserID(r.Context())
+		if !ok {
+			httputil.Error(w, r, "User ID not found in context", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			httputil.Error(w, r, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		ruleID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			httputil.Error(w, r, "Invalid price alert ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := priceAlerts.DeleteRule(r.Context(), userID, ruleID); err != nil {
+			if errors.Is(err, alerts.ErrPriceAlertNotFound) {
+				httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeAlertNotFound, err.Error())
+				return
+			}
+			logger.Error(r.Context(), "Price alert deletion failed", err)
+			httputil.Error(w, r, "Failed to delete price alert", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func handleAlertSubscribe(alertService *alerts.AlertService, logger *observability.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		topic := strings.TrimPrefix(r.URL.Path, "/web3/alerts/subscribe/")
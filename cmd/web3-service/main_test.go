@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// priceAlertStore keeps rules in memory, scoped to their owner
+type priceAlertStore struct {
+	mu    sync.Mutex
+	rules map[uuid.UUID]alerts.PriceAlertRule
+}
+
+func (s *priceAlertStore) SaveRule(ctx context.Context, rule *alerts.PriceAlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[rule.ID] = *rule
+	return nil
+}
+
+func (s *priceAlertStore) ListRules(ctx context.Context, userID uuid.UUID) ([]*alerts.PriceAlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := make([]*alerts.PriceAlertRule, 0)
+	for _, rule := range s.rules {
+		if rule.UserID == userID {
+			rule := rule
+			rules = append(rules, &rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *priceAlertStore) ListActiveRules(ctx context.Context) ([]*alerts.PriceAlertRule, error) {
+	return nil, nil
+}
+
+func (s *priceAlertStore) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rule, exists := s.rules[ruleID]; !exists || rule.UserID != userID {
+		return alerts.ErrPriceAlertNotFound
+	}
+	delete(s.rules, ruleID)
+	return nil
+}
+
+func (s *priceAlertStore) RecordTrigger(ctx context.Context, ruleID uuid.UUID, triggeredAt time.Time, active bool) error {
+	return nil
+}
+
+// idleMarket hands out update streams that never publish
+type idleMarket struct{}
+
+func (idleMarket) Subscribe(symbol string, exchanges ...string) <-chan realtime.MarketUpdate {
+	return make(chan realtime.MarketUpdate)
+}
+
+func (idleMarket) Unsubscribe(symbol string, ch <-chan realtime.MarketUpdate) {}
+
+func TestPriceAlertHandlers(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	alertService := alerts.NewAlertService(logger, alerts.AlertConfig{MaxHistorySize: 100})
+	priceAlerts := alerts.NewPriceAlertManager(logger, &priceAlertStore{rules: make(map[uuid.UUID]alerts.PriceAlertRule)}, alertService, idleMarket{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /web3/alerts/price", handleCreatePriceAlert(priceAlerts, logger))
+	mux.HandleFunc("GET /web3/alerts/price", handleListPriceAlerts(priceAlerts, logger))
+	mux.HandleFunc("DELETE /web3/alerts/price/{id}", handleDeletePriceAlert(priceAlerts, logger))
+
+	owner, other := uuid.New().String(), uuid.New().String()
+	do := func(userID, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(owner, http.MethodPost, "/web3/alerts/price",
+		`{"symbol": "btc/usd", "condition": "percent_change", "threshold": "5", "window": "30m", "channel": "email", "repeat": true}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "BTCUSD", created["symbol"])
+	assert.Equal(t, "30m0s", created["window"])
+	assert.Equal(t, "15m0s", created["cooldown"])
+	assert.Equal(t, []interface{}{"email"}, created["channels"])
+	assert.Equal(t, true, created["active"])
+
+	for _, body := range []string{
+		`{"symbol": "BTCUSD", "condition": "crosses", "threshold": "5"}`,
+		`{"symbol": "BTCUSD", "condition": "above", "threshold": "-1"}`,
+		`{"symbol": "BTCUSD", "condition": "above", "threshold": "5", "channel": "sms"}`,
+		`{"symbol": `,
+	} {
+		assert.Equal(t, http.StatusBadRequest, do(owner, http.MethodPost, "/web3/alerts/price", body).Code, body)
+	}
+
+	list := func(userID string) []interface{} {
+		rec := do(userID, http.MethodGet, "/web3/alerts/price", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Alerts []interface{} `json:"alerts"`
+			Count  int           `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Len(t, body.Alerts, body.Count)
+		return body.Alerts
+	}
+	assert.Len(t, list(owner), 1)
+	assert.Empty(t, list(other), "alerts are listed per user")
+
+	path := "/web3/alerts/price/" + created["id"].(string)
+	assert.Equal(t, http.StatusNotFound, do(other, http.MethodDelete, path, "").Code, "users cannot delete each other's alerts")
+	assert.Equal(t, http.StatusBadRequest, do(owner, http.MethodDelete, "/web3/alerts/price/not-a-uuid", "").Code)
+	assert.Equal(t, http.StatusNoContent, do(owner, http.MethodDelete, path, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(owner, http.MethodDelete, path, "").Code)
+	assert.Empty(t, list(owner))
+}
+
+// unavailableStrategyStore fails every read and write
+type unavailableStrategyStore struct{}
+
+func (unavailableStrategyStore) Save(ctx context.Context, strategy *web3.RebalanceStrategy) error {
+	return errors.New("database unavailable")
+}
+
+func (unavailableStrategyStore) ListByPortfolio(ctx context.Context, portfolioID uuid.UUID) ([]*web3.RebalanceStrategy, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func (unavailableStrategyStore) ListActive(ctx context.Context) ([]*web3.RebalanceStrategy, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestRebalanceStrategyHandlers(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	clients := make(map[int]*ethclient.Client)
+	engine := web3.NewTradingEngine(clients, logger, web3.NewRiskAssessmentService(clients, logger))
+	rebalancer := web3.NewPortfolioRebalancer(logger, engine, web3.NewDeFiProtocolManager(logger))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /web3/rebalance/strategy", handleCreateRebalanceStrategy(rebalancer, logger))
+	mux.HandleFunc("GET /web3/rebalance/strategy/{portfolio_id}", handleGetRebalanceStrategy(rebalancer, logger))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	portfolioID := uuid.New().String()
+	create := `{"portfolio_id": "` + portfolioID + `", "name": "Half ETH", "type": "fixed", "target_allocations": {"ETH": "0.5", "USDC": "0.5"}}`
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/web3/rebalance/strategy/"+portfolioID, "").Code)
+	require.Equal(t, http.StatusCreated, do(http.MethodPost, "/web3/rebalance/strategy", create).Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/web3/rebalance/strategy/"+portfolioID, "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/web3/rebalance/strategy/not-a-uuid", "").Code)
+
+	for _, body := range []string{
+		`{"portfolio_id": "` + portfolioID + `", "type": "fixed", "target_allocations": {"ETH": "0.9"}}`,
+		`{"portfolio_id": "` + portfolioID + `", "type": "fixed", "target_allocations": {"ETH": "1"}, "schedule": {"frequency": "hourly"}}`,
+		`{"portfolio_id": "not-a-uuid"}`,
+		`{"portfolio_id": `,
+	} {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/web3/rebalance/strategy", body).Code, body)
+	}
+
+	// Store failures are server errors, not missing strategies
+	rebalancer.SetStrategyStore(unavailableStrategyStore{})
+	assert.Equal(t, http.StatusInternalServerError, do(http.MethodGet, "/web3/rebalance/strategy/"+portfolioID, "").Code)
+	assert.Equal(t, http.StatusInternalServerError, do(http.MethodPost, "/web3/rebalance/strategy", create).Code)
+}
@@ -281,6 +281,8 @@ func main() {
 			method,
 			constraints,
 			objective,
+			nil,
+			nil,
 		)
 		if err != nil {
 			log.Printf("Failed to optimize portfolio with %s: %v", method, err)
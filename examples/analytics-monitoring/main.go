@@ -126,16 +126,29 @@ func demoAnalyticsEngine(ctx context.Context, logger *observability.Logger) {
 			fmt.Printf("    🖥️  System Event: %s (Value: %.2f)\n", event.EventType, event.Metrics["cpu_usage"])
 		case <-timeout:
 			fmt.Printf("    ✅ Processed %d real-time events\n", eventCount)
+			printEngineStats(engine)
 			return
 		}
 
 		if eventCount >= 10 {
 			fmt.Printf("    ✅ Processed %d real-time events\n", eventCount)
+			printEngineStats(engine)
 			return
 		}
 	}
 }
 
+// printEngineStats prints the event flow of the analytics engine
+func printEngineStats(engine *analytics.RealTimeAnalyticsEngine) {
+	stats := engine.GetEngineStats()
+	fmt.Printf("    📦 Published %d events, dropped %d (%s policy, %d slow consumers)\n",
+		stats.EventsPublished, stats.EventsDropped, stats.OverflowPolicy, stats.SlowConsumers)
+	for _, stream := range stats.Streams {
+		fmt.Printf("       %s: %d delivered, %d dropped, buffer %.1f%% full\n",
+			stream.Name, stream.Delivered, stream.Dropped, stream.BufferUtilization)
+	}
+}
+
 // demoAnomalyDetection demonstrates anomaly detection capabilities
 func demoAnomalyDetection(ctx context.Context, logger *observability.Logger) {
 	fmt.Println("  Creating anomaly detection system...")
@@ -224,8 +237,8 @@ func demoPredictiveAnalytics(ctx context.Context, logger *observability.Logger)
 	// Generate historical data
 	fmt.Printf("    📊 Generating historical training data...\n")
 
-	baseTime := time.Now().Add(-24 * time.Hour)
-	for i := 0; i < 144; i++ { // 24 hours of 10-minute intervals
+	baseTime := time.Now().Add(-72 * time.Hour)
+	for i := 0; i < 432; i++ { // 3 days of 10-minute intervals, enough for daily seasonality
 		timestamp := baseTime.Add(time.Duration(i) * 10 * time.Minute)
 
 		// Simulate CPU usage with daily pattern
@@ -256,7 +269,7 @@ func demoPredictiveAnalytics(ctx context.Context, logger *observability.Logger)
 		analyzer.AddTrainingData("trading_volume", volumePoint)
 	}
 
-	fmt.Printf("    ✅ Generated 144 historical data points for each metric\n")
+	fmt.Printf("    ✅ Generated 432 historical data points for each metric\n")
 
 	// Create predictive models
 	cpuModel, err := analyzer.CreateModel("cpu_usage", analytics.ModelTypeLinearRegression, map[string]float64{})
@@ -265,7 +278,8 @@ func demoPredictiveAnalytics(ctx context.Context, logger *observability.Logger)
 		return
 	}
 
-	volumeModel, err := analyzer.CreateModel("trading_volume", analytics.ModelTypeExponentialSmoothing, map[string]float64{"alpha": 0.3})
+	// Auto picks the model with the lowest holdout error, such as Holt-Winters for seasonal volume
+	volumeModel, err := analyzer.CreateModel("trading_volume", analytics.ModelTypeAuto, map[string]float64{})
 	if err != nil {
 		fmt.Printf("    ❌ Error creating volume model: %v\n", err)
 		return
@@ -307,8 +321,9 @@ func demoPredictiveAnalytics(ctx context.Context, logger *observability.Logger)
 
 	fmt.Printf("    ✅ Volume Forecast (next 2 hours, confidence: %.1f%%):\n", volumeForecast.Confidence*100)
 	for _, prediction := range volumeForecast.Predictions[:3] {
-		fmt.Printf("      • %s: %.0f BTC (trend: %s)\n",
-			prediction.TargetTime.Format("15:04"), prediction.PredictedValue, prediction.Trend)
+		fmt.Printf("      • %s: %.0f BTC (trend: %s, %.0f trend + %.0f seasonal)\n",
+			prediction.TargetTime.Format("15:04"), prediction.PredictedValue, prediction.Trend,
+			prediction.TrendComponent, prediction.SeasonalComponent)
 	}
 }
 
@@ -390,6 +405,52 @@ func demoIntelligentAlerting(ctx context.Context, logger *observability.Logger)
 
 	fmt.Printf("    ✅ Created %d custom alert rules\n", 2)
 
+	// Slow responses usually follow CPU spikes, so report them as one incident
+	if err := alertManager.CreateCorrelationRule(&analytics.CorrelationRule{
+		Name:    "CPU saturation",
+		RuleIDs: []string{cpuRule.RuleID, responseRule.RuleID},
+		Enabled: true,
+	}); err != nil {
+		fmt.Printf("    ❌ Error creating correlation rule: %v\n", err)
+		return
+	}
+
+	// Critical alerts that nobody acknowledges escalate to Slack, then page whoever is on call
+	schedule := &analytics.OnCallSchedule{
+		Name: "Primary on-call",
+		Users: []analytics.OnCallUser{
+			{UserID: "alice", Name: "Alice", NotificationPreferences: []analytics.AlertAction{
+				{ActionType: analytics.ActionTypeSMS, Target: "+15550001", Enabled: true},
+			}},
+			{UserID: "bob", Name: "Bob", NotificationPreferences: []analytics.AlertAction{
+				{ActionType: analytics.ActionTypeEmail, Target: "bob@example.com", Enabled: true},
+			}},
+		},
+	}
+	if err := alertManager.CreateOnCallSchedule(schedule); err != nil {
+		fmt.Printf("    ❌ Error creating on-call schedule: %v\n", err)
+		return
+	}
+	policy := &analytics.EscalationPolicy{
+		Name:    "Critical escalation",
+		Enabled: true,
+		Steps: []analytics.EscalationStep{
+			{Delay: 5 * time.Minute, Actions: []analytics.AlertAction{{ActionType: analytics.ActionTypeSlack, Target: "#incidents", Enabled: true}}},
+			{Delay: 10 * time.Minute, Actions: []analytics.AlertAction{{ActionType: analytics.ActionTypePageOnCall, Target: schedule.ScheduleID, Enabled: true}}},
+		},
+	}
+	if err := alertManager.CreateEscalationPolicy(policy); err != nil {
+		fmt.Printf("    ❌ Error creating escalation policy: %v\n", err)
+		return
+	}
+	if err := alertManager.SetSeverityEscalationPolicy(analytics.SeverityCritical, policy.PolicyID); err != nil {
+		fmt.Printf("    ❌ Error attaching escalation policy: %v\n", err)
+		return
+	}
+	if onCall, err := alertManager.CurrentOnCall(schedule.ScheduleID); err == nil {
+		fmt.Printf("    ✅ Critical alerts escalate to %s, currently on call\n", onCall.Name)
+	}
+
 	// Simulate metric values that trigger alerts
 	fmt.Printf("    🚨 Simulating alert-triggering conditions...\n")
 
@@ -413,13 +474,18 @@ func demoIntelligentAlerting(ctx context.Context, logger *observability.Logger)
 			alert.RuleName, alert.Message, alert.Severity, alert.Value)
 	}
 
-	// Demonstrate alert acknowledgment
-	if len(activeAlerts) > 0 {
-		alertID := activeAlerts[0].AlertID
-		if err := alertManager.AcknowledgeAlert(alertID, "admin"); err != nil {
-			fmt.Printf("    ❌ Error acknowledging alert: %v\n", err)
+	fmt.Printf("    ✅ Escalating alerts: %d\n", len(alertManager.GetActiveEscalations()))
+
+	// Demonstrate incident acknowledgment, which acknowledges all of its alerts and stops
+	// their escalation
+	incidents := alertManager.GetIncidents(analytics.IncidentStatusOpen)
+	fmt.Printf("    ✅ Open incidents: %d\n", len(incidents))
+	if len(incidents) > 0 {
+		if _, err := alertManager.AcknowledgeIncident(incidents[0].IncidentID, "admin"); err != nil {
+			fmt.Printf("    ❌ Error acknowledging incident: %v\n", err)
 		} else {
-			fmt.Printf("    ✅ Alert acknowledged by admin\n")
+			fmt.Printf("    ✅ Incident with %d alerts acknowledged by admin\n", len(incidents[0].Alerts))
+			fmt.Printf("    ✅ Escalating alerts after acknowledgment: %d\n", len(alertManager.GetActiveEscalations()))
 		}
 	}
 }
@@ -502,6 +568,35 @@ func demoRealTimeDashboards(ctx context.Context, logger *observability.Logger) {
 
 	fmt.Printf("    ✅ Created custom trading dashboard with %d widgets\n", len(tradingDashboard.Widgets))
 
+	// Correlation matrices and heatmaps are computed server-side from recorded metrics
+	now := time.Now()
+	for i := 0; i < 24; i++ {
+		at := now.Add(-time.Duration(i) * time.Hour)
+		load := 50 + 30*math.Sin(float64(at.Hour())*math.Pi/12)
+		dashboardManager.RecordMetric("cpu_usage", load, at)
+		dashboardManager.RecordMetric("response_time", 100+4*load+rand.Float64()*20, at)
+	}
+	matrix, err := dashboardManager.ComputeCorrelationMatrix(ctx, analytics.CorrelationMatrixQuery{
+		Series:    []string{"cpu_usage", "response_time"},
+		TimeRange: analytics.TimeRange{Relative: "1d"},
+	})
+	if err != nil {
+		fmt.Printf("    ❌ Error computing correlation matrix: %v\n", err)
+	} else if r := matrix.Matrix[0][1]; r != nil {
+		fmt.Printf("    ✅ CPU and response time correlation: %.2f over %d hours\n", *r, matrix.Overlap[0][1])
+	}
+	heatmap, err := dashboardManager.ComputeHeatmap(ctx, analytics.HeatmapQuery{
+		Metric:    "cpu_usage",
+		TimeRange: analytics.TimeRange{Relative: "1d"},
+		X:         analytics.DimensionHourOfDay,
+		Y:         analytics.DimensionDayOfWeek,
+	})
+	if err != nil {
+		fmt.Printf("    ❌ Error computing heatmap: %v\n", err)
+	} else {
+		fmt.Printf("    ✅ CPU heatmap ranges from %.1f%% to %.1f%%\n", *heatmap.Min, *heatmap.Max)
+	}
+
 	// Export dashboard configuration
 	exportData, err := dashboardManager.ExportDashboard(tradingDashboard.DashboardID)
 	if err != nil {
@@ -170,6 +170,11 @@ func main() {
 		trading.OptimizationMethodMaxSharpe,
 		constraints,
 		objective,
+		map[string]trading.PortfolioHolding{
+			"BTC/USD": {Quantity: decimal.NewFromFloat(0.2), Price: decimal.NewFromInt(45000)},
+			"ETH/USD": {Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(3000)},
+		},
+		&trading.RebalanceCostModel{DefaultFeeBps: decimal.NewFromInt(10)},
 	)
 	if err != nil {
 		log.Printf("Failed to optimize portfolio: %v", err)
@@ -181,6 +186,11 @@ func main() {
 		for asset, weight := range portfolio.Weights {
 			fmt.Printf("        - %s: %.1f%%\n", asset, weight.InexactFloat64()*100)
 		}
+		if plan := portfolio.RebalancePlan; plan != nil {
+			fmt.Printf("      • Rebalance: %s (%d trades, cost $%.2f, net benefit $%.2f, breakeven %.2f%%)\n",
+				plan.Recommendation, len(plan.Trades), plan.EstimatedCost.InexactFloat64(),
+				plan.NetBenefit.InexactFloat64(), plan.BreakevenReturn.InexactFloat64()*100)
+		}
 	}
 
 	// Demo 5: Smart Order Router
@@ -0,0 +1,484 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recommendation bases: cohort recommendations serve new users until their own profile is
+// confident enough for personal ones
+const (
+	RecommendationBasisCohort   = "cohort"
+	RecommendationBasisPersonal = "personal"
+)
+
+// Cohort assignment sources
+const (
+	CohortSourceOnboarding = "onboarding"
+	CohortSourceEvents     = "events"
+)
+
+// Cohort dimensions
+const (
+	RiskAppetiteConservative = "conservative"
+	RiskAppetiteModerate     = "moderate"
+	RiskAppetiteAggressive   = "aggressive"
+
+	TradingFrequencyOccasional = "occasional" // less than a trade a day
+	TradingFrequencyActive     = "active"     // up to 5 trades a day
+	TradingFrequencyFrequent   = "frequent"
+
+	AssetFocusMajors    = "majors"
+	AssetFocusAltcoins  = "altcoins"
+	AssetFocusMixed     = "mixed"
+	AssetFocusUndecided = "undecided"
+)
+
+// cohortMinEvents is how many events a user without onboarding answers needs before being
+// assigned a cohort from them
+const cohortMinEvents = 3
+
+// cohortRecommendationTTL is how long a cohort recommendation stays before it is refreshed
+const cohortRecommendationTTL = 7 * 24 * time.Hour
+
+// majorAssets are the assets of the majors focus
+var majorAssets = map[string]bool{"BTC": true, "ETH": true}
+
+// ErrInvalidOnboarding is returned for onboarding answers outside the known cohort dimensions
+var ErrInvalidOnboarding = errors.New("invalid onboarding answers")
+
+// OnboardingAnswers are what a new user tells about their trading, used to place them in a
+// cohort before they have any history
+type OnboardingAnswers struct {
+	RiskAppetite     string   `json:"risk_appetite"`     // conservative, moderate, aggressive
+	TradingFrequency string   `json:"trading_frequency"` // occasional, active, frequent
+	PreferredAssets  []string `json:"preferred_assets"`
+}
+
+// CohortAssignment places a user in the behavior cohort of users with the same risk
+// appetite, trading frequency and asset focus
+type CohortAssignment struct {
+	ID               string    `json:"id"`
+	RiskAppetite     string    `json:"risk_appetite"`
+	TradingFrequency string    `json:"trading_frequency"`
+	AssetFocus       string    `json:"asset_focus"`
+	Assets           []string  `json:"assets,omitempty"`
+	Source           string    `json:"source"` // onboarding, events
+	AssignedAt       time.Time `json:"assigned_at"`
+}
+
+// PersonalizationStatus tells which recommendations a user gets: cohort ones until the
+// profile confidence reaches the threshold, personal ones after
+type PersonalizationStatus struct {
+	Basis      string     `json:"basis"`
+	Confidence float64    `json:"confidence"`
+	Threshold  float64    `json:"threshold"`
+	SwitchedAt *time.Time `json:"switched_at,omitempty"`
+}
+
+// BehaviorCohort summarizes the users of a cohort. Established members are those getting
+// personal recommendations; cohort recommendations are drawn from them.
+type BehaviorCohort struct {
+	ID                 string   `json:"id"`
+	RiskAppetite       string   `json:"risk_appetite"`
+	TradingFrequency   string   `json:"trading_frequency"`
+	AssetFocus         string   `json:"asset_focus"`
+	Members            int      `json:"members"`
+	EstablishedMembers int      `json:"established_members"`
+	AverageWinRate     float64  `json:"average_win_rate"`
+	TopAssets          []string `json:"top_assets"`
+}
+
+// SetOnboardingAnswers places a user in a cohort from their onboarding answers, creating their
+// profile. The cohort holds until the user gets personal recommendations.
+func (u *UserBehaviorLearningEngine) SetOnboardingAnswers(ctx context.Context, userID uuid.UUID, answers OnboardingAnswers) (*CohortAssignment, error) {
+	risk := strings.ToLower(answers.RiskAppetite)
+	switch risk {
+	case RiskAppetiteConservative, RiskAppetiteModerate, RiskAppetiteAggressive:
+	default:
+		return nil, fmt.Errorf("%w: risk_appetite must be conservative, moderate or aggressive", ErrInvalidOnboarding)
+	}
+	frequency := strings.ToLower(answers.TradingFrequency)
+	switch frequency {
+	case TradingFrequencyOccasional, TradingFrequencyActive, TradingFrequencyFrequent:
+	default:
+		return nil, fmt.Errorf("%w: trading_frequency must be occasional, active or frequent", ErrInvalidOnboarding)
+	}
+
+	assets := make([]string, 0, len(answers.PreferredAssets))
+	for _, asset := range answers.PreferredAssets {
+		if asset = strings.ToUpper(strings.TrimSpace(asset)); asset != "" {
+			assets = append(assets, asset)
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	profile := u.getUserProfile(userID)
+	profile.Cohort = newCohortAssignment(risk, frequency, assets, CohortSourceOnboarding)
+	if len(profile.TradingStyle.PreferredAssets) == 0 {
+		profile.TradingStyle.PreferredAssets = assets
+	}
+	u.updatePersonalization(profile)
+
+	u.logger.Info(ctx, "User assigned to behavior cohort", map[string]interface{}{
+		"user_id": userID,
+		"cohort":  profile.Cohort.ID,
+		"source":  profile.Cohort.Source,
+	})
+	return profile.Cohort, nil
+}
+
+// GetCohorts clusters the users with a cohort into their behavior cohorts
+func (u *UserBehaviorLearningEngine) GetCohorts(ctx context.Context) []*BehaviorCohort {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	cohorts := make(map[string]*BehaviorCohort)
+	assets := make(map[string]map[string]int)
+	for _, profile := range u.userProfiles {
+		if profile.Cohort == nil {
+			continue
+		}
+		cohort, exists := cohorts[profile.Cohort.ID]
+		if !exists {
+			cohort = &BehaviorCohort{
+				ID:               profile.Cohort.ID,
+				RiskAppetite:     profile.Cohort.RiskAppetite,
+				TradingFrequency: profile.Cohort.TradingFrequency,
+				AssetFocus:       profile.Cohort.AssetFocus,
+			}
+			cohorts[cohort.ID] = cohort
+			assets[cohort.ID] = make(map[string]int)
+		}
+		cohort.Members++
+		if isEstablished(profile) {
+			cohort.EstablishedMembers++
+			cohort.AverageWinRate += profile.PerformanceMetrics.WinRate
+			for _, asset := range profile.Cohort.Assets {
+				assets[cohort.ID][asset]++
+			}
+		}
+	}
+
+	list := make([]*BehaviorCohort, 0, len(cohorts))
+	for id, cohort := range cohorts {
+		if cohort.EstablishedMembers > 0 {
+			cohort.AverageWinRate /= float64(cohort.EstablishedMembers)
+		}
+		cohort.TopAssets = topCounts(assets[id], 3)
+		list = append(list, cohort)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Members > list[j].Members || (list[i].Members == list[j].Members && list[i].ID < list[j].ID)
+	})
+	return list
+}
+
+// updateCohort reassigns a user's cohort from their events, unless onboarding answers still
+// place them, and updates their personalization status. The caller must hold u.mu.
+func (u *UserBehaviorLearningEngine) updateCohort(profile *UserBehaviorProfile) {
+	u.updatePersonalization(profile)
+
+	onboarded := profile.Cohort != nil && profile.Cohort.Source == CohortSourceOnboarding
+	if onboarded && profile.Personalization.Basis == RecommendationBasisCohort {
+		return
+	}
+	history := u.behaviorHistory[profile.UserID]
+	if len(history) < cohortMinEvents {
+		return
+	}
+
+	risk := RiskAppetiteModerate
+	if profile.RiskProfile.Confidence > 0 {
+		risk = riskAppetiteOf(profile.RiskProfile.RiskTolerance)
+	}
+	assignment := newCohortAssignment(risk, tradingFrequencyOf(history), eventAssets(history), CohortSourceEvents)
+	if profile.Cohort != nil && profile.Cohort.ID == assignment.ID && profile.Cohort.Source == assignment.Source {
+		profile.Cohort.Assets = assignment.Assets
+		return
+	}
+	profile.Cohort = assignment
+}
+
+// updatePersonalization switches a profile to personal recommendations once its confidence
+// reaches the threshold. The caller must hold u.mu.
+func (u *UserBehaviorLearningEngine) updatePersonalization(profile *UserBehaviorProfile) {
+	if profile.Personalization == nil {
+		profile.Personalization = &PersonalizationStatus{Basis: RecommendationBasisCohort}
+	}
+	status := profile.Personalization
+	status.Confidence = profile.Confidence
+	status.Threshold = u.config.ConfidenceThreshold
+	if status.Basis == RecommendationBasisCohort && profile.Confidence >= status.Threshold {
+		now := time.Now()
+		status.Basis = RecommendationBasisPersonal
+		status.SwitchedAt = &now
+	}
+}
+
+// refreshCohortRecommendationsLocked adds the current recommendations of the profile's cohort
+// to its recommendations, keeping those it already has unless they expired so their status
+// sticks. The caller must hold u.mu.
+func (u *UserBehaviorLearningEngine) refreshCohortRecommendationsLocked(profile *UserBehaviorProfile) {
+	existing := make(map[string]int, len(profile.Recommendations))
+	for i, rec := range profile.Recommendations {
+		existing[rec.ID] = i
+	}
+	now := time.Now()
+	for _, rec := range u.cohortRecommendationsLocked(profile) {
+		i, exists := existing[rec.ID]
+		switch {
+		case !exists:
+			profile.Recommendations = append(profile.Recommendations, rec)
+		case profile.Recommendations[i].ExpiresAt != nil && profile.Recommendations[i].ExpiresAt.Before(now):
+			profile.Recommendations[i] = rec
+		}
+	}
+}
+
+// servesRecommendation reports whether a recommendation matches the basis a profile gets:
+// personal ones once established, otherwise those of its current cohort
+func servesRecommendation(profile *UserBehaviorProfile, rec *PersonalizedRecommendation) bool {
+	if isEstablished(profile) {
+		return rec.Basis != RecommendationBasisCohort
+	}
+	return rec.Basis == RecommendationBasisCohort && profile.Cohort != nil && rec.Metadata["cohort_id"] == profile.Cohort.ID
+}
+
+// cohortRecommendationsLocked recommends what established members of the profile's cohort
+// do and accepted. The caller must hold u.mu.
+func (u *UserBehaviorLearningEngine) cohortRecommendationsLocked(profile *UserBehaviorProfile) []*PersonalizedRecommendation {
+	cohort := profile.Cohort
+	if cohort == nil {
+		return []*PersonalizedRecommendation{}
+	}
+
+	members := 0
+	assets := make(map[string]int)
+	accepted := make(map[string]int)
+	acceptedExamples := make(map[string]*PersonalizedRecommendation)
+	for _, member := range u.userProfiles {
+		if member.UserID == profile.UserID || member.Cohort == nil || member.Cohort.ID != cohort.ID || !isEstablished(member) {
+			continue
+		}
+		members++
+		for _, asset := range member.Cohort.Assets {
+			assets[asset]++
+		}
+		for _, rec := range member.Recommendations {
+			if rec.Status == "accepted" {
+				accepted[rec.Title]++
+				acceptedExamples[rec.Title] = rec
+			}
+		}
+	}
+
+	confidence := math.Min(0.7, 0.3+0.05*float64(members))
+	overallRisk := map[string]float64{
+		RiskAppetiteConservative: 0.2,
+		RiskAppetiteModerate:     0.4,
+		RiskAppetiteAggressive:   0.7,
+	}[cohort.RiskAppetite]
+	now := time.Now()
+	expiresAt := now.Add(cohortRecommendationTTL)
+	basis := func(rec *PersonalizedRecommendation) *PersonalizedRecommendation {
+		rec.Basis = RecommendationBasisCohort
+		rec.Confidence = confidence
+		rec.CreatedAt = now
+		rec.ExpiresAt = &expiresAt
+		rec.Status = "pending"
+		if rec.Parameters == nil {
+			rec.Parameters = make(map[string]interface{})
+		}
+		if rec.ExpectedOutcome == nil {
+			rec.ExpectedOutcome = &UserExpectedOutcome{ProbabilityOfSuccess: confidence, TimeHorizon: 30 * 24 * time.Hour}
+		}
+		if rec.RiskAssessment == nil {
+			rec.RiskAssessment = &RecommendationRisk{
+				OverallRisk: overallRisk,
+				RiskFactors: []*BehaviorRiskFactor{},
+				Mitigation:  []string{"Start with small position sizes"},
+			}
+		}
+		rec.Personalization = &PersonalizationInfo{
+			PersonalizationScore: confidence,
+			UserFactors:          []string{"risk_appetite", "trading_frequency", "asset_focus"},
+			BehaviorFactors:      []string{"cohort_behavior"},
+			Adaptations:          []string{"cohort_cold_start"},
+		}
+		rec.Metadata = map[string]interface{}{"cohort_id": cohort.ID, "cohort_members": members}
+		return rec
+	}
+
+	sizing := map[string]float64{
+		RiskAppetiteConservative: 0.01,
+		RiskAppetiteModerate:     0.02,
+		RiskAppetiteAggressive:   0.05,
+	}[cohort.RiskAppetite]
+	recommendations := []*PersonalizedRecommendation{basis(&PersonalizedRecommendation{
+		ID:          fmt.Sprintf("cohort-%s-sizing", cohort.ID),
+		Type:        "risk",
+		Title:       fmt.Sprintf("Risk up to %.0f%% per trade", sizing*100),
+		Description: fmt.Sprintf("Traders with a %s risk appetite who trade %s keep each position within %.0f%% of their portfolio", cohort.RiskAppetite, frequencyPhrase(cohort.TradingFrequency), sizing*100),
+		Reasoning:   []string{fmt.Sprintf("Based on your %s cohort until we learn your own trading", cohort.ID)},
+		Priority:    "medium",
+		Category:    "risk_management",
+		Parameters:  map[string]interface{}{"max_position_percent": sizing},
+	})}
+
+	if top := topCounts(assets, 3); len(top) > 0 {
+		recommendations = append(recommendations, basis(&PersonalizedRecommendation{
+			ID:          fmt.Sprintf("cohort-%s-assets", cohort.ID),
+			Type:        "trade",
+			Title:       "Assets traders like you follow",
+			Description: fmt.Sprintf("Established traders in your cohort trade %s most", strings.Join(top, ", ")),
+			Reasoning:   []string{fmt.Sprintf("Most traded by %d traders in your cohort", members)},
+			Priority:    "low",
+			Category:    "discovery",
+			Parameters:  map[string]interface{}{"assets": top},
+		}))
+	}
+
+	for i, title := range topCounts(accepted, 2) {
+		example := acceptedExamples[title]
+		recommendations = append(recommendations, basis(&PersonalizedRecommendation{
+			ID:              fmt.Sprintf("cohort-%s-accepted-%d", cohort.ID, i+1),
+			Type:            example.Type,
+			Title:           example.Title,
+			Description:     example.Description,
+			Reasoning:       []string{fmt.Sprintf("Accepted by %d traders in your cohort", accepted[title])},
+			Priority:        "medium",
+			Category:        example.Category,
+			ExpectedOutcome: example.ExpectedOutcome,
+			RiskAssessment:  example.RiskAssessment,
+		}))
+	}
+	return recommendations
+}
+
+// isEstablished reports whether a profile gets personal recommendations
+func isEstablished(profile *UserBehaviorProfile) bool {
+	return profile.Personalization != nil && profile.Personalization.Basis == RecommendationBasisPersonal
+}
+
+// newCohortAssignment builds the assignment to the cohort of the given dimensions
+func newCohortAssignment(risk, frequency string, assets []string, source string) *CohortAssignment {
+	focus := AssetFocusUndecided
+	if len(assets) > 0 {
+		majors := 0
+		for _, asset := range assets {
+			if majorAssets[asset] {
+				majors++
+			}
+		}
+		switch {
+		case majors == len(assets):
+			focus = AssetFocusMajors
+		case majors == 0:
+			focus = AssetFocusAltcoins
+		default:
+			focus = AssetFocusMixed
+		}
+	}
+	return &CohortAssignment{
+		ID:               fmt.Sprintf("%s-%s-%s", risk, frequency, focus),
+		RiskAppetite:     risk,
+		TradingFrequency: frequency,
+		AssetFocus:       focus,
+		Assets:           assets,
+		Source:           source,
+		AssignedAt:       time.Now(),
+	}
+}
+
+// riskAppetiteOf buckets a learned risk tolerance
+func riskAppetiteOf(tolerance float64) string {
+	switch {
+	case tolerance < 0.35:
+		return RiskAppetiteConservative
+	case tolerance < 0.65:
+		return RiskAppetiteModerate
+	default:
+		return RiskAppetiteAggressive
+	}
+}
+
+// tradingFrequencyOf buckets the trades a day of a behavior history, over at least a day
+func tradingFrequencyOf(history []*BehaviorEvent) string {
+	trades := 0
+	first, last := history[0].Timestamp, history[0].Timestamp
+	for _, event := range history {
+		if event.Type == "trade" {
+			trades++
+		}
+		if event.Timestamp.Before(first) {
+			first = event.Timestamp
+		}
+		if event.Timestamp.After(last) {
+			last = event.Timestamp
+		}
+	}
+	days := math.Max(1, last.Sub(first).Hours()/24)
+	switch perDay := float64(trades) / days; {
+	case perDay < 1:
+		return TradingFrequencyOccasional
+	case perDay <= 5:
+		return TradingFrequencyActive
+	default:
+		return TradingFrequencyFrequent
+	}
+}
+
+// eventAssets returns the three assets a behavior history mentions most, from the symbol or
+// asset metadata of its events
+func eventAssets(history []*BehaviorEvent) []string {
+	counts := make(map[string]int)
+	for _, event := range history {
+		for _, key := range []string{"symbol", "asset"} {
+			if asset, ok := event.Metadata[key].(string); ok && asset != "" {
+				counts[strings.ToUpper(asset)]++
+				break
+			}
+		}
+	}
+	return topCounts(counts, 3)
+}
+
+// topCounts returns up to n keys with the highest counts, ties by key
+func topCounts(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// frequencyPhrase describes a trading frequency in a sentence
+func frequencyPhrase(frequency string) string {
+	switch frequency {
+	case TradingFrequencyOccasional:
+		return "occasionally"
+	case TradingFrequencyFrequent:
+		return "frequently"
+	default:
+		return "actively"
+	}
+}
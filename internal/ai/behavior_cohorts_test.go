@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCohortColdStart(t *testing.T) {
+	ctx := context.Background()
+	engine := NewUserBehaviorLearningEngine(&observability.Logger{})
+
+	// An established user whose cohort recommendations are drawn from
+	veteran := uuid.New()
+	for i := 0; i < 30; i++ {
+		err := engine.LearnFromBehavior(ctx, &BehaviorEvent{
+			ID:        uuid.New().String(),
+			UserID:    veteran,
+			Type:      "trade",
+			Action:    "buy",
+			Outcome:   &BehaviorOutcome{Success: true, Performance: 0.02},
+			Timestamp: time.Now().Add(-time.Duration(i) * time.Hour),
+			Metadata:  map[string]interface{}{"symbol": "btc"},
+		})
+		require.NoError(t, err)
+	}
+	profile, err := engine.GetUserProfile(ctx, veteran)
+	require.NoError(t, err)
+	require.Equal(t, RecommendationBasisPersonal, profile.Personalization.Basis)
+	require.NotNil(t, profile.Personalization.SwitchedAt)
+	require.NotNil(t, profile.Cohort)
+	assert.Equal(t, CohortSourceEvents, profile.Cohort.Source)
+	assert.Equal(t, []string{"BTC"}, profile.Cohort.Assets)
+
+	t.Run("Onboarding", func(t *testing.T) {
+		_, err := engine.SetOnboardingAnswers(ctx, uuid.New(), OnboardingAnswers{RiskAppetite: "yolo", TradingFrequency: "active"})
+		assert.ErrorIs(t, err, ErrInvalidOnboarding)
+
+		newcomer := uuid.New()
+		cohort, err := engine.SetOnboardingAnswers(ctx, newcomer, OnboardingAnswers{
+			RiskAppetite:     profile.Cohort.RiskAppetite,
+			TradingFrequency: profile.Cohort.TradingFrequency,
+			PreferredAssets:  []string{"btc"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, profile.Cohort.ID, cohort.ID)
+
+		recommendations, err := engine.GetPersonalizedRecommendations(ctx, newcomer, 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, recommendations)
+		titles := make(map[string]bool)
+		for _, rec := range recommendations {
+			assert.Equal(t, RecommendationBasisCohort, rec.Basis)
+			assert.Equal(t, cohort.ID, rec.Metadata["cohort_id"])
+			titles[rec.Title] = true
+		}
+		assert.True(t, titles["Assets traders like you follow"])
+
+		// Status updates stick across requests
+		require.NoError(t, engine.UpdateRecommendationStatus(ctx, newcomer, recommendations[0].ID, "rejected"))
+		again, err := engine.GetPersonalizedRecommendations(ctx, newcomer, 10)
+		require.NoError(t, err)
+		assert.Len(t, again, len(recommendations)-1)
+	})
+
+	t.Run("Switchover", func(t *testing.T) {
+		userID := uuid.New()
+		for i := 0; i < 30; i++ {
+			require.NoError(t, engine.LearnFromBehavior(ctx, &BehaviorEvent{
+				ID:        uuid.New().String(),
+				UserID:    userID,
+				Type:      "trade",
+				Action:    "buy",
+				Outcome:   &BehaviorOutcome{Success: true},
+				Timestamp: time.Now(),
+			}))
+
+			recommendations, err := engine.GetPersonalizedRecommendations(ctx, userID, 10)
+			require.NoError(t, err)
+			profile, err := engine.GetUserProfile(ctx, userID)
+			require.NoError(t, err)
+			for _, rec := range recommendations {
+				if profile.Personalization.Basis == RecommendationBasisPersonal {
+					assert.Equal(t, RecommendationBasisPersonal, rec.Basis)
+				} else {
+					assert.Equal(t, RecommendationBasisCohort, rec.Basis)
+				}
+			}
+		}
+		profile, err := engine.GetUserProfile(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, RecommendationBasisPersonal, profile.Personalization.Basis)
+		assert.GreaterOrEqual(t, profile.Personalization.Confidence, profile.Personalization.Threshold)
+	})
+
+	t.Run("Cohorts", func(t *testing.T) {
+		cohorts := engine.GetCohorts(ctx)
+		require.NotEmpty(t, cohorts)
+		var found *BehaviorCohort
+		for _, cohort := range cohorts {
+			if cohort.ID == profile.Cohort.ID {
+				found = cohort
+			}
+		}
+		require.NotNil(t, found)
+		assert.GreaterOrEqual(t, found.Members, 2)
+		assert.GreaterOrEqual(t, found.EstablishedMembers, 1)
+		assert.Contains(t, found.TopAssets, "BTC")
+	})
+}
@@ -0,0 +1,409 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/google/uuid"
+)
+
+// BehaviorProfileExportVersion is the version of exported behavior profile documents. Imports
+// of other versions are rejected.
+const BehaviorProfileExportVersion = 1
+
+// Behavior profile import modes
+const (
+	BehaviorImportMerge   = "merge"
+	BehaviorImportReplace = "replace"
+)
+
+var (
+	// ErrBehaviorProfileNotFound is returned when a user has no behavior profile
+	ErrBehaviorProfileNotFound = errors.New("behavior profile not found")
+	// ErrInvalidBehaviorImport is returned for import documents of another version, without a
+	// profile, or with an unknown mode
+	ErrInvalidBehaviorImport = errors.New("invalid behavior profile import")
+)
+
+// BehaviorProfileExport is a portable copy of a user's learned behavior profile, used to move
+// it between environments
+type BehaviorProfileExport struct {
+	Version         int                      `json:"version"`
+	ExportedAt      time.Time                `json:"exported_at"`
+	UserID          uuid.UUID                `json:"user_id"`
+	Profile         *UserBehaviorProfile     `json:"profile"`
+	Preferences     *UserBehaviorPreferences `json:"preferences"`
+	ModelParameters BehaviorModelParameters  `json:"model_parameters"`
+}
+
+// BehaviorModelParameters are the learning parameters a profile was built with
+type BehaviorModelParameters struct {
+	LearningRate            float64 `json:"learning_rate"`
+	MinObservations         int     `json:"min_observations"`
+	PreferenceUpdateRate    float64 `json:"preference_update_rate"`
+	PersonalityUpdateRate   float64 `json:"personality_update_rate"`
+	RiskToleranceUpdateRate float64 `json:"risk_tolerance_update_rate"`
+	RecommendationThreshold float64 `json:"recommendation_threshold"`
+	ConfidenceThreshold     float64 `json:"confidence_threshold"`
+}
+
+// BehaviorProfileImportRequest imports an exported profile, merging it into the user's
+// current profile or replacing it. Dry runs only report the changes.
+type BehaviorProfileImportRequest struct {
+	Mode     string                `json:"mode"`
+	DryRun   bool                  `json:"dry_run"`
+	Document BehaviorProfileExport `json:"document"`
+}
+
+// BehaviorProfileChange is a profile section an import changes
+type BehaviorProfileChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// BehaviorProfileImportResult reports the changes of an import and whether they were applied
+type BehaviorProfileImportResult struct {
+	Mode     string                  `json:"mode"`
+	DryRun   bool                    `json:"dry_run"`
+	Applied  bool                    `json:"applied"`
+	Changes  []BehaviorProfileChange `json:"changes"`
+	Warnings []string                `json:"warnings,omitempty"`
+}
+
+// BehaviorProfileReset reports what resetting a user's learning deleted
+type BehaviorProfileReset struct {
+	ProfileDeleted         bool `json:"profile_deleted"`
+	EventsDeleted          int  `json:"events_deleted"`
+	RecommendationsDeleted int  `json:"recommendations_deleted"`
+}
+
+// SetAuditor records profile imports and resets in the audit log
+func (u *UserBehaviorLearningEngine) SetAuditor(auditor *security.AuditManager) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.auditor = auditor
+}
+
+// ExportUserProfile returns a versioned copy of a user's profile. Recommendations are left out:
+// they are derived from the profile and regenerated after an import.
+func (u *UserBehaviorLearningEngine) ExportUserProfile(ctx context.Context, userID uuid.UUID) (*BehaviorProfileExport, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	profile, exists := u.userProfiles[userID]
+	if !exists {
+		return nil, fmt.Errorf("%w for user %s", ErrBehaviorProfileNotFound, userID)
+	}
+
+	exported, err := cloneBehaviorProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	exported.Recommendations = nil
+
+	return &BehaviorProfileExport{
+		Version:         BehaviorProfileExportVersion,
+		ExportedAt:      time.Now(),
+		UserID:          userID,
+		Profile:         exported,
+		Preferences:     exported.Preferences,
+		ModelParameters: u.modelParameters(),
+	}, nil
+}
+
+// ImportUserProfile merges an exported profile into the user's profile, or replaces it. Merges
+// keep the more confident of each learned section, union behavior patterns and add up
+// observations. Applied imports drop the user's recommendations, which are regenerated from
+// the imported profile, and are recorded in the audit log.
+func (u *UserBehaviorLearningEngine) ImportUserProfile(ctx context.Context, userID uuid.UUID, req BehaviorProfileImportRequest) (*BehaviorProfileImportResult, error) {
+	if req.Mode == "" {
+		req.Mode = BehaviorImportMerge
+	}
+	if req.Mode != BehaviorImportMerge && req.Mode != BehaviorImportReplace {
+		return nil, fmt.Errorf("%w: mode must be %q or %q", ErrInvalidBehaviorImport, BehaviorImportMerge, BehaviorImportReplace)
+	}
+	if req.Document.Version != BehaviorProfileExportVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d, expected %d", ErrInvalidBehaviorImport, req.Document.Version, BehaviorProfileExportVersion)
+	}
+	if req.Document.Profile == nil {
+		return nil, fmt.Errorf("%w: document has no profile", ErrInvalidBehaviorImport)
+	}
+
+	imported, err := cloneBehaviorProfile(req.Document.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if req.Document.Preferences != nil {
+		imported.Preferences = req.Document.Preferences
+	}
+	imported.UserID = userID
+	imported.Recommendations = []*PersonalizedRecommendation{}
+	fillBehaviorProfile(imported)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	current := u.userProfiles[userID]
+	next := imported
+	if current != nil && req.Mode == BehaviorImportMerge {
+		if next, err = cloneBehaviorProfile(current); err != nil {
+			return nil, err
+		}
+		mergeBehaviorProfile(next, imported)
+		next.Recommendations = []*PersonalizedRecommendation{}
+	}
+	next.LastUpdated = time.Now()
+	u.updateLearningProgress(next)
+	u.updateCohort(next)
+
+	changes, err := diffBehaviorProfiles(current, next)
+	if err != nil {
+		return nil, err
+	}
+	result := &BehaviorProfileImportResult{
+		Mode:    req.Mode,
+		DryRun:  req.DryRun,
+		Changes: changes,
+	}
+	if req.Document.ModelParameters != u.modelParameters() {
+		result.Warnings = append(result.Warnings, "profile was learned with different model parameters; confidence may not be comparable")
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	details := map[string]interface{}{
+		"mode":           req.Mode,
+		"source_user_id": req.Document.UserID.String(),
+		"changes":        len(changes),
+	}
+	if err := u.auditLocked(ctx, userID, "behavior_profile_import", details); err != nil {
+		return nil, err
+	}
+
+	u.userProfiles[userID] = next
+	if next.ObservationCount >= u.config.MinObservations {
+		if err := u.generateRecommendations(ctx, next); err != nil {
+			u.logger.Warn(ctx, "Failed to generate recommendations", map[string]interface{}{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+		}
+	}
+	result.Applied = true
+
+	u.logger.Info(ctx, "User behavior profile imported", map[string]interface{}{
+		"user_id": userID,
+		"mode":    req.Mode,
+		"changes": len(changes),
+	})
+	return result, nil
+}
+
+// ResetUserProfile deletes a user's profile, its recommendations and the behavior history, so
+// learning starts over. The reset is recorded in the audit log first and doesn't happen when
+// that fails.
+func (u *UserBehaviorLearningEngine) ResetUserProfile(ctx context.Context, userID uuid.UUID) (*BehaviorProfileReset, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	reset := &BehaviorProfileReset{EventsDeleted: len(u.behaviorHistory[userID])}
+	if profile, exists := u.userProfiles[userID]; exists {
+		reset.ProfileDeleted = true
+		reset.RecommendationsDeleted = len(profile.Recommendations)
+	}
+
+	details := map[string]interface{}{
+		"profile_deleted":         reset.ProfileDeleted,
+		"events_deleted":          reset.EventsDeleted,
+		"recommendations_deleted": reset.RecommendationsDeleted,
+	}
+	if err := u.auditLocked(ctx, userID, "behavior_profile_reset", details); err != nil {
+		return nil, err
+	}
+
+	delete(u.userProfiles, userID)
+	delete(u.behaviorHistory, userID)
+
+	u.logger.Info(ctx, "User behavior profile reset", map[string]interface{}{
+		"user_id":                 userID,
+		"events_deleted":          reset.EventsDeleted,
+		"recommendations_deleted": reset.RecommendationsDeleted,
+	})
+	return reset, nil
+}
+
+// auditLocked records a change of a user's profile in the audit log, when one is set. The
+// caller must hold u.mu.
+func (u *UserBehaviorLearningEngine) auditLocked(ctx context.Context, userID uuid.UUID, action string, details map[string]interface{}) error {
+	if u.auditor == nil {
+		return nil
+	}
+	err := u.auditor.LogEvent(ctx, &security.AuditEvent{
+		EventType:     security.AuditEventTypeDataModification,
+		Category:      security.AuditCategoryBusiness,
+		Severity:      security.AuditSeverityMedium,
+		UserID:        &userID,
+		Resource:      "behavior_profile",
+		Action:        action,
+		Result:        security.AuditResultSuccess,
+		Details:       details,
+		ComplianceTag: "DATA",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to audit %s: %w", action, err)
+	}
+	return nil
+}
+
+// modelParameters returns the learning parameters of the engine
+func (u *UserBehaviorLearningEngine) modelParameters() BehaviorModelParameters {
+	return BehaviorModelParameters{
+		LearningRate:            u.config.LearningRate,
+		MinObservations:         u.config.MinObservations,
+		PreferenceUpdateRate:    u.config.PreferenceUpdateRate,
+		PersonalityUpdateRate:   u.config.PersonalityUpdateRate,
+		RiskToleranceUpdateRate: u.config.RiskToleranceUpdateRate,
+		RecommendationThreshold: u.config.RecommendationThreshold,
+		ConfidenceThreshold:     u.config.ConfidenceThreshold,
+	}
+}
+
+// cloneBehaviorProfile deep copies a profile
+func cloneBehaviorProfile(profile *UserBehaviorProfile) (*UserBehaviorProfile, error) {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy behavior profile: %w", err)
+	}
+	var clone UserBehaviorProfile
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to copy behavior profile: %w", err)
+	}
+	return &clone, nil
+}
+
+// fillBehaviorProfile sets the sections an imported profile lacks the way new profiles start
+func fillBehaviorProfile(profile *UserBehaviorProfile) {
+	if profile.CreatedAt.IsZero() {
+		profile.CreatedAt = time.Now()
+	}
+	if profile.TradingStyle == nil {
+		profile.TradingStyle = &TradingStyleProfile{}
+	}
+	if profile.RiskProfile == nil {
+		profile.RiskProfile = &UserRiskProfile{}
+	}
+	if profile.PersonalityProfile == nil {
+		profile.PersonalityProfile = &PersonalityProfile{}
+	}
+	if profile.PersonalityProfile.Traits == nil {
+		profile.PersonalityProfile.Traits = make(map[string]float64)
+	}
+	if profile.Preferences == nil {
+		profile.Preferences = &UserBehaviorPreferences{}
+	}
+	if profile.BehaviorPatterns == nil {
+		profile.BehaviorPatterns = []*BehaviorPattern{}
+	}
+	if profile.PerformanceMetrics == nil {
+		profile.PerformanceMetrics = &UserPerformanceProfile{}
+	}
+	if profile.LearningProgress == nil {
+		profile.LearningProgress = &LearningProgress{}
+	}
+	if profile.LearningProgress.Milestones == nil {
+		profile.LearningProgress.Milestones = []*LearningMilestone{}
+	}
+	if profile.Metadata == nil {
+		profile.Metadata = make(map[string]interface{})
+	}
+}
+
+// mergeBehaviorProfile merges imported into profile: each learned section keeps the more
+// confident side, patterns are unioned by ID and observations add up
+func mergeBehaviorProfile(profile, imported *UserBehaviorProfile) {
+	if imported.TradingStyle.Confidence > profile.TradingStyle.Confidence {
+		profile.TradingStyle = imported.TradingStyle
+	}
+	if imported.RiskProfile.Confidence > profile.RiskProfile.Confidence {
+		profile.RiskProfile = imported.RiskProfile
+	}
+	if imported.PersonalityProfile.Confidence > profile.PersonalityProfile.Confidence {
+		profile.PersonalityProfile = imported.PersonalityProfile
+	}
+	if imported.Preferences.Confidence > profile.Preferences.Confidence {
+		profile.Preferences = imported.Preferences
+	}
+	if imported.PerformanceMetrics.Confidence > profile.PerformanceMetrics.Confidence {
+		profile.PerformanceMetrics = imported.PerformanceMetrics
+	}
+
+	patterns := make(map[string]int, len(profile.BehaviorPatterns))
+	for i, pattern := range profile.BehaviorPatterns {
+		patterns[pattern.ID] = i
+	}
+	for _, pattern := range imported.BehaviorPatterns {
+		i, exists := patterns[pattern.ID]
+		switch {
+		case !exists:
+			profile.BehaviorPatterns = append(profile.BehaviorPatterns, pattern)
+		case pattern.Confidence > profile.BehaviorPatterns[i].Confidence:
+			profile.BehaviorPatterns[i] = pattern
+		}
+	}
+
+	for key, value := range imported.Metadata {
+		if _, exists := profile.Metadata[key]; !exists {
+			profile.Metadata[key] = value
+		}
+	}
+	if imported.CreatedAt.Before(profile.CreatedAt) {
+		profile.CreatedAt = imported.CreatedAt
+	}
+	profile.ObservationCount += imported.ObservationCount
+}
+
+// diffBehaviorProfiles lists the top-level profile fields that differ between before, which
+// may be nil, and after. Timestamps of the update itself are ignored.
+func diffBehaviorProfiles(before, after *UserBehaviorProfile) ([]BehaviorProfileChange, error) {
+	fields := func(profile *UserBehaviorProfile) (map[string]json.RawMessage, error) {
+		values := make(map[string]json.RawMessage)
+		if profile == nil {
+			return values, nil
+		}
+		data, err := json.Marshal(profile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare behavior profiles: %w", err)
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to compare behavior profiles: %w", err)
+		}
+		delete(values, "last_updated")
+		return values, nil
+	}
+
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []BehaviorProfileChange{}
+	for field, value := range afterFields {
+		if previous, exists := beforeFields[field]; !exists || !bytes.Equal(previous, value) {
+			changes = append(changes, BehaviorProfileChange{Field: field, Before: previous, After: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
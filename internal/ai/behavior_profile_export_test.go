@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func learnBehavior(t *testing.T, engine *UserBehaviorLearningEngine, userID uuid.UUID, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		err := engine.LearnFromBehavior(context.Background(), &BehaviorEvent{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			Type:      "trade",
+			Action:    "buy_btc",
+			Context:   &BehaviorContext{MarketConditions: "bullish"},
+			Outcome:   &BehaviorOutcome{Success: true, Performance: 0.05},
+			Timestamp: time.Now(),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestBehaviorProfileExportImport(t *testing.T) {
+	logger := &observability.Logger{}
+	source := NewUserBehaviorLearningEngine(logger)
+	userID := uuid.New()
+	learnBehavior(t, source, userID, 12)
+
+	export, err := source.ExportUserProfile(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, BehaviorProfileExportVersion, export.Version)
+	assert.Nil(t, export.Profile.Recommendations)
+	assert.Equal(t, 12, export.Profile.ObservationCount)
+
+	_, err = source.ExportUserProfile(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrBehaviorProfileNotFound)
+
+	target := NewUserBehaviorLearningEngine(logger)
+	targetUser := uuid.New()
+
+	t.Run("DryRun", func(t *testing.T) {
+		result, err := target.ImportUserProfile(context.Background(), targetUser, BehaviorProfileImportRequest{DryRun: true, Document: *export})
+		require.NoError(t, err)
+		assert.False(t, result.Applied)
+		assert.NotEmpty(t, result.Changes)
+		_, err = target.GetUserProfile(context.Background(), targetUser)
+		assert.Error(t, err)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		result, err := target.ImportUserProfile(context.Background(), targetUser, BehaviorProfileImportRequest{Mode: BehaviorImportReplace, Document: *export})
+		require.NoError(t, err)
+		assert.True(t, result.Applied)
+
+		profile, err := target.GetUserProfile(context.Background(), targetUser)
+		require.NoError(t, err)
+		assert.Equal(t, targetUser, profile.UserID)
+		assert.Equal(t, 12, profile.ObservationCount)
+		assert.Equal(t, export.Profile.TradingStyle.Confidence, profile.TradingStyle.Confidence)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		result, err := target.ImportUserProfile(context.Background(), targetUser, BehaviorProfileImportRequest{Mode: BehaviorImportMerge, Document: *export})
+		require.NoError(t, err)
+		assert.True(t, result.Applied)
+
+		fields := make(map[string]bool)
+		for _, change := range result.Changes {
+			fields[change.Field] = true
+		}
+		assert.True(t, fields["observation_count"])
+		assert.False(t, fields["trading_style"])
+
+		profile, err := target.GetUserProfile(context.Background(), targetUser)
+		require.NoError(t, err)
+		assert.Equal(t, 24, profile.ObservationCount)
+	})
+
+	t.Run("InvalidDocument", func(t *testing.T) {
+		document := *export
+		document.Version = BehaviorProfileExportVersion + 1
+		_, err := target.ImportUserProfile(context.Background(), targetUser, BehaviorProfileImportRequest{Document: document})
+		assert.ErrorIs(t, err, ErrInvalidBehaviorImport)
+
+		_, err = target.ImportUserProfile(context.Background(), targetUser, BehaviorProfileImportRequest{Mode: "append", Document: *export})
+		assert.ErrorIs(t, err, ErrInvalidBehaviorImport)
+
+		_, err = target.ImportUserProfile(context.Background(), targetUser, BehaviorProfileImportRequest{Document: BehaviorProfileExport{Version: BehaviorProfileExportVersion}})
+		assert.ErrorIs(t, err, ErrInvalidBehaviorImport)
+	})
+}
+
+func TestResetUserProfile(t *testing.T) {
+	logger := &observability.Logger{}
+	engine := NewUserBehaviorLearningEngine(logger)
+	auditor := security.NewAuditManager(logger, &security.AuditConfig{EnableAuditLogging: true, AuditLevel: security.AuditLevelStandard}, nil)
+	engine.SetAuditor(auditor)
+
+	userID := uuid.New()
+	learnBehavior(t, engine, userID, 12)
+	profile, err := engine.GetUserProfile(context.Background(), userID)
+	require.NoError(t, err)
+	recommendations := len(profile.Recommendations)
+
+	reset, err := engine.ResetUserProfile(context.Background(), userID)
+	require.NoError(t, err)
+	assert.True(t, reset.ProfileDeleted)
+	assert.Equal(t, 12, reset.EventsDeleted)
+	assert.Equal(t, recommendations, reset.RecommendationsDeleted)
+
+	_, err = engine.GetUserProfile(context.Background(), userID)
+	assert.Error(t, err)
+	_, err = engine.GetPersonalizedRecommendations(context.Background(), userID, 0)
+	assert.Error(t, err)
+	history, err := engine.GetBehaviorHistory(context.Background(), userID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	events, err := auditor.GetAuditEvents(context.Background(), security.AuditEventFilter{UserID: &userID, Action: "behavior_profile_reset"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 12, events[0].Details["events_deleted"])
+}
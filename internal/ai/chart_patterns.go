@@ -0,0 +1,584 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"  // chart screenshots
+	_ "image/jpeg" // chart screenshots
+	_ "image/png"  // chart screenshots
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chart patterns
+const (
+	ChartPatternHeadAndShoulders        = "head_and_shoulders"
+	ChartPatternInverseHeadAndShoulders = "inverse_head_and_shoulders"
+	ChartPatternDoubleTop               = "double_top"
+	ChartPatternDoubleBottom            = "double_bottom"
+	ChartPatternAscendingTriangle       = "ascending_triangle"
+	ChartPatternSupport                 = "support"
+	ChartPatternResistance              = "resistance"
+)
+
+// Units of chart pattern levels
+const (
+	ChartLevelPrice    = "price"
+	ChartLevelRelative = "relative" // vertical image coordinate, 0 at the top and 1 at the bottom
+)
+
+const (
+	// chartSeriesBuckets is how many points the traced price line is reduced to
+	chartSeriesBuckets = 120
+	// chartMinTracedColumns is how many image columns need price marks for a usable trace
+	chartMinTracedColumns = 20
+	// chartMaxPixels bounds the images decoded for tracing
+	chartMaxPixels = 40_000_000
+	// chartInkDistance is how far, summed over RGB, a pixel must be from the background to count
+	// as drawn
+	chartInkDistance = 96
+	// chartGridLineCoverage is the share of a row or column that, once drawn on, marks it as a
+	// grid line or axis rather than price
+	chartGridLineCoverage = 0.9
+	// chartLevelTolerance is how close, as a share of the traced price range, levels must be to
+	// count as equal
+	chartLevelTolerance = 0.03
+)
+
+var axisLabelPattern = regexp.MustCompile(`^\$?\s*(\d[\d,]*(?:\.\d+)?|\.\d+)\s*([kKmM]?)$`)
+
+// ChartPatternDetector names technical patterns in a chart image
+type ChartPatternDetector interface {
+	DetectChartPatterns(ctx context.Context, input ChartPatternInput) (*ChartPatternAnalysis, error)
+}
+
+// ChartPatternInput is a decoded chart image with the text OCR read from it. Price levels are
+// read from numeric labels along the left or right edge.
+type ChartPatternInput struct {
+	Image      image.Image
+	TextBlocks []TextBlock
+}
+
+// ChartPatternAnalysis lists the patterns found in a chart
+type ChartPatternAnalysis struct {
+	Patterns  []DetectedChartPattern `json:"patterns"`
+	LevelUnit string                 `json:"level_unit"` // price, or relative when the axis couldn't be read
+	Warnings  []string               `json:"warnings,omitempty"`
+}
+
+// DetectedChartPattern is a pattern with the levels that define it
+type DetectedChartPattern struct {
+	Pattern     string              `json:"pattern"`
+	Bias        string              `json:"bias"` // bullish, bearish, neutral
+	Confidence  float64             `json:"confidence"`
+	Levels      []ChartPatternLevel `json:"levels"`
+	Description string              `json:"description"`
+}
+
+// ChartPatternLevel is a level of a pattern, such as a neckline or a peak
+type ChartPatternLevel struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"` // in the analysis' level unit
+	X     float64 `json:"x"`     // horizontal image coordinate, 0 at the left and 1 at the right
+}
+
+// TraceChartPatternDetector is the default ChartPatternDetector. It traces the price line or
+// candles by their contrast to the background, ignoring grid lines and OCR text, and matches
+// swing highs and lows against pattern rules.
+type TraceChartPatternDetector struct{}
+
+// NewTraceChartPatternDetector creates the default chart pattern detector
+func NewTraceChartPatternDetector() *TraceChartPatternDetector {
+	return &TraceChartPatternDetector{}
+}
+
+// chartPivot is a swing high or low of the traced series. Value is the height in the image,
+// 0 at the bottom and 1 at the top.
+type chartPivot struct {
+	high  bool
+	value float64
+	x     float64
+}
+
+func (d *TraceChartPatternDetector) DetectChartPatterns(ctx context.Context, input ChartPatternInput) (*ChartPatternAnalysis, error) {
+	analysis := &ChartPatternAnalysis{Patterns: make([]DetectedChartPattern, 0), LevelUnit: ChartLevelRelative}
+
+	highs, lows, xs, ok := traceChartSeries(input.Image, input.TextBlocks)
+	if !ok {
+		analysis.Warnings = append(analysis.Warnings, "no price line could be traced in the chart image")
+		return analysis, ctx.Err()
+	}
+
+	toLevel := func(height float64) float64 { return 1 - height }
+	if scale, ok := readPriceAxis(input.TextBlocks, input.Image.Bounds()); ok {
+		analysis.LevelUnit = ChartLevelPrice
+		toLevel = func(height float64) float64 { return scale.price(1 - height) }
+	} else {
+		analysis.Warnings = append(analysis.Warnings,
+			"price axis labels could not be read; levels are relative image coordinates (0 = top, 1 = bottom)")
+	}
+
+	pivots := findChartPivots(highs, lows, xs)
+	rangeHeight := maxFloat(highs) - minFloat(lows)
+	if rangeHeight <= 0 || len(pivots) < 2 {
+		return analysis, ctx.Err()
+	}
+	tolerance := chartLevelTolerance * rangeHeight
+
+	matchers := []func([]chartPivot, float64, func(float64) float64) *DetectedChartPattern{
+		matchHeadAndShoulders,
+		matchDoubleTopOrBottom,
+		matchAscendingTriangle,
+	}
+	for _, match := range matchers {
+		if pattern := match(pivots, tolerance, toLevel); pattern != nil {
+			analysis.Patterns = append(analysis.Patterns, *pattern)
+		}
+	}
+	analysis.Patterns = append(analysis.Patterns, matchSupportResistance(pivots, tolerance, toLevel)...)
+
+	sort.SliceStable(analysis.Patterns, func(i, j int) bool {
+		return analysis.Patterns[i].Confidence > analysis.Patterns[j].Confidence
+	})
+	return analysis, ctx.Err()
+}
+
+// traceChartSeries reduces a chart image to the highest and lowest drawn height per bucket of
+// columns, with each bucket's horizontal position. Heights run from 0 at the bottom to 1 at
+// the top.
+func traceChartSeries(img image.Image, blocks []TextBlock) (highs, lows, xs []float64, ok bool) {
+	if img == nil {
+		return nil, nil, nil, false
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < chartMinTracedColumns || height < 10 {
+		return nil, nil, nil, false
+	}
+
+	background := chartBackground(img)
+	textBoxes := make([]image.Rectangle, 0, len(blocks))
+	for _, block := range blocks {
+		textBoxes = append(textBoxes, blockRect(block.BoundingBox, bounds))
+	}
+
+	ink := make([][]bool, width)
+	rowInk := make([]int, height)
+	columnInk := make([]int, width)
+	for x := 0; x < width; x++ {
+		ink[x] = make([]bool, height)
+		for y := 0; y < height; y++ {
+			point := image.Pt(bounds.Min.X+x, bounds.Min.Y+y)
+			if colorDistance(img, point, background) < chartInkDistance || insideAny(point, textBoxes) {
+				continue
+			}
+			ink[x][y] = true
+			rowInk[y]++
+			columnInk[x]++
+		}
+	}
+
+	// Grid lines and axes span the plot; they'd read as flat prices at every column
+	columnTops := make([]int, width)
+	columnBottoms := make([]int, width)
+	traced := 0
+	for x := 0; x < width; x++ {
+		columnTops[x], columnBottoms[x] = -1, -1
+		if float64(columnInk[x]) >= chartGridLineCoverage*float64(height) {
+			continue
+		}
+		for y := 0; y < height; y++ {
+			if !ink[x][y] || float64(rowInk[y]) >= chartGridLineCoverage*float64(width) {
+				continue
+			}
+			if columnTops[x] < 0 {
+				columnTops[x] = y
+			}
+			columnBottoms[x] = y
+		}
+		if columnTops[x] >= 0 {
+			traced++
+		}
+	}
+	if traced < chartMinTracedColumns {
+		return nil, nil, nil, false
+	}
+
+	buckets := min(chartSeriesBuckets, width)
+	for b := 0; b < buckets; b++ {
+		from, to := b*width/buckets, (b+1)*width/buckets
+		top, bottom := height, -1
+		for x := from; x < to; x++ {
+			if columnTops[x] < 0 {
+				continue
+			}
+			top = min(top, columnTops[x])
+			bottom = max(bottom, columnBottoms[x])
+		}
+		if bottom < 0 {
+			continue // gaps between candles
+		}
+		highs = append(highs, 1-float64(top)/float64(height-1))
+		lows = append(lows, 1-float64(bottom)/float64(height-1))
+		xs = append(xs, (float64(from+to)/2)/float64(width))
+	}
+	return highs, lows, xs, len(highs) >= 5
+}
+
+// chartBackground is the most common color along the image border, quantized to tolerate
+// compression noise
+func chartBackground(img image.Image) [3]uint32 {
+	bounds := img.Bounds()
+	counts := make(map[[3]uint32]int)
+	count := func(x, y int) {
+		r, g, b, _ := img.At(x, y).RGBA()
+		counts[[3]uint32{r >> 12, g >> 12, b >> 12}]++
+	}
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		count(x, bounds.Min.Y)
+		count(x, bounds.Max.Y-1)
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		count(bounds.Min.X, y)
+		count(bounds.Max.X-1, y)
+	}
+
+	var background [3]uint32
+	best := -1
+	for color, n := range counts {
+		if n > best || (n == best && color[0]+color[1]+color[2] > background[0]+background[1]+background[2]) {
+			background, best = color, n
+		}
+	}
+	// Back to 8 bits per channel, at the middle of the quantization step
+	return [3]uint32{background[0]<<4 | 8, background[1]<<4 | 8, background[2]<<4 | 8}
+}
+
+// colorDistance sums the per-channel difference of a pixel to an 8-bit color
+func colorDistance(img image.Image, point image.Point, color [3]uint32) int {
+	r, g, b, _ := img.At(point.X, point.Y).RGBA()
+	diff := func(a, b uint32) int {
+		return int(math.Abs(float64(a>>8) - float64(b)))
+	}
+	return diff(r, color[0]) + diff(g, color[1]) + diff(b, color[2])
+}
+
+// blockRect converts an OCR bounding box, relative (0 to 1) or in pixels, to image pixels
+func blockRect(box BoundingBox, bounds image.Rectangle) image.Rectangle {
+	x, y, w, h := box.X, box.Y, box.Width, box.Height
+	if x <= 1 && y <= 1 && w <= 1 && h <= 1 {
+		x, w = x*float64(bounds.Dx()), w*float64(bounds.Dx())
+		y, h = y*float64(bounds.Dy()), h*float64(bounds.Dy())
+	}
+	return image.Rect(int(x), int(y), int(math.Ceil(x+w)), int(math.Ceil(y+h))).Add(bounds.Min)
+}
+
+func insideAny(point image.Point, rects []image.Rectangle) bool {
+	for _, rect := range rects {
+		if point.In(rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// chartPriceScale maps vertical image coordinates to prices
+type chartPriceScale struct {
+	intercept, slope float64
+}
+
+func (s chartPriceScale) price(y float64) float64 {
+	return s.intercept + s.slope*y
+}
+
+// readPriceAxis fits a linear price scale to numeric labels along the left or right edge. The
+// labels must agree on the scale, with prices falling from top to bottom.
+func readPriceAxis(blocks []TextBlock, bounds image.Rectangle) (chartPriceScale, bool) {
+	var ys, prices []float64
+	for _, block := range blocks {
+		price, ok := parseAxisLabel(block.Text)
+		if !ok {
+			continue
+		}
+		rect := blockRect(block.BoundingBox, bounds)
+		centerX := (float64(rect.Min.X+rect.Max.X)/2 - float64(bounds.Min.X)) / float64(bounds.Dx())
+		if centerX > 0.2 && centerX < 0.8 {
+			continue // labels inside the plot aren't axis ticks
+		}
+		ys = append(ys, (float64(rect.Min.Y+rect.Max.Y)/2-float64(bounds.Min.Y))/float64(bounds.Dy()))
+		prices = append(prices, price)
+	}
+	if len(ys) < 2 {
+		return chartPriceScale{}, false
+	}
+
+	meanY, meanPrice := meanFloat(ys), meanFloat(prices)
+	var covariance, varianceY, variancePrice float64
+	for i := range ys {
+		covariance += (ys[i] - meanY) * (prices[i] - meanPrice)
+		varianceY += (ys[i] - meanY) * (ys[i] - meanY)
+		variancePrice += (prices[i] - meanPrice) * (prices[i] - meanPrice)
+	}
+	if varianceY == 0 || variancePrice == 0 {
+		return chartPriceScale{}, false
+	}
+	slope := covariance / varianceY
+	correlation := covariance / math.Sqrt(varianceY*variancePrice)
+	if slope >= 0 || correlation > -0.99 {
+		return chartPriceScale{}, false
+	}
+	return chartPriceScale{intercept: meanPrice - slope*meanY, slope: slope}, true
+}
+
+// parseAxisLabel reads a price tick such as "50,000", "$1.25" or "62.5k"
+func parseAxisLabel(text string) (float64, bool) {
+	match := axisLabelPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch strings.ToLower(match[2]) {
+	case "k":
+		value *= 1e3
+	case "m":
+		value *= 1e6
+	}
+	return value, true
+}
+
+// findChartPivots returns the swing highs and lows of a series, alternating, keeping the more
+// extreme of consecutive pivots of the same kind
+func findChartPivots(highs, lows, xs []float64) []chartPivot {
+	window := max(2, len(highs)/20)
+	pivots := make([]chartPivot, 0)
+	add := func(pivot chartPivot) {
+		if n := len(pivots); n > 0 && pivots[n-1].high == pivot.high {
+			if (pivot.high && pivot.value > pivots[n-1].value) || (!pivot.high && pivot.value < pivots[n-1].value) {
+				pivots[n-1] = pivot
+			}
+			return
+		}
+		pivots = append(pivots, pivot)
+	}
+
+	for i := range highs {
+		from, to := max(0, i-window), min(len(highs)-1, i+window)
+		isHigh, isLow := true, true
+		for j := from; j <= to; j++ {
+			if highs[j] > highs[i] || (j < i && highs[j] == highs[i]) {
+				isHigh = false
+			}
+			if lows[j] < lows[i] || (j < i && lows[j] == lows[i]) {
+				isLow = false
+			}
+		}
+		if isHigh {
+			add(chartPivot{high: true, value: highs[i], x: xs[i]})
+		}
+		if isLow {
+			add(chartPivot{high: false, value: lows[i], x: xs[i]})
+		}
+	}
+	return pivots
+}
+
+// matchHeadAndShoulders finds the latest peak flanked by two lower peaks of similar height, or
+// the inverse with troughs
+func matchHeadAndShoulders(pivots []chartPivot, tolerance float64, toLevel func(float64) float64) *DetectedChartPattern {
+	for i := len(pivots) - 5; i >= 0; i-- {
+		left, leftDip, head, rightDip, right := pivots[i], pivots[i+1], pivots[i+2], pivots[i+3], pivots[i+4]
+		sign := 1.0
+		if !head.high {
+			sign = -1
+		}
+		shoulders := math.Max(sign*left.value, sign*right.value)
+		if sign*head.value-shoulders <= tolerance || math.Abs(left.value-right.value) > 2*tolerance {
+			continue
+		}
+
+		neckline := (leftDip.value + rightDip.value) / 2
+		pattern := &DetectedChartPattern{
+			Pattern:    ChartPatternHeadAndShoulders,
+			Bias:       "bearish",
+			Confidence: patternConfidence(math.Abs(left.value-right.value), 2*tolerance, 0.6),
+			Levels: []ChartPatternLevel{
+				{Name: "left_shoulder", Value: toLevel(left.value), X: left.x},
+				{Name: "head", Value: toLevel(head.value), X: head.x},
+				{Name: "right_shoulder", Value: toLevel(right.value), X: right.x},
+				{Name: "neckline", Value: toLevel(neckline), X: (leftDip.x + rightDip.x) / 2},
+			},
+			Description: "Head and shoulders: a higher peak between two similar ones signals a reversal down once the neckline breaks",
+		}
+		if !head.high {
+			pattern.Pattern = ChartPatternInverseHeadAndShoulders
+			pattern.Bias = "bullish"
+			pattern.Description = "Inverse head and shoulders: a lower trough between two similar ones signals a reversal up once the neckline breaks"
+		}
+		return pattern
+	}
+	return nil
+}
+
+// matchDoubleTopOrBottom finds the latest two peaks, or troughs, of similar height with a clear
+// pullback between them
+func matchDoubleTopOrBottom(pivots []chartPivot, tolerance float64, toLevel func(float64) float64) *DetectedChartPattern {
+	for i := len(pivots) - 3; i >= 0; i-- {
+		first, middle, second := pivots[i], pivots[i+1], pivots[i+2]
+		difference := math.Abs(first.value - second.value)
+		depth := math.Min(math.Abs(first.value-middle.value), math.Abs(second.value-middle.value))
+		if difference > tolerance || depth < 3*tolerance {
+			continue
+		}
+
+		if first.high {
+			return &DetectedChartPattern{
+				Pattern:    ChartPatternDoubleTop,
+				Bias:       "bearish",
+				Confidence: patternConfidence(difference, tolerance, 0.55),
+				Levels: []ChartPatternLevel{
+					{Name: "first_peak", Value: toLevel(first.value), X: first.x},
+					{Name: "second_peak", Value: toLevel(second.value), X: second.x},
+					{Name: "neckline", Value: toLevel(middle.value), X: middle.x},
+				},
+				Description: "Double top: price failed twice at the same high, a reversal down is likely below the neckline",
+			}
+		}
+		return &DetectedChartPattern{
+			Pattern:    ChartPatternDoubleBottom,
+			Bias:       "bullish",
+			Confidence: patternConfidence(difference, tolerance, 0.55),
+			Levels: []ChartPatternLevel{
+				{Name: "first_trough", Value: toLevel(first.value), X: first.x},
+				{Name: "second_trough", Value: toLevel(second.value), X: second.x},
+				{Name: "neckline", Value: toLevel(middle.value), X: middle.x},
+			},
+			Description: "Double bottom: price held twice at the same low, a reversal up is likely above the neckline",
+		}
+	}
+	return nil
+}
+
+// matchAscendingTriangle looks for flat highs over rising lows among the latest pivots
+func matchAscendingTriangle(pivots []chartPivot, tolerance float64, toLevel func(float64) float64) *DetectedChartPattern {
+	recent := pivots[max(0, len(pivots)-6):]
+	var highs, lows []chartPivot
+	for _, pivot := range recent {
+		if pivot.high {
+			highs = append(highs, pivot)
+		} else {
+			lows = append(lows, pivot)
+		}
+	}
+	if len(highs) < 2 || len(lows) < 2 {
+		return nil
+	}
+
+	top, spread := 0.0, 0.0
+	for _, high := range highs {
+		top += high.value / float64(len(highs))
+	}
+	for _, high := range highs {
+		spread = math.Max(spread, math.Abs(high.value-top))
+	}
+	if spread > tolerance {
+		return nil
+	}
+	for i := 1; i < len(lows); i++ {
+		if lows[i].value-lows[i-1].value < tolerance/2 {
+			return nil
+		}
+	}
+
+	last := lows[len(lows)-1]
+	return &DetectedChartPattern{
+		Pattern:    ChartPatternAscendingTriangle,
+		Bias:       "bullish",
+		Confidence: patternConfidence(spread, tolerance, 0.5+0.05*float64(len(lows))),
+		Levels: []ChartPatternLevel{
+			{Name: "resistance", Value: toLevel(top), X: highs[len(highs)-1].x},
+			{Name: "rising_support", Value: toLevel(last.value), X: last.x},
+		},
+		Description: "Ascending triangle: rising lows press against flat resistance, a breakout up is likely",
+	}
+}
+
+// matchSupportResistance clusters swing lows into support and swing highs into resistance
+// levels, keeping those touched at least twice
+func matchSupportResistance(pivots []chartPivot, tolerance float64, toLevel func(float64) float64) []DetectedChartPattern {
+	patterns := make([]DetectedChartPattern, 0)
+	for _, high := range []bool{false, true} {
+		values := make([]chartPivot, 0)
+		for _, pivot := range pivots {
+			if pivot.high == high {
+				values = append(values, pivot)
+			}
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i].value < values[j].value })
+
+		for start := 0; start < len(values); {
+			end := start + 1
+			for end < len(values) && values[end].value-values[start].value <= tolerance {
+				end++
+			}
+			if touches := end - start; touches >= 2 {
+				level, lastX := 0.0, 0.0
+				for _, pivot := range values[start:end] {
+					level += pivot.value / float64(touches)
+					lastX = math.Max(lastX, pivot.x)
+				}
+				pattern := DetectedChartPattern{
+					Pattern:     ChartPatternSupport,
+					Bias:        "neutral",
+					Confidence:  math.Min(0.9, 0.4+0.1*float64(touches)),
+					Levels:      []ChartPatternLevel{{Name: "support", Value: toLevel(level), X: lastX}},
+					Description: fmt.Sprintf("Support held %d times", touches),
+				}
+				if high {
+					pattern.Pattern = ChartPatternResistance
+					pattern.Levels[0].Name = "resistance"
+					pattern.Description = fmt.Sprintf("Resistance capped price %d times", touches)
+				}
+				patterns = append(patterns, pattern)
+			}
+			start = end
+		}
+	}
+	return patterns
+}
+
+// patternConfidence scales from base up to 0.9 the more closely a pattern's defining levels match
+func patternConfidence(mismatch, tolerance, base float64) float64 {
+	fit := 1 - mismatch/tolerance
+	return math.Round(math.Min(0.9, base+(0.9-base)*math.Max(0, fit))*100) / 100
+}
+
+func maxFloat(values []float64) float64 {
+	result := math.Inf(-1)
+	for _, v := range values {
+		result = math.Max(result, v)
+	}
+	return result
+}
+
+func minFloat(values []float64) float64 {
+	result := math.Inf(1)
+	for _, v := range values {
+		result = math.Min(result, v)
+	}
+	return result
+}
+
+func meanFloat(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
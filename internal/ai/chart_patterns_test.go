@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drawLineChart draws a 3px dark price line through the given (x, row) points on a white
+// 400x200 image with a grid line across the middle
+func drawLineChart(points [][2]int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.White)
+		}
+		img.Set(x, 100, color.Gray{Y: 60})
+	}
+
+	prevY := -1
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		for x := from[0]; x <= to[0]; x++ {
+			y := from[1] + (to[1]-from[1])*(x-from[0])/(to[0]-from[0])
+			top, bottom := y, y
+			if prevY >= 0 {
+				top, bottom = min(y, prevY), max(y, prevY)
+			}
+			for row := top - 1; row <= bottom+1; row++ {
+				img.Set(x, row, color.Black)
+			}
+			prevY = y
+		}
+	}
+	return img
+}
+
+// testDoubleTopChart peaks twice at row 40 with a pullback to row 120 between
+var testDoubleTopChart = [][2]int{{0, 180}, {100, 40}, {200, 120}, {300, 40}, {399, 180}}
+
+func testAxisLabels() []TextBlock {
+	label := func(text string, y float64) TextBlock {
+		return TextBlock{Text: text, Confidence: 0.9, BoundingBox: BoundingBox{X: 0.0, Y: y - 0.02, Width: 0.08, Height: 0.04}}
+	}
+	// 60,000 at 20% of the height and 50,000 at 60%
+	return []TextBlock{label("60,000", 0.2), label("$50k", 0.6), label("BTC/USD", 0.5)}
+}
+
+func findChartPattern(analysis *ChartPatternAnalysis, name string) *DetectedChartPattern {
+	for i := range analysis.Patterns {
+		if analysis.Patterns[i].Pattern == name {
+			return &analysis.Patterns[i]
+		}
+	}
+	return nil
+}
+
+func TestTraceChartPatternDetector_DoubleTop(t *testing.T) {
+	detector := NewTraceChartPatternDetector()
+
+	analysis, err := detector.DetectChartPatterns(context.Background(), ChartPatternInput{
+		Image:      drawLineChart(testDoubleTopChart),
+		TextBlocks: testAxisLabels(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ChartLevelPrice, analysis.LevelUnit)
+	assert.Empty(t, analysis.Warnings)
+	assert.Nil(t, findChartPattern(analysis, ChartPatternHeadAndShoulders))
+
+	top := findChartPattern(analysis, ChartPatternDoubleTop)
+	require.NotNil(t, top)
+	assert.Equal(t, "bearish", top.Bias)
+	assert.Greater(t, top.Confidence, 0.5)
+	require.Len(t, top.Levels, 3)
+	assert.InDelta(t, 60000, top.Levels[0].Value, 500)
+	assert.InDelta(t, 60000, top.Levels[1].Value, 500)
+	assert.InDelta(t, 50000, top.Levels[2].Value, 500, "neckline at the pullback")
+	assert.InDelta(t, 0.25, top.Levels[0].X, 0.03)
+	assert.InDelta(t, 0.75, top.Levels[1].X, 0.03)
+
+	resistance := findChartPattern(analysis, ChartPatternResistance)
+	require.NotNil(t, resistance)
+	assert.Equal(t, "neutral", resistance.Bias)
+	assert.InDelta(t, 60000, resistance.Levels[0].Value, 500)
+
+	t.Run("UnreadableAxis", func(t *testing.T) {
+		analysis, err := detector.DetectChartPatterns(context.Background(), ChartPatternInput{
+			Image:      drawLineChart(testDoubleTopChart),
+			TextBlocks: []TextBlock{{Text: "BTC/USD", BoundingBox: BoundingBox{X: 0.4, Y: 0.02, Width: 0.2, Height: 0.04}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ChartLevelRelative, analysis.LevelUnit)
+		require.Len(t, analysis.Warnings, 1)
+		assert.Contains(t, analysis.Warnings[0], "relative image coordinates")
+
+		top := findChartPattern(analysis, ChartPatternDoubleTop)
+		require.NotNil(t, top)
+		assert.InDelta(t, 0.2, top.Levels[0].Value, 0.02)
+		assert.InDelta(t, 0.6, top.Levels[2].Value, 0.02)
+	})
+}
+
+func TestTraceChartPatternDetector_Patterns(t *testing.T) {
+	detector := NewTraceChartPatternDetector()
+	detect := func(points [][2]int) *ChartPatternAnalysis {
+		analysis, err := detector.DetectChartPatterns(context.Background(), ChartPatternInput{
+			Image:      drawLineChart(points),
+			TextBlocks: testAxisLabels(),
+		})
+		require.NoError(t, err)
+		return analysis
+	}
+
+	t.Run("HeadAndShoulders", func(t *testing.T) {
+		analysis := detect([][2]int{{0, 180}, {70, 70}, {130, 130}, {200, 30}, {270, 130}, {330, 70}, {399, 180}})
+		pattern := findChartPattern(analysis, ChartPatternHeadAndShoulders)
+		require.NotNil(t, pattern)
+		assert.Equal(t, "bearish", pattern.Bias)
+		assert.Equal(t, "head", pattern.Levels[1].Name)
+		assert.Greater(t, pattern.Levels[1].Value, pattern.Levels[0].Value)
+	})
+
+	t.Run("DoubleBottom", func(t *testing.T) {
+		analysis := detect([][2]int{{0, 20}, {100, 160}, {200, 80}, {300, 160}, {399, 20}})
+		pattern := findChartPattern(analysis, ChartPatternDoubleBottom)
+		require.NotNil(t, pattern)
+		assert.Equal(t, "bullish", pattern.Bias)
+		assert.Nil(t, findChartPattern(analysis, ChartPatternDoubleTop))
+	})
+
+	t.Run("AscendingTriangle", func(t *testing.T) {
+		analysis := detect([][2]int{{0, 50}, {60, 170}, {130, 50}, {190, 140}, {260, 50}, {320, 110}, {399, 50}})
+		pattern := findChartPattern(analysis, ChartPatternAscendingTriangle)
+		require.NotNil(t, pattern)
+		assert.Equal(t, "bullish", pattern.Bias)
+	})
+
+	t.Run("Blank", func(t *testing.T) {
+		analysis := detect(nil)
+		assert.Empty(t, analysis.Patterns)
+		assert.Contains(t, analysis.Warnings, "no price line could be traced in the chart image")
+	})
+}
+
+func TestParseAxisLabel(t *testing.T) {
+	for text, expected := range map[string]float64{"50,000": 50000, "$1.25": 1.25, "62.5k": 62500, "1.2M": 1.2e6, ".5": 0.5} {
+		value, ok := parseAxisLabel(text)
+		assert.True(t, ok, text)
+		assert.Equal(t, expected, value, text)
+	}
+	for _, text := range []string{"BTC", "12:00", "-3%", ""} {
+		_, ok := parseAxisLabel(text)
+		assert.False(t, ok, text)
+	}
+}
+
+func TestMultiModalEngine_ChartPatterns(t *testing.T) {
+	engine := NewMultiModalEngine(&observability.Logger{})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, drawLineChart(testDoubleTopChart)))
+	result, err := engine.ProcessMultiModalRequest(context.Background(), &MultiModalRequest{
+		RequestID: uuid.New().String(),
+		UserID:    uuid.New(),
+		Type:      "image",
+		Content: []MultiModalContent{{
+			ID:       uuid.New().String(),
+			Type:     "image",
+			Data:     base64.StdEncoding.EncodeToString(buf.Bytes()),
+			MimeType: "image/png",
+			Filename: "chart.png",
+			Size:     int64(buf.Len()),
+		}},
+		Options:     MultiModalOptions{AnalyzeCharts: true},
+		RequestedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+
+	chart := result.Results[0].ChartAnalysis
+	require.NotNil(t, chart)
+	require.NotNil(t, chart.ChartPatterns)
+	assert.NotNil(t, findChartPattern(chart.ChartPatterns, ChartPatternDoubleTop))
+
+	t.Run("Undecodable", func(t *testing.T) {
+		result, err := engine.ProcessMultiModalRequest(context.Background(), &MultiModalRequest{
+			RequestID: uuid.New().String(),
+			UserID:    uuid.New(),
+			Type:      "image",
+			Content: []MultiModalContent{{
+				ID:       uuid.New().String(),
+				Type:     "image",
+				Data:     base64.StdEncoding.EncodeToString([]byte("not an image")),
+				MimeType: "image/png",
+				Size:     12,
+			}},
+			Options:     MultiModalOptions{AnalyzeCharts: true},
+			RequestedAt: time.Now(),
+		})
+		require.NoError(t, err)
+		chart := result.Results[0].ChartAnalysis
+		require.NotNil(t, chart)
+		require.NotNil(t, chart.ChartPatterns)
+		assert.Empty(t, chart.ChartPatterns.Patterns)
+		assert.Equal(t, ChartLevelRelative, chart.ChartPatterns.LevelUnit)
+		assert.NotEmpty(t, chart.ChartPatterns.Warnings)
+	})
+}
@@ -0,0 +1,304 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Actionable chat intents
+const (
+	ChatIntentCheckBalance         = "check_balance"
+	ChatIntentPortfolioPerformance = "portfolio_performance"
+	ChatIntentCreatePriceAlert     = "create_price_alert"
+	ChatIntentAnalyzeCoin          = "analyze_coin"
+	ChatIntentExecuteTrade         = "execute_trade"
+)
+
+// Chat action statuses
+const (
+	ChatActionCompleted = "completed"
+	ChatActionFailed    = "failed"
+	ChatActionProposed  = "proposed" // awaits the user's confirmation; chat never executes it
+)
+
+// ChatAction is an action chat took, or proposed, for a message
+type ChatAction struct {
+	Intent               string                 `json:"intent"`
+	Status               string                 `json:"status"`
+	Parameters           map[string]interface{} `json:"parameters,omitempty"`
+	Result               interface{}            `json:"result,omitempty"`
+	Error                string                 `json:"error,omitempty"`
+	RequiresConfirmation bool                   `json:"requires_confirmation,omitempty"`
+}
+
+// ChatBalanceSource reports a user's balances across wallets and chains
+type ChatBalanceSource interface {
+	GetAggregateBalance(ctx context.Context, userID uuid.UUID) (*web3.AggregateBalanceResponse, error)
+}
+
+// ChatPortfolioSource lists a user's trading portfolios
+type ChatPortfolioSource interface {
+	ListUserPortfolios(ctx context.Context, userID uuid.UUID) ([]*web3.Portfolio, error)
+}
+
+// ChatPriceAlertCreator creates price alert rules for a user
+type ChatPriceAlertCreator interface {
+	CreateRule(ctx context.Context, userID uuid.UUID, req alerts.CreatePriceAlertRequest) (*alerts.PriceAlertRule, error)
+}
+
+// ChatCoinAnalyzer analyzes a coin; CryptoCoinAnalyzer implements it
+type ChatCoinAnalyzer interface {
+	AnalyzeCoin(ctx context.Context, symbol string) (*CoinAnalysisReport, error)
+}
+
+// ChatActionServices are the services chat intents act through. Intents whose service is nil
+// are answered as plain chat.
+type ChatActionServices struct {
+	Balances    ChatBalanceSource
+	Portfolios  ChatPortfolioSource // defaults to the trading engine when one is injected
+	PriceAlerts ChatPriceAlertCreator
+	Coins       ChatCoinAnalyzer
+}
+
+// chatIntent is an actionable intent recognized in a message, with its parameters
+type chatIntent struct {
+	name      string
+	symbol    string
+	side      string // buy or sell, for trades
+	condition alerts.PriceCondition
+	amount    decimal.Decimal // alert threshold, or trade quantity
+}
+
+var (
+	chatAmountPattern  = regexp.MustCompile(`\$?(\d[\d,]*(?:\.\d+)?)\s*(k\b)?`)
+	chatWordPattern    = regexp.MustCompile(`[A-Za-z]+`)
+	chatTradePattern   = regexp.MustCompile(`(?i)^(?:please\s+)?(buy|sell)\b|\b(?:i want to|i'd like to|place an? (?:order|trade) to|execute an? (?:order|trade) to)\s+(buy|sell)\b`)
+	chatAlertPattern   = regexp.MustCompile(`(?i)\b(alert|notify|remind|let me know|tell me)\b`)
+	chatBelowPattern   = regexp.MustCompile(`(?i)\b(below|under|drops?|falls?|dips?|less than)\b`)
+	chatAnalyzePattern = regexp.MustCompile(`(?i)\b(analy[sz]e|analysis|outlook|thoughts on|deep dive)\b`)
+	chatBalancePattern = regexp.MustCompile(`(?i)\b(balances?|how much (?:crypto |money )?do i (?:have|own|hold)|my wallets?)\b`)
+	chatReturnsPattern = regexp.MustCompile(`(?i)\b(perform\w*|p&l|pnl|profit|returns?|gains?|how (?:is|'s) my portfolio)\b`)
+)
+
+// chatCoinNames maps coin names to their tickers
+var chatCoinNames = map[string]string{
+	"bitcoin": "BTC", "ethereum": "ETH", "ether": "ETH", "solana": "SOL", "cardano": "ADA",
+	"ripple": "XRP", "dogecoin": "DOGE", "polkadot": "DOT", "avalanche": "AVAX", "chainlink": "LINK",
+	"polygon": "MATIC", "litecoin": "LTC", "binance": "BNB", "tron": "TRX", "uniswap": "UNI",
+}
+
+// chatTickers are tickers recognized in any case; other tickers must be written in capitals
+var chatTickers = map[string]bool{
+	"BTC": true, "ETH": true, "SOL": true, "ADA": true, "XRP": true, "DOGE": true, "DOT": true,
+	"AVAX": true, "LINK": true, "MATIC": true, "LTC": true, "BNB": true, "TRX": true, "UNI": true,
+}
+
+// chatTickerStopwords are capitalized words that aren't tickers
+var chatTickerStopwords = map[string]bool{
+	"I": true, "A": true, "ME": true, "MY": true, "USD": true, "USDT": true, "PNL": true, "OK": true,
+	"AI": true, "DEFI": true, "APY": true, "NFT": true, "IS": true, "IF": true,
+}
+
+// SetActionServices lets chat act on balance, portfolio, price alert and coin analysis requests
+func (c *ConversationalAI) SetActionServices(services ChatActionServices) {
+	if services.Portfolios == nil && c.tradingEngine != nil {
+		services.Portfolios = c.tradingEngine
+	}
+	c.actions = services
+}
+
+// classifyChatIntent recognizes an actionable intent in a message. Requests the services
+// can't serve, and anything else, return false and are answered as plain chat.
+func (c *ConversationalAI) classifyChatIntent(message string) (chatIntent, bool) {
+	symbol := chatSymbol(message)
+	lower := strings.ToLower(message)
+
+	switch {
+	case chatAlertPattern.MatchString(message) && symbol != "" && c.actions.PriceAlerts != nil:
+		amount, ok := chatAmount(message)
+		if !ok {
+			return chatIntent{}, false
+		}
+		condition := alerts.PriceAbove
+		if chatBelowPattern.MatchString(message) {
+			condition = alerts.PriceBelow
+		}
+		return chatIntent{name: ChatIntentCreatePriceAlert, symbol: symbol, condition: condition, amount: amount}, true
+	case chatTradePattern.MatchString(message) && symbol != "":
+		match := chatTradePattern.FindStringSubmatch(message)
+		side := strings.ToLower(match[1] + match[2])
+		amount, _ := chatAmount(message)
+		return chatIntent{name: ChatIntentExecuteTrade, symbol: symbol, side: side, amount: amount}, true
+	case chatBalancePattern.MatchString(message) && c.actions.Balances != nil:
+		return chatIntent{name: ChatIntentCheckBalance}, true
+	case strings.Contains(lower, "portfolio") && chatReturnsPattern.MatchString(message) && c.actions.Portfolios != nil:
+		return chatIntent{name: ChatIntentPortfolioPerformance}, true
+	case chatAnalyzePattern.MatchString(message) && symbol != "" && c.actions.Coins != nil:
+		return chatIntent{name: ChatIntentAnalyzeCoin, symbol: symbol}, true
+	}
+	return chatIntent{}, false
+}
+
+// performChatAction carries out an intent, filling in the reply and the action taken
+func (c *ConversationalAI) performChatAction(ctx context.Context, userID uuid.UUID, intent chatIntent, response *ConversationalResponse) {
+	action := ChatAction{Intent: intent.name, Status: ChatActionCompleted, Parameters: make(map[string]interface{})}
+
+	var err error
+	switch intent.name {
+	case ChatIntentCheckBalance:
+		var balance *web3.AggregateBalanceResponse
+		if balance, err = c.actions.Balances.GetAggregateBalance(ctx, userID); err == nil {
+			action.Result = balance
+			response.Content = describeBalance(balance)
+		}
+	case ChatIntentPortfolioPerformance:
+		var portfolios []*web3.Portfolio
+		if portfolios, err = c.actions.Portfolios.ListUserPortfolios(ctx, userID); err == nil {
+			action.Result = portfolios
+			response.Content = describePortfolioPerformance(portfolios)
+		}
+	case ChatIntentCreatePriceAlert:
+		action.Parameters["symbol"] = intent.symbol
+		action.Parameters["condition"] = string(intent.condition)
+		action.Parameters["threshold"] = intent.amount.String()
+		var rule *alerts.PriceAlertRule
+		rule, err = c.actions.PriceAlerts.CreateRule(ctx, userID, alerts.CreatePriceAlertRequest{
+			Symbol:    intent.symbol + "USDT",
+			Condition: intent.condition,
+			Threshold: intent.amount,
+		})
+		if err == nil {
+			action.Result = rule
+			response.Content = fmt.Sprintf("Done. I'll alert you when %s goes %s $%s.", intent.symbol, intent.condition, intent.amount.String())
+		}
+	case ChatIntentAnalyzeCoin:
+		action.Parameters["symbol"] = intent.symbol
+		var report *CoinAnalysisReport
+		if report, err = c.actions.Coins.AnalyzeCoin(ctx, intent.symbol); err == nil {
+			action.Result = report
+			response.Content = describeCoinAnalysis(report)
+		}
+	case ChatIntentExecuteTrade:
+		// Trades are only ever proposed; the user confirms them through the trading endpoints
+		action.Status = ChatActionProposed
+		action.RequiresConfirmation = true
+		action.Parameters["symbol"] = intent.symbol
+		action.Parameters["side"] = intent.side
+		order := "a quantity of " + intent.symbol
+		if intent.amount.IsPositive() {
+			action.Parameters["quantity"] = intent.amount.String()
+			order = intent.amount.String() + " " + intent.symbol
+		}
+		response.Content = fmt.Sprintf("I've prepared a proposal to %s %s, but I don't place trades from chat. Review it and confirm the order through the trading endpoints if you want to go ahead.",
+			intent.side, order)
+	}
+
+	if err != nil {
+		action.Status = ChatActionFailed
+		action.Error = err.Error()
+		response.Content = fmt.Sprintf("Sorry, I couldn't complete that: %v", err)
+		c.logger.Warn(ctx, "Chat action failed", map[string]interface{}{
+			"intent":  intent.name,
+			"user_id": userID.String(),
+			"error":   err.Error(),
+		})
+	}
+
+	response.ActionsTaken = append(response.ActionsTaken, action)
+	response.Metadata["intent"] = intent.name
+}
+
+// chatSymbol returns the first coin named in a message, as a ticker
+func chatSymbol(message string) string {
+	for _, word := range chatWordPattern.FindAllString(message, -1) {
+		if ticker, ok := chatCoinNames[strings.ToLower(word)]; ok {
+			return ticker
+		}
+		upper := strings.ToUpper(word)
+		if chatTickers[upper] {
+			return upper
+		}
+		if word == upper && len(word) >= 2 && len(word) <= 6 && !chatTickerStopwords[word] {
+			return upper
+		}
+	}
+	return ""
+}
+
+// chatAmount returns the first amount in a message, with a k suffix read as thousands
+func chatAmount(message string) (decimal.Decimal, bool) {
+	match := chatAmountPattern.FindStringSubmatch(message)
+	if match == nil {
+		return decimal.Zero, false
+	}
+	amount, err := decimal.NewFromString(strings.ReplaceAll(match[1], ",", ""))
+	if err != nil || !amount.IsPositive() {
+		return decimal.Zero, false
+	}
+	if strings.EqualFold(match[2], "k") {
+		amount = amount.Mul(decimal.NewFromInt(1000))
+	}
+	return amount, true
+}
+
+func describeBalance(balance *web3.AggregateBalanceResponse) string {
+	if balance.WalletCount == 0 {
+		return "You don't have any connected wallets yet. Connect one and I can report its balances."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Your %d wallet(s) hold $%.2f in total:\n", balance.WalletCount, balance.TotalUSDValue)
+	for _, token := range balance.Tokens {
+		fmt.Fprintf(&b, "• %s %s ($%.2f)\n", token.Balance.String(), token.Symbol, token.USDValue)
+	}
+	if len(balance.PartialErrors) > 0 {
+		fmt.Fprintf(&b, "\nSome balances couldn't be fetched (%d chain errors), so the total may be incomplete.", len(balance.PartialErrors))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func describePortfolioPerformance(portfolios []*web3.Portfolio) string {
+	if len(portfolios) == 0 {
+		return "You don't have any trading portfolios yet."
+	}
+
+	var b strings.Builder
+	for _, portfolio := range portfolios {
+		pnlPercent := 0.0
+		if portfolio.InvestedAmount.IsPositive() {
+			pnlPercent = portfolio.TotalPnL.Div(portfolio.InvestedAmount).Mul(decimal.NewFromInt(100)).InexactFloat64()
+		}
+		fmt.Fprintf(&b, "• %s: value $%s, total P&L $%s (%.2f%%), today $%s, %d open position(s)\n",
+			portfolio.Name, portfolio.TotalValue.StringFixed(2), portfolio.TotalPnL.StringFixed(2), pnlPercent,
+			portfolio.DailyPnL.StringFixed(2), len(portfolio.ActivePositions))
+	}
+	return "Here's how your portfolios are doing:\n" + strings.TrimSpace(b.String())
+}
+
+func describeCoinAnalysis(report *CoinAnalysisReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Here's my analysis of %s.", report.Symbol)
+	if report.CurrentData != nil {
+		fmt.Fprintf(&b, " It trades at $%s (%s%% over 24h).", report.CurrentData.Price.StringFixed(2), report.CurrentData.ChangePercent24h.StringFixed(2))
+	}
+	if summary := report.Summary; summary != nil {
+		fmt.Fprintf(&b, "\n\nOutlook: %s.", summary.OverallOutlook)
+		if summary.ShortTermView != "" {
+			fmt.Fprintf(&b, " Short term: %s.", summary.ShortTermView)
+		}
+		for _, insight := range summary.KeyInsights {
+			fmt.Fprintf(&b, "\n• %s", insight)
+		}
+		if len(summary.RiskFactors) > 0 {
+			fmt.Fprintf(&b, "\n\nRisks: %s.", strings.Join(summary.RiskFactors, "; "))
+		}
+	}
+	return b.String()
+}
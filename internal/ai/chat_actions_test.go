@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChatBalances struct {
+	balance *web3.AggregateBalanceResponse
+	err     error
+}
+
+func (f *fakeChatBalances) GetAggregateBalance(ctx context.Context, userID uuid.UUID) (*web3.AggregateBalanceResponse, error) {
+	return f.balance, f.err
+}
+
+type fakeChatPortfolios struct {
+	portfolios []*web3.Portfolio
+}
+
+func (f *fakeChatPortfolios) ListUserPortfolios(ctx context.Context, userID uuid.UUID) ([]*web3.Portfolio, error) {
+	return f.portfolios, nil
+}
+
+type fakeChatPriceAlerts struct {
+	requests []alerts.CreatePriceAlertRequest
+}
+
+func (f *fakeChatPriceAlerts) CreateRule(ctx context.Context, userID uuid.UUID, req alerts.CreatePriceAlertRequest) (*alerts.PriceAlertRule, error) {
+	f.requests = append(f.requests, req)
+	return &alerts.PriceAlertRule{ID: uuid.New(), UserID: userID, Symbol: req.Symbol, Condition: req.Condition, Threshold: req.Threshold}, nil
+}
+
+type fakeChatCoins struct {
+	symbols []string
+}
+
+func (f *fakeChatCoins) AnalyzeCoin(ctx context.Context, symbol string) (*CoinAnalysisReport, error) {
+	f.symbols = append(f.symbols, symbol)
+	return &CoinAnalysisReport{
+		Symbol:      symbol,
+		CurrentData: &CurrentMarketData{Price: decimal.NewFromInt(3000), ChangePercent24h: decimal.NewFromFloat(2.5)},
+		Summary:     &AnalysisSummary{OverallOutlook: "bullish", KeyInsights: []string{"Rising volume"}},
+	}, nil
+}
+
+func newChatActionsAI() (*ConversationalAI, *fakeChatPriceAlerts, *fakeChatCoins) {
+	priceAlerts := &fakeChatPriceAlerts{}
+	coins := &fakeChatCoins{}
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	conversationalAI.SetActionServices(ChatActionServices{
+		Balances: &fakeChatBalances{balance: &web3.AggregateBalanceResponse{
+			WalletCount:   2,
+			TotalUSDValue: 1500,
+			Tokens:        []*web3.AggregateTokenBalance{{Symbol: "ETH", Balance: decimal.NewFromFloat(0.5), USDValue: 1500}},
+		}},
+		Portfolios: &fakeChatPortfolios{portfolios: []*web3.Portfolio{{
+			Name:           "Main",
+			TotalValue:     decimal.NewFromInt(1100),
+			InvestedAmount: decimal.NewFromInt(1000),
+			TotalPnL:       decimal.NewFromInt(100),
+		}}},
+		PriceAlerts: priceAlerts,
+		Coins:       coins,
+	})
+	return conversationalAI, priceAlerts, coins
+}
+
+func TestConversationalAI_ChatActions(t *testing.T) {
+	ctx := context.Background()
+	conversationalAI, priceAlerts, coins := newChatActionsAI()
+
+	tests := []struct {
+		message string
+		intent  string
+		content string
+	}{
+		{"What's my balance?", ChatIntentCheckBalance, "$1500.00"},
+		{"How is my portfolio performing?", ChatIntentPortfolioPerformance, "(10.00%)"},
+		{"Alert me when bitcoin drops below $60k", ChatIntentCreatePriceAlert, "below $60000"},
+		{"Can you analyze ETH for me?", ChatIntentAnalyzeCoin, "Outlook: bullish"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.intent, func(t *testing.T) {
+			response, err := conversationalAI.ProcessMessage(ctx, uuid.New(), tt.message)
+			require.NoError(t, err)
+			require.Len(t, response.ActionsTaken, 1)
+			assert.Equal(t, tt.intent, response.ActionsTaken[0].Intent)
+			assert.Equal(t, ChatActionCompleted, response.ActionsTaken[0].Status)
+			assert.NotNil(t, response.ActionsTaken[0].Result)
+			assert.Contains(t, response.Content, tt.content)
+		})
+	}
+
+	require.Len(t, priceAlerts.requests, 1)
+	assert.Equal(t, "BTCUSDT", priceAlerts.requests[0].Symbol)
+	assert.Equal(t, alerts.PriceBelow, priceAlerts.requests[0].Condition)
+	assert.True(t, decimal.NewFromInt(60000).Equal(priceAlerts.requests[0].Threshold))
+	assert.Equal(t, []string{"ETH"}, coins.symbols)
+}
+
+func TestConversationalAI_ChatTradeIsOnlyProposed(t *testing.T) {
+	conversationalAI, _, _ := newChatActionsAI()
+
+	response, err := conversationalAI.ProcessMessage(context.Background(), uuid.New(), "Buy 0.5 ETH")
+	require.NoError(t, err)
+	require.Len(t, response.ActionsTaken, 1)
+
+	action := response.ActionsTaken[0]
+	assert.Equal(t, ChatIntentExecuteTrade, action.Intent)
+	assert.Equal(t, ChatActionProposed, action.Status)
+	assert.True(t, action.RequiresConfirmation)
+	assert.Equal(t, "buy", action.Parameters["side"])
+	assert.Equal(t, "0.5", action.Parameters["quantity"])
+	assert.Nil(t, action.Result)
+}
+
+func TestConversationalAI_ChatActionFallbacks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("UnknownIntent", func(t *testing.T) {
+		conversationalAI, _, _ := newChatActionsAI()
+		response, err := conversationalAI.ProcessMessage(ctx, uuid.New(), "Hello there")
+		require.NoError(t, err)
+		assert.Empty(t, response.ActionsTaken)
+		assert.NotEmpty(t, response.Content)
+	})
+
+	t.Run("NoService", func(t *testing.T) {
+		conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+		response, err := conversationalAI.ProcessMessage(ctx, uuid.New(), "What's my balance?")
+		require.NoError(t, err)
+		assert.Empty(t, response.ActionsTaken)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+		conversationalAI.SetActionServices(ChatActionServices{Balances: &fakeChatBalances{err: errors.New("rpc unavailable")}})
+		response, err := conversationalAI.ProcessMessage(ctx, uuid.New(), "Show my wallet balances")
+		require.NoError(t, err)
+		require.Len(t, response.ActionsTaken, 1)
+		assert.Equal(t, ChatActionFailed, response.ActionsTaken[0].Status)
+		assert.Equal(t, "rpc unavailable", response.ActionsTaken[0].Error)
+	})
+}
+
+func TestChatAmount(t *testing.T) {
+	tests := map[string]string{
+		"below $60k":        "60000",
+		"above 1,250.50":    "1250.5",
+		"reaches $2.5k now": "2500",
+	}
+	for message, want := range tests {
+		amount, ok := chatAmount(message)
+		require.True(t, ok, message)
+		assert.Equal(t, want, amount.String(), message)
+	}
+
+	_, ok := chatAmount("no numbers here")
+	assert.False(t, ok)
+}
@@ -0,0 +1,554 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonReplyInstruction adapts JSON requests for providers without a native JSON mode
+const jsonReplyInstruction = "Respond with a single valid JSON object and nothing else."
+
+// CompletionMessage is one turn of a completion request
+type CompletionMessage struct {
+	Role    MessageRole `json:"role"`
+	Content string      `json:"content"`
+}
+
+// CompletionRequest is a provider-neutral chat completion request. Each provider adapts it
+// to its own API.
+type CompletionRequest struct {
+	System      string
+	Messages    []CompletionMessage
+	MaxTokens   int
+	Temperature float64
+	JSON        bool // ask for a JSON object reply
+}
+
+// CompletionProvider generates chat completions with one AI provider
+type CompletionProvider interface {
+	// Name identifies the provider, e.g. openai
+	Name() string
+	// Model is the model completions are requested from
+	Model() string
+	Complete(ctx context.Context, req CompletionRequest) (string, error)
+}
+
+// CompletionStreamer is implemented by providers that can stream a completion. Stream passes
+// each content delta to onDelta as it arrives and returns the whole completion; an error from
+// onDelta stops the stream and is returned.
+type CompletionStreamer interface {
+	Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (string, error)
+}
+
+// ProviderError is a failed provider call. StatusCode is zero when no response was received.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header of 429 and 503 responses
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s returned status %d: %v", e.Provider, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s request failed: %v", e.Provider, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// isTransientProviderError reports whether retrying the same provider may succeed: rate
+// limits, server errors and timeouts
+func isTransientProviderError(err error) bool {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.StatusCode != 0 {
+		return providerErr.StatusCode == http.StatusTooManyRequests || providerErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// openAICompatibleProvider calls a /chat/completions API: OpenAI or LM Studio
+type openAICompatibleProvider struct {
+	name     string
+	baseURL  string
+	apiKey   string
+	model    string
+	jsonMode bool // supports response_format json_object
+	client   *http.Client
+}
+
+// NewOpenAICompletionProvider creates an OpenAI chat completion provider
+func NewOpenAICompletionProvider(apiKey, model string) CompletionProvider {
+	return &openAICompatibleProvider{
+		name:     "openai",
+		baseURL:  "https://api.openai.com/v1",
+		apiKey:   apiKey,
+		model:    model,
+		jsonMode: true,
+		client:   &http.Client{},
+	}
+}
+
+// NewLMStudioCompletionProvider creates a provider for a local LM Studio server
+func NewLMStudioCompletionProvider(baseURL, model string) CompletionProvider {
+	return &openAICompatibleProvider{
+		name:    "lmstudio",
+		baseURL: baseURL,
+		model:   model,
+		client:  &http.Client{},
+	}
+}
+
+func (p *openAICompatibleProvider) Name() string  { return p.name }
+func (p *openAICompatibleProvider) Model() string { return p.model }
+
+func (p *openAICompatibleProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postProviderJSON(ctx, p.client, p.name, p.url(), p.headers(), p.payload(req), &completion); err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", &ProviderError{Provider: p.name, Err: errors.New("empty completion")}
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// Stream reads the server-sent events of a streamed completion, one data line per delta
+// until the [DONE] line
+func (p *openAICompatibleProvider) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (string, error) {
+	payload := p.payload(req)
+	payload["stream"] = true
+
+	var content strings.Builder
+	err := streamProviderLines(ctx, p.client, p.name, p.url(), p.headers(), payload, func(line []byte) (bool, error) {
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			return false, nil
+		}
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			return true, nil
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return false, &ProviderError{Provider: p.name, Err: fmt.Errorf("invalid stream event: %w", err)}
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return false, nil
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		return false, onDelta(chunk.Choices[0].Delta.Content)
+	})
+	if err != nil {
+		return "", err
+	}
+	if content.Len() == 0 {
+		return "", &ProviderError{Provider: p.name, Err: errors.New("empty completion")}
+	}
+	return content.String(), nil
+}
+
+func (p *openAICompatibleProvider) url() string {
+	return strings.TrimRight(p.baseURL, "/") + "/chat/completions"
+}
+
+func (p *openAICompatibleProvider) headers() map[string]string {
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	return headers
+}
+
+func (p *openAICompatibleProvider) payload(req CompletionRequest) map[string]interface{} {
+	system := req.System
+	if req.JSON && !p.jsonMode {
+		system = appendInstruction(system, jsonReplyInstruction)
+	}
+
+	messages := make([]map[string]string, 0, len(req.Messages)+1)
+	if system != "" {
+		messages = append(messages, map[string]string{"role": string(RoleSystem), "content": system})
+	}
+	for _, message := range req.Messages {
+		messages = append(messages, map[string]string{"role": string(message.Role), "content": message.Content})
+	}
+
+	payload := map[string]interface{}{
+		"model":       p.model,
+		"messages":    messages,
+		"temperature": req.Temperature,
+	}
+	if req.MaxTokens > 0 {
+		payload["max_tokens"] = req.MaxTokens
+	}
+	if req.JSON && p.jsonMode {
+		payload["response_format"] = map[string]string{"type": "json_object"}
+	}
+	return payload
+}
+
+// anthropicProvider calls the Anthropic messages API
+type anthropicProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewAnthropicCompletionProvider creates an Anthropic messages API provider
+func NewAnthropicCompletionProvider(apiKey, model string) CompletionProvider {
+	return &anthropicProvider{
+		baseURL: "https://api.anthropic.com/v1",
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{},
+	}
+}
+
+func (p *anthropicProvider) Name() string  { return "anthropic" }
+func (p *anthropicProvider) Model() string { return p.model }
+
+func (p *anthropicProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	var completion struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := postProviderJSON(ctx, p.client, p.Name(), p.url(), p.headers(), p.payload(req), &completion); err != nil {
+		return "", err
+	}
+
+	var content strings.Builder
+	for _, block := range completion.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	if content.Len() == 0 {
+		return "", &ProviderError{Provider: p.Name(), Err: errors.New("empty completion")}
+	}
+	return content.String(), nil
+}
+
+// Stream reads the server-sent events of a streamed message: text arrives in
+// content_block_delta events and the message ends with message_stop
+func (p *anthropicProvider) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (string, error) {
+	payload := p.payload(req)
+	payload["stream"] = true
+
+	var content strings.Builder
+	err := streamProviderLines(ctx, p.client, p.Name(), p.url(), p.headers(), payload, func(line []byte) (bool, error) {
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			return false, nil
+		}
+		var event struct {
+			Type  string `json:"type"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(bytes.TrimSpace(data), &event); err != nil {
+			return false, &ProviderError{Provider: p.Name(), Err: fmt.Errorf("invalid stream event: %w", err)}
+		}
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				return false, nil
+			}
+			content.WriteString(event.Delta.Text)
+			return false, onDelta(event.Delta.Text)
+		case "message_stop":
+			return true, nil
+		case "error":
+			return false, &ProviderError{Provider: p.Name(), Err: errors.New(event.Error.Message)}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if content.Len() == 0 {
+		return "", &ProviderError{Provider: p.Name(), Err: errors.New("empty completion")}
+	}
+	return content.String(), nil
+}
+
+func (p *anthropicProvider) url() string {
+	return strings.TrimRight(p.baseURL, "/") + "/messages"
+}
+
+func (p *anthropicProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": "2023-06-01",
+	}
+}
+
+// payload moves system turns into the system prompt and merges consecutive turns of the
+// same role, since the messages API only accepts alternating user and assistant turns
+// starting with the user
+func (p *anthropicProvider) payload(req CompletionRequest) map[string]interface{} {
+	system := req.System
+	messages := make([]map[string]string, 0, len(req.Messages))
+	for _, message := range req.Messages {
+		if message.Role == RoleSystem {
+			system = appendInstruction(system, message.Content)
+			continue
+		}
+		if len(messages) == 0 && message.Role != RoleUser {
+			continue
+		}
+		if last := len(messages) - 1; last >= 0 && messages[last]["role"] == string(message.Role) {
+			messages[last]["content"] += "\n\n" + message.Content
+			continue
+		}
+		messages = append(messages, map[string]string{"role": string(message.Role), "content": message.Content})
+	}
+	if req.JSON {
+		system = appendInstruction(system, jsonReplyInstruction)
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+	payload := map[string]interface{}{
+		"model":       p.model,
+		"messages":    messages,
+		"max_tokens":  maxTokens,
+		"temperature": req.Temperature,
+	}
+	if system != "" {
+		payload["system"] = system
+	}
+	return payload
+}
+
+// ollamaProvider calls the Ollama chat API
+type ollamaProvider struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOllamaCompletionProvider creates a provider for an Ollama server
+func NewOllamaCompletionProvider(baseURL, model string) CompletionProvider {
+	return &ollamaProvider{
+		baseURL: baseURL,
+		model:   model,
+		client:  &http.Client{},
+	}
+}
+
+func (p *ollamaProvider) Name() string  { return "ollama" }
+func (p *ollamaProvider) Model() string { return p.model }
+
+func (p *ollamaProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	var completion struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := postProviderJSON(ctx, p.client, p.Name(), p.url(), nil, p.payload(req, false), &completion); err != nil {
+		return "", err
+	}
+	return completion.Message.Content, nil
+}
+
+// Stream reads a streamed chat reply, one JSON object per line until the one with done set
+func (p *ollamaProvider) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (string, error) {
+	var content strings.Builder
+	err := streamProviderLines(ctx, p.client, p.Name(), p.url(), nil, p.payload(req, true), func(line []byte) (bool, error) {
+		var chunk struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Done  bool   `json:"done"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return false, &ProviderError{Provider: p.Name(), Err: fmt.Errorf("invalid stream event: %w", err)}
+		}
+		if chunk.Error != "" {
+			return false, &ProviderError{Provider: p.Name(), Err: errors.New(chunk.Error)}
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return false, err
+			}
+		}
+		return chunk.Done, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return content.String(), nil
+}
+
+func (p *ollamaProvider) url() string {
+	return strings.TrimRight(p.baseURL, "/") + "/api/chat"
+}
+
+func (p *ollamaProvider) payload(req CompletionRequest, stream bool) map[string]interface{} {
+	messages := make([]map[string]string, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, map[string]string{"role": string(RoleSystem), "content": req.System})
+	}
+	for _, message := range req.Messages {
+		messages = append(messages, map[string]string{"role": string(message.Role), "content": message.Content})
+	}
+
+	options := map[string]interface{}{"temperature": req.Temperature}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	payload := map[string]interface{}{
+		"model":    p.model,
+		"messages": messages,
+		"stream":   stream,
+		"options":  options,
+	}
+	if req.JSON {
+		payload["format"] = "json"
+	}
+	return payload
+}
+
+// postProviderJSON posts a JSON payload and decodes the JSON reply, returning a ProviderError
+// for failed requests and non-200 responses
+func postProviderJSON(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, payload, out interface{}) error {
+	resp, err := sendProviderRequest(ctx, client, provider, url, headers, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return &ProviderError{Provider: provider, Err: err}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &ProviderError{Provider: provider, Err: fmt.Errorf("invalid response: %w", err)}
+	}
+	return nil
+}
+
+// streamProviderLines posts a JSON payload and passes each non-empty line of the streamed
+// reply to onLine until it reports the end of the stream. A reply that ends without that
+// report was cut off and fails.
+func streamProviderLines(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, payload interface{}, onLine func(line []byte) (bool, error)) error {
+	resp, err := sendProviderRequest(ctx, client, provider, url, headers, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		done, err := onLine(line)
+		if err != nil || done {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &ProviderError{Provider: provider, Err: err}
+	}
+	return &ProviderError{Provider: provider, Err: io.ErrUnexpectedEOF}
+}
+
+// sendProviderRequest posts a JSON payload, returning a ProviderError for failed requests and
+// non-200 responses. The caller closes the body of the returned response.
+func sendProviderRequest(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: provider, Err: err}
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	providerErr := &ProviderError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Err:        errors.New(truncateProviderBody(data)),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		providerErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return nil, providerErr
+}
+
+func truncateProviderBody(data []byte) string {
+	body := strings.TrimSpace(string(data))
+	if len(body) > 200 {
+		body = body[:200] + "…"
+	}
+	if body == "" {
+		body = "empty response body"
+	}
+	return body
+}
+
+func appendInstruction(prompt, instruction string) string {
+	if prompt == "" {
+		return instruction
+	}
+	return prompt + "\n\n" + instruction
+}
@@ -0,0 +1,292 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+)
+
+const (
+	// chatSystemPrompt is the system prompt of chat completions
+	chatSystemPrompt = "You are a helpful cryptocurrency trading and DeFi assistant. Answer concisely and point out risks where relevant. Never present answers as financial advice."
+	// summaryBatch is how many messages past the context window are collected before they are
+	// folded into the summary, so the summary isn't rewritten on every exchange
+	summaryBatch = 4
+	// summaryExcerptLength caps each message in the built-in summary
+	summaryExcerptLength = 160
+)
+
+// ChatOptions tune a single chat exchange
+type ChatOptions struct {
+	Debug bool // include the context assembled for the model in the response
+}
+
+// ChatContextConfig controls how much of a conversation is sent to the chat model and what
+// its calls are estimated to cost. Zero values keep the current settings.
+type ChatContextConfig struct {
+	Messages         int          // newest messages sent verbatim; older ones are summarized
+	SummaryMaxTokens int          // length cap of the rolling summary
+	Pricing          TokenPricing // for cost estimates
+}
+
+// TokenPricing is what a thousand tokens cost, in USD
+type TokenPricing struct {
+	PromptPer1K     float64 `json:"prompt_per_1k"`
+	CompletionPer1K float64 `json:"completion_per_1k"`
+}
+
+// ConversationUsage is the estimated token usage and cost of a conversation's model calls,
+// summaries included
+type ConversationUsage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// ChatContextDebug is the context assembled for a chat exchange, returned on request
+type ChatContextDebug struct {
+	System          string              `json:"system"`
+	Summary         string              `json:"summary,omitempty"`
+	SummarizedUntil *time.Time          `json:"summarized_until,omitempty"`
+	Snippets        []string            `json:"snippets,omitempty"`
+	Messages        []CompletionMessage `json:"messages"`
+	EstimatedTokens int                 `json:"estimated_tokens"`
+	SentToModel     bool                `json:"sent_to_model"` // false when the reply was built in
+}
+
+// SetContextConfig changes the conversation context sent to the chat model and the token
+// prices its cost is estimated at
+func (c *ConversationalAI) SetContextConfig(config ChatContextConfig) {
+	if config.Messages > 0 {
+		c.config.ContextWindow = config.Messages
+	}
+	if config.SummaryMaxTokens > 0 {
+		c.config.SummaryMaxTokens = config.SummaryMaxTokens
+	}
+	if config.Pricing.PromptPer1K > 0 || config.Pricing.CompletionPer1K > 0 {
+		c.config.Pricing = config.Pricing
+	}
+}
+
+// assembleContext builds what the chat model sees: the system prompt with the rolling
+// summary and, for intents that need them, snippets of the user's portfolio state, followed
+// by the messages the summary doesn't cover
+func (c *ConversationalAI) assembleContext(ctx context.Context, conversation *Conversation, message, intent string) *ChatContextDebug {
+	c.mu.RLock()
+	assembled := &ChatContextDebug{Summary: conversation.Summary, Messages: make([]CompletionMessage, 0)}
+	if conversation.SummarizedUntil != nil {
+		summarizedUntil := *conversation.SummarizedUntil
+		assembled.SummarizedUntil = &summarizedUntil
+	}
+	for _, m := range conversation.Messages {
+		if conversation.SummarizedUntil == nil || m.Timestamp.After(*conversation.SummarizedUntil) {
+			assembled.Messages = append(assembled.Messages, CompletionMessage{Role: m.Role, Content: m.Content})
+		}
+	}
+	c.mu.RUnlock()
+
+	// Messages dropped from the history before they could be summarized are lost to the
+	// model; cap the verbatim part at the window plus a pending summary batch
+	if limit := c.config.ContextWindow + summaryBatch; c.config.ContextWindow > 0 && len(assembled.Messages) > limit {
+		assembled.Messages = assembled.Messages[len(assembled.Messages)-limit:]
+	}
+
+	if contextNeedsPortfolio(message, intent) {
+		assembled.Snippets = c.portfolioSnippets(ctx, conversation)
+	}
+
+	var system strings.Builder
+	system.WriteString(chatSystemPrompt)
+	if assembled.Summary != "" {
+		fmt.Fprintf(&system, "\n\nSummary of the earlier conversation:\n%s", assembled.Summary)
+	}
+	if len(assembled.Snippets) > 0 {
+		fmt.Fprintf(&system, "\n\nThe user's current portfolio state:\n%s", strings.Join(assembled.Snippets, "\n"))
+	}
+	assembled.System = system.String()
+
+	assembled.EstimatedTokens = estimateTokens(assembled.System)
+	for _, m := range assembled.Messages {
+		assembled.EstimatedTokens += estimateTokens(m.Content)
+	}
+	return assembled
+}
+
+// contextNeedsPortfolio tells whether a message is about the user's own holdings, so that
+// portfolio state is worth its tokens
+func contextNeedsPortfolio(message, intent string) bool {
+	if intent == "portfolio_advice" || intent == "risk_assessment" {
+		return true
+	}
+	lower := strings.ToLower(message)
+	for _, keyword := range []string{"position", "holding", "exposure", "my balance", "p&l", "pnl"} {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// portfolioSnippets describes the user's portfolios and their open positions in a line each
+func (c *ConversationalAI) portfolioSnippets(ctx context.Context, conversation *Conversation) []string {
+	var portfolios []*web3.Portfolio
+	if c.actions.Portfolios != nil {
+		listed, err := c.actions.Portfolios.ListUserPortfolios(ctx, conversation.UserID)
+		if err != nil {
+			c.logger.Warn(ctx, "Failed to load portfolios for chat context", map[string]interface{}{
+				"conversation_id": conversation.ID.String(),
+				"error":           err.Error(),
+			})
+		}
+		portfolios = listed
+	}
+	if len(portfolios) == 0 && conversation.Context.CurrentPortfolio != nil {
+		portfolios = []*web3.Portfolio{conversation.Context.CurrentPortfolio}
+	}
+
+	snippets := make([]string, 0, len(portfolios))
+	for _, portfolio := range portfolios {
+		holdings := make([]string, 0, len(portfolio.Holdings))
+		for _, holding := range portfolio.Holdings {
+			holdings = append(holdings, fmt.Sprintf("%s %s ($%s, P&L %s%%)",
+				holding.Amount.String(), holding.TokenSymbol, holding.Value.StringFixed(2), holding.PnLPercentage.StringFixed(2)))
+		}
+		snippet := fmt.Sprintf("- %s: value $%s, available $%s, total P&L $%s, %d open position(s)",
+			portfolio.Name, portfolio.TotalValue.StringFixed(2), portfolio.AvailableBalance.StringFixed(2),
+			portfolio.TotalPnL.StringFixed(2), len(portfolio.ActivePositions))
+		if len(holdings) > 0 {
+			sort.Strings(holdings)
+			snippet += "; holdings: " + strings.Join(holdings, ", ")
+		}
+		snippets = append(snippets, snippet)
+	}
+	return snippets
+}
+
+// summarizeOlderMessages folds the messages that fell out of the context window into the
+// conversation's rolling summary, once a batch of them has collected. The AI providers write
+// the summary when configured; otherwise, or when they fail, excerpts are appended.
+func (c *ConversationalAI) summarizeOlderMessages(ctx context.Context, conversation *Conversation) {
+	if c.config.ContextWindow <= 0 {
+		return
+	}
+
+	c.mu.RLock()
+	var pending []ConversationMessage
+	if older := len(conversation.Messages) - c.config.ContextWindow; older > 0 {
+		for _, m := range conversation.Messages[:older] {
+			if conversation.SummarizedUntil == nil || m.Timestamp.After(*conversation.SummarizedUntil) {
+				pending = append(pending, m)
+			}
+		}
+	}
+	previous := conversation.Summary
+	c.mu.RUnlock()
+
+	if len(pending) < summaryBatch {
+		return
+	}
+
+	summary := ""
+	if c.completions != nil {
+		var transcript strings.Builder
+		if previous != "" {
+			fmt.Fprintf(&transcript, "Summary so far:\n%s\n\n", previous)
+		}
+		transcript.WriteString("New messages:\n")
+		for _, m := range pending {
+			fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+		}
+		request := CompletionRequest{
+			System:      "Update the summary of this conversation between a user and a crypto assistant with the new messages. Keep facts the user shared, their goals, and decisions made. Reply with the summary only.",
+			Messages:    []CompletionMessage{{Role: RoleUser, Content: transcript.String()}},
+			MaxTokens:   c.config.SummaryMaxTokens,
+			Temperature: 0.2,
+		}
+		result, err := c.completions.Complete(ctx, "summary", request)
+		if err == nil {
+			summary = strings.TrimSpace(result.Content)
+			c.recordUsage(ctx, conversation, estimateTokens(request.System)+estimateTokens(transcript.String()), estimateTokens(result.Content))
+		} else {
+			c.logger.Warn(ctx, "AI providers failed to summarize conversation, using excerpts", map[string]interface{}{
+				"conversation_id": conversation.ID.String(),
+				"error":           err.Error(),
+			})
+		}
+	}
+	if summary == "" {
+		summary = excerptSummary(previous, pending, c.config.SummaryMaxTokens)
+	}
+
+	summarizedUntil := pending[len(pending)-1].Timestamp
+	c.mu.Lock()
+	conversation.Summary = summary
+	conversation.SummarizedUntil = &summarizedUntil
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.UpdateSummary(ctx, conversation.ID, summary, summarizedUntil); err != nil {
+			c.logger.Error(ctx, "Failed to persist conversation summary", err, map[string]interface{}{
+				"conversation_id": conversation.ID.String(),
+			})
+		}
+	}
+}
+
+// excerptSummary appends an excerpt of each message to the summary, dropping the oldest
+// lines once it exceeds maxTokens
+func excerptSummary(previous string, messages []ConversationMessage, maxTokens int) string {
+	lines := make([]string, 0)
+	if previous != "" {
+		lines = append(lines, strings.Split(previous, "\n")...)
+	}
+	for _, m := range messages {
+		content := strings.Join(strings.Fields(m.Content), " ")
+		if runes := []rune(content); len(runes) > summaryExcerptLength {
+			content = string(runes[:summaryExcerptLength]) + "…"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", m.Role, content))
+	}
+
+	summary := strings.Join(lines, "\n")
+	for maxTokens > 0 && len(lines) > 1 && estimateTokens(summary) > maxTokens {
+		lines = lines[1:]
+		summary = strings.Join(lines, "\n")
+	}
+	return summary
+}
+
+// recordUsage adds the tokens of a model call to the conversation's usage
+func (c *ConversationalAI) recordUsage(ctx context.Context, conversation *Conversation, promptTokens, completionTokens int) {
+	usage := ConversationUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+
+	c.mu.Lock()
+	usage.EstimatedCostUSD = float64(promptTokens)/1000*c.config.Pricing.PromptPer1K +
+		float64(completionTokens)/1000*c.config.Pricing.CompletionPer1K
+	conversation.Usage.add(usage)
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.AddUsage(ctx, conversation.ID, usage); err != nil {
+			c.logger.Error(ctx, "Failed to persist conversation usage", err, map[string]interface{}{
+				"conversation_id": conversation.ID.String(),
+			})
+		}
+	}
+}
+
+func (u *ConversationUsage) add(other ConversationUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.EstimatedCostUSD += other.EstimatedCostUSD
+}
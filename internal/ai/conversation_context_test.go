@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationalAI_SummarizesOlderMessages(t *testing.T) {
+	ctx := context.Background()
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	conversationalAI.SetContextConfig(ChatContextConfig{Messages: 4})
+	userID := uuid.New()
+
+	// The welcome message plus six exchanges
+	for i := 0; i < 6; i++ {
+		_, err := conversationalAI.ProcessMessage(ctx, userID, fmt.Sprintf("Question number %d", i))
+		require.NoError(t, err)
+	}
+
+	conversation := conversationalAI.conversations[userID]
+	require.NotNil(t, conversation.SummarizedUntil)
+	assert.Contains(t, conversation.Summary, "user: Question number 0")
+	assert.NotContains(t, conversation.Summary, "Question number 5")
+
+	response, err := conversationalAI.ProcessMessageWithOptions(ctx, userID, "And one more thing", ChatOptions{Debug: true})
+	require.NoError(t, err)
+	require.NotNil(t, response.Debug)
+	assert.False(t, response.Debug.SentToModel)
+	assert.Contains(t, response.Debug.System, "Summary of the earlier conversation")
+	assert.LessOrEqual(t, len(response.Debug.Messages), 4+summaryBatch)
+	assert.Equal(t, "And one more thing", response.Debug.Messages[len(response.Debug.Messages)-1].Content)
+	for _, message := range response.Debug.Messages {
+		assert.NotEqual(t, "Question number 0", message.Content, "summarized messages are not sent verbatim")
+	}
+
+	// No model was called, so nothing was spent
+	assert.Zero(t, conversation.Usage.TotalTokens)
+
+	withoutDebug, err := conversationalAI.ProcessMessage(ctx, userID, "Thanks")
+	require.NoError(t, err)
+	assert.Nil(t, withoutDebug.Debug)
+}
+
+func TestConversationalAI_TracksUsageAcrossRestart(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryConversationStore()
+	userID := uuid.New()
+
+	first := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	first.SetConversationStore(store)
+	first.SetCompletionRouter(newTestProviderRouter(&scriptedProvider{name: "primary"}))
+	first.SetContextConfig(ChatContextConfig{Messages: 2, Pricing: TokenPricing{PromptPer1K: 1, CompletionPer1K: 2}})
+
+	for i := 0; i < 4; i++ {
+		response, err := first.ProcessMessage(ctx, userID, fmt.Sprintf("Tell me something interesting %d", i))
+		require.NoError(t, err)
+		assert.Equal(t, "reply from primary", response.Content)
+	}
+
+	conversation := first.conversations[userID]
+	assert.Equal(t, "reply from primary", conversation.Summary, "the providers write the summary")
+	usage := conversation.Usage
+	assert.Positive(t, usage.PromptTokens)
+	assert.Positive(t, usage.CompletionTokens)
+	assert.Equal(t, usage.PromptTokens+usage.CompletionTokens, usage.TotalTokens)
+	assert.InDelta(t, float64(usage.PromptTokens)/1000+float64(usage.CompletionTokens)*2/1000, usage.EstimatedCostUSD, 1e-9)
+
+	restarted := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	restarted.SetConversationStore(store)
+	list, err := restarted.ListConversations(ctx, userID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Conversations, 1)
+	assert.Equal(t, usage.PromptTokens, list.Conversations[0].Usage.PromptTokens)
+	assert.InDelta(t, usage.EstimatedCostUSD, list.Conversations[0].Usage.EstimatedCostUSD, 1e-9)
+
+	response, err := restarted.ProcessMessageWithOptions(ctx, userID, "What did we discuss?", ChatOptions{Debug: true})
+	require.NoError(t, err)
+	require.NotNil(t, response.Debug)
+	assert.Equal(t, "reply from primary", response.Debug.Summary)
+}
+
+func TestConversationalAI_PortfolioSnippetsOnlyWhenNeeded(t *testing.T) {
+	ctx := context.Background()
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	conversationalAI.SetCompletionRouter(newTestProviderRouter(&scriptedProvider{name: "primary"}))
+	conversationalAI.SetActionServices(ChatActionServices{Portfolios: &fakeChatPortfolios{portfolios: []*web3.Portfolio{{
+		Name:       "Main",
+		TotalValue: decimal.NewFromInt(1100),
+		Holdings: map[string]*web3.Holding{
+			"0xeth": {TokenSymbol: "ETH", Amount: decimal.NewFromFloat(0.5), Value: decimal.NewFromInt(1100)},
+		},
+	}}}})
+	userID := uuid.New()
+
+	response, err := conversationalAI.ProcessMessageWithOptions(ctx, userID, "Are my open positions too concentrated?", ChatOptions{Debug: true})
+	require.NoError(t, err)
+	require.NotNil(t, response.Debug)
+	assert.True(t, response.Debug.SentToModel)
+	require.Len(t, response.Debug.Snippets, 1)
+	assert.Contains(t, response.Debug.Snippets[0], "Main: value $1100.00")
+	assert.Contains(t, response.Debug.Snippets[0], "0.5 ETH")
+	assert.Contains(t, response.Debug.System, "current portfolio state")
+
+	response, err = conversationalAI.ProcessMessageWithOptions(ctx, userID, "Explain how staking works", ChatOptions{Debug: true})
+	require.NoError(t, err)
+	require.NotNil(t, response.Debug)
+	assert.Empty(t, response.Debug.Snippets)
+	assert.NotContains(t, response.Debug.System, "current portfolio state")
+}
+
+func TestExcerptSummary(t *testing.T) {
+	messages := []ConversationMessage{
+		{Role: RoleUser, Content: "first   question\nwith lines"},
+		{Role: RoleAssistant, Content: "first answer"},
+	}
+	summary := excerptSummary("", messages, 0)
+	assert.Equal(t, "user: first question with lines\nassistant: first answer", summary)
+
+	// Over the cap the oldest lines go first
+	capped := excerptSummary(summary, []ConversationMessage{{Role: RoleUser, Content: "second question"}}, 12)
+	assert.Equal(t, "assistant: first answer\nuser: second question", capped)
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrConversationNotFound is returned when a conversation does not exist or belongs to another user
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ConversationSummary is a conversation entry in a user's listing
+type ConversationSummary struct {
+	ID           uuid.UUID         `json:"id"`
+	Title        string            `json:"title"`
+	MessageCount int               `json:"message_count"`
+	StartedAt    time.Time         `json:"started_at"`
+	LastActive   time.Time         `json:"last_active"`
+	Usage        ConversationUsage `json:"usage"`
+}
+
+// ConversationList is a page of conversation summaries
+type ConversationList struct {
+	Conversations []ConversationSummary `json:"conversations"`
+	Total         int                   `json:"total"`
+	Limit         int                   `json:"limit"`
+	Offset        int                   `json:"offset"`
+}
+
+// ConversationMessageList is a page of messages of a single conversation, oldest first
+type ConversationMessageList struct {
+	ConversationID uuid.UUID             `json:"conversation_id"`
+	Messages       []ConversationMessage `json:"messages"`
+	Total          int                   `json:"total"`
+	Limit          int                   `json:"limit"`
+	Offset         int                   `json:"offset"`
+}
+
+// ConversationStore persists conversations and their messages
+type ConversationStore interface {
+	SaveConversation(ctx context.Context, conversation *Conversation) error
+	AppendMessage(ctx context.Context, conversationID uuid.UUID, message ConversationMessage) error
+	// UpdateSummary stores the rolling summary of the messages up to summarizedUntil
+	UpdateSummary(ctx context.Context, conversationID uuid.UUID, summary string, summarizedUntil time.Time) error
+	// AddUsage adds the tokens and cost of model calls to the conversation's totals
+	AddUsage(ctx context.Context, conversationID uuid.UUID, usage ConversationUsage) error
+	// LatestConversation returns the user's most recently active conversation with its summary,
+	// usage and up to maxMessages of its newest messages, or ErrConversationNotFound
+	LatestConversation(ctx context.Context, userID uuid.UUID, maxMessages int) (*Conversation, error)
+	ListConversations(ctx context.Context, userID uuid.UUID, limit, offset int) ([]ConversationSummary, int, error)
+	ListMessages(ctx context.Context, userID, conversationID uuid.UUID, limit, offset int) ([]ConversationMessage, int, error)
+	// DeleteUserConversations deletes the user's conversations with their messages and
+	// returns the number of conversations deleted
+	DeleteUserConversations(ctx context.Context, userID uuid.UUID) (int, error)
+}
@@ -0,0 +1,226 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresConversationStore implements ConversationStore using the ai_conversations and ai_messages tables
+type postgresConversationStore struct {
+	db *database.DB
+}
+
+func NewPostgresConversationStore(db *database.DB) ConversationStore {
+	return &postgresConversationStore{db: db}
+}
+
+func (s *postgresConversationStore) SaveConversation(ctx context.Context, conversation *Conversation) error {
+	query := `
+		INSERT INTO ai_conversations (id, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.db.ExecWithMetrics(ctx, query, conversation.ID, conversation.UserID, conversation.StartedAt, conversation.LastActive)
+	return err
+}
+
+func (s *postgresConversationStore) AppendMessage(ctx context.Context, conversationID uuid.UUID, message ConversationMessage) error {
+	var metadata interface{}
+	if len(message.Metadata) > 0 {
+		metadata = []byte(message.Metadata)
+	}
+
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ai_messages (id, conversation_id, role, content, metadata, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, message.ID, conversationID, string(message.Role), message.Content, metadata, message.Timestamp); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE ai_conversations SET updated_at = $2 WHERE id = $1`, conversationID, message.Timestamp)
+		return err
+	})
+}
+
+func (s *postgresConversationStore) UpdateSummary(ctx context.Context, conversationID uuid.UUID, summary string, summarizedUntil time.Time) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE ai_conversations SET summary = $2, summarized_until = $3 WHERE id = $1
+	`, conversationID, summary, summarizedUntil)
+	return err
+}
+
+func (s *postgresConversationStore) AddUsage(ctx context.Context, conversationID uuid.UUID, usage ConversationUsage) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE ai_conversations
+		SET prompt_tokens = prompt_tokens + $2,
+		    completion_tokens = completion_tokens + $3,
+		    estimated_cost_usd = estimated_cost_usd + $4
+		WHERE id = $1
+	`, conversationID, usage.PromptTokens, usage.CompletionTokens, usage.EstimatedCostUSD)
+	return err
+}
+
+func (s *postgresConversationStore) LatestConversation(ctx context.Context, userID uuid.UUID, maxMessages int) (*Conversation, error) {
+	conversation := &Conversation{
+		UserID:   userID,
+		Messages: make([]ConversationMessage, 0),
+		Metadata: make(map[string]interface{}),
+	}
+	var summary sql.NullString
+	var summarizedUntil sql.NullTime
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, created_at, updated_at, summary, summarized_until,
+		       prompt_tokens, completion_tokens, estimated_cost_usd
+		FROM ai_conversations
+		WHERE user_id = $1
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`, userID)
+	if err := row.Scan(&conversation.ID, &conversation.StartedAt, &conversation.LastActive, &summary, &summarizedUntil,
+		&conversation.Usage.PromptTokens, &conversation.Usage.CompletionTokens, &conversation.Usage.EstimatedCostUSD); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	conversation.Summary = summary.String
+	if summarizedUntil.Valid {
+		conversation.SummarizedUntil = &summarizedUntil.Time
+	}
+	conversation.Usage.TotalTokens = conversation.Usage.PromptTokens + conversation.Usage.CompletionTokens
+
+	// Newest messages first, then reversed into chronological order
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, role, content, metadata, created_at FROM ai_messages
+		WHERE conversation_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, conversation.ID, maxMessages)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanConversationMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	conversation.Messages = messages
+
+	return conversation, nil
+}
+
+func (s *postgresConversationStore) ListConversations(ctx context.Context, userID uuid.UUID, limit, offset int) ([]ConversationSummary, int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ai_conversations WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.id, c.title, c.created_at, c.updated_at,
+		       c.prompt_tokens, c.completion_tokens, c.estimated_cost_usd,
+		       (SELECT COUNT(*) FROM ai_messages m WHERE m.conversation_id = c.id),
+		       (SELECT m.content FROM ai_messages m WHERE m.conversation_id = c.id AND m.role = 'user'
+		        ORDER BY m.created_at LIMIT 1)
+		FROM ai_conversations c
+		WHERE c.user_id = $1
+		ORDER BY c.updated_at DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	summaries := make([]ConversationSummary, 0)
+	for rows.Next() {
+		var summary ConversationSummary
+		var title, firstMessage sql.NullString
+		if err := rows.Scan(&summary.ID, &title, &summary.StartedAt, &summary.LastActive,
+			&summary.Usage.PromptTokens, &summary.Usage.CompletionTokens, &summary.Usage.EstimatedCostUSD,
+			&summary.MessageCount, &firstMessage); err != nil {
+			return nil, 0, err
+		}
+		summary.Usage.TotalTokens = summary.Usage.PromptTokens + summary.Usage.CompletionTokens
+		summary.Title = title.String
+		if summary.Title == "" {
+			summary.Title = conversationTitle(firstMessage.String)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return summaries, total, nil
+}
+
+func (s *postgresConversationStore) ListMessages(ctx context.Context, userID, conversationID uuid.UUID, limit, offset int) ([]ConversationMessage, int, error) {
+	var total int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(m.id) FROM ai_conversations c
+		LEFT JOIN ai_messages m ON m.conversation_id = c.id
+		WHERE c.id = $1 AND c.user_id = $2
+		GROUP BY c.id
+	`, conversationID, userID).Scan(&total)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, ErrConversationNotFound
+		}
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, role, content, metadata, created_at FROM ai_messages
+		WHERE conversation_id = $1
+		ORDER BY created_at
+		LIMIT $2 OFFSET $3
+	`, conversationID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages, err := scanConversationMessages(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
+
+func scanConversationMessages(rows *sql.Rows) ([]ConversationMessage, error) {
+	messages := make([]ConversationMessage, 0)
+	for rows.Next() {
+		var message ConversationMessage
+		var role string
+		var metadata []byte
+		if err := rows.Scan(&message.ID, &role, &message.Content, &metadata, &message.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		message.Role = MessageRole(role)
+		if len(metadata) > 0 {
+			message.Metadata = metadata
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (s *postgresConversationStore) DeleteUserConversations(ctx context.Context, userID uuid.UUID) (int, error) {
+	// Messages are deleted by the ai_messages foreign key cascade
+	result, err := s.db.ExecWithMetrics(ctx, `DELETE FROM ai_conversations WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
@@ -3,8 +3,10 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/web3"
@@ -20,8 +22,13 @@ type ConversationalAI struct {
 	defiManager    *web3.DeFiProtocolManager
 	riskAssessment *web3.RiskAssessmentService
 	marketAnalyzer *MarketAnalyzer
-	conversations  map[uuid.UUID]*Conversation
+	conversations  map[uuid.UUID]*Conversation // active conversation per user, cached in front of store
+	store          ConversationStore
+	providers      *ProviderRegistry
+	completions    *ProviderRouter
 	config         ConversationalConfig
+	actions        ChatActionServices
+	mu             sync.RWMutex
 }
 
 // ConversationalConfig holds configuration for conversational AI
@@ -32,6 +39,8 @@ type ConversationalConfig struct {
 	EnablePersonalization  bool          `json:"enable_personalization"`
 	EnableMarketInsights   bool          `json:"enable_market_insights"`
 	EnableRiskWarnings     bool          `json:"enable_risk_warnings"`
+	SummaryMaxTokens       int           `json:"summary_max_tokens"`
+	Pricing                TokenPricing  `json:"pricing"`
 }
 
 // Conversation represents an ongoing conversation with a user
@@ -43,6 +52,11 @@ type Conversation struct {
 	StartedAt  time.Time              `json:"started_at"`
 	LastActive time.Time              `json:"last_active"`
 	Metadata   map[string]interface{} `json:"metadata"`
+
+	// Summary condenses the messages up to SummarizedUntil, which are no longer sent to the model
+	Summary         string            `json:"summary,omitempty"`
+	SummarizedUntil *time.Time        `json:"summarized_until,omitempty"`
+	Usage           ConversationUsage `json:"usage"`
 }
 
 // ConversationMessage represents a message in a conversation
@@ -112,13 +126,32 @@ type MarketEvent struct {
 
 // ConversationalResponse represents an AI response
 type ConversationalResponse struct {
-	Content     string                 `json:"content"`
-	Insights    []MarketInsight        `json:"insights,omitempty"`
-	Suggestions []ActionSuggestion     `json:"suggestions,omitempty"`
-	Warnings    []RiskWarning          `json:"warnings,omitempty"`
-	Data        interface{}            `json:"data,omitempty"`
-	Confidence  float64                `json:"confidence"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	Content      string                 `json:"content"`
+	Insights     []MarketInsight        `json:"insights,omitempty"`
+	Suggestions  []ActionSuggestion     `json:"suggestions,omitempty"`
+	Warnings     []RiskWarning          `json:"warnings,omitempty"`
+	Data         interface{}            `json:"data,omitempty"`
+	Confidence   float64                `json:"confidence"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	ActionsTaken []ChatAction           `json:"actions_taken,omitempty"`
+	Debug        *ChatContextDebug      `json:"debug,omitempty"`
+}
+
+// ConversationalStreamChunk represents a single event of a streamed response
+type ConversationalStreamChunk struct {
+	Delta          string                  `json:"delta,omitempty"`
+	Done           bool                    `json:"done"`
+	ConversationID uuid.UUID               `json:"conversation_id,omitempty"`
+	Usage          *TokenUsage             `json:"usage,omitempty"`
+	Response       *ConversationalResponse `json:"response,omitempty"`
+	Error          string                  `json:"error,omitempty"`
+}
+
+// TokenUsage represents token accounting for a single exchange
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // MarketInsight represents an AI-generated market insight
@@ -164,6 +197,8 @@ func NewConversationalAI(
 		EnablePersonalization:  true,
 		EnableMarketInsights:   true,
 		EnableRiskWarnings:     true,
+		SummaryMaxTokens:       300,
+		Pricing:                TokenPricing{PromptPer1K: 0.0005, CompletionPer1K: 0.0015},
 	}
 
 	return &ConversationalAI{
@@ -177,6 +212,27 @@ func NewConversationalAI(
 	}
 }
 
+// SetProviderRegistry attaches the registry of AI providers backing the assistant
+func (c *ConversationalAI) SetProviderRegistry(registry *ProviderRegistry) {
+	c.providers = registry
+}
+
+// SetCompletionRouter lets general questions be answered by the routed AI providers, with
+// retries and failover. Without a router the assistant uses its built-in replies.
+func (c *ConversationalAI) SetCompletionRouter(router *ProviderRouter) {
+	c.completions = router
+}
+
+// Providers returns the attached provider registry, or nil when none is configured
+func (c *ConversationalAI) Providers() *ProviderRegistry {
+	return c.providers
+}
+
+// SetConversationStore enables persistence of conversations; without a store they live in memory only
+func (c *ConversationalAI) SetConversationStore(store ConversationStore) {
+	c.store = store
+}
+
 // StartConversation starts a new conversation with a user
 func (c *ConversationalAI) StartConversation(ctx context.Context, userID uuid.UUID) (*Conversation, error) {
 	conversation := &Conversation{
@@ -189,11 +245,19 @@ func (c *ConversationalAI) StartConversation(ctx context.Context, userID uuid.UU
 		Metadata:   make(map[string]interface{}),
 	}
 
+	if c.store != nil {
+		if err := c.store.SaveConversation(ctx, conversation); err != nil {
+			return nil, fmt.Errorf("failed to persist conversation: %w", err)
+		}
+	}
+
+	c.mu.Lock()
 	c.conversations[userID] = conversation
+	c.mu.Unlock()
 
 	// Add welcome message
 	welcomeMsg := c.generateWelcomeMessage(ctx, conversation)
-	c.addMessage(conversation, RoleAssistant, welcomeMsg)
+	c.addMessage(ctx, conversation, RoleAssistant, welcomeMsg)
 
 	c.logger.Info(ctx, "Conversation started", map[string]interface{}{
 		"conversation_id": conversation.ID.String(),
@@ -205,36 +269,295 @@ func (c *ConversationalAI) StartConversation(ctx context.Context, userID uuid.UU
 
 // ProcessMessage processes a user message and generates a response
 func (c *ConversationalAI) ProcessMessage(ctx context.Context, userID uuid.UUID, message string) (*ConversationalResponse, error) {
-	conversation, exists := c.conversations[userID]
-	if !exists {
-		var err error
-		conversation, err = c.StartConversation(ctx, userID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start conversation: %w", err)
-		}
+	return c.ProcessMessageWithOptions(ctx, userID, message, ChatOptions{})
+}
+
+// ProcessMessageWithOptions processes a user message like ProcessMessage, with options for the exchange
+func (c *ConversationalAI) ProcessMessageWithOptions(ctx context.Context, userID uuid.UUID, message string, opts ChatOptions) (*ConversationalResponse, error) {
+	conversation, err := c.activeConversation(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Add user message
-	c.addMessage(conversation, RoleUser, message)
+	c.addMessage(ctx, conversation, RoleUser, message)
 	conversation.LastActive = time.Now()
 
 	// Update context based on message
 	c.updateContext(ctx, conversation, message)
 
 	// Generate response
-	response, err := c.generateResponse(ctx, conversation, message)
+	response, err := c.generateResponse(ctx, conversation, message, opts, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate response: %w", err)
 	}
 
 	// Add assistant response
-	c.addMessage(conversation, RoleAssistant, response.Content)
+	c.addMessage(ctx, conversation, RoleAssistant, response.Content)
 
 	return response, nil
 }
 
-// generateResponse generates an AI response based on the conversation context
-func (c *ConversationalAI) generateResponse(ctx context.Context, conversation *Conversation, message string) (*ConversationalResponse, error) {
+// ProcessMessageStream processes a user message and streams the response as it is produced.
+// The returned channel yields content deltas followed by a final chunk with Done set, or a
+// chunk with Error set if generation fails. The channel is closed without a final chunk
+// when ctx is cancelled.
+func (c *ConversationalAI) ProcessMessageStream(ctx context.Context, userID uuid.UUID, message string) (<-chan *ConversationalStreamChunk, error) {
+	return c.ProcessMessageStreamWithOptions(ctx, userID, message, ChatOptions{})
+}
+
+// ProcessMessageStreamWithOptions streams a response like ProcessMessageStream, with options for the exchange
+func (c *ConversationalAI) ProcessMessageStreamWithOptions(ctx context.Context, userID uuid.UUID, message string, opts ChatOptions) (<-chan *ConversationalStreamChunk, error) {
+	conversation, err := c.activeConversation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add user message
+	c.addMessage(ctx, conversation, RoleUser, message)
+	conversation.LastActive = time.Now()
+
+	// Update context based on message
+	c.updateContext(ctx, conversation, message)
+
+	chunks := make(chan *ConversationalStreamChunk)
+
+	go func() {
+		defer close(chunks)
+
+		send := func(chunk *ConversationalStreamChunk) bool {
+			select {
+			case chunks <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		cancelled := func() {
+			c.logger.Info(ctx, "Chat stream cancelled by client", map[string]interface{}{
+				"conversation_id": conversation.ID.String(),
+			})
+		}
+
+		// Provider replies are passed on as the provider streams them
+		streamed := false
+		response, err := c.generateResponse(ctx, conversation, message, opts, func(delta string) error {
+			streamed = true
+			if !send(&ConversationalStreamChunk{Delta: delta}) {
+				return ctx.Err()
+			}
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				cancelled()
+				return
+			}
+			send(&ConversationalStreamChunk{
+				ConversationID: conversation.ID,
+				Error:          fmt.Sprintf("failed to generate response: %v", err),
+			})
+			return
+		}
+
+		// Built-in replies are complete at once and are sent word by word
+		if !streamed {
+			for _, token := range splitStreamTokens(response.Content) {
+				if !send(&ConversationalStreamChunk{Delta: token}) {
+					cancelled()
+					return
+				}
+			}
+		}
+
+		// Add assistant response only once the full content has been delivered; persist it even if the client leaves now
+		c.addMessage(context.WithoutCancel(ctx), conversation, RoleAssistant, response.Content)
+
+		send(&ConversationalStreamChunk{
+			Done:           true,
+			ConversationID: conversation.ID,
+			Usage: &TokenUsage{
+				PromptTokens:     estimateTokens(message),
+				CompletionTokens: estimateTokens(response.Content),
+				TotalTokens:      estimateTokens(message) + estimateTokens(response.Content),
+			},
+			Response: response,
+		})
+	}()
+
+	return chunks, nil
+}
+
+// activeConversation returns the user's current conversation from the cache, then the store,
+// starting a new one when neither has it
+func (c *ConversationalAI) activeConversation(ctx context.Context, userID uuid.UUID) (*Conversation, error) {
+	c.mu.RLock()
+	conversation, exists := c.conversations[userID]
+	c.mu.RUnlock()
+	if exists {
+		return conversation, nil
+	}
+
+	if c.store != nil {
+		stored, err := c.store.LatestConversation(ctx, userID, c.config.MaxConversationHistory)
+		switch {
+		case err == nil:
+			stored.Context = c.initializeContext(ctx, userID)
+			c.mu.Lock()
+			if cached, exists := c.conversations[userID]; exists {
+				stored = cached
+			} else {
+				c.conversations[userID] = stored
+			}
+			c.mu.Unlock()
+			return stored, nil
+		case !errors.Is(err, ErrConversationNotFound):
+			return nil, fmt.Errorf("failed to load conversation: %w", err)
+		}
+	}
+
+	conversation, err := c.StartConversation(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+// ListConversations returns a page of the user's conversations, most recently active first
+func (c *ConversationalAI) ListConversations(ctx context.Context, userID uuid.UUID, limit, offset int) (*ConversationList, error) {
+	limit, offset = normalizeConversationPage(limit, offset)
+	list := &ConversationList{Limit: limit, Offset: offset, Conversations: make([]ConversationSummary, 0)}
+
+	if c.store != nil {
+		summaries, total, err := c.store.ListConversations(ctx, userID, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list conversations: %w", err)
+		}
+		list.Conversations = summaries
+		list.Total = total
+		return list, nil
+	}
+
+	// Without a store only the cached conversation is known
+	c.mu.RLock()
+	conversation, exists := c.conversations[userID]
+	c.mu.RUnlock()
+	if exists {
+		list.Total = 1
+		if offset == 0 {
+			list.Conversations = append(list.Conversations, summarizeConversation(conversation))
+		}
+	}
+	return list, nil
+}
+
+// ListMessages returns a page of messages of one of the user's conversations, oldest first
+func (c *ConversationalAI) ListMessages(ctx context.Context, userID, conversationID uuid.UUID, limit, offset int) (*ConversationMessageList, error) {
+	limit, offset = normalizeConversationPage(limit, offset)
+	list := &ConversationMessageList{ConversationID: conversationID, Limit: limit, Offset: offset}
+
+	if c.store != nil {
+		messages, total, err := c.store.ListMessages(ctx, userID, conversationID, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		list.Messages = messages
+		list.Total = total
+		return list, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	conversation, exists := c.conversations[userID]
+	if !exists || conversation.ID != conversationID {
+		return nil, ErrConversationNotFound
+	}
+	list.Total = len(conversation.Messages)
+	list.Messages = make([]ConversationMessage, 0)
+	if offset < len(conversation.Messages) {
+		end := offset + limit
+		if end > len(conversation.Messages) {
+			end = len(conversation.Messages)
+		}
+		list.Messages = append(list.Messages, conversation.Messages[offset:end]...)
+	}
+	return list, nil
+}
+
+// DeleteUserConversations erases the user's conversations from the cache and the store, for
+// right-to-erasure requests. It returns the number of conversations deleted.
+func (c *ConversationalAI) DeleteUserConversations(ctx context.Context, userID uuid.UUID) (int, error) {
+	c.mu.Lock()
+	_, cached := c.conversations[userID]
+	delete(c.conversations, userID)
+	c.mu.Unlock()
+
+	if c.store != nil {
+		return c.store.DeleteUserConversations(ctx, userID)
+	}
+	if cached {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func normalizeConversationPage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func summarizeConversation(conversation *Conversation) ConversationSummary {
+	summary := ConversationSummary{
+		ID:           conversation.ID,
+		MessageCount: len(conversation.Messages),
+		StartedAt:    conversation.StartedAt,
+		LastActive:   conversation.LastActive,
+		Usage:        conversation.Usage,
+	}
+	var firstUserMessage string
+	for _, message := range conversation.Messages {
+		if message.Role == RoleUser {
+			firstUserMessage = message.Content
+			break
+		}
+	}
+	summary.Title = conversationTitle(firstUserMessage)
+	return summary
+}
+
+// conversationTitle derives a display title from the first user message
+func conversationTitle(firstUserMessage string) string {
+	title := strings.TrimSpace(firstUserMessage)
+	if title == "" {
+		return "New conversation"
+	}
+	if runes := []rune(title); len(runes) > 60 {
+		title = string(runes[:60]) + "…"
+	}
+	return title
+}
+
+// generateResponse generates an AI response based on the conversation context. With onDelta
+// set, replies from the AI providers are streamed to it as they are produced.
+func (c *ConversationalAI) generateResponse(ctx context.Context, conversation *Conversation, message string, opts ChatOptions, onDelta func(delta string) error) (*ConversationalResponse, error) {
+	c.summarizeOlderMessages(ctx, conversation)
+
+	// Requests chat can act on are carried out instead of talked about
+	if action, ok := c.classifyChatIntent(message); ok {
+		response := &ConversationalResponse{Confidence: 0.9, Metadata: map[string]interface{}{"provider": "actions"}}
+		c.performChatAction(ctx, conversation.UserID, action, response)
+		if opts.Debug {
+			response.Debug = c.assembleContext(ctx, conversation, message, action.name)
+		}
+		return response, nil
+	}
+
 	// Analyze the message intent and context
 	intent := c.analyzeIntent(message)
 
@@ -252,7 +575,9 @@ func (c *ConversationalAI) generateResponse(ctx context.Context, conversation *C
 		Metadata:    make(map[string]interface{}),
 	}
 
-	// Generate response based on intent
+	// Generate response based on intent. Free-form questions, and questions about the user's
+	// portfolio and risk, go to the AI providers when a completion router is configured.
+	freeform := false
 	switch intent {
 	case "market_analysis":
 		response.Content = c.generateMarketAnalysis(ctx, conversation, marketContext)
@@ -260,21 +585,83 @@ func (c *ConversationalAI) generateResponse(ctx context.Context, conversation *C
 	case "portfolio_advice":
 		response.Content = c.generatePortfolioAdvice(ctx, conversation)
 		response.Suggestions = c.generatePortfolioSuggestions(ctx, conversation)
+		freeform = true
 	case "risk_assessment":
 		response.Content = c.generateRiskAssessment(ctx, conversation, message)
 		response.Warnings = c.generateRiskWarnings(ctx, conversation)
+		freeform = true
 	case "yield_opportunities":
 		response.Content = c.generateYieldAnalysis(ctx, conversation)
 		response.Suggestions = c.generateYieldSuggestions(ctx)
 	case "general_question":
 		response.Content = c.generateGeneralResponse(ctx, conversation, message)
+		freeform = true
 	default:
 		response.Content = c.generateDefaultResponse(ctx, conversation, message)
+		freeform = true
+	}
+
+	response.Metadata["provider"] = "builtin"
+	if freeform && c.completions != nil {
+		assembled := c.assembleContext(ctx, conversation, message, intent)
+		sent, err := c.completeWithProviders(ctx, conversation, assembled, response, onDelta)
+		if err != nil {
+			return nil, err
+		}
+		assembled.SentToModel = sent
+		if opts.Debug {
+			response.Debug = assembled
+		}
+	} else if opts.Debug {
+		response.Debug = c.assembleContext(ctx, conversation, message, intent)
 	}
 
 	return response, nil
 }
 
+// completeWithProviders replaces a canned reply with a completion from the chat route and
+// reports whether it did. The canned reply is kept when every provider fails, unless part of
+// the completion was already streamed to onDelta; that failure is returned.
+func (c *ConversationalAI) completeWithProviders(ctx context.Context, conversation *Conversation, assembled *ChatContextDebug, response *ConversationalResponse, onDelta func(delta string) error) (bool, error) {
+	req := CompletionRequest{
+		System:      assembled.System,
+		Messages:    assembled.Messages,
+		MaxTokens:   800,
+		Temperature: 0.7,
+	}
+
+	var result *CompletionResult
+	var err error
+	if onDelta != nil {
+		streamed := false
+		result, err = c.completions.Stream(ctx, "chat", req, func(delta string) error {
+			streamed = true
+			return onDelta(delta)
+		})
+		if err != nil && streamed {
+			return false, err
+		}
+	} else {
+		result, err = c.completions.Complete(ctx, "chat", req)
+	}
+	if err != nil {
+		c.logger.Warn(ctx, "AI providers failed, using built-in reply", map[string]interface{}{
+			"conversation_id": conversation.ID.String(),
+			"error":           err.Error(),
+		})
+		response.Metadata["provider_error"] = err.Error()
+		return false, nil
+	}
+
+	c.recordUsage(ctx, conversation, assembled.EstimatedTokens, estimateTokens(result.Content))
+	response.Content = result.Content
+	response.Metadata["provider"] = result.Provider
+	response.Metadata["provider_model"] = result.Model
+	response.Metadata["provider_attempts"] = result.Attempts
+	response.Metadata["provider_failed_over"] = result.FailedOver
+	return true, nil
+}
+
 // generateMarketAnalysis generates market analysis content
 func (c *ConversationalAI) generateMarketAnalysis(ctx context.Context, conversation *Conversation, marketContext *MarketContext) string {
 	if marketContext == nil {
@@ -445,7 +832,7 @@ func (c *ConversationalAI) generateWelcomeMessage(ctx context.Context, conversat
 What would you like to explore today?`
 }
 
-func (c *ConversationalAI) addMessage(conversation *Conversation, role MessageRole, content string) {
+func (c *ConversationalAI) addMessage(ctx context.Context, conversation *Conversation, role MessageRole, content string) {
 	message := ConversationMessage{
 		ID:        uuid.New(),
 		Role:      role,
@@ -453,12 +840,22 @@ func (c *ConversationalAI) addMessage(conversation *Conversation, role MessageRo
 		Timestamp: time.Now(),
 	}
 
+	c.mu.Lock()
 	conversation.Messages = append(conversation.Messages, message)
 
-	// Keep conversation history within limits
+	// Keep conversation history within limits; the store keeps the full history
 	if len(conversation.Messages) > c.config.MaxConversationHistory {
 		conversation.Messages = conversation.Messages[1:]
 	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.AppendMessage(ctx, conversation.ID, message); err != nil {
+			c.logger.Error(ctx, "Failed to persist conversation message", err, map[string]interface{}{
+				"conversation_id": conversation.ID.String(),
+			})
+		}
+	}
 }
 
 func (c *ConversationalAI) updateContext(ctx context.Context, conversation *Conversation, message string) {
@@ -515,3 +912,31 @@ func (c *ConversationalAI) analyzePortfolioPerformance(portfolio *web3.Portfolio
 func (c *ConversationalAI) generatePortfolioRecommendations(portfolio *web3.Portfolio) string {
 	return "Consider diversifying across different asset classes and maintaining appropriate risk management."
 }
+
+// splitStreamTokens splits content into word-level deltas, keeping the trailing
+// whitespace with each word so that concatenating the deltas restores the content
+func splitStreamTokens(content string) []string {
+	tokens := make([]string, 0)
+	start := 0
+	inSpace := false
+	for i, r := range content {
+		isSpace := r == ' ' || r == '\n' || r == '\t'
+		if inSpace && !isSpace {
+			tokens = append(tokens, content[start:i])
+			start = i
+		}
+		inSpace = isSpace
+	}
+	if start < len(content) {
+		tokens = append(tokens, content[start:])
+	}
+	return tokens
+}
+
+// estimateTokens approximates the token count of text (roughly 4 characters per token)
+func estimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
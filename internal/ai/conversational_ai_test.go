@@ -0,0 +1,326 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationalAI_ProcessMessageStream(t *testing.T) {
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	userID := uuid.New()
+
+	chunks, err := conversationalAI.ProcessMessageStream(context.Background(), userID, "How is the market trend today?")
+	require.NoError(t, err)
+
+	var content strings.Builder
+	var final *ConversationalStreamChunk
+	for chunk := range chunks {
+		require.Empty(t, chunk.Error)
+		if chunk.Done {
+			final = chunk
+			continue
+		}
+		content.WriteString(chunk.Delta)
+	}
+
+	require.NotNil(t, final, "stream should end with a done event")
+	assert.NotEqual(t, uuid.Nil, final.ConversationID)
+	require.NotNil(t, final.Response)
+	assert.Equal(t, final.Response.Content, content.String())
+	require.NotNil(t, final.Usage)
+	assert.Equal(t, final.Usage.PromptTokens+final.Usage.CompletionTokens, final.Usage.TotalTokens)
+
+	// The streamed reply should be recorded in the conversation history
+	conversation := conversationalAI.conversations[userID]
+	last := conversation.Messages[len(conversation.Messages)-1]
+	assert.Equal(t, RoleAssistant, last.Role)
+	assert.Equal(t, content.String(), last.Content)
+}
+
+func TestConversationalAI_ProcessMessageStreamCancelled(t *testing.T) {
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	userID := uuid.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks, err := conversationalAI.ProcessMessageStream(ctx, userID, "Tell me about my portfolio holdings")
+	require.NoError(t, err)
+
+	// Read a single delta then disconnect
+	<-chunks
+	cancel()
+
+	for chunk := range chunks {
+		assert.False(t, chunk.Done, "cancelled stream must not report completion")
+	}
+}
+
+// gatedStreamProvider streams its first delta, then waits for the test before the rest
+type gatedStreamProvider struct {
+	release chan struct{}
+	err     error
+}
+
+func (p *gatedStreamProvider) Name() string  { return "gated" }
+func (p *gatedStreamProvider) Model() string { return "gated-model" }
+
+func (p *gatedStreamProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	return "", errors.New("not streamed")
+}
+
+func (p *gatedStreamProvider) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (string, error) {
+	if err := onDelta("Diversify "); err != nil {
+		return "", err
+	}
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	if err := onDelta("gradually."); err != nil {
+		return "", err
+	}
+	return "Diversify gradually.", nil
+}
+
+func TestConversationalAI_ProcessMessageStreamFromProvider(t *testing.T) {
+	t.Run("DeltasArriveAsStreamed", func(t *testing.T) {
+		provider := &gatedStreamProvider{release: make(chan struct{})}
+		conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+		conversationalAI.SetCompletionRouter(newTestProviderRouter(provider))
+		userID := uuid.New()
+
+		chunks, err := conversationalAI.ProcessMessageStream(context.Background(), userID, "Tell me about my portfolio holdings")
+		require.NoError(t, err)
+
+		// The first delta is passed on while the provider is still producing the reply
+		select {
+		case chunk := <-chunks:
+			assert.Equal(t, "Diversify ", chunk.Delta)
+		case <-time.After(time.Second):
+			t.Fatal("the first delta was held back until the reply was complete")
+		}
+		close(provider.release)
+
+		var rest []string
+		var final *ConversationalStreamChunk
+		for chunk := range chunks {
+			require.Empty(t, chunk.Error)
+			if chunk.Done {
+				final = chunk
+				continue
+			}
+			rest = append(rest, chunk.Delta)
+		}
+		assert.Equal(t, []string{"gradually."}, rest)
+		require.NotNil(t, final)
+		assert.Equal(t, "Diversify gradually.", final.Response.Content)
+		assert.Equal(t, "gated", final.Response.Metadata["provider"])
+
+		conversation := conversationalAI.conversations[userID]
+		assert.Equal(t, "Diversify gradually.", conversation.Messages[len(conversation.Messages)-1].Content)
+	})
+
+	t.Run("FailureMidStream", func(t *testing.T) {
+		provider := &gatedStreamProvider{release: make(chan struct{}), err: errors.New("connection reset")}
+		close(provider.release)
+		conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+		conversationalAI.SetCompletionRouter(newTestProviderRouter(provider))
+		userID := uuid.New()
+
+		chunks, err := conversationalAI.ProcessMessageStream(context.Background(), userID, "Tell me about my portfolio holdings")
+		require.NoError(t, err)
+
+		var received []*ConversationalStreamChunk
+		for chunk := range chunks {
+			received = append(received, chunk)
+		}
+		require.Len(t, received, 2)
+		assert.Equal(t, "Diversify ", received[0].Delta)
+		assert.Contains(t, received[1].Error, "connection reset", "a partly sent reply is not swapped for the built-in one")
+		assert.False(t, received[1].Done)
+
+		conversation := conversationalAI.conversations[userID]
+		assert.Equal(t, RoleUser, conversation.Messages[len(conversation.Messages)-1].Role, "the broken reply is not recorded")
+	})
+}
+
+func TestSplitStreamTokens(t *testing.T) {
+	content := "Hello  world\nsecond line "
+	tokens := splitStreamTokens(content)
+	assert.Equal(t, []string{"Hello  ", "world\n", "second ", "line "}, tokens)
+	assert.Equal(t, content, strings.Join(tokens, ""))
+}
+
+// memoryConversationStore is a ConversationStore backed by maps, standing in for Postgres
+type memoryConversationStore struct {
+	conversations map[uuid.UUID]*Conversation
+	messages      map[uuid.UUID][]ConversationMessage
+}
+
+func newMemoryConversationStore() *memoryConversationStore {
+	return &memoryConversationStore{
+		conversations: make(map[uuid.UUID]*Conversation),
+		messages:      make(map[uuid.UUID][]ConversationMessage),
+	}
+}
+
+func (s *memoryConversationStore) SaveConversation(ctx context.Context, conversation *Conversation) error {
+	s.conversations[conversation.ID] = &Conversation{ID: conversation.ID, UserID: conversation.UserID, StartedAt: conversation.StartedAt, LastActive: conversation.LastActive}
+	return nil
+}
+
+func (s *memoryConversationStore) AppendMessage(ctx context.Context, conversationID uuid.UUID, message ConversationMessage) error {
+	s.messages[conversationID] = append(s.messages[conversationID], message)
+	s.conversations[conversationID].LastActive = message.Timestamp
+	return nil
+}
+
+func (s *memoryConversationStore) UpdateSummary(ctx context.Context, conversationID uuid.UUID, summary string, summarizedUntil time.Time) error {
+	s.conversations[conversationID].Summary = summary
+	s.conversations[conversationID].SummarizedUntil = &summarizedUntil
+	return nil
+}
+
+func (s *memoryConversationStore) AddUsage(ctx context.Context, conversationID uuid.UUID, usage ConversationUsage) error {
+	s.conversations[conversationID].Usage.add(usage)
+	return nil
+}
+
+func (s *memoryConversationStore) LatestConversation(ctx context.Context, userID uuid.UUID, maxMessages int) (*Conversation, error) {
+	var latest *Conversation
+	for _, conversation := range s.conversations {
+		if conversation.UserID == userID && (latest == nil || conversation.LastActive.After(latest.LastActive)) {
+			latest = conversation
+		}
+	}
+	if latest == nil {
+		return nil, ErrConversationNotFound
+	}
+	messages := s.messages[latest.ID]
+	if len(messages) > maxMessages {
+		messages = messages[len(messages)-maxMessages:]
+	}
+	return &Conversation{ID: latest.ID, UserID: userID, Messages: append([]ConversationMessage(nil), messages...), StartedAt: latest.StartedAt, LastActive: latest.LastActive,
+		Summary: latest.Summary, SummarizedUntil: latest.SummarizedUntil, Usage: latest.Usage}, nil
+}
+
+func (s *memoryConversationStore) ListConversations(ctx context.Context, userID uuid.UUID, limit, offset int) ([]ConversationSummary, int, error) {
+	summaries := make([]ConversationSummary, 0)
+	for _, conversation := range s.conversations {
+		if conversation.UserID == userID {
+			summaries = append(summaries, ConversationSummary{ID: conversation.ID, MessageCount: len(s.messages[conversation.ID]), Usage: conversation.Usage})
+		}
+	}
+	return summaries, len(summaries), nil
+}
+
+func (s *memoryConversationStore) ListMessages(ctx context.Context, userID, conversationID uuid.UUID, limit, offset int) ([]ConversationMessage, int, error) {
+	conversation, exists := s.conversations[conversationID]
+	if !exists || conversation.UserID != userID {
+		return nil, 0, ErrConversationNotFound
+	}
+	return s.messages[conversationID], len(s.messages[conversationID]), nil
+}
+
+func (s *memoryConversationStore) DeleteUserConversations(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleted := 0
+	for id, conversation := range s.conversations {
+		if conversation.UserID == userID {
+			delete(s.conversations, id)
+			delete(s.messages, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestConversationalAI_PersistsAcrossRestart(t *testing.T) {
+	store := newMemoryConversationStore()
+	userID := uuid.New()
+
+	first := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	first.SetConversationStore(store)
+	_, err := first.ProcessMessage(context.Background(), userID, "What is the market trend?")
+	require.NoError(t, err)
+
+	// A fresh instance has an empty cache and must resume from the store
+	restarted := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	restarted.SetConversationStore(store)
+	_, err = restarted.ProcessMessage(context.Background(), userID, "And my portfolio?")
+	require.NoError(t, err)
+
+	list, err := restarted.ListConversations(context.Background(), userID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Total)
+	assert.Equal(t, 20, list.Limit)
+
+	messages, err := restarted.ListMessages(context.Background(), userID, list.Conversations[0].ID, 0, 0)
+	require.NoError(t, err)
+	// welcome + two exchanges
+	assert.Equal(t, 5, messages.Total)
+	assert.Equal(t, "What is the market trend?", messages.Messages[1].Content)
+	assert.Equal(t, "And my portfolio?", messages.Messages[3].Content)
+
+	_, err = restarted.ListMessages(context.Background(), uuid.New(), list.Conversations[0].ID, 0, 0)
+	assert.ErrorIs(t, err, ErrConversationNotFound)
+}
+
+func TestConversationalAI_ListWithoutStore(t *testing.T) {
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	userID := uuid.New()
+
+	_, err := conversationalAI.ProcessMessage(context.Background(), userID, "Hello there")
+	require.NoError(t, err)
+
+	list, err := conversationalAI.ListConversations(context.Background(), userID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Conversations, 1)
+	assert.Equal(t, "Hello there", list.Conversations[0].Title)
+
+	messages, err := conversationalAI.ListMessages(context.Background(), userID, list.Conversations[0].ID, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, messages.Total)
+	assert.Len(t, messages.Messages, 2)
+	assert.Equal(t, RoleUser, messages.Messages[0].Role)
+}
+
+func TestConversationalAI_DeleteUserConversations(t *testing.T) {
+	store := newMemoryConversationStore()
+	conversationalAI := NewConversationalAI(createTestLogger(), nil, nil, nil)
+	conversationalAI.SetConversationStore(store)
+	userID, otherID := uuid.New(), uuid.New()
+
+	for _, id := range []uuid.UUID{userID, otherID} {
+		_, err := conversationalAI.ProcessMessage(context.Background(), id, "What is the market trend?")
+		require.NoError(t, err)
+	}
+
+	deleted, err := conversationalAI.DeleteUserConversations(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	list, err := conversationalAI.ListConversations(context.Background(), userID, 0, 0)
+	require.NoError(t, err)
+	assert.Zero(t, list.Total)
+	list, err = conversationalAI.ListConversations(context.Background(), otherID, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+
+	// A new message starts a fresh conversation instead of reviving the cached one
+	_, err = conversationalAI.ProcessMessage(context.Background(), userID, "Hello again")
+	require.NoError(t, err)
+	list, err = conversationalAI.ListConversations(context.Background(), userID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Total)
+	assert.Equal(t, 3, list.Conversations[0].MessageCount)
+}
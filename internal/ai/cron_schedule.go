@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCronExpression is returned for cron expressions that cannot be parsed
+var ErrInvalidCronExpression = errors.New("invalid cron expression")
+
+// cronSearchLimit bounds the search for the next run of expressions that rarely or never match, e.g. "0 0 31 2 *"
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// CronSchedule is a parsed five-field cron expression (minute hour day-of-month month
+// day-of-week), evaluated in UTC. Fields accept *, lists, ranges and steps, e.g. "*/15",
+// "1-5" or "0,30". Day-of-week 0 and 7 are Sunday.
+type CronSchedule struct {
+	expression string
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// Standard cron matches either day field when both are restricted
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// ParseCronSchedule parses a cron expression or one of the @hourly, @daily, @weekly,
+// @monthly and @yearly macros
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	spec := expression
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q must have 5 fields", ErrInvalidCronExpression, expression)
+	}
+
+	schedule := &CronSchedule{expression: expression}
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+	schedule.anyDayOfMonth = fields[2] == "*"
+	schedule.anyDayOfWeek = fields[4] == "*"
+
+	return schedule, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (c *CronSchedule) String() string {
+	return c.expression
+}
+
+// Next returns the first run strictly after t, or the zero time when the expression
+// matches no date within the next five years
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := c.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDayOfMonth && c.anyDayOfWeek:
+		return true
+	case c.anyDayOfMonth:
+		return dayOfWeek
+	case c.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
+
+// parseCronField parses one field into a bitset of the values it matches
+func parseCronField(field string, low, high int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: bad step in %q", ErrInvalidCronExpression, part)
+			}
+		}
+
+		start, end := low, high
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(bounds[0])
+			end, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("%w: bad range %q", ErrInvalidCronExpression, rangePart)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%w: bad value %q", ErrInvalidCronExpression, rangePart)
+			}
+			start, end = value, value
+			if strings.Contains(part, "/") {
+				end = high
+			}
+		}
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("%w: %q is outside %d-%d", ErrInvalidCronExpression, part, low, high)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
@@ -145,6 +145,39 @@ func (g *CryptoAnalysisReportGenerator) GenerateStructuredReport(report *CoinAna
 	}
 	builder.WriteString("\n")
 
+	// Market Structure
+	builder.WriteString("## MARKET STRUCTURE\n")
+	if report.MarketStructure != nil {
+		fundingSign := ""
+		if report.MarketStructure.FundingRate.IsPositive() {
+			fundingSign = "+"
+		}
+		builder.WriteString(fmt.Sprintf("- Funding Rate: %s%s%% per period\n",
+			fundingSign,
+			report.MarketStructure.FundingRate.StringFixed(4)))
+
+		oiSign := ""
+		if report.MarketStructure.OpenInterestChange.IsPositive() {
+			oiSign = "+"
+		}
+		builder.WriteString(fmt.Sprintf("- Open Interest: %s (%s%s%% over %s)\n",
+			g.formatLargeNumber(report.MarketStructure.OpenInterest),
+			oiSign,
+			report.MarketStructure.OpenInterestChange.StringFixed(2),
+			report.MarketStructure.Window))
+
+		builder.WriteString(fmt.Sprintf("- Positioning: %s\n",
+			g.capitalizeFirst(strings.ReplaceAll(report.MarketStructure.Positioning, "_", " "))))
+
+		if len(report.MarketStructure.Exchanges) > 0 {
+			builder.WriteString(fmt.Sprintf("- Exchanges: %s\n",
+				strings.Join(report.MarketStructure.Exchanges, ", ")))
+		}
+	} else {
+		builder.WriteString("- Perpetual futures data unavailable\n")
+	}
+	builder.WriteString("\n")
+
 	// Fundamental Insights
 	builder.WriteString("## FUNDAMENTAL INSIGHTS\n")
 	if report.FundamentalData != nil {
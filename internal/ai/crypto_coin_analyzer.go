@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -22,9 +23,19 @@ type CryptoCoinAnalyzer struct {
 	reportGenerator *CryptoAnalysisReportGenerator
 	dataCache       map[string]*CoinAnalysisCache
 	lastUpdated     time.Time
-	currentReport   *CoinAnalysisReport // Track current report for data source tracking
+	batchWorkers    int
+	searchCache     SearchResultCache
+	newsProviders   *NewsProviderRegistry
+	cacheMu         sync.RWMutex
+
+	// derivatives supplies perpetual futures funding and open interest; reports omit market
+	// structure without it
+	derivatives DerivativesSource
 }
 
+// coinReportKey carries the report under construction so concurrent analyses track their own data sources
+type coinReportKey struct{}
+
 // CoinAnalysisCache represents cached analysis data
 type CoinAnalysisCache struct {
 	Data        *CoinAnalysisReport
@@ -41,6 +52,7 @@ type CoinAnalysisReport struct {
 	MarketSentiment *MarketSentimentAnalysis `json:"market_sentiment"`
 	TechnicalData   *TechnicalIndicators     `json:"technical_data"`
 	FundamentalData *FundamentalAnalysis     `json:"fundamental_data"`
+	MarketStructure *MarketStructure         `json:"market_structure,omitempty"`
 	Summary         *AnalysisSummary         `json:"summary"`
 	Sources         []DataSource             `json:"sources"`
 }
@@ -67,6 +79,8 @@ type NewsItem struct {
 	PublishedAt time.Time `json:"published_at"`
 	Impact      string    `json:"impact"` // bullish, bearish, neutral
 	Relevance   float64   `json:"relevance"`
+	Provider    string    `json:"provider"` // news source the item was retrieved from
+	RetrievedAt time.Time `json:"retrieved_at"`
 }
 
 // MarketSentimentAnalysis represents sentiment analysis
@@ -129,6 +143,16 @@ type VolumeProfile struct {
 	VolumeIndicator string          `json:"volume_indicator"`
 }
 
+// MarketStructure represents perpetual futures positioning
+type MarketStructure struct {
+	FundingRate        decimal.Decimal `json:"funding_rate"`         // percent per funding period
+	OpenInterest       decimal.Decimal `json:"open_interest"`        // in the base asset
+	OpenInterestChange decimal.Decimal `json:"open_interest_change"` // percent over Window
+	Window             string          `json:"window"`
+	Positioning        string          `json:"positioning"` // crowded_long, crowded_short, balanced
+	Exchanges          []string        `json:"exchanges"`
+}
+
 // FundamentalAnalysis represents fundamental analysis
 type FundamentalAnalysis struct {
 	ProjectStatus       string               `json:"project_status"`
@@ -203,6 +227,8 @@ type DataSource struct {
 	Type        string    `json:"type"`
 	Reliability string    `json:"reliability"`
 	LastChecked time.Time `json:"last_checked"`
+	Skipped     bool      `json:"skipped,omitempty"`
+	SkipReason  string    `json:"skip_reason,omitempty"`
 }
 
 // WebSearchResult represents a web search result
@@ -219,7 +245,7 @@ func NewCryptoCoinAnalyzer(logger *observability.Logger) *CryptoCoinAnalyzer {
 	webSearch := NewWebSearchService(logger, "")
 	reportGenerator := NewCryptoAnalysisReportGenerator(logger)
 
-	return &CryptoCoinAnalyzer{
+	analyzer := &CryptoCoinAnalyzer{
 		logger: logger,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
@@ -228,7 +254,12 @@ func NewCryptoCoinAnalyzer(logger *observability.Logger) *CryptoCoinAnalyzer {
 		reportGenerator: reportGenerator,
 		dataCache:       make(map[string]*CoinAnalysisCache),
 		lastUpdated:     time.Time{},
+		batchWorkers:    defaultBatchWorkers,
+		newsProviders:   NewNewsProviderRegistry(defaultNewsProviderTimeout),
 	}
+	analyzer.newsProviders.Register(&webSearchNewsProvider{analyzer: analyzer})
+
+	return analyzer
 }
 
 // AnalyzeCoin performs comprehensive analysis of a cryptocurrency
@@ -255,8 +286,8 @@ func (c *CryptoCoinAnalyzer) AnalyzeCoin(ctx context.Context, symbol string) (*C
 		Sources:   make([]DataSource, 0),
 	}
 
-	// Set current report for data source tracking
-	c.currentReport = report
+	// Track data sources on this report
+	ctx = context.WithValue(ctx, coinReportKey{}, report)
 
 	// Gather data from multiple sources (5 tools as per requirements)
 	var err error
@@ -304,6 +335,16 @@ func (c *CryptoCoinAnalyzer) AnalyzeCoin(ctx context.Context, symbol string) (*C
 		report.FundamentalData = c.getDefaultFundamentalData()
 	}
 
+	// 6. Get perpetual futures market structure
+	if c.derivatives != nil {
+		report.MarketStructure, err = c.getMarketStructure(ctx, symbol)
+		if err != nil {
+			c.logger.Warn(ctx, "Failed to get market structure", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	// Generate summary
 	report.Summary = c.generateAnalysisSummary(report)
 
@@ -382,6 +423,20 @@ func (c *CryptoCoinAnalyzer) GenerateMarkdownReport(report *CoinAnalysisReport)
 	}
 	builder.WriteString("\n")
 
+	// Market Structure
+	if report.MarketStructure != nil {
+		builder.WriteString("## MARKET STRUCTURE\n")
+		builder.WriteString(fmt.Sprintf("- Funding Rate: %s%%\n",
+			report.MarketStructure.FundingRate.StringFixed(4)))
+		builder.WriteString(fmt.Sprintf("- Open Interest: %s (%s%% over %s)\n",
+			c.formatLargeNumber(report.MarketStructure.OpenInterest),
+			report.MarketStructure.OpenInterestChange.StringFixed(2),
+			report.MarketStructure.Window))
+		builder.WriteString(fmt.Sprintf("- Positioning: %s\n",
+			c.capitalizeFirst(strings.ReplaceAll(report.MarketStructure.Positioning, "_", " "))))
+		builder.WriteString("\n")
+	}
+
 	// Fundamental Insights
 	builder.WriteString("## FUNDAMENTAL INSIGHTS\n")
 	if report.FundamentalData != nil {
@@ -429,6 +484,9 @@ func (c *CryptoCoinAnalyzer) GenerateMarkdownReport(report *CoinAnalysisReport)
 
 // getCachedAnalysis retrieves cached analysis if available and not expired
 func (c *CryptoCoinAnalyzer) getCachedAnalysis(symbol string) *CoinAnalysisCache {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
 	if cached, exists := c.dataCache[symbol]; exists {
 		if time.Now().Before(cached.ExpiresAt) {
 			return cached
@@ -441,6 +499,9 @@ func (c *CryptoCoinAnalyzer) getCachedAnalysis(symbol string) *CoinAnalysisCache
 
 // cacheAnalysis stores analysis in cache
 func (c *CryptoCoinAnalyzer) cacheAnalysis(symbol string, report *CoinAnalysisReport) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
 	c.dataCache[symbol] = &CoinAnalysisCache{
 		Data:        report,
 		LastUpdated: time.Now(),
@@ -470,33 +531,11 @@ func (c *CryptoCoinAnalyzer) getCurrentMarketData(ctx context.Context, symbol st
 	}
 
 	// Add data source
-	c.addDataSource("Web Search - Market Data", "https://www.google.com/search", "market_data", "high")
+	c.addDataSource(ctx, "Web Search - Market Data", "https://www.google.com/search", "market_data", "high")
 
 	return marketData, nil
 }
 
-// getRecentNews fetches recent news about the cryptocurrency
-func (c *CryptoCoinAnalyzer) getRecentNews(ctx context.Context, symbol string) ([]NewsItem, error) {
-	// Search for recent news
-	query := fmt.Sprintf("%s cryptocurrency news last 7 days", symbol)
-	results, err := c.performWebSearch(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search for news: %w", err)
-	}
-
-	var newsItems []NewsItem
-	for _, result := range results {
-		if newsItem := c.parseNewsItem(result, symbol); newsItem != nil {
-			newsItems = append(newsItems, *newsItem)
-		}
-	}
-
-	// Add data source
-	c.addDataSource("Web Search - News", "https://www.google.com/search", "news", "medium")
-
-	return newsItems, nil
-}
-
 // analyzeMarketSentiment analyzes market sentiment
 func (c *CryptoCoinAnalyzer) analyzeMarketSentiment(ctx context.Context, symbol string) (*MarketSentimentAnalysis, error) {
 	// Search for sentiment analysis
@@ -518,7 +557,7 @@ func (c *CryptoCoinAnalyzer) analyzeMarketSentiment(ctx context.Context, symbol
 	c.analyzeSentimentFromResults(results, sentiment)
 
 	// Add data source
-	c.addDataSource("Web Search - Sentiment", "https://www.google.com/search", "sentiment", "medium")
+	c.addDataSource(ctx, "Web Search - Sentiment", "https://www.google.com/search", "sentiment", "medium")
 
 	return sentiment, nil
 }
@@ -544,11 +583,30 @@ func (c *CryptoCoinAnalyzer) getTechnicalIndicators(ctx context.Context, symbol
 	c.parseTechnicalData(results, technical)
 
 	// Add data source
-	c.addDataSource("Web Search - Technical Analysis", "https://www.google.com/search", "technical", "medium")
+	c.addDataSource(ctx, "Web Search - Technical Analysis", "https://www.google.com/search", "technical", "medium")
 
 	return technical, nil
 }
 
+// getMarketStructure summarizes perpetual futures funding and open interest
+func (c *CryptoCoinAnalyzer) getMarketStructure(ctx context.Context, symbol string) (*MarketStructure, error) {
+	summary, err := c.derivatives.GetDerivativesSummary(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get derivatives summary: %w", err)
+	}
+
+	c.addDataSource(ctx, "Perpetual Futures - Funding & Open Interest", "", "derivatives", "high")
+
+	return &MarketStructure{
+		FundingRate:        decimal.NewFromFloat(summary.FundingRate * 100),
+		OpenInterest:       decimal.NewFromFloat(summary.OpenInterest),
+		OpenInterestChange: decimal.NewFromFloat(summary.OpenInterestChange * 100),
+		Window:             strings.TrimSuffix(strings.TrimSuffix(summary.Window.String(), "0s"), "0m"), // 24h, not 24h0m0s
+		Positioning:        summary.Positioning(),
+		Exchanges:          summary.Exchanges,
+	}, nil
+}
+
 // getFundamentalAnalysis fetches fundamental analysis data
 func (c *CryptoCoinAnalyzer) getFundamentalAnalysis(ctx context.Context, symbol string) (*FundamentalAnalysis, error) {
 	// Search for fundamental analysis
@@ -576,7 +634,7 @@ func (c *CryptoCoinAnalyzer) getFundamentalAnalysis(ctx context.Context, symbol
 	c.parseFundamentalData(results, fundamental)
 
 	// Add data source
-	c.addDataSource("Web Search - Fundamental Analysis", "https://www.google.com/search", "fundamental", "medium")
+	c.addDataSource(ctx, "Web Search - Fundamental Analysis", "https://www.google.com/search", "fundamental", "medium")
 
 	return fundamental, nil
 }
@@ -713,6 +771,20 @@ func (c *CryptoCoinAnalyzer) generateAnalysisSummary(report *CoinAnalysisReport)
 		}
 	}
 
+	// Analyze derivatives positioning
+	if report.MarketStructure != nil {
+		switch report.MarketStructure.Positioning {
+		case PositioningCrowdedLong:
+			summary.RiskFactors = append(summary.RiskFactors, fmt.Sprintf(
+				"Crowded long positioning: %s%% funding with open interest up %s%%, long squeeze risk",
+				report.MarketStructure.FundingRate.StringFixed(4), report.MarketStructure.OpenInterestChange.StringFixed(1)))
+		case PositioningCrowdedShort:
+			summary.Opportunities = append(summary.Opportunities, fmt.Sprintf(
+				"Crowded short positioning: %s%% funding with open interest up %s%%, short squeeze potential",
+				report.MarketStructure.FundingRate.StringFixed(4), report.MarketStructure.OpenInterestChange.StringFixed(1)))
+		}
+	}
+
 	// Determine overall outlook
 	if bullishFactors > bearishFactors {
 		summary.OverallOutlook = "bullish"
@@ -760,12 +832,22 @@ func (c *CryptoCoinAnalyzer) performWebSearch(ctx context.Context, query string)
 		req.TimeFilter = "week"
 	}
 
-	response, err := c.webSearch.Search(ctx, req)
-	if err != nil {
-		return nil, err
+	search := func() ([]WebSearchResult, error) {
+		return c.cachedSearch(ctx, query, func() ([]WebSearchResult, error) {
+			response, err := c.webSearch.Search(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return response.Results, nil
+		})
 	}
 
-	return response.Results, nil
+	// Within a batch, identical lookups are fetched once and shared; the search cache shares
+	// them across batches and instances
+	if memo, ok := ctx.Value(searchMemoKey{}).(*searchMemo); ok {
+		return memo.do(query, search)
+	}
+	return search()
 }
 
 // extractPriceData extracts price data from search results
@@ -829,6 +911,11 @@ func (c *CryptoCoinAnalyzer) parseNewsItem(result WebSearchResult, symbol string
 
 // determineNewsImpact determines the impact of news based on content
 func (c *CryptoCoinAnalyzer) determineNewsImpact(content string) string {
+	return determineContentImpact(content)
+}
+
+// determineContentImpact classifies text as bullish, bearish or neutral by keyword counts
+func determineContentImpact(content string) string {
 	content = strings.ToLower(content)
 
 	bullishWords := []string{"bullish", "positive", "growth", "adoption", "partnership", "upgrade", "rally"}
@@ -860,9 +947,9 @@ func (c *CryptoCoinAnalyzer) determineNewsImpact(content string) string {
 
 // Utility and helper methods
 
-// addDataSource adds a data source to the current report
-func (c *CryptoCoinAnalyzer) addDataSource(name, url, dataType, reliability string) {
-	if c.currentReport != nil {
+// addDataSource adds a data source to the report being built in ctx
+func (c *CryptoCoinAnalyzer) addDataSource(ctx context.Context, name, url, dataType, reliability string) {
+	if report, ok := ctx.Value(coinReportKey{}).(*CoinAnalysisReport); ok {
 		source := DataSource{
 			Name:        name,
 			URL:         url,
@@ -870,10 +957,10 @@ func (c *CryptoCoinAnalyzer) addDataSource(name, url, dataType, reliability stri
 			Reliability: reliability,
 			LastChecked: time.Now(),
 		}
-		c.currentReport.Sources = append(c.currentReport.Sources, source)
+		report.Sources = append(report.Sources, source)
 	}
 
-	c.logger.Info(context.Background(), "Data source added", map[string]interface{}{
+	c.logger.Info(ctx, "Data source added", map[string]interface{}{
 		"name":        name,
 		"type":        dataType,
 		"reliability": reliability,
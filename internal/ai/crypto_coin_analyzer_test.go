@@ -2,7 +2,10 @@ package ai
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -369,3 +372,135 @@ func TestGetCurrentTimestamp(t *testing.T) {
 		t.Error("Expected timestamp to contain current year")
 	}
 }
+
+func TestAnalyzeCoins(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "info",
+		LogFormat:   "text",
+	})
+
+	analyzer := NewCryptoCoinAnalyzer(logger)
+	analyzer.SetBatchWorkers(2)
+
+	result, err := analyzer.AnalyzeCoins(context.Background(), []string{"BTC", "eth", "btc", " ADA ", "X"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Requested != 4 {
+		t.Errorf("Expected 4 deduplicated symbols, got %d", result.Requested)
+	}
+	for _, symbol := range []string{"BTC", "ETH", "ADA"} {
+		report, ok := result.Reports[symbol]
+		if !ok {
+			t.Errorf("Expected report for %s", symbol)
+			continue
+		}
+		if report.Symbol != symbol {
+			t.Errorf("Expected report symbol %s, got %s", symbol, report.Symbol)
+		}
+		if len(report.Sources) != 5 {
+			t.Errorf("Expected 5 data sources for %s, got %d", symbol, len(report.Sources))
+		}
+	}
+	if _, ok := result.Errors["X"]; !ok {
+		t.Error("Expected per-symbol error for invalid symbol X")
+	}
+	if result.Succeeded != 3 || result.Failed != 1 {
+		t.Errorf("Expected 3 succeeded and 1 failed, got %d and %d", result.Succeeded, result.Failed)
+	}
+}
+
+func TestAnalyzeCoins_Limits(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "info",
+		LogFormat:   "text",
+	})
+
+	analyzer := NewCryptoCoinAnalyzer(logger)
+
+	if _, err := analyzer.AnalyzeCoins(context.Background(), nil); err == nil {
+		t.Error("Expected error for empty batch")
+	}
+
+	symbols := make([]string, 0, MaxBatchSymbols+1)
+	for i := 0; i <= MaxBatchSymbols; i++ {
+		symbols = append(symbols, fmt.Sprintf("C%02d", i))
+	}
+	if _, err := analyzer.AnalyzeCoins(context.Background(), symbols); err == nil {
+		t.Error("Expected error for oversized batch")
+	}
+}
+
+// memorySearchCache is a SearchResultCache backed by a map, standing in for Redis
+type memorySearchCache struct {
+	mu      sync.Mutex
+	results map[string][]WebSearchResult
+	err     error
+}
+
+func (m *memorySearchCache) Get(ctx context.Context, query string) ([]WebSearchResult, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	results, found := m.results[query]
+	return results, found, nil
+}
+
+func (m *memorySearchCache) Set(ctx context.Context, query string, results []WebSearchResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.results[query] = results
+	return nil
+}
+
+func TestAnalyzeCoins_SharedSearchCache(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "info",
+		LogFormat:   "text",
+	})
+	ctx := context.Background()
+	cache := &memorySearchCache{results: make(map[string][]WebSearchResult)}
+
+	first := NewCryptoCoinAnalyzer(logger)
+	first.SetSearchCache(cache)
+	if _, err := first.AnalyzeCoins(ctx, []string{"BTC", "ETH"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cache.results) == 0 {
+		t.Fatal("Expected batch searches to be cached")
+	}
+
+	// Another instance serves the same lookups from the cache instead of searching again
+	cached := []WebSearchResult{{Title: "Cached", Snippet: "BTC trades at $12,345", Source: "cache"}}
+	for query := range cache.results {
+		cache.results[query] = cached
+	}
+	second := NewCryptoCoinAnalyzer(logger)
+	second.SetSearchCache(cache)
+	results, err := second.performWebSearch(ctx, "BTC cryptocurrency price market cap volume")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Source != "cache" {
+		t.Errorf("Expected the cached results, got %+v", results)
+	}
+
+	// Without a working cache the search runs uncached
+	cache.err = errors.New("redis unavailable")
+	results, err = second.performWebSearch(ctx, "BTC cryptocurrency price market cap volume")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) > 0 && results[0].Source == "cache" {
+		t.Error("Expected a fresh search when the cache fails")
+	}
+}
@@ -0,0 +1,252 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultBatchWorkers bounds concurrent analyses in a batch unless configured otherwise
+	defaultBatchWorkers = 5
+	// MaxBatchSymbols is the largest number of distinct symbols accepted in one batch
+	MaxBatchSymbols = 25
+)
+
+// BatchAnalysisResult holds per-symbol reports and failures for a batch analysis
+type BatchAnalysisResult struct {
+	Reports   map[string]*CoinAnalysisReport `json:"reports"`
+	Errors    map[string]string              `json:"errors,omitempty"`
+	Requested int                            `json:"requested"`
+	Succeeded int                            `json:"succeeded"`
+	Failed    int                            `json:"failed"`
+	Duration  time.Duration                  `json:"duration"`
+}
+
+// searchMemoKey scopes a searchMemo to a single batch through the context
+type searchMemoKey struct{}
+
+// searchMemo shares web search results between analyses of the same batch
+type searchMemo struct {
+	entries map[string]*searchMemoEntry
+	mu      sync.Mutex
+}
+
+type searchMemoEntry struct {
+	once    sync.Once
+	results []WebSearchResult
+	err     error
+}
+
+// do runs fetch once per query, letting concurrent callers wait for the first result
+func (m *searchMemo) do(query string, fetch func() ([]WebSearchResult, error)) ([]WebSearchResult, error) {
+	m.mu.Lock()
+	entry, exists := m.entries[query]
+	if !exists {
+		entry = &searchMemoEntry{}
+		m.entries[query] = entry
+	}
+	m.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.results, entry.err = fetch()
+	})
+	return entry.results, entry.err
+}
+
+// SearchResultCache shares web search results between analyzer instances, so a lookup made by
+// one instance is reused by the others until it expires
+type SearchResultCache interface {
+	Get(ctx context.Context, query string) ([]WebSearchResult, bool, error)
+	Set(ctx context.Context, query string, results []WebSearchResult) error
+}
+
+// redisSearchResultCache implements SearchResultCache with expiring Redis keys
+type redisSearchResultCache struct {
+	redis *database.RedisClient
+	ttl   time.Duration
+}
+
+// NewRedisSearchResultCache creates a search result cache backed by Redis
+func NewRedisSearchResultCache(redis *database.RedisClient, ttl time.Duration) SearchResultCache {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	return &redisSearchResultCache{redis: redis, ttl: ttl}
+}
+
+func (c *redisSearchResultCache) Get(ctx context.Context, query string) ([]WebSearchResult, bool, error) {
+	data, err := c.redis.Client.Get(ctx, searchCacheKey(query)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var results []WebSearchResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, false, err
+	}
+	return results, true, nil
+}
+
+func (c *redisSearchResultCache) Set(ctx context.Context, query string, results []WebSearchResult) error {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return c.redis.SetWithExpiry(ctx, searchCacheKey(query), data, c.ttl)
+}
+
+// searchCacheKey identifies the results of a search query
+func searchCacheKey(query string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(query))))
+	return "ai:crypto:search:" + hex.EncodeToString(hash[:])
+}
+
+// SetSearchCache shares web search results through cache. Without one, searches are only
+// shared between the analyses of a batch.
+func (c *CryptoCoinAnalyzer) SetSearchCache(cache SearchResultCache) {
+	c.searchCache = cache
+}
+
+// cachedSearch serves a query from the search cache, running fetch and caching its results on
+// a miss. Cache failures are logged and the search runs uncached.
+func (c *CryptoCoinAnalyzer) cachedSearch(ctx context.Context, query string, fetch func() ([]WebSearchResult, error)) ([]WebSearchResult, error) {
+	if c.searchCache == nil {
+		return fetch()
+	}
+
+	results, found, err := c.searchCache.Get(ctx, query)
+	if err != nil {
+		c.logger.Warn(ctx, "Search cache lookup failed", map[string]interface{}{
+			"query": query,
+			"error": err.Error(),
+		})
+	} else if found {
+		return results, nil
+	}
+
+	results, err = fetch()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.searchCache.Set(ctx, query, results); err != nil {
+		c.logger.Warn(ctx, "Failed to cache search results", map[string]interface{}{
+			"query": query,
+			"error": err.Error(),
+		})
+	}
+	return results, nil
+}
+
+// SetBatchWorkers sets how many symbols a batch analyzes concurrently
+func (c *CryptoCoinAnalyzer) SetBatchWorkers(workers int) {
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	c.batchWorkers = workers
+}
+
+// AnalyzeCoins analyzes several symbols concurrently with a bounded worker pool.
+// Symbols are normalized and deduplicated; a failure for one symbol does not fail the batch.
+func (c *CryptoCoinAnalyzer) AnalyzeCoins(ctx context.Context, symbols []string) (*BatchAnalysisResult, error) {
+	start := time.Now()
+
+	unique := make([]string, 0, len(symbols))
+	seen := make(map[string]bool)
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		unique = append(unique, symbol)
+	}
+
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("at least one symbol is required")
+	}
+	if len(unique) > MaxBatchSymbols {
+		return nil, fmt.Errorf("batch exceeds maximum of %d symbols, got %d", MaxBatchSymbols, len(unique))
+	}
+
+	result := &BatchAnalysisResult{
+		Reports:   make(map[string]*CoinAnalysisReport),
+		Errors:    make(map[string]string),
+		Requested: len(unique),
+	}
+
+	ctx = context.WithValue(ctx, searchMemoKey{}, &searchMemo{entries: make(map[string]*searchMemoEntry)})
+
+	workers := c.batchWorkers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range jobs {
+				var report *CoinAnalysisReport
+				var err error
+				if len(symbol) < 2 || len(symbol) > 10 {
+					err = fmt.Errorf("invalid symbol format")
+				} else {
+					report, err = c.AnalyzeCoin(ctx, symbol)
+				}
+
+				mu.Lock()
+				if err != nil {
+					result.Errors[symbol] = err.Error()
+				} else {
+					result.Reports[symbol] = report
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, symbol := range unique {
+		select {
+		case jobs <- symbol:
+		case <-ctx.Done():
+			mu.Lock()
+			result.Errors[symbol] = ctx.Err().Error()
+			mu.Unlock()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	result.Succeeded = len(result.Reports)
+	result.Failed = len(result.Errors)
+	result.Duration = time.Since(start)
+
+	c.logger.Info(ctx, "Batch cryptocurrency analysis completed", map[string]interface{}{
+		"requested": result.Requested,
+		"succeeded": result.Succeeded,
+		"failed":    result.Failed,
+		"workers":   workers,
+		"duration":  result.Duration.Milliseconds(),
+	})
+
+	return result, nil
+}
@@ -0,0 +1,487 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrReportScheduleNotFound = errors.New("report schedule not found")
+	ErrReportNotFound         = errors.New("report not found")
+	ErrInvalidReportSchedule  = errors.New("invalid report schedule")
+)
+
+// ReportFormat is the output format of a generated coin report
+type ReportFormat string
+
+const (
+	ReportFormatMarkdown ReportFormat = "markdown"
+	ReportFormatJSON     ReportFormat = "json"
+)
+
+const (
+	maxReportSymbols          = 10
+	maxReportSchedulesPerUser = 20
+	minReportInterval         = 15 * time.Minute
+	reportGenerationTimeout   = 5 * time.Minute
+	maxReportRetryDelay       = 30 * time.Minute
+	reportRetentionInterval   = time.Hour
+)
+
+// ReportSchedule is a user's recurring coin analysis report
+type ReportSchedule struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Symbols   []string     `json:"symbols"`
+	Cron      string       `json:"cron"` // evaluated in UTC
+	Format    ReportFormat `json:"format"`
+	Active    bool         `json:"active"`
+	NextRunAt time.Time    `json:"next_run_at"`
+	LastRunAt *time.Time   `json:"last_run_at,omitempty"`
+	LastError string       `json:"last_error,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	cron *CronSchedule
+}
+
+// CreateReportScheduleRequest is the body of a report schedule creation request
+type CreateReportScheduleRequest struct {
+	Symbols []string     `json:"symbols"`
+	Cron    string       `json:"cron"`
+	Format  ReportFormat `json:"format"`
+}
+
+// CryptoReport is a stored coin analysis report. Listings leave Content empty.
+type CryptoReport struct {
+	ID          uuid.UUID    `json:"id"`
+	ScheduleID  *uuid.UUID   `json:"schedule_id,omitempty"`
+	UserID      uuid.UUID    `json:"user_id"`
+	Symbol      string       `json:"symbol"`
+	Format      ReportFormat `json:"format"`
+	Content     string       `json:"content,omitempty"`
+	Size        int          `json:"size"`
+	Attempts    int          `json:"attempts"`
+	GeneratedAt time.Time    `json:"generated_at"`
+}
+
+// CryptoReportFilter selects stored reports of a user
+type CryptoReportFilter struct {
+	UserID uuid.UUID
+	Symbol string    // optional
+	From   time.Time // optional, inclusive
+	To     time.Time // optional, exclusive
+	Limit  int
+	Offset int
+}
+
+// CryptoReportStore persists report schedules and generated reports
+type CryptoReportStore interface {
+	SaveSchedule(ctx context.Context, schedule *ReportSchedule) error
+	ListSchedules(ctx context.Context, userID uuid.UUID) ([]*ReportSchedule, error)
+	ListActiveSchedules(ctx context.Context) ([]*ReportSchedule, error)
+	DeleteSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error
+	RecordScheduleRun(ctx context.Context, scheduleID uuid.UUID, ranAt, nextRunAt time.Time, lastError string) error
+	SaveReport(ctx context.Context, report *CryptoReport) error
+	ListReports(ctx context.Context, filter CryptoReportFilter) ([]*CryptoReport, error)
+	GetReport(ctx context.Context, userID, reportID uuid.UUID) (*CryptoReport, error)
+	DeleteReportsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// CoinReportSource produces coin analysis reports; CryptoCoinAnalyzer implements it
+type CoinReportSource interface {
+	AnalyzeCoin(ctx context.Context, symbol string) (*CoinAnalysisReport, error)
+	AnalyzeCoinWithStructuredReport(ctx context.Context, symbol string) (string, error)
+}
+
+// CryptoReportScheduler runs report schedules in the background, storing each report and
+// raising an alert when a symbol still fails after its retries
+type CryptoReportScheduler struct {
+	logger    *observability.Logger
+	store     CryptoReportStore
+	source    CoinReportSource
+	alerts    *alerts.AlertService
+	config    config.CryptoReportsConfig
+	schedules map[uuid.UUID]*ReportSchedule
+	running   map[uuid.UUID]bool
+	lastPrune time.Time
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+}
+
+// NewCryptoReportScheduler creates a report scheduler. alertService may be nil, in which
+// case failed runs are only logged and recorded on the schedule.
+func NewCryptoReportScheduler(logger *observability.Logger, store CryptoReportStore, source CoinReportSource, alertService *alerts.AlertService, cfg config.CryptoReportsConfig) *CryptoReportScheduler {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	return &CryptoReportScheduler{
+		logger:    logger,
+		store:     store,
+		source:    source,
+		alerts:    alertService,
+		config:    cfg,
+		schedules: make(map[uuid.UUID]*ReportSchedule),
+		running:   make(map[uuid.UUID]bool),
+	}
+}
+
+// Start loads the active schedules and runs them as they come due until ctx is done.
+// Schedules whose run was missed while the service was down run once on the first check.
+func (s *CryptoReportScheduler) Start(ctx context.Context) error {
+	schedules, err := s.store.ListActiveSchedules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load report schedules: %w", err)
+	}
+
+	s.mu.Lock()
+	for _, schedule := range schedules {
+		cron, err := ParseCronSchedule(schedule.Cron)
+		if err != nil {
+			s.logger.Warn(ctx, "Skipping report schedule with invalid cron expression", map[string]interface{}{
+				"schedule_id": schedule.ID.String(),
+				"cron":        schedule.Cron,
+			})
+			continue
+		}
+		schedule.cron = cron
+		s.schedules[schedule.ID] = schedule
+	}
+	loaded := len(s.schedules)
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			s.runDue(ctx, time.Now())
+			s.pruneReports(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	s.logger.Info(ctx, "Report scheduler started", map[string]interface{}{
+		"schedules": loaded,
+		"retention": s.config.Retention.String(),
+	})
+	return nil
+}
+
+// CreateSchedule validates and stores a new report schedule for a user
+func (s *CryptoReportScheduler) CreateSchedule(ctx context.Context, userID uuid.UUID, req CreateReportScheduleRequest) (*ReportSchedule, error) {
+	schedule, err := newReportSchedule(userID, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	count := 0
+	for _, existing := range s.schedules {
+		if existing.UserID == userID {
+			count++
+		}
+	}
+	s.mu.Unlock()
+	if count >= maxReportSchedulesPerUser {
+		return nil, fmt.Errorf("%w: at most %d schedules per user", ErrInvalidReportSchedule, maxReportSchedulesPerUser)
+	}
+
+	if err := s.store.SaveSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save report schedule: %w", err)
+	}
+
+	// The runner updates its own copy so the returned schedule is safe to encode
+	scheduled := *schedule
+	s.mu.Lock()
+	s.schedules[schedule.ID] = &scheduled
+	s.mu.Unlock()
+
+	s.logger.Info(ctx, "Report schedule created", map[string]interface{}{
+		"schedule_id": schedule.ID.String(),
+		"user_id":     userID.String(),
+		"symbols":     schedule.Symbols,
+		"cron":        schedule.Cron,
+		"next_run_at": schedule.NextRunAt,
+	})
+	return schedule, nil
+}
+
+// ListSchedules returns the report schedules of a user
+func (s *CryptoReportScheduler) ListSchedules(ctx context.Context, userID uuid.UUID) ([]*ReportSchedule, error) {
+	return s.store.ListSchedules(ctx, userID)
+}
+
+// DeleteSchedule removes a user's report schedule. Reports it already produced are kept
+// until they expire.
+func (s *CryptoReportScheduler) DeleteSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error {
+	if err := s.store.DeleteSchedule(ctx, userID, scheduleID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.schedules, scheduleID)
+	s.mu.Unlock()
+	return nil
+}
+
+// ListReports returns stored reports without their content
+func (s *CryptoReportScheduler) ListReports(ctx context.Context, filter CryptoReportFilter) ([]*CryptoReport, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	filter.Symbol = strings.ToUpper(strings.TrimSpace(filter.Symbol))
+	return s.store.ListReports(ctx, filter)
+}
+
+// GetReport returns a stored report of a user with its content
+func (s *CryptoReportScheduler) GetReport(ctx context.Context, userID, reportID uuid.UUID) (*CryptoReport, error) {
+	return s.store.GetReport(ctx, userID, reportID)
+}
+
+// Wait blocks until runs in progress have finished
+func (s *CryptoReportScheduler) Wait() {
+	s.wg.Wait()
+}
+
+// runDue starts every schedule that is due and not already running
+func (s *CryptoReportScheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, schedule := range s.schedules {
+		if !schedule.Active || s.running[id] || schedule.NextRunAt.After(now) {
+			continue
+		}
+		s.running[id] = true
+		s.wg.Add(1)
+		go s.run(ctx, *schedule, now)
+	}
+}
+
+// run generates the reports of one schedule run and records its outcome. The next run is
+// the first one after both the due check and the end of this run, so missed runs are skipped.
+func (s *CryptoReportScheduler) run(ctx context.Context, schedule ReportSchedule, dueAt time.Time) {
+	defer s.wg.Done()
+
+	var failures []string
+	for _, symbol := range schedule.Symbols {
+		report, err := s.generate(ctx, schedule, symbol)
+		if err == nil {
+			err = s.store.SaveReport(ctx, report)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", symbol, err))
+			s.reportFailure(ctx, schedule, symbol, err)
+		}
+	}
+
+	ranAt := time.Now()
+	nextRunAt := schedule.cron.Next(ranAt)
+	if dueAt.After(ranAt) {
+		nextRunAt = schedule.cron.Next(dueAt)
+	}
+	lastError := strings.Join(failures, "; ")
+	if err := s.store.RecordScheduleRun(context.WithoutCancel(ctx), schedule.ID, ranAt, nextRunAt, lastError); err != nil {
+		s.logger.Error(ctx, "Failed to record report schedule run", err, map[string]interface{}{
+			"schedule_id": schedule.ID.String(),
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, schedule.ID)
+	if current, exists := s.schedules[schedule.ID]; exists {
+		current.LastRunAt = &ranAt
+		current.NextRunAt = nextRunAt
+		current.LastError = lastError
+		if nextRunAt.IsZero() {
+			current.Active = false
+		}
+	}
+}
+
+// generate produces one report, retrying with exponential backoff
+func (s *CryptoReportScheduler) generate(ctx context.Context, schedule ReportSchedule, symbol string) (*CryptoReport, error) {
+	var err error
+	delay := s.config.RetryDelay
+	for attempt := 1; attempt <= s.config.MaxAttempts; attempt++ {
+		var content string
+		content, err = s.render(ctx, schedule.Format, symbol)
+		if err == nil {
+			scheduleID := schedule.ID
+			return &CryptoReport{
+				ID:          uuid.New(),
+				ScheduleID:  &scheduleID,
+				UserID:      schedule.UserID,
+				Symbol:      symbol,
+				Format:      schedule.Format,
+				Content:     content,
+				Size:        len(content),
+				Attempts:    attempt,
+				GeneratedAt: time.Now().UTC(),
+			}, nil
+		}
+
+		s.logger.Warn(ctx, "Scheduled report generation failed", map[string]interface{}{
+			"schedule_id": schedule.ID.String(),
+			"symbol":      symbol,
+			"attempt":     attempt,
+			"error":       err.Error(),
+		})
+		if attempt == s.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReportRetryDelay {
+			delay = maxReportRetryDelay
+		}
+	}
+	return nil, fmt.Errorf("failed after %d attempts: %w", s.config.MaxAttempts, err)
+}
+
+// render runs the analysis for a symbol in the requested format
+func (s *CryptoReportScheduler) render(ctx context.Context, format ReportFormat, symbol string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, reportGenerationTimeout)
+	defer cancel()
+
+	if format == ReportFormatJSON {
+		report, err := s.source.AnalyzeCoin(ctx, symbol)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(report)
+		return string(data), err
+	}
+	return s.source.AnalyzeCoinWithStructuredReport(ctx, symbol)
+}
+
+// reportFailure raises an alert for a symbol whose report could not be generated
+func (s *CryptoReportScheduler) reportFailure(ctx context.Context, schedule ReportSchedule, symbol string, err error) {
+	s.logger.Error(ctx, "Scheduled report failed", err, map[string]interface{}{
+		"schedule_id": schedule.ID.String(),
+		"symbol":      symbol,
+	})
+	if s.alerts == nil {
+		return
+	}
+
+	alert := s.alerts.CreateAlert("report_schedule_"+schedule.ID.String(), fmt.Sprintf("Scheduled %s report failed", symbol),
+		fmt.Sprintf("The scheduled %s report for %s could not be generated: %v", schedule.Format, symbol, err),
+		alerts.SeverityWarning, "crypto_report", decimal.Zero, decimal.Zero, nil)
+	alert.UserID = &schedule.UserID
+	alert.Category = alerts.CategorySystemAlerts
+	alert.Metadata["schedule_id"] = schedule.ID.String()
+	alert.Metadata["symbol"] = symbol
+	alert.Metadata["cron"] = schedule.Cron
+	s.alerts.SendAlert(alert)
+}
+
+// pruneReports deletes reports past the retention period, at most once per hour
+func (s *CryptoReportScheduler) pruneReports(ctx context.Context, now time.Time) {
+	if s.config.Retention <= 0 || now.Sub(s.lastPrune) < reportRetentionInterval {
+		return
+	}
+	s.lastPrune = now
+
+	deleted, err := s.store.DeleteReportsBefore(ctx, now.Add(-s.config.Retention))
+	if err != nil {
+		s.logger.Error(ctx, "Failed to delete expired reports", err)
+		return
+	}
+	if deleted > 0 {
+		s.logger.Info(ctx, "Expired reports deleted", map[string]interface{}{
+			"deleted": deleted,
+		})
+	}
+}
+
+// newReportSchedule validates a creation request into a new active schedule
+func newReportSchedule(userID uuid.UUID, req CreateReportScheduleRequest, now time.Time) (*ReportSchedule, error) {
+	schedule := &ReportSchedule{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Cron:      strings.TrimSpace(req.Cron),
+		Format:    ReportFormat(strings.ToLower(string(req.Format))),
+		Active:    true,
+		CreatedAt: now.UTC(),
+	}
+
+	seen := make(map[string]bool)
+	for _, symbol := range req.Symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if len(symbol) < 2 || len(symbol) > 10 {
+			return nil, fmt.Errorf("%w: invalid symbol %q", ErrInvalidReportSchedule, symbol)
+		}
+		if !seen[symbol] {
+			seen[symbol] = true
+			schedule.Symbols = append(schedule.Symbols, symbol)
+		}
+	}
+	if len(schedule.Symbols) == 0 || len(schedule.Symbols) > maxReportSymbols {
+		return nil, fmt.Errorf("%w: between 1 and %d symbols are required", ErrInvalidReportSchedule, maxReportSymbols)
+	}
+
+	switch schedule.Format {
+	case "":
+		schedule.Format = ReportFormatMarkdown
+	case ReportFormatMarkdown, ReportFormatJSON:
+	default:
+		return nil, fmt.Errorf("%w: format must be markdown or json", ErrInvalidReportSchedule)
+	}
+
+	cron, err := ParseCronSchedule(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportSchedule, err)
+	}
+	schedule.cron = cron
+
+	// Reject schedules that would run more often than the minimum interval anywhere in their cycle
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: cron expression %q never runs", ErrInvalidReportSchedule, schedule.Cron)
+	}
+	for i, run := 0, next; i < 100; i++ {
+		following := cron.Next(run)
+		if following.IsZero() {
+			break
+		}
+		if following.Sub(run) < minReportInterval {
+			return nil, fmt.Errorf("%w: schedules may run at most every %s", ErrInvalidReportSchedule, minReportInterval)
+		}
+		run = following
+	}
+	schedule.NextRunAt = next
+
+	return schedule, nil
+}
@@ -0,0 +1,303 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2025, 3, 14, 10, 30, 45, 0, time.UTC) // a Friday
+	testCases := []struct {
+		expression string
+		next       time.Time
+	}{
+		{"0 6 * * *", time.Date(2025, 3, 15, 6, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)},
+		{"30 8 1,15 * *", time.Date(2025, 3, 15, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		schedule, err := ParseCronSchedule(tc.expression)
+		require.NoError(t, err, tc.expression)
+		assert.Equal(t, tc.next, schedule.Next(from), tc.expression)
+	}
+
+	never, err := ParseCronSchedule("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@fortnightly"} {
+		_, err := ParseCronSchedule(expression)
+		assert.ErrorIs(t, err, ErrInvalidCronExpression, expression)
+	}
+}
+
+func TestNewReportSchedule(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)
+
+	schedule, err := newReportSchedule(userID, CreateReportScheduleRequest{
+		Symbols: []string{"btc", " ETH ", "BTC"},
+		Cron:    "0 6 * * *",
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTC", "ETH"}, schedule.Symbols)
+	assert.Equal(t, ReportFormatMarkdown, schedule.Format)
+	assert.Equal(t, time.Date(2025, 3, 15, 6, 0, 0, 0, time.UTC), schedule.NextRunAt)
+	assert.True(t, schedule.Active)
+
+	invalid := []CreateReportScheduleRequest{
+		{Cron: "0 6 * * *"},
+		{Symbols: []string{"B"}, Cron: "0 6 * * *"},
+		{Symbols: []string{"BTC"}, Cron: "0 6 * * *", Format: "pdf"},
+		{Symbols: []string{"BTC"}, Cron: "not a cron"},
+		{Symbols: []string{"BTC"}, Cron: "*/5 * * * *"},
+		{Symbols: []string{"BTC"}, Cron: "0,10 * * * *"},
+		{Symbols: []string{"BTC"}, Cron: "0 0 31 2 *"},
+	}
+	for _, req := range invalid {
+		_, err := newReportSchedule(userID, req, now)
+		assert.ErrorIs(t, err, ErrInvalidReportSchedule, "%+v", req)
+	}
+}
+
+type memoryCryptoReportStore struct {
+	mu        sync.Mutex
+	schedules map[uuid.UUID]*ReportSchedule
+	reports   []*CryptoReport
+}
+
+func newMemoryCryptoReportStore() *memoryCryptoReportStore {
+	return &memoryCryptoReportStore{schedules: make(map[uuid.UUID]*ReportSchedule)}
+}
+
+func (m *memoryCryptoReportStore) SaveSchedule(ctx context.Context, schedule *ReportSchedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *schedule
+	m.schedules[schedule.ID] = &stored
+	return nil
+}
+
+func (m *memoryCryptoReportStore) ListSchedules(ctx context.Context, userID uuid.UUID) ([]*ReportSchedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var schedules []*ReportSchedule
+	for _, schedule := range m.schedules {
+		if schedule.UserID == userID {
+			stored := *schedule
+			schedules = append(schedules, &stored)
+		}
+	}
+	return schedules, nil
+}
+
+func (m *memoryCryptoReportStore) ListActiveSchedules(ctx context.Context) ([]*ReportSchedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var schedules []*ReportSchedule
+	for _, schedule := range m.schedules {
+		if schedule.Active {
+			stored := *schedule
+			schedules = append(schedules, &stored)
+		}
+	}
+	return schedules, nil
+}
+
+func (m *memoryCryptoReportStore) DeleteSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if schedule, ok := m.schedules[scheduleID]; !ok || schedule.UserID != userID {
+		return ErrReportScheduleNotFound
+	}
+	delete(m.schedules, scheduleID)
+	return nil
+}
+
+func (m *memoryCryptoReportStore) RecordScheduleRun(ctx context.Context, scheduleID uuid.UUID, ranAt, nextRunAt time.Time, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if schedule, ok := m.schedules[scheduleID]; ok {
+		schedule.LastRunAt = &ranAt
+		schedule.LastError = lastError
+		if nextRunAt.IsZero() {
+			schedule.Active = false
+		} else {
+			schedule.NextRunAt = nextRunAt
+		}
+	}
+	return nil
+}
+
+func (m *memoryCryptoReportStore) SaveReport(ctx context.Context, report *CryptoReport) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func (m *memoryCryptoReportStore) ListReports(ctx context.Context, filter CryptoReportFilter) ([]*CryptoReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var reports []*CryptoReport
+	for _, report := range m.reports {
+		if report.UserID == filter.UserID && (filter.Symbol == "" || report.Symbol == filter.Symbol) {
+			listed := *report
+			listed.Content = ""
+			reports = append(reports, &listed)
+		}
+	}
+	return reports, nil
+}
+
+func (m *memoryCryptoReportStore) GetReport(ctx context.Context, userID, reportID uuid.UUID) (*CryptoReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, report := range m.reports {
+		if report.ID == reportID && report.UserID == userID {
+			return report, nil
+		}
+	}
+	return nil, ErrReportNotFound
+}
+
+func (m *memoryCryptoReportStore) DeleteReportsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.reports[:0]
+	for _, report := range m.reports {
+		if !report.GeneratedAt.Before(cutoff) {
+			kept = append(kept, report)
+		}
+	}
+	deleted := int64(len(m.reports) - len(kept))
+	m.reports = kept
+	return deleted, nil
+}
+
+// flakyReportSource fails the first failures calls per symbol
+type flakyReportSource struct {
+	mu       sync.Mutex
+	failures map[string]int
+	calls    map[string]int
+}
+
+func (f *flakyReportSource) AnalyzeCoin(ctx context.Context, symbol string) (*CoinAnalysisReport, error) {
+	if err := f.call(symbol); err != nil {
+		return nil, err
+	}
+	return &CoinAnalysisReport{Symbol: symbol}, nil
+}
+
+func (f *flakyReportSource) AnalyzeCoinWithStructuredReport(ctx context.Context, symbol string) (string, error) {
+	if err := f.call(symbol); err != nil {
+		return "", err
+	}
+	return "# " + symbol + " report", nil
+}
+
+func (f *flakyReportSource) call(symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[symbol]++
+	if f.calls[symbol] <= f.failures[symbol] {
+		return errors.New("market data unavailable")
+	}
+	return nil
+}
+
+func TestCryptoReportScheduler_RunWithRetries(t *testing.T) {
+	logger := &observability.Logger{}
+	store := newMemoryCryptoReportStore()
+	source := &flakyReportSource{
+		failures: map[string]int{"BTC": 1, "DOGE": 100},
+		calls:    make(map[string]int),
+	}
+	alertService := alerts.NewAlertService(logger, alerts.AlertConfig{MaxHistorySize: 10})
+	scheduler := NewCryptoReportScheduler(logger, store, source, alertService, config.CryptoReportsConfig{
+		MaxAttempts: 3,
+		RetryDelay:  time.Millisecond,
+	})
+
+	userID := uuid.New()
+	schedule, err := scheduler.CreateSchedule(context.Background(), userID, CreateReportScheduleRequest{
+		Symbols: []string{"BTC", "DOGE"},
+		Cron:    "0 6 * * *",
+	})
+	require.NoError(t, err)
+
+	// Not due yet
+	scheduler.runDue(context.Background(), schedule.NextRunAt.Add(-time.Minute))
+	scheduler.Wait()
+	assert.Empty(t, store.reports)
+
+	scheduler.runDue(context.Background(), schedule.NextRunAt)
+	scheduler.Wait()
+
+	reports, err := scheduler.ListReports(context.Background(), CryptoReportFilter{UserID: userID, Symbol: "btc"})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, 2, reports[0].Attempts)
+	assert.Empty(t, reports[0].Content)
+	assert.Equal(t, len("# BTC report"), reports[0].Size)
+
+	report, err := scheduler.GetReport(context.Background(), userID, reports[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "# BTC report", report.Content)
+	assert.Equal(t, 3, source.calls["DOGE"])
+
+	// The failing symbol is recorded on the schedule and raised as an alert for the user
+	schedules, err := scheduler.ListSchedules(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Contains(t, schedules[0].LastError, "DOGE: failed after 3 attempts")
+	require.NotNil(t, schedules[0].LastRunAt)
+	assert.True(t, schedules[0].NextRunAt.After(schedule.NextRunAt))
+
+	sent := alertService.GetAlerts(0)
+	require.Len(t, sent, 1)
+	assert.Equal(t, "crypto_report", sent[0].Metric)
+	assert.Equal(t, "DOGE", sent[0].Metadata["symbol"])
+	require.NotNil(t, sent[0].UserID)
+	assert.Equal(t, userID, *sent[0].UserID)
+
+	// The schedule is not due again until its next run
+	scheduler.runDue(context.Background(), schedule.NextRunAt.Add(time.Minute))
+	scheduler.Wait()
+	assert.Len(t, store.reports, 1)
+
+	_, err = scheduler.GetReport(context.Background(), uuid.New(), reports[0].ID)
+	assert.ErrorIs(t, err, ErrReportNotFound)
+	assert.ErrorIs(t, scheduler.DeleteSchedule(context.Background(), uuid.New(), schedule.ID), ErrReportScheduleNotFound)
+	require.NoError(t, scheduler.DeleteSchedule(context.Background(), userID, schedule.ID))
+}
+
+func TestCryptoReportScheduler_PruneReports(t *testing.T) {
+	store := newMemoryCryptoReportStore()
+	now := time.Now()
+	store.reports = []*CryptoReport{
+		{ID: uuid.New(), GeneratedAt: now.Add(-48 * time.Hour)},
+		{ID: uuid.New(), GeneratedAt: now.Add(-time.Hour)},
+	}
+	scheduler := NewCryptoReportScheduler(&observability.Logger{}, store, nil, nil, config.CryptoReportsConfig{Retention: 24 * time.Hour})
+
+	scheduler.pruneReports(context.Background(), now)
+	require.Len(t, store.reports, 1)
+	assert.Equal(t, now.Add(-time.Hour), store.reports[0].GeneratedAt)
+}
@@ -0,0 +1,183 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// postgresCryptoReportStore implements CryptoReportStore using the crypto_report_schedules and crypto_reports tables
+type postgresCryptoReportStore struct {
+	db *database.DB
+}
+
+func NewPostgresCryptoReportStore(db *database.DB) CryptoReportStore {
+	return &postgresCryptoReportStore{db: db}
+}
+
+const reportScheduleColumns = `id, user_id, symbols, cron, format, is_active, next_run_at, last_run_at, last_error, created_at`
+
+func (s *postgresCryptoReportStore) SaveSchedule(ctx context.Context, schedule *ReportSchedule) error {
+	query := `
+		INSERT INTO crypto_report_schedules (` + reportScheduleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := s.db.ExecWithMetrics(ctx, query, schedule.ID, schedule.UserID, pq.Array(schedule.Symbols), schedule.Cron,
+		string(schedule.Format), schedule.Active, schedule.NextRunAt, schedule.LastRunAt, nullString(schedule.LastError),
+		schedule.CreatedAt)
+	return err
+}
+
+func (s *postgresCryptoReportStore) ListSchedules(ctx context.Context, userID uuid.UUID) ([]*ReportSchedule, error) {
+	return s.querySchedules(ctx, `
+		SELECT `+reportScheduleColumns+` FROM crypto_report_schedules
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+}
+
+func (s *postgresCryptoReportStore) ListActiveSchedules(ctx context.Context) ([]*ReportSchedule, error) {
+	return s.querySchedules(ctx, `
+		SELECT `+reportScheduleColumns+` FROM crypto_report_schedules
+		WHERE is_active
+	`)
+}
+
+func (s *postgresCryptoReportStore) DeleteSchedule(ctx context.Context, userID, scheduleID uuid.UUID) error {
+	result, err := s.db.ExecWithMetrics(ctx, `DELETE FROM crypto_report_schedules WHERE id = $1 AND user_id = $2`, scheduleID, userID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrReportScheduleNotFound
+	}
+	return nil
+}
+
+func (s *postgresCryptoReportStore) RecordScheduleRun(ctx context.Context, scheduleID uuid.UUID, ranAt, nextRunAt time.Time, lastError string) error {
+	// A zero next run means the expression never matches again
+	var next interface{} = nextRunAt
+	if nextRunAt.IsZero() {
+		next = nil
+	}
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE crypto_report_schedules
+		SET last_run_at = $2, next_run_at = COALESCE($3, next_run_at), is_active = $3 IS NOT NULL, last_error = $4
+		WHERE id = $1
+	`, scheduleID, ranAt, next, nullString(lastError))
+	return err
+}
+
+func (s *postgresCryptoReportStore) SaveReport(ctx context.Context, report *CryptoReport) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO crypto_reports (id, schedule_id, user_id, symbol, format, content, attempts, generated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, report.ID, report.ScheduleID, report.UserID, report.Symbol, string(report.Format), report.Content,
+		report.Attempts, report.GeneratedAt)
+	return err
+}
+
+func (s *postgresCryptoReportStore) ListReports(ctx context.Context, filter CryptoReportFilter) ([]*CryptoReport, error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{filter.UserID}
+	if filter.Symbol != "" {
+		args = append(args, filter.Symbol)
+		conditions = append(conditions, fmt.Sprintf("symbol = $%d", len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("generated_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("generated_at < $%d", len(args)))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, schedule_id, user_id, symbol, format, LENGTH(content), attempts, generated_at
+		FROM crypto_reports
+		WHERE %s
+		ORDER BY generated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(conditions, " AND "), len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]*CryptoReport, 0)
+	for rows.Next() {
+		report := &CryptoReport{}
+		var format string
+		if err := rows.Scan(&report.ID, &report.ScheduleID, &report.UserID, &report.Symbol, &format, &report.Size,
+			&report.Attempts, &report.GeneratedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		report.Format = ReportFormat(format)
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+func (s *postgresCryptoReportStore) GetReport(ctx context.Context, userID, reportID uuid.UUID) (*CryptoReport, error) {
+	report := &CryptoReport{}
+	var format string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, schedule_id, user_id, symbol, format, content, attempts, generated_at
+		FROM crypto_reports
+		WHERE id = $1 AND user_id = $2
+	`, reportID, userID).Scan(&report.ID, &report.ScheduleID, &report.UserID, &report.Symbol, &format, &report.Content,
+		&report.Attempts, &report.GeneratedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Format = ReportFormat(format)
+	report.Size = len(report.Content)
+	return report, nil
+}
+
+func (s *postgresCryptoReportStore) DeleteReportsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := s.db.ExecWithMetrics(ctx, `DELETE FROM crypto_reports WHERE generated_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *postgresCryptoReportStore) querySchedules(ctx context.Context, query string, args ...interface{}) ([]*ReportSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]*ReportSchedule, 0)
+	for rows.Next() {
+		schedule := &ReportSchedule{}
+		var format string
+		var lastError sql.NullString
+		if err := rows.Scan(&schedule.ID, &schedule.UserID, pq.Array(&schedule.Symbols), &schedule.Cron, &format,
+			&schedule.Active, &schedule.NextRunAt, &schedule.LastRunAt, &lastError, &schedule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan report schedule: %w", err)
+		}
+		schedule.Format = ReportFormat(format)
+		schedule.LastError = lastError.String
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
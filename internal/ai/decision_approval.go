@@ -0,0 +1,330 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Decision approval errors
+var (
+	ErrDecisionNotFound            = errors.New("decision not found")
+	ErrDecisionExpired             = errors.New("decision has expired")
+	ErrDecisionAlreadyReviewed     = errors.New("decision has already been reviewed")
+	ErrDecisionExecuting           = errors.New("decision is being executed")
+	ErrDecisionNotApprovable       = errors.New("decision has no recommendation to execute")
+	ErrDecisionExecutorUnavailable = errors.New("decision execution is not configured")
+	ErrInvalidApprovalQuantity     = errors.New("invalid approval quantity")
+)
+
+// Approval states of a recorded decision
+const (
+	DecisionApprovalPending  = "pending"
+	DecisionApprovalApproved = "approved"
+	DecisionApprovalRejected = "rejected"
+
+	// DecisionApprovalExecutionFailed marks an approval whose execution failed; approving the
+	// decision again retries it
+	DecisionApprovalExecutionFailed = "execution_failed"
+)
+
+// Execution states of an approved decision
+const (
+	DecisionExecutionRunning   = "executing"
+	DecisionExecutionCompleted = "completed"
+	DecisionExecutionSubmitted = "submitted"
+	DecisionExecutionFailed    = "failed"
+)
+
+// DecisionApproval records the review of a decision before it is executed
+type DecisionApproval struct {
+	Status     string    `json:"status"`
+	ReviewedBy uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt time.Time `json:"reviewed_at,omitempty"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// DecisionExecutor carries out the recommendation of an approved decision. Executors either
+// trade directly, reporting a completed execution, or hand the recommendation to another
+// service, reporting it as submitted.
+type DecisionExecutor interface {
+	ExecuteDecision(ctx context.Context, record DecisionRecord) (*ExecutionRecord, error)
+}
+
+// SetExecutor sets the executor used for approved decisions
+func (d *DecisionEngine) SetExecutor(executor DecisionExecutor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.executor = executor
+}
+
+// ApproveDecision approves a pending decision on behalf of its owner and executes its
+// recommendation. The decision is only marked approved once execution succeeds; a failed
+// execution leaves it in DecisionApprovalExecutionFailed, and approving it again retries.
+// Approving an already approved decision returns it unchanged, so retries never execute a
+// decision twice.
+func (d *DecisionEngine) ApproveDecision(ctx context.Context, decisionID string, userID uuid.UUID, note string) (*DecisionRecord, error) {
+	return d.ApproveDecisionWithQuantity(ctx, decisionID, userID, note, decimal.Zero)
+}
+
+// ApproveDecisionWithQuantity approves a decision like ApproveDecision, executing quantity
+// instead of the recommended quantity when it is positive. Whether the user kept the
+// recommended size is recorded so later recommendations adapt to it.
+func (d *DecisionEngine) ApproveDecisionWithQuantity(ctx context.Context, decisionID string, userID uuid.UUID, note string, quantity decimal.Decimal) (*DecisionRecord, error) {
+	if quantity.IsNegative() {
+		return nil, fmt.Errorf("%w: quantity must not be negative", ErrInvalidApprovalQuantity)
+	}
+
+	d.mu.Lock()
+	index, err := d.findDecisionLocked(decisionID, userID)
+	if err != nil {
+		d.mu.Unlock()
+		return nil, err
+	}
+	record := d.decisionHistory[index]
+
+	switch record.Approval.Status {
+	case DecisionApprovalApproved:
+		d.mu.Unlock()
+		return &record, nil
+	case DecisionApprovalRejected:
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: decision %s was rejected", ErrDecisionAlreadyReviewed, decisionID)
+	}
+	if record.Execution != nil && record.Execution.Status == DecisionExecutionRunning {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDecisionExecuting, decisionID)
+	}
+
+	if record.Result == nil || record.Result.Recommendation == nil {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDecisionNotApprovable, decisionID)
+	}
+	if expiresAt := decisionExpiry(record); !expiresAt.IsZero() && time.Now().After(expiresAt) {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: decision %s expired at %s", ErrDecisionExpired, decisionID, expiresAt.Format(time.RFC3339))
+	}
+	executor := d.executor
+	if executor == nil {
+		d.mu.Unlock()
+		return nil, ErrDecisionExecutorUnavailable
+	}
+
+	if quantity.IsPositive() && !quantity.Equal(record.Result.Recommendation.Quantity) {
+		result := *record.Result
+		recommendation := *result.Recommendation
+		recommendation.Quantity = quantity
+		result.Recommendation = &recommendation
+		record.Result = &result
+	}
+
+	// The running execution blocks concurrent approvals until the outcome is known
+	now := time.Now()
+	record.Execution = &ExecutionRecord{Status: DecisionExecutionRunning}
+	record.ExecutedAt = now
+	d.decisionHistory[index] = record
+	d.mu.Unlock()
+
+	// Executors see the approval they act on; it is only recorded if execution succeeds
+	review := DecisionApproval{
+		Status:     DecisionApprovalApproved,
+		ReviewedBy: userID,
+		ReviewedAt: now,
+		Note:       note,
+	}
+	executing := record
+	executing.Approval = &review
+	execution, err := executor.ExecuteDecision(ctx, executing)
+	if execution == nil {
+		execution = &ExecutionRecord{Status: DecisionExecutionCompleted}
+	}
+	if err != nil {
+		d.logger.Error(ctx, "Decision execution failed", err, map[string]interface{}{
+			"decision_id": decisionID,
+		})
+		execution.Status = DecisionExecutionFailed
+		execution.Errors = append(execution.Errors, ExecutionError{
+			Error:     err.Error(),
+			Severity:  "high",
+			Timestamp: time.Now(),
+		})
+		review.Status = DecisionApprovalExecutionFailed
+	}
+	if execution.ExecutionTime == 0 {
+		execution.ExecutionTime = time.Since(now)
+	}
+
+	d.mu.Lock()
+	if index, findErr := d.findDecisionLocked(decisionID, userID); findErr == nil {
+		approval := review
+		d.decisionHistory[index].Approval = &approval
+		d.decisionHistory[index].Execution = execution
+		if review.Status == DecisionApprovalApproved {
+			d.decisionHistory[index].CompletedAt = time.Now()
+		}
+		record = d.decisionHistory[index]
+	}
+	d.mu.Unlock()
+
+	d.performanceTracker.RecordExecution(record)
+	if review.Status != DecisionApprovalApproved {
+		return &record, nil
+	}
+
+	d.logger.Info(ctx, "Decision approved", map[string]interface{}{
+		"decision_id": decisionID,
+		"user_id":     userID.String(),
+		"action":      record.Result.Recommendation.Action,
+		"asset":       record.Result.Recommendation.Asset,
+		"quantity":    record.Result.Recommendation.Quantity.String(),
+	})
+
+	d.recordSizingOutcome(ctx, record)
+
+	return &record, nil
+}
+
+// RejectDecision rejects a pending decision, or one whose execution failed, so it can no longer
+// be executed. Rejecting an already rejected decision returns it unchanged.
+func (d *DecisionEngine) RejectDecision(ctx context.Context, decisionID string, userID uuid.UUID, reason string) (*DecisionRecord, error) {
+	d.mu.Lock()
+	index, err := d.findDecisionLocked(decisionID, userID)
+	if err != nil {
+		d.mu.Unlock()
+		return nil, err
+	}
+	record := d.decisionHistory[index]
+
+	switch record.Approval.Status {
+	case DecisionApprovalRejected:
+		d.mu.Unlock()
+		return &record, nil
+	case DecisionApprovalApproved:
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: decision %s was approved", ErrDecisionAlreadyReviewed, decisionID)
+	}
+	if record.Execution != nil && record.Execution.Status == DecisionExecutionRunning {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDecisionExecuting, decisionID)
+	}
+
+	record.Approval = &DecisionApproval{
+		Status:     DecisionApprovalRejected,
+		ReviewedBy: userID,
+		ReviewedAt: time.Now(),
+		Note:       reason,
+	}
+	d.decisionHistory[index] = record
+	d.mu.Unlock()
+
+	d.performanceTracker.RecordRejection(record)
+
+	d.logger.Info(ctx, "Decision rejected", map[string]interface{}{
+		"decision_id": decisionID,
+		"user_id":     userID.String(),
+	})
+
+	return &record, nil
+}
+
+// findDecisionLocked returns the history index of a user's decision. The caller must hold d.mu.
+func (d *DecisionEngine) findDecisionLocked(decisionID string, userID uuid.UUID) (int, error) {
+	for i := range d.decisionHistory {
+		record := &d.decisionHistory[i]
+		if record.DecisionID != decisionID {
+			continue
+		}
+		// Decisions of other users are reported as missing rather than forbidden
+		if record.UserID != userID {
+			break
+		}
+		if record.Approval == nil {
+			record.Approval = &DecisionApproval{Status: DecisionApprovalPending}
+		}
+		return i, nil
+	}
+	return -1, fmt.Errorf("%w: %s", ErrDecisionNotFound, decisionID)
+}
+
+// decisionExpiry returns the earlier of the request and result expiry
+func decisionExpiry(record DecisionRecord) time.Time {
+	var expiresAt time.Time
+	if record.Result != nil {
+		expiresAt = record.Result.ExpiresAt
+	}
+	if record.Request != nil && !record.Request.ExpiresAt.IsZero() &&
+		(expiresAt.IsZero() || record.Request.ExpiresAt.Before(expiresAt)) {
+		expiresAt = record.Request.ExpiresAt
+	}
+	return expiresAt
+}
+
+// DecisionExecutionEvent is published for each approved decision by the Redis executor
+type DecisionExecutionEvent struct {
+	ExecutionID    string                 `json:"execution_id"`
+	DecisionID     string                 `json:"decision_id"`
+	UserID         uuid.UUID              `json:"user_id"`
+	DecisionType   string                 `json:"decision_type"`
+	Recommendation DecisionRecommendation `json:"recommendation"`
+	ExecutionPlan  *ExecutionPlan         `json:"execution_plan,omitempty"`
+	ApprovedBy     uuid.UUID              `json:"approved_by"`
+	ApprovedAt     time.Time              `json:"approved_at"`
+}
+
+// redisDecisionExecutor hands approved decisions to trading services over Redis pub/sub
+type redisDecisionExecutor struct {
+	redis   *database.RedisClient
+	channel string
+}
+
+// NewRedisDecisionExecutor creates an executor that publishes a DecisionExecutionEvent on a
+// Redis channel for each approved decision. Executions are reported as submitted once at
+// least one subscriber received the event.
+func NewRedisDecisionExecutor(redis *database.RedisClient, channel string) DecisionExecutor {
+	if channel == "" {
+		channel = "ai:decisions:execute"
+	}
+	return &redisDecisionExecutor{redis: redis, channel: channel}
+}
+
+func (e *redisDecisionExecutor) ExecuteDecision(ctx context.Context, record DecisionRecord) (*ExecutionRecord, error) {
+	event := DecisionExecutionEvent{
+		ExecutionID:    uuid.New().String(),
+		DecisionID:     record.DecisionID,
+		UserID:         record.UserID,
+		DecisionType:   record.DecisionType,
+		Recommendation: *record.Result.Recommendation,
+		ExecutionPlan:  record.Result.ExecutionPlan,
+	}
+	if record.Approval != nil {
+		event.ApprovedBy = record.Approval.ReviewedBy
+		event.ApprovedAt = record.Approval.ReviewedAt
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	execution := &ExecutionRecord{
+		ExecutionID: event.ExecutionID,
+		Metadata:    map[string]interface{}{"channel": e.channel},
+	}
+	receivers, err := e.redis.Client.Publish(ctx, e.channel, payload).Result()
+	if err != nil {
+		return execution, fmt.Errorf("failed to publish execution event: %w", err)
+	}
+	if receivers == 0 {
+		return execution, fmt.Errorf("no execution service is subscribed to %s", e.channel)
+	}
+
+	execution.Status = DecisionExecutionSubmitted
+	execution.Metadata["receivers"] = receivers
+	return execution, nil
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/web3"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -28,6 +29,13 @@ type DecisionEngine struct {
 	performanceTracker *DecisionPerformanceTracker
 	mu                 sync.RWMutex
 	lastUpdate         time.Time
+
+	// executor carries out approved decisions
+	executor DecisionExecutor
+
+	// positionSizer sizes trade recommendations; sizingLearner adapts sizes to the user
+	positionSizer PositionSizer
+	sizingLearner *LearningEngine
 }
 
 // DecisionEngineConfig holds configuration for the decision engine
@@ -143,6 +151,9 @@ type DecisionRecommendation struct {
 	Dependencies   []string               `json:"dependencies,omitempty"`
 	Conditions     []ExecutionCondition   `json:"conditions,omitempty"`
 	Metadata       map[string]interface{} `json:"metadata"`
+
+	// Sizing explains how Quantity was derived for trade decisions
+	Sizing *web3.PositionSizeRecommendation `json:"sizing,omitempty"`
 }
 
 // DecisionReasoning explains the reasoning behind a decision
@@ -381,6 +392,9 @@ type DecisionRecord struct {
 	ExecutedAt   time.Time              `json:"executed_at,omitempty"`
 	CompletedAt  time.Time              `json:"completed_at,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata"`
+
+	// Approval is the review of the recommendation; Execution records its outcome once approved
+	Approval *DecisionApproval `json:"approval,omitempty"`
 }
 
 // ExecutionRecord represents execution details
@@ -457,8 +471,10 @@ type DecisionPerformanceTracker struct {
 // OverallPerformanceMetrics represents overall performance metrics
 type OverallPerformanceMetrics struct {
 	TotalDecisions      int                `json:"total_decisions"`
-	SuccessfulDecisions int                `json:"successful_decisions"`
-	SuccessRate         float64            `json:"success_rate"`
+	ExecutedDecisions   int                `json:"executed_decisions"`
+	RejectedDecisions   int                `json:"rejected_decisions"`
+	SuccessfulDecisions int                `json:"successful_decisions"` // executions that did not fail
+	SuccessRate         float64            `json:"success_rate"`         // successful / executed
 	AverageROI          float64            `json:"average_roi"`
 	AverageAccuracy     float64            `json:"average_accuracy"`
 	TotalValue          decimal.Decimal    `json:"total_value"`
@@ -669,6 +685,7 @@ func NewDecisionEngine(logger *observability.Logger) *DecisionEngine {
 		decisionHistory:    []DecisionRecord{},
 		performanceTracker: NewDecisionPerformanceTracker(),
 		lastUpdate:         time.Now(),
+		positionSizer:      web3.NewRiskAssessmentService(nil, logger),
 	}
 
 	// Initialize default decision trees and strategies
@@ -837,6 +854,14 @@ func (d *DecisionEngine) processSimpleDecision(ctx context.Context, activeDecisi
 		return nil, fmt.Errorf("recommendation generation failed: %w", err)
 	}
 
+	// Size the primary trade from the portfolio and risk constraints
+	metadata := make(map[string]interface{})
+	if req.DecisionType == "trade" {
+		if err := d.sizeRecommendation(ctx, req, &recommendations[0], marketAnalysis); err != nil {
+			metadata["position_sizing_error"] = err.Error()
+		}
+	}
+
 	// Update progress
 	d.updateActiveDecisionProgress(activeDecision.DecisionID, 0.8, "creating_execution_plan")
 
@@ -865,7 +890,7 @@ func (d *DecisionEngine) processSimpleDecision(ctx context.Context, activeDecisi
 		RequiresApproval: d.requiresApproval(recommendations[0], riskAssessment),
 		AutoExecutable:   d.isAutoExecutable(recommendations[0], riskAssessment, req),
 		ExpiresAt:        time.Now().Add(24 * time.Hour), // Default 24 hour expiry
-		Metadata:         make(map[string]interface{}),
+		Metadata:         metadata,
 	}
 
 	return result, nil
@@ -878,8 +903,6 @@ func (d *DecisionEngine) processComplexDecision(ctx context.Context, activeDecis
 	result, err := d.processSimpleDecision(ctx, activeDecision)
 
 	d.mu.Lock()
-	defer d.mu.Unlock()
-
 	if err != nil {
 		activeDecision.Status = "failed"
 		activeDecision.Error = err.Error()
@@ -891,12 +914,15 @@ func (d *DecisionEngine) processComplexDecision(ctx context.Context, activeDecis
 
 	activeDecision.CompletedAt = time.Now()
 	activeDecision.UpdatedAt = time.Now()
+	d.mu.Unlock()
 
-	// Record decision
+	// Record decision; recordDecision takes the lock itself
 	d.recordDecision(activeDecision)
 
 	// Clean up
+	d.mu.Lock()
 	delete(d.activeDecisions, activeDecision.DecisionID)
+	d.mu.Unlock()
 }
 
 func (d *DecisionEngine) createPendingResult(decisionID string, req *DecisionRequest) *DecisionResult {
@@ -1093,6 +1119,9 @@ func (d *DecisionEngine) recordDecision(activeDecision *ActiveDecision) {
 	if activeDecision.Status == "completed" {
 		record.CompletedAt = activeDecision.CompletedAt
 	}
+	if record.Result != nil && record.Result.Recommendation != nil {
+		record.Approval = &DecisionApproval{Status: DecisionApprovalPending}
+	}
 
 	d.mu.Lock()
 	d.decisionHistory = append(d.decisionHistory, record)
@@ -1225,12 +1254,8 @@ func (dpt *DecisionPerformanceTracker) RecordDecision(record DecisionRecord) {
 	dpt.mu.Lock()
 	defer dpt.mu.Unlock()
 
-	// Update overall metrics
+	// Update overall metrics. Success is only known once a decision is executed.
 	dpt.overallMetrics.TotalDecisions++
-	if record.Result != nil && record.Result.Confidence > 0.7 {
-		dpt.overallMetrics.SuccessfulDecisions++
-	}
-	dpt.overallMetrics.SuccessRate = float64(dpt.overallMetrics.SuccessfulDecisions) / float64(dpt.overallMetrics.TotalDecisions)
 	dpt.overallMetrics.LastUpdated = time.Now()
 
 	// Update user metrics
@@ -1244,6 +1269,42 @@ func (dpt *DecisionPerformanceTracker) RecordDecision(record DecisionRecord) {
 	userMetrics.LastActive = time.Now()
 }
 
+// RecordExecution records the outcome of an approved decision
+func (dpt *DecisionPerformanceTracker) RecordExecution(record DecisionRecord) {
+	if record.Execution == nil {
+		return
+	}
+
+	dpt.mu.Lock()
+	defer dpt.mu.Unlock()
+
+	dpt.overallMetrics.ExecutedDecisions++
+	if record.Execution.Status != DecisionExecutionFailed {
+		dpt.overallMetrics.SuccessfulDecisions++
+	}
+	dpt.overallMetrics.SuccessRate = float64(dpt.overallMetrics.SuccessfulDecisions) / float64(dpt.overallMetrics.ExecutedDecisions)
+	dpt.overallMetrics.LastUpdated = time.Now()
+
+	dpt.timeSeriesData = append(dpt.timeSeriesData, PerformanceDataPoint{
+		Timestamp: time.Now(),
+		Metric:    "execution_success_rate",
+		Value:     dpt.overallMetrics.SuccessRate,
+		Context: map[string]interface{}{
+			"decision_id": record.DecisionID,
+			"status":      record.Execution.Status,
+		},
+	})
+}
+
+// RecordRejection records a decision rejected during review
+func (dpt *DecisionPerformanceTracker) RecordRejection(record DecisionRecord) {
+	dpt.mu.Lock()
+	defer dpt.mu.Unlock()
+
+	dpt.overallMetrics.RejectedDecisions++
+	dpt.overallMetrics.LastUpdated = time.Now()
+}
+
 // GetOverallMetrics returns overall performance metrics
 func (dpt *DecisionPerformanceTracker) GetOverallMetrics() *OverallPerformanceMetrics {
 	dpt.mu.RLock()
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -549,8 +550,8 @@ func TestDecisionPerformanceTracker(t *testing.T) {
 		// Check overall metrics
 		metrics := tracker.GetOverallMetrics()
 		assert.Equal(t, 1, metrics.TotalDecisions)
-		assert.Equal(t, 1, metrics.SuccessfulDecisions) // High confidence counts as success
-		assert.Equal(t, 1.0, metrics.SuccessRate)
+		assert.Equal(t, 0, metrics.SuccessfulDecisions) // Recommendations alone are not successes
+		assert.Equal(t, 0.0, metrics.SuccessRate)
 		assert.False(t, metrics.LastUpdated.IsZero())
 
 		// Record a low confidence decision
@@ -573,7 +574,322 @@ func TestDecisionPerformanceTracker(t *testing.T) {
 		// Check updated metrics
 		metrics = tracker.GetOverallMetrics()
 		assert.Equal(t, 2, metrics.TotalDecisions)
-		assert.Equal(t, 1, metrics.SuccessfulDecisions) // Only high confidence counts
+		assert.Equal(t, 0, metrics.SuccessfulDecisions)
+		assert.Equal(t, 0.0, metrics.SuccessRate)
+	})
+
+	t.Run("RecordExecution", func(t *testing.T) {
+		tracker.RecordExecution(DecisionRecord{
+			DecisionID: uuid.New().String(),
+			Execution:  &ExecutionRecord{Status: DecisionExecutionCompleted},
+		})
+		tracker.RecordExecution(DecisionRecord{
+			DecisionID: uuid.New().String(),
+			Execution:  &ExecutionRecord{Status: DecisionExecutionFailed},
+		})
+
+		// Success rate reflects executions only
+		metrics := tracker.GetOverallMetrics()
+		assert.Equal(t, 2, metrics.TotalDecisions)
+		assert.Equal(t, 2, metrics.ExecutedDecisions)
+		assert.Equal(t, 1, metrics.SuccessfulDecisions)
 		assert.Equal(t, 0.5, metrics.SuccessRate)
 	})
 }
+
+type recordingDecisionExecutor struct {
+	executed []DecisionRecord
+	err      error
+}
+
+func (e *recordingDecisionExecutor) ExecuteDecision(ctx context.Context, record DecisionRecord) (*ExecutionRecord, error) {
+	e.executed = append(e.executed, record)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &ExecutionRecord{ExecutionID: uuid.New().String(), Status: DecisionExecutionCompleted}, nil
+}
+
+// blockingDecisionExecutor holds an execution until released
+type blockingDecisionExecutor struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingDecisionExecutor) ExecuteDecision(ctx context.Context, record DecisionRecord) (*ExecutionRecord, error) {
+	close(e.started)
+	<-e.release
+	return &ExecutionRecord{Status: DecisionExecutionCompleted}, nil
+}
+
+func TestDecisionApproval(t *testing.T) {
+	ctx := context.Background()
+	logger := &observability.Logger{}
+
+	newDecision := func(t *testing.T, engine *DecisionEngine, userID uuid.UUID) string {
+		result, err := engine.ProcessDecisionRequest(ctx, &DecisionRequest{
+			RequestID:    uuid.New().String(),
+			UserID:       userID,
+			DecisionType: "trade",
+			Context:      &DecisionContext{MarketConditions: "bullish"},
+			RequestedAt:  time.Now(),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		return result.DecisionID
+	}
+
+	t.Run("ApproveExecutesOnce", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		executor := &recordingDecisionExecutor{}
+		engine.SetExecutor(executor)
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		history := engine.GetDecisionHistory(userID, 1)
+		require.Len(t, history, 1)
+		require.NotNil(t, history[0].Approval)
+		assert.Equal(t, DecisionApprovalPending, history[0].Approval.Status)
+
+		record, err := engine.ApproveDecision(ctx, decisionID, userID, "looks good")
+		require.NoError(t, err)
+		assert.Equal(t, DecisionApprovalApproved, record.Approval.Status)
+		assert.Equal(t, userID, record.Approval.ReviewedBy)
+		require.NotNil(t, record.Execution)
+		assert.Equal(t, DecisionExecutionCompleted, record.Execution.Status)
+
+		// Approving again is idempotent
+		again, err := engine.ApproveDecision(ctx, decisionID, userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, record.Execution.ExecutionID, again.Execution.ExecutionID)
+		assert.Len(t, executor.executed, 1)
+
+		// History carries the outcome and metrics count the execution
+		history = engine.GetDecisionHistory(userID, 1)
+		require.NotNil(t, history[0].Execution)
+		assert.Equal(t, DecisionExecutionCompleted, history[0].Execution.Status)
+		metrics := engine.GetPerformanceMetrics()
+		assert.Equal(t, 1, metrics.ExecutedDecisions)
+		assert.Equal(t, 1.0, metrics.SuccessRate)
+
+		_, err = engine.RejectDecision(ctx, decisionID, userID, "changed my mind")
+		assert.ErrorIs(t, err, ErrDecisionAlreadyReviewed)
+	})
+
+	t.Run("FailedExecutionCanBeRetried", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		executor := &recordingDecisionExecutor{err: errors.New("exchange unavailable")}
+		engine.SetExecutor(executor)
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		record, err := engine.ApproveDecision(ctx, decisionID, userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, DecisionApprovalExecutionFailed, record.Approval.Status)
+		assert.Equal(t, DecisionExecutionFailed, record.Execution.Status)
+		require.Len(t, record.Execution.Errors, 1)
+		assert.Equal(t, 0.0, engine.GetPerformanceMetrics().SuccessRate)
+
+		// Approving again retries the execution
+		executor.err = nil
+		record, err = engine.ApproveDecision(ctx, decisionID, userID, "retry")
+		require.NoError(t, err)
+		assert.Equal(t, DecisionApprovalApproved, record.Approval.Status)
+		assert.Equal(t, "retry", record.Approval.Note)
+		assert.Equal(t, DecisionExecutionCompleted, record.Execution.Status)
+		assert.Len(t, executor.executed, 2)
+		assert.Equal(t, DecisionApprovalApproved, executor.executed[1].Approval.Status)
+	})
+
+	t.Run("FailedExecutionCanBeRejected", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		engine.SetExecutor(&recordingDecisionExecutor{err: errors.New("exchange unavailable")})
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		_, err := engine.ApproveDecision(ctx, decisionID, userID, "")
+		require.NoError(t, err)
+		record, err := engine.RejectDecision(ctx, decisionID, userID, "gave up")
+		require.NoError(t, err)
+		assert.Equal(t, DecisionApprovalRejected, record.Approval.Status)
+	})
+
+	t.Run("ConcurrentApprovalWaitsForExecution", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		executor := &blockingDecisionExecutor{started: make(chan struct{}), release: make(chan struct{})}
+		engine.SetExecutor(executor)
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		done := make(chan *DecisionRecord)
+		go func() {
+			record, _ := engine.ApproveDecision(ctx, decisionID, userID, "")
+			done <- record
+		}()
+		<-executor.started
+
+		history := engine.GetDecisionHistory(userID, 1)
+		assert.Equal(t, DecisionApprovalPending, history[0].Approval.Status)
+		_, err := engine.ApproveDecision(ctx, decisionID, userID, "")
+		assert.ErrorIs(t, err, ErrDecisionExecuting)
+		_, err = engine.RejectDecision(ctx, decisionID, userID, "")
+		assert.ErrorIs(t, err, ErrDecisionExecuting)
+
+		close(executor.release)
+		record := <-done
+		require.NotNil(t, record)
+		assert.Equal(t, DecisionApprovalApproved, record.Approval.Status)
+	})
+
+	t.Run("RejectPreventsExecution", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		executor := &recordingDecisionExecutor{}
+		engine.SetExecutor(executor)
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		record, err := engine.RejectDecision(ctx, decisionID, userID, "too risky")
+		require.NoError(t, err)
+		assert.Equal(t, DecisionApprovalRejected, record.Approval.Status)
+		assert.Equal(t, "too risky", record.Approval.Note)
+
+		_, err = engine.RejectDecision(ctx, decisionID, userID, "")
+		assert.NoError(t, err)
+
+		_, err = engine.ApproveDecision(ctx, decisionID, userID, "")
+		assert.ErrorIs(t, err, ErrDecisionAlreadyReviewed)
+		assert.Empty(t, executor.executed)
+	})
+
+	t.Run("ExpiredDecision", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		engine.SetExecutor(&recordingDecisionExecutor{})
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		engine.mu.Lock()
+		engine.decisionHistory[0].Result.ExpiresAt = time.Now().Add(-time.Minute)
+		engine.mu.Unlock()
+
+		_, err := engine.ApproveDecision(ctx, decisionID, userID, "")
+		assert.ErrorIs(t, err, ErrDecisionExpired)
+	})
+
+	t.Run("OtherUsersDecisionsAreHidden", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		engine.SetExecutor(&recordingDecisionExecutor{})
+		decisionID := newDecision(t, engine, uuid.New())
+
+		_, err := engine.ApproveDecision(ctx, decisionID, uuid.New(), "")
+		assert.ErrorIs(t, err, ErrDecisionNotFound)
+	})
+
+	t.Run("ExecutorRequired", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		userID := uuid.New()
+		decisionID := newDecision(t, engine, userID)
+
+		_, err := engine.ApproveDecision(ctx, decisionID, userID, "")
+		assert.ErrorIs(t, err, ErrDecisionExecutorUnavailable)
+
+		history := engine.GetDecisionHistory(userID, 1)
+		assert.Equal(t, DecisionApprovalPending, history[0].Approval.Status)
+	})
+}
+
+func TestDecisionPositionSizing(t *testing.T) {
+	ctx := context.Background()
+	logger := &observability.Logger{}
+
+	tradeRequest := func(userID uuid.UUID, volatility float64, maxPosition decimal.Decimal) *DecisionRequest {
+		return &DecisionRequest{
+			RequestID:    uuid.New().String(),
+			UserID:       userID,
+			DecisionType: "trade",
+			Context:      &DecisionContext{MarketConditions: "bullish"},
+			Constraints: &DecisionConstraints{
+				MaxPositionSize: maxPosition,
+				MaxRiskExposure: 0.05,
+			},
+			Preferences: &UserDecisionPrefs{RiskTolerance: 0.6},
+			MarketData: &MarketDataSnapshot{
+				Prices:     map[string]decimal.Decimal{"BTC": decimal.NewFromInt(50000)},
+				Volatility: map[string]float64{"BTC": volatility},
+			},
+			PortfolioState: &PortfolioSnapshot{
+				TotalValue: decimal.NewFromInt(100000),
+				Cash:       decimal.NewFromInt(50000),
+			},
+			RequestedAt: time.Now(),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+	}
+
+	t.Run("SizesFromConstraints", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		result, err := engine.ProcessDecisionRequest(ctx, tradeRequest(uuid.New(), 0.3, decimal.NewFromInt(1000)))
+		require.NoError(t, err)
+
+		recommendation := result.Recommendation
+		require.NotNil(t, recommendation.Sizing)
+		assert.True(t, decimal.NewFromFloat(0.02).Equal(recommendation.Quantity), recommendation.Quantity.String())
+		assert.True(t, decimal.NewFromInt(1000).Equal(recommendation.Sizing.Notional))
+		assert.Equal(t, 1.0, recommendation.Sizing.PortfolioPercent)
+		assert.Equal(t, "max_position_size", recommendation.Sizing.LimitedBy)
+		assert.Equal(t, 0.3, recommendation.Sizing.Inputs.Volatility)
+		assert.Equal(t, 0.6, recommendation.Sizing.Inputs.RiskTolerance)
+		assert.Equal(t, recommendation.Quantity, result.ExecutionPlan.Steps[0].Parameters["quantity"])
+		assert.NotContains(t, result.Metadata, "position_sizing_error")
+	})
+
+	t.Run("KeepsQuantityWithoutPortfolio", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		req := tradeRequest(uuid.New(), 0.3, decimal.Zero)
+		req.PortfolioState = nil
+
+		result, err := engine.ProcessDecisionRequest(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, result.Recommendation.Sizing)
+		assert.True(t, result.Recommendation.Quantity.IsPositive())
+		assert.Contains(t, result.Metadata["position_sizing_error"], "portfolio value is required")
+	})
+
+	t.Run("AdaptsToFollowedSizes", func(t *testing.T) {
+		engine := NewDecisionEngine(logger)
+		learner := NewLearningEngine(logger)
+		engine.SetSizingLearner(learner)
+		engine.SetExecutor(&recordingDecisionExecutor{})
+		userID := uuid.New()
+
+		// High volatility keeps the size within the concentration limit
+		first, err := engine.ProcessDecisionRequest(ctx, tradeRequest(userID, 1.5, decimal.Zero))
+		require.NoError(t, err)
+		require.NotNil(t, first.Recommendation.Sizing)
+		assert.Equal(t, "risk_budget", first.Recommendation.Sizing.LimitedBy)
+
+		_, err = engine.ApproveDecisionWithQuantity(ctx, first.DecisionID, userID, "", decimal.NewFromInt(-1))
+		assert.ErrorIs(t, err, ErrInvalidApprovalQuantity)
+
+		half := first.Recommendation.Quantity.Div(decimal.NewFromInt(2))
+		record, err := engine.ApproveDecisionWithQuantity(ctx, first.DecisionID, userID, "smaller please", half)
+		require.NoError(t, err)
+		assert.True(t, half.Equal(record.Result.Recommendation.Quantity))
+		assert.True(t, first.Recommendation.Quantity.Equal(record.Result.Recommendation.Sizing.Quantity))
+
+		profile, err := learner.GetUserProfile(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, profile.TradingPatterns.PositionSizing.RecommendationsSized)
+		assert.Equal(t, 0, profile.TradingPatterns.PositionSizing.RecommendationsFollowed)
+		assert.InDelta(t, 0.9, learner.PositionSizeAdjustment(userID), 0.001)
+
+		second, err := engine.ProcessDecisionRequest(ctx, tradeRequest(userID, 1.5, decimal.Zero))
+		require.NoError(t, err)
+		assert.Equal(t, 0.9, second.Recommendation.Sizing.Inputs.SizeAdjustment)
+		assert.True(t, second.Recommendation.Quantity.LessThan(first.Recommendation.Quantity))
+
+		_, err = engine.ApproveDecision(ctx, second.DecisionID, userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, profile.TradingPatterns.PositionSizing.RecommendationsFollowed)
+		assert.InDelta(t, 0.9, learner.PositionSizeAdjustment(userID), 0.001)
+	})
+}
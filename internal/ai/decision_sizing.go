@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+)
+
+// ErrNoPositionSizer is returned when trade decisions cannot be sized
+var ErrNoPositionSizer = errors.New("position sizing is not configured")
+
+// PositionSizer recommends how much of an asset to trade. It is implemented by
+// web3.RiskAssessmentService.
+type PositionSizer interface {
+	RecommendPositionSize(ctx context.Context, req web3.PositionSizeRequest) (*web3.PositionSizeRecommendation, error)
+}
+
+// SetPositionSizer sets the service used to size trade recommendations
+func (d *DecisionEngine) SetPositionSizer(sizer PositionSizer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.positionSizer = sizer
+}
+
+// SetSizingLearner sets the learning engine that records whether users follow recommended
+// sizes and adapts future recommendations to them
+func (d *DecisionEngine) SetSizingLearner(learner *LearningEngine) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sizingLearner = learner
+}
+
+// sizeRecommendation replaces the quantity of a trade recommendation with one sized from the
+// portfolio, the request's constraints, the asset's volatility and the user's risk tolerance.
+// The recommendation keeps its quantity when it cannot be sized.
+func (d *DecisionEngine) sizeRecommendation(ctx context.Context, req *DecisionRequest, recommendation *DecisionRecommendation, marketAnalysis map[string]interface{}) error {
+	d.mu.RLock()
+	sizer, learner := d.positionSizer, d.sizingLearner
+	d.mu.RUnlock()
+	if sizer == nil {
+		return ErrNoPositionSizer
+	}
+
+	sizeReq := web3.PositionSizeRequest{
+		Asset:          recommendation.Asset,
+		Price:          recommendation.Price,
+		RiskTolerance:  0.5,
+		SizeAdjustment: 1,
+	}
+	if req.MarketData != nil {
+		if price, exists := req.MarketData.Prices[recommendation.Asset]; exists && !sizeReq.Price.IsPositive() {
+			sizeReq.Price = price
+		}
+		sizeReq.Volatility = req.MarketData.Volatility[recommendation.Asset]
+	}
+	if sizeReq.Volatility == 0 {
+		sizeReq.Volatility, _ = marketAnalysis["volatility"].(float64)
+	}
+	if req.PortfolioState != nil {
+		sizeReq.PortfolioValue = req.PortfolioState.TotalValue
+		sizeReq.AvailableCash = req.PortfolioState.Cash
+	}
+	if req.Constraints != nil {
+		sizeReq.MaxPositionSize = req.Constraints.MaxPositionSize
+		sizeReq.MaxRiskExposure = req.Constraints.MaxRiskExposure
+	}
+	if recommendation.StopLoss.IsPositive() && sizeReq.Price.IsPositive() {
+		sizeReq.StopDistance = sizeReq.Price.Sub(recommendation.StopLoss).Abs().Div(sizeReq.Price).InexactFloat64()
+	}
+
+	// Explicit preferences win over the tolerance learned from the user's trades
+	if req.Preferences != nil && req.Preferences.RiskTolerance > 0 {
+		sizeReq.RiskTolerance = req.Preferences.RiskTolerance
+	} else if learner != nil {
+		if profile, err := learner.GetUserProfile(req.UserID); err == nil {
+			sizeReq.RiskTolerance = profile.RiskTolerance
+		}
+	}
+	if learner != nil {
+		sizeReq.SizeAdjustment = learner.PositionSizeAdjustment(req.UserID)
+	}
+
+	sizing, err := sizer.RecommendPositionSize(ctx, sizeReq)
+	if err != nil {
+		return fmt.Errorf("failed to size %s position: %w", recommendation.Asset, err)
+	}
+	if !sizing.Quantity.IsPositive() {
+		return fmt.Errorf("recommended %s position rounds to zero", recommendation.Asset)
+	}
+
+	recommendation.Quantity = sizing.Quantity
+	recommendation.Sizing = sizing
+	return nil
+}
+
+// recordSizingOutcome tells the learning engine whether the user traded the recommended size
+func (d *DecisionEngine) recordSizingOutcome(ctx context.Context, record DecisionRecord) {
+	d.mu.RLock()
+	learner := d.sizingLearner
+	d.mu.RUnlock()
+	if learner == nil || record.Result == nil || record.Result.Recommendation == nil {
+		return
+	}
+	recommendation := record.Result.Recommendation
+	if recommendation.Sizing == nil || !recommendation.Sizing.Quantity.IsPositive() {
+		return
+	}
+
+	recommended := recommendation.Sizing.Quantity.InexactFloat64()
+	executed := recommendation.Quantity.InexactFloat64()
+	outcome := "followed"
+	if !recommendation.Quantity.Equal(recommendation.Sizing.Quantity) {
+		outcome = "adjusted"
+	}
+
+	behavior := &UserBehaviorData{
+		Type:      "position_sizing",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"asset":                recommendation.Asset,
+			"recommended_quantity": recommended,
+			"executed_quantity":    executed,
+		},
+		Context: map[string]interface{}{
+			"decision_id": record.DecisionID,
+			"limited_by":  recommendation.Sizing.LimitedBy,
+		},
+		Outcome:     outcome,
+		Performance: executed / recommended,
+	}
+	if err := learner.LearnFromUserBehavior(ctx, record.UserID, behavior); err != nil {
+		d.logger.Warn(ctx, "Failed to record position sizing outcome", map[string]interface{}{
+			"decision_id": record.DecisionID,
+			"error":       err.Error(),
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// Document search errors
+var (
+	ErrDocumentNotFound       = errors.New("document not found")
+	ErrInvalidDocumentSearch  = errors.New("invalid document search")
+	errEmptyEmbeddingResponse = errors.New("empty embedding response")
+)
+
+const (
+	// defaultDocumentSearchLimit and maxDocumentSearchLimit bound the chunks a search returns
+	defaultDocumentSearchLimit = 5
+	maxDocumentSearchLimit     = 20
+	// maxDocumentChunks caps the chunks embedded per document; text past it isn't searchable
+	maxDocumentChunks = 500
+	// embeddingBatchSize is how many chunks are embedded per provider call
+	embeddingBatchSize = 64
+	// hashingEmbeddingDimensions is the vector size of the local hashing embedder
+	hashingEmbeddingDimensions = 256
+)
+
+// EmbeddingProvider turns texts into vectors whose cosine similarity reflects how related the
+// texts are
+type EmbeddingProvider interface {
+	// Name identifies the provider and model; vectors of different names are not comparable
+	Name() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbeddingProvider returns the OpenAI embedding provider when an API key is configured
+// and the local hashing embedder otherwise
+func NewEmbeddingProvider(cfg config.AIConfig) EmbeddingProvider {
+	if cfg.OpenAIKey == "" {
+		return NewHashingEmbeddingProvider(hashingEmbeddingDimensions)
+	}
+	return NewOpenAIEmbeddingProvider(cfg.OpenAIKey, cfg.EmbeddingModel)
+}
+
+// HashingEmbeddingProvider embeds texts locally by hashing their words into a fixed number of
+// dimensions. It only matches shared words, not meaning, but needs no external service.
+type HashingEmbeddingProvider struct {
+	dimensions int
+}
+
+// NewHashingEmbeddingProvider creates a hashing embedder with vectors of the given size
+func NewHashingEmbeddingProvider(dimensions int) *HashingEmbeddingProvider {
+	if dimensions <= 0 {
+		dimensions = hashingEmbeddingDimensions
+	}
+	return &HashingEmbeddingProvider{dimensions: dimensions}
+}
+
+// Name identifies the provider and vector size
+func (p *HashingEmbeddingProvider) Name() string {
+	return fmt.Sprintf("hashing:%d", p.dimensions)
+}
+
+// Embed hashes each lowercased word to a dimension and sign, and normalizes the result
+func (p *HashingEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, p.dimensions)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			sum := h.Sum32()
+			sign := float32(1)
+			if sum&(1<<31) != 0 {
+				sign = -1
+			}
+			vector[int(sum%uint32(p.dimensions))] += sign
+		}
+		vectors[i] = normalizeVector(vector)
+	}
+	return vectors, nil
+}
+
+// OpenAIEmbeddingProvider embeds texts with the OpenAI embeddings API
+type OpenAIEmbeddingProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAIEmbeddingProvider creates an OpenAI embedding provider. An empty model defaults to
+// text-embedding-3-small.
+func NewOpenAIEmbeddingProvider(apiKey, model string) *OpenAIEmbeddingProvider {
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	return &OpenAIEmbeddingProvider{
+		baseURL: "https://api.openai.com/v1",
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name identifies the provider and model
+func (p *OpenAIEmbeddingProvider) Name() string {
+	return "openai:" + p.model
+}
+
+// Embed requests the embeddings of all texts in one call
+func (p *OpenAIEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"model": p.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.baseURL, "/")+"/embeddings", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from embedding API", resp.StatusCode)
+	}
+
+	var embeddings struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &embeddings); err != nil {
+		return nil, fmt.Errorf("invalid embedding response: %w", err)
+	}
+	if len(embeddings.Data) != len(texts) {
+		return nil, errEmptyEmbeddingResponse
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range embeddings.Data {
+		if item.Index < 0 || item.Index >= len(texts) || len(item.Embedding) == 0 {
+			return nil, errEmptyEmbeddingResponse
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// StoredDocument is an analyzed document whose text is searchable
+type StoredDocument struct {
+	ID             uuid.UUID `json:"id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Filename       string    `json:"filename,omitempty"`
+	MimeType       string    `json:"mime_type,omitempty"`
+	SHA256         string    `json:"sha256,omitempty"`
+	ChunkCount     int       `json:"chunk_count"`
+	EmbeddingModel string    `json:"embedding_model"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// DocumentChunk is an embedded piece of a document's text
+type DocumentChunk struct {
+	Index     int
+	Content   string
+	Embedding []float32
+}
+
+// DocumentSearchResult is a chunk matching a search, with its document
+type DocumentSearchResult struct {
+	Document   StoredDocument `json:"document"`
+	ChunkIndex int            `json:"chunk_index"`
+	Content    string         `json:"content"`
+	Score      float64        `json:"score"` // cosine similarity to the query
+}
+
+// DocumentStore persists documents with their embedded chunks
+type DocumentStore interface {
+	SaveDocument(ctx context.Context, document *StoredDocument, chunks []DocumentChunk) error
+	// SearchChunks returns the user's chunks embedded by embeddingModel that are most similar
+	// to vector, within one document when documentID is set
+	SearchChunks(ctx context.Context, userID uuid.UUID, embeddingModel string, vector []float32, documentID *uuid.UUID, limit int) ([]DocumentSearchResult, error)
+	ListDocuments(ctx context.Context, userID uuid.UUID) ([]*StoredDocument, error)
+	// DeleteDocument removes a document and its chunks, or returns ErrDocumentNotFound
+	DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error
+}
+
+// DocumentIndex chunks and embeds the text of analyzed documents so users can search them later
+type DocumentIndex struct {
+	logger     *observability.Logger
+	provider   EmbeddingProvider
+	store      DocumentStore
+	chunkWords int
+	overlap    int
+}
+
+// NewDocumentIndex creates a document index embedding chunks of chunkWords words, each
+// repeating overlap words of the previous one
+func NewDocumentIndex(logger *observability.Logger, provider EmbeddingProvider, store DocumentStore, chunkWords, overlap int) *DocumentIndex {
+	if chunkWords <= 0 {
+		chunkWords = 200
+	}
+	if overlap < 0 || overlap >= chunkWords {
+		overlap = chunkWords / 5
+	}
+	return &DocumentIndex{
+		logger:     logger,
+		provider:   provider,
+		store:      store,
+		chunkWords: chunkWords,
+		overlap:    overlap,
+	}
+}
+
+// IndexDocument embeds a document's extracted text and stores it for the user
+func (d *DocumentIndex) IndexDocument(ctx context.Context, userID uuid.UUID, content MultiModalContent, text, sha256 string) (*StoredDocument, error) {
+	chunks := chunkDocumentText(text, d.chunkWords, d.overlap)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("document has no text to index")
+	}
+	if len(chunks) > maxDocumentChunks {
+		d.logger.Warn(ctx, "Document exceeds the chunk limit, indexing its beginning", map[string]interface{}{
+			"filename": content.Filename,
+			"chunks":   len(chunks),
+		})
+		chunks = chunks[:maxDocumentChunks]
+	}
+
+	embedded := make([]DocumentChunk, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embeddingBatchSize {
+		batch := chunks[start:min(start+embeddingBatchSize, len(chunks))]
+		vectors, err := d.provider.Embed(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed document: %w", err)
+		}
+		for i, vector := range vectors {
+			embedded = append(embedded, DocumentChunk{Index: start + i, Content: batch[i], Embedding: vector})
+		}
+	}
+
+	document := &StoredDocument{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Filename:       content.Filename,
+		MimeType:       content.MimeType,
+		SHA256:         sha256,
+		ChunkCount:     len(embedded),
+		EmbeddingModel: d.provider.Name(),
+		CreatedAt:      time.Now(),
+	}
+	if err := d.store.SaveDocument(ctx, document, embedded); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+	return document, nil
+}
+
+// Search returns the user's document chunks most relevant to a natural-language query, within
+// one document when documentID is set. Documents embedded by another provider are not searched.
+func (d *DocumentIndex) Search(ctx context.Context, userID uuid.UUID, query string, documentID *uuid.UUID, limit int) ([]DocumentSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is required", ErrInvalidDocumentSearch)
+	}
+	if limit <= 0 {
+		limit = defaultDocumentSearchLimit
+	}
+	if limit > maxDocumentSearchLimit {
+		limit = maxDocumentSearchLimit
+	}
+
+	vectors, err := d.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return d.store.SearchChunks(ctx, userID, d.provider.Name(), vectors[0], documentID, limit)
+}
+
+// ListDocuments returns the user's searchable documents, newest first
+func (d *DocumentIndex) ListDocuments(ctx context.Context, userID uuid.UUID) ([]*StoredDocument, error) {
+	return d.store.ListDocuments(ctx, userID)
+}
+
+// DeleteDocument removes a user's document along with its vectors
+func (d *DocumentIndex) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	return d.store.DeleteDocument(ctx, userID, documentID)
+}
+
+// chunkDocumentText splits text into chunks of size words, each starting with the last overlap
+// words of the previous chunk so that passages cut at a boundary stay findable
+func chunkDocumentText(text string, size, overlap int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	chunks := make([]string, 0, len(words)/(size-overlap)+1)
+	for start := 0; ; start += size - overlap {
+		end := min(start+size, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+	}
+	return chunks
+}
+
+// normalizeVector scales a vector to unit length, leaving zero vectors as they are
+func normalizeVector(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
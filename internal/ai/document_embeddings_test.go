@@ -0,0 +1,200 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryDocumentStore struct {
+	documents map[uuid.UUID]*StoredDocument
+	chunks    map[uuid.UUID][]DocumentChunk
+}
+
+func newMemoryDocumentStore() *memoryDocumentStore {
+	return &memoryDocumentStore{documents: make(map[uuid.UUID]*StoredDocument), chunks: make(map[uuid.UUID][]DocumentChunk)}
+}
+
+func (s *memoryDocumentStore) SaveDocument(ctx context.Context, document *StoredDocument, chunks []DocumentChunk) error {
+	s.documents[document.ID] = document
+	s.chunks[document.ID] = chunks
+	return nil
+}
+
+func (s *memoryDocumentStore) SearchChunks(ctx context.Context, userID uuid.UUID, embeddingModel string, vector []float32, documentID *uuid.UUID, limit int) ([]DocumentSearchResult, error) {
+	results := make([]DocumentSearchResult, 0)
+	for id, document := range s.documents {
+		if document.UserID != userID || document.EmbeddingModel != embeddingModel || (documentID != nil && *documentID != id) {
+			continue
+		}
+		for _, chunk := range s.chunks[id] {
+			results = append(results, DocumentSearchResult{
+				Document:   *document,
+				ChunkIndex: chunk.Index,
+				Content:    chunk.Content,
+				Score:      testCosineSimilarity(vector, chunk.Embedding),
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *memoryDocumentStore) ListDocuments(ctx context.Context, userID uuid.UUID) ([]*StoredDocument, error) {
+	documents := make([]*StoredDocument, 0)
+	for _, document := range s.documents {
+		if document.UserID == userID {
+			documents = append(documents, document)
+		}
+	}
+	return documents, nil
+}
+
+func (s *memoryDocumentStore) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	document, ok := s.documents[documentID]
+	if !ok || document.UserID != userID {
+		return ErrDocumentNotFound
+	}
+	delete(s.documents, documentID)
+	delete(s.chunks, documentID)
+	return nil
+}
+
+func testCosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+type failingEmbeddingProvider struct{}
+
+func (failingEmbeddingProvider) Name() string { return "failing" }
+
+func (failingEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("embedding service down")
+}
+
+func TestChunkDocumentText(t *testing.T) {
+	text := strings.Join([]string{"a", "b", "c", "d", "e", "f", "g"}, "  \n")
+
+	chunks := chunkDocumentText(text, 3, 1)
+	assert.Equal(t, []string{"a b c", "c d e", "e f g"}, chunks)
+
+	assert.Equal(t, []string{"a b c d e f g"}, chunkDocumentText(text, 10, 2))
+	assert.Empty(t, chunkDocumentText("   ", 10, 2))
+}
+
+func TestHashingEmbeddingProvider(t *testing.T) {
+	provider := NewHashingEmbeddingProvider(64)
+	vectors, err := provider.Embed(context.Background(), []string{"Bitcoin halving", "bitcoin HALVING!", "", "staking yields"})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+
+	assert.Len(t, vectors[0], 64)
+	assert.InDelta(t, 1, testCosineSimilarity(vectors[0], vectors[1]), 1e-6, "case and punctuation don't matter")
+	assert.Zero(t, testCosineSimilarity(vectors[0], vectors[2]))
+	assert.Less(t, testCosineSimilarity(vectors[0], vectors[3]), 0.9)
+	assert.Equal(t, "hashing:64", provider.Name())
+}
+
+func TestDocumentIndex_IndexSearchAndDelete(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryDocumentStore()
+	index := NewDocumentIndex(createTestLogger(), NewHashingEmbeddingProvider(0), store, 8, 2)
+	userID := uuid.New()
+
+	text := "Ethereum staking rewards depend on validator uptime and network participation. " +
+		"Bitcoin mining difficulty adjusts every two weeks to keep block times stable. " +
+		"Stablecoin reserves should be audited by independent firms every quarter."
+	content := MultiModalContent{Filename: "research.txt", MimeType: "text/plain"}
+	document, err := index.IndexDocument(ctx, userID, content, text, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "research.txt", document.Filename)
+	assert.Equal(t, "abc123", document.SHA256)
+	assert.Equal(t, len(store.chunks[document.ID]), document.ChunkCount)
+	assert.Greater(t, document.ChunkCount, 2)
+
+	results, err := index.Search(ctx, userID, "bitcoin mining difficulty", nil, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0].Content, "mining difficulty")
+	assert.Equal(t, document.ID, results[0].Document.ID)
+	assert.GreaterOrEqual(t, results[0].Score, results[1].Score)
+
+	// Other users' documents are never searched
+	others, err := index.Search(ctx, uuid.New(), "bitcoin mining difficulty", nil, 2)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+
+	_, err = index.Search(ctx, userID, "  ", nil, 0)
+	assert.ErrorIs(t, err, ErrInvalidDocumentSearch)
+
+	assert.ErrorIs(t, index.DeleteDocument(ctx, uuid.New(), document.ID), ErrDocumentNotFound)
+	require.NoError(t, index.DeleteDocument(ctx, userID, document.ID))
+	assert.Empty(t, store.chunks, "vectors go with the document")
+
+	results, err = index.Search(ctx, userID, "bitcoin mining difficulty", nil, 2)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestMultiModalEngine_StoresAnalyzedDocuments(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryDocumentStore()
+	engine := NewMultiModalEngine(createTestLogger())
+	userID := uuid.New()
+
+	request := func(index *DocumentIndex) *MultiModalResult {
+		engine.SetDocumentIndex(index)
+		result, err := engine.ProcessMultiModalRequest(ctx, &MultiModalRequest{
+			RequestID: uuid.New().String(),
+			UserID:    userID,
+			Type:      "document",
+			Content: []MultiModalContent{{
+				ID:       uuid.New().String(),
+				Type:     "document",
+				Data:     base64.StdEncoding.EncodeToString([]byte("Quarterly on-chain revenue grew while gas fees fell.")),
+				MimeType: "text/plain",
+				Filename: "q3.txt",
+				Size:     52,
+			}},
+			Options:     MultiModalOptions{ExtractText: true},
+			RequestedAt: time.Now(),
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Results, 1)
+		return result
+	}
+
+	result := request(NewDocumentIndex(createTestLogger(), NewHashingEmbeddingProvider(0), store, 50, 10))
+	documentID, err := uuid.Parse(result.Results[0].DocumentID)
+	require.NoError(t, err)
+	require.Contains(t, store.documents, documentID)
+	assert.Equal(t, "q3.txt", store.documents[documentID].Filename)
+	assert.NotEmpty(t, store.documents[documentID].SHA256)
+
+	// A failing embedder leaves the analysis intact, without a document ID
+	result = request(NewDocumentIndex(createTestLogger(), failingEmbeddingProvider{}, store, 50, 10))
+	assert.Empty(t, result.Results[0].DocumentID)
+	assert.NotNil(t, result.Results[0].DocumentAnalysis)
+	assert.Len(t, store.documents, 1)
+}
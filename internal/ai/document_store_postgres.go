@@ -0,0 +1,126 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresDocumentStore implements DocumentStore using the ai_documents table and pgvector
+// embeddings in ai_document_chunks
+type postgresDocumentStore struct {
+	db *database.DB
+}
+
+func NewPostgresDocumentStore(db *database.DB) DocumentStore {
+	return &postgresDocumentStore{db: db}
+}
+
+func (s *postgresDocumentStore) SaveDocument(ctx context.Context, document *StoredDocument, chunks []DocumentChunk) error {
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ai_documents (id, user_id, filename, mime_type, sha256, chunk_count, embedding_model, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, document.ID, document.UserID, document.Filename, document.MimeType, document.SHA256,
+			document.ChunkCount, document.EmbeddingModel, document.CreatedAt); err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO ai_document_chunks (document_id, chunk_index, content, embedding)
+				VALUES ($1, $2, $3, $4::vector)
+			`, document.ID, chunk.Index, chunk.Content, formatVector(chunk.Embedding)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *postgresDocumentStore) SearchChunks(ctx context.Context, userID uuid.UUID, embeddingModel string, vector []float32, documentID *uuid.UUID, limit int) ([]DocumentSearchResult, error) {
+	var document interface{}
+	if documentID != nil {
+		document = *documentID
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT d.id, d.user_id, d.filename, d.mime_type, d.sha256, d.chunk_count, d.embedding_model, d.created_at,
+		       c.chunk_index, c.content, 1 - (c.embedding <=> $3::vector)
+		FROM ai_document_chunks c
+		JOIN ai_documents d ON d.id = c.document_id
+		WHERE d.user_id = $1 AND d.embedding_model = $2 AND ($4::uuid IS NULL OR d.id = $4)
+		ORDER BY c.embedding <=> $3::vector
+		LIMIT $5
+	`, userID, embeddingModel, formatVector(vector), document, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]DocumentSearchResult, 0, limit)
+	for rows.Next() {
+		var result DocumentSearchResult
+		doc := &result.Document
+		if err := rows.Scan(&doc.ID, &doc.UserID, &doc.Filename, &doc.MimeType, &doc.SHA256, &doc.ChunkCount,
+			&doc.EmbeddingModel, &doc.CreatedAt, &result.ChunkIndex, &result.Content, &result.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan document chunk: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (s *postgresDocumentStore) ListDocuments(ctx context.Context, userID uuid.UUID) ([]*StoredDocument, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, filename, mime_type, sha256, chunk_count, embedding_model, created_at
+		FROM ai_documents
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := make([]*StoredDocument, 0)
+	for rows.Next() {
+		doc := &StoredDocument{}
+		if err := rows.Scan(&doc.ID, &doc.UserID, &doc.Filename, &doc.MimeType, &doc.SHA256, &doc.ChunkCount,
+			&doc.EmbeddingModel, &doc.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+	return documents, rows.Err()
+}
+
+func (s *postgresDocumentStore) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	// Chunks and their vectors go with the document through ON DELETE CASCADE
+	result, err := s.db.ExecWithMetrics(ctx, `DELETE FROM ai_documents WHERE id = $1 AND user_id = $2`, documentID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+// formatVector renders a vector in pgvector's text format, e.g. [0.1,-0.2]
+func formatVector(vector []float32) string {
+	values := make([]string, len(vector))
+	for i, v := range vector {
+		values[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
@@ -2,12 +2,15 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/alerts"
 	"github.com/ai-agentic-browser/pkg/ml"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // EnhancedAIService provides advanced AI capabilities
@@ -22,6 +25,13 @@ type EnhancedAIService struct {
 	decisionEngine       *DecisionEngine
 	logger               *observability.Logger
 	config               *EnhancedAIConfig
+	metrics              *observability.PrometheusExporter
+
+	translator  TranslationProvider
+	completions *ProviderRouter
+	drift       *modelDriftMonitor
+	alerts      *alerts.AlertService
+	evaluator   *PredictionEvaluator
 }
 
 // EnhancedAIConfig holds configuration for the enhanced AI service
@@ -56,6 +66,8 @@ type AIRequestOptions struct {
 	IncludeRiskAssessment  bool    `json:"include_risk_assessment"`
 	TimeHorizon            int     `json:"time_horizon"` // hours
 	ConfidenceThreshold    float64 `json:"confidence_threshold"`
+
+	IncludeSummary bool `json:"include_summary"` // LLM-written summary, needs a completion router
 }
 
 // AIResponse represents a comprehensive AI analysis response
@@ -206,6 +218,7 @@ func NewEnhancedAIService(logger *observability.Logger) *EnhancedAIService {
 	adaptiveModelManager := NewAdaptiveModelManager(learningEngine, logger)
 	advancedNLP := NewAdvancedNLPEngine(logger)
 	decisionEngine := NewDecisionEngine(logger)
+	decisionEngine.SetSizingLearner(learningEngine)
 
 	// Register models with the manager
 	modelManager.RegisterModel("price_prediction", pricePrediction, &ml.ModelConfig{
@@ -230,6 +243,7 @@ func NewEnhancedAIService(logger *observability.Logger) *EnhancedAIService {
 		decisionEngine:       decisionEngine,
 		logger:               logger,
 		config:               config,
+		drift:                newModelDriftMonitor(),
 	}
 
 	logger.Info(context.Background(), "Enhanced AI service initialized", map[string]interface{}{
@@ -241,6 +255,11 @@ func NewEnhancedAIService(logger *observability.Logger) *EnhancedAIService {
 	return service
 }
 
+// SetPrometheusExporter records request counts and model inference durations in Prometheus
+func (s *EnhancedAIService) SetPrometheusExporter(exporter *observability.PrometheusExporter) {
+	s.metrics = exporter
+}
+
 // ProcessRequest processes a comprehensive AI request
 func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest) (*AIResponse, error) {
 	startTime := time.Now()
@@ -263,6 +282,9 @@ func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest)
 
 	var totalConfidence float64
 	var confidenceCount int
+	// success is cleared when any requested analysis fails, even though the response is
+	// still returned with the parts that succeeded
+	success := true
 
 	// Process price prediction if requested
 	if req.Options.IncludePredictions && s.config.EnablePricePrediction {
@@ -272,6 +294,7 @@ func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest)
 				s.logger.Warn(ctx, "Price prediction failed", map[string]interface{}{
 					"error": err.Error(),
 				})
+				success = false
 			} else {
 				response.PricePrediction = prediction
 				totalConfidence += prediction.Confidence
@@ -288,6 +311,7 @@ func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest)
 				s.logger.Warn(ctx, "Sentiment analysis failed", map[string]interface{}{
 					"error": err.Error(),
 				})
+				success = false
 			} else {
 				response.SentimentAnalysis = sentiment
 				totalConfidence += sentiment.Aggregated.OverallConfidence
@@ -324,6 +348,13 @@ func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest)
 		confidenceCount++
 	}
 
+	// Generate a written summary if requested
+	if req.Options.IncludeSummary && s.completions != nil {
+		if err := s.generateSummary(ctx, req, response); err != nil {
+			success = false
+		}
+	}
+
 	// Calculate overall confidence
 	if confidenceCount > 0 {
 		response.Confidence = totalConfidence / float64(confidenceCount)
@@ -331,6 +362,12 @@ func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest)
 
 	response.ProcessingTime = time.Since(startTime)
 
+	requestType := req.Type
+	if requestType == "" {
+		requestType = "general"
+	}
+	s.metrics.RecordAIRequest(requestType, success)
+
 	s.logger.Info(ctx, "AI request processed", map[string]interface{}{
 		"request_id":      req.RequestID,
 		"processing_time": response.ProcessingTime.Milliseconds(),
@@ -340,9 +377,35 @@ func (s *EnhancedAIService) ProcessRequest(ctx context.Context, req *AIRequest)
 	return response, nil
 }
 
-// GetModelStatus returns the status of all AI models
-func (s *EnhancedAIService) GetModelStatus(ctx context.Context) map[string]*ml.ModelInfo {
-	return s.modelManager.ListModels()
+// GetModelStatus returns the status of all AI models with their versions and drift state
+func (s *EnhancedAIService) GetModelStatus(ctx context.Context) map[string]*ModelStatus {
+	models := s.modelManager.ListVersionedModels()
+	status := make(map[string]*ModelStatus, len(models))
+	for id, info := range models {
+		status[id] = &ModelStatus{VersionedModelInfo: info, Drift: s.drift.status(id)}
+		if s.evaluator != nil {
+			status[id].Evaluation = s.evaluator.Headline(id)
+		}
+	}
+	return status
+}
+
+// SetPredictionEvaluator stores served price predictions so they are evaluated against the
+// realized prices
+func (s *EnhancedAIService) SetPredictionEvaluator(evaluator *PredictionEvaluator) {
+	s.evaluator = evaluator
+}
+
+// GetModelEvaluation returns the accuracy of the predictions a model served within the last
+// period
+func (s *EnhancedAIService) GetModelEvaluation(ctx context.Context, modelID string, period time.Duration) (*ModelEvaluation, error) {
+	if s.evaluator == nil {
+		return nil, ErrEvaluationUnavailable
+	}
+	if _, err := s.modelManager.GetModel(modelID); err != nil {
+		return nil, err
+	}
+	return s.evaluator.Evaluate(ctx, modelID, period)
 }
 
 // TrainModel trains a specific model with new data
@@ -350,9 +413,113 @@ func (s *EnhancedAIService) TrainModel(ctx context.Context, modelID string, data
 	return s.modelManager.TrainModel(ctx, modelID, data)
 }
 
-// ProvideFeedback provides feedback on AI predictions for model improvement
+// ProvideFeedback provides feedback on AI predictions for model improvement. The prediction
+// error is tracked for drift detection; see SetDriftConfig.
 func (s *EnhancedAIService) ProvideFeedback(ctx context.Context, modelID string, feedback *ml.PredictionFeedback) error {
-	return s.modelManager.ProvideFeedback(ctx, modelID, feedback)
+	if err := s.modelManager.ProvideFeedback(ctx, modelID, feedback); err != nil {
+		return err
+	}
+
+	evidence, config := s.drift.record(modelID, feedbackError(feedback), time.Now())
+	if evidence != nil {
+		s.handleModelDrift(ctx, modelID, evidence, config)
+	}
+	return nil
+}
+
+// SetDriftConfig sets the drift detection thresholds of a model and whether detected drift
+// queues a retraining
+func (s *EnhancedAIService) SetDriftConfig(modelID string, config ModelDriftConfig) error {
+	if _, err := s.modelManager.GetModel(modelID); err != nil {
+		return err
+	}
+	return s.drift.configure(modelID, config)
+}
+
+// DriftConfig returns the drift detection settings of a model
+func (s *EnhancedAIService) DriftConfig(modelID string) (ModelDriftConfig, error) {
+	if _, err := s.modelManager.GetModel(modelID); err != nil {
+		return ModelDriftConfig{}, err
+	}
+	return s.drift.config(modelID), nil
+}
+
+// SetAlertService raises an alert whenever drift is detected in a model
+func (s *EnhancedAIService) SetAlertService(alertService *alerts.AlertService) {
+	s.alerts = alertService
+}
+
+// handleModelDrift queues a drift adaptation unless auto-retraining is off for the model,
+// and alerts with the evidence
+func (s *EnhancedAIService) handleModelDrift(ctx context.Context, modelID string, evidence *ModelDriftEvidence, config ModelDriftConfig) {
+	s.logger.Warn(ctx, "Model drift detected", map[string]interface{}{
+		"model_id":     modelID,
+		"psi":          evidence.PSI,
+		"mean_shift":   evidence.MeanShift,
+		"auto_retrain": config.AutoRetrain,
+	})
+
+	if config.AutoRetrain {
+		err := s.adaptiveModelManager.RequestAdaptation(&AdaptationRequest{
+			ModelID: modelID,
+			Type:    "drift",
+			Trigger: "concept_drift",
+			Data: map[string]interface{}{
+				"psi":            evidence.PSI,
+				"mean_shift":     evidence.MeanShift,
+				"reference_mean": evidence.ReferenceMean,
+				"current_mean":   evidence.CurrentMean,
+			},
+			Priority: 3,
+		})
+		if err != nil {
+			s.logger.Error(ctx, "Failed to request drift adaptation", err, map[string]interface{}{
+				"model_id": modelID,
+			})
+		}
+	}
+
+	if s.alerts == nil {
+		return
+	}
+	action := "Automatic retraining is disabled for this model."
+	if config.AutoRetrain {
+		action = "A retraining has been queued."
+	}
+	alert := s.alerts.CreateAlert("model_drift_"+modelID, fmt.Sprintf("Drift detected in model %s", modelID),
+		fmt.Sprintf("Prediction errors of %s shifted (PSI %.3f, mean shift %.2f std). %s", modelID, evidence.PSI, evidence.MeanShift, action),
+		alerts.SeverityWarning, "model_drift_psi", decimal.NewFromFloat(evidence.PSI), decimal.NewFromFloat(config.PSIThreshold), nil)
+	alert.Category = alerts.CategorySystemAlerts
+	alert.Metadata["model_id"] = modelID
+	alert.Metadata["psi"] = evidence.PSI
+	alert.Metadata["mean_shift"] = evidence.MeanShift
+	alert.Metadata["mean_shift_threshold"] = config.MeanShiftThreshold
+	alert.Metadata["reference_mean"] = evidence.ReferenceMean
+	alert.Metadata["current_mean"] = evidence.CurrentMean
+	alert.Metadata["samples"] = evidence.Samples
+	alert.Metadata["retrain_requested"] = evidence.RetrainRequested
+	s.alerts.SendAlert(alert)
+}
+
+// ActivateModelVersion switches the version serving predictions for a model
+func (s *EnhancedAIService) ActivateModelVersion(ctx context.Context, modelID string, version int) (*ml.ModelVersion, error) {
+	return s.modelManager.ActivateVersion(ctx, modelID, version)
+}
+
+// RollbackModel switches a model back to the version before the active one
+func (s *EnhancedAIService) RollbackModel(ctx context.Context, modelID string) (*ml.ModelVersion, error) {
+	return s.modelManager.Rollback(ctx, modelID)
+}
+
+// SetTranslationProvider sets the provider that translates non-English texts for sentiment analysis.
+// It is passed to the active sentiment model on each request, so it applies across model versions.
+func (s *EnhancedAIService) SetTranslationProvider(provider TranslationProvider) {
+	s.translator = provider
+}
+
+// SetModelVersionStore persists model versions, restoring previously active versions
+func (s *EnhancedAIService) SetModelVersionStore(ctx context.Context, store ml.ModelVersionStore) error {
+	return s.modelManager.SetVersionStore(ctx, store)
 }
 
 // GeneratePredictiveAnalytics generates comprehensive predictive analytics
@@ -375,9 +542,44 @@ func (s *EnhancedAIService) GetMarketPatterns() map[string]*MarketPattern {
 	return s.learningEngine.GetMarketPatterns()
 }
 
-// GetPerformanceMetrics returns performance tracking data
+// SetCompletionRouter sets the router that serves LLM completions with retries and
+// provider failover
+func (s *EnhancedAIService) SetCompletionRouter(router *ProviderRouter) {
+	s.completions = router
+}
+
+// GetPerformanceMetrics returns performance tracking data. Completion providers are listed
+// as provider:<name> with their retries, failovers and error rate in the metadata.
 func (s *EnhancedAIService) GetPerformanceMetrics() map[string]*ModelPerformance {
-	return s.learningEngine.GetPerformanceMetrics()
+	metrics := s.learningEngine.GetPerformanceMetrics()
+	if s.completions == nil {
+		return metrics
+	}
+
+	for name, stats := range s.completions.Stats() {
+		accuracy := float64(0)
+		if stats.Requests > 0 {
+			accuracy = 1 - stats.ErrorRate
+		}
+		metrics["provider:"+name] = &ModelPerformance{
+			ModelID:         "provider:" + name,
+			ModelType:       "ai_provider",
+			Accuracy:        accuracy,
+			PredictionCount: int(stats.Requests),
+			CorrectCount:    int(stats.Successes),
+			LastUpdated:     time.Now(),
+			Metadata: map[string]interface{}{
+				"model":         stats.Model,
+				"errors":        stats.Errors,
+				"retries":       stats.Retries,
+				"failovers":     stats.Failovers,
+				"error_rate":    stats.ErrorRate,
+				"last_error":    stats.LastError,
+				"last_error_at": stats.LastErrorAt,
+			},
+		}
+	}
+	return metrics
 }
 
 // RequestModelAdaptation requests adaptation for a model
@@ -415,6 +617,32 @@ func (s *EnhancedAIService) GetDecisionHistory(userID uuid.UUID, limit int) []De
 	return s.decisionEngine.GetDecisionHistory(userID, limit)
 }
 
+// SetDecisionExecutor sets the executor for approved decisions
+func (s *EnhancedAIService) SetDecisionExecutor(executor DecisionExecutor) {
+	s.decisionEngine.SetExecutor(executor)
+}
+
+// ApproveDecision approves a pending decision and executes its recommendation
+func (s *EnhancedAIService) ApproveDecision(ctx context.Context, decisionID string, userID uuid.UUID, note string) (*DecisionRecord, error) {
+	return s.decisionEngine.ApproveDecision(ctx, decisionID, userID, note)
+}
+
+// ApproveDecisionWithQuantity approves a pending decision and executes it with the user's
+// quantity instead of the recommended one
+func (s *EnhancedAIService) ApproveDecisionWithQuantity(ctx context.Context, decisionID string, userID uuid.UUID, note string, quantity decimal.Decimal) (*DecisionRecord, error) {
+	return s.decisionEngine.ApproveDecisionWithQuantity(ctx, decisionID, userID, note, quantity)
+}
+
+// SetPositionSizer sets the service that sizes trade decisions
+func (s *EnhancedAIService) SetPositionSizer(sizer PositionSizer) {
+	s.decisionEngine.SetPositionSizer(sizer)
+}
+
+// RejectDecision rejects a pending decision
+func (s *EnhancedAIService) RejectDecision(ctx context.Context, decisionID string, userID uuid.UUID, reason string) (*DecisionRecord, error) {
+	return s.decisionEngine.RejectDecision(ctx, decisionID, userID, reason)
+}
+
 // GetDecisionPerformanceMetrics returns decision performance metrics
 func (s *EnhancedAIService) GetDecisionPerformanceMetrics() *OverallPerformanceMetrics {
 	return s.decisionEngine.GetPerformanceMetrics()
@@ -427,7 +655,15 @@ func (s *EnhancedAIService) processPricePrediction(ctx context.Context, req *Pri
 		"request": req,
 	}
 
-	prediction, err := s.pricePrediction.Predict(ctx, features)
+	// Predictions are served by the active version of the model
+	model, err := s.modelManager.GetModel("price_prediction")
+	if err != nil {
+		return nil, err
+	}
+
+	inferenceStart := time.Now()
+	prediction, err := model.Predict(ctx, features)
+	s.metrics.ObserveModelInference("price_prediction", time.Since(inferenceStart))
 	if err != nil {
 		return nil, err
 	}
@@ -437,6 +673,16 @@ func (s *EnhancedAIService) processPricePrediction(ctx context.Context, req *Pri
 		return nil, fmt.Errorf("invalid price prediction response type")
 	}
 
+	if s.evaluator != nil {
+		_, version := s.modelManager.ModelVersions("price_prediction")
+		if err := s.evaluator.Record(ctx, "price_prediction", version, req, response); err != nil {
+			s.logger.Warn(ctx, "Failed to record price prediction for evaluation", map[string]interface{}{
+				"symbol": req.Symbol,
+				"error":  err.Error(),
+			})
+		}
+	}
+
 	return response, nil
 }
 
@@ -444,8 +690,18 @@ func (s *EnhancedAIService) processSentimentAnalysis(ctx context.Context, req *S
 	features := map[string]interface{}{
 		"request": req,
 	}
+	if s.translator != nil {
+		features["translator"] = s.translator
+	}
 
-	prediction, err := s.sentimentAnalyzer.Predict(ctx, features)
+	model, err := s.modelManager.GetModel("sentiment_analysis")
+	if err != nil {
+		return nil, err
+	}
+
+	inferenceStart := time.Now()
+	prediction, err := model.Predict(ctx, features)
+	s.metrics.ObserveModelInference("sentiment_analysis", time.Since(inferenceStart))
 	if err != nil {
 		return nil, err
 	}
@@ -730,3 +986,45 @@ func (s *EnhancedAIService) generateRiskAssessment(ctx context.Context, req *AIR
 		},
 	}
 }
+
+// generateSummary asks the completion providers routed for the request type for a short
+// written summary of the analysis and records which provider served it. A failure is recorded
+// as summary_error and returned.
+func (s *EnhancedAIService) generateSummary(ctx context.Context, req *AIRequest, response *AIResponse) error {
+	analysis, err := json.Marshal(map[string]interface{}{
+		"symbol":          response.Symbol,
+		"market_insights": response.MarketInsights,
+		"recommendations": response.Recommendations,
+		"risk_assessment": response.RiskAssessment,
+	})
+	if err != nil {
+		response.Metadata["summary_error"] = err.Error()
+		return err
+	}
+
+	requestType := req.Type
+	if requestType == "" {
+		requestType = defaultProviderRoute
+	}
+	result, err := s.completions.Complete(ctx, requestType, CompletionRequest{
+		System:      "You are a cryptocurrency market analyst. Summarize the analysis for a trader in at most five sentences. Do not invent figures that are not in the analysis.",
+		Messages:    []CompletionMessage{{Role: RoleUser, Content: string(analysis)}},
+		MaxTokens:   400,
+		Temperature: 0.3,
+	})
+	if err != nil {
+		s.logger.Warn(ctx, "AI summary failed", map[string]interface{}{
+			"request_id": req.RequestID,
+			"error":      err.Error(),
+		})
+		response.Metadata["summary_error"] = err.Error()
+		return err
+	}
+
+	response.Metadata["summary"] = result.Content
+	response.Metadata["provider"] = result.Provider
+	response.Metadata["provider_model"] = result.Model
+	response.Metadata["provider_attempts"] = result.Attempts
+	response.Metadata["provider_failed_over"] = result.FailedOver
+	return nil
+}
@@ -2,6 +2,9 @@ package ai
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -390,3 +393,152 @@ func TestEnhancedAIIntegration(t *testing.T) {
 		assert.NotNil(t, priceModel)
 	})
 }
+
+// memoryVersionStore is an in-memory ml.ModelVersionStore shared across service instances
+type memoryVersionStore struct {
+	versions map[string][]*ml.ModelVersion
+}
+
+func (m *memoryVersionStore) SaveVersion(ctx context.Context, version *ml.ModelVersion) error {
+	stored := *version
+	m.versions[version.ModelID] = append(m.versions[version.ModelID], &stored)
+	return nil
+}
+
+func (m *memoryVersionStore) ListVersions(ctx context.Context, modelID string) ([]*ml.ModelVersion, error) {
+	var versions []*ml.ModelVersion
+	for _, version := range m.versions[modelID] {
+		copied := *version
+		versions = append(versions, &copied)
+	}
+	return versions, nil
+}
+
+func (m *memoryVersionStore) SetActiveVersion(ctx context.Context, modelID string, number int) error {
+	for _, version := range m.versions[modelID] {
+		version.Active = version.Version == number
+	}
+	return nil
+}
+
+func TestEnhancedAIService_ModelVersioning(t *testing.T) {
+	ctx := context.Background()
+	logger := &observability.Logger{}
+	store := &memoryVersionStore{versions: make(map[string][]*ml.ModelVersion)}
+
+	service := NewEnhancedAIService(logger)
+	require.NoError(t, service.SetModelVersionStore(ctx, store))
+
+	status := service.GetModelStatus(ctx)["price_prediction"]
+	require.Len(t, status.Versions, 1)
+	assert.Equal(t, 1, status.ActiveVersion)
+	assert.Equal(t, ml.VersionSourceInitial, status.Versions[0].Source)
+	initialAccuracy := status.Accuracy
+
+	data := ml.TrainingData{Features: make([]map[string]interface{}, 5000)}
+	require.NoError(t, service.TrainModel(ctx, "price_prediction", data))
+
+	status = service.GetModelStatus(ctx)["price_prediction"]
+	require.Len(t, status.Versions, 2)
+	assert.Equal(t, 2, status.ActiveVersion)
+	assert.Equal(t, 5000, status.Versions[1].TrainingSize)
+	assert.NotNil(t, status.Versions[1].Metrics)
+	assert.NotEqual(t, initialAccuracy, status.Accuracy)
+	// Training ran on a copy; the originally registered model was never mutated
+	assert.Equal(t, initialAccuracy, service.pricePrediction.GetInfo().Accuracy)
+	assert.False(t, service.pricePrediction.IsReady())
+
+	t.Run("Rollback", func(t *testing.T) {
+		version, err := service.RollbackModel(ctx, "price_prediction")
+		require.NoError(t, err)
+		assert.Equal(t, 1, version.Version)
+
+		status := service.GetModelStatus(ctx)["price_prediction"]
+		assert.Equal(t, 1, status.ActiveVersion)
+		assert.Equal(t, initialAccuracy, status.Accuracy)
+
+		_, err = service.RollbackModel(ctx, "price_prediction")
+		assert.ErrorIs(t, err, ml.ErrNoPreviousVersion)
+	})
+
+	t.Run("Activate", func(t *testing.T) {
+		_, err := service.ActivateModelVersion(ctx, "price_prediction", 9)
+		assert.ErrorIs(t, err, ml.ErrVersionNotFound)
+		_, err = service.ActivateModelVersion(ctx, "unknown", 1)
+		assert.ErrorIs(t, err, ml.ErrModelNotFound)
+
+		version, err := service.ActivateModelVersion(ctx, "price_prediction", 2)
+		require.NoError(t, err)
+		assert.True(t, version.Active)
+
+		model, err := service.modelManager.GetModel("price_prediction")
+		require.NoError(t, err)
+		assert.True(t, model.IsReady())
+	})
+
+	t.Run("SurvivesRestart", func(t *testing.T) {
+		restarted := NewEnhancedAIService(logger)
+		require.NoError(t, restarted.SetModelVersionStore(ctx, store))
+
+		status := restarted.GetModelStatus(ctx)["price_prediction"]
+		assert.Len(t, status.Versions, 2)
+		assert.Equal(t, 2, status.ActiveVersion)
+		assert.Equal(t, service.GetModelStatus(ctx)["price_prediction"].Accuracy, status.Accuracy)
+	})
+}
+
+// scrapeMetrics returns the exporter's metrics in the Prometheus text format
+func scrapeMetrics(t *testing.T, exporter *observability.PrometheusExporter) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestEnhancedAIService_RecordsRequestOutcome(t *testing.T) {
+	service := NewEnhancedAIService(&observability.Logger{})
+	exporter := observability.NewPrometheusExporter("ai")
+	service.SetPrometheusExporter(exporter)
+	ctx := context.Background()
+
+	_, err := service.ProcessRequest(ctx, &AIRequest{RequestID: "ok", Type: "market_analysis", Symbol: "BTC"})
+	require.NoError(t, err)
+
+	// Too little history to predict from
+	response, err := service.ProcessRequest(ctx, &AIRequest{
+		RequestID: "prediction",
+		Type:      "price_prediction",
+		Symbol:    "BTC",
+		Data:      map[string]interface{}{"price_prediction_request": &PricePredictionRequest{Symbol: "BTC", Horizon: 1}},
+		Options:   AIRequestOptions{IncludePredictions: true},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, response.PricePrediction)
+
+	// A requested summary the providers cannot produce fails the request, although the rest
+	// of the analysis is still returned
+	rejected := &scriptedProvider{name: "rejected", errs: []error{
+		&ProviderError{Provider: "rejected", StatusCode: http.StatusUnauthorized, Err: errors.New("invalid key")},
+	}}
+	service.SetCompletionRouter(newTestProviderRouter(rejected))
+	response, err = service.ProcessRequest(ctx, &AIRequest{
+		RequestID: "summary",
+		Type:      "market_analysis",
+		Symbol:    "BTC",
+		Options:   AIRequestOptions{IncludeSummary: true},
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, response.MarketInsights)
+	assert.Contains(t, response.Metadata, "summary_error")
+
+	_, err = service.ProcessRequest(ctx, &AIRequest{RequestID: "general"})
+	require.NoError(t, err)
+
+	metrics := scrapeMetrics(t, exporter)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai",status="success",type="market_analysis"} 1`)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai",status="error",type="market_analysis"} 1`)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai",status="success",type="general"} 1`)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai",status="error",type="price_prediction"} 1`)
+	assert.NotContains(t, metrics, `ai_requests_total{service="ai",status="success",type="price_prediction"}`)
+}
@@ -23,12 +23,22 @@ type HealthStatus struct {
 	Error        string        `json:"error,omitempty"`
 	Models       []string      `json:"models,omitempty"`
 	ResponseTime time.Duration `json:"response_time"`
+	Checks       int64         `json:"checks"`
+	ErrorRate    float64       `json:"error_rate"` // failure ratio over the recent check window
 }
 
+const (
+	// healthHistorySize is the number of recent checks used to compute a provider's error rate
+	healthHistorySize = 20
+	// degradedErrorRate marks a provider that still answers but fails too often
+	degradedErrorRate = 0.25
+)
+
 // HealthMonitor monitors the health of AI providers
 type HealthMonitor struct {
 	providers map[string]HealthChecker
 	statuses  map[string]*HealthStatus
+	history   map[string][]bool // recent check outcomes per provider, true on failure
 	mutex     sync.RWMutex
 	logger    *observability.Logger
 	stopCh    chan struct{}
@@ -44,6 +54,7 @@ func NewHealthMonitor(logger *observability.Logger, interval time.Duration) *Hea
 	return &HealthMonitor{
 		providers: make(map[string]HealthChecker),
 		statuses:  make(map[string]*HealthStatus),
+		history:   make(map[string][]bool),
 		logger:    logger,
 		stopCh:    make(chan struct{}),
 		interval:  interval,
@@ -176,6 +187,19 @@ func (hm *HealthMonitor) checkProvider(ctx context.Context, name string, provide
 	} else {
 		status.Error = ""
 	}
+	status.Checks++
+	history := append(hm.history[name], err != nil)
+	if len(history) > healthHistorySize {
+		history = history[len(history)-healthHistorySize:]
+	}
+	hm.history[name] = history
+	failures := 0
+	for _, failed := range history {
+		if failed {
+			failures++
+		}
+	}
+	status.ErrorRate = float64(failures) / float64(len(history))
 	hm.mutex.Unlock()
 
 	// Log health check result
@@ -207,6 +231,7 @@ type HealthSummary struct {
 	Total     int `json:"total"`
 	Healthy   int `json:"healthy"`
 	Unhealthy int `json:"unhealthy"`
+	Degraded  int `json:"degraded"` // healthy providers with an elevated error rate
 }
 
 // GetHealthCheckResponse returns a comprehensive health check response
@@ -220,12 +245,18 @@ func (hm *HealthMonitor) GetHealthCheckResponse() *HealthCheckResponse {
 	for _, status := range statuses {
 		if status.Healthy {
 			summary.Healthy++
+			if status.ErrorRate >= degradedErrorRate {
+				summary.Degraded++
+			}
 		} else {
 			summary.Unhealthy++
 		}
 	}
 
 	overallStatus := "healthy"
+	if summary.Degraded > 0 {
+		overallStatus = "degraded"
+	}
 	if summary.Unhealthy > 0 {
 		if summary.Healthy == 0 {
 			overallStatus = "unhealthy"
@@ -256,6 +287,15 @@ func (hm *HealthMonitor) CheckProviderNow(ctx context.Context, providerName stri
 	return nil
 }
 
+// HasProvider reports whether a provider is registered
+func (hm *HealthMonitor) HasProvider(providerName string) bool {
+	hm.mutex.RLock()
+	defer hm.mutex.RUnlock()
+
+	_, exists := hm.providers[providerName]
+	return exists
+}
+
 // GetProviderModels returns the available models for a specific provider
 func (hm *HealthMonitor) GetProviderModels(providerName string) ([]string, error) {
 	status, exists := hm.GetStatus(providerName)
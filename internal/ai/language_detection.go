@@ -0,0 +1,238 @@
+package ai
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// mixedLanguageShare is the share of a second language above which a text counts as mixed
+	mixedLanguageShare = 0.2
+	// ideographWeight counts each Han, kana or Hangul character as this many Latin letters,
+	// since a single character carries roughly a word's worth of text
+	ideographWeight = 2
+)
+
+// LanguageDetection is the detected language of a text
+type LanguageDetection struct {
+	Language   string             `json:"language"` // ISO 639-1 code
+	Confidence float64            `json:"confidence"`
+	Mixed      bool               `json:"mixed,omitempty"`
+	Shares     map[string]float64 `json:"shares,omitempty"` // language -> share of the text, for mixed texts
+}
+
+// scriptLanguages maps writing systems used by a single language (or language group) to it
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// latinStopwords are frequent function words that tell Latin-script languages apart
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "to", "of", "it", "this", "that", "for", "with", "be", "will", "just", "not", "was", "you", "my", "on", "what", "why", "how", "going", "about"},
+	"es": {"el", "los", "las", "que", "y", "es", "por", "para", "con", "una", "muy", "pero", "está", "del", "como", "más", "hoy", "se"},
+	"fr": {"le", "les", "et", "est", "pour", "dans", "pas", "une", "avec", "sur", "ce", "des", "du", "je", "mais", "très", "au", "il"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "ein", "eine", "zu", "auf", "für", "den", "auch", "ich", "sehr", "heute", "wird"},
+	"pt": {"os", "não", "com", "uma", "mais", "muito", "está", "são", "hoje", "vai", "mas", "isso", "também", "do", "da", "em"},
+	"it": {"il", "che", "non", "per", "sono", "molto", "ma", "gli", "della", "questo", "anche", "oggi", "ci", "di", "è"},
+}
+
+// latinMarkers are letters that only occur in some Latin-script languages
+var latinMarkers = map[rune][]string{
+	'ñ': {"es"}, '¿': {"es"}, '¡': {"es"},
+	'ç': {"fr", "pt"}, 'ê': {"fr", "pt"}, 'è': {"fr", "it"}, 'à': {"fr", "it", "pt"},
+	'ß': {"de"}, 'ä': {"de"}, 'ö': {"de"}, 'ü': {"de"},
+	'ã': {"pt"}, 'õ': {"pt"},
+}
+
+var latinStopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range latinStopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// DetectLanguage identifies the language of a text from its scripts and function words,
+// without calling any provider. Tickers, cashtags, mentions and URLs are ignored so that
+// e.g. a Korean post about "BTC" is not counted as partly English.
+func DetectLanguage(text string) LanguageDetection {
+	if isObviouslyEnglish(text) {
+		return LanguageDetection{Language: "en", Confidence: 0.95}
+	}
+
+	weights := make(map[string]float64)
+	var latinWords []string
+	var latinLetters float64
+	latinMarked := make(map[string]float64)
+
+	for _, token := range strings.Fields(text) {
+		if isLanguageNeutralToken(token) {
+			continue
+		}
+		var word strings.Builder
+		for _, r := range token {
+			if language := scriptLanguage(r); language != "" {
+				weights[language] += ideographWeight
+				continue
+			}
+			lower := unicode.ToLower(r)
+			for _, language := range latinMarkers[lower] {
+				latinMarked[language]++
+			}
+			if unicode.Is(unicode.Latin, r) {
+				latinLetters++
+				word.WriteRune(lower)
+			}
+		}
+		if word.Len() > 0 {
+			latinWords = append(latinWords, word.String())
+		}
+	}
+
+	// Japanese is written with Han characters alongside kana
+	if weights["ja"] > 0 && weights["zh"] > 0 {
+		weights["ja"] += weights["zh"]
+		delete(weights, "zh")
+	}
+
+	latinConfidence := 1.0
+	if latinLetters > 0 {
+		language, confidence := detectLatinLanguage(latinWords, latinMarked)
+		weights[language] += latinLetters
+		latinConfidence = confidence
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return LanguageDetection{Language: "en", Confidence: 0.3}
+	}
+
+	type share struct {
+		language string
+		value    float64
+	}
+	shares := make([]share, 0, len(weights))
+	for language, weight := range weights {
+		shares = append(shares, share{language, weight / total})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].value != shares[j].value {
+			return shares[i].value > shares[j].value
+		}
+		return shares[i].language < shares[j].language
+	})
+
+	detection := LanguageDetection{Language: shares[0].language, Confidence: shares[0].value}
+	if _, latin := latinStopwords[detection.Language]; latin {
+		detection.Confidence *= latinConfidence
+	}
+	if len(shares) > 1 && shares[1].value >= mixedLanguageShare {
+		detection.Mixed = true
+		detection.Shares = make(map[string]float64, len(shares))
+		for _, s := range shares {
+			detection.Shares[s.language] = math.Round(s.value*100) / 100
+		}
+	}
+	detection.Confidence = math.Round(detection.Confidence*100) / 100
+	return detection
+}
+
+// isObviouslyEnglish is the fast path for the common case: plain ASCII text containing
+// several English function words and none that are specific to another language
+func isObviouslyEnglish(text string) bool {
+	english := 0
+	for _, word := range strings.Fields(text) {
+		for _, r := range word {
+			if r > unicode.MaxASCII {
+				return false
+			}
+		}
+		word = strings.ToLower(strings.Trim(word, ".,!?;:'\"()"))
+		for _, language := range latinStopwordIndex[word] {
+			if language != "en" {
+				return false
+			}
+			english++
+		}
+	}
+	return english >= 2
+}
+
+// detectLatinLanguage picks the Latin-script language with the most function words and
+// distinctive letters, defaulting to English when nothing distinguishes the text
+func detectLatinLanguage(words []string, marked map[string]float64) (string, float64) {
+	scores := make(map[string]float64)
+	for _, word := range words {
+		for _, language := range latinStopwordIndex[word] {
+			scores[language]++
+		}
+	}
+	for language, count := range marked {
+		scores[language] += count
+	}
+
+	best, bestScore, total := "en", 0.0, 0.0
+	for language, score := range scores {
+		total += score
+		if score > bestScore || (score == bestScore && language < best) {
+			best, bestScore = language, score
+		}
+	}
+	if total == 0 {
+		return "en", 0.5
+	}
+	return best, 0.5 + 0.5*bestScore/total
+}
+
+func scriptLanguage(r rune) string {
+	if r <= unicode.MaxLatin1 {
+		return ""
+	}
+	for _, script := range scriptLanguages {
+		if unicode.Is(script.table, r) {
+			return script.language
+		}
+	}
+	return ""
+}
+
+// isLanguageNeutralToken reports tokens that appear unchanged in any language: tickers,
+// cashtags, hashtags, mentions, URLs and numbers
+func isLanguageNeutralToken(token string) bool {
+	if strings.HasPrefix(token, "$") || strings.HasPrefix(token, "@") || strings.HasPrefix(token, "#") ||
+		strings.HasPrefix(token, "http://") || strings.HasPrefix(token, "https://") {
+		return true
+	}
+	letters, upper := 0, 0
+	for _, r := range token {
+		if unicode.IsLetter(r) {
+			if r > unicode.MaxASCII {
+				return false
+			}
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters == 0 || (letters >= 2 && upper == letters)
+}
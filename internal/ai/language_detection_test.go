@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	testCases := []struct {
+		text     string
+		language string
+		mixed    bool
+	}{
+		{"BTC is going to the moon, this rally is just getting started", "en", false},
+		{"hodl", "en", false},
+		{"비트코인 오늘 폭락했다 다 팔아야 하나", "ko", false},
+		{"BTC $ETH 지금 떡락 중 https://example.com #crypto", "ko", false},
+		{"ビットコインが暴落しています", "ja", false},
+		{"比特币今天暴跌了", "zh", false},
+		{"Биткоин сегодня падает", "ru", false},
+		{"El precio de bitcoin está muy alto hoy", "es", false},
+		{"Le bitcoin est très volatil dans ce marché", "fr", false},
+		{"Der Bitcoin ist heute nicht gestiegen", "de", false},
+		{"Bitcoin is crashing 비트코인 폭락 진짜 무섭다 다들 괜찮아요", "ko", true},
+	}
+
+	for _, tc := range testCases {
+		detection := DetectLanguage(tc.text)
+		assert.Equal(t, tc.language, detection.Language, "text: %s", tc.text)
+		assert.Equal(t, tc.mixed, detection.Mixed, "text: %s", tc.text)
+		assert.Greater(t, detection.Confidence, 0.0, "text: %s", tc.text)
+		if tc.mixed {
+			assert.Contains(t, detection.Shares, "en")
+		}
+	}
+}
+
+type fakeTranslationProvider struct {
+	mu           sync.Mutex
+	calls        []string
+	translations map[string]string
+}
+
+func (p *fakeTranslationProvider) Name() string { return "fake" }
+
+func (p *fakeTranslationProvider) Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, text)
+	if translated, ok := p.translations[text]; ok {
+		return translated, nil
+	}
+	return "", errors.New("unsupported text")
+}
+
+func TestSentimentAnalyzer_TranslateToEnglish(t *testing.T) {
+	logger := &observability.Logger{}
+	analyzer := NewSentimentAnalyzer(logger)
+
+	korean := "비트코인 폭락 공포"
+	spanish := "El bitcoin está muy bajo hoy"
+	translator := &fakeTranslationProvider{translations: map[string]string{
+		korean: "bitcoin crash panic fear",
+	}}
+
+	req := &SentimentRequest{
+		Texts:              []string{"bitcoin is bullish and the rally will surge", korean, spanish},
+		Source:             "twitter",
+		TranslateToEnglish: true,
+	}
+	prediction, err := analyzer.Predict(context.Background(), map[string]interface{}{
+		"request":    req,
+		"translator": TranslationProvider(translator),
+	})
+	require.NoError(t, err)
+	response := prediction.Value.(*SentimentResponse)
+
+	// English texts are never sent to the provider
+	assert.ElementsMatch(t, []string{korean, spanish}, translator.calls)
+
+	english, ko, es := response.Results[0], response.Results[1], response.Results[2]
+	assert.Equal(t, "en", english.Language)
+	assert.Nil(t, english.Translation)
+
+	assert.Equal(t, "ko", ko.Language)
+	require.NotNil(t, ko.Translation)
+	require.NotNil(t, ko.Translation.TranslatedSentiment)
+	assert.Equal(t, *ko.Translation.TranslatedSentiment, ko.Sentiment)
+	assert.Less(t, ko.Sentiment, ko.Translation.OriginalSentiment, "the translation carries the bearish wording the lexicon understands")
+	assert.Equal(t, "negative", ko.Label)
+	assert.Equal(t, korean, ko.Text)
+
+	// A failed translation keeps the original-language score
+	assert.Equal(t, "es", es.Language)
+	require.NotNil(t, es.Translation)
+	assert.Nil(t, es.Translation.TranslatedSentiment)
+	assert.Contains(t, es.Translation.Error, "unsupported")
+	assert.Equal(t, es.Translation.OriginalSentiment, es.Sentiment)
+
+	byLanguage := response.Aggregated.ByLanguage
+	require.Len(t, byLanguage, 3)
+	assert.Greater(t, byLanguage["en"].Sentiment, byLanguage["ko"].Sentiment)
+	assert.Equal(t, 1, byLanguage["ko"].Translated)
+	assert.Equal(t, 0, byLanguage["es"].Translated)
+
+	t.Run("DeclaredLanguage", func(t *testing.T) {
+		prediction, err := analyzer.Predict(context.Background(), map[string]interface{}{
+			"request": &SentimentRequest{Texts: []string{"bullish"}, Language: "en"},
+		})
+		require.NoError(t, err)
+		result := prediction.Value.(*SentimentResponse).Results[0]
+		assert.Equal(t, "en", result.Language)
+		assert.Nil(t, result.LanguageDetection)
+	})
+
+	t.Run("NoProvider", func(t *testing.T) {
+		prediction, err := analyzer.Predict(context.Background(), map[string]interface{}{
+			"request": &SentimentRequest{Texts: []string{korean}, TranslateToEnglish: true},
+		})
+		require.NoError(t, err)
+		result := prediction.Value.(*SentimentResponse).Results[0]
+		require.NotNil(t, result.Translation)
+		assert.Equal(t, ErrTranslationUnavailable.Error(), result.Translation.Error)
+	})
+}
@@ -13,6 +13,15 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const (
+	// positionSizeLearningRate is how quickly the size adjustment follows the user's trades
+	positionSizeLearningRate = 0.2
+	// positionSizeFollowTolerance is how far a traded size may deviate and still count as followed
+	positionSizeFollowTolerance = 0.01
+	minPositionSizeAdjustment   = 0.25
+	maxPositionSizeAdjustment   = 2.0
+)
+
 // LearningEngine implements adaptive learning mechanisms
 type LearningEngine struct {
 	logger             *observability.Logger
@@ -76,6 +85,11 @@ type PositionSizingPattern struct {
 	RiskPerTrade         float64            `json:"risk_per_trade"`
 	CorrelationAwareness float64            `json:"correlation_awareness"`
 	Diversification      map[string]float64 `json:"diversification"`
+
+	// SizeAdjustment scales recommended position sizes towards the sizes the user trades
+	SizeAdjustment          float64 `json:"size_adjustment"`
+	RecommendationsSized    int     `json:"recommendations_sized"`
+	RecommendationsFollowed int     `json:"recommendations_followed"`
 }
 
 // PatternSignature represents a learned pattern signature
@@ -683,6 +697,9 @@ func (l *LearningEngine) LearnFromUserBehavior(ctx context.Context, userID uuid.
 	// Update decision factors
 	l.updateDecisionFactors(profile, behavior)
 
+	// Update position sizing
+	l.updatePositionSizing(profile, behavior)
+
 	// Update performance metrics
 	l.updateUserPerformanceMetrics(profile, behavior)
 
@@ -738,6 +755,7 @@ func (l *LearningEngine) createNewUserProfile(userID uuid.UUID) *UserProfile {
 				MaxPositionSize: 0.2,
 				SizingStrategy:  "percentage",
 				RiskPerTrade:    0.02,
+				SizeAdjustment:  1.0,
 			},
 			RiskManagement: &RiskManagementPattern{
 				StopLossUsage:       0.8,
@@ -805,6 +823,35 @@ func (l *LearningEngine) updateRiskTolerance(profile *UserProfile, behavior *Use
 	}
 }
 
+// updatePositionSizing moves the user's size adjustment towards the ratio between the sizes
+// they traded and the sizes recommended to them
+func (l *LearningEngine) updatePositionSizing(profile *UserProfile, behavior *UserBehaviorData) {
+	if behavior.Type != "position_sizing" || profile.TradingPatterns == nil || profile.TradingPatterns.PositionSizing == nil {
+		return
+	}
+	recommended, _ := behavior.Data["recommended_quantity"].(float64)
+	executed, ok := behavior.Data["executed_quantity"].(float64)
+	if !ok || recommended <= 0 || executed < 0 {
+		return
+	}
+
+	sizing := profile.TradingPatterns.PositionSizing
+	adjustment := sizing.SizeAdjustment
+	if adjustment <= 0 {
+		adjustment = 1
+	}
+	ratio := executed / recommended
+
+	sizing.RecommendationsSized++
+	if math.Abs(ratio-1) <= positionSizeFollowTolerance {
+		sizing.RecommendationsFollowed++
+	}
+	// Recommendations already include the adjustment, so the user's preferred size relative
+	// to an unadjusted recommendation is adjustment * ratio
+	target := math.Max(minPositionSizeAdjustment, math.Min(maxPositionSizeAdjustment, adjustment*ratio))
+	sizing.SizeAdjustment = l.updateWithDecay(adjustment, target, positionSizeLearningRate)
+}
+
 func (l *LearningEngine) updateDecisionFactors(profile *UserProfile, behavior *UserBehaviorData) {
 	// Update decision factor weights based on what user pays attention to
 	if factors, exists := behavior.Data["decision_factors"].(map[string]float64); exists {
@@ -932,6 +979,20 @@ func (l *LearningEngine) GetUserProfile(userID uuid.UUID) (*UserProfile, error)
 	return profile, nil
 }
 
+// PositionSizeAdjustment returns the multiplier applied to position sizes recommended to a
+// user, learned from the sizes they traded. Users without history get 1.
+func (l *LearningEngine) PositionSizeAdjustment(userID uuid.UUID) float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	profile, exists := l.userProfiles[userID]
+	if !exists || profile.TradingPatterns == nil || profile.TradingPatterns.PositionSizing == nil ||
+		profile.TradingPatterns.PositionSizing.SizeAdjustment <= 0 {
+		return 1
+	}
+	return profile.TradingPatterns.PositionSizing.SizeAdjustment
+}
+
 // GetMarketPatterns returns learned market patterns
 func (l *LearningEngine) GetMarketPatterns() map[string]*MarketPattern {
 	l.marketPatterns.mu.RLock()
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/web3"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 )
@@ -26,6 +27,16 @@ type MarketAdaptationEngine struct {
 	performanceMetrics  map[string]*MarketPerformanceMetrics
 	mu                  sync.RWMutex
 	lastUpdate          time.Time
+
+	// candleSource loads history for backtests; adaptations are backtested only when set
+	candleSource CandleSource
+	// adaptationCandles caches the history adaptation backtests run over by symbol. candlesMu
+	// serializes its refreshes and is taken before mu.
+	adaptationCandles map[string]*adaptationHistory
+	candlesMu         sync.Mutex
+
+	// derivativesSource adds funding rates and open interest change to pattern detection input
+	derivativesSource DerivativesSource
 }
 
 // MarketAdaptationConfig holds configuration for market adaptation
@@ -40,6 +51,14 @@ type MarketAdaptationConfig struct {
 	MaxAdaptationHistory        int           `json:"max_adaptation_history"`
 	EnableRealTimeAdaptation    bool          `json:"enable_real_time_adaptation"`
 	ConfidenceThreshold         float64       `json:"confidence_threshold"`
+
+	// Adaptations of strategies with a "symbol" in their metadata are backtested with the old
+	// and new parameters over the trailing window, and the results attached to the record
+	BacktestAdaptations        bool          `json:"backtest_adaptations"`
+	AdaptationBacktestWindow   time.Duration `json:"adaptation_backtest_window"`
+	AdaptationBacktestInterval string        `json:"adaptation_backtest_interval"`
+	// AdaptationCandleTTL is how long history loaded for adaptation backtests is reused
+	AdaptationCandleTTL time.Duration `json:"adaptation_candle_ttl"`
 }
 
 // DetectedPattern represents a detected market pattern
@@ -122,6 +141,9 @@ type AdaptiveStrategy struct {
 	IsActive           bool                        `json:"is_active"`
 	Confidence         float64                     `json:"confidence"`
 	Metadata           map[string]interface{}      `json:"metadata"`
+
+	// Positions are only opened within the schedule's trading windows
+	Schedule *web3.TradingSchedule `json:"schedule,omitempty"`
 }
 
 // AdaptationRule represents a rule for strategy adaptation
@@ -227,6 +249,9 @@ type MarketPerformanceMetrics struct {
 	AdaptationImpact   *AdaptationImpact      `json:"adaptation_impact"`
 	LastUpdated        time.Time              `json:"last_updated"`
 	Metadata           map[string]interface{} `json:"metadata"`
+
+	// Entry signals skipped outside the strategy's trading schedule
+	SuppressedBySchedule int `json:"suppressed_by_schedule"`
 }
 
 // AdaptationImpact represents the impact of adaptations on performance
@@ -252,6 +277,7 @@ type AdaptationRecord struct {
 	PatternID      string                 `json:"pattern_id"`
 	AdaptationData map[string]interface{} `json:"adaptation_data"`
 	Impact         *AdaptationImpact      `json:"impact"`
+	Backtest       *AdaptationBacktest    `json:"backtest,omitempty"`
 	Confidence     float64                `json:"confidence"`
 	Timestamp      time.Time              `json:"timestamp"`
 	Metadata       map[string]interface{} `json:"metadata"`
@@ -309,6 +335,10 @@ func NewMarketAdaptationEngine(logger *observability.Logger) *MarketAdaptationEn
 		MaxAdaptationHistory:        1000,
 		EnableRealTimeAdaptation:    true,
 		ConfidenceThreshold:         0.6,
+		BacktestAdaptations:         true,
+		AdaptationBacktestWindow:    30 * 24 * time.Hour,
+		AdaptationBacktestInterval:  "1h",
+		AdaptationCandleTTL:         time.Hour,
 	}
 
 	engine := &MarketAdaptationEngine{
@@ -339,6 +369,9 @@ func NewMarketAdaptationEngine(logger *observability.Logger) *MarketAdaptationEn
 
 // DetectPatterns detects patterns in market data
 func (m *MarketAdaptationEngine) DetectPatterns(ctx context.Context, marketData map[string]interface{}) ([]*DetectedPattern, error) {
+	// Derivatives data is loaded before taking the lock
+	marketData = m.withDerivatives(ctx, marketData)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -386,6 +419,9 @@ func (m *MarketAdaptationEngine) DetectPatterns(ctx context.Context, marketData
 
 // AdaptStrategies adapts trading strategies based on detected patterns
 func (m *MarketAdaptationEngine) AdaptStrategies(ctx context.Context, patterns []*DetectedPattern) error {
+	// History for adaptation backtests is loaded before taking the lock
+	histories := m.loadAdaptationCandles(ctx)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -439,6 +475,19 @@ func (m *MarketAdaptationEngine) AdaptStrategies(ctx context.Context, patterns [
 			Metadata:       map[string]interface{}{},
 		}
 
+		if history, exists := histories[strategySymbol(strategy)]; exists {
+			backtest, err := m.backtestAdaptation(ctx, strategy, adaptation, history.candles, history.start, history.end)
+			if err != nil {
+				m.logger.Warn(ctx, "Failed to backtest adaptation", map[string]interface{}{
+					"strategy_id":   strategy.ID,
+					"adaptation_id": adaptation.ID,
+					"error":         err.Error(),
+				})
+			} else {
+				record.Backtest = backtest
+			}
+		}
+
 		m.adaptationHistory = append(m.adaptationHistory, record)
 		adaptationCount++
 
@@ -597,6 +646,10 @@ func (pd *PatternDetector) DetectPatterns(ctx context.Context, marketData map[st
 		patterns = append(patterns, trendPattern)
 	}
 
+	if positioningPattern := detectPositioningPattern(marketData); positioningPattern != nil {
+		patterns = append(patterns, positioningPattern)
+	}
+
 	return patterns, nil
 }
 
@@ -661,6 +714,19 @@ func (sm *StrategyManager) AdaptStrategy(ctx context.Context, strategy *Adaptive
 				}
 			}
 		}
+		// Crowded perpetual positioning risks a squeeze, so trade smaller with tighter stops
+		for _, pattern := range patterns {
+			if pattern.Type != "positioning" {
+				continue
+			}
+			if posSize, exists := adaptation.NewParameters["position_size"]; exists {
+				adaptation.NewParameters["position_size"] = posSize * 0.8
+			}
+			if stopLoss, exists := adaptation.NewParameters["stop_loss"]; exists {
+				adaptation.NewParameters["stop_loss"] = math.Max(stopLoss*0.8, 0.01)
+			}
+			break
+		}
 	}
 
 	return adaptation, nil
@@ -755,6 +821,12 @@ func (m *MarketAdaptationEngine) GetPerformanceMetrics(ctx context.Context, stra
 
 // AddAdaptiveStrategy adds a new adaptive strategy
 func (m *MarketAdaptationEngine) AddAdaptiveStrategy(ctx context.Context, strategy *AdaptiveStrategy) error {
+	if strategy.Schedule != nil {
+		if err := strategy.Schedule.Validate(); err != nil {
+			return err
+		}
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -795,7 +867,7 @@ func (m *MarketAdaptationEngine) UpdateStrategyStatus(ctx context.Context, strat
 		}
 	}
 
-	return fmt.Errorf("strategy not found: %s", strategyID)
+	return fmt.Errorf("%w: %s", ErrAdaptiveStrategyNotFound, strategyID)
 }
 
 // SetPerformanceMetrics sets performance metrics for a strategy (for demo purposes)
@@ -0,0 +1,521 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/ml"
+)
+
+var (
+	// ErrAdaptiveStrategyNotFound is returned for an unknown adaptive strategy ID
+	ErrAdaptiveStrategyNotFound = errors.New("adaptive strategy not found")
+	// ErrCandleSourceUnavailable is returned when historical candles are requested without a source
+	ErrCandleSourceUnavailable = errors.New("candle source not configured")
+	// ErrInsufficientCandles is returned when there is too little history to evaluate a strategy
+	ErrInsufficientCandles = errors.New("insufficient candles for backtest")
+)
+
+// CandleSource loads historical OHLCV candles, oldest first
+type CandleSource interface {
+	GetCandles(ctx context.Context, symbol, interval string, start, end time.Time) ([]ml.PriceData, error)
+}
+
+// StrategyBacktestOptions controls a backtest run
+type StrategyBacktestOptions struct {
+	InitialCapital float64            `json:"initial_capital"` // defaults to 10000
+	FeeRate        float64            `json:"fee_rate"`        // charged on the notional of each entry and exit
+	Lookback       int                `json:"lookback"`        // candles used for the signal indicators, defaults to 20
+	Parameters     map[string]float64 `json:"parameters"`      // overrides the strategy's current parameters
+}
+
+// StrategyBacktestResult is the outcome of replaying candles through a strategy
+type StrategyBacktestResult struct {
+	StrategyID  string                    `json:"strategy_id"`
+	Symbol      string                    `json:"symbol"`
+	Start       time.Time                 `json:"start"`
+	End         time.Time                 `json:"end"`
+	Candles     int                       `json:"candles"`
+	Parameters  map[string]float64        `json:"parameters"`
+	Metrics     *MarketPerformanceMetrics `json:"metrics"`
+	EquityCurve []EquityPoint             `json:"equity_curve"`
+	Trades      []BacktestTrade           `json:"trades"`
+}
+
+// EquityPoint is the account value at the close of a candle
+type EquityPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Equity    float64   `json:"equity"`
+	Drawdown  float64   `json:"drawdown"`
+}
+
+// BacktestTrade is a simulated round trip
+type BacktestTrade struct {
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Quantity   float64   `json:"quantity"`
+	PnL        float64   `json:"pnl"`
+	Return     float64   `json:"return"`
+	ExitReason string    `json:"exit_reason"` // signal, stop_loss, take_profit, hold_time, end_of_data
+}
+
+// AdaptationBacktest compares an adaptation's old and new parameters over the same history
+type AdaptationBacktest struct {
+	Symbol   string               `json:"symbol"`
+	Interval string               `json:"interval"`
+	Start    time.Time            `json:"start"`
+	End      time.Time            `json:"end"`
+	Before   *PerformanceSnapshot `json:"before"`
+	After    *PerformanceSnapshot `json:"after"`
+	Improved bool                 `json:"improved"` // the new parameters have the higher Sharpe ratio
+}
+
+// SetCandleSource sets where BacktestStrategy and adaptation backtests load history from
+func (m *MarketAdaptationEngine) SetCandleSource(source CandleSource) {
+	m.candlesMu.Lock()
+	defer m.candlesMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.candleSource = source
+	m.adaptationCandles = nil
+}
+
+// BacktestStrategy loads candles for symbol between start and end and backtests the strategy
+// over them
+func (m *MarketAdaptationEngine) BacktestStrategy(ctx context.Context, strategyID, symbol, interval string, start, end time.Time, options StrategyBacktestOptions) (*StrategyBacktestResult, error) {
+	m.mu.RLock()
+	source := m.candleSource
+	var strategy *AdaptiveStrategy
+	for _, s := range m.adaptiveStrategies {
+		if s.ID == strategyID {
+			strategy = s
+			break
+		}
+	}
+	var parameters map[string]float64
+	if strategy != nil {
+		parameters = copyParameters(strategy.CurrentParameters)
+	}
+	m.mu.RUnlock()
+
+	if strategy == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAdaptiveStrategyNotFound, strategyID)
+	}
+	if source == nil {
+		return nil, ErrCandleSourceUnavailable
+	}
+
+	candles, err := source.GetCandles(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load candles: %w", err)
+	}
+
+	if options.Parameters == nil {
+		options.Parameters = parameters
+	}
+	result, err := m.Backtest(ctx, strategy, candles, options)
+	if err != nil {
+		return nil, err
+	}
+	result.Symbol = symbol
+	return result, nil
+}
+
+// Backtest replays candles through the strategy's parameters as a long-only strategy and
+// reports the resulting performance and equity curve. trend_following enters when price is
+// above its moving average and the share of up candles reaches entry_threshold; momentum
+// uses the rate of change instead of the moving average; mean_reversion enters when price is
+// reversion_threshold standard deviations below its average. Positions are sized by
+// position_size and exit on the opposite signal, stop_loss, take_profit or hold_time (hours).
+// Entries at candles outside the strategy's schedule are skipped and counted as suppressed.
+func (m *MarketAdaptationEngine) Backtest(ctx context.Context, strategy *AdaptiveStrategy, candles []ml.PriceData, options StrategyBacktestOptions) (*StrategyBacktestResult, error) {
+	if options.InitialCapital <= 0 {
+		options.InitialCapital = 10000
+	}
+	if options.Lookback <= 1 {
+		options.Lookback = 20
+	}
+	params := options.Parameters
+	if params == nil {
+		params = strategy.CurrentParameters
+	}
+	params = copyParameters(params)
+
+	if len(candles) < options.Lookback+2 {
+		return nil, fmt.Errorf("%w: need at least %d, got %d", ErrInsufficientCandles, options.Lookback+2, len(candles))
+	}
+
+	candles = append([]ml.PriceData(nil), candles...)
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Timestamp.Before(candles[j].Timestamp)
+	})
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close.InexactFloat64()
+	}
+
+	positionSize := parameterOr(params, "position_size", 1)
+	stopLoss := parameterOr(params, "stop_loss", 0)
+	takeProfit := parameterOr(params, "take_profit", 0)
+	holdTime := time.Duration(parameterOr(params, "hold_time", 0) * float64(time.Hour))
+
+	cash := options.InitialCapital
+	var position *BacktestTrade
+	var trades []BacktestTrade
+	curve := make([]EquityPoint, 0, len(candles)-options.Lookback)
+	peak := options.InitialCapital
+	suppressed := 0
+
+	closePosition := func(candle ml.PriceData, price float64, reason string) {
+		proceeds := position.Quantity * price
+		cash += proceeds - proceeds*options.FeeRate
+		cost := position.Quantity * position.EntryPrice
+		position.ExitTime = candle.Timestamp
+		position.ExitPrice = price
+		position.PnL = proceeds - cost - (proceeds+cost)*options.FeeRate
+		if cost > 0 {
+			position.Return = position.PnL / cost
+		}
+		position.ExitReason = reason
+		trades = append(trades, *position)
+		position = nil
+	}
+
+	for i := options.Lookback; i < len(candles); i++ {
+		if i%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		candle := candles[i]
+		price := closes[i]
+		enter, exit := backtestSignal(strategy.Type, params, closes[i-options.Lookback:i+1])
+
+		if position != nil {
+			low := candle.Low.InexactFloat64()
+			high := candle.High.InexactFloat64()
+			switch {
+			case stopLoss > 0 && low > 0 && low <= position.EntryPrice*(1-stopLoss):
+				closePosition(candle, position.EntryPrice*(1-stopLoss), "stop_loss")
+			case takeProfit > 0 && high >= position.EntryPrice*(1+takeProfit):
+				closePosition(candle, position.EntryPrice*(1+takeProfit), "take_profit")
+			case holdTime > 0 && candle.Timestamp.Sub(position.EntryTime) >= holdTime:
+				closePosition(candle, price, "hold_time")
+			case exit:
+				closePosition(candle, price, "signal")
+			}
+		} else if enter && price > 0 && !strategy.Schedule.Allows(candle.Timestamp) {
+			suppressed++
+		} else if enter && price > 0 {
+			notional := cash * math.Min(positionSize, 1)
+			quantity := notional / (price * (1 + options.FeeRate))
+			cash -= quantity * price * (1 + options.FeeRate)
+			position = &BacktestTrade{EntryTime: candle.Timestamp, EntryPrice: price, Quantity: quantity}
+		}
+
+		equity := cash
+		if position != nil {
+			equity += position.Quantity * price
+		}
+		peak = math.Max(peak, equity)
+		curve = append(curve, EquityPoint{Timestamp: candle.Timestamp, Equity: equity, Drawdown: (peak - equity) / peak})
+	}
+
+	if position != nil {
+		last := candles[len(candles)-1]
+		closePosition(last, closes[len(closes)-1], "end_of_data")
+		curve[len(curve)-1].Equity = cash
+		curve[len(curve)-1].Drawdown = (peak - cash) / peak
+	}
+
+	metrics := m.backtestMetrics(strategy.ID, options.InitialCapital, candles, curve, trades)
+	metrics.SuppressedBySchedule = suppressed
+
+	return &StrategyBacktestResult{
+		StrategyID:  strategy.ID,
+		Symbol:      candles[0].Symbol,
+		Start:       candles[0].Timestamp,
+		End:         candles[len(candles)-1].Timestamp,
+		Candles:     len(candles),
+		Parameters:  params,
+		Metrics:     metrics,
+		EquityCurve: curve,
+		Trades:      trades,
+	}, nil
+}
+
+// backtestSignal evaluates entry and exit signals over a window ending at the current candle
+func backtestSignal(strategyType string, params map[string]float64, window []float64) (enter, exit bool) {
+	current := window[len(window)-1]
+	history := window[:len(window)-1]
+	mean, stdDev := meanStdDev(history)
+
+	ups := 0
+	for i := 1; i < len(window); i++ {
+		if window[i] > window[i-1] {
+			ups++
+		}
+	}
+	upShare := float64(ups) / float64(len(window)-1)
+
+	switch strategyType {
+	case "mean_reversion":
+		if stdDev == 0 {
+			return false, false
+		}
+		z := (current - mean) / stdDev
+		return z <= -parameterOr(params, "reversion_threshold", 2), z >= 0
+	case "momentum":
+		roc := current/window[0] - 1
+		return roc > 0 && upShare >= parameterOr(params, "entry_threshold", 0.6), roc < 0
+	default: // trend_following
+		return current > mean && upShare >= parameterOr(params, "entry_threshold", 0.6), current < mean
+	}
+}
+
+// backtestMetrics computes performance metrics from a backtest's equity curve and trades
+func (m *MarketAdaptationEngine) backtestMetrics(strategyID string, initialCapital float64, candles []ml.PriceData, curve []EquityPoint, trades []BacktestTrade) *MarketPerformanceMetrics {
+	metrics := &MarketPerformanceMetrics{
+		StrategyID:  strategyID,
+		TotalTrades: len(trades),
+		LastUpdated: time.Now(),
+		Metadata:    map[string]interface{}{"source": "backtest"},
+	}
+
+	finalEquity := curve[len(curve)-1].Equity
+	metrics.TotalReturn = finalEquity/initialCapital - 1
+
+	span := candles[len(candles)-1].Timestamp.Sub(candles[0].Timestamp)
+	periodsPerYear := 0.0
+	if span > 0 {
+		years := span.Hours() / (24 * 365)
+		if finalEquity > 0 {
+			metrics.AnnualizedReturn = math.Pow(finalEquity/initialCapital, 1/years) - 1
+		} else {
+			metrics.AnnualizedReturn = -1
+		}
+		periodsPerYear = float64(len(candles)-1) / years
+	}
+
+	returns := make([]float64, 0, len(curve)-1)
+	downside := 0.0
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Equity <= 0 {
+			continue
+		}
+		r := curve[i].Equity/curve[i-1].Equity - 1
+		returns = append(returns, r)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	meanReturn, stdDev := meanStdDev(returns)
+	riskFreePerPeriod := 0.0
+	if periodsPerYear > 0 {
+		riskFreePerPeriod = m.performanceAnalyzer.config.RiskFreeRate / periodsPerYear
+	}
+	metrics.Volatility = stdDev * math.Sqrt(periodsPerYear)
+	if stdDev > 0 {
+		metrics.SharpeRatio = (meanReturn - riskFreePerPeriod) / stdDev * math.Sqrt(periodsPerYear)
+	}
+	if len(returns) > 0 && downside > 0 {
+		downsideDev := math.Sqrt(downside / float64(len(returns)))
+		metrics.SortinoRatio = (meanReturn - riskFreePerPeriod) / downsideDev * math.Sqrt(periodsPerYear)
+	}
+
+	for _, point := range curve {
+		metrics.MaxDrawdown = math.Max(metrics.MaxDrawdown, point.Drawdown)
+	}
+	if metrics.MaxDrawdown > 0 {
+		metrics.RiskAdjustedReturn = metrics.TotalReturn / metrics.MaxDrawdown
+	}
+
+	var grossProfit, grossLoss float64
+	var holdTime time.Duration
+	wins, losses := 0, 0
+	for i, trade := range trades {
+		holdTime += trade.ExitTime.Sub(trade.EntryTime)
+		if i == 0 || trade.Return > metrics.BestTrade {
+			metrics.BestTrade = trade.Return
+		}
+		if i == 0 || trade.Return < metrics.WorstTrade {
+			metrics.WorstTrade = trade.Return
+		}
+
+		if trade.PnL > 0 {
+			metrics.WinningTrades++
+			grossProfit += trade.PnL
+			wins, losses = wins+1, 0
+		} else {
+			metrics.LosingTrades++
+			grossLoss -= trade.PnL
+			wins, losses = 0, losses+1
+		}
+		if wins > metrics.ConsecutiveWins {
+			metrics.ConsecutiveWins = wins
+		}
+		if losses > metrics.ConsecutiveLosses {
+			metrics.ConsecutiveLosses = losses
+		}
+	}
+	if len(trades) > 0 {
+		metrics.WinRate = float64(metrics.WinningTrades) / float64(len(trades))
+		metrics.AverageHoldTime = holdTime / time.Duration(len(trades))
+	}
+	if metrics.WinningTrades > 0 {
+		metrics.AverageWin = grossProfit / float64(metrics.WinningTrades)
+	}
+	if metrics.LosingTrades > 0 {
+		metrics.AverageLoss = grossLoss / float64(metrics.LosingTrades)
+	}
+	if grossLoss > 0 {
+		metrics.ProfitFactor = grossProfit / grossLoss
+	}
+
+	return metrics
+}
+
+// adaptationHistory is the history an adaptation backtest runs over
+type adaptationHistory struct {
+	candles    []ml.PriceData
+	start, end time.Time
+}
+
+// loadAdaptationCandles returns the recent history used to backtest adaptations, keyed by the
+// symbol in each active strategy's metadata. History is fetched again only once it is older
+// than AdaptationCandleTTL; a failed fetch is logged and falls back to the cached history, if any.
+func (m *MarketAdaptationEngine) loadAdaptationCandles(ctx context.Context) map[string]*adaptationHistory {
+	m.candlesMu.Lock()
+	defer m.candlesMu.Unlock()
+
+	m.mu.RLock()
+	source := m.candleSource
+	enabled := m.config.BacktestAdaptations
+	window := m.config.AdaptationBacktestWindow
+	interval := m.config.AdaptationBacktestInterval
+	ttl := m.config.AdaptationCandleTTL
+	symbols := map[string]bool{}
+	for _, strategy := range m.adaptiveStrategies {
+		if symbol := strategySymbol(strategy); strategy.IsActive && symbol != "" {
+			symbols[symbol] = true
+		}
+	}
+	m.mu.RUnlock()
+
+	if !enabled || source == nil || len(symbols) == 0 {
+		return nil
+	}
+	if m.adaptationCandles == nil {
+		m.adaptationCandles = make(map[string]*adaptationHistory)
+	}
+
+	now := time.Now()
+	histories := make(map[string]*adaptationHistory, len(symbols))
+	for symbol := range symbols {
+		cached, exists := m.adaptationCandles[symbol]
+		if exists && now.Sub(cached.end) < ttl {
+			histories[symbol] = cached
+			continue
+		}
+
+		start := now.Add(-window)
+		candles, err := source.GetCandles(ctx, symbol, interval, start, now)
+		if err != nil {
+			m.logger.Warn(ctx, "Failed to load candles for adaptation backtest", map[string]interface{}{
+				"symbol": symbol,
+				"cached": exists,
+				"error":  err.Error(),
+			})
+			if exists {
+				histories[symbol] = cached
+			}
+			continue
+		}
+		history := &adaptationHistory{candles: candles, start: start, end: now}
+		m.adaptationCandles[symbol] = history
+		histories[symbol] = history
+	}
+	return histories
+}
+
+// backtestAdaptation backtests an adaptation's old and new parameters over the same candles
+func (m *MarketAdaptationEngine) backtestAdaptation(ctx context.Context, strategy *AdaptiveStrategy, adaptation *MarketStrategyAdaptation, candles []ml.PriceData, start, end time.Time) (*AdaptationBacktest, error) {
+	before, err := m.Backtest(ctx, strategy, candles, StrategyBacktestOptions{Parameters: adaptation.OldParameters})
+	if err != nil {
+		return nil, err
+	}
+	after, err := m.Backtest(ctx, strategy, candles, StrategyBacktestOptions{Parameters: adaptation.NewParameters})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdaptationBacktest{
+		Symbol:   strategySymbol(strategy),
+		Interval: m.config.AdaptationBacktestInterval,
+		Start:    start,
+		End:      end,
+		Before:   backtestSnapshot(before),
+		After:    backtestSnapshot(after),
+		Improved: after.Metrics.SharpeRatio > before.Metrics.SharpeRatio,
+	}, nil
+}
+
+// backtestSnapshot summarizes a backtest result as a performance snapshot
+func backtestSnapshot(result *StrategyBacktestResult) *PerformanceSnapshot {
+	return &PerformanceSnapshot{
+		Return:       result.Metrics.TotalReturn,
+		Volatility:   result.Metrics.Volatility,
+		SharpeRatio:  result.Metrics.SharpeRatio,
+		MaxDrawdown:  result.Metrics.MaxDrawdown,
+		WinRate:      result.Metrics.WinRate,
+		ProfitFactor: result.Metrics.ProfitFactor,
+		TotalTrades:  result.Metrics.TotalTrades,
+		Timestamp:    result.End,
+	}
+}
+
+// strategySymbol returns the market a strategy trades, from its "symbol" metadata
+func strategySymbol(strategy *AdaptiveStrategy) string {
+	symbol, _ := strategy.Metadata["symbol"].(string)
+	return symbol
+}
+
+// parameterOr returns a strategy parameter, or fallback when it is not set
+func parameterOr(params map[string]float64, name string, fallback float64) float64 {
+	if value, exists := params[name]; exists {
+		return value
+	}
+	return fallback
+}
+
+// copyParameters returns a copy of a parameter map
+func copyParameters(params map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(params))
+	for k, v := range params {
+		copied[k] = v
+	}
+	return copied
+}
+
+// meanStdDev returns the mean and population standard deviation of values
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
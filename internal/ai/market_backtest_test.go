@@ -0,0 +1,256 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/ml"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticCandleSource struct {
+	candles []ml.PriceData
+	calls   int
+	err     error
+}
+
+func (s *staticCandleSource) GetCandles(ctx context.Context, symbol, interval string, start, end time.Time) ([]ml.PriceData, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.candles, nil
+}
+
+// syntheticCandles builds hourly candles whose close follows price(i)
+func syntheticCandles(n int, price func(i int) float64) []ml.PriceData {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]ml.PriceData, n)
+	for i := range candles {
+		closePrice := decimal.NewFromFloat(price(i))
+		candles[i] = ml.PriceData{
+			Symbol:    "BTCUSDT",
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Open:      closePrice,
+			High:      closePrice.Mul(decimal.NewFromFloat(1.001)),
+			Low:       closePrice.Mul(decimal.NewFromFloat(0.999)),
+			Close:     closePrice,
+			Volume:    decimal.NewFromInt(10),
+		}
+	}
+	return candles
+}
+
+func TestBacktest(t *testing.T) {
+	logger := &observability.Logger{}
+	ctx := context.Background()
+
+	uptrend := syntheticCandles(200, func(i int) float64 {
+		return 100 + float64(i) + 2*math.Sin(float64(i))
+	})
+
+	t.Run("TrendFollowingProfitsInUptrend", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		strategy := &AdaptiveStrategy{
+			ID:   "trend",
+			Type: "trend_following",
+			CurrentParameters: map[string]float64{
+				"position_size":   1,
+				"entry_threshold": 0.5,
+			},
+		}
+
+		result, err := engine.Backtest(ctx, strategy, uptrend, StrategyBacktestOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 200, result.Candles)
+		assert.Len(t, result.EquityCurve, 180)
+		assert.NotEmpty(t, result.Trades)
+		assert.Greater(t, result.Metrics.TotalReturn, 0.0)
+		assert.Greater(t, result.Metrics.SharpeRatio, 0.0)
+		assert.InDelta(t, 10000*(1+result.Metrics.TotalReturn), result.EquityCurve[len(result.EquityCurve)-1].Equity, 1e-6)
+		assert.Equal(t, result.Metrics.WinningTrades+result.Metrics.LosingTrades, result.Metrics.TotalTrades)
+		for _, point := range result.EquityCurve {
+			assert.GreaterOrEqual(t, point.Drawdown, 0.0)
+			assert.LessOrEqual(t, point.Drawdown, result.Metrics.MaxDrawdown)
+		}
+	})
+
+	t.Run("StopLossCapsLosses", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		strategy := &AdaptiveStrategy{
+			ID:   "reversion",
+			Type: "mean_reversion",
+			CurrentParameters: map[string]float64{
+				"position_size":       1,
+				"reversion_threshold": 1,
+				"stop_loss":           0.02,
+			},
+		}
+		downtrend := syntheticCandles(100, func(i int) float64 {
+			return 1000 - 5*float64(i)
+		})
+
+		result, err := engine.Backtest(ctx, strategy, downtrend, StrategyBacktestOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Trades)
+		for _, trade := range result.Trades {
+			assert.GreaterOrEqual(t, trade.Return, -0.02-1e-9)
+		}
+		assert.Less(t, result.Metrics.TotalReturn, 0.0)
+	})
+
+	t.Run("ScheduleSuppressesEntries", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		strategy := &AdaptiveStrategy{
+			ID:                "trend",
+			Type:              "trend_following",
+			CurrentParameters: map[string]float64{"position_size": 1, "entry_threshold": 0.5},
+			Schedule: &web3.TradingSchedule{Windows: []web3.TradingWindow{
+				{Start: "09:00", End: "12:00"},
+			}},
+		}
+
+		result, err := engine.Backtest(ctx, strategy, uptrend, StrategyBacktestOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Trades)
+		assert.Greater(t, result.Metrics.SuppressedBySchedule, 0)
+		for _, trade := range result.Trades {
+			assert.True(t, strategy.Schedule.Allows(trade.EntryTime), "entry at %s", trade.EntryTime)
+		}
+
+		strategy.Schedule.Timezone = "Not/AZone"
+		assert.ErrorIs(t, engine.AddAdaptiveStrategy(ctx, strategy), web3.ErrInvalidTradingSchedule)
+	})
+
+	t.Run("ParameterOverride", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		strategy := &AdaptiveStrategy{
+			ID:                "trend",
+			Type:              "trend_following",
+			CurrentParameters: map[string]float64{"position_size": 1},
+		}
+
+		result, err := engine.Backtest(ctx, strategy, uptrend, StrategyBacktestOptions{
+			Parameters: map[string]float64{"position_size": 0},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, result.Metrics.TotalReturn)
+		assert.Equal(t, 0.0, result.Parameters["position_size"])
+		assert.Equal(t, 1.0, strategy.CurrentParameters["position_size"])
+	})
+
+	t.Run("InsufficientCandles", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		strategy := &AdaptiveStrategy{ID: "trend", Type: "trend_following"}
+
+		_, err := engine.Backtest(ctx, strategy, uptrend[:10], StrategyBacktestOptions{})
+		assert.ErrorIs(t, err, ErrInsufficientCandles)
+	})
+
+	t.Run("BacktestStrategyLoadsCandles", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		strategy := &AdaptiveStrategy{
+			Type:              "momentum",
+			CurrentParameters: map[string]float64{"position_size": 0.5},
+		}
+		require.NoError(t, engine.AddAdaptiveStrategy(ctx, strategy))
+
+		_, err := engine.BacktestStrategy(ctx, strategy.ID, "BTCUSDT", "1h", time.Time{}, time.Now(), StrategyBacktestOptions{})
+		assert.ErrorIs(t, err, ErrCandleSourceUnavailable)
+
+		source := &staticCandleSource{candles: uptrend}
+		engine.SetCandleSource(source)
+
+		result, err := engine.BacktestStrategy(ctx, strategy.ID, "BTCUSDT", "1h", time.Time{}, time.Now(), StrategyBacktestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, source.calls)
+		assert.Equal(t, "BTCUSDT", result.Symbol)
+		assert.Equal(t, 0.5, result.Parameters["position_size"])
+
+		_, err = engine.BacktestStrategy(ctx, "missing", "BTCUSDT", "1h", time.Time{}, time.Now(), StrategyBacktestOptions{})
+		assert.ErrorIs(t, err, ErrAdaptiveStrategyNotFound)
+	})
+
+	t.Run("AdaptationRecordsBacktest", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		engine.SetCandleSource(&staticCandleSource{candles: uptrend})
+
+		strategy := &AdaptiveStrategy{
+			Type: "trend_following",
+			CurrentParameters: map[string]float64{
+				"position_size":   0.05,
+				"entry_threshold": 0.5,
+			},
+			PerformanceMetrics: &MarketPerformanceMetrics{SharpeRatio: 0.3},
+			PerformanceTargets: &PerformanceTargets{MinSharpeRatio: 1.0},
+			Metadata:           map[string]interface{}{"symbol": "BTCUSDT"},
+		}
+		require.NoError(t, engine.AddAdaptiveStrategy(ctx, strategy))
+		require.NoError(t, engine.AdaptStrategies(ctx, nil))
+
+		history, err := engine.GetAdaptationHistory(ctx, 0)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+
+		backtest := history[0].Backtest
+		require.NotNil(t, backtest)
+		assert.Equal(t, "BTCUSDT", backtest.Symbol)
+		require.NotNil(t, backtest.Before)
+		require.NotNil(t, backtest.After)
+		// poor_sharpe_ratio shrinks the position, which shrinks the uptrend's return
+		assert.Less(t, backtest.After.Return, backtest.Before.Return)
+	})
+
+	t.Run("AdaptationCandlesAreCached", func(t *testing.T) {
+		engine := NewMarketAdaptationEngine(logger)
+		source := &staticCandleSource{candles: uptrend}
+		engine.SetCandleSource(source)
+		for _, symbol := range []string{"BTCUSDT", "ETHUSDT"} {
+			require.NoError(t, engine.AddAdaptiveStrategy(ctx, &AdaptiveStrategy{
+				Type:              "momentum",
+				CurrentParameters: map[string]float64{"position_size": 0.5},
+				Metadata:          map[string]interface{}{"symbol": symbol},
+			}))
+		}
+
+		first := engine.loadAdaptationCandles(ctx)
+		require.Len(t, first, 2)
+		assert.Equal(t, 2, source.calls)
+
+		// Repeated adaptations reuse the history until it is older than the TTL
+		second := engine.loadAdaptationCandles(ctx)
+		assert.Equal(t, 2, source.calls)
+		assert.Same(t, first["BTCUSDT"], second["BTCUSDT"])
+
+		engine.candlesMu.Lock()
+		engine.adaptationCandles["BTCUSDT"].end = time.Now().Add(-2 * time.Hour)
+		engine.candlesMu.Unlock()
+		refreshed := engine.loadAdaptationCandles(ctx)
+		assert.Equal(t, 3, source.calls, "only the expired symbol is fetched again")
+		assert.NotSame(t, first["BTCUSDT"], refreshed["BTCUSDT"])
+		assert.Same(t, first["ETHUSDT"], refreshed["ETHUSDT"])
+
+		// A failed refresh falls back to the expired history
+		engine.candlesMu.Lock()
+		engine.adaptationCandles["BTCUSDT"].end = time.Now().Add(-2 * time.Hour)
+		engine.candlesMu.Unlock()
+		source.err = errors.New("exchange unavailable")
+		fallback := engine.loadAdaptationCandles(ctx)
+		assert.Equal(t, 4, source.calls)
+		assert.Same(t, refreshed["BTCUSDT"], fallback["BTCUSDT"])
+
+		// A new source starts from an empty cache
+		replacement := &staticCandleSource{candles: uptrend}
+		engine.SetCandleSource(replacement)
+		engine.loadAdaptationCandles(ctx)
+		assert.Equal(t, 2, replacement.calls)
+	})
+}
@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// crowdedFundingRate is the funding rate per period (0.05%) beyond which one side pays
+	// heavily to keep its positions open
+	crowdedFundingRate = 0.0005
+	// crowdedOIChange is the open interest growth over the summary window that shows new
+	// positions piling into the paying side
+	crowdedOIChange = 0.1
+)
+
+// Positioning classifications of perpetual futures markets
+const (
+	PositioningCrowdedLong  = "crowded_long"
+	PositioningCrowdedShort = "crowded_short"
+	PositioningBalanced     = "balanced"
+)
+
+// DerivativesSource reports perpetual futures positioning in a symbol such as BTC or BTCUSDT
+type DerivativesSource interface {
+	GetDerivativesSummary(ctx context.Context, symbol string) (*DerivativesSummary, error)
+}
+
+// DerivativesSummary is the current perpetual futures positioning in a symbol
+type DerivativesSummary struct {
+	FundingRate        float64       `json:"funding_rate"`         // latest rate per funding period
+	OpenInterest       float64       `json:"open_interest"`        // in the base asset
+	OpenInterestChange float64       `json:"open_interest_change"` // share changed over Window
+	Window             time.Duration `json:"window"`
+	Exchanges          []string      `json:"exchanges"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+// Positioning classifies the market as crowded long or short when funding is extreme on one
+// side and open interest is growing, which leaves it prone to a squeeze against that side
+func (d *DerivativesSummary) Positioning() string {
+	return classifyPositioning(d.FundingRate, d.OpenInterestChange)
+}
+
+func classifyPositioning(fundingRate, oiChange float64) string {
+	if oiChange < crowdedOIChange {
+		return PositioningBalanced
+	}
+	switch {
+	case fundingRate >= crowdedFundingRate:
+		return PositioningCrowdedLong
+	case fundingRate <= -crowdedFundingRate:
+		return PositioningCrowdedShort
+	default:
+		return PositioningBalanced
+	}
+}
+
+// SetDerivativesSource enriches DetectPatterns input carrying a "symbol" with the current
+// funding rate and open interest change
+func (m *MarketAdaptationEngine) SetDerivativesSource(source DerivativesSource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.derivativesSource = source
+}
+
+// SetDerivativesSource adds a market structure section with perpetual futures funding and open
+// interest to reports
+func (c *CryptoCoinAnalyzer) SetDerivativesSource(source DerivativesSource) {
+	c.derivatives = source
+}
+
+// withDerivatives returns marketData with "funding_rate" and "open_interest_change" added from
+// the derivatives source. Input that already has a funding rate or no symbol is returned as is.
+func (m *MarketAdaptationEngine) withDerivatives(ctx context.Context, marketData map[string]interface{}) map[string]interface{} {
+	m.mu.RLock()
+	source := m.derivativesSource
+	m.mu.RUnlock()
+
+	symbol, _ := marketData["symbol"].(string)
+	if _, supplied := marketData["funding_rate"]; source == nil || symbol == "" || supplied {
+		return marketData
+	}
+
+	summary, err := source.GetDerivativesSummary(ctx, symbol)
+	if err != nil {
+		m.logger.Warn(ctx, "Failed to load derivatives data for pattern detection", map[string]interface{}{
+			"symbol": symbol,
+			"error":  err.Error(),
+		})
+		return marketData
+	}
+
+	enriched := make(map[string]interface{}, len(marketData)+2)
+	for key, value := range marketData {
+		enriched[key] = value
+	}
+	enriched["funding_rate"] = summary.FundingRate
+	enriched["open_interest_change"] = summary.OpenInterestChange
+	return enriched
+}
+
+// detectPositioningPattern reports crowded perpetual futures positioning from the
+// "funding_rate" and "open_interest_change" inputs
+func detectPositioningPattern(marketData map[string]interface{}) *DetectedPattern {
+	fundingRate, ok := marketData["funding_rate"].(float64)
+	if !ok {
+		return nil
+	}
+	oiChange, _ := marketData["open_interest_change"].(float64)
+
+	positioning := classifyPositioning(fundingRate, oiChange)
+	if positioning == PositioningBalanced {
+		return nil
+	}
+
+	asset, _ := marketData["symbol"].(string)
+	if asset == "" {
+		asset = "BTC"
+	}
+	// Strength grows with how far funding is past the threshold, saturating at three times it
+	strength := math.Min(math.Abs(fundingRate)/crowdedFundingRate/3, 1)
+
+	pattern := &DetectedPattern{
+		ID:          uuid.New().String(),
+		Type:        "positioning",
+		Name:        "Crowded Long",
+		Description: "Longs pay elevated funding while open interest grows, raising long squeeze risk",
+		Asset:       asset,
+		TimeFrame:   "8h",
+		Strength:    strength,
+		Confidence:  0.6 + 0.3*strength,
+		Duration:    8 * time.Hour,
+		Characteristics: map[string]float64{
+			"funding_rate":         fundingRate,
+			"open_interest_change": oiChange,
+		},
+		TriggerConditions: []*TriggerCondition{
+			{
+				Type:       "indicator",
+				Indicator:  "funding_rate",
+				Operator:   "gt",
+				Value:      crowdedFundingRate,
+				Timeframe:  "8h",
+				Confidence: 0.7,
+			},
+			{
+				Type:       "indicator",
+				Indicator:  "open_interest_change",
+				Operator:   "gt",
+				Value:      crowdedOIChange,
+				Timeframe:  "24h",
+				Confidence: 0.7,
+			},
+		},
+		ExpectedOutcome: &ExpectedOutcome{
+			Direction:   "down",
+			Magnitude:   0.05,
+			Probability: 0.55 + 0.2*strength,
+			TimeHorizon: 24 * time.Hour,
+			Confidence:  0.6 + 0.3*strength,
+		},
+		Metadata: map[string]interface{}{"positioning": positioning},
+	}
+	if positioning == PositioningCrowdedShort {
+		pattern.Name = "Crowded Short"
+		pattern.Description = "Shorts pay elevated funding while open interest grows, raising short squeeze risk"
+		pattern.TriggerConditions[0].Operator = "lt"
+		pattern.TriggerConditions[0].Value = -crowdedFundingRate
+		pattern.ExpectedOutcome.Direction = "up"
+	}
+	return pattern
+}
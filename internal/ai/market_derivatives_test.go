@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubDerivativesSource struct {
+	summaries map[string]*DerivativesSummary
+	calls     int
+}
+
+func (s *stubDerivativesSource) GetDerivativesSummary(ctx context.Context, symbol string) (*DerivativesSummary, error) {
+	s.calls++
+	if summary, ok := s.summaries[symbol]; ok {
+		return summary, nil
+	}
+	return nil, ErrCandleSourceUnavailable
+}
+
+func TestDetectPatterns_DerivativesPositioning(t *testing.T) {
+	ctx := context.Background()
+	engine := NewMarketAdaptationEngine(&observability.Logger{})
+	source := &stubDerivativesSource{summaries: map[string]*DerivativesSummary{
+		"BTCUSDT": {FundingRate: 0.0012, OpenInterestChange: 0.25, Window: 24 * time.Hour},
+		"ETHUSDT": {FundingRate: -0.0009, OpenInterestChange: 0.15, Window: 24 * time.Hour},
+		"SOLUSDT": {FundingRate: 0.0012, OpenInterestChange: 0.02, Window: 24 * time.Hour},
+	}}
+	engine.SetDerivativesSource(source)
+
+	positioning := func(symbol string) *DetectedPattern {
+		patterns, err := engine.DetectPatterns(ctx, map[string]interface{}{"symbol": symbol})
+		require.NoError(t, err)
+		for _, pattern := range patterns {
+			if pattern.Type == "positioning" {
+				return pattern
+			}
+		}
+		return nil
+	}
+
+	long := positioning("BTCUSDT")
+	require.NotNil(t, long)
+	assert.Equal(t, "Crowded Long", long.Name)
+	assert.Equal(t, "down", long.ExpectedOutcome.Direction)
+	assert.Equal(t, 0.0012, long.Characteristics["funding_rate"])
+
+	short := positioning("ETHUSDT")
+	require.NotNil(t, short)
+	assert.Equal(t, "up", short.ExpectedOutcome.Direction)
+
+	// Extreme funding without growing open interest isn't crowded
+	assert.Nil(t, positioning("SOLUSDT"))
+
+	// Supplied funding data isn't overridden and a failing source doesn't fail detection
+	calls := source.calls
+	patterns, err := engine.DetectPatterns(ctx, map[string]interface{}{"symbol": "BTCUSDT", "funding_rate": 0.0001})
+	require.NoError(t, err)
+	assert.Empty(t, patterns)
+	assert.Equal(t, calls, source.calls)
+	_, err = engine.DetectPatterns(ctx, map[string]interface{}{"symbol": "DOGEUSDT"})
+	assert.NoError(t, err)
+}
+
+func TestStructuredReport_MarketStructure(t *testing.T) {
+	generator := NewCryptoAnalysisReportGenerator(&observability.Logger{})
+	report := &CoinAnalysisReport{
+		Symbol: "BTC",
+		MarketStructure: &MarketStructure{
+			FundingRate:        decimal.NewFromFloat(0.12),
+			OpenInterest:       decimal.NewFromInt(95000),
+			OpenInterestChange: decimal.NewFromFloat(25),
+			Window:             "24h",
+			Positioning:        PositioningCrowdedLong,
+			Exchanges:          []string{"binance", "bybit"},
+		},
+	}
+
+	output := generator.GenerateStructuredReport(report)
+	section := output[strings.Index(output, "## MARKET STRUCTURE"):]
+	assert.Contains(t, section, "- Funding Rate: +0.1200% per period")
+	assert.Contains(t, section, "(+25.00% over 24h)")
+	assert.Contains(t, section, "- Positioning: Crowded long")
+
+	report.MarketStructure = nil
+	assert.Contains(t, generator.GenerateStructuredReport(report), "- Perpetual futures data unavailable")
+}
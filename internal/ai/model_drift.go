@@ -0,0 +1,289 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/ml"
+)
+
+const (
+	// driftBins is how many reference quantiles split the error distribution for the PSI
+	driftBins = 10
+	// driftEpsilon stands in for empty bins, whose log ratio would otherwise be infinite
+	driftEpsilon = 1e-4
+	// driftStdFloor keeps the mean shift finite when the reference errors barely vary
+	driftStdFloor = 0.01
+)
+
+// ErrInvalidDriftConfig is returned for drift settings that could never detect drift
+var ErrInvalidDriftConfig = errors.New("invalid drift config")
+
+// ModelDriftConfig controls drift detection for a model. The first WindowSize feedback
+// errors form the reference distribution; the latest WindowSize are compared against it.
+type ModelDriftConfig struct {
+	WindowSize         int     `json:"window_size"`
+	MinSamples         int     `json:"min_samples"`          // current errors needed before checking
+	PSIThreshold       float64 `json:"psi_threshold"`        // population stability index
+	MeanShiftThreshold float64 `json:"mean_shift_threshold"` // in reference standard deviations
+	AutoRetrain        bool    `json:"auto_retrain"`         // queue a drift adaptation when drift is found
+}
+
+// DefaultModelDriftConfig returns the drift settings models start with. A PSI above 0.25 is
+// the usual mark of a significant population shift.
+func DefaultModelDriftConfig() ModelDriftConfig {
+	return ModelDriftConfig{
+		WindowSize:         100,
+		MinSamples:         30,
+		PSIThreshold:       0.25,
+		MeanShiftThreshold: 3,
+		AutoRetrain:        true,
+	}
+}
+
+func (c ModelDriftConfig) validate() error {
+	switch {
+	case c.WindowSize < 2:
+		return fmt.Errorf("%w: window_size must be at least 2", ErrInvalidDriftConfig)
+	case c.MinSamples < 2 || c.MinSamples > c.WindowSize:
+		return fmt.Errorf("%w: min_samples must be between 2 and window_size", ErrInvalidDriftConfig)
+	case c.PSIThreshold <= 0 || c.MeanShiftThreshold <= 0:
+		return fmt.Errorf("%w: thresholds must be positive", ErrInvalidDriftConfig)
+	}
+	return nil
+}
+
+// ModelDriftEvidence describes the error distributions that triggered a drift detection
+type ModelDriftEvidence struct {
+	PSI              float64   `json:"psi"`
+	MeanShift        float64   `json:"mean_shift"`
+	ReferenceMean    float64   `json:"reference_mean"`
+	CurrentMean      float64   `json:"current_mean"`
+	ReferenceStd     float64   `json:"reference_std"`
+	Samples          int       `json:"samples"`
+	ReferenceSamples int       `json:"reference_samples"`
+	RetrainRequested bool      `json:"retrain_requested"`
+	DetectedAt       time.Time `json:"detected_at"`
+}
+
+// ModelDriftStatus is the drift state of a model as shown in its status
+type ModelDriftStatus struct {
+	Score            float64             `json:"score"` // PSI at the last check
+	MeanShift        float64             `json:"mean_shift"`
+	Drifted          bool                `json:"drifted"` // whether the last check found drift
+	Samples          int                 `json:"samples"`
+	ReferenceSamples int                 `json:"reference_samples"`
+	LastCheck        *time.Time          `json:"last_check,omitempty"`
+	LastDrift        *ModelDriftEvidence `json:"last_drift,omitempty"`
+	Config           ModelDriftConfig    `json:"config"`
+}
+
+// ModelStatus is a model's version information along with its drift state and, for models
+// whose predictions are evaluated, their accuracy over the last 30 days
+type ModelStatus struct {
+	*ml.VersionedModelInfo
+	Drift      *ModelDriftStatus   `json:"drift,omitempty"`
+	Evaluation *PredictionAccuracy `json:"evaluation,omitempty"`
+}
+
+// modelDriftMonitor tracks the prediction error distribution of each model fed back to it
+type modelDriftMonitor struct {
+	mu     sync.Mutex
+	models map[string]*modelDriftState
+}
+
+type modelDriftState struct {
+	config    ModelDriftConfig
+	reference []float64
+	current   []float64
+	status    ModelDriftStatus
+}
+
+func newModelDriftMonitor() *modelDriftMonitor {
+	return &modelDriftMonitor{models: make(map[string]*modelDriftState)}
+}
+
+// stateLocked returns the state of a model, creating it with the default config
+func (m *modelDriftMonitor) stateLocked(modelID string) *modelDriftState {
+	state, ok := m.models[modelID]
+	if !ok {
+		state = &modelDriftState{config: DefaultModelDriftConfig()}
+		m.models[modelID] = state
+	}
+	return state
+}
+
+// configure replaces a model's drift settings. Collected errors are kept, trimmed to the new
+// window.
+func (m *modelDriftMonitor) configure(modelID string, config ModelDriftConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.stateLocked(modelID)
+	state.config = config
+	if len(state.reference) > config.WindowSize {
+		state.reference = state.reference[:config.WindowSize]
+	}
+	if len(state.current) > config.WindowSize {
+		state.current = state.current[len(state.current)-config.WindowSize:]
+	}
+	return nil
+}
+
+func (m *modelDriftMonitor) config(modelID string) ModelDriftConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stateLocked(modelID).config
+}
+
+func (m *modelDriftMonitor) status(modelID string) *ModelDriftStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.stateLocked(modelID)
+	status := state.status
+	status.Samples = len(state.current)
+	status.ReferenceSamples = len(state.reference)
+	status.Config = state.config
+	return &status
+}
+
+// record adds a prediction error and checks for drift once enough errors were collected.
+// When drift is found the current window becomes the new reference, so the same shift is
+// reported once, and the evidence is returned along with the config it was found under.
+func (m *modelDriftMonitor) record(modelID string, predictionError float64, now time.Time) (*ModelDriftEvidence, ModelDriftConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.stateLocked(modelID)
+	config := state.config
+	if len(state.reference) < config.WindowSize {
+		state.reference = append(state.reference, predictionError)
+		return nil, config
+	}
+
+	state.current = append(state.current, predictionError)
+	if len(state.current) > config.WindowSize {
+		state.current = state.current[len(state.current)-config.WindowSize:]
+	}
+	if len(state.current) < config.MinSamples {
+		return nil, config
+	}
+
+	refMean, refStd := meanStd(state.reference)
+	curMean, _ := meanStd(state.current)
+	psi := populationStabilityIndex(state.reference, state.current)
+	shift := math.Abs(curMean-refMean) / math.Max(refStd, driftStdFloor)
+
+	checked := now
+	state.status.Score = psi
+	state.status.MeanShift = shift
+	state.status.LastCheck = &checked
+	state.status.Drifted = psi > config.PSIThreshold || shift > config.MeanShiftThreshold
+	if !state.status.Drifted {
+		return nil, config
+	}
+
+	evidence := &ModelDriftEvidence{
+		PSI:              psi,
+		MeanShift:        shift,
+		ReferenceMean:    refMean,
+		CurrentMean:      curMean,
+		ReferenceStd:     refStd,
+		Samples:          len(state.current),
+		ReferenceSamples: len(state.reference),
+		RetrainRequested: config.AutoRetrain,
+		DetectedAt:       now,
+	}
+	state.status.LastDrift = evidence
+	state.reference = state.current
+	state.current = nil
+	return evidence, config
+}
+
+// feedbackError measures how wrong a prediction was: the relative error when the feedback
+// carries the predicted value and a numeric actual value, else 0 or 1 from Correct
+func feedbackError(feedback *ml.PredictionFeedback) float64 {
+	actual, okActual := driftFloat(feedback.ActualValue)
+	predicted, okPredicted := driftFloat(feedback.Metadata["predicted_value"])
+	if okActual && okPredicted {
+		return math.Abs(predicted-actual) / math.Max(math.Abs(actual), 1e-9)
+	}
+	if feedback.Correct {
+		return 0
+	}
+	return 1
+}
+
+func driftFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// populationStabilityIndex compares current against reference over bins cut at the
+// reference deciles
+func populationStabilityIndex(reference, current []float64) float64 {
+	sorted := append([]float64(nil), reference...)
+	sort.Float64s(sorted)
+
+	var edges []float64
+	for i := 1; i < driftBins; i++ {
+		edge := sorted[i*len(sorted)/driftBins]
+		if len(edges) == 0 || edge > edges[len(edges)-1] {
+			edges = append(edges, edge)
+		}
+	}
+
+	refShare := binShares(reference, edges)
+	curShare := binShares(current, edges)
+	var psi float64
+	for i := range refShare {
+		psi += (curShare[i] - refShare[i]) * math.Log(curShare[i]/refShare[i])
+	}
+	return psi
+}
+
+func binShares(values, edges []float64) []float64 {
+	shares := make([]float64, len(edges)+1)
+	for _, v := range values {
+		shares[sort.SearchFloat64s(edges, v)]++
+	}
+	for i := range shares {
+		shares[i] = math.Max(shares[i]/float64(len(values)), driftEpsilon)
+	}
+	return shares
+}
@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/ml"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func priceFeedback(predicted, actual float64) *ml.PredictionFeedback {
+	return &ml.PredictionFeedback{
+		PredictionID: uuid.New().String(),
+		ActualValue:  actual,
+		Correct:      predicted == actual,
+		Timestamp:    time.Now(),
+		Metadata:     map[string]interface{}{"predicted_value": predicted},
+	}
+}
+
+func TestModelDriftDetection(t *testing.T) {
+	ctx := context.Background()
+	service := NewEnhancedAIService(&observability.Logger{})
+	config := ModelDriftConfig{WindowSize: 50, MinSamples: 25, PSIThreshold: 0.25, MeanShiftThreshold: 3, AutoRetrain: true}
+	require.NoError(t, service.SetDriftConfig("price_prediction", config))
+
+	// Errors of 1-2% build the reference, then keep the same distribution
+	for i := 0; i < 100; i++ {
+		require.NoError(t, service.ProvideFeedback(ctx, "price_prediction", priceFeedback(100+float64(1+i%2), 100)))
+	}
+	drift := service.GetModelStatus(ctx)["price_prediction"].Drift
+	require.NotNil(t, drift.LastCheck)
+	assert.False(t, drift.Drifted)
+	assert.Nil(t, drift.LastDrift)
+	assert.Equal(t, 50, drift.ReferenceSamples)
+	assert.Empty(t, service.adaptiveModelManager.adaptationQueue)
+
+	// A single outlier is not drift
+	require.NoError(t, service.ProvideFeedback(ctx, "price_prediction", priceFeedback(110, 100)))
+	assert.False(t, service.GetModelStatus(ctx)["price_prediction"].Drift.Drifted)
+
+	// Errors jump to 10-11%
+	var detected *ModelDriftStatus
+	for i := 0; i < 25 && detected == nil; i++ {
+		require.NoError(t, service.ProvideFeedback(ctx, "price_prediction", priceFeedback(100+float64(10+i%2), 100)))
+		if drift := service.GetModelStatus(ctx)["price_prediction"].Drift; drift.Drifted {
+			detected = drift
+		}
+	}
+	require.NotNil(t, detected)
+	require.NotNil(t, detected.LastDrift)
+	assert.True(t, detected.LastDrift.PSI > config.PSIThreshold || detected.LastDrift.MeanShift > config.MeanShiftThreshold)
+	assert.Greater(t, detected.LastDrift.CurrentMean, detected.LastDrift.ReferenceMean)
+	assert.True(t, detected.LastDrift.RetrainRequested)
+	assert.Zero(t, detected.Samples, "the drifted window becomes the new reference")
+
+	require.Len(t, service.adaptiveModelManager.adaptationQueue, 1)
+	request := service.adaptiveModelManager.adaptationQueue[0]
+	assert.Equal(t, "drift", request.Type)
+	assert.Equal(t, "concept_drift", request.Trigger)
+	assert.Equal(t, "price_prediction", request.ModelID)
+}
+
+func TestModelDriftConfig(t *testing.T) {
+	ctx := context.Background()
+	service := NewEnhancedAIService(&observability.Logger{})
+
+	config, err := service.DriftConfig("price_prediction")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultModelDriftConfig(), config)
+
+	_, err = service.DriftConfig("unknown")
+	assert.ErrorIs(t, err, ml.ErrModelNotFound)
+	assert.ErrorIs(t, service.SetDriftConfig("unknown", config), ml.ErrModelNotFound)
+
+	invalid := config
+	invalid.MinSamples = invalid.WindowSize + 1
+	assert.ErrorIs(t, service.SetDriftConfig("price_prediction", invalid), ErrInvalidDriftConfig)
+
+	t.Run("AutoRetrainDisabled", func(t *testing.T) {
+		require.NoError(t, service.SetDriftConfig("sentiment_analysis", ModelDriftConfig{
+			WindowSize: 10, MinSamples: 5, PSIThreshold: 0.25, MeanShiftThreshold: 3,
+		}))
+
+		// Correct predictions, then a run of wrong ones
+		for i := 0; i < 20; i++ {
+			require.NoError(t, service.ProvideFeedback(ctx, "sentiment_analysis", &ml.PredictionFeedback{
+				PredictionID: uuid.New().String(),
+				Correct:      i < 10,
+			}))
+		}
+
+		drift := service.GetModelStatus(ctx)["sentiment_analysis"].Drift
+		require.NotNil(t, drift.LastDrift)
+		assert.False(t, drift.LastDrift.RetrainRequested)
+		assert.False(t, drift.Config.AutoRetrain)
+		assert.Empty(t, service.adaptiveModelManager.adaptationQueue)
+	})
+}
+
+func TestPopulationStabilityIndex(t *testing.T) {
+	reference := make([]float64, 100)
+	for i := range reference {
+		reference[i] = float64(i) / 100
+	}
+	assert.InDelta(t, 0, populationStabilityIndex(reference, reference), 1e-9)
+
+	shifted := make([]float64, 100)
+	for i := range shifted {
+		shifted[i] = 0.5 + float64(i)/200
+	}
+	assert.Greater(t, populationStabilityIndex(reference, shifted), 0.25)
+}
@@ -1,8 +1,13 @@
 package ai
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -746,3 +751,87 @@ func TestOCREngine(t *testing.T) {
 		assert.GreaterOrEqual(t, block.BoundingBox.Height, 0.0)
 	})
 }
+
+func TestMultiModalEngine_StreamingDocument(t *testing.T) {
+	logger := &observability.Logger{}
+	engine := NewMultiModalEngine(logger)
+	engine.config.StagingDir = t.TempDir()
+	ctx := context.Background()
+
+	// Large enough to span several extraction chunks, with a multi-byte rune on every line
+	var document strings.Builder
+	for document.Len() < 3*documentChunkSize {
+		document.WriteString("Bitcoin revenue grew 15% — strong quarter\n")
+	}
+	documentText := document.String()
+
+	staged, err := engine.StageContent(ctx, strings.NewReader(documentText), engine.MaxContentSize("document"))
+	require.NoError(t, err)
+	defer staged.Remove()
+	assert.Equal(t, int64(len(documentText)), staged.Size)
+	sum := sha256.Sum256([]byte(documentText))
+	assert.Equal(t, hex.EncodeToString(sum[:]), staged.SHA256)
+
+	newRequest := func(content MultiModalContent) *MultiModalRequest {
+		return &MultiModalRequest{
+			RequestID:   uuid.New().String(),
+			UserID:      uuid.New(),
+			Type:        "document",
+			Content:     []MultiModalContent{content},
+			Options:     MultiModalOptions{ExtractText: true},
+			RequestedAt: time.Now(),
+		}
+	}
+
+	streamed, err := engine.ProcessMultiModalRequest(ctx, newRequest(MultiModalContent{
+		ID: uuid.New().String(), Type: "document", MimeType: "text/plain", Filename: "report.txt",
+		Size: staged.Size, Source: staged,
+	}))
+	require.NoError(t, err)
+	encoded, err := engine.ProcessMultiModalRequest(ctx, newRequest(MultiModalContent{
+		ID: uuid.New().String(), Type: "document", MimeType: "text/plain", Filename: "report.txt",
+		Size: int64(len(documentText)), Data: base64.StdEncoding.EncodeToString([]byte(documentText)),
+	}))
+	require.NoError(t, err)
+
+	// Both paths produce the same analysis and stats
+	assert.Positive(t, streamed.ProcessingTime)
+	assert.Equal(t, encoded.AggregatedData.ProcessingStats, streamed.AggregatedData.ProcessingStats)
+	streamedDoc, encodedDoc := streamed.Results[0].DocumentAnalysis, encoded.Results[0].DocumentAnalysis
+	require.NotNil(t, streamedDoc)
+	assert.Equal(t, encodedDoc.ExtractedText, streamedDoc.ExtractedText)
+	assert.Equal(t, strings.TrimSpace(documentText), streamedDoc.ExtractedText)
+	assert.Equal(t, 7*strings.Count(documentText, "\n"), streamedDoc.Structure.WordCount)
+	assert.Equal(t, staged.SHA256, streamedDoc.Structure.Metadata["sha256"])
+	assert.Equal(t, 4, streamedDoc.Structure.Metadata["chunks"])
+
+	t.Run("SizeLimit", func(t *testing.T) {
+		_, err := engine.StageContent(ctx, strings.NewReader(documentText), 1024)
+		assert.ErrorIs(t, err, ErrContentTooLarge)
+		entries, err := os.ReadDir(engine.config.StagingDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "rejected uploads must not leave staging files behind")
+	})
+
+	t.Run("ShouldStream", func(t *testing.T) {
+		assert.False(t, engine.ShouldStream(1024))
+		assert.True(t, engine.ShouldStream(engine.config.StreamingThreshold+1))
+		assert.True(t, engine.ShouldStream(-1))
+	})
+}
+
+func TestExtractDocumentText_Binary(t *testing.T) {
+	// A PDF-like stream whose page markers and text straddle chunk boundaries
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n1 0 obj << /Type /Pages /Count 3 >>\n")
+	for page := 0; page < 3; page++ {
+		pdf.Write(bytes.Repeat([]byte{0x00, 0xff}, documentChunkSize/2-7))
+		pdf.WriteString("<< /Type /Page >> BT (Quarterly ETH outlook) ET\n")
+	}
+
+	text, err := extractDocumentText(context.Background(), &pdf, "application/pdf", "report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, 3, text.PageCount)
+	assert.Equal(t, 3, strings.Count(text.Text, "Quarterly ETH outlook"))
+	assert.NotContains(t, text.Text, "\x00")
+}
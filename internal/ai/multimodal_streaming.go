@@ -0,0 +1,254 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrContentTooLarge is returned when streamed content exceeds the limit for its type
+var ErrContentTooLarge = errors.New("content exceeds size limit")
+
+const (
+	documentChunkSize    = 256 * 1024
+	maxExtractedTextSize = 1 << 20 // text kept in the result; words and pages are counted over the whole document
+	minBinaryTextRun     = 4       // shortest printable run taken from binary formats
+)
+
+// pdfPagePattern matches page objects but not the /Pages tree node
+var pdfPagePattern = regexp.MustCompile(`/Type\s{0,3}/Page[^s]`)
+
+// ContentSource supplies content bytes as a stream, for uploads too large to hold base64-encoded
+// in a request. Open may be called once per analysis step.
+type ContentSource interface {
+	Open() (io.ReadCloser, error)
+}
+
+// StagedContent is content streamed to a temporary file
+type StagedContent struct {
+	Path   string
+	Size   int64
+	SHA256 string
+}
+
+// Open returns a reader over the staged bytes
+func (s *StagedContent) Open() (io.ReadCloser, error) {
+	return os.Open(s.Path)
+}
+
+// Remove deletes the staged file
+func (s *StagedContent) Remove() error {
+	return os.Remove(s.Path)
+}
+
+// StageContent streams r to a temporary file, computing its checksum on the way, and fails
+// with ErrContentTooLarge as soon as more than maxSize bytes arrive.
+func (m *MultiModalEngine) StageContent(ctx context.Context, r io.Reader, maxSize int64) (*StagedContent, error) {
+	file, err := os.CreateTemp(m.config.StagingDir, "multimodal-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging file: %w", err)
+	}
+	staged := &StagedContent{Path: file.Name()}
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(&contextReader{ctx: ctx, r: r}, maxSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > maxSize {
+		err = fmt.Errorf("%w: more than %d bytes", ErrContentTooLarge, maxSize)
+	}
+	if err != nil {
+		os.Remove(staged.Path)
+		return nil, err
+	}
+
+	staged.Size = written
+	staged.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return staged, nil
+}
+
+// ShouldStream reports whether an upload of the given size should be staged and streamed
+// rather than base64-encoded into the request. Unknown sizes (negative) are always streamed.
+func (m *MultiModalEngine) ShouldStream(size int64) bool {
+	return size < 0 || size > m.config.StreamingThreshold
+}
+
+// MaxContentSize returns the size limit for a content type
+func (m *MultiModalEngine) MaxContentSize(contentType string) int64 {
+	return m.getMaxSizeForType(contentType)
+}
+
+// openContent returns a reader over the raw bytes of a content item, decoding base64 data.
+// Data that is not valid base64 is treated as plain text.
+func openContent(content MultiModalContent) (io.ReadCloser, error) {
+	if content.Source != nil {
+		return content.Source.Open()
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(content.Data); err == nil {
+		return io.NopCloser(bytes.NewReader(decoded)), nil
+	}
+	return io.NopCloser(strings.NewReader(content.Data)), nil
+}
+
+// documentText is the result of incremental text extraction
+type documentText struct {
+	Text      string
+	Truncated bool
+	WordCount int
+	PageCount int
+	Bytes     int64
+	Chunks    int
+	SHA256    string
+}
+
+// extractDocumentText reads a document chunk by chunk, keeping memory bounded regardless of
+// its size. Text formats are decoded as UTF-8; other formats contribute their printable runs.
+func extractDocumentText(ctx context.Context, r io.Reader, mimeType, filename string) (*documentText, error) {
+	plain := isPlainTextDocument(mimeType, filename)
+	result := &documentText{}
+	hash := sha256.New()
+
+	var text strings.Builder
+	var pending []byte // incomplete UTF-8 sequence or printable run carried into the next chunk
+	var pageTail []byte
+	inWord := false
+
+	emit := func(s string) {
+		for _, ch := range s {
+			if unicode.IsSpace(ch) {
+				inWord = false
+			} else if !inWord {
+				inWord = true
+				result.WordCount++
+			}
+		}
+		if remaining := maxExtractedTextSize - text.Len(); remaining > 0 {
+			if len(s) > remaining {
+				s = strings.ToValidUTF8(s[:remaining], "")
+				result.Truncated = true
+			}
+			text.WriteString(s)
+		} else if s != "" {
+			result.Truncated = true
+		}
+	}
+
+	buf := make([]byte, documentChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := buf[:n]
+			hash.Write(chunk)
+			result.Bytes += int64(n)
+			result.Chunks++
+
+			// Count pages on the raw bytes, overlapping the previous chunk so markers split across chunks are seen
+			window := append(pageTail, chunk...)
+			for _, match := range pdfPagePattern.FindAllIndex(window, -1) {
+				if match[1] > len(pageTail) {
+					result.PageCount++
+				}
+			}
+			pageTail = append([]byte(nil), window[max(0, len(window)-16):]...)
+
+			data := append(pending, chunk...)
+			if plain {
+				cut := len(data)
+				for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+					if utf8.RuneStart(data[i]) {
+						if !utf8.FullRune(data[i:]) {
+							cut = i
+						}
+						break
+					}
+				}
+				emit(strings.ToValidUTF8(string(data[:cut]), ""))
+				pending = append([]byte(nil), data[cut:]...)
+			} else {
+				consumed := emitPrintableRuns(data, emit)
+				pending = append([]byte(nil), data[consumed:]...)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if plain {
+		emit(strings.ToValidUTF8(string(pending), ""))
+	} else if len(pending) >= minBinaryTextRun {
+		emit(string(pending) + "\n")
+	}
+
+	result.Text = strings.TrimSpace(text.String())
+	result.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return result, nil
+}
+
+// emitPrintableRuns emits each run of printable ASCII long enough to be text and returns how
+// many bytes were consumed. A run reaching the end of data is left for the next chunk unless
+// it already spans a whole chunk.
+func emitPrintableRuns(data []byte, emit func(string)) int {
+	start := -1
+	for i, b := range data {
+		printable := b == '\t' || b == '\n' || b == '\r' || (b >= 0x20 && b < 0x7f)
+		if printable {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minBinaryTextRun {
+			emit(string(data[start:i]) + "\n")
+		}
+		start = -1
+	}
+	if start >= 0 && len(data)-start <= documentChunkSize {
+		return start
+	}
+	if start >= 0 {
+		emit(string(data[start:]))
+	}
+	return len(data)
+}
+
+func isPlainTextDocument(mimeType, filename string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if strings.HasPrefix(mimeType, "text/") || strings.Contains(mimeType, "json") || strings.Contains(mimeType, "csv") {
+		return true
+	}
+	switch strings.ToLower(filename[strings.LastIndex(filename, ".")+1:]) {
+	case "txt", "csv", "md", "json":
+		return true
+	}
+	return false
+}
+
+// contextReader stops a copy when its context is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,649 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+)
+
+// News source names used for configuration and item tagging
+const (
+	NewsProviderWebSearch   = "web_search"
+	NewsProviderCryptoPanic = "cryptopanic"
+	NewsProviderReddit      = "reddit"
+	newsProviderRSSPrefix   = "rss:"
+)
+
+const (
+	defaultNewsProviderTimeout = 10 * time.Second
+	maxNewsItemsPerSource      = 20
+	maxNewsResponseBytes       = 2 << 20
+	// newsTitleSimilarity is the share of common headline words above which two items are one story
+	newsTitleSimilarity = 0.8
+)
+
+// coinAssetClasses groups well-known coins so sources such as Reddit can follow per-class communities
+var coinAssetClasses = map[string]string{
+	"BTC": "layer1", "ETH": "layer1", "SOL": "layer1", "ADA": "layer1", "AVAX": "layer1",
+	"DOT": "layer1", "ATOM": "layer1", "NEAR": "layer1", "XRP": "layer1", "LTC": "layer1",
+	"UNI": "defi", "AAVE": "defi", "MKR": "defi", "COMP": "defi", "CRV": "defi",
+	"LDO": "defi", "SUSHI": "defi", "LINK": "defi",
+	"DOGE": "meme", "SHIB": "meme", "PEPE": "meme", "BONK": "meme", "WIF": "meme",
+	"USDT": "stablecoin", "USDC": "stablecoin", "DAI": "stablecoin",
+	"MATIC": "layer2", "ARB": "layer2", "OP": "layer2",
+}
+
+// coinNames lets feeds that spell out coin names match their symbols
+var coinNames = map[string]string{
+	"BTC": "bitcoin", "ETH": "ethereum", "SOL": "solana", "ADA": "cardano", "XRP": "ripple",
+	"DOGE": "dogecoin", "DOT": "polkadot", "AVAX": "avalanche", "MATIC": "polygon",
+	"LINK": "chainlink", "UNI": "uniswap", "LTC": "litecoin", "ATOM": "cosmos",
+}
+
+// coinAssetClass returns the asset class of a symbol, defaulting to altcoin
+func coinAssetClass(symbol string) string {
+	if class, ok := coinAssetClasses[strings.ToUpper(symbol)]; ok {
+		return class
+	}
+	return "altcoin"
+}
+
+// NewsProvider fetches news articles or social posts about a coin
+type NewsProvider interface {
+	// Name identifies the source in configuration and on the items it returns
+	Name() string
+	// Describe returns how the source is listed in a report's data sources
+	Describe() DataSource
+	FetchNews(ctx context.Context, symbol, assetClass string) ([]NewsItem, error)
+}
+
+// NewsProviderRegistry holds the news sources consulted by coin analysis
+type NewsProviderRegistry struct {
+	sources  []NewsProvider
+	disabled map[string]bool
+	timeout  time.Duration
+	mu       sync.RWMutex
+}
+
+// NewNewsProviderRegistry creates an empty registry fetching each source within timeout
+func NewNewsProviderRegistry(timeout time.Duration) *NewsProviderRegistry {
+	if timeout <= 0 {
+		timeout = defaultNewsProviderTimeout
+	}
+	return &NewsProviderRegistry{
+		disabled: make(map[string]bool),
+		timeout:  timeout,
+	}
+}
+
+// Register adds a source, replacing any source with the same name
+func (r *NewsProviderRegistry) Register(source NewsProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.sources {
+		if existing.Name() == source.Name() {
+			r.sources[i] = source
+			return
+		}
+	}
+	r.sources = append(r.sources, source)
+}
+
+// SetEnabled enables or disables a source by name
+func (r *NewsProviderRegistry) SetEnabled(name string, enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabled[name] = !enabled
+}
+
+// SetTimeout sets the per-source fetch timeout
+func (r *NewsProviderRegistry) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeout = timeout
+}
+
+// enabled returns the enabled sources in registration order and the fetch timeout
+func (r *NewsProviderRegistry) enabled() ([]NewsProvider, time.Duration) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sources := make([]NewsProvider, 0, len(r.sources))
+	for _, source := range r.sources {
+		if !r.disabled[source.Name()] {
+			sources = append(sources, source)
+		}
+	}
+	return sources, r.timeout
+}
+
+// ConfigureNewsSources registers the RSS feeds, CryptoPanic and Reddit sources from cfg alongside
+// web search, and disables the sources cfg lists
+func (c *CryptoCoinAnalyzer) ConfigureNewsSources(cfg config.NewsSourcesConfig) {
+	for _, feed := range cfg.RSSFeeds {
+		name, feedURL, found := strings.Cut(feed, "=")
+		if !found {
+			feedURL = feed
+			if parsed, err := url.Parse(feed); err == nil && parsed.Host != "" {
+				name = parsed.Host
+			}
+		}
+		c.newsProviders.Register(NewRSSNewsProvider(strings.TrimSpace(name), strings.TrimSpace(feedURL), c.httpClient))
+	}
+	if cfg.CryptoPanicKey != "" {
+		c.newsProviders.Register(NewCryptoPanicNewsProvider(cfg.CryptoPanicKey, c.httpClient))
+	}
+	if len(cfg.RedditSubreddits) > 0 {
+		c.newsProviders.Register(NewRedditNewsProvider(cfg.RedditSubreddits, c.httpClient))
+	}
+	for _, name := range cfg.DisabledSources {
+		c.newsProviders.SetEnabled(name, false)
+	}
+	c.newsProviders.SetTimeout(cfg.SourceTimeout)
+}
+
+// NewsProviders returns the registry of news sources used by the analyzer
+func (c *CryptoCoinAnalyzer) NewsProviders() *NewsProviderRegistry {
+	return c.newsProviders
+}
+
+type newsFetchResult struct {
+	items       []NewsItem
+	err         error
+	retrievedAt time.Time
+}
+
+// getRecentNews fetches news from all enabled sources concurrently. Failing sources are listed
+// as skipped in the report's data sources; an error is returned only when every source failed.
+func (c *CryptoCoinAnalyzer) getRecentNews(ctx context.Context, symbol string) ([]NewsItem, error) {
+	sources, timeout := c.newsProviders.enabled()
+	assetClass := coinAssetClass(symbol)
+
+	results := make([]newsFetchResult, len(sources))
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source NewsProvider) {
+			defer wg.Done()
+
+			fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			items, err := source.FetchNews(fetchCtx, symbol, assetClass)
+			if err != nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", timeout)
+			}
+			results[i] = newsFetchResult{items: items, err: err, retrievedAt: time.Now()}
+		}(i, source)
+	}
+	wg.Wait()
+
+	var newsItems []NewsItem
+	var failures []string
+	for i, source := range sources {
+		result := results[i]
+		described := source.Describe()
+		if result.err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", source.Name(), result.err))
+			c.skipDataSource(ctx, described, result.err.Error())
+			continue
+		}
+
+		if len(result.items) > maxNewsItemsPerSource {
+			result.items = result.items[:maxNewsItemsPerSource]
+		}
+		for _, item := range result.items {
+			item.Provider = source.Name()
+			item.RetrievedAt = result.retrievedAt
+			newsItems = append(newsItems, item)
+		}
+		c.addDataSource(ctx, described.Name, described.URL, described.Type, described.Reliability)
+	}
+
+	if len(sources) > 0 && len(failures) == len(sources) {
+		return nil, fmt.Errorf("all news sources failed: %s", strings.Join(failures, "; "))
+	}
+
+	return dedupeNews(newsItems), nil
+}
+
+// skipDataSource lists a source that could not be used in the report being built in ctx
+func (c *CryptoCoinAnalyzer) skipDataSource(ctx context.Context, source DataSource, reason string) {
+	source.Skipped = true
+	source.SkipReason = reason
+	source.LastChecked = time.Now()
+	if report, ok := ctx.Value(coinReportKey{}).(*CoinAnalysisReport); ok {
+		report.Sources = append(report.Sources, source)
+	}
+
+	c.logger.Warn(ctx, "Data source skipped", map[string]interface{}{
+		"name":   source.Name,
+		"reason": reason,
+	})
+}
+
+// dedupeNews drops items whose URL or headline repeats an earlier item and orders the rest
+// newest first
+func dedupeNews(items []NewsItem) []NewsItem {
+	seenURLs := make(map[string]bool)
+	var kept []NewsItem
+	var keptTitles [][]string
+
+	for _, item := range items {
+		if key := normalizeNewsURL(item.URL); key != "" {
+			if seenURLs[key] {
+				continue
+			}
+			seenURLs[key] = true
+		}
+
+		words := titleWords(item.Title)
+		duplicate := false
+		for _, other := range keptTitles {
+			if titleSimilarity(words, other) >= newsTitleSimilarity {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		kept = append(kept, item)
+		keptTitles = append(keptTitles, words)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].PublishedAt.After(kept[j].PublishedAt)
+	})
+	return kept
+}
+
+// normalizeNewsURL reduces a URL to host and path without tracking parameters
+func normalizeNewsURL(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Host == "" {
+		return strings.ToLower(strings.TrimSpace(raw))
+	}
+
+	query := parsed.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	normalized := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.") + strings.TrimSuffix(parsed.Path, "/")
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
+
+var titleWordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+func titleWords(title string) []string {
+	return titleWordPattern.FindAllString(strings.ToLower(title), -1)
+}
+
+// titleSimilarity is the Jaccard similarity of two headlines' word sets
+func titleSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, word := range a {
+		set[word] = true
+	}
+	union := len(set)
+	common := 0
+	seen := make(map[string]bool, len(b))
+	for _, word := range b {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		if set[word] {
+			common++
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
+
+// mentionsCoin reports whether text refers to symbol or the coin's name
+func mentionsCoin(text, symbol string) bool {
+	lower := strings.ToLower(text)
+	if name, ok := coinNames[strings.ToUpper(symbol)]; ok && strings.Contains(lower, name) {
+		return true
+	}
+	pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(strings.ToLower(symbol)) + `\b`)
+	return pattern.MatchString(lower)
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips markup from feed descriptions and shortens them for reports
+func plainText(text string) string {
+	text = strings.Join(strings.Fields(htmlTagPattern.ReplaceAllString(text, " ")), " ")
+	if len(text) > 300 {
+		text = text[:297] + "..."
+	}
+	return text
+}
+
+// getNewsResponse fetches a source URL and returns the response body
+func getNewsResponse(ctx context.Context, client *http.Client, requestURL string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxNewsResponseBytes))
+}
+
+// webSearchNewsProvider finds news through the analyzer's web search
+type webSearchNewsProvider struct {
+	analyzer *CryptoCoinAnalyzer
+}
+
+func (s *webSearchNewsProvider) Name() string { return NewsProviderWebSearch }
+
+func (s *webSearchNewsProvider) Describe() DataSource {
+	return DataSource{Name: "Web Search - News", URL: "https://www.google.com/search", Type: "news", Reliability: "medium"}
+}
+
+func (s *webSearchNewsProvider) FetchNews(ctx context.Context, symbol, assetClass string) ([]NewsItem, error) {
+	query := fmt.Sprintf("%s cryptocurrency news last 7 days", symbol)
+	results, err := s.analyzer.performWebSearch(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for news: %w", err)
+	}
+
+	var newsItems []NewsItem
+	for _, result := range results {
+		if newsItem := s.analyzer.parseNewsItem(result, symbol); newsItem != nil {
+			newsItems = append(newsItems, *newsItem)
+		}
+	}
+	return newsItems, nil
+}
+
+// RSSNewsProvider reads articles mentioning the coin from an RSS or Atom feed
+type RSSNewsProvider struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewRSSNewsProvider creates a source for the feed at feedURL
+func NewRSSNewsProvider(name, feedURL string, client *http.Client) *RSSNewsProvider {
+	if name == "" {
+		name = feedURL
+	}
+	return &RSSNewsProvider{name: name, url: feedURL, client: client}
+}
+
+func (s *RSSNewsProvider) Name() string { return newsProviderRSSPrefix + s.name }
+
+func (s *RSSNewsProvider) Describe() DataSource {
+	return DataSource{Name: "RSS - " + s.name, URL: s.url, Type: "news", Reliability: "medium"}
+}
+
+// rssFeed covers RSS 2.0 channels and Atom feeds
+type rssFeed struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+func (s *RSSNewsProvider) FetchNews(ctx context.Context, symbol, assetClass string) ([]NewsItem, error) {
+	body, err := getNewsResponse(ctx, s.client, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed rssFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	var newsItems []NewsItem
+	add := func(title, link, description, published string) {
+		if !mentionsCoin(title+" "+description, symbol) {
+			return
+		}
+		description = plainText(description)
+		newsItems = append(newsItems, NewsItem{
+			Title:       strings.TrimSpace(title),
+			Description: description,
+			URL:         strings.TrimSpace(link),
+			Source:      s.name,
+			PublishedAt: parseFeedTime(published),
+			Impact:      determineContentImpact(title + " " + description),
+			Relevance:   0.7,
+		})
+	}
+
+	for _, item := range feed.Items {
+		add(item.Title, item.Link, item.Description, item.PubDate)
+	}
+	for _, entry := range feed.Entries {
+		link := ""
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		add(entry.Title, link, entry.Summary, published)
+	}
+	return newsItems, nil
+}
+
+// parseFeedTime parses the date formats used by RSS and Atom, falling back to now
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Now()
+}
+
+// CryptoPanicNewsProvider reads aggregated crypto news from the CryptoPanic API
+type CryptoPanicNewsProvider struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewCryptoPanicNewsProvider creates a CryptoPanic source authenticated with apiKey
+func NewCryptoPanicNewsProvider(apiKey string, client *http.Client) *CryptoPanicNewsProvider {
+	return &CryptoPanicNewsProvider{apiKey: apiKey, baseURL: "https://cryptopanic.com/api/v1", client: client}
+}
+
+func (s *CryptoPanicNewsProvider) Name() string { return NewsProviderCryptoPanic }
+
+func (s *CryptoPanicNewsProvider) Describe() DataSource {
+	return DataSource{Name: "CryptoPanic", URL: "https://cryptopanic.com", Type: "news", Reliability: "high"}
+}
+
+func (s *CryptoPanicNewsProvider) FetchNews(ctx context.Context, symbol, assetClass string) ([]NewsItem, error) {
+	params := url.Values{}
+	params.Set("auth_token", s.apiKey)
+	params.Set("currencies", strings.ToUpper(symbol))
+	params.Set("kind", "news")
+	params.Set("public", "true")
+
+	body, err := getNewsResponse(ctx, s.client, s.baseURL+"/posts/?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Results []struct {
+			Title       string    `json:"title"`
+			URL         string    `json:"url"`
+			PublishedAt time.Time `json:"published_at"`
+			Source      struct {
+				Title string `json:"title"`
+			} `json:"source"`
+			Votes struct {
+				Positive int `json:"positive"`
+				Negative int `json:"negative"`
+			} `json:"votes"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	newsItems := make([]NewsItem, 0, len(response.Results))
+	for _, post := range response.Results {
+		impact := determineContentImpact(post.Title)
+		if post.Votes.Positive > post.Votes.Negative*2 {
+			impact = "bullish"
+		} else if post.Votes.Negative > post.Votes.Positive*2 {
+			impact = "bearish"
+		}
+		newsItems = append(newsItems, NewsItem{
+			Title:       post.Title,
+			URL:         post.URL,
+			Source:      post.Source.Title,
+			PublishedAt: post.PublishedAt,
+			Impact:      impact,
+			Relevance:   0.9, // filtered by currency on the API
+		})
+	}
+	return newsItems, nil
+}
+
+// RedditNewsProvider searches subreddits chosen per asset class for posts about the coin
+type RedditNewsProvider struct {
+	subreddits map[string][]string
+	baseURL    string
+	client     *http.Client
+}
+
+// NewRedditNewsProvider creates a Reddit source; subreddits are keyed by asset class and the
+// "all" key applies to every class
+func NewRedditNewsProvider(subreddits map[string][]string, client *http.Client) *RedditNewsProvider {
+	return &RedditNewsProvider{subreddits: subreddits, baseURL: "https://www.reddit.com", client: client}
+}
+
+func (s *RedditNewsProvider) Name() string { return NewsProviderReddit }
+
+func (s *RedditNewsProvider) Describe() DataSource {
+	return DataSource{Name: "Reddit", URL: s.baseURL, Type: "social", Reliability: "low"}
+}
+
+// subredditsFor returns the subreddits followed for an asset class
+func (s *RedditNewsProvider) subredditsFor(assetClass string) []string {
+	subreddits := append([]string(nil), s.subreddits["all"]...)
+	return append(subreddits, s.subreddits[assetClass]...)
+}
+
+func (s *RedditNewsProvider) FetchNews(ctx context.Context, symbol, assetClass string) ([]NewsItem, error) {
+	subreddits := s.subredditsFor(assetClass)
+	if len(subreddits) == 0 {
+		return nil, fmt.Errorf("no subreddits configured for asset class %s", assetClass)
+	}
+
+	query := symbol
+	if name, ok := coinNames[strings.ToUpper(symbol)]; ok {
+		query = fmt.Sprintf("%s OR %s", symbol, name)
+	}
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("restrict_sr", "1")
+	params.Set("sort", "new")
+	params.Set("t", "week")
+	params.Set("limit", fmt.Sprint(maxNewsItemsPerSource))
+
+	requestURL := fmt.Sprintf("%s/r/%s/search.json?%s", s.baseURL, strings.Join(subreddits, "+"), params.Encode())
+	body, err := getNewsResponse(ctx, s.client, requestURL, map[string]string{"User-Agent": "ai-agentic-browser/1.0"})
+	if err != nil {
+		return nil, err
+	}
+
+	var listing struct {
+		Data struct {
+			Children []struct {
+				Data struct {
+					Title      string  `json:"title"`
+					Selftext   string  `json:"selftext"`
+					Permalink  string  `json:"permalink"`
+					Subreddit  string  `json:"subreddit"`
+					CreatedUTC float64 `json:"created_utc"`
+				} `json:"data"`
+			} `json:"children"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	newsItems := make([]NewsItem, 0, len(listing.Data.Children))
+	for _, child := range listing.Data.Children {
+		post := child.Data
+		newsItems = append(newsItems, NewsItem{
+			Title:       post.Title,
+			Description: plainText(post.Selftext),
+			URL:         "https://www.reddit.com" + post.Permalink,
+			Source:      "r/" + post.Subreddit,
+			PublishedAt: time.Unix(int64(post.CreatedUTC), 0),
+			Impact:      determineContentImpact(post.Title + " " + post.Selftext),
+			Relevance:   0.5,
+		})
+	}
+	return newsItems, nil
+}
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+const testRSSFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<item>
+  <title>Bitcoin ETF inflows hit record</title>
+  <link>https://news.example.com/btc-etf?utm_source=rss</link>
+  <description>&lt;p&gt;Strong adoption and growth&lt;/p&gt;</description>
+  <pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+</item>
+<item>
+  <title>Solana validators upgrade client</title>
+  <link>https://news.example.com/sol</link>
+</item>
+</channel></rss>`
+
+func newNewsTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSSFeed)
+	})
+	mux.HandleFunc("/broken.xml", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/slow.xml", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	mux.HandleFunc("/api/posts/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("auth_token") != "panic-key" || r.URL.Query().Get("currencies") != "BTC" {
+			t.Errorf("unexpected CryptoPanic query: %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"results":[
+			{"title":"Bitcoin ETF inflows hit a record","url":"https://other.example.com/etf","published_at":"2024-01-15T10:00:00Z","source":{"title":"CoinDesk"},"votes":{"positive":10,"negative":1}},
+			{"title":"Miners expand hash rate","url":"https://www.news.example.com/btc-etf/","published_at":"2024-01-14T10:00:00Z","source":{"title":"Dup"}}
+		]}`)
+	})
+	mux.HandleFunc("/r/CryptoCurrency+Bitcoin/search.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"children":[
+			{"data":{"title":"Why is BTC dumping today?","selftext":"panic selling","permalink":"/r/Bitcoin/comments/abc/","subreddit":"Bitcoin","created_utc":1705312800}}
+		]}}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetRecentNews_ConfiguredProviders(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{ServiceName: "test", LogLevel: "error", LogFormat: "text"})
+	server := newNewsTestServer(t)
+
+	analyzer := NewCryptoCoinAnalyzer(logger)
+	analyzer.ConfigureNewsSources(config.NewsSourcesConfig{
+		RSSFeeds:         []string{"Example=" + server.URL + "/feed.xml", server.URL + "/broken.xml", "Slow=" + server.URL + "/slow.xml"},
+		CryptoPanicKey:   "panic-key",
+		RedditSubreddits: map[string][]string{"all": {"CryptoCurrency"}, "layer1": {"Bitcoin"}, "meme": {"dogecoin"}},
+		DisabledSources:  []string{NewsProviderWebSearch},
+		SourceTimeout:    200 * time.Millisecond,
+	})
+	for _, provider := range analyzer.newsProviders.sources {
+		switch p := provider.(type) {
+		case *CryptoPanicNewsProvider:
+			p.baseURL = server.URL + "/api"
+		case *RedditNewsProvider:
+			p.baseURL = server.URL
+		}
+	}
+
+	report := &CoinAnalysisReport{Symbol: "BTC"}
+	ctx := context.WithValue(context.Background(), coinReportKey{}, report)
+
+	items, err := analyzer.getRecentNews(ctx, "BTC")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The CryptoPanic duplicates of the RSS article are dropped by URL and by headline
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 deduplicated items, got %d: %+v", len(items), items)
+	}
+	providers := map[string]NewsItem{}
+	for _, item := range items {
+		if item.RetrievedAt.IsZero() {
+			t.Errorf("Expected retrieval time on %q", item.Title)
+		}
+		providers[item.Provider] = item
+	}
+	rss, ok := providers["rss:Example"]
+	if !ok || rss.Description != "Strong adoption and growth" || rss.Impact != "bullish" {
+		t.Errorf("Expected parsed RSS item, got %+v", rss)
+	}
+	reddit, ok := providers[NewsProviderReddit]
+	if !ok || reddit.URL != "https://www.reddit.com/r/Bitcoin/comments/abc/" || reddit.Source != "r/Bitcoin" {
+		t.Errorf("Expected Reddit post, got %+v", reddit)
+	}
+
+	skipped := map[string]string{}
+	used := 0
+	for _, source := range report.Sources {
+		if source.Skipped {
+			skipped[source.Name] = source.SkipReason
+		} else {
+			used++
+		}
+	}
+	if used != 3 {
+		t.Errorf("Expected 3 sources used, got %d", used)
+	}
+	brokenName := "RSS - " + strings.TrimPrefix(server.URL, "http://")
+	if reason := skipped[brokenName]; !strings.Contains(reason, "503") {
+		t.Errorf("Expected broken feed skipped with its status, got %q", reason)
+	}
+	if reason := skipped["RSS - Slow"]; !strings.Contains(reason, "timed out") {
+		t.Errorf("Expected slow feed skipped on timeout, got %q", reason)
+	}
+}
+
+func TestGetRecentNews_AllProvidersFail(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{ServiceName: "test", LogLevel: "error", LogFormat: "text"})
+	server := newNewsTestServer(t)
+
+	analyzer := NewCryptoCoinAnalyzer(logger)
+	analyzer.ConfigureNewsSources(config.NewsSourcesConfig{
+		RSSFeeds:        []string{"Broken=" + server.URL + "/broken.xml"},
+		DisabledSources: []string{NewsProviderWebSearch},
+	})
+
+	if _, err := analyzer.getRecentNews(context.Background(), "BTC"); err == nil {
+		t.Error("Expected an error when every news source fails")
+	}
+}
+
+func TestDedupeNews(t *testing.T) {
+	now := time.Now()
+	items := dedupeNews([]NewsItem{
+		{Title: "ETH upgrade ships", URL: "https://a.example.com/x", PublishedAt: now.Add(-time.Hour)},
+		{Title: "Ethereum upgrade ships on mainnet", URL: "https://a.example.com/x?utm_medium=feed", PublishedAt: now},
+		{Title: "ETH upgrade ships!", URL: "https://b.example.com/y", PublishedAt: now},
+		{Title: "Gas fees fall after upgrade", URL: "https://c.example.com/z", PublishedAt: now},
+	})
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d: %+v", len(items), items)
+	}
+	if items[0].Title != "Gas fees fall after upgrade" {
+		t.Errorf("Expected newest item first, got %q", items[0].Title)
+	}
+}
@@ -0,0 +1,396 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+const (
+	// predictionResolveBatch bounds the predictions resolved per pass
+	predictionResolveBatch = 500
+	// predictionExpiry is how long after its target a prediction waits for a realized price
+	// before it is given up on
+	predictionExpiry = 24 * time.Hour
+	// predictionPriceWindow is the span after the target searched for the realized price
+	predictionPriceWindow = 5 * time.Minute
+	// predictionHeadlineRange is the range of the accuracy shown in the model status
+	predictionHeadlineRange = 30 * 24 * time.Hour
+	// calibrationBuckets splits the confidence range for the calibration table
+	calibrationBuckets = 5
+)
+
+// Stored prediction statuses
+const (
+	PredictionPending  = "pending"
+	PredictionResolved = "resolved"
+	PredictionExpired  = "expired" // no realized price was found in time
+)
+
+var (
+	// ErrInvalidEvaluationRange is returned for evaluation ranges that aren't positive
+	ErrInvalidEvaluationRange = errors.New("invalid evaluation range")
+	// ErrEvaluationUnavailable is returned when no prediction evaluator is set
+	ErrEvaluationUnavailable = errors.New("prediction evaluation is not configured")
+)
+
+// PricePredictionRecord is a served price prediction, resolved against the realized price once
+// its horizon elapsed
+type PricePredictionRecord struct {
+	ID             uuid.UUID  `json:"id"`
+	ModelID        string     `json:"model_id"`
+	ModelVersion   int        `json:"model_version,omitempty"`
+	Symbol         string     `json:"symbol"`
+	Timeframe      string     `json:"timeframe"`
+	Horizon        int        `json:"horizon"`
+	BasePrice      float64    `json:"base_price"` // price the prediction was made from
+	PredictedPrice float64    `json:"predicted_price"`
+	Confidence     float64    `json:"confidence"`
+	PredictedAt    time.Time  `json:"predicted_at"`
+	TargetAt       time.Time  `json:"target_at"`
+	Status         string     `json:"status"`
+	ActualPrice    *float64   `json:"actual_price,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// PredictionStore persists served predictions and their resolution
+type PredictionStore interface {
+	SavePrediction(ctx context.Context, record *PricePredictionRecord) error
+	// ListDuePredictions returns pending predictions whose target is at or before now, oldest first
+	ListDuePredictions(ctx context.Context, now time.Time, limit int) ([]*PricePredictionRecord, error)
+	ResolvePrediction(ctx context.Context, id uuid.UUID, status string, actualPrice *float64, resolvedAt time.Time) error
+	// ListPredictions returns the predictions of a model made since the given time
+	ListPredictions(ctx context.Context, modelID string, since time.Time) ([]*PricePredictionRecord, error)
+}
+
+// PredictionAccuracy summarizes resolved predictions. MAPE and the accuracies are fractions.
+type PredictionAccuracy struct {
+	Predictions         int                 `json:"predictions"`
+	MAE                 float64             `json:"mae"`
+	MAPE                float64             `json:"mape"`
+	DirectionalAccuracy float64             `json:"directional_accuracy"`
+	CalibrationError    float64             `json:"calibration_error"` // confidence-weighted gap between confidence and directional hit rate
+	Calibration         []CalibrationBucket `json:"calibration,omitempty"`
+}
+
+// CalibrationBucket compares the confidence of predictions in a range with how often they got
+// the direction right
+type CalibrationBucket struct {
+	MinConfidence  float64 `json:"min_confidence"`
+	MaxConfidence  float64 `json:"max_confidence"`
+	Predictions    int     `json:"predictions"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	HitRate        float64 `json:"hit_rate"`
+}
+
+// HorizonEvaluation is the accuracy of a model's predictions for one timeframe and horizon
+type HorizonEvaluation struct {
+	Timeframe string `json:"timeframe"`
+	Horizon   int    `json:"horizon"`
+	PredictionAccuracy
+}
+
+// ModelEvaluation is the accuracy of a model's predictions made within a range. Predictions
+// whose horizon hasn't elapsed are only counted as pending.
+type ModelEvaluation struct {
+	ModelID  string              `json:"model_id"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Pending  int                 `json:"pending"`
+	Expired  int                 `json:"expired"`
+	Overall  PredictionAccuracy  `json:"overall"`
+	Horizons []HorizonEvaluation `json:"horizons"`
+}
+
+// PredictionEvaluator stores served price predictions and resolves them in the background
+// against realized prices, so model accuracy can be measured on live predictions
+type PredictionEvaluator struct {
+	logger    *observability.Logger
+	store     PredictionStore
+	candles   CandleSource
+	interval  time.Duration
+	headlines map[string]*PredictionAccuracy
+	mu        sync.RWMutex
+}
+
+// NewPredictionEvaluator creates an evaluator resolving due predictions every interval with
+// prices from candles
+func NewPredictionEvaluator(logger *observability.Logger, store PredictionStore, candles CandleSource, interval time.Duration) *PredictionEvaluator {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &PredictionEvaluator{
+		logger:    logger,
+		store:     store,
+		candles:   candles,
+		interval:  interval,
+		headlines: make(map[string]*PredictionAccuracy),
+	}
+}
+
+// Start resolves due predictions until ctx is done
+func (e *PredictionEvaluator) Start(ctx context.Context, modelIDs ...string) {
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			e.resolveDue(ctx, time.Now())
+			e.refreshHeadlines(ctx, modelIDs, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	e.logger.Info(ctx, "Prediction evaluator started", map[string]interface{}{
+		"interval": e.interval.String(),
+	})
+}
+
+// Record stores a served price prediction, targeting its last predicted point
+func (e *PredictionEvaluator) Record(ctx context.Context, modelID string, version int, req *PricePredictionRequest, resp *PricePredictionResponse) error {
+	if len(resp.PredictedPrices) == 0 {
+		return nil
+	}
+	target := resp.PredictedPrices[len(resp.PredictedPrices)-1]
+
+	record := &PricePredictionRecord{
+		ID:             uuid.New(),
+		ModelID:        modelID,
+		ModelVersion:   version,
+		Symbol:         resp.Symbol,
+		Timeframe:      req.Timeframe,
+		Horizon:        req.Horizon,
+		BasePrice:      resp.CurrentPrice.InexactFloat64(),
+		PredictedPrice: target.Price.InexactFloat64(),
+		Confidence:     resp.Confidence,
+		PredictedAt:    resp.GeneratedAt,
+		TargetAt:       target.Timestamp,
+		Status:         PredictionPending,
+	}
+	if err := e.store.SavePrediction(ctx, record); err != nil {
+		return fmt.Errorf("failed to store prediction: %w", err)
+	}
+	return nil
+}
+
+// Evaluate measures the predictions a model made within the last period
+func (e *PredictionEvaluator) Evaluate(ctx context.Context, modelID string, period time.Duration) (*ModelEvaluation, error) {
+	if period <= 0 {
+		return nil, ErrInvalidEvaluationRange
+	}
+
+	now := time.Now()
+	records, err := e.store.ListPredictions(ctx, modelID, now.Add(-period))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list predictions: %w", err)
+	}
+	return evaluatePredictions(modelID, records, now.Add(-period), now), nil
+}
+
+// Headline returns the accuracy of a model over the last 30 days as of the last background
+// pass, or nil when none of its predictions were resolved
+func (e *PredictionEvaluator) Headline(modelID string) *PredictionAccuracy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.headlines[modelID]
+}
+
+// resolveDue looks up the realized price of predictions whose horizon elapsed
+func (e *PredictionEvaluator) resolveDue(ctx context.Context, now time.Time) {
+	due, err := e.store.ListDuePredictions(ctx, now, predictionResolveBatch)
+	if err != nil {
+		e.logger.Error(ctx, "Failed to list due predictions", err)
+		return
+	}
+
+	resolved, expired := 0, 0
+	for _, record := range due {
+		price, err := e.realizedPrice(ctx, record)
+		if err != nil {
+			e.logger.Warn(ctx, "Failed to get realized price", map[string]interface{}{
+				"prediction_id": record.ID.String(),
+				"symbol":        record.Symbol,
+				"error":         err.Error(),
+			})
+		}
+
+		status := PredictionResolved
+		switch {
+		case price != nil:
+			resolved++
+		case now.Sub(record.TargetAt) > predictionExpiry:
+			status = PredictionExpired
+			expired++
+		default:
+			continue // the candle may not be available yet
+		}
+		if err := e.store.ResolvePrediction(ctx, record.ID, status, price, now); err != nil {
+			e.logger.Error(ctx, "Failed to resolve prediction", err, map[string]interface{}{
+				"prediction_id": record.ID.String(),
+			})
+		}
+	}
+
+	if resolved > 0 || expired > 0 {
+		e.logger.Info(ctx, "Predictions resolved", map[string]interface{}{
+			"resolved": resolved,
+			"expired":  expired,
+		})
+	}
+}
+
+// realizedPrice returns the close of the first minute candle closing at or after the target,
+// or nil when there is none yet. Candles are timestamped with their close.
+func (e *PredictionEvaluator) realizedPrice(ctx context.Context, record *PricePredictionRecord) (*float64, error) {
+	candles, err := e.candles.GetCandles(ctx, record.Symbol, "1m", record.TargetAt.Add(-time.Minute), record.TargetAt.Add(predictionPriceWindow))
+	if err != nil {
+		return nil, err
+	}
+	for _, candle := range candles {
+		if !candle.Timestamp.Before(record.TargetAt) {
+			price := candle.Close.InexactFloat64()
+			return &price, nil
+		}
+	}
+	return nil, nil
+}
+
+// refreshHeadlines recomputes the status accuracy of each model
+func (e *PredictionEvaluator) refreshHeadlines(ctx context.Context, modelIDs []string, now time.Time) {
+	for _, modelID := range modelIDs {
+		records, err := e.store.ListPredictions(ctx, modelID, now.Add(-predictionHeadlineRange))
+		if err != nil {
+			e.logger.Error(ctx, "Failed to list predictions", err, map[string]interface{}{
+				"model_id": modelID,
+			})
+			continue
+		}
+
+		evaluation := evaluatePredictions(modelID, records, now.Add(-predictionHeadlineRange), now)
+		var headline *PredictionAccuracy
+		if evaluation.Overall.Predictions > 0 {
+			overall := evaluation.Overall
+			overall.Calibration = nil
+			headline = &overall
+		}
+
+		e.mu.Lock()
+		e.headlines[modelID] = headline
+		e.mu.Unlock()
+	}
+}
+
+// evaluatePredictions computes the accuracy of the resolved records, overall and per horizon
+func evaluatePredictions(modelID string, records []*PricePredictionRecord, from, to time.Time) *ModelEvaluation {
+	evaluation := &ModelEvaluation{ModelID: modelID, From: from, To: to, Horizons: []HorizonEvaluation{}}
+
+	type horizonKey struct {
+		timeframe string
+		horizon   int
+	}
+	var resolved []*PricePredictionRecord
+	byHorizon := make(map[horizonKey][]*PricePredictionRecord)
+	for _, record := range records {
+		switch {
+		case record.Status == PredictionExpired:
+			evaluation.Expired++
+		case record.Status != PredictionResolved || record.ActualPrice == nil || record.TargetAt.After(to):
+			evaluation.Pending++
+		default:
+			resolved = append(resolved, record)
+			key := horizonKey{record.Timeframe, record.Horizon}
+			byHorizon[key] = append(byHorizon[key], record)
+		}
+	}
+
+	evaluation.Overall = predictionAccuracy(resolved)
+	for key, group := range byHorizon {
+		evaluation.Horizons = append(evaluation.Horizons, HorizonEvaluation{
+			Timeframe:          key.timeframe,
+			Horizon:            key.horizon,
+			PredictionAccuracy: predictionAccuracy(group),
+		})
+	}
+	sort.Slice(evaluation.Horizons, func(i, j int) bool {
+		a, b := evaluation.Horizons[i], evaluation.Horizons[j]
+		if a.Timeframe != b.Timeframe {
+			return a.Timeframe < b.Timeframe
+		}
+		return a.Horizon < b.Horizon
+	})
+	return evaluation
+}
+
+func predictionAccuracy(records []*PricePredictionRecord) PredictionAccuracy {
+	accuracy := PredictionAccuracy{Predictions: len(records)}
+	if len(records) == 0 {
+		return accuracy
+	}
+
+	buckets := make([]CalibrationBucket, calibrationBuckets)
+	for i := range buckets {
+		buckets[i].MinConfidence = float64(i) / calibrationBuckets
+		buckets[i].MaxConfidence = float64(i+1) / calibrationBuckets
+	}
+
+	var absError, pctError float64
+	var pctCount, hits int
+	for _, record := range records {
+		actual := *record.ActualPrice
+		absError += math.Abs(record.PredictedPrice - actual)
+		if actual != 0 {
+			pctError += math.Abs(record.PredictedPrice-actual) / math.Abs(actual)
+			pctCount++
+		}
+
+		hit := directionOf(record.PredictedPrice-record.BasePrice) == directionOf(actual-record.BasePrice)
+		if hit {
+			hits++
+		}
+
+		bucket := &buckets[min(int(record.Confidence*calibrationBuckets), calibrationBuckets-1)]
+		bucket.Predictions++
+		bucket.MeanConfidence += record.Confidence
+		if hit {
+			bucket.HitRate++
+		}
+	}
+
+	count := float64(len(records))
+	accuracy.MAE = absError / count
+	if pctCount > 0 {
+		accuracy.MAPE = pctError / float64(pctCount)
+	}
+	accuracy.DirectionalAccuracy = float64(hits) / count
+
+	for _, bucket := range buckets {
+		if bucket.Predictions == 0 {
+			continue
+		}
+		bucket.MeanConfidence /= float64(bucket.Predictions)
+		bucket.HitRate /= float64(bucket.Predictions)
+		accuracy.CalibrationError += float64(bucket.Predictions) / count * math.Abs(bucket.HitRate-bucket.MeanConfidence)
+		accuracy.Calibration = append(accuracy.Calibration, bucket)
+	}
+	return accuracy
+}
+
+func directionOf(change float64) int {
+	switch {
+	case change > 0:
+		return 1
+	case change < 0:
+		return -1
+	}
+	return 0
+}
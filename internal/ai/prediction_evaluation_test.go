@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/ml"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPredictionStore is an in-memory PredictionStore
+type memoryPredictionStore struct {
+	records []*PricePredictionRecord
+}
+
+func (m *memoryPredictionStore) SavePrediction(ctx context.Context, record *PricePredictionRecord) error {
+	stored := *record
+	m.records = append(m.records, &stored)
+	return nil
+}
+
+func (m *memoryPredictionStore) ListDuePredictions(ctx context.Context, now time.Time, limit int) ([]*PricePredictionRecord, error) {
+	var due []*PricePredictionRecord
+	for _, record := range m.records {
+		if record.Status == PredictionPending && !record.TargetAt.After(now) && len(due) < limit {
+			copied := *record
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryPredictionStore) ResolvePrediction(ctx context.Context, id uuid.UUID, status string, actualPrice *float64, resolvedAt time.Time) error {
+	for _, record := range m.records {
+		if record.ID == id {
+			record.Status = status
+			record.ActualPrice = actualPrice
+			record.ResolvedAt = &resolvedAt
+		}
+	}
+	return nil
+}
+
+func (m *memoryPredictionStore) ListPredictions(ctx context.Context, modelID string, since time.Time) ([]*PricePredictionRecord, error) {
+	var records []*PricePredictionRecord
+	for _, record := range m.records {
+		if record.ModelID == modelID && !record.PredictedAt.Before(since) {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+	return records, nil
+}
+
+func recordPrediction(t *testing.T, evaluator *PredictionEvaluator, base, predicted, confidence float64, horizon int, target time.Time) {
+	t.Helper()
+	err := evaluator.Record(context.Background(), "price_prediction", 1,
+		&PricePredictionRequest{Symbol: "BTCUSDT", Timeframe: "1h", Horizon: horizon},
+		&PricePredictionResponse{
+			Symbol:          "BTCUSDT",
+			CurrentPrice:    decimal.NewFromFloat(base),
+			PredictedPrices: []PricePredictionPoint{{Timestamp: target, Price: decimal.NewFromFloat(predicted)}},
+			Confidence:      confidence,
+			GeneratedAt:     target.Add(-time.Duration(horizon) * time.Hour),
+		})
+	require.NoError(t, err)
+}
+
+func TestPredictionEvaluator(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := &memoryPredictionStore{}
+	candles := &staticCandleSource{candles: []ml.PriceData{
+		{Symbol: "BTCUSDT", Timestamp: now.Add(-time.Hour), Close: decimal.NewFromFloat(110)},
+	}}
+	evaluator := NewPredictionEvaluator(&observability.Logger{}, store, candles, time.Minute)
+
+	// Up from 100: two right about the direction, one wrong
+	recordPrediction(t, evaluator, 100, 105, 0.9, 2, now.Add(-time.Hour))
+	recordPrediction(t, evaluator, 100, 120, 0.9, 2, now.Add(-time.Hour))
+	recordPrediction(t, evaluator, 100, 90, 0.3, 4, now.Add(-time.Hour))
+	// Not due yet
+	recordPrediction(t, evaluator, 100, 130, 0.5, 4, now.Add(time.Hour))
+
+	evaluator.resolveDue(ctx, now)
+	evaluation, err := evaluator.Evaluate(ctx, "price_prediction", 30*24*time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, evaluation.Pending)
+	assert.Equal(t, 3, evaluation.Overall.Predictions)
+	assert.InDelta(t, (5.0+10+20)/3, evaluation.Overall.MAE, 1e-9)
+	assert.InDelta(t, (5.0+10+20)/3/110, evaluation.Overall.MAPE, 1e-9)
+	assert.InDelta(t, 2.0/3, evaluation.Overall.DirectionalAccuracy, 1e-9)
+	require.Len(t, evaluation.Overall.Calibration, 2)
+	assert.Equal(t, 1.0, evaluation.Overall.Calibration[1].HitRate)
+	assert.InDelta(t, (2*0.1+0.3)/3, evaluation.Overall.CalibrationError, 1e-9)
+
+	require.Len(t, evaluation.Horizons, 2)
+	assert.Equal(t, 2, evaluation.Horizons[0].Horizon)
+	assert.Equal(t, 2, evaluation.Horizons[0].Predictions)
+	assert.Equal(t, 1.0, evaluation.Horizons[0].DirectionalAccuracy)
+	assert.Equal(t, 4, evaluation.Horizons[1].Horizon)
+	assert.Equal(t, 1, evaluation.Horizons[1].Predictions)
+
+	_, err = evaluator.Evaluate(ctx, "price_prediction", 0)
+	assert.ErrorIs(t, err, ErrInvalidEvaluationRange)
+
+	t.Run("Expiry", func(t *testing.T) {
+		store := &memoryPredictionStore{}
+		evaluator := NewPredictionEvaluator(&observability.Logger{}, store, &staticCandleSource{}, time.Minute)
+		recordPrediction(t, evaluator, 100, 105, 0.9, 2, now.Add(-time.Hour))
+		recordPrediction(t, evaluator, 100, 105, 0.9, 2, now.Add(-2*predictionExpiry))
+
+		evaluator.resolveDue(ctx, now)
+		evaluation, err := evaluator.Evaluate(ctx, "price_prediction", 30*24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 1, evaluation.Pending)
+		assert.Equal(t, 1, evaluation.Expired)
+		assert.Zero(t, evaluation.Overall.Predictions)
+	})
+
+	t.Run("ModelStatus", func(t *testing.T) {
+		service := NewEnhancedAIService(&observability.Logger{})
+		_, err := service.GetModelEvaluation(ctx, "price_prediction", time.Hour)
+		assert.ErrorIs(t, err, ErrEvaluationUnavailable)
+
+		service.SetPredictionEvaluator(evaluator)
+		_, err = service.GetModelEvaluation(ctx, "unknown", time.Hour)
+		assert.ErrorIs(t, err, ml.ErrModelNotFound)
+
+		evaluator.refreshHeadlines(ctx, []string{"price_prediction", "sentiment_analysis"}, now)
+		status := service.GetModelStatus(ctx)
+		require.NotNil(t, status["price_prediction"].Evaluation)
+		assert.Equal(t, 3, status["price_prediction"].Evaluation.Predictions)
+		assert.Nil(t, status["price_prediction"].Evaluation.Calibration)
+		assert.Nil(t, status["sentiment_analysis"].Evaluation)
+	})
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresPredictionStore implements PredictionStore using the price_predictions table
+type postgresPredictionStore struct {
+	db *database.DB
+}
+
+func NewPostgresPredictionStore(db *database.DB) PredictionStore {
+	return &postgresPredictionStore{db: db}
+}
+
+const pricePredictionColumns = `id, model_id, model_version, symbol, timeframe, horizon, base_price, predicted_price, confidence,
+	predicted_at, target_at, status, actual_price, resolved_at`
+
+func (s *postgresPredictionStore) SavePrediction(ctx context.Context, record *PricePredictionRecord) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO price_predictions (`+pricePredictionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, record.ID, record.ModelID, record.ModelVersion, record.Symbol, record.Timeframe, record.Horizon, record.BasePrice,
+		record.PredictedPrice, record.Confidence, record.PredictedAt, record.TargetAt, record.Status, record.ActualPrice,
+		record.ResolvedAt)
+	return err
+}
+
+func (s *postgresPredictionStore) ListDuePredictions(ctx context.Context, now time.Time, limit int) ([]*PricePredictionRecord, error) {
+	return s.queryPredictions(ctx, `
+		SELECT `+pricePredictionColumns+` FROM price_predictions
+		WHERE status = 'pending' AND target_at <= $1
+		ORDER BY target_at
+		LIMIT $2
+	`, now, limit)
+}
+
+func (s *postgresPredictionStore) ResolvePrediction(ctx context.Context, id uuid.UUID, status string, actualPrice *float64, resolvedAt time.Time) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE price_predictions SET status = $2, actual_price = $3, resolved_at = $4 WHERE id = $1
+	`, id, status, actualPrice, resolvedAt)
+	return err
+}
+
+func (s *postgresPredictionStore) ListPredictions(ctx context.Context, modelID string, since time.Time) ([]*PricePredictionRecord, error) {
+	return s.queryPredictions(ctx, `
+		SELECT `+pricePredictionColumns+` FROM price_predictions
+		WHERE model_id = $1 AND predicted_at >= $2
+		ORDER BY predicted_at
+	`, modelID, since)
+}
+
+func (s *postgresPredictionStore) queryPredictions(ctx context.Context, query string, args ...interface{}) ([]*PricePredictionRecord, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*PricePredictionRecord, 0)
+	for rows.Next() {
+		record := &PricePredictionRecord{}
+		if err := rows.Scan(&record.ID, &record.ModelID, &record.ModelVersion, &record.Symbol, &record.Timeframe,
+			&record.Horizon, &record.BasePrice, &record.PredictedPrice, &record.Confidence, &record.PredictedAt,
+			&record.TargetAt, &record.Status, &record.ActualPrice, &record.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price prediction: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"sort"
@@ -298,6 +299,45 @@ func (p *PricePredictionModel) IsReady() bool {
 	return p.isReady
 }
 
+// pricePredictionState is the serialized trained state of a price prediction model
+type pricePredictionState struct {
+	Info    *ml.ModelInfo        `json:"info"`
+	Weights map[string][]float64 `json:"weights"`
+	Ready   bool                 `json:"ready"`
+}
+
+// Clone returns an independent copy of the model for training a new version
+func (p *PricePredictionModel) Clone() ml.Model {
+	clone := *p
+	clone.info = p.info.Clone()
+	clone.features = append([]string(nil), p.features...)
+	clone.weights = make(map[string][]float64, len(p.weights))
+	for feature, weights := range p.weights {
+		clone.weights[feature] = append([]float64(nil), weights...)
+	}
+	return &clone
+}
+
+// MarshalState serializes the weights and training info of the model
+func (p *PricePredictionModel) MarshalState() ([]byte, error) {
+	return json.Marshal(pricePredictionState{Info: p.info, Weights: p.weights, Ready: p.isReady})
+}
+
+// UnmarshalState restores weights and training info produced by MarshalState
+func (p *PricePredictionModel) UnmarshalState(data []byte) error {
+	var state pricePredictionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid price prediction state: %w", err)
+	}
+	if state.Info == nil {
+		return fmt.Errorf("invalid price prediction state: missing model info")
+	}
+	p.info = state.Info
+	p.weights = state.Weights
+	p.isReady = state.Ready
+	return nil
+}
+
 // UpdateWeights updates model weights based on feedback
 func (p *PricePredictionModel) UpdateWeights(ctx context.Context, feedback *ml.PredictionFeedback) error {
 	// Implement online learning weight updates
@@ -0,0 +1,348 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+// ErrNoCompletionProvider is returned when no provider is configured for a request type
+var ErrNoCompletionProvider = errors.New("no completion provider configured")
+
+// ErrAllProvidersFailed is returned when every provider on a request type's route failed
+var ErrAllProvidersFailed = errors.New("all providers failed")
+
+// maxProviderRetryDelay caps the backoff between retries, including Retry-After hints
+const maxProviderRetryDelay = 10 * time.Second
+
+// defaultProviderRoute is the route of request types without their own provider list
+const defaultProviderRoute = "default"
+
+// CompletionResult is a completion annotated with the provider that served it
+type CompletionResult struct {
+	Content    string   `json:"content"`
+	Provider   string   `json:"provider"`
+	Model      string   `json:"model"`
+	Attempts   int      `json:"attempts"` // across all providers tried
+	FailedOver bool     `json:"failed_over"`
+	Errors     []string `json:"errors,omitempty"` // why earlier providers were skipped
+}
+
+// ProviderCallStats tracks the completion calls of one provider
+type ProviderCallStats struct {
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	Requests    int64     `json:"requests"` // attempts, including retries
+	Successes   int64     `json:"successes"`
+	Errors      int64     `json:"errors"`
+	Retries     int64     `json:"retries"`
+	Failovers   int64     `json:"failovers"` // requests handed to the next provider
+	ErrorRate   float64   `json:"error_rate"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
+
+// ProviderRouter sends completion requests to an ordered list of providers per request
+// type. Transient errors (429, 5xx, timeouts) are retried with jittered exponential
+// backoff; once retries are exhausted, or on any other error, the request fails over to
+// the next provider.
+type ProviderRouter struct {
+	logger         *observability.Logger
+	providers      map[string]CompletionProvider
+	routes         map[string][]string
+	maxRetries     int
+	retryDelay     time.Duration
+	attemptTimeout time.Duration
+	stats          map[string]*ProviderCallStats
+	usage          *UsageAccountant
+	mu             sync.RWMutex
+}
+
+// NewProviderRouter creates a router with every provider present in the AI configuration.
+// Without a configured route, requests try the primary provider first and then the others.
+func NewProviderRouter(logger *observability.Logger, cfg config.AIConfig) *ProviderRouter {
+	router := &ProviderRouter{
+		logger:         logger,
+		providers:      make(map[string]CompletionProvider),
+		routes:         make(map[string][]string),
+		maxRetries:     cfg.ProviderMaxRetries,
+		retryDelay:     cfg.ProviderRetryDelay,
+		attemptTimeout: cfg.ProviderTimeout,
+		stats:          make(map[string]*ProviderCallStats),
+	}
+	if router.maxRetries < 0 {
+		router.maxRetries = 0
+	}
+	if router.retryDelay <= 0 {
+		router.retryDelay = 500 * time.Millisecond
+	}
+
+	// The configured model name applies to the primary provider only
+	modelFor := func(provider, fallback string) string {
+		if cfg.Provider == provider && cfg.ModelName != "" {
+			return cfg.ModelName
+		}
+		return fallback
+	}
+	if cfg.OpenAIKey != "" {
+		router.Register(NewOpenAICompletionProvider(cfg.OpenAIKey, modelFor("openai", "gpt-4o-mini")))
+	}
+	if cfg.AnthropicKey != "" {
+		router.Register(NewAnthropicCompletionProvider(cfg.AnthropicKey, modelFor("anthropic", "claude-3-5-haiku-latest")))
+	}
+	if cfg.OllamaConfig.BaseURL != "" {
+		router.Register(NewOllamaCompletionProvider(cfg.OllamaConfig.BaseURL, cfg.OllamaConfig.Model))
+	}
+	if cfg.LMStudioConfig.BaseURL != "" {
+		router.Register(NewLMStudioCompletionProvider(cfg.LMStudioConfig.BaseURL, cfg.LMStudioConfig.Model))
+	}
+
+	defaultRoute := []string{cfg.Provider}
+	for _, name := range []string{"openai", "anthropic", "ollama", "lmstudio"} {
+		if name != cfg.Provider {
+			defaultRoute = append(defaultRoute, name)
+		}
+	}
+	router.SetRoute(defaultProviderRoute, defaultRoute)
+	for requestType, providers := range cfg.ProviderRoutes {
+		router.SetRoute(requestType, providers)
+	}
+
+	return router
+}
+
+// Register adds or replaces a provider
+func (r *ProviderRouter) Register(provider CompletionProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[provider.Name()] = provider
+	r.stats[provider.Name()] = &ProviderCallStats{Provider: provider.Name(), Model: provider.Model()}
+}
+
+// SetRoute sets the ordered providers of a request type. Providers that are not registered
+// when a request is made are skipped.
+func (r *ProviderRouter) SetRoute(requestType string, providers []string) {
+	route := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if provider = strings.ToLower(strings.TrimSpace(provider)); provider != "" {
+			route = append(route, provider)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[strings.ToLower(requestType)] = route
+}
+
+// Route returns the registered providers a request type is sent to, in order
+func (r *ProviderRouter) Route(requestType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	route, exists := r.routes[strings.ToLower(requestType)]
+	if !exists {
+		route = r.routes[defaultProviderRoute]
+	}
+
+	available := make([]string, 0, len(route))
+	for _, provider := range route {
+		if _, registered := r.providers[provider]; registered {
+			available = append(available, provider)
+		}
+	}
+	return available
+}
+
+// SetUsageAccountant records the cost of every served completion and refuses requests of
+// users over their monthly budget
+func (r *ProviderRouter) SetUsageAccountant(usage *UsageAccountant) {
+	r.usage = usage
+}
+
+// Complete serves a completion request through the route of its request type
+func (r *ProviderRouter) Complete(ctx context.Context, requestType string, req CompletionRequest) (*CompletionResult, error) {
+	return r.serve(ctx, requestType, req, func(ctx context.Context, provider CompletionProvider) (string, error) {
+		return provider.Complete(ctx, req)
+	}, nil)
+}
+
+// Stream serves a completion request like Complete, passing content deltas to onDelta as the
+// provider produces them. Providers that cannot stream deliver their completion as a single
+// delta. Retries and failover only happen before the first delta: once part of a reply has
+// been passed on, a failing provider fails the request.
+func (r *ProviderRouter) Stream(ctx context.Context, requestType string, req CompletionRequest, onDelta func(delta string) error) (*CompletionResult, error) {
+	streamed := false
+	forward := func(delta string) error {
+		if delta == "" {
+			return nil
+		}
+		streamed = true
+		return onDelta(delta)
+	}
+	return r.serve(ctx, requestType, req, func(ctx context.Context, provider CompletionProvider) (string, error) {
+		if streamer, ok := provider.(CompletionStreamer); ok {
+			return streamer.Stream(ctx, req, forward)
+		}
+		content, err := provider.Complete(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return content, forward(content)
+	}, func() bool { return streamed })
+}
+
+// serve sends a request down the route of its request type with call, retrying and failing
+// over until a provider succeeds. Once committed reports true the reply can no longer be
+// taken back, so the request fails with the provider's error instead.
+func (r *ProviderRouter) serve(ctx context.Context, requestType string, req CompletionRequest, call providerCall, committed func() bool) (*CompletionResult, error) {
+	route := r.Route(requestType)
+	if len(route) == 0 {
+		return nil, fmt.Errorf("%w for %s requests", ErrNoCompletionProvider, requestType)
+	}
+	if r.usage != nil {
+		if err := r.usage.CheckBudget(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &CompletionResult{}
+	for i, name := range route {
+		r.mu.RLock()
+		provider := r.providers[name]
+		r.mu.RUnlock()
+
+		content, attempts, err := r.completeWithRetry(ctx, provider, call, committed)
+		result.Attempts += attempts
+		if err == nil {
+			result.Content = content
+			result.Provider = name
+			result.Model = provider.Model()
+			result.FailedOver = i > 0
+			if r.usage != nil {
+				r.usage.Record(ctx, name, result.Model, completionRequestTokens(req), estimateTokens(content))
+			}
+			return result, nil
+		}
+
+		result.Errors = append(result.Errors, err.Error())
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if committed != nil && committed() {
+			return nil, fmt.Errorf("%s failed mid-stream for %s requests: %w", name, requestType, err)
+		}
+		if i < len(route)-1 {
+			r.record(name, func(stats *ProviderCallStats) { stats.Failovers++ })
+			r.logger.Warn(ctx, "AI provider failed, failing over", map[string]interface{}{
+				"request_type": requestType,
+				"provider":     name,
+				"next":         route[i+1],
+				"attempts":     attempts,
+				"error":        err.Error(),
+			})
+		}
+	}
+
+	return nil, fmt.Errorf("%w for %s requests: %s", ErrAllProvidersFailed, requestType, strings.Join(result.Errors, "; "))
+}
+
+// Stats returns the call statistics of every registered provider
+func (r *ProviderRouter) Stats() map[string]ProviderCallStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make(map[string]ProviderCallStats, len(r.stats))
+	for name, providerStats := range r.stats {
+		snapshot := *providerStats
+		if snapshot.Requests > 0 {
+			snapshot.ErrorRate = float64(snapshot.Errors) / float64(snapshot.Requests)
+		}
+		stats[name] = snapshot
+	}
+	return stats
+}
+
+// providerCall requests a completion from one provider
+type providerCall func(ctx context.Context, provider CompletionProvider) (string, error)
+
+// completeWithRetry calls one provider, retrying transient errors until the reply is committed
+func (r *ProviderRouter) completeWithRetry(ctx context.Context, provider CompletionProvider, call providerCall, committed func() bool) (string, int, error) {
+	var err error
+	for attempt := 0; attempt <= r.maxRetries; attempt++ {
+		if attempt > 0 {
+			r.record(provider.Name(), func(stats *ProviderCallStats) { stats.Retries++ })
+			select {
+			case <-ctx.Done():
+				return "", attempt, ctx.Err()
+			case <-time.After(r.backoff(attempt, err)):
+			}
+		}
+
+		var content string
+		content, err = r.attempt(ctx, provider, call)
+		if err == nil {
+			r.record(provider.Name(), func(stats *ProviderCallStats) {
+				stats.Requests++
+				stats.Successes++
+			})
+			return content, attempt + 1, nil
+		}
+
+		r.record(provider.Name(), func(stats *ProviderCallStats) {
+			stats.Requests++
+			stats.Errors++
+			stats.LastError = err.Error()
+			stats.LastErrorAt = time.Now()
+		})
+		if ctx.Err() != nil || !isTransientProviderError(err) || (committed != nil && committed()) {
+			return "", attempt + 1, err
+		}
+	}
+	return "", r.maxRetries + 1, err
+}
+
+func (r *ProviderRouter) attempt(ctx context.Context, provider CompletionProvider, call providerCall) (string, error) {
+	if r.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.attemptTimeout)
+		defer cancel()
+	}
+	return call(ctx, provider)
+}
+
+// backoff returns the jittered exponential delay before a retry, or the provider's
+// Retry-After hint when it is longer
+func (r *ProviderRouter) backoff(retry int, err error) time.Duration {
+	delay := r.retryDelay << (retry - 1)
+	if delay <= 0 || delay > maxProviderRetryDelay {
+		delay = maxProviderRetryDelay
+	}
+	// Full jitter between half and the whole delay spreads out retries of concurrent requests
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.RetryAfter > delay {
+		delay = providerErr.RetryAfter
+		if delay > maxProviderRetryDelay {
+			delay = maxProviderRetryDelay
+		}
+	}
+	return delay
+}
+
+func (r *ProviderRouter) record(provider string, update func(stats *ProviderCallStats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stats, exists := r.stats[provider]; exists {
+		update(stats)
+	}
+}
@@ -0,0 +1,354 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedProvider returns the scripted errors in order, then succeeds
+type scriptedProvider struct {
+	name  string
+	errs  []error
+	calls int
+}
+
+func (p *scriptedProvider) Name() string  { return p.name }
+func (p *scriptedProvider) Model() string { return p.name + "-model" }
+
+func (p *scriptedProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return "", p.errs[p.calls-1]
+	}
+	return "reply from " + p.name, nil
+}
+
+func newTestProviderRouter(providers ...CompletionProvider) *ProviderRouter {
+	router := NewProviderRouter(createTestLogger(), config.AIConfig{
+		ProviderMaxRetries: 2,
+		ProviderRetryDelay: time.Millisecond,
+	})
+	names := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		router.Register(provider)
+		names = append(names, provider.Name())
+	}
+	router.SetRoute(defaultProviderRoute, names)
+	return router
+}
+
+func TestProviderRouter_RetriesTransientErrors(t *testing.T) {
+	primary := &scriptedProvider{name: "primary", errs: []error{
+		&ProviderError{Provider: "primary", StatusCode: http.StatusTooManyRequests, Err: errors.New("rate limited")},
+		&ProviderError{Provider: "primary", StatusCode: http.StatusBadGateway, Err: errors.New("bad gateway")},
+	}}
+	secondary := &scriptedProvider{name: "secondary"}
+	router := newTestProviderRouter(primary, secondary)
+
+	result, err := router.Complete(context.Background(), "chat", CompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "reply from primary", result.Content)
+	assert.Equal(t, "primary", result.Provider)
+	assert.Equal(t, 3, result.Attempts)
+	assert.False(t, result.FailedOver)
+	assert.Equal(t, 0, secondary.calls)
+
+	stats := router.Stats()["primary"]
+	assert.Equal(t, int64(3), stats.Requests)
+	assert.Equal(t, int64(2), stats.Retries)
+	assert.Equal(t, int64(0), stats.Failovers)
+	assert.InDelta(t, 2.0/3.0, stats.ErrorRate, 0.001)
+}
+
+func TestProviderRouter_FailsOver(t *testing.T) {
+	unavailable := &ProviderError{Provider: "primary", StatusCode: http.StatusServiceUnavailable, Err: errors.New("overloaded")}
+	primary := &scriptedProvider{name: "primary", errs: []error{unavailable, unavailable, unavailable}}
+	unauthorized := &scriptedProvider{name: "unauthorized", errs: []error{
+		&ProviderError{Provider: "unauthorized", StatusCode: http.StatusUnauthorized, Err: errors.New("invalid key")},
+	}}
+	fallback := &scriptedProvider{name: "fallback"}
+	router := newTestProviderRouter(primary, unauthorized, fallback)
+
+	result, err := router.Complete(context.Background(), "chat", CompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", result.Provider)
+	assert.True(t, result.FailedOver)
+	assert.Equal(t, 5, result.Attempts)
+	assert.Len(t, result.Errors, 2)
+
+	// Non-transient errors are not retried
+	assert.Equal(t, 3, primary.calls)
+	assert.Equal(t, 1, unauthorized.calls)
+
+	stats := router.Stats()
+	assert.Equal(t, int64(1), stats["primary"].Failovers)
+	assert.Equal(t, int64(2), stats["primary"].Retries)
+	assert.Equal(t, int64(1), stats["unauthorized"].Failovers)
+	assert.Equal(t, int64(0), stats["unauthorized"].Retries)
+	assert.Equal(t, float64(0), stats["fallback"].ErrorRate)
+}
+
+func TestProviderRouter_Routes(t *testing.T) {
+	first := &scriptedProvider{name: "first"}
+	second := &scriptedProvider{name: "second"}
+	router := newTestProviderRouter(first, second)
+	router.SetRoute("market_analysis", []string{"missing", "second"})
+
+	result, err := router.Complete(context.Background(), "Market_Analysis", CompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "second", result.Provider)
+	assert.False(t, result.FailedOver)
+
+	router.SetRoute("offline", []string{"missing"})
+	_, err = router.Complete(context.Background(), "offline", CompletionRequest{})
+	assert.ErrorIs(t, err, ErrNoCompletionProvider)
+
+	failing := &scriptedProvider{name: "failing", errs: []error{errors.New("invalid request")}}
+	router.Register(failing)
+	router.SetRoute("strict", []string{"failing"})
+	_, err = router.Complete(context.Background(), "strict", CompletionRequest{})
+	assert.ErrorContains(t, err, "all providers failed")
+}
+
+func TestProviderRouter_StopsWhenCancelled(t *testing.T) {
+	timeout := &ProviderError{Provider: "primary", StatusCode: http.StatusGatewayTimeout, Err: errors.New("timeout")}
+	primary := &scriptedProvider{name: "primary", errs: []error{timeout, timeout, timeout}}
+	secondary := &scriptedProvider{name: "secondary"}
+	router := newTestProviderRouter(primary, secondary)
+	router.retryDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := router.Complete(ctx, "chat", CompletionRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestIsTransientProviderError(t *testing.T) {
+	assert.True(t, isTransientProviderError(&ProviderError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, isTransientProviderError(&ProviderError{StatusCode: http.StatusInternalServerError}))
+	assert.True(t, isTransientProviderError(&ProviderError{Err: context.DeadlineExceeded}))
+	assert.False(t, isTransientProviderError(&ProviderError{StatusCode: http.StatusBadRequest}))
+	assert.False(t, isTransientProviderError(errors.New("invalid response")))
+}
+
+func TestAnthropicCompletionProvider_AdaptsRequest(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.Write([]byte(`{"content":[{"type":"text","text":"{\"ok\":true}"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicCompletionProvider("test-key", "test-model").(*anthropicProvider)
+	provider.baseURL = server.URL
+
+	content, err := provider.Complete(context.Background(), CompletionRequest{
+		System: "Be brief.",
+		Messages: []CompletionMessage{
+			{Role: RoleAssistant, Content: "Welcome!"},
+			{Role: RoleUser, Content: "Hi"},
+			{Role: RoleUser, Content: "Price of BTC?"},
+			{Role: RoleSystem, Content: "Use USD."},
+		},
+		JSON: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, content)
+
+	assert.Equal(t, "Be brief.\n\nUse USD.\n\n"+jsonReplyInstruction, payload["system"])
+	assert.Equal(t, float64(1024), payload["max_tokens"])
+	messages := payload["messages"].([]interface{})
+	require.Len(t, messages, 1)
+	assert.Equal(t, map[string]interface{}{"role": "user", "content": "Hi\n\nPrice of BTC?"}, messages[0])
+}
+
+func TestPostProviderJSON_RetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewLMStudioCompletionProvider(server.URL, "local")
+	_, err := provider.Complete(context.Background(), CompletionRequest{})
+
+	var providerErr *ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, "lmstudio", providerErr.Provider)
+	assert.Equal(t, http.StatusTooManyRequests, providerErr.StatusCode)
+	assert.Equal(t, 3*time.Second, providerErr.RetryAfter)
+	assert.True(t, isTransientProviderError(err))
+}
+
+// streamingProvider streams its deltas, then fails with err when it is set
+type streamingProvider struct {
+	name   string
+	deltas []string
+	err    error
+	calls  int
+}
+
+func (p *streamingProvider) Name() string  { return p.name }
+func (p *streamingProvider) Model() string { return p.name + "-model" }
+
+func (p *streamingProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	return p.Stream(ctx, req, func(string) error { return nil })
+}
+
+func (p *streamingProvider) Stream(ctx context.Context, req CompletionRequest, onDelta func(delta string) error) (string, error) {
+	p.calls++
+	var content strings.Builder
+	for _, delta := range p.deltas {
+		content.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return "", err
+		}
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	return content.String(), nil
+}
+
+func TestProviderRouter_Stream(t *testing.T) {
+	ctx := context.Background()
+	unavailable := &ProviderError{Provider: "down", StatusCode: http.StatusServiceUnavailable, Err: errors.New("overloaded")}
+
+	t.Run("FailsOverBeforeFirstDelta", func(t *testing.T) {
+		down := &streamingProvider{name: "down", err: unavailable}
+		up := &streamingProvider{name: "up", deltas: []string{"Hello ", "there"}}
+		router := newTestProviderRouter(down, up)
+
+		var deltas []string
+		result, err := router.Stream(ctx, "chat", CompletionRequest{}, func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hello ", "there"}, deltas)
+		assert.Equal(t, "Hello there", result.Content)
+		assert.Equal(t, "up", result.Provider)
+		assert.True(t, result.FailedOver)
+		assert.Equal(t, 3, down.calls, "transient errors are retried before anything was streamed")
+	})
+
+	t.Run("NoFailoverMidStream", func(t *testing.T) {
+		broken := &streamingProvider{name: "broken", deltas: []string{"Hel"}, err: unavailable}
+		fallback := &scriptedProvider{name: "fallback"}
+		router := newTestProviderRouter(broken, fallback)
+
+		var deltas []string
+		_, err := router.Stream(ctx, "chat", CompletionRequest{}, func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+		assert.ErrorIs(t, err, unavailable)
+		assert.NotErrorIs(t, err, ErrAllProvidersFailed)
+		assert.Equal(t, []string{"Hel"}, deltas)
+		assert.Equal(t, 1, broken.calls, "a partly streamed reply is not retried")
+		assert.Equal(t, 0, fallback.calls)
+	})
+
+	t.Run("ProvidersWithoutStreaming", func(t *testing.T) {
+		router := newTestProviderRouter(&scriptedProvider{name: "plain"})
+
+		var deltas []string
+		result, err := router.Stream(ctx, "chat", CompletionRequest{}, func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reply from plain"}, deltas)
+		assert.Equal(t, "reply from plain", result.Content)
+	})
+}
+
+func TestCompletionProviders_Stream(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		provider func(url string) CompletionProvider
+	}{
+		{
+			name: "openai",
+			path: "/chat/completions",
+			body: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hello \"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"there\"}}]}\n\n" +
+				"data: [DONE]\n\n",
+			provider: func(url string) CompletionProvider { return NewLMStudioCompletionProvider(url, "local") },
+		},
+		{
+			name: "anthropic",
+			path: "/messages",
+			body: "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello \"}}\n\n" +
+				"event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"there\"}}\n\n" +
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			provider: func(url string) CompletionProvider {
+				provider := NewAnthropicCompletionProvider("test-key", "test-model").(*anthropicProvider)
+				provider.baseURL = url
+				return provider
+			},
+		},
+		{
+			name: "ollama",
+			path: "/api/chat",
+			body: "{\"message\":{\"content\":\"Hello \"},\"done\":false}\n" +
+				"{\"message\":{\"content\":\"there\"},\"done\":false}\n" +
+				"{\"message\":{\"content\":\"\"},\"done\":true}\n",
+			provider: func(url string) CompletionProvider { return NewOllamaCompletionProvider(url, "llama3") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, truncated := range []bool{false, true} {
+				body := tt.body
+				if truncated {
+					body = body[:strings.LastIndex(strings.TrimRight(body, "\n"), "\n")+1]
+				}
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, tt.path, r.URL.Path)
+					var payload map[string]interface{}
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+					assert.Equal(t, true, payload["stream"])
+					w.Write([]byte(body))
+				}))
+
+				var deltas []string
+				content, err := tt.provider(server.URL).(CompletionStreamer).Stream(context.Background(), CompletionRequest{
+					Messages: []CompletionMessage{{Role: RoleUser, Content: "Hi"}},
+				}, func(delta string) error {
+					deltas = append(deltas, delta)
+					return nil
+				})
+				server.Close()
+
+				assert.Equal(t, []string{"Hello ", "there"}, deltas)
+				if truncated {
+					assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "a stream without its end was cut off")
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, "Hello there", content)
+			}
+		})
+	}
+}
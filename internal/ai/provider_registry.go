@@ -0,0 +1,267 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+// ProviderRegistry tracks the configured AI providers, their health and model catalogues
+type ProviderRegistry struct {
+	monitor       *HealthMonitor
+	providers     map[string]HealthChecker
+	modelCache    map[string]*cachedModels
+	probeTimeout  time.Duration
+	staleAfter    time.Duration
+	modelCacheTTL time.Duration
+	mu            sync.RWMutex
+}
+
+// cachedModels is a provider model catalogue with its fetch time
+type cachedModels struct {
+	models    []string
+	fetchedAt time.Time
+}
+
+// NewProviderRegistry creates a registry with every provider present in the AI configuration.
+// Hosted providers are only registered when an API key is configured.
+func NewProviderRegistry(logger *observability.Logger, cfg config.AIConfig) *ProviderRegistry {
+	registry := &ProviderRegistry{
+		monitor:       NewHealthMonitor(logger, 30*time.Second),
+		providers:     make(map[string]HealthChecker),
+		modelCache:    make(map[string]*cachedModels),
+		probeTimeout:  5 * time.Second,
+		staleAfter:    30 * time.Second,
+		modelCacheTTL: 10 * time.Minute,
+	}
+
+	if cfg.OpenAIKey != "" {
+		registry.Register("openai", NewOpenAICompatibleChecker("https://api.openai.com/v1", map[string]string{
+			"Authorization": "Bearer " + cfg.OpenAIKey,
+		}))
+	}
+	if cfg.AnthropicKey != "" {
+		registry.Register("anthropic", NewOpenAICompatibleChecker("https://api.anthropic.com/v1", map[string]string{
+			"x-api-key":         cfg.AnthropicKey,
+			"anthropic-version": "2023-06-01",
+		}))
+	}
+	if cfg.OllamaConfig.BaseURL != "" {
+		registry.Register("ollama", NewOllamaChecker(cfg.OllamaConfig.BaseURL))
+	}
+	if cfg.LMStudioConfig.BaseURL != "" {
+		registry.Register("lmstudio", NewOpenAICompatibleChecker(cfg.LMStudioConfig.BaseURL, nil))
+	}
+
+	return registry
+}
+
+// Register adds or replaces a provider
+func (r *ProviderRegistry) Register(name string, checker HealthChecker) {
+	r.mu.Lock()
+	r.providers[name] = checker
+	delete(r.modelCache, name)
+	r.mu.Unlock()
+
+	r.monitor.RegisterProvider(name, checker)
+}
+
+// Providers returns the registered provider names
+func (r *ProviderRegistry) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Has reports whether a provider is registered
+func (r *ProviderRegistry) Has(provider string) bool {
+	return r.monitor.HasProvider(provider)
+}
+
+// Health returns the provider status, probing it first when the last result is stale
+func (r *ProviderRegistry) Health(ctx context.Context, provider string) (*HealthStatus, error) {
+	status, exists := r.monitor.GetStatus(provider)
+	if !exists {
+		return nil, fmt.Errorf("provider %s not found", provider)
+	}
+	if time.Since(status.LastChecked) < r.staleAfter {
+		return status, nil
+	}
+	return r.Check(ctx, provider)
+}
+
+// Check forces a fresh probe of the provider and records its latency and outcome
+func (r *ProviderRegistry) Check(ctx context.Context, provider string) (*HealthStatus, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, r.probeTimeout)
+	defer cancel()
+
+	if err := r.monitor.CheckProviderNow(probeCtx, provider); err != nil {
+		return nil, err
+	}
+
+	status, _ := r.monitor.GetStatus(provider)
+	if status.Healthy && len(status.Models) > 0 {
+		r.mu.Lock()
+		r.modelCache[provider] = &cachedModels{models: status.Models, fetchedAt: status.LastChecked}
+		r.mu.Unlock()
+	}
+	return status, nil
+}
+
+// ListModels returns the provider model catalogue, served from cache while fresh
+func (r *ProviderRegistry) ListModels(ctx context.Context, provider string) ([]string, error) {
+	r.mu.RLock()
+	checker, exists := r.providers[provider]
+	cached := r.modelCache[provider]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("provider %s not found", provider)
+	}
+	if cached != nil && time.Since(cached.fetchedAt) < r.modelCacheTTL {
+		return cached.models, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, r.probeTimeout)
+	defer cancel()
+
+	models, err := checker.ListModels(probeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models for %s: %w", provider, err)
+	}
+
+	r.mu.Lock()
+	r.modelCache[provider] = &cachedModels{models: models, fetchedAt: time.Now()}
+	r.mu.Unlock()
+
+	return models, nil
+}
+
+// Status refreshes stale providers and returns the aggregate health report
+func (r *ProviderRegistry) Status(ctx context.Context) *HealthCheckResponse {
+	var wg sync.WaitGroup
+	for _, provider := range r.Providers() {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			r.Health(ctx, name)
+		}(provider)
+	}
+	wg.Wait()
+
+	return r.monitor.GetHealthCheckResponse()
+}
+
+// httpModelsChecker probes a provider through its model listing endpoint
+type httpModelsChecker struct {
+	url     string
+	headers map[string]string
+	parse   func([]byte) ([]string, error)
+	client  *http.Client
+}
+
+// NewOpenAICompatibleChecker creates a checker for APIs exposing GET {baseURL}/models with a data[].id list
+func NewOpenAICompatibleChecker(baseURL string, headers map[string]string) HealthChecker {
+	return &httpModelsChecker{
+		url:     strings.TrimRight(baseURL, "/") + "/models",
+		headers: headers,
+		parse:   parseOpenAIModels,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewOllamaChecker creates a checker for an Ollama server using GET /api/tags
+func NewOllamaChecker(baseURL string) HealthChecker {
+	return &httpModelsChecker{
+		url:    strings.TrimRight(baseURL, "/") + "/api/tags",
+		parse:  parseOllamaModels,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// IsHealthy succeeds when the model listing endpoint answers successfully
+func (c *httpModelsChecker) IsHealthy(ctx context.Context) error {
+	_, err := c.fetch(ctx)
+	return err
+}
+
+// ListModels returns the model identifiers reported by the provider
+func (c *httpModelsChecker) ListModels(ctx context.Context) ([]string, error) {
+	body, err := c.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.parse(body)
+}
+
+func (c *httpModelsChecker) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, c.url)
+	}
+	return body, nil
+}
+
+func parseOpenAIModels(body []byte) ([]string, error) {
+	var payload struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+
+	models := make([]string, 0, len(payload.Data))
+	for _, model := range payload.Data {
+		models = append(models, model.ID)
+	}
+	return models, nil
+}
+
+func parseOllamaModels(body []byte) ([]string, error) {
+	var payload struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+
+	models := make([]string, 0, len(payload.Models))
+	for _, model := range payload.Models {
+		models = append(models, model.Name)
+	}
+	return models, nil
+}
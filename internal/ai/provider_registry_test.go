@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderRegistry_ConfiguredProviders(t *testing.T) {
+	registry := NewProviderRegistry(createTestLogger(), config.AIConfig{
+		OpenAIKey:    "sk-test",
+		OllamaConfig: config.OllamaConfig{BaseURL: "http://localhost:11434"},
+	})
+
+	assert.True(t, registry.Has("openai"))
+	assert.True(t, registry.Has("ollama"))
+	assert.False(t, registry.Has("anthropic"), "hosted providers without a key are not registered")
+	assert.False(t, registry.Has("lmstudio"))
+}
+
+func TestProviderRegistry_ListModelsCached(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data":[{"id":"model-a"},{"id":"model-b"}]}`))
+	}))
+	defer server.Close()
+
+	registry := NewProviderRegistry(createTestLogger(), config.AIConfig{})
+	registry.Register("local", NewOpenAICompatibleChecker(server.URL+"/v1", map[string]string{"Authorization": "Bearer key"}))
+
+	models, err := registry.ListModels(context.Background(), "local")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"model-a", "model-b"}, models)
+
+	_, err = registry.ListModels(context.Background(), "local")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "second listing should be served from cache")
+
+	_, err = registry.ListModels(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestProviderRegistry_CheckRecordsFailures(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"models":[{"name":"qwen3"}]}`))
+	}))
+	defer server.Close()
+
+	registry := NewProviderRegistry(createTestLogger(), config.AIConfig{})
+	registry.Register("ollama", NewOllamaChecker(server.URL))
+
+	status, err := registry.Health(context.Background(), "ollama")
+	require.NoError(t, err)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, []string{"qwen3"}, status.Models)
+
+	// A cached result is returned until it goes stale
+	status, err = registry.Health(context.Background(), "ollama")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), status.Checks)
+
+	failing.Store(true)
+	status, err = registry.Check(context.Background(), "ollama")
+	require.NoError(t, err)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, int64(2), status.Checks)
+	assert.InDelta(t, 0.5, status.ErrorRate, 0.001)
+
+	failing.Store(false)
+	registry.Check(context.Background(), "ollama")
+	report := registry.Status(context.Background())
+	assert.Equal(t, "degraded", report.Status, "a recovered provider with recent failures is degraded")
+	assert.Equal(t, 1, report.Summary.Degraded)
+}
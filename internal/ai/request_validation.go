@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/ai-agentic-browser/pkg/validation"
+)
+
+// AIRequestTypes are the analyses an AIRequest can ask for
+var AIRequestTypes = []string{"price_prediction", "sentiment_analysis", "market_analysis", "comprehensive_analysis"}
+
+// PredictionTimeframes are the candle timeframes price predictions are made on
+var PredictionTimeframes = []string{"1m", "5m", "15m", "30m", "1h", "4h", "1d", "1w"}
+
+// Bounds of request options
+const (
+	maxTimeHorizonHours  = 365 * 24
+	maxPredictionHorizon = 500
+)
+
+// Validate checks the fields a client sets on an AIRequest and returns
+// validation.FieldErrors listing every invalid one
+func (r *AIRequest) Validate() error {
+	var v validation.Validator
+	if v.Required("type", r.Type) {
+		v.OneOf("type", r.Type, AIRequestTypes...)
+	}
+	if r.Type == "price_prediction" || r.Options.IncludePredictions || r.Options.IncludePatterns {
+		v.Required("symbol", r.Symbol)
+	}
+	v.IntRange("options.time_horizon", r.Options.TimeHorizon, 0, maxTimeHorizonHours)
+	v.FloatRange("options.confidence_threshold", r.Options.ConfidenceThreshold, 0, 1)
+	return v.Err()
+}
+
+// Validate checks a price prediction request and returns validation.FieldErrors listing every
+// invalid field
+func (r *PricePredictionRequest) Validate() error {
+	var v validation.Validator
+	v.Required("symbol", r.Symbol)
+	if v.Required("timeframe", r.Timeframe) {
+		v.OneOf("timeframe", r.Timeframe, PredictionTimeframes...)
+	}
+	v.IntRange("horizon", r.Horizon, 1, maxPredictionHorizon)
+
+	if len(r.HistoricalData) == 0 {
+		v.Fail("historical_data", validation.ConstraintRequired, "at least one price point is required")
+	}
+	for i, point := range r.HistoricalData {
+		field := fmt.Sprintf("historical_data[%d]", i)
+		v.Positive(field+".close", point.Close)
+		v.NotNegative(field+".volume", point.Volume)
+		if !point.High.IsZero() && point.High.LessThan(point.Low) {
+			v.Fail(field+".high", validation.ConstraintRange, "must not be less than low")
+		}
+	}
+	return v.Err()
+}
@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/ai-agentic-browser/pkg/ml"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldConstraints maps each invalid field of a validation error to its constraint
+func fieldConstraints(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var fieldErrors validation.FieldErrors
+	require.ErrorAs(t, err, &fieldErrors)
+	constraints := make(map[string]string, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		constraints[fieldError.Field] = fieldError.Constraint
+	}
+	return constraints
+}
+
+func TestAIRequestValidate(t *testing.T) {
+	valid := AIRequest{Type: "market_analysis", Symbol: "BTC", Options: AIRequestOptions{IncludePredictions: true, TimeHorizon: 24, ConfidenceThreshold: 0.7}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := AIRequest{Type: "astrology", Options: AIRequestOptions{IncludePatterns: true, TimeHorizon: -1, ConfidenceThreshold: 1.5}}
+	assert.ErrorIs(t, invalid.Validate(), validation.ErrInvalidRequest)
+	assert.Equal(t, map[string]string{
+		"type":                         validation.ConstraintEnum,
+		"symbol":                       validation.ConstraintRequired,
+		"options.time_horizon":         validation.ConstraintRange,
+		"options.confidence_threshold": validation.ConstraintRange,
+	}, fieldConstraints(t, invalid.Validate()))
+
+	assert.Equal(t, map[string]string{"type": validation.ConstraintRequired}, fieldConstraints(t, (&AIRequest{}).Validate()))
+}
+
+func TestPricePredictionRequestValidate(t *testing.T) {
+	point := ml.PriceData{High: decimal.NewFromInt(105), Low: decimal.NewFromInt(95), Close: decimal.NewFromInt(100), Volume: decimal.NewFromInt(10)}
+	valid := PricePredictionRequest{Symbol: "ETH", Timeframe: "4h", Horizon: 6, HistoricalData: []ml.PriceData{point}}
+	assert.NoError(t, valid.Validate())
+
+	inverted := point
+	inverted.High, inverted.Low = point.Low, point.High
+	invalid := PricePredictionRequest{Timeframe: "2h", HistoricalData: []ml.PriceData{point, {}, inverted}}
+	assert.Equal(t, map[string]string{
+		"symbol":                   validation.ConstraintRequired,
+		"timeframe":                validation.ConstraintEnum,
+		"horizon":                  validation.ConstraintRange,
+		"historical_data[1].close": validation.ConstraintRange,
+		"historical_data[2].high":  validation.ConstraintRange,
+	}, fieldConstraints(t, invalid.Validate()))
+
+	assert.Contains(t, fieldConstraints(t, (&PricePredictionRequest{Symbol: "ETH", Timeframe: "1h", Horizon: 1}).Validate()), "historical_data")
+}
@@ -2,16 +2,21 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/ml"
 	"github.com/ai-agentic-browser/pkg/observability"
 )
 
+// maxConcurrentTranslations bounds the translation requests in flight for one sentiment request
+const maxConcurrentTranslations = 4
+
 // SentimentAnalyzer implements advanced sentiment analysis for crypto markets
 type SentimentAnalyzer struct {
 	info           *ml.ModelInfo
@@ -40,9 +45,12 @@ type SentimentRequest struct {
 	Texts    []string               `json:"texts"`
 	Source   string                 `json:"source"`
 	Symbol   string                 `json:"symbol,omitempty"`
-	Language string                 `json:"language"`
+	Language string                 `json:"language"` // empty or "auto" detects the language of each text
 	Context  map[string]interface{} `json:"context,omitempty"`
 	Options  SentimentOptions       `json:"options"`
+
+	// TranslateToEnglish scores non-English texts on their English translation
+	TranslateToEnglish bool `json:"translate_to_english"`
 }
 
 // SentimentOptions represents options for sentiment analysis
@@ -77,6 +85,28 @@ type SentimentResult struct {
 	IsSarcasm    bool                   `json:"is_sarcasm,omitempty"`
 	Subjectivity float64                `json:"subjectivity"` // 0.0 to 1.0
 	Metadata     map[string]interface{} `json:"metadata"`
+
+	LanguageDetection *LanguageDetection    `json:"language_detection,omitempty"`
+	Translation       *SentimentTranslation `json:"translation,omitempty"`
+}
+
+// SentimentTranslation records the English translation a text was scored on
+type SentimentTranslation struct {
+	Text                string   `json:"text,omitempty"`
+	SourceLanguage      string   `json:"source_language"`
+	Provider            string   `json:"provider"`
+	OriginalSentiment   float64  `json:"original_sentiment"`
+	TranslatedSentiment *float64 `json:"translated_sentiment,omitempty"` // unset when translation failed
+	Error               string   `json:"error,omitempty"`
+}
+
+// LanguageSentiment is the aggregated sentiment of the texts in one language
+type LanguageSentiment struct {
+	Texts        int            `json:"texts"`
+	Sentiment    float64        `json:"sentiment"`
+	Confidence   float64        `json:"confidence"`
+	Distribution map[string]int `json:"distribution"`
+	Translated   int            `json:"translated,omitempty"`
 }
 
 // AggregatedSentiment represents aggregated sentiment across multiple texts
@@ -89,6 +119,8 @@ type AggregatedSentiment struct {
 	TrendingEntities      []EntitySentiment     `json:"trending_entities,omitempty"`
 	VolumeMetrics         VolumeMetrics         `json:"volume_metrics"`
 	TimeSeriesData        []TimeSeriesSentiment `json:"time_series_data,omitempty"`
+
+	ByLanguage map[string]*LanguageSentiment `json:"by_language,omitempty"`
 }
 
 // KeywordSentiment represents sentiment for a specific keyword
@@ -196,6 +228,11 @@ func (s *SentimentAnalyzer) Predict(ctx context.Context, features map[string]int
 		return nil, fmt.Errorf("no texts provided for analysis")
 	}
 
+	// Detect languages and translate before scoring
+	translator, _ := features["translator"].(TranslationProvider)
+	languages := s.detectLanguages(req)
+	translations := s.translateTexts(ctx, req, languages, translator)
+
 	// Process texts
 	results := make([]SentimentResult, len(req.Texts))
 	for i, text := range req.Texts {
@@ -207,6 +244,14 @@ func (s *SentimentAnalyzer) Predict(ctx context.Context, features map[string]int
 			})
 			continue
 		}
+		if languages != nil {
+			detection := languages[i]
+			result.Language = detection.Language
+			result.LanguageDetection = &detection
+		}
+		if translation, ok := translations[i]; ok {
+			s.applyTranslation(result, translation, req)
+		}
 		results[i] = *result
 	}
 
@@ -223,6 +268,7 @@ func (s *SentimentAnalyzer) Predict(ctx context.Context, features map[string]int
 			"source":      req.Source,
 			"symbol":      req.Symbol,
 			"language":    req.Language,
+			"translated":  len(translations),
 		},
 	}
 
@@ -325,6 +371,53 @@ func (s *SentimentAnalyzer) IsReady() bool {
 	return s.isReady
 }
 
+// sentimentAnalyzerState is the serialized trained state of a sentiment analyzer
+type sentimentAnalyzerState struct {
+	Info           *ml.ModelInfo      `json:"info"`
+	Lexicon        map[string]float64 `json:"lexicon"`
+	CryptoTerms    map[string]float64 `json:"crypto_terms"`
+	EmotionWeights map[string]float64 `json:"emotion_weights"`
+	Ready          bool               `json:"ready"`
+}
+
+// Clone returns an independent copy of the analyzer for training a new version
+func (s *SentimentAnalyzer) Clone() ml.Model {
+	clone := *s
+	clone.info = s.info.Clone()
+	clone.lexicon = copyWeights(s.lexicon)
+	clone.cryptoTerms = copyWeights(s.cryptoTerms)
+	clone.emotionWeights = copyWeights(s.emotionWeights)
+	return &clone
+}
+
+// MarshalState serializes the lexicons and training info of the analyzer
+func (s *SentimentAnalyzer) MarshalState() ([]byte, error) {
+	return json.Marshal(sentimentAnalyzerState{
+		Info:           s.info,
+		Lexicon:        s.lexicon,
+		CryptoTerms:    s.cryptoTerms,
+		EmotionWeights: s.emotionWeights,
+		Ready:          s.isReady,
+	})
+}
+
+// UnmarshalState restores lexicons and training info produced by MarshalState
+func (s *SentimentAnalyzer) UnmarshalState(data []byte) error {
+	var state sentimentAnalyzerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid sentiment analyzer state: %w", err)
+	}
+	if state.Info == nil {
+		return fmt.Errorf("invalid sentiment analyzer state: missing model info")
+	}
+	s.info = state.Info
+	s.lexicon = state.Lexicon
+	s.cryptoTerms = state.CryptoTerms
+	s.emotionWeights = state.EmotionWeights
+	s.isReady = state.Ready
+	return nil
+}
+
 // UpdateWeights updates model weights based on feedback
 func (s *SentimentAnalyzer) UpdateWeights(ctx context.Context, feedback *ml.PredictionFeedback) error {
 	s.logger.Info(ctx, "Updating sentiment analyzer weights", map[string]interface{}{
@@ -446,6 +539,97 @@ func (s *SentimentAnalyzer) analyzeText(text string, req *SentimentRequest) (*Se
 	return result, nil
 }
 
+// detectLanguages detects the language of each text, or returns nil when the request declares one
+func (s *SentimentAnalyzer) detectLanguages(req *SentimentRequest) []LanguageDetection {
+	if req.Language != "" && req.Language != "auto" {
+		return nil
+	}
+	languages := make([]LanguageDetection, len(req.Texts))
+	for i, text := range req.Texts {
+		languages[i] = DetectLanguage(text)
+	}
+	return languages
+}
+
+// translateTexts translates every text that is not plainly English when the request asks for it,
+// keyed by text index. Failures are recorded on the translation rather than failing the request.
+func (s *SentimentAnalyzer) translateTexts(ctx context.Context, req *SentimentRequest, languages []LanguageDetection, translator TranslationProvider) map[int]*SentimentTranslation {
+	if !req.TranslateToEnglish {
+		return nil
+	}
+	if translator == nil {
+		translator = NoopTranslationProvider{}
+	}
+
+	translations := make(map[int]*SentimentTranslation)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentTranslations)
+
+	for i, text := range req.Texts {
+		language := req.Language
+		if languages != nil {
+			if languages[i].Language == "en" && !languages[i].Mixed {
+				continue
+			}
+			language = languages[i].Language
+		} else if language == "en" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, text, language string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			translation := &SentimentTranslation{SourceLanguage: language, Provider: translator.Name()}
+			translated, err := translator.Translate(ctx, text, language, "English")
+			if err != nil {
+				translation.Error = err.Error()
+				s.logger.Warn(ctx, "Failed to translate text for sentiment analysis", map[string]interface{}{
+					"error":    err.Error(),
+					"language": language,
+					"provider": translator.Name(),
+				})
+			} else {
+				translation.Text = translated
+			}
+
+			mu.Lock()
+			translations[i] = translation
+			mu.Unlock()
+		}(i, text, language)
+	}
+	wg.Wait()
+
+	return translations
+}
+
+// applyTranslation rescores a result on its translation, keeping the original-language score
+func (s *SentimentAnalyzer) applyTranslation(result *SentimentResult, translation *SentimentTranslation, req *SentimentRequest) {
+	translation.OriginalSentiment = result.Sentiment
+	result.Translation = translation
+	if translation.Error != "" {
+		return
+	}
+
+	translated, err := s.analyzeText(translation.Text, req)
+	if err != nil {
+		return
+	}
+	translation.TranslatedSentiment = &translated.Sentiment
+
+	result.Sentiment = translated.Sentiment
+	result.Confidence = translated.Confidence
+	result.Label = translated.Label
+	result.Emotions = translated.Emotions
+	result.Keywords = translated.Keywords
+	result.Entities = translated.Entities
+	result.IsSarcasm = translated.IsSarcasm
+	result.Subjectivity = translated.Subjectivity
+}
+
 func (s *SentimentAnalyzer) calculateSentiment(text string) float64 {
 	words := strings.Fields(strings.ToLower(text))
 	if len(words) == 0 {
@@ -796,12 +980,49 @@ func (s *SentimentAnalyzer) aggregateResults(results []SentimentResult) *Aggrega
 		TopKeywords:           topKeywords,
 		TrendingEntities:      trendingEntities,
 		VolumeMetrics:         volumeMetrics,
+		ByLanguage:            s.aggregateByLanguage(results),
 	}
 }
 
+// aggregateByLanguage breaks sentiment down by the (detected or declared) language of each text
+func (s *SentimentAnalyzer) aggregateByLanguage(results []SentimentResult) map[string]*LanguageSentiment {
+	byLanguage := make(map[string]*LanguageSentiment)
+	for _, result := range results {
+		language := result.Language
+		if language == "" {
+			language = "unknown"
+		}
+		group, exists := byLanguage[language]
+		if !exists {
+			group = &LanguageSentiment{Distribution: map[string]int{"positive": 0, "negative": 0, "neutral": 0}}
+			byLanguage[language] = group
+		}
+		group.Texts++
+		group.Sentiment += result.Sentiment
+		group.Confidence += result.Confidence
+		group.Distribution[result.Label]++
+		if result.Translation != nil && result.Translation.TranslatedSentiment != nil {
+			group.Translated++
+		}
+	}
+	for _, group := range byLanguage {
+		group.Sentiment /= float64(group.Texts)
+		group.Confidence /= float64(group.Texts)
+	}
+	return byLanguage
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
 	}
 	return b
 }
+
+func copyWeights(weights map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(weights))
+	for key, value := range weights {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,271 @@
+package ai
+
+import (
+	"context"
+	"encoding/csv"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	minTableRows     = 2 // header plus at least one data row
+	maxTablesPerItem = 50
+	maxTableRows     = 1000
+)
+
+// Table sources
+const (
+	TableSourceTextLayout = "text_layout"
+	TableSourceOCR        = "ocr"
+)
+
+var columnGapPattern = regexp.MustCompile(`\s{2,}`)
+var separatorRowPattern = regexp.MustCompile(`^[\s|:+-]+$`)
+
+// TableDetector finds tables in extracted document text or positioned OCR output
+type TableDetector interface {
+	DetectTables(ctx context.Context, input TableDetectionInput) ([]ExtractedTable, error)
+}
+
+// TableDetectionInput is the text of a document or image to search for tables. Text keeps
+// the line layout of the source; TextBlocks are positioned OCR results for images and scans.
+type TableDetectionInput struct {
+	Text       string
+	TextBlocks []TextBlock
+	MimeType   string
+	Filename   string
+}
+
+// LayoutTableDetector is the default TableDetector. It treats runs of lines that split into
+// the same number of columns (by tabs, pipes, wide gaps or CSV commas) as tables, and groups
+// OCR blocks into rows and columns by their position.
+type LayoutTableDetector struct{}
+
+// NewLayoutTableDetector creates the default table detector
+func NewLayoutTableDetector() *LayoutTableDetector {
+	return &LayoutTableDetector{}
+}
+
+func (d *LayoutTableDetector) DetectTables(ctx context.Context, input TableDetectionInput) ([]ExtractedTable, error) {
+	tables := detectTextTables(input.Text, isCSVDocument(input.MimeType, input.Filename))
+	tables = append(tables, detectBlockTables(input.TextBlocks)...)
+	if len(tables) > maxTablesPerItem {
+		tables = tables[:maxTablesPerItem]
+	}
+	return tables, ctx.Err()
+}
+
+// tableRowDelimiter is how a line was split into cells, in decreasing order of reliability
+type tableRowDelimiter int
+
+const (
+	delimiterTab tableRowDelimiter = iota
+	delimiterPipe
+	delimiterComma
+	delimiterGap
+)
+
+var delimiterConfidence = map[tableRowDelimiter]float64{
+	delimiterTab:   0.95,
+	delimiterPipe:  0.95,
+	delimiterComma: 0.9,
+	delimiterGap:   0.8,
+}
+
+// tableBuilder accumulates consecutive rows with the same shape
+type tableBuilder struct {
+	rows       [][]string
+	confidence [][]float64
+	columns    int
+	delimiter  tableRowDelimiter
+	source     string
+}
+
+func (b *tableBuilder) add(cells []string, confidence []float64) {
+	if len(b.rows) < maxTableRows {
+		b.rows = append(b.rows, cells)
+		b.confidence = append(b.confidence, confidence)
+	}
+}
+
+// build turns the rows into a table, taking the first row as the header
+func (b *tableBuilder) build() (ExtractedTable, bool) {
+	if len(b.rows) < minTableRows {
+		return ExtractedTable{}, false
+	}
+
+	var total float64
+	var count int
+	for _, row := range b.confidence {
+		for _, c := range row {
+			total += c
+			count++
+		}
+	}
+
+	return ExtractedTable{
+		Headers:        b.rows[0],
+		Rows:           b.rows[1:],
+		Confidence:     math.Round(total/float64(count)*100) / 100,
+		CellConfidence: b.confidence[1:],
+		Source:         b.source,
+		Metadata: map[string]interface{}{
+			"columns": b.columns,
+		},
+	}, true
+}
+
+// detectTextTables finds tables in line-oriented text
+func detectTextTables(text string, csvFormat bool) []ExtractedTable {
+	var tables []ExtractedTable
+	var current *tableBuilder
+
+	flush := func() {
+		if current != nil {
+			if table, ok := current.build(); ok {
+				tables = append(tables, table)
+			}
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		// Markdown-style separator rows continue a table without adding to it
+		if current != nil && current.delimiter == delimiterPipe && separatorRowPattern.MatchString(line) && strings.Contains(line, "-") {
+			continue
+		}
+
+		cells, delimiter := splitTableRow(line, csvFormat)
+		if len(cells) < 2 {
+			flush()
+			continue
+		}
+		if current != nil && (len(cells) != current.columns || delimiter != current.delimiter) {
+			flush()
+		}
+		if current == nil {
+			current = &tableBuilder{columns: len(cells), delimiter: delimiter, source: TableSourceTextLayout}
+		}
+
+		base := delimiterConfidence[delimiter]
+		confidence := make([]float64, len(cells))
+		for i, cell := range cells {
+			confidence[i] = base
+			if cell == "" {
+				confidence[i] = base / 2
+			}
+		}
+		current.add(cells, confidence)
+	}
+	flush()
+
+	return tables
+}
+
+// splitTableRow splits a line into cells using the most reliable delimiter it contains
+func splitTableRow(line string, csvFormat bool) ([]string, tableRowDelimiter) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return nil, delimiterGap
+	}
+
+	switch {
+	case strings.Contains(line, "\t"):
+		return trimCells(strings.Split(strings.Trim(line, "\t"), "\t")), delimiterTab
+	case strings.Count(line, "|") >= 2:
+		return trimCells(strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")), delimiterPipe
+	case csvFormat:
+		reader := csv.NewReader(strings.NewReader(line))
+		reader.LazyQuotes = true
+		if cells, err := reader.Read(); err == nil {
+			return trimCells(cells), delimiterComma
+		}
+		return nil, delimiterComma
+	default:
+		return trimCells(columnGapPattern.Split(strings.TrimSpace(line), -1)), delimiterGap
+	}
+}
+
+func trimCells(cells []string) []string {
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// detectBlockTables groups OCR blocks into rows by vertical position and treats consecutive
+// rows with the same number of blocks as a table, using each block's confidence for its cell
+func detectBlockTables(blocks []TextBlock) []ExtractedTable {
+	positioned := make([]TextBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if strings.TrimSpace(block.Text) != "" {
+			positioned = append(positioned, block)
+		}
+	}
+	if len(positioned) < 2*minTableRows {
+		return nil
+	}
+
+	centerY := func(block TextBlock) float64 { return block.BoundingBox.Y + block.BoundingBox.Height/2 }
+	sort.SliceStable(positioned, func(i, j int) bool { return centerY(positioned[i]) < centerY(positioned[j]) })
+
+	// Blocks whose centres are within half a line height share a row
+	var rows [][]TextBlock
+	for _, block := range positioned {
+		if n := len(rows); n > 0 {
+			last := rows[n-1][0]
+			tolerance := math.Max(last.BoundingBox.Height, block.BoundingBox.Height) / 2
+			if math.Abs(centerY(block)-centerY(last)) <= tolerance {
+				rows[n-1] = append(rows[n-1], block)
+				continue
+			}
+		}
+		rows = append(rows, []TextBlock{block})
+	}
+
+	var tables []ExtractedTable
+	var current *tableBuilder
+	flush := func() {
+		if current != nil {
+			if table, ok := current.build(); ok {
+				tables = append(tables, table)
+			}
+			current = nil
+		}
+	}
+
+	for _, row := range rows {
+		if len(row) < 2 {
+			flush()
+			continue
+		}
+		sort.SliceStable(row, func(i, j int) bool { return row[i].BoundingBox.X < row[j].BoundingBox.X })
+		if current != nil && len(row) != current.columns {
+			flush()
+		}
+		if current == nil {
+			current = &tableBuilder{columns: len(row), source: TableSourceOCR}
+		}
+
+		cells := make([]string, len(row))
+		confidence := make([]float64, len(row))
+		for i, block := range row {
+			cells[i] = strings.TrimSpace(block.Text)
+			confidence[i] = block.Confidence
+		}
+		current.add(cells, confidence)
+	}
+	flush()
+
+	return tables
+}
+
+func isCSVDocument(mimeType, filename string) bool {
+	return strings.Contains(strings.ToLower(mimeType), "csv") || strings.HasSuffix(strings.ToLower(filename), ".csv")
+}
+
+func isPDFDocument(mimeType, filename string) bool {
+	return strings.Contains(strings.ToLower(mimeType), "pdf") || strings.HasSuffix(strings.ToLower(filename), ".pdf")
+}
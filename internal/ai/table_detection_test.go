@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFinancialStatement = `Q3 2024 Treasury Report
+
+Holdings remained stable over the quarter.
+
+| Asset | Amount | Value (USD) |
+|-------|--------|-------------|
+| BTC   | 12.5   | 812,500     |
+| ETH   | 340    | 1,105,000   |
+
+Quarter      Revenue      Expenses
+Q1           1.2M         0.8M
+Q2           1.5M         0.9M
+Q3           1.9M
+
+Outlook: continued accumulation.`
+
+func TestLayoutTableDetector_Text(t *testing.T) {
+	detector := NewLayoutTableDetector()
+
+	tables, err := detector.DetectTables(context.Background(), TableDetectionInput{Text: testFinancialStatement})
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+
+	holdings := tables[0]
+	assert.Equal(t, []string{"Asset", "Amount", "Value (USD)"}, holdings.Headers)
+	assert.Equal(t, [][]string{{"BTC", "12.5", "812,500"}, {"ETH", "340", "1,105,000"}}, holdings.Rows)
+	assert.Equal(t, TableSourceTextLayout, holdings.Source)
+	require.Len(t, holdings.CellConfidence, 2)
+	assert.Len(t, holdings.CellConfidence[0], 3)
+
+	// The Q3 row has a missing column, so it ends the table
+	revenue := tables[1]
+	assert.Equal(t, []string{"Quarter", "Revenue", "Expenses"}, revenue.Headers)
+	assert.Len(t, revenue.Rows, 2)
+	assert.Less(t, revenue.Confidence, holdings.Confidence)
+
+	t.Run("CSV", func(t *testing.T) {
+		tables, err := detector.DetectTables(context.Background(), TableDetectionInput{
+			Text:     "date,symbol,close\n2024-01-01,BTC,\"42,280\"\n2024-01-02,BTC,\"44,950\"\n",
+			MimeType: "text/csv",
+		})
+		require.NoError(t, err)
+		require.Len(t, tables, 1)
+		assert.Equal(t, []string{"2024-01-02", "BTC", "44,950"}, tables[0].Rows[1])
+	})
+
+	t.Run("Prose", func(t *testing.T) {
+		tables, err := detector.DetectTables(context.Background(), TableDetectionInput{Text: "Bitcoin rallied, then fell.\nEthereum, too."})
+		require.NoError(t, err)
+		assert.Empty(t, tables)
+	})
+}
+
+func TestLayoutTableDetector_OCRBlocks(t *testing.T) {
+	block := func(text string, x, y, confidence float64) TextBlock {
+		return TextBlock{Text: text, Confidence: confidence, BoundingBox: BoundingBox{X: x, Y: y, Width: 0.2, Height: 0.04}}
+	}
+	blocks := []TextBlock{
+		block("BTC/USD Order Book", 0.1, 0.02, 0.97),
+		block("Size", 0.5, 0.10, 0.93),
+		block("Price", 0.1, 0.11, 0.95), // slightly lower on the scan but the same row
+		block("50,010", 0.1, 0.16, 0.9),
+		block("1.25", 0.5, 0.16, 0.6),
+		block("50,005", 0.1, 0.21, 0.88),
+		block("0.40", 0.5, 0.21, 0.85),
+	}
+
+	tables, err := NewLayoutTableDetector().DetectTables(context.Background(), TableDetectionInput{TextBlocks: blocks})
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+
+	table := tables[0]
+	assert.Equal(t, TableSourceOCR, table.Source)
+	assert.Equal(t, []string{"Price", "Size"}, table.Headers)
+	assert.Equal(t, [][]string{{"50,010", "1.25"}, {"50,005", "0.40"}}, table.Rows)
+	assert.Equal(t, [][]float64{{0.9, 0.6}, {0.88, 0.85}}, table.CellConfidence)
+}
+
+type failingTableDetector struct{}
+
+func (failingTableDetector) DetectTables(ctx context.Context, input TableDetectionInput) ([]ExtractedTable, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func TestMultiModalEngine_ExtractTables(t *testing.T) {
+	engine := NewMultiModalEngine(&observability.Logger{})
+	ctx := context.Background()
+
+	document := MultiModalContent{
+		ID:       uuid.New().String(),
+		Type:     "document",
+		Data:     base64.StdEncoding.EncodeToString([]byte(testFinancialStatement)),
+		MimeType: "text/plain",
+		Filename: "treasury.txt",
+		Size:     int64(len(testFinancialStatement)),
+	}
+	process := func(content MultiModalContent, options MultiModalOptions) ContentAnalysisResult {
+		result, err := engine.ProcessMultiModalRequest(ctx, &MultiModalRequest{
+			RequestID:   uuid.New().String(),
+			UserID:      uuid.New(),
+			Type:        content.Type,
+			Content:     []MultiModalContent{content},
+			Options:     options,
+			RequestedAt: time.Now(),
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Results, 1)
+		return result.Results[0]
+	}
+
+	result := process(document, MultiModalOptions{ExtractText: true, ExtractTables: true})
+	require.NotNil(t, result.DocumentAnalysis)
+	assert.Len(t, result.Tables, 2)
+	assert.Equal(t, result.Tables, result.DocumentAnalysis.Tables)
+
+	document.ID = uuid.New().String()
+	result = process(document, MultiModalOptions{ExtractText: true})
+	assert.Empty(t, result.Tables, "tables are only extracted on request")
+
+	t.Run("Chart", func(t *testing.T) {
+		result := process(MultiModalContent{
+			ID:       uuid.New().String(),
+			Type:     "image",
+			Data:     "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChAHGArEkAAAAAElFTkSuQmCC",
+			MimeType: "image/png",
+			Filename: "chart.png",
+			Size:     100,
+		}, MultiModalOptions{AnalyzeCharts: true})
+		require.NotNil(t, result.ChartAnalysis)
+		assert.Nil(t, result.OCRResult, "OCR output is only returned when text extraction is requested")
+	})
+
+	t.Run("DetectorFailure", func(t *testing.T) {
+		engine.SetTableDetector(failingTableDetector{})
+		document.ID = uuid.New().String()
+		result := process(document, MultiModalOptions{ExtractText: true, ExtractTables: true})
+		require.NotNil(t, result.DocumentAnalysis)
+		assert.Empty(t, result.Tables)
+	})
+}
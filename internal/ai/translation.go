@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+)
+
+// ErrTranslationUnavailable is returned when no translation provider is configured
+var ErrTranslationUnavailable = errors.New("translation unavailable")
+
+// TranslationProvider translates text between languages
+type TranslationProvider interface {
+	// Name identifies the provider and model
+	Name() string
+	// Translate renders text in the target language. sourceLanguage may be empty when unknown.
+	Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error)
+}
+
+// NewTranslationProvider returns the OpenAI translation provider when an API key is
+// configured and a provider that always fails with ErrTranslationUnavailable otherwise
+func NewTranslationProvider(cfg config.AIConfig) TranslationProvider {
+	if cfg.OpenAIKey == "" {
+		return NoopTranslationProvider{}
+	}
+	return NewOpenAITranslationProvider(cfg.OpenAIKey, cfg.TranslationModel)
+}
+
+// NoopTranslationProvider never translates
+type NoopTranslationProvider struct{}
+
+// Name identifies the provider
+func (NoopTranslationProvider) Name() string { return "noop" }
+
+// Translate always fails with ErrTranslationUnavailable
+func (NoopTranslationProvider) Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error) {
+	return "", ErrTranslationUnavailable
+}
+
+// OpenAITranslationProvider translates with the OpenAI chat completions API
+type OpenAITranslationProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAITranslationProvider creates an OpenAI translation provider. An empty model
+// defaults to gpt-4o-mini.
+func NewOpenAITranslationProvider(apiKey, model string) *OpenAITranslationProvider {
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+	return &OpenAITranslationProvider{
+		baseURL: "https://api.openai.com/v1",
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name identifies the provider and model
+func (p *OpenAITranslationProvider) Name() string {
+	return "openai:" + p.model
+}
+
+// Translate asks the model for a literal translation that keeps tickers, slang and tone
+func (p *OpenAITranslationProvider) Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error) {
+	instruction := fmt.Sprintf("Translate the user's message to %s. Keep ticker symbols, numbers and the author's tone, "+
+		"including slang and sarcasm. Reply with the translation only.", targetLanguage)
+	if sourceLanguage != "" {
+		instruction += fmt.Sprintf(" The message is probably in %s.", sourceLanguage)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"model":       p.model,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "system", "content": instruction},
+			{"role": "user", "content": text},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.baseURL, "/")+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("translation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d from translation API", resp.StatusCode)
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &completion); err != nil {
+		return "", fmt.Errorf("invalid translation response: %w", err)
+	}
+	if len(completion.Choices) == 0 || strings.TrimSpace(completion.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("empty translation response")
+	}
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
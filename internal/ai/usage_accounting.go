@@ -0,0 +1,489 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/redis/go-redis/v9"
+)
+
+// Usage accounting errors
+var (
+	ErrUsageBudgetExceeded = errors.New("monthly AI usage budget exceeded")
+	ErrInvalidUsageQuery   = errors.New("invalid usage query")
+	ErrInvalidUsageBudget  = errors.New("invalid usage budget")
+)
+
+// Usage report groupings
+const (
+	UsageGroupByUser     = "user"
+	UsageGroupByEndpoint = "endpoint"
+	UsageGroupByModel    = "model"
+)
+
+const (
+	// usageUnattributed stands in for the user and endpoint of calls made outside a request,
+	// such as scheduled reports
+	usageUnattributed = "background"
+	// usageBudgetCacheTTL is how long a user's budget limit is cached
+	usageBudgetCacheTTL = time.Minute
+	// usageMonthKeyTTL keeps a month's spend counter until the month is well over
+	usageMonthKeyTTL = 40 * 24 * time.Hour
+)
+
+type usageAttributionKey struct{}
+
+// UsageAttribution is the user and endpoint provider calls are made for
+type UsageAttribution struct {
+	UserID   string
+	Endpoint string // route pattern, e.g. "POST /ai/chat"
+}
+
+// WithUsageAttribution attributes the provider calls made with ctx to a user and endpoint
+func WithUsageAttribution(ctx context.Context, attribution UsageAttribution) context.Context {
+	return context.WithValue(ctx, usageAttributionKey{}, attribution)
+}
+
+func usageAttributionFrom(ctx context.Context) UsageAttribution {
+	attribution, _ := ctx.Value(usageAttributionKey{}).(UsageAttribution)
+	if attribution.UserID == "" {
+		attribution.UserID = usageUnattributed
+	}
+	if attribution.Endpoint == "" {
+		attribution.Endpoint = usageUnattributed
+	}
+	return attribution
+}
+
+// ModelPriceTable prices provider calls per model
+type ModelPriceTable struct {
+	prices   map[string]TokenPricing
+	fallback TokenPricing
+}
+
+// NewModelPriceTable parses per-model "input,output" USD prices per 1K tokens. Models not in
+// the table, unless a "default" entry is given, are priced at fallback.
+func NewModelPriceTable(prices map[string][]string, fallback TokenPricing) (ModelPriceTable, error) {
+	table := ModelPriceTable{prices: make(map[string]TokenPricing), fallback: fallback}
+	for model, values := range prices {
+		if len(values) != 2 {
+			return table, fmt.Errorf("price of model %s must be input,output", model)
+		}
+		input, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return table, fmt.Errorf("invalid input price of model %s: %w", model, err)
+		}
+		output, err := strconv.ParseFloat(values[1], 64)
+		if err != nil {
+			return table, fmt.Errorf("invalid output price of model %s: %w", model, err)
+		}
+		table.prices[strings.ToLower(model)] = TokenPricing{PromptPer1K: input, CompletionPer1K: output}
+	}
+	if pricing, ok := table.prices[defaultProviderRoute]; ok {
+		table.fallback = pricing
+	}
+	return table, nil
+}
+
+// Cost returns the USD cost of a call to model
+func (t ModelPriceTable) Cost(model string, inputTokens, outputTokens int) float64 {
+	pricing, ok := t.prices[strings.ToLower(model)]
+	if !ok {
+		pricing = t.fallback
+	}
+	return float64(inputTokens)/1000*pricing.PromptPer1K + float64(outputTokens)/1000*pricing.CompletionPer1K
+}
+
+// UsageRecord is one provider call
+type UsageRecord struct {
+	UserID       string
+	Endpoint     string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+	At           time.Time
+}
+
+// UsageAggregate is the usage of one user, endpoint and model within an hour
+type UsageAggregate struct {
+	Hour         time.Time
+	UserID       string
+	Endpoint     string
+	Provider     string
+	Model        string
+	Calls        int64
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// UsageGroup is the usage of one user, endpoint or model in a report
+type UsageGroup struct {
+	Key          string  `json:"key"`
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// UsageReport is the usage over a period, grouped and ordered by cost
+type UsageReport struct {
+	GroupBy string       `json:"group_by"`
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+	Groups  []UsageGroup `json:"groups"`
+	Total   UsageGroup   `json:"total"`
+	Budget  *UsageBudget `json:"budget,omitempty"`
+}
+
+// UsageBudget is a user's monthly limit and what they spent this month
+type UsageBudget struct {
+	MonthlyLimitUSD float64  `json:"monthly_limit_usd"` // zero means no limit
+	SpentUSD        float64  `json:"spent_usd"`
+	RemainingUSD    *float64 `json:"remaining_usd,omitempty"`
+	Month           string   `json:"month"`
+}
+
+// UsageCounters accumulate usage until it is flushed, and keep each user's spend per month
+type UsageCounters interface {
+	Add(ctx context.Context, record UsageRecord) error
+	MonthlyCost(ctx context.Context, userID string, month time.Time) (float64, error)
+	// Drain returns the accumulated aggregates and removes them from the counters
+	Drain(ctx context.Context) ([]UsageAggregate, error)
+}
+
+// UsageStore persists flushed usage and per-user budget limits
+type UsageStore interface {
+	// AddUsage adds the aggregates to the stored totals of their hour
+	AddUsage(ctx context.Context, aggregates []UsageAggregate) error
+	// UsageGroups sums the usage since from by the groupBy column, for one user when userID is set
+	UsageGroups(ctx context.Context, groupBy, userID string, from time.Time) ([]UsageGroup, error)
+	// GetBudget returns a user's monthly limit, or false when they have none of their own
+	GetBudget(ctx context.Context, userID string) (float64, bool, error)
+	SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error
+}
+
+// UsageAccountant prices and records provider calls per user and endpoint, and enforces
+// monthly budgets. Usage is counted in Redis so budgets hold across replicas, and flushed to
+// Postgres for reporting.
+type UsageAccountant struct {
+	logger        *observability.Logger
+	counters      UsageCounters
+	store         UsageStore
+	prices        ModelPriceTable
+	defaultBudget float64
+	interval      time.Duration
+
+	mu      sync.Mutex
+	budgets map[string]cachedUsageBudget
+}
+
+type cachedUsageBudget struct {
+	limit     float64
+	fetchedAt time.Time
+}
+
+// NewUsageAccountant creates an accountant flushing counters to store every interval.
+// defaultBudget is the monthly limit of users without their own; zero means none.
+func NewUsageAccountant(logger *observability.Logger, counters UsageCounters, store UsageStore, prices ModelPriceTable, defaultBudget float64, interval time.Duration) *UsageAccountant {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &UsageAccountant{
+		logger:        logger,
+		counters:      counters,
+		store:         store,
+		prices:        prices,
+		defaultBudget: defaultBudget,
+		interval:      interval,
+		budgets:       make(map[string]cachedUsageBudget),
+	}
+}
+
+// Start flushes the counters every interval until ctx is cancelled, and once more on the way out
+func (a *UsageAccountant) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := a.Flush(context.WithoutCancel(ctx)); err != nil {
+					a.logger.Error(ctx, "Failed to flush AI usage on shutdown", err)
+				}
+				return
+			case <-ticker.C:
+				if err := a.Flush(ctx); err != nil {
+					a.logger.Error(ctx, "Failed to flush AI usage", err)
+				}
+			}
+		}
+	}()
+}
+
+// Record prices a provider call and counts it against the user and endpoint in ctx. Counting
+// failures are logged rather than failing the call that was already made.
+func (a *UsageAccountant) Record(ctx context.Context, provider, model string, inputTokens, outputTokens int) {
+	attribution := usageAttributionFrom(ctx)
+	record := UsageRecord{
+		UserID:       attribution.UserID,
+		Endpoint:     attribution.Endpoint,
+		Provider:     provider,
+		Model:        model,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CostUSD:      a.prices.Cost(model, inputTokens, outputTokens),
+		At:           time.Now(),
+	}
+	if err := a.counters.Add(ctx, record); err != nil {
+		a.logger.Warn(ctx, "Failed to count AI usage", map[string]interface{}{
+			"user_id":  record.UserID,
+			"endpoint": record.Endpoint,
+			"model":    model,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// CheckBudget returns ErrUsageBudgetExceeded once the user in ctx spent their monthly budget.
+// Calls outside a request and users without a limit always pass, as do checks that fail.
+func (a *UsageAccountant) CheckBudget(ctx context.Context) error {
+	attribution := usageAttributionFrom(ctx)
+	if attribution.UserID == usageUnattributed {
+		return nil
+	}
+	budget, err := a.Budget(ctx, attribution.UserID)
+	if err != nil {
+		a.logger.Warn(ctx, "Failed to check AI usage budget", map[string]interface{}{
+			"user_id": attribution.UserID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	if budget.MonthlyLimitUSD > 0 && budget.SpentUSD >= budget.MonthlyLimitUSD {
+		return fmt.Errorf("%w: spent $%.2f of $%.2f for %s", ErrUsageBudgetExceeded, budget.SpentUSD, budget.MonthlyLimitUSD, budget.Month)
+	}
+	return nil
+}
+
+// Budget returns a user's monthly limit and their spend this month
+func (a *UsageAccountant) Budget(ctx context.Context, userID string) (*UsageBudget, error) {
+	now := time.Now().UTC()
+	limit, err := a.budgetLimit(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	spent, err := a.counters.MonthlyCost(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &UsageBudget{MonthlyLimitUSD: limit, SpentUSD: spent, Month: now.Format("2006-01")}
+	if limit > 0 {
+		remaining := limit - spent
+		if remaining < 0 {
+			remaining = 0
+		}
+		budget.RemainingUSD = &remaining
+	}
+	return budget, nil
+}
+
+// SetBudget sets a user's own monthly limit, overriding the default; zero removes their limit
+func (a *UsageAccountant) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error {
+	if monthlyLimitUSD < 0 {
+		return fmt.Errorf("%w: monthly limit must not be negative", ErrInvalidUsageBudget)
+	}
+	if err := a.store.SetBudget(ctx, userID, monthlyLimitUSD); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	delete(a.budgets, userID)
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *UsageAccountant) budgetLimit(ctx context.Context, userID string, now time.Time) (float64, error) {
+	a.mu.Lock()
+	cached, ok := a.budgets[userID]
+	a.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < usageBudgetCacheTTL {
+		return cached.limit, nil
+	}
+
+	limit, own, err := a.store.GetBudget(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !own {
+		limit = a.defaultBudget
+	}
+
+	a.mu.Lock()
+	a.budgets[userID] = cachedUsageBudget{limit: limit, fetchedAt: now}
+	a.mu.Unlock()
+	return limit, nil
+}
+
+// Flush moves the counted usage to the store. Drained aggregates that can't be stored are
+// logged and lost, so that a store outage doesn't grow the counters without bound.
+func (a *UsageAccountant) Flush(ctx context.Context) error {
+	aggregates, err := a.counters.Drain(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drain usage counters: %w", err)
+	}
+	if len(aggregates) == 0 {
+		return nil
+	}
+	if err := a.store.AddUsage(ctx, aggregates); err != nil {
+		a.logger.Error(ctx, "Failed to store AI usage", err, map[string]interface{}{
+			"aggregates": len(aggregates),
+		})
+		return err
+	}
+	return nil
+}
+
+// Report sums the usage of the last period by user, endpoint or model, for one user when
+// userID is set. Usage shows up once the counters were flushed.
+func (a *UsageAccountant) Report(ctx context.Context, groupBy, userID string, period time.Duration) (*UsageReport, error) {
+	switch groupBy {
+	case UsageGroupByUser, UsageGroupByEndpoint, UsageGroupByModel:
+	default:
+		return nil, fmt.Errorf("%w: group_by must be user, endpoint or model", ErrInvalidUsageQuery)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("%w: range must be positive", ErrInvalidUsageQuery)
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-period)
+	groups, err := a.store.UsageGroups(ctx, groupBy, userID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{GroupBy: groupBy, From: from, To: to, Groups: groups, Total: UsageGroup{Key: "total"}}
+	for _, group := range groups {
+		report.Total.Calls += group.Calls
+		report.Total.InputTokens += group.InputTokens
+		report.Total.OutputTokens += group.OutputTokens
+		report.Total.CostUSD += group.CostUSD
+	}
+	return report, nil
+}
+
+// completionRequestTokens estimates the input tokens of a completion request
+func completionRequestTokens(req CompletionRequest) int {
+	tokens := estimateTokens(req.System)
+	for _, message := range req.Messages {
+		tokens += estimateTokens(message.Content)
+	}
+	return tokens
+}
+
+// redisUsageCounters implements UsageCounters with a hash per pending aggregate, indexed by a
+// set, and a spend counter per user and month
+type redisUsageCounters struct {
+	redis *database.RedisClient
+}
+
+const (
+	usagePendingSetKey = "ai_usage:pending"
+	usagePendingPrefix = "ai_usage:pending:"
+	usageMonthPrefix   = "ai_usage:month:"
+)
+
+func NewRedisUsageCounters(redis *database.RedisClient) UsageCounters {
+	return &redisUsageCounters{redis: redis}
+}
+
+func (c *redisUsageCounters) Add(ctx context.Context, record UsageRecord) error {
+	hour := record.At.UTC().Truncate(time.Hour)
+	key := fmt.Sprintf("%s%d|%s|%s|%s|%s", usagePendingPrefix, hour.Unix(), record.UserID, record.Endpoint, record.Provider, record.Model)
+	monthKey := usageMonthKey(record.UserID, record.At)
+
+	_, err := c.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "hour", hour.Unix(), "user_id", record.UserID, "endpoint", record.Endpoint,
+			"provider", record.Provider, "model", record.Model)
+		pipe.HIncrBy(ctx, key, "calls", 1)
+		pipe.HIncrBy(ctx, key, "input_tokens", int64(record.InputTokens))
+		pipe.HIncrBy(ctx, key, "output_tokens", int64(record.OutputTokens))
+		pipe.HIncrByFloat(ctx, key, "cost_usd", record.CostUSD)
+		pipe.SAdd(ctx, usagePendingSetKey, key)
+		pipe.IncrByFloat(ctx, monthKey, record.CostUSD)
+		pipe.Expire(ctx, monthKey, usageMonthKeyTTL)
+		return nil
+	})
+	return err
+}
+
+func (c *redisUsageCounters) MonthlyCost(ctx context.Context, userID string, month time.Time) (float64, error) {
+	cost, err := c.redis.Client.Get(ctx, usageMonthKey(userID, month)).Float64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return cost, err
+}
+
+func (c *redisUsageCounters) Drain(ctx context.Context) ([]UsageAggregate, error) {
+	keys, err := c.redis.Client.SMembers(ctx, usagePendingSetKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates := make([]UsageAggregate, 0, len(keys))
+	for _, key := range keys {
+		// Read, delete and unindex at once so concurrent increments land in a fresh hash
+		var fields *redis.MapStringStringCmd
+		_, err := c.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			fields = pipe.HGetAll(ctx, key)
+			pipe.Del(ctx, key)
+			pipe.SRem(ctx, usagePendingSetKey, key)
+			return nil
+		})
+		if err != nil {
+			return aggregates, err
+		}
+		if aggregate, ok := parseUsageAggregate(fields.Val()); ok {
+			aggregates = append(aggregates, aggregate)
+		}
+	}
+	return aggregates, nil
+}
+
+func parseUsageAggregate(fields map[string]string) (UsageAggregate, bool) {
+	if len(fields) == 0 {
+		return UsageAggregate{}, false
+	}
+	hour, _ := strconv.ParseInt(fields["hour"], 10, 64)
+	calls, _ := strconv.ParseInt(fields["calls"], 10, 64)
+	inputTokens, _ := strconv.ParseInt(fields["input_tokens"], 10, 64)
+	outputTokens, _ := strconv.ParseInt(fields["output_tokens"], 10, 64)
+	cost, _ := strconv.ParseFloat(fields["cost_usd"], 64)
+	return UsageAggregate{
+		Hour:         time.Unix(hour, 0).UTC(),
+		UserID:       fields["user_id"],
+		Endpoint:     fields["endpoint"],
+		Provider:     fields["provider"],
+		Model:        fields["model"],
+		Calls:        calls,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CostUSD:      cost,
+	}, true
+}
+
+func usageMonthKey(userID string, at time.Time) string {
+	return usageMonthPrefix + at.UTC().Format("2006-01") + ":" + userID
+}
@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUsageCounters keeps usage counters in memory, keyed like the Redis counters
+type memoryUsageCounters struct {
+	pending map[string]*UsageAggregate
+	monthly map[string]float64
+}
+
+func newMemoryUsageCounters() *memoryUsageCounters {
+	return &memoryUsageCounters{pending: make(map[string]*UsageAggregate), monthly: make(map[string]float64)}
+}
+
+func (c *memoryUsageCounters) Add(ctx context.Context, record UsageRecord) error {
+	hour := record.At.UTC().Truncate(time.Hour)
+	key := hour.String() + "|" + record.UserID + "|" + record.Endpoint + "|" + record.Provider + "|" + record.Model
+	aggregate, ok := c.pending[key]
+	if !ok {
+		aggregate = &UsageAggregate{Hour: hour, UserID: record.UserID, Endpoint: record.Endpoint, Provider: record.Provider, Model: record.Model}
+		c.pending[key] = aggregate
+	}
+	aggregate.Calls++
+	aggregate.InputTokens += int64(record.InputTokens)
+	aggregate.OutputTokens += int64(record.OutputTokens)
+	aggregate.CostUSD += record.CostUSD
+	c.monthly[usageMonthKey(record.UserID, record.At)] += record.CostUSD
+	return nil
+}
+
+func (c *memoryUsageCounters) MonthlyCost(ctx context.Context, userID string, month time.Time) (float64, error) {
+	return c.monthly[usageMonthKey(userID, month)], nil
+}
+
+func (c *memoryUsageCounters) Drain(ctx context.Context) ([]UsageAggregate, error) {
+	aggregates := make([]UsageAggregate, 0, len(c.pending))
+	for key, aggregate := range c.pending {
+		aggregates = append(aggregates, *aggregate)
+		delete(c.pending, key)
+	}
+	return aggregates, nil
+}
+
+type memoryUsageStore struct {
+	usage   []UsageAggregate
+	budgets map[string]float64
+}
+
+func newMemoryUsageStore() *memoryUsageStore {
+	return &memoryUsageStore{budgets: make(map[string]float64)}
+}
+
+func (s *memoryUsageStore) AddUsage(ctx context.Context, aggregates []UsageAggregate) error {
+	s.usage = append(s.usage, aggregates...)
+	return nil
+}
+
+func (s *memoryUsageStore) UsageGroups(ctx context.Context, groupBy, userID string, from time.Time) ([]UsageGroup, error) {
+	byKey := make(map[string]*UsageGroup)
+	for _, aggregate := range s.usage {
+		if aggregate.Hour.Before(from.Truncate(time.Hour)) || (userID != "" && aggregate.UserID != userID) {
+			continue
+		}
+		key := map[string]string{
+			UsageGroupByUser:     aggregate.UserID,
+			UsageGroupByEndpoint: aggregate.Endpoint,
+			UsageGroupByModel:    aggregate.Model,
+		}[groupBy]
+		group, ok := byKey[key]
+		if !ok {
+			group = &UsageGroup{Key: key}
+			byKey[key] = group
+		}
+		group.Calls += aggregate.Calls
+		group.InputTokens += aggregate.InputTokens
+		group.OutputTokens += aggregate.OutputTokens
+		group.CostUSD += aggregate.CostUSD
+	}
+
+	groups := make([]UsageGroup, 0, len(byKey))
+	for _, group := range byKey {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].CostUSD > groups[j].CostUSD })
+	return groups, nil
+}
+
+func (s *memoryUsageStore) GetBudget(ctx context.Context, userID string) (float64, bool, error) {
+	limit, ok := s.budgets[userID]
+	return limit, ok, nil
+}
+
+func (s *memoryUsageStore) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error {
+	s.budgets[userID] = monthlyLimitUSD
+	return nil
+}
+
+func newTestUsageAccountant(t *testing.T, defaultBudget float64) (*UsageAccountant, *memoryUsageStore) {
+	prices, err := NewModelPriceTable(map[string][]string{
+		"primary-model": {"1", "2"},
+	}, TokenPricing{PromptPer1K: 0.5, CompletionPer1K: 0.5})
+	require.NoError(t, err)
+	store := newMemoryUsageStore()
+	return NewUsageAccountant(createTestLogger(), newMemoryUsageCounters(), store, prices, defaultBudget, time.Minute), store
+}
+
+func TestModelPriceTable(t *testing.T) {
+	prices, err := NewModelPriceTable(map[string][]string{
+		"gpt-4o":  {"0.0025", "0.01"},
+		"default": {"0.001", "0.002"},
+	}, TokenPricing{PromptPer1K: 5, CompletionPer1K: 5})
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.0025*2+0.01, prices.Cost("GPT-4o", 2000, 1000), 1e-12)
+	assert.InDelta(t, 0.001+0.002, prices.Cost("unknown-model", 1000, 1000), 1e-12, "the default entry replaces the fallback")
+
+	_, err = NewModelPriceTable(map[string][]string{"gpt-4o": {"0.0025"}}, TokenPricing{})
+	assert.Error(t, err)
+	_, err = NewModelPriceTable(map[string][]string{"gpt-4o": {"cheap", "0.01"}}, TokenPricing{})
+	assert.Error(t, err)
+}
+
+func TestUsageAccountant_RecordsAndReports(t *testing.T) {
+	accountant, _ := newTestUsageAccountant(t, 0)
+	router := newTestProviderRouter(&scriptedProvider{name: "primary"})
+	router.SetUsageAccountant(accountant)
+
+	alice := WithUsageAttribution(context.Background(), UsageAttribution{UserID: "alice", Endpoint: "POST /ai/chat"})
+	bob := WithUsageAttribution(context.Background(), UsageAttribution{UserID: "bob", Endpoint: "POST /ai/analyze"})
+	request := CompletionRequest{System: "be brief", Messages: []CompletionMessage{{Role: RoleUser, Content: "How is the market today?"}}}
+	for _, ctx := range []context.Context{alice, alice, bob, context.Background()} {
+		_, err := router.Complete(ctx, "chat", request)
+		require.NoError(t, err)
+	}
+
+	// Nothing is reported before the counters are flushed
+	report, err := accountant.Report(context.Background(), UsageGroupByUser, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, report.Groups)
+
+	require.NoError(t, accountant.Flush(context.Background()))
+
+	report, err = accountant.Report(context.Background(), UsageGroupByUser, "", 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 3)
+	assert.Equal(t, "alice", report.Groups[0].Key)
+	assert.EqualValues(t, 2, report.Groups[0].Calls)
+	assert.EqualValues(t, 4, report.Total.Calls)
+
+	inputTokens := completionRequestTokens(request)
+	outputTokens := estimateTokens("reply from primary")
+	assert.EqualValues(t, 2*inputTokens, report.Groups[0].InputTokens)
+	assert.InDelta(t, 2*(float64(inputTokens)/1000+float64(outputTokens)*2/1000), report.Groups[0].CostUSD, 1e-9)
+
+	byEndpoint, err := accountant.Report(context.Background(), UsageGroupByEndpoint, "", 24*time.Hour)
+	require.NoError(t, err)
+	keys := make([]string, 0, len(byEndpoint.Groups))
+	for _, group := range byEndpoint.Groups {
+		keys = append(keys, group.Key)
+	}
+	assert.ElementsMatch(t, []string{"POST /ai/chat", "POST /ai/analyze", usageUnattributed}, keys)
+
+	mine, err := accountant.Report(context.Background(), UsageGroupByModel, "bob", 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mine.Groups, 1)
+	assert.Equal(t, "primary-model", mine.Groups[0].Key)
+	assert.EqualValues(t, 1, mine.Total.Calls)
+
+	_, err = accountant.Report(context.Background(), "provider", "", 24*time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidUsageQuery)
+}
+
+func TestUsageAccountant_EnforcesMonthlyBudget(t *testing.T) {
+	accountant, _ := newTestUsageAccountant(t, 0.001)
+	provider := &scriptedProvider{name: "primary"}
+	router := newTestProviderRouter(provider)
+	router.SetUsageAccountant(accountant)
+
+	ctx := WithUsageAttribution(context.Background(), UsageAttribution{UserID: "alice", Endpoint: "POST /ai/chat"})
+	request := CompletionRequest{Messages: []CompletionMessage{{Role: RoleUser, Content: "Write me a long market outlook please"}}}
+
+	// The first call fits the default budget, after which the user is over it
+	_, err := router.Complete(ctx, "chat", request)
+	require.NoError(t, err)
+	_, err = router.Complete(ctx, "chat", request)
+	assert.ErrorIs(t, err, ErrUsageBudgetExceeded)
+	assert.Equal(t, 1, provider.calls, "no provider is called over budget")
+
+	budget, err := accountant.Budget(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 0.001, budget.MonthlyLimitUSD)
+	require.NotNil(t, budget.RemainingUSD)
+	assert.Zero(t, *budget.RemainingUSD)
+
+	// Calls outside a request are never refused
+	_, err = router.Complete(context.Background(), "chat", request)
+	assert.NoError(t, err)
+
+	// A user's own limit overrides the default; zero lifts it
+	require.NoError(t, accountant.SetBudget(ctx, "alice", 0))
+	_, err = router.Complete(ctx, "chat", request)
+	assert.NoError(t, err)
+	budget, err = accountant.Budget(ctx, "alice")
+	require.NoError(t, err)
+	assert.Zero(t, budget.MonthlyLimitUSD)
+	assert.Nil(t, budget.RemainingUSD)
+
+	assert.True(t, errors.Is(accountant.SetBudget(ctx, "alice", -1), ErrInvalidUsageBudget))
+}
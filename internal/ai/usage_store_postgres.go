@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// usageGroupColumns maps report groupings to ai_usage_hourly columns
+var usageGroupColumns = map[string]string{
+	UsageGroupByUser:     "user_id",
+	UsageGroupByEndpoint: "endpoint",
+	UsageGroupByModel:    "model",
+}
+
+// postgresUsageStore implements UsageStore using the ai_usage_hourly and ai_usage_budgets tables
+type postgresUsageStore struct {
+	db *database.DB
+}
+
+func NewPostgresUsageStore(db *database.DB) UsageStore {
+	return &postgresUsageStore{db: db}
+}
+
+func (s *postgresUsageStore) AddUsage(ctx context.Context, aggregates []UsageAggregate) error {
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, aggregate := range aggregates {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO ai_usage_hourly (hour, user_id, endpoint, provider, model, calls, input_tokens, output_tokens, cost_usd)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (hour, user_id, endpoint, provider, model) DO UPDATE SET
+					calls = ai_usage_hourly.calls + EXCLUDED.calls,
+					input_tokens = ai_usage_hourly.input_tokens + EXCLUDED.input_tokens,
+					output_tokens = ai_usage_hourly.output_tokens + EXCLUDED.output_tokens,
+					cost_usd = ai_usage_hourly.cost_usd + EXCLUDED.cost_usd
+			`, aggregate.Hour, aggregate.UserID, aggregate.Endpoint, aggregate.Provider, aggregate.Model,
+				aggregate.Calls, aggregate.InputTokens, aggregate.OutputTokens, aggregate.CostUSD); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *postgresUsageStore) UsageGroups(ctx context.Context, groupBy, userID string, from time.Time) ([]UsageGroup, error) {
+	column, ok := usageGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown grouping %q", ErrInvalidUsageQuery, groupBy)
+	}
+
+	query := `
+		SELECT ` + column + `, SUM(calls), SUM(input_tokens), SUM(output_tokens), SUM(cost_usd)
+		FROM ai_usage_hourly
+		WHERE hour >= $1 AND ($2 = '' OR user_id = $2)
+		GROUP BY ` + column + `
+		ORDER BY SUM(cost_usd) DESC
+		LIMIT 100
+	`
+	rows, err := s.db.QueryContext(ctx, query, from.Truncate(time.Hour), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]UsageGroup, 0)
+	for rows.Next() {
+		var group UsageGroup
+		if err := rows.Scan(&group.Key, &group.Calls, &group.InputTokens, &group.OutputTokens, &group.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan usage group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (s *postgresUsageStore) GetBudget(ctx context.Context, userID string) (float64, bool, error) {
+	var limit float64
+	err := s.db.QueryRowContext(ctx, `SELECT monthly_limit_usd FROM ai_usage_budgets WHERE user_id = $1`, userID).Scan(&limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return limit, true, nil
+}
+
+func (s *postgresUsageStore) SetBudget(ctx context.Context, userID string, monthlyLimitUSD float64) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO ai_usage_budgets (user_id, monthly_limit_usd, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET monthly_limit_usd = EXCLUDED.monthly_limit_usd, updated_at = NOW()
+	`, userID, monthlyLimitUSD)
+	return err
+}
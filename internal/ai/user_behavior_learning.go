@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/security"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 )
@@ -25,6 +26,7 @@ type UserBehaviorLearningEngine struct {
 	userProfiles         map[uuid.UUID]*UserBehaviorProfile
 	behaviorHistory      map[uuid.UUID][]*BehaviorEvent
 	learningModels       map[string]*LearningModel
+	auditor              *security.AuditManager // records profile imports and resets
 	mu                   sync.RWMutex
 	lastUpdate           time.Time
 }
@@ -60,6 +62,8 @@ type UserBehaviorProfile struct {
 	PerformanceMetrics *UserPerformanceProfile       `json:"performance_metrics"`
 	LearningProgress   *LearningProgress             `json:"learning_progress"`
 	Recommendations    []*PersonalizedRecommendation `json:"recommendations"`
+	Cohort             *CohortAssignment             `json:"cohort,omitempty"`
+	Personalization    *PersonalizationStatus        `json:"personalization,omitempty"`
 	Confidence         float64                       `json:"confidence"`
 	ObservationCount   int                           `json:"observation_count"`
 	Metadata           map[string]interface{}        `json:"metadata"`
@@ -361,6 +365,7 @@ type PersonalizedRecommendation struct {
 	CreatedAt       time.Time              `json:"created_at"`
 	ExpiresAt       *time.Time             `json:"expires_at,omitempty"`
 	Status          string                 `json:"status"` // pending, accepted, rejected, expired
+	Basis           string                 `json:"basis"`  // personal, cohort
 	Metadata        map[string]interface{} `json:"metadata"`
 }
 
@@ -669,6 +674,9 @@ func (u *UserBehaviorLearningEngine) LearnFromBehavior(ctx context.Context, even
 	// Update learning progress
 	u.updateLearningProgress(profile)
 
+	// Place the user in a cohort and switch to personal recommendations once confident
+	u.updateCohort(profile)
+
 	// Generate recommendations if needed
 	if profile.ObservationCount >= u.config.MinObservations {
 		if err := u.generateRecommendations(ctx, profile); err != nil {
@@ -701,22 +709,26 @@ func (u *UserBehaviorLearningEngine) GetUserProfile(ctx context.Context, userID
 	return profile, nil
 }
 
-// GetPersonalizedRecommendations retrieves personalized recommendations for a user
+// GetPersonalizedRecommendations retrieves personalized recommendations for a user. Until the
+// profile is confident enough, they are the recommendations of the user's cohort.
 func (u *UserBehaviorLearningEngine) GetPersonalizedRecommendations(ctx context.Context, userID uuid.UUID, limit int) ([]*PersonalizedRecommendation, error) {
-	u.mu.RLock()
-	defer u.mu.RUnlock()
+	u.mu.Lock()
+	defer u.mu.Unlock()
 
 	profile, exists := u.userProfiles[userID]
 	if !exists {
 		return nil, fmt.Errorf("user profile not found for user %s", userID)
 	}
+	if !isEstablished(profile) {
+		u.refreshCohortRecommendationsLocked(profile)
+	}
 
 	// Filter active recommendations
 	var activeRecommendations []*PersonalizedRecommendation
 	now := time.Now()
 
 	for _, rec := range profile.Recommendations {
-		if rec.Status == "pending" && (rec.ExpiresAt == nil || rec.ExpiresAt.After(now)) {
+		if rec.Status == "pending" && (rec.ExpiresAt == nil || rec.ExpiresAt.After(now)) && servesRecommendation(profile, rec) {
 			activeRecommendations = append(activeRecommendations, rec)
 		}
 	}
@@ -1053,7 +1065,13 @@ func NewRecommendationEngine(logger *observability.Logger) *RecommendationEngine
 
 func (re *RecommendationEngine) GenerateRecommendations(ctx context.Context, profile *UserBehaviorProfile) error {
 	// Simplified recommendation generation
-	if profile.Confidence > 0.6 && len(profile.Recommendations) < 5 {
+	personal := 0
+	for _, rec := range profile.Recommendations {
+		if rec.Basis != RecommendationBasisCohort {
+			personal++
+		}
+	}
+	if profile.Confidence > 0.6 && personal < 5 {
 		recommendation := &PersonalizedRecommendation{
 			ID:             uuid.New().String(),
 			Type:           "strategy",
@@ -1088,6 +1106,7 @@ func (re *RecommendationEngine) GenerateRecommendations(ctx context.Context, pro
 			},
 			CreatedAt: time.Now(),
 			Status:    "pending",
+			Basis:     RecommendationBasisPersonal,
 			Metadata:  make(map[string]interface{}),
 		}
 
@@ -1193,6 +1212,22 @@ func (u *UserBehaviorLearningEngine) GetBehaviorHistory(ctx context.Context, use
 	return history, nil
 }
 
+// DeleteUserData erases the user's behavior profile and history, for right-to-erasure
+// requests. It returns the number of records deleted.
+func (u *UserBehaviorLearningEngine) DeleteUserData(ctx context.Context, userID uuid.UUID) (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	deleted := len(u.behaviorHistory[userID])
+	if _, exists := u.userProfiles[userID]; exists {
+		deleted++
+	}
+	delete(u.userProfiles, userID)
+	delete(u.behaviorHistory, userID)
+
+	return deleted, nil
+}
+
 // GetLearningModels retrieves learning models
 func (u *UserBehaviorLearningEngine) GetLearningModels() map[string]*LearningModel {
 	u.mu.RLock()
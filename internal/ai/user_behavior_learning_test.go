@@ -547,6 +547,35 @@ func TestUserBehaviorLearningEngine(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("DeleteUserData", func(t *testing.T) {
+		ctx := context.Background()
+		userID := uuid.New()
+		for i := 0; i < 3; i++ {
+			err := engine.LearnFromBehavior(ctx, &BehaviorEvent{
+				ID:        uuid.New().String(),
+				UserID:    userID,
+				Type:      "trade",
+				Action:    "buy_eth",
+				Timestamp: time.Now(),
+			})
+			require.NoError(t, err)
+		}
+
+		deleted, err := engine.DeleteUserData(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, deleted, "three events and the profile")
+
+		_, err = engine.GetUserProfile(ctx, userID)
+		assert.Error(t, err)
+		history, err := engine.GetBehaviorHistory(ctx, userID, 0)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+
+		deleted, err = engine.DeleteUserData(ctx, userID)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+	})
+
 	t.Run("ConfigurationValidation", func(t *testing.T) {
 		// Test configuration values
 		config := engine.config
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -20,6 +21,8 @@ type VoiceInterface struct {
 	nlpProcessor   *NLPProcessor
 	commandHistory []VoiceCommand
 	config         VoiceConfig
+	ttsProvider    TTSProvider
+	speechCache    SpeechCache
 }
 
 // VoiceConfig holds configuration for voice interface
@@ -87,6 +90,15 @@ type VoiceResponse struct {
 	Confidence float64                `json:"confidence"`
 	Duration   time.Duration          `json:"duration"`
 	Metadata   map[string]interface{} `json:"metadata"`
+
+	// Synthesized speech, base64 encoded, when audio was requested
+	Audio         string `json:"audio,omitempty"`
+	AudioMimeType string `json:"audio_mime_type,omitempty"`
+}
+
+// VoiceCommandOptions controls how a voice command response is delivered
+type VoiceCommandOptions struct {
+	ResponseFormat ResponseFormat `json:"response_format"`
 }
 
 // SuggestedAction represents a suggested follow-up action
@@ -123,7 +135,36 @@ func NewVoiceInterface(
 	}
 }
 
-// ProcessVoiceCommand processes a voice command and returns a response
+// SetTTSProvider sets the speech provider used for audio responses
+func (v *VoiceInterface) SetTTSProvider(provider TTSProvider) {
+	v.ttsProvider = provider
+}
+
+// SetSpeechCache sets the cache for synthesized responses
+func (v *VoiceInterface) SetSpeechCache(cache SpeechCache) {
+	v.speechCache = cache
+}
+
+// ProcessVoiceCommandWithOptions processes a voice command and delivers the response in the
+// requested format. When speech synthesis fails the text response is returned instead.
+func (v *VoiceInterface) ProcessVoiceCommandWithOptions(ctx context.Context, userID uuid.UUID, audioData []byte, text string, options VoiceCommandOptions) (*VoiceResponse, error) {
+	format, err := ParseResponseFormat(string(options.ResponseFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := v.ProcessVoiceCommand(ctx, userID, audioData, text)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != ResponseFormatText {
+		v.attachSpeech(ctx, response, format)
+	}
+	return response, nil
+}
+
+// ProcessVoiceCommand processes a voice command and returns a text response
 func (v *VoiceInterface) ProcessVoiceCommand(ctx context.Context, userID uuid.UUID, audioData []byte, text string) (*VoiceResponse, error) {
 	startTime := time.Now()
 
@@ -389,6 +430,64 @@ func (v *VoiceInterface) handleFindYield(ctx context.Context, command VoiceComma
 	}, nil
 }
 
+// attachSpeech adds synthesized audio of the response text, reusing cached audio for
+// identical responses
+func (v *VoiceInterface) attachSpeech(ctx context.Context, response *VoiceResponse, format ResponseFormat) {
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	if v.ttsProvider == nil || response.Text == "" {
+		response.Metadata["audio_error"] = "speech synthesis unavailable"
+		return
+	}
+
+	key := speechCacheKey(v.ttsProvider.Name(), response.Text)
+	var speech *SynthesizedAudio
+	if v.speechCache != nil {
+		cached, found, err := v.speechCache.Get(ctx, key)
+		if err != nil {
+			v.logger.Warn(ctx, "Speech cache lookup failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else if found {
+			speech = cached
+			response.Metadata["audio_cached"] = true
+		}
+	}
+
+	if speech == nil {
+		synthesized, err := v.ttsProvider.Synthesize(ctx, response.Text)
+		if err != nil {
+			v.logger.Warn(ctx, "Speech synthesis failed", map[string]interface{}{
+				"provider": v.ttsProvider.Name(),
+				"error":    err.Error(),
+			})
+			response.Metadata["audio_error"] = "speech synthesis failed"
+			return
+		}
+		speech = synthesized
+
+		if v.speechCache != nil && len(speech.Data) > 0 {
+			if err := v.speechCache.Set(ctx, key, speech); err != nil {
+				v.logger.Warn(ctx, "Failed to cache synthesized speech", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+
+	if len(speech.Data) == 0 {
+		response.Metadata["audio_error"] = "speech synthesis unavailable"
+		return
+	}
+
+	response.Audio = base64.StdEncoding.EncodeToString(speech.Data)
+	response.AudioMimeType = speech.MimeType
+	if format == ResponseFormatAudio {
+		response.Text = ""
+	}
+}
+
 // addToHistory adds a command to the history
 func (v *VoiceInterface) addToHistory(command VoiceCommand) {
 	v.commandHistory = append(v.commandHistory, command)
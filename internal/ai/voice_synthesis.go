@@ -0,0 +1,200 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// ResponseFormat selects how a voice command response is delivered
+type ResponseFormat string
+
+const (
+	ResponseFormatText  ResponseFormat = "text"
+	ResponseFormatAudio ResponseFormat = "audio"
+	ResponseFormatBoth  ResponseFormat = "both"
+)
+
+// ErrInvalidResponseFormat is returned for response formats other than text, audio or both
+var ErrInvalidResponseFormat = errors.New("invalid response format")
+
+// ParseResponseFormat validates a requested response format, defaulting to text when empty
+func ParseResponseFormat(format string) (ResponseFormat, error) {
+	switch ResponseFormat(strings.ToLower(strings.TrimSpace(format))) {
+	case "", ResponseFormatText:
+		return ResponseFormatText, nil
+	case ResponseFormatAudio:
+		return ResponseFormatAudio, nil
+	case ResponseFormatBoth:
+		return ResponseFormatBoth, nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected text, audio or both", ErrInvalidResponseFormat, format)
+	}
+}
+
+// SynthesizedAudio is speech rendered from a response text
+type SynthesizedAudio struct {
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type"`
+}
+
+// TTSProvider converts response text to speech
+type TTSProvider interface {
+	// Name identifies the provider and its voice settings; it is part of the cache key
+	Name() string
+	// Synthesize renders text as speech. An empty result means no audio is available.
+	Synthesize(ctx context.Context, text string) (*SynthesizedAudio, error)
+}
+
+// NewTTSProvider returns the OpenAI speech provider when an API key is configured and a
+// no-op provider otherwise
+func NewTTSProvider(cfg config.AIConfig) TTSProvider {
+	if cfg.OpenAIKey == "" {
+		return NoopTTSProvider{}
+	}
+	return NewOpenAITTSProvider(cfg.OpenAIKey, cfg.TTSModel, cfg.TTSVoice)
+}
+
+// NoopTTSProvider never produces audio, leaving responses text only
+type NoopTTSProvider struct{}
+
+// Name identifies the provider
+func (NoopTTSProvider) Name() string { return "noop" }
+
+// Synthesize returns no audio
+func (NoopTTSProvider) Synthesize(ctx context.Context, text string) (*SynthesizedAudio, error) {
+	return &SynthesizedAudio{}, nil
+}
+
+// OpenAITTSProvider synthesizes speech with the OpenAI audio API
+type OpenAITTSProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+// NewOpenAITTSProvider creates an OpenAI speech provider. Empty model and voice default to
+// tts-1 and alloy.
+func NewOpenAITTSProvider(apiKey, model, voice string) *OpenAITTSProvider {
+	if model == "" {
+		model = "tts-1"
+	}
+	if voice == "" {
+		voice = "alloy"
+	}
+	return &OpenAITTSProvider{
+		baseURL: "https://api.openai.com/v1",
+		apiKey:  apiKey,
+		model:   model,
+		voice:   voice,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name identifies the provider, model and voice
+func (p *OpenAITTSProvider) Name() string {
+	return "openai:" + p.model + ":" + p.voice
+}
+
+// Synthesize renders text as MP3 speech
+func (p *OpenAITTSProvider) Synthesize(ctx context.Context, text string) (*SynthesizedAudio, error) {
+	payload, err := json.Marshal(map[string]string{
+		"model":           p.model,
+		"voice":           p.voice,
+		"input":           text,
+		"response_format": "mp3",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.baseURL, "/")+"/audio/speech", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from speech API", resp.StatusCode)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "audio/mpeg"
+	}
+	return &SynthesizedAudio{Data: body, MimeType: mimeType}, nil
+}
+
+// SpeechCache stores synthesized audio so identical responses are only synthesized once
+type SpeechCache interface {
+	Get(ctx context.Context, key string) (*SynthesizedAudio, bool, error)
+	Set(ctx context.Context, key string, audio *SynthesizedAudio) error
+}
+
+// redisSpeechCache implements SpeechCache with expiring Redis keys
+type redisSpeechCache struct {
+	redis *database.RedisClient
+	ttl   time.Duration
+}
+
+func NewRedisSpeechCache(redis *database.RedisClient, ttl time.Duration) SpeechCache {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &redisSpeechCache{redis: redis, ttl: ttl}
+}
+
+func (c *redisSpeechCache) Get(ctx context.Context, key string) (*SynthesizedAudio, bool, error) {
+	data, err := c.redis.Client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var audio SynthesizedAudio
+	if err := json.Unmarshal(data, &audio); err != nil {
+		return nil, false, err
+	}
+	return &audio, true, nil
+}
+
+func (c *redisSpeechCache) Set(ctx context.Context, key string, audio *SynthesizedAudio) error {
+	data, err := json.Marshal(audio)
+	if err != nil {
+		return err
+	}
+	return c.redis.SetWithExpiry(ctx, key, data, c.ttl)
+}
+
+// speechCacheKey identifies the audio of a text rendered by a provider
+func speechCacheKey(provider, text string) string {
+	hash := sha256.Sum256([]byte(provider + "\x00" + text))
+	return "voice:tts:" + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTTSProvider struct {
+	calls int
+	err   error
+}
+
+func (p *fakeTTSProvider) Name() string { return "fake" }
+
+func (p *fakeTTSProvider) Synthesize(ctx context.Context, text string) (*SynthesizedAudio, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &SynthesizedAudio{Data: []byte("audio:" + text), MimeType: "audio/mpeg"}, nil
+}
+
+type memorySpeechCache struct {
+	entries map[string]*SynthesizedAudio
+}
+
+func (c *memorySpeechCache) Get(ctx context.Context, key string) (*SynthesizedAudio, bool, error) {
+	audio, found := c.entries[key]
+	return audio, found, nil
+}
+
+func (c *memorySpeechCache) Set(ctx context.Context, key string, audio *SynthesizedAudio) error {
+	c.entries[key] = audio
+	return nil
+}
+
+func TestVoiceResponseFormats(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	newVoiceInterface := func(provider TTSProvider) *VoiceInterface {
+		voice := NewVoiceInterface(&observability.Logger{}, nil, nil, nil)
+		voice.SetTTSProvider(provider)
+		voice.SetSpeechCache(&memorySpeechCache{entries: make(map[string]*SynthesizedAudio)})
+		return voice
+	}
+
+	t.Run("TextOmitsAudio", func(t *testing.T) {
+		provider := &fakeTTSProvider{}
+		voice := newVoiceInterface(provider)
+
+		response, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.Text)
+		assert.Empty(t, response.Audio)
+		assert.Equal(t, 0, provider.calls)
+	})
+
+	t.Run("BothIncludesTextAndAudio", func(t *testing.T) {
+		provider := &fakeTTSProvider{}
+		voice := newVoiceInterface(provider)
+
+		response, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{ResponseFormat: ResponseFormatBoth})
+		require.NoError(t, err)
+		require.NotEmpty(t, response.Text)
+
+		audio, err := base64.StdEncoding.DecodeString(response.Audio)
+		require.NoError(t, err)
+		assert.Equal(t, "audio:"+response.Text, string(audio))
+		assert.Equal(t, "audio/mpeg", response.AudioMimeType)
+	})
+
+	t.Run("AudioIsCached", func(t *testing.T) {
+		provider := &fakeTTSProvider{}
+		voice := newVoiceInterface(provider)
+
+		first, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{ResponseFormat: ResponseFormatAudio})
+		require.NoError(t, err)
+		second, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{ResponseFormat: ResponseFormatAudio})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, provider.calls)
+		assert.Empty(t, first.Text)
+		assert.NotEmpty(t, first.Audio)
+		assert.Equal(t, first.Audio, second.Audio)
+		assert.Equal(t, true, second.Metadata["audio_cached"])
+	})
+
+	t.Run("SynthesisFailureFallsBackToText", func(t *testing.T) {
+		voice := newVoiceInterface(&fakeTTSProvider{err: errors.New("speech API down")})
+
+		response, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{ResponseFormat: ResponseFormatAudio})
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.Text)
+		assert.Empty(t, response.Audio)
+		assert.NotEmpty(t, response.Metadata["audio_error"])
+	})
+
+	t.Run("NoopProviderLeavesTextOnly", func(t *testing.T) {
+		voice := newVoiceInterface(NoopTTSProvider{})
+
+		response, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{ResponseFormat: ResponseFormatBoth})
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.Text)
+		assert.Empty(t, response.Audio)
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		voice := newVoiceInterface(&fakeTTSProvider{})
+
+		_, err := voice.ProcessVoiceCommandWithOptions(ctx, userID, nil, "help", VoiceCommandOptions{ResponseFormat: "video"})
+		assert.ErrorIs(t, err, ErrInvalidResponseFormat)
+	})
+}
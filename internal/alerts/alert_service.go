@@ -1,12 +1,27 @@
 package alerts
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -23,6 +38,13 @@ type AlertService struct {
 	mu          sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// lastDelivered holds the last dispatch time per rule and channel for cooldowns
+	lastDelivered map[string]time.Time
+
+	// preferences routes user-owned alerts; deferred holds those waiting out quiet hours
+	preferences NotificationPreferenceStore
+	deferred    []Alert
 }
 
 // AlertConfig holds configuration for the alert service
@@ -34,9 +56,22 @@ type AlertConfig struct {
 	EnableSlack     bool          `json:"enable_slack"`
 	EnableTelegram  bool          `json:"enable_telegram"`
 	EnablePushNotif bool          `json:"enable_push_notifications"`
+
+	Email    EmailConfig    `json:"email"`
+	Webhook  WebhookConfig  `json:"webhook"`
+	Slack    SlackConfig    `json:"slack"`
+	Telegram TelegramConfig `json:"telegram"`
+
+	// TopicChannels routes every alert published under a topic (all, severity_<level>,
+	// metric_<name> or rule_<id>) to extra channels on top of the alert's own.
+	TopicChannels       map[string][]string `json:"topic_channels"`
+	MaxDeliveryAttempts int                 `json:"max_delivery_attempts"`
+	RetryBaseDelay      time.Duration       `json:"retry_base_delay"`
+	DeliveryTimeout     time.Duration       `json:"delivery_timeout"`
 }
 
-// AlertChannel interface for different notification channels
+// AlertChannel interface for different notification channels. Send is retried by the
+// service, so implementations should make a single attempt and honour ctx.
 type AlertChannel interface {
 	Send(ctx context.Context, alert Alert) error
 	Name() string
@@ -98,6 +133,16 @@ type Alert struct {
 	Metadata    map[string]interface{} `json:"metadata"`
 	UserID      *uuid.UUID             `json:"user_id,omitempty"`
 	PortfolioID *uuid.UUID             `json:"portfolio_id,omitempty"`
+	Category    string                 `json:"category,omitempty"`
+	Deliveries  []DeliveryRecord       `json:"deliveries,omitempty"`
+
+	// Notification is how the owner's preferences handled the alert, with DeferredUntil set
+	// when quiet hours held it back
+	Notification  string     `json:"notification,omitempty"`
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+
+	// Cooldown overrides the rule cooldown between deliveries on the same channel
+	Cooldown time.Duration `json:"-"`
 }
 
 // EmailChannel implements email notifications
@@ -120,6 +165,7 @@ type EmailConfig struct {
 // WebhookChannel implements webhook notifications
 type WebhookChannel struct {
 	config WebhookConfig
+	client *http.Client
 	logger *observability.Logger
 }
 
@@ -128,6 +174,7 @@ type WebhookConfig struct {
 	URL     string            `json:"url"`
 	Method  string            `json:"method"`
 	Headers map[string]string `json:"headers"`
+	Secret  string            `json:"-"` // signs the payload with HMAC-SHA256 when set
 	Timeout time.Duration     `json:"timeout"`
 	Enabled bool              `json:"enabled"`
 }
@@ -135,6 +182,7 @@ type WebhookConfig struct {
 // SlackChannel implements Slack notifications
 type SlackChannel struct {
 	config SlackConfig
+	client *http.Client
 	logger *observability.Logger
 }
 
@@ -147,6 +195,62 @@ type SlackConfig struct {
 	Enabled    bool   `json:"enabled"`
 }
 
+// TelegramChannel implements Telegram bot notifications
+type TelegramChannel struct {
+	config TelegramConfig
+	client *http.Client
+	logger *observability.Logger
+}
+
+// TelegramConfig holds Telegram bot configuration
+type TelegramConfig struct {
+	BotToken string   `json:"-"`
+	ChatIDs  []string `json:"chat_ids"`
+	APIURL   string   `json:"api_url"`
+	Enabled  bool     `json:"enabled"`
+}
+
+// NewAlertConfig builds the alert service configuration from the environment settings,
+// enabling every channel type; channels without a destination are skipped at start
+func NewAlertConfig(cfg config.AlertsConfig) AlertConfig {
+	return AlertConfig{
+		MaxHistorySize:  1000,
+		DefaultCooldown: 5 * time.Minute,
+		EnableEmail:     true,
+		EnableWebhook:   true,
+		EnableSlack:     true,
+		EnableTelegram:  true,
+		EnablePushNotif: true,
+		Email: EmailConfig{
+			SMTPHost:    cfg.SMTPHost,
+			SMTPPort:    cfg.SMTPPort,
+			Username:    cfg.SMTPUsername,
+			Password:    cfg.SMTPPassword,
+			FromAddress: cfg.EmailFrom,
+			ToAddresses: cfg.EmailTo,
+		},
+		Webhook: WebhookConfig{
+			URL:     cfg.WebhookURL,
+			Secret:  cfg.WebhookSecret,
+			Timeout: cfg.DeliveryTimeout,
+		},
+		Slack: SlackConfig{
+			WebhookURL: cfg.SlackWebhookURL,
+			Channel:    cfg.SlackChannel,
+			Username:   "CryptoBrowser",
+			IconEmoji:  ":warning:",
+		},
+		Telegram: TelegramConfig{
+			BotToken: cfg.TelegramBotToken,
+			ChatIDs:  cfg.TelegramChatIDs,
+		},
+		TopicChannels:       cfg.TopicChannels,
+		MaxDeliveryAttempts: cfg.MaxDeliveryAttempts,
+		RetryBaseDelay:      cfg.RetryBaseDelay,
+		DeliveryTimeout:     cfg.DeliveryTimeout,
+	}
+}
+
 // NewAlertService creates a new alert service
 func NewAlertService(logger *observability.Logger, config AlertConfig) *AlertService {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -160,6 +264,8 @@ func NewAlertService(logger *observability.Logger, config AlertConfig) *AlertSer
 		history:     make([]Alert, 0),
 		ctx:         ctx,
 		cancel:      cancel,
+
+		lastDelivered: make(map[string]time.Time),
 	}
 }
 
@@ -176,6 +282,9 @@ func (a *AlertService) Start() error {
 	// Load default alert rules
 	a.loadDefaultRules()
 
+	// Deliver alerts deferred by quiet hours once they end
+	go a.releaseDeferred()
+
 	return nil
 }
 
@@ -203,9 +312,19 @@ func (a *AlertService) Stop() error {
 
 // SendAlert sends an alert through configured channels
 func (a *AlertService) SendAlert(alert Alert) error {
+	// Loaded before locking since the lookup may hit the database
+	prefs := a.ownerPreferences(alert)
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Send through configured channels, recording each delivery on the alert
+	notifyOwner := applyPreferences(&alert, prefs, time.Now())
+	a.dispatchLocked(&alert, prefs, notifyOwner, true)
+	if alert.Notification == NotificationDeferred {
+		a.deferLocked(alert)
+	}
+
 	// Add to history
 	a.history = append(a.history, alert)
 	if len(a.history) > a.config.MaxHistorySize {
@@ -215,20 +334,6 @@ func (a *AlertService) SendAlert(alert Alert) error {
 	// Send to subscribers
 	a.notifySubscribers(alert)
 
-	// Send through configured channels
-	for _, channelName := range alert.Channels {
-		if channel, exists := a.channels[channelName]; exists && channel.IsEnabled() {
-			go func(ch AlertChannel, al Alert) {
-				if err := ch.Send(a.ctx, al); err != nil {
-					a.logger.Error(a.ctx, "Failed to send alert", err, map[string]interface{}{
-						"alert_id": al.ID,
-						"channel":  ch.Name(),
-					})
-				}
-			}(channel, alert)
-		}
-	}
-
 	a.logger.Info(a.ctx, "Alert sent", map[string]interface{}{
 		"alert_id": alert.ID,
 		"severity": string(alert.Severity),
@@ -289,9 +394,11 @@ func (a *AlertService) GetAlerts(limit int) []Alert {
 		limit = len(a.history)
 	}
 
-	// Return most recent alerts
+	// Return most recent alerts; copied since deliveries keep updating the history
 	start := len(a.history) - limit
-	return a.history[start:]
+	alerts := make([]Alert, limit)
+	copy(alerts, a.history[start:])
+	return alerts
 }
 
 // GetActiveAlerts returns unresolved alerts
@@ -332,6 +439,14 @@ func (a *AlertService) ResolveAlert(alertID string) error {
 	return fmt.Errorf("alert not found: %s", alertID)
 }
 
+// RegisterChannel adds or replaces a notification channel
+func (a *AlertService) RegisterChannel(channel AlertChannel) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.channels[channel.Name()] = channel
+}
+
 // AddRule adds a new alert rule
 func (a *AlertService) AddRule(rule AlertRule) {
 	a.mu.Lock()
@@ -421,35 +536,10 @@ func (a *AlertService) evaluateCondition(condition AlertCondition, value, thresh
 	}
 }
 
-// notifySubscribers notifies all subscribers of an alert
+// notifySubscribers notifies the subscribers of every topic the alert is published under
 func (a *AlertService) notifySubscribers(alert Alert) {
-	// Notify general subscribers
-	if subscribers, exists := a.subscribers["all"]; exists {
-		for _, ch := range subscribers {
-			select {
-			case ch <- alert:
-			default:
-				// Channel is full, skip
-			}
-		}
-	}
-
-	// Notify severity-specific subscribers
-	severityTopic := fmt.Sprintf("severity_%s", string(alert.Severity))
-	if subscribers, exists := a.subscribers[severityTopic]; exists {
-		for _, ch := range subscribers {
-			select {
-			case ch <- alert:
-			default:
-				// Channel is full, skip
-			}
-		}
-	}
-
-	// Notify metric-specific subscribers
-	metricTopic := fmt.Sprintf("metric_%s", alert.Metric)
-	if subscribers, exists := a.subscribers[metricTopic]; exists {
-		for _, ch := range subscribers {
+	for _, topic := range alertTopics(alert) {
+		for _, ch := range a.subscribers[topic] {
 			select {
 			case ch <- alert:
 			default:
@@ -459,42 +549,54 @@ func (a *AlertService) notifySubscribers(alert Alert) {
 	}
 }
 
-// initializeChannels initializes alert channels
+// initializeChannels initializes the enabled alert channels that have a destination configured
 func (a *AlertService) initializeChannels() {
-	// Initialize email channel
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.config.EnableEmail {
-		emailConfig := EmailConfig{
-			SMTPHost:    "smtp.gmail.com",
-			SMTPPort:    587,
-			FromAddress: "alerts@crypto-browser.com",
-			Enabled:     true,
+		if emailConfig := a.config.Email; emailConfig.SMTPHost != "" && len(emailConfig.ToAddresses) > 0 {
+			emailConfig.Enabled = true
+			a.channels["email"] = NewEmailChannel(emailConfig, a.logger)
+		} else {
+			a.logger.Warn(a.ctx, "Email alerts enabled but SMTP host or recipients are not configured")
 		}
-		a.channels["email"] = NewEmailChannel(emailConfig, a.logger)
 	}
 
-	// Initialize webhook channel
 	if a.config.EnableWebhook {
-		webhookConfig := WebhookConfig{
-			URL:     "https://api.example.com/webhooks/alerts",
-			Method:  "POST",
-			Headers: map[string]string{"Content-Type": "application/json"},
-			Timeout: 10 * time.Second,
-			Enabled: true,
+		if webhookConfig := a.config.Webhook; webhookConfig.URL != "" {
+			webhookConfig.Enabled = true
+			a.channels["webhook"] = NewWebhookChannel(webhookConfig, a.logger)
+		} else {
+			a.logger.Warn(a.ctx, "Webhook alerts enabled but no webhook URL is configured")
 		}
-		a.channels["webhook"] = NewWebhookChannel(webhookConfig, a.logger)
 	}
 
-	// Initialize Slack channel
 	if a.config.EnableSlack {
-		slackConfig := SlackConfig{
-			WebhookURL: "https://hooks.slack.com/services/YOUR/SLACK/WEBHOOK",
-			Channel:    "#alerts",
-			Username:   "CryptoBrowser",
-			IconEmoji:  ":warning:",
-			Enabled:    true,
+		if slackConfig := a.config.Slack; slackConfig.WebhookURL != "" {
+			slackConfig.Enabled = true
+			a.channels["slack"] = NewSlackChannel(slackConfig, a.logger)
+		} else {
+			a.logger.Warn(a.ctx, "Slack alerts enabled but no incoming webhook URL is configured")
+		}
+	}
+
+	if a.config.EnableTelegram {
+		if telegramConfig := a.config.Telegram; telegramConfig.BotToken != "" && len(telegramConfig.ChatIDs) > 0 {
+			telegramConfig.Enabled = true
+			a.channels["telegram"] = NewTelegramChannel(telegramConfig, a.logger)
+		} else {
+			a.logger.Warn(a.ctx, "Telegram alerts enabled but bot token or chat IDs are not configured")
 		}
-		a.channels["slack"] = NewSlackChannel(slackConfig, a.logger)
 	}
+
+	names := make([]string, 0, len(a.channels))
+	for name := range a.channels {
+		names = append(names, name)
+	}
+	a.logger.Info(a.ctx, "Alert channels initialized", map[string]interface{}{
+		"channels": names,
+	})
 }
 
 // loadDefaultRules loads default alert rules
@@ -566,13 +668,68 @@ func NewEmailChannel(config EmailConfig, logger *observability.Logger) *EmailCha
 }
 
 func (e *EmailChannel) Send(ctx context.Context, alert Alert) error {
-	// Simplified email sending (would use actual SMTP in production)
-	e.logger.Info(ctx, "Email alert sent", map[string]interface{}{
-		"alert_id": alert.ID,
-		"title":    alert.Title,
-		"to":       e.config.ToAddresses,
-	})
-	return nil
+	addr := net.JoinHostPort(e.config.SMTPHost, strconv.Itoa(e.config.SMTPPort))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.config.SMTPHost}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if e.config.Username != "" {
+		auth := smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return smtpError("authentication failed", err)
+		}
+	}
+
+	if err := client.Mail(e.config.FromAddress); err != nil {
+		return smtpError("sender rejected", err)
+	}
+	for _, to := range e.config.ToAddresses {
+		if err := client.Rcpt(to); err != nil {
+			return smtpError("recipient rejected", err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return smtpError("failed to start message", err)
+	}
+	if _, err := writer.Write(e.buildMessage(alert)); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return smtpError("message rejected", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders the alert as a plain text email
+func (e *EmailChannel) buildMessage(alert Alert) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.config.FromAddress)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.config.ToAddresses, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", fmt.Sprintf("[%s] %s", strings.ToUpper(string(alert.Severity)), alert.Title)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.Timestamp.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(formatAlertText(alert), "\n", "\r\n"))
+	return msg.Bytes()
 }
 
 func (e *EmailChannel) Name() string {
@@ -585,24 +742,35 @@ func (e *EmailChannel) IsEnabled() bool {
 
 // NewWebhookChannel creates a new webhook channel
 func NewWebhookChannel(config WebhookConfig, logger *observability.Logger) *WebhookChannel {
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
 	return &WebhookChannel{
 		config: config,
+		client: &http.Client{Timeout: config.Timeout},
 		logger: logger,
 	}
 }
 
+// Send posts the alert as JSON. With a secret configured the request carries
+// X-Alert-Timestamp and X-Alert-Signature, the hex HMAC-SHA256 of "<timestamp>.<body>".
 func (w *WebhookChannel) Send(ctx context.Context, alert Alert) error {
-	// Simplified webhook sending
-	payload, _ := json.Marshal(alert)
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return &permanentError{err: fmt.Errorf("failed to encode alert: %w", err)}
+	}
 
-	w.logger.Info(ctx, "Webhook alert sent", map[string]interface{}{
-		"alert_id": alert.ID,
-		"url":      w.config.URL,
-		"payload":  string(payload),
-	})
+	headers := make(map[string]string, len(w.config.Headers)+2)
+	for key, value := range w.config.Headers {
+		headers[key] = value
+	}
+	if w.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers["X-Alert-Timestamp"] = timestamp
+		headers["X-Alert-Signature"] = "sha256=" + signPayload(w.config.Secret, timestamp, payload)
+	}
 
-	// In production, would make actual HTTP request
-	return nil
+	return sendJSON(ctx, w.client, w.config.Method, w.config.URL, headers, payload)
 }
 
 func (w *WebhookChannel) Name() string {
@@ -617,12 +785,12 @@ func (w *WebhookChannel) IsEnabled() bool {
 func NewSlackChannel(config SlackConfig, logger *observability.Logger) *SlackChannel {
 	return &SlackChannel{
 		config: config,
+		client: &http.Client{},
 		logger: logger,
 	}
 }
 
 func (s *SlackChannel) Send(ctx context.Context, alert Alert) error {
-	// Simplified Slack message formatting
 	color := "warning"
 	switch alert.Severity {
 	case SeverityCritical:
@@ -655,16 +823,12 @@ func (s *SlackChannel) Send(ctx context.Context, alert Alert) error {
 		},
 	}
 
-	payload, _ := json.Marshal(message)
-
-	s.logger.Info(ctx, "Slack alert sent", map[string]interface{}{
-		"alert_id": alert.ID,
-		"channel":  s.config.Channel,
-		"payload":  string(payload),
-	})
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return &permanentError{err: fmt.Errorf("failed to encode Slack message: %w", err)}
+	}
 
-	// In production, would make actual HTTP request to Slack webhook
-	return nil
+	return sendJSON(ctx, s.client, http.MethodPost, s.config.WebhookURL, nil, payload)
 }
 
 func (s *SlackChannel) Name() string {
@@ -674,3 +838,109 @@ func (s *SlackChannel) Name() string {
 func (s *SlackChannel) IsEnabled() bool {
 	return s.config.Enabled
 }
+
+// NewTelegramChannel creates a new Telegram channel
+func NewTelegramChannel(config TelegramConfig, logger *observability.Logger) *TelegramChannel {
+	if config.APIURL == "" {
+		config.APIURL = "https://api.telegram.org"
+	}
+	return &TelegramChannel{
+		config: config,
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// Send posts the alert to every configured chat through the Bot API sendMessage method
+func (t *TelegramChannel) Send(ctx context.Context, alert Alert) error {
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(t.config.APIURL, "/"), t.config.BotToken)
+	text := formatAlertText(alert)
+
+	for _, chatID := range t.config.ChatIDs {
+		payload, err := json.Marshal(map[string]interface{}{
+			"chat_id":                  chatID,
+			"text":                     text,
+			"disable_web_page_preview": true,
+		})
+		if err != nil {
+			return &permanentError{err: fmt.Errorf("failed to encode Telegram message: %w", err)}
+		}
+		if err := sendJSON(ctx, t.client, http.MethodPost, endpoint, nil, payload); err != nil {
+			return fmt.Errorf("chat %s: %w", chatID, err)
+		}
+	}
+	return nil
+}
+
+func (t *TelegramChannel) Name() string {
+	return "telegram"
+}
+
+func (t *TelegramChannel) IsEnabled() bool {
+	return t.config.Enabled
+}
+
+// sendJSON sends a JSON payload and fails on any non-2xx response. Client errors other
+// than timeouts and rate limits are permanent, since resending the same payload won't help.
+func sendJSON(ctx context.Context, client *http.Client, method, url string, headers map[string]string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return &permanentError{err: fmt.Errorf("invalid request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err: err}
+	}
+	return err
+}
+
+// smtpError wraps an SMTP failure, treating 5xx replies as permanent
+func smtpError(message string, err error) error {
+	err = fmt.Errorf("%s: %w", message, err)
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return &permanentError{err: err}
+	}
+	return err
+}
+
+// signPayload returns the hex HMAC-SHA256 of "<timestamp>.<payload>"
+func signPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// formatAlertText renders an alert as plain text for email and chat channels
+func formatAlertText(alert Alert) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[%s] %s\n", strings.ToUpper(string(alert.Severity)), alert.Title)
+	if alert.Message != "" {
+		fmt.Fprintf(&text, "%s\n", alert.Message)
+	}
+	if alert.Metric != "" {
+		fmt.Fprintf(&text, "\nMetric: %s\nValue: %s (threshold %s)\n", alert.Metric, alert.Value.String(), alert.Threshold.String())
+	}
+	fmt.Fprintf(&text, "Time: %s\nAlert ID: %s\n", alert.Timestamp.UTC().Format(time.RFC3339), alert.ID)
+	return text.String()
+}
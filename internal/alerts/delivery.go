@@ -0,0 +1,250 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultMaxDeliveryAttempts = 3
+	defaultRetryBaseDelay      = 2 * time.Second
+	defaultDeliveryTimeout     = 10 * time.Second
+	maxRetryDelay              = time.Minute
+)
+
+// DeliveryStatus is the outcome of delivering an alert through one channel
+type DeliveryStatus string
+
+const (
+	DeliveryPending    DeliveryStatus = "pending"
+	DeliveryDelivered  DeliveryStatus = "delivered"
+	DeliveryFailed     DeliveryStatus = "failed"
+	DeliverySuppressed DeliveryStatus = "suppressed" // channel still in cooldown for this rule
+)
+
+// DeliveryRecord tracks the delivery of an alert through a single channel
+type DeliveryRecord struct {
+	Channel       string         `json:"channel"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	LatencyMs     int64          `json:"latency_ms"`
+	Error         string         `json:"error,omitempty"`
+	LastAttemptAt *time.Time     `json:"last_attempt_at,omitempty"`
+	DeliveredAt   *time.Time     `json:"delivered_at,omitempty"`
+}
+
+// permanentError marks a delivery failure that retrying will not fix, such as a rejected payload
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// dispatchLocked resolves the channels for an alert, skips those still in cooldown and
+// starts delivery on the rest. The alert's own channels are used when notifyOwner is set and
+// those its topics are routed to when notifyTopics is set. Callers must hold a.mu.
+func (a *AlertService) dispatchLocked(alert *Alert, prefs *NotificationPreferences, notifyOwner, notifyTopics bool) {
+	now := time.Now()
+	cooldown := a.cooldownLocked(*alert)
+
+	for _, channel := range a.resolveChannelsLocked(*alert, prefs, notifyOwner, notifyTopics) {
+		if !channel.IsEnabled() {
+			continue
+		}
+		name := channel.Name()
+
+		key := deliveryKey(*alert, name)
+		if last, ok := a.lastDelivered[key]; ok && now.Sub(last) < cooldown {
+			alert.Deliveries = append(alert.Deliveries, DeliveryRecord{Channel: name, Status: DeliverySuppressed})
+			continue
+		}
+		a.lastDelivered[key] = now
+
+		alert.Deliveries = append(alert.Deliveries, DeliveryRecord{Channel: name, Status: DeliveryPending})
+		payload := *alert
+		payload.Deliveries = nil
+		go a.deliver(channel, payload, key, now)
+	}
+}
+
+// deliver sends an alert through a channel, retrying with exponential backoff
+func (a *AlertService) deliver(channel AlertChannel, alert Alert, key string, dispatchedAt time.Time) {
+	maxAttempts := a.config.MaxDeliveryAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxDeliveryAttempts
+	}
+	timeout := a.config.DeliveryTimeout
+	if timeout <= 0 {
+		timeout = defaultDeliveryTimeout
+	}
+
+	var err error
+retry:
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(a.ctx, timeout)
+		err = channel.Send(ctx, alert)
+		cancel()
+
+		now := time.Now()
+		a.updateDelivery(alert.ID, channel.Name(), func(record *DeliveryRecord) {
+			record.Attempts = attempt
+			record.LastAttemptAt = &now
+			record.LatencyMs = now.Sub(dispatchedAt).Milliseconds()
+			if err == nil {
+				record.Status = DeliveryDelivered
+				record.DeliveredAt = &now
+				record.Error = ""
+			} else {
+				record.Error = err.Error()
+			}
+		})
+		if err == nil {
+			a.logger.Info(a.ctx, "Alert delivered", map[string]interface{}{
+				"alert_id": alert.ID,
+				"channel":  channel.Name(),
+				"attempts": attempt,
+			})
+			return
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-a.ctx.Done():
+			break retry
+		case <-time.After(a.retryDelay(attempt)):
+		}
+	}
+
+	a.mu.Lock()
+	// Let the next alert for this rule through instead of holding the cooldown for a delivery that never happened
+	if last, ok := a.lastDelivered[key]; ok && last.Equal(dispatchedAt) {
+		delete(a.lastDelivered, key)
+	}
+	a.mu.Unlock()
+
+	a.updateDelivery(alert.ID, channel.Name(), func(record *DeliveryRecord) {
+		record.Status = DeliveryFailed
+	})
+	a.logger.Error(a.ctx, "Failed to deliver alert", err, map[string]interface{}{
+		"alert_id": alert.ID,
+		"channel":  channel.Name(),
+	})
+}
+
+// updateDelivery applies an update to the delivery record of an alert in the history.
+// The records are copied so alerts already handed to subscribers are never mutated.
+func (a *AlertService) updateDelivery(alertID, channel string, update func(*DeliveryRecord)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i := len(a.history) - 1; i >= 0; i-- {
+		if a.history[i].ID != alertID {
+			continue
+		}
+		deliveries := append([]DeliveryRecord(nil), a.history[i].Deliveries...)
+		for j := range deliveries {
+			if deliveries[j].Channel == channel {
+				update(&deliveries[j])
+			}
+		}
+		a.history[i].Deliveries = deliveries
+		return
+	}
+}
+
+// resolveChannelsLocked merges the alert's own channels with those mapped to its topics.
+// With owner preferences the own channels default to every destination the owner set, and
+// each goes to the owner's destination where there is one instead of the service-wide channel.
+func (a *AlertService) resolveChannelsLocked(alert Alert, prefs *NotificationPreferences, notifyOwner, notifyTopics bool) []AlertChannel {
+	seen := make(map[string]bool)
+	channels := make([]AlertChannel, 0, len(alert.Channels))
+	add := func(channel AlertChannel) {
+		if channel != nil && !seen[channel.Name()] {
+			seen[channel.Name()] = true
+			channels = append(channels, channel)
+		}
+	}
+
+	if notifyOwner {
+		names := alert.Channels
+		if prefs != nil && len(names) == 0 {
+			names = prefs.channelNames()
+		}
+		for _, name := range names {
+			if prefs != nil {
+				if channel := a.ownerChannelLocked(prefs, name); channel != nil {
+					add(channel)
+					continue
+				}
+			}
+			add(a.channels[name])
+		}
+	}
+	if notifyTopics {
+		for _, topic := range alertTopics(alert) {
+			for _, name := range a.config.TopicChannels[topic] {
+				add(a.channels[name])
+			}
+		}
+	}
+	return channels
+}
+
+// cooldownLocked returns the per-channel cooldown for an alert, preferring its own over its rule's
+func (a *AlertService) cooldownLocked(alert Alert) time.Duration {
+	if alert.Cooldown > 0 {
+		return alert.Cooldown
+	}
+	for _, rule := range a.rules {
+		if rule.ID == alert.RuleID && rule.Cooldown > 0 {
+			return rule.Cooldown
+		}
+	}
+	return a.config.DefaultCooldown
+}
+
+func (a *AlertService) retryDelay(attempt int) time.Duration {
+	delay := a.config.RetryBaseDelay
+	if delay <= 0 {
+		delay = defaultRetryBaseDelay
+	}
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// alertTopics lists the topics an alert is published under
+func alertTopics(alert Alert) []string {
+	topics := []string{
+		"all",
+		fmt.Sprintf("severity_%s", string(alert.Severity)),
+		fmt.Sprintf("metric_%s", alert.Metric),
+	}
+	if alert.RuleID != "" {
+		topics = append(topics, fmt.Sprintf("rule_%s", alert.RuleID))
+	}
+	if alert.UserID != nil {
+		topics = append(topics, fmt.Sprintf("user_%s", alert.UserID.String()))
+	}
+	return topics
+}
+
+// deliveryKey identifies the cooldown slot of an alert on a channel
+func deliveryKey(alert Alert, channel string) string {
+	source := alert.RuleID
+	if source == "" {
+		source = alert.Metric + ":" + alert.Title
+	}
+	return source + "|" + channel
+}
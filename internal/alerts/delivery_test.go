@@ -0,0 +1,426 @@
+package alerts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturedRequest is a request received by a fake channel endpoint
+type capturedRequest struct {
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+// channelEndpoint is a fake Slack, Telegram or webhook endpoint answering with the queued
+// statuses, then 200
+type channelEndpoint struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []capturedRequest
+}
+
+func newChannelEndpoint(t *testing.T, statuses ...int) *channelEndpoint {
+	e := &channelEndpoint{statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		e.mu.Lock()
+		e.requests = append(e.requests, capturedRequest{path: r.URL.Path, headers: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(e.statuses) > 0 {
+			status, e.statuses = e.statuses[0], e.statuses[1:]
+		}
+		e.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *channelEndpoint) received() []capturedRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]capturedRequest(nil), e.requests...)
+}
+
+func testAlert() Alert {
+	return Alert{
+		ID:        "alert-1",
+		RuleID:    "high_error_rate",
+		Title:     "High Error Rate",
+		Message:   "Error rate is 7%",
+		Severity:  SeverityCritical,
+		Metric:    "error_rate_percent",
+		Value:     decimal.NewFromInt(7),
+		Threshold: decimal.NewFromInt(5),
+		Timestamp: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+func testLogger() *observability.Logger {
+	return observability.NewLogger(config.ObservabilityConfig{})
+}
+
+func TestWebhookChannel_SignedPayload(t *testing.T) {
+	endpoint := newChannelEndpoint(t)
+	channel := NewWebhookChannel(WebhookConfig{
+		URL:     endpoint.URL + "/hooks/alerts",
+		Headers: map[string]string{"X-Source": "crypto-browser"},
+		Secret:  "webhook-secret",
+		Enabled: true,
+	}, testLogger())
+
+	require.NoError(t, channel.Send(context.Background(), testAlert()))
+
+	requests := endpoint.received()
+	require.Len(t, requests, 1)
+	req := requests[0]
+	assert.Equal(t, "/hooks/alerts", req.path)
+	assert.Equal(t, "application/json", req.headers.Get("Content-Type"))
+	assert.Equal(t, "crypto-browser", req.headers.Get("X-Source"))
+
+	var payload Alert
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "alert-1", payload.ID)
+	assert.Equal(t, SeverityCritical, payload.Severity)
+	assert.True(t, payload.Value.Equal(decimal.NewFromInt(7)))
+
+	timestamp := req.headers.Get("X-Alert-Timestamp")
+	_, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256="+signPayload("webhook-secret", timestamp, req.body), req.headers.Get("X-Alert-Signature"))
+}
+
+func TestSlackChannel_Payload(t *testing.T) {
+	endpoint := newChannelEndpoint(t)
+	channel := NewSlackChannel(SlackConfig{
+		WebhookURL: endpoint.URL,
+		Channel:    "#alerts",
+		Username:   "CryptoBrowser",
+		IconEmoji:  ":warning:",
+		Enabled:    true,
+	}, testLogger())
+
+	require.NoError(t, channel.Send(context.Background(), testAlert()))
+
+	requests := endpoint.received()
+	require.Len(t, requests, 1)
+	assert.JSONEq(t, `{
+		"channel": "#alerts",
+		"username": "CryptoBrowser",
+		"icon_emoji": ":warning:",
+		"attachments": [{
+			"color": "danger",
+			"title": "High Error Rate",
+			"text": "Error rate is 7%",
+			"fields": [
+				{"title": "Severity", "value": "critical", "short": true},
+				{"title": "Metric", "value": "error_rate_percent", "short": true},
+				{"title": "Value", "value": "7", "short": true},
+				{"title": "Threshold", "value": "5", "short": true}
+			],
+			"ts": 1736937000
+		}]
+	}`, string(requests[0].body))
+}
+
+func TestTelegramChannel_SendsToEveryChat(t *testing.T) {
+	endpoint := newChannelEndpoint(t)
+	channel := NewTelegramChannel(TelegramConfig{
+		BotToken: "123:token",
+		ChatIDs:  []string{"1001", "1002"},
+		APIURL:   endpoint.URL + "/",
+		Enabled:  true,
+	}, testLogger())
+
+	require.NoError(t, channel.Send(context.Background(), testAlert()))
+
+	requests := endpoint.received()
+	require.Len(t, requests, 2)
+	for i, chatID := range []string{"1001", "1002"} {
+		assert.Equal(t, "/bot123:token/sendMessage", requests[i].path)
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(requests[i].body, &payload))
+		assert.Equal(t, chatID, payload["chat_id"])
+		assert.Equal(t, true, payload["disable_web_page_preview"])
+		assert.Contains(t, payload["text"], "[CRITICAL] High Error Rate")
+		assert.Contains(t, payload["text"], "Value: 7 (threshold 5)")
+	}
+}
+
+func TestSendJSON_ClassifiesFailures(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusForbidden, true},
+		{http.StatusRequestTimeout, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, false},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			endpoint := newChannelEndpoint(t, tt.status)
+			err := sendJSON(context.Background(), http.DefaultClient, http.MethodPost, endpoint.URL, nil, []byte(`{}`))
+			require.Error(t, err)
+			_, permanent := err.(*permanentError)
+			assert.Equal(t, tt.permanent, permanent)
+		})
+	}
+}
+
+// smtpServer is a fake SMTP server recording the envelope and message of every delivery.
+// Recipients listed in reject are refused with a permanent 550 reply.
+type smtpServer struct {
+	listener net.Listener
+	reject   map[string]bool
+	mu       sync.Mutex
+	from     string
+	to       []string
+	message  string
+}
+
+func newSMTPServer(t *testing.T, reject ...string) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &smtpServer{listener: listener, reject: make(map[string]bool)}
+	for _, address := range reject {
+		s.reject[address] = true
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); {
+		case verb == "EHLO" || verb == "HELO":
+			reply("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(command), "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(command[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(strings.ToUpper(command), "RCPT TO:"):
+			to := strings.Trim(command[len("RCPT TO:"):], "<> ")
+			if s.reject[to] {
+				reply("550 No such user")
+				continue
+			}
+			s.mu.Lock()
+			s.to = append(s.to, to)
+			s.mu.Unlock()
+			reply("250 OK")
+		case verb == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var message strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				message.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.message = message.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case verb == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) channel(to ...string) *EmailChannel {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return NewEmailChannel(EmailConfig{
+		SMTPHost:    host,
+		SMTPPort:    portNumber,
+		FromAddress: "alerts@example.com",
+		ToAddresses: to,
+		Enabled:     true,
+	}, testLogger())
+}
+
+func TestEmailChannel_SendsMessage(t *testing.T) {
+	server := newSMTPServer(t)
+
+	require.NoError(t, server.channel("ops@example.com", "oncall@example.com").Send(context.Background(), testAlert()))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "alerts@example.com", server.from)
+	assert.Equal(t, []string{"ops@example.com", "oncall@example.com"}, server.to)
+	assert.Contains(t, server.message, "From: alerts@example.com\r\n")
+	assert.Contains(t, server.message, "To: ops@example.com, oncall@example.com\r\n")
+	assert.Contains(t, server.message, "Subject: [CRITICAL] High Error Rate\r\n")
+	assert.Contains(t, server.message, "Content-Type: text/plain; charset=UTF-8\r\n")
+	assert.Contains(t, server.message, "Error rate is 7%\r\n")
+	assert.Contains(t, server.message, "Alert ID: alert-1\r\n")
+}
+
+func TestEmailChannel_RejectedRecipientIsPermanent(t *testing.T) {
+	server := newSMTPServer(t, "gone@example.com")
+
+	err := server.channel("gone@example.com").Send(context.Background(), testAlert())
+	require.Error(t, err)
+	_, permanent := err.(*permanentError)
+	assert.True(t, permanent, "a 5xx reply won't change on retry")
+}
+
+// newDeliveryService returns an alert service delivering through the given channels with
+// fast retries
+func newDeliveryService(t *testing.T, channels ...AlertChannel) *AlertService {
+	service := NewAlertService(testLogger(), AlertConfig{
+		MaxHistorySize:      100,
+		DefaultCooldown:     time.Hour,
+		MaxDeliveryAttempts: 3,
+		RetryBaseDelay:      10 * time.Millisecond,
+		DeliveryTimeout:     time.Second,
+	})
+	t.Cleanup(func() { service.Stop() })
+	for _, channel := range channels {
+		service.RegisterChannel(channel)
+	}
+	return service
+}
+
+// settledDeliveries waits until no delivery of the latest alert is pending and returns them
+// by channel
+func settledDeliveries(t *testing.T, service *AlertService) map[string]DeliveryRecord {
+	t.Helper()
+	var deliveries map[string]DeliveryRecord
+	require.Eventually(t, func() bool {
+		alerts := service.GetAlerts(1)
+		if len(alerts) == 0 {
+			return false
+		}
+		deliveries = make(map[string]DeliveryRecord)
+		for _, record := range alerts[0].Deliveries {
+			if record.Status == DeliveryPending {
+				return false
+			}
+			deliveries[record.Channel] = record
+		}
+		return true
+	}, 2*time.Second, 5*time.Millisecond)
+	return deliveries
+}
+
+func TestDelivery_RetriesTransientFailures(t *testing.T) {
+	endpoint := newChannelEndpoint(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	service := newDeliveryService(t, NewWebhookChannel(WebhookConfig{URL: endpoint.URL, Enabled: true}, testLogger()))
+
+	alert := testAlert()
+	alert.Channels = []string{"webhook"}
+	start := time.Now()
+	require.NoError(t, service.SendAlert(alert))
+
+	record := settledDeliveries(t, service)["webhook"]
+	assert.Equal(t, DeliveryDelivered, record.Status)
+	assert.Equal(t, 3, record.Attempts)
+	assert.Empty(t, record.Error)
+	assert.NotNil(t, record.DeliveredAt)
+	assert.Len(t, endpoint.received(), 3)
+	// Backoff waits 10ms, then 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestDelivery_GivesUpOnPermanentFailures(t *testing.T) {
+	endpoint := newChannelEndpoint(t, http.StatusBadRequest)
+	service := newDeliveryService(t, NewWebhookChannel(WebhookConfig{URL: endpoint.URL, Enabled: true}, testLogger()))
+
+	alert := testAlert()
+	alert.Channels = []string{"webhook"}
+	require.NoError(t, service.SendAlert(alert))
+
+	record := settledDeliveries(t, service)["webhook"]
+	assert.Equal(t, DeliveryFailed, record.Status)
+	assert.Equal(t, 1, record.Attempts)
+	assert.Contains(t, record.Error, "unexpected status 400")
+	assert.Len(t, endpoint.received(), 1)
+}
+
+func TestDelivery_IsolatesChannelFailures(t *testing.T) {
+	failing := newChannelEndpoint(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	healthy := newChannelEndpoint(t)
+	service := newDeliveryService(t,
+		NewSlackChannel(SlackConfig{WebhookURL: failing.URL, Enabled: true}, testLogger()),
+		NewWebhookChannel(WebhookConfig{URL: healthy.URL, Enabled: true}, testLogger()),
+	)
+
+	alert := testAlert()
+	alert.Channels = []string{"slack", "webhook"}
+	require.NoError(t, service.SendAlert(alert))
+
+	deliveries := settledDeliveries(t, service)
+	assert.Equal(t, DeliveryFailed, deliveries["slack"].Status)
+	assert.Equal(t, 3, deliveries["slack"].Attempts)
+	assert.Equal(t, DeliveryDelivered, deliveries["webhook"].Status)
+	assert.Equal(t, 1, deliveries["webhook"].Attempts)
+
+	// The failed channel gives up its cooldown so the next alert tries it again, while the
+	// delivered channel stays in cooldown
+	alert.ID = "alert-2"
+	require.NoError(t, service.SendAlert(alert))
+	deliveries = settledDeliveries(t, service)
+	assert.Equal(t, DeliveryDelivered, deliveries["slack"].Status)
+	assert.Equal(t, DeliverySuppressed, deliveries["webhook"].Status)
+	assert.Len(t, healthy.received(), 1)
+}
+
+func TestDelivery_RetryDelayBacksOffExponentially(t *testing.T) {
+	service := NewAlertService(testLogger(), AlertConfig{RetryBaseDelay: time.Second})
+	assert.Equal(t, time.Second, service.retryDelay(1))
+	assert.Equal(t, 2*time.Second, service.retryDelay(2))
+	assert.Equal(t, 4*time.Second, service.retryDelay(3))
+	assert.Equal(t, maxRetryDelay, service.retryDelay(10))
+
+	assert.Equal(t, defaultRetryBaseDelay, NewAlertService(testLogger(), AlertConfig{}).retryDelay(1))
+}
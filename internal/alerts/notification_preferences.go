@@ -0,0 +1,464 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification categories users can switch off. Alerts without a category are system alerts.
+const (
+	CategoryTradingFills = "trading_fills"
+	CategoryRiskAlerts   = "risk_alerts"
+	CategoryPriceAlerts  = "price_alerts"
+	CategorySystemAlerts = "system_alerts"
+)
+
+// How the owner's preferences handled an alert
+const (
+	NotificationSent       = "sent"
+	NotificationSuppressed = "suppressed" // its category is switched off
+	NotificationDeferred   = "deferred"   // held until the owner's quiet hours end
+)
+
+const (
+	quietHoursLayout = "15:04"
+	// deferredReleaseInterval is how often deferred alerts are checked for the end of quiet hours
+	deferredReleaseInterval = time.Minute
+	preferenceLookupTimeout = 2 * time.Second
+)
+
+var (
+	ErrInvalidNotificationPreferences  = errors.New("invalid notification preferences")
+	ErrNotificationPreferencesNotFound = errors.New("notification preferences not found")
+)
+
+var notificationCategories = []string{CategoryTradingFills, CategoryRiskAlerts, CategoryPriceAlerts, CategorySystemAlerts}
+
+// telegramChatID matches numeric chat IDs and public @channel names
+var telegramChatID = regexp.MustCompile(`^(-?[0-9]{1,20}|@[A-Za-z0-9_]{5,32})$`)
+
+// NotificationPreferences routes a user's alerts to their own destinations instead of the
+// service-wide ones
+type NotificationPreferences struct {
+	UserID     uuid.UUID            `json:"user_id"`
+	Channels   NotificationChannels `json:"channels"`
+	QuietHours *QuietHours          `json:"quiet_hours,omitempty"`
+	Categories map[string]bool      `json:"categories"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// NotificationChannels are the user's destinations per channel type
+type NotificationChannels struct {
+	Email          string `json:"email,omitempty"`
+	TelegramChatID string `json:"telegram_chat_id,omitempty"`
+	WebhookURL     string `json:"webhook_url,omitempty"`
+}
+
+// QuietHours is a daily window, which may span midnight, in which non-critical alerts are
+// held back. Times are HH:MM in the IANA timezone, UTC when empty.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// NotificationPreferenceStore persists notification preferences
+type NotificationPreferenceStore interface {
+	// GetPreferences returns ErrNotificationPreferencesNotFound for users who never set any
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*NotificationPreferences, error)
+	SavePreferences(ctx context.Context, prefs *NotificationPreferences) error
+}
+
+// DefaultNotificationPreferences returns the preferences of a user who has not set any: no
+// own destinations, no quiet hours and every category on
+func DefaultNotificationPreferences(userID uuid.UUID) *NotificationPreferences {
+	prefs := &NotificationPreferences{UserID: userID, Categories: make(map[string]bool, len(notificationCategories))}
+	for _, category := range notificationCategories {
+		prefs.Categories[category] = true
+	}
+	return prefs
+}
+
+// Normalize validates the preferences in place, trimming destinations and filling in the
+// categories left out as enabled
+func (p *NotificationPreferences) Normalize() error {
+	channels := &p.Channels
+	channels.Email = strings.TrimSpace(channels.Email)
+	channels.TelegramChatID = strings.TrimSpace(channels.TelegramChatID)
+	channels.WebhookURL = strings.TrimSpace(channels.WebhookURL)
+
+	if channels.Email != "" {
+		address, err := mail.ParseAddress(channels.Email)
+		if err != nil {
+			return fmt.Errorf("%w: invalid email address", ErrInvalidNotificationPreferences)
+		}
+		channels.Email = address.Address
+	}
+	if channels.TelegramChatID != "" && !telegramChatID.MatchString(channels.TelegramChatID) {
+		return fmt.Errorf("%w: telegram_chat_id must be a numeric chat ID or an @channel name", ErrInvalidNotificationPreferences)
+	}
+	if channels.WebhookURL != "" {
+		if err := validateWebhookURL(channels.WebhookURL); err != nil {
+			return err
+		}
+	}
+
+	if p.QuietHours != nil {
+		if _, _, _, err := p.QuietHours.parse(); err != nil {
+			return err
+		}
+	}
+
+	categories := make(map[string]bool, len(notificationCategories))
+	for _, category := range notificationCategories {
+		categories[category] = true
+	}
+	for category, enabled := range p.Categories {
+		if _, known := categories[category]; !known {
+			return fmt.Errorf("%w: unknown category %q, expected one of %s", ErrInvalidNotificationPreferences,
+				category, strings.Join(notificationCategories, ", "))
+		}
+		categories[category] = enabled
+	}
+	p.Categories = categories
+	return nil
+}
+
+// CategoryEnabled reports whether the user wants alerts of category
+func (p *NotificationPreferences) CategoryEnabled(category string) bool {
+	enabled, set := p.Categories[category]
+	return enabled || !set
+}
+
+// channelNames lists the channel types the user has a destination for
+func (p *NotificationPreferences) channelNames() []string {
+	names := make([]string, 0, 3)
+	if p.Channels.Email != "" {
+		names = append(names, "email")
+	}
+	if p.Channels.TelegramChatID != "" {
+		names = append(names, "telegram")
+	}
+	if p.Channels.WebhookURL != "" {
+		names = append(names, "webhook")
+	}
+	return names
+}
+
+// quietUntil returns when the quiet hours containing now end, or false outside quiet hours
+func (p *NotificationPreferences) quietUntil(now time.Time) (time.Time, bool) {
+	if p.QuietHours == nil {
+		return time.Time{}, false
+	}
+	start, end, location, err := p.QuietHours.parse()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	endAt := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, end/60, end%60, 0, 0, location)
+	}
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endAt(0), true
+	case start > end && minute >= start:
+		return endAt(1), true
+	case start > end && minute < end:
+		return endAt(0), true
+	}
+	return time.Time{}, false
+}
+
+// parse returns the window as minutes of the day in its location
+func (q *QuietHours) parse() (start, end int, location *time.Location, err error) {
+	startAt, err := time.Parse(quietHoursLayout, strings.TrimSpace(q.Start))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("%w: quiet_hours.start must be HH:MM", ErrInvalidNotificationPreferences)
+	}
+	endAt, err := time.Parse(quietHoursLayout, strings.TrimSpace(q.End))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("%w: quiet_hours.end must be HH:MM", ErrInvalidNotificationPreferences)
+	}
+	start, end = startAt.Hour()*60+startAt.Minute(), endAt.Hour()*60+endAt.Minute()
+	if start == end {
+		return 0, 0, nil, fmt.Errorf("%w: quiet_hours.start and end must differ", ErrInvalidNotificationPreferences)
+	}
+
+	location = time.UTC
+	if q.Timezone != "" {
+		if location, err = time.LoadLocation(q.Timezone); err != nil {
+			return 0, 0, nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidNotificationPreferences, q.Timezone)
+		}
+	}
+	return start, end, location, nil
+}
+
+// validateWebhookURL accepts HTTPS URLs, rejecting hosts given as loopback, private or
+// link-local addresses so user webhooks cannot reach the internal network directly
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: webhook_url must be an https URL", ErrInvalidNotificationPreferences)
+	}
+	host := parsed.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("%w: webhook_url must not point to localhost", ErrInvalidNotificationPreferences)
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()) {
+		return fmt.Errorf("%w: webhook_url must not point to a private address", ErrInvalidNotificationPreferences)
+	}
+	return nil
+}
+
+// SetPreferenceStore enables per-user routing of alerts that have an owner
+func (a *AlertService) SetPreferenceStore(store NotificationPreferenceStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.preferences = store
+}
+
+// GetNotificationPreferences returns the user's preferences, or the defaults when they never
+// set any
+func (a *AlertService) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (*NotificationPreferences, error) {
+	store := a.preferenceStore()
+	if store == nil {
+		return DefaultNotificationPreferences(userID), nil
+	}
+	prefs, err := store.GetPreferences(ctx, userID)
+	if errors.Is(err, ErrNotificationPreferencesNotFound) {
+		return DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// UpdateNotificationPreferences validates and replaces the user's preferences
+func (a *AlertService) UpdateNotificationPreferences(ctx context.Context, prefs *NotificationPreferences) error {
+	store := a.preferenceStore()
+	if store == nil {
+		return errors.New("notification preferences are not available")
+	}
+	if err := prefs.Normalize(); err != nil {
+		return err
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+	if err := store.SavePreferences(ctx, prefs); err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	a.logger.Info(ctx, "Notification preferences updated", map[string]interface{}{
+		"user_id":  prefs.UserID.String(),
+		"channels": prefs.channelNames(),
+	})
+	return nil
+}
+
+// GetMissedAlerts returns the user's most recent alerts that their preferences suppressed or
+// are still holding back for quiet hours
+func (a *AlertService) GetMissedAlerts(userID uuid.UUID, limit int) []Alert {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	missed := make([]Alert, 0)
+	for i := len(a.history) - 1; i >= 0 && (limit <= 0 || len(missed) < limit); i-- {
+		alert := a.history[i]
+		if alert.UserID == nil || *alert.UserID != userID {
+			continue
+		}
+		if alert.Notification == NotificationSuppressed || alert.Notification == NotificationDeferred {
+			missed = append(missed, alert)
+		}
+	}
+
+	// Oldest first, like GetAlerts
+	for i, j := 0, len(missed)-1; i < j; i, j = i+1, j-1 {
+		missed[i], missed[j] = missed[j], missed[i]
+	}
+	return missed
+}
+
+func (a *AlertService) preferenceStore() NotificationPreferenceStore {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.preferences
+}
+
+// ownerPreferences loads the preferences of the alert's owner. It returns nil, keeping the
+// service-wide routing, for alerts without an owner, owners without preferences and failed
+// lookups.
+func (a *AlertService) ownerPreferences(alert Alert) *NotificationPreferences {
+	store := a.preferenceStore()
+	if alert.UserID == nil || store == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, preferenceLookupTimeout)
+	defer cancel()
+	prefs, err := store.GetPreferences(ctx, *alert.UserID)
+	if err != nil {
+		if !errors.Is(err, ErrNotificationPreferencesNotFound) {
+			a.logger.Error(a.ctx, "Failed to load notification preferences", err, map[string]interface{}{
+				"alert_id": alert.ID,
+				"user_id":  alert.UserID.String(),
+			})
+		}
+		return nil
+	}
+	return prefs
+}
+
+// applyPreferences records how the owner's preferences handle the alert and reports whether
+// its own channels should be notified now. Critical alerts are never deferred.
+func applyPreferences(alert *Alert, prefs *NotificationPreferences, now time.Time) bool {
+	if prefs == nil {
+		return true
+	}
+	if !prefs.CategoryEnabled(alertCategory(*alert)) {
+		alert.Notification = NotificationSuppressed
+		return false
+	}
+	if alert.Severity != SeverityCritical {
+		if until, quiet := prefs.quietUntil(now); quiet {
+			alert.Notification = NotificationDeferred
+			alert.DeferredUntil = &until
+			return false
+		}
+	}
+	alert.Notification = NotificationSent
+	return true
+}
+
+// deferLocked queues an alert for delivery after quiet hours, dropping the oldest beyond the
+// history size. Callers must hold a.mu.
+func (a *AlertService) deferLocked(alert Alert) {
+	alert.Deliveries = nil
+	a.deferred = append(a.deferred, alert)
+	if limit := a.config.MaxHistorySize; limit > 0 && len(a.deferred) > limit {
+		a.deferred = a.deferred[len(a.deferred)-limit:]
+	}
+}
+
+// releaseDeferred periodically delivers the deferred alerts whose quiet hours are over
+func (a *AlertService) releaseDeferred() {
+	ticker := time.NewTicker(deferredReleaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.releaseDue(now)
+		}
+	}
+}
+
+// releaseDue re-applies the owner's current preferences to each due alert, so one whose
+// category was switched off meanwhile is suppressed, and delivers it on its own channels.
+// Its topic channels were notified when it was sent.
+func (a *AlertService) releaseDue(now time.Time) {
+	a.mu.Lock()
+	due := make([]Alert, 0)
+	kept := make([]Alert, 0, len(a.deferred))
+	for _, alert := range a.deferred {
+		if alert.DeferredUntil != nil && alert.DeferredUntil.After(now) {
+			kept = append(kept, alert)
+		} else {
+			due = append(due, alert)
+		}
+	}
+	a.deferred = kept
+	a.mu.Unlock()
+
+	for _, alert := range due {
+		prefs := a.ownerPreferences(alert)
+
+		a.mu.Lock()
+		alert.Notification = NotificationSent
+		if applyPreferences(&alert, prefs, now) {
+			a.dispatchLocked(&alert, prefs, true, false)
+		} else if alert.Notification == NotificationDeferred {
+			a.deferLocked(alert)
+		}
+		a.updateReleasedLocked(alert)
+		a.mu.Unlock()
+	}
+}
+
+// updateReleasedLocked records the outcome of a released alert on its history entry. Callers
+// must hold a.mu.
+func (a *AlertService) updateReleasedLocked(alert Alert) {
+	for i := len(a.history) - 1; i >= 0; i-- {
+		if a.history[i].ID != alert.ID {
+			continue
+		}
+		a.history[i].Notification = alert.Notification
+		a.history[i].DeferredUntil = alert.DeferredUntil
+		a.history[i].Deliveries = append(append([]DeliveryRecord(nil), a.history[i].Deliveries...), alert.Deliveries...)
+		return
+	}
+}
+
+// alertCategory returns the notification category of an alert
+func alertCategory(alert Alert) string {
+	if alert.Category != "" {
+		return alert.Category
+	}
+	return CategorySystemAlerts
+}
+
+// ownerChannel delivers to a destination from the owner's preferences, named apart from the
+// service-wide channel of the same type so both can be recorded on one alert
+type ownerChannel struct {
+	AlertChannel
+	name string
+}
+
+func (c ownerChannel) Name() string {
+	return c.name
+}
+
+// ownerChannelLocked returns the channel delivering to the owner's destination for a channel
+// type, or nil when the user has none or the type isn't configured on the service, such as
+// email without an SMTP server. Callers must hold a.mu.
+func (a *AlertService) ownerChannelLocked(prefs *NotificationPreferences, name string) AlertChannel {
+	switch name {
+	case "email":
+		emailConfig := a.config.Email
+		if prefs.Channels.Email == "" || !a.config.EnableEmail || emailConfig.SMTPHost == "" {
+			return nil
+		}
+		emailConfig.ToAddresses = []string{prefs.Channels.Email}
+		emailConfig.Enabled = true
+		return ownerChannel{AlertChannel: NewEmailChannel(emailConfig, a.logger), name: "user_email"}
+	case "telegram":
+		telegramConfig := a.config.Telegram
+		if prefs.Channels.TelegramChatID == "" || !a.config.EnableTelegram || telegramConfig.BotToken == "" {
+			return nil
+		}
+		telegramConfig.ChatIDs = []string{prefs.Channels.TelegramChatID}
+		telegramConfig.Enabled = true
+		return ownerChannel{AlertChannel: NewTelegramChannel(telegramConfig, a.logger), name: "user_telegram"}
+	case "webhook":
+		if prefs.Channels.WebhookURL == "" || !a.config.EnableWebhook {
+			return nil
+		}
+		webhookConfig := WebhookConfig{URL: prefs.Channels.WebhookURL, Timeout: a.config.DeliveryTimeout, Enabled: true}
+		return ownerChannel{AlertChannel: NewWebhookChannel(webhookConfig, a.logger), name: "user_webhook"}
+	}
+	return nil
+}
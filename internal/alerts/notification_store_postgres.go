@@ -0,0 +1,82 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresNotificationStore implements NotificationPreferenceStore using the
+// notification_preferences table
+type postgresNotificationStore struct {
+	db *database.DB
+}
+
+func NewPostgresNotificationStore(db *database.DB) NotificationPreferenceStore {
+	return &postgresNotificationStore{db: db}
+}
+
+func (s *postgresNotificationStore) GetPreferences(ctx context.Context, userID uuid.UUID) (*NotificationPreferences, error) {
+	prefs := &NotificationPreferences{UserID: userID}
+	var quietStart, quietEnd, quietTimezone sql.NullString
+	var categories []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT email, telegram_chat_id, webhook_url, quiet_start, quiet_end, quiet_timezone, categories, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID).Scan(&prefs.Channels.Email, &prefs.Channels.TelegramChatID, &prefs.Channels.WebhookURL,
+		&quietStart, &quietEnd, &quietTimezone, &categories, &prefs.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationPreferencesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if quietStart.Valid && quietEnd.Valid {
+		prefs.QuietHours = &QuietHours{Start: quietStart.String, End: quietEnd.String, Timezone: quietTimezone.String}
+	}
+	prefs.Categories = make(map[string]bool)
+	if len(categories) > 0 {
+		if err := json.Unmarshal(categories, &prefs.Categories); err != nil {
+			return nil, fmt.Errorf("failed to decode categories: %w", err)
+		}
+	}
+	prefs.UpdatedAt = prefs.UpdatedAt.UTC()
+	return prefs, nil
+}
+
+func (s *postgresNotificationStore) SavePreferences(ctx context.Context, prefs *NotificationPreferences) error {
+	categories, err := json.Marshal(prefs.Categories)
+	if err != nil {
+		return fmt.Errorf("failed to encode categories: %w", err)
+	}
+	var quietStart, quietEnd, quietTimezone sql.NullString
+	if prefs.QuietHours != nil {
+		quietStart = sql.NullString{String: prefs.QuietHours.Start, Valid: true}
+		quietEnd = sql.NullString{String: prefs.QuietHours.End, Valid: true}
+		quietTimezone = sql.NullString{String: prefs.QuietHours.Timezone, Valid: true}
+	}
+
+	_, err = s.db.ExecWithMetrics(ctx, `
+		INSERT INTO notification_preferences (user_id, email, telegram_chat_id, webhook_url, quiet_start, quiet_end,
+			quiet_timezone, categories, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			telegram_chat_id = EXCLUDED.telegram_chat_id,
+			webhook_url = EXCLUDED.webhook_url,
+			quiet_start = EXCLUDED.quiet_start,
+			quiet_end = EXCLUDED.quiet_end,
+			quiet_timezone = EXCLUDED.quiet_timezone,
+			categories = EXCLUDED.categories,
+			updated_at = EXCLUDED.updated_at
+	`, prefs.UserID, prefs.Channels.Email, prefs.Channels.TelegramChatID, prefs.Channels.WebhookURL,
+		quietStart, quietEnd, quietTimezone, categories, prefs.UpdatedAt)
+	return err
+}
@@ -0,0 +1,94 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// postgresPriceAlertStore implements PriceAlertStore using the price_alert_rules table
+type postgresPriceAlertStore struct {
+	db *database.DB
+}
+
+func NewPostgresPriceAlertStore(db *database.DB) PriceAlertStore {
+	return &postgresPriceAlertStore{db: db}
+}
+
+const priceAlertColumns = `id, user_id, symbol, condition, threshold, window_seconds, channels, repeating,
+	cooldown_seconds, is_active, trigger_count, last_triggered_at, created_at`
+
+func (s *postgresPriceAlertStore) SaveRule(ctx context.Context, rule *PriceAlertRule) error {
+	query := `
+		INSERT INTO price_alert_rules (` + priceAlertColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := s.db.ExecWithMetrics(ctx, query, rule.ID, rule.UserID, rule.Symbol, string(rule.Condition), rule.Threshold,
+		int64(rule.Window/time.Second), pq.Array(rule.Channels), rule.Repeat, int64(rule.Cooldown/time.Second),
+		rule.Active, rule.TriggerCount, rule.LastTriggered, rule.CreatedAt)
+	return err
+}
+
+func (s *postgresPriceAlertStore) ListRules(ctx context.Context, userID uuid.UUID) ([]*PriceAlertRule, error) {
+	return s.queryRules(ctx, `
+		SELECT `+priceAlertColumns+` FROM price_alert_rules
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+}
+
+func (s *postgresPriceAlertStore) ListActiveRules(ctx context.Context) ([]*PriceAlertRule, error) {
+	return s.queryRules(ctx, `
+		SELECT `+priceAlertColumns+` FROM price_alert_rules
+		WHERE is_active
+	`)
+}
+
+func (s *postgresPriceAlertStore) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	result, err := s.db.ExecWithMetrics(ctx, `DELETE FROM price_alert_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPriceAlertNotFound
+	}
+	return nil
+}
+
+func (s *postgresPriceAlertStore) RecordTrigger(ctx context.Context, ruleID uuid.UUID, triggeredAt time.Time, active bool) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE price_alert_rules
+		SET last_triggered_at = $2, trigger_count = trigger_count + 1, is_active = $3
+		WHERE id = $1
+	`, ruleID, triggeredAt, active)
+	return err
+}
+
+func (s *postgresPriceAlertStore) queryRules(ctx context.Context, query string, args ...interface{}) ([]*PriceAlertRule, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]*PriceAlertRule, 0)
+	for rows.Next() {
+		rule := &PriceAlertRule{}
+		var condition string
+		var windowSeconds, cooldownSeconds int64
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.Symbol, &condition, &rule.Threshold, &windowSeconds,
+			pq.Array(&rule.Channels), &rule.Repeat, &cooldownSeconds, &rule.Active, &rule.TriggerCount,
+			&rule.LastTriggered, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price alert: %w", err)
+		}
+		rule.Condition = PriceCondition(condition)
+		rule.Window = time.Duration(windowSeconds) * time.Second
+		rule.Cooldown = time.Duration(cooldownSeconds) * time.Second
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,471 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPriceAlertNotFound = errors.New("price alert not found")
+	ErrInvalidPriceAlert  = errors.New("invalid price alert")
+)
+
+// PriceCondition is the market condition a price alert waits for
+type PriceCondition string
+
+const (
+	PriceAbove         PriceCondition = "above"
+	PriceBelow         PriceCondition = "below"
+	PricePercentChange PriceCondition = "percent_change" // moves by at least threshold percent within the window
+)
+
+// ChannelInApp delivers price alerts only to the alert stream, under the user_<id> topic
+const ChannelInApp = "in_app"
+
+const (
+	defaultPriceAlertWindow = time.Hour
+	maxPriceAlertWindow     = 24 * time.Hour
+	defaultPriceAlertRepeat = 15 * time.Minute
+	priceSampleInterval     = time.Second
+)
+
+var priceAlertChannels = map[string]bool{
+	ChannelInApp: true,
+	"email":      true,
+	"slack":      true,
+	"telegram":   true,
+	"webhook":    true,
+}
+
+// PriceAlertRule is a user-defined alert on the live price of a market symbol
+type PriceAlertRule struct {
+	ID            uuid.UUID       `json:"id"`
+	UserID        uuid.UUID       `json:"user_id"`
+	Symbol        string          `json:"symbol"` // canonical symbol, e.g. BTCUSD
+	Condition     PriceCondition  `json:"condition"`
+	Threshold     decimal.Decimal `json:"threshold"`
+	Window        time.Duration   `json:"-"` // percent_change only
+	Channels      []string        `json:"channels"`
+	Repeat        bool            `json:"repeat"`
+	Cooldown      time.Duration   `json:"-"` // between repeats
+	Active        bool            `json:"active"`
+	TriggerCount  int             `json:"trigger_count"`
+	LastTriggered *time.Time      `json:"last_triggered,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// MarshalJSON renders the window and cooldown in the same duration syntax requests use
+func (r PriceAlertRule) MarshalJSON() ([]byte, error) {
+	type rule PriceAlertRule
+	out := struct {
+		rule
+		Window   string `json:"window,omitempty"`
+		Cooldown string `json:"cooldown,omitempty"`
+	}{rule: rule(r)}
+	if r.Window > 0 {
+		out.Window = r.Window.String()
+	}
+	if r.Cooldown > 0 {
+		out.Cooldown = r.Cooldown.String()
+	}
+	return json.Marshal(out)
+}
+
+// CreatePriceAlertRequest is the body of a price alert creation request. Durations use Go
+// syntax such as "30m"; channel is shorthand for a single entry in channels.
+type CreatePriceAlertRequest struct {
+	Symbol    string          `json:"symbol"`
+	Condition PriceCondition  `json:"condition"`
+	Threshold decimal.Decimal `json:"threshold"`
+	Window    string          `json:"window,omitempty"`
+	Channel   string          `json:"channel,omitempty"`
+	Channels  []string        `json:"channels,omitempty"`
+	Repeat    bool            `json:"repeat"`
+	Cooldown  string          `json:"cooldown,omitempty"`
+}
+
+// PriceAlertStore persists price alert rules
+type PriceAlertStore interface {
+	SaveRule(ctx context.Context, rule *PriceAlertRule) error
+	ListRules(ctx context.Context, userID uuid.UUID) ([]*PriceAlertRule, error)
+	ListActiveRules(ctx context.Context) ([]*PriceAlertRule, error)
+	DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error
+	RecordTrigger(ctx context.Context, ruleID uuid.UUID, triggeredAt time.Time, active bool) error
+}
+
+// MarketDataSource streams live market updates per symbol
+type MarketDataSource interface {
+	Subscribe(symbol string, exchanges ...string) <-chan realtime.MarketUpdate
+	Unsubscribe(symbol string, ch <-chan realtime.MarketUpdate)
+}
+
+type pricePoint struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+// PriceAlertManager evaluates user price alert rules against the live ticker stream and
+// fires them through the alert service
+type PriceAlertManager struct {
+	logger  *observability.Logger
+	store   PriceAlertStore
+	alerts  *AlertService
+	market  MarketDataSource
+	rules   map[uuid.UUID]*PriceAlertRule
+	symbols map[string]<-chan realtime.MarketUpdate // subscribed symbol -> update stream
+	prices  map[string][]pricePoint                 // recent prices per symbol for percent_change rules
+	ctx     context.Context
+	mu      sync.Mutex
+}
+
+// NewPriceAlertManager creates a new price alert manager
+func NewPriceAlertManager(logger *observability.Logger, store PriceAlertStore, alertService *AlertService, market MarketDataSource) *PriceAlertManager {
+	return &PriceAlertManager{
+		logger:  logger,
+		store:   store,
+		alerts:  alertService,
+		market:  market,
+		rules:   make(map[uuid.UUID]*PriceAlertRule),
+		symbols: make(map[string]<-chan realtime.MarketUpdate),
+		prices:  make(map[string][]pricePoint),
+		ctx:     context.Background(),
+	}
+}
+
+// Start loads the active rules and begins watching their symbols until ctx is done
+func (m *PriceAlertManager) Start(ctx context.Context) error {
+	rules, err := m.store.ListActiveRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load price alerts: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ctx = ctx
+	for _, rule := range rules {
+		m.rules[rule.ID] = rule
+		m.watchLocked(rule.Symbol)
+	}
+
+	go func() {
+		<-ctx.Done()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for symbol := range m.symbols {
+			m.unwatchLocked(symbol)
+		}
+	}()
+
+	m.logger.Info(ctx, "Price alert evaluator started", map[string]interface{}{
+		"rules":   len(rules),
+		"symbols": len(m.symbols),
+	})
+	return nil
+}
+
+// CreateRule validates and stores a new price alert for a user and starts evaluating it
+func (m *PriceAlertManager) CreateRule(ctx context.Context, userID uuid.UUID, req CreatePriceAlertRequest) (*PriceAlertRule, error) {
+	rule, err := newPriceAlertRule(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store.SaveRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save price alert: %w", err)
+	}
+
+	// Evaluation mutates its own copy so the returned rule is safe to encode
+	evaluated := *rule
+	m.mu.Lock()
+	m.rules[rule.ID] = &evaluated
+	m.watchLocked(rule.Symbol)
+	m.mu.Unlock()
+
+	m.logger.Info(ctx, "Price alert created", map[string]interface{}{
+		"rule_id":   rule.ID.String(),
+		"user_id":   userID.String(),
+		"symbol":    rule.Symbol,
+		"condition": string(rule.Condition),
+		"threshold": rule.Threshold.String(),
+	})
+	return rule, nil
+}
+
+// ListRules returns all price alerts of a user, including one-shot alerts that already fired
+func (m *PriceAlertManager) ListRules(ctx context.Context, userID uuid.UUID) ([]*PriceAlertRule, error) {
+	return m.store.ListRules(ctx, userID)
+}
+
+// DeleteRule removes a user's price alert
+func (m *PriceAlertManager) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	if err := m.store.DeleteRule(ctx, userID, ruleID); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeRuleLocked(ruleID)
+	return nil
+}
+
+// watchLocked subscribes to a symbol's updates unless already subscribed
+func (m *PriceAlertManager) watchLocked(symbol string) {
+	if _, watching := m.symbols[symbol]; watching {
+		return
+	}
+	updates := m.market.Subscribe(symbol)
+	m.symbols[symbol] = updates
+
+	go func() {
+		for update := range updates {
+			if update.Type != realtime.UpdateTypeTicker && update.Type != realtime.UpdateTypeTrade {
+				continue
+			}
+			if !update.Price.IsPositive() {
+				continue
+			}
+			m.evaluate(symbol, update.Price, time.Now())
+		}
+	}()
+}
+
+// unwatchLocked ends the subscription of a symbol, which stops its evaluation goroutine
+func (m *PriceAlertManager) unwatchLocked(symbol string) {
+	if updates, watching := m.symbols[symbol]; watching {
+		m.market.Unsubscribe(symbol, updates)
+		delete(m.symbols, symbol)
+		delete(m.prices, symbol)
+	}
+}
+
+// removeRuleLocked drops a rule from evaluation, unsubscribing its symbol when no rule is left on it
+func (m *PriceAlertManager) removeRuleLocked(ruleID uuid.UUID) {
+	rule, exists := m.rules[ruleID]
+	if !exists {
+		return
+	}
+	delete(m.rules, ruleID)
+
+	for _, other := range m.rules {
+		if other.Symbol == rule.Symbol {
+			return
+		}
+	}
+	m.unwatchLocked(rule.Symbol)
+}
+
+// evaluate checks every rule on a symbol against a new price and fires those that match
+func (m *PriceAlertManager) evaluate(symbol string, price decimal.Decimal, now time.Time) {
+	type firing struct {
+		rule   PriceAlertRule
+		change decimal.Decimal
+	}
+	var fired []firing
+
+	m.mu.Lock()
+	ctx := m.ctx
+	if _, watching := m.symbols[symbol]; !watching {
+		m.mu.Unlock()
+		return
+	}
+	m.recordPriceLocked(symbol, price, now)
+
+	for _, rule := range m.rules {
+		if rule.Symbol != symbol || !rule.Active {
+			continue
+		}
+		if rule.LastTriggered != nil && now.Sub(*rule.LastTriggered) < rule.Cooldown {
+			continue
+		}
+
+		matched, change := m.matchLocked(rule, price, now)
+		if !matched {
+			continue
+		}
+
+		triggeredAt := now
+		rule.LastTriggered = &triggeredAt
+		rule.TriggerCount++
+		if !rule.Repeat {
+			rule.Active = false
+		}
+		fired = append(fired, firing{rule: *rule, change: change})
+	}
+	for _, f := range fired {
+		if !f.rule.Active {
+			m.removeRuleLocked(f.rule.ID)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, f := range fired {
+		m.fire(ctx, f.rule, price, f.change)
+	}
+}
+
+// matchLocked reports whether a rule's condition holds at the given price, along with
+// the percent change over the rule's window for percent_change rules
+func (m *PriceAlertManager) matchLocked(rule *PriceAlertRule, price decimal.Decimal, now time.Time) (bool, decimal.Decimal) {
+	switch rule.Condition {
+	case PriceAbove:
+		return price.GreaterThanOrEqual(rule.Threshold), decimal.Zero
+	case PriceBelow:
+		return price.LessThanOrEqual(rule.Threshold), decimal.Zero
+	case PricePercentChange:
+		since := now.Add(-rule.Window)
+		for _, point := range m.prices[rule.Symbol] {
+			if point.at.Before(since) {
+				continue
+			}
+			if point.at.Equal(now) {
+				break
+			}
+			change := price.Sub(point.price).Div(point.price).Mul(decimal.NewFromInt(100))
+			return change.Abs().GreaterThanOrEqual(rule.Threshold), change
+		}
+	}
+	return false, decimal.Zero
+}
+
+// recordPriceLocked keeps the price history needed by the longest percent_change window on a symbol
+func (m *PriceAlertManager) recordPriceLocked(symbol string, price decimal.Decimal, now time.Time) {
+	var window time.Duration
+	for _, rule := range m.rules {
+		if rule.Symbol == symbol && rule.Condition == PricePercentChange && rule.Window > window {
+			window = rule.Window
+		}
+	}
+	if window == 0 {
+		delete(m.prices, symbol)
+		return
+	}
+
+	points := m.prices[symbol]
+	if n := len(points); n > 0 && now.Sub(points[n-1].at) < priceSampleInterval {
+		return
+	}
+	points = append(points, pricePoint{price: price, at: now})
+
+	cutoff := now.Add(-window)
+	drop := 0
+	for drop < len(points)-1 && points[drop].at.Before(cutoff) {
+		drop++
+	}
+	m.prices[symbol] = points[drop:]
+}
+
+// fire sends the alert for a triggered rule and persists its trigger state
+func (m *PriceAlertManager) fire(ctx context.Context, rule PriceAlertRule, price, change decimal.Decimal) {
+	var message string
+	switch rule.Condition {
+	case PricePercentChange:
+		message = fmt.Sprintf("%s moved %s%% within %s (price %s)", rule.Symbol, change.StringFixed(2), rule.Window, price.String())
+	default:
+		message = fmt.Sprintf("%s is %s %s (price %s)", rule.Symbol, rule.Condition, rule.Threshold.String(), price.String())
+	}
+
+	channels := make([]string, 0, len(rule.Channels))
+	for _, channel := range rule.Channels {
+		if channel != ChannelInApp {
+			channels = append(channels, channel)
+		}
+	}
+
+	alert := m.alerts.CreateAlert(rule.ID.String(), fmt.Sprintf("%s price alert", rule.Symbol), message,
+		SeverityInfo, "price_"+rule.Symbol, price, rule.Threshold, channels)
+	alert.UserID = &rule.UserID
+	alert.Category = CategoryPriceAlerts
+	alert.Cooldown = rule.Cooldown
+	alert.Metadata["symbol"] = rule.Symbol
+	alert.Metadata["condition"] = string(rule.Condition)
+	if rule.Condition == PricePercentChange {
+		alert.Metadata["change_percent"] = change.StringFixed(2)
+		alert.Metadata["window"] = rule.Window.String()
+	}
+	m.alerts.SendAlert(alert)
+
+	if err := m.store.RecordTrigger(ctx, rule.ID, *rule.LastTriggered, rule.Active); err != nil {
+		m.logger.Error(ctx, "Failed to record price alert trigger", err, map[string]interface{}{
+			"rule_id": rule.ID.String(),
+		})
+	}
+}
+
+// newPriceAlertRule validates a creation request into a new active rule
+func newPriceAlertRule(userID uuid.UUID, req CreatePriceAlertRequest) (*PriceAlertRule, error) {
+	symbol := realtime.CanonicalSymbol(req.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidPriceAlert)
+	}
+	if !req.Threshold.IsPositive() {
+		return nil, fmt.Errorf("%w: threshold must be positive", ErrInvalidPriceAlert)
+	}
+
+	rule := &PriceAlertRule{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Symbol:    symbol,
+		Condition: req.Condition,
+		Threshold: req.Threshold,
+		Repeat:    req.Repeat,
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	switch req.Condition {
+	case PriceAbove, PriceBelow:
+	case PricePercentChange:
+		rule.Window = defaultPriceAlertWindow
+		if req.Window != "" {
+			window, err := time.ParseDuration(req.Window)
+			if err != nil || window <= 0 || window > maxPriceAlertWindow {
+				return nil, fmt.Errorf("%w: window must be a duration up to %s", ErrInvalidPriceAlert, maxPriceAlertWindow)
+			}
+			rule.Window = window
+		}
+	default:
+		return nil, fmt.Errorf("%w: condition must be one of above, below, percent_change", ErrInvalidPriceAlert)
+	}
+
+	channels := req.Channels
+	if req.Channel != "" {
+		channels = append([]string{req.Channel}, channels...)
+	}
+	seen := make(map[string]bool)
+	for _, channel := range channels {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if !priceAlertChannels[channel] {
+			return nil, fmt.Errorf("%w: unknown channel %q", ErrInvalidPriceAlert, channel)
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			rule.Channels = append(rule.Channels, channel)
+		}
+	}
+	if len(rule.Channels) == 0 {
+		rule.Channels = []string{ChannelInApp}
+	}
+
+	if req.Repeat {
+		rule.Cooldown = defaultPriceAlertRepeat
+		if req.Cooldown != "" {
+			cooldown, err := time.ParseDuration(req.Cooldown)
+			if err != nil || cooldown < time.Minute {
+				return nil, fmt.Errorf("%w: cooldown must be a duration of at least 1m", ErrInvalidPriceAlert)
+			}
+			rule.Cooldown = cooldown
+		}
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,345 @@
+package alerts
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPriceAlertStore keeps rules in memory and records every trigger
+type memoryPriceAlertStore struct {
+	mu       sync.Mutex
+	rules    map[uuid.UUID]*PriceAlertRule
+	triggers []priceAlertTrigger
+}
+
+type priceAlertTrigger struct {
+	ruleID uuid.UUID
+	at     time.Time
+	active bool
+}
+
+func newMemoryPriceAlertStore() *memoryPriceAlertStore {
+	return &memoryPriceAlertStore{rules: make(map[uuid.UUID]*PriceAlertRule)}
+}
+
+func (s *memoryPriceAlertStore) SaveRule(ctx context.Context, rule *PriceAlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *rule
+	s.rules[rule.ID] = &stored
+	return nil
+}
+
+func (s *memoryPriceAlertStore) ListRules(ctx context.Context, userID uuid.UUID) ([]*PriceAlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rules []*PriceAlertRule
+	for _, rule := range s.rules {
+		if rule.UserID == userID {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	return rules, nil
+}
+
+func (s *memoryPriceAlertStore) ListActiveRules(ctx context.Context) ([]*PriceAlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rules []*PriceAlertRule
+	for _, rule := range s.rules {
+		if rule.Active {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	return rules, nil
+}
+
+func (s *memoryPriceAlertStore) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, exists := s.rules[ruleID]
+	if !exists || rule.UserID != userID {
+		return ErrPriceAlertNotFound
+	}
+	delete(s.rules, ruleID)
+	return nil
+}
+
+func (s *memoryPriceAlertStore) RecordTrigger(ctx context.Context, ruleID uuid.UUID, triggeredAt time.Time, active bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.triggers = append(s.triggers, priceAlertTrigger{ruleID: ruleID, at: triggeredAt, active: active})
+	if rule, exists := s.rules[ruleID]; exists {
+		rule.TriggerCount++
+		rule.LastTriggered = &triggeredAt
+		rule.Active = active
+	}
+	return nil
+}
+
+func (s *memoryPriceAlertStore) triggered(ruleID uuid.UUID) []priceAlertTrigger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var triggers []priceAlertTrigger
+	for _, trigger := range s.triggers {
+		if trigger.ruleID == ruleID {
+			triggers = append(triggers, trigger)
+		}
+	}
+	return triggers
+}
+
+// silentMarket hands out update streams that never publish, so tests drive evaluate directly
+type silentMarket struct {
+	mu         sync.Mutex
+	subscribed map[string]int
+}
+
+func (m *silentMarket) Subscribe(symbol string, exchanges ...string) <-chan realtime.MarketUpdate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribed[symbol]++
+	return make(chan realtime.MarketUpdate)
+}
+
+func (m *silentMarket) Unsubscribe(symbol string, ch <-chan realtime.MarketUpdate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribed[symbol]--
+}
+
+func (m *silentMarket) subscriptions(symbol string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.subscribed[symbol]
+}
+
+type priceAlertFixture struct {
+	manager *PriceAlertManager
+	store   *memoryPriceAlertStore
+	market  *silentMarket
+	alerts  *AlertService
+	userID  uuid.UUID
+	start   time.Time
+}
+
+func newPriceAlertFixture(t *testing.T) *priceAlertFixture {
+	f := &priceAlertFixture{
+		store:  newMemoryPriceAlertStore(),
+		market: &silentMarket{subscribed: make(map[string]int)},
+		alerts: newDeliveryService(t),
+		userID: uuid.New(),
+		start:  time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.manager = NewPriceAlertManager(testLogger(), f.store, f.alerts, f.market)
+	return f
+}
+
+func (f *priceAlertFixture) create(t *testing.T, req CreatePriceAlertRequest) *PriceAlertRule {
+	t.Helper()
+	rule, err := f.manager.CreateRule(context.Background(), f.userID, req)
+	require.NoError(t, err)
+	return rule
+}
+
+// feed evaluates a price sequence on a symbol, one price per offset from the fixture start
+func (f *priceAlertFixture) feed(symbol string, ticks ...priceTick) {
+	for _, tick := range ticks {
+		f.manager.evaluate(symbol, decimal.NewFromFloat(tick.price), f.start.Add(tick.after))
+	}
+}
+
+type priceTick struct {
+	after time.Duration
+	price float64
+}
+
+func (f *priceAlertFixture) firedAlerts(ruleID uuid.UUID) []Alert {
+	var fired []Alert
+	for _, alert := range f.alerts.GetAlerts(0) {
+		if alert.RuleID == ruleID.String() {
+			fired = append(fired, alert)
+		}
+	}
+	return fired
+}
+
+func TestPriceAlerts_AboveAndBelow(t *testing.T) {
+	f := newPriceAlertFixture(t)
+	above := f.create(t, CreatePriceAlertRequest{Symbol: "BTC/USD", Condition: PriceAbove, Threshold: decimal.NewFromInt(50000), Channel: "email"})
+	below := f.create(t, CreatePriceAlertRequest{Symbol: "btc-usd", Condition: PriceBelow, Threshold: decimal.NewFromInt(45000)})
+	assert.Equal(t, "BTCUSD", above.Symbol)
+	assert.Equal(t, 1, f.market.subscriptions("BTCUSD"), "rules on the same symbol share one subscription")
+
+	f.feed("BTCUSD",
+		priceTick{0, 49000},
+		priceTick{time.Second, 50000}, // above fires at the threshold
+		priceTick{2 * time.Second, 51000},
+		priceTick{3 * time.Second, 46000},
+		priceTick{4 * time.Second, 44000}, // below fires
+		priceTick{5 * time.Second, 40000},
+	)
+
+	fired := f.firedAlerts(above.ID)
+	require.Len(t, fired, 1)
+	assert.Equal(t, f.userID, *fired[0].UserID)
+	assert.Equal(t, CategoryPriceAlerts, fired[0].Category)
+	assert.Equal(t, []string{"email"}, fired[0].Channels)
+	assert.True(t, fired[0].Value.Equal(decimal.NewFromInt(50000)))
+	assert.Equal(t, "BTCUSD is above 50000 (price 50000)", fired[0].Message)
+
+	// One-shot rules fire once and are persisted as inactive
+	assert.Len(t, f.firedAlerts(below.ID), 1)
+	assert.Equal(t, []priceAlertTrigger{{ruleID: above.ID, at: f.start.Add(time.Second), active: false}}, f.store.triggered(above.ID))
+	assert.Equal(t, []priceAlertTrigger{{ruleID: below.ID, at: f.start.Add(4 * time.Second), active: false}}, f.store.triggered(below.ID))
+
+	// Once no rule is left on a symbol it is no longer watched
+	assert.Equal(t, 0, f.market.subscriptions("BTCUSD"))
+	f.feed("BTCUSD", priceTick{time.Minute, 60000})
+	assert.Len(t, f.alerts.GetAlerts(0), 2)
+}
+
+func TestPriceAlerts_PercentChangeWithinWindow(t *testing.T) {
+	f := newPriceAlertFixture(t)
+	rule := f.create(t, CreatePriceAlertRequest{Symbol: "ETHUSD", Condition: PricePercentChange, Threshold: decimal.NewFromInt(5), Window: "10m"})
+
+	f.feed("ETHUSD",
+		priceTick{0, 100},
+		priceTick{time.Minute, 103},      // +3% since the start
+		priceTick{4 * time.Minute, 97},   // -3%
+		priceTick{11 * time.Minute, 104}, // the start fell out of the window, +1% since 103
+	)
+	assert.Empty(t, f.firedAlerts(rule.ID))
+
+	// Compared against the oldest price still inside the window
+	f.feed("ETHUSD", priceTick{12 * time.Minute, 91})
+	fired := f.firedAlerts(rule.ID)
+	require.Len(t, fired, 1)
+	assert.Equal(t, "-6.19", fired[0].Metadata["change_percent"])
+	assert.Equal(t, "10m0s", fired[0].Metadata["window"])
+	assert.Equal(t, "ETHUSD moved -6.19% within 10m0s (price 91)", fired[0].Message)
+}
+
+func TestPriceAlerts_PercentChangeSamplesPrices(t *testing.T) {
+	f := newPriceAlertFixture(t)
+	rule := f.create(t, CreatePriceAlertRequest{Symbol: "ETHUSD", Condition: PricePercentChange, Threshold: decimal.NewFromInt(5), Window: "1m"})
+
+	// A tick within the sample interval of the last point is evaluated but not kept as a reference price
+	f.feed("ETHUSD",
+		priceTick{0, 100},
+		priceTick{500 * time.Millisecond, 96},
+		priceTick{time.Minute + 200*time.Millisecond, 101}, // +5.2% against the skipped tick
+	)
+	assert.Empty(t, f.firedAlerts(rule.ID))
+
+	f.feed("ETHUSD", priceTick{62 * time.Second, 106.1})
+	fired := f.firedAlerts(rule.ID)
+	require.Len(t, fired, 1)
+	assert.Equal(t, "5.05", fired[0].Metadata["change_percent"])
+}
+
+func TestPriceAlerts_RepeatCooldown(t *testing.T) {
+	f := newPriceAlertFixture(t)
+	rule := f.create(t, CreatePriceAlertRequest{Symbol: "SOLUSD", Condition: PriceAbove, Threshold: decimal.NewFromInt(150), Repeat: true, Cooldown: "5m"})
+	assert.Equal(t, 5*time.Minute, rule.Cooldown)
+
+	f.feed("SOLUSD",
+		priceTick{0, 151},               // fires
+		priceTick{time.Minute, 152},     // within the cooldown
+		priceTick{4 * time.Minute, 155}, // within the cooldown
+		priceTick{5 * time.Minute, 149}, // cooled down but below the threshold
+		priceTick{6 * time.Minute, 150}, // fires again
+		priceTick{7 * time.Minute, 160},
+	)
+
+	assert.Len(t, f.firedAlerts(rule.ID), 2)
+	assert.Equal(t, []priceAlertTrigger{
+		{ruleID: rule.ID, at: f.start, active: true},
+		{ruleID: rule.ID, at: f.start.Add(6 * time.Minute), active: true},
+	}, f.store.triggered(rule.ID))
+	assert.Equal(t, 1, f.market.subscriptions("SOLUSD"), "repeating rules stay watched")
+
+	// Deleting the rule stops its evaluation
+	require.NoError(t, f.manager.DeleteRule(context.Background(), f.userID, rule.ID))
+	assert.Equal(t, 0, f.market.subscriptions("SOLUSD"))
+	f.feed("SOLUSD", priceTick{time.Hour, 200})
+	assert.Len(t, f.firedAlerts(rule.ID), 2)
+
+	assert.ErrorIs(t, f.manager.DeleteRule(context.Background(), f.userID, rule.ID), ErrPriceAlertNotFound)
+}
+
+func TestPriceAlerts_StartLoadsActiveRules(t *testing.T) {
+	f := newPriceAlertFixture(t)
+	rule, err := newPriceAlertRule(f.userID, CreatePriceAlertRequest{Symbol: "BTCUSD", Condition: PriceBelow, Threshold: decimal.NewFromInt(30000)})
+	require.NoError(t, err)
+	require.NoError(t, f.store.SaveRule(context.Background(), rule))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, f.manager.Start(ctx))
+	assert.Equal(t, 1, f.market.subscriptions("BTCUSD"))
+
+	f.feed("BTCUSD", priceTick{0, 29000})
+	assert.Len(t, f.firedAlerts(rule.ID), 1)
+
+	// Stopping ends every subscription
+	f.create(t, CreatePriceAlertRequest{Symbol: "ETHUSD", Condition: PriceAbove, Threshold: decimal.NewFromInt(5000)})
+	cancel()
+	assert.Eventually(t, func() bool { return f.market.subscriptions("ETHUSD") == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestNewPriceAlertRule(t *testing.T) {
+	userID := uuid.New()
+	threshold := decimal.NewFromInt(5)
+
+	rule, err := newPriceAlertRule(userID, CreatePriceAlertRequest{Symbol: "eth/usdt", Condition: PricePercentChange, Threshold: threshold})
+	require.NoError(t, err)
+	assert.Equal(t, "ETHUSD", rule.Symbol)
+	assert.Equal(t, defaultPriceAlertWindow, rule.Window)
+	assert.Equal(t, []string{ChannelInApp}, rule.Channels)
+	assert.Zero(t, rule.Cooldown, "one-shot rules have no cooldown")
+	assert.True(t, rule.Active)
+
+	rule, err = newPriceAlertRule(userID, CreatePriceAlertRequest{Symbol: "BTCUSD", Condition: PriceAbove, Threshold: threshold,
+		Channel: "Slack", Channels: []string{"email", "slack"}, Repeat: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"slack", "email"}, rule.Channels)
+	assert.Equal(t, defaultPriceAlertRepeat, rule.Cooldown)
+	assert.Zero(t, rule.Window, "only percent_change rules have a window")
+
+	invalid := []CreatePriceAlertRequest{
+		{Condition: PriceAbove, Threshold: threshold},
+		{Symbol: "BTCUSD", Condition: PriceAbove},
+		{Symbol: "BTCUSD", Condition: PriceAbove, Threshold: decimal.NewFromInt(-1)},
+		{Symbol: "BTCUSD", Condition: "crosses", Threshold: threshold},
+		{Symbol: "BTCUSD", Condition: PricePercentChange, Threshold: threshold, Window: "48h"},
+		{Symbol: "BTCUSD", Condition: PricePercentChange, Threshold: threshold, Window: "soon"},
+		{Symbol: "BTCUSD", Condition: PriceAbove, Threshold: threshold, Channel: "sms"},
+		{Symbol: "BTCUSD", Condition: PriceAbove, Threshold: threshold, Repeat: true, Cooldown: "30s"},
+	}
+	for _, req := range invalid {
+		_, err := newPriceAlertRule(userID, req)
+		assert.ErrorIs(t, err, ErrInvalidPriceAlert, "%+v", req)
+	}
+}
+
+func TestPriceAlertRule_MarshalJSON(t *testing.T) {
+	rule, err := newPriceAlertRule(uuid.New(), CreatePriceAlertRequest{Symbol: "BTCUSD", Condition: PricePercentChange,
+		Threshold: decimal.NewFromInt(5), Window: "30m", Repeat: true, Cooldown: "10m"})
+	require.NoError(t, err)
+
+	data, err := rule.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"window":"30m0s"`)
+	assert.Contains(t, string(data), `"cooldown":"10m0s"`)
+	assert.Contains(t, string(data), `"threshold":"5"`)
+}
@@ -0,0 +1,508 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCorrelationRule is returned for correlation rules that relate no alerts
+	ErrInvalidCorrelationRule = errors.New("invalid correlation rule")
+	// ErrIncidentNotFound is returned for unknown incident IDs
+	ErrIncidentNotFound = errors.New("incident not found")
+)
+
+const (
+	defaultCorrelationWindow = 5 * time.Minute
+	defaultIncidentGroupWait = 30 * time.Second
+)
+
+// CorrelationRule relates alerts that are really one incident, such as latency and error rate
+// alerts on the host whose CPU spiked. Alerts are related when both match every criterion the
+// rule sets: both of their alert rules are in RuleIDs, both of their metrics are in
+// MetricNames, and they have equal values for each of SharedTags.
+type CorrelationRule struct {
+	RuleID      string        `json:"rule_id"`
+	Name        string        `json:"name"`
+	RuleIDs     []string      `json:"rule_ids,omitempty"`
+	MetricNames []string      `json:"metric_names,omitempty"`
+	SharedTags  []string      `json:"shared_tags,omitempty"`
+	Window      time.Duration `json:"window,omitempty"` // overrides the manager's correlation window
+	Enabled     bool          `json:"enabled"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Incident groups correlated alerts. It is notified once, and resolved when all of its alerts
+// are.
+type Incident struct {
+	IncidentID           string              `json:"incident_id"`
+	Title                string              `json:"title"`
+	Status               IncidentStatus      `json:"status"`
+	Severity             AlertSeverity       `json:"severity"`
+	Alerts               []*Alert            `json:"alerts"`
+	CorrelationRuleIDs   []string            `json:"correlation_rule_ids,omitempty"`
+	DuplicatesSuppressed int                 `json:"duplicates_suppressed"`
+	OpenedAt             time.Time           `json:"opened_at"`
+	LastAlertAt          time.Time           `json:"last_alert_at"`
+	AcknowledgedAt       *time.Time          `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy       string              `json:"acknowledged_by,omitempty"`
+	ResolvedAt           *time.Time          `json:"resolved_at,omitempty"`
+	NotifyAt             time.Time           `json:"notify_at"`
+	NotificationsSent    []AlertNotification `json:"notifications_sent"`
+
+	notified bool
+}
+
+// IncidentStatus defines incident status
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen         IncidentStatus = "open"
+	IncidentStatusAcknowledged IncidentStatus = "acknowledged"
+	IncidentStatusResolved     IncidentStatus = "resolved"
+)
+
+// CreateCorrelationRule adds a rule relating alerts into incidents
+func (am *AlertManager) CreateCorrelationRule(rule *CorrelationRule) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if err := am.addCorrelationRule(rule); err != nil {
+		return err
+	}
+
+	am.logger.Info(context.Background(), "Correlation rule created", map[string]interface{}{
+		"rule_id":      rule.RuleID,
+		"name":         rule.Name,
+		"rule_ids":     rule.RuleIDs,
+		"metric_names": rule.MetricNames,
+		"shared_tags":  rule.SharedTags,
+	})
+
+	return nil
+}
+
+// RemoveCorrelationRule removes a correlation rule. Open incidents keep their alerts.
+func (am *AlertManager) RemoveCorrelationRule(ruleID string) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if _, exists := am.correlationRules[ruleID]; !exists {
+		return fmt.Errorf("correlation rule not found: %s", ruleID)
+	}
+	delete(am.correlationRules, ruleID)
+	return nil
+}
+
+// GetCorrelationRules returns all correlation rules
+func (am *AlertManager) GetCorrelationRules() []*CorrelationRule {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	rules := make([]*CorrelationRule, 0, len(am.correlationRules))
+	for _, rule := range am.correlationRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].CreatedAt.Before(rules[j].CreatedAt) })
+	return rules
+}
+
+// GetIncidents returns incidents with their alerts, newest first. An empty status returns all
+// of them.
+func (am *AlertManager) GetIncidents(status IncidentStatus) []*Incident {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	incidents := make([]*Incident, 0, len(am.incidents))
+	for _, incident := range am.incidents {
+		if status == "" || incident.Status == status {
+			incidents = append(incidents, incident.snapshot())
+		}
+	}
+	sort.Slice(incidents, func(i, j int) bool { return incidents[i].OpenedAt.After(incidents[j].OpenedAt) })
+	return incidents
+}
+
+// GetIncident returns an incident with its alerts
+func (am *AlertManager) GetIncident(incidentID string) (*Incident, error) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	incident, exists := am.incidents[incidentID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrIncidentNotFound, incidentID)
+	}
+	return incident.snapshot(), nil
+}
+
+// AcknowledgeIncident acknowledges an incident and all of its unresolved alerts
+func (am *AlertManager) AcknowledgeIncident(incidentID, acknowledgedBy string) (*Incident, error) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	incident, exists := am.incidents[incidentID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrIncidentNotFound, incidentID)
+	}
+
+	now := time.Now()
+	incident.AcknowledgedAt = &now
+	incident.AcknowledgedBy = acknowledgedBy
+	if incident.Status == IncidentStatusOpen {
+		incident.Status = IncidentStatusAcknowledged
+	}
+	for _, alert := range incident.Alerts {
+		if alert.Status == AlertStatusActive {
+			alert.Status = AlertStatusAcknowledged
+			alert.AcknowledgedAt = &now
+			alert.AcknowledgedBy = acknowledgedBy
+			am.stopEscalation(alert, "acknowledged")
+		}
+	}
+
+	am.logger.Info(context.Background(), "Incident acknowledged", map[string]interface{}{
+		"incident_id":     incidentID,
+		"acknowledged_by": acknowledgedBy,
+		"alert_count":     len(incident.Alerts),
+	})
+
+	return incident.snapshot(), nil
+}
+
+// addCorrelationRule validates and stores rule. The caller holds am.mu.
+func (am *AlertManager) addCorrelationRule(rule *CorrelationRule) error {
+	if len(rule.RuleIDs) == 0 && len(rule.MetricNames) == 0 && len(rule.SharedTags) == 0 {
+		return fmt.Errorf("%w: set rule IDs, metric names or shared tags", ErrInvalidCorrelationRule)
+	}
+	if rule.Window < 0 {
+		return fmt.Errorf("%w: window must not be negative", ErrInvalidCorrelationRule)
+	}
+	if rule.RuleID == "" {
+		rule.RuleID = uuid.New().String()
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now()
+	}
+	am.correlationRules[rule.RuleID] = rule
+	return nil
+}
+
+// relates reports whether the rule correlates alerts a and b
+func (r *CorrelationRule) relates(a, b *Alert) bool {
+	if !r.Enabled {
+		return false
+	}
+	if len(r.RuleIDs) > 0 && (!containsString(r.RuleIDs, a.RuleID) || !containsString(r.RuleIDs, b.RuleID)) {
+		return false
+	}
+	if len(r.MetricNames) > 0 && (!containsString(r.MetricNames, a.MetricName) || !containsString(r.MetricNames, b.MetricName)) {
+		return false
+	}
+	for _, tag := range r.SharedTags {
+		value, exists := a.Tags[tag]
+		if !exists || b.Tags[tag] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// reopenDuplicate suppresses a new alert for rule while an open incident already has one from
+// it, reactivating that alert instead. The caller holds am.mu.
+func (am *AlertManager) reopenDuplicate(rule *AlertRule, value float64) bool {
+	for _, incident := range am.incidents {
+		if incident.Status == IncidentStatusResolved {
+			continue
+		}
+		for _, alert := range incident.Alerts {
+			if alert.RuleID != rule.RuleID {
+				continue
+			}
+
+			incident.DuplicatesSuppressed++
+			alert.Value = value
+			alert.Context["last_updated"] = time.Now()
+			if alert.Status == AlertStatusResolved {
+				alert.Status = AlertStatusActive
+				alert.ResolvedAt = nil
+				am.activeAlerts[fmt.Sprintf("%s_%s", rule.RuleID, rule.MetricName)] = alert
+				am.startEscalation(alert, rule)
+			}
+
+			am.logger.Debug(context.Background(), "Duplicate alert suppressed by open incident", map[string]interface{}{
+				"incident_id": incident.IncidentID,
+				"alert_id":    alert.AlertID,
+				"rule_name":   rule.Name,
+			})
+			return true
+		}
+	}
+	return false
+}
+
+// correlateAlert adds a new alert to the open incident it relates to, or opens an incident for
+// it. The caller holds am.mu.
+func (am *AlertManager) correlateAlert(alert *Alert) {
+	if incident, ruleID := am.findIncident(alert); incident != nil {
+		incident.Alerts = append(incident.Alerts, alert)
+		incident.LastAlertAt = alert.TriggeredAt
+		if alertSeverityRank[alert.Severity] > alertSeverityRank[incident.Severity] {
+			incident.Severity = alert.Severity
+		}
+		if !containsString(incident.CorrelationRuleIDs, ruleID) {
+			incident.CorrelationRuleIDs = append(incident.CorrelationRuleIDs, ruleID)
+		}
+		am.alertIncidents[alert.AlertID] = incident.IncidentID
+
+		am.logger.Info(context.Background(), "Alert correlated into incident", map[string]interface{}{
+			"incident_id":         incident.IncidentID,
+			"alert_id":            alert.AlertID,
+			"correlation_rule_id": ruleID,
+			"alert_count":         len(incident.Alerts),
+		})
+		return
+	}
+
+	incident := &Incident{
+		IncidentID:        uuid.New().String(),
+		Title:             alert.RuleName,
+		Status:            IncidentStatusOpen,
+		Severity:          alert.Severity,
+		Alerts:            []*Alert{alert},
+		OpenedAt:          alert.TriggeredAt,
+		LastAlertAt:       alert.TriggeredAt,
+		NotifyAt:          alert.TriggeredAt,
+		NotificationsSent: make([]AlertNotification, 0),
+	}
+	am.incidents[incident.IncidentID] = incident
+	am.alertIncidents[alert.AlertID] = incident.IncidentID
+
+	// Without correlation rules nothing can join the incident, so there is no point waiting
+	if !am.hasCorrelationRules() || am.incidentGroupWait <= 0 {
+		am.notifyIncident(incident)
+		return
+	}
+	incident.NotifyAt = alert.TriggeredAt.Add(am.incidentGroupWait)
+}
+
+// findIncident returns the open incident with the latest alert related to alert within the
+// correlation window, and the correlation rule relating them
+func (am *AlertManager) findIncident(alert *Alert) (*Incident, string) {
+	var found *Incident
+	foundRuleID := ""
+	for _, incident := range am.incidents {
+		if incident.Status == IncidentStatusResolved || (found != nil && !incident.LastAlertAt.After(found.LastAlertAt)) {
+			continue
+		}
+		for _, rule := range am.correlationRules {
+			window := rule.Window
+			if window == 0 {
+				window = am.correlationWindow
+			}
+			if alert.TriggeredAt.Sub(incident.LastAlertAt) > window {
+				continue
+			}
+			if incident.relatedBy(rule, alert) {
+				found, foundRuleID = incident, rule.RuleID
+				break
+			}
+		}
+	}
+	return found, foundRuleID
+}
+
+func (i *Incident) relatedBy(rule *CorrelationRule, alert *Alert) bool {
+	for _, member := range i.Alerts {
+		if rule.relates(member, alert) {
+			return true
+		}
+	}
+	return false
+}
+
+func (am *AlertManager) hasCorrelationRules() bool {
+	for _, rule := range am.correlationRules {
+		if rule.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveIncidentAlert resolves the incident of a resolved alert once all its alerts are
+// resolved. The caller holds am.mu.
+func (am *AlertManager) resolveIncidentAlert(alert *Alert) {
+	incident, exists := am.incidents[am.alertIncidents[alert.AlertID]]
+	if !exists || incident.Status == IncidentStatusResolved {
+		return
+	}
+	for _, member := range incident.Alerts {
+		if member.Status != AlertStatusResolved {
+			return
+		}
+	}
+
+	now := time.Now()
+	incident.Status = IncidentStatusResolved
+	incident.ResolvedAt = &now
+
+	am.logger.Info(context.Background(), "Incident resolved", map[string]interface{}{
+		"incident_id": incident.IncidentID,
+		"alert_count": len(incident.Alerts),
+		"duration":    now.Sub(incident.OpenedAt),
+	})
+}
+
+// processIncidents sends incident notifications once their group wait is over
+func (am *AlertManager) processIncidents(ctx context.Context) {
+	interval := am.incidentGroupWait / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			am.flushIncidentNotifications(now)
+		}
+	}
+}
+
+// flushIncidentNotifications notifies incidents whose group wait ended by now
+func (am *AlertManager) flushIncidentNotifications(now time.Time) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	for _, incident := range am.incidents {
+		if !incident.notified && !now.Before(incident.NotifyAt) {
+			am.notifyIncident(incident)
+		}
+	}
+}
+
+// notifyIncident sends one notification per action of the incident's alert rules. Incidents of
+// a single alert are notified as that alert. The caller holds am.mu.
+func (am *AlertManager) notifyIncident(incident *Incident) {
+	incident.notified = true
+	if incident.Status == IncidentStatusResolved {
+		return
+	}
+	first := incident.Alerts[0]
+	if len(incident.Alerts) > 1 {
+		incident.Title = fmt.Sprintf("%s and %d related alerts", first.RuleName, len(incident.Alerts)-1)
+	}
+
+	seen := make(map[string]bool)
+	for _, alert := range incident.Alerts {
+		rule, exists := am.alertRules[alert.RuleID]
+		if !exists {
+			continue
+		}
+		for _, action := range rule.Actions {
+			key := string(action.ActionType) + "|" + action.Target
+			if !action.Enabled || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			message := am.formatNotificationMessage(first, action)
+			if len(incident.Alerts) > 1 {
+				message = am.formatIncidentMessage(incident)
+			}
+			metadata := make(map[string]interface{}, len(action.Parameters)+2)
+			for name, value := range action.Parameters {
+				metadata[name] = value
+			}
+			metadata["incident_id"] = incident.IncidentID
+			metadata["alert_count"] = len(incident.Alerts)
+
+			notification := &AlertNotification{
+				NotificationID: uuid.New().String(),
+				AlertID:        first.AlertID,
+				ActionType:     action.ActionType,
+				Target:         action.Target,
+				Message:        message,
+				Priority:       am.severityToPriority(incident.Severity),
+				ScheduledAt:    time.Now(),
+				Status:         NotificationStatusPending,
+				MaxRetries:     3,
+				Metadata:       metadata,
+			}
+
+			select {
+			case am.notifications <- notification:
+				incident.NotificationsSent = append(incident.NotificationsSent, *notification)
+				first.NotificationsSent = append(first.NotificationsSent, *notification)
+			default:
+				am.logger.Warn(context.Background(), "Notification queue full", map[string]interface{}{
+					"incident_id": incident.IncidentID,
+					"action_type": action.ActionType,
+				})
+			}
+		}
+	}
+}
+
+// formatIncidentMessage summarizes an incident and its alerts
+func (am *AlertManager) formatIncidentMessage(incident *Incident) string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "🚨 %s Incident: %s\n\nOpened: %s\nAlerts:",
+		incident.Severity, incident.Title, incident.OpenedAt.Format(time.RFC3339))
+	for _, alert := range incident.Alerts {
+		fmt.Fprintf(&message, "\n• [%s] %s: %s %.2f (threshold: %.2f)",
+			alert.Severity, alert.RuleName, alert.MetricName, alert.Value, alert.Threshold)
+	}
+	return message.String()
+}
+
+// cleanupIncidents removes incidents resolved before cutoff. The caller holds am.mu.
+func (am *AlertManager) cleanupIncidents(cutoff time.Time) {
+	for incidentID, incident := range am.incidents {
+		if incident.ResolvedAt != nil && incident.ResolvedAt.Before(cutoff) {
+			for _, alert := range incident.Alerts {
+				delete(am.alertIncidents, alert.AlertID)
+			}
+			delete(am.incidents, incidentID)
+		}
+	}
+}
+
+// snapshot copies the incident so callers can read it without the manager's lock
+func (i *Incident) snapshot() *Incident {
+	incident := *i
+	incident.Alerts = make([]*Alert, len(i.Alerts))
+	for index, alert := range i.Alerts {
+		alertCopy := *alert
+		incident.Alerts[index] = &alertCopy
+	}
+	incident.CorrelationRuleIDs = append([]string(nil), i.CorrelationRuleIDs...)
+	incident.NotificationsSent = append([]AlertNotification(nil), i.NotificationsSent...)
+	return &incident
+}
+
+// alertSeverityRank orders alert severities from info to critical
+var alertSeverityRank = map[AlertSeverity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityError:    3,
+	SeverityCritical: 4,
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package analytics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+// newTestAlertManager returns an alert manager with CPU, latency and error rate rules that all
+// email the on-call engineer
+func newTestAlertManager(t *testing.T, analyticsConfig *AnalyticsConfig) *AlertManager {
+	t.Helper()
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "error",
+	})
+	am := NewAlertManager(logger, analyticsConfig)
+
+	email := []AlertAction{{ActionType: ActionTypeEmail, Target: "oncall@example.com", Enabled: true}}
+	rules := []*AlertRule{
+		{RuleID: "cpu", Name: "High CPU", MetricName: "cpu_usage", Condition: ConditionGreaterThan, Threshold: 80, Severity: SeverityWarning, Enabled: true, Actions: email},
+		{RuleID: "latency", Name: "High latency", MetricName: "response_time", Condition: ConditionGreaterThan, Threshold: 1000, Severity: SeverityError, Enabled: true, Actions: email},
+		{RuleID: "errors", Name: "High error rate", MetricName: "error_rate", Condition: ConditionGreaterThan, Threshold: 5, Severity: SeverityCritical, Enabled: true, Actions: email},
+	}
+	for _, rule := range rules {
+		if err := am.CreateAlertRule(rule); err != nil {
+			t.Fatalf("Failed to create alert rule: %v", err)
+		}
+	}
+	return am
+}
+
+func sameHostRule() *CorrelationRule {
+	return &CorrelationRule{Name: "Same host", SharedTags: []string{"host"}, Enabled: true}
+}
+
+func TestAlertStormCorrelatesIntoOneIncident(t *testing.T) {
+	am := newTestAlertManager(t, &AnalyticsConfig{CorrelationRules: []*CorrelationRule{sameHostRule()}})
+	server1 := map[string]string{"host": "server1"}
+
+	am.EvaluateMetric("cpu_usage", 95, server1)
+	am.EvaluateMetric("response_time", 2500, server1)
+	am.EvaluateMetric("error_rate", 12, map[string]string{"host": "server2"})
+
+	incidents := am.GetIncidents(IncidentStatusOpen)
+	if len(incidents) != 2 {
+		t.Fatalf("Expected 2 incidents, got %d", len(incidents))
+	}
+	storm := incidents[1]
+	if len(storm.Alerts) != 2 || storm.Severity != SeverityError {
+		t.Errorf("Expected 2 alerts at error severity, got %d at %s", len(storm.Alerts), storm.Severity)
+	}
+
+	if queued := len(am.notifications); queued != 0 {
+		t.Errorf("Expected notifications to wait for the group wait, got %d", queued)
+	}
+	am.flushIncidentNotifications(time.Now().Add(defaultIncidentGroupWait))
+	if queued := len(am.notifications); queued != 2 {
+		t.Fatalf("Expected one notification per incident, got %d", queued)
+	}
+
+	storm, err := am.GetIncident(storm.IncidentID)
+	if err != nil {
+		t.Fatalf("Failed to get incident: %v", err)
+	}
+	if len(storm.NotificationsSent) != 1 {
+		t.Fatalf("Expected 1 notification for the storm, got %d", len(storm.NotificationsSent))
+	}
+	notification := storm.NotificationsSent[0]
+	for _, name := range []string{"High CPU", "High latency"} {
+		if !strings.Contains(notification.Message, name) {
+			t.Errorf("Expected the summary to list %s, got %q", name, notification.Message)
+		}
+	}
+	if notification.Priority != PriorityHigh || notification.Metadata["incident_id"] != storm.IncidentID {
+		t.Errorf("Expected a high priority notification of the incident, got %+v", notification)
+	}
+}
+
+func TestCorrelationRuleRelationships(t *testing.T) {
+	am := newTestAlertManager(t, &AnalyticsConfig{IncidentGroupWait: -1})
+	if err := am.CreateCorrelationRule(&CorrelationRule{Name: "Empty", Enabled: true}); !errors.Is(err, ErrInvalidCorrelationRule) {
+		t.Errorf("Expected ErrInvalidCorrelationRule, got %v", err)
+	}
+	if err := am.CreateCorrelationRule(&CorrelationRule{Name: "Load", RuleIDs: []string{"cpu", "latency"}, Enabled: true}); err != nil {
+		t.Fatalf("Failed to create correlation rule: %v", err)
+	}
+
+	am.EvaluateMetric("cpu_usage", 95, map[string]string{"host": "server1"})
+	am.EvaluateMetric("response_time", 2500, map[string]string{"endpoint": "/api/data"})
+	am.EvaluateMetric("error_rate", 12, map[string]string{"host": "server1"})
+
+	incidents := am.GetIncidents("")
+	if len(incidents) != 2 {
+		t.Fatalf("Expected CPU and latency in one incident and errors in another, got %d incidents", len(incidents))
+	}
+	if len(incidents[1].Alerts) != 2 || len(incidents[0].Alerts) != 1 || incidents[0].Alerts[0].RuleID != "errors" {
+		t.Errorf("Expected CPU and latency together and the error rate alone, got %d and %d alerts", len(incidents[1].Alerts), len(incidents[0].Alerts))
+	}
+	if queued := len(am.notifications); queued != 2 {
+		t.Errorf("Expected incidents to notify at once without a group wait, got %d", queued)
+	}
+}
+
+func TestCorrelationWindow(t *testing.T) {
+	rule := sameHostRule()
+	rule.Window = time.Nanosecond
+	am := newTestAlertManager(t, &AnalyticsConfig{CorrelationRules: []*CorrelationRule{rule}})
+
+	am.EvaluateMetric("cpu_usage", 95, map[string]string{"host": "server1"})
+	time.Sleep(time.Millisecond)
+	am.EvaluateMetric("response_time", 2500, map[string]string{"host": "server1"})
+
+	if incidents := am.GetIncidents(""); len(incidents) != 2 {
+		t.Errorf("Expected alerts outside the window to open separate incidents, got %d", len(incidents))
+	}
+}
+
+func TestIncidentSuppressesDuplicateAlerts(t *testing.T) {
+	am := newTestAlertManager(t, &AnalyticsConfig{CorrelationRules: []*CorrelationRule{sameHostRule()}})
+	server1 := map[string]string{"host": "server1"}
+
+	am.EvaluateMetric("cpu_usage", 95, server1)
+	am.EvaluateMetric("response_time", 2500, server1)
+	am.EvaluateMetric("cpu_usage", 50, server1) // CPU recovers while latency stays high
+	am.EvaluateMetric("cpu_usage", 97, server1) // and spikes again
+
+	if history := am.GetAlertHistory(0); len(history) != 2 {
+		t.Errorf("Expected no new alert while the incident is open, got %d alerts", len(history))
+	}
+	incidents := am.GetIncidents(IncidentStatusOpen)
+	if len(incidents) != 1 || incidents[0].DuplicatesSuppressed != 1 {
+		t.Fatalf("Expected 1 open incident with 1 suppressed duplicate, got %+v", incidents)
+	}
+	cpu := incidents[0].Alerts[0]
+	if cpu.Status != AlertStatusActive || cpu.Value != 97 {
+		t.Errorf("Expected the CPU alert reactivated at 97, got %s at %.0f", cpu.Status, cpu.Value)
+	}
+}
+
+func TestAcknowledgeAndResolveIncident(t *testing.T) {
+	am := newTestAlertManager(t, &AnalyticsConfig{CorrelationRules: []*CorrelationRule{sameHostRule()}})
+	server1 := map[string]string{"host": "server1"}
+
+	am.EvaluateMetric("cpu_usage", 95, server1)
+	am.EvaluateMetric("response_time", 2500, server1)
+	incidentID := am.GetIncidents("")[0].IncidentID
+
+	if _, err := am.AcknowledgeIncident("missing", "admin"); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("Expected ErrIncidentNotFound, got %v", err)
+	}
+	incident, err := am.AcknowledgeIncident(incidentID, "admin")
+	if err != nil {
+		t.Fatalf("Failed to acknowledge incident: %v", err)
+	}
+	if incident.Status != IncidentStatusAcknowledged {
+		t.Errorf("Expected acknowledged incident, got %s", incident.Status)
+	}
+	for _, alert := range am.GetActiveAlerts() {
+		if alert.Status != AlertStatusAcknowledged || alert.AcknowledgedBy != "admin" {
+			t.Errorf("Expected %s acknowledged by admin, got %s", alert.RuleName, alert.Status)
+		}
+	}
+
+	am.EvaluateMetric("cpu_usage", 40, server1)
+	if incident, _ := am.GetIncident(incidentID); incident.Status != IncidentStatusAcknowledged {
+		t.Errorf("Expected the incident to stay open while latency is high, got %s", incident.Status)
+	}
+	am.EvaluateMetric("response_time", 200, server1)
+	incident, _ = am.GetIncident(incidentID)
+	if incident.Status != IncidentStatusResolved || incident.ResolvedAt == nil {
+		t.Errorf("Expected the incident resolved with its alerts, got %s", incident.Status)
+	}
+
+	// A new spike after the incident resolved opens a new one
+	am.EvaluateMetric("cpu_usage", 95, server1)
+	if open := am.GetIncidents(IncidentStatusOpen); len(open) != 1 || open[0].IncidentID == incidentID {
+		t.Errorf("Expected a new open incident, got %+v", open)
+	}
+}
+
+func TestAlertsNotifyImmediatelyWithoutCorrelationRules(t *testing.T) {
+	am := newTestAlertManager(t, &AnalyticsConfig{})
+
+	am.EvaluateMetric("cpu_usage", 95, map[string]string{"host": "server1"})
+	am.EvaluateMetric("response_time", 2500, map[string]string{"host": "server1"})
+
+	if queued := len(am.notifications); queued != 2 {
+		t.Errorf("Expected a notification per alert, got %d", queued)
+	}
+	if incidents := am.GetIncidents(""); len(incidents) != 2 {
+		t.Errorf("Expected an incident per alert, got %d", len(incidents))
+	}
+}
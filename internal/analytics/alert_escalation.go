@@ -0,0 +1,561 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidEscalationPolicy is returned for escalation policies without runnable steps
+	ErrInvalidEscalationPolicy = errors.New("invalid escalation policy")
+	// ErrEscalationPolicyNotFound is returned for unknown escalation policy IDs
+	ErrEscalationPolicyNotFound = errors.New("escalation policy not found")
+	// ErrInvalidOnCallSchedule is returned for on-call schedules nobody can be paged through
+	ErrInvalidOnCallSchedule = errors.New("invalid on-call schedule")
+	// ErrOnCallScheduleNotFound is returned for unknown on-call schedule IDs
+	ErrOnCallScheduleNotFound = errors.New("on-call schedule not found")
+)
+
+const (
+	onCallRotationPeriod    = 7 * 24 * time.Hour
+	escalationCheckInterval = 15 * time.Second
+	escalationStoreTimeout  = 5 * time.Second
+)
+
+// OnCallSchedule rotates on-call duty through Users weekly, handing off every 7 days from
+// RotationStart
+type OnCallSchedule struct {
+	ScheduleID    string       `json:"schedule_id"`
+	Name          string       `json:"name"`
+	Users         []OnCallUser `json:"users"`
+	RotationStart time.Time    `json:"rotation_start"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// OnCallUser is a member of an on-call rotation. Pages reach them through each of their
+// notification preferences.
+type OnCallUser struct {
+	UserID                  string        `json:"user_id"`
+	Name                    string        `json:"name"`
+	NotificationPreferences []AlertAction `json:"notification_preferences"`
+}
+
+// EscalationState is an alert's progress through its escalation policy. Active escalations
+// are persisted with a snapshot of their alert, so paging resumes after a restart.
+type EscalationState struct {
+	AlertID    string    `json:"alert_id"`
+	PolicyID   string    `json:"policy_id"`
+	NextStep   int       `json:"next_step"` // index of the next step to run
+	StartedAt  time.Time `json:"started_at"`
+	NextStepAt time.Time `json:"next_step_at"`
+	Alert      Alert     `json:"alert"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	alert *Alert
+}
+
+// EscalationStore persists active escalations
+type EscalationStore interface {
+	SaveEscalation(ctx context.Context, state *EscalationState) error
+	DeleteEscalation(ctx context.Context, alertID string) error
+	ListEscalations(ctx context.Context) ([]EscalationState, error)
+}
+
+// OnCallAt returns the user on call at the given time
+func (s *OnCallSchedule) OnCallAt(at time.Time) *OnCallUser {
+	if len(s.Users) == 0 {
+		return nil
+	}
+	elapsed := at.Sub(s.RotationStart)
+	shift := int(elapsed / onCallRotationPeriod)
+	if elapsed < 0 && elapsed%onCallRotationPeriod != 0 {
+		shift--
+	}
+	index := shift % len(s.Users)
+	if index < 0 {
+		index += len(s.Users)
+	}
+	return &s.Users[index]
+}
+
+// SetEscalationStore enables persistence of active escalations. Set it before Start, which
+// resumes the stored escalations.
+func (am *AlertManager) SetEscalationStore(store EscalationStore) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.escalationStore = store
+}
+
+// CreateEscalationPolicy adds an escalation policy, ordering its steps by step number
+func (am *AlertManager) CreateEscalationPolicy(policy *EscalationPolicy) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if err := am.addEscalationPolicy(policy); err != nil {
+		return err
+	}
+
+	am.logger.Info(context.Background(), "Escalation policy created", map[string]interface{}{
+		"policy_id": policy.PolicyID,
+		"name":      policy.Name,
+		"steps":     len(policy.Steps),
+	})
+
+	return nil
+}
+
+// RemoveEscalationPolicy removes an escalation policy and detaches it from severities. Alerts
+// escalating through it stop at their next check.
+func (am *AlertManager) RemoveEscalationPolicy(policyID string) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if _, exists := am.escalations[policyID]; !exists {
+		return fmt.Errorf("%w: %s", ErrEscalationPolicyNotFound, policyID)
+	}
+	delete(am.escalations, policyID)
+	for severity, attached := range am.severityPolicies {
+		if attached == policyID {
+			delete(am.severityPolicies, severity)
+		}
+	}
+	return nil
+}
+
+// GetEscalationPolicies returns all escalation policies
+func (am *AlertManager) GetEscalationPolicies() []*EscalationPolicy {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	policies := make([]*EscalationPolicy, 0, len(am.escalations))
+	for _, policy := range am.escalations {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].CreatedAt.Before(policies[j].CreatedAt) })
+	return policies
+}
+
+// SetSeverityEscalationPolicy escalates alerts of severity through a policy unless their rule
+// names its own. An empty policy ID detaches the severity's policy.
+func (am *AlertManager) SetSeverityEscalationPolicy(severity AlertSeverity, policyID string) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if policyID == "" {
+		delete(am.severityPolicies, severity)
+		return nil
+	}
+	if _, exists := am.escalations[policyID]; !exists {
+		return fmt.Errorf("%w: %s", ErrEscalationPolicyNotFound, policyID)
+	}
+	am.severityPolicies[severity] = policyID
+	return nil
+}
+
+// CreateOnCallSchedule adds an on-call schedule that escalation steps can page
+func (am *AlertManager) CreateOnCallSchedule(schedule *OnCallSchedule) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if err := am.addOnCallSchedule(schedule); err != nil {
+		return err
+	}
+
+	am.logger.Info(context.Background(), "On-call schedule created", map[string]interface{}{
+		"schedule_id":    schedule.ScheduleID,
+		"name":           schedule.Name,
+		"users":          len(schedule.Users),
+		"rotation_start": schedule.RotationStart,
+	})
+
+	return nil
+}
+
+// GetOnCallSchedules returns all on-call schedules
+func (am *AlertManager) GetOnCallSchedules() []*OnCallSchedule {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	schedules := make([]*OnCallSchedule, 0, len(am.onCallSchedules))
+	for _, schedule := range am.onCallSchedules {
+		schedules = append(schedules, schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].CreatedAt.Before(schedules[j].CreatedAt) })
+	return schedules
+}
+
+// CurrentOnCall returns the user currently on call for a schedule
+func (am *AlertManager) CurrentOnCall(scheduleID string) (*OnCallUser, error) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	schedule, exists := am.onCallSchedules[scheduleID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrOnCallScheduleNotFound, scheduleID)
+	}
+	user := *schedule.OnCallAt(time.Now())
+	return &user, nil
+}
+
+// GetActiveEscalations returns the escalations still waiting on a step, oldest first
+func (am *AlertManager) GetActiveEscalations() []*EscalationState {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	states := make([]*EscalationState, 0, len(am.escalationStates))
+	for _, state := range am.escalationStates {
+		snapshot := *state
+		snapshot.Alert = *state.alert
+		states = append(states, &snapshot)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].StartedAt.Before(states[j].StartedAt) })
+	return states
+}
+
+// addEscalationPolicy validates and stores policy. The caller holds am.mu.
+func (am *AlertManager) addEscalationPolicy(policy *EscalationPolicy) error {
+	if len(policy.Steps) == 0 {
+		return fmt.Errorf("%w: add at least one step", ErrInvalidEscalationPolicy)
+	}
+	sort.SliceStable(policy.Steps, func(i, j int) bool { return policy.Steps[i].StepNumber < policy.Steps[j].StepNumber })
+	for i := range policy.Steps {
+		step := &policy.Steps[i]
+		step.StepNumber = i + 1
+		if step.Delay < 0 {
+			return fmt.Errorf("%w: step %d delay must not be negative", ErrInvalidEscalationPolicy, step.StepNumber)
+		}
+		enabled := 0
+		for _, action := range step.Actions {
+			if !action.Enabled {
+				continue
+			}
+			enabled++
+			if action.ActionType == ActionTypePageOnCall {
+				if _, exists := am.onCallSchedules[action.Target]; !exists {
+					return fmt.Errorf("%w: step %d pages unknown on-call schedule %q", ErrInvalidEscalationPolicy, step.StepNumber, action.Target)
+				}
+			}
+		}
+		if enabled == 0 {
+			return fmt.Errorf("%w: step %d has no enabled actions", ErrInvalidEscalationPolicy, step.StepNumber)
+		}
+	}
+
+	if policy.PolicyID == "" {
+		policy.PolicyID = uuid.New().String()
+	}
+	now := time.Now()
+	if policy.CreatedAt.IsZero() {
+		policy.CreatedAt = now
+	}
+	policy.UpdatedAt = now
+	am.escalations[policy.PolicyID] = policy
+	return nil
+}
+
+// addOnCallSchedule validates and stores schedule. The caller holds am.mu.
+func (am *AlertManager) addOnCallSchedule(schedule *OnCallSchedule) error {
+	if len(schedule.Users) == 0 {
+		return fmt.Errorf("%w: add at least one user", ErrInvalidOnCallSchedule)
+	}
+	for _, user := range schedule.Users {
+		reachable := false
+		for _, preference := range user.NotificationPreferences {
+			if preference.ActionType == ActionTypePageOnCall {
+				return fmt.Errorf("%w: %s cannot be paged through another schedule", ErrInvalidOnCallSchedule, user.Name)
+			}
+			reachable = reachable || preference.Enabled
+		}
+		if !reachable {
+			return fmt.Errorf("%w: %s has no enabled notification preferences", ErrInvalidOnCallSchedule, user.Name)
+		}
+	}
+
+	if schedule.ScheduleID == "" {
+		schedule.ScheduleID = uuid.New().String()
+	}
+	now := time.Now()
+	if schedule.RotationStart.IsZero() {
+		schedule.RotationStart = now
+	}
+	if schedule.CreatedAt.IsZero() {
+		schedule.CreatedAt = now
+	}
+	am.onCallSchedules[schedule.ScheduleID] = schedule
+	return nil
+}
+
+// policyFor returns the escalation policy of an alert: its rule's, or else its severity's.
+// The caller holds am.mu.
+func (am *AlertManager) policyFor(alert *Alert, rule *AlertRule) *EscalationPolicy {
+	policy, exists := am.escalations[rule.EscalationPolicy]
+	if !exists {
+		policy, exists = am.escalations[am.severityPolicies[alert.Severity]]
+	}
+	if !exists || !policy.Enabled {
+		return nil
+	}
+	return policy
+}
+
+// startEscalation starts escalating a new or reactivated alert, running the steps that are
+// due at once. The caller holds am.mu.
+func (am *AlertManager) startEscalation(alert *Alert, rule *AlertRule) {
+	if _, escalating := am.escalationStates[alert.AlertID]; escalating {
+		return
+	}
+	policy := am.policyFor(alert, rule)
+	if policy == nil {
+		return
+	}
+
+	now := time.Now()
+	state := &EscalationState{
+		AlertID:    alert.AlertID,
+		PolicyID:   policy.PolicyID,
+		StartedAt:  now,
+		NextStepAt: now.Add(policy.Steps[0].Delay),
+		alert:      alert,
+	}
+	am.escalationStates[alert.AlertID] = state
+
+	am.logger.Info(context.Background(), "Escalation started", map[string]interface{}{
+		"alert_id":  alert.AlertID,
+		"policy_id": policy.PolicyID,
+		"rule_name": alert.RuleName,
+	})
+
+	am.runEscalation(state, policy, now)
+}
+
+// stopEscalation ends an alert's escalation once it is acknowledged or resolved. The caller
+// holds am.mu.
+func (am *AlertManager) stopEscalation(alert *Alert, reason string) {
+	state, exists := am.escalationStates[alert.AlertID]
+	if !exists {
+		return
+	}
+	delete(am.escalationStates, alert.AlertID)
+	am.deleteEscalation(alert.AlertID)
+
+	am.logger.Info(context.Background(), "Escalation stopped", map[string]interface{}{
+		"alert_id":    alert.AlertID,
+		"policy_id":   state.PolicyID,
+		"reason":      reason,
+		"steps_taken": state.NextStep,
+	})
+}
+
+// advanceEscalations runs the escalation steps due by now
+func (am *AlertManager) advanceEscalations(now time.Time) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	for alertID, state := range am.escalationStates {
+		policy, exists := am.escalations[state.PolicyID]
+		if !exists || !policy.Enabled {
+			delete(am.escalationStates, alertID)
+			am.deleteEscalation(alertID)
+			am.logger.Warn(context.Background(), "Escalation policy no longer applies", map[string]interface{}{
+				"alert_id":  alertID,
+				"policy_id": state.PolicyID,
+			})
+			continue
+		}
+		am.runEscalation(state, policy, now)
+	}
+}
+
+// runEscalation runs the steps of state due by now and persists its progress. Each step is
+// due its delay after the previous one was. The caller holds am.mu.
+func (am *AlertManager) runEscalation(state *EscalationState, policy *EscalationPolicy, now time.Time) {
+	progressed := state.UpdatedAt.IsZero()
+	for state.NextStep < len(policy.Steps) && !now.Before(state.NextStepAt) {
+		am.runEscalationStep(state.alert, policy.Steps[state.NextStep], now)
+		state.NextStep++
+		if state.NextStep < len(policy.Steps) {
+			state.NextStepAt = state.NextStepAt.Add(policy.Steps[state.NextStep].Delay)
+		}
+		progressed = true
+	}
+
+	if state.NextStep >= len(policy.Steps) {
+		delete(am.escalationStates, state.AlertID)
+		am.deleteEscalation(state.AlertID)
+		am.logger.Warn(context.Background(), "Escalation policy exhausted", map[string]interface{}{
+			"alert_id":  state.AlertID,
+			"policy_id": policy.PolicyID,
+		})
+		return
+	}
+	if progressed {
+		am.saveEscalation(state)
+	}
+}
+
+// runEscalationStep notifies the targets of an escalation step. The caller holds am.mu.
+func (am *AlertManager) runEscalationStep(alert *Alert, step EscalationStep, now time.Time) {
+	alert.Escalated = true
+	alert.EscalationLevel = step.StepNumber
+
+	am.logger.Warn(context.Background(), "Alert escalated", map[string]interface{}{
+		"alert_id":         alert.AlertID,
+		"rule_name":        alert.RuleName,
+		"escalation_level": alert.EscalationLevel,
+	})
+
+	for _, action := range step.Actions {
+		if !action.Enabled {
+			continue
+		}
+		if action.ActionType == ActionTypePageOnCall {
+			am.pageOnCall(alert, action, now)
+			continue
+		}
+		message := fmt.Sprintf("🚨 ESCALATED: %s (Level %d)", alert.Message, alert.EscalationLevel)
+		am.queueEscalationNotification(alert, action, message, nil)
+	}
+}
+
+// pageOnCall notifies whoever is on call for the action's schedule through each of their
+// notification preferences. The caller holds am.mu.
+func (am *AlertManager) pageOnCall(alert *Alert, action AlertAction, now time.Time) {
+	schedule, exists := am.onCallSchedules[action.Target]
+	if !exists {
+		am.logger.Warn(context.Background(), "Cannot page unknown on-call schedule", map[string]interface{}{
+			"alert_id":    alert.AlertID,
+			"schedule_id": action.Target,
+		})
+		return
+	}
+
+	user := schedule.OnCallAt(now)
+	message := fmt.Sprintf("📟 PAGE for %s: %s (Level %d)", user.Name, alert.Message, alert.EscalationLevel)
+	metadata := map[string]interface{}{
+		"schedule_id":  schedule.ScheduleID,
+		"on_call_user": user.UserID,
+	}
+	for _, preference := range user.NotificationPreferences {
+		if preference.Enabled {
+			am.queueEscalationNotification(alert, preference, message, metadata)
+		}
+	}
+}
+
+// queueEscalationNotification queues a critical notification of alert. The caller holds am.mu.
+func (am *AlertManager) queueEscalationNotification(alert *Alert, action AlertAction, message string, extra map[string]interface{}) {
+	metadata := make(map[string]interface{}, len(action.Parameters)+len(extra)+1)
+	for name, value := range action.Parameters {
+		metadata[name] = value
+	}
+	for name, value := range extra {
+		metadata[name] = value
+	}
+	metadata["escalation_level"] = alert.EscalationLevel
+
+	notification := &AlertNotification{
+		NotificationID: uuid.New().String(),
+		AlertID:        alert.AlertID,
+		ActionType:     action.ActionType,
+		Target:         action.Target,
+		Message:        message,
+		Priority:       PriorityCritical,
+		ScheduledAt:    time.Now(),
+		Status:         NotificationStatusPending,
+		MaxRetries:     3,
+		Metadata:       metadata,
+	}
+
+	select {
+	case am.notifications <- notification:
+		alert.NotificationsSent = append(alert.NotificationsSent, *notification)
+	default:
+		am.logger.Warn(context.Background(), "Escalation notification queue full", map[string]interface{}{
+			"alert_id":    alert.AlertID,
+			"action_type": action.ActionType,
+		})
+	}
+}
+
+// restoreEscalations resumes the stored escalations, restoring alerts that were lost with the
+// previous process so they can still be acknowledged
+func (am *AlertManager) restoreEscalations(ctx context.Context) {
+	if am.escalationStore == nil {
+		return
+	}
+	states, err := am.escalationStore.ListEscalations(ctx)
+	if err != nil {
+		am.logger.Error(ctx, "Failed to restore escalations", err)
+		return
+	}
+
+	am.mu.Lock()
+	for i := range states {
+		state := &states[i]
+		var alert *Alert
+		for _, active := range am.activeAlerts {
+			if active.AlertID == state.AlertID {
+				alert = active
+				break
+			}
+		}
+		if alert == nil {
+			restored := state.Alert
+			alert = &restored
+			if alert.Context == nil {
+				alert.Context = make(map[string]interface{})
+			}
+			alert.NotificationsSent = make([]AlertNotification, 0)
+			am.activeAlerts[fmt.Sprintf("%s_%s", alert.RuleID, alert.MetricName)] = alert
+			am.alertHistory = append(am.alertHistory, alert)
+		}
+		state.alert = alert
+		am.escalationStates[state.AlertID] = state
+	}
+	am.mu.Unlock()
+
+	if len(states) > 0 {
+		am.logger.Info(ctx, "Escalations restored", map[string]interface{}{
+			"count": len(states),
+		})
+	}
+	am.advanceEscalations(time.Now())
+}
+
+// saveEscalation persists an escalation with a snapshot of its alert. The caller holds am.mu.
+func (am *AlertManager) saveEscalation(state *EscalationState) {
+	state.UpdatedAt = time.Now()
+	state.Alert = *state.alert
+	state.Alert.NotificationsSent = nil
+	if am.escalationStore == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), escalationStoreTimeout)
+	defer cancel()
+	if err := am.escalationStore.SaveEscalation(ctx, state); err != nil {
+		am.logger.Error(ctx, "Failed to persist escalation", err, map[string]interface{}{
+			"alert_id": state.AlertID,
+		})
+	}
+}
+
+// deleteEscalation removes a finished escalation from the store. The caller holds am.mu.
+func (am *AlertManager) deleteEscalation(alertID string) {
+	if am.escalationStore == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), escalationStoreTimeout)
+	defer cancel()
+	if err := am.escalationStore.DeleteEscalation(ctx, alertID); err != nil {
+		am.logger.Error(ctx, "Failed to delete escalation", err, map[string]interface{}{
+			"alert_id": alertID,
+		})
+	}
+}
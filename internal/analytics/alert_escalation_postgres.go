@@ -0,0 +1,68 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// postgresEscalationStore implements EscalationStore using the alert_escalations table
+type postgresEscalationStore struct {
+	db *database.DB
+}
+
+func NewPostgresEscalationStore(db *database.DB) EscalationStore {
+	return &postgresEscalationStore{db: db}
+}
+
+func (s *postgresEscalationStore) SaveEscalation(ctx context.Context, state *EscalationState) error {
+	alert, err := json.Marshal(state.Alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	query := `
+		INSERT INTO alert_escalations (alert_id, policy_id, next_step, started_at, next_step_at, alert, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (alert_id) DO UPDATE SET
+			policy_id = EXCLUDED.policy_id,
+			next_step = EXCLUDED.next_step,
+			next_step_at = EXCLUDED.next_step_at,
+			alert = EXCLUDED.alert,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = s.db.ExecWithMetrics(ctx, query, state.AlertID, state.PolicyID, state.NextStep, state.StartedAt, state.NextStepAt, alert, state.UpdatedAt)
+	return err
+}
+
+func (s *postgresEscalationStore) DeleteEscalation(ctx context.Context, alertID string) error {
+	_, err := s.db.ExecWithMetrics(ctx, `DELETE FROM alert_escalations WHERE alert_id = $1`, alertID)
+	return err
+}
+
+func (s *postgresEscalationStore) ListEscalations(ctx context.Context) ([]EscalationState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT alert_id, policy_id, next_step, started_at, next_step_at, alert, updated_at FROM alert_escalations
+		ORDER BY started_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make([]EscalationState, 0)
+	for rows.Next() {
+		var state EscalationState
+		var alert []byte
+		if err := rows.Scan(&state.AlertID, &state.PolicyID, &state.NextStep, &state.StartedAt, &state.NextStepAt, &alert, &state.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan escalation: %w", err)
+		}
+		if err := json.Unmarshal(alert, &state.Alert); err != nil {
+			return nil, fmt.Errorf("failed to decode alert: %w", err)
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
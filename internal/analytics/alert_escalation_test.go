@@ -0,0 +1,275 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryEscalationStore is an in-memory EscalationStore for tests
+type memoryEscalationStore struct {
+	mu     sync.Mutex
+	states map[string]EscalationState
+}
+
+func newMemoryEscalationStore() *memoryEscalationStore {
+	return &memoryEscalationStore{states: make(map[string]EscalationState)}
+}
+
+func (s *memoryEscalationStore) SaveEscalation(ctx context.Context, state *EscalationState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.AlertID] = *state
+	return nil
+}
+
+func (s *memoryEscalationStore) DeleteEscalation(ctx context.Context, alertID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, alertID)
+	return nil
+}
+
+func (s *memoryEscalationStore) ListEscalations(ctx context.Context) ([]EscalationState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]EscalationState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+var rotationStart = time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC) // a Monday
+
+func testOnCallSchedule() *OnCallSchedule {
+	return &OnCallSchedule{
+		ScheduleID: "primary",
+		Name:       "Primary",
+		Users: []OnCallUser{
+			{UserID: "alice", Name: "Alice", NotificationPreferences: []AlertAction{
+				{ActionType: ActionTypeSMS, Target: "+15550001", Enabled: true},
+				{ActionType: ActionTypeEmail, Target: "alice@example.com", Enabled: true},
+			}},
+			{UserID: "bob", Name: "Bob", NotificationPreferences: []AlertAction{
+				{ActionType: ActionTypePagerDuty, Target: "bob-pd", Enabled: true},
+			}},
+			{UserID: "carol", Name: "Carol", NotificationPreferences: []AlertAction{
+				{ActionType: ActionTypeSlack, Target: "@carol", Enabled: true},
+			}},
+		},
+		RotationStart: rotationStart,
+	}
+}
+
+// testEscalationPolicy emails the team, then after 5 minutes posts to Slack, then after 10
+// more pages the on-call user
+func testEscalationPolicy() *EscalationPolicy {
+	return &EscalationPolicy{
+		PolicyID: "critical",
+		Name:     "Critical",
+		Enabled:  true,
+		Steps: []EscalationStep{
+			{StepNumber: 2, Delay: 5 * time.Minute, Actions: []AlertAction{{ActionType: ActionTypeSlack, Target: "#incidents", Enabled: true}}},
+			{StepNumber: 1, Actions: []AlertAction{{ActionType: ActionTypeEmail, Target: "team@example.com", Enabled: true}}},
+			{StepNumber: 3, Delay: 10 * time.Minute, Actions: []AlertAction{{ActionType: ActionTypePageOnCall, Target: "primary", Enabled: true}}},
+		},
+	}
+}
+
+func newTestEscalationConfig() *AnalyticsConfig {
+	return &AnalyticsConfig{
+		IncidentGroupWait:          -1,
+		OnCallSchedules:            []*OnCallSchedule{testOnCallSchedule()},
+		EscalationPolicies:         []*EscalationPolicy{testEscalationPolicy()},
+		SeverityEscalationPolicies: map[AlertSeverity]string{SeverityCritical: "critical"},
+	}
+}
+
+// drainNotifications empties the notification queue, returning the queued notifications
+func drainNotifications(am *AlertManager) []*AlertNotification {
+	var notifications []*AlertNotification
+	for {
+		select {
+		case notification := <-am.notifications:
+			notifications = append(notifications, notification)
+		default:
+			return notifications
+		}
+	}
+}
+
+func TestOnCallRotation(t *testing.T) {
+	schedule := testOnCallSchedule()
+	tests := []struct {
+		at   time.Time
+		user string
+	}{
+		{rotationStart, "alice"},
+		{rotationStart.Add(7*24*time.Hour - time.Minute), "alice"},
+		{rotationStart.AddDate(0, 0, 7), "bob"},
+		{rotationStart.AddDate(0, 0, 15), "carol"},
+		{rotationStart.AddDate(0, 0, 21), "alice"},
+		{rotationStart.Add(-time.Hour), "carol"},
+	}
+	for _, tt := range tests {
+		if user := schedule.OnCallAt(tt.at); user.UserID != tt.user {
+			t.Errorf("Expected %s on call at %s, got %s", tt.user, tt.at, user.UserID)
+		}
+	}
+}
+
+func TestEscalationStepsUntilAcknowledged(t *testing.T) {
+	am := newTestAlertManager(t, newTestEscalationConfig())
+
+	am.EvaluateMetric("error_rate", 12, map[string]string{"host": "server1"})
+	alert := am.GetActiveAlerts()[0]
+	queued := drainNotifications(am)
+	if len(queued) != 2 || queued[1].Target != "team@example.com" || queued[1].Priority != PriorityCritical {
+		t.Fatalf("Expected the rule's notification and the first step's email, got %+v", queued)
+	}
+
+	am.advanceEscalations(alert.TriggeredAt.Add(4 * time.Minute))
+	if queued := drainNotifications(am); len(queued) != 0 {
+		t.Errorf("Expected no escalation before the delay, got %d notifications", len(queued))
+	}
+	am.advanceEscalations(alert.TriggeredAt.Add(6 * time.Minute))
+	queued = drainNotifications(am)
+	if len(queued) != 1 || queued[0].Target != "#incidents" || alert.EscalationLevel != 2 {
+		t.Fatalf("Expected step 2 to post to Slack, got %+v at level %d", queued, alert.EscalationLevel)
+	}
+
+	if err := am.AcknowledgeAlert(alert.AlertID, "alice"); err != nil {
+		t.Fatalf("Failed to acknowledge alert: %v", err)
+	}
+	am.advanceEscalations(alert.TriggeredAt.Add(time.Hour))
+	if queued := drainNotifications(am); len(queued) != 0 {
+		t.Errorf("Expected acknowledgment to stop escalation, got %d notifications", len(queued))
+	}
+	if active := am.GetActiveEscalations(); len(active) != 0 {
+		t.Errorf("Expected no active escalations, got %d", len(active))
+	}
+}
+
+func TestEscalationPagesCurrentOnCall(t *testing.T) {
+	am := newTestAlertManager(t, newTestEscalationConfig())
+	am.EvaluateMetric("error_rate", 12, nil)
+	alert := am.GetActiveAlerts()[0]
+	drainNotifications(am)
+
+	// The page goes out in the rotation's second week, so Bob is on call
+	now := rotationStart.AddDate(0, 0, 8)
+	am.mu.Lock()
+	am.escalationStates[alert.AlertID].NextStepAt = now.Add(-10 * time.Minute)
+	am.mu.Unlock()
+	am.advanceEscalations(now)
+
+	queued := drainNotifications(am)
+	if len(queued) != 2 {
+		t.Fatalf("Expected Slack and the page, got %d notifications", len(queued))
+	}
+	page := queued[1]
+	if page.ActionType != ActionTypePagerDuty || page.Target != "bob-pd" || page.Metadata["on_call_user"] != "bob" {
+		t.Errorf("Expected Bob paged through PagerDuty, got %+v", page)
+	}
+	if active := am.GetActiveEscalations(); len(active) != 0 {
+		t.Errorf("Expected the escalation to end after its last step, got %d", len(active))
+	}
+}
+
+func TestRuleEscalationPolicyOverridesSeverity(t *testing.T) {
+	am := newTestAlertManager(t, newTestEscalationConfig())
+	if err := am.CreateEscalationPolicy(&EscalationPolicy{PolicyID: "latency", Enabled: true, Steps: []EscalationStep{
+		{Delay: time.Minute, Actions: []AlertAction{{ActionType: ActionTypeWebhook, Target: "https://hooks.example.com", Enabled: true}}},
+	}}); err != nil {
+		t.Fatalf("Failed to create escalation policy: %v", err)
+	}
+	am.alertRules["errors"].EscalationPolicy = "latency"
+
+	am.EvaluateMetric("error_rate", 12, nil)
+	am.EvaluateMetric("cpu_usage", 95, nil)
+
+	active := am.GetActiveEscalations()
+	if len(active) != 1 || active[0].PolicyID != "latency" {
+		t.Errorf("Expected only the error rate alert escalating through its rule's policy, got %+v", active)
+	}
+}
+
+func TestEscalationPolicyValidation(t *testing.T) {
+	am := newTestAlertManager(t, &AnalyticsConfig{})
+	policies := []*EscalationPolicy{
+		{Name: "No steps", Enabled: true},
+		{Name: "Negative delay", Enabled: true, Steps: []EscalationStep{{Delay: -time.Minute, Actions: []AlertAction{{ActionType: ActionTypeEmail, Target: "team@example.com", Enabled: true}}}}},
+		{Name: "No actions", Enabled: true, Steps: []EscalationStep{{Actions: []AlertAction{{ActionType: ActionTypeEmail, Target: "team@example.com"}}}}},
+		{Name: "Unknown schedule", Enabled: true, Steps: []EscalationStep{{Actions: []AlertAction{{ActionType: ActionTypePageOnCall, Target: "primary", Enabled: true}}}}},
+	}
+	for _, policy := range policies {
+		if err := am.CreateEscalationPolicy(policy); !errors.Is(err, ErrInvalidEscalationPolicy) {
+			t.Errorf("%s: expected ErrInvalidEscalationPolicy, got %v", policy.Name, err)
+		}
+	}
+
+	if err := am.CreateOnCallSchedule(&OnCallSchedule{Name: "Empty"}); !errors.Is(err, ErrInvalidOnCallSchedule) {
+		t.Errorf("Expected ErrInvalidOnCallSchedule, got %v", err)
+	}
+	if err := am.SetSeverityEscalationPolicy(SeverityCritical, "missing"); !errors.Is(err, ErrEscalationPolicyNotFound) {
+		t.Errorf("Expected ErrEscalationPolicyNotFound, got %v", err)
+	}
+	if _, err := am.CurrentOnCall("missing"); !errors.Is(err, ErrOnCallScheduleNotFound) {
+		t.Errorf("Expected ErrOnCallScheduleNotFound, got %v", err)
+	}
+}
+
+func TestEscalationSurvivesRestart(t *testing.T) {
+	store := newMemoryEscalationStore()
+	before := newTestAlertManager(t, newTestEscalationConfig())
+	before.SetEscalationStore(store)
+	before.EvaluateMetric("error_rate", 12, map[string]string{"host": "server1"})
+	alert := before.GetActiveAlerts()[0]
+
+	stored, _ := store.ListEscalations(context.Background())
+	if len(stored) != 1 || stored[0].NextStep != 1 || stored[0].Alert.RuleName != "High error rate" {
+		t.Fatalf("Expected the escalation persisted after its first step, got %+v", stored)
+	}
+
+	after := newTestAlertManager(t, newTestEscalationConfig())
+	after.SetEscalationStore(store)
+	after.restoreEscalations(context.Background())
+
+	active := after.GetActiveEscalations()
+	if len(active) != 1 || active[0].AlertID != alert.AlertID {
+		t.Fatalf("Expected the escalation restored, got %+v", active)
+	}
+	after.advanceEscalations(alert.TriggeredAt.Add(6 * time.Minute))
+	if queued := drainNotifications(after); len(queued) != 1 || queued[0].Target != "#incidents" {
+		t.Errorf("Expected the restored escalation to continue with step 2, got %+v", queued)
+	}
+	if stored, _ := store.ListEscalations(context.Background()); stored[0].NextStep != 2 {
+		t.Errorf("Expected the progress persisted, got step %d", stored[0].NextStep)
+	}
+
+	if err := after.AcknowledgeAlert(alert.AlertID, "bob"); err != nil {
+		t.Fatalf("Failed to acknowledge restored alert: %v", err)
+	}
+	if stored, _ := store.ListEscalations(context.Background()); len(stored) != 0 {
+		t.Errorf("Expected acknowledgment to remove the stored escalation, got %d", len(stored))
+	}
+}
+
+func TestResolvedAlertStopsEscalation(t *testing.T) {
+	store := newMemoryEscalationStore()
+	am := newTestAlertManager(t, newTestEscalationConfig())
+	am.SetEscalationStore(store)
+
+	am.EvaluateMetric("error_rate", 12, nil)
+	am.EvaluateMetric("error_rate", 1, nil)
+
+	if active := am.GetActiveEscalations(); len(active) != 0 {
+		t.Errorf("Expected no active escalations, got %d", len(active))
+	}
+	if stored, _ := store.ListEscalations(context.Background()); len(stored) != 0 {
+		t.Errorf("Expected the stored escalation removed, got %d", len(stored))
+	}
+}
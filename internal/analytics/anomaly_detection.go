@@ -20,9 +20,13 @@ type AnomalyDetector struct {
 	anomalies       []*Anomaly
 	alertThresholds map[string]*AnomalyThreshold
 	baselineModels  map[string]*BaselineModel
+	handlers        []AnomalyHandler
 	mu              sync.RWMutex
 }
 
+// AnomalyHandler is called for every detected anomaly, after it has been recorded
+type AnomalyHandler func(anomaly *Anomaly)
+
 // MetricDetector detects anomalies for a specific metric
 type MetricDetector struct {
 	MetricName      string                 `json:"metric_name"`
@@ -33,6 +37,9 @@ type MetricDetector struct {
 	Statistics      *MetricStatistics      `json:"statistics"`
 	LastUpdated     time.Time              `json:"last_updated"`
 	mu              sync.RWMutex           `json:"-"`
+
+	// Limit flags any value above it, even before enough history exists; zero disables it
+	Limit float64 `json:"limit,omitempty"`
 }
 
 // AnomalyDetectionMethod defines detection methods
@@ -46,6 +53,7 @@ const (
 	DetectionMethodMovingAverage   AnomalyDetectionMethod = "moving_average"
 	DetectionMethodSeasonal        AnomalyDetectionMethod = "seasonal"
 	DetectionMethodML              AnomalyDetectionMethod = "machine_learning"
+	DetectionMethodLimit           AnomalyDetectionMethod = "limit"
 )
 
 // DataPoint represents a single data point
@@ -186,6 +194,36 @@ func (ad *AnomalyDetector) RegisterMetricDetector(metricName string, method Anom
 	})
 }
 
+// SetMetricLimit flags values of a metric above limit regardless of its history
+func (ad *AnomalyDetector) SetMetricLimit(metricName string, limit float64) {
+	ad.mu.RLock()
+	detector, exists := ad.detectors[metricName]
+	ad.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	detector.mu.Lock()
+	defer detector.mu.Unlock()
+	detector.Limit = limit
+}
+
+// HasMetricDetector reports whether a detector is registered for a metric
+func (ad *AnomalyDetector) HasMetricDetector(metricName string) bool {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+	_, exists := ad.detectors[metricName]
+	return exists
+}
+
+// OnAnomaly registers a handler for anomalies detected from now on. Handlers run on the
+// goroutine that added the data point and must not block.
+func (ad *AnomalyDetector) OnAnomaly(handler AnomalyHandler) {
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	ad.handlers = append(ad.handlers, handler)
+}
+
 // AddDataPoint adds a data point for anomaly detection
 func (ad *AnomalyDetector) AddDataPoint(metricName string, value float64, tags map[string]string) {
 	ad.mu.RLock()
@@ -200,15 +238,43 @@ func (ad *AnomalyDetector) AddDataPoint(metricName string, value float64, tags m
 		ad.mu.RUnlock()
 	}
 
-	detector.mu.Lock()
-	defer detector.mu.Unlock()
-
 	dataPoint := DataPoint{
 		Timestamp: time.Now(),
 		Value:     value,
 		Tags:      tags,
 	}
 
+	anomaly := ad.addToDetector(detector, dataPoint)
+	if anomaly == nil {
+		return
+	}
+
+	ad.mu.Lock()
+	ad.anomalies = append(ad.anomalies, anomaly)
+	handlers := ad.handlers
+	ad.mu.Unlock()
+
+	ad.logger.Warn(context.Background(), "Anomaly detected", map[string]interface{}{
+		"anomaly_id":  anomaly.AnomalyID,
+		"metric_name": anomaly.MetricName,
+		"value":       anomaly.Value,
+		"expected":    anomaly.ExpectedValue,
+		"deviation":   anomaly.Deviation,
+		"severity":    anomaly.Severity,
+		"confidence":  anomaly.Confidence,
+	})
+
+	for _, handler := range handlers {
+		handler(anomaly)
+	}
+}
+
+// addToDetector appends a data point to a detector's window and returns the anomaly it
+// represents, if any
+func (ad *AnomalyDetector) addToDetector(detector *MetricDetector, dataPoint DataPoint) *Anomaly {
+	detector.mu.Lock()
+	defer detector.mu.Unlock()
+
 	// Add data point to window
 	detector.DataPoints = append(detector.DataPoints, dataPoint)
 
@@ -219,27 +285,15 @@ func (ad *AnomalyDetector) AddDataPoint(metricName string, value float64, tags m
 
 	// Update statistics
 	ad.updateStatistics(detector)
+	detector.LastUpdated = time.Now()
 
 	// Check for anomalies
 	if len(detector.DataPoints) >= 10 { // Minimum data points for detection
 		if anomaly := ad.detectAnomaly(detector, dataPoint); anomaly != nil {
-			ad.mu.Lock()
-			ad.anomalies = append(ad.anomalies, anomaly)
-			ad.mu.Unlock()
-
-			ad.logger.Warn(context.Background(), "Anomaly detected", map[string]interface{}{
-				"anomaly_id":  anomaly.AnomalyID,
-				"metric_name": anomaly.MetricName,
-				"value":       anomaly.Value,
-				"expected":    anomaly.ExpectedValue,
-				"deviation":   anomaly.Deviation,
-				"severity":    anomaly.Severity,
-				"confidence":  anomaly.Confidence,
-			})
+			return anomaly
 		}
 	}
-
-	detector.LastUpdated = time.Now()
+	return ad.detectLimitAnomaly(detector, dataPoint)
 }
 
 // detectAnomaly detects if a data point is anomalous
@@ -258,6 +312,33 @@ func (ad *AnomalyDetector) detectAnomaly(detector *MetricDetector, dataPoint Dat
 	}
 }
 
+// detectLimitAnomaly flags a data point above the detector's fixed limit
+func (ad *AnomalyDetector) detectLimitAnomaly(detector *MetricDetector, dataPoint DataPoint) *Anomaly {
+	if detector.Limit <= 0 || dataPoint.Value <= detector.Limit {
+		return nil
+	}
+
+	ratio := dataPoint.Value / detector.Limit
+	return &Anomaly{
+		AnomalyID:       uuid.New().String(),
+		MetricName:      detector.MetricName,
+		DetectionMethod: DetectionMethodLimit,
+		Timestamp:       dataPoint.Timestamp,
+		Value:           dataPoint.Value,
+		ExpectedValue:   detector.Statistics.Mean,
+		Deviation:       dataPoint.Value - detector.Limit,
+		Severity:        ad.calculateSeverity(detector.MetricName, ratio, 0.5),
+		Confidence:      1.0,
+		Description:     fmt.Sprintf("Limit exceeded: value %.4f above %.4f", dataPoint.Value, detector.Limit),
+		Context: map[string]interface{}{
+			"limit": detector.Limit,
+			"mean":  detector.Statistics.Mean,
+		},
+		Tags:   dataPoint.Tags,
+		Status: AnomalyStatusActive,
+	}
+}
+
 // detectZScoreAnomaly detects anomalies using Z-score method
 func (ad *AnomalyDetector) detectZScoreAnomaly(detector *MetricDetector, dataPoint DataPoint) *Anomaly {
 	stats := detector.Statistics
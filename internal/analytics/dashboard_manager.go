@@ -19,7 +19,20 @@ type DashboardManager struct {
 	widgets    map[string]*Widget
 	layouts    map[string]*DashboardLayout
 	themes     map[string]*DashboardTheme
-	mu         sync.RWMutex
+
+	// Correlation matrix and heatmap widgets are computed from recorded metrics and candles
+	metricSeries map[string][]DataPoint
+	candles      BenchmarkCandleSource
+	widgetCache  map[string]cachedWidgetData
+	cacheMu      sync.Mutex
+
+	// Share links are signed with shareKey and revoked server-side, and kept in shareStore
+	// when one is set
+	shares     map[string]*DashboardShare
+	shareKey   []byte
+	shareStore DashboardShareStore
+
+	mu sync.RWMutex
 }
 
 // Dashboard represents a real-time dashboard
@@ -40,11 +53,15 @@ type Dashboard struct {
 	UpdatedAt   time.Time              `json:"updated_at"`
 	LastViewed  *time.Time             `json:"last_viewed,omitempty"`
 	ViewCount   int64                  `json:"view_count"`
+	SharedViews int64                  `json:"shared_views"`
 	IsPublic    bool                   `json:"is_public"`
 	IsTemplate  bool                   `json:"is_template"`
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
+// SystemOverviewDashboardID is the ID of the default system overview dashboard
+const SystemOverviewDashboardID = "system-overview"
+
 // DashboardCategory defines dashboard categories
 type DashboardCategory string
 
@@ -173,6 +190,9 @@ const (
 	DataSourceTypeAnomalies   DataSourceType = "anomalies"
 	DataSourceTypePredictions DataSourceType = "predictions"
 	DataSourceTypeCustom      DataSourceType = "custom"
+	// Computed server-side from metric and symbol series
+	DataSourceTypeCorrelationMatrix DataSourceType = "correlation_matrix"
+	DataSourceTypeHeatmap           DataSourceType = "heatmap"
 )
 
 // TimeRange defines time range for data
@@ -296,6 +316,10 @@ func NewDashboardManager(logger *observability.Logger, config *AnalyticsConfig)
 		widgets:    make(map[string]*Widget),
 		layouts:    make(map[string]*DashboardLayout),
 		themes:     make(map[string]*DashboardTheme),
+
+		metricSeries: make(map[string][]DataPoint),
+		widgetCache:  make(map[string]cachedWidgetData),
+		shares:       make(map[string]*DashboardShare),
 	}
 
 	// Initialize default themes
@@ -325,6 +349,11 @@ func (dm *DashboardManager) CreateDashboard(dashboard *Dashboard) error {
 	if dashboard.DashboardID == "" {
 		dashboard.DashboardID = uuid.New().String()
 	}
+	for _, widget := range dashboard.Widgets {
+		if widget.WidgetID == "" {
+			widget.WidgetID = uuid.New().String()
+		}
+	}
 
 	dashboard.CreatedAt = time.Now()
 	dashboard.UpdatedAt = time.Now()
@@ -348,7 +377,7 @@ func (dm *DashboardManager) GetDashboard(dashboardID string) (*Dashboard, error)
 
 	dashboard, exists := dm.dashboards[dashboardID]
 	if !exists {
-		return nil, fmt.Errorf("dashboard not found: %s", dashboardID)
+		return nil, fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboardID)
 	}
 
 	// Update view statistics
@@ -393,7 +422,7 @@ func (dm *DashboardManager) UpdateDashboard(dashboard *Dashboard) error {
 	defer dm.mu.Unlock()
 
 	if _, exists := dm.dashboards[dashboard.DashboardID]; !exists {
-		return fmt.Errorf("dashboard not found: %s", dashboard.DashboardID)
+		return fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboard.DashboardID)
 	}
 
 	dashboard.UpdatedAt = time.Now()
@@ -413,7 +442,7 @@ func (dm *DashboardManager) DeleteDashboard(dashboardID string) error {
 	defer dm.mu.Unlock()
 
 	if _, exists := dm.dashboards[dashboardID]; !exists {
-		return fmt.Errorf("dashboard not found: %s", dashboardID)
+		return fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboardID)
 	}
 
 	delete(dm.dashboards, dashboardID)
@@ -496,7 +525,9 @@ func (dm *DashboardManager) initializeDefaultThemes() {
 // initializeDefaultDashboards initializes default dashboards
 func (dm *DashboardManager) initializeDefaultDashboards() {
 	// System Overview Dashboard
+	// A stable ID keeps the dashboard's share links valid across restarts
 	systemDashboard := &Dashboard{
+		DashboardID: SystemOverviewDashboardID,
 		Name:        "System Overview",
 		Description: "Real-time system performance and health metrics",
 		Category:    CategoryOverview,
@@ -674,18 +705,30 @@ func (dm *DashboardManager) GetDashboardMetrics() map[string]interface{} {
 	defer dm.mu.RUnlock()
 
 	totalViews := int64(0)
+	totalSharedViews := int64(0)
 	categoryCount := make(map[DashboardCategory]int)
 
 	for _, dashboard := range dm.dashboards {
 		totalViews += dashboard.ViewCount
+		totalSharedViews += dashboard.SharedViews
 		categoryCount[dashboard.Category]++
 	}
 
+	now := time.Now()
+	activeShares := 0
+	for _, share := range dm.shares {
+		if share.RevokedAt == nil && now.Before(share.ExpiresAt) {
+			activeShares++
+		}
+	}
+
 	return map[string]interface{}{
-		"total_dashboards": len(dm.dashboards),
-		"total_views":      totalViews,
-		"category_count":   categoryCount,
-		"total_widgets":    len(dm.widgets),
-		"total_themes":     len(dm.themes),
+		"total_dashboards":   len(dm.dashboards),
+		"total_views":        totalViews,
+		"total_shared_views": totalSharedViews,
+		"active_shares":      activeShares,
+		"category_count":     categoryCount,
+		"total_widgets":      len(dm.widgets),
+		"total_themes":       len(dm.themes),
 	}
 }
@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// postgresShareStore implements DashboardShareStore using the dashboard_shares table
+type postgresShareStore struct {
+	db *database.DB
+}
+
+func NewPostgresShareStore(db *database.DB) DashboardShareStore {
+	return &postgresShareStore{db: db}
+}
+
+func (s *postgresShareStore) SaveShare(ctx context.Context, share *DashboardShare) error {
+	scope, err := json.Marshal(share.Scope)
+	if err != nil {
+		return fmt.Errorf("failed to encode share scope: %w", err)
+	}
+
+	query := `
+		INSERT INTO dashboard_shares (share_id, dashboard_id, scope, created_by, created_at, expires_at, revoked_at, view_count, last_viewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (share_id) DO UPDATE SET
+			revoked_at = EXCLUDED.revoked_at,
+			view_count = EXCLUDED.view_count,
+			last_viewed_at = EXCLUDED.last_viewed_at
+	`
+	_, err = s.db.ExecWithMetrics(ctx, query, share.ShareID, share.DashboardID, scope, share.CreatedBy, share.CreatedAt,
+		share.ExpiresAt, share.RevokedAt, share.ViewCount, share.LastViewedAt)
+	return err
+}
+
+func (s *postgresShareStore) ListShares(ctx context.Context) ([]*DashboardShare, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT share_id, dashboard_id, scope, created_by, created_at, expires_at, revoked_at, view_count, last_viewed_at
+		FROM dashboard_shares
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := make([]*DashboardShare, 0)
+	for rows.Next() {
+		var share DashboardShare
+		var scope []byte
+		if err := rows.Scan(&share.ShareID, &share.DashboardID, &scope, &share.CreatedBy, &share.CreatedAt,
+			&share.ExpiresAt, &share.RevokedAt, &share.ViewCount, &share.LastViewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dashboard share: %w", err)
+		}
+		if err := json.Unmarshal(scope, &share.Scope); err != nil {
+			return nil, fmt.Errorf("failed to decode share scope: %w", err)
+		}
+		shares = append(shares, &share)
+	}
+	return shares, rows.Err()
+}
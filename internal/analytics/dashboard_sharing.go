@@ -0,0 +1,476 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSharingUnavailable is returned when no share signing key is configured
+	ErrSharingUnavailable = errors.New("dashboard sharing is not available")
+	// ErrInvalidShare is returned for share requests without a data scope or with a bad expiry
+	ErrInvalidShare = errors.New("invalid dashboard share")
+	// ErrShareForbidden is returned when the requester may not share the dashboard
+	ErrShareForbidden = errors.New("not allowed to share dashboard")
+	// ErrShareNotFound is returned for unknown share IDs
+	ErrShareNotFound = errors.New("dashboard share not found")
+	// ErrInvalidShareToken is returned for share tokens that are malformed or not signed by us
+	ErrInvalidShareToken = errors.New("invalid share token")
+	// ErrShareExpired is returned for share tokens past their expiry or revoked
+	ErrShareExpired = errors.New("share link has expired or been revoked")
+)
+
+const (
+	defaultShareTTL = 7 * 24 * time.Hour
+	maxShareTTL     = 90 * 24 * time.Hour
+	// defaultSharedMetricRange is the range of shared metric widgets that set none
+	defaultSharedMetricRange = time.Hour
+	// NotSharedPlaceholder is shown instead of the data of widgets outside a share's scope
+	NotSharedPlaceholder = "not shared"
+)
+
+// DashboardShareScope is the data a share link may read. A widget is in scope when every
+// metric or symbol it reads is in Metrics and the portfolio it names, if any, is in Portfolios.
+type DashboardShareScope struct {
+	Metrics    []string `json:"metrics,omitempty"`
+	Portfolios []string `json:"portfolios,omitempty"`
+}
+
+// DashboardShare is a revocable, expiring public link to a dashboard
+type DashboardShare struct {
+	ShareID      string              `json:"share_id"`
+	DashboardID  string              `json:"dashboard_id"`
+	Scope        DashboardShareScope `json:"scope"`
+	CreatedBy    string              `json:"created_by"`
+	CreatedAt    time.Time           `json:"created_at"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+	RevokedAt    *time.Time          `json:"revoked_at,omitempty"`
+	ViewCount    int64               `json:"view_count"`
+	LastViewedAt *time.Time          `json:"last_viewed_at,omitempty"`
+}
+
+// SharedDashboard is a dashboard rendered for a share link. Widget data sources are not
+// exposed, only the data computed within the share's scope.
+type SharedDashboard struct {
+	DashboardID string           `json:"dashboard_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Layout      *DashboardLayout `json:"layout"`
+	Theme       string           `json:"theme"`
+	Widgets     []SharedWidget   `json:"widgets"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+	RenderedAt  time.Time        `json:"rendered_at"`
+}
+
+// SharedWidget is a widget rendered for a share link. Widgets outside the share's scope have
+// no data and the NotSharedPlaceholder.
+type SharedWidget struct {
+	WidgetID      string               `json:"widget_id"`
+	Name          string               `json:"name"`
+	Type          WidgetType           `json:"type"`
+	Position      WidgetPosition       `json:"position"`
+	Size          WidgetSize           `json:"size"`
+	Configuration *WidgetConfiguration `json:"configuration,omitempty"`
+	Visualization *WidgetVisualization `json:"visualization,omitempty"`
+	Shared        bool                 `json:"shared"`
+	Placeholder   string               `json:"placeholder,omitempty"`
+	Data          interface{}          `json:"data,omitempty"`
+	Error         string               `json:"error,omitempty"`
+}
+
+// shareClaims is the signed payload of a share token
+type shareClaims struct {
+	ShareID     string              `json:"sid"`
+	DashboardID string              `json:"did"`
+	Scope       DashboardShareScope `json:"scope"`
+	ExpiresAt   int64               `json:"exp"`
+}
+
+// SetShareSigningKey enables share links, signing their tokens with key
+func (dm *DashboardManager) SetShareSigningKey(key []byte) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.shareKey = key
+}
+
+// DashboardShareStore persists share links, so they keep working, stay revoked and keep
+// their view counts across restarts
+type DashboardShareStore interface {
+	// SaveShare inserts or replaces a share
+	SaveShare(ctx context.Context, share *DashboardShare) error
+	ListShares(ctx context.Context) ([]*DashboardShare, error)
+}
+
+// SetShareStore loads the stored share links and persists the links created, revoked and
+// viewed from now on
+func (dm *DashboardManager) SetShareStore(ctx context.Context, store DashboardShareStore) error {
+	shares, err := store.ListShares(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load dashboard shares: %w", err)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.shareStore = store
+	for _, share := range shares {
+		dm.shares[share.ShareID] = share
+	}
+	return nil
+}
+
+// CreateShare creates a share link to a dashboard that may read the data in scope until ttl
+// passes, returning the share and its token. The dashboard's owner and editors may share it,
+// and anyone may share a public dashboard.
+func (dm *DashboardManager) CreateShare(dashboardID string, scope DashboardShareScope, ttl time.Duration, createdBy string) (*DashboardShare, string, error) {
+	if ttl == 0 {
+		ttl = defaultShareTTL
+	}
+	if ttl < 0 || ttl > maxShareTTL {
+		return nil, "", fmt.Errorf("%w: expiry must be positive and at most %s", ErrInvalidShare, FormatHistoryDuration(maxShareTTL))
+	}
+	scope = scope.normalized()
+	if len(scope.Metrics) == 0 && len(scope.Portfolios) == 0 {
+		return nil, "", fmt.Errorf("%w: scope must allow at least one metric or portfolio", ErrInvalidShare)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if len(dm.shareKey) == 0 {
+		return nil, "", ErrSharingUnavailable
+	}
+	dashboard, exists := dm.dashboards[dashboardID]
+	if !exists {
+		return nil, "", fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboardID)
+	}
+	if !dashboard.canShare(createdBy) {
+		return nil, "", fmt.Errorf("%w: %s", ErrShareForbidden, dashboardID)
+	}
+
+	now := time.Now()
+	share := &DashboardShare{
+		ShareID:     uuid.New().String(),
+		DashboardID: dashboardID,
+		Scope:       scope,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	token, err := signShareToken(dm.shareKey, shareClaims{
+		ShareID:     share.ShareID,
+		DashboardID: dashboardID,
+		Scope:       scope,
+		ExpiresAt:   share.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	// A link that isn't stored would stop working at the next restart
+	if dm.shareStore != nil {
+		if err := dm.shareStore.SaveShare(context.Background(), share); err != nil {
+			return nil, "", fmt.Errorf("failed to save dashboard share: %w", err)
+		}
+	}
+	dm.shares[share.ShareID] = share
+
+	dm.logger.Info(context.Background(), "Dashboard share created", map[string]interface{}{
+		"dashboard_id": dashboardID,
+		"share_id":     share.ShareID,
+		"created_by":   createdBy,
+		"expires_at":   share.ExpiresAt,
+	})
+
+	snapshot := *share
+	return &snapshot, token, nil
+}
+
+// RevokeShare revokes a dashboard's share link so its token stops working. The dashboard's
+// owner and editors may revoke any of its links, other users only the links they created.
+func (dm *DashboardManager) RevokeShare(dashboardID, shareID, userID string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	share, exists := dm.shares[shareID]
+	if !exists || share.DashboardID != dashboardID {
+		return fmt.Errorf("%w: %s", ErrShareNotFound, shareID)
+	}
+	dashboard, exists := dm.dashboards[dashboardID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboardID)
+	}
+	if !dashboard.canManageShares(userID) && (userID == "" || share.CreatedBy != userID) {
+		return fmt.Errorf("%w: %s", ErrShareForbidden, dashboardID)
+	}
+	if share.RevokedAt == nil {
+		now := time.Now()
+		revoked := *share
+		revoked.RevokedAt = &now
+		// The revocation must outlive a restart, so it only takes effect once stored
+		if dm.shareStore != nil {
+			if err := dm.shareStore.SaveShare(context.Background(), &revoked); err != nil {
+				return fmt.Errorf("failed to save dashboard share: %w", err)
+			}
+		}
+		share.RevokedAt = &now
+	}
+
+	dm.logger.Info(context.Background(), "Dashboard share revoked", map[string]interface{}{
+		"dashboard_id": dashboardID,
+		"share_id":     shareID,
+		"revoked_by":   userID,
+	})
+
+	return nil
+}
+
+// GetShares returns the share links of a dashboard, newest first: all of them to its owner
+// and editors, and their own links to other users who may share it
+func (dm *DashboardManager) GetShares(dashboardID, userID string) ([]*DashboardShare, error) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	dashboard, exists := dm.dashboards[dashboardID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboardID)
+	}
+	if !dashboard.canShare(userID) {
+		return nil, fmt.Errorf("%w: %s", ErrShareForbidden, dashboardID)
+	}
+	all := dashboard.canManageShares(userID)
+
+	shares := make([]*DashboardShare, 0)
+	for _, share := range dm.shares {
+		if share.DashboardID == dashboardID && (all || (userID != "" && share.CreatedBy == userID)) {
+			snapshot := *share
+			shares = append(shares, &snapshot)
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].CreatedAt.After(shares[j].CreatedAt) })
+	return shares, nil
+}
+
+// GetSharedDashboard renders the dashboard of a share token with the data of the widgets in
+// the share's scope, counting the view separately from direct views
+func (dm *DashboardManager) GetSharedDashboard(ctx context.Context, token string) (*SharedDashboard, error) {
+	now := time.Now()
+
+	dm.mu.Lock()
+	if len(dm.shareKey) == 0 {
+		dm.mu.Unlock()
+		return nil, ErrSharingUnavailable
+	}
+	claims, err := verifyShareToken(dm.shareKey, token)
+	if err != nil {
+		dm.mu.Unlock()
+		return nil, err
+	}
+	share, exists := dm.shares[claims.ShareID]
+	if !exists || share.DashboardID != claims.DashboardID {
+		dm.mu.Unlock()
+		return nil, ErrInvalidShareToken
+	}
+	if share.RevokedAt != nil || !now.Before(share.ExpiresAt) {
+		dm.mu.Unlock()
+		return nil, ErrShareExpired
+	}
+	dashboard, exists := dm.dashboards[share.DashboardID]
+	if !exists {
+		dm.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDashboardNotFound, share.DashboardID)
+	}
+
+	share.ViewCount++
+	share.LastViewedAt = &now
+	dashboard.SharedViews++
+	store, viewed := dm.shareStore, *share
+
+	shared := &SharedDashboard{
+		DashboardID: dashboard.DashboardID,
+		Name:        dashboard.Name,
+		Description: dashboard.Description,
+		Layout:      dashboard.Layout,
+		Theme:       dashboard.Theme,
+		Widgets:     make([]SharedWidget, 0, len(dashboard.Widgets)),
+		ExpiresAt:   share.ExpiresAt,
+		RenderedAt:  now,
+	}
+	widgets := make([]Widget, 0, len(dashboard.Widgets))
+	for _, widget := range dashboard.Widgets {
+		if widget.IsVisible {
+			widgets = append(widgets, *widget)
+		}
+	}
+	scope := share.Scope
+	dm.mu.Unlock()
+
+	if store != nil {
+		if err := store.SaveShare(ctx, &viewed); err != nil {
+			dm.logger.Warn(ctx, "Failed to save dashboard share views", map[string]interface{}{
+				"share_id": viewed.ShareID,
+				"error":    err.Error(),
+			})
+		}
+	}
+
+	for _, widget := range widgets {
+		rendered := SharedWidget{
+			WidgetID:      widget.WidgetID,
+			Name:          widget.Name,
+			Type:          widget.Type,
+			Position:      widget.Position,
+			Size:          widget.Size,
+			Configuration: widget.Configuration,
+			Visualization: widget.Visualization,
+		}
+		if !scope.allows(widget.DataSource) {
+			rendered.Placeholder = NotSharedPlaceholder
+			shared.Widgets = append(shared.Widgets, rendered)
+			continue
+		}
+
+		rendered.Shared = true
+		if widget.DataSource != nil {
+			data, err := dm.computeSharedWidget(ctx, widget.DataSource, now)
+			if err != nil {
+				rendered.Error = err.Error()
+			}
+			rendered.Data = data
+		}
+		shared.Widgets = append(shared.Widgets, rendered)
+	}
+
+	return shared, nil
+}
+
+// computeSharedWidget computes the data of an in-scope widget's source
+func (dm *DashboardManager) computeSharedWidget(ctx context.Context, source *WidgetDataSource, now time.Time) (interface{}, error) {
+	switch source.Type {
+	case DataSourceTypeCorrelationMatrix, DataSourceTypeHeatmap:
+		return dm.computeDataSource(ctx, source)
+	default:
+		from, to := now.Add(-defaultSharedMetricRange), now
+		if source.TimeRange.Relative != "" || !source.TimeRange.From.IsZero() {
+			var err error
+			if from, to, err = source.TimeRange.resolve(now); err != nil {
+				return nil, err
+			}
+		}
+		return dm.loadSeries(ctx, source.MetricName, from, to, time.Minute)
+	}
+}
+
+// allows reports whether every metric, symbol and portfolio a widget reads is in scope.
+// Widgets without a data source read nothing.
+func (s DashboardShareScope) allows(source *WidgetDataSource) bool {
+	if source == nil {
+		return true
+	}
+
+	var metrics []string
+	switch source.Type {
+	case DataSourceTypeMetrics, DataSourceTypeHeatmap:
+		metrics = []string{source.MetricName}
+	case DataSourceTypeCorrelationMatrix:
+		metrics = stringsParameter(source.Parameters, "series")
+	default:
+		return false
+	}
+	if len(metrics) == 0 {
+		return false
+	}
+	for _, metric := range metrics {
+		if !containsString(s.Metrics, metric) {
+			return false
+		}
+	}
+
+	if portfolioID, ok := source.Parameters["portfolio_id"].(string); ok && !containsString(s.Portfolios, portfolioID) {
+		return false
+	}
+	return true
+}
+
+// normalized trims, de-duplicates and sorts the scope
+func (s DashboardShareScope) normalized() DashboardShareScope {
+	return DashboardShareScope{Metrics: uniqueSorted(s.Metrics), Portfolios: uniqueSorted(s.Portfolios)}
+}
+
+// canShare reports whether userID may create share links to the dashboard
+func (d *Dashboard) canShare(userID string) bool {
+	permissions := d.Permissions
+	if permissions == nil || permissions.Owner == "" || permissions.Public || d.IsPublic {
+		return true
+	}
+	return d.canManageShares(userID)
+}
+
+// canManageShares reports whether userID may list and revoke every share link of the
+// dashboard, which only its owner and editors may
+func (d *Dashboard) canManageShares(userID string) bool {
+	permissions := d.Permissions
+	if permissions == nil || userID == "" {
+		return false
+	}
+	return permissions.Owner == userID || containsString(permissions.Editors, userID)
+}
+
+// signShareToken encodes claims as base64url JSON followed by its HMAC-SHA256 signature
+func signShareToken(key []byte, claims shareClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode share token: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(shareSignature(key, encoded)), nil
+}
+
+// verifyShareToken returns the claims of a token signed with key that has not expired
+func verifyShareToken(key []byte, token string) (*shareClaims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidShareToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, shareSignature(key, encoded)) {
+		return nil, ErrInvalidShareToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidShareToken
+	}
+	var claims shareClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidShareToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrShareExpired
+	}
+	return &claims, nil
+}
+
+func shareSignature(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func uniqueSorted(values []string) []string {
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !containsString(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,333 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryShareStore is an in-memory DashboardShareStore for tests
+type memoryShareStore struct {
+	mu     sync.Mutex
+	shares map[string]DashboardShare
+}
+
+func (s *memoryShareStore) SaveShare(ctx context.Context, share *DashboardShare) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shares == nil {
+		s.shares = make(map[string]DashboardShare)
+	}
+	s.shares[share.ShareID] = *share
+	return nil
+}
+
+func (s *memoryShareStore) ListShares(ctx context.Context) ([]*DashboardShare, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shares := make([]*DashboardShare, 0, len(s.shares))
+	for _, share := range s.shares {
+		copied := share
+		shares = append(shares, &copied)
+	}
+	return shares, nil
+}
+
+// newSharingTestDashboard creates a dashboard owned by alice with a CPU metric widget, a
+// correlation widget reading CPU and memory, a heatmap of the P&L of portfolio p1 and a
+// static text widget
+func newSharingTestDashboard(t *testing.T) (*DashboardManager, *Dashboard) {
+	t.Helper()
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	dm.SetShareSigningKey([]byte("test-share-key"))
+
+	now := time.Now()
+	for i := 0; i < 30; i++ {
+		at := now.Add(-time.Duration(i) * time.Minute)
+		dm.RecordMetric("cpu_usage", 40+float64(i%5), at)
+		dm.RecordMetric("memory_usage", 60+float64(i%7), at)
+		dm.RecordMetric("pnl", float64(i), at)
+	}
+
+	dashboard := &Dashboard{
+		Name:        "Operations",
+		Permissions: &DashboardPermissions{Owner: "alice", Editors: []string{"bob"}},
+		Widgets: []*Widget{
+			{Name: "CPU", Type: WidgetTypeChart, IsVisible: true, DataSource: &WidgetDataSource{
+				Type: DataSourceTypeMetrics, MetricName: "cpu_usage",
+			}},
+			{Name: "Correlations", Type: WidgetTypeChart, IsVisible: true, DataSource: &WidgetDataSource{
+				Type:       DataSourceTypeCorrelationMatrix,
+				TimeRange:  TimeRange{Relative: "1h"},
+				Parameters: map[string]interface{}{"series": []string{"cpu_usage", "memory_usage"}, "interval": "1m"},
+			}},
+			{Name: "P&L by hour", Type: WidgetTypeChart, IsVisible: true, DataSource: &WidgetDataSource{
+				Type:       DataSourceTypeHeatmap,
+				MetricName: "pnl",
+				TimeRange:  TimeRange{Relative: "1d"},
+				Parameters: map[string]interface{}{"x": "hour_of_day", "y": "day_of_week", "portfolio_id": "p1"},
+			}},
+			{Name: "Notes", Type: WidgetTypeText, IsVisible: true},
+		},
+	}
+	if err := dm.CreateDashboard(dashboard); err != nil {
+		t.Fatalf("Failed to create dashboard: %v", err)
+	}
+	return dm, dashboard
+}
+
+func TestSharedDashboardIsLimitedToScope(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+
+	_, token, err := dm.CreateShare(dashboard.DashboardID, DashboardShareScope{Metrics: []string{"cpu_usage", "pnl"}}, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	shared, err := dm.GetSharedDashboard(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Failed to render shared dashboard: %v", err)
+	}
+	if len(shared.Widgets) != 4 {
+		t.Fatalf("Expected 4 widgets, got %d", len(shared.Widgets))
+	}
+
+	cpu := shared.Widgets[0]
+	if points, ok := cpu.Data.([]DataPoint); !cpu.Shared || !ok || len(points) == 0 {
+		t.Errorf("Expected the CPU widget shared with data, got %+v", cpu)
+	}
+	// The correlation widget also reads memory usage, and the heatmap a portfolio not in scope
+	for _, widget := range shared.Widgets[1:3] {
+		if widget.Shared || widget.Data != nil || widget.Placeholder != NotSharedPlaceholder {
+			t.Errorf("Expected %s not shared, got %+v", widget.Name, widget)
+		}
+	}
+	if notes := shared.Widgets[3]; !notes.Shared {
+		t.Errorf("Expected the static widget shared, got %+v", notes)
+	}
+
+	_, token, err = dm.CreateShare(dashboard.DashboardID, DashboardShareScope{
+		Metrics:    []string{"cpu_usage", "memory_usage", "pnl"},
+		Portfolios: []string{"p1"},
+	}, time.Hour, "bob")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	shared, err = dm.GetSharedDashboard(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Failed to render shared dashboard: %v", err)
+	}
+	if matrix, ok := shared.Widgets[1].Data.(*CorrelationMatrix); !ok || len(matrix.Series) != 2 {
+		t.Errorf("Expected the correlation matrix shared, got %+v", shared.Widgets[1])
+	}
+	if _, ok := shared.Widgets[2].Data.(*Heatmap); !ok {
+		t.Errorf("Expected the heatmap shared, got %+v", shared.Widgets[2])
+	}
+}
+
+func TestSharedViewsCountedSeparately(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+	share, token, err := dm.CreateShare(dashboard.DashboardID, DashboardShareScope{Metrics: []string{"cpu_usage"}}, 0, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	if expiry := share.ExpiresAt.Sub(share.CreatedAt); expiry != defaultShareTTL {
+		t.Errorf("Expected the default expiry, got %s", expiry)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := dm.GetSharedDashboard(context.Background(), token); err != nil {
+			t.Fatalf("Failed to render shared dashboard: %v", err)
+		}
+	}
+
+	metrics := dm.GetDashboardMetrics()
+	if metrics["total_shared_views"] != int64(3) || metrics["total_views"] != int64(0) || metrics["active_shares"] != 1 {
+		t.Errorf("Expected 3 shared views of 1 active share, got %+v", metrics)
+	}
+	if shares, err := dm.GetShares(dashboard.DashboardID, "alice"); err != nil || len(shares) != 1 || shares[0].ViewCount != 3 {
+		t.Errorf("Expected the share to count 3 views, got %+v", shares)
+	}
+}
+
+func TestRevokedAndExpiredSharesStopWorking(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+	scope := DashboardShareScope{Metrics: []string{"cpu_usage"}}
+
+	share, token, err := dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	if err := dm.RevokeShare(dashboard.DashboardID, share.ShareID, "alice"); err != nil {
+		t.Fatalf("Failed to revoke share: %v", err)
+	}
+	if _, err := dm.GetSharedDashboard(context.Background(), token); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("Expected ErrShareExpired for a revoked share, got %v", err)
+	}
+	if err := dm.RevokeShare("other", share.ShareID, "alice"); !errors.Is(err, ErrShareNotFound) {
+		t.Errorf("Expected ErrShareNotFound for another dashboard's share, got %v", err)
+	}
+
+	share, token, err = dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	dm.mu.Lock()
+	dm.shares[share.ShareID].ExpiresAt = time.Now().Add(-time.Minute)
+	dm.mu.Unlock()
+	if _, err := dm.GetSharedDashboard(context.Background(), token); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("Expected ErrShareExpired for an expired share, got %v", err)
+	}
+	if active := dm.GetDashboardMetrics()["active_shares"]; active != 0 {
+		t.Errorf("Expected no active shares, got %v", active)
+	}
+}
+
+func TestShareTokenRejectsTampering(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+	_, token, err := dm.CreateShare(dashboard.DashboardID, DashboardShareScope{Metrics: []string{"cpu_usage"}}, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+
+	// Widening the scope in the payload invalidates the signature
+	payload, signature, _ := strings.Cut(token, ".")
+	claims, err := verifyShareToken(dm.shareKey, token)
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	claims.Scope.Metrics = append(claims.Scope.Metrics, "memory_usage")
+	widened, _ := signShareToken([]byte("another-key"), *claims)
+	widenedPayload, _, _ := strings.Cut(widened, ".")
+
+	for _, tampered := range []string{widenedPayload + "." + signature, payload + ".", widened, "not-a-token"} {
+		if _, err := dm.GetSharedDashboard(context.Background(), tampered); !errors.Is(err, ErrInvalidShareToken) {
+			t.Errorf("Expected ErrInvalidShareToken for %q, got %v", tampered, err)
+		}
+	}
+}
+
+func TestCreateShareValidation(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+	scope := DashboardShareScope{Metrics: []string{"cpu_usage"}}
+
+	if _, _, err := dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "mallory"); !errors.Is(err, ErrShareForbidden) {
+		t.Errorf("Expected ErrShareForbidden for a viewer, got %v", err)
+	}
+	if _, _, err := dm.CreateShare(dashboard.DashboardID, DashboardShareScope{Metrics: []string{" "}}, time.Hour, "alice"); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for an empty scope, got %v", err)
+	}
+	if _, _, err := dm.CreateShare(dashboard.DashboardID, scope, maxShareTTL+time.Hour, "alice"); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for a long expiry, got %v", err)
+	}
+	if _, _, err := dm.CreateShare("missing", scope, time.Hour, "alice"); !errors.Is(err, ErrDashboardNotFound) {
+		t.Errorf("Expected ErrDashboardNotFound, got %v", err)
+	}
+
+	unsigned := newTestDashboardManager(&AnalyticsConfig{})
+	if _, _, err := unsigned.CreateShare(dashboard.DashboardID, scope, time.Hour, "alice"); !errors.Is(err, ErrSharingUnavailable) {
+		t.Errorf("Expected ErrSharingUnavailable without a signing key, got %v", err)
+	}
+}
+
+func TestShareManagementIsLimitedToOwnersAndEditors(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+	scope := DashboardShareScope{Metrics: []string{"cpu_usage"}}
+
+	owners, _, err := dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	editors, _, err := dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "bob")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+
+	for _, user := range []string{"mallory", ""} {
+		if _, err := dm.GetShares(dashboard.DashboardID, user); !errors.Is(err, ErrShareForbidden) {
+			t.Errorf("Expected ErrShareForbidden listing shares as %q, got %v", user, err)
+		}
+		if err := dm.RevokeShare(dashboard.DashboardID, owners.ShareID, user); !errors.Is(err, ErrShareForbidden) {
+			t.Errorf("Expected ErrShareForbidden revoking a share as %q, got %v", user, err)
+		}
+	}
+	if shares, err := dm.GetShares(dashboard.DashboardID, "bob"); err != nil || len(shares) != 2 {
+		t.Errorf("Expected editors to see every share, got %d (%v)", len(shares), err)
+	}
+	if err := dm.RevokeShare(dashboard.DashboardID, editors.ShareID, "alice"); err != nil {
+		t.Errorf("Expected the owner to revoke an editor's share, got %v", err)
+	}
+	if _, err := dm.GetShares("missing", "alice"); !errors.Is(err, ErrDashboardNotFound) {
+		t.Errorf("Expected ErrDashboardNotFound, got %v", err)
+	}
+
+	// Anyone may share a public dashboard, but only manages the links they created
+	public := &Dashboard{Name: "Public", IsPublic: true, Permissions: &DashboardPermissions{Owner: "alice"}}
+	if err := dm.CreateDashboard(public); err != nil {
+		t.Fatalf("Failed to create dashboard: %v", err)
+	}
+	own, _, err := dm.CreateShare(public.DashboardID, scope, time.Hour, "carol")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	others, _, err := dm.CreateShare(public.DashboardID, scope, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	if shares, err := dm.GetShares(public.DashboardID, "carol"); err != nil || len(shares) != 1 || shares[0].ShareID != own.ShareID {
+		t.Errorf("Expected carol to see only her share, got %+v (%v)", shares, err)
+	}
+	if err := dm.RevokeShare(public.DashboardID, others.ShareID, "carol"); !errors.Is(err, ErrShareForbidden) {
+		t.Errorf("Expected ErrShareForbidden revoking another user's share, got %v", err)
+	}
+	if err := dm.RevokeShare(public.DashboardID, own.ShareID, "carol"); err != nil {
+		t.Errorf("Expected carol to revoke her share, got %v", err)
+	}
+}
+
+func TestSharesArePersisted(t *testing.T) {
+	dm, dashboard := newSharingTestDashboard(t)
+	store := &memoryShareStore{}
+	if err := dm.SetShareStore(context.Background(), store); err != nil {
+		t.Fatalf("Failed to set share store: %v", err)
+	}
+	scope := DashboardShareScope{Metrics: []string{"cpu_usage"}}
+
+	kept, keptToken, err := dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	revoked, revokedToken, err := dm.CreateShare(dashboard.DashboardID, scope, time.Hour, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create share: %v", err)
+	}
+	if err := dm.RevokeShare(dashboard.DashboardID, revoked.ShareID, "alice"); err != nil {
+		t.Fatalf("Failed to revoke share: %v", err)
+	}
+	if _, err := dm.GetSharedDashboard(context.Background(), keptToken); err != nil {
+		t.Fatalf("Failed to render shared dashboard: %v", err)
+	}
+
+	// A restarted manager with the same dashboard and key loads the links from the store
+	restarted := newTestDashboardManager(&AnalyticsConfig{})
+	restarted.SetShareSigningKey([]byte("test-share-key"))
+	restored := *dashboard
+	if err := restarted.CreateDashboard(&restored); err != nil {
+		t.Fatalf("Failed to create dashboard: %v", err)
+	}
+	if err := restarted.SetShareStore(context.Background(), store); err != nil {
+		t.Fatalf("Failed to set share store: %v", err)
+	}
+
+	if _, err := restarted.GetSharedDashboard(context.Background(), keptToken); err != nil {
+		t.Errorf("Expected the stored share to keep working, got %v", err)
+	}
+	if _, err := restarted.GetSharedDashboard(context.Background(), revokedToken); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("Expected the stored revocation to hold, got %v", err)
+	}
+	if stored := store.shares[kept.ShareID]; stored.ViewCount != 2 || stored.LastViewedAt == nil {
+		t.Errorf("Expected 2 stored views, got %+v", stored)
+	}
+}
@@ -0,0 +1,662 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDashboardNotFound is returned for unknown dashboard IDs
+	ErrDashboardNotFound = errors.New("dashboard not found")
+	// ErrWidgetNotFound is returned for unknown widget IDs
+	ErrWidgetNotFound = errors.New("widget not found")
+	// ErrInvalidWidgetQuery is returned for computed widget queries that cannot be answered
+	ErrInvalidWidgetQuery = errors.New("invalid widget query")
+)
+
+const (
+	defaultWidgetCacheTTL       = 5 * time.Minute
+	defaultCorrelationInterval  = time.Hour
+	defaultCorrelationOverlap   = 10
+	minCorrelationOverlap       = 3
+	maxCorrelationSeries        = 20
+	maxWidgetBuckets            = 10000
+	defaultMetricRetention      = 24 * time.Hour
+	maxRecordedSamplesPerMetric = 20000
+)
+
+// HeatmapDimension buckets timestamps along one axis of a heatmap
+type HeatmapDimension string
+
+const (
+	DimensionHourOfDay  HeatmapDimension = "hour_of_day"
+	DimensionDayOfWeek  HeatmapDimension = "day_of_week"
+	DimensionDayOfMonth HeatmapDimension = "day_of_month"
+	DimensionMonth      HeatmapDimension = "month"
+)
+
+// Aggregations of the samples in a heatmap cell
+const (
+	HeatmapAggregationAvg   = "avg"
+	HeatmapAggregationSum   = "sum"
+	HeatmapAggregationMin   = "min"
+	HeatmapAggregationMax   = "max"
+	HeatmapAggregationCount = "count"
+)
+
+// heatmapAxis labels the buckets of a dimension and places timestamps in them
+type heatmapAxis struct {
+	labels []string
+	index  func(t time.Time) int
+}
+
+var heatmapAxes = map[HeatmapDimension]heatmapAxis{
+	DimensionHourOfDay: {
+		labels: numberLabels(0, 23, "%02d"),
+		index:  func(t time.Time) int { return t.Hour() },
+	},
+	DimensionDayOfWeek: {
+		labels: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		index:  func(t time.Time) int { return (int(t.Weekday()) + 6) % 7 },
+	},
+	DimensionDayOfMonth: {
+		labels: numberLabels(1, 31, "%d"),
+		index:  func(t time.Time) int { return t.Day() - 1 },
+	},
+	DimensionMonth: {
+		labels: []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		index:  func(t time.Time) int { return int(t.Month()) - 1 },
+	},
+}
+
+// candleIntervals are the candle intervals symbol series are loaded at, largest first
+var candleIntervals = []struct {
+	duration time.Duration
+	name     string
+}{
+	{24 * time.Hour, "1d"},
+	{4 * time.Hour, "4h"},
+	{time.Hour, "1h"},
+	{15 * time.Minute, "15m"},
+	{5 * time.Minute, "5m"},
+	{time.Minute, "1m"},
+}
+
+// CorrelationMatrixQuery selects the series of a correlation matrix. Series are metric names
+// recorded by the dashboard manager or, failing that, market symbols such as BTCUSDT.
+type CorrelationMatrixQuery struct {
+	Series     []string      `json:"series"`
+	TimeRange  TimeRange     `json:"time_range"`
+	Interval   time.Duration `json:"interval"`    // series are averaged over buckets of this size
+	MinOverlap int           `json:"min_overlap"` // buckets a pair needs in common
+	Returns    bool          `json:"returns"`     // correlate bucket-over-bucket changes, not levels
+}
+
+// CorrelationMatrix holds the pairwise Pearson correlations of a query's series, in query
+// order. Pairs with fewer than MinOverlap buckets in common, or a constant series, are null.
+type CorrelationMatrix struct {
+	Series     []string     `json:"series"`
+	Matrix     [][]*float64 `json:"matrix"`
+	Overlap    [][]int      `json:"overlap"`
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	Interval   string       `json:"interval"`
+	MinOverlap int          `json:"min_overlap"`
+	Returns    bool         `json:"returns"`
+	ComputedAt time.Time    `json:"computed_at"`
+	Cached     bool         `json:"cached"`
+}
+
+// HeatmapQuery selects a metric or symbol and the two time dimensions to bucket it by
+type HeatmapQuery struct {
+	Metric      string           `json:"metric"`
+	TimeRange   TimeRange        `json:"time_range"`
+	X           HeatmapDimension `json:"x"`
+	Y           HeatmapDimension `json:"y"`
+	Aggregation string           `json:"aggregation"`
+	Timezone    string           `json:"timezone,omitempty"` // IANA zone of the buckets, UTC when empty
+}
+
+// Heatmap is a metric aggregated over two time dimensions. Values are indexed [y][x]; cells
+// without samples are null.
+type Heatmap struct {
+	Metric      string           `json:"metric"`
+	X           HeatmapDimension `json:"x"`
+	Y           HeatmapDimension `json:"y"`
+	XLabels     []string         `json:"x_labels"`
+	YLabels     []string         `json:"y_labels"`
+	Values      [][]*float64     `json:"values"`
+	Counts      [][]int          `json:"counts"`
+	Min         *float64         `json:"min"`
+	Max         *float64         `json:"max"`
+	Aggregation string           `json:"aggregation"`
+	Timezone    string           `json:"timezone"`
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	ComputedAt  time.Time        `json:"computed_at"`
+	Cached      bool             `json:"cached"`
+}
+
+// cachedWidgetData is a computed widget result and when it goes stale
+type cachedWidgetData struct {
+	data      interface{}
+	expiresAt time.Time
+}
+
+// SetCandleSource lets computed widgets load market symbols, such as BTCUSDT, as close prices
+func (dm *DashboardManager) SetCandleSource(source BenchmarkCandleSource) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.candles = source
+}
+
+// RecordMetric adds a metric sample for computed widgets. Samples older than the metrics
+// retention period are dropped.
+func (dm *DashboardManager) RecordMetric(name string, value float64, at time.Time) {
+	dm.recordMetric(name, value, at, 0)
+}
+
+// recordMetric adds a metric sample, dropping the metric's samples older than retention. A
+// zero retention takes the metrics retention period.
+func (dm *DashboardManager) recordMetric(name string, value float64, at time.Time, retention time.Duration) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	series := append(dm.metricSeries[name], DataPoint{Timestamp: at, Value: value})
+	if n := len(series); n > 1 && at.Before(series[n-2].Timestamp) {
+		sort.SliceStable(series, func(i, j int) bool { return series[i].Timestamp.Before(series[j].Timestamp) })
+	}
+
+	if retention <= 0 {
+		retention = defaultMetricRetention
+		if dm.config != nil && dm.config.MetricsRetentionPeriod > 0 {
+			retention = dm.config.MetricsRetentionPeriod
+		}
+	}
+	cutoff := time.Now().Add(-retention)
+	drop := sort.Search(len(series), func(i int) bool { return !series[i].Timestamp.Before(cutoff) })
+	if excess := len(series) - drop - maxRecordedSamplesPerMetric; excess > 0 {
+		drop += excess
+	}
+	dm.metricSeries[name] = series[drop:]
+}
+
+// ComputeCorrelationMatrix computes the pairwise correlations of the query's series. Results
+// are cached for the widget cache TTL.
+func (dm *DashboardManager) ComputeCorrelationMatrix(ctx context.Context, query CorrelationMatrixQuery) (*CorrelationMatrix, error) {
+	if query.Interval == 0 {
+		query.Interval = defaultCorrelationInterval
+	}
+	if query.MinOverlap == 0 {
+		query.MinOverlap = defaultCorrelationOverlap
+	}
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	from, to, err := query.TimeRange.resolve(now)
+	if err != nil {
+		return nil, err
+	}
+	if to.Sub(from)/query.Interval > maxWidgetBuckets {
+		return nil, fmt.Errorf("%w: range spans more than %d intervals", ErrInvalidWidgetQuery, maxWidgetBuckets)
+	}
+
+	key := fmt.Sprintf("correlation|%s|%s|%s|%d|%t", strings.Join(query.Series, ","), query.TimeRange.cacheKey(),
+		query.Interval, query.MinOverlap, query.Returns)
+	if cached, ok := dm.cachedWidget(key, now); ok {
+		matrix := *cached.(*CorrelationMatrix)
+		matrix.Cached = true
+		return &matrix, nil
+	}
+
+	buckets := make([]map[int64]float64, len(query.Series))
+	for i, name := range query.Series {
+		points, err := dm.loadSeries(ctx, name, from, to, query.Interval)
+		if err != nil {
+			return nil, err
+		}
+		buckets[i] = bucketMeans(points, query.Interval)
+		if query.Returns {
+			buckets[i] = bucketChanges(buckets[i], query.Interval)
+		}
+	}
+
+	n := len(query.Series)
+	matrix := &CorrelationMatrix{
+		Series:     query.Series,
+		Matrix:     make([][]*float64, n),
+		Overlap:    make([][]int, n),
+		From:       from,
+		To:         to,
+		Interval:   FormatHistoryDuration(query.Interval),
+		MinOverlap: query.MinOverlap,
+		Returns:    query.Returns,
+		ComputedAt: now,
+	}
+	for i := range matrix.Matrix {
+		matrix.Matrix[i] = make([]*float64, n)
+		matrix.Overlap[i] = make([]int, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			x, y := pairedValues(buckets[i], buckets[j])
+			matrix.Overlap[i][j], matrix.Overlap[j][i] = len(x), len(x)
+			if len(x) < query.MinOverlap {
+				continue
+			}
+			if r, ok := pearson(x, y); ok {
+				matrix.Matrix[i][j], matrix.Matrix[j][i] = &r, &r
+			}
+		}
+	}
+
+	dm.cacheWidget(key, matrix, now)
+	return matrix, nil
+}
+
+// ComputeHeatmap aggregates the query's metric over two time dimensions. Results are cached
+// for the widget cache TTL.
+func (dm *DashboardManager) ComputeHeatmap(ctx context.Context, query HeatmapQuery) (*Heatmap, error) {
+	if query.Aggregation == "" {
+		query.Aggregation = HeatmapAggregationAvg
+	}
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	location := time.UTC
+	if query.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(query.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidWidgetQuery, query.Timezone)
+		}
+	}
+	now := time.Now()
+	from, to, err := query.TimeRange.resolve(now)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("heatmap|%s|%s|%s|%s|%s|%s", query.Metric, query.TimeRange.cacheKey(), query.X, query.Y,
+		query.Aggregation, location)
+	if cached, ok := dm.cachedWidget(key, now); ok {
+		heatmap := *cached.(*Heatmap)
+		heatmap.Cached = true
+		return &heatmap, nil
+	}
+
+	points, err := dm.loadSeries(ctx, query.Metric, from, to, time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	xAxis, yAxis := heatmapAxes[query.X], heatmapAxes[query.Y]
+	heatmap := &Heatmap{
+		Metric:      query.Metric,
+		X:           query.X,
+		Y:           query.Y,
+		XLabels:     xAxis.labels,
+		YLabels:     yAxis.labels,
+		Values:      make([][]*float64, len(yAxis.labels)),
+		Counts:      make([][]int, len(yAxis.labels)),
+		Aggregation: query.Aggregation,
+		Timezone:    location.String(),
+		From:        from,
+		To:          to,
+		ComputedAt:  now,
+	}
+	sums := make([][]float64, len(yAxis.labels))
+	for y := range heatmap.Values {
+		heatmap.Values[y] = make([]*float64, len(xAxis.labels))
+		heatmap.Counts[y] = make([]int, len(xAxis.labels))
+		sums[y] = make([]float64, len(xAxis.labels))
+	}
+
+	for _, point := range points {
+		local := point.Timestamp.In(location)
+		x, y := xAxis.index(local), yAxis.index(local)
+		cell := heatmap.Values[y][x]
+		switch {
+		case cell == nil:
+			value := point.Value
+			heatmap.Values[y][x] = &value
+		case query.Aggregation == HeatmapAggregationMin:
+			*cell = math.Min(*cell, point.Value)
+		case query.Aggregation == HeatmapAggregationMax:
+			*cell = math.Max(*cell, point.Value)
+		}
+		heatmap.Counts[y][x]++
+		sums[y][x] += point.Value
+	}
+
+	for y, row := range heatmap.Values {
+		for x, cell := range row {
+			if cell == nil {
+				continue
+			}
+			switch query.Aggregation {
+			case HeatmapAggregationAvg:
+				*cell = sums[y][x] / float64(heatmap.Counts[y][x])
+			case HeatmapAggregationSum:
+				*cell = sums[y][x]
+			case HeatmapAggregationCount:
+				*cell = float64(heatmap.Counts[y][x])
+			}
+			if heatmap.Min == nil || *cell < *heatmap.Min {
+				heatmap.Min = floatPtr(*cell)
+			}
+			if heatmap.Max == nil || *cell > *heatmap.Max {
+				heatmap.Max = floatPtr(*cell)
+			}
+		}
+	}
+
+	dm.cacheWidget(key, heatmap, now)
+	return heatmap, nil
+}
+
+// ComputeWidgetData computes the data of a dashboard widget whose data source is computed
+// server-side: a correlation matrix or a heatmap
+func (dm *DashboardManager) ComputeWidgetData(ctx context.Context, dashboardID, widgetID string) (interface{}, error) {
+	dm.mu.RLock()
+	dashboard, exists := dm.dashboards[dashboardID]
+	if !exists {
+		dm.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s", ErrDashboardNotFound, dashboardID)
+	}
+	var source *WidgetDataSource
+	found := false
+	for _, widget := range dashboard.Widgets {
+		if widget.WidgetID == widgetID {
+			source, found = widget.DataSource, true
+			break
+		}
+	}
+	dm.mu.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrWidgetNotFound, widgetID)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: widget has no data source", ErrInvalidWidgetQuery)
+	}
+	return dm.computeDataSource(ctx, source)
+}
+
+// computeDataSource computes the data of a correlation matrix or heatmap data source
+func (dm *DashboardManager) computeDataSource(ctx context.Context, source *WidgetDataSource) (interface{}, error) {
+	switch source.Type {
+	case DataSourceTypeCorrelationMatrix:
+		query := CorrelationMatrixQuery{
+			Series:     stringsParameter(source.Parameters, "series"),
+			TimeRange:  source.TimeRange,
+			MinOverlap: intParameter(source.Parameters, "min_overlap"),
+			Returns:    source.Parameters["returns"] == true,
+		}
+		if interval, ok := source.Parameters["interval"].(string); ok && interval != "" {
+			duration, err := ParseHistoryDuration(interval)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid interval %q", ErrInvalidWidgetQuery, interval)
+			}
+			query.Interval = duration
+		}
+		return dm.ComputeCorrelationMatrix(ctx, query)
+	case DataSourceTypeHeatmap:
+		x, _ := source.Parameters["x"].(string)
+		y, _ := source.Parameters["y"].(string)
+		timezone, _ := source.Parameters["timezone"].(string)
+		return dm.ComputeHeatmap(ctx, HeatmapQuery{
+			Metric:      source.MetricName,
+			TimeRange:   source.TimeRange,
+			X:           HeatmapDimension(x),
+			Y:           HeatmapDimension(y),
+			Aggregation: source.Aggregation,
+			Timezone:    timezone,
+		})
+	default:
+		return nil, fmt.Errorf("%w: %s data sources are not computed server-side", ErrInvalidWidgetQuery, source.Type)
+	}
+}
+
+func (q CorrelationMatrixQuery) validate() error {
+	if len(q.Series) < 2 || len(q.Series) > maxCorrelationSeries {
+		return fmt.Errorf("%w: select between 2 and %d series", ErrInvalidWidgetQuery, maxCorrelationSeries)
+	}
+	seen := make(map[string]bool, len(q.Series))
+	for _, name := range q.Series {
+		if name == "" || seen[name] {
+			return fmt.Errorf("%w: series must be distinct and non-empty", ErrInvalidWidgetQuery)
+		}
+		seen[name] = true
+	}
+	if q.Interval < time.Minute {
+		return fmt.Errorf("%w: interval must be at least 1m", ErrInvalidWidgetQuery)
+	}
+	if q.MinOverlap < minCorrelationOverlap {
+		return fmt.Errorf("%w: min overlap must be at least %d", ErrInvalidWidgetQuery, minCorrelationOverlap)
+	}
+	return nil
+}
+
+func (q HeatmapQuery) validate() error {
+	if q.Metric == "" {
+		return fmt.Errorf("%w: metric is required", ErrInvalidWidgetQuery)
+	}
+	if _, ok := heatmapAxes[q.X]; !ok {
+		return fmt.Errorf("%w: unknown x dimension %q", ErrInvalidWidgetQuery, q.X)
+	}
+	if _, ok := heatmapAxes[q.Y]; !ok {
+		return fmt.Errorf("%w: unknown y dimension %q", ErrInvalidWidgetQuery, q.Y)
+	}
+	if q.X == q.Y {
+		return fmt.Errorf("%w: x and y dimensions must differ", ErrInvalidWidgetQuery)
+	}
+	switch q.Aggregation {
+	case HeatmapAggregationAvg, HeatmapAggregationSum, HeatmapAggregationMin, HeatmapAggregationMax, HeatmapAggregationCount:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown aggregation %q", ErrInvalidWidgetQuery, q.Aggregation)
+}
+
+// resolve returns the absolute bounds of the range: the trailing Relative duration up to now,
+// or From to To
+func (r TimeRange) resolve(now time.Time) (time.Time, time.Time, error) {
+	if r.Relative != "" {
+		duration, err := ParseHistoryDuration(r.Relative)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid range %q", ErrInvalidWidgetQuery, r.Relative)
+		}
+		return now.Add(-duration), now, nil
+	}
+	if r.From.IsZero() || !r.To.After(r.From) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: set a relative range, or a from before to", ErrInvalidWidgetQuery)
+	}
+	return r.From, r.To, nil
+}
+
+// cacheKey identifies the range so relative ranges share a cache entry as time passes
+func (r TimeRange) cacheKey() string {
+	if r.Relative != "" {
+		return r.Relative
+	}
+	return strconv.FormatInt(r.From.UnixNano(), 10) + "-" + strconv.FormatInt(r.To.UnixNano(), 10)
+}
+
+// loadSeries returns the samples of a recorded metric, or else the closes of a symbol's candles
+// at the largest candle interval within step
+func (dm *DashboardManager) loadSeries(ctx context.Context, name string, from, to time.Time, step time.Duration) ([]DataPoint, error) {
+	dm.mu.RLock()
+	recorded, exists := dm.metricSeries[name]
+	points := make([]DataPoint, 0, len(recorded))
+	for _, point := range recorded {
+		if !point.Timestamp.Before(from) && point.Timestamp.Before(to) {
+			points = append(points, point)
+		}
+	}
+	source := dm.candles
+	dm.mu.RUnlock()
+
+	if exists || source == nil {
+		return points, nil
+	}
+
+	interval := candleIntervals[len(candleIntervals)-1].name
+	for _, candidate := range candleIntervals {
+		if candidate.duration <= step {
+			interval = candidate.name
+			break
+		}
+	}
+	candles, err := source.GetCandles(ctx, benchmarkExchange, name, interval, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s prices: %w", name, err)
+	}
+	for _, candle := range candles {
+		if price := candle.Close.InexactFloat64(); price > 0 {
+			points = append(points, DataPoint{Timestamp: candle.OpenTime, Value: price})
+		}
+	}
+	return points, nil
+}
+
+// cachedWidget returns a computed widget result that has not expired
+func (dm *DashboardManager) cachedWidget(key string, now time.Time) (interface{}, bool) {
+	dm.cacheMu.Lock()
+	defer dm.cacheMu.Unlock()
+
+	entry, exists := dm.widgetCache[key]
+	if !exists || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// cacheWidget stores a computed widget result for the cache TTL, evicting expired results
+func (dm *DashboardManager) cacheWidget(key string, data interface{}, now time.Time) {
+	ttl := defaultWidgetCacheTTL
+	if dm.config != nil && dm.config.WidgetCacheTTL > 0 {
+		ttl = dm.config.WidgetCacheTTL
+	}
+
+	dm.cacheMu.Lock()
+	defer dm.cacheMu.Unlock()
+
+	for cachedKey, entry := range dm.widgetCache {
+		if !now.Before(entry.expiresAt) {
+			delete(dm.widgetCache, cachedKey)
+		}
+	}
+	dm.widgetCache[key] = cachedWidgetData{data: data, expiresAt: now.Add(ttl)}
+}
+
+// bucketMeans averages points over buckets of interval, keyed by bucket start
+func bucketMeans(points []DataPoint, interval time.Duration) map[int64]float64 {
+	sums := make(map[int64]float64)
+	counts := make(map[int64]int)
+	for _, point := range points {
+		bucket := point.Timestamp.Truncate(interval).UnixNano()
+		sums[bucket] += point.Value
+		counts[bucket]++
+	}
+	for bucket, count := range counts {
+		sums[bucket] /= float64(count)
+	}
+	return sums
+}
+
+// bucketChanges returns the relative change of each bucket from the one before it. Buckets
+// without a predecessor, or following a zero, have no change.
+func bucketChanges(buckets map[int64]float64, interval time.Duration) map[int64]float64 {
+	changes := make(map[int64]float64, len(buckets))
+	for bucket, value := range buckets {
+		if previous, ok := buckets[bucket-int64(interval)]; ok && previous != 0 {
+			changes[bucket] = value/previous - 1
+		}
+	}
+	return changes
+}
+
+// pairedValues returns the values of the buckets a and b have in common, in time order
+func pairedValues(a, b map[int64]float64) ([]float64, []float64) {
+	buckets := make([]int64, 0, len(a))
+	for bucket := range a {
+		if _, ok := b[bucket]; ok {
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	x := make([]float64, len(buckets))
+	y := make([]float64, len(buckets))
+	for i, bucket := range buckets {
+		x[i], y[i] = a[bucket], b[bucket]
+	}
+	return x, y
+}
+
+// pearson returns the correlation coefficient of x and y, which is undefined when either is
+// constant
+func pearson(x, y []float64) (float64, bool) {
+	meanX, meanY := mean(x), mean(y)
+	var covariance, varianceX, varianceY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+	r := covariance / math.Sqrt(varianceX*varianceY)
+	return math.Max(-1, math.Min(1, r)), true
+}
+
+// stringsParameter reads a list of strings from widget parameters, as set in code or decoded
+// from JSON
+func stringsParameter(parameters map[string]interface{}, name string) []string {
+	switch value := parameters[name].(type) {
+	case []string:
+		return value
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	case string:
+		return strings.Split(value, ",")
+	}
+	return nil
+}
+
+// intParameter reads an integer from widget parameters, as set in code or decoded from JSON
+func intParameter(parameters map[string]interface{}, name string) int {
+	switch value := parameters[name].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+func numberLabels(first, last int, format string) []string {
+	labels := make([]string, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		labels = append(labels, fmt.Sprintf(format, i))
+	}
+	return labels
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,265 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+func newTestDashboardManager(analyticsConfig *AnalyticsConfig) *DashboardManager {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "error",
+	})
+	if analyticsConfig.MetricsRetentionPeriod == 0 {
+		analyticsConfig.MetricsRetentionPeriod = 60 * 24 * time.Hour
+	}
+	return NewDashboardManager(logger, analyticsConfig)
+}
+
+// widgetDataStart is midnight UTC two weeks ago, within the test retention period
+func widgetDataStart() time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -14)
+}
+
+func TestCorrelationMatrix(t *testing.T) {
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	start := widgetDataStart()
+	for i := 0; i < 48; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		load := math.Sin(float64(i) / 5)
+		dm.RecordMetric("cpu_usage", 50+10*load, at)
+		dm.RecordMetric("response_time", 200+80*load, at.Add(time.Minute))
+		dm.RecordMetric("throughput", 1000-300*load, at)
+		if i < 4 {
+			dm.RecordMetric("queue_depth", float64(i), at)
+		}
+	}
+
+	matrix, err := dm.ComputeCorrelationMatrix(context.Background(), CorrelationMatrixQuery{
+		Series:    []string{"cpu_usage", "response_time", "throughput", "queue_depth"},
+		TimeRange: TimeRange{From: start, To: start.Add(48 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compute correlation matrix: %v", err)
+	}
+
+	if r := matrix.Matrix[0][1]; r == nil || math.Abs(*r-1) > 1e-9 {
+		t.Errorf("Expected CPU and response time fully correlated, got %v", r)
+	}
+	if r := matrix.Matrix[2][0]; r == nil || math.Abs(*r+1) > 1e-9 {
+		t.Errorf("Expected throughput inversely correlated with CPU, got %v", r)
+	}
+	if r := matrix.Matrix[1][1]; r == nil || *r != 1 {
+		t.Errorf("Expected 1 on the diagonal, got %v", r)
+	}
+	for i := range matrix.Series {
+		if matrix.Matrix[3][i] != nil || matrix.Matrix[i][3] != nil {
+			t.Errorf("Expected null for queue depth with %s, got a value", matrix.Series[i])
+		}
+	}
+	if overlap := matrix.Overlap[0][3]; overlap != 4 {
+		t.Errorf("Expected 4 overlapping buckets with queue depth, got %d", overlap)
+	}
+	if matrix.Interval != "1h" || matrix.MinOverlap != defaultCorrelationOverlap {
+		t.Errorf("Expected the default interval and overlap, got %s and %d", matrix.Interval, matrix.MinOverlap)
+	}
+}
+
+func TestCorrelationMatrixOfSymbolReturns(t *testing.T) {
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	start := widgetDataStart()
+
+	// ETH moves twice as much as BTC each day, SOL is flat
+	btc, eth, sol := []float64{100}, []float64{10}, []float64{50}
+	for i := 1; i < 15; i++ {
+		change := 0.01 * float64(i%4-1)
+		btc = append(btc, btc[i-1]*(1+change))
+		eth = append(eth, eth[i-1]*(1+2*change))
+		sol = append(sol, 50)
+	}
+	dm.SetCandleSource(&stubCandleSource{start: start, closes: map[string][]float64{"BTCUSDT": btc, "ETHUSDT": eth, "SOLUSDT": sol}})
+
+	matrix, err := dm.ComputeCorrelationMatrix(context.Background(), CorrelationMatrixQuery{
+		Series:     []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+		TimeRange:  TimeRange{Relative: "30d"},
+		Interval:   24 * time.Hour,
+		MinOverlap: 5,
+		Returns:    true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to compute correlation matrix: %v", err)
+	}
+	if r := matrix.Matrix[0][1]; r == nil || math.Abs(*r-1) > 1e-9 {
+		t.Errorf("Expected BTC and ETH returns fully correlated, got %v", r)
+	}
+	if matrix.Overlap[0][1] != 14 {
+		t.Errorf("Expected 14 daily returns in common, got %d", matrix.Overlap[0][1])
+	}
+	if matrix.Matrix[0][2] != nil {
+		t.Errorf("Expected null correlation with a flat series, got %v", *matrix.Matrix[0][2])
+	}
+}
+
+func TestCorrelationMatrixCache(t *testing.T) {
+	ctx := context.Background()
+	query := CorrelationMatrixQuery{Series: []string{"a", "b"}, TimeRange: TimeRange{Relative: "7d"}, MinOverlap: 3}
+	record := func(dm *DashboardManager, b float64) {
+		start := time.Now().Add(-6 * time.Hour)
+		for i, a := range []float64{1, 2, 3, 4} {
+			at := start.Add(time.Duration(i) * time.Hour)
+			dm.RecordMetric("a", a, at)
+			dm.RecordMetric("b", b*a, at)
+		}
+	}
+
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	record(dm, 1)
+	first, err := dm.ComputeCorrelationMatrix(ctx, query)
+	if err != nil {
+		t.Fatalf("Failed to compute correlation matrix: %v", err)
+	}
+	record(dm, -1)
+	second, _ := dm.ComputeCorrelationMatrix(ctx, query)
+	if first.Cached || !second.Cached || *second.Matrix[0][1] != *first.Matrix[0][1] {
+		t.Errorf("Expected the second matrix served from cache, got cached=%t", second.Cached)
+	}
+
+	expiring := newTestDashboardManager(&AnalyticsConfig{WidgetCacheTTL: time.Nanosecond})
+	record(expiring, 1)
+	expiring.ComputeCorrelationMatrix(ctx, query)
+	time.Sleep(time.Millisecond)
+	if again, _ := expiring.ComputeCorrelationMatrix(ctx, query); again.Cached {
+		t.Error("Expected an expired matrix to be recomputed")
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	start := widgetDataStart()
+	for i := 0; i < 14*24; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		if at.Weekday() == time.Saturday {
+			continue
+		}
+		weekday := (int(at.Weekday()) + 6) % 7
+		dm.RecordMetric("trading_volume", float64(at.Hour()+100*weekday), at)
+	}
+
+	heatmap, err := dm.ComputeHeatmap(context.Background(), HeatmapQuery{
+		Metric:    "trading_volume",
+		TimeRange: TimeRange{From: start, To: start.AddDate(0, 0, 14)},
+		X:         DimensionHourOfDay,
+		Y:         DimensionDayOfWeek,
+	})
+	if err != nil {
+		t.Fatalf("Failed to compute heatmap: %v", err)
+	}
+
+	if len(heatmap.XLabels) != 24 || len(heatmap.YLabels) != 7 || heatmap.YLabels[0] != "Mon" {
+		t.Fatalf("Expected hours by weekdays from Monday, got %v by %v", heatmap.XLabels, heatmap.YLabels)
+	}
+	if cell := heatmap.Values[2][14]; cell == nil || *cell != 214 || heatmap.Counts[2][14] != 2 {
+		t.Errorf("Expected Wednesday 14:00 to average 214 over 2 samples, got %v", cell)
+	}
+	for x, cell := range heatmap.Values[5] {
+		if cell != nil {
+			t.Errorf("Expected no Saturday data at %s, got %.0f", heatmap.XLabels[x], *cell)
+		}
+	}
+	if *heatmap.Min != 0 || *heatmap.Max != 623 {
+		t.Errorf("Expected values from 0 to 623, got %.0f to %.0f", *heatmap.Min, *heatmap.Max)
+	}
+
+	counts, _ := dm.ComputeHeatmap(context.Background(), HeatmapQuery{
+		Metric:      "trading_volume",
+		TimeRange:   TimeRange{From: start, To: start.AddDate(0, 0, 14)},
+		X:           DimensionHourOfDay,
+		Y:           DimensionDayOfWeek,
+		Aggregation: HeatmapAggregationCount,
+		Timezone:    "Asia/Tokyo",
+	})
+	if counts.Timezone != "Asia/Tokyo" || counts.Values[5][0] == nil || *counts.Values[5][0] != 2 {
+		t.Errorf("Expected Friday evening UTC to land on Saturday morning in Tokyo, got %v", counts.Values[5][0])
+	}
+}
+
+func TestComputeWidgetData(t *testing.T) {
+	ctx := context.Background()
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	start := time.Now().Add(-2 * time.Hour)
+	for i := 0; i < 5; i++ {
+		dm.RecordMetric("cpu_usage", float64(i), start.Add(time.Duration(i)*time.Minute))
+	}
+	dashboard := &Dashboard{Name: "Operations", Widgets: []*Widget{
+		{WidgetID: "load", Name: "Load by hour", DataSource: &WidgetDataSource{
+			Type:       DataSourceTypeHeatmap,
+			MetricName: "cpu_usage",
+			TimeRange:  TimeRange{Relative: "1d"},
+			Parameters: map[string]interface{}{"x": "hour_of_day", "y": "day_of_week"},
+		}},
+		{WidgetID: "cpu", Name: "CPU", DataSource: &WidgetDataSource{Type: DataSourceTypeMetrics, MetricName: "cpu_usage"}},
+	}}
+	if err := dm.CreateDashboard(dashboard); err != nil {
+		t.Fatalf("Failed to create dashboard: %v", err)
+	}
+
+	data, err := dm.ComputeWidgetData(ctx, dashboard.DashboardID, "load")
+	if err != nil {
+		t.Fatalf("Failed to compute widget data: %v", err)
+	}
+	heatmap, ok := data.(*Heatmap)
+	if !ok || heatmap.Aggregation != HeatmapAggregationAvg {
+		t.Fatalf("Expected an average heatmap, got %+v", data)
+	}
+	local := start.UTC()
+	if cell := heatmap.Values[(int(local.Weekday())+6)%7][local.Hour()]; cell == nil {
+		t.Error("Expected the recorded CPU samples in the heatmap")
+	}
+
+	if _, err := dm.ComputeWidgetData(ctx, dashboard.DashboardID, "cpu"); !errors.Is(err, ErrInvalidWidgetQuery) {
+		t.Errorf("Expected ErrInvalidWidgetQuery for a plain metric widget, got %v", err)
+	}
+	if _, err := dm.ComputeWidgetData(ctx, dashboard.DashboardID, "missing"); !errors.Is(err, ErrWidgetNotFound) {
+		t.Errorf("Expected ErrWidgetNotFound, got %v", err)
+	}
+	if _, err := dm.ComputeWidgetData(ctx, "missing", "load"); !errors.Is(err, ErrDashboardNotFound) {
+		t.Errorf("Expected ErrDashboardNotFound, got %v", err)
+	}
+}
+
+func TestWidgetQueryValidation(t *testing.T) {
+	ctx := context.Background()
+	dm := newTestDashboardManager(&AnalyticsConfig{})
+	week := TimeRange{Relative: "7d"}
+
+	correlations := []CorrelationMatrixQuery{
+		{Series: []string{"a"}, TimeRange: week},
+		{Series: []string{"a", "a"}, TimeRange: week},
+		{Series: []string{"a", "b"}, TimeRange: week, MinOverlap: 2},
+		{Series: []string{"a", "b"}, TimeRange: TimeRange{Relative: "soon"}},
+		{Series: []string{"a", "b"}, TimeRange: TimeRange{Relative: "365d"}, Interval: time.Minute},
+	}
+	for _, query := range correlations {
+		if _, err := dm.ComputeCorrelationMatrix(ctx, query); !errors.Is(err, ErrInvalidWidgetQuery) {
+			t.Errorf("Expected ErrInvalidWidgetQuery for %+v, got %v", query, err)
+		}
+	}
+
+	heatmaps := []HeatmapQuery{
+		{TimeRange: week, X: DimensionHourOfDay, Y: DimensionDayOfWeek},
+		{Metric: "a", TimeRange: week, X: DimensionHourOfDay, Y: DimensionHourOfDay},
+		{Metric: "a", TimeRange: week, X: DimensionHourOfDay, Y: DimensionDayOfWeek, Aggregation: "median"},
+		{Metric: "a", TimeRange: week, X: DimensionHourOfDay, Y: DimensionDayOfWeek, Timezone: "Mars/Olympus"},
+	}
+	for _, query := range heatmaps {
+		if _, err := dm.ComputeHeatmap(ctx, query); !errors.Is(err, ErrInvalidWidgetQuery) {
+			t.Errorf("Expected ErrInvalidWidgetQuery for %+v, got %v", query, err)
+		}
+	}
+}
@@ -20,6 +20,8 @@ type PortfolioAnalytics struct {
 	dataRetention  time.Duration
 	updateInterval time.Duration
 	cache          map[uuid.UUID]*PortfolioMetrics
+	snapshots      SnapshotStore
+	benchmarks     BenchmarkCandleSource
 }
 
 // PortfolioMetrics contains comprehensive portfolio performance metrics
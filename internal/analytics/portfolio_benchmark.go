@@ -0,0 +1,295 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/google/uuid"
+)
+
+const (
+	// benchmarkExchange is the exchange benchmark prices are taken from
+	benchmarkExchange = "binance"
+	// benchmarkQuote is appended to basket symbols given without a quote currency
+	benchmarkQuote = "USDT"
+	// maxBenchmarkComponents bounds the size of a custom basket
+	maxBenchmarkComponents = 10
+	// hourlyBenchmarkRange is the longest range compared on hourly rather than daily returns
+	hourlyBenchmarkRange = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidBenchmark is returned for unknown benchmarks and malformed baskets
+	ErrInvalidBenchmark = errors.New("invalid benchmark")
+	// ErrBenchmarkUnavailable is returned when no benchmark price source is configured
+	ErrBenchmarkUnavailable = errors.New("benchmark prices are not available")
+)
+
+// namedBenchmarks are the predefined benchmarks by the name accepted in queries
+var namedBenchmarks = map[string][]BenchmarkComponent{
+	"btc":   {{Symbol: "BTCUSDT", Weight: 1}},
+	"eth":   {{Symbol: "ETHUSDT", Weight: 1}},
+	"60_40": {{Symbol: "BTCUSDT", Weight: 0.6}, {Symbol: "ETHUSDT", Weight: 0.4}},
+}
+
+// BenchmarkCandleSource loads historical candles, such as the realtime candle service
+type BenchmarkCandleSource interface {
+	GetCandles(ctx context.Context, exchange, symbol, interval string, from, to time.Time) ([]realtime.Candle, error)
+}
+
+// BenchmarkComponent is one asset of a benchmark with its share of the starting value
+type BenchmarkComponent struct {
+	Symbol string  `json:"symbol"`
+	Weight float64 `json:"weight"`
+}
+
+// BenchmarkPoint compares the portfolio and benchmark values, both indexed to 1 at the start
+type BenchmarkPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Portfolio float64   `json:"portfolio"`
+	Benchmark float64   `json:"benchmark"`
+	Relative  float64   `json:"relative"` // portfolio / benchmark
+}
+
+// PortfolioBenchmarkReport measures a portfolio against a benchmark over a range. Returns are
+// fractions over the range; alpha and tracking error are annualized over 365 days.
+type PortfolioBenchmarkReport struct {
+	PortfolioID uuid.UUID            `json:"portfolio_id"`
+	Benchmark   string               `json:"benchmark"`
+	Components  []BenchmarkComponent `json:"components"`
+	Range       string               `json:"range"`
+	Interval    string               `json:"interval"`
+	Start       time.Time            `json:"start"`
+	End         time.Time            `json:"end"`
+	Truncated   bool                 `json:"truncated"` // the portfolio is younger than the range
+
+	PortfolioReturn  float64 `json:"portfolio_return"`
+	BenchmarkReturn  float64 `json:"benchmark_return"`
+	ExcessReturn     float64 `json:"excess_return"`
+	Alpha            float64 `json:"alpha"`
+	Beta             float64 `json:"beta"`
+	Correlation      float64 `json:"correlation"`
+	TrackingError    float64 `json:"tracking_error"`
+	RelativeDrawdown float64 `json:"relative_drawdown"` // largest fall of the relative value from its peak
+
+	Points []BenchmarkPoint `json:"points"`
+}
+
+// SetBenchmarkSource sets where benchmark prices for GetBenchmarkComparison are loaded from
+func (p *PortfolioAnalytics) SetBenchmarkSource(source BenchmarkCandleSource) {
+	p.benchmarks = source
+}
+
+// ParseBenchmark resolves a benchmark name (btc, eth or 60_40, a 60/40 BTC/ETH index) or a
+// weighted basket such as "BTC:0.5,ETH:0.3,SOL:0.2". Basket weights are normalized to sum to 1.
+func ParseBenchmark(spec string) ([]BenchmarkComponent, error) {
+	spec = strings.TrimSpace(spec)
+	if components, ok := namedBenchmarks[strings.ToLower(spec)]; ok {
+		return append([]BenchmarkComponent(nil), components...), nil
+	}
+	if !strings.Contains(spec, ":") {
+		return nil, fmt.Errorf("%w: %q, use btc, eth, 60_40 or a basket such as BTC:0.5,ETH:0.5", ErrInvalidBenchmark, spec)
+	}
+
+	parts := strings.Split(spec, ",")
+	if len(parts) > maxBenchmarkComponents {
+		return nil, fmt.Errorf("%w: a basket may hold at most %d assets", ErrInvalidBenchmark, maxBenchmarkComponents)
+	}
+	weights := make(map[string]float64, len(parts))
+	total := 0.0
+	for _, part := range parts {
+		symbol, weightStr, _ := strings.Cut(part, ":")
+		symbol = strings.ToUpper(strings.NewReplacer("-", "", "/", "", "_", "").Replace(strings.TrimSpace(symbol)))
+		weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+		if symbol == "" || err != nil || weight <= 0 || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("%w: bad basket entry %q", ErrInvalidBenchmark, part)
+		}
+		if !strings.HasSuffix(symbol, benchmarkQuote) {
+			symbol += benchmarkQuote
+		}
+		weights[symbol] += weight
+		total += weight
+	}
+
+	components := make([]BenchmarkComponent, 0, len(weights))
+	for symbol, weight := range weights {
+		components = append(components, BenchmarkComponent{Symbol: symbol, Weight: weight / total})
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Symbol < components[j].Symbol
+	})
+	return components, nil
+}
+
+// GetBenchmarkComparison compares the portfolio's value history over the trailing range with a
+// buy-and-hold benchmark whose weights are set at the start and not rebalanced. Ranges up to 7
+// days use hourly returns, longer ones daily returns. A portfolio younger than the range is
+// compared from its creation.
+func (p *PortfolioAnalytics) GetBenchmarkComparison(ctx context.Context, portfolioID uuid.UUID, benchmark string, historyRange time.Duration) (*PortfolioBenchmarkReport, error) {
+	components, err := ParseBenchmark(benchmark)
+	if err != nil {
+		return nil, err
+	}
+	if p.snapshots == nil {
+		return nil, ErrHistoryUnavailable
+	}
+	if p.benchmarks == nil {
+		return nil, ErrBenchmarkUnavailable
+	}
+	if historyRange <= 0 || historyRange > p.dataRetention {
+		return nil, fmt.Errorf("%w: range must be positive and at most %s", ErrInvalidHistoryQuery, FormatHistoryDuration(p.dataRetention))
+	}
+
+	portfolio, err := p.tradingEngine.GetPortfolio(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	interval, step := "1d", 24*time.Hour
+	periodsPerYear := 365.0
+	if historyRange <= hourlyBenchmarkRange {
+		interval, step = "1h", time.Hour
+		periodsPerYear = 365 * 24
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-historyRange)
+	truncated := false
+	if created := portfolio.CreatedAt.UTC(); created.After(start) {
+		start, truncated = created, true
+	}
+	// Buckets line up with the candles' open times
+	bucketStart := start.Truncate(step)
+
+	snapshots, err := p.snapshots.ListSnapshots(ctx, portfolioID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load portfolio history: %w", err)
+	}
+
+	prices := make([]map[int64]float64, len(components))
+	for i, component := range components {
+		candles, err := p.benchmarks.GetCandles(ctx, benchmarkExchange, component.Symbol, interval, bucketStart, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s benchmark prices: %w", component.Symbol, err)
+		}
+		prices[i] = make(map[int64]float64, len(candles))
+		for _, candle := range candles {
+			if price := candle.Close.InexactFloat64(); price > 0 {
+				prices[i][candle.OpenTime.UnixMilli()] = price
+			}
+		}
+	}
+
+	report := &PortfolioBenchmarkReport{
+		PortfolioID: portfolioID,
+		Benchmark:   strings.ToLower(strings.TrimSpace(benchmark)),
+		Components:  components,
+		Range:       FormatHistoryDuration(historyRange),
+		Interval:    interval,
+		Start:       start,
+		End:         end,
+		Truncated:   truncated,
+		Points:      make([]BenchmarkPoint, 0),
+	}
+
+	// Each bucket pairs the portfolio's latest value with the benchmark close
+	var startPrices []float64
+	var startValue float64
+	for _, point := range downsampleSnapshots(snapshots, bucketStart, step) {
+		value := point.Value.InexactFloat64()
+		bucketPrices := make([]float64, len(components))
+		complete := value > 0
+		for i := range components {
+			bucketPrices[i] = prices[i][point.Timestamp.UnixMilli()]
+			complete = complete && bucketPrices[i] > 0
+		}
+		if !complete {
+			continue
+		}
+		if startPrices == nil {
+			startPrices, startValue = bucketPrices, value
+		}
+
+		benchmarkIndex := 0.0
+		for i, component := range components {
+			benchmarkIndex += component.Weight * bucketPrices[i] / startPrices[i]
+		}
+		portfolioIndex := value / startValue
+		report.Points = append(report.Points, BenchmarkPoint{
+			Timestamp: point.Timestamp,
+			Portfolio: portfolioIndex,
+			Benchmark: benchmarkIndex,
+			Relative:  portfolioIndex / benchmarkIndex,
+		})
+	}
+
+	computeBenchmarkMetrics(report, periodsPerYear)
+	return report, nil
+}
+
+// computeBenchmarkMetrics fills the report's metrics from its points. Fewer than three points
+// leave the regression metrics at zero.
+func computeBenchmarkMetrics(report *PortfolioBenchmarkReport, periodsPerYear float64) {
+	points := report.Points
+	if len(points) < 2 {
+		return
+	}
+	last := points[len(points)-1]
+	report.PortfolioReturn = last.Portfolio - 1
+	report.BenchmarkReturn = last.Benchmark - 1
+	report.ExcessReturn = report.PortfolioReturn - report.BenchmarkReturn
+
+	peak := 0.0
+	for _, point := range points {
+		peak = math.Max(peak, point.Relative)
+		report.RelativeDrawdown = math.Max(report.RelativeDrawdown, (peak-point.Relative)/peak)
+	}
+
+	portfolioReturns := make([]float64, 0, len(points)-1)
+	benchmarkReturns := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		portfolioReturns = append(portfolioReturns, points[i].Portfolio/points[i-1].Portfolio-1)
+		benchmarkReturns = append(benchmarkReturns, points[i].Benchmark/points[i-1].Benchmark-1)
+	}
+	if len(portfolioReturns) < 2 {
+		return
+	}
+
+	portfolioMean, benchmarkMean := mean(portfolioReturns), mean(benchmarkReturns)
+	var covariance, portfolioVariance, benchmarkVariance, activeVariance float64
+	activeMean := portfolioMean - benchmarkMean
+	for i := range portfolioReturns {
+		dp := portfolioReturns[i] - portfolioMean
+		db := benchmarkReturns[i] - benchmarkMean
+		da := portfolioReturns[i] - benchmarkReturns[i] - activeMean
+		covariance += dp * db
+		portfolioVariance += dp * dp
+		benchmarkVariance += db * db
+		activeVariance += da * da
+	}
+	n := float64(len(portfolioReturns) - 1)
+
+	if benchmarkVariance > 0 {
+		report.Beta = covariance / benchmarkVariance
+	}
+	if portfolioVariance > 0 && benchmarkVariance > 0 {
+		report.Correlation = covariance / math.Sqrt(portfolioVariance*benchmarkVariance)
+	}
+	report.Alpha = (portfolioMean - report.Beta*benchmarkMean) * periodsPerYear
+	report.TrackingError = math.Sqrt(activeVariance/n) * math.Sqrt(periodsPerYear)
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,117 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/shopspring/decimal"
+)
+
+// stubCandleSource serves daily candles from a fixed close series per symbol
+type stubCandleSource struct {
+	start  time.Time
+	closes map[string][]float64
+}
+
+func (s *stubCandleSource) GetCandles(ctx context.Context, exchange, symbol, interval string, from, to time.Time) ([]realtime.Candle, error) {
+	candles := make([]realtime.Candle, 0)
+	for i, price := range s.closes[symbol] {
+		openTime := s.start.Add(time.Duration(i) * 24 * time.Hour)
+		if openTime.Before(from) || !openTime.Before(to) {
+			continue
+		}
+		candles = append(candles, realtime.Candle{Symbol: symbol, OpenTime: openTime, Close: decimal.NewFromFloat(price), Closed: true})
+	}
+	return candles, nil
+}
+
+func TestPortfolioBenchmarkComparison(t *testing.T) {
+	portfolioAnalytics, portfolio, store := newTestPortfolioAnalytics(t)
+	ctx := context.Background()
+
+	// The portfolio is nine days old and moves twice as much as BTC each day
+	created := time.Now().UTC().Truncate(24 * time.Hour).Add(-8 * 24 * time.Hour)
+	portfolio.CreatedAt = created
+	btc := []float64{100, 102, 101, 104, 103, 105, 108, 107, 110}
+	eth := []float64{10, 10, 10, 10, 10, 10, 10, 10, 10}
+	value := 1000.0
+	for i := range btc {
+		if i > 0 {
+			value *= 1 + 2*(btc[i]/btc[i-1]-1)
+		}
+		store.SaveSnapshot(ctx, &PortfolioValueSnapshot{
+			PortfolioID: portfolio.ID,
+			TotalValue:  decimal.NewFromFloat(value),
+			RecordedAt:  created.Add(time.Duration(i)*24*time.Hour + time.Minute),
+		})
+	}
+
+	if _, err := portfolioAnalytics.GetBenchmarkComparison(ctx, portfolio.ID, "btc", 90*24*time.Hour); !errors.Is(err, ErrBenchmarkUnavailable) {
+		t.Fatalf("Expected benchmark unavailable without a candle source, got %v", err)
+	}
+	portfolioAnalytics.SetBenchmarkSource(&stubCandleSource{
+		start:  created,
+		closes: map[string][]float64{"BTCUSDT": btc, "ETHUSDT": eth},
+	})
+
+	report, err := portfolioAnalytics.GetBenchmarkComparison(ctx, portfolio.ID, "btc", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to compare with benchmark: %v", err)
+	}
+	if !report.Truncated || !report.Start.Equal(created) || report.Interval != "1d" {
+		t.Errorf("Expected daily comparison truncated to creation, got truncated=%v start=%v interval=%s", report.Truncated, report.Start, report.Interval)
+	}
+	if len(report.Points) != len(btc) {
+		t.Fatalf("Expected %d points, got %d", len(btc), len(report.Points))
+	}
+	if math.Abs(report.Beta-2) > 1e-6 || math.Abs(report.Correlation-1) > 1e-6 {
+		t.Errorf("Expected beta 2 and correlation 1, got %f and %f", report.Beta, report.Correlation)
+	}
+	if math.Abs(report.Alpha) > 1e-6 {
+		t.Errorf("Expected no alpha for a leveraged benchmark, got %f", report.Alpha)
+	}
+	if report.TrackingError <= 0 || report.RelativeDrawdown <= 0 {
+		t.Errorf("Expected tracking error and relative drawdown, got %f and %f", report.TrackingError, report.RelativeDrawdown)
+	}
+	if math.Abs(report.BenchmarkReturn-0.1) > 1e-9 || report.ExcessReturn <= 0 {
+		t.Errorf("Expected 10%% benchmark return and positive excess return, got %f and %f", report.BenchmarkReturn, report.ExcessReturn)
+	}
+
+	// Flat ETH halves the 50/50 basket's moves
+	basket, err := portfolioAnalytics.GetBenchmarkComparison(ctx, portfolio.ID, "btc:1,eth/usdt:1", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to compare with basket: %v", err)
+	}
+	if math.Abs(basket.BenchmarkReturn-0.05) > 1e-9 {
+		t.Errorf("Expected 5%% basket return, got %f", basket.BenchmarkReturn)
+	}
+
+	if _, err := portfolioAnalytics.GetBenchmarkComparison(ctx, portfolio.ID, "spx", 90*24*time.Hour); !errors.Is(err, ErrInvalidBenchmark) {
+		t.Errorf("Expected invalid benchmark error, got %v", err)
+	}
+}
+
+func TestParseBenchmark(t *testing.T) {
+	components, err := ParseBenchmark("60_40")
+	if err != nil || len(components) != 2 || components[0].Weight != 0.6 {
+		t.Errorf("Unexpected 60/40 benchmark %v, %v", components, err)
+	}
+
+	components, err = ParseBenchmark("SOL:2, btc-usdt:1, SOL:1")
+	if err != nil {
+		t.Fatalf("Failed to parse basket: %v", err)
+	}
+	if len(components) != 2 || components[0].Symbol != "BTCUSDT" || components[1].Symbol != "SOLUSDT" || components[1].Weight != 0.75 {
+		t.Errorf("Unexpected basket %v", components)
+	}
+
+	for _, spec := range []string{"", "btc:", "btc:-1", ":1", "btc:abc"} {
+		if _, err := ParseBenchmark(spec); !errors.Is(err, ErrInvalidBenchmark) {
+			t.Errorf("Expected invalid benchmark error for %q, got %v", spec, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package analytics
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// ExportFormat selects the file format of a portfolio export
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// ErrInvalidExportFormat is returned for export formats other than csv or xlsx
+var ErrInvalidExportFormat = errors.New("invalid export format")
+
+// tradeExportHeader lists the columns of exported trade rows
+var tradeExportHeader = []string{"timestamp", "symbol", "side", "quantity", "price", "fees", "realized_pnl"}
+
+// ParseExportFormat validates a requested export format, defaulting to CSV when empty
+func ParseExportFormat(format string) (ExportFormat, error) {
+	switch ExportFormat(strings.ToLower(strings.TrimSpace(format))) {
+	case "", ExportFormatCSV:
+		return ExportFormatCSV, nil
+	case ExportFormatXLSX:
+		return ExportFormatXLSX, nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected csv or xlsx", ErrInvalidExportFormat, format)
+	}
+}
+
+// ContentType returns the MIME type of files in the format
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// PortfolioExport is the trade history and summary metrics of a portfolio prepared for export
+type PortfolioExport struct {
+	Metrics     *PortfolioMetrics
+	Trades      []web3.TradeRecord
+	GeneratedAt time.Time
+}
+
+// GetPortfolioExport collects the trade history and metrics of a portfolio for export
+func (p *PortfolioAnalytics) GetPortfolioExport(ctx context.Context, portfolioID uuid.UUID) (*PortfolioExport, error) {
+	metrics, err := p.GetPortfolioMetrics(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := p.tradingEngine.GetTradeHistory(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade history: %w", err)
+	}
+
+	return &PortfolioExport{
+		Metrics:     metrics,
+		Trades:      trades,
+		GeneratedAt: time.Now().UTC(),
+	}, nil
+}
+
+// FileName returns the suggested download name of the export
+func (e *PortfolioExport) FileName(format ExportFormat) string {
+	return fmt.Sprintf("portfolio-%s-%s.%s", e.Metrics.PortfolioID, e.GeneratedAt.Format("20060102T150405Z"), format)
+}
+
+// Write writes the export to w in the given format
+func (e *PortfolioExport) Write(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportFormatCSV:
+		return e.WriteCSV(w)
+	case ExportFormatXLSX:
+		return e.WriteXLSX(w)
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidExportFormat, format)
+	}
+}
+
+// WriteCSV streams the trade rows to w, followed by a blank line and the summary metrics as
+// metric,value rows. Rows are written as they are produced rather than buffered.
+func (e *PortfolioExport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(tradeExportHeader); err != nil {
+		return err
+	}
+	for _, trade := range e.Trades {
+		if err := writer.Write(tradeExportRow(trade)); err != nil {
+			return err
+		}
+	}
+
+	// A blank line separates the summary section
+	writer.Flush()
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	if err := writer.Write([]string{"metric", "value"}); err != nil {
+		return err
+	}
+	for _, row := range e.summaryRows() {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX writes a workbook with a Trades sheet and a Summary sheet. Trade rows are written
+// through a stream writer; decimals are stored as text so no precision is lost.
+func (e *PortfolioExport) WriteXLSX(w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName("Sheet1", "Trades"); err != nil {
+		return err
+	}
+	stream, err := file.NewStreamWriter("Trades")
+	if err != nil {
+		return err
+	}
+	if err := stream.SetRow("A1", stringsToCells(tradeExportHeader)); err != nil {
+		return err
+	}
+	for i, trade := range e.Trades {
+		if err := stream.SetRow("A"+strconv.Itoa(i+2), stringsToCells(tradeExportRow(trade))); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := file.NewSheet("Summary"); err != nil {
+		return err
+	}
+	rows := append([][]string{{"metric", "value"}}, e.summaryRows()...)
+	for i, row := range rows {
+		if err := file.SetSheetRow("Summary", "A"+strconv.Itoa(i+1), &row); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
+
+// summaryRows returns the portfolio metrics as metric and value pairs
+func (e *PortfolioExport) summaryRows() [][]string {
+	metrics := e.Metrics
+
+	totalFees, realizedPnL := decimal.Zero, decimal.Zero
+	for _, trade := range e.Trades {
+		totalFees = totalFees.Add(trade.Fees)
+		realizedPnL = realizedPnL.Add(trade.RealizedPnL)
+	}
+
+	return [][]string{
+		{"portfolio_id", metrics.PortfolioID.String()},
+		{"name", metrics.Name},
+		{"generated_at", formatExportTime(e.GeneratedAt)},
+		{"metrics_updated_at", formatExportTime(metrics.LastUpdated)},
+		{"total_value", metrics.TotalValue.String()},
+		{"total_pnl", metrics.TotalPnL.String()},
+		{"total_pnl_percent", metrics.TotalPnLPercent.String()},
+		{"daily_pnl", metrics.DailyPnL.String()},
+		{"weekly_pnl", metrics.WeeklyPnL.String()},
+		{"monthly_pnl", metrics.MonthlyPnL.String()},
+		{"max_drawdown", metrics.MaxDrawdown.String()},
+		{"sharpe_ratio", metrics.SharpeRatio.String()},
+		{"sortino_ratio", metrics.SortinoRatio.String()},
+		{"volatility", metrics.Volatility.String()},
+		{"beta", metrics.Beta.String()},
+		{"alpha", metrics.Alpha.String()},
+		{"var_95", metrics.RiskMetrics.VaR95.String()},
+		{"var_99", metrics.RiskMetrics.VaR99.String()},
+		{"risk_grade", metrics.RiskMetrics.RiskGrade},
+		{"trade_count", strconv.Itoa(len(e.Trades))},
+		{"total_fees", totalFees.String()},
+		{"realized_pnl", realizedPnL.String()},
+	}
+}
+
+// tradeExportRow formats a trade in the column order of tradeExportHeader
+func tradeExportRow(trade web3.TradeRecord) []string {
+	return []string{
+		formatExportTime(trade.ExecutedAt),
+		trade.Symbol,
+		trade.Side,
+		trade.Quantity.String(),
+		trade.Price.String(),
+		trade.Fees.String(),
+		trade.RealizedPnL.String(),
+	}
+}
+
+// formatExportTime formats a timestamp as RFC3339 in UTC
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func stringsToCells(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return cells
+}
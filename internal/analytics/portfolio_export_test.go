@@ -0,0 +1,152 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+func testPortfolioExport() *PortfolioExport {
+	executedAt := time.Date(2024, 3, 1, 14, 30, 0, 0, time.FixedZone("CET", 3600))
+	return &PortfolioExport{
+		Metrics: &PortfolioMetrics{
+			PortfolioID: uuid.New(),
+			Name:        "export",
+			TotalValue:  decimal.RequireFromString("12345.123456789012345678"),
+			TotalPnL:    decimal.RequireFromString("-0.000000000000000001"),
+			LastUpdated: executedAt,
+		},
+		Trades: []web3.TradeRecord{
+			{
+				Symbol:      "ETH",
+				Side:        web3.TradeSideBuy,
+				Quantity:    decimal.RequireFromString("1.123456789012345678"),
+				Price:       decimal.RequireFromString("2000.5"),
+				Fees:        decimal.RequireFromString("0.001"),
+				RealizedPnL: decimal.Zero,
+				ExecutedAt:  executedAt,
+			},
+			{
+				Symbol:      "ETH",
+				Side:        web3.TradeSideSell,
+				Quantity:    decimal.RequireFromString("1.123456789012345678"),
+				Price:       decimal.RequireFromString("2100"),
+				Fees:        decimal.RequireFromString("0.002"),
+				RealizedPnL: decimal.RequireFromString("111.7839505067283949"),
+				ExecutedAt:  executedAt.Add(time.Hour),
+			},
+		},
+		GeneratedAt: executedAt.UTC(),
+	}
+}
+
+func TestPortfolioExportCSV(t *testing.T) {
+	export := testPortfolioExport()
+
+	var buf bytes.Buffer
+	if err := export.WriteCSV(&buf); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	trades, summary, found := strings.Cut(buf.String(), "\n\n")
+	if !found {
+		t.Fatalf("Expected a blank line between trades and summary, got %q", buf.String())
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(trades)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse trade rows: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected header and 2 trade rows, got %d", len(rows))
+	}
+	if strings.Join(rows[0], ",") != "timestamp,symbol,side,quantity,price,fees,realized_pnl" {
+		t.Errorf("Unexpected header %v", rows[0])
+	}
+	if rows[1][0] != "2024-03-01T13:30:00Z" {
+		t.Errorf("Expected RFC3339 UTC timestamp, got %s", rows[1][0])
+	}
+	if rows[1][3] != "1.123456789012345678" || rows[2][6] != "111.7839505067283949" {
+		t.Errorf("Decimal values lost precision: %v", rows[1:])
+	}
+
+	metrics, err := csv.NewReader(strings.NewReader(summary)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse summary rows: %v", err)
+	}
+	values := make(map[string]string)
+	for _, row := range metrics[1:] {
+		values[row[0]] = row[1]
+	}
+	if values["total_value"] != "12345.123456789012345678" || values["total_pnl"] != "-0.000000000000000001" {
+		t.Errorf("Unexpected summary values %v", values)
+	}
+	if values["trade_count"] != "2" || values["total_fees"] != "0.003" {
+		t.Errorf("Unexpected trade totals %v", values)
+	}
+}
+
+func TestPortfolioExportXLSX(t *testing.T) {
+	export := testPortfolioExport()
+
+	var buf bytes.Buffer
+	if err := export.WriteXLSX(&buf); err != nil {
+		t.Fatalf("Failed to write XLSX: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+	defer file.Close()
+
+	trades, err := file.GetRows("Trades")
+	if err != nil {
+		t.Fatalf("Failed to read trades sheet: %v", err)
+	}
+	if len(trades) != 3 {
+		t.Fatalf("Expected header and 2 trade rows, got %d", len(trades))
+	}
+	if trades[2][0] != "2024-03-01T14:30:00Z" || trades[2][3] != "1.123456789012345678" {
+		t.Errorf("Unexpected trade row %v", trades[2])
+	}
+
+	summary, err := file.GetRows("Summary")
+	if err != nil {
+		t.Fatalf("Failed to read summary sheet: %v", err)
+	}
+	if len(summary) < 2 || summary[0][0] != "metric" || summary[1][1] != export.Metrics.PortfolioID.String() {
+		t.Errorf("Unexpected summary sheet %v", summary)
+	}
+}
+
+func TestPortfolioExportFromEngine(t *testing.T) {
+	portfolioAnalytics, portfolio, _ := newTestPortfolioAnalytics(t)
+
+	export, err := portfolioAnalytics.GetPortfolioExport(context.Background(), portfolio.ID)
+	if err != nil {
+		t.Fatalf("Failed to get export: %v", err)
+	}
+	if export.Metrics.PortfolioID != portfolio.ID || len(export.Trades) != 0 {
+		t.Errorf("Unexpected export for a new portfolio: %+v", export)
+	}
+	if !strings.HasSuffix(export.FileName(ExportFormatXLSX), ".xlsx") {
+		t.Errorf("Unexpected file name %s", export.FileName(ExportFormatXLSX))
+	}
+
+	if _, err := ParseExportFormat("pdf"); !errors.Is(err, ErrInvalidExportFormat) {
+		t.Errorf("Expected invalid format error, got %v", err)
+	}
+	if format, err := ParseExportFormat(""); err != nil || format != ExportFormatCSV {
+		t.Errorf("Expected CSV default, got %s, %v", format, err)
+	}
+}
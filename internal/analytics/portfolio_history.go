@@ -0,0 +1,252 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// maxHistoryPoints bounds the number of points returned by a single history query
+	maxHistoryPoints = 2000
+	// minHistoryInterval is the finest downsampling interval accepted
+	minHistoryInterval = time.Minute
+)
+
+var (
+	// ErrHistoryUnavailable is returned when no snapshot store is configured
+	ErrHistoryUnavailable = errors.New("portfolio history is not available")
+	// ErrInvalidHistoryQuery is returned for an unsupported range or interval
+	ErrInvalidHistoryQuery = errors.New("invalid history query")
+)
+
+// PortfolioValueSnapshot is a point-in-time record of a portfolio's value, PnL and allocation
+type PortfolioValueSnapshot struct {
+	PortfolioID uuid.UUID                  `json:"portfolio_id"`
+	TotalValue  decimal.Decimal            `json:"total_value"`
+	TotalPnL    decimal.Decimal            `json:"total_pnl"`
+	Allocations map[string]decimal.Decimal `json:"allocations"`
+	RecordedAt  time.Time                  `json:"recorded_at"`
+}
+
+// SnapshotStore persists portfolio snapshots
+type SnapshotStore interface {
+	SaveSnapshot(ctx context.Context, snapshot *PortfolioValueSnapshot) error
+	// ListSnapshots returns the snapshots of a portfolio recorded in [from, to), oldest first
+	ListSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]PortfolioValueSnapshot, error)
+	DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// PortfolioHistoryPoint is a downsampled portfolio value sample
+type PortfolioHistoryPoint struct {
+	Timestamp   time.Time                  `json:"timestamp"`
+	Value       decimal.Decimal            `json:"value"`
+	PnL         decimal.Decimal            `json:"pnl"`
+	Allocations map[string]decimal.Decimal `json:"allocations"`
+}
+
+// PortfolioValueHistory is a portfolio value time series over a requested range
+type PortfolioValueHistory struct {
+	PortfolioID uuid.UUID               `json:"portfolio_id"`
+	Range       string                  `json:"range"`
+	Interval    string                  `json:"interval"`
+	Start       time.Time               `json:"start"`
+	End         time.Time               `json:"end"`
+	Points      []PortfolioHistoryPoint `json:"points"`
+}
+
+// SetSnapshotStore enables persistence of portfolio value history
+func (p *PortfolioAnalytics) SetSnapshotStore(store SnapshotStore) {
+	p.snapshots = store
+}
+
+// StartSampler records a snapshot of every portfolio on each interval until ctx is cancelled.
+// Snapshots older than the data retention period are pruned as they age out.
+func (p *PortfolioAnalytics) StartSampler(ctx context.Context, interval time.Duration) {
+	if p.snapshots == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = p.updateInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		p.SampleNow(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.SampleNow(ctx)
+			}
+		}
+	}()
+
+	p.logger.Info(ctx, "Portfolio history sampler started", map[string]interface{}{
+		"interval": interval.String(),
+	})
+}
+
+// SampleNow records a snapshot of every portfolio managed by the trading engine
+func (p *PortfolioAnalytics) SampleNow(ctx context.Context) {
+	if p.snapshots == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, portfolio := range p.tradingEngine.ListPortfolios() {
+		if err := p.snapshots.SaveSnapshot(ctx, newPortfolioValueSnapshot(portfolio, now)); err != nil {
+			p.logger.Error(ctx, "Failed to record portfolio snapshot", err, map[string]interface{}{
+				"portfolio_id": portfolio.ID.String(),
+			})
+		}
+	}
+
+	if _, err := p.snapshots.DeleteSnapshotsBefore(ctx, now.Add(-p.dataRetention)); err != nil {
+		p.logger.Error(ctx, "Failed to prune portfolio snapshots", err)
+	}
+}
+
+// GetValueHistory returns the portfolio value series over the trailing range, downsampled to
+// interval buckets. Each bucket carries its latest snapshot; empty buckets are omitted and the
+// series starts no earlier than the portfolio's creation time.
+func (p *PortfolioAnalytics) GetValueHistory(ctx context.Context, portfolioID uuid.UUID, historyRange, interval time.Duration) (*PortfolioValueHistory, error) {
+	if p.snapshots == nil {
+		return nil, ErrHistoryUnavailable
+	}
+	if historyRange <= 0 || historyRange > p.dataRetention {
+		return nil, fmt.Errorf("%w: range must be positive and at most %s", ErrInvalidHistoryQuery, FormatHistoryDuration(p.dataRetention))
+	}
+	if interval < minHistoryInterval || interval > historyRange {
+		return nil, fmt.Errorf("%w: interval must be between 1m and the requested range", ErrInvalidHistoryQuery)
+	}
+	if historyRange/interval > maxHistoryPoints {
+		return nil, fmt.Errorf("%w: range and interval yield more than %d points", ErrInvalidHistoryQuery, maxHistoryPoints)
+	}
+
+	portfolio, err := p.tradingEngine.GetPortfolio(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-historyRange)
+	if created := portfolio.CreatedAt.UTC(); created.After(start) {
+		start = created
+	}
+
+	snapshots, err := p.snapshots.ListSnapshots(ctx, portfolioID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load portfolio history: %w", err)
+	}
+
+	return &PortfolioValueHistory{
+		PortfolioID: portfolioID,
+		Range:       FormatHistoryDuration(historyRange),
+		Interval:    FormatHistoryDuration(interval),
+		Start:       start,
+		End:         end,
+		Points:      downsampleSnapshots(snapshots, start, interval),
+	}, nil
+}
+
+// downsampleSnapshots keeps the latest snapshot of every interval bucket counted from start.
+// Snapshots must be ordered oldest first.
+func downsampleSnapshots(snapshots []PortfolioValueSnapshot, start time.Time, interval time.Duration) []PortfolioHistoryPoint {
+	points := make([]PortfolioHistoryPoint, 0)
+	lastBucket := int64(-1)
+
+	for _, snapshot := range snapshots {
+		if snapshot.RecordedAt.Before(start) {
+			continue
+		}
+		bucket := int64(snapshot.RecordedAt.Sub(start) / interval)
+		point := PortfolioHistoryPoint{
+			Timestamp:   start.Add(time.Duration(bucket) * interval),
+			Value:       snapshot.TotalValue,
+			PnL:         snapshot.TotalPnL,
+			Allocations: snapshot.Allocations,
+		}
+		if bucket == lastBucket {
+			points[len(points)-1] = point
+			continue
+		}
+		points = append(points, point)
+		lastBucket = bucket
+	}
+
+	return points
+}
+
+// newPortfolioValueSnapshot captures the portfolio value and each holding's share of it in percent
+func newPortfolioValueSnapshot(portfolio *web3.Portfolio, recordedAt time.Time) *PortfolioValueSnapshot {
+	allocations := make(map[string]decimal.Decimal, len(portfolio.Holdings))
+	for symbol, holding := range portfolio.Holdings {
+		weight := decimal.Zero
+		if portfolio.TotalValue.IsPositive() {
+			weight = holding.Amount.Mul(holding.CurrentPrice).Div(portfolio.TotalValue).Mul(decimal.NewFromInt(100))
+		}
+		allocations[symbol] = weight.Round(4)
+	}
+
+	return &PortfolioValueSnapshot{
+		PortfolioID: portfolio.ID,
+		TotalValue:  portfolio.TotalValue,
+		TotalPnL:    portfolio.TotalPnL,
+		Allocations: allocations,
+		RecordedAt:  recordedAt,
+	}
+}
+
+// ParseHistoryDuration parses durations such as "30m", "1h", "7d" or "2w"
+func ParseHistoryDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return duration, nil
+	}
+
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return time.Duration(count) * unit, nil
+}
+
+// FormatHistoryDuration renders a duration in the notation accepted by ParseHistoryDuration
+func FormatHistoryDuration(duration time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case duration%day == 0:
+		return fmt.Sprintf("%dd", duration/day)
+	case duration%time.Hour == 0:
+		return fmt.Sprintf("%dh", duration/time.Hour)
+	case duration%time.Minute == 0:
+		return fmt.Sprintf("%dm", duration/time.Minute)
+	default:
+		return duration.String()
+	}
+}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// postgresSnapshotStore implements SnapshotStore using the portfolio_value_snapshots table
+type postgresSnapshotStore struct {
+	db *database.DB
+}
+
+func NewPostgresSnapshotStore(db *database.DB) SnapshotStore {
+	return &postgresSnapshotStore{db: db}
+}
+
+func (s *postgresSnapshotStore) SaveSnapshot(ctx context.Context, snapshot *PortfolioValueSnapshot) error {
+	allocations, err := json.Marshal(snapshot.Allocations)
+	if err != nil {
+		return fmt.Errorf("failed to encode allocations: %w", err)
+	}
+
+	query := `
+		INSERT INTO portfolio_value_snapshots (portfolio_id, total_value, total_pnl, allocations, recorded_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = s.db.ExecWithMetrics(ctx, query, snapshot.PortfolioID, snapshot.TotalValue, snapshot.TotalPnL, allocations, snapshot.RecordedAt)
+	return err
+}
+
+func (s *postgresSnapshotStore) ListSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]PortfolioValueSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT total_value, total_pnl, allocations, recorded_at FROM portfolio_value_snapshots
+		WHERE portfolio_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+		ORDER BY recorded_at
+	`, portfolioID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]PortfolioValueSnapshot, 0)
+	for rows.Next() {
+		snapshot := PortfolioValueSnapshot{PortfolioID: portfolioID}
+		var allocations []byte
+		if err := rows.Scan(&snapshot.TotalValue, &snapshot.TotalPnL, &allocations, &snapshot.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		snapshot.Allocations = make(map[string]decimal.Decimal)
+		if len(allocations) > 0 {
+			if err := json.Unmarshal(allocations, &snapshot.Allocations); err != nil {
+				return nil, fmt.Errorf("failed to decode allocations: %w", err)
+			}
+		}
+		snapshot.RecordedAt = snapshot.RecordedAt.UTC()
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (s *postgresSnapshotStore) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := s.db.ExecWithMetrics(ctx, `DELETE FROM portfolio_value_snapshots WHERE recorded_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,169 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// memorySnapshotStore is an in-memory SnapshotStore for tests
+type memorySnapshotStore struct {
+	snapshots []PortfolioValueSnapshot
+	mu        sync.Mutex
+}
+
+func (s *memorySnapshotStore) SaveSnapshot(ctx context.Context, snapshot *PortfolioValueSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots = append(s.snapshots, *snapshot)
+	return nil
+}
+
+func (s *memorySnapshotStore) ListSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]PortfolioValueSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]PortfolioValueSnapshot, 0)
+	for _, snapshot := range s.snapshots {
+		if snapshot.PortfolioID == portfolioID && !snapshot.RecordedAt.Before(from) && snapshot.RecordedAt.Before(to) {
+			result = append(result, snapshot)
+		}
+	}
+	return result, nil
+}
+
+func (s *memorySnapshotStore) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.snapshots[:0]
+	for _, snapshot := range s.snapshots {
+		if !snapshot.RecordedAt.Before(cutoff) {
+			kept = append(kept, snapshot)
+		}
+	}
+	deleted := int64(len(s.snapshots) - len(kept))
+	s.snapshots = kept
+	return deleted, nil
+}
+
+func newTestPortfolioAnalytics(t *testing.T) (*PortfolioAnalytics, *web3.Portfolio, *memorySnapshotStore) {
+	t.Helper()
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "error",
+	})
+	clients := make(map[int]*ethclient.Client)
+	engine := web3.NewTradingEngine(clients, logger, web3.NewRiskAssessmentService(clients, logger))
+
+	portfolio, err := engine.CreatePortfolio(context.Background(), uuid.New(), "history", decimal.NewFromInt(1000), web3.RiskProfile{Level: "moderate"})
+	if err != nil {
+		t.Fatalf("Failed to create portfolio: %v", err)
+	}
+
+	store := &memorySnapshotStore{}
+	portfolioAnalytics := NewPortfolioAnalytics(logger, engine)
+	portfolioAnalytics.SetSnapshotStore(store)
+	return portfolioAnalytics, portfolio, store
+}
+
+func TestPortfolioValueHistory(t *testing.T) {
+	portfolioAnalytics, portfolio, store := newTestPortfolioAnalytics(t)
+	ctx := context.Background()
+
+	// Portfolio created mid-range: three hours ago within a one day range
+	created := time.Now().UTC().Add(-3 * time.Hour)
+	portfolio.CreatedAt = created
+	for i := 0; i < 6; i++ {
+		store.SaveSnapshot(ctx, &PortfolioValueSnapshot{
+			PortfolioID: portfolio.ID,
+			TotalValue:  decimal.NewFromInt(int64(1000 + i*10)),
+			TotalPnL:    decimal.NewFromInt(int64(i * 10)),
+			RecordedAt:  created.Add(time.Duration(i) * 30 * time.Minute),
+		})
+	}
+
+	history, err := portfolioAnalytics.GetValueHistory(ctx, portfolio.ID, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to get value history: %v", err)
+	}
+
+	if !history.Start.Equal(created) {
+		t.Errorf("Expected series to start at creation time %v, got %v", created, history.Start)
+	}
+	if len(history.Points) != 3 {
+		t.Fatalf("Expected 3 hourly points, got %d", len(history.Points))
+	}
+	if !history.Points[0].Value.Equal(decimal.NewFromInt(1010)) {
+		t.Errorf("Expected bucket to hold its latest value 1010, got %s", history.Points[0].Value)
+	}
+	if !history.Points[2].PnL.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("Expected last bucket PnL 50, got %s", history.Points[2].PnL)
+	}
+	if history.Range != "1d" || history.Interval != "1h" {
+		t.Errorf("Unexpected range/interval labels %s/%s", history.Range, history.Interval)
+	}
+
+	if _, err := portfolioAnalytics.GetValueHistory(ctx, portfolio.ID, 24*time.Hour, time.Second); !errors.Is(err, ErrInvalidHistoryQuery) {
+		t.Errorf("Expected invalid query error for sub-minute interval, got %v", err)
+	}
+	if _, err := portfolioAnalytics.GetValueHistory(ctx, uuid.New(), 24*time.Hour, time.Hour); err == nil {
+		t.Error("Expected error for unknown portfolio")
+	}
+}
+
+func TestPortfolioSampler(t *testing.T) {
+	portfolioAnalytics, portfolio, store := newTestPortfolioAnalytics(t)
+	ctx := context.Background()
+
+	store.SaveSnapshot(ctx, &PortfolioValueSnapshot{
+		PortfolioID: portfolio.ID,
+		RecordedAt:  time.Now().UTC().Add(-400 * 24 * time.Hour),
+	})
+	portfolio.Holdings["ETH"] = &web3.Holding{
+		TokenSymbol:  "ETH",
+		Amount:       decimal.NewFromInt(1),
+		CurrentPrice: decimal.NewFromInt(250),
+	}
+
+	portfolioAnalytics.SampleNow(ctx)
+
+	if len(store.snapshots) != 1 {
+		t.Fatalf("Expected expired snapshot pruned and one new snapshot, got %d", len(store.snapshots))
+	}
+	snapshot := store.snapshots[0]
+	if !snapshot.TotalValue.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("Expected total value 1000, got %s", snapshot.TotalValue)
+	}
+	if !snapshot.Allocations["ETH"].Equal(decimal.NewFromInt(25)) {
+		t.Errorf("Expected ETH allocation 25%%, got %s", snapshot.Allocations["ETH"])
+	}
+}
+
+func TestParseHistoryDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"1h":  time.Hour,
+		"15m": 15 * time.Minute,
+	}
+	for input, expected := range cases {
+		got, err := ParseHistoryDuration(input)
+		if err != nil || got != expected {
+			t.Errorf("ParseHistoryDuration(%q) = %v, %v; want %v", input, got, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "d", "-1d", "abc"} {
+		if _, err := ParseHistoryDuration(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
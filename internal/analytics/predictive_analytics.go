@@ -18,6 +18,7 @@ type PredictiveAnalyzer struct {
 	models          map[string]*PredictiveModel
 	predictions     map[string]*Prediction
 	trainingData    map[string][]DataPoint
+	holidays        map[string]bool
 	forecastHorizon time.Duration
 	updateInterval  time.Duration
 	mu              sync.RWMutex
@@ -36,6 +37,7 @@ type PredictiveModel struct {
 	RMSE           float64                `json:"rmse"`
 	MAE            float64                `json:"mae"`
 	R2Score        float64                `json:"r2_score"`
+	Seasonality    *SeasonalDecomposition `json:"seasonality,omitempty"`
 	LastTrained    time.Time              `json:"last_trained"`
 	LastUpdated    time.Time              `json:"last_updated"`
 	Status         ModelStatus            `json:"status"`
@@ -55,6 +57,10 @@ const (
 	ModelTypeSeasonal             PredictiveModelType = "seasonal"
 	ModelTypeNeuralNetwork        PredictiveModelType = "neural_network"
 	ModelTypeEnsemble             PredictiveModelType = "ensemble"
+	ModelTypeHoltWinters          PredictiveModelType = "holt_winters"
+	// ModelTypeAuto trains every supported model type and keeps the one with the lowest
+	// holdout error
+	ModelTypeAuto PredictiveModelType = "auto"
 )
 
 // ModelStatus defines model status
@@ -70,22 +76,25 @@ const (
 
 // Prediction represents a prediction result
 type Prediction struct {
-	PredictionID    string                 `json:"prediction_id"`
-	ModelID         string                 `json:"model_id"`
-	MetricName      string                 `json:"metric_name"`
-	PredictedValue  float64                `json:"predicted_value"`
-	ConfidenceLevel float64                `json:"confidence_level"`
-	PredictionTime  time.Time              `json:"prediction_time"`
-	TargetTime      time.Time              `json:"target_time"`
-	Horizon         time.Duration          `json:"horizon"`
-	UpperBound      float64                `json:"upper_bound"`
-	LowerBound      float64                `json:"lower_bound"`
-	Trend           TrendDirection         `json:"trend"`
-	Seasonality     *SeasonalityInfo       `json:"seasonality,omitempty"`
-	Context         map[string]interface{} `json:"context"`
-	Accuracy        float64                `json:"accuracy,omitempty"`
-	ActualValue     *float64               `json:"actual_value,omitempty"`
-	Error           *float64               `json:"error,omitempty"`
+	PredictionID    string         `json:"prediction_id"`
+	ModelID         string         `json:"model_id"`
+	MetricName      string         `json:"metric_name"`
+	PredictedValue  float64        `json:"predicted_value"`
+	ConfidenceLevel float64        `json:"confidence_level"`
+	PredictionTime  time.Time      `json:"prediction_time"`
+	TargetTime      time.Time      `json:"target_time"`
+	Horizon         time.Duration  `json:"horizon"`
+	UpperBound      float64        `json:"upper_bound"`
+	LowerBound      float64        `json:"lower_bound"`
+	Trend           TrendDirection `json:"trend"`
+	// TrendComponent and SeasonalComponent add up to PredictedValue
+	TrendComponent    float64                `json:"trend_component"`
+	SeasonalComponent float64                `json:"seasonal_component"`
+	Seasonality       *SeasonalityInfo       `json:"seasonality,omitempty"`
+	Context           map[string]interface{} `json:"context"`
+	Accuracy          float64                `json:"accuracy,omitempty"`
+	ActualValue       *float64               `json:"actual_value,omitempty"`
+	Error             *float64               `json:"error,omitempty"`
 }
 
 // TrendDirection defines trend directions
@@ -134,6 +143,7 @@ func NewPredictiveAnalyzer(logger *observability.Logger, config *AnalyticsConfig
 		models:          make(map[string]*PredictiveModel),
 		predictions:     make(map[string]*Prediction),
 		trainingData:    make(map[string][]DataPoint),
+		holidays:        make(map[string]bool),
 		forecastHorizon: config.PredictionHorizon,
 		updateInterval:  1 * time.Hour,
 	}
@@ -213,14 +223,20 @@ func (pa *PredictiveAnalyzer) CreateModel(metricName string, modelType Predictiv
 
 // GenerateForecast generates a forecast for a metric
 func (pa *PredictiveAnalyzer) GenerateForecast(ctx context.Context, request *ForecastRequest) (*ForecastResult, error) {
-	// Find the best model for the metric
+	// Find the best model for the metric. Auto picks the lowest holdout error of any type.
 	var bestModel *PredictiveModel
 	bestAccuracy := 0.0
+	autoSelect := request.ModelType != nil && *request.ModelType == ModelTypeAuto
 
 	pa.mu.RLock()
 	for _, model := range pa.models {
 		if model.MetricName == request.MetricName && model.Status == ModelStatusActive {
-			if request.ModelType == nil || model.ModelType == *request.ModelType {
+			if autoSelect {
+				if len(model.ValidationData) > 0 && (bestModel == nil || model.RMSE < bestModel.RMSE) {
+					bestModel = model
+					bestAccuracy = model.Accuracy
+				}
+			} else if request.ModelType == nil || model.ModelType == *request.ModelType {
 				if model.Accuracy > bestAccuracy {
 					bestModel = model
 					bestAccuracy = model.Accuracy
@@ -260,6 +276,9 @@ func (pa *PredictiveAnalyzer) GenerateForecast(ctx context.Context, request *For
 			"r2_score":   bestModel.R2Score,
 		},
 	}
+	if bestModel.Seasonality != nil {
+		result.Metadata["seasonal_strength"] = bestModel.Seasonality.Strength
+	}
 
 	pa.logger.Info(ctx, "Forecast generated", map[string]interface{}{
 		"metric_name":      request.MetricName,
@@ -293,25 +312,17 @@ func (pa *PredictiveAnalyzer) generateSinglePrediction(model *PredictiveModel, t
 	}
 
 	// Generate prediction based on model type
-	var predictedValue float64
-	var confidence float64
-	var upperBound, lowerBound float64
-	var trend TrendDirection
-
-	switch model.ModelType {
-	case ModelTypeMovingAverage:
-		predictedValue, confidence = pa.predictMovingAverage(trainingData, model.Parameters)
-	case ModelTypeLinearRegression:
-		predictedValue, confidence = pa.predictLinearRegression(trainingData, model.Parameters)
-	case ModelTypeExponentialSmoothing:
-		predictedValue, confidence = pa.predictExponentialSmoothing(trainingData, model.Parameters)
-	default:
-		predictedValue, confidence = pa.predictMovingAverage(trainingData, model.Parameters)
-	}
+	forecast := pa.predictAt(model, trainingData, targetTime)
+	predictedValue := forecast.value
 
 	// Calculate bounds and trend
-	variance := model.Parameters["variance"]
-	if variance > 0 {
+	var upperBound, lowerBound float64
+	if model.Seasonality != nil {
+		// Seasonal models use their residual spread, widened when the seasonal fit is poor
+		stdDev := model.Seasonality.intervalStdDev(model.RMSE)
+		upperBound = predictedValue + 2*stdDev
+		lowerBound = predictedValue - 2*stdDev
+	} else if variance := model.Parameters["variance"]; variance > 0 {
 		stdDev := math.Sqrt(variance)
 		upperBound = predictedValue + 2*stdDev
 		lowerBound = predictedValue - 2*stdDev
@@ -320,25 +331,31 @@ func (pa *PredictiveAnalyzer) generateSinglePrediction(model *PredictiveModel, t
 		lowerBound = predictedValue * 0.9
 	}
 
-	trend = pa.calculateTrend(trainingData)
+	trend := pa.calculateTrend(trainingData)
 
 	prediction := &Prediction{
-		PredictionID:    uuid.New().String(),
-		ModelID:         model.ModelID,
-		MetricName:      model.MetricName,
-		PredictedValue:  predictedValue,
-		ConfidenceLevel: confidence,
-		PredictionTime:  time.Now(),
-		TargetTime:      targetTime,
-		Horizon:         targetTime.Sub(time.Now()),
-		UpperBound:      upperBound,
-		LowerBound:      lowerBound,
-		Trend:           trend,
+		PredictionID:      uuid.New().String(),
+		ModelID:           model.ModelID,
+		MetricName:        model.MetricName,
+		PredictedValue:    predictedValue,
+		ConfidenceLevel:   forecast.confidence,
+		PredictionTime:    time.Now(),
+		TargetTime:        targetTime,
+		Horizon:           targetTime.Sub(time.Now()),
+		UpperBound:        upperBound,
+		LowerBound:        lowerBound,
+		Trend:             trend,
+		TrendComponent:    forecast.trend,
+		SeasonalComponent: forecast.seasonal,
+		Seasonality:       model.Seasonality.info(),
 		Context: map[string]interface{}{
 			"model_type":      model.ModelType,
 			"training_points": len(trainingData),
 		},
 	}
+	if model.Seasonality.isHoliday(targetTime) {
+		prediction.Context["holiday"] = true
+	}
 
 	// Store prediction
 	pa.mu.Lock()
@@ -577,15 +594,10 @@ func (pa *PredictiveAnalyzer) trainModel(model *PredictiveModel) {
 	model.ValidationData = trainingData[splitIndex:]
 
 	// Train based on model type
-	switch model.ModelType {
-	case ModelTypeMovingAverage:
-		pa.trainMovingAverage(model)
-	case ModelTypeLinearRegression:
-		pa.trainLinearRegression(model)
-	case ModelTypeExponentialSmoothing:
-		pa.trainExponentialSmoothing(model)
-	default:
-		pa.trainMovingAverage(model)
+	if model.ModelType == ModelTypeAuto {
+		pa.trainAuto(model)
+	} else {
+		pa.fitModel(model)
 	}
 
 	// Validate model
@@ -604,14 +616,43 @@ func (pa *PredictiveAnalyzer) trainModel(model *PredictiveModel) {
 	})
 }
 
+// fitModel decomposes the training data's seasonality, when the model type doesn't model it
+// itself, and fits the model's parameters
+func (pa *PredictiveAnalyzer) fitModel(model *PredictiveModel) {
+	if model.Parameters == nil {
+		model.Parameters = make(map[string]float64)
+	}
+
+	if model.algorithm() == ModelTypeHoltWinters {
+		pa.trainHoltWinters(model)
+		return
+	}
+
+	pa.mu.RLock()
+	model.Seasonality = decomposeSeasonality(model.TrainingData, pa.holidays)
+	pa.mu.RUnlock()
+
+	switch model.algorithm() {
+	case ModelTypeMovingAverage:
+		pa.trainMovingAverage(model)
+	case ModelTypeLinearRegression:
+		pa.trainLinearRegression(model)
+	case ModelTypeExponentialSmoothing:
+		pa.trainExponentialSmoothing(model)
+	default:
+		pa.trainMovingAverage(model)
+	}
+}
+
 // trainMovingAverage trains a moving average model
 func (pa *PredictiveAnalyzer) trainMovingAverage(model *PredictiveModel) {
 	// Optimize window size
 	bestWindow := 10
 	bestError := math.Inf(1)
 
+	data := model.Seasonality.adjust(model.TrainingData)
 	for window := 5; window <= 20; window++ {
-		error := pa.calculateMovingAverageError(model.TrainingData, window)
+		error := pa.calculateMovingAverageError(data, window)
 		if error < bestError {
 			bestError = error
 			bestWindow = window
@@ -624,7 +665,7 @@ func (pa *PredictiveAnalyzer) trainMovingAverage(model *PredictiveModel) {
 
 // trainLinearRegression trains a linear regression model
 func (pa *PredictiveAnalyzer) trainLinearRegression(model *PredictiveModel) {
-	data := model.TrainingData
+	data := model.Seasonality.adjust(model.TrainingData)
 	if len(data) < 2 {
 		return
 	}
@@ -664,8 +705,9 @@ func (pa *PredictiveAnalyzer) trainExponentialSmoothing(model *PredictiveModel)
 	bestAlpha := 0.3
 	bestError := math.Inf(1)
 
+	data := model.Seasonality.adjust(model.TrainingData)
 	for alpha := 0.1; alpha <= 0.9; alpha += 0.1 {
-		error := pa.calculateExponentialSmoothingError(model.TrainingData, alpha)
+		error := pa.calculateExponentialSmoothingError(data, alpha)
 		if error < bestError {
 			bestError = error
 			bestAlpha = alpha
@@ -689,21 +731,9 @@ func (pa *PredictiveAnalyzer) validateModel(model *PredictiveModel) {
 	// Generate predictions for validation data
 	for i, point := range model.ValidationData {
 		// Use training data up to this point
-		trainingSubset := append(model.TrainingData, model.ValidationData[:i]...)
-
-		var predicted float64
-		switch model.ModelType {
-		case ModelTypeMovingAverage:
-			predicted, _ = pa.predictMovingAverage(trainingSubset, model.Parameters)
-		case ModelTypeLinearRegression:
-			predicted, _ = pa.predictLinearRegression(trainingSubset, model.Parameters)
-		case ModelTypeExponentialSmoothing:
-			predicted, _ = pa.predictExponentialSmoothing(trainingSubset, model.Parameters)
-		default:
-			predicted, _ = pa.predictMovingAverage(trainingSubset, model.Parameters)
-		}
+		trainingSubset := append(model.TrainingData[:len(model.TrainingData):len(model.TrainingData)], model.ValidationData[:i]...)
 
-		predictions[i] = predicted
+		predictions[i] = pa.predictAt(model, trainingSubset, point.Timestamp).value
 		actuals[i] = point.Value
 	}
 
@@ -0,0 +1,220 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+var seasonalStart = time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC) // a Monday
+
+func newTestPredictiveAnalyzer() *PredictiveAnalyzer {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "error",
+	})
+	return NewPredictiveAnalyzer(logger, &AnalyticsConfig{PredictionHorizon: time.Hour})
+}
+
+// seasonalVolume is hourly trading volume with a slow trend, an afternoon peak, busier
+// weekends and noise of the given spread
+func seasonalVolume(days int, noise float64, rng *rand.Rand) []DataPoint {
+	data := make([]DataPoint, 0, days*24)
+	for i := 0; i < days*24; i++ {
+		timestamp := seasonalStart.Add(time.Duration(i) * time.Hour)
+		value := 1000 + 0.5*float64(i) + 200*math.Sin(float64(timestamp.Hour()-8)*math.Pi/12)
+		if weekday := timestamp.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			value += 150
+		}
+		value += (rng.Float64()*2 - 1) * noise
+		data = append(data, DataPoint{Timestamp: timestamp, Value: value})
+	}
+	return data
+}
+
+// trainTestModel trains a model of modelType on data and makes it available for forecasts
+func trainTestModel(pa *PredictiveAnalyzer, metricName string, modelType PredictiveModelType, parameters map[string]float64, data []DataPoint) *PredictiveModel {
+	pa.trainingData[metricName] = data
+	model := &PredictiveModel{
+		ModelID:    string(modelType) + "-" + metricName,
+		MetricName: metricName,
+		ModelType:  modelType,
+		Algorithm:  string(modelType),
+		Parameters: parameters,
+		Metadata:   make(map[string]interface{}),
+	}
+	pa.trainModel(model)
+	pa.models[model.ModelID] = model
+	return model
+}
+
+func TestSeasonalDecomposition(t *testing.T) {
+	data := seasonalVolume(21, 10, rand.New(rand.NewSource(1)))
+	decomposition := decomposeSeasonality(data, nil)
+	if decomposition == nil {
+		t.Fatal("Expected seasonality to be decomposed")
+	}
+
+	if len(decomposition.Daily) != 24 || len(decomposition.Weekly) != 7 {
+		t.Fatalf("Expected daily and weekly indexes, got %d and %d", len(decomposition.Daily), len(decomposition.Weekly))
+	}
+	if peak := decomposition.Daily[14]; math.Abs(peak-200) > 20 {
+		t.Errorf("Expected the 14:00 index near 200, got %.1f", peak)
+	}
+	if weekend := decomposition.Weekly[time.Saturday] - decomposition.Weekly[time.Wednesday]; math.Abs(weekend-150) > 20 {
+		t.Errorf("Expected weekends 150 above weekdays, got %.1f", weekend)
+	}
+	if decomposition.Strength < 0.95 {
+		t.Errorf("Expected strong seasonality, got %.2f", decomposition.Strength)
+	}
+	if decomposition.Period != 24*time.Hour {
+		t.Errorf("Expected the daily cycle to dominate, got %v", decomposition.Period)
+	}
+
+	if short := decomposeSeasonality(data[:36], nil); short != nil {
+		t.Error("Expected no decomposition for less than two days of data")
+	}
+	if twoDays := decomposeSeasonality(data[:72], nil); twoDays == nil || twoDays.Weekly != nil {
+		t.Error("Expected daily seasonality only for less than two weeks of data")
+	}
+}
+
+func TestSeasonalHolidayEffect(t *testing.T) {
+	data := seasonalVolume(21, 10, rand.New(rand.NewSource(2)))
+	holiday := seasonalStart.AddDate(0, 0, 9) // a Wednesday
+	for i := range data {
+		if data[i].Timestamp.YearDay() == holiday.YearDay() {
+			data[i].Value -= 300
+		}
+	}
+
+	pa := newTestPredictiveAnalyzer()
+	pa.SetHolidays(holiday)
+	model := trainTestModel(pa, "trading_volume", ModelTypeLinearRegression, map[string]float64{}, data)
+
+	if effect := model.Seasonality.HolidayEffect; math.Abs(effect+300) > 40 {
+		t.Errorf("Expected a holiday effect near -300, got %.1f", effect)
+	}
+	if weekday := model.Seasonality.Weekly[time.Wednesday]; math.Abs(weekday) > 60 {
+		t.Errorf("Expected the holiday not to skew Wednesdays, got %.1f", weekday)
+	}
+
+	nextHoliday := data[len(data)-1].Timestamp.Add(12 * time.Hour)
+	pa.SetHolidays(holiday, nextHoliday)
+	model = trainTestModel(pa, "trading_volume", ModelTypeLinearRegression, map[string]float64{}, data)
+	prediction := pa.generateSinglePrediction(model, nextHoliday)
+	regular := pa.generateSinglePrediction(model, nextHoliday.AddDate(0, 0, 7))
+	if difference := prediction.SeasonalComponent - regular.SeasonalComponent; prediction.Context["holiday"] != true || math.Abs(difference+300) > 40 {
+		t.Errorf("Expected the holiday effect in the forecast, got %.1f below a regular day", -difference)
+	}
+}
+
+func TestSeasonalForecastComponents(t *testing.T) {
+	pa := newTestPredictiveAnalyzer()
+	data := seasonalVolume(21, 10, rand.New(rand.NewSource(3)))
+	model := trainTestModel(pa, "trading_volume", ModelTypeExponentialSmoothing, map[string]float64{}, data)
+
+	last := data[len(data)-1].Timestamp
+	peak := pa.generateSinglePrediction(model, time.Date(last.Year(), last.Month(), last.Day()+1, 14, 0, 0, 0, time.UTC))
+	trough := pa.generateSinglePrediction(model, time.Date(last.Year(), last.Month(), last.Day()+1, 2, 0, 0, 0, time.UTC))
+
+	for _, prediction := range []*Prediction{peak, trough} {
+		if math.Abs(prediction.TrendComponent+prediction.SeasonalComponent-prediction.PredictedValue) > 1e-9 {
+			t.Errorf("Expected components to add up to %.1f, got %.1f + %.1f", prediction.PredictedValue, prediction.TrendComponent, prediction.SeasonalComponent)
+		}
+		if prediction.Seasonality == nil || prediction.Seasonality.Period != 24*time.Hour {
+			t.Errorf("Expected daily seasonality info, got %+v", prediction.Seasonality)
+		}
+	}
+	if peak.PredictedValue-trough.PredictedValue < 300 {
+		t.Errorf("Expected the afternoon forecast well above the night one, got %.1f and %.1f", peak.PredictedValue, trough.PredictedValue)
+	}
+}
+
+func TestHoltWintersModel(t *testing.T) {
+	pa := newTestPredictiveAnalyzer()
+	data := seasonalVolume(14, 10, rand.New(rand.NewSource(4)))
+
+	model := trainTestModel(pa, "trading_volume", ModelTypeHoltWinters, map[string]float64{"seasonal_period": 24}, data)
+	if model.Status != ModelStatusActive {
+		t.Fatalf("Expected an active model, got %s", model.Status)
+	}
+	if model.Parameters["seasonal_period"] != 24 || model.Seasonality == nil || model.Seasonality.Period != 24*time.Hour {
+		t.Errorf("Expected a 24 hour season, got %v and %+v", model.Parameters["seasonal_period"], model.Seasonality)
+	}
+
+	movingAverage := trainTestModel(pa, "trading_volume", ModelTypeMovingAverage, map[string]float64{}, data)
+	movingAverage.Seasonality = nil
+	pa.validateModel(movingAverage)
+	if model.RMSE >= movingAverage.RMSE {
+		t.Errorf("Expected Holt-Winters to beat a plain moving average, got RMSE %.1f and %.1f", model.RMSE, movingAverage.RMSE)
+	}
+
+	// The season period is inferred from the data's interval when unset
+	inferred := trainTestModel(pa, "trading_volume", ModelTypeHoltWinters, nil, data)
+	if inferred.Parameters["seasonal_period"] != 24 {
+		t.Errorf("Expected a 24 point season for hourly data, got %v", inferred.Parameters["seasonal_period"])
+	}
+}
+
+func TestAutoModelSelection(t *testing.T) {
+	pa := newTestPredictiveAnalyzer()
+	data := seasonalVolume(21, 10, rand.New(rand.NewSource(5)))
+	model := trainTestModel(pa, "trading_volume", ModelTypeAuto, map[string]float64{}, data)
+
+	holdoutRMSE, ok := model.Metadata["holdout_rmse"].(map[string]float64)
+	if !ok || len(holdoutRMSE) != len(autoModelTypes) {
+		t.Fatalf("Expected the holdout RMSE of every candidate, got %v", model.Metadata["holdout_rmse"])
+	}
+	selected := model.Metadata["selected_model"].(string)
+	for candidate, rmse := range holdoutRMSE {
+		if rmse < holdoutRMSE[selected] {
+			t.Errorf("Expected %s to be selected over %s, got RMSE %.1f and %.1f", candidate, selected, rmse, holdoutRMSE[selected])
+		}
+	}
+	if model.Algorithm != selected || math.Abs(model.RMSE-holdoutRMSE[selected]) > 1e-9 {
+		t.Errorf("Expected the model to predict with %s, got %s", selected, model.Algorithm)
+	}
+
+	trainTestModel(pa, "trading_volume", ModelTypeMovingAverage, map[string]float64{}, data)
+	auto := ModelTypeAuto
+	forecast, err := pa.GenerateForecast(context.Background(), &ForecastRequest{
+		MetricName: "trading_volume",
+		Horizon:    6 * time.Hour,
+		Intervals:  6,
+		ModelType:  &auto,
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate forecast: %v", err)
+	}
+	if forecast.ModelUsed != model.ModelID {
+		t.Errorf("Expected the lowest holdout error model, got %s", forecast.ModelUsed)
+	}
+	if _, ok := forecast.Metadata["seasonal_strength"]; !ok {
+		t.Error("Expected the seasonal strength in the forecast metadata")
+	}
+}
+
+func TestSeasonalIntervalsWidenWithPoorFit(t *testing.T) {
+	pa := newTestPredictiveAnalyzer()
+	clean := trainTestModel(pa, "clean_volume", ModelTypeLinearRegression, map[string]float64{}, seasonalVolume(21, 5, rand.New(rand.NewSource(6))))
+	noisy := trainTestModel(pa, "noisy_volume", ModelTypeLinearRegression, map[string]float64{}, seasonalVolume(21, 400, rand.New(rand.NewSource(6))))
+
+	if noisy.Seasonality.Strength >= clean.Seasonality.Strength {
+		t.Fatalf("Expected a weaker seasonal fit for noisy data, got %.2f and %.2f", noisy.Seasonality.Strength, clean.Seasonality.Strength)
+	}
+
+	target := seasonalStart.AddDate(0, 0, 22)
+	cleanPrediction := pa.generateSinglePrediction(clean, target)
+	noisyPrediction := pa.generateSinglePrediction(noisy, target)
+	cleanWidth := cleanPrediction.UpperBound - cleanPrediction.LowerBound
+	noisyWidth := noisyPrediction.UpperBound - noisyPrediction.LowerBound
+	if noisyWidth < 4*cleanWidth {
+		t.Errorf("Expected much wider intervals for the poor fit, got %.1f and %.1f", noisyWidth, cleanWidth)
+	}
+}
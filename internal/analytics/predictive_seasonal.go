@@ -0,0 +1,534 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// Seasonality is only decomposed once the data covers two full cycles, so a single
+	// unusual day or week isn't mistaken for a pattern
+	minDailySpan  = 2 * 24 * time.Hour
+	minWeeklySpan = 2 * 7 * 24 * time.Hour
+	holidayLayout = "2006-01-02"
+)
+
+// autoModelTypes are the model types ModelTypeAuto chooses between
+var autoModelTypes = []PredictiveModelType{
+	ModelTypeMovingAverage,
+	ModelTypeLinearRegression,
+	ModelTypeExponentialSmoothing,
+	ModelTypeHoltWinters,
+}
+
+// SeasonalDecomposition describes the seasonality fitted to a model's training data. Daily,
+// Weekly and HolidayEffect are additive offsets from the trend; Holt-Winters models track their
+// seasonality themselves and only report its period and fit.
+type SeasonalDecomposition struct {
+	Daily            []float64     `json:"daily,omitempty"`  // by UTC hour of day
+	Weekly           []float64     `json:"weekly,omitempty"` // by weekday, Sunday first
+	HolidayEffect    float64       `json:"holiday_effect,omitempty"`
+	Period           time.Duration `json:"period"`    // period of the strongest seasonality
+	Amplitude        float64       `json:"amplitude"` // half its peak-to-trough range
+	Phase            float64       `json:"phase"`     // where in its period it peaks, 0 to 1
+	Strength         float64       `json:"strength"`  // share of detrended variance it explains, 0 to 1
+	ResidualVariance float64       `json:"residual_variance"`
+
+	holidays map[string]bool
+}
+
+// forecastComponents is a prediction split into the trend and seasonal contributions
+type forecastComponents struct {
+	value      float64
+	confidence float64
+	trend      float64
+	seasonal   float64
+}
+
+// SetHolidays sets the dates whose holiday effect seasonal models learn and forecast, such as
+// exchange holidays that thin trading volume. Models pick them up when next trained.
+func (pa *PredictiveAnalyzer) SetHolidays(dates ...time.Time) {
+	holidays := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		holidays[date.UTC().Format(holidayLayout)] = true
+	}
+
+	pa.mu.Lock()
+	pa.holidays = holidays
+	pa.mu.Unlock()
+}
+
+// algorithm returns the model type that makes predictions, which for auto models is the
+// selected one
+func (m *PredictiveModel) algorithm() PredictiveModelType {
+	if m.ModelType == ModelTypeAuto {
+		return PredictiveModelType(m.Algorithm)
+	}
+	return m.ModelType
+}
+
+// predictAt predicts the value at target from data. Seasonal models forecast the seasonally
+// adjusted data and add the seasonal component at target.
+func (pa *PredictiveAnalyzer) predictAt(model *PredictiveModel, data []DataPoint, target time.Time) forecastComponents {
+	if model.algorithm() == ModelTypeHoltWinters {
+		return pa.predictHoltWinters(data, model.Parameters, target)
+	}
+
+	adjusted := model.Seasonality.adjust(data)
+	var base, confidence float64
+	switch model.algorithm() {
+	case ModelTypeMovingAverage:
+		base, confidence = pa.predictMovingAverage(adjusted, model.Parameters)
+	case ModelTypeLinearRegression:
+		base, confidence = pa.predictLinearRegression(adjusted, model.Parameters)
+	case ModelTypeExponentialSmoothing:
+		base, confidence = pa.predictExponentialSmoothing(adjusted, model.Parameters)
+	default:
+		base, confidence = pa.predictMovingAverage(adjusted, model.Parameters)
+	}
+
+	seasonal := model.Seasonality.component(target)
+	return forecastComponents{value: base + seasonal, confidence: confidence, trend: base, seasonal: seasonal}
+}
+
+// trainAuto trains a candidate of every auto model type on the model's training data and keeps
+// the parameters of the one with the lowest holdout RMSE
+func (pa *PredictiveAnalyzer) trainAuto(model *PredictiveModel) {
+	var best *PredictiveModel
+	holdoutRMSE := make(map[string]float64, len(autoModelTypes))
+
+	for _, modelType := range autoModelTypes {
+		candidate := &PredictiveModel{
+			ModelType:      modelType,
+			Algorithm:      string(modelType),
+			Parameters:     make(map[string]float64),
+			TrainingData:   model.TrainingData,
+			ValidationData: model.ValidationData,
+		}
+		if modelType == ModelTypeHoltWinters && model.Parameters["seasonal_period"] > 0 {
+			candidate.Parameters["seasonal_period"] = model.Parameters["seasonal_period"]
+		}
+
+		pa.fitModel(candidate)
+		pa.validateModel(candidate)
+		holdoutRMSE[string(modelType)] = candidate.RMSE
+
+		if best == nil || candidate.RMSE < best.RMSE {
+			best = candidate
+		}
+	}
+
+	model.Algorithm = best.Algorithm
+	model.Parameters = best.Parameters
+	model.Seasonality = best.Seasonality
+	if model.Metadata == nil {
+		model.Metadata = make(map[string]interface{})
+	}
+	model.Metadata["selected_model"] = best.Algorithm
+	model.Metadata["holdout_rmse"] = holdoutRMSE
+
+	pa.logger.Info(context.Background(), "Auto model selected", map[string]interface{}{
+		"model_id":       model.ModelID,
+		"metric_name":    model.MetricName,
+		"selected_model": best.Algorithm,
+		"holdout_rmse":   best.RMSE,
+	})
+}
+
+// decomposeSeasonality fits daily, weekly and holiday seasonality to data around a linear
+// trend. It returns nil when data doesn't cover two days.
+func decomposeSeasonality(data []DataPoint, holidays map[string]bool) *SeasonalDecomposition {
+	if len(data) < 2 {
+		return nil
+	}
+	start := data[0].Timestamp
+	span := data[len(data)-1].Timestamp.Sub(start)
+	if span < minDailySpan {
+		return nil
+	}
+
+	decomposition := &SeasonalDecomposition{holidays: make(map[string]bool, len(holidays))}
+	for date := range holidays {
+		decomposition.holidays[date] = true
+	}
+
+	// Remove the trend, fitted by least squares over time
+	hours := make([]float64, len(data))
+	values := make([]float64, len(data))
+	for i, point := range data {
+		hours[i] = point.Timestamp.Sub(start).Hours()
+		values[i] = point.Value
+	}
+	slope, intercept := fitLine(hours, values)
+	detrended := make([]float64, len(data))
+	for i := range data {
+		detrended[i] = values[i] - (slope*hours[i] + intercept)
+	}
+
+	// Holidays are left out of the regular seasonality so they don't skew it
+	remainder := append([]float64(nil), detrended...)
+	regular := func(i int) bool { return !decomposition.isHoliday(data[i].Timestamp) }
+
+	decomposition.Daily = seasonalIndexes(data, remainder, 24, func(t time.Time) int { return t.UTC().Hour() }, regular)
+	if span >= minWeeklySpan {
+		decomposition.Weekly = seasonalIndexes(data, remainder, 7, func(t time.Time) int { return int(t.UTC().Weekday()) }, regular)
+	}
+
+	holidayCount := 0
+	holidayTotal := 0.0
+	for i := range data {
+		if !regular(i) {
+			holidayTotal += remainder[i]
+			holidayCount++
+		}
+	}
+	if holidayCount > 0 {
+		decomposition.HolidayEffect = holidayTotal / float64(holidayCount)
+		for i := range data {
+			if !regular(i) {
+				remainder[i] -= decomposition.HolidayEffect
+			}
+		}
+	}
+
+	decomposition.ResidualVariance = variance(remainder)
+	if detrendedVariance := variance(detrended); detrendedVariance > 0 {
+		decomposition.Strength = math.Max(0, 1-decomposition.ResidualVariance/detrendedVariance)
+	}
+
+	// Report the stronger of the daily and weekly cycles
+	decomposition.Period = 24 * time.Hour
+	decomposition.Amplitude, decomposition.Phase = cycleShape(decomposition.Daily)
+	if amplitude, phase := cycleShape(decomposition.Weekly); amplitude > decomposition.Amplitude {
+		decomposition.Period = 7 * 24 * time.Hour
+		decomposition.Amplitude, decomposition.Phase = amplitude, phase
+	}
+
+	return decomposition
+}
+
+// seasonalIndexes averages remainder by bucket over the points that count, centres the
+// averages on zero and subtracts them from remainder
+func seasonalIndexes(data []DataPoint, remainder []float64, buckets int, bucket func(time.Time) int, counts func(int) bool) []float64 {
+	sums := make([]float64, buckets)
+	samples := make([]int, buckets)
+	for i, point := range data {
+		if counts(i) {
+			b := bucket(point.Timestamp)
+			sums[b] += remainder[i]
+			samples[b]++
+		}
+	}
+
+	indexes := make([]float64, buckets)
+	populated := 0
+	total := 0.0
+	for b := range indexes {
+		if samples[b] > 0 {
+			indexes[b] = sums[b] / float64(samples[b])
+			total += indexes[b]
+			populated++
+		}
+	}
+	if populated == 0 {
+		return nil
+	}
+	mean := total / float64(populated)
+	for b := range indexes {
+		if samples[b] > 0 {
+			indexes[b] -= mean
+		}
+	}
+
+	for i, point := range data {
+		remainder[i] -= indexes[bucket(point.Timestamp)]
+	}
+	return indexes
+}
+
+// component returns the seasonal and holiday offset from the trend at t
+func (d *SeasonalDecomposition) component(t time.Time) float64 {
+	if d == nil {
+		return 0
+	}
+	offset := 0.0
+	if len(d.Daily) == 24 {
+		offset += d.Daily[t.UTC().Hour()]
+	}
+	if len(d.Weekly) == 7 {
+		offset += d.Weekly[t.UTC().Weekday()]
+	}
+	if d.isHoliday(t) {
+		offset += d.HolidayEffect
+	}
+	return offset
+}
+
+// adjust returns data with the seasonal component removed
+func (d *SeasonalDecomposition) adjust(data []DataPoint) []DataPoint {
+	if d == nil || (d.Daily == nil && d.Weekly == nil && d.HolidayEffect == 0) {
+		return data
+	}
+	adjusted := make([]DataPoint, len(data))
+	for i, point := range data {
+		adjusted[i] = point
+		adjusted[i].Value = point.Value - d.component(point.Timestamp)
+	}
+	return adjusted
+}
+
+func (d *SeasonalDecomposition) isHoliday(t time.Time) bool {
+	return d != nil && d.holidays[t.UTC().Format(holidayLayout)]
+}
+
+// intervalStdDev returns the standard deviation of prediction intervals: the larger of the
+// residual spread and the holdout RMSE, up to doubled as the seasonal strength falls to zero
+func (d *SeasonalDecomposition) intervalStdDev(holdoutRMSE float64) float64 {
+	stdDev := math.Max(math.Sqrt(d.ResidualVariance), holdoutRMSE)
+	return stdDev * (2 - d.Strength)
+}
+
+// info summarizes the decomposition for predictions
+func (d *SeasonalDecomposition) info() *SeasonalityInfo {
+	if d == nil {
+		return nil
+	}
+	return &SeasonalityInfo{
+		Period:    d.Period,
+		Amplitude: d.Amplitude,
+		Phase:     d.Phase,
+		Strength:  d.Strength,
+	}
+}
+
+// trainHoltWinters picks the smoothing factors with the lowest one-step error over the
+// training data. The seasonal period is the "seasonal_period" parameter in data points, or a
+// day of points when unset.
+func (pa *PredictiveAnalyzer) trainHoltWinters(model *PredictiveModel) {
+	data := model.TrainingData
+	if len(data) < 3 {
+		return
+	}
+
+	interval := medianInterval(data)
+	period := int(model.Parameters["seasonal_period"])
+	if period <= 0 && interval > 0 {
+		period = int(math.Round(float64(24*time.Hour) / float64(interval)))
+	}
+	if period < 2 || 2*period > len(data) {
+		// Too little data for a season; fall back to Holt's linear trend
+		period = 0
+	}
+
+	values := make([]float64, len(data))
+	for i, point := range data {
+		values[i] = point.Value
+	}
+
+	var best holtWintersState
+	bestAlpha, bestBeta, bestGamma := 0.3, 0.1, 0.1
+	for _, alpha := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+		for _, beta := range []float64{0.01, 0.05, 0.1, 0.3} {
+			for _, gamma := range []float64{0.05, 0.1, 0.3, 0.5} {
+				state := runHoltWinters(values, period, alpha, beta, gamma)
+				if best.steps == 0 || state.sse < best.sse {
+					best = state
+					bestAlpha, bestBeta, bestGamma = alpha, beta, gamma
+				}
+			}
+		}
+	}
+
+	model.Parameters["alpha"] = bestAlpha
+	model.Parameters["beta"] = bestBeta
+	model.Parameters["gamma"] = bestGamma
+	model.Parameters["seasonal_period"] = float64(period)
+	model.Parameters["interval_seconds"] = interval.Seconds()
+	model.Parameters["error"] = math.Sqrt(best.sse / float64(best.steps))
+
+	model.Seasonality = nil
+	if period > 0 {
+		residualVariance := best.sse / float64(best.steps)
+		seasonalVariance := variance(best.season)
+		decomposition := &SeasonalDecomposition{
+			Period:           time.Duration(period) * interval,
+			ResidualVariance: residualVariance,
+		}
+		decomposition.Amplitude, decomposition.Phase = cycleShape(best.season)
+		if seasonalVariance+residualVariance > 0 {
+			decomposition.Strength = seasonalVariance / (seasonalVariance + residualVariance)
+		}
+		model.Seasonality = decomposition
+	}
+}
+
+// predictHoltWinters forecasts target from the level, trend and season of data
+func (pa *PredictiveAnalyzer) predictHoltWinters(data []DataPoint, parameters map[string]float64, target time.Time) forecastComponents {
+	if len(data) < 3 {
+		value, confidence := pa.predictMovingAverage(data, parameters)
+		return forecastComponents{value: value, confidence: confidence, trend: value}
+	}
+
+	alpha, beta, gamma := parameters["alpha"], parameters["beta"], parameters["gamma"]
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.3
+	}
+	if beta <= 0 || beta > 1 {
+		beta = 0.1
+	}
+	if gamma <= 0 || gamma > 1 {
+		gamma = 0.1
+	}
+	period := int(parameters["seasonal_period"])
+	if 2*period > len(data) {
+		period = 0
+	}
+
+	values := make([]float64, len(data))
+	for i, point := range data {
+		values[i] = point.Value
+	}
+	state := runHoltWinters(values, period, alpha, beta, gamma)
+
+	// Steps ahead of the last point at the data's usual interval
+	steps := 1
+	interval := time.Duration(parameters["interval_seconds"] * float64(time.Second))
+	if interval <= 0 {
+		interval = medianInterval(data)
+	}
+	if interval > 0 {
+		steps = max(1, int(math.Round(float64(target.Sub(data[len(data)-1].Timestamp))/float64(interval))))
+	}
+
+	forecast := forecastComponents{trend: state.level + float64(steps)*state.trend}
+	if period > 0 {
+		forecast.seasonal = state.season[(len(values)+steps-1)%period]
+	}
+	forecast.value = forecast.trend + forecast.seasonal
+
+	if total := variance(values) * float64(len(values)); total > 0 {
+		forecast.confidence = math.Max(0, math.Min(1, 1-state.sse/total))
+	}
+	return forecast
+}
+
+// holtWintersState is additive Holt-Winters smoothing after the last value
+type holtWintersState struct {
+	level  float64
+	trend  float64
+	season []float64
+	sse    float64 // sum of squared one-step errors
+	steps  int
+}
+
+// runHoltWinters smooths values with an additive season of period values, or with Holt's
+// linear trend only when period is 0
+func runHoltWinters(values []float64, period int, alpha, beta, gamma float64) holtWintersState {
+	state := holtWintersState{}
+	first := 1
+	if period > 0 {
+		// Initialize from the first two seasons
+		firstMean := mean(values[:period])
+		secondMean := mean(values[period : 2*period])
+		state.level = firstMean
+		state.trend = (secondMean - firstMean) / float64(period)
+		state.season = make([]float64, period)
+		for i := 0; i < period; i++ {
+			state.season[i] = values[i] - firstMean
+		}
+		first = period
+	} else {
+		state.level = values[0]
+		state.trend = values[1] - values[0]
+	}
+
+	for t := first; t < len(values); t++ {
+		seasonal := 0.0
+		if period > 0 {
+			seasonal = state.season[t%period]
+		}
+		forecastError := values[t] - (state.level + state.trend + seasonal)
+		state.sse += forecastError * forecastError
+		state.steps++
+
+		previousLevel := state.level
+		state.level = alpha*(values[t]-seasonal) + (1-alpha)*(state.level+state.trend)
+		state.trend = beta*(state.level-previousLevel) + (1-beta)*state.trend
+		if period > 0 {
+			state.season[t%period] = gamma*(values[t]-state.level) + (1-gamma)*seasonal
+		}
+	}
+
+	if state.steps == 0 {
+		state.steps = 1
+	}
+	return state
+}
+
+// cycleShape returns half the peak-to-trough range of seasonal indexes and where in the cycle
+// the peak falls, as a fraction of it
+func cycleShape(indexes []float64) (float64, float64) {
+	if len(indexes) == 0 {
+		return 0, 0
+	}
+	peak, trough := 0, 0
+	for i, value := range indexes {
+		if value > indexes[peak] {
+			peak = i
+		}
+		if value < indexes[trough] {
+			trough = i
+		}
+	}
+	return (indexes[peak] - indexes[trough]) / 2, float64(peak) / float64(len(indexes))
+}
+
+// medianInterval returns the median time between consecutive points
+func medianInterval(data []DataPoint) time.Duration {
+	if len(data) < 2 {
+		return 0
+	}
+	intervals := make([]time.Duration, 0, len(data)-1)
+	for i := 1; i < len(data); i++ {
+		if gap := data[i].Timestamp.Sub(data[i-1].Timestamp); gap > 0 {
+			intervals = append(intervals, gap)
+		}
+	}
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+// fitLine fits y = slope*x + intercept by least squares
+func fitLine(x, y []float64) (float64, float64) {
+	n := float64(len(x))
+	sumX, sumY, sumXY, sumX2 := 0.0, 0.0, 0.0, 0.0
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+		sumXY += x[i] * y[i]
+		sumX2 += x[i] * x[i]
+	}
+	denominator := n*sumX2 - sumX*sumX
+	if denominator == 0 {
+		return 0, sumY / n
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return slope, (sumY - slope*sumX) / n
+}
+
+func variance(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - m) * (value - m)
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,408 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to an event for a full stream buffer or subscriber queue
+type OverflowPolicy string
+
+const (
+	// OverflowDropNewest drops the event being published
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest drops the oldest queued event to make room, keeping consumers current
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock makes the publisher wait up to the block timeout for room, then drops the
+	// event
+	OverflowBlock OverflowPolicy = "block"
+)
+
+const (
+	defaultBlockTimeout        = 100 * time.Millisecond
+	defaultSlowConsumerTimeout = 30 * time.Second
+	defaultSubscriberBuffer    = 100
+)
+
+// SubscribeOptions configures a subscription. Zero values take the engine's configuration.
+type SubscribeOptions struct {
+	Name           string         // shown in stats and slow consumer logs
+	BufferSize     int            // capacity of the subscriber's queue
+	OverflowPolicy OverflowPolicy // what happens to events while the queue is full
+	BlockTimeout   time.Duration  // how long OverflowBlock waits for room
+}
+
+// Subscription is a subscriber's bounded queue of events of an event type or, for stream
+// subscriptions, of a data stream. Events is closed when the subscriber unsubscribes, is
+// disconnected as a slow consumer or its stream is deleted.
+type Subscription struct {
+	ID        string
+	EventType EventType
+	StreamID  string
+	Events    <-chan *AnalyticsEvent
+}
+
+// subscriber is the engine's side of a subscription
+type subscriber struct {
+	id           string
+	name         string
+	eventType    EventType
+	streamID     string
+	queue        chan *AnalyticsEvent
+	policy       OverflowPolicy
+	blockTimeout time.Duration
+
+	published atomic.Int64
+	delivered atomic.Int64
+	dropped   atomic.Int64
+
+	// mu serializes deliveries with closing the queue
+	mu             sync.Mutex
+	closed         bool
+	saturatedSince time.Time
+	slow           bool
+}
+
+// StreamStats counts the events routed to a data stream. Delivered events reached the stream's
+// processor.
+type StreamStats struct {
+	StreamID          string  `json:"stream_id"`
+	Name              string  `json:"name"`
+	Published         int64   `json:"published"`
+	Delivered         int64   `json:"delivered"`
+	Dropped           int64   `json:"dropped"`
+	Buffered          int     `json:"buffered"`
+	BufferSize        int     `json:"buffer_size"`
+	BufferUtilization float64 `json:"buffer_utilization"`
+}
+
+// SubscriberStats counts the events published to a subscriber. Delivered events were queued
+// for it; dropped ones were not, or were evicted by OverflowDropOldest.
+type SubscriberStats struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name,omitempty"`
+	EventType      EventType      `json:"event_type,omitempty"`
+	StreamID       string         `json:"stream_id,omitempty"`
+	OverflowPolicy OverflowPolicy `json:"overflow_policy"`
+	Published      int64          `json:"published"`
+	Delivered      int64          `json:"delivered"`
+	Dropped        int64          `json:"dropped"`
+	Queued         int            `json:"queued"`
+	QueueSize      int            `json:"queue_size"`
+	SaturatedFor   time.Duration  `json:"saturated_for"`
+	SlowConsumer   bool           `json:"slow_consumer"`
+}
+
+// EngineStats reports the event flow through the engine next to its configured capacity, for
+// sizing MaxConcurrentStreams and BufferSize
+type EngineStats struct {
+	EventsPublished         int64             `json:"events_published"`
+	EventsDropped           int64             `json:"events_dropped"`
+	ActiveStreams           int               `json:"active_streams"`
+	MaxConcurrentStreams    int               `json:"max_concurrent_streams"`
+	BufferSize              int               `json:"buffer_size"`
+	OverflowPolicy          OverflowPolicy    `json:"overflow_policy"`
+	SlowConsumers           int               `json:"slow_consumers"`
+	DisconnectedSubscribers int64             `json:"disconnected_subscribers"`
+	Streams                 []StreamStats     `json:"streams"`
+	Subscribers             []SubscriberStats `json:"subscribers"`
+	GeneratedAt             time.Time         `json:"generated_at"`
+}
+
+// SubscribeWithOptions subscribes to events of a specific type with a bounded queue
+func (e *RealTimeAnalyticsEngine) SubscribeWithOptions(eventType EventType, options SubscribeOptions) *Subscription {
+	sub := e.newSubscriber(eventType, options)
+
+	e.mu.Lock()
+	e.subscribers[string(eventType)] = append(e.subscribers[string(eventType)], sub)
+	e.mu.Unlock()
+
+	return &Subscription{ID: sub.id, EventType: eventType, Events: sub.queue}
+}
+
+// Unsubscribe removes a subscription and closes its events channel
+func (e *RealTimeAnalyticsEngine) Unsubscribe(subscriptionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for eventType, subs := range e.subscribers {
+		for i, sub := range subs {
+			if sub.id == subscriptionID {
+				e.subscribers[eventType] = append(subs[:i:i], subs[i+1:]...)
+				sub.close()
+				return
+			}
+		}
+	}
+	for _, stream := range e.dataStreams {
+		for i, sub := range stream.subscribers {
+			if sub.id == subscriptionID {
+				stream.subscribers = append(stream.subscribers[:i:i], stream.subscribers[i+1:]...)
+				sub.close()
+				return
+			}
+		}
+	}
+}
+
+// GetEngineStats returns the published, delivered and dropped events of every stream and
+// subscriber
+func (e *RealTimeAnalyticsEngine) GetEngineStats() *EngineStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := time.Now()
+	stats := &EngineStats{
+		EventsPublished:         e.published.Load(),
+		ActiveStreams:           len(e.dataStreams),
+		MaxConcurrentStreams:    e.config.MaxConcurrentStreams,
+		BufferSize:              e.config.BufferSize,
+		OverflowPolicy:          e.config.OverflowPolicy,
+		DisconnectedSubscribers: e.disconnected.Load(),
+		Streams:                 make([]StreamStats, 0, len(e.dataStreams)),
+		Subscribers:             []SubscriberStats{},
+		GeneratedAt:             now,
+	}
+
+	for _, stream := range e.dataStreams {
+		streamStats := StreamStats{
+			StreamID:          stream.StreamID,
+			Name:              stream.Name,
+			Published:         stream.published.Load(),
+			Delivered:         stream.delivered.Load(),
+			Dropped:           stream.dropped.Load(),
+			Buffered:          len(stream.Buffer),
+			BufferSize:        cap(stream.Buffer),
+			BufferUtilization: bufferUtilization(stream.Buffer),
+		}
+		stats.EventsDropped += streamStats.Dropped
+		stats.Streams = append(stats.Streams, streamStats)
+	}
+	sort.Slice(stats.Streams, func(i, j int) bool { return stats.Streams[i].Name < stats.Streams[j].Name })
+
+	addSubscribers := func(subs []*subscriber) {
+		for _, sub := range subs {
+			subscriberStats := sub.stats(now)
+			if subscriberStats.SlowConsumer {
+				stats.SlowConsumers++
+			}
+			stats.EventsDropped += subscriberStats.Dropped
+			stats.Subscribers = append(stats.Subscribers, subscriberStats)
+		}
+	}
+	for _, subs := range e.subscribers {
+		addSubscribers(subs)
+	}
+	for _, stream := range e.dataStreams {
+		addSubscribers(stream.subscribers)
+	}
+	sort.Slice(stats.Subscribers, func(i, j int) bool {
+		a, b := stats.Subscribers[i], stats.Subscribers[j]
+		if a.EventType != b.EventType {
+			return a.EventType < b.EventType
+		}
+		if a.StreamID != b.StreamID {
+			return a.StreamID < b.StreamID
+		}
+		return a.ID < b.ID
+	})
+
+	return stats
+}
+
+// monitorSubscribers checks for slow consumers twice per slow consumer timeout
+func (e *RealTimeAnalyticsEngine) monitorSubscribers(ctx context.Context) {
+	interval := e.config.SlowConsumerTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.detectSlowConsumers(ctx, now)
+		}
+	}
+}
+
+// detectSlowConsumers logs subscribers whose queue has been full for the slow consumer timeout
+// and, when configured, disconnects them
+func (e *RealTimeAnalyticsEngine) detectSlowConsumers(ctx context.Context, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for eventType, subs := range e.subscribers {
+		e.subscribers[eventType] = e.checkSlowConsumers(ctx, subs, now)
+	}
+	for _, stream := range e.dataStreams {
+		stream.subscribers = e.checkSlowConsumers(ctx, stream.subscribers, now)
+	}
+}
+
+// checkSlowConsumers logs the newly slow consumers of subs, returning the subscribers kept
+func (e *RealTimeAnalyticsEngine) checkSlowConsumers(ctx context.Context, subs []*subscriber, now time.Time) []*subscriber {
+	kept := subs[:0]
+	for _, sub := range subs {
+		saturatedFor, newlySlow := sub.checkSlow(now, e.config.SlowConsumerTimeout)
+		if newlySlow {
+			e.logger.Warn(ctx, "Slow analytics event consumer", map[string]interface{}{
+				"subscription_id": sub.id,
+				"name":            sub.name,
+				"event_type":      sub.eventType,
+				"stream_id":       sub.streamID,
+				"saturated_for":   saturatedFor.String(),
+				"dropped":         sub.dropped.Load(),
+				"disconnect":      e.config.DisconnectSlowConsumers,
+			})
+		}
+		if newlySlow && e.config.DisconnectSlowConsumers {
+			sub.close()
+			e.disconnected.Add(1)
+			continue
+		}
+		kept = append(kept, sub)
+	}
+	return kept
+}
+
+// deliver queues event for the subscriber following its overflow policy
+func (s *subscriber) deliver(event *AnalyticsEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	s.published.Add(1)
+	enqueued, dropped, saturated := offer(s.queue, event, s.policy, s.blockTimeout)
+	if enqueued {
+		s.delivered.Add(1)
+	}
+	s.dropped.Add(dropped)
+
+	switch {
+	case !saturated:
+		s.saturatedSince = time.Time{}
+		s.slow = false
+	case s.saturatedSince.IsZero():
+		s.saturatedSince = time.Now()
+	}
+}
+
+// checkSlow reports how long the queue has been full and whether the subscriber just became a
+// slow consumer
+func (s *subscriber) checkSlow(now time.Time, timeout time.Duration) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The queue may have drained since the last delivery
+	if s.saturatedSince.IsZero() || len(s.queue) < cap(s.queue) {
+		s.saturatedSince = time.Time{}
+		s.slow = false
+		return 0, false
+	}
+	saturatedFor := now.Sub(s.saturatedSince)
+	if saturatedFor < timeout || s.slow {
+		return saturatedFor, false
+	}
+	s.slow = true
+	return saturatedFor, true
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+}
+
+func (s *subscriber) stats(now time.Time) SubscriberStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SubscriberStats{
+		ID:             s.id,
+		Name:           s.name,
+		EventType:      s.eventType,
+		StreamID:       s.streamID,
+		OverflowPolicy: s.policy,
+		Published:      s.published.Load(),
+		Delivered:      s.delivered.Load(),
+		Dropped:        s.dropped.Load(),
+		Queued:         len(s.queue),
+		QueueSize:      cap(s.queue),
+		SlowConsumer:   s.slow,
+	}
+	if !s.saturatedSince.IsZero() {
+		stats.SaturatedFor = now.Sub(s.saturatedSince)
+	}
+	return stats
+}
+
+// offer queues event following policy. It reports whether the event was queued, how many
+// events were dropped for it, and whether the queue was full.
+func offer(queue chan *AnalyticsEvent, event *AnalyticsEvent, policy OverflowPolicy, blockTimeout time.Duration) (bool, int64, bool) {
+	select {
+	case queue <- event:
+		return true, 0, false
+	default:
+	}
+
+	switch policy {
+	case OverflowDropOldest:
+		var dropped int64
+		select {
+		case <-queue:
+			dropped++
+		default:
+		}
+		select {
+		case queue <- event:
+			return true, dropped, true
+		default:
+			// Another publisher took the slot
+			return false, dropped + 1, true
+		}
+	case OverflowBlock:
+		timer := time.NewTimer(blockTimeout)
+		defer timer.Stop()
+		select {
+		case queue <- event:
+			return true, 0, true
+		case <-timer.C:
+			return false, 1, true
+		}
+	default:
+		return false, 1, true
+	}
+}
+
+// metricsSnapshot returns a copy of the stream's metrics with current counters
+func (s *DataStream) metricsSnapshot() *StreamMetrics {
+	s.mu.RLock()
+	metrics := *s.Metrics
+	s.mu.RUnlock()
+
+	metrics.EventsPublished = s.published.Load()
+	metrics.EventsDropped = s.dropped.Load()
+	metrics.EventsProcessed = s.delivered.Load()
+	metrics.BufferUtilization = bufferUtilization(s.Buffer)
+	return &metrics
+}
+
+// bufferUtilization returns how full a buffer is in percent
+func bufferUtilization(buffer chan *AnalyticsEvent) float64 {
+	if cap(buffer) == 0 {
+		return 0
+	}
+	return float64(len(buffer)) / float64(cap(buffer)) * 100
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
@@ -21,7 +22,9 @@ type RealTimeAnalyticsEngine struct {
 	alertManager       *AlertManager
 	dashboardManager   *DashboardManager
 	dataStreams        map[string]*DataStream
-	subscribers        map[string][]chan *AnalyticsEvent
+	subscribers        map[string][]*subscriber
+	published          atomic.Int64
+	disconnected       atomic.Int64
 	mu                 sync.RWMutex
 }
 
@@ -39,6 +42,32 @@ type AnalyticsConfig struct {
 	BufferSize                  int           `json:"buffer_size"`
 	EnableDataCompression       bool          `json:"enable_data_compression"`
 	EnableDataEncryption        bool          `json:"enable_data_encryption"`
+
+	// OverflowPolicy decides what happens to events for full stream buffers and subscriber
+	// queues; subscribers may choose their own. BlockTimeout bounds OverflowBlock.
+	OverflowPolicy OverflowPolicy `json:"overflow_policy"`
+	BlockTimeout   time.Duration  `json:"block_timeout"`
+
+	// Subscribers whose queue stays full for SlowConsumerTimeout are logged as slow consumers
+	// and, with DisconnectSlowConsumers, unsubscribed
+	SlowConsumerTimeout     time.Duration `json:"slow_consumer_timeout"`
+	DisconnectSlowConsumers bool          `json:"disconnect_slow_consumers"`
+
+	// Alerts related by CorrelationRules within CorrelationWindow are grouped into one
+	// incident, notified IncidentGroupWait after it opens; a negative wait notifies at once
+	CorrelationWindow time.Duration      `json:"correlation_window"`
+	IncidentGroupWait time.Duration      `json:"incident_group_wait"`
+	CorrelationRules  []*CorrelationRule `json:"correlation_rules,omitempty"`
+
+	// Unacknowledged alerts escalate through the policy their rule names, or else the one
+	// SeverityEscalationPolicies attaches to their severity. Escalation steps may page the
+	// current on-call user of OnCallSchedules.
+	EscalationPolicies         []*EscalationPolicy      `json:"escalation_policies,omitempty"`
+	SeverityEscalationPolicies map[AlertSeverity]string `json:"severity_escalation_policies,omitempty"`
+	OnCallSchedules            []*OnCallSchedule        `json:"on_call_schedules,omitempty"`
+
+	// Computed dashboard widgets, such as correlation matrices, are cached for WidgetCacheTTL
+	WidgetCacheTTL time.Duration `json:"widget_cache_ttl"`
 }
 
 // AnalyticsEvent represents a real-time analytics event
@@ -96,6 +125,16 @@ type DataStream struct {
 	CreatedAt    time.Time            `json:"created_at"`
 	LastActivity time.Time            `json:"last_activity"`
 	mu           sync.RWMutex         `json:"-"`
+
+	published atomic.Int64
+	delivered atomic.Int64
+	dropped   atomic.Int64
+
+	// subscribers is guarded by the engine's lock
+	subscribers     []*subscriber
+	cancel          context.CancelFunc
+	throughputCount int64
+	throughputAt    time.Time
 }
 
 // StreamConfig contains stream configuration
@@ -106,7 +145,7 @@ type StreamConfig struct {
 	BatchSize              int           `json:"batch_size"`
 	EnableCompression      bool          `json:"enable_compression"`
 	EnableEncryption       bool          `json:"enable_encryption"`
-	RetentionPeriod        time.Duration `json:"retention_period"`
+	RetentionPeriod        time.Duration `json:"retention_period"` // at most MetricsRetentionPeriod
 	EnableAnomalyDetection bool          `json:"enable_anomaly_detection"`
 	EnablePrediction       bool          `json:"enable_prediction"`
 }
@@ -124,6 +163,8 @@ const (
 
 // StreamMetrics contains stream performance metrics
 type StreamMetrics struct {
+	EventsPublished   int64         `json:"events_published"`
+	EventsDropped     int64         `json:"events_dropped"`
 	EventsProcessed   int64         `json:"events_processed"`
 	EventsPerSecond   float64       `json:"events_per_second"`
 	AverageLatency    time.Duration `json:"average_latency"`
@@ -150,12 +191,21 @@ func NewRealTimeAnalyticsEngine(logger *observability.Logger, config *AnalyticsC
 			EnableDataEncryption:        false,
 		}
 	}
+	if config.OverflowPolicy == "" {
+		config.OverflowPolicy = OverflowDropNewest
+	}
+	if config.BlockTimeout <= 0 {
+		config.BlockTimeout = defaultBlockTimeout
+	}
+	if config.SlowConsumerTimeout <= 0 {
+		config.SlowConsumerTimeout = defaultSlowConsumerTimeout
+	}
 
 	engine := &RealTimeAnalyticsEngine{
 		logger:      logger,
 		config:      config,
 		dataStreams: make(map[string]*DataStream),
-		subscribers: make(map[string][]chan *AnalyticsEvent),
+		subscribers: make(map[string][]*subscriber),
 	}
 
 	// Initialize components
@@ -212,13 +262,25 @@ func (e *RealTimeAnalyticsEngine) Start(ctx context.Context) error {
 	go e.processEvents(ctx)
 	go e.aggregateMetrics(ctx)
 	go e.monitorStreams(ctx)
+	go e.monitorSubscribers(ctx)
 
 	e.logger.Info(ctx, "Real-time analytics engine started successfully", nil)
 	return nil
 }
 
-// CreateDataStream creates a new data stream
+// CreateDataStream creates a new data stream. The metrics of events routed only to streams
+// with a shorter retention period than MetricsRetentionPeriod are kept for that period.
 func (e *RealTimeAnalyticsEngine) CreateDataStream(name, source string, eventTypes []EventType, config *StreamConfig) (*DataStream, error) {
+	if config != nil {
+		retention, err := e.streamRetention(config.RetentionPeriod)
+		if err != nil {
+			return nil, err
+		}
+		copied := *config
+		copied.RetentionPeriod = retention
+		config = &copied
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -227,17 +289,7 @@ func (e *RealTimeAnalyticsEngine) CreateDataStream(name, source string, eventTyp
 	}
 
 	if config == nil {
-		config = &StreamConfig{
-			BufferSize:             e.config.BufferSize,
-			ProcessingInterval:     e.config.ProcessingInterval,
-			EnableBatching:         true,
-			BatchSize:              100,
-			EnableCompression:      e.config.EnableDataCompression,
-			EnableEncryption:       e.config.EnableDataEncryption,
-			RetentionPeriod:        e.config.MetricsRetentionPeriod,
-			EnableAnomalyDetection: e.config.EnableAnomalyDetection,
-			EnablePrediction:       e.config.EnablePredictiveAnalytics,
-		}
+		config = e.DefaultStreamConfig()
 	}
 
 	streamID := uuid.New().String()
@@ -257,8 +309,10 @@ func (e *RealTimeAnalyticsEngine) CreateDataStream(name, source string, eventTyp
 
 	e.dataStreams[streamID] = stream
 
-	// Start stream processor
-	go e.processStream(context.Background(), stream)
+	// Start stream processor until the stream is deleted
+	ctx, cancel := context.WithCancel(context.Background())
+	stream.cancel = cancel
+	go e.processStream(ctx, stream)
 
 	e.logger.Info(context.Background(), "Data stream created", map[string]interface{}{
 		"stream_id":   streamID,
@@ -270,7 +324,24 @@ func (e *RealTimeAnalyticsEngine) CreateDataStream(name, source string, eventTyp
 	return stream, nil
 }
 
-// PublishEvent publishes an analytics event to the appropriate streams
+// DefaultStreamConfig returns the configuration of data streams created without one
+func (e *RealTimeAnalyticsEngine) DefaultStreamConfig() *StreamConfig {
+	return &StreamConfig{
+		BufferSize:             e.config.BufferSize,
+		ProcessingInterval:     e.config.ProcessingInterval,
+		EnableBatching:         true,
+		BatchSize:              100,
+		EnableCompression:      e.config.EnableDataCompression,
+		EnableEncryption:       e.config.EnableDataEncryption,
+		RetentionPeriod:        e.config.MetricsRetentionPeriod,
+		EnableAnomalyDetection: e.config.EnableAnomalyDetection,
+		EnablePrediction:       e.config.EnablePredictiveAnalytics,
+	}
+}
+
+// PublishEvent publishes an analytics event to the appropriate streams and subscribers. Full
+// buffers and queues are handled by their overflow policy; with OverflowBlock publishing waits
+// up to BlockTimeout for each of them.
 func (e *RealTimeAnalyticsEngine) PublishEvent(event *AnalyticsEvent) error {
 	if event.EventID == "" {
 		event.EventID = uuid.New().String()
@@ -278,54 +349,64 @@ func (e *RealTimeAnalyticsEngine) PublishEvent(event *AnalyticsEvent) error {
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now()
 	}
+	e.published.Add(1)
 
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	// Route event to appropriate streams
+	var routed []*DataStream
 	for _, stream := range e.dataStreams {
 		if e.shouldRouteToStream(event, stream) {
-			select {
-			case stream.Buffer <- event:
-				stream.LastActivity = time.Now()
-			default:
+			routed = append(routed, stream)
+			stream.published.Add(1)
+			enqueued, dropped, _ := offer(stream.Buffer, event, e.config.OverflowPolicy, e.config.BlockTimeout)
+			if dropped > 0 {
+				stream.dropped.Add(dropped)
 				e.logger.Warn(context.Background(), "Stream buffer full, dropping event", map[string]interface{}{
-					"stream_id": stream.StreamID,
-					"event_id":  event.EventID,
+					"stream_id":       stream.StreamID,
+					"event_id":        event.EventID,
+					"overflow_policy": e.config.OverflowPolicy,
 				})
 			}
+			if enqueued {
+				stream.mu.Lock()
+				stream.LastActivity = time.Now()
+				stream.mu.Unlock()
+			}
+			for _, subscriber := range stream.subscribers {
+				subscriber.deliver(event)
+			}
 		}
 	}
 
 	// Notify subscribers
-	if subscribers, exists := e.subscribers[string(event.EventType)]; exists {
-		for _, subscriber := range subscribers {
-			select {
-			case subscriber <- event:
-			default:
-				// Non-blocking send
-			}
-		}
+	for _, subscriber := range e.subscribers[string(event.EventType)] {
+		subscriber.deliver(event)
+	}
+
+	// Keep metric history for computed dashboard widgets
+	retention := e.metricRetention(routed)
+	for name, value := range event.Metrics {
+		e.dashboardManager.recordMetric(name, value, event.Timestamp, retention)
 	}
 
 	return nil
 }
 
-// Subscribe subscribes to events of a specific type
+// Subscribe subscribes to events of a specific type with the engine's overflow policy
 func (e *RealTimeAnalyticsEngine) Subscribe(eventType EventType, bufferSize int) <-chan *AnalyticsEvent {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
-	subscriber := make(chan *AnalyticsEvent, bufferSize)
-	eventTypeStr := string(eventType)
-
-	if _, exists := e.subscribers[eventTypeStr]; !exists {
-		e.subscribers[eventTypeStr] = make([]chan *AnalyticsEvent, 0)
-	}
+	return e.SubscribeWithOptions(eventType, SubscribeOptions{BufferSize: bufferSize}).Events
+}
 
-	e.subscribers[eventTypeStr] = append(e.subscribers[eventTypeStr], subscriber)
+// GetAlertManager returns the engine's alert manager
+func (e *RealTimeAnalyticsEngine) GetAlertManager() *AlertManager {
+	return e.alertManager
+}
 
-	return subscriber
+// GetDashboardManager returns the engine's dashboard manager
+func (e *RealTimeAnalyticsEngine) GetDashboardManager() *DashboardManager {
+	return e.dashboardManager
 }
 
 // GetStreamMetrics returns metrics for all streams
@@ -335,9 +416,7 @@ func (e *RealTimeAnalyticsEngine) GetStreamMetrics() map[string]*StreamMetrics {
 
 	metrics := make(map[string]*StreamMetrics)
 	for streamID, stream := range e.dataStreams {
-		stream.mu.RLock()
-		metrics[streamID] = stream.Metrics
-		stream.mu.RUnlock()
+		metrics[streamID] = stream.metricsSnapshot()
 	}
 
 	return metrics
@@ -395,6 +474,7 @@ func (e *RealTimeAnalyticsEngine) processStream(ctx context.Context, stream *Dat
 		case <-ctx.Done():
 			return
 		case event := <-stream.Buffer:
+			stream.delivered.Add(1)
 			if err := stream.Processor.ProcessEvent(ctx, event); err != nil {
 				e.logger.Error(ctx, "Failed to process event", err, map[string]interface{}{
 					"stream_id": stream.StreamID,
@@ -405,8 +485,12 @@ func (e *RealTimeAnalyticsEngine) processStream(ctx context.Context, stream *Dat
 	}
 }
 
-// shouldRouteToStream determines if an event should be routed to a stream
+// shouldRouteToStream determines if an event should be routed to a stream. Paused streams
+// receive no events.
 func (e *RealTimeAnalyticsEngine) shouldRouteToStream(event *AnalyticsEvent, stream *DataStream) bool {
+	if stream.Status != StreamStatusActive {
+		return false
+	}
 	for _, eventType := range stream.EventTypes {
 		if event.EventType == eventType {
 			return true
@@ -420,10 +504,15 @@ func (e *RealTimeAnalyticsEngine) updateStreamMetrics() {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	now := time.Now()
 	for _, stream := range e.dataStreams {
 		stream.mu.Lock()
-		stream.Metrics.BufferUtilization = float64(len(stream.Buffer)) / float64(cap(stream.Buffer)) * 100
-		stream.Metrics.LastUpdated = time.Now()
+		stream.updateThroughput(now)
+		stream.Metrics.EventsPublished = stream.published.Load()
+		stream.Metrics.EventsDropped = stream.dropped.Load()
+		stream.Metrics.EventsProcessed = stream.delivered.Load()
+		stream.Metrics.BufferUtilization = bufferUtilization(stream.Buffer)
+		stream.Metrics.LastUpdated = now
 		stream.mu.Unlock()
 	}
 }
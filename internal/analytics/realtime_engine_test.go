@@ -0,0 +1,177 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+)
+
+func newTestRealTimeEngine(analyticsConfig *AnalyticsConfig) *RealTimeAnalyticsEngine {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "error",
+	})
+	return NewRealTimeAnalyticsEngine(logger, analyticsConfig)
+}
+
+func publishPrices(t *testing.T, engine *RealTimeAnalyticsEngine, prices ...float64) {
+	t.Helper()
+	for _, price := range prices {
+		event := &AnalyticsEvent{EventType: EventTypeMarketData, Metrics: map[string]float64{"price": price}}
+		if err := engine.PublishEvent(event); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+}
+
+func queuedPrices(events <-chan *AnalyticsEvent) []float64 {
+	var prices []float64
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return prices
+			}
+			prices = append(prices, event.Metrics["price"])
+		default:
+			return prices
+		}
+	}
+}
+
+func TestSubscriberOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		queued []float64
+	}{
+		{OverflowDropNewest, []float64{1, 2}},
+		{OverflowDropOldest, []float64{3, 4}},
+		{OverflowBlock, []float64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			engine := newTestRealTimeEngine(&AnalyticsConfig{BlockTimeout: 5 * time.Millisecond})
+			subscription := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{
+				Name:           "prices",
+				BufferSize:     2,
+				OverflowPolicy: tt.policy,
+			})
+
+			publishPrices(t, engine, 1, 2, 3, 4)
+
+			prices := queuedPrices(subscription.Events)
+			if len(prices) != len(tt.queued) || prices[0] != tt.queued[0] || prices[1] != tt.queued[1] {
+				t.Errorf("Expected queued prices %v, got %v", tt.queued, prices)
+			}
+
+			stats := engine.GetEngineStats()
+			if len(stats.Subscribers) != 1 {
+				t.Fatalf("Expected 1 subscriber, got %d", len(stats.Subscribers))
+			}
+			subscriber := stats.Subscribers[0]
+			if subscriber.Published != 4 || subscriber.Dropped != 2 {
+				t.Errorf("Expected 4 published and 2 dropped, got %d and %d", subscriber.Published, subscriber.Dropped)
+			}
+			if tt.policy != OverflowDropOldest && subscriber.Delivered != 2 {
+				t.Errorf("Expected 2 delivered, got %d", subscriber.Delivered)
+			}
+			if stats.EventsPublished != 4 || stats.EventsDropped != 2 {
+				t.Errorf("Expected engine to count 4 published and 2 dropped, got %d and %d", stats.EventsPublished, stats.EventsDropped)
+			}
+		})
+	}
+}
+
+func TestBlockingSubscriberWaitsForRoom(t *testing.T) {
+	engine := newTestRealTimeEngine(&AnalyticsConfig{BlockTimeout: time.Second})
+	subscription := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{BufferSize: 1, OverflowPolicy: OverflowBlock})
+
+	publishPrices(t, engine, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-subscription.Events
+	}()
+	publishPrices(t, engine, 2)
+
+	if prices := queuedPrices(subscription.Events); len(prices) != 1 || prices[0] != 2 {
+		t.Errorf("Expected the blocked event to be queued, got %v", prices)
+	}
+	if stats := engine.GetEngineStats(); stats.EventsDropped != 0 {
+		t.Errorf("Expected no dropped events, got %d", stats.EventsDropped)
+	}
+}
+
+func TestSlowConsumerDetection(t *testing.T) {
+	ctx := context.Background()
+	engine := newTestRealTimeEngine(&AnalyticsConfig{SlowConsumerTimeout: time.Minute})
+	slow := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{Name: "slow", BufferSize: 1})
+	fast := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{Name: "fast", BufferSize: 10})
+
+	publishPrices(t, engine, 1, 2)
+
+	engine.detectSlowConsumers(ctx, time.Now())
+	if stats := engine.GetEngineStats(); stats.SlowConsumers != 0 {
+		t.Errorf("Expected no slow consumers before the timeout, got %d", stats.SlowConsumers)
+	}
+
+	engine.detectSlowConsumers(ctx, time.Now().Add(2*time.Minute))
+	stats := engine.GetEngineStats()
+	if stats.SlowConsumers != 1 || stats.DisconnectedSubscribers != 0 {
+		t.Errorf("Expected 1 slow consumer still connected, got %d slow and %d disconnected", stats.SlowConsumers, stats.DisconnectedSubscribers)
+	}
+
+	// Draining the queue clears the slow consumer
+	queuedPrices(slow.Events)
+	queuedPrices(fast.Events)
+	engine.detectSlowConsumers(ctx, time.Now().Add(2*time.Minute))
+	if stats := engine.GetEngineStats(); stats.SlowConsumers != 0 {
+		t.Errorf("Expected drained consumer not to be slow, got %d", stats.SlowConsumers)
+	}
+}
+
+func TestSlowConsumerDisconnect(t *testing.T) {
+	ctx := context.Background()
+	engine := newTestRealTimeEngine(&AnalyticsConfig{SlowConsumerTimeout: time.Minute, DisconnectSlowConsumers: true})
+	slow := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{Name: "slow", BufferSize: 1})
+	fast := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{Name: "fast", BufferSize: 10})
+
+	publishPrices(t, engine, 1, 2)
+	engine.detectSlowConsumers(ctx, time.Now().Add(2*time.Minute))
+
+	// The slow subscriber keeps its queued event, then its channel is closed
+	if prices := queuedPrices(slow.Events); len(prices) != 1 {
+		t.Errorf("Expected 1 queued event for the slow consumer, got %v", prices)
+	}
+	if _, ok := <-slow.Events; ok {
+		t.Error("Expected slow consumer's channel to be closed")
+	}
+
+	publishPrices(t, engine, 3)
+	if prices := queuedPrices(fast.Events); len(prices) != 3 {
+		t.Errorf("Expected fast consumer to keep receiving events, got %v", prices)
+	}
+
+	stats := engine.GetEngineStats()
+	if stats.DisconnectedSubscribers != 1 || len(stats.Subscribers) != 1 || stats.Subscribers[0].Name != "fast" {
+		t.Errorf("Expected only the fast subscriber to remain, got %+v", stats.Subscribers)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	engine := newTestRealTimeEngine(nil)
+	subscription := engine.SubscribeWithOptions(EventTypeMarketData, SubscribeOptions{})
+
+	engine.Unsubscribe(subscription.ID)
+	publishPrices(t, engine, 1)
+
+	if _, ok := <-subscription.Events; ok {
+		t.Error("Expected events channel to be closed")
+	}
+	if stats := engine.GetEngineStats(); len(stats.Subscribers) != 0 || stats.OverflowPolicy != OverflowDropNewest {
+		t.Errorf("Expected no subscribers and the default policy, got %+v", stats)
+	}
+}
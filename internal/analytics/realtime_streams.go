@@ -0,0 +1,304 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrStreamNotFound is returned for unknown data stream IDs
+	ErrStreamNotFound = errors.New("data stream not found")
+	// ErrInvalidStream is returned for data streams without a name or event types, or with a
+	// retention period longer than the metrics retention period
+	ErrInvalidStream = errors.New("invalid data stream")
+)
+
+// DataStreamInfo describes a data stream for administration: its event type filters,
+// throughput and subscribers. Subscribers lag behind the stream by their queued events.
+type DataStreamInfo struct {
+	StreamID        string            `json:"stream_id"`
+	Name            string            `json:"name"`
+	Source          string            `json:"source"`
+	EventTypes      []EventType       `json:"event_types"`
+	Status          StreamStatus      `json:"status"`
+	RetentionPeriod time.Duration     `json:"retention_period"`
+	Metrics         *StreamMetrics    `json:"metrics"`
+	Subscribers     []SubscriberStats `json:"subscribers"`
+	CreatedAt       time.Time         `json:"created_at"`
+	LastActivity    time.Time         `json:"last_activity"`
+}
+
+// DataStreamUpdate changes a data stream. Nil fields are left unchanged; a zero retention
+// period resets the stream to the metrics retention period.
+type DataStreamUpdate struct {
+	Name            *string        `json:"name,omitempty"`
+	EventTypes      []EventType    `json:"event_types,omitempty"`
+	RetentionPeriod *time.Duration `json:"retention_period,omitempty"`
+}
+
+// GetDataStreams returns every data stream, ordered by name
+func (e *RealTimeAnalyticsEngine) GetDataStreams() []*DataStreamInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := time.Now()
+	streams := make([]*DataStreamInfo, 0, len(e.dataStreams))
+	for _, stream := range e.dataStreams {
+		streams = append(streams, stream.info(now))
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		if streams[i].Name != streams[j].Name {
+			return streams[i].Name < streams[j].Name
+		}
+		return streams[i].StreamID < streams[j].StreamID
+	})
+	return streams
+}
+
+// GetDataStream returns a data stream
+func (e *RealTimeAnalyticsEngine) GetDataStream(streamID string) (*DataStreamInfo, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	stream, exists := e.dataStreams[streamID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrStreamNotFound, streamID)
+	}
+	return stream.info(time.Now()), nil
+}
+
+// UpdateDataStream renames a data stream, replaces its event type filters or changes its
+// retention period. New filters apply to events published after the update; the stream keeps
+// its buffered events and subscribers.
+func (e *RealTimeAnalyticsEngine) UpdateDataStream(streamID string, update DataStreamUpdate) (*DataStreamInfo, error) {
+	if update.Name != nil && *update.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidStream)
+	}
+	if update.EventTypes != nil && len(update.EventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidStream)
+	}
+	var retention time.Duration
+	if update.RetentionPeriod != nil {
+		var err error
+		if retention, err = e.streamRetention(*update.RetentionPeriod); err != nil {
+			return nil, err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stream, exists := e.dataStreams[streamID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrStreamNotFound, streamID)
+	}
+
+	// Publishing reads the filters under the engine's read lock, so the new filters take
+	// effect from the next published event
+	stream.mu.Lock()
+	if update.Name != nil {
+		stream.Name = *update.Name
+	}
+	if update.EventTypes != nil {
+		stream.EventTypes = append([]EventType(nil), update.EventTypes...)
+	}
+	if update.RetentionPeriod != nil {
+		config := *stream.Config
+		config.RetentionPeriod = retention
+		stream.Config = &config
+	}
+	stream.mu.Unlock()
+
+	e.logger.Info(context.Background(), "Data stream updated", map[string]interface{}{
+		"stream_id":        streamID,
+		"name":             stream.Name,
+		"event_types":      stream.EventTypes,
+		"retention_period": stream.Config.RetentionPeriod.String(),
+	})
+
+	return stream.info(time.Now()), nil
+}
+
+// DeleteDataStream stops a data stream's processor and closes its subscriptions. Events still
+// buffered are discarded.
+func (e *RealTimeAnalyticsEngine) DeleteDataStream(streamID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stream, exists := e.dataStreams[streamID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrStreamNotFound, streamID)
+	}
+	delete(e.dataStreams, streamID)
+
+	stream.mu.Lock()
+	stream.Status = StreamStatusStopped
+	stream.mu.Unlock()
+	if stream.cancel != nil {
+		stream.cancel()
+	}
+	for _, sub := range stream.subscribers {
+		sub.close()
+	}
+	stream.subscribers = nil
+
+	e.logger.Info(context.Background(), "Data stream deleted", map[string]interface{}{
+		"stream_id": streamID,
+		"name":      stream.Name,
+	})
+
+	return nil
+}
+
+// PauseDataStream stops routing events to a data stream and its subscribers. Events already
+// buffered are still processed.
+func (e *RealTimeAnalyticsEngine) PauseDataStream(streamID string) error {
+	return e.setStreamStatus(streamID, StreamStatusPaused)
+}
+
+// ResumeDataStream resumes routing events to a paused data stream. Events published while it
+// was paused are not replayed.
+func (e *RealTimeAnalyticsEngine) ResumeDataStream(streamID string) error {
+	return e.setStreamStatus(streamID, StreamStatusActive)
+}
+
+// SubscribeToStream subscribes to the events routed to a data stream, following the stream's
+// event type filters as they change
+func (e *RealTimeAnalyticsEngine) SubscribeToStream(streamID string, options SubscribeOptions) (*Subscription, error) {
+	sub := e.newSubscriber("", options)
+	sub.streamID = streamID
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stream, exists := e.dataStreams[streamID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrStreamNotFound, streamID)
+	}
+	stream.subscribers = append(stream.subscribers, sub)
+
+	return &Subscription{ID: sub.id, StreamID: streamID, Events: sub.queue}, nil
+}
+
+func (e *RealTimeAnalyticsEngine) setStreamStatus(streamID string, status StreamStatus) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stream, exists := e.dataStreams[streamID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrStreamNotFound, streamID)
+	}
+
+	stream.mu.Lock()
+	previous := stream.Status
+	stream.Status = status
+	stream.mu.Unlock()
+
+	if previous != status {
+		e.logger.Info(context.Background(), "Data stream status changed", map[string]interface{}{
+			"stream_id": streamID,
+			"name":      stream.Name,
+			"status":    status,
+		})
+	}
+	return nil
+}
+
+// streamRetention validates a stream's retention period, defaulting it to the metrics
+// retention period. Streams may keep metrics for less time than the default, not more.
+func (e *RealTimeAnalyticsEngine) streamRetention(retention time.Duration) (time.Duration, error) {
+	if retention < 0 {
+		return 0, fmt.Errorf("%w: retention period must not be negative", ErrInvalidStream)
+	}
+	if retention == 0 {
+		return e.config.MetricsRetentionPeriod, nil
+	}
+	if e.config.MetricsRetentionPeriod > 0 && retention > e.config.MetricsRetentionPeriod {
+		return 0, fmt.Errorf("%w: retention period must not exceed the metrics retention period of %s", ErrInvalidStream, e.config.MetricsRetentionPeriod)
+	}
+	return retention, nil
+}
+
+// metricRetention returns how long to keep the metrics of an event: the longest retention
+// period of the streams it was routed to, or the metrics retention period when it was routed
+// to none. It must be called with the engine's lock held.
+func (e *RealTimeAnalyticsEngine) metricRetention(routed []*DataStream) time.Duration {
+	if len(routed) == 0 {
+		return e.config.MetricsRetentionPeriod
+	}
+	var retention time.Duration
+	for _, stream := range routed {
+		stream.mu.RLock()
+		streamRetention := stream.Config.RetentionPeriod
+		stream.mu.RUnlock()
+		if streamRetention <= 0 {
+			return e.config.MetricsRetentionPeriod
+		}
+		if streamRetention > retention {
+			retention = streamRetention
+		}
+	}
+	return retention
+}
+
+// newSubscriber creates a subscriber with the engine's defaults for unset options
+func (e *RealTimeAnalyticsEngine) newSubscriber(eventType EventType, options SubscribeOptions) *subscriber {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultSubscriberBuffer
+	}
+	if options.OverflowPolicy == "" {
+		options.OverflowPolicy = e.config.OverflowPolicy
+	}
+	if options.BlockTimeout <= 0 {
+		options.BlockTimeout = e.config.BlockTimeout
+	}
+
+	return &subscriber{
+		id:           uuid.New().String(),
+		name:         options.Name,
+		eventType:    eventType,
+		queue:        make(chan *AnalyticsEvent, options.BufferSize),
+		policy:       options.OverflowPolicy,
+		blockTimeout: options.BlockTimeout,
+	}
+}
+
+// updateThroughput sets the stream's events per second since the last update
+func (s *DataStream) updateThroughput(now time.Time) {
+	published := s.published.Load()
+	if !s.throughputAt.IsZero() {
+		if elapsed := now.Sub(s.throughputAt).Seconds(); elapsed > 0 {
+			s.Metrics.EventsPerSecond = float64(published-s.throughputCount) / elapsed
+		}
+	}
+	s.throughputCount = published
+	s.throughputAt = now
+}
+
+// info describes the stream. It must be called with the engine's lock held.
+func (s *DataStream) info(now time.Time) *DataStreamInfo {
+	s.mu.RLock()
+	info := &DataStreamInfo{
+		StreamID:        s.StreamID,
+		Name:            s.Name,
+		Source:          s.Source,
+		EventTypes:      append([]EventType(nil), s.EventTypes...),
+		Status:          s.Status,
+		RetentionPeriod: s.Config.RetentionPeriod,
+		Subscribers:     make([]SubscriberStats, 0, len(s.subscribers)),
+		CreatedAt:       s.CreatedAt,
+		LastActivity:    s.LastActivity,
+	}
+	s.mu.RUnlock()
+
+	info.Metrics = s.metricsSnapshot()
+	for _, sub := range s.subscribers {
+		info.Subscribers = append(info.Subscribers, sub.stats(now))
+	}
+	return info
+}
@@ -0,0 +1,158 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateStreamFiltersKeepsSubscribers(t *testing.T) {
+	engine := newTestRealTimeEngine(nil)
+	stream, err := engine.CreateDataStream("Prices", "exchange", []EventType{EventTypeMarketData}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	subscription, err := engine.SubscribeToStream(stream.StreamID, SubscribeOptions{Name: "chart"})
+	if err != nil {
+		t.Fatalf("Failed to subscribe to stream: %v", err)
+	}
+
+	publishPrices(t, engine, 1)
+	if err := engine.PublishEvent(&AnalyticsEvent{EventType: EventTypeTradingActivity, Metrics: map[string]float64{"price": 2}}); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+
+	name := "Trades"
+	info, err := engine.UpdateDataStream(stream.StreamID, DataStreamUpdate{Name: &name, EventTypes: []EventType{EventTypeTradingActivity}})
+	if err != nil {
+		t.Fatalf("Failed to update stream: %v", err)
+	}
+	if info.Name != "Trades" || len(info.Subscribers) != 1 || info.Subscribers[0].Name != "chart" {
+		t.Fatalf("Expected the renamed stream to keep its subscriber, got %+v", info)
+	}
+
+	publishPrices(t, engine, 3)
+	if err := engine.PublishEvent(&AnalyticsEvent{EventType: EventTypeTradingActivity, Metrics: map[string]float64{"price": 4}}); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+
+	if prices := queuedPrices(subscription.Events); len(prices) != 2 || prices[0] != 1 || prices[1] != 4 {
+		t.Errorf("Expected market data before the update and trades after it, got %v", prices)
+	}
+	info, _ = engine.GetDataStream(stream.StreamID)
+	if subscriber := info.Subscribers[0]; subscriber.StreamID != stream.StreamID || subscriber.Delivered != 2 {
+		t.Errorf("Expected the subscriber's stats on the stream, got %+v", subscriber)
+	}
+}
+
+func TestPausedStreamReceivesNoEvents(t *testing.T) {
+	engine := newTestRealTimeEngine(nil)
+	stream, err := engine.CreateDataStream("Prices", "exchange", []EventType{EventTypeMarketData}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	subscription, _ := engine.SubscribeToStream(stream.StreamID, SubscribeOptions{})
+
+	if err := engine.PauseDataStream(stream.StreamID); err != nil {
+		t.Fatalf("Failed to pause stream: %v", err)
+	}
+	publishPrices(t, engine, 1, 2)
+	if err := engine.ResumeDataStream(stream.StreamID); err != nil {
+		t.Fatalf("Failed to resume stream: %v", err)
+	}
+	publishPrices(t, engine, 3)
+
+	if prices := queuedPrices(subscription.Events); len(prices) != 1 || prices[0] != 3 {
+		t.Errorf("Expected only the event published after resuming, got %v", prices)
+	}
+	if info, _ := engine.GetDataStream(stream.StreamID); info.Status != StreamStatusActive || info.Metrics.EventsPublished != 1 {
+		t.Errorf("Expected an active stream with 1 published event, got %+v", info)
+	}
+}
+
+func TestDeleteStreamClosesSubscriptions(t *testing.T) {
+	engine := newTestRealTimeEngine(nil)
+	stream, err := engine.CreateDataStream("Prices", "exchange", []EventType{EventTypeMarketData}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	subscription, _ := engine.SubscribeToStream(stream.StreamID, SubscribeOptions{})
+
+	if err := engine.DeleteDataStream(stream.StreamID); err != nil {
+		t.Fatalf("Failed to delete stream: %v", err)
+	}
+	if _, ok := <-subscription.Events; ok {
+		t.Error("Expected the stream's events channel to be closed")
+	}
+	if streams := engine.GetDataStreams(); len(streams) != 0 {
+		t.Errorf("Expected no streams, got %d", len(streams))
+	}
+	if err := engine.PauseDataStream(stream.StreamID); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("Expected ErrStreamNotFound, got %v", err)
+	}
+	if _, err := engine.SubscribeToStream(stream.StreamID, SubscribeOptions{}); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("Expected ErrStreamNotFound, got %v", err)
+	}
+}
+
+func TestStreamRetentionLimitsMetricHistory(t *testing.T) {
+	engine := newTestRealTimeEngine(nil)
+	if _, err := engine.CreateDataStream("Ticks", "exchange", []EventType{EventTypeMarketData}, &StreamConfig{BufferSize: 10, RetentionPeriod: time.Hour}); err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	if _, err := engine.CreateDataStream("Long", "exchange", nil, &StreamConfig{BufferSize: 10, RetentionPeriod: 48 * time.Hour}); !errors.Is(err, ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for retention beyond the default, got %v", err)
+	}
+
+	now := time.Now()
+	for _, event := range []*AnalyticsEvent{
+		{EventType: EventTypeMarketData, Timestamp: now.Add(-2 * time.Hour), Metrics: map[string]float64{"tick": 1}},
+		{EventType: EventTypeMarketData, Timestamp: now, Metrics: map[string]float64{"tick": 2}},
+		{EventType: EventTypeSystemMetric, Timestamp: now.Add(-2 * time.Hour), Metrics: map[string]float64{"cpu": 1}},
+		{EventType: EventTypeSystemMetric, Timestamp: now, Metrics: map[string]float64{"cpu": 2}},
+	} {
+		if err := engine.PublishEvent(event); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+
+	dm := engine.GetDashboardManager()
+	dm.mu.RLock()
+	ticks, cpu := len(dm.metricSeries["tick"]), len(dm.metricSeries["cpu"])
+	dm.mu.RUnlock()
+	if ticks != 1 {
+		t.Errorf("Expected the stream's 1h retention to drop the old tick, got %d samples", ticks)
+	}
+	if cpu != 2 {
+		t.Errorf("Expected unrouted metrics kept for the default retention, got %d samples", cpu)
+	}
+}
+
+func TestUpdateStreamValidation(t *testing.T) {
+	engine := newTestRealTimeEngine(nil)
+	stream, err := engine.CreateDataStream("Prices", "exchange", []EventType{EventTypeMarketData}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	empty := ""
+	negative := -time.Minute
+	for _, update := range []DataStreamUpdate{
+		{Name: &empty},
+		{EventTypes: []EventType{}},
+		{RetentionPeriod: &negative},
+	} {
+		if _, err := engine.UpdateDataStream(stream.StreamID, update); !errors.Is(err, ErrInvalidStream) {
+			t.Errorf("Expected ErrInvalidStream for %+v, got %v", update, err)
+		}
+	}
+
+	reset := time.Duration(0)
+	info, err := engine.UpdateDataStream(stream.StreamID, DataStreamUpdate{RetentionPeriod: &reset})
+	if err != nil || info.RetentionPeriod != 24*time.Hour {
+		t.Errorf("Expected a zero retention to reset to the default, got %+v, %v", info, err)
+	}
+	if _, err := engine.UpdateDataStream("missing", DataStreamUpdate{}); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("Expected ErrStreamNotFound, got %v", err)
+	}
+}
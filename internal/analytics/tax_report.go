@@ -0,0 +1,224 @@
+package analytics
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidTaxYear is returned for tax years before 2009 or in the future
+var ErrInvalidTaxYear = errors.New("invalid tax year")
+
+// taxReportHeader lists the columns of exported realized gain rows
+var taxReportHeader = []string{"symbol", "lot_id", "acquired_at", "disposed_at", "quantity", "proceeds", "cost_basis", "gain", "holding_period", "cost_basis_missing"}
+
+// TaxReport is the realized gains of a portfolio's sales in a calendar year (UTC) matched
+// against tax lots, with its open lots and their unrealized gains at the report time
+type TaxReport struct {
+	PortfolioID uuid.UUID          `json:"portfolio_id"`
+	Year        int                `json:"year"`
+	Method      web3.LotMethod     `json:"method"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Summary     TaxReportSummary   `json:"summary"`
+	Disposals   []web3.LotDisposal `json:"disposals"`
+	OpenLots    []OpenTaxLot       `json:"open_lots"`
+}
+
+// OpenTaxLot is a lot still held. UnrealizedGain is nil when the lot's cost basis is missing
+// or no current price is known.
+type OpenTaxLot struct {
+	*web3.TaxLot
+	CurrentPrice   *decimal.Decimal `json:"current_price,omitempty"`
+	UnrealizedGain *decimal.Decimal `json:"unrealized_gain,omitempty"`
+}
+
+// TaxReportSummary totals a tax report. Gains exclude disposals with a missing cost basis,
+// whose proceeds are totalled separately so they are reviewed rather than taxed as all gain.
+type TaxReportSummary struct {
+	Disposals                int             `json:"disposals"`
+	Proceeds                 decimal.Decimal `json:"proceeds"`
+	CostBasis                decimal.Decimal `json:"cost_basis"`
+	RealizedGain             decimal.Decimal `json:"realized_gain"`
+	ShortTermGain            decimal.Decimal `json:"short_term_gain"`
+	LongTermGain             decimal.Decimal `json:"long_term_gain"`
+	MissingCostBasis         int             `json:"missing_cost_basis"`
+	MissingCostBasisProceeds decimal.Decimal `json:"missing_cost_basis_proceeds"`
+	UnrealizedGain           decimal.Decimal `json:"unrealized_gain"`
+	TransfersExcluded        int             `json:"transfers_excluded"` // between the owner's wallets
+}
+
+// GetTaxReport matches the portfolio's whole trade history against tax lots with method and
+// reports the sales of year. Earlier years' sales consume lots but are not reported.
+func (p *PortfolioAnalytics) GetTaxReport(ctx context.Context, portfolioID uuid.UUID, year int, method web3.LotMethod) (*TaxReport, error) {
+	now := time.Now().UTC()
+	if year < 2009 || year > now.Year() {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidTaxYear, year)
+	}
+
+	portfolio, err := p.tradingEngine.GetPortfolio(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	trades, err := p.tradingEngine.GetTradeHistory(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade history: %w", err)
+	}
+	ledger := web3.MatchTaxLots(trades, method)
+
+	report := &TaxReport{
+		PortfolioID: portfolioID,
+		Year:        year,
+		Method:      method,
+		GeneratedAt: now,
+		Disposals:   make([]web3.LotDisposal, 0),
+		OpenLots:    make([]OpenTaxLot, 0, len(ledger.Lots)),
+	}
+	summary := &report.Summary
+	summary.TransfersExcluded = ledger.Transfers
+
+	for _, disposal := range ledger.Disposals {
+		if disposal.DisposedAt.UTC().Year() != year {
+			continue
+		}
+		report.Disposals = append(report.Disposals, disposal)
+		summary.Disposals++
+		if disposal.CostBasisMissing {
+			summary.MissingCostBasis++
+			summary.MissingCostBasisProceeds = summary.MissingCostBasisProceeds.Add(disposal.Proceeds)
+			continue
+		}
+		summary.Proceeds = summary.Proceeds.Add(disposal.Proceeds)
+		summary.CostBasis = summary.CostBasis.Add(disposal.CostBasis)
+		summary.RealizedGain = summary.RealizedGain.Add(disposal.Gain)
+		if disposal.HoldingPeriod == web3.HoldingPeriodLongTerm {
+			summary.LongTermGain = summary.LongTermGain.Add(disposal.Gain)
+		} else {
+			summary.ShortTermGain = summary.ShortTermGain.Add(disposal.Gain)
+		}
+	}
+
+	prices := p.currentPrices(portfolio)
+	for _, lot := range ledger.Lots {
+		open := OpenTaxLot{TaxLot: lot}
+		if price, ok := prices[lot.Symbol]; ok {
+			open.CurrentPrice = &price
+			if !lot.CostBasisMissing {
+				gain := price.Sub(lot.CostBasis).Mul(lot.Remaining)
+				open.UnrealizedGain = &gain
+				summary.UnrealizedGain = summary.UnrealizedGain.Add(gain)
+			}
+		}
+		report.OpenLots = append(report.OpenLots, open)
+	}
+
+	return report, nil
+}
+
+// currentPrices returns the latest known price of each symbol held in the portfolio, from its
+// holdings and otherwise its active positions
+func (p *PortfolioAnalytics) currentPrices(portfolio *web3.Portfolio) map[string]decimal.Decimal {
+	prices := make(map[string]decimal.Decimal)
+	if positions, err := p.tradingEngine.GetActivePositions(portfolio.ID); err == nil {
+		for _, position := range positions {
+			if position.CurrentPrice.IsPositive() {
+				prices[strings.ToUpper(position.TokenSymbol)] = position.CurrentPrice
+			}
+		}
+	}
+	for symbol, holding := range portfolio.Holdings {
+		if holding.CurrentPrice.IsPositive() {
+			prices[strings.ToUpper(symbol)] = holding.CurrentPrice
+		}
+	}
+	return prices
+}
+
+// FileName returns the suggested download name of the report
+func (r *TaxReport) FileName() string {
+	return fmt.Sprintf("tax-report-%s-%d-%s.csv", r.PortfolioID, r.Year, r.Method)
+}
+
+// WriteCSV writes a realized gain row per lot disposal, followed by a blank line and the
+// summary as metric,value rows
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(taxReportHeader); err != nil {
+		return err
+	}
+	for _, disposal := range r.Disposals {
+		if err := writer.Write(taxDisposalRow(disposal)); err != nil {
+			return err
+		}
+	}
+
+	// A blank line separates the summary section
+	writer.Flush()
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	summary := r.Summary
+	rows := [][]string{
+		{"metric", "value"},
+		{"portfolio_id", r.PortfolioID.String()},
+		{"year", strconv.Itoa(r.Year)},
+		{"method", string(r.Method)},
+		{"generated_at", formatExportTime(r.GeneratedAt)},
+		{"disposals", strconv.Itoa(summary.Disposals)},
+		{"proceeds", summary.Proceeds.String()},
+		{"cost_basis", summary.CostBasis.String()},
+		{"realized_gain", summary.RealizedGain.String()},
+		{"short_term_gain", summary.ShortTermGain.String()},
+		{"long_term_gain", summary.LongTermGain.String()},
+		{"missing_cost_basis", strconv.Itoa(summary.MissingCostBasis)},
+		{"missing_cost_basis_proceeds", summary.MissingCostBasisProceeds.String()},
+		{"unrealized_gain", summary.UnrealizedGain.String()},
+		{"transfers_excluded", strconv.Itoa(summary.TransfersExcluded)},
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// taxDisposalRow formats a disposal in the column order of taxReportHeader. The cost basis and
+// gain of disposals with a missing cost basis are left empty.
+func taxDisposalRow(disposal web3.LotDisposal) []string {
+	lotID, acquiredAt := "", ""
+	if disposal.LotID != nil {
+		lotID = disposal.LotID.String()
+	}
+	if disposal.AcquiredAt != nil {
+		acquiredAt = formatExportTime(*disposal.AcquiredAt)
+	}
+	costBasis, gain := "", ""
+	if !disposal.CostBasisMissing {
+		costBasis, gain = disposal.CostBasis.String(), disposal.Gain.String()
+	}
+
+	return []string{
+		disposal.Symbol,
+		lotID,
+		acquiredAt,
+		formatExportTime(disposal.DisposedAt),
+		disposal.Quantity.String(),
+		disposal.Proceeds.String(),
+		costBasis,
+		gain,
+		disposal.HoldingPeriod,
+		strconv.FormatBool(disposal.CostBasisMissing),
+	}
+}
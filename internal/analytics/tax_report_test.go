@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestTaxReport(t *testing.T) {
+	portfolioAnalytics, portfolio, _ := newTestPortfolioAnalytics(t)
+	engine := portfolioAnalytics.tradingEngine
+	ctx := context.Background()
+
+	basis := decimal.NewFromInt(2000)
+	receipts := []web3.AssetTransfer{
+		{Symbol: "ETH", Quantity: decimal.NewFromInt(2), ToAddress: "0xa", CostBasis: &basis},
+		{Symbol: "ARB", Quantity: decimal.NewFromInt(50), ToAddress: "0xa"},
+		{Symbol: "ETH", Quantity: decimal.NewFromInt(2), FromAddress: "0xb", ToAddress: "0xa", Internal: true},
+	}
+	for _, receipt := range receipts {
+		if _, err := engine.RecordTransfer(ctx, portfolio.ID, receipt); err != nil {
+			t.Fatalf("Failed to record transfer: %v", err)
+		}
+	}
+	portfolio.Holdings["ETH"] = &web3.Holding{TokenSymbol: "ETH", CurrentPrice: decimal.NewFromInt(2500)}
+	portfolio.Holdings["ARB"] = &web3.Holding{TokenSymbol: "ARB", CurrentPrice: decimal.NewFromInt(1)}
+
+	report, err := portfolioAnalytics.GetTaxReport(ctx, portfolio.ID, time.Now().UTC().Year(), web3.LotMethodFIFO)
+	if err != nil {
+		t.Fatalf("Failed to build tax report: %v", err)
+	}
+
+	if len(report.Disposals) != 0 || report.Summary.TransfersExcluded != 1 {
+		t.Errorf("Expected no disposals and one excluded transfer, got %d and %d", len(report.Disposals), report.Summary.TransfersExcluded)
+	}
+	if len(report.OpenLots) != 2 {
+		t.Fatalf("Expected an ETH and an ARB lot, got %d", len(report.OpenLots))
+	}
+	for _, lot := range report.OpenLots {
+		switch lot.Symbol {
+		case "ETH":
+			if lot.UnrealizedGain == nil || !lot.UnrealizedGain.Equal(decimal.NewFromInt(1000)) {
+				t.Errorf("Expected ETH unrealized gain 1000, got %v", lot.UnrealizedGain)
+			}
+		case "ARB":
+			if !lot.CostBasisMissing || lot.UnrealizedGain != nil {
+				t.Errorf("Expected airdropped ARB flagged without an unrealized gain, got %+v", lot)
+			}
+		}
+	}
+	if !report.Summary.UnrealizedGain.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("Expected total unrealized gain 1000, got %s", report.Summary.UnrealizedGain)
+	}
+
+	if _, err := portfolioAnalytics.GetTaxReport(ctx, portfolio.ID, time.Now().Year()+1, web3.LotMethodFIFO); !errors.Is(err, ErrInvalidTaxYear) {
+		t.Errorf("Expected invalid tax year error, got %v", err)
+	}
+	if _, err := portfolioAnalytics.GetTaxReport(ctx, uuid.New(), 2024, web3.LotMethodFIFO); err == nil {
+		t.Error("Expected error for unknown portfolio")
+	}
+}
+
+func TestTaxReportCSV(t *testing.T) {
+	lotID := uuid.New()
+	acquiredAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	report := &TaxReport{
+		PortfolioID: uuid.New(),
+		Year:        2024,
+		Method:      web3.LotMethodHIFO,
+		GeneratedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		Disposals: []web3.LotDisposal{
+			{
+				LotID:         &lotID,
+				Symbol:        "ETH",
+				AcquiredAt:    &acquiredAt,
+				DisposedAt:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+				Quantity:      decimal.NewFromInt(1),
+				Proceeds:      decimal.NewFromInt(3500),
+				CostBasis:     decimal.NewFromInt(1800),
+				Gain:          decimal.NewFromInt(1700),
+				HoldingPeriod: web3.HoldingPeriodLongTerm,
+			},
+			{
+				Symbol:           "ARB",
+				DisposedAt:       time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+				Quantity:         decimal.NewFromInt(50),
+				Proceeds:         decimal.NewFromInt(60),
+				CostBasisMissing: true,
+			},
+		},
+		Summary: TaxReportSummary{Disposals: 2, RealizedGain: decimal.NewFromInt(1700), MissingCostBasis: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	rows, summary, found := strings.Cut(buf.String(), "\n\n")
+	if !found {
+		t.Fatalf("Expected a blank line before the summary, got %q", buf.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(rows), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and two disposal rows, got %d lines", len(lines))
+	}
+	expected := "ETH," + lotID.String() + ",2023-05-01T12:00:00Z,2024-06-01T12:00:00Z,1,3500,1800,1700,long_term,false"
+	if lines[1] != expected {
+		t.Errorf("Unexpected disposal row %q", lines[1])
+	}
+	if lines[2] != "ARB,,,2024-07-01T00:00:00Z,50,60,,,,true" {
+		t.Errorf("Expected missing cost basis row with empty basis and gain, got %q", lines[2])
+	}
+	if !strings.Contains(summary, "realized_gain,1700\n") || !strings.Contains(summary, "missing_cost_basis,1\n") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+	if report.FileName() != "tax-report-"+report.PortfolioID.String()+"-2024-HIFO.csv" {
+		t.Errorf("Unexpected file name %s", report.FileName())
+	}
+}
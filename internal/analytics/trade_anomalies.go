@@ -0,0 +1,338 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Trade metrics watched for anomalies, each as one stream per portfolio
+const (
+	TradeMetricOrderSize = "order_size"   // notional of each fill
+	TradeMetricSlippage  = "slippage_bps" // adverse fill slippage in basis points
+	TradeMetricDrawdown  = "drawdown"     // share of portfolio value lost since its peak
+)
+
+const (
+	// tradeMetricTag marks data points fed by the trade anomaly monitor
+	tradeMetricTag = "trade_metric"
+
+	maxTradeAnomalies     = 1000
+	defaultTradeAnomalies = 50
+
+	// A drawdown stays anomalous while it lasts; it is reported again only after this long
+	// or when its severity rises
+	drawdownReportInterval = 15 * time.Minute
+)
+
+// TradeAnomalyConfig tunes the default trade metric detectors
+type TradeAnomalyConfig struct {
+	Sensitivity    float64 // 0-1; higher flags smaller deviations
+	MaxSlippageBps float64 // slippage above this is always flagged; zero disables the limit
+	MaxDrawdown    float64 // drawdown above this is always flagged; zero disables the limit
+}
+
+// TradeMetricDetector configures the detector created for each portfolio's stream of a metric
+type TradeMetricDetector struct {
+	Metric     string                 `json:"metric"`
+	Method     AnomalyDetectionMethod `json:"method"`
+	WindowSize int                    `json:"window_size"`
+	Limit      float64                `json:"limit,omitempty"`
+}
+
+// DefaultTradeMetricDetectors returns the detectors for order size, slippage and drawdown.
+// Order sizes are compared with the portfolio's recent orders, slippage with its usual range
+// and drawdown with its recent level, and hard limits catch slippage and drawdown that build
+// up without ever looking unusual.
+func DefaultTradeMetricDetectors(cfg TradeAnomalyConfig) []TradeMetricDetector {
+	return []TradeMetricDetector{
+		{Metric: TradeMetricOrderSize, Method: DetectionMethodZScore, WindowSize: 50},
+		{Metric: TradeMetricSlippage, Method: DetectionMethodIQR, WindowSize: 50, Limit: cfg.MaxSlippageBps},
+		{Metric: TradeMetricDrawdown, Method: DetectionMethodMovingAverage, WindowSize: 100, Limit: cfg.MaxDrawdown},
+	}
+}
+
+// TradeAnomaly is an anomaly in a portfolio's trading, with the order that triggered it
+type TradeAnomaly struct {
+	ID              string                 `json:"id"`
+	Metric          string                 `json:"metric"`
+	PortfolioID     string                 `json:"portfolio_id"`
+	UserID          *uuid.UUID             `json:"user_id,omitempty"`
+	OrderID         string                 `json:"order_id,omitempty"`
+	PositionID      string                 `json:"position_id,omitempty"`
+	Symbol          string                 `json:"symbol,omitempty"`
+	Side            string                 `json:"side,omitempty"`
+	Value           float64                `json:"value"`
+	ExpectedValue   float64                `json:"expected_value"`
+	Deviation       float64                `json:"deviation"`
+	Severity        AnomalySeverity        `json:"severity"`
+	Confidence      float64                `json:"confidence"`
+	DetectionMethod AnomalyDetectionMethod `json:"detection_method"`
+	Description     string                 `json:"description"`
+	Context         map[string]interface{} `json:"context"`
+	AlertID         string                 `json:"alert_id,omitempty"`
+	DetectedAt      time.Time              `json:"detected_at"`
+}
+
+// TradeAnomalyQuery selects recent trade anomalies; no portfolio IDs selects none
+type TradeAnomalyQuery struct {
+	PortfolioIDs []string
+	Limit        int
+}
+
+// TradeAnomalyMonitor feeds fills and valuations from the trading and execution engines into
+// an AnomalyDetector as per-portfolio metric streams, and raises an alert for every anomaly
+// found. It implements web3.TradeEventListener and trading.ExecutionObserver.
+type TradeAnomalyMonitor struct {
+	logger    *observability.Logger
+	detector  *AnomalyDetector
+	alerts    *alerts.AlertService
+	config    TradeAnomalyConfig
+	detectors map[string]TradeMetricDetector
+
+	mu            sync.Mutex
+	registered    map[string]bool
+	anomalies     []*TradeAnomaly
+	lastDrawdowns map[string]*TradeAnomaly
+}
+
+// NewTradeAnomalyMonitor creates a monitor that reports anomalies to alertService, which may
+// be nil to only record them
+func NewTradeAnomalyMonitor(logger *observability.Logger, detector *AnomalyDetector, alertService *alerts.AlertService, cfg TradeAnomalyConfig) *TradeAnomalyMonitor {
+	m := &TradeAnomalyMonitor{
+		logger:        logger,
+		detector:      detector,
+		alerts:        alertService,
+		config:        cfg,
+		detectors:     make(map[string]TradeMetricDetector),
+		registered:    make(map[string]bool),
+		lastDrawdowns: make(map[string]*TradeAnomaly),
+	}
+	for _, spec := range DefaultTradeMetricDetectors(cfg) {
+		m.detectors[spec.Metric] = spec
+	}
+	detector.OnAnomaly(m.handleAnomaly)
+	return m
+}
+
+// OnTradeEvent feeds a fill's size and slippage, or a valuation's drawdown, to the detector
+func (m *TradeAnomalyMonitor) OnTradeEvent(ctx context.Context, event web3.TradeEvent) {
+	portfolioID := event.PortfolioID.String()
+	tags := map[string]string{
+		"portfolio_id": portfolioID,
+		"user_id":      event.UserID.String(),
+	}
+
+	switch event.Type {
+	case web3.TradeEventFill:
+		tags["order_id"] = event.OrderID
+		tags["position_id"] = event.PositionID.String()
+		tags["symbol"] = event.Symbol
+		tags["side"] = event.Side
+		m.observe(TradeMetricOrderSize, portfolioID, event.Notional, tags)
+		if event.SlippageBps != nil {
+			m.observe(TradeMetricSlippage, portfolioID, *event.SlippageBps, tags)
+		}
+	case web3.TradeEventValuation:
+		m.observe(TradeMetricDrawdown, portfolioID, event.Drawdown, tags)
+	}
+}
+
+// OnExecutionReport feeds the size and slippage of a finished algorithmic order. Orders
+// without a portfolio are not monitored.
+func (m *TradeAnomalyMonitor) OnExecutionReport(ctx context.Context, order *trading.ExecutionOrder, report trading.ExecutionReport) {
+	if order.PortfolioID == "" || !report.FilledQuantity.IsPositive() {
+		return
+	}
+
+	tags := map[string]string{
+		"portfolio_id": order.PortfolioID,
+		"order_id":     order.ID,
+		"symbol":       order.Symbol,
+		"side":         string(order.Side),
+		"algorithm":    string(order.AlgorithmType),
+	}
+	if order.StrategyID != "" {
+		tags["strategy_id"] = order.StrategyID
+	}
+	m.observe(TradeMetricOrderSize, order.PortfolioID, report.FilledQuantity.Mul(report.AveragePrice), tags)
+	m.observe(TradeMetricSlippage, order.PortfolioID, report.SlippageBps, tags)
+}
+
+// ListAnomalies returns the most recent anomalies of the given portfolios, newest first
+func (m *TradeAnomalyMonitor) ListAnomalies(query TradeAnomalyQuery) []*TradeAnomaly {
+	limit := query.Limit
+	if limit <= 0 || limit > maxTradeAnomalies {
+		limit = defaultTradeAnomalies
+	}
+	portfolios := make(map[string]bool, len(query.PortfolioIDs))
+	for _, id := range query.PortfolioIDs {
+		portfolios[id] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*TradeAnomaly, 0)
+	for i := len(m.anomalies) - 1; i >= 0 && len(result) < limit; i-- {
+		if portfolios[m.anomalies[i].PortfolioID] {
+			result = append(result, m.anomalies[i])
+		}
+	}
+	return result
+}
+
+// observe adds a value to a portfolio's stream of metric, creating its detector on first use
+func (m *TradeAnomalyMonitor) observe(metric, portfolioID string, value decimal.Decimal, tags map[string]string) {
+	name := fmt.Sprintf("trade.%s.%s", metric, portfolioID)
+
+	m.mu.Lock()
+	if !m.registered[name] {
+		spec := m.detectors[metric]
+		m.detector.RegisterMetricDetector(name, spec.Method, m.config.Sensitivity, spec.WindowSize)
+		if spec.Limit > 0 {
+			m.detector.SetMetricLimit(name, spec.Limit)
+		}
+		m.registered[name] = true
+	}
+	m.mu.Unlock()
+
+	pointTags := make(map[string]string, len(tags)+1)
+	for key, tag := range tags {
+		pointTags[key] = tag
+	}
+	pointTags[tradeMetricTag] = metric
+	m.detector.AddDataPoint(name, value.InexactFloat64(), pointTags)
+}
+
+// handleAnomaly records an anomaly of a trade metric stream and raises its alert
+func (m *TradeAnomalyMonitor) handleAnomaly(anomaly *Anomaly) {
+	metric := anomaly.Tags[tradeMetricTag]
+	if metric == "" {
+		return
+	}
+
+	tradeAnomaly := &TradeAnomaly{
+		ID:              anomaly.AnomalyID,
+		Metric:          metric,
+		PortfolioID:     anomaly.Tags["portfolio_id"],
+		OrderID:         anomaly.Tags["order_id"],
+		PositionID:      anomaly.Tags["position_id"],
+		Symbol:          anomaly.Tags["symbol"],
+		Side:            anomaly.Tags["side"],
+		Value:           anomaly.Value,
+		ExpectedValue:   anomaly.ExpectedValue,
+		Deviation:       anomaly.Deviation,
+		Severity:        anomaly.Severity,
+		Confidence:      anomaly.Confidence,
+		DetectionMethod: anomaly.DetectionMethod,
+		Description:     anomaly.Description,
+		Context:         anomaly.Context,
+		DetectedAt:      anomaly.Timestamp,
+	}
+	if userID, err := uuid.Parse(anomaly.Tags["user_id"]); err == nil && userID != uuid.Nil {
+		tradeAnomaly.UserID = &userID
+	}
+
+	m.mu.Lock()
+	if metric == TradeMetricDrawdown {
+		last := m.lastDrawdowns[tradeAnomaly.PortfolioID]
+		if last != nil && tradeAnomaly.DetectedAt.Sub(last.DetectedAt) < drawdownReportInterval &&
+			severityRank[tradeAnomaly.Severity] <= severityRank[last.Severity] {
+			m.mu.Unlock()
+			return
+		}
+		m.lastDrawdowns[tradeAnomaly.PortfolioID] = tradeAnomaly
+	}
+	m.mu.Unlock()
+
+	tradeAnomaly.AlertID = m.sendAlert(tradeAnomaly)
+
+	m.mu.Lock()
+	m.anomalies = append(m.anomalies, tradeAnomaly)
+	if len(m.anomalies) > maxTradeAnomalies {
+		m.anomalies = m.anomalies[len(m.anomalies)-maxTradeAnomalies:]
+	}
+	m.mu.Unlock()
+
+	m.logger.Warn(context.Background(), "Trade anomaly detected", map[string]interface{}{
+		"anomaly_id":   tradeAnomaly.ID,
+		"metric":       metric,
+		"portfolio_id": tradeAnomaly.PortfolioID,
+		"order_id":     tradeAnomaly.OrderID,
+		"value":        tradeAnomaly.Value,
+		"severity":     string(tradeAnomaly.Severity),
+	})
+}
+
+// tradeAnomalyTitles names each trade metric's anomaly in alerts
+var tradeAnomalyTitles = map[string]string{
+	TradeMetricOrderSize: "Unusually large order",
+	TradeMetricSlippage:  "Abnormal fill slippage",
+	TradeMetricDrawdown:  "Sudden portfolio drawdown",
+}
+
+var severityRank = map[AnomalySeverity]int{
+	AnomalySeverityLow:      1,
+	AnomalySeverityMedium:   2,
+	AnomalySeverityHigh:     3,
+	AnomalySeverityCritical: 4,
+}
+
+// sendAlert raises an alert for a trade anomaly and returns its ID. Alerts of one metric of a
+// portfolio share a rule, so repeats are throttled by the alert service's cooldown.
+func (m *TradeAnomalyMonitor) sendAlert(anomaly *TradeAnomaly) string {
+	if m.alerts == nil {
+		return ""
+	}
+
+	title := tradeAnomalyTitles[anomaly.Metric]
+	message := fmt.Sprintf("%s in portfolio %s: %s", title, anomaly.PortfolioID, anomaly.Description)
+	if anomaly.OrderID != "" {
+		message = fmt.Sprintf("%s (order %s)", message, anomaly.OrderID)
+	}
+
+	alert := m.alerts.CreateAlert(fmt.Sprintf("trade_anomaly_%s_%s", anomaly.Metric, anomaly.PortfolioID), title, message,
+		alertSeverity(anomaly.Severity), "trade_"+anomaly.Metric,
+		decimal.NewFromFloat(anomaly.Value), decimal.NewFromFloat(anomaly.ExpectedValue), nil)
+	alert.UserID = anomaly.UserID
+	alert.Category = alerts.CategoryRiskAlerts
+	if portfolioID, err := uuid.Parse(anomaly.PortfolioID); err == nil {
+		alert.PortfolioID = &portfolioID
+	}
+	alert.Metadata["anomaly_id"] = anomaly.ID
+	alert.Metadata["portfolio_id"] = anomaly.PortfolioID
+	if anomaly.OrderID != "" {
+		alert.Metadata["order_id"] = anomaly.OrderID
+	}
+	if anomaly.PositionID != "" {
+		alert.Metadata["position_id"] = anomaly.PositionID
+	}
+	if anomaly.Symbol != "" {
+		alert.Metadata["symbol"] = anomaly.Symbol
+	}
+	m.alerts.SendAlert(alert)
+	return alert.ID
+}
+
+// alertSeverity maps an anomaly severity to an alert severity
+func alertSeverity(severity AnomalySeverity) alerts.AlertSeverity {
+	switch severity {
+	case AnomalySeverityCritical:
+		return alerts.SeverityCritical
+	case AnomalySeverityHigh:
+		return alerts.SeverityError
+	case AnomalySeverityMedium:
+		return alerts.SeverityWarning
+	default:
+		return alerts.SeverityInfo
+	}
+}
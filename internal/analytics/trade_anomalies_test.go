@@ -0,0 +1,150 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func newTestTradeAnomalyMonitor() (*TradeAnomalyMonitor, *alerts.AlertService) {
+	logger := observability.NewLogger(config.ObservabilityConfig{
+		ServiceName: "test",
+		LogLevel:    "error",
+	})
+	alertService := alerts.NewAlertService(logger, alerts.NewAlertConfig(config.AlertsConfig{}))
+	detector := NewAnomalyDetector(logger, &AnalyticsConfig{AnomalyDetectionSensitivity: 0.8})
+	monitor := NewTradeAnomalyMonitor(logger, detector, alertService, TradeAnomalyConfig{
+		Sensitivity:    0.8,
+		MaxSlippageBps: 100,
+		MaxDrawdown:    0.1,
+	})
+	return monitor, alertService
+}
+
+func TestTradeAnomalyMonitor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("LargeOrder", func(t *testing.T) {
+		monitor, alertService := newTestTradeAnomalyMonitor()
+		userID, portfolioID := uuid.New(), uuid.New()
+		fill := func(orderID string, notional int64) {
+			monitor.OnTradeEvent(ctx, web3.TradeEvent{
+				Type:        web3.TradeEventFill,
+				PortfolioID: portfolioID,
+				UserID:      userID,
+				OrderID:     orderID,
+				Symbol:      "ETH",
+				Side:        web3.TradeSideBuy,
+				Notional:    decimal.NewFromInt(notional),
+			})
+		}
+
+		for i := 0; i < 20; i++ {
+			fill(uuid.NewString(), 95+int64(i%10))
+		}
+		if anomalies := monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{portfolioID.String()}}); len(anomalies) != 0 {
+			t.Fatalf("Expected no anomalies for regular orders, got %d", len(anomalies))
+		}
+
+		fill("large-order", 5000)
+		anomalies := monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{portfolioID.String()}})
+		if len(anomalies) != 1 {
+			t.Fatalf("Expected 1 anomaly, got %d", len(anomalies))
+		}
+		anomaly := anomalies[0]
+		if anomaly.Metric != TradeMetricOrderSize || anomaly.OrderID != "large-order" || anomaly.Value != 5000 {
+			t.Errorf("Unexpected anomaly: %+v", anomaly)
+		}
+		if anomaly.UserID == nil || *anomaly.UserID != userID {
+			t.Errorf("Expected anomaly of user %s", userID)
+		}
+
+		alertList := alertService.GetAlerts(10)
+		if len(alertList) != 1 {
+			t.Fatalf("Expected 1 alert, got %d", len(alertList))
+		}
+		alert := alertList[0]
+		if alert.ID != anomaly.AlertID || alert.Metadata["order_id"] != "large-order" {
+			t.Errorf("Alert does not reference the anomaly's order: %+v", alert)
+		}
+		if alert.PortfolioID == nil || *alert.PortfolioID != portfolioID || alert.UserID == nil || *alert.UserID != userID {
+			t.Errorf("Alert does not reference the portfolio and user: %+v", alert)
+		}
+
+		if other := monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{uuid.NewString()}}); len(other) != 0 {
+			t.Errorf("Expected anomalies to be scoped to their portfolio, got %d", len(other))
+		}
+	})
+
+	t.Run("Drawdown", func(t *testing.T) {
+		monitor, _ := newTestTradeAnomalyMonitor()
+		portfolioID := uuid.New()
+		value := func(drawdown float64) {
+			monitor.OnTradeEvent(ctx, web3.TradeEvent{
+				Type:        web3.TradeEventValuation,
+				PortfolioID: portfolioID,
+				UserID:      uuid.New(),
+				Drawdown:    decimal.NewFromFloat(drawdown),
+			})
+		}
+
+		value(0)
+		value(0.02)
+		value(0.12)
+		value(0.13) // same episode and severity: not reported again
+
+		anomalies := monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{portfolioID.String()}})
+		if len(anomalies) != 1 {
+			t.Fatalf("Expected 1 drawdown anomaly, got %d", len(anomalies))
+		}
+		if anomalies[0].Metric != TradeMetricDrawdown || anomalies[0].DetectionMethod != DetectionMethodLimit {
+			t.Errorf("Unexpected anomaly: %+v", anomalies[0])
+		}
+
+		value(0.3) // twice the limit raises the severity
+		if anomalies = monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{portfolioID.String()}}); len(anomalies) != 2 {
+			t.Fatalf("Expected a worsening drawdown to be reported, got %d anomalies", len(anomalies))
+		}
+		if anomalies[0].Severity != AnomalySeverityCritical {
+			t.Errorf("Expected critical severity, got %s", anomalies[0].Severity)
+		}
+	})
+
+	t.Run("ExecutionSlippage", func(t *testing.T) {
+		monitor, _ := newTestTradeAnomalyMonitor()
+		portfolioID := uuid.NewString()
+		order := &trading.ExecutionOrder{ID: "twap-1", Symbol: "ETHUSDT", Side: trading.OrderSideBuy, PortfolioID: portfolioID}
+		monitor.OnExecutionReport(ctx, order, trading.ExecutionReport{
+			OrderID:        order.ID,
+			FilledQuantity: decimal.NewFromInt(2),
+			AveragePrice:   decimal.NewFromInt(2050),
+			SlippageBps:    decimal.NewFromInt(250),
+			CompletedAt:    time.Now(),
+		})
+
+		anomalies := monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{portfolioID}})
+		if len(anomalies) != 1 {
+			t.Fatalf("Expected 1 slippage anomaly, got %d", len(anomalies))
+		}
+		if anomalies[0].Metric != TradeMetricSlippage || anomalies[0].OrderID != "twap-1" || anomalies[0].UserID != nil {
+			t.Errorf("Unexpected anomaly: %+v", anomalies[0])
+		}
+
+		// Orders placed without a portfolio are not monitored
+		monitor.OnExecutionReport(ctx, &trading.ExecutionOrder{ID: "twap-2"}, trading.ExecutionReport{
+			FilledQuantity: decimal.NewFromInt(1),
+			SlippageBps:    decimal.NewFromInt(500),
+		})
+		if anomalies := monitor.ListAnomalies(TradeAnomalyQuery{PortfolioIDs: []string{""}}); len(anomalies) != 0 {
+			t.Errorf("Expected no anomalies without a portfolio, got %d", len(anomalies))
+		}
+	})
+}
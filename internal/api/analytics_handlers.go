@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/analytics"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
 	"github.com/gorilla/mux"
 )
 
@@ -15,6 +20,9 @@ import (
 type AnalyticsHandlers struct {
 	logger            *observability.Logger
 	performanceEngine *analytics.PerformanceEngine
+	realtimeEngine    *analytics.RealTimeAnalyticsEngine
+	alertManager      *analytics.AlertManager
+	dashboardManager  *analytics.DashboardManager
 }
 
 // NewAnalyticsHandlers creates new analytics handlers
@@ -25,6 +33,52 @@ func NewAnalyticsHandlers(logger *observability.Logger, pe *analytics.Performanc
 	}
 }
 
+// SetRealTimeEngine exposes the event flow stats, alert incidents and computed dashboard
+// widgets of the real-time analytics engine
+func (h *AnalyticsHandlers) SetRealTimeEngine(engine *analytics.RealTimeAnalyticsEngine) {
+	h.realtimeEngine = engine
+	h.alertManager = engine.GetAlertManager()
+	h.dashboardManager = engine.GetDashboardManager()
+}
+
+// SetDashboardManager exposes the dashboards and share links of a dashboard manager running
+// outside a real-time engine
+func (h *AnalyticsHandlers) SetDashboardManager(dashboardManager *analytics.DashboardManager) {
+	h.dashboardManager = dashboardManager
+}
+
+// SetAlertManager exposes the incidents of an alert manager running outside a real-time engine
+func (h *AnalyticsHandlers) SetAlertManager(alertManager *analytics.AlertManager) {
+	h.alertManager = alertManager
+}
+
+// RegisterPublicRoutes registers the routes served without authentication: dashboards
+// rendered through share links, whose signed token is the credential
+func (h *AnalyticsHandlers) RegisterPublicRoutes(router *mux.Router) {
+	router.HandleFunc("/api/public/dashboards/{token}", h.GetSharedDashboard).Methods("GET")
+}
+
+// RegisterAdminRoutes registers the routes managing the real-time engine's data streams. They
+// must be mounted behind admin authorization.
+func (h *AnalyticsHandlers) RegisterAdminRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/analytics/streams", h.ListDataStreams).Methods("GET")
+	router.HandleFunc("/api/admin/analytics/streams", h.CreateDataStream).Methods("POST")
+	router.HandleFunc("/api/admin/analytics/streams/{id}", h.GetDataStream).Methods("GET")
+	router.HandleFunc("/api/admin/analytics/streams/{id}", h.UpdateDataStream).Methods("PATCH")
+	router.HandleFunc("/api/admin/analytics/streams/{id}", h.DeleteDataStream).Methods("DELETE")
+	router.HandleFunc("/api/admin/analytics/streams/{id}/pause", h.PauseDataStream).Methods("POST")
+	router.HandleFunc("/api/admin/analytics/streams/{id}/resume", h.ResumeDataStream).Methods("POST")
+	router.HandleFunc("/api/admin/analytics/streams/{id}/subscribers", h.GetStreamSubscribers).Methods("GET")
+}
+
+// RegisterShareRoutes registers the routes managing dashboard share links. They must be
+// mounted behind authentication, which identifies who shares and revokes.
+func (h *AnalyticsHandlers) RegisterShareRoutes(router *mux.Router) {
+	router.HandleFunc("/api/analytics/dashboards/{id}/share", h.ShareDashboard).Methods("POST")
+	router.HandleFunc("/api/analytics/dashboards/{id}/shares", h.GetDashboardShares).Methods("GET")
+	router.HandleFunc("/api/analytics/dashboards/{id}/shares/{share_id}", h.RevokeDashboardShare).Methods("DELETE")
+}
+
 // RegisterRoutes registers analytics API routes
 func (h *AnalyticsHandlers) RegisterRoutes(router *mux.Router) {
 	// Performance metrics
@@ -32,6 +86,7 @@ func (h *AnalyticsHandlers) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/analytics/performance/trading", h.GetTradingMetrics).Methods("GET")
 	router.HandleFunc("/api/analytics/performance/system", h.GetSystemMetrics).Methods("GET")
 	router.HandleFunc("/api/analytics/performance/portfolio", h.GetPortfolioMetrics).Methods("GET")
+	router.HandleFunc("/api/analytics/performance/realtime", h.GetRealTimeStats).Methods("GET")
 
 	// Performance analysis
 	router.HandleFunc("/api/analytics/analysis/overview", h.GetPerformanceOverview).Methods("GET")
@@ -54,6 +109,15 @@ func (h *AnalyticsHandlers) RegisterRoutes(router *mux.Router) {
 
 	// Dashboard
 	router.HandleFunc("/api/analytics/dashboard", h.GetAnalyticsDashboard).Methods("GET")
+	router.HandleFunc("/api/analytics/dashboards/{id}/widgets/{widget_id}/data", h.GetWidgetData).Methods("GET")
+	router.HandleFunc("/api/analytics/correlations", h.GetCorrelationMatrix).Methods("GET")
+	router.HandleFunc("/api/analytics/heatmap", h.GetHeatmap).Methods("GET")
+	h.RegisterShareRoutes(router)
+
+	// Alert incidents
+	router.HandleFunc("/api/analytics/incidents", h.GetIncidents).Methods("GET")
+	router.HandleFunc("/api/analytics/incidents/{id}", h.GetIncident).Methods("GET")
+	router.HandleFunc("/api/analytics/incidents/{id}/acknowledge", h.AcknowledgeIncident).Methods("POST")
 }
 
 // GetPerformanceMetrics returns comprehensive performance metrics
@@ -100,6 +164,20 @@ func (h *AnalyticsHandlers) GetPortfolioMetrics(w http.ResponseWriter, r *http.R
 	json.NewEncoder(w).Encode(metrics)
 }
 
+// GetRealTimeStats returns the published, delivered and dropped events of the real-time
+// analytics streams and subscribers
+func (h *AnalyticsHandlers) GetRealTimeStats(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	stats := h.realtimeEngine.GetEngineStats()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
 // GetPerformanceOverview returns performance overview
 func (h *AnalyticsHandlers) GetPerformanceOverview(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -571,3 +649,590 @@ func (h *AnalyticsHandlers) GetAnalyticsDashboard(w http.ResponseWriter, r *http
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(dashboard)
 }
+
+// GetIncidents returns alert incidents with their member alerts, optionally filtered by status
+func (h *AnalyticsHandlers) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	if h.alertManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Alert manager is not running")
+		return
+	}
+
+	status := analytics.IncidentStatus(r.URL.Query().Get("status"))
+	var v validation.Validator
+	v.OneOf("status", string(status), string(analytics.IncidentStatusOpen), string(analytics.IncidentStatusAcknowledged), string(analytics.IncidentStatusResolved))
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	incidents := h.alertManager.GetIncidents(status)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"incidents": incidents,
+		"count":     len(incidents),
+	})
+}
+
+// GetIncident returns an alert incident with its member alerts
+func (h *AnalyticsHandlers) GetIncident(w http.ResponseWriter, r *http.Request) {
+	if h.alertManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Alert manager is not running")
+		return
+	}
+
+	incident, err := h.alertManager.GetIncident(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeIncidentError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incident)
+}
+
+// AcknowledgeIncident acknowledges an alert incident and all of its member alerts
+func (h *AnalyticsHandlers) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	if h.alertManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Alert manager is not running")
+		return
+	}
+
+	var request struct {
+		AcknowledgedBy string `json:"acknowledged_by"`
+	}
+	if err := (httputil.BodyDecoder{}).Decode(r, &request); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+	var v validation.Validator
+	v.Required("acknowledged_by", request.AcknowledgedBy)
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	incident, err := h.alertManager.AcknowledgeIncident(mux.Vars(r)["id"], request.AcknowledgedBy)
+	if err != nil {
+		h.writeIncidentError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incident)
+}
+
+func (h *AnalyticsHandlers) writeIncidentError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, analytics.ErrIncidentNotFound) {
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeNotFound, "Incident not found")
+		return
+	}
+	httputil.InternalError(w, r, h.logger, "Failed to load incident", err)
+}
+
+// GetWidgetData returns the server-side computed data of a correlation matrix or heatmap widget
+func (h *AnalyticsHandlers) GetWidgetData(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	vars := mux.Vars(r)
+	data, err := h.dashboardManager.ComputeWidgetData(r.Context(), vars["id"], vars["widget_id"])
+	if err != nil {
+		h.writeWidgetError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// GetCorrelationMatrix returns the pairwise correlations of metrics or symbols, e.g.
+// ?series=BTCUSDT,ETHUSDT,SOLUSDT&range=30d&interval=1d&returns=true
+func (h *AnalyticsHandlers) GetCorrelationMatrix(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	q := r.URL.Query()
+	var v validation.Validator
+	query := analytics.CorrelationMatrixQuery{TimeRange: analytics.TimeRange{Relative: q.Get("range")}}
+	if v.Required("series", q.Get("series")) {
+		query.Series = strings.Split(q.Get("series"), ",")
+	}
+	if query.TimeRange.Relative == "" {
+		query.TimeRange.Relative = "7d"
+	}
+	if value := q.Get("interval"); value != "" {
+		interval, err := analytics.ParseHistoryDuration(value)
+		if err != nil {
+			v.Fail("interval", "format", "must be a duration such as 1h or 1d")
+		}
+		query.Interval = interval
+	}
+	if value := q.Get("min_overlap"); value != "" {
+		minOverlap, err := strconv.Atoi(value)
+		if err != nil {
+			v.Fail("min_overlap", "type", "must be an integer")
+		}
+		query.MinOverlap = minOverlap
+	}
+	if value := q.Get("returns"); value != "" {
+		returns, err := strconv.ParseBool(value)
+		if err != nil {
+			v.Fail("returns", "type", "must be true or false")
+		}
+		query.Returns = returns
+	}
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	matrix, err := h.dashboardManager.ComputeCorrelationMatrix(r.Context(), query)
+	if err != nil {
+		h.writeWidgetError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matrix)
+}
+
+// GetHeatmap returns a metric or symbol aggregated over two time dimensions, e.g.
+// ?metric=trading_volume&x=hour_of_day&y=day_of_week&range=30d&aggregation=avg
+func (h *AnalyticsHandlers) GetHeatmap(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	q := r.URL.Query()
+	query := analytics.HeatmapQuery{
+		Metric:      q.Get("metric"),
+		TimeRange:   analytics.TimeRange{Relative: q.Get("range")},
+		X:           analytics.HeatmapDimension(q.Get("x")),
+		Y:           analytics.HeatmapDimension(q.Get("y")),
+		Aggregation: q.Get("aggregation"),
+		Timezone:    q.Get("timezone"),
+	}
+	if query.TimeRange.Relative == "" {
+		query.TimeRange.Relative = "30d"
+	}
+	if query.X == "" {
+		query.X = analytics.DimensionHourOfDay
+	}
+	if query.Y == "" {
+		query.Y = analytics.DimensionDayOfWeek
+	}
+
+	var v validation.Validator
+	v.Required("metric", query.Metric)
+	dimensions := []string{string(analytics.DimensionHourOfDay), string(analytics.DimensionDayOfWeek), string(analytics.DimensionDayOfMonth), string(analytics.DimensionMonth)}
+	v.OneOf("x", string(query.X), dimensions...)
+	v.OneOf("y", string(query.Y), dimensions...)
+	v.OneOf("aggregation", query.Aggregation, analytics.HeatmapAggregationAvg, analytics.HeatmapAggregationSum,
+		analytics.HeatmapAggregationMin, analytics.HeatmapAggregationMax, analytics.HeatmapAggregationCount)
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	heatmap, err := h.dashboardManager.ComputeHeatmap(r.Context(), query)
+	if err != nil {
+		h.writeWidgetError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(heatmap)
+}
+
+func (h *AnalyticsHandlers) writeWidgetError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, analytics.ErrInvalidWidgetQuery):
+		httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, err.Error())
+	case errors.Is(err, analytics.ErrDashboardNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeNotFound, "Dashboard not found")
+	case errors.Is(err, analytics.ErrWidgetNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeNotFound, "Widget not found")
+	default:
+		httputil.InternalError(w, r, h.logger, "Failed to compute widget data", err)
+	}
+}
+
+// ShareDashboard creates an expiring share link to a dashboard that may read the given
+// metrics and portfolios. The token in the response is only returned once.
+func (h *AnalyticsHandlers) ShareDashboard(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	var request struct {
+		Metrics    []string `json:"metrics"`
+		Portfolios []string `json:"portfolios"`
+		ExpiresIn  string   `json:"expires_in"`
+	}
+	if err := (httputil.BodyDecoder{}).Decode(r, &request); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+	var v validation.Validator
+	if len(request.Metrics) == 0 && len(request.Portfolios) == 0 {
+		v.Fail("metrics", "required", "at least one metric or portfolio must be shared")
+	}
+	var ttl time.Duration
+	if request.ExpiresIn != "" {
+		duration, err := analytics.ParseHistoryDuration(request.ExpiresIn)
+		if err != nil {
+			v.Fail("expires_in", "duration", "must be a duration such as 12h or 7d")
+		}
+		ttl = duration
+	}
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r.Context())
+	scope := analytics.DashboardShareScope{Metrics: request.Metrics, Portfolios: request.Portfolios}
+	share, token, err := h.dashboardManager.CreateShare(mux.Vars(r)["id"], scope, ttl, userID)
+	if err != nil {
+		h.writeShareError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"share": share,
+		"token": token,
+	})
+}
+
+// GetDashboardShares lists the share links of a dashboard, including revoked and expired
+// ones: all of them to the dashboard's owner and editors, their own to other users
+func (h *AnalyticsHandlers) GetDashboardShares(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r.Context())
+	shares, err := h.dashboardManager.GetShares(mux.Vars(r)["id"], userID)
+	if err != nil {
+		h.writeShareError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"shares": shares,
+	})
+}
+
+// RevokeDashboardShare revokes a dashboard share link. The dashboard's owner and editors may
+// revoke any of its links, other users only their own.
+func (h *AnalyticsHandlers) RevokeDashboardShare(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	vars := mux.Vars(r)
+	userID, _ := middleware.GetUserID(r.Context())
+	if err := h.dashboardManager.RevokeShare(vars["id"], vars["share_id"], userID); err != nil {
+		h.writeShareError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedDashboard renders a dashboard through a share link. Widgets outside the link's
+// scope are returned without data.
+func (h *AnalyticsHandlers) GetSharedDashboard(w http.ResponseWriter, r *http.Request) {
+	if h.dashboardManager == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard manager is not running")
+		return
+	}
+
+	dashboard, err := h.dashboardManager.GetSharedDashboard(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		h.writeShareError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(dashboard)
+}
+
+func (h *AnalyticsHandlers) writeShareError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, analytics.ErrInvalidShare):
+		httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeValidationFailed, err.Error())
+	case errors.Is(err, analytics.ErrShareForbidden):
+		httputil.WriteError(w, r, http.StatusForbidden, httputil.CodeForbidden, "Not allowed to share this dashboard")
+	case errors.Is(err, analytics.ErrShareExpired):
+		httputil.WriteError(w, r, http.StatusGone, httputil.CodeExpired, "Share link has expired or been revoked")
+	case errors.Is(err, analytics.ErrInvalidShareToken), errors.Is(err, analytics.ErrDashboardNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeNotFound, "Dashboard not found")
+	case errors.Is(err, analytics.ErrShareNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeNotFound, "Share not found")
+	case errors.Is(err, analytics.ErrSharingUnavailable):
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Dashboard sharing is not configured")
+	default:
+		httputil.InternalError(w, r, h.logger, "Failed to share dashboard", err)
+	}
+}
+
+// eventTypes lists the event types data streams may filter on
+var eventTypes = []string{
+	string(analytics.EventTypeUserAction),
+	string(analytics.EventTypeSystemMetric),
+	string(analytics.EventTypeTradingActivity),
+	string(analytics.EventTypeSecurityEvent),
+	string(analytics.EventTypePerformance),
+	string(analytics.EventTypeError),
+	string(analytics.EventTypeBusinessMetric),
+	string(analytics.EventTypeAIInsight),
+	string(analytics.EventTypeMarketData),
+	string(analytics.EventTypeCustom),
+}
+
+// ListDataStreams lists the data streams with their event type filters and throughput
+func (h *AnalyticsHandlers) ListDataStreams(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"streams": h.realtimeEngine.GetDataStreams(),
+	})
+}
+
+// CreateDataStream creates a data stream. retention_period, such as 6h, must not exceed the
+// metrics retention period and defaults to it.
+func (h *AnalyticsHandlers) CreateDataStream(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	var request struct {
+		Name            string   `json:"name"`
+		Source          string   `json:"source"`
+		EventTypes      []string `json:"event_types"`
+		BufferSize      int      `json:"buffer_size"`
+		RetentionPeriod string   `json:"retention_period"`
+	}
+	if err := (httputil.BodyDecoder{}).Decode(r, &request); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+	var v validation.Validator
+	v.Required("name", request.Name)
+	v.Required("source", request.Source)
+	types := validateEventTypes(&v, request.EventTypes)
+	config := h.realtimeEngine.DefaultStreamConfig()
+	if request.BufferSize != 0 {
+		v.IntRange("buffer_size", request.BufferSize, 1, 100000)
+		config.BufferSize = request.BufferSize
+	}
+	if request.RetentionPeriod != "" {
+		config.RetentionPeriod = parseRetention(&v, request.RetentionPeriod)
+	}
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	stream, err := h.realtimeEngine.CreateDataStream(request.Name, request.Source, types, config)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidStream) {
+			httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeValidationFailed, err.Error())
+			return
+		}
+		httputil.WriteError(w, r, http.StatusConflict, httputil.CodeConflict, err.Error())
+		return
+	}
+	info, err := h.realtimeEngine.GetDataStream(stream.StreamID)
+	if err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(info)
+}
+
+// GetDataStream returns a data stream with its subscribers
+func (h *AnalyticsHandlers) GetDataStream(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	info, err := h.realtimeEngine.GetDataStream(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// UpdateDataStream renames a data stream, replaces its event type filters or changes its
+// retention period. Subscribers stay subscribed and receive events matching the new filters.
+func (h *AnalyticsHandlers) UpdateDataStream(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	var request struct {
+		Name            *string  `json:"name"`
+		EventTypes      []string `json:"event_types"`
+		RetentionPeriod *string  `json:"retention_period"`
+	}
+	if err := (httputil.BodyDecoder{}).Decode(r, &request); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+	var v validation.Validator
+	var update analytics.DataStreamUpdate
+	if request.Name != nil {
+		v.Required("name", *request.Name)
+		update.Name = request.Name
+	}
+	if request.EventTypes != nil {
+		update.EventTypes = validateEventTypes(&v, request.EventTypes)
+	}
+	if request.RetentionPeriod != nil {
+		// An empty retention period resets the stream to the metrics retention period
+		var retention time.Duration
+		if *request.RetentionPeriod != "" {
+			retention = parseRetention(&v, *request.RetentionPeriod)
+		}
+		update.RetentionPeriod = &retention
+	}
+	if err := v.Err(); err != nil {
+		httputil.WriteRequestError(w, r, err)
+		return
+	}
+
+	info, err := h.realtimeEngine.UpdateDataStream(mux.Vars(r)["id"], update)
+	if err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// DeleteDataStream deletes a data stream and closes its subscriptions
+func (h *AnalyticsHandlers) DeleteDataStream(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	if err := h.realtimeEngine.DeleteDataStream(mux.Vars(r)["id"]); err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PauseDataStream stops routing events to a data stream
+func (h *AnalyticsHandlers) PauseDataStream(w http.ResponseWriter, r *http.Request) {
+	h.setStreamStatus(w, r, h.realtimeEngine.PauseDataStream)
+}
+
+// ResumeDataStream resumes routing events to a paused data stream
+func (h *AnalyticsHandlers) ResumeDataStream(w http.ResponseWriter, r *http.Request) {
+	h.setStreamStatus(w, r, h.realtimeEngine.ResumeDataStream)
+}
+
+// GetStreamSubscribers lists the subscribers of a data stream. A subscriber's lag is its
+// queued events.
+func (h *AnalyticsHandlers) GetStreamSubscribers(w http.ResponseWriter, r *http.Request) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	info, err := h.realtimeEngine.GetDataStream(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stream_id":   info.StreamID,
+		"subscribers": info.Subscribers,
+	})
+}
+
+func (h *AnalyticsHandlers) setStreamStatus(w http.ResponseWriter, r *http.Request, set func(string) error) {
+	if h.realtimeEngine == nil {
+		httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeServiceUnavailable, "Real-time analytics engine is not running")
+		return
+	}
+
+	streamID := mux.Vars(r)["id"]
+	if err := set(streamID); err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+	info, err := h.realtimeEngine.GetDataStream(streamID)
+	if err != nil {
+		h.writeStreamError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+func (h *AnalyticsHandlers) writeStreamError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, analytics.ErrStreamNotFound):
+		httputil.WriteError(w, r, http.StatusNotFound, httputil.CodeNotFound, "Data stream not found")
+	case errors.Is(err, analytics.ErrInvalidStream):
+		httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeValidationFailed, err.Error())
+	default:
+		httputil.InternalError(w, r, h.logger, "Failed to manage data stream", err)
+	}
+}
+
+func validateEventTypes(v *validation.Validator, values []string) []analytics.EventType {
+	if len(values) == 0 {
+		v.Fail("event_types", "required", "at least one event type is required")
+	}
+	types := make([]analytics.EventType, 0, len(values))
+	for _, value := range values {
+		v.OneOf("event_types", value, eventTypes...)
+		types = append(types, analytics.EventType(value))
+	}
+	return types
+}
+
+func parseRetention(v *validation.Validator, value string) time.Duration {
+	retention, err := analytics.ParseHistoryDuration(value)
+	if err != nil {
+		v.Fail("retention_period", "duration", "must be a duration such as 6h or 7d")
+	}
+	return retention
+}
@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// apiKeyPrefix marks API keys so they are recognisable in configs and secret scanners
+	apiKeyPrefix = "acb_"
+	// apiKeyDisplayLength is how much of a key is stored in the clear to identify it
+	apiKeyDisplayLength = 12
+	maxAPIKeysPerUser   = 25
+)
+
+var (
+	// ErrInvalidAPIKeyRequest is returned when an API key cannot be created as requested
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+	// ErrAPIKeyNotFound is returned for unknown, already revoked and other users' keys
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// APIKey is a user's key for programmatic access. The secret itself is never stored; only
+// its prefix is kept to tell keys apart.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyRequest represents an API key creation request
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey is a new API key with its secret, which is only returned once
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey creates an API key for a user, with no scope beyond what their role allows
+func (s *Service) CreateAPIKey(ctx context.Context, userID uuid.UUID, req CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newAPIKey(userID, user.Role, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var active int
+	err = s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())`,
+		userID).Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	if active >= maxAPIKeysPerUser {
+		return nil, fmt.Errorf("%w: at most %d active keys are allowed", ErrInvalidAPIKeyRequest, maxAPIKeysPerUser)
+	}
+
+	secret, err := generateAPIKeySecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key.Prefix = secret[:apiKeyDisplayLength]
+
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.db.ExecContext(ctx, query, key.ID, key.UserID, key.Name, key.Prefix,
+		middleware.HashAPIKey(secret), pq.Array(key.Scopes), key.ExpiresAt, key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	s.logger.Info(ctx, "API key created", map[string]interface{}{
+		"user_id":    userID.String(),
+		"api_key_id": key.ID.String(),
+		"scopes":     strings.Join(key.Scopes, ","),
+	})
+
+	return &CreatedAPIKey{APIKey: *key, Key: secret}, nil
+}
+
+// ListAPIKeys returns a user's API keys, newest first, including revoked and expired ones
+func (s *Service) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*APIKey, error) {
+	query := `
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		key := &APIKey{}
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, pq.Array(&key.Scopes),
+			&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes one of a user's API keys. Services caching the key reject it within
+// seconds through the revocation list.
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := s.revocations.RevokeAPIKey(ctx, keyID.String()); err != nil {
+		// Cached copies of the key stay valid until they expire
+		s.logger.Error(ctx, "Failed to publish api key revocation", err, map[string]interface{}{
+			"api_key_id": keyID.String(),
+		})
+	}
+
+	s.logger.Info(ctx, "API key revoked", map[string]interface{}{
+		"user_id":    userID.String(),
+		"api_key_id": keyID.String(),
+	})
+	return nil
+}
+
+// newAPIKey validates a creation request by a user with role and builds the key without its
+// secret
+func newAPIKey(userID uuid.UUID, role string, req CreateAPIKeyRequest, now time.Time) (*APIKey, error) {
+	key := &APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: now.UTC(),
+	}
+	if key.Name == "" || len(key.Name) > 100 {
+		return nil, fmt.Errorf("%w: name must be between 1 and 100 characters", ErrInvalidAPIKeyRequest)
+	}
+
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !middleware.ValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q, expected one of %s",
+				ErrInvalidAPIKeyRequest, scope, strings.Join(middleware.APIKeyScopes, ", "))
+		}
+		if required := middleware.APIKeyScopeRole(scope); !middleware.RoleIncludes(role, required) {
+			return nil, fmt.Errorf("%w: scope %q requires the %s role", ErrInvalidAPIKeyRequest, scope, required)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			key.Scopes = append(key.Scopes, scope)
+		}
+	}
+	if len(key.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyRequest)
+		}
+		expiresAt := req.ExpiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+	return key, nil
+}
+
+// generateAPIKeySecret creates a random API key
+func generateAPIKeySecret() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(bytes), nil
+}
+
+// apiKeyStore resolves API keys for middleware.APIKeyAuthenticator from PostgreSQL
+type apiKeyStore struct {
+	db *database.DB
+}
+
+// NewAPIKeyStore creates the store services use to authenticate API keys
+func NewAPIKeyStore(db *database.DB) middleware.APIKeyStore {
+	return &apiKeyStore{db: db}
+}
+
+func (s *apiKeyStore) FindAPIKey(ctx context.Context, keyHash string) (*middleware.APIKey, error) {
+	query := `
+		SELECT k.id, k.user_id, COALESCE(u.role, ''), k.scopes, k.expires_at
+		FROM api_keys k JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL
+	`
+	var id, userID uuid.UUID
+	key := &middleware.APIKey{}
+	err := s.db.QueryRowContext(ctx, query, keyHash).Scan(&id, &userID, &key.Role, pq.Array(&key.Scopes), &key.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, middleware.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	key.ID = id.String()
+	key.UserID = userID.String()
+	return key, nil
+}
+
+func (s *apiKeyStore) TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, usedAt, keyID)
+	return err
+}
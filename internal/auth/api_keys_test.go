@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)
+	expiresAt := now.Add(90 * 24 * time.Hour)
+
+	key, err := newAPIKey(userID, middleware.RoleTrader, CreateAPIKeyRequest{
+		Name:      " trading bot ",
+		Scopes:    []string{"trading:read", " Trading:Write ", "trading:read"},
+		ExpiresAt: &expiresAt,
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, userID, key.UserID)
+	assert.Equal(t, "trading bot", key.Name)
+	assert.Equal(t, []string{middleware.ScopeTradingRead, middleware.ScopeTradingWrite}, key.Scopes)
+	assert.Equal(t, expiresAt, *key.ExpiresAt)
+	assert.Equal(t, now, key.CreatedAt)
+
+	past := now.Add(-time.Minute)
+	invalid := []CreateAPIKeyRequest{
+		{Scopes: []string{"ai:invoke"}},
+		{Name: strings.Repeat("k", 101), Scopes: []string{"ai:invoke"}},
+		{Name: "no scopes"},
+		{Name: "unknown scope", Scopes: []string{"admin"}},
+		{Name: "expired", Scopes: []string{"ai:invoke"}, ExpiresAt: &past},
+	}
+	for _, req := range invalid {
+		_, err := newAPIKey(userID, middleware.RoleTrader, req, now)
+		assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest, "%+v", req)
+	}
+}
+
+func TestNewAPIKey_ScopesLimitedByRole(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+	write := CreateAPIKeyRequest{Name: "bot", Scopes: []string{"trading:read", "trading:write"}}
+	read := CreateAPIKeyRequest{Name: "dashboard", Scopes: []string{"trading:read", "ai:invoke"}}
+
+	_, err := newAPIKey(userID, middleware.RoleViewer, write, now)
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+	assert.ErrorContains(t, err, `scope "trading:write" requires the trader role`)
+	_, err = newAPIKey(userID, "", read, now)
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest, "users without a known role can't create keys")
+
+	_, err = newAPIKey(userID, middleware.RoleViewer, read, now)
+	assert.NoError(t, err)
+	_, err = newAPIKey(userID, middleware.RoleTrader, write, now)
+	assert.NoError(t, err)
+	_, err = newAPIKey(userID, middleware.RoleAdmin, write, now)
+	assert.NoError(t, err)
+}
+
+func TestGenerateAPIKeySecret(t *testing.T) {
+	first, err := generateAPIKeySecret()
+	require.NoError(t, err)
+	second, err := generateAPIKeySecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, apiKeyPrefix))
+	assert.Len(t, first, len(apiKeyPrefix)+48)
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, middleware.HashAPIKey(first), middleware.HashAPIKey(second))
+	assert.Len(t, middleware.HashAPIKey(first), 64)
+}
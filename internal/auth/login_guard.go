@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAction is what a LoginGuard requires of a login attempt before its credentials are checked
+type LoginAction string
+
+const (
+	// LoginActionAllow lets the attempt proceed
+	LoginActionAllow LoginAction = "allow"
+	// LoginActionDelay lets the attempt proceed after the decision's delay
+	LoginActionDelay LoginAction = "delay"
+	// LoginActionChallenge requires the decision's challenge to be passed first
+	LoginActionChallenge LoginAction = "challenge"
+	// LoginActionLocked rejects the attempt until the decision's retry time
+	LoginActionLocked LoginAction = "locked"
+)
+
+// LoginChallenge is the check a client must pass before retrying a throttled login
+type LoginChallenge string
+
+const (
+	LoginChallengeCaptcha LoginChallenge = "captcha"
+	LoginChallengeStepUp  LoginChallenge = "step_up"
+)
+
+// LoginDecision is a LoginGuard's verdict on a login attempt
+type LoginDecision struct {
+	Action     LoginAction    `json:"action"`
+	Challenge  LoginChallenge `json:"challenge,omitempty"`
+	Delay      time.Duration  `json:"-"`
+	RetryAfter time.Duration  `json:"-"`
+	Reason     string         `json:"reason,omitempty"`
+}
+
+// LoginAttempt identifies a login attempt to a LoginGuard. UserID is set once the email is
+// known to belong to an account.
+type LoginAttempt struct {
+	Email     string
+	IPAddress string
+	UserAgent string
+	DeviceID  string
+	UserID    *uuid.UUID
+}
+
+// LoginGuard slows down and stops password guessing and credential stuffing. It is asked about
+// every login attempt before the credentials are checked, and told about the outcome.
+type LoginGuard interface {
+	CheckLogin(ctx context.Context, attempt LoginAttempt) (LoginDecision, error)
+	RecordLoginFailure(ctx context.Context, attempt LoginAttempt, reason string) (LoginDecision, error)
+	RecordLoginSuccess(ctx context.Context, attempt LoginAttempt) error
+}
+
+// ChallengeVerifier verifies the CAPTCHA or step-up response sent with a login request
+type ChallengeVerifier interface {
+	VerifyChallenge(ctx context.Context, challenge LoginChallenge, response string, attempt LoginAttempt) (bool, error)
+}
+
+// LoginBlockedError is returned by Login when the LoginGuard stops an attempt. Handlers
+// respond with its decision so clients know whether to wait or pass a challenge.
+type LoginBlockedError struct {
+	Decision LoginDecision
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Decision.Action == LoginActionChallenge {
+		return fmt.Sprintf("login requires %s", e.Decision.Challenge)
+	}
+	return "too many failed login attempts, try again later"
+}
+
+// SetLoginGuard throttles logins with guard. Challenges are only enforced with a verifier;
+// without one, attempts that would be challenged are delayed instead.
+func (s *Service) SetLoginGuard(guard LoginGuard, verifier ChallengeVerifier) {
+	s.loginGuard = guard
+	s.challengeVerifier = verifier
+}
+
+// guardLogin applies the LoginGuard's decision to an attempt before its credentials are checked
+func (s *Service) guardLogin(ctx context.Context, attempt LoginAttempt, challengeResponse string) error {
+	if s.loginGuard == nil {
+		return nil
+	}
+
+	decision, err := s.loginGuard.CheckLogin(ctx, attempt)
+	if err != nil {
+		// Throttling is a second line of defense; logins keep working without its store
+		s.logger.Warn(ctx, "Login guard unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	switch decision.Action {
+	case LoginActionLocked:
+		return &LoginBlockedError{Decision: decision}
+	case LoginActionChallenge:
+		if s.challengeVerifier != nil {
+			if challengeResponse == "" {
+				return &LoginBlockedError{Decision: decision}
+			}
+			passed, err := s.challengeVerifier.VerifyChallenge(ctx, decision.Challenge, challengeResponse, attempt)
+			if err != nil {
+				return fmt.Errorf("failed to verify %s: %w", decision.Challenge, err)
+			}
+			if !passed {
+				return &LoginBlockedError{Decision: decision}
+			}
+		}
+	}
+
+	if decision.Delay > 0 {
+		timer := time.NewTimer(decision.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure tells the LoginGuard about a failed attempt
+func (s *Service) recordLoginFailure(ctx context.Context, attempt LoginAttempt, reason string) {
+	if s.loginGuard == nil {
+		return
+	}
+	if _, err := s.loginGuard.RecordLoginFailure(ctx, attempt, reason); err != nil {
+		s.logger.Warn(ctx, "Failed to record login failure", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// recordLoginSuccess tells the LoginGuard about a successful attempt
+func (s *Service) recordLoginSuccess(ctx context.Context, attempt LoginAttempt) {
+	if s.loginGuard == nil {
+		return
+	}
+	if err := s.loginGuard.RecordLoginSuccess(ctx, attempt); err != nil {
+		s.logger.Warn(ctx, "Failed to record login success", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
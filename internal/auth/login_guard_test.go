@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLoginGuard returns the same decision for every attempt
+type stubLoginGuard struct {
+	decision LoginDecision
+	err      error
+}
+
+func (g *stubLoginGuard) CheckLogin(ctx context.Context, attempt LoginAttempt) (LoginDecision, error) {
+	return g.decision, g.err
+}
+
+func (g *stubLoginGuard) RecordLoginFailure(ctx context.Context, attempt LoginAttempt, reason string) (LoginDecision, error) {
+	return g.decision, g.err
+}
+
+func (g *stubLoginGuard) RecordLoginSuccess(ctx context.Context, attempt LoginAttempt) error {
+	return g.err
+}
+
+// stubChallengeVerifier accepts one response
+type stubChallengeVerifier struct {
+	valid string
+}
+
+func (v *stubChallengeVerifier) VerifyChallenge(ctx context.Context, challenge LoginChallenge, response string, attempt LoginAttempt) (bool, error) {
+	return response == v.valid, nil
+}
+
+func TestGuardLogin(t *testing.T) {
+	ctx := context.Background()
+	attempt := LoginAttempt{Email: "alice@example.com", IPAddress: "203.0.113.7"}
+	service := &Service{logger: observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})}
+
+	// Without a guard every attempt proceeds
+	require.NoError(t, service.guardLogin(ctx, attempt, ""))
+
+	locked := &stubLoginGuard{decision: LoginDecision{Action: LoginActionLocked, RetryAfter: time.Minute}}
+	service.SetLoginGuard(locked, nil)
+	var blocked *LoginBlockedError
+	require.ErrorAs(t, service.guardLogin(ctx, attempt, ""), &blocked)
+	assert.Equal(t, time.Minute, blocked.Decision.RetryAfter)
+
+	// Challenges are only enforced with a verifier; without one the delay still applies
+	challenge := &stubLoginGuard{decision: LoginDecision{
+		Action:    LoginActionChallenge,
+		Challenge: LoginChallengeCaptcha,
+		Delay:     10 * time.Millisecond,
+	}}
+	service.SetLoginGuard(challenge, nil)
+	started := time.Now()
+	require.NoError(t, service.guardLogin(ctx, attempt, ""))
+	assert.GreaterOrEqual(t, time.Since(started), 10*time.Millisecond)
+
+	service.SetLoginGuard(challenge, &stubChallengeVerifier{valid: "solved"})
+	require.ErrorAs(t, service.guardLogin(ctx, attempt, ""), &blocked)
+	assert.Equal(t, LoginChallengeCaptcha, blocked.Decision.Challenge)
+	require.ErrorAs(t, service.guardLogin(ctx, attempt, "wrong"), &blocked)
+	require.NoError(t, service.guardLogin(ctx, attempt, "solved"))
+
+	// A cancelled request stops waiting out the delay
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, service.guardLogin(cancelled, attempt, "solved"), context.Canceled)
+
+	// Logins keep working when the guard's store is down
+	service.SetLoginGuard(&stubLoginGuard{err: errors.New("redis unavailable")}, nil)
+	assert.NoError(t, service.guardLogin(ctx, attempt, ""))
+}
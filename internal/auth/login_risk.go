@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+)
+
+// LoginRiskAssessment is a LoginRiskAssessor's verdict on a successful login
+type LoginRiskAssessment struct {
+	Score       float64  `json:"risk_score"`
+	Signals     []string `json:"risk_signals,omitempty"`
+	RequiresMFA bool     `json:"requires_mfa"`
+}
+
+// LoginRiskAssessor scores how anomalous a login is for its user, from where and on what
+// device it comes from and when. It is asked about every login that passed the credential
+// check and is expected to remember the login for the next assessment.
+type LoginRiskAssessor interface {
+	AssessLogin(ctx context.Context, attempt LoginAttempt) (LoginRiskAssessment, error)
+}
+
+// SetLoginRiskAssessor scores successful logins with assessor; the score and whether MFA
+// is required are returned with the tokens
+func (s *Service) SetLoginRiskAssessor(assessor LoginRiskAssessor) {
+	s.loginRisk = assessor
+}
+
+// assessLoginRisk asks the LoginRiskAssessor about a successful login. The login proceeds
+// without a score when the assessor fails.
+func (s *Service) assessLoginRisk(ctx context.Context, attempt LoginAttempt) *LoginRiskAssessment {
+	if s.loginRisk == nil {
+		return nil
+	}
+
+	assessment, err := s.loginRisk.AssessLogin(ctx, attempt)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to assess login risk", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	return &assessment
+}
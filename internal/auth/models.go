@@ -41,6 +41,11 @@ type UserSession struct {
 	LastUsedAt       time.Time `json:"last_used_at" db:"last_used_at"`
 	UserAgent        *string   `json:"user_agent" db:"user_agent"`
 	IPAddress        *string   `json:"ip_address" db:"ip_address"`
+
+	// Rotation: every refresh token issued from one login shares a family. A rotated
+	// token has been exchanged for a new one and must not be presented again.
+	FamilyID  uuid.UUID  `json:"family_id" db:"family_id"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
 }
 
 // RegisterRequest represents a user registration request
@@ -55,6 +60,12 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+
+	// ChallengeResponse answers the CAPTCHA or step-up challenge of a throttled login
+	ChallengeResponse string `json:"challenge_response,omitempty"`
+
+	// DeviceID is the client's device fingerprint, used to recognise new devices
+	DeviceID string `json:"device_id,omitempty"`
 }
 
 // LoginResponse represents a successful login response
@@ -63,6 +74,9 @@ type LoginResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"`
+
+	// Risk is how anomalous the login looked, when logins are scored
+	Risk *LoginRiskAssessment `json:"risk,omitempty"`
 }
 
 // RefreshTokenRequest represents a token refresh request
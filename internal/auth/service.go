@@ -3,14 +3,17 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/middleware"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -18,6 +21,13 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrRefreshTokenReused is returned when a rotated refresh token is presented again. The
+// whole session family is revoked, since the token has likely been stolen.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// ErrInvalidRole is returned when assigning a role other than viewer, trader or admin
+var ErrInvalidRole = errors.New("invalid role")
+
 // Context keys for storing user information
 type contextKey string
 
@@ -60,6 +70,15 @@ type Service struct {
 	mfaService     *MFAService
 	rbacService    *RBACService
 	securityConfig *SecurityConfig
+	sessions       sessionStore
+	revocations    accessTokenRevoker
+
+	// Optional brute-force protection consulted on every login
+	loginGuard        LoginGuard
+	challengeVerifier ChallengeVerifier
+
+	// Optional anomaly scoring of successful logins
+	loginRisk LoginRiskAssessor
 }
 
 // SecurityConfig contains security configuration
@@ -93,10 +112,12 @@ type PasswordComplexityConfig struct {
 // NewService creates a new authentication service
 func NewService(db *database.DB, redis *database.RedisClient, cfg config.JWTConfig, logger *observability.Logger) *Service {
 	return &Service{
-		db:     db,
-		redis:  redis,
-		config: cfg,
-		logger: logger,
+		db:          db,
+		redis:       redis,
+		config:      cfg,
+		logger:      logger,
+		sessions:    &postgresSessionStore{db: db},
+		revocations: middleware.NewTokenRevocationList(redis),
 	}
 }
 
@@ -156,14 +177,22 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, userAgent, ipAddr
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("auth-service").Start(ctx, "auth.Login")
 	defer span.End()
 
+	// Throttled or locked out attempts are stopped before the credentials are checked
+	attempt := LoginAttempt{Email: req.Email, IPAddress: ipAddress, UserAgent: userAgent, DeviceID: req.DeviceID}
+	if err := s.guardLogin(ctx, attempt, req.ChallengeResponse); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		s.logger.Warn(ctx, "Login attempt with non-existent email", map[string]interface{}{
 			"email": req.Email,
 		})
+		s.recordLoginFailure(ctx, attempt, "unknown account")
 		return nil, fmt.Errorf("invalid credentials")
 	}
+	attempt.UserID = &user.ID
 
 	// Check if user is active
 	if !user.IsActive {
@@ -177,23 +206,20 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, userAgent, ipAddr
 			"user_id": user.ID.String(),
 			"email":   user.Email,
 		})
+		s.recordLoginFailure(ctx, attempt, "invalid password")
 		return nil, fmt.Errorf("invalid credentials")
 	}
+	s.recordLoginSuccess(ctx, attempt)
+	risk := s.assessLoginRisk(ctx, attempt)
 
 	// Generate tokens
-	accessToken, err := s.generateAccessToken(user)
-	if err != nil {
-		s.logger.Error(ctx, "Failed to generate access token", err)
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
 	refreshToken, err := s.generateRefreshToken()
 	if err != nil {
 		s.logger.Error(ctx, "Failed to generate refresh token", err)
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	// Store refresh token session
+	// Store refresh token session, which starts a new rotation family
 	session := &UserSession{
 		ID:               uuid.New(),
 		UserID:           user.ID,
@@ -204,8 +230,15 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, userAgent, ipAddr
 		UserAgent:        &userAgent,
 		IPAddress:        &ipAddress,
 	}
+	session.FamilyID = session.ID
 
-	err = s.createSession(ctx, session)
+	accessToken, err := s.generateAccessToken(user, session.FamilyID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to generate access token", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	err = s.sessions.CreateSession(ctx, session)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to create session", err)
 		return nil, fmt.Errorf("failed to create session: %w", err)
@@ -225,24 +258,31 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, userAgent, ipAddr
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		ExpiresIn:    int64(s.config.Expiry.Seconds()),
+		Risk:         risk,
 	}, nil
 }
 
-// RefreshToken generates new tokens using a refresh token
+// RefreshToken exchanges a refresh token for a new access and refresh token. The presented
+// refresh token is rotated out; presenting it again revokes every token of its session.
 func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*LoginResponse, error) {
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("auth-service").Start(ctx, "auth.RefreshToken")
 	defer span.End()
 
 	// Find session by refresh token hash
 	tokenHash := s.hashToken(refreshToken)
-	session, err := s.getSessionByRefreshToken(ctx, tokenHash)
+	session, err := s.sessions.GetSessionByRefreshToken(ctx, tokenHash)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token")
 	}
 
+	// A rotated token should never come back; assume it was stolen
+	if session.RotatedAt != nil {
+		return nil, s.handleRefreshTokenReuse(ctx, session)
+	}
+
 	// Check if session is expired
 	if time.Now().After(session.ExpiresAt) {
-		s.deleteSession(ctx, session.ID)
+		s.sessions.DeleteSession(ctx, session.ID)
 		return nil, fmt.Errorf("refresh token expired")
 	}
 
@@ -254,83 +294,151 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Login
 
 	// Check if user is still active
 	if !user.IsActive {
-		s.deleteSession(ctx, session.ID)
+		s.revokeSessionFamily(ctx, session.FamilyID)
 		return nil, fmt.Errorf("account is deactivated")
 	}
 
-	// Generate new access token
-	accessToken, err := s.generateAccessToken(user)
+	// Claim the token; a concurrent refresh with the same token loses and counts as reuse
+	rotated, err := s.sessions.MarkSessionRotated(ctx, session.ID, time.Now())
+	if err != nil {
+		s.logger.Error(ctx, "Failed to rotate session", err)
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		return nil, s.handleRefreshTokenReuse(ctx, session)
+	}
+
+	// Issue the next token of the family
+	newRefreshToken, err := s.generateRefreshToken()
+	if err != nil {
+		s.logger.Error(ctx, "Failed to generate refresh token", err)
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := time.Now()
+	next := &UserSession{
+		ID:               uuid.New(),
+		UserID:           session.UserID,
+		RefreshTokenHash: s.hashToken(newRefreshToken),
+		ExpiresAt:        now.Add(s.config.RefreshTokenExpiry),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		UserAgent:        session.UserAgent,
+		IPAddress:        session.IPAddress,
+		FamilyID:         session.FamilyID,
+	}
+	if err := s.sessions.CreateSession(ctx, next); err != nil {
+		s.logger.Error(ctx, "Failed to create session", err)
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	accessToken, err := s.generateAccessToken(user, session.FamilyID)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to generate access token", err)
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	// Update session last used time
-	session.LastUsedAt = time.Now()
-	s.updateSessionLastUsed(ctx, session.ID, session.LastUsedAt)
-
 	// Clear password before returning
 	user.Password = ""
 
 	return &LoginResponse{
 		User:         *user,
 		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		RefreshToken: newRefreshToken,
 		ExpiresIn:    int64(s.config.Expiry.Seconds()),
 	}, nil
 }
 
-// Logout invalidates a refresh token
+// Logout invalidates a refresh token together with every access token issued to its session
 func (s *Service) Logout(ctx context.Context, refreshToken string) error {
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("auth-service").Start(ctx, "auth.Logout")
 	defer span.End()
 
 	tokenHash := s.hashToken(refreshToken)
-	session, err := s.getSessionByRefreshToken(ctx, tokenHash)
+	session, err := s.sessions.GetSessionByRefreshToken(ctx, tokenHash)
 	if err != nil {
 		return nil // Already logged out or invalid token
 	}
 
-	err = s.deleteSession(ctx, session.ID)
+	err = s.revokeSessionFamily(ctx, session.FamilyID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to delete session", err)
+		s.logger.Error(ctx, "Failed to revoke session", err)
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 
 	s.logger.Info(ctx, "User logged out successfully", map[string]interface{}{
 		"user_id":    session.UserID.String(),
-		"session_id": session.ID.String(),
+		"session_id": session.FamilyID.String(),
 	})
 
 	return nil
 }
 
+// RevokeAccessToken revokes a single access token until it expires
+func (s *Service) RevokeAccessToken(ctx context.Context, accessToken string) error {
+	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.config.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		return fmt.Errorf("invalid access token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return fmt.Errorf("invalid access token")
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return fmt.Errorf("access token has no jti claim")
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return fmt.Errorf("access token has no exp claim")
+	}
+
+	return s.revocations.RevokeToken(ctx, jti, expiresAt.Time)
+}
+
+// handleRefreshTokenReuse revokes the family of a reused refresh token
+func (s *Service) handleRefreshTokenReuse(ctx context.Context, session *UserSession) error {
+	s.logger.Warn(ctx, "Refresh token reuse detected, revoking session", map[string]interface{}{
+		"user_id":    session.UserID.String(),
+		"session_id": session.FamilyID.String(),
+	})
+
+	if err := s.revokeSessionFamily(ctx, session.FamilyID); err != nil {
+		s.logger.Error(ctx, "Failed to revoke session", err)
+	}
+	return ErrRefreshTokenReused
+}
+
+// revokeSessionFamily deletes every refresh token of a session and revokes the access tokens
+// issued to it
+func (s *Service) revokeSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := s.sessions.DeleteSessionFamily(ctx, familyID); err != nil {
+		return err
+	}
+	// Access tokens outlive the session by at most their own lifetime
+	return s.revocations.RevokeSession(ctx, familyID.String(), s.config.Expiry)
+}
+
 // GetUserByID retrieves a user by ID
 func (s *Service) GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, is_active, is_verified, created_at, updated_at
-		FROM users WHERE id = $1
-	`
-	user := &User{}
-	err := s.db.QueryRowContext(ctx, query, userID).Scan(
-		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName,
-		&user.IsActive, &user.IsVerified, &user.CreatedAt, &user.UpdatedAt,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
-	}
-	return user, nil
+	return s.sessions.GetUserByID(ctx, userID)
 }
 
 // GetUserByEmail retrieves a user by email
 func (s *Service) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, is_active, is_verified, created_at, updated_at
+		SELECT id, email, password_hash, first_name, last_name, COALESCE(role, ''), is_active, is_verified, created_at, updated_at
 		FROM users WHERE email = $1
 	`
 	user := &User{}
 	err := s.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName,
+		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Role,
 		&user.IsActive, &user.IsVerified, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
@@ -339,12 +447,54 @@ func (s *Service) GetUserByEmail(ctx context.Context, email string) (*User, erro
 	return user, nil
 }
 
-// generateAccessToken creates a new JWT access token
-func (s *Service) generateAccessToken(user *User) (string, error) {
+// SetUserRole assigns a role to a user and ends their sessions, so tokens carrying the old
+// role stop working and the new one applies from their next login
+func (s *Service) SetUserRole(ctx context.Context, userID uuid.UUID, role string) error {
+	if !middleware.ValidRole(role) {
+		return fmt.Errorf("%w: %q, expected viewer, trader or admin", ErrInvalidRole, role)
+	}
+
+	result, err := s.db.ExecContext(ctx, `UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`, role, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("user not found: %s", userID)
+	}
+
+	families, err := s.sessions.ListSessionFamilies(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, familyID := range families {
+		if err := s.revokeSessionFamily(ctx, familyID); err != nil {
+			return fmt.Errorf("failed to end session: %w", err)
+		}
+	}
+
+	s.logger.Info(ctx, "User role changed", map[string]interface{}{
+		"user_id":  userID.String(),
+		"role":     role,
+		"sessions": len(families),
+	})
+	return nil
+}
+
+// generateAccessToken creates a new JWT access token. Each token carries a unique jti and the
+// session it was issued to as sid, so it can be revoked alone or with its session, and the
+// user's role, trader when none is set.
+func (s *Service) generateAccessToken(user *User, sessionID uuid.UUID) (string, error) {
 	now := time.Now()
+	role := user.Role
+	if !middleware.ValidRole(role) {
+		role = middleware.RoleTrader
+	}
 	claims := jwt.MapClaims{
 		"user_id": user.ID.String(),
 		"email":   user.Email,
+		"role":    role,
+		"jti":     uuid.New().String(),
+		"sid":     sessionID.String(),
 		"iat":     now.Unix(),
 		"exp":     now.Add(s.config.Expiry).Unix(),
 	}
@@ -362,49 +512,11 @@ func (s *Service) generateRefreshToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// hashToken creates a hash of a token for storage
+// hashToken creates a hash of a token for storage. Refresh tokens are random, so an unsalted
+// SHA-256 is enough and keeps the hash usable as a lookup key.
 func (s *Service) hashToken(token string) string {
-	hash, _ := bcrypt.GenerateFromPassword([]byte(token), bcrypt.DefaultCost)
-	return string(hash)
-}
-
-// Helper methods for session management
-func (s *Service) createSession(ctx context.Context, session *UserSession) error {
-	query := `
-		INSERT INTO user_sessions (id, user_id, refresh_token_hash, expires_at, created_at, last_used_at, user_agent, ip_address)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`
-	_, err := s.db.ExecContext(ctx, query, session.ID, session.UserID, session.RefreshTokenHash,
-		session.ExpiresAt, session.CreatedAt, session.LastUsedAt, session.UserAgent, session.IPAddress)
-	return err
-}
-
-func (s *Service) getSessionByRefreshToken(ctx context.Context, tokenHash string) (*UserSession, error) {
-	query := `
-		SELECT id, user_id, refresh_token_hash, expires_at, created_at, last_used_at, user_agent, ip_address
-		FROM user_sessions WHERE refresh_token_hash = $1
-	`
-	session := &UserSession{}
-	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
-		&session.ID, &session.UserID, &session.RefreshTokenHash, &session.ExpiresAt,
-		&session.CreatedAt, &session.LastUsedAt, &session.UserAgent, &session.IPAddress,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return session, nil
-}
-
-func (s *Service) updateSessionLastUsed(ctx context.Context, sessionID uuid.UUID, lastUsed time.Time) error {
-	query := `UPDATE user_sessions SET last_used_at = $1 WHERE id = $2`
-	_, err := s.db.ExecContext(ctx, query, lastUsed, sessionID)
-	return err
-}
-
-func (s *Service) deleteSession(ctx context.Context, sessionID uuid.UUID) error {
-	query := `DELETE FROM user_sessions WHERE id = $1`
-	_, err := s.db.ExecContext(ctx, query, sessionID)
-	return err
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
 // Advanced Security Methods
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -62,6 +64,46 @@ func (suite *AuthServiceTestSuite) TestValidatePasswordComplexity() {
 	assert.NoError(suite.T(), err)
 }
 
+// TestHashToken tests that refresh token hashes can be used as lookup keys
+func (suite *AuthServiceTestSuite) TestHashToken() {
+	hash := suite.service.hashToken("refresh-token")
+	assert.Equal(suite.T(), hash, suite.service.hashToken("refresh-token"))
+	assert.NotEqual(suite.T(), hash, suite.service.hashToken("other-token"))
+	assert.NotContains(suite.T(), hash, "refresh-token")
+}
+
+// TestAccessTokenClaims tests that access tokens can be revoked individually and by session
+func (suite *AuthServiceTestSuite) TestAccessTokenClaims() {
+	user := &User{ID: uuid.New(), Email: "test@example.com"}
+	sessionID := uuid.New()
+
+	first, err := suite.service.generateAccessToken(user, sessionID)
+	assert.NoError(suite.T(), err)
+	second, err := suite.service.generateAccessToken(user, sessionID)
+	assert.NoError(suite.T(), err)
+
+	parse := func(tokenString string) jwt.MapClaims {
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte("test-secret"), nil
+		})
+		assert.NoError(suite.T(), err)
+		return claims
+	}
+	firstClaims, secondClaims := parse(first), parse(second)
+
+	assert.Equal(suite.T(), user.ID.String(), firstClaims["user_id"])
+	assert.Equal(suite.T(), sessionID.String(), firstClaims["sid"])
+	assert.NotEmpty(suite.T(), firstClaims["jti"])
+	assert.NotEqual(suite.T(), firstClaims["jti"], secondClaims["jti"])
+	assert.Equal(suite.T(), "trader", firstClaims["role"], "users without a role trade")
+
+	user.Role = "viewer"
+	viewer, err := suite.service.generateAccessToken(user, sessionID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "viewer", parse(viewer)["role"])
+}
+
 // Run the test suite
 func TestAuthServiceSuite(t *testing.T) {
 	suite.Run(t, new(AuthServiceTestSuite))
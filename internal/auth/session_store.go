@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// sessionStore holds refresh token sessions. It also looks users up by ID, since every
+// refresh re-checks that the account is still active.
+type sessionStore interface {
+	CreateSession(ctx context.Context, session *UserSession) error
+	GetSessionByRefreshToken(ctx context.Context, tokenHash string) (*UserSession, error)
+	// MarkSessionRotated records that a session's refresh token was exchanged, reporting
+	// false if it already had been
+	MarkSessionRotated(ctx context.Context, sessionID uuid.UUID, rotatedAt time.Time) (bool, error)
+	DeleteSession(ctx context.Context, sessionID uuid.UUID) error
+	DeleteSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	ListSessionFamilies(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error)
+}
+
+// accessTokenRevoker rejects access tokens before they expire
+type accessTokenRevoker interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error
+	RevokeAPIKey(ctx context.Context, keyID string) error
+}
+
+// postgresSessionStore keeps sessions in the user_sessions table
+type postgresSessionStore struct {
+	db *database.DB
+}
+
+func (p *postgresSessionStore) CreateSession(ctx context.Context, session *UserSession) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, refresh_token_hash, expires_at, created_at, last_used_at, user_agent, ip_address, family_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := p.db.ExecContext(ctx, query, session.ID, session.UserID, session.RefreshTokenHash,
+		session.ExpiresAt, session.CreatedAt, session.LastUsedAt, session.UserAgent, session.IPAddress, session.FamilyID)
+	return err
+}
+
+func (p *postgresSessionStore) GetSessionByRefreshToken(ctx context.Context, tokenHash string) (*UserSession, error) {
+	query := `
+		SELECT id, user_id, refresh_token_hash, expires_at, created_at, last_used_at, user_agent, ip_address, family_id, rotated_at
+		FROM user_sessions WHERE refresh_token_hash = $1
+	`
+	session := &UserSession{}
+	err := p.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&session.ID, &session.UserID, &session.RefreshTokenHash, &session.ExpiresAt,
+		&session.CreatedAt, &session.LastUsedAt, &session.UserAgent, &session.IPAddress,
+		&session.FamilyID, &session.RotatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (p *postgresSessionStore) MarkSessionRotated(ctx context.Context, sessionID uuid.UUID, rotatedAt time.Time) (bool, error) {
+	query := `UPDATE user_sessions SET rotated_at = $1, last_used_at = $1 WHERE id = $2 AND rotated_at IS NULL`
+	result, err := p.db.ExecContext(ctx, query, rotatedAt, sessionID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (p *postgresSessionStore) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
+	query := `DELETE FROM user_sessions WHERE id = $1`
+	_, err := p.db.ExecContext(ctx, query, sessionID)
+	return err
+}
+
+func (p *postgresSessionStore) DeleteSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `DELETE FROM user_sessions WHERE family_id = $1`
+	_, err := p.db.ExecContext(ctx, query, familyID)
+	return err
+}
+
+func (p *postgresSessionStore) ListSessionFamilies(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT DISTINCT family_id FROM user_sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var families []uuid.UUID
+	for rows.Next() {
+		var familyID uuid.UUID
+		if err := rows.Scan(&familyID); err != nil {
+			return nil, err
+		}
+		families = append(families, familyID)
+	}
+	return families, rows.Err()
+}
+
+func (p *postgresSessionStore) GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error) {
+	query := `
+		SELECT id, email, password_hash, first_name, last_name, COALESCE(role, ''), is_active, is_verified, created_at, updated_at
+		FROM users WHERE id = $1
+	`
+	user := &User{}
+	err := p.db.QueryRowContext(ctx, query, userID).Scan(
+		&user.ID, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Role,
+		&user.IsActive, &user.IsVerified, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return user, nil
+}
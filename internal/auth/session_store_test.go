@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySessionStore is an in-memory sessionStore
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]UserSession
+	users    map[uuid.UUID]User
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[uuid.UUID]UserSession), users: make(map[uuid.UUID]User)}
+}
+
+func (m *memorySessionStore) CreateSession(ctx context.Context, session *UserSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *memorySessionStore) GetSessionByRefreshToken(ctx context.Context, tokenHash string) (*UserSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, session := range m.sessions {
+		if session.RefreshTokenHash == tokenHash {
+			return &session, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *memorySessionStore) MarkSessionRotated(ctx context.Context, sessionID uuid.UUID, rotatedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, exists := m.sessions[sessionID]
+	if !exists || session.RotatedAt != nil {
+		return false, nil
+	}
+	session.RotatedAt = &rotatedAt
+	m.sessions[sessionID] = session
+	return true, nil
+}
+
+func (m *memorySessionStore) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+func (m *memorySessionStore) DeleteSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, session := range m.sessions {
+		if session.FamilyID == familyID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *memorySessionStore) ListSessionFamilies(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := make(map[uuid.UUID]bool)
+	var families []uuid.UUID
+	for _, session := range m.sessions {
+		if session.UserID == userID && !seen[session.FamilyID] {
+			seen[session.FamilyID] = true
+			families = append(families, session.FamilyID)
+		}
+	}
+	return families, nil
+}
+
+func (m *memorySessionStore) GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, exists := m.users[userID]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return &user, nil
+}
+
+func (m *memorySessionStore) family(familyID uuid.UUID) []UserSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []UserSession
+	for _, session := range m.sessions {
+		if session.FamilyID == familyID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// recordingRevoker records the sessions and tokens it revokes
+type recordingRevoker struct {
+	mu       sync.Mutex
+	sessions map[string]time.Duration
+	tokens   []string
+}
+
+func (r *recordingRevoker) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, jti)
+	return nil
+}
+
+func (r *recordingRevoker) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[sessionID] = ttl
+	return nil
+}
+
+func (r *recordingRevoker) RevokeAPIKey(ctx context.Context, keyID string) error {
+	return nil
+}
+
+type sessionFixture struct {
+	service  *Service
+	store    *memorySessionStore
+	revoker  *recordingRevoker
+	user     User
+	familyID uuid.UUID
+	token    string
+}
+
+// newSessionFixture signs an active user in with a fresh refresh token family
+func newSessionFixture(t *testing.T) *sessionFixture {
+	store := newMemorySessionStore()
+	revoker := &recordingRevoker{sessions: make(map[string]time.Duration)}
+	service := &Service{
+		config: config.JWTConfig{
+			Secret:             "test-secret",
+			Expiry:             15 * time.Minute,
+			RefreshTokenExpiry: 24 * time.Hour,
+		},
+		logger:      observability.NewLogger(config.ObservabilityConfig{}),
+		sessions:    store,
+		revocations: revoker,
+	}
+
+	user := User{ID: uuid.New(), Email: "trader@example.com", IsActive: true}
+	store.users[user.ID] = user
+
+	token, err := service.generateRefreshToken()
+	require.NoError(t, err)
+	now := time.Now()
+	session := &UserSession{
+		ID:               uuid.New(),
+		UserID:           user.ID,
+		RefreshTokenHash: service.hashToken(token),
+		ExpiresAt:        now.Add(24 * time.Hour),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+	}
+	session.FamilyID = session.ID
+	require.NoError(t, store.CreateSession(context.Background(), session))
+
+	return &sessionFixture{service: service, store: store, revoker: revoker, user: user, familyID: session.FamilyID, token: token}
+}
+
+func TestRefreshToken_Rotation(t *testing.T) {
+	ctx := context.Background()
+	f := newSessionFixture(t)
+
+	resp, err := f.service.RefreshToken(ctx, f.token)
+	require.NoError(t, err)
+	assert.NotEqual(t, f.token, resp.RefreshToken, "the refresh token is rotated")
+	assert.Equal(t, f.user.ID, resp.User.ID)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(resp.AccessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, f.familyID.String(), claims["sid"], "access tokens carry the family as their session")
+
+	family := f.store.family(f.familyID)
+	require.Len(t, family, 2)
+
+	// The new token rotates in turn, staying in the family
+	next, err := f.service.RefreshToken(ctx, resp.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, resp.RefreshToken, next.RefreshToken)
+	assert.Len(t, f.store.family(f.familyID), 3)
+	assert.Empty(t, f.revoker.sessions)
+
+	_, err = f.service.RefreshToken(ctx, "unknown-token")
+	assert.EqualError(t, err, "invalid refresh token")
+	assert.Empty(t, f.revoker.sessions)
+}
+
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	f := newSessionFixture(t)
+
+	rotated, err := f.service.RefreshToken(ctx, f.token)
+	require.NoError(t, err)
+
+	// Presenting the rotated token again ends the whole session
+	_, err = f.service.RefreshToken(ctx, f.token)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Empty(t, f.store.family(f.familyID), "every refresh token of the family is deleted")
+	assert.Equal(t, map[string]time.Duration{f.familyID.String(): 15 * time.Minute}, f.revoker.sessions,
+		"access tokens of the family are revoked for their lifetime")
+
+	// Including the token the legitimate holder was given
+	_, err = f.service.RefreshToken(ctx, rotated.RefreshToken)
+	assert.EqualError(t, err, "invalid refresh token")
+}
+
+// racingSessionStore lets a concurrent request rotate each session just before this one does
+type racingSessionStore struct {
+	*memorySessionStore
+}
+
+func (r racingSessionStore) MarkSessionRotated(ctx context.Context, sessionID uuid.UUID, rotatedAt time.Time) (bool, error) {
+	if _, err := r.memorySessionStore.MarkSessionRotated(ctx, sessionID, rotatedAt); err != nil {
+		return false, err
+	}
+	return r.memorySessionStore.MarkSessionRotated(ctx, sessionID, rotatedAt)
+}
+
+func TestRefreshToken_ConcurrentUseCountsAsReuse(t *testing.T) {
+	f := newSessionFixture(t)
+	f.service.sessions = racingSessionStore{f.store}
+
+	_, err := f.service.RefreshToken(context.Background(), f.token)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused, "the request that loses the rotation is treated as reuse")
+	assert.Empty(t, f.store.family(f.familyID))
+	assert.Contains(t, f.revoker.sessions, f.familyID.String())
+}
+
+func TestRefreshToken_DeactivatedUser(t *testing.T) {
+	ctx := context.Background()
+	f := newSessionFixture(t)
+
+	user := f.store.users[f.user.ID]
+	user.IsActive = false
+	f.store.users[f.user.ID] = user
+
+	_, err := f.service.RefreshToken(ctx, f.token)
+	assert.EqualError(t, err, "account is deactivated")
+	assert.Empty(t, f.store.family(f.familyID))
+	assert.Contains(t, f.revoker.sessions, f.familyID.String())
+}
+
+func TestLogout_RevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	f := newSessionFixture(t)
+
+	rotated, err := f.service.RefreshToken(ctx, f.token)
+	require.NoError(t, err)
+
+	require.NoError(t, f.service.Logout(ctx, rotated.RefreshToken))
+	assert.Empty(t, f.store.family(f.familyID))
+	assert.Contains(t, f.revoker.sessions, f.familyID.String())
+
+	require.NoError(t, f.service.Logout(ctx, rotated.RefreshToken), "logging out twice is not an error")
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	ctx := context.Background()
+	f := newSessionFixture(t)
+
+	token, err := f.service.generateAccessToken(&f.user, f.familyID)
+	require.NoError(t, err)
+	require.NoError(t, f.service.RevokeAccessToken(ctx, token))
+	require.Len(t, f.revoker.tokens, 1)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, claims["jti"], f.revoker.tokens[0])
+
+	assert.Error(t, f.service.RevokeAccessToken(ctx, "not-a-jwt"))
+}
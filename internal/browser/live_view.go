@@ -0,0 +1,244 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/google/uuid"
+)
+
+const (
+	// liveViewBuffer is how many events a viewer may fall behind before frames are dropped
+	liveViewBuffer = 32
+	// liveViewStopTimeout bounds stopping a screencast once its last viewer left
+	liveViewStopTimeout = 5 * time.Second
+)
+
+// ErrLiveViewUnavailable is returned when live view is requested without the browser pool,
+// whose sessions are the only ones keeping their page between operations
+var ErrLiveViewUnavailable = errors.New("live view requires the browser pool")
+
+// Live view event types
+const (
+	LiveEventFrame   = "frame"
+	LiveEventConsole = "console"
+	LiveEventClosed  = "closed"
+)
+
+// LiveEvent is a message streamed to the viewers of a session: a JPEG frame of the page, a
+// console message it logged, or the notice that the session closed
+type LiveEvent struct {
+	Type      string    `json:"type"`
+	Data      string    `json:"data,omitempty"`  // base64 JPEG of frame events
+	Level     string    `json:"level,omitempty"` // console events: log, info, warning, error...
+	Text      string    `json:"text,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// liveView fans a session's screencast out to its viewers. Frames are only captured while
+// someone is watching, so unwatched automations don't pay for them.
+type liveView struct {
+	mu      sync.Mutex
+	viewers map[chan LiveEvent]struct{}
+	stop    func() // stops the capture; nil while nobody watches
+}
+
+// WatchSession streams the frames and console messages of one of a user's active sessions
+// at up to fps frames per second, capped at the configured live view rate. The stream ends
+// when ctx is done, or with a closed event when the session closes. Viewers can't act on the
+// session.
+func (s *Service) WatchSession(ctx context.Context, userID, sessionID uuid.UUID, fps int) (<-chan LiveEvent, error) {
+	if s.pool == nil {
+		return nil, ErrLiveViewUnavailable
+	}
+
+	// Other users' sessions are reported as missing rather than forbidden
+	owner, err := s.sessionOwner(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != userID {
+		return nil, ErrSessionNotFound
+	}
+
+	session := s.pool.session(sessionID)
+	if session == nil {
+		if session, err = s.pool.Checkout(ctx, userID, sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	maxFPS := s.config.LiveViewFPS
+	if maxFPS <= 0 {
+		maxFPS = 5
+	}
+	if fps <= 0 || fps > maxFPS {
+		fps = maxFPS
+	}
+
+	captured := make(chan LiveEvent, liveViewBuffer)
+	session.live.join(captured, func() func() {
+		return s.screencast(session.tab, &session.live, maxFPS)
+	})
+
+	events := make(chan LiveEvent, liveViewBuffer)
+	go func() {
+		defer close(events)
+		defer session.live.leave(captured)
+
+		interval := time.Second / time.Duration(fps)
+		var lastFrame time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-session.tab.Done():
+				select {
+				case events <- LiveEvent{Type: LiveEventClosed, Timestamp: time.Now()}:
+				default:
+				}
+				return
+			case event := <-captured:
+				if event.Type == LiveEventFrame {
+					if event.Timestamp.Sub(lastFrame) < interval {
+						continue
+					}
+					lastFrame = event.Timestamp
+				}
+				select {
+				case events <- event:
+				default: // the viewer is behind; drop rather than stall the others
+				}
+			}
+		}
+	}()
+
+	s.logger.Info(ctx, "Browser session live view started", map[string]interface{}{
+		"session_id": sessionID.String(),
+		"user_id":    userID.String(),
+		"fps":        fps,
+	})
+	return events, nil
+}
+
+// join adds a viewer, starting the capture with start when it is the first
+func (v *liveView) join(viewer chan LiveEvent, start func() func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.viewers == nil {
+		v.viewers = make(map[chan LiveEvent]struct{})
+	}
+	v.viewers[viewer] = struct{}{}
+	if v.stop == nil {
+		v.stop = start()
+	}
+}
+
+// leave removes a viewer, stopping the capture when it was the last
+func (v *liveView) leave(viewer chan LiveEvent) {
+	v.mu.Lock()
+	delete(v.viewers, viewer)
+	var stop func()
+	if len(v.viewers) == 0 {
+		stop, v.stop = v.stop, nil
+	}
+	v.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// broadcast sends an event to every viewer that has room for it
+func (v *liveView) broadcast(event LiveEvent) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for viewer := range v.viewers {
+		select {
+		case viewer <- event:
+		default:
+		}
+	}
+}
+
+// startScreencast streams JPEG frames of the page in tab and its console messages to the
+// live view's viewers, and returns the function stopping it. Each frame is acknowledged one
+// frame interval after it arrived, which is what paces Chrome's screencast to fps.
+func (s *Service) startScreencast(tab context.Context, live *liveView, fps int) func() {
+	ctx, cancel := context.WithCancel(tab)
+	interval := time.Second / time.Duration(fps)
+
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventScreencastFrame:
+			live.broadcast(LiveEvent{Type: LiveEventFrame, Data: ev.Data, Timestamp: time.Now()})
+			// Listeners run on the event loop, so commands must be sent from elsewhere
+			go func(frame int64) {
+				select {
+				case <-time.After(interval):
+				case <-ctx.Done():
+					return
+				}
+				_ = chromedp.Run(ctx, page.ScreencastFrameAck(frame))
+			}(ev.SessionID)
+		case *runtime.EventConsoleAPICalled:
+			live.broadcast(LiveEvent{Type: LiveEventConsole, Level: string(ev.Type), Text: consoleText(ev.Args), Timestamp: time.Now()})
+		case *runtime.EventExceptionThrown:
+			text := ev.ExceptionDetails.Text
+			if ev.ExceptionDetails.Exception != nil && ev.ExceptionDetails.Exception.Description != "" {
+				text = ev.ExceptionDetails.Exception.Description
+			}
+			live.broadcast(LiveEvent{Type: LiveEventConsole, Level: "error", Text: text, Timestamp: time.Now()})
+		}
+	})
+
+	quality := s.config.LiveViewQuality
+	if quality <= 0 || quality > 100 {
+		quality = 60
+	}
+	go func() {
+		start := page.StartScreencast().WithFormat(page.ScreencastFormatJpeg).WithQuality(int64(quality))
+		if err := chromedp.Run(ctx, start); err != nil && ctx.Err() == nil {
+			s.logger.Warn(context.Background(), "Failed to start browser screencast", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	return func() {
+		stopCtx, stopCancel := context.WithTimeout(tab, liveViewStopTimeout)
+		defer stopCancel()
+		_ = chromedp.Run(stopCtx, page.StopScreencast())
+		cancel()
+	}
+}
+
+// consoleText joins the arguments of a console call the way DevTools prints them
+func consoleText(args []*runtime.RemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case len(arg.Value) > 0:
+			var text string
+			if err := json.Unmarshal(arg.Value, &text); err == nil {
+				parts = append(parts, text)
+			} else {
+				parts = append(parts, string(arg.Value))
+			}
+		case arg.UnserializableValue != "":
+			parts = append(parts, string(arg.UnserializableValue))
+		default:
+			parts = append(parts, arg.Description)
+		}
+	}
+	return strings.Join(parts, " ")
+}
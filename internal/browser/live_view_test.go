@@ -0,0 +1,153 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScreencast hands the test the live view a capture was started for
+type fakeScreencast struct {
+	mu      sync.Mutex
+	live    *liveView
+	fps     int
+	starts  int
+	stopped int
+}
+
+func (f *fakeScreencast) start(tab context.Context, live *liveView, fps int) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.live, f.fps = live, fps
+	f.starts++
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.stopped++
+	}
+}
+
+func (f *fakeScreencast) counts() (starts, stopped int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.starts, f.stopped
+}
+
+type liveViewFixture struct {
+	service    *Service
+	screencast *fakeScreencast
+	owner      uuid.UUID
+	sessionID  uuid.UUID
+}
+
+func newLiveViewFixture(t *testing.T) *liveViewFixture {
+	cfg := config.BrowserConfig{PoolSize: 2, PoolCheckoutTimeout: time.Second, LiveViewFPS: 5}
+	service := NewService(nil, nil, cfg, observability.NewLogger(config.ObservabilityConfig{}))
+	service.pool.launch = (&fakeBrowsers{}).launch
+	t.Cleanup(service.pool.Close)
+
+	f := &liveViewFixture{service: service, screencast: &fakeScreencast{}, owner: uuid.New(), sessionID: uuid.New()}
+	service.screencast = f.screencast.start
+	service.sessionOwner = func(ctx context.Context, sessionID uuid.UUID) (uuid.UUID, error) {
+		if sessionID != f.sessionID {
+			return uuid.Nil, ErrSessionNotFound
+		}
+		return f.owner, nil
+	}
+	return f
+}
+
+func receive(t *testing.T, events <-chan LiveEvent) LiveEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "the stream ended")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no live view event")
+		return LiveEvent{}
+	}
+}
+
+func TestWatchSession_OwnerOnly(t *testing.T) {
+	ctx := context.Background()
+	f := newLiveViewFixture(t)
+
+	_, err := f.service.WatchSession(ctx, uuid.New(), f.sessionID, 0)
+	assert.ErrorIs(t, err, ErrSessionNotFound, "other users' sessions are not found")
+	_, err = f.service.WatchSession(ctx, f.owner, uuid.New(), 0)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	starts, _ := f.screencast.counts()
+	assert.Zero(t, starts)
+	assert.Zero(t, f.service.pool.Stats().InUse, "no browser is checked out for a rejected viewer")
+
+	lookupFailed := errors.New("database unavailable")
+	f.service.sessionOwner = func(ctx context.Context, sessionID uuid.UUID) (uuid.UUID, error) {
+		return uuid.Nil, lookupFailed
+	}
+	_, err = f.service.WatchSession(ctx, f.owner, f.sessionID, 0)
+	assert.ErrorIs(t, err, lookupFailed)
+
+	// Without the pool there is no tab to watch
+	f.service.pool = nil
+	_, err = f.service.WatchSession(ctx, f.owner, f.sessionID, 0)
+	assert.ErrorIs(t, err, ErrLiveViewUnavailable)
+}
+
+func TestWatchSession_Stream(t *testing.T) {
+	f := newLiveViewFixture(t)
+
+	first, stopFirst := context.WithCancel(context.Background())
+	defer stopFirst()
+	fast, err := f.service.WatchSession(first, f.owner, f.sessionID, 100)
+	require.NoError(t, err)
+	slow, err := f.service.WatchSession(context.Background(), f.owner, f.sessionID, 2)
+	require.NoError(t, err)
+
+	// One capture at the configured rate serves every viewer
+	starts, _ := f.screencast.counts()
+	assert.Equal(t, 1, starts)
+	assert.Equal(t, 5, f.screencast.fps)
+	assert.Equal(t, 1, f.service.pool.Stats().InUse)
+
+	// Each viewer gets frames at its own rate, and every console message
+	at := time.Now()
+	f.screencast.live.broadcast(LiveEvent{Type: LiveEventFrame, Data: "1", Timestamp: at})
+	f.screencast.live.broadcast(LiveEvent{Type: LiveEventFrame, Data: "2", Timestamp: at.Add(200 * time.Millisecond)})
+	f.screencast.live.broadcast(LiveEvent{Type: LiveEventConsole, Level: "error", Text: "boom", Timestamp: at.Add(300 * time.Millisecond)})
+	f.screencast.live.broadcast(LiveEvent{Type: LiveEventFrame, Data: "3", Timestamp: at.Add(600 * time.Millisecond)})
+
+	assert.Equal(t, "1", receive(t, fast).Data)
+	assert.Equal(t, "2", receive(t, fast).Data, "requested rates are capped at the configured rate")
+	assert.Equal(t, "boom", receive(t, fast).Text)
+	assert.Equal(t, "3", receive(t, fast).Data)
+
+	assert.Equal(t, "1", receive(t, slow).Data)
+	assert.Equal(t, LiveEventConsole, receive(t, slow).Type, "frame 2 comes too soon at 2 fps")
+	assert.Equal(t, "3", receive(t, slow).Data)
+
+	// The capture runs until the last viewer leaves
+	stopFirst()
+	_, open := <-fast
+	assert.False(t, open)
+	_, stopped := f.screencast.counts()
+	assert.Zero(t, stopped)
+
+	// Closing the session ends the remaining streams with a closed event
+	f.service.pool.Checkin(f.sessionID)
+	assert.Equal(t, LiveEventClosed, receive(t, slow).Type)
+	_, open = <-slow
+	assert.False(t, open)
+	require.Eventually(t, func() bool {
+		_, stopped := f.screencast.counts()
+		return stopped == 1
+	}, time.Second, 5*time.Millisecond)
+}
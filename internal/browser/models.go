@@ -8,15 +8,31 @@ import (
 
 // BrowserSession represents a browser session
 type BrowserSession struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	UserID      uuid.UUID `json:"user_id" db:"user_id"`
-	SessionName string    `json:"session_name" db:"session_name"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Tabs        []Tab     `json:"tabs,omitempty"`
+	ID                uuid.UUID     `json:"id" db:"id"`
+	UserID            uuid.UUID     `json:"user_id" db:"user_id"`
+	SessionName       string        `json:"session_name" db:"session_name"`
+	IsActive          bool          `json:"is_active" db:"is_active"`
+	Status            SessionStatus `json:"status" db:"status"`
+	TerminationReason string        `json:"termination_reason,omitempty" db:"termination_reason"`
+	Record            bool          `json:"record" db:"record"`
+	CurrentURL        string        `json:"current_url,omitempty"`
+	PageTitle         string        `json:"page_title,omitempty"`
+	LastActivityAt    time.Time     `json:"last_activity_at" db:"last_activity_at"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
+	Tabs              []Tab         `json:"tabs,omitempty"`
 }
 
+// SessionStatus represents the lifecycle status of a browser session
+type SessionStatus string
+
+const (
+	SessionStatusActive SessionStatus = "active"
+	SessionStatusClosed SessionStatus = "closed"
+	// SessionStatusTerminated marks sessions that crashed or were reaped after a timeout
+	SessionStatusTerminated SessionStatus = "terminated"
+)
+
 // Tab represents a browser tab
 type Tab struct {
 	ID        uuid.UUID `json:"id" db:"id"`
@@ -35,6 +51,7 @@ type NavigateRequest struct {
 	Timeout         int               `json:"timeout,omitempty"`
 	Headers         map[string]string `json:"headers,omitempty"`
 	UserAgent       string            `json:"user_agent,omitempty"`
+	WaitFor         []WaitCondition   `json:"wait_for,omitempty"` // Checked in order after the page loads
 }
 
 // NavigateResponse represents a navigation response
@@ -45,15 +62,18 @@ type NavigateResponse struct {
 	StatusCode int                    `json:"status_code,omitempty"`
 	LoadTime   time.Duration          `json:"load_time"`
 	Screenshot string                 `json:"screenshot,omitempty"`
+	Waits      []WaitResult           `json:"waits,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	Error      string                 `json:"error,omitempty"`
 }
 
 // InteractRequest represents a page interaction request
 type InteractRequest struct {
-	Actions     []Action `json:"actions" validate:"required"`
-	WaitBetween int      `json:"wait_between,omitempty"` // milliseconds
-	Screenshot  bool     `json:"screenshot,omitempty"`
+	Actions     []Action        `json:"actions" validate:"required"`
+	WaitBetween int             `json:"wait_between,omitempty"` // milliseconds
+	Screenshot  bool            `json:"screenshot,omitempty"`
+	WaitFor     []WaitCondition `json:"wait_for,omitempty"` // Checked after each action
+	Retries     int             `json:"retries,omitempty"`  // Extra attempts for a failing action
 }
 
 // Action represents a single browser action
@@ -99,14 +119,18 @@ type ActionResult struct {
 	Data     map[string]interface{} `json:"data,omitempty"`
 	Error    string                 `json:"error,omitempty"`
 	Duration time.Duration          `json:"duration"`
+	Attempts int                    `json:"attempts"`
+	Waits    []WaitResult           `json:"waits,omitempty"`
 }
 
 // ExtractRequest represents a content extraction request
 type ExtractRequest struct {
-	Selectors []string       `json:"selectors,omitempty"`
-	DataType  string         `json:"data_type,omitempty"` // text, links, images, tables, forms
-	Schema    string         `json:"schema,omitempty"`    // JSON schema for structured extraction
-	Options   ExtractOptions `json:"options,omitempty"`
+	Selectors  []string       `json:"selectors,omitempty"`
+	DataType   string         `json:"data_type,omitempty"` // text, links, images, tables, forms
+	Schema     string         `json:"schema,omitempty"`    // JSON schema for structured extraction
+	Options    ExtractOptions `json:"options,omitempty"`
+	TemplateID *uuid.UUID     `json:"template_id,omitempty"` // Extract the fields of a saved template
+	URL        string         `json:"url,omitempty"`         // Page to start template extraction from
 }
 
 // ExtractOptions represents options for content extraction
@@ -124,9 +148,62 @@ type ExtractResponse struct {
 	Data       map[string]interface{} `json:"data"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	Screenshot string                 `json:"screenshot,omitempty"`
+	Warnings   []string               `json:"warnings,omitempty"`
 	Error      string                 `json:"error,omitempty"`
 }
 
+// ExtractionTemplate represents a saved set of fields to extract from a page
+type ExtractionTemplate struct {
+	ID         uuid.UUID           `json:"id" db:"id"`
+	UserID     uuid.UUID           `json:"user_id" db:"user_id"`
+	Name       string              `json:"name" db:"name"`
+	Fields     []TemplateField     `json:"fields" db:"fields"`
+	Pagination *TemplatePagination `json:"pagination,omitempty" db:"pagination"`
+	CreatedAt  time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// TemplateField represents a named field extracted with a CSS or XPath selector
+type TemplateField struct {
+	Name         string            `json:"name"`
+	Selector     string            `json:"selector"`
+	SelectorType SelectorType      `json:"selector_type,omitempty"` // css (default) or xpath
+	Type         TemplateFieldType `json:"type"`
+	Attribute    string            `json:"attribute,omitempty"` // For attribute fields
+}
+
+// TemplatePagination describes how to follow a multi-page listing
+type TemplatePagination struct {
+	NextSelector string       `json:"next_selector"`
+	SelectorType SelectorType `json:"selector_type,omitempty"`
+	MaxPages     int          `json:"max_pages"`
+}
+
+// SelectorType represents the syntax of a template selector
+type SelectorType string
+
+const (
+	SelectorTypeCSS   SelectorType = "css"
+	SelectorTypeXPath SelectorType = "xpath"
+)
+
+// TemplateFieldType represents the JSON type a template field is extracted as
+type TemplateFieldType string
+
+const (
+	TemplateFieldText      TemplateFieldType = "text"
+	TemplateFieldAttribute TemplateFieldType = "attribute"
+	TemplateFieldNumber    TemplateFieldType = "number"
+	TemplateFieldList      TemplateFieldType = "list" // Text of every matching element
+)
+
+// ExtractionTemplateRequest represents a request to create or update an extraction template
+type ExtractionTemplateRequest struct {
+	Name       string              `json:"name" validate:"required"`
+	Fields     []TemplateField     `json:"fields" validate:"required"`
+	Pagination *TemplatePagination `json:"pagination,omitempty"`
+}
+
 // ScreenshotRequest represents a screenshot request
 type ScreenshotRequest struct {
 	Selector string `json:"selector,omitempty"`  // CSS selector for element screenshot
@@ -166,8 +243,28 @@ const (
 	WaitTypeNavigation  WaitType = "navigation"
 	WaitTypeTimeout     WaitType = "timeout"
 	WaitTypeNetworkIdle WaitType = "network_idle"
+	WaitTypeURL         WaitType = "url"   // The page URL matches a regular expression
+	WaitTypeDelay       WaitType = "delay" // A fixed delay
 )
 
+// WaitCondition represents a condition to wait for after navigating or acting
+type WaitCondition struct {
+	Type     WaitType `json:"type" validate:"required"`
+	Selector string   `json:"selector,omitempty"` // For selector waits
+	Text     string   `json:"text,omitempty"`     // For text waits
+	URL      string   `json:"url,omitempty"`      // Regular expression for url waits
+	Delay    int      `json:"delay,omitempty"`    // milliseconds, for delay waits
+	Timeout  int      `json:"timeout,omitempty"`  // milliseconds
+}
+
+// WaitResult represents how a wait condition was met
+type WaitResult struct {
+	Condition WaitCondition `json:"condition"`
+	Success   bool          `json:"success"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
 // WaitResponse represents a wait response
 type WaitResponse struct {
 	Success   bool                   `json:"success"`
@@ -240,6 +337,7 @@ type SessionCreateRequest struct {
 	SessionName string   `json:"session_name,omitempty"`
 	UserAgent   string   `json:"user_agent,omitempty"`
 	Viewport    Viewport `json:"viewport,omitempty"`
+	Record      bool     `json:"record,omitempty"` // Record each step with a screenshot
 }
 
 // Viewport represents browser viewport settings
@@ -255,10 +353,11 @@ type SessionCreateResponse struct {
 
 // SessionListRequest represents a request to list browser sessions
 type SessionListRequest struct {
-	UserID   uuid.UUID `json:"user_id"`
-	IsActive *bool     `json:"is_active,omitempty"`
-	Limit    int       `json:"limit,omitempty"`
-	Offset   int       `json:"offset,omitempty"`
+	UserID   uuid.UUID     `json:"user_id"`
+	IsActive *bool         `json:"is_active,omitempty"`
+	Status   SessionStatus `json:"status,omitempty"` // "closed" also matches terminated sessions
+	Limit    int           `json:"limit,omitempty"`
+	Offset   int           `json:"offset,omitempty"`
 }
 
 // SessionListResponse represents a response with session list
@@ -268,6 +367,38 @@ type SessionListResponse struct {
 	HasMore  bool             `json:"has_more"`
 }
 
+// RecordingStepType represents the kind of step in a session recording
+type RecordingStepType string
+
+const (
+	RecordingStepNavigate RecordingStepType = "navigate"
+	RecordingStepInteract RecordingStepType = "interact"
+	RecordingStepExtract  RecordingStepType = "extract"
+)
+
+// RecordingStep represents one step of a recorded session
+type RecordingStep struct {
+	Sequence  int                    `json:"sequence"`
+	Type      RecordingStepType      `json:"type"`
+	URL       string                 `json:"url,omitempty"`
+	Title     string                 `json:"title,omitempty"`
+	Action    *Action                `json:"action,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Success   bool                   `json:"success"`
+	Error     string                 `json:"error,omitempty"`
+	Duration  time.Duration          `json:"duration"`
+	Thumbnail string                 `json:"thumbnail,omitempty"` // Base64 encoded JPEG
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// SessionRecording represents the ordered steps of a recorded session
+type SessionRecording struct {
+	SessionID      uuid.UUID       `json:"session_id"`
+	Steps          []RecordingStep `json:"steps"`
+	ThumbnailBytes int             `json:"thumbnail_bytes"`
+	Truncated      bool            `json:"truncated"` // Steps or thumbnails were dropped by the size caps
+}
+
 // TabCreateRequest represents a request to create a new tab
 type TabCreateRequest struct {
 	SessionID uuid.UUID `json:"session_id"`
@@ -0,0 +1,381 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/chromedp/chromedp"
+	"github.com/google/uuid"
+)
+
+const (
+	// PoolRetryAfter is the suggested delay before retrying when the pool is exhausted
+	PoolRetryAfter = 5 * time.Second
+	// poolMaintenanceInterval is how often idle browsers are recycled and the pool re-warmed
+	poolMaintenanceInterval = time.Minute
+)
+
+var (
+	// ErrPoolExhausted is returned when no pooled browser became free in time
+	ErrPoolExhausted = errors.New("no browser available, try again later")
+	// ErrSessionLimitReached is returned when a user already holds the maximum number of sessions
+	ErrSessionLimitReached = errors.New("concurrent browser session limit reached")
+)
+
+// PoolStats represents the state of the browser pool
+type PoolStats struct {
+	Size              int     `json:"size"`
+	Available         int     `json:"available"`
+	InUse             int     `json:"in_use"`
+	Launching         int     `json:"launching"`
+	Recycled          int64   `json:"recycled"`
+	Rejected          int64   `json:"rejected"`
+	Checkouts         int64   `json:"checkouts"`
+	AvgCheckoutWaitMs float64 `json:"avg_checkout_wait_ms"`
+}
+
+// pooledBrowser is a started headless browser
+type pooledBrowser struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	newTab    func() (context.Context, context.CancelFunc) // opens a tab in a fresh browser context
+	createdAt time.Time
+	uses      int
+}
+
+func (b *pooledBrowser) alive() bool {
+	return b.ctx.Err() == nil
+}
+
+// pooledSession is a session's isolated browser context on a pooled browser. Its tab keeps
+// the page between the session's operations.
+type pooledSession struct {
+	mu       sync.Mutex // serializes the session's operations
+	userID   uuid.UUID
+	browser  *pooledBrowser
+	tab      context.Context
+	closeTab context.CancelFunc
+	live     liveView // viewers of the tab's screencast
+}
+
+// BrowserPool keeps headless browsers started so sessions don't wait for a browser launch.
+// Each session checks out a browser and gets its own browser context on it, so cookies and
+// storage don't leak between sessions sharing a browser over time.
+type BrowserPool struct {
+	config config.BrowserConfig
+	logger *observability.Logger
+	opts   []chromedp.ExecAllocatorOption
+	launch func() (*pooledBrowser, error)
+
+	mu           sync.Mutex
+	idle         []*pooledBrowser
+	sessions     map[uuid.UUID]*pooledSession
+	userSessions map[uuid.UUID]int
+	launching    int
+	freed        chan struct{} // closed and replaced whenever a browser is returned
+	closed       bool
+
+	recycled     int64
+	rejected     int64
+	checkouts    int64
+	checkoutWait time.Duration
+}
+
+// NewBrowserPool creates a pool of up to cfg.PoolSize browsers launched with opts
+func NewBrowserPool(cfg config.BrowserConfig, logger *observability.Logger, opts ...chromedp.ExecAllocatorOption) *BrowserPool {
+	p := &BrowserPool{
+		config:       cfg,
+		logger:       logger,
+		opts:         opts,
+		sessions:     make(map[uuid.UUID]*pooledSession),
+		userSessions: make(map[uuid.UUID]int),
+		freed:        make(chan struct{}),
+	}
+	p.launch = p.launchChrome
+	return p
+}
+
+// Start warms the pool and recycles aged idle browsers until ctx is done, then closes all
+// browsers
+func (p *BrowserPool) Start(ctx context.Context) {
+	p.warm()
+	go func() {
+		ticker := time.NewTicker(poolMaintenanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				p.Close()
+				return
+			case <-ticker.C:
+				p.recycleIdle()
+				p.warm()
+			}
+		}
+	}()
+}
+
+// Checkout assigns a browser to a session. It waits up to the configured checkout timeout
+// for a browser to become free.
+func (p *BrowserPool) Checkout(ctx context.Context, userID, sessionID uuid.UUID) (*pooledSession, error) {
+	start := time.Now()
+	timer := time.NewTimer(p.config.PoolCheckoutTimeout)
+	defer timer.Stop()
+
+	p.mu.Lock()
+	if session, exists := p.sessions[sessionID]; exists {
+		p.mu.Unlock()
+		if session.userID != userID {
+			return nil, ErrSessionNotFound
+		}
+		return session, nil
+	}
+
+	var browser *pooledBrowser
+	for browser == nil {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolExhausted
+		}
+		if p.config.MaxSessionsPerUser > 0 && p.userSessions[userID] >= p.config.MaxSessionsPerUser {
+			p.rejected++
+			p.mu.Unlock()
+			return nil, ErrSessionLimitReached
+		}
+
+		if n := len(p.idle); n > 0 {
+			browser = p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			if !browser.alive() {
+				p.recycled++
+				go browser.cancel()
+				browser = nil
+			}
+			continue
+		}
+
+		if len(p.sessions)+p.launching < p.config.PoolSize {
+			p.launching++
+			p.mu.Unlock()
+			launched, err := p.launch()
+			p.mu.Lock()
+			p.launching--
+			if err != nil {
+				p.mu.Unlock()
+				return nil, err
+			}
+			browser = launched
+			continue
+		}
+
+		freed := p.freed
+		p.mu.Unlock()
+		select {
+		case <-freed:
+		case <-timer.C:
+			p.mu.Lock()
+			p.rejected++
+			p.mu.Unlock()
+			return nil, ErrPoolExhausted
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		p.mu.Lock()
+	}
+
+	tab, closeTab := browser.newTab()
+	session := &pooledSession{
+		userID:   userID,
+		browser:  browser,
+		tab:      tab,
+		closeTab: closeTab,
+	}
+	browser.uses++
+	p.sessions[sessionID] = session
+	p.userSessions[userID]++
+	p.checkouts++
+	p.checkoutWait += time.Since(start)
+	p.mu.Unlock()
+
+	go p.warm()
+	return session, nil
+}
+
+// Checkin returns a session's browser to the pool, discarding the session's browser context.
+// Browsers past their maximum uses or age are recycled.
+func (p *BrowserPool) Checkin(sessionID uuid.UUID) {
+	p.mu.Lock()
+	session, exists := p.sessions[sessionID]
+	if !exists {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.sessions, sessionID)
+	if p.userSessions[session.userID]--; p.userSessions[session.userID] <= 0 {
+		delete(p.userSessions, session.userID)
+	}
+
+	browser := session.browser
+	retire := p.closed || p.expired(browser)
+	if retire {
+		p.recycled++
+	} else {
+		p.idle = append(p.idle, browser)
+	}
+	close(p.freed)
+	p.freed = make(chan struct{})
+	p.mu.Unlock()
+
+	session.closeTab()
+	if retire {
+		browser.cancel()
+		go p.warm()
+	}
+}
+
+// session returns the pooled session of sessionID, dropping it if its browser died
+func (p *BrowserPool) session(sessionID uuid.UUID) *pooledSession {
+	p.mu.Lock()
+	session := p.sessions[sessionID]
+	p.mu.Unlock()
+
+	if session != nil && !session.browser.alive() {
+		p.Checkin(sessionID)
+		return nil
+	}
+	return session
+}
+
+// Stats returns the current pool counters
+func (p *BrowserPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := PoolStats{
+		Size:      p.config.PoolSize,
+		Available: len(p.idle),
+		InUse:     len(p.sessions),
+		Launching: p.launching,
+		Recycled:  p.recycled,
+		Rejected:  p.rejected,
+		Checkouts: p.checkouts,
+	}
+	if p.checkouts > 0 {
+		stats.AvgCheckoutWaitMs = float64(p.checkoutWait.Microseconds()) / float64(p.checkouts) / 1000
+	}
+	return stats
+}
+
+// Close stops all browsers; later checkouts fail
+func (p *BrowserPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	browsers := p.idle
+	p.idle = nil
+	for id, session := range p.sessions {
+		browsers = append(browsers, session.browser)
+		delete(p.sessions, id)
+	}
+	p.userSessions = make(map[uuid.UUID]int)
+	p.mu.Unlock()
+
+	for _, browser := range browsers {
+		browser.cancel()
+	}
+}
+
+// warm launches browsers until PoolWarm are idle, within the pool size
+func (p *BrowserPool) warm() {
+	for {
+		p.mu.Lock()
+		if p.closed || len(p.idle)+p.launching >= p.config.PoolWarm ||
+			len(p.idle)+len(p.sessions)+p.launching >= p.config.PoolSize {
+			p.mu.Unlock()
+			return
+		}
+		p.launching++
+		p.mu.Unlock()
+
+		browser, err := p.launch()
+
+		p.mu.Lock()
+		p.launching--
+		if err == nil && !p.closed {
+			p.idle = append(p.idle, browser)
+			close(p.freed)
+			p.freed = make(chan struct{})
+		}
+		closed := p.closed
+		p.mu.Unlock()
+
+		if err != nil {
+			p.logger.Warn(context.Background(), "Failed to warm browser pool", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		if closed {
+			browser.cancel()
+			return
+		}
+	}
+}
+
+// recycleIdle stops idle browsers that are past their maximum age or died
+func (p *BrowserPool) recycleIdle() {
+	p.mu.Lock()
+	var retired []*pooledBrowser
+	idle := p.idle[:0]
+	for _, browser := range p.idle {
+		if p.expired(browser) {
+			retired = append(retired, browser)
+			continue
+		}
+		idle = append(idle, browser)
+	}
+	p.idle = idle
+	p.recycled += int64(len(retired))
+	p.mu.Unlock()
+
+	for _, browser := range retired {
+		browser.cancel()
+	}
+}
+
+// expired reports whether a browser should be recycled rather than reused
+func (p *BrowserPool) expired(browser *pooledBrowser) bool {
+	if !browser.alive() {
+		return true
+	}
+	if p.config.InstanceMaxUses > 0 && browser.uses >= p.config.InstanceMaxUses {
+		return true
+	}
+	return p.config.InstanceMaxAge > 0 && time.Since(browser.createdAt) >= p.config.InstanceMaxAge
+}
+
+// launchChrome starts a headless browser
+func (p *BrowserPool) launchChrome() (*pooledBrowser, error) {
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), p.opts...)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(browserCtx); err != nil {
+		cancelBrowser()
+		cancelAlloc()
+		return nil, err
+	}
+	return &pooledBrowser{
+		ctx: browserCtx,
+		cancel: func() {
+			cancelBrowser()
+			cancelAlloc()
+		},
+		newTab: func() (context.Context, context.CancelFunc) {
+			return chromedp.NewContext(browserCtx, chromedp.WithNewBrowserContext())
+		},
+		createdAt: time.Now(),
+	}, nil
+}
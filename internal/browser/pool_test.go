@@ -0,0 +1,238 @@
+package browser
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrowsers launches browsers that are plain contexts, so the pool runs without Chrome
+type fakeBrowsers struct {
+	launched atomic.Int32
+}
+
+func (f *fakeBrowsers) launch() (*pooledBrowser, error) {
+	f.launched.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pooledBrowser{
+		ctx:    ctx,
+		cancel: cancel,
+		newTab: func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(ctx)
+		},
+		createdAt: time.Now(),
+	}, nil
+}
+
+func newTestPool(t *testing.T, cfg config.BrowserConfig) (*BrowserPool, *fakeBrowsers) {
+	if cfg.PoolCheckoutTimeout == 0 {
+		cfg.PoolCheckoutTimeout = 20 * time.Millisecond
+	}
+	pool := NewBrowserPool(cfg, observability.NewLogger(config.ObservabilityConfig{}))
+	browsers := &fakeBrowsers{}
+	pool.launch = browsers.launch
+	t.Cleanup(pool.Close)
+	return pool, browsers
+}
+
+func TestBrowserPool_CheckoutCheckin(t *testing.T) {
+	ctx := context.Background()
+	pool, browsers := newTestPool(t, config.BrowserConfig{PoolSize: 2})
+	alice, bob := uuid.New(), uuid.New()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	session, err := pool.Checkout(ctx, alice, first)
+	require.NoError(t, err)
+	_, err = pool.Checkout(ctx, bob, second)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), browsers.launched.Load())
+
+	// Checking a session out again returns its browser, but only to its owner
+	again, err := pool.Checkout(ctx, alice, first)
+	require.NoError(t, err)
+	assert.Same(t, session, again)
+	_, err = pool.Checkout(ctx, bob, first)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	// Every browser is in use
+	_, err = pool.Checkout(ctx, alice, third)
+	assert.ErrorIs(t, err, ErrPoolExhausted)
+	stats := pool.Stats()
+	assert.Equal(t, 2, stats.InUse)
+	assert.Equal(t, 0, stats.Available)
+	assert.Equal(t, int64(1), stats.Rejected)
+
+	// Checkin closes the session's tab and frees its browser for the next session
+	pool.Checkin(first)
+	assert.Error(t, session.tab.Err(), "the session's browser context is discarded")
+	assert.NoError(t, session.browser.ctx.Err(), "the browser itself stays up")
+	assert.Equal(t, 1, pool.Stats().Available)
+
+	reused, err := pool.Checkout(ctx, alice, third)
+	require.NoError(t, err)
+	assert.Same(t, session.browser, reused.browser)
+	assert.Equal(t, 2, reused.browser.uses)
+	assert.NotEqual(t, session.tab, reused.tab, "each session gets its own browser context")
+	assert.Equal(t, int32(2), browsers.launched.Load())
+	assert.Equal(t, int64(3), pool.Stats().Checkouts)
+
+	pool.Checkin(uuid.New()) // unknown sessions are ignored
+}
+
+func TestBrowserPool_CheckoutWaitsForCheckin(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := newTestPool(t, config.BrowserConfig{PoolSize: 1, PoolCheckoutTimeout: 5 * time.Second})
+	user := uuid.New()
+	held := uuid.New()
+
+	_, err := pool.Checkout(ctx, user, held)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pool.Checkout(ctx, user, uuid.New())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("checkout returned before a browser was free: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	pool.Checkin(held)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("checkout did not get the returned browser")
+	}
+
+	// A waiting checkout gives up with its context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = pool.Checkout(cancelled, user, uuid.New())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBrowserPool_SessionsPerUser(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := newTestPool(t, config.BrowserConfig{PoolSize: 5, MaxSessionsPerUser: 2})
+	alice, bob := uuid.New(), uuid.New()
+
+	first := uuid.New()
+	for _, sessionID := range []uuid.UUID{first, uuid.New()} {
+		_, err := pool.Checkout(ctx, alice, sessionID)
+		require.NoError(t, err)
+	}
+	_, err := pool.Checkout(ctx, alice, uuid.New())
+	assert.ErrorIs(t, err, ErrSessionLimitReached)
+	assert.Equal(t, int64(1), pool.Stats().Rejected)
+
+	// The limit is per user, and a checkin makes room again
+	_, err = pool.Checkout(ctx, bob, uuid.New())
+	assert.NoError(t, err)
+	pool.Checkin(first)
+	_, err = pool.Checkout(ctx, alice, uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pool.Stats().InUse)
+}
+
+func TestBrowserPool_Recycling(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("maximum uses", func(t *testing.T) {
+		pool, browsers := newTestPool(t, config.BrowserConfig{PoolSize: 1, InstanceMaxUses: 2})
+		user := uuid.New()
+
+		var browser *pooledBrowser
+		for i := 0; i < 2; i++ {
+			sessionID := uuid.New()
+			session, err := pool.Checkout(ctx, user, sessionID)
+			require.NoError(t, err)
+			browser = session.browser
+			pool.Checkin(sessionID)
+		}
+		assert.Error(t, browser.ctx.Err(), "the browser is stopped after its last use")
+		assert.Equal(t, int64(1), pool.Stats().Recycled)
+		assert.Equal(t, 0, pool.Stats().Available)
+
+		session, err := pool.Checkout(ctx, user, uuid.New())
+		require.NoError(t, err)
+		assert.NotSame(t, browser, session.browser)
+		assert.Equal(t, int32(2), browsers.launched.Load())
+	})
+
+	t.Run("idle eviction by age", func(t *testing.T) {
+		pool, _ := newTestPool(t, config.BrowserConfig{PoolSize: 2, InstanceMaxAge: time.Hour})
+		user := uuid.New()
+		aged, fresh := uuid.New(), uuid.New()
+
+		agedSession, err := pool.Checkout(ctx, user, aged)
+		require.NoError(t, err)
+		freshSession, err := pool.Checkout(ctx, user, fresh)
+		require.NoError(t, err)
+		pool.Checkin(aged)
+		pool.Checkin(fresh)
+		require.Equal(t, 2, pool.Stats().Available)
+
+		pool.mu.Lock()
+		agedSession.browser.createdAt = time.Now().Add(-2 * time.Hour)
+		pool.mu.Unlock()
+
+		pool.recycleIdle()
+		assert.Error(t, agedSession.browser.ctx.Err())
+		assert.NoError(t, freshSession.browser.ctx.Err())
+		assert.Equal(t, 1, pool.Stats().Available)
+		assert.Equal(t, int64(1), pool.Stats().Recycled)
+	})
+
+	t.Run("dead browsers", func(t *testing.T) {
+		pool, browsers := newTestPool(t, config.BrowserConfig{PoolSize: 1})
+		user := uuid.New()
+		sessionID := uuid.New()
+
+		session, err := pool.Checkout(ctx, user, sessionID)
+		require.NoError(t, err)
+		session.browser.cancel()
+
+		// The session is dropped once its browser is found dead, and the browser discarded
+		assert.Nil(t, pool.session(sessionID))
+		assert.Equal(t, 0, pool.Stats().InUse)
+		assert.Equal(t, 0, pool.Stats().Available)
+
+		replacement, err := pool.Checkout(ctx, user, sessionID)
+		require.NoError(t, err)
+		assert.NoError(t, replacement.browser.ctx.Err())
+		assert.Equal(t, int32(2), browsers.launched.Load())
+	})
+}
+
+func TestBrowserPool_WarmAndClose(t *testing.T) {
+	pool, browsers := newTestPool(t, config.BrowserConfig{PoolSize: 3, PoolWarm: 2})
+
+	pool.warm()
+	assert.Equal(t, 2, pool.Stats().Available)
+	assert.Equal(t, int32(2), browsers.launched.Load())
+
+	session, err := pool.Checkout(context.Background(), uuid.New(), uuid.New())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return pool.Stats().Available == 2 }, time.Second, 5*time.Millisecond,
+		"checkouts re-warm the pool within its size")
+
+	pool.warm()
+	assert.Equal(t, int32(3), browsers.launched.Load(), "warming never exceeds the pool size")
+
+	pool.Close()
+	assert.Error(t, session.browser.ctx.Err())
+	_, err = pool.Checkout(context.Background(), uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, ErrPoolExhausted)
+	assert.Equal(t, 0, pool.Stats().InUse)
+}
@@ -0,0 +1,229 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordingThumbnailWidth is the width screenshots are scaled down to in recordings
+const recordingThumbnailWidth = 320
+
+var (
+	// ErrSessionNotFound is returned for sessions that don't exist or belong to another user
+	ErrSessionNotFound = errors.New("browser session not found")
+	// ErrRecordingDisabled is returned for sessions created without recording
+	ErrRecordingDisabled = errors.New("browser session is not recorded")
+)
+
+// isRecorded reports whether a session records its steps
+func (s *Service) isRecorded(ctx context.Context, sessionID uuid.UUID) bool {
+	var record bool
+	if err := s.db.QueryRowContext(ctx, `SELECT record FROM browser_sessions WHERE id = $1`, sessionID).Scan(&record); err != nil {
+		return false
+	}
+	return record
+}
+
+// recordStep appends a step to a session's recording with a thumbnail of screenshot. Failing
+// to record never fails the step itself.
+func (s *Service) recordStep(ctx context.Context, sessionID uuid.UUID, step RecordingStep, screenshot []byte) {
+	if err := s.appendRecordingStep(ctx, sessionID, step, screenshot); err != nil {
+		s.logger.Warn(ctx, "Failed to record session step", map[string]interface{}{
+			"session_id": sessionID.String(),
+			"step_type":  string(step.Type),
+			"error":      err.Error(),
+		})
+	}
+}
+
+// appendRecordingStep stores a step unless the recording has reached its step cap. The
+// thumbnail is omitted once the recording's thumbnails reach the byte cap.
+func (s *Service) appendRecordingStep(ctx context.Context, sessionID uuid.UUID, step RecordingStep, screenshot []byte) error {
+	var steps, thumbnailBytes int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(thumbnail_bytes), 0)
+		FROM browser_session_recording_steps WHERE session_id = $1
+	`, sessionID).Scan(&steps, &thumbnailBytes); err != nil {
+		return err
+	}
+	if s.config.RecordingMaxSteps > 0 && steps >= s.config.RecordingMaxSteps {
+		return s.markRecordingTruncated(ctx, sessionID)
+	}
+
+	truncated := false
+	var thumbnail []byte
+	if len(screenshot) > 0 {
+		var err error
+		if thumbnail, err = makeThumbnail(screenshot, recordingThumbnailWidth); err != nil {
+			return fmt.Errorf("failed to create thumbnail: %w", err)
+		}
+		if s.config.RecordingMaxBytes > 0 && thumbnailBytes+len(thumbnail) > s.config.RecordingMaxBytes {
+			thumbnail = nil
+			truncated = true
+		}
+	}
+
+	var action, data []byte
+	if step.Action != nil {
+		var err error
+		if action, err = json.Marshal(step.Action); err != nil {
+			return err
+		}
+	}
+	if step.Data != nil {
+		var err error
+		if data, err = json.Marshal(step.Data); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO browser_session_recording_steps
+			(id, session_id, sequence, step_type, url, title, action, data, success, error, duration_ms, thumbnail, thumbnail_bytes, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, NULLIF($10, ''), $11, $12, $13, $14)
+	`, uuid.New(), sessionID, steps+1, string(step.Type), step.URL, step.Title, action, data, step.Success, step.Error,
+		step.Duration.Milliseconds(), thumbnail, len(thumbnail), time.Now())
+	if err != nil {
+		return err
+	}
+
+	if truncated {
+		return s.markRecordingTruncated(ctx, sessionID)
+	}
+	return nil
+}
+
+func (s *Service) markRecordingTruncated(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE browser_sessions SET recording_truncated = true WHERE id = $1`, sessionID)
+	return err
+}
+
+// GetRecording returns the ordered steps of one of a user's recorded sessions
+func (s *Service) GetRecording(ctx context.Context, userID, sessionID uuid.UUID) (*SessionRecording, error) {
+	var record, truncated bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT record, recording_truncated FROM browser_sessions WHERE id = $1 AND user_id = $2
+	`, sessionID, userID).Scan(&record, &truncated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get browser session: %w", err)
+	}
+	if !record {
+		return nil, ErrRecordingDisabled
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT sequence, step_type, url, title, action, data, success, error, duration_ms, thumbnail, created_at
+		FROM browser_session_recording_steps
+		WHERE session_id = $1
+		ORDER BY sequence
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session recording: %w", err)
+	}
+	defer rows.Close()
+
+	recording := &SessionRecording{
+		SessionID: sessionID,
+		Steps:     make([]RecordingStep, 0),
+		Truncated: truncated,
+	}
+	for rows.Next() {
+		var step RecordingStep
+		var stepType string
+		var url, title, stepError sql.NullString
+		var action, data, thumbnail []byte
+		var durationMs int64
+		if err := rows.Scan(&step.Sequence, &stepType, &url, &title, &action, &data, &step.Success, &stepError,
+			&durationMs, &thumbnail, &step.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recording step: %w", err)
+		}
+		step.Type = RecordingStepType(stepType)
+		step.URL = url.String
+		step.Title = title.String
+		step.Error = stepError.String
+		step.Duration = time.Duration(durationMs) * time.Millisecond
+		if len(action) > 0 {
+			step.Action = &Action{}
+			if err := json.Unmarshal(action, step.Action); err != nil {
+				return nil, fmt.Errorf("failed to decode recorded action: %w", err)
+			}
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &step.Data); err != nil {
+				return nil, fmt.Errorf("failed to decode recorded data: %w", err)
+			}
+		}
+		if len(thumbnail) > 0 {
+			step.Thumbnail = base64.StdEncoding.EncodeToString(thumbnail)
+			recording.ThumbnailBytes += len(thumbnail)
+		}
+		recording.Steps = append(recording.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session recording: %w", err)
+	}
+
+	return recording, nil
+}
+
+// pruneRecordings deletes the recordings of a user's sessions that ended before the recent
+// session window, once they no longer appear in session listings
+func (s *Service) pruneRecordings(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		DELETE FROM browser_session_recording_steps
+		WHERE session_id IN (
+			SELECT id FROM browser_sessions
+			WHERE user_id = $1 AND status <> 'active' AND last_activity_at < $2
+		)
+	`
+	result, err := s.db.ExecContext(ctx, query, userID, time.Now().Add(-recentSessionWindow))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// makeThumbnail scales a PNG screenshot down to width and encodes it as JPEG
+func makeThumbnail(screenshot []byte, width int) ([]byte, error) {
+	src, err := png.Decode(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, err
+	}
+
+	var thumbnail image.Image = src
+	bounds := src.Bounds()
+	if bounds.Dx() > width {
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+		// Nearest-neighbour sampling is enough for a thumbnail
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				dst.Set(x, y, src.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+			}
+		}
+		thumbnail = dst
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 70}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
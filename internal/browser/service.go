@@ -2,17 +2,28 @@ package browser
 
 import (
 	"context"
+	"database/sql"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/database"
 	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/chromedp/cdproto/emulation"
 	"github.com/chromedp/chromedp"
 	"github.com/google/uuid"
 )
 
+const (
+	// sessionIdleTimeout is how long an active session may go without activity before it is reaped
+	sessionIdleTimeout = 30 * time.Minute
+	// recentSessionWindow bounds how far back closed sessions are listed
+	recentSessionWindow = 24 * time.Hour
+)
+
 // Service provides browser automation functionality
 type Service struct {
 	db        *database.DB
@@ -20,17 +31,108 @@ type Service struct {
 	config    config.BrowserConfig
 	logger    *observability.Logger
 	instances map[string]*BrowserInstance
+
+	// Started browsers checked out by sessions; nil launches a browser per operation
+	pool *BrowserPool
+
+	sessionOwner func(ctx context.Context, sessionID uuid.UUID) (uuid.UUID, error)
+	screencast   func(tab context.Context, live *liveView, fps int) func()
 }
 
 // NewService creates a new browser service
 func NewService(db *database.DB, redis *database.RedisClient, cfg config.BrowserConfig, logger *observability.Logger) *Service {
-	return &Service{
+	s := &Service{
 		db:        db,
 		redis:     redis,
 		config:    cfg,
 		logger:    logger,
 		instances: make(map[string]*BrowserInstance),
 	}
+	s.sessionOwner = s.activeSessionOwner
+	s.screencast = s.startScreencast
+	if cfg.PoolSize > 0 {
+		s.pool = NewBrowserPool(cfg, logger, s.allocatorOptions()...)
+	}
+	return s
+}
+
+// allocatorOptions returns the flags browsers are launched with
+func (s *Service) allocatorOptions() []chromedp.ExecAllocatorOption {
+	return []chromedp.ExecAllocatorOption{
+		chromedp.Flag("headless", s.config.Headless),
+		chromedp.Flag("disable-gpu", s.config.DisableGPU),
+		chromedp.Flag("no-sandbox", s.config.NoSandbox),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("disable-background-timer-throttling", false),
+		chromedp.Flag("disable-backgrounding-occluded-windows", false),
+		chromedp.Flag("disable-renderer-backgrounding", false),
+	}
+}
+
+// StartPool warms the browser pool and keeps recycling its browsers until ctx is done
+func (s *Service) StartPool(ctx context.Context) {
+	if s.pool != nil {
+		s.pool.Start(ctx)
+	}
+}
+
+// PoolStats returns the browser pool counters, or nil when the pool is disabled
+func (s *Service) PoolStats() *PoolStats {
+	if s.pool == nil {
+		return nil
+	}
+	stats := s.pool.Stats()
+	return &stats
+}
+
+// sessionBrowser returns the browser context to run an operation of a session in, and a
+// function releasing it. With the pool, this is the session's tab on its pooled browser,
+// checked out again if the session lost it, e.g. after a restart; otherwise a browser is
+// launched for the operation.
+func (s *Service) sessionBrowser(ctx context.Context, sessionID uuid.UUID) (context.Context, func(), error) {
+	if s.pool == nil {
+		allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, s.allocatorOptions()...)
+		browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+		return browserCtx, func() {
+			cancelBrowser()
+			cancelAlloc()
+		}, nil
+	}
+
+	session := s.pool.session(sessionID)
+	if session == nil {
+		userID, err := s.sessionOwner(ctx, sessionID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if session, err = s.pool.Checkout(ctx, userID, sessionID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Operations run on the session's tab, which outlives them, so cancel them with ctx
+	session.mu.Lock()
+	opCtx, cancel := context.WithCancel(session.tab)
+	stop := context.AfterFunc(ctx, cancel)
+	return opCtx, func() {
+		stop()
+		cancel()
+		session.mu.Unlock()
+	}, nil
+}
+
+// activeSessionOwner returns the user an active session belongs to, or ErrSessionNotFound when
+// the session doesn't exist or has closed
+func (s *Service) activeSessionOwner(ctx context.Context, sessionID uuid.UUID) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := s.db.QueryRowContext(ctx, `SELECT user_id FROM browser_sessions WHERE id = $1 AND status = 'active'`, sessionID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get browser session: %w", err)
+	}
+	return userID, nil
 }
 
 // CreateSession creates a new browser session
@@ -39,12 +141,22 @@ func (s *Service) CreateSession(ctx context.Context, userID uuid.UUID, req Sessi
 	defer span.End()
 
 	session := &BrowserSession{
-		ID:          uuid.New(),
-		UserID:      userID,
-		SessionName: req.SessionName,
-		IsActive:    true,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:             uuid.New(),
+		UserID:         userID,
+		SessionName:    req.SessionName,
+		IsActive:       true,
+		Record:         req.Record,
+		Status:         SessionStatusActive,
+		LastActivityAt: time.Now(),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	// Check out a started browser up front, so the first step doesn't wait for a launch
+	if s.pool != nil {
+		if _, err := s.pool.Checkout(ctx, userID, session.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	if session.SessionName == "" {
@@ -53,11 +165,14 @@ func (s *Service) CreateSession(ctx context.Context, userID uuid.UUID, req Sessi
 
 	// Insert session into database
 	query := `
-		INSERT INTO browser_sessions (id, user_id, session_name, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO browser_sessions (id, user_id, session_name, is_active, status, record, last_activity_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
-	_, err := s.db.ExecContext(ctx, query, session.ID, session.UserID, session.SessionName, session.IsActive, session.CreatedAt, session.UpdatedAt)
+	_, err := s.db.ExecContext(ctx, query, session.ID, session.UserID, session.SessionName, session.IsActive, session.Status, session.Record, session.LastActivityAt, session.CreatedAt, session.UpdatedAt)
 	if err != nil {
+		if s.pool != nil {
+			s.pool.Checkin(session.ID)
+		}
 		s.logger.Error(ctx, "Failed to create browser session", err)
 		return nil, fmt.Errorf("failed to create browser session: %w", err)
 	}
@@ -70,33 +185,198 @@ func (s *Service) CreateSession(ctx context.Context, userID uuid.UUID, req Sessi
 	return session, nil
 }
 
-// Navigate navigates to a URL in a browser context
-func (s *Service) Navigate(ctx context.Context, sessionID uuid.UUID, req NavigateRequest) (*NavigateResponse, error) {
-	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("browser-service").Start(ctx, "browser.Navigate")
+// ListSessions returns a user's active and recently closed sessions, newest activity first.
+// Active sessions idle past the timeout are reaped and reported as terminated.
+func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID, req SessionListRequest) (*SessionListResponse, error) {
+	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("browser-service").Start(ctx, "browser.ListSessions")
 	defer span.End()
 
-	startTime := time.Now()
+	if reaped, err := s.reapIdleSessions(ctx, userID); err != nil {
+		s.logger.Warn(ctx, "Failed to reap idle sessions", map[string]interface{}{"error": err.Error()})
+	} else if reaped > 0 {
+		s.logger.Info(ctx, "Reaped idle browser sessions", map[string]interface{}{
+			"user_id": userID.String(),
+			"count":   reaped,
+		})
+	}
+	if _, err := s.pruneRecordings(ctx, userID); err != nil {
+		s.logger.Warn(ctx, "Failed to prune session recordings", map[string]interface{}{"error": err.Error()})
+	}
 
-	// Create browser context with options
-	opts := []chromedp.ExecAllocatorOption{
-		chromedp.Flag("headless", s.config.Headless),
-		chromedp.Flag("disable-gpu", s.config.DisableGPU),
-		chromedp.Flag("no-sandbox", s.config.NoSandbox),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("disable-background-timer-throttling", false),
-		chromedp.Flag("disable-backgrounding-occluded-windows", false),
-		chromedp.Flag("disable-renderer-backgrounding", false),
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
 	}
 
-	if req.UserAgent != "" {
-		opts = append(opts, chromedp.UserAgent(req.UserAgent))
+	where := []string{"s.user_id = $1", "(s.status = 'active' OR s.last_activity_at >= $2)"}
+	args := []interface{}{userID, time.Now().Add(-recentSessionWindow)}
+	status := req.Status
+	if status == "" && req.IsActive != nil {
+		status = SessionStatusClosed
+		if *req.IsActive {
+			status = SessionStatusActive
+		}
+	}
+	switch status {
+	case "":
+	case SessionStatusActive, SessionStatusTerminated:
+		where = append(where, "s.status = $3")
+		args = append(args, string(status))
+	case SessionStatusClosed:
+		where = append(where, "s.status IN ('closed', 'terminated')")
+	default:
+		return nil, fmt.Errorf("invalid session status: %s", status)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM browser_sessions s WHERE %s", whereClause)
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count browser sessions: %w", err)
+	}
+
+	listQuery := fmt.Sprintf(`
+		SELECT s.id, s.user_id, s.session_name, s.is_active, s.status, s.termination_reason, s.record,
+		       s.last_activity_at, s.created_at, s.updated_at, t.url, t.title
+		FROM browser_sessions s
+		LEFT JOIN LATERAL (
+			SELECT url, title FROM browser_tabs
+			WHERE session_id = s.id
+			ORDER BY is_active DESC, updated_at DESC
+			LIMIT 1
+		) t ON true
+		WHERE %s
+		ORDER BY s.last_activity_at DESC
+		LIMIT %d OFFSET %d
+	`, whereClause, req.Limit, req.Offset)
+
+	rows, err := s.db.QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list browser sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]BrowserSession, 0)
+	for rows.Next() {
+		var session BrowserSession
+		var sessionName, reason, url, title sql.NullString
+		var lastActivity sql.NullTime
+		if err := rows.Scan(&session.ID, &session.UserID, &sessionName, &session.IsActive, &session.Status, &reason, &session.Record,
+			&lastActivity, &session.CreatedAt, &session.UpdatedAt, &url, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan browser session: %w", err)
+		}
+		session.SessionName = sessionName.String
+		session.TerminationReason = reason.String
+		session.CurrentURL = url.String
+		session.PageTitle = title.String
+		session.LastActivityAt = session.UpdatedAt
+		if lastActivity.Valid {
+			session.LastActivityAt = lastActivity.Time
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read browser sessions: %w", err)
 	}
 
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
+	return &SessionListResponse{
+		Sessions: sessions,
+		Total:    total,
+		HasMore:  req.Offset+len(sessions) < total,
+	}, nil
+}
 
-	browserCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
+// TerminateSession marks a session as terminated, e.g. after its browser crashed
+func (s *Service) TerminateSession(ctx context.Context, sessionID uuid.UUID, reason string) error {
+	query := `
+		UPDATE browser_sessions
+		SET status = 'terminated', is_active = false, termination_reason = $2, updated_at = $3
+		WHERE id = $1 AND status = 'active'
+	`
+	_, err := s.db.ExecContext(ctx, query, sessionID, reason, time.Now())
+	if s.pool != nil {
+		s.pool.Checkin(sessionID)
+	}
+	return err
+}
+
+// CloseSession closes one of a user's active sessions and returns its browser to the pool
+func (s *Service) CloseSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	query := `
+		UPDATE browser_sessions
+		SET status = 'closed', is_active = false, updated_at = $3
+		WHERE id = $1 AND user_id = $2 AND status = 'active'
+	`
+	result, err := s.db.ExecContext(ctx, query, sessionID, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to close browser session: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrSessionNotFound
+	}
+	if s.pool != nil {
+		s.pool.Checkin(sessionID)
+	}
+	return nil
+}
+
+// reapIdleSessions terminates a user's active sessions that exceeded the idle timeout
+func (s *Service) reapIdleSessions(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE browser_sessions
+		SET status = 'terminated', is_active = false, termination_reason = 'idle_timeout', updated_at = $3
+		WHERE user_id = $1 AND status = 'active' AND last_activity_at < $2
+		RETURNING id
+	`
+	now := time.Now()
+	rows, err := s.db.QueryContext(ctx, query, userID, now.Add(-sessionIdleTimeout), now)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var reaped int64
+	for rows.Next() {
+		var sessionID uuid.UUID
+		if err := rows.Scan(&sessionID); err != nil {
+			return reaped, err
+		}
+		if s.pool != nil {
+			s.pool.Checkin(sessionID)
+		}
+		reaped++
+	}
+	return reaped, rows.Err()
+}
+
+// recordSessionActivity stores the current page of a session and bumps its activity timestamp
+func (s *Service) recordSessionActivity(ctx context.Context, sessionID uuid.UUID, url, title string) error {
+	now := time.Now()
+	if _, err := s.db.ExecContext(ctx, `UPDATE browser_sessions SET last_activity_at = $2, updated_at = $2 WHERE id = $1`, sessionID, now); err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `UPDATE browser_tabs SET url = $2, title = $3, updated_at = $4 WHERE session_id = $1 AND is_active = true`, sessionID, url, title, now)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO browser_tabs (id, session_id, url, title, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, true, $5, $5)
+	`, uuid.New(), sessionID, url, title, now)
+	return err
+}
+
+// Navigate navigates to a URL in a browser context
+func (s *Service) Navigate(ctx context.Context, sessionID uuid.UUID, req NavigateRequest) (*NavigateResponse, error) {
+	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("browser-service").Start(ctx, "browser.Navigate")
+	defer span.End()
 
 	// Set timeout
 	timeout := s.config.Timeout
@@ -104,42 +384,78 @@ func (s *Service) Navigate(ctx context.Context, sessionID uuid.UUID, req Navigat
 		timeout = time.Duration(req.Timeout) * time.Second
 	}
 
+	conditions := req.WaitFor
+	if req.WaitForSelector != "" {
+		conditions = append([]WaitCondition{{Type: WaitTypeSelector, Selector: req.WaitForSelector, Timeout: int(timeout.Milliseconds())}}, conditions...)
+	}
+	if err := validateWaitConditions(conditions); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	recording := s.isRecorded(ctx, sessionID)
+
+	// Create browser context with options
+	browserCtx, release, err := s.sessionBrowser(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	timeoutCtx, cancel := context.WithTimeout(browserCtx, timeout)
 	defer cancel()
 
 	var title string
 	var screenshot []byte
 
-	// Build tasks
-	tasks := []chromedp.Action{
-		chromedp.Navigate(req.URL),
+	// Load the page, then wait for the requested conditions
+	var waits []WaitResult
+	var tasks []chromedp.Action
+	if req.UserAgent != "" {
+		tasks = append(tasks, emulation.SetUserAgentOverride(req.UserAgent))
 	}
-
-	// Add wait condition if specified
-	if req.WaitForSelector != "" {
-		tasks = append(tasks, chromedp.WaitVisible(req.WaitForSelector))
+	tasks = append(tasks, chromedp.Navigate(req.URL), chromedp.WaitReady("body"))
+	err = chromedp.Run(timeoutCtx, tasks...)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrNavigationFailed, err)
+	} else if waits, err = s.waitFor(timeoutCtx, conditions); err != nil {
+		// Show what the page looked like when the wait gave up
+		chromedp.Run(timeoutCtx, chromedp.CaptureScreenshot(&screenshot))
 	} else {
-		tasks = append(tasks, chromedp.WaitReady("body"))
+		err = chromedp.Run(timeoutCtx, chromedp.Title(&title), chromedp.CaptureScreenshot(&screenshot))
 	}
-
-	// Get page title
-	tasks = append(tasks, chromedp.Title(&title))
-
-	// Take screenshot
-	tasks = append(tasks, chromedp.CaptureScreenshot(&screenshot))
-
-	// Execute tasks
-	err := chromedp.Run(timeoutCtx, tasks...)
 	if err != nil {
 		s.logger.Error(ctx, "Navigation failed", err, map[string]interface{}{
 			"url":        req.URL,
 			"session_id": sessionID.String(),
 		})
-		return &NavigateResponse{
+		// A cancellation that did not come from the caller means the browser went away
+		if ctx.Err() == nil && errors.Is(err, context.Canceled) {
+			if terr := s.TerminateSession(ctx, sessionID, "browser_crashed"); terr != nil {
+				s.logger.Warn(ctx, "Failed to mark crashed session", map[string]interface{}{
+					"session_id": sessionID.String(),
+					"error":      terr.Error(),
+				})
+			}
+		}
+		if recording {
+			s.recordStep(ctx, sessionID, RecordingStep{
+				Type:     RecordingStepNavigate,
+				URL:      req.URL,
+				Error:    err.Error(),
+				Duration: time.Since(startTime),
+			}, screenshot)
+		}
+		response := &NavigateResponse{
 			Success: false,
 			URL:     req.URL,
+			Waits:   waits,
 			Error:   err.Error(),
-		}, nil
+		}
+		if len(screenshot) > 0 {
+			response.Screenshot = base64.StdEncoding.EncodeToString(screenshot)
+		}
+		return response, nil
 	}
 
 	loadTime := time.Since(startTime)
@@ -153,12 +469,29 @@ func (s *Service) Navigate(ctx context.Context, sessionID uuid.UUID, req Navigat
 		Title:      title,
 		LoadTime:   loadTime,
 		Screenshot: screenshotB64,
+		Waits:      waits,
 		Metadata: map[string]interface{}{
 			"session_id": sessionID.String(),
 			"timestamp":  time.Now(),
 		},
 	}
 
+	if err := s.recordSessionActivity(ctx, sessionID, req.URL, title); err != nil {
+		s.logger.Warn(ctx, "Failed to record session activity", map[string]interface{}{
+			"session_id": sessionID.String(),
+			"error":      err.Error(),
+		})
+	}
+	if recording {
+		s.recordStep(ctx, sessionID, RecordingStep{
+			Type:     RecordingStepNavigate,
+			URL:      req.URL,
+			Title:    title,
+			Success:  true,
+			Duration: loadTime,
+		}, screenshot)
+	}
+
 	s.logger.Info(ctx, "Navigation completed", map[string]interface{}{
 		"url":        req.URL,
 		"title":      title,
@@ -174,24 +507,26 @@ func (s *Service) Interact(ctx context.Context, sessionID uuid.UUID, req Interac
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("browser-service").Start(ctx, "browser.Interact")
 	defer span.End()
 
-	// Create browser context
-	opts := []chromedp.ExecAllocatorOption{
-		chromedp.Flag("headless", s.config.Headless),
-		chromedp.Flag("disable-gpu", s.config.DisableGPU),
-		chromedp.Flag("no-sandbox", s.config.NoSandbox),
+	if err := validateWaitConditions(req.WaitFor); err != nil {
+		return nil, err
+	}
+	if req.Retries < 0 || req.Retries > maxActionRetries {
+		return nil, fmt.Errorf("%w: retries must be 0-%d", ErrInvalidRetries, maxActionRetries)
 	}
 
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
-
-	browserCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
+	// Create browser context
+	browserCtx, release, err := s.sessionBrowser(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	timeoutCtx, cancel := context.WithTimeout(browserCtx, s.config.Timeout)
 	defer cancel()
 
 	var results []ActionResult
 	var screenshots []string
+	recording := s.isRecorded(ctx, sessionID)
 
 	for i, action := range req.Actions {
 		startTime := time.Now()
@@ -200,8 +535,24 @@ func (s *Service) Interact(ctx context.Context, sessionID uuid.UUID, req Interac
 			Success: true,
 		}
 
-		// Execute action
-		err := s.executeAction(timeoutCtx, action)
+		// Execute action, retrying flaky elements. Each attempt queries the selector afresh and
+		// gets an equal share of the timeout, so a missing element doesn't use up all of it.
+		var err error
+		for attempt := 0; attempt <= req.Retries; attempt++ {
+			result.Attempts++
+			attemptCtx, cancelAttempt := context.WithTimeout(timeoutCtx, s.config.Timeout/time.Duration(req.Retries+1))
+			err = s.executeAction(attemptCtx, action)
+			if err != nil && attemptCtx.Err() != nil && action.Selector != "" {
+				err = fmt.Errorf("%w: %s", ErrElementNotFound, action.Selector)
+			}
+			cancelAttempt()
+			if err == nil {
+				result.Waits, err = s.waitFor(timeoutCtx, req.WaitFor)
+			}
+			if err == nil || timeoutCtx.Err() != nil {
+				break
+			}
+		}
 		if err != nil {
 			result.Success = false
 			result.Error = err.Error()
@@ -209,20 +560,31 @@ func (s *Service) Interact(ctx context.Context, sessionID uuid.UUID, req Interac
 				"action_type":  action.Type,
 				"selector":     action.Selector,
 				"action_index": i,
+				"attempts":     result.Attempts,
 			})
 		}
 
 		result.Duration = time.Since(startTime)
 		results = append(results, result)
 
-		// Take screenshot if requested or after failed action
-		if req.Screenshot || !result.Success {
-			var screenshot []byte
-			if err := chromedp.Run(timeoutCtx, chromedp.CaptureScreenshot(&screenshot)); err == nil {
+		// Take screenshot if requested, after failed action or for the recording
+		var screenshot []byte
+		if req.Screenshot || !result.Success || recording {
+			if err := chromedp.Run(timeoutCtx, chromedp.CaptureScreenshot(&screenshot)); err == nil && (req.Screenshot || !result.Success) {
 				screenshotB64 := base64.StdEncoding.EncodeToString(screenshot)
 				screenshots = append(screenshots, screenshotB64)
 			}
 		}
+		if recording {
+			action := action
+			s.recordStep(ctx, sessionID, RecordingStep{
+				Type:     RecordingStepInteract,
+				Action:   &action,
+				Success:  result.Success,
+				Error:    result.Error,
+				Duration: result.Duration,
+			}, screenshot)
+		}
 
 		// Wait between actions if specified
 		if req.WaitBetween > 0 && i < len(req.Actions)-1 {
@@ -342,21 +704,16 @@ func (s *Service) Extract(ctx context.Context, sessionID uuid.UUID, req ExtractR
 	defer span.End()
 
 	// Create browser context
-	opts := []chromedp.ExecAllocatorOption{
-		chromedp.Flag("headless", s.config.Headless),
-		chromedp.Flag("disable-gpu", s.config.DisableGPU),
-		chromedp.Flag("no-sandbox", s.config.NoSandbox),
+	browserCtx, release, err := s.sessionBrowser(ctx, sessionID)
+	if err != nil {
+		return nil, err
 	}
-
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
-
-	browserCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
+	defer release()
 
 	timeoutCtx, cancel := context.WithTimeout(browserCtx, s.config.Timeout)
 	defer cancel()
 
+	startTime := time.Now()
 	data := make(map[string]interface{})
 
 	// Extract based on data type
@@ -383,6 +740,15 @@ func (s *Service) Extract(ctx context.Context, sessionID uuid.UUID, req ExtractR
 	chromedp.Run(timeoutCtx, chromedp.CaptureScreenshot(&screenshot))
 	screenshotB64 := base64.StdEncoding.EncodeToString(screenshot)
 
+	if s.isRecorded(ctx, sessionID) {
+		s.recordStep(ctx, sessionID, RecordingStep{
+			Type:     RecordingStepExtract,
+			Data:     data,
+			Success:  true,
+			Duration: time.Since(startTime),
+		}, screenshot)
+	}
+
 	response := &ExtractResponse{
 		Success:    true,
 		Data:       data,
@@ -533,24 +899,16 @@ func (s *Service) TakeScreenshot(ctx context.Context, sessionID uuid.UUID, req S
 	defer span.End()
 
 	// Create browser context
-	opts := []chromedp.ExecAllocatorOption{
-		chromedp.Flag("headless", s.config.Headless),
-		chromedp.Flag("disable-gpu", s.config.DisableGPU),
-		chromedp.Flag("no-sandbox", s.config.NoSandbox),
+	browserCtx, release, err := s.sessionBrowser(ctx, sessionID)
+	if err != nil {
+		return nil, err
 	}
-
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
-
-	browserCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
+	defer release()
 
 	timeoutCtx, cancel := context.WithTimeout(browserCtx, s.config.Timeout)
 	defer cancel()
 
 	var screenshot []byte
-	var err error
-
 	if req.Selector != "" {
 		// Element screenshot
 		err = chromedp.Run(timeoutCtx, chromedp.Screenshot(req.Selector, &screenshot))
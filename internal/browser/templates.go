@@ -0,0 +1,638 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/chromedp/chromedp"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxTemplateFields bounds the number of fields in an extraction template
+	maxTemplateFields = 50
+	// maxTemplatePages bounds how many pages a template may crawl
+	maxTemplatePages = 20
+)
+
+var (
+	// ErrTemplateNotFound is returned for templates that don't exist or belong to another user
+	ErrTemplateNotFound = errors.New("extraction template not found")
+	// ErrInvalidTemplate is returned for templates that fail validation
+	ErrInvalidTemplate = errors.New("invalid extraction template")
+	// ErrTemplateURLRequired is returned for template extractions without a start URL
+	ErrTemplateURLRequired = errors.New("url is required for template extraction")
+)
+
+// attributeSelectorPattern matches the contents of a CSS attribute selector
+var attributeSelectorPattern = regexp.MustCompile(`^\s*[-\w|*]+\s*(?:[~|^$*]?=\s*(?:"[^"]*"|'[^']*'|[^\s"'\]]+)\s*[iIsS]?\s*)?$`)
+
+// CreateTemplate saves a new extraction template for a user
+func (s *Service) CreateTemplate(ctx context.Context, userID uuid.UUID, req ExtractionTemplateRequest) (*ExtractionTemplate, error) {
+	if err := normalizeTemplate(&req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &ExtractionTemplate{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Name:       req.Name,
+		Fields:     req.Fields,
+		Pagination: req.Pagination,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	fields, pagination, err := encodeTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO browser_extraction_templates (id, user_id, name, fields, pagination, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err := s.db.ExecContext(ctx, query, template.ID, userID, template.Name, fields, pagination, now, now); err != nil {
+		return nil, fmt.Errorf("failed to create extraction template: %w", err)
+	}
+
+	s.logger.Info(ctx, "Extraction template created", map[string]interface{}{
+		"template_id": template.ID.String(),
+		"user_id":     userID.String(),
+		"fields":      len(template.Fields),
+	})
+
+	return template, nil
+}
+
+// ListTemplates returns a user's extraction templates by name
+func (s *Service) ListTemplates(ctx context.Context, userID uuid.UUID) ([]ExtractionTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, name, fields, pagination, created_at, updated_at
+		FROM browser_extraction_templates
+		WHERE user_id = $1
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list extraction templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]ExtractionTemplate, 0)
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read extraction templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate returns one of a user's extraction templates
+func (s *Service) GetTemplate(ctx context.Context, userID, templateID uuid.UUID) (*ExtractionTemplate, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, name, fields, pagination, created_at, updated_at
+		FROM browser_extraction_templates
+		WHERE id = $1 AND user_id = $2
+	`, templateID, userID)
+	template, err := scanTemplate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction template: %w", err)
+	}
+	return template, nil
+}
+
+// UpdateTemplate replaces the name, fields and pagination of one of a user's templates
+func (s *Service) UpdateTemplate(ctx context.Context, userID, templateID uuid.UUID, req ExtractionTemplateRequest) (*ExtractionTemplate, error) {
+	if err := normalizeTemplate(&req); err != nil {
+		return nil, err
+	}
+
+	template := &ExtractionTemplate{Fields: req.Fields, Pagination: req.Pagination}
+	fields, pagination, err := encodeTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE browser_extraction_templates
+		SET name = $3, fields = $4, pagination = $5, updated_at = $6
+		WHERE id = $1 AND user_id = $2
+	`, templateID, userID, req.Name, fields, pagination, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update extraction template: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrTemplateNotFound
+	}
+	return s.GetTemplate(ctx, userID, templateID)
+}
+
+// DeleteTemplate deletes one of a user's extraction templates
+func (s *Service) DeleteTemplate(ctx context.Context, userID, templateID uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM browser_extraction_templates WHERE id = $1 AND user_id = $2`, templateID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete extraction template: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// ExtractWithTemplate opens req.URL and extracts the fields of a saved template as typed
+// values, following the template's next-page selector up to its page limit. Fields that can't
+// be extracted are null, with a warning.
+func (s *Service) ExtractWithTemplate(ctx context.Context, userID, sessionID uuid.UUID, req ExtractRequest) (*ExtractResponse, error) {
+	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("browser-service").Start(ctx, "browser.ExtractWithTemplate")
+	defer span.End()
+
+	if req.TemplateID == nil {
+		return nil, fmt.Errorf("%w: template_id is required", ErrInvalidTemplate)
+	}
+	if req.URL == "" {
+		return nil, ErrTemplateURLRequired
+	}
+	template, err := s.GetTemplate(ctx, userID, *req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPages := 1
+	if template.Pagination != nil {
+		maxPages = template.Pagination.MaxPages
+	}
+
+	browserCtx, release, err := s.sessionBrowser(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Each page gets the configured timeout
+	timeoutCtx, cancel := context.WithTimeout(browserCtx, s.config.Timeout*time.Duration(maxPages))
+	defer cancel()
+
+	startTime := time.Now()
+	if err := chromedp.Run(timeoutCtx, chromedp.Navigate(req.URL), chromedp.WaitReady("body")); err != nil {
+		s.logger.Error(ctx, "Template extraction navigation failed", err, map[string]interface{}{
+			"url":         req.URL,
+			"template_id": template.ID.String(),
+		})
+		return &ExtractResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	script, err := templateFieldsScript(template.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]map[string]interface{}, 0, maxPages)
+	var warnings []string
+	visited := map[string]bool{req.URL: true}
+	for page := 1; ; page++ {
+		var results map[string]templateFieldResult
+		if err := chromedp.Run(timeoutCtx, chromedp.Evaluate(script, &results)); err != nil {
+			warnings = append(warnings, fmt.Sprintf("page %d: %v", page, err))
+			break
+		}
+
+		values := make(map[string]interface{}, len(template.Fields))
+		for _, field := range template.Fields {
+			value, warning := convertTemplateField(field, results[field.Name])
+			values[field.Name] = value
+			if warning != "" {
+				warnings = append(warnings, fmt.Sprintf("page %d: field %q: %s", page, field.Name, warning))
+			}
+		}
+		pages = append(pages, values)
+
+		if page >= maxPages {
+			break
+		}
+		more, err := followNextPage(timeoutCtx, template.Pagination, visited)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("page %d: failed to follow next page: %v", page, err))
+			break
+		}
+		if !more {
+			break
+		}
+	}
+
+	var screenshot []byte
+	chromedp.Run(timeoutCtx, chromedp.CaptureScreenshot(&screenshot))
+
+	data := map[string]interface{}{
+		"template_id": template.ID,
+		"template":    template.Name,
+		"pages":       pages,
+	}
+	if s.isRecorded(ctx, sessionID) {
+		s.recordStep(ctx, sessionID, RecordingStep{
+			Type:     RecordingStepExtract,
+			URL:      req.URL,
+			Data:     data,
+			Success:  true,
+			Duration: time.Since(startTime),
+		}, screenshot)
+	}
+
+	s.logger.Info(ctx, "Template extraction completed", map[string]interface{}{
+		"session_id":  sessionID.String(),
+		"template_id": template.ID.String(),
+		"pages":       len(pages),
+		"warnings":    len(warnings),
+	})
+
+	return &ExtractResponse{
+		Success:    true,
+		Data:       data,
+		Screenshot: base64.StdEncoding.EncodeToString(screenshot),
+		Warnings:   warnings,
+		Metadata: map[string]interface{}{
+			"session_id": sessionID.String(),
+			"timestamp":  time.Now(),
+			"url":        req.URL,
+		},
+	}, nil
+}
+
+// templateFieldResult is what the page script returns for a field: the text or attribute of
+// the matching elements, or why they couldn't be read
+type templateFieldResult struct {
+	Values []*string `json:"values"`
+	Error  string    `json:"error"`
+}
+
+// templateFieldsScript builds the script evaluating all fields of a template in the page.
+// Selector errors are caught per field.
+func templateFieldsScript(fields []TemplateField) (string, error) {
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`
+		(function(fields) {
+			const find = (selector, type) => {
+				if (type === 'xpath') {
+					const result = document.evaluate(selector, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+					const nodes = [];
+					for (let i = 0; i < result.snapshotLength; i++) nodes.push(result.snapshotItem(i));
+					return nodes;
+				}
+				return Array.from(document.querySelectorAll(selector));
+			};
+			const out = {};
+			for (const field of fields) {
+				try {
+					const read = node => field.type === 'attribute'
+						? (node.getAttribute ? node.getAttribute(field.attribute) : null)
+						: (node.textContent || '').trim();
+					const nodes = find(field.selector, field.selector_type);
+					if (field.type === 'list') {
+						out[field.name] = { values: nodes.map(read) };
+					} else if (nodes.length === 0) {
+						out[field.name] = { error: 'no element matches the selector' };
+					} else {
+						out[field.name] = { values: [read(nodes[0])] };
+					}
+				} catch (e) {
+					out[field.name] = { error: String(e && e.message || e) };
+				}
+			}
+			return out;
+		})(%s)
+	`, encoded), nil
+}
+
+// convertTemplateField converts the page result of a field to its template type. It returns
+// nil and a warning when the field couldn't be extracted.
+func convertTemplateField(field TemplateField, result templateFieldResult) (interface{}, string) {
+	if result.Error != "" {
+		return nil, result.Error
+	}
+
+	if field.Type == TemplateFieldList {
+		values := make([]string, 0, len(result.Values))
+		for _, value := range result.Values {
+			if value != nil {
+				values = append(values, *value)
+			}
+		}
+		return values, ""
+	}
+
+	if len(result.Values) == 0 || result.Values[0] == nil {
+		if field.Type == TemplateFieldAttribute {
+			return nil, fmt.Sprintf("element has no %q attribute", field.Attribute)
+		}
+		return nil, "no value"
+	}
+	value := *result.Values[0]
+
+	if field.Type == TemplateFieldNumber {
+		number, err := parseTemplateNumber(value)
+		if err != nil {
+			return nil, fmt.Sprintf("%q is not a number", value)
+		}
+		return number, ""
+	}
+	return value, ""
+}
+
+// parseTemplateNumber parses numbers as they appear on pages, ignoring currency symbols,
+// units and thousands separators
+func parseTemplateNumber(value string) (float64, error) {
+	var b strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return strconv.ParseFloat(b.String(), 64)
+}
+
+// followNextPage moves to the next page of a listing. It returns false when there is no next
+// page or it was already visited.
+func followNextPage(ctx context.Context, pagination *TemplatePagination, visited map[string]bool) (bool, error) {
+	selector, err := json.Marshal(pagination.NextSelector)
+	if err != nil {
+		return false, err
+	}
+	// Links are followed by URL; other elements, e.g. buttons, are clicked
+	script := fmt.Sprintf(`
+		(function(selector, xpath) {
+			const el = xpath
+				? document.evaluate(selector, document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue
+				: document.querySelector(selector);
+			if (!el) return { found: false, href: '' };
+			if (el.href) return { found: true, href: String(el.href) };
+			el.click();
+			return { found: true, href: '' };
+		})(%s, %t)
+	`, selector, pagination.SelectorType == SelectorTypeXPath)
+
+	var next struct {
+		Found bool   `json:"found"`
+		Href  string `json:"href"`
+	}
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &next)); err != nil {
+		return false, err
+	}
+	if !next.Found {
+		return false, nil
+	}
+
+	if next.Href == "" {
+		// Give the click a moment to start loading before waiting for the page
+		if err := chromedp.Run(ctx, chromedp.Sleep(time.Second), chromedp.WaitReady("body")); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if visited[next.Href] {
+		return false, nil
+	}
+	visited[next.Href] = true
+	if err := chromedp.Run(ctx, chromedp.Navigate(next.Href), chromedp.WaitReady("body")); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// normalizeTemplate applies defaults to a template request and validates it
+func normalizeTemplate(req *ExtractionTemplateRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidTemplate)
+	}
+	if len(req.Fields) == 0 || len(req.Fields) > maxTemplateFields {
+		return fmt.Errorf("%w: a template needs 1-%d fields", ErrInvalidTemplate, maxTemplateFields)
+	}
+
+	names := make(map[string]bool, len(req.Fields))
+	for i := range req.Fields {
+		field := &req.Fields[i]
+		field.Name = strings.TrimSpace(field.Name)
+		if field.Name == "" {
+			return fmt.Errorf("%w: field %d has no name", ErrInvalidTemplate, i+1)
+		}
+		if names[field.Name] {
+			return fmt.Errorf("%w: duplicate field %q", ErrInvalidTemplate, field.Name)
+		}
+		names[field.Name] = true
+
+		switch field.Type {
+		case TemplateFieldText, TemplateFieldNumber, TemplateFieldList:
+		case TemplateFieldAttribute:
+			if strings.TrimSpace(field.Attribute) == "" {
+				return fmt.Errorf("%w: attribute field %q needs an attribute", ErrInvalidTemplate, field.Name)
+			}
+		default:
+			return fmt.Errorf("%w: field %q has unsupported type %q", ErrInvalidTemplate, field.Name, field.Type)
+		}
+
+		if field.SelectorType == "" {
+			field.SelectorType = SelectorTypeCSS
+		}
+		if err := validateSelector(field.Selector, field.SelectorType); err != nil {
+			return fmt.Errorf("%w: field %q: %v", ErrInvalidTemplate, field.Name, err)
+		}
+	}
+
+	if pagination := req.Pagination; pagination != nil {
+		if pagination.SelectorType == "" {
+			pagination.SelectorType = SelectorTypeCSS
+		}
+		if err := validateSelector(pagination.NextSelector, pagination.SelectorType); err != nil {
+			return fmt.Errorf("%w: next page selector: %v", ErrInvalidTemplate, err)
+		}
+		if pagination.MaxPages < 1 || pagination.MaxPages > maxTemplatePages {
+			return fmt.Errorf("%w: max_pages must be 1-%d", ErrInvalidTemplate, maxTemplatePages)
+		}
+	}
+	return nil
+}
+
+// validateSelector checks that a selector parses. Selectors that parse may still be rejected
+// by the browser; such fields are reported as warnings during extraction.
+func validateSelector(selector string, selectorType SelectorType) error {
+	if strings.TrimSpace(selector) == "" {
+		return errors.New("selector is empty")
+	}
+	switch selectorType {
+	case SelectorTypeCSS:
+		return validateCSSSelector(selector)
+	case SelectorTypeXPath:
+		return validateXPath(selector)
+	default:
+		return fmt.Errorf("unsupported selector type %q", selectorType)
+	}
+}
+
+// validateCSSSelector checks quotes, brackets, attribute selectors and combinators
+func validateCSSSelector(selector string) error {
+	if err := checkBalanced(selector); err != nil {
+		return err
+	}
+
+	for _, group := range splitOutsideBrackets(selector, ',') {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			return errors.New("empty selector in list")
+		}
+		if strings.ContainsAny(group[:1], ">+~") || strings.ContainsAny(group[len(group)-1:], ">+~") {
+			return fmt.Errorf("dangling combinator in %q", group)
+		}
+	}
+
+	for rest := selector; ; {
+		start := strings.IndexByte(rest, '[')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], ']')
+		if !attributeSelectorPattern.MatchString(rest[start+1 : start+end]) {
+			return fmt.Errorf("invalid attribute selector %q", rest[start:start+end+1])
+		}
+		rest = rest[start+end+1:]
+	}
+	return nil
+}
+
+// validateXPath checks quotes, brackets and path steps
+func validateXPath(expr string) error {
+	if err := checkBalanced(expr); err != nil {
+		return err
+	}
+	trimmed := strings.TrimSpace(expr)
+	if strings.Contains(trimmed, "///") {
+		return errors.New("empty location step")
+	}
+	if trimmed != "/" && strings.HasSuffix(trimmed, "/") {
+		return errors.New("expression ends with a path separator")
+	}
+	if strings.Contains(trimmed, "[]") {
+		return errors.New("empty predicate")
+	}
+	return nil
+}
+
+// checkBalanced checks that quotes are terminated and brackets and parentheses are balanced
+func checkBalanced(s string) error {
+	var stack []rune
+	var quote rune
+	for _, r := range s {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'':
+			quote = r
+		case '[', '(':
+			stack = append(stack, r)
+		case ']', ')':
+			open := '['
+			if r == ')' {
+				open = '('
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return fmt.Errorf("unbalanced %q", r)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if quote != 0 {
+		return errors.New("unterminated string")
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed %q", stack[len(stack)-1])
+	}
+	return nil
+}
+
+// splitOutsideBrackets splits s on sep where it is not inside quotes, brackets or parentheses
+func splitOutsideBrackets(s string, sep rune) []string {
+	var parts []string
+	var quote rune
+	depth, start := 0, 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '(':
+			depth++
+		case r == ']' || r == ')':
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTemplate(row rowScanner) (*ExtractionTemplate, error) {
+	var template ExtractionTemplate
+	var fields, pagination []byte
+	if err := row.Scan(&template.ID, &template.UserID, &template.Name, &fields, &pagination, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fields, &template.Fields); err != nil {
+		return nil, fmt.Errorf("failed to decode template fields: %w", err)
+	}
+	if len(pagination) > 0 {
+		template.Pagination = &TemplatePagination{}
+		if err := json.Unmarshal(pagination, template.Pagination); err != nil {
+			return nil, fmt.Errorf("failed to decode template pagination: %w", err)
+		}
+	}
+	return &template, nil
+}
+
+func encodeTemplate(template *ExtractionTemplate) ([]byte, []byte, error) {
+	fields, err := json.Marshal(template.Fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	if template.Pagination == nil {
+		return fields, nil, nil
+	}
+	pagination, err := json.Marshal(template.Pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fields, pagination, nil
+}
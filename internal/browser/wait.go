@@ -0,0 +1,185 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// defaultWaitTimeout bounds wait conditions without a timeout
+	defaultWaitTimeout = 10 * time.Second
+	// waitPollInterval is how often polled wait conditions are checked
+	waitPollInterval = 100 * time.Millisecond
+	// networkIdleWindow is how long no new resources may load for the network to be idle
+	networkIdleWindow = 500 * time.Millisecond
+	// maxActionRetries bounds the retries of a single action
+	maxActionRetries = 5
+)
+
+var (
+	// ErrNavigationFailed is returned when the page itself could not be loaded
+	ErrNavigationFailed = errors.New("navigation failed")
+	// ErrElementNotFound is returned when a waited-for element never appeared
+	ErrElementNotFound = errors.New("element never appeared")
+	// ErrWaitTimeout is returned when any other wait condition was not met in time
+	ErrWaitTimeout = errors.New("wait condition timed out")
+	// ErrInvalidWaitCondition is returned for wait conditions that can't be evaluated
+	ErrInvalidWaitCondition = errors.New("invalid wait condition")
+	// ErrInvalidRetries is returned for retry counts outside 0-maxActionRetries
+	ErrInvalidRetries = errors.New("invalid retry count")
+)
+
+// validateWaitConditions checks that each condition has what its type needs
+func validateWaitConditions(conditions []WaitCondition) error {
+	for i, condition := range conditions {
+		if condition.Timeout < 0 {
+			return fmt.Errorf("%w: condition %d has a negative timeout", ErrInvalidWaitCondition, i+1)
+		}
+		switch condition.Type {
+		case WaitTypeSelector:
+			if condition.Selector == "" {
+				return fmt.Errorf("%w: condition %d needs a selector", ErrInvalidWaitCondition, i+1)
+			}
+		case WaitTypeText:
+			if condition.Text == "" {
+				return fmt.Errorf("%w: condition %d needs text", ErrInvalidWaitCondition, i+1)
+			}
+		case WaitTypeURL:
+			if _, err := regexp.Compile(condition.URL); err != nil || condition.URL == "" {
+				return fmt.Errorf("%w: condition %d needs a valid url pattern", ErrInvalidWaitCondition, i+1)
+			}
+		case WaitTypeDelay:
+			if condition.Delay <= 0 {
+				return fmt.Errorf("%w: condition %d needs a positive delay", ErrInvalidWaitCondition, i+1)
+			}
+		case WaitTypeNetworkIdle:
+		default:
+			return fmt.Errorf("%w: condition %d has unsupported type %q", ErrInvalidWaitCondition, i+1, condition.Type)
+		}
+	}
+	return nil
+}
+
+// waitFor waits for each condition in order, reporting how long each took. It stops at the
+// first condition that isn't met.
+func (s *Service) waitFor(ctx context.Context, conditions []WaitCondition) ([]WaitResult, error) {
+	results := make([]WaitResult, 0, len(conditions))
+	for _, condition := range conditions {
+		start := time.Now()
+		err := waitForCondition(ctx, condition)
+		result := WaitResult{
+			Condition: condition,
+			Success:   err == nil,
+			Duration:  time.Since(start),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+func waitForCondition(ctx context.Context, condition WaitCondition) error {
+	timeout := defaultWaitTimeout
+	if condition.Timeout > 0 {
+		timeout = time.Duration(condition.Timeout) * time.Millisecond
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var err error
+	switch condition.Type {
+	case WaitTypeSelector:
+		err = chromedp.Run(waitCtx, chromedp.WaitVisible(condition.Selector))
+		if err != nil && waitCtx.Err() != nil {
+			return fmt.Errorf("%w: %s not visible after %s", ErrElementNotFound, condition.Selector, timeout)
+		}
+		return err
+
+	case WaitTypeText:
+		text, _ := json.Marshal(condition.Text)
+		script := fmt.Sprintf(`document.body ? document.body.innerText.includes(%s) : false`, text)
+		err = pollUntil(waitCtx, func() (bool, error) {
+			var found bool
+			err := chromedp.Run(waitCtx, chromedp.Evaluate(script, &found))
+			return found, err
+		})
+
+	case WaitTypeURL:
+		pattern := regexp.MustCompile(condition.URL)
+		err = pollUntil(waitCtx, func() (bool, error) {
+			var location string
+			err := chromedp.Run(waitCtx, chromedp.Location(&location))
+			return pattern.MatchString(location), err
+		})
+
+	case WaitTypeNetworkIdle:
+		// The network is idle once the document has loaded and no resources started loading
+		// for networkIdleWindow
+		var resources int
+		idleSince := time.Now()
+		err = pollUntil(waitCtx, func() (bool, error) {
+			var state struct {
+				Ready     bool `json:"ready"`
+				Resources int  `json:"resources"`
+			}
+			script := `({ ready: document.readyState === 'complete', resources: performance.getEntriesByType('resource').length })`
+			if err := chromedp.Run(waitCtx, chromedp.Evaluate(script, &state)); err != nil {
+				return false, err
+			}
+			if !state.Ready || state.Resources != resources {
+				resources = state.Resources
+				idleSince = time.Now()
+				return false, nil
+			}
+			return time.Since(idleSince) >= networkIdleWindow, nil
+		})
+
+	case WaitTypeDelay:
+		select {
+		case <-time.After(time.Duration(condition.Delay) * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+	default:
+		return fmt.Errorf("%w: unsupported type %q", ErrInvalidWaitCondition, condition.Type)
+	}
+
+	if err != nil && waitCtx.Err() != nil {
+		return fmt.Errorf("%w: %s not met after %s", ErrWaitTimeout, condition.Type, timeout)
+	}
+	return err
+}
+
+// pollUntil calls check every waitPollInterval until it reports true, fails or ctx is done
+func pollUntil(ctx context.Context, check func() (bool, error)) error {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,10 +18,14 @@ type Config struct {
 	Web3          Web3Config
 	Browser       BrowserConfig
 	Terminal      TerminalConfig
+	WebSocket     WebSocketConfig
 	Observability ObservabilityConfig
 	RateLimit     RateLimitConfig
 	Security      SecurityConfig
 	Logger        LoggerConfig
+	Alerts        AlertsConfig
+	Gateway       GatewayConfig
+	Maintenance   MaintenanceConfig
 }
 
 type ServerConfig struct {
@@ -29,6 +34,9 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	// StrictJSON rejects request bodies with unknown fields instead of ignoring them
+	StrictJSON bool
 }
 
 type DatabaseConfig struct {
@@ -63,6 +71,60 @@ type RedisConfig struct {
 	MaxMemory          string
 	EvictionPolicy     string
 	CompressionLevel   int
+
+	// HTTP response caching; the first route whose pattern matches sets the TTL
+	ResponseCacheTTL    time.Duration
+	ResponseCacheRoutes []RouteTTL
+}
+
+// RouteTTL sets the response cache TTL of the request paths matching Pattern, a glob in
+// which * also matches /, or a regular expression when prefixed with "regex:". A zero
+// TTL disables caching for the route.
+type RouteTTL struct {
+	Pattern string
+	TTL     time.Duration
+}
+
+// RouteRole requires Role or a higher role for requests matching Pattern, a net/http
+// ServeMux pattern such as "POST /web3/trading/killswitch" or "DELETE /ai/"
+type RouteRole struct {
+	Pattern string
+	Role    string
+}
+
+// DefaultRoutePolicy lets viewers read, traders change their own trading and only admins
+// stop trading globally or change strategies and models for everyone
+var DefaultRoutePolicy = []RouteRole{
+	{Pattern: "POST /web3/trading/", Role: "trader"},
+	{Pattern: "PUT /web3/trading/", Role: "trader"},
+	{Pattern: "DELETE /web3/trading/", Role: "trader"},
+	{Pattern: "POST /web3/transaction", Role: "trader"},
+	{Pattern: "POST /web3/enhanced/transaction", Role: "trader"},
+	{Pattern: "POST /web3/defi/interact", Role: "trader"},
+	{Pattern: "POST /web3/trading/portfolio/{id}/stop", Role: "admin"},
+	{Pattern: "POST /web3/trading/killswitch", Role: "admin"},
+	{Pattern: "POST /web3/trading/killswitch/reset", Role: "admin"},
+	{Pattern: "POST /web3/trading/blackouts", Role: "admin"},
+	{Pattern: "DELETE /web3/trading/blackouts/{id}", Role: "admin"},
+	{Pattern: "PUT /web3/trading/strategies/{name}/schedule", Role: "admin"},
+	{Pattern: "POST /ai/models/train", Role: "admin"},
+	{Pattern: "POST /ai/models/{id}/versions/{version}/activate", Role: "admin"},
+	{Pattern: "POST /ai/models/{id}/rollback", Role: "admin"},
+	{Pattern: "POST /ai/market/strategies", Role: "admin"},
+	{Pattern: "POST /ai/market/strategies/adapt", Role: "admin"},
+	{Pattern: "PUT /ai/market/strategies/{id}/status", Role: "admin"},
+	{Pattern: "POST /admin/", Role: "admin"},
+}
+
+// DefaultMaintenanceEssentialPaths keep closing positions and stopping trading available
+// during maintenance
+var DefaultMaintenanceEssentialPaths = []string{
+	"/web3/trading/positions/*/close",
+	"/web3/trading/portfolio/*/stop",
+	"/web3/trading/killswitch",
+	"/web3/trading/killswitch/reset",
+	"/api/v1/trading-bots/*/stop",
+	"/api/v1/trading-bots/stop-all",
 }
 
 type JWTConfig struct {
@@ -78,6 +140,65 @@ type AIConfig struct {
 	ModelName      string
 	OllamaConfig   OllamaConfig
 	LMStudioConfig LMStudioConfig
+
+	CryptoBatchWorkers   int           // concurrent analyses per batch request
+	CryptoSearchCacheTTL time.Duration // how long coin analysis web searches are shared through Redis
+
+	// News and social sources for coin analysis
+	News NewsSourcesConfig
+
+	// Speech synthesis for voice command responses
+	TTSModel    string
+	TTSVoice    string
+	TTSCacheTTL time.Duration
+
+	// Translation of non-English texts for sentiment analysis
+	TranslationModel string
+
+	// Scheduled coin analysis reports
+	Reports CryptoReportsConfig
+
+	// How often served price predictions whose horizon elapsed are resolved against realized prices
+	PredictionEvaluationInterval time.Duration
+
+	// Conversation context sent to the chat model, and the token prices its cost is estimated at
+	ChatContextMessages     int     // newest messages sent verbatim; older ones are summarized
+	ChatSummaryMaxTokens    int     // length cap of a conversation's rolling summary
+	ChatPromptCostPer1K     float64 // USD
+	ChatCompletionCostPer1K float64 // USD
+
+	// Usage accounting of provider calls
+	ModelPrices          map[string][]string // USD per 1K input and output tokens per model; "default" covers the rest
+	UsageFlushInterval   time.Duration       // how often usage counters are flushed to Postgres
+	UserMonthlyBudgetUSD float64             // default monthly limit per user; zero means none
+
+	// Embeddings of analyzed documents for semantic search
+	EmbeddingModel       string // OpenAI model; without an API key a local hashing embedder is used
+	DocumentChunkWords   int    // words per embedded chunk
+	DocumentChunkOverlap int    // words repeated from the previous chunk
+
+	// Retries and failover between providers for completion requests
+	ProviderRoutes     map[string][]string // ordered providers per request type; "default" covers the rest
+	ProviderMaxRetries int                 // retries per provider on 429, 5xx and timeouts
+	ProviderRetryDelay time.Duration       // base backoff, doubled per retry with jitter
+	ProviderTimeout    time.Duration       // per attempt
+}
+
+// CryptoReportsConfig configures the scheduled coin analysis report runner
+type CryptoReportsConfig struct {
+	Retention    time.Duration // stored reports older than this are deleted
+	MaxAttempts  int           // generation attempts per symbol and run
+	RetryDelay   time.Duration // doubled after every failed attempt
+	PollInterval time.Duration // how often due schedules are checked
+}
+
+// NewsSourcesConfig configures the news and social media sources used by coin analysis
+type NewsSourcesConfig struct {
+	RSSFeeds         []string            // feed URLs, optionally prefixed with "name="
+	CryptoPanicKey   string              // enables CryptoPanic when set
+	RedditSubreddits map[string][]string // subreddits per asset class; "all" applies to every class
+	DisabledSources  []string            // source names to skip, e.g. web_search
+	SourceTimeout    time.Duration       // per-source fetch timeout
 }
 
 type OllamaConfig struct {
@@ -110,9 +231,14 @@ type Web3Config struct {
 	PolygonRPC         string
 	ArbitrumRPC        string
 	OptimismRPC        string
+	BaseRPC            string
 	BSCMainnetRPC      string
 	BSCTestnetRPC      string
 	SepoliaRPC         string
+	PolygonAmoyRPC     string
+	ArbitrumSepoliaRPC string
+	OptimismSepoliaRPC string
+	BaseSepoliaRPC     string
 	IPFSNodeURL        string
 	IPFSGateway        string
 	IPFSMaxFileSize    int64
@@ -122,6 +248,103 @@ type Web3Config struct {
 	TransactionTimeout time.Duration
 	MaxRetries         int
 	RetryDelay         time.Duration
+	SnapshotInterval   time.Duration // portfolio value history sampling interval
+
+	RebalanceCheckInterval time.Duration // how often scheduled rebalance strategies are evaluated
+
+	// Kill switch triggers for autonomous trading; a zero threshold disables its trigger
+	KillSwitchStaleData     time.Duration // halt when no market data arrived for this long
+	KillSwitchMaxDailyLoss  float64       // halt when a portfolio lost this share of its value today
+	KillSwitchFlatten       bool          // close all open positions when the kill switch trips
+	KillSwitchCheckInterval time.Duration
+
+	// Live DeFi yield sources; a protocol without a subgraph URL keeps its built-in data
+	DeFiRefreshInterval  time.Duration
+	DeFiPoolsPerProtocol int
+	AaveSubgraphURL      string
+	CompoundSubgraphURL  string
+	UniswapV3SubgraphURL string
+
+	// NFT holdings are indexed by the Alchemy NFT API; NFT tracking is disabled without a key
+	AlchemyAPIKey string
+
+	// Transactions are screened against operator deny/allow lists, Chainalysis sanctions
+	// screening and Etherscan contract data; a provider without a key is skipped
+	ChainalysisAPIKey       string
+	EtherscanAPIKey         string
+	ScreeningCacheTTL       time.Duration // how long address screenings are reused
+	ScreeningNewContractAge time.Duration // contracts deployed more recently are flagged
+
+	// Token approvals are found from Approval events in the last ApprovalScanBlocks blocks and
+	// allowances of well-known spenders; scans at or above ApprovalAlertMinRisk alert the owner
+	ApprovalCacheTTL     time.Duration
+	ApprovalScanBlocks   int
+	ApprovalAlertMinRisk string // low, medium, high or critical; empty disables alerts
+
+	// Transactions are signed in the user's wallet: requests expire after SigningRequestTTL.
+	// WalletConnect pushes them to the wallet app and is disabled without a project ID.
+	SigningRequestTTL      time.Duration
+	WalletConnectProjectID string
+	WalletConnectRelayURL  string
+	WalletConnectAppURL    string // shown to the user in their wallet app
+
+	// Multisig proposals close unless enough members approve within MultisigProposalTTL;
+	// SafeAPIKey authenticates with Safe's transaction service and is optional
+	MultisigProposalTTL time.Duration
+	SafeAPIKey          string
+
+	// Bridge routes can be executed for BridgeQuoteTTL after quoting. Transfers are polled every
+	// BridgePollInterval and alert their owner once BridgeStuckAfter passes without delivery.
+	BridgeQuoteTTL     time.Duration
+	BridgePollInterval time.Duration
+	BridgeStuckAfter   time.Duration
+	LiFiAPIKey         string
+
+	// Trade anomaly detection on order sizes, fill slippage and portfolio drawdown
+	TradeAnomalySensitivity    float64 // 0-1; higher flags smaller deviations
+	TradeAnomalyMaxSlippageBps float64 // always flag fills slipping more than this; 0 disables
+	TradeAnomalyMaxDrawdown    float64 // always flag drawdowns from peak beyond this share; 0 disables
+
+	// Watch-only wallets are polled for balance changes and outgoing transactions
+	WatchOnlyPollInterval    time.Duration
+	WatchOnlyChangeThreshold float64 // alert when a balance changes by more than this share
+
+	// Aggregate balances read chains concurrently; a chain slower than the timeout is reported
+	// as a partial error
+	BalanceChainParallelism int
+	BalanceChainTimeout     time.Duration
+
+	// Perpetual futures funding rates and open interest are polled for these exchanges and
+	// symbols; open interest change is measured over DerivativesOIChangeWindow
+	DerivativesExchanges      []string
+	DerivativesSymbols        []string
+	DerivativesPollInterval   time.Duration
+	DerivativesOIChangeWindow time.Duration
+}
+
+// AlertsConfig configures where alert notifications are delivered. A channel is only
+// active when its destination is set.
+type AlertsConfig struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	EmailFrom    string
+	EmailTo      []string
+
+	SlackWebhookURL string
+	SlackChannel    string
+
+	TelegramBotToken string
+	TelegramChatIDs  []string
+
+	WebhookURL    string
+	WebhookSecret string // HMAC-SHA256 key used to sign webhook payloads
+
+	TopicChannels       map[string][]string // extra channels per alert topic, e.g. severity_critical
+	MaxDeliveryAttempts int
+	RetryBaseDelay      time.Duration // doubled after every failed attempt
+	DeliveryTimeout     time.Duration // per attempt
 }
 
 type BrowserConfig struct {
@@ -129,6 +352,26 @@ type BrowserConfig struct {
 	DisableGPU bool
 	NoSandbox  bool
 	Timeout    time.Duration
+
+	// Caps on the recording of a session created with "record": true. Steps past
+	// RecordingMaxSteps are dropped and thumbnails past RecordingMaxBytes are omitted.
+	RecordingMaxSteps int
+	RecordingMaxBytes int
+
+	// Browser pool: at most PoolSize browsers (0 launches one per operation), PoolWarm of them
+	// kept started while idle. A browser is recycled after InstanceMaxUses sessions or once it
+	// is InstanceMaxAge old.
+	PoolSize            int
+	PoolWarm            int
+	PoolCheckoutTimeout time.Duration
+	MaxSessionsPerUser  int
+	InstanceMaxUses     int
+	InstanceMaxAge      time.Duration
+
+	// Live view of pooled sessions: screencast frames per second at most, and their JPEG
+	// quality (1-100)
+	LiveViewFPS     int
+	LiveViewQuality int
 }
 
 type ObservabilityConfig struct {
@@ -141,11 +384,99 @@ type ObservabilityConfig struct {
 type RateLimitConfig struct {
 	RequestsPerMinute int
 	Burst             int
+
+	// PerUser keys limits on the JWT user_id, falling back to the client IP for anonymous
+	// requests. AI-heavy routes and GET requests use their own per-minute limits.
+	PerUser                  bool
+	AIHeavyRequestsPerMinute int
+	ReadRequestsPerMinute    int
+
+	// APIKeyRequestsPerMinute limits each API key, independently of the limits above
+	APIKeyRequestsPerMinute int
+
+	// TrustedProxies are the addresses or CIDR ranges of proxies, such as the API gateway,
+	// whose X-Forwarded-For and X-Real-IP headers identify the client
+	TrustedProxies []string
+}
+
+// GatewayConfig configures how the API gateway proxies requests to the services
+type GatewayConfig struct {
+	CircuitFailureThreshold int           // consecutive failures that open a service's circuit
+	CircuitCooldown         time.Duration // how long an open circuit rejects requests before a health probe
+	UpstreamDialTimeout     time.Duration
+}
+
+// MaintenanceConfig configures maintenance mode. While it is enabled, services answer new
+// non-essential requests with 503 and Retry-After, and close streams still open once
+// DrainTimeout has passed.
+type MaintenanceConfig struct {
+	DrainTimeout time.Duration
+	RetryAfter   time.Duration
+	SyncInterval time.Duration // how often services read maintenance changes made elsewhere
+
+	// EssentialPaths are still served during maintenance, in addition to health checks and
+	// the maintenance routes: path.Match patterns, or prefixes when they end in "/"
+	EssentialPaths []string
 }
 
 type SecurityConfig struct {
 	CORSAllowedOrigins []string
 	BCryptCost         int
+
+	// AdminUsers lists the user IDs or emails allowed to call admin endpoints
+	AdminUsers []string
+
+	// RoutePolicy maps route patterns to the least role allowed to call them. Entries from
+	// RBAC_ROUTE_POLICY replace defaults with the same pattern and add to the rest.
+	RoutePolicy []RouteRole
+
+	// Audit log retention: events stay queryable for AuditHotRetention, then move to
+	// compressed segments in AuditArchiveDir (AES-256-GCM with the hex AuditArchiveKey when
+	// set) and are deleted after AuditRetention. An empty directory disables archival.
+	AuditHotRetention    time.Duration
+	AuditRetention       time.Duration
+	AuditArchiveDir      string
+	AuditArchiveKey      string
+	AuditArchiveInterval time.Duration
+
+	// Right-to-erasure: the data owners every request waits for, the key signing erasure
+	// certificates (the JWT secret by default) and how often services process their tasks
+	ErasureDataOwners    []string
+	ErasureSigningKey    string
+	ErasureCheckInterval time.Duration
+
+	// Hex-encoded AES-256 key encrypting users' exchange API credentials at rest; exchange
+	// connections are disabled without it
+	ExchangeCredentialsKey string
+
+	// PEM file with the operator's RSA public key that encryption keys are escrowed to for
+	// disaster recovery; escrow exports are disabled without it
+	KeyEscrowPublicKeyFile string
+
+	// Key signing the tokens of public analytics dashboard share links; sharing is disabled
+	// without it
+	DashboardShareKey string
+
+	// Failed login protection: within LoginFailureWindow, an account is challenged with a
+	// CAPTCHA after LoginChallengeAfter failures and locked for LoginLockoutDuration after
+	// LoginMaxFailures; an IP address is locked after LoginIPMaxFailures across accounts
+	LoginMaxFailures     int
+	LoginIPMaxFailures   int
+	LoginFailureWindow   time.Duration
+	LoginLockoutDuration time.Duration
+	LoginChallengeAfter  int
+
+	// Login risk scoring: the weights of each anomaly in a login's score, capped at 1, and
+	// the score from which MFA is required. Logins are located with the MaxMind GeoIP2 web
+	// service when an account ID and license key are set.
+	LoginRiskImpossibleTravelWeight float64
+	LoginRiskNewCountryWeight       float64
+	LoginRiskNewDeviceWeight        float64
+	LoginRiskUnusualHourWeight      float64
+	LoginRiskMFAThreshold           float64
+	GeoIPEndpoint                   string
+	GeoIPAccountID                  string
+	GeoIPLicenseKey                 string
 }
 
 // Load loads configuration from environment variables
@@ -157,6 +488,7 @@ func Load() (*Config, error) {
 			ReadTimeout:  getDurationEnv("READ_TIMEOUT", 15*time.Second),
 			WriteTimeout: getDurationEnv("WRITE_TIMEOUT", 15*time.Second),
 			IdleTimeout:  getDurationEnv("IDLE_TIMEOUT", 60*time.Second),
+			StrictJSON:   getBoolEnv("STRICT_JSON", false),
 		},
 		Database: DatabaseConfig{
 			URL:                 getEnv("DATABASE_URL", ""),
@@ -189,6 +521,9 @@ func Load() (*Config, error) {
 			MaxMemory:          getEnv("REDIS_MAX_MEMORY", "256mb"),
 			EvictionPolicy:     getEnv("REDIS_EVICTION_POLICY", "allkeys-lru"),
 			CompressionLevel:   getIntEnv("REDIS_COMPRESSION_LEVEL", 6),
+
+			ResponseCacheTTL:    getDurationEnv("RESPONSE_CACHE_TTL", 5*time.Minute),
+			ResponseCacheRoutes: getRouteTTLEnv("RESPONSE_CACHE_ROUTE_TTLS"),
 		},
 		JWT: JWTConfig{
 			Secret:             getEnv("JWT_SECRET", ""),
@@ -196,10 +531,33 @@ func Load() (*Config, error) {
 			RefreshTokenExpiry: getDurationEnv("REFRESH_TOKEN_EXPIRY", 168*time.Hour),
 		},
 		AI: AIConfig{
-			Provider:     getEnv("AI_MODEL_PROVIDER", "openai"),
-			OpenAIKey:    getEnv("OPENAI_API_KEY", ""),
-			AnthropicKey: getEnv("ANTHROPIC_API_KEY", ""),
-			ModelName:    getEnv("AI_MODEL_NAME", "gpt-4-turbo-preview"),
+			Provider:             getEnv("AI_MODEL_PROVIDER", "openai"),
+			OpenAIKey:            getEnv("OPENAI_API_KEY", ""),
+			AnthropicKey:         getEnv("ANTHROPIC_API_KEY", ""),
+			ModelName:            getEnv("AI_MODEL_NAME", "gpt-4-turbo-preview"),
+			CryptoBatchWorkers:   getIntEnv("AI_CRYPTO_BATCH_WORKERS", 5),
+			CryptoSearchCacheTTL: getDurationEnv("AI_CRYPTO_SEARCH_CACHE_TTL", 10*time.Minute),
+			TTSModel:             getEnv("OPENAI_TTS_MODEL", "tts-1"),
+			TTSVoice:             getEnv("OPENAI_TTS_VOICE", "alloy"),
+			TTSCacheTTL:          getDurationEnv("VOICE_TTS_CACHE_TTL", 24*time.Hour),
+			TranslationModel:     getEnv("OPENAI_TRANSLATION_MODEL", "gpt-4o-mini"),
+			Reports: CryptoReportsConfig{
+				Retention:    getDurationEnv("AI_REPORT_RETENTION", 90*24*time.Hour),
+				MaxAttempts:  getIntEnv("AI_REPORT_MAX_ATTEMPTS", 3),
+				RetryDelay:   getDurationEnv("AI_REPORT_RETRY_DELAY", time.Minute),
+				PollInterval: getDurationEnv("AI_REPORT_POLL_INTERVAL", 30*time.Second),
+			},
+			ProviderRoutes:     getListMapEnv("AI_PROVIDER_ROUTES"),
+			ProviderMaxRetries: getIntEnv("AI_PROVIDER_MAX_RETRIES", 2),
+			ProviderRetryDelay: getDurationEnv("AI_PROVIDER_RETRY_DELAY", 500*time.Millisecond),
+			ProviderTimeout:    getDurationEnv("AI_PROVIDER_TIMEOUT", 30*time.Second),
+			News: NewsSourcesConfig{
+				RSSFeeds:         getListEnv("AI_NEWS_RSS_FEEDS", ","),
+				CryptoPanicKey:   getEnv("CRYPTOPANIC_API_KEY", ""),
+				RedditSubreddits: getListMapEnv("AI_NEWS_REDDIT_SUBREDDITS"),
+				DisabledSources:  getListEnv("AI_NEWS_DISABLED_SOURCES", ","),
+				SourceTimeout:    getDurationEnv("AI_NEWS_SOURCE_TIMEOUT", 10*time.Second),
+			},
 			OllamaConfig: OllamaConfig{
 				BaseURL:             getEnv("OLLAMA_BASE_URL", "http://localhost:11434"),
 				Model:               getEnv("OLLAMA_MODEL", "qwen3"),
@@ -223,15 +581,31 @@ func Load() (*Config, error) {
 				RetryDelay:          getDurationEnv("LMSTUDIO_RETRY_DELAY", 2*time.Second),
 				HealthCheckInterval: getDurationEnv("LMSTUDIO_HEALTH_CHECK_INTERVAL", 30*time.Second),
 			},
+			PredictionEvaluationInterval: getDurationEnv("AI_PREDICTION_EVALUATION_INTERVAL", 5*time.Minute),
+			ChatContextMessages:          getIntEnv("AI_CHAT_CONTEXT_MESSAGES", 10),
+			ChatSummaryMaxTokens:         getIntEnv("AI_CHAT_SUMMARY_MAX_TOKENS", 300),
+			ChatPromptCostPer1K:          getFloatEnv("AI_CHAT_PROMPT_COST_PER_1K", 0.0005),
+			ChatCompletionCostPer1K:      getFloatEnv("AI_CHAT_COMPLETION_COST_PER_1K", 0.0015),
+			ModelPrices:                  getListMapEnv("AI_MODEL_PRICES"),
+			UsageFlushInterval:           getDurationEnv("AI_USAGE_FLUSH_INTERVAL", time.Minute),
+			UserMonthlyBudgetUSD:         getFloatEnv("AI_USER_MONTHLY_BUDGET_USD", 0),
+			EmbeddingModel:               getEnv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
+			DocumentChunkWords:           getIntEnv("AI_DOCUMENT_CHUNK_WORDS", 200),
+			DocumentChunkOverlap:         getIntEnv("AI_DOCUMENT_CHUNK_OVERLAP", 40),
 		},
 		Web3: Web3Config{
 			EthereumRPC:        getEnv("ETHEREUM_RPC_URL", ""),
 			PolygonRPC:         getEnv("POLYGON_RPC_URL", ""),
 			ArbitrumRPC:        getEnv("ARBITRUM_RPC_URL", ""),
 			OptimismRPC:        getEnv("OPTIMISM_RPC_URL", ""),
+			BaseRPC:            getEnv("BASE_RPC_URL", ""),
 			BSCMainnetRPC:      getEnv("BSC_MAINNET_RPC_URL", ""),
 			BSCTestnetRPC:      getEnv("BSC_TESTNET_RPC_URL", ""),
 			SepoliaRPC:         getEnv("SEPOLIA_RPC_URL", ""),
+			PolygonAmoyRPC:     getEnv("POLYGON_AMOY_RPC_URL", ""),
+			ArbitrumSepoliaRPC: getEnv("ARBITRUM_SEPOLIA_RPC_URL", ""),
+			OptimismSepoliaRPC: getEnv("OPTIMISM_SEPOLIA_RPC_URL", ""),
+			BaseSepoliaRPC:     getEnv("BASE_SEPOLIA_RPC_URL", ""),
 			IPFSNodeURL:        getEnv("IPFS_NODE_URL", "http://localhost:5001"),
 			IPFSGateway:        getEnv("IPFS_GATEWAY", "https://ipfs.io"),
 			IPFSMaxFileSize:    int64(getIntEnv("IPFS_MAX_FILE_SIZE", 10*1024*1024)), // 10MB default
@@ -241,12 +615,78 @@ func Load() (*Config, error) {
 			TransactionTimeout: getDurationEnv("WEB3_TRANSACTION_TIMEOUT", 5*time.Minute),
 			MaxRetries:         getIntEnv("WEB3_MAX_RETRIES", 3),
 			RetryDelay:         getDurationEnv("WEB3_RETRY_DELAY", 2*time.Second),
+			SnapshotInterval:   getDurationEnv("PORTFOLIO_SNAPSHOT_INTERVAL", 5*time.Minute),
+
+			RebalanceCheckInterval: getDurationEnv("REBALANCE_CHECK_INTERVAL", time.Minute),
+
+			KillSwitchStaleData:     getDurationEnv("KILL_SWITCH_STALE_DATA", 2*time.Minute),
+			KillSwitchMaxDailyLoss:  getFloatEnv("KILL_SWITCH_MAX_DAILY_LOSS", 0.1),
+			KillSwitchFlatten:       getBoolEnv("KILL_SWITCH_FLATTEN", false),
+			KillSwitchCheckInterval: getDurationEnv("KILL_SWITCH_CHECK_INTERVAL", 10*time.Second),
+
+			DeFiRefreshInterval:  getDurationEnv("DEFI_REFRESH_INTERVAL", 5*time.Minute),
+			DeFiPoolsPerProtocol: getIntEnv("DEFI_POOLS_PER_PROTOCOL", 20),
+			AaveSubgraphURL:      getEnv("DEFI_AAVE_SUBGRAPH_URL", ""),
+			CompoundSubgraphURL:  getEnv("DEFI_COMPOUND_SUBGRAPH_URL", ""),
+			UniswapV3SubgraphURL: getEnv("DEFI_UNISWAP_V3_SUBGRAPH_URL", ""),
+
+			AlchemyAPIKey: getEnv("ALCHEMY_API_KEY", ""),
+
+			ChainalysisAPIKey:       getEnv("CHAINALYSIS_API_KEY", ""),
+			EtherscanAPIKey:         getEnv("ETHERSCAN_API_KEY", ""),
+			ScreeningCacheTTL:       getDurationEnv("SCREENING_CACHE_TTL", time.Hour),
+			ScreeningNewContractAge: getDurationEnv("SCREENING_NEW_CONTRACT_AGE", 7*24*time.Hour),
+
+			ApprovalCacheTTL:     getDurationEnv("APPROVAL_CACHE_TTL", 15*time.Minute),
+			ApprovalScanBlocks:   getIntEnv("APPROVAL_SCAN_BLOCKS", 200000),
+			ApprovalAlertMinRisk: getEnv("APPROVAL_ALERT_MIN_RISK", "high"),
+
+			SigningRequestTTL:      getDurationEnv("SIGNING_REQUEST_TTL", 15*time.Minute),
+			WalletConnectProjectID: getEnv("WALLETCONNECT_PROJECT_ID", ""),
+			WalletConnectRelayURL:  getEnv("WALLETCONNECT_RELAY_URL", "https://relay.walletconnect.org"),
+			WalletConnectAppURL:    getEnv("WALLETCONNECT_APP_URL", "http://localhost:3000"),
+
+			MultisigProposalTTL: getDurationEnv("MULTISIG_PROPOSAL_TTL", 72*time.Hour),
+			SafeAPIKey:          getEnv("SAFE_API_KEY", ""),
+
+			BridgeQuoteTTL:     getDurationEnv("BRIDGE_QUOTE_TTL", 2*time.Minute),
+			BridgePollInterval: getDurationEnv("BRIDGE_POLL_INTERVAL", 30*time.Second),
+			BridgeStuckAfter:   getDurationEnv("BRIDGE_STUCK_AFTER", time.Hour),
+			LiFiAPIKey:         getEnv("LIFI_API_KEY", ""),
+
+			TradeAnomalySensitivity:    getFloatEnv("TRADE_ANOMALY_SENSITIVITY", 0.8),
+			TradeAnomalyMaxSlippageBps: getFloatEnv("TRADE_ANOMALY_MAX_SLIPPAGE_BPS", 100),
+			TradeAnomalyMaxDrawdown:    getFloatEnv("TRADE_ANOMALY_MAX_DRAWDOWN", 0.1),
+
+			WatchOnlyPollInterval:    getDurationEnv("WATCH_ONLY_POLL_INTERVAL", 5*time.Minute),
+			WatchOnlyChangeThreshold: getFloatEnv("WATCH_ONLY_CHANGE_THRESHOLD", 0.01),
+
+			BalanceChainParallelism: getIntEnv("WEB3_BALANCE_CHAIN_PARALLELISM", 4),
+			BalanceChainTimeout:     getDurationEnv("WEB3_BALANCE_CHAIN_TIMEOUT", 5*time.Second),
+
+			DerivativesExchanges:      getSliceEnv("DERIVATIVES_EXCHANGES", []string{"binance", "bybit"}),
+			DerivativesSymbols:        getSliceEnv("DERIVATIVES_SYMBOLS", []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}),
+			DerivativesPollInterval:   getDurationEnv("DERIVATIVES_POLL_INTERVAL", 5*time.Minute),
+			DerivativesOIChangeWindow: getDurationEnv("DERIVATIVES_OI_CHANGE_WINDOW", 24*time.Hour),
 		},
 		Browser: BrowserConfig{
 			Headless:   getBoolEnv("CHROME_HEADLESS", true),
 			DisableGPU: getBoolEnv("CHROME_DISABLE_GPU", true),
 			NoSandbox:  getBoolEnv("CHROME_NO_SANDBOX", true),
 			Timeout:    getDurationEnv("BROWSER_TIMEOUT", 30*time.Second),
+
+			RecordingMaxSteps: getIntEnv("BROWSER_RECORDING_MAX_STEPS", 200),
+			RecordingMaxBytes: getIntEnv("BROWSER_RECORDING_MAX_BYTES", 10*1024*1024),
+
+			PoolSize:            getIntEnv("BROWSER_POOL_SIZE", 10),
+			PoolWarm:            getIntEnv("BROWSER_POOL_WARM", 2),
+			PoolCheckoutTimeout: getDurationEnv("BROWSER_POOL_CHECKOUT_TIMEOUT", 2*time.Second),
+			MaxSessionsPerUser:  getIntEnv("BROWSER_MAX_SESSIONS_PER_USER", 3),
+			InstanceMaxUses:     getIntEnv("BROWSER_INSTANCE_MAX_USES", 20),
+			InstanceMaxAge:      getDurationEnv("BROWSER_INSTANCE_MAX_AGE", 30*time.Minute),
+
+			LiveViewFPS:     getIntEnv("BROWSER_LIVE_VIEW_FPS", 5),
+			LiveViewQuality: getIntEnv("BROWSER_LIVE_VIEW_QUALITY", 60),
 		},
 		Terminal: TerminalConfig{
 			Host:         getEnv("TERMINAL_HOST", "0.0.0.0"),
@@ -257,6 +697,12 @@ func Load() (*Config, error) {
 			MaxSessions:  getIntEnv("TERMINAL_MAX_SESSIONS", 10),
 			SessionTTL:   getDurationEnv("TERMINAL_SESSION_TTL", 24*time.Hour),
 		},
+		WebSocket: WebSocketConfig{
+			PingInterval:   getDurationEnv("WS_PING_INTERVAL", 30*time.Second),
+			WriteTimeout:   getDurationEnv("WS_WRITE_TIMEOUT", 10*time.Second),
+			SendBufferSize: getIntEnv("WS_SEND_BUFFER_SIZE", 256),
+			Symbols:        getListEnv("WS_MARKET_SYMBOLS", ","),
+		},
 		Observability: ObservabilityConfig{
 			JaegerEndpoint: getEnv("JAEGER_ENDPOINT", "http://localhost:14268/api/traces"),
 			ServiceName:    getEnv("OTEL_SERVICE_NAME", "agentic-browser"),
@@ -264,17 +710,83 @@ func Load() (*Config, error) {
 			LogFormat:      getEnv("LOG_FORMAT", "json"),
 		},
 		RateLimit: RateLimitConfig{
-			RequestsPerMinute: getIntEnv("RATE_LIMIT_REQUESTS_PER_MINUTE", 100),
-			Burst:             getIntEnv("RATE_LIMIT_BURST", 20),
+			RequestsPerMinute:        getIntEnv("RATE_LIMIT_REQUESTS_PER_MINUTE", 100),
+			Burst:                    getIntEnv("RATE_LIMIT_BURST", 20),
+			PerUser:                  getBoolEnv("RATE_LIMIT_PER_USER", true),
+			AIHeavyRequestsPerMinute: getIntEnv("RATE_LIMIT_AI_HEAVY_PER_MINUTE", 10),
+			ReadRequestsPerMinute:    getIntEnv("RATE_LIMIT_READ_PER_MINUTE", 300),
+
+			APIKeyRequestsPerMinute: getIntEnv("RATE_LIMIT_API_KEY_PER_MINUTE", 120),
+			TrustedProxies:          getListEnv("RATE_LIMIT_TRUSTED_PROXIES", ","),
 		},
 		Security: SecurityConfig{
 			CORSAllowedOrigins: getSliceEnv("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
 			BCryptCost:         getIntEnv("BCRYPT_COST", 12),
+			AdminUsers:         getListEnv("ADMIN_USERS", ","),
+			RoutePolicy:        getRoutePolicyEnv("RBAC_ROUTE_POLICY", DefaultRoutePolicy),
+
+			AuditHotRetention:    getDurationEnv("AUDIT_HOT_RETENTION", 30*24*time.Hour),
+			AuditRetention:       getDurationEnv("AUDIT_RETENTION", 7*365*24*time.Hour),
+			AuditArchiveDir:      getEnv("AUDIT_ARCHIVE_DIR", ""),
+			AuditArchiveKey:      getEnv("AUDIT_ARCHIVE_KEY", ""),
+			AuditArchiveInterval: getDurationEnv("AUDIT_ARCHIVE_INTERVAL", time.Hour),
+
+			ErasureDataOwners:    getSliceEnv("PRIVACY_ERASURE_OWNERS", []string{"conversations", "behavior_profiles", "trading_history", "audit_log"}),
+			ErasureSigningKey:    getEnv("PRIVACY_ERASURE_SIGNING_KEY", getEnv("JWT_SECRET", "")),
+			ErasureCheckInterval: getDurationEnv("PRIVACY_ERASURE_INTERVAL", 30*time.Second),
+
+			ExchangeCredentialsKey: getEnv("EXCHANGE_CREDENTIALS_KEY", ""),
+			KeyEscrowPublicKeyFile: getEnv("KEY_ESCROW_PUBLIC_KEY_FILE", ""),
+			DashboardShareKey:      getEnv("DASHBOARD_SHARE_SIGNING_KEY", ""),
+
+			LoginMaxFailures:     getIntEnv("LOGIN_MAX_FAILURES", 5),
+			LoginIPMaxFailures:   getIntEnv("LOGIN_IP_MAX_FAILURES", 20),
+			LoginFailureWindow:   getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LoginLockoutDuration: getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			LoginChallengeAfter:  getIntEnv("LOGIN_CAPTCHA_AFTER", 3),
+
+			LoginRiskImpossibleTravelWeight: getFloatEnv("LOGIN_RISK_IMPOSSIBLE_TRAVEL_WEIGHT", 0.6),
+			LoginRiskNewCountryWeight:       getFloatEnv("LOGIN_RISK_NEW_COUNTRY_WEIGHT", 0.3),
+			LoginRiskNewDeviceWeight:        getFloatEnv("LOGIN_RISK_NEW_DEVICE_WEIGHT", 0.25),
+			LoginRiskUnusualHourWeight:      getFloatEnv("LOGIN_RISK_UNUSUAL_HOUR_WEIGHT", 0.15),
+			LoginRiskMFAThreshold:           getFloatEnv("LOGIN_RISK_MFA_THRESHOLD", 0.5),
+			GeoIPEndpoint:                   getEnv("GEOIP_ENDPOINT", ""),
+			GeoIPAccountID:                  getEnv("GEOIP_ACCOUNT_ID", ""),
+			GeoIPLicenseKey:                 getEnv("GEOIP_LICENSE_KEY", ""),
 		},
 		Logger: LoggerConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
+		Alerts: AlertsConfig{
+			SMTPHost:            getEnv("ALERT_SMTP_HOST", ""),
+			SMTPPort:            getIntEnv("ALERT_SMTP_PORT", 587),
+			SMTPUsername:        getEnv("ALERT_SMTP_USERNAME", ""),
+			SMTPPassword:        getEnv("ALERT_SMTP_PASSWORD", ""),
+			EmailFrom:           getEnv("ALERT_EMAIL_FROM", "alerts@crypto-browser.com"),
+			EmailTo:             getListEnv("ALERT_EMAIL_TO", ","),
+			SlackWebhookURL:     getEnv("ALERT_SLACK_WEBHOOK_URL", ""),
+			SlackChannel:        getEnv("ALERT_SLACK_CHANNEL", "#alerts"),
+			TelegramBotToken:    getEnv("ALERT_TELEGRAM_BOT_TOKEN", ""),
+			TelegramChatIDs:     getListEnv("ALERT_TELEGRAM_CHAT_IDS", ","),
+			WebhookURL:          getEnv("ALERT_WEBHOOK_URL", ""),
+			WebhookSecret:       getEnv("ALERT_WEBHOOK_SECRET", ""),
+			TopicChannels:       getListMapEnv("ALERT_TOPIC_CHANNELS"),
+			MaxDeliveryAttempts: getIntEnv("ALERT_DELIVERY_MAX_ATTEMPTS", 3),
+			RetryBaseDelay:      getDurationEnv("ALERT_DELIVERY_RETRY_DELAY", 2*time.Second),
+			DeliveryTimeout:     getDurationEnv("ALERT_DELIVERY_TIMEOUT", 10*time.Second),
+		},
+		Gateway: GatewayConfig{
+			CircuitFailureThreshold: getIntEnv("GATEWAY_CIRCUIT_FAILURE_THRESHOLD", 5),
+			CircuitCooldown:         getDurationEnv("GATEWAY_CIRCUIT_COOLDOWN", 30*time.Second),
+			UpstreamDialTimeout:     getDurationEnv("GATEWAY_UPSTREAM_DIAL_TIMEOUT", 5*time.Second),
+		},
+		Maintenance: MaintenanceConfig{
+			DrainTimeout:   getDurationEnv("MAINTENANCE_DRAIN_TIMEOUT", 2*time.Minute),
+			RetryAfter:     getDurationEnv("MAINTENANCE_RETRY_AFTER", 5*time.Minute),
+			SyncInterval:   getDurationEnv("MAINTENANCE_SYNC_INTERVAL", 5*time.Second),
+			EssentialPaths: getSliceEnv("MAINTENANCE_ESSENTIAL_PATHS", DefaultMaintenanceEssentialPaths),
+		},
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -339,19 +851,79 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 }
 
 func getSliceEnv(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
-		// Simple comma-separated parsing
-		result := []string{}
-		for _, item := range []string{value} {
-			if item != "" {
-				result = append(result, item)
+	if result := getListEnv(key, ","); len(result) > 0 {
+		return result
+	}
+	return defaultValue
+}
+
+func getListEnv(key, sep string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getListMapEnv parses "key:a,b;other:c" into lists per key
+func getListMapEnv(key string) map[string][]string {
+	result := make(map[string][]string)
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		name, values, found := strings.Cut(entry, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !found || name == "" {
+			continue
+		}
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				result[name] = append(result[name], value)
 			}
 		}
-		if len(result) > 0 {
-			return result
+	}
+	return result
+}
+
+// getRouteTTLEnv parses "pattern=ttl;pattern=ttl", keeping the order of the entries.
+// Entries with an invalid duration are skipped.
+func getRouteTTLEnv(key string) []RouteTTL {
+	var result []RouteTTL
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			continue
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(entry[i+1:]))
+		if err != nil {
+			continue
 		}
+		result = append(result, RouteTTL{Pattern: strings.TrimSpace(entry[:i]), TTL: ttl})
 	}
-	return defaultValue
+	return result
+}
+
+// getRoutePolicyEnv parses "pattern=role;pattern=role" over the defaults. An entry replaces
+// the default with the same pattern and is otherwise added.
+func getRoutePolicyEnv(key string, defaults []RouteRole) []RouteRole {
+	result := append([]RouteRole(nil), defaults...)
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			continue
+		}
+		route := RouteRole{Pattern: strings.TrimSpace(entry[:i]), Role: strings.ToLower(strings.TrimSpace(entry[i+1:]))}
+		replaced := false
+		for j := range result {
+			if result[j].Pattern == route.Pattern {
+				result[j], replaced = route, true
+			}
+		}
+		if !replaced {
+			result = append(result, route)
+		}
+	}
+	return result
 }
 
 // TerminalConfig contains terminal service configuration
@@ -365,6 +937,14 @@ type TerminalConfig struct {
 	SessionTTL   time.Duration `json:"session_ttl"`
 }
 
+// WebSocketConfig contains gateway WebSocket streaming configuration
+type WebSocketConfig struct {
+	PingInterval   time.Duration `json:"ping_interval"`
+	WriteTimeout   time.Duration `json:"write_timeout"`
+	SendBufferSize int           `json:"send_buffer_size"` // undelivered messages before a slow client is dropped
+	Symbols        []string      `json:"symbols"`          // Binance symbols streamed upstream; clients can subscribe to these only
+}
+
 // LoggerConfig contains logger configuration
 type LoggerConfig struct {
 	Level  string `json:"level"`
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRoutePolicyEnv(t *testing.T) {
+	defaults := []RouteRole{
+		{Pattern: "POST /web3/trading/", Role: "trader"},
+		{Pattern: "POST /web3/trading/killswitch", Role: "admin"},
+	}
+
+	t.Setenv("RBAC_ROUTE_POLICY", "")
+	assert.Equal(t, defaults, getRoutePolicyEnv("RBAC_ROUTE_POLICY", defaults))
+
+	// Entries replace defaults with the same pattern and add the others; malformed entries
+	// are skipped
+	t.Setenv("RBAC_ROUTE_POLICY", " POST /web3/trading/ = Admin ;GET /web3/reports=viewer;no-role;=admin")
+	assert.Equal(t, []RouteRole{
+		{Pattern: "POST /web3/trading/", Role: "admin"},
+		{Pattern: "POST /web3/trading/killswitch", Role: "admin"},
+		{Pattern: "GET /web3/reports", Role: "viewer"},
+	}, getRoutePolicyEnv("RBAC_ROUTE_POLICY", defaults))
+	assert.Equal(t, "trader", defaults[0].Role, "the defaults are not modified")
+
+	// Patterns with wildcards keep everything before the last "="
+	t.Setenv("RBAC_ROUTE_POLICY", "PUT /web3/trading/strategies/{name}/schedule=trader")
+	routes := getRoutePolicyEnv("RBAC_ROUTE_POLICY", defaults)
+	assert.Equal(t, RouteRole{Pattern: "PUT /web3/trading/strategies/{name}/schedule", Role: "trader"}, routes[2])
+}
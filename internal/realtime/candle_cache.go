@@ -0,0 +1,225 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// ErrInvalidCandleInterval is returned for intervals outside 1m to 1w
+	ErrInvalidCandleInterval = errors.New("invalid candle interval")
+	// ErrInvalidCandleRange is returned when a candle range is empty or too long
+	ErrInvalidCandleRange = errors.New("invalid candle range")
+)
+
+const (
+	// defaultCandleCount is the number of candles served when no start time is given
+	defaultCandleCount = 100
+	// candleFetchTimeout bounds one upstream backfill, which may page through many requests
+	candleFetchTimeout = 30 * time.Second
+	// weekOffset aligns weekly candles to Monday 00:00 UTC; the Unix epoch was a Thursday
+	weekOffset = 4 * 24 * time.Hour
+)
+
+// candleIntervals are the supported intervals in Binance notation
+var candleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// CandleIntervalDuration returns the length of a candle interval such as 1m, 4h or 1w
+func CandleIntervalDuration(interval string) (time.Duration, error) {
+	step, ok := candleIntervals[interval]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q, use one of 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 8h, 12h, 1d, 3d or 1w", ErrInvalidCandleInterval, interval)
+	}
+	return step, nil
+}
+
+// alignCandleTime returns the open time of the candle containing t
+func alignCandleTime(t time.Time, step time.Duration) time.Time {
+	var offset int64
+	if step == candleIntervals["1w"] {
+		offset = weekOffset.Milliseconds()
+	}
+	ms := t.UnixMilli() - offset
+	return time.UnixMilli(ms - ms%step.Milliseconds() + offset).UTC()
+}
+
+// compactSymbol strips separators from a symbol, so BTC-USDT and btc/usdt become BTCUSDT
+func compactSymbol(symbol string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", "/", "", "_", "").Replace(strings.TrimSpace(symbol)))
+}
+
+// CandleStore caches closed candles keyed by exchange, symbol, interval and open time
+type CandleStore interface {
+	// LoadCandles returns the cached candles opening in [from, to), oldest first
+	LoadCandles(ctx context.Context, exchange, symbol, interval string, from, to time.Time) ([]Candle, error)
+	// SaveCandles upserts closed candles
+	SaveCandles(ctx context.Context, exchange, interval string, candles []Candle) error
+}
+
+// candleSource fetches candles from an exchange's REST API
+type candleSource interface {
+	GetCandles(ctx context.Context, exchange, symbol, interval string, start, end time.Time) ([]Candle, error)
+}
+
+// CandleService serves historical candles from the cache and backfills missing ranges from the
+// exchange on demand. Only closed candles are cached, so the current candle is always fetched
+// fresh and returned with Closed unset. Concurrent requests missing the same range share one
+// upstream fetch.
+type CandleService struct {
+	logger  *observability.Logger
+	source  candleSource
+	store   CandleStore
+	fetches singleflight.Group
+}
+
+// NewCandleService creates a candle service fetching through the market data service's exchanges
+func NewCandleService(logger *observability.Logger, marketData *MarketDataService, store CandleStore) *CandleService {
+	return &CandleService{
+		logger: logger,
+		source: marketData,
+		store:  store,
+	}
+}
+
+// GetCandles returns the candles of an exchange symbol opening in [from, to), oldest first. A zero
+// to means now and a zero from means the last 100 candles.
+func (s *CandleService) GetCandles(ctx context.Context, exchange, symbol, interval string, from, to time.Time) ([]Candle, error) {
+	step, err := CandleIntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultCandleCount * step)
+	}
+	start := alignCandleTime(from, step)
+	if !to.After(start) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidCandleRange)
+	}
+	if count := to.Sub(start) / step; count > maxCandles {
+		return nil, fmt.Errorf("%w: range spans %d candles, at most %d are allowed", ErrInvalidCandleRange, count, maxCandles)
+	}
+	exchange = strings.ToLower(exchange)
+	symbol = compactSymbol(symbol)
+
+	cached, err := s.store.LoadCandles(ctx, exchange, symbol, interval, start, to)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load cached candles", map[string]interface{}{
+			"exchange": exchange,
+			"symbol":   symbol,
+			"interval": interval,
+			"error":    err.Error(),
+		})
+		cached = nil
+	}
+	byOpen := make(map[int64]Candle, len(cached))
+	for _, candle := range cached {
+		byOpen[candle.OpenTime.UnixMilli()] = candle
+	}
+
+	// Contiguous runs of open times without a cached candle are fetched as one range each
+	var gaps [][2]time.Time
+	for t := start; t.Before(to); t = t.Add(step) {
+		if _, ok := byOpen[t.UnixMilli()]; ok {
+			continue
+		}
+		if n := len(gaps); n > 0 && gaps[n-1][1].Add(step).Equal(t) {
+			gaps[n-1][1] = t
+			continue
+		}
+		gaps = append(gaps, [2]time.Time{t, t})
+	}
+
+	for _, gap := range gaps {
+		// The range ends at the close time of the last missing candle
+		fetched, err := s.backfill(ctx, exchange, symbol, interval, gap[0], gap[1].Add(step-time.Millisecond))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s %s candles from %s: %w", symbol, interval, exchange, err)
+		}
+		for _, candle := range fetched {
+			if candle.OpenTime.Before(start) || !candle.OpenTime.Before(to) {
+				continue
+			}
+			byOpen[candle.OpenTime.UnixMilli()] = candle
+		}
+	}
+
+	candles := make([]Candle, 0, len(byOpen))
+	for _, candle := range byOpen {
+		candles = append(candles, candle)
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].OpenTime.Before(candles[j].OpenTime)
+	})
+	return candles, nil
+}
+
+// backfill fetches a range from the exchange and caches its closed candles, sharing the fetch
+// with concurrent callers missing the same range
+func (s *CandleService) backfill(ctx context.Context, exchange, symbol, interval string, start, end time.Time) ([]Candle, error) {
+	key := fmt.Sprintf("%s|%s|%s|%d|%d", exchange, symbol, interval, start.UnixMilli(), end.UnixMilli())
+	result := s.fetches.DoChan(key, func() (interface{}, error) {
+		// Detached from the first caller so its cancellation doesn't fail the others
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), candleFetchTimeout)
+		defer cancel()
+
+		candles, err := s.source.GetCandles(fetchCtx, exchange, symbol, interval, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		closed := make([]Candle, 0, len(candles))
+		for _, candle := range candles {
+			if candle.Closed {
+				closed = append(closed, candle)
+			}
+		}
+		if len(closed) > 0 {
+			if err := s.store.SaveCandles(fetchCtx, exchange, interval, closed); err != nil {
+				s.logger.Warn(fetchCtx, "Failed to cache candles", map[string]interface{}{
+					"exchange": exchange,
+					"symbol":   symbol,
+					"interval": interval,
+					"error":    err.Error(),
+				})
+			}
+		}
+		return candles, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]Candle), nil
+	}
+}
@@ -0,0 +1,77 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// candleInsertBatch is the number of candles upserted per statement
+const candleInsertBatch = 500
+
+// postgresCandleStore implements CandleStore using the market_candles table
+type postgresCandleStore struct {
+	db *database.DB
+}
+
+func NewPostgresCandleStore(db *database.DB) CandleStore {
+	return &postgresCandleStore{db: db}
+}
+
+func (s *postgresCandleStore) LoadCandles(ctx context.Context, exchange, symbol, interval string, from, to time.Time) ([]Candle, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT open_time, close_time, open, high, low, close, volume FROM market_candles
+		WHERE exchange = $1 AND symbol = $2 AND candle_interval = $3 AND open_time >= $4 AND open_time < $5
+		ORDER BY open_time
+	`, exchange, symbol, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candles := make([]Candle, 0)
+	for rows.Next() {
+		candle := Candle{Symbol: symbol, Closed: true}
+		if err := rows.Scan(&candle.OpenTime, &candle.CloseTime, &candle.Open, &candle.High, &candle.Low,
+			&candle.Close, &candle.Volume); err != nil {
+			return nil, fmt.Errorf("failed to scan candle: %w", err)
+		}
+		candles = append(candles, candle)
+	}
+	return candles, rows.Err()
+}
+
+func (s *postgresCandleStore) SaveCandles(ctx context.Context, exchange, interval string, candles []Candle) error {
+	for len(candles) > 0 {
+		batch := candles
+		if len(batch) > candleInsertBatch {
+			batch = batch[:candleInsertBatch]
+		}
+		candles = candles[len(batch):]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*10)
+		for _, candle := range batch {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10))
+			args = append(args, exchange, candle.Symbol, interval, candle.OpenTime, candle.CloseTime,
+				candle.Open, candle.High, candle.Low, candle.Close, candle.Volume)
+		}
+
+		_, err := s.db.ExecWithMetrics(ctx, `
+			INSERT INTO market_candles (exchange, symbol, candle_interval, open_time, close_time, open, high, low, close, volume)
+			VALUES `+strings.Join(values, ", ")+`
+			ON CONFLICT (exchange, symbol, candle_interval, open_time) DO UPDATE SET
+				close_time = EXCLUDED.close_time, open = EXCLUDED.open, high = EXCLUDED.high,
+				low = EXCLUDED.low, close = EXCLUDED.close, volume = EXCLUDED.volume
+		`, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
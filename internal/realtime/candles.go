@@ -0,0 +1,148 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrCandlesUnsupported is returned when an exchange has no historical candle endpoint configured
+var ErrCandlesUnsupported = errors.New("historical candles not supported")
+
+const (
+	// binanceKlineLimit is the most candles Binance returns per request
+	binanceKlineLimit = 1000
+	// maxCandles bounds a single historical range request
+	maxCandles = 10000
+)
+
+// Candle is one OHLCV bar of historical market data
+type Candle struct {
+	Symbol    string          `json:"symbol"`
+	OpenTime  time.Time       `json:"open_time"`
+	CloseTime time.Time       `json:"close_time"`
+	Open      decimal.Decimal `json:"open"`
+	High      decimal.Decimal `json:"high"`
+	Low       decimal.Decimal `json:"low"`
+	Close     decimal.Decimal `json:"close"`
+	Volume    decimal.Decimal `json:"volume"`
+
+	// Closed is false for the current candle, whose values still change until CloseTime
+	Closed bool `json:"closed"`
+}
+
+// candleFetcher is implemented by exchange adapters that serve historical candles over REST
+type candleFetcher interface {
+	fetchCandles(ctx context.Context, client *http.Client, restURL, symbol, interval string, start, end time.Time) ([]Candle, error)
+}
+
+// GetCandles loads historical candles for an exchange symbol between start and end, oldest
+// first. The exchange only needs to be configured, not connected. Interval uses the
+// exchange's native notation, e.g. 1m, 1h or 1d for Binance.
+func (m *MarketDataService) GetCandles(ctx context.Context, exchange, symbol, interval string, start, end time.Time) ([]Candle, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+
+	var config *ExchangeConfig
+	for i := range m.config.Exchanges {
+		if strings.EqualFold(m.config.Exchanges[i].Name, exchange) {
+			config = &m.config.Exchanges[i]
+			break
+		}
+	}
+	if config == nil {
+		return nil, fmt.Errorf("%w: exchange %s is not configured", ErrCandlesUnsupported, exchange)
+	}
+
+	adapter, err := newExchangeAdapter(config.Name)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, ok := adapter.(candleFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCandlesUnsupported, exchange)
+	}
+
+	return fetcher.fetchCandles(ctx, m.httpClient, config.RESTUrl, symbol, interval, start, end)
+}
+
+func (binanceAdapter) fetchCandles(ctx context.Context, client *http.Client, restURL, symbol, interval string, start, end time.Time) ([]Candle, error) {
+	if restURL == "" {
+		restURL = binanceRESTUrl
+	}
+	symbol = compactSymbol(symbol)
+
+	var candles []Candle
+	from := start
+	for from.Before(end) {
+		query := url.Values{
+			"symbol":    {symbol},
+			"interval":  {interval},
+			"startTime": {strconv.FormatInt(from.UnixMilli(), 10)},
+			"endTime":   {strconv.FormatInt(end.UnixMilli(), 10)},
+			"limit":     {strconv.Itoa(binanceKlineLimit)},
+		}
+		endpoint := strings.TrimSuffix(restURL, "/") + "/api/v3/klines?" + query.Encode()
+
+		var rows [][]json.RawMessage
+		if err := getJSON(ctx, client, endpoint, &rows); err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			candle, err := parseBinanceKline(symbol, row)
+			if err != nil {
+				return nil, err
+			}
+			if n := len(candles); n > 0 && !candle.OpenTime.After(candles[n-1].OpenTime) {
+				continue
+			}
+			candles = append(candles, candle)
+		}
+		if len(candles) > maxCandles {
+			return nil, fmt.Errorf("range exceeds %d candles, use a shorter range or a longer interval", maxCandles)
+		}
+		if len(rows) < binanceKlineLimit {
+			break
+		}
+		from = candles[len(candles)-1].OpenTime.Add(time.Millisecond)
+	}
+
+	return candles, nil
+}
+
+// parseBinanceKline converts a kline row [openTime, open, high, low, close, volume, closeTime, ...]
+func parseBinanceKline(symbol string, row []json.RawMessage) (Candle, error) {
+	if len(row) < 7 {
+		return Candle{}, fmt.Errorf("malformed kline: %d fields", len(row))
+	}
+
+	var openTime, closeTime int64
+	var open, high, low, closePrice, volume string
+	for i, target := range []interface{}{&openTime, &open, &high, &low, &closePrice, &volume, &closeTime} {
+		if err := json.Unmarshal(row[i], target); err != nil {
+			return Candle{}, fmt.Errorf("malformed kline field %d: %w", i, err)
+		}
+	}
+
+	return Candle{
+		Symbol:    symbol,
+		OpenTime:  time.UnixMilli(openTime),
+		CloseTime: time.UnixMilli(closeTime),
+		Open:      parseDecimal(open),
+		High:      parseDecimal(high),
+		Low:       parseDecimal(low),
+		Close:     parseDecimal(closePrice),
+		Volume:    parseDecimal(volume),
+		Closed:    time.UnixMilli(closeTime).Before(time.Now()),
+	}, nil
+}
@@ -0,0 +1,366 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrDerivativesUnsupported is returned for exchanges without a perpetual futures collector
+	ErrDerivativesUnsupported = errors.New("perpetual futures data not supported")
+	// ErrInvalidDerivativesRange is returned when a history range is empty or too long
+	ErrInvalidDerivativesRange = errors.New("invalid derivatives range")
+	// ErrNoDerivativesData is returned when no snapshot has been collected for a symbol
+	ErrNoDerivativesData = errors.New("no derivatives data collected")
+)
+
+const (
+	binanceFuturesRESTUrl = "https://fapi.binance.com"
+	bybitRESTUrl          = "https://api.bybit.com"
+	// maxDerivativesRange bounds a single history request
+	maxDerivativesRange = 90 * 24 * time.Hour
+)
+
+// DerivativesSnapshot is one observation of a USDT-margined perpetual futures market
+type DerivativesSnapshot struct {
+	Exchange        string          `json:"exchange"`
+	Symbol          string          `json:"symbol"`
+	FundingRate     decimal.Decimal `json:"funding_rate"` // current funding period, 0.0001 is 0.01%
+	MarkPrice       decimal.Decimal `json:"mark_price"`
+	OpenInterest    decimal.Decimal `json:"open_interest"` // in the base asset
+	NextFundingTime time.Time       `json:"next_funding_time"`
+	Timestamp       time.Time       `json:"timestamp"`
+}
+
+// FundingRatePoint is one funding rate observation
+type FundingRatePoint struct {
+	Exchange        string          `json:"exchange"`
+	FundingRate     decimal.Decimal `json:"funding_rate"`
+	MarkPrice       decimal.Decimal `json:"mark_price"`
+	NextFundingTime time.Time       `json:"next_funding_time"`
+	Timestamp       time.Time       `json:"timestamp"`
+}
+
+// OpenInterestPoint is one open interest observation, valued at the mark price
+type OpenInterestPoint struct {
+	Exchange          string          `json:"exchange"`
+	OpenInterest      decimal.Decimal `json:"open_interest"`
+	OpenInterestValue decimal.Decimal `json:"open_interest_value"`
+	Timestamp         time.Time       `json:"timestamp"`
+}
+
+// DerivativesSummary is the current positioning in a symbol across exchanges
+type DerivativesSummary struct {
+	Symbol       string          `json:"symbol"`
+	FundingRate  decimal.Decimal `json:"funding_rate"`  // mean of the latest rate on each exchange
+	OpenInterest decimal.Decimal `json:"open_interest"` // sum of the latest open interest on each exchange
+	// OpenInterestChange is the share open interest changed by over Window, on the exchanges
+	// observed at both ends of it
+	OpenInterestChange float64       `json:"open_interest_change"`
+	Window             time.Duration `json:"window"`
+	Exchanges          []string      `json:"exchanges"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+// DerivativesStore persists derivatives snapshots
+type DerivativesStore interface {
+	SaveSnapshots(ctx context.Context, snapshots []DerivativesSnapshot) error
+	// ListSnapshots returns the snapshots of a symbol taken in [from, to], oldest first. An
+	// empty exchange matches every exchange.
+	ListSnapshots(ctx context.Context, exchange, symbol string, from, to time.Time) ([]DerivativesSnapshot, error)
+}
+
+// DerivativesConfig configures the perpetual futures collector
+type DerivativesConfig struct {
+	Exchanges      []string      // binance and bybit are supported
+	Symbols        []string      // USDT-margined perpetuals such as BTCUSDT
+	PollInterval   time.Duration // how often funding rates and open interest are sampled
+	OIChangeWindow time.Duration // window of DerivativesSummary.OpenInterestChange
+}
+
+// derivativesFetcher reads the current funding rate and open interest of a perpetual
+type derivativesFetcher func(ctx context.Context, client *http.Client, symbol string) (*DerivativesSnapshot, error)
+
+// derivativesFetchers are the supported exchanges' futures REST readers
+var derivativesFetchers = map[string]derivativesFetcher{
+	"binance": fetchBinanceDerivatives,
+	"bybit":   fetchBybitDerivatives,
+}
+
+// DerivativesCollector samples funding rates and open interest of perpetual futures on an
+// interval and serves the stored series
+type DerivativesCollector struct {
+	logger     *observability.Logger
+	store      DerivativesStore
+	httpClient *http.Client
+	config     DerivativesConfig
+}
+
+// NewDerivativesCollector creates a collector. It only reads stored data until started.
+func NewDerivativesCollector(logger *observability.Logger, store DerivativesStore, cfg DerivativesConfig) *DerivativesCollector {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Minute
+	}
+	if cfg.OIChangeWindow <= 0 {
+		cfg.OIChangeWindow = 24 * time.Hour
+	}
+
+	return &DerivativesCollector{
+		logger:     logger,
+		store:      store,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		config:     cfg,
+	}
+}
+
+// Start polls the configured exchanges and symbols on the configured interval until ctx is done
+func (c *DerivativesCollector) Start(ctx context.Context) {
+	go func() {
+		c.Poll(ctx)
+
+		ticker := time.NewTicker(c.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Poll(ctx)
+			}
+		}
+	}()
+
+	c.logger.Info(ctx, "Derivatives collector started", map[string]interface{}{
+		"exchanges": c.config.Exchanges,
+		"symbols":   c.config.Symbols,
+		"interval":  c.config.PollInterval.String(),
+	})
+}
+
+// Poll samples every configured exchange and symbol once and returns the number of snapshots stored
+func (c *DerivativesCollector) Poll(ctx context.Context) int {
+	snapshots := make([]DerivativesSnapshot, 0, len(c.config.Exchanges)*len(c.config.Symbols))
+	for _, exchange := range c.config.Exchanges {
+		exchange = strings.ToLower(exchange)
+		fetch, ok := derivativesFetchers[exchange]
+		if !ok {
+			c.logger.Warn(ctx, "Skipping exchange without perpetual futures support", map[string]interface{}{
+				"exchange": exchange,
+			})
+			continue
+		}
+
+		for _, symbol := range c.config.Symbols {
+			snapshot, err := fetch(ctx, c.httpClient, compactSymbol(symbol))
+			if err != nil {
+				c.logger.Warn(ctx, "Failed to fetch perpetual futures data", map[string]interface{}{
+					"exchange": exchange,
+					"symbol":   symbol,
+					"error":    err.Error(),
+				})
+				continue
+			}
+			snapshots = append(snapshots, *snapshot)
+		}
+	}
+
+	if len(snapshots) == 0 {
+		return 0
+	}
+	if err := c.store.SaveSnapshots(ctx, snapshots); err != nil {
+		c.logger.Error(ctx, "Failed to store derivatives snapshots", err)
+		return 0
+	}
+	return len(snapshots)
+}
+
+// GetFundingRates returns the funding rate history of a symbol in [from, to], oldest first. A
+// zero to means now, a zero from the 24 hours before to and an empty exchange every exchange.
+func (c *DerivativesCollector) GetFundingRates(ctx context.Context, exchange, symbol string, from, to time.Time) ([]FundingRatePoint, error) {
+	snapshots, err := c.history(ctx, exchange, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]FundingRatePoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		points = append(points, FundingRatePoint{
+			Exchange:        snapshot.Exchange,
+			FundingRate:     snapshot.FundingRate,
+			MarkPrice:       snapshot.MarkPrice,
+			NextFundingTime: snapshot.NextFundingTime,
+			Timestamp:       snapshot.Timestamp,
+		})
+	}
+	return points, nil
+}
+
+// GetOpenInterest returns the open interest history of a symbol, with the same range defaults
+// as GetFundingRates
+func (c *DerivativesCollector) GetOpenInterest(ctx context.Context, exchange, symbol string, from, to time.Time) ([]OpenInterestPoint, error) {
+	snapshots, err := c.history(ctx, exchange, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]OpenInterestPoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		points = append(points, OpenInterestPoint{
+			Exchange:          snapshot.Exchange,
+			OpenInterest:      snapshot.OpenInterest,
+			OpenInterestValue: snapshot.OpenInterest.Mul(snapshot.MarkPrice),
+			Timestamp:         snapshot.Timestamp,
+		})
+	}
+	return points, nil
+}
+
+// Summary returns the latest funding rate and open interest of a symbol across exchanges and
+// how open interest changed over the configured window
+func (c *DerivativesCollector) Summary(ctx context.Context, symbol string) (*DerivativesSummary, error) {
+	symbol = compactSymbol(symbol)
+	now := time.Now()
+	snapshots, err := c.store.ListSnapshots(ctx, "", symbol, now.Add(-c.config.OIChangeWindow), now)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoDerivativesData, symbol)
+	}
+
+	first := make(map[string]DerivativesSnapshot)
+	last := make(map[string]DerivativesSnapshot)
+	for _, snapshot := range snapshots {
+		if _, ok := first[snapshot.Exchange]; !ok {
+			first[snapshot.Exchange] = snapshot
+		}
+		last[snapshot.Exchange] = snapshot
+	}
+
+	summary := &DerivativesSummary{
+		Symbol:    symbol,
+		Window:    c.config.OIChangeWindow,
+		Exchanges: make([]string, 0, len(last)),
+	}
+	var fundingSum, oiBefore, oiAfter decimal.Decimal
+	for exchange, latest := range last {
+		summary.Exchanges = append(summary.Exchanges, exchange)
+		fundingSum = fundingSum.Add(latest.FundingRate)
+		summary.OpenInterest = summary.OpenInterest.Add(latest.OpenInterest)
+		if latest.Timestamp.After(summary.UpdatedAt) {
+			summary.UpdatedAt = latest.Timestamp
+		}
+		if earliest := first[exchange]; earliest.Timestamp.Before(latest.Timestamp) {
+			oiBefore = oiBefore.Add(earliest.OpenInterest)
+			oiAfter = oiAfter.Add(latest.OpenInterest)
+		}
+	}
+	sort.Strings(summary.Exchanges)
+	summary.FundingRate = fundingSum.Div(decimal.NewFromInt(int64(len(last))))
+	if oiBefore.IsPositive() {
+		summary.OpenInterestChange, _ = oiAfter.Sub(oiBefore).Div(oiBefore).Float64()
+	}
+	return summary, nil
+}
+
+// history validates a history request and loads its snapshots
+func (c *DerivativesCollector) history(ctx context.Context, exchange, symbol string, from, to time.Time) ([]DerivativesSnapshot, error) {
+	exchange = strings.ToLower(exchange)
+	if _, ok := derivativesFetchers[exchange]; exchange != "" && !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDerivativesUnsupported, exchange)
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-24 * time.Hour)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidDerivativesRange)
+	}
+	if to.Sub(from) > maxDerivativesRange {
+		return nil, fmt.Errorf("%w: range exceeds %d days", ErrInvalidDerivativesRange, int(maxDerivativesRange.Hours()/24))
+	}
+
+	return c.store.ListSnapshots(ctx, exchange, compactSymbol(symbol), from, to)
+}
+
+func fetchBinanceDerivatives(ctx context.Context, client *http.Client, symbol string) (*DerivativesSnapshot, error) {
+	query := url.Values{"symbol": {symbol}}.Encode()
+
+	var premium struct {
+		MarkPrice       string `json:"markPrice"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := getJSON(ctx, client, binanceFuturesRESTUrl+"/fapi/v1/premiumIndex?"+query, &premium); err != nil {
+		return nil, err
+	}
+
+	var openInterest struct {
+		OpenInterest string `json:"openInterest"`
+	}
+	if err := getJSON(ctx, client, binanceFuturesRESTUrl+"/fapi/v1/openInterest?"+query, &openInterest); err != nil {
+		return nil, err
+	}
+
+	return &DerivativesSnapshot{
+		Exchange:        "binance",
+		Symbol:          symbol,
+		FundingRate:     parseDecimal(premium.LastFundingRate),
+		MarkPrice:       parseDecimal(premium.MarkPrice),
+		OpenInterest:    parseDecimal(openInterest.OpenInterest),
+		NextFundingTime: time.UnixMilli(premium.NextFundingTime),
+		Timestamp:       time.Now().UTC(),
+	}, nil
+}
+
+func fetchBybitDerivatives(ctx context.Context, client *http.Client, symbol string) (*DerivativesSnapshot, error) {
+	query := url.Values{"category": {"linear"}, "symbol": {symbol}}.Encode()
+
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				MarkPrice       string `json:"markPrice"`
+				FundingRate     string `json:"fundingRate"`
+				NextFundingTime string `json:"nextFundingTime"`
+				OpenInterest    string `json:"openInterest"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, client, bybitRESTUrl+"/v5/market/tickers?"+query, &resp); err != nil {
+		return nil, err
+	}
+	if resp.RetCode != 0 {
+		return nil, fmt.Errorf("bybit error %d: %s", resp.RetCode, resp.RetMsg)
+	}
+	if len(resp.Result.List) == 0 {
+		return nil, fmt.Errorf("bybit has no linear perpetual %s", symbol)
+	}
+
+	ticker := resp.Result.List[0]
+	nextFunding, _ := strconv.ParseInt(ticker.NextFundingTime, 10, 64)
+	return &DerivativesSnapshot{
+		Exchange:        "bybit",
+		Symbol:          symbol,
+		FundingRate:     parseDecimal(ticker.FundingRate),
+		MarkPrice:       parseDecimal(ticker.MarkPrice),
+		OpenInterest:    parseDecimal(ticker.OpenInterest),
+		NextFundingTime: time.UnixMilli(nextFunding),
+		Timestamp:       time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// maxDerivativesRows bounds a single history query
+const maxDerivativesRows = 50000
+
+// postgresDerivativesStore implements DerivativesStore using the market_derivatives_snapshots table
+type postgresDerivativesStore struct {
+	db *database.DB
+}
+
+func NewPostgresDerivativesStore(db *database.DB) DerivativesStore {
+	return &postgresDerivativesStore{db: db}
+}
+
+func (s *postgresDerivativesStore) SaveSnapshots(ctx context.Context, snapshots []DerivativesSnapshot) error {
+	values := make([]string, 0, len(snapshots))
+	args := make([]interface{}, 0, len(snapshots)*7)
+	for _, snapshot := range snapshots {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7))
+		args = append(args, snapshot.Exchange, snapshot.Symbol, snapshot.Timestamp, snapshot.FundingRate,
+			snapshot.MarkPrice, snapshot.OpenInterest, snapshot.NextFundingTime)
+	}
+
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO market_derivatives_snapshots (exchange, symbol, observed_at, funding_rate, mark_price, open_interest, next_funding_time)
+		VALUES `+strings.Join(values, ", ")+`
+		ON CONFLICT (exchange, symbol, observed_at) DO NOTHING
+	`, args...)
+	return err
+}
+
+func (s *postgresDerivativesStore) ListSnapshots(ctx context.Context, exchange, symbol string, from, to time.Time) ([]DerivativesSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT exchange, symbol, observed_at, funding_rate, mark_price, open_interest, next_funding_time
+		FROM market_derivatives_snapshots
+		WHERE symbol = $1 AND ($2 = '' OR exchange = $2) AND observed_at >= $3 AND observed_at <= $4
+		ORDER BY observed_at
+		LIMIT $5
+	`, symbol, exchange, from, to, maxDerivativesRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]DerivativesSnapshot, 0)
+	for rows.Next() {
+		var snapshot DerivativesSnapshot
+		if err := rows.Scan(&snapshot.Exchange, &snapshot.Symbol, &snapshot.Timestamp, &snapshot.FundingRate,
+			&snapshot.MarkPrice, &snapshot.OpenInterest, &snapshot.NextFundingTime); err != nil {
+			return nil, fmt.Errorf("failed to scan derivatives snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,336 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalSymbol(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{"BTCUSDT", "BTCUSD"},
+		{"btcusdt", "BTCUSD"},
+		{"BTC-USD", "BTCUSD"},
+		{"XBT/USD", "BTCUSD"},
+		{"BTC_USDC", "BTCUSD"},
+		{" eth/usdt ", "ETHUSD"},
+		{"ETHFDUSD", "ETHUSD"},
+		{"XDG/EUR", "DOGEEUR"},
+		{"ETHBTC", "ETHBTC"},
+		{"ETH/XBT", "ETHBTC"},
+		{"SOLEUR", "SOLEUR"},
+		{"USDT", "USDT"},       // a bare quote asset has no base to split off
+		{"UNKNOWN", "UNKNOWN"}, // unrecognized quotes pass through
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CanonicalSymbol(tt.symbol), tt.symbol)
+	}
+}
+
+func assertLevels(t *testing.T, want []OrderBookLevel, got []OrderBookLevel) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.True(t, got[i].Price.Equal(want[i].Price) && got[i].Size.Equal(want[i].Size),
+			"level %d: want %s@%s, got %s@%s", i, want[i].Size, want[i].Price, got[i].Size, got[i].Price)
+	}
+}
+
+func TestBinanceAdapter_ParseMessage(t *testing.T) {
+	adapter := binanceAdapter{}
+	d := decimal.RequireFromString
+
+	t.Run("ticker", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("binance", []byte(`{"e":"24hrTicker","E":1700000000123,"s":"BTCUSDT","p":"-150.50","P":"-0.4","c":"37000.10","C":1700000000000,"b":"37000.00","B":"2","a":"37000.20","A":"1","h":"37500","l":"36500","v":"1234.5","q":"45000000","L":99}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.updates, 1)
+		assert.Empty(t, parsed.books)
+
+		update := parsed.updates[0]
+		assert.Equal(t, "binance", update.Exchange)
+		assert.Equal(t, "BTCUSD", update.Symbol)
+		assert.Equal(t, "BTCUSDT", update.ExchangeSymbol)
+		assert.Equal(t, UpdateTypeTicker, update.Type)
+		assert.True(t, update.Price.Equal(d("37000.10")), "price %s", update.Price)
+		assert.True(t, update.Bid.Equal(d("37000.00")), "bid %s", update.Bid)
+		assert.True(t, update.Ask.Equal(d("37000.20")), "ask %s", update.Ask)
+		assert.True(t, update.High24h.Equal(d("37500")))
+		assert.True(t, update.Low24h.Equal(d("36500")))
+		assert.True(t, update.Volume.Equal(d("1234.5")), "base volume rather than quote volume")
+		assert.True(t, update.Change24h.Equal(d("-150.50")), "change %s", update.Change24h)
+		assert.Equal(t, time.UnixMilli(1700000000123), update.Timestamp)
+		assert.NotEmpty(t, update.Metadata)
+	})
+
+	t.Run("trade", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("binance", []byte(`{"e":"trade","E":1700000000500,"s":"ETHUSDT","t":12345,"p":"2000.50","q":"0.75","T":1700000000499,"m":true,"M":true}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.updates, 1)
+
+		update := parsed.updates[0]
+		assert.Equal(t, "ETHUSD", update.Symbol)
+		assert.Equal(t, UpdateTypeTrade, update.Type)
+		assert.True(t, update.Price.Equal(d("2000.50")), "price %s", update.Price)
+		assert.True(t, update.Volume.Equal(d("0.75")), "volume %s", update.Volume)
+		assert.Equal(t, int64(12345), update.Sequence)
+		assert.Equal(t, time.UnixMilli(1700000000499), update.Timestamp, "trade time over event time")
+	})
+
+	t.Run("depth update", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("binance", []byte(`{"e":"depthUpdate","E":1700000000600,"s":"BTCUSDT","U":157,"u":160,"b":[["37000.00","1.5"],["36999.00","0"]],"a":[["37001.00","2"]]}`))
+		require.NoError(t, err)
+		assert.Empty(t, parsed.updates)
+		require.Len(t, parsed.books, 1)
+
+		book := parsed.books[0]
+		assert.Equal(t, "BTCUSDT", book.Symbol)
+		assert.False(t, book.Snapshot)
+		assert.Equal(t, int64(157), book.FirstSequence)
+		assert.Equal(t, int64(160), book.Sequence)
+		assertLevels(t, []OrderBookLevel{level("37000", "1.5"), level("36999", "0")}, book.Bids)
+		assertLevels(t, []OrderBookLevel{level("37001", "2")}, book.Asks)
+		assert.Equal(t, time.UnixMilli(1700000000600), book.Timestamp)
+	})
+
+	t.Run("control messages", func(t *testing.T) {
+		for _, raw := range []string{`{"result":null,"id":1}`, `{"e":"kline","E":1,"s":"BTCUSDT"}`} {
+			parsed, err := adapter.parseMessage("binance", []byte(raw))
+			require.NoError(t, err, raw)
+			assert.Empty(t, parsed.updates, raw)
+			assert.Empty(t, parsed.books, raw)
+		}
+	})
+
+	_, err := adapter.parseMessage("binance", []byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestBinanceAdapter_FetchOrderBookSnapshot(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/depth" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"lastUpdateId":1027024,"bids":[["4.00000000","431.00000000"]],"asks":[["4.00000200","12.00000000"],["4.00000300","5"]]}`))
+	}))
+	defer server.Close()
+
+	adapter := binanceAdapter{}
+	book, err := adapter.fetchOrderBookSnapshot(context.Background(), server.Client(), server.URL+"/", "btcusdt")
+	require.NoError(t, err)
+	assert.Equal(t, "limit=1000&symbol=BTCUSDT", query)
+	assert.Equal(t, "btcusdt", book.Symbol)
+	assert.True(t, book.Snapshot)
+	assert.Equal(t, int64(1027024), book.Sequence)
+	assertLevels(t, []OrderBookLevel{level("4", "431")}, book.Bids)
+	assertLevels(t, []OrderBookLevel{level("4.000002", "12"), level("4.000003", "5")}, book.Asks)
+
+	_, err = adapter.fetchOrderBookSnapshot(context.Background(), server.Client(), server.URL+"/missing", "BTCUSDT")
+	assert.Error(t, err, "non-200 responses fail")
+	assert.True(t, adapter.seedsOrderBookOverREST())
+}
+
+func TestCoinbaseAdapter_ParseMessage(t *testing.T) {
+	adapter := coinbaseAdapter{}
+	d := decimal.RequireFromString
+	at := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+
+	t.Run("ticker", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("coinbase", []byte(`{"type":"ticker","sequence":5928281084,"product_id":"BTC-USD","price":"37000.10","open_24h":"36000.10","volume_24h":"15000.5","low_24h":"35900","high_24h":"37200","best_bid":"37000.00","best_ask":"37000.20","side":"buy","time":"2023-11-14T22:13:20Z","trade_id":1,"last_size":"0.01"}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.updates, 1)
+
+		update := parsed.updates[0]
+		assert.Equal(t, "coinbase", update.Exchange)
+		assert.Equal(t, "BTCUSD", update.Symbol)
+		assert.Equal(t, "BTC-USD", update.ExchangeSymbol)
+		assert.Equal(t, UpdateTypeTicker, update.Type)
+		assert.True(t, update.Price.Equal(d("37000.10")))
+		assert.True(t, update.Volume.Equal(d("15000.5")))
+		assert.True(t, update.Bid.Equal(d("37000.00")))
+		assert.True(t, update.Ask.Equal(d("37000.20")))
+		assert.True(t, update.High24h.Equal(d("37200")))
+		assert.True(t, update.Low24h.Equal(d("35900")))
+		assert.True(t, update.Change24h.Equal(d("1000")), "change is price less the 24h open, got %s", update.Change24h)
+		assert.Equal(t, int64(5928281084), update.Sequence)
+		assert.True(t, update.Timestamp.Equal(at))
+	})
+
+	t.Run("match", func(t *testing.T) {
+		for _, kind := range []string{"match", "last_match"} {
+			parsed, err := adapter.parseMessage("coinbase", []byte(`{"type":"`+kind+`","trade_id":10,"sequence":50,"maker_order_id":"a","taker_order_id":"b","time":"2023-11-14T22:13:20Z","product_id":"ETH-USDC","size":"1.25","price":"2000.5","side":"sell"}`))
+			require.NoError(t, err, kind)
+			require.Len(t, parsed.updates, 1, kind)
+
+			update := parsed.updates[0]
+			assert.Equal(t, "ETHUSD", update.Symbol)
+			assert.Equal(t, UpdateTypeTrade, update.Type)
+			assert.True(t, update.Price.Equal(d("2000.5")))
+			assert.True(t, update.Volume.Equal(d("1.25")))
+			assert.Equal(t, int64(50), update.Sequence)
+		}
+	})
+
+	t.Run("level2 snapshot", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("coinbase", []byte(`{"type":"snapshot","product_id":"BTC-USD","bids":[["37000.00","1.5"],["36999.50","2"]],"asks":[["37000.50","0.5"]]}`))
+		require.NoError(t, err)
+		assert.Empty(t, parsed.updates)
+		require.Len(t, parsed.books, 1)
+
+		book := parsed.books[0]
+		assert.Equal(t, "BTC-USD", book.Symbol)
+		assert.True(t, book.Snapshot)
+		assert.Zero(t, book.Sequence, "level2 is unsequenced")
+		assertLevels(t, []OrderBookLevel{level("37000", "1.5"), level("36999.5", "2")}, book.Bids)
+		assertLevels(t, []OrderBookLevel{level("37000.5", "0.5")}, book.Asks)
+		assert.False(t, book.Timestamp.IsZero(), "a missing time defaults to now")
+	})
+
+	t.Run("level2 update", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("coinbase", []byte(`{"type":"l2update","product_id":"BTC-USD","changes":[["buy","37000.00","0"],["sell","37001.00","3"],["buy","36998.00","1"]],"time":"2023-11-14T22:13:20Z"}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.books, 1)
+
+		book := parsed.books[0]
+		assert.False(t, book.Snapshot)
+		assertLevels(t, []OrderBookLevel{level("37000", "0"), level("36998", "1")}, book.Bids)
+		assertLevels(t, []OrderBookLevel{level("37001", "3")}, book.Asks)
+		assert.True(t, book.Timestamp.Equal(at))
+	})
+
+	t.Run("control messages", func(t *testing.T) {
+		for _, raw := range []string{
+			`{"type":"subscriptions","channels":[{"name":"ticker","product_ids":["BTC-USD"]}]}`,
+			`{"type":"heartbeat","sequence":90,"last_trade_id":20,"product_id":"BTC-USD","time":"2023-11-14T22:13:20Z"}`,
+		} {
+			parsed, err := adapter.parseMessage("coinbase", []byte(raw))
+			require.NoError(t, err, raw)
+			assert.Empty(t, parsed.updates, raw)
+			assert.Empty(t, parsed.books, raw)
+		}
+	})
+
+	_, err := adapter.parseMessage("coinbase", []byte(`{"type":"error","message":"Failed to subscribe","reason":"BTC-XYZ is not a valid product"}`))
+	assert.ErrorContains(t, err, "BTC-XYZ is not a valid product")
+
+	_, err = adapter.fetchOrderBookSnapshot(context.Background(), http.DefaultClient, "", "BTC-USD")
+	assert.Error(t, err, "coinbase books come from the stream")
+	assert.False(t, adapter.seedsOrderBookOverREST())
+}
+
+func TestKrakenAdapter_ParseMessage(t *testing.T) {
+	adapter := krakenAdapter{}
+	d := decimal.RequireFromString
+
+	t.Run("ticker", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("kraken", []byte(`{"channel":"ticker","type":"update","data":[{"symbol":"XBT/USD","bid":37000.0,"bid_qty":0.5,"ask":37000.2,"ask_qty":1.1,"last":37000.1,"volume":1234.5,"vwap":36800,"low":36500,"high":37500,"change":-150.5,"change_pct":-0.4}]}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.updates, 1)
+
+		update := parsed.updates[0]
+		assert.Equal(t, "kraken", update.Exchange)
+		assert.Equal(t, "BTCUSD", update.Symbol)
+		assert.Equal(t, "XBT/USD", update.ExchangeSymbol)
+		assert.Equal(t, UpdateTypeTicker, update.Type)
+		assert.True(t, update.Price.Equal(d("37000.1")), "price %s", update.Price)
+		assert.True(t, update.Bid.Equal(d("37000")))
+		assert.True(t, update.Ask.Equal(d("37000.2")))
+		assert.True(t, update.Volume.Equal(d("1234.5")))
+		assert.True(t, update.High24h.Equal(d("37500")))
+		assert.True(t, update.Low24h.Equal(d("36500")))
+		assert.True(t, update.Change24h.Equal(d("-150.5")))
+	})
+
+	t.Run("trades", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("kraken", []byte(`{"channel":"trade","type":"update","data":[{"symbol":"ETH/USD","side":"buy","price":2000.5,"qty":0.75,"ord_type":"market","trade_id":4665906,"timestamp":"2023-11-14T22:13:20.123456Z"},{"symbol":"ETH/USD","side":"sell","price":2000.4,"qty":1,"ord_type":"limit","trade_id":4665907,"timestamp":"2023-11-14T22:13:21Z"}]}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.updates, 2, "one update per trade")
+
+		update := parsed.updates[0]
+		assert.Equal(t, "ETHUSD", update.Symbol)
+		assert.Equal(t, UpdateTypeTrade, update.Type)
+		assert.True(t, update.Price.Equal(d("2000.5")))
+		assert.True(t, update.Volume.Equal(d("0.75")))
+		assert.Equal(t, int64(4665906), update.Sequence)
+		assert.True(t, update.Timestamp.Equal(time.Date(2023, 11, 14, 22, 13, 20, 123456000, time.UTC)))
+		assert.Equal(t, int64(4665907), parsed.updates[1].Sequence)
+	})
+
+	t.Run("book snapshot and update", func(t *testing.T) {
+		parsed, err := adapter.parseMessage("kraken", []byte(`{"channel":"book","type":"snapshot","data":[{"symbol":"XBT/USD","bids":[{"price":37000.0,"qty":1.5},{"price":36999.5,"qty":2}],"asks":[{"price":37000.5,"qty":0.5}],"checksum":2114181697}]}`))
+		require.NoError(t, err)
+		assert.Empty(t, parsed.updates)
+		require.Len(t, parsed.books, 1)
+
+		book := parsed.books[0]
+		assert.Equal(t, "XBT/USD", book.Symbol)
+		assert.True(t, book.Snapshot)
+		assert.Equal(t, krakenBookDepth, book.MaxDepth)
+		assertLevels(t, []OrderBookLevel{level("37000", "1.5"), level("36999.5", "2")}, book.Bids)
+		assertLevels(t, []OrderBookLevel{level("37000.5", "0.5")}, book.Asks)
+
+		parsed, err = adapter.parseMessage("kraken", []byte(`{"channel":"book","type":"update","data":[{"symbol":"XBT/USD","bids":[{"price":37000.0,"qty":0}],"asks":[],"checksum":1,"timestamp":"2023-11-14T22:13:20Z"}]}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.books, 1)
+		book = parsed.books[0]
+		assert.False(t, book.Snapshot)
+		assertLevels(t, []OrderBookLevel{level("37000", "0")}, book.Bids)
+		assert.Empty(t, book.Asks)
+		assert.True(t, book.Timestamp.Equal(time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)))
+	})
+
+	t.Run("control messages", func(t *testing.T) {
+		for _, raw := range []string{
+			`{"channel":"heartbeat"}`,
+			`{"channel":"status","type":"update","data":[{"system":"online","api_version":"v2"}]}`,
+			`{"method":"subscribe","result":{"channel":"ticker","symbol":"XBT/USD"},"success":true,"time_in":"2023-11-14T22:13:20Z"}`,
+		} {
+			parsed, err := adapter.parseMessage("kraken", []byte(raw))
+			require.NoError(t, err, raw)
+			assert.Empty(t, parsed.updates, raw)
+			assert.Empty(t, parsed.books, raw)
+		}
+	})
+
+	_, err := adapter.parseMessage("kraken", []byte(`{"method":"subscribe","error":"Currency pair not supported XYZ/USD","success":false}`))
+	assert.ErrorContains(t, err, "Currency pair not supported")
+	_, err = adapter.parseMessage("kraken", []byte(`{"channel":"ticker","data":[{"symbol":"XBT/USD","last":"not a number"}]}`))
+	assert.Error(t, err)
+}
+
+func TestSubscribeMessages(t *testing.T) {
+	messages, err := binanceAdapter{}.subscribeMessages([]string{"BTCUSDT"}, []string{"ticker", "orderbook"})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"btcusdt@ticker", "btcusdt@depth@100ms"}, messages[0].(map[string]interface{})["params"])
+
+	messages, err = coinbaseAdapter{}.subscribeMessages([]string{"BTC-USD"}, []string{"trade", "orderbook"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"matches", "level2_batch"}, messages[0].(map[string]interface{})["channels"])
+
+	messages, err = krakenAdapter{}.subscribeMessages([]string{"XBT/USD"}, []string{"ticker", "orderbook"})
+	require.NoError(t, err)
+	require.Len(t, messages, 2, "kraken subscribes one channel per message")
+	book := messages[1].(map[string]interface{})["params"].(map[string]interface{})
+	assert.Equal(t, "book", book["channel"])
+	assert.Equal(t, krakenBookDepth, book["depth"])
+
+	for name, adapter := range map[string]exchangeAdapter{"binance": binanceAdapter{}, "coinbase": coinbaseAdapter{}, "kraken": krakenAdapter{}} {
+		_, err := adapter.subscribeMessages([]string{"BTCUSD"}, []string{"candles"})
+		assert.Error(t, err, name)
+	}
+	_, err = newExchangeAdapter("bitfinex")
+	assert.Error(t, err)
+}
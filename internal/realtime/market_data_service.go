@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,11 +15,15 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-// MarketDataService provides real-time market data from multiple exchanges
+// MarketDataService provides real-time market data from multiple exchanges. Updates are keyed
+// by canonical symbol (see CanonicalSymbol), so a subscription receives a merged stream from
+// every connected exchange with each update tagged by its source exchange.
 type MarketDataService struct {
 	logger      *observability.Logger
 	connections map[string]*ExchangeConnection
-	subscribers map[string][]chan MarketUpdate
+	subscribers map[string][]*subscription
+	books       map[string]map[string]*localOrderBook // canonical symbol -> exchange -> book
+	httpClient  *http.Client
 	config      MarketDataConfig
 	mu          sync.RWMutex
 	ctx         context.Context
@@ -32,12 +38,15 @@ type MarketDataConfig struct {
 	MaxReconnects   int              `json:"max_reconnects"`
 	BufferSize      int              `json:"buffer_size"`
 	EnableHeartbeat bool             `json:"enable_heartbeat"`
+	StaleAfter      time.Duration    `json:"stale_after"` // a connection without messages for this long is unhealthy
 }
 
-// ExchangeConfig holds configuration for a specific exchange
+// ExchangeConfig holds configuration for a specific exchange. Name selects the protocol adapter
+// (binance, coinbase or kraken) and Symbols use the exchange's native format.
 type ExchangeConfig struct {
 	Name      string            `json:"name"`
 	WSUrl     string            `json:"ws_url"`
+	RESTUrl   string            `json:"rest_url,omitempty"` // order book snapshots; defaults per exchange
 	APIKey    string            `json:"api_key,omitempty"`
 	APISecret string            `json:"api_secret,omitempty"`
 	Symbols   []string          `json:"symbols"`
@@ -48,22 +57,32 @@ type ExchangeConfig struct {
 
 // ExchangeConnection represents a WebSocket connection to an exchange
 type ExchangeConnection struct {
-	Name         string
-	Config       ExchangeConfig
-	Conn         *websocket.Conn
-	LastPing     time.Time
-	LastPong     time.Time
-	Reconnects   int
-	IsConnected  bool
-	MessageCount int64
-	ErrorCount   int64
-	mu           sync.RWMutex
+	Name          string
+	Config        ExchangeConfig
+	Conn          *websocket.Conn
+	ConnectedAt   time.Time
+	LastPing      time.Time
+	LastPong      time.Time
+	LastMessageAt time.Time
+	LastError     string
+	Reconnects    int
+	IsConnected   bool
+	MessageCount  int64
+	ErrorCount    int64
+	adapter       exchangeAdapter
+	mu            sync.RWMutex
+}
+
+// subscription is a subscriber channel, optionally limited to a set of exchanges
+type subscription struct {
+	ch        chan MarketUpdate
+	exchanges map[string]bool
 }
 
 // MarketUpdate represents a real-time market data update
 type MarketUpdate struct {
 	Exchange  string          `json:"exchange"`
-	Symbol    string          `json:"symbol"`
+	Symbol    string          `json:"symbol"` // canonical symbol, e.g. BTCUSD
 	Type      UpdateType      `json:"type"`
 	Price     decimal.Decimal `json:"price"`
 	Volume    decimal.Decimal `json:"volume"`
@@ -75,6 +94,9 @@ type MarketUpdate struct {
 	Timestamp time.Time       `json:"timestamp"`
 	Sequence  int64           `json:"sequence,omitempty"`
 	Metadata  json.RawMessage `json:"metadata,omitempty"`
+
+	// ExchangeSymbol is the symbol as the source exchange names it, e.g. XBT/USD
+	ExchangeSymbol string `json:"exchange_symbol,omitempty"`
 }
 
 // UpdateType represents the type of market data update
@@ -92,10 +114,16 @@ const (
 func NewMarketDataService(logger *observability.Logger, config MarketDataConfig) *MarketDataService {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = time.Minute
+	}
+
 	return &MarketDataService{
 		logger:      logger,
 		connections: make(map[string]*ExchangeConnection),
-		subscribers: make(map[string][]chan MarketUpdate),
+		subscribers: make(map[string][]*subscription),
+		books:       make(map[string]map[string]*localOrderBook),
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
 		config:      config,
 		ctx:         ctx,
 		cancel:      cancel,
@@ -113,7 +141,7 @@ func (m *MarketDataService) Start() error {
 			continue
 		}
 
-		if err := m.connectToExchange(exchangeConfig); err != nil {
+		if err := m.connectToExchange(exchangeConfig, 0); err != nil {
 			m.logger.Error(m.ctx, "Failed to connect to exchange", err, map[string]interface{}{
 				"exchange": exchangeConfig.Name,
 			})
@@ -149,38 +177,44 @@ func (m *MarketDataService) Stop() error {
 	}
 
 	// Close all subscriber channels
-	for symbol, channels := range m.subscribers {
-		for _, ch := range channels {
-			close(ch)
+	for symbol, subscriptions := range m.subscribers {
+		for _, sub := range subscriptions {
+			close(sub.ch)
 		}
 		m.logger.Info(m.ctx, "Closed subscriber channels", map[string]interface{}{
 			"symbol": symbol,
-			"count":  len(channels),
+			"count":  len(subscriptions),
 		})
 	}
+	m.subscribers = make(map[string][]*subscription)
 
 	return nil
 }
 
-// Subscribe subscribes to market data updates for a symbol
-func (m *MarketDataService) Subscribe(symbol string) <-chan MarketUpdate {
+// Subscribe subscribes to market data updates for a symbol in any exchange format. Updates
+// from all exchanges are merged unless exchanges names the ones to receive.
+func (m *MarketDataService) Subscribe(symbol string, exchanges ...string) <-chan MarketUpdate {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	ch := make(chan MarketUpdate, m.config.BufferSize)
-
-	if m.subscribers[symbol] == nil {
-		m.subscribers[symbol] = make([]chan MarketUpdate, 0)
+	symbol = CanonicalSymbol(symbol)
+	sub := &subscription{ch: make(chan MarketUpdate, m.config.BufferSize)}
+	if len(exchanges) > 0 {
+		sub.exchanges = make(map[string]bool, len(exchanges))
+		for _, exchange := range exchanges {
+			sub.exchanges[strings.ToLower(exchange)] = true
+		}
 	}
 
-	m.subscribers[symbol] = append(m.subscribers[symbol], ch)
+	m.subscribers[symbol] = append(m.subscribers[symbol], sub)
 
 	m.logger.Info(m.ctx, "New subscriber added", map[string]interface{}{
 		"symbol":      symbol,
+		"exchanges":   exchanges,
 		"subscribers": len(m.subscribers[symbol]),
 	})
 
-	return ch
+	return sub.ch
 }
 
 // Unsubscribe removes a subscription for market data updates
@@ -188,12 +222,13 @@ func (m *MarketDataService) Unsubscribe(symbol string, ch <-chan MarketUpdate) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if channels, exists := m.subscribers[symbol]; exists {
-		for i, subscriber := range channels {
-			if subscriber == ch {
+	symbol = CanonicalSymbol(symbol)
+	if subscriptions, exists := m.subscribers[symbol]; exists {
+		for i, sub := range subscriptions {
+			if sub.ch == ch {
 				// Remove the channel from the slice
-				m.subscribers[symbol] = append(channels[:i], channels[i+1:]...)
-				close(subscriber)
+				m.subscribers[symbol] = append(subscriptions[:i], subscriptions[i+1:]...)
+				close(sub.ch)
 				break
 			}
 		}
@@ -211,17 +246,28 @@ func (m *MarketDataService) GetConnectionStatus() map[string]ConnectionStatus {
 	defer m.mu.RUnlock()
 
 	status := make(map[string]ConnectionStatus)
+	now := time.Now()
 
 	for name, conn := range m.connections {
 		conn.mu.RLock()
+		// A connection is healthy while it keeps receiving messages; a fresh connection
+		// gets StaleAfter to deliver its first one
+		lastActivity := conn.LastMessageAt
+		if lastActivity.Before(conn.ConnectedAt) {
+			lastActivity = conn.ConnectedAt
+		}
 		status[name] = ConnectionStatus{
-			Exchange:     name,
-			IsConnected:  conn.IsConnected,
-			LastPing:     conn.LastPing,
-			LastPong:     conn.LastPong,
-			Reconnects:   conn.Reconnects,
-			MessageCount: conn.MessageCount,
-			ErrorCount:   conn.ErrorCount,
+			Exchange:      name,
+			IsConnected:   conn.IsConnected,
+			Healthy:       conn.IsConnected && now.Sub(lastActivity) < m.config.StaleAfter,
+			ConnectedAt:   conn.ConnectedAt,
+			LastPing:      conn.LastPing,
+			LastPong:      conn.LastPong,
+			LastMessageAt: conn.LastMessageAt,
+			LastError:     conn.LastError,
+			Reconnects:    conn.Reconnects,
+			MessageCount:  conn.MessageCount,
+			ErrorCount:    conn.ErrorCount,
 		}
 		conn.mu.RUnlock()
 	}
@@ -231,17 +277,27 @@ func (m *MarketDataService) GetConnectionStatus() map[string]ConnectionStatus {
 
 // ConnectionStatus represents the status of an exchange connection
 type ConnectionStatus struct {
-	Exchange     string    `json:"exchange"`
-	IsConnected  bool      `json:"is_connected"`
-	LastPing     time.Time `json:"last_ping"`
-	LastPong     time.Time `json:"last_pong"`
-	Reconnects   int       `json:"reconnects"`
-	MessageCount int64     `json:"message_count"`
-	ErrorCount   int64     `json:"error_count"`
+	Exchange      string    `json:"exchange"`
+	IsConnected   bool      `json:"is_connected"`
+	Healthy       bool      `json:"healthy"`
+	ConnectedAt   time.Time `json:"connected_at"`
+	LastPing      time.Time `json:"last_ping"`
+	LastPong      time.Time `json:"last_pong"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	Reconnects    int       `json:"reconnects"`
+	MessageCount  int64     `json:"message_count"`
+	ErrorCount    int64     `json:"error_count"`
 }
 
-// connectToExchange establishes a WebSocket connection to an exchange
-func (m *MarketDataService) connectToExchange(config ExchangeConfig) error {
+// connectToExchange establishes a WebSocket connection to an exchange, carrying over the
+// reconnect count of the connection it replaces
+func (m *MarketDataService) connectToExchange(config ExchangeConfig, reconnects int) error {
+	adapter, err := newExchangeAdapter(config.Name)
+	if err != nil {
+		return err
+	}
+
 	u, err := url.Parse(config.WSUrl)
 	if err != nil {
 		return fmt.Errorf("invalid WebSocket URL: %w", err)
@@ -263,13 +319,23 @@ func (m *MarketDataService) connectToExchange(config ExchangeConfig) error {
 	}
 
 	// Create exchange connection
+	now := time.Now()
 	exchangeConn := &ExchangeConnection{
 		Name:        config.Name,
 		Config:      config,
 		Conn:        conn,
-		LastPing:    time.Now(),
+		ConnectedAt: now,
+		LastPing:    now,
+		Reconnects:  reconnects,
 		IsConnected: true,
+		adapter:     adapter,
 	}
+	conn.SetPongHandler(func(string) error {
+		exchangeConn.mu.Lock()
+		exchangeConn.LastPong = time.Now()
+		exchangeConn.mu.Unlock()
+		return nil
+	})
 
 	m.mu.Lock()
 	m.connections[config.Name] = exchangeConn
@@ -295,20 +361,20 @@ func (m *MarketDataService) connectToExchange(config ExchangeConfig) error {
 	return nil
 }
 
-// subscribeToChannels sends subscription messages to the exchange
+// subscribeToChannels sends the exchange-specific subscription messages
 func (m *MarketDataService) subscribeToChannels(conn *ExchangeConnection) error {
-	// This is a simplified subscription - in reality, each exchange has different formats
-	for _, symbol := range conn.Config.Symbols {
-		for _, channel := range conn.Config.Channels {
-			subscribeMsg := map[string]interface{}{
-				"method": "SUBSCRIBE",
-				"params": []string{fmt.Sprintf("%s@%s", symbol, channel)},
-				"id":     time.Now().Unix(),
-			}
+	messages, err := conn.adapter.subscribeMessages(conn.Config.Symbols, conn.Config.Channels)
+	if err != nil {
+		return err
+	}
 
-			if err := conn.Conn.WriteJSON(subscribeMsg); err != nil {
-				return fmt.Errorf("failed to subscribe to %s@%s: %w", symbol, channel, err)
-			}
+	// Writes share the lock with sendPing; gorilla/websocket allows one concurrent writer
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	for _, message := range messages {
+		if err := conn.Conn.WriteJSON(message); err != nil {
+			return fmt.Errorf("failed to subscribe on %s: %w", conn.Name, err)
 		}
 	}
 
@@ -322,13 +388,15 @@ func (m *MarketDataService) handleMessages(conn *ExchangeConnection) {
 		conn.IsConnected = false
 		conn.mu.Unlock()
 
+		// Deltas were missed while disconnected; books wait for a fresh snapshot
+		m.invalidateOrderBooks(conn.Name)
+
 		if conn.Conn != nil {
 			conn.Conn.Close()
 		}
 
 		// Attempt reconnection if not cancelled
-		if m.ctx.Err() == nil && conn.Reconnects < m.config.MaxReconnects {
-			time.Sleep(m.config.ReconnectDelay)
+		if m.ctx.Err() == nil {
 			m.reconnectExchange(conn)
 		}
 	}()
@@ -338,10 +406,11 @@ func (m *MarketDataService) handleMessages(conn *ExchangeConnection) {
 		case <-m.ctx.Done():
 			return
 		default:
-			var rawMessage json.RawMessage
-			if err := conn.Conn.ReadJSON(&rawMessage); err != nil {
+			_, rawMessage, err := conn.Conn.ReadMessage()
+			if err != nil {
 				conn.mu.Lock()
 				conn.ErrorCount++
+				conn.LastError = err.Error()
 				conn.mu.Unlock()
 
 				m.logger.Error(m.ctx, "WebSocket read error", err, map[string]interface{}{
@@ -352,82 +421,88 @@ func (m *MarketDataService) handleMessages(conn *ExchangeConnection) {
 
 			conn.mu.Lock()
 			conn.MessageCount++
+			conn.LastMessageAt = time.Now()
 			conn.mu.Unlock()
 
 			// Parse and distribute the message
-			if update, err := m.parseMessage(conn.Name, rawMessage); err == nil {
+			parsed, err := conn.adapter.parseMessage(conn.Name, rawMessage)
+			if err != nil {
+				conn.mu.Lock()
+				conn.ErrorCount++
+				conn.LastError = err.Error()
+				conn.mu.Unlock()
+				continue
+			}
+			for _, update := range parsed.updates {
 				m.distributeUpdate(update)
 			}
+			for _, event := range parsed.books {
+				m.handleBookEvent(conn, event)
+			}
 		}
 	}
 }
 
-// parseMessage parses a raw WebSocket message into a MarketUpdate
-func (m *MarketDataService) parseMessage(exchange string, rawMessage json.RawMessage) (MarketUpdate, error) {
-	// This is a simplified parser - in reality, each exchange has different message formats
-	var genericMessage map[string]interface{}
-	if err := json.Unmarshal(rawMessage, &genericMessage); err != nil {
-		return MarketUpdate{}, err
-	}
-
-	// Extract common fields (this would be exchange-specific in reality)
-	update := MarketUpdate{
-		Exchange:  exchange,
-		Timestamp: time.Now(),
-		Metadata:  rawMessage,
-	}
-
-	// Parse based on message structure (simplified)
-	if symbol, ok := genericMessage["s"].(string); ok {
-		update.Symbol = symbol
-	}
-	if price, ok := genericMessage["p"].(string); ok {
-		if p, err := decimal.NewFromString(price); err == nil {
-			update.Price = p
-		}
-	}
-	if volume, ok := genericMessage["v"].(string); ok {
-		if v, err := decimal.NewFromString(volume); err == nil {
-			update.Volume = v
-		}
-	}
-
-	update.Type = UpdateTypeTicker // Default type
-
-	return update, nil
-}
-
-// distributeUpdate sends a market update to all subscribers
+// distributeUpdate sends a market update to the subscribers of its symbol
 func (m *MarketDataService) distributeUpdate(update MarketUpdate) {
+	// Hold the read lock while fanning out so Unsubscribe cannot close a channel mid-send
 	m.mu.RLock()
-	subscribers, exists := m.subscribers[update.Symbol]
-	m.mu.RUnlock()
+	defer m.mu.RUnlock()
 
+	subscriptions, exists := m.subscribers[update.Symbol]
 	if !exists {
 		return
 	}
 
-	for _, ch := range subscribers {
+	exchange := strings.ToLower(update.Exchange)
+	for _, sub := range subscriptions {
+		if sub.exchanges != nil && !sub.exchanges[exchange] {
+			continue
+		}
 		select {
-		case ch <- update:
+		case sub.ch <- update:
 		default:
 			// Channel is full, skip this update
 		}
 	}
 }
 
-// reconnectExchange attempts to reconnect to an exchange
+// reconnectExchange retries the connection to an exchange until it succeeds, the service
+// stops or MaxReconnects is reached
 func (m *MarketDataService) reconnectExchange(conn *ExchangeConnection) {
-	conn.mu.Lock()
-	conn.Reconnects++
-	conn.mu.Unlock()
+	for {
+		conn.mu.Lock()
+		if conn.Reconnects >= m.config.MaxReconnects {
+			conn.mu.Unlock()
+			m.logger.Warn(m.ctx, "Giving up reconnecting to exchange", map[string]interface{}{
+				"exchange":   conn.Name,
+				"reconnects": m.config.MaxReconnects,
+			})
+			return
+		}
+		conn.Reconnects++
+		reconnects := conn.Reconnects
+		conn.mu.Unlock()
 
-	m.logger.Info(m.ctx, "Attempting to reconnect to exchange", map[string]interface{}{
-		"exchange":   conn.Name,
-		"reconnects": conn.Reconnects,
-	})
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.config.ReconnectDelay):
+		}
 
-	if err := m.connectToExchange(conn.Config); err != nil {
+		m.logger.Info(m.ctx, "Attempting to reconnect to exchange", map[string]interface{}{
+			"exchange":   conn.Name,
+			"reconnects": reconnects,
+		})
+
+		err := m.connectToExchange(conn.Config, reconnects)
+		if err == nil {
+			return
+		}
+
+		conn.mu.Lock()
+		conn.LastError = err.Error()
+		conn.mu.Unlock()
 		m.logger.Error(m.ctx, "Reconnection failed", err, map[string]interface{}{
 			"exchange": conn.Name,
 		})
@@ -446,7 +521,10 @@ func (m *MarketDataService) heartbeatMonitor() {
 		case <-ticker.C:
 			m.mu.RLock()
 			for _, conn := range m.connections {
-				if conn.IsConnected {
+				conn.mu.RLock()
+				connected := conn.IsConnected
+				conn.mu.RUnlock()
+				if connected {
 					go m.sendPing(conn)
 				}
 			}
@@ -465,6 +543,7 @@ func (m *MarketDataService) sendPing(conn *ExchangeConnection) {
 			"exchange": conn.Name,
 		})
 		conn.ErrorCount++
+		conn.LastError = err.Error()
 		return
 	}
 
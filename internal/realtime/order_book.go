@@ -0,0 +1,464 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrOrderBookUnavailable is returned when no synchronized order book exists for a symbol
+var ErrOrderBookUnavailable = errors.New("order book not available")
+
+// maxOrderBookDepth bounds the number of levels returned per side
+const maxOrderBookDepth = 1000
+
+// OrderBookLevel is a price level and the total size resting at it
+type OrderBookLevel struct {
+	Price decimal.Decimal `json:"price"`
+	Size  decimal.Decimal `json:"size"`
+}
+
+// OrderBook is a level-2 depth snapshot. Bids are sorted best (highest) first and asks
+// best (lowest) first.
+type OrderBook struct {
+	Symbol    string           `json:"symbol"`
+	Exchanges []string         `json:"exchanges"`
+	Bids      []OrderBookLevel `json:"bids"`
+	Asks      []OrderBookLevel `json:"asks"`
+	Sequence  int64            `json:"sequence,omitempty"` // last applied update, single-exchange books only
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// FillEstimate is the expected result of walking the book with a market order
+type FillEstimate struct {
+	Side           string          `json:"side"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	FilledQuantity decimal.Decimal `json:"filled_quantity"`
+	BestPrice      decimal.Decimal `json:"best_price"`
+	AveragePrice   decimal.Decimal `json:"average_price"`
+	WorstPrice     decimal.Decimal `json:"worst_price"`
+	SlippageBps    decimal.Decimal `json:"slippage_bps"` // average price versus best price
+	FullyFilled    bool            `json:"fully_filled"`
+}
+
+// BestBid returns the highest bid, or zero when the bid side is empty
+func (b *OrderBook) BestBid() decimal.Decimal {
+	if len(b.Bids) == 0 {
+		return decimal.Zero
+	}
+	return b.Bids[0].Price
+}
+
+// BestAsk returns the lowest ask, or zero when the ask side is empty
+func (b *OrderBook) BestAsk() decimal.Decimal {
+	if len(b.Asks) == 0 {
+		return decimal.Zero
+	}
+	return b.Asks[0].Price
+}
+
+// EstimateFill walks the asks for a buy or the bids for a sell and returns the average fill
+// price and its slippage from the top of the book. The estimate is only as deep as the book.
+func (b *OrderBook) EstimateFill(side string, quantity decimal.Decimal) (*FillEstimate, error) {
+	var levels []OrderBookLevel
+	switch side = strings.ToLower(side); side {
+	case "buy":
+		levels = b.Asks
+	case "sell":
+		levels = b.Bids
+	default:
+		return nil, fmt.Errorf("unsupported order side: %s", side)
+	}
+	if !quantity.IsPositive() {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("%w: no %s liquidity for %s", ErrOrderBookUnavailable, side, b.Symbol)
+	}
+
+	estimate := &FillEstimate{
+		Side:      side,
+		Quantity:  quantity,
+		BestPrice: levels[0].Price,
+	}
+
+	remaining := quantity
+	notional := decimal.Zero
+	for _, level := range levels {
+		take := decimal.Min(remaining, level.Size)
+		notional = notional.Add(take.Mul(level.Price))
+		remaining = remaining.Sub(take)
+		estimate.WorstPrice = level.Price
+		if !remaining.IsPositive() {
+			break
+		}
+	}
+
+	estimate.FilledQuantity = quantity.Sub(remaining)
+	estimate.FullyFilled = !remaining.IsPositive()
+	if estimate.FilledQuantity.IsPositive() {
+		estimate.AveragePrice = notional.Div(estimate.FilledQuantity)
+		estimate.SlippageBps = estimate.AveragePrice.Sub(estimate.BestPrice).Abs().
+			Div(estimate.BestPrice).Mul(decimal.NewFromInt(10000))
+	}
+
+	return estimate, nil
+}
+
+// bookEvent is an exchange order book message. A zero size removes the level.
+type bookEvent struct {
+	Symbol        string // exchange symbol
+	Snapshot      bool
+	Bids          []OrderBookLevel
+	Asks          []OrderBookLevel
+	FirstSequence int64 // first update covered by this event; zero when the exchange does not sequence updates
+	Sequence      int64 // last update covered by this event
+	MaxDepth      int   // levels kept per side for depth-limited feeds, zero for full books
+	Timestamp     time.Time
+}
+
+// localOrderBook is the in-memory book for one symbol on one exchange
+type localOrderBook struct {
+	exchange       string
+	symbol         string
+	exchangeSymbol string
+	bids           map[string]OrderBookLevel
+	asks           map[string]OrderBookLevel
+	sequence       int64
+	synced         bool
+	resyncing      bool
+	resyncs        int
+	retryAt        time.Time    // earliest time to retry a failed snapshot fetch
+	pending        []*bookEvent // deltas buffered while a snapshot is fetched
+	updatedAt      time.Time
+	mu             sync.RWMutex
+}
+
+const (
+	// maxPendingBookEvents bounds the deltas buffered during a resync
+	maxPendingBookEvents = 1000
+	// orderBookRetryDelay spaces out snapshot fetches that failed or came back stale
+	orderBookRetryDelay = time.Second
+)
+
+func newLocalOrderBook(exchange, exchangeSymbol string) *localOrderBook {
+	return &localOrderBook{
+		exchange:       exchange,
+		symbol:         CanonicalSymbol(exchangeSymbol),
+		exchangeSymbol: exchangeSymbol,
+		bids:           make(map[string]OrderBookLevel),
+		asks:           make(map[string]OrderBookLevel),
+	}
+}
+
+// applySnapshot replaces the book contents. The caller must hold mu.
+func (b *localOrderBook) applySnapshot(event *bookEvent) {
+	b.bids = make(map[string]OrderBookLevel, len(event.Bids))
+	b.asks = make(map[string]OrderBookLevel, len(event.Asks))
+	b.sequence = event.Sequence
+	b.synced = true
+	b.applyLevels(event)
+}
+
+// applyDelta applies an incremental update. It returns false when the update does not follow
+// the last applied sequence and the book must be resynchronized. The caller must hold mu.
+func (b *localOrderBook) applyDelta(event *bookEvent) bool {
+	if event.Sequence > 0 {
+		if event.Sequence <= b.sequence {
+			return true // already covered by the snapshot
+		}
+		if event.FirstSequence > b.sequence+1 {
+			return false
+		}
+		b.sequence = event.Sequence
+	}
+	b.applyLevels(event)
+	return true
+}
+
+// applyLevels upserts or removes the levels of an event. The caller must hold mu.
+func (b *localOrderBook) applyLevels(event *bookEvent) {
+	for _, level := range event.Bids {
+		setLevel(b.bids, level)
+	}
+	for _, level := range event.Asks {
+		setLevel(b.asks, level)
+	}
+	if event.MaxDepth > 0 {
+		truncateLevels(b.bids, event.MaxDepth, true)
+		truncateLevels(b.asks, event.MaxDepth, false)
+	}
+
+	b.updatedAt = event.Timestamp
+	if b.updatedAt.IsZero() {
+		b.updatedAt = time.Now()
+	}
+}
+
+// snapshot returns the top depth levels of the book. The caller must hold mu.
+func (b *localOrderBook) snapshot(depth int) *OrderBook {
+	return &OrderBook{
+		Symbol:    b.symbol,
+		Exchanges: []string{b.exchange},
+		Bids:      sortedLevels(b.bids, depth, true),
+		Asks:      sortedLevels(b.asks, depth, false),
+		Sequence:  b.sequence,
+		UpdatedAt: b.updatedAt,
+	}
+}
+
+// setLevel stores a level keyed by its normalized price, removing it when the size is zero
+func setLevel(levels map[string]OrderBookLevel, level OrderBookLevel) {
+	key := level.Price.String()
+	if level.Size.IsZero() {
+		delete(levels, key)
+		return
+	}
+	levels[key] = level
+}
+
+// sortedLevels returns the best depth levels, highest first for bids and lowest first for asks
+func sortedLevels(levels map[string]OrderBookLevel, depth int, descending bool) []OrderBookLevel {
+	sorted := make([]OrderBookLevel, 0, len(levels))
+	for _, level := range levels {
+		sorted = append(sorted, level)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Price.GreaterThan(sorted[j].Price)
+		}
+		return sorted[i].Price.LessThan(sorted[j].Price)
+	})
+	if depth > 0 && len(sorted) > depth {
+		sorted = sorted[:depth]
+	}
+	return sorted
+}
+
+// truncateLevels drops the levels beyond depth from the worst end of a side
+func truncateLevels(levels map[string]OrderBookLevel, depth int, descending bool) {
+	if len(levels) <= depth {
+		return
+	}
+	for _, level := range sortedLevels(levels, 0, descending)[depth:] {
+		delete(levels, level.Price.String())
+	}
+}
+
+// GetOrderBook returns the top depth levels for a symbol in any exchange format, merged across
+// every exchange with a synchronized book. Sizes at the same price are summed.
+func (m *MarketDataService) GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	symbol = CanonicalSymbol(symbol)
+	depth = clampDepth(depth)
+
+	m.mu.RLock()
+	books := make([]*localOrderBook, 0, len(m.books[symbol]))
+	for _, book := range m.books[symbol] {
+		books = append(books, book)
+	}
+	m.mu.RUnlock()
+
+	merged := &OrderBook{Symbol: symbol}
+	bids := make(map[string]OrderBookLevel)
+	asks := make(map[string]OrderBookLevel)
+	for _, book := range books {
+		book.mu.RLock()
+		if book.synced {
+			merged.Exchanges = append(merged.Exchanges, book.exchange)
+			mergeLevels(bids, book.bids)
+			mergeLevels(asks, book.asks)
+			if book.updatedAt.After(merged.UpdatedAt) {
+				merged.UpdatedAt = book.updatedAt
+			}
+		}
+		book.mu.RUnlock()
+	}
+
+	if len(merged.Exchanges) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrOrderBookUnavailable, symbol)
+	}
+
+	sort.Strings(merged.Exchanges)
+	merged.Bids = sortedLevels(bids, depth, true)
+	merged.Asks = sortedLevels(asks, depth, false)
+	return merged, nil
+}
+
+// GetExchangeOrderBook returns the top depth levels of a single exchange's book for a symbol
+func (m *MarketDataService) GetExchangeOrderBook(exchange, symbol string, depth int) (*OrderBook, error) {
+	symbol = CanonicalSymbol(symbol)
+
+	m.mu.RLock()
+	book, exists := m.books[symbol][strings.ToLower(exchange)]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s on %s", ErrOrderBookUnavailable, symbol, exchange)
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	if !book.synced {
+		return nil, fmt.Errorf("%w: %s on %s is resynchronizing", ErrOrderBookUnavailable, symbol, exchange)
+	}
+	return book.snapshot(clampDepth(depth)), nil
+}
+
+// mergeLevels adds the sizes of src into dst by price
+func mergeLevels(dst, src map[string]OrderBookLevel) {
+	for key, level := range src {
+		if existing, exists := dst[key]; exists {
+			level.Size = level.Size.Add(existing.Size)
+		}
+		dst[key] = level
+	}
+}
+
+// clampDepth defaults depth to 20 levels and caps it at maxOrderBookDepth
+func clampDepth(depth int) int {
+	if depth <= 0 {
+		return 20
+	}
+	if depth > maxOrderBookDepth {
+		return maxOrderBookDepth
+	}
+	return depth
+}
+
+// orderBook returns the local book for an exchange symbol, creating it on first use
+func (m *MarketDataService) orderBook(exchange, exchangeSymbol string) *localOrderBook {
+	symbol := CanonicalSymbol(exchangeSymbol)
+	key := strings.ToLower(exchange)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.books[symbol] == nil {
+		m.books[symbol] = make(map[string]*localOrderBook)
+	}
+	book, exists := m.books[symbol][key]
+	if !exists {
+		book = newLocalOrderBook(key, exchangeSymbol)
+		m.books[symbol][key] = book
+	}
+	return book
+}
+
+// invalidateOrderBooks marks every book of an exchange as out of sync
+func (m *MarketDataService) invalidateOrderBooks(exchange string) {
+	exchange = strings.ToLower(exchange)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, books := range m.books {
+		if book, exists := books[exchange]; exists {
+			book.mu.Lock()
+			book.synced = false
+			book.pending = nil
+			book.mu.Unlock()
+		}
+	}
+}
+
+// handleBookEvent applies an order book message. Exchanges that seed books over REST buffer
+// deltas until the snapshot arrives, and a sequence gap triggers a fresh snapshot.
+func (m *MarketDataService) handleBookEvent(conn *ExchangeConnection, event *bookEvent) {
+	book := m.orderBook(conn.Name, event.Symbol)
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+
+	if event.Snapshot {
+		book.applySnapshot(event)
+		return
+	}
+
+	restSeeded := conn.adapter.seedsOrderBookOverREST()
+	if !book.synced {
+		if restSeeded {
+			book.bufferEvent(event)
+			m.resyncOrderBook(conn, book)
+		}
+		// In-band snapshot feeds drop deltas until their snapshot message arrives
+		return
+	}
+
+	if !book.applyDelta(event) {
+		m.logger.Warn(m.ctx, "Order book sequence gap, resynchronizing", map[string]interface{}{
+			"exchange": book.exchange,
+			"symbol":   book.exchangeSymbol,
+			"expected": book.sequence + 1,
+			"received": event.FirstSequence,
+		})
+		book.synced = false
+		book.bufferEvent(event)
+		if restSeeded {
+			m.resyncOrderBook(conn, book)
+		}
+	}
+}
+
+// bufferEvent queues a delta for replay after the next snapshot. The caller must hold mu.
+func (b *localOrderBook) bufferEvent(event *bookEvent) {
+	if len(b.pending) >= maxPendingBookEvents {
+		b.pending = b.pending[1:]
+	}
+	b.pending = append(b.pending, event)
+}
+
+// resyncOrderBook fetches a REST snapshot in the background and replays buffered deltas on top
+// of it. The caller must hold book.mu.
+func (m *MarketDataService) resyncOrderBook(conn *ExchangeConnection, book *localOrderBook) {
+	if book.resyncing || time.Now().Before(book.retryAt) {
+		return
+	}
+	book.resyncing = true
+	book.resyncs++
+
+	go func() {
+		ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+		defer cancel()
+
+		snapshot, err := conn.adapter.fetchOrderBookSnapshot(ctx, m.httpClient, conn.Config.RESTUrl, book.exchangeSymbol)
+
+		book.mu.Lock()
+		defer book.mu.Unlock()
+		book.resyncing = false
+
+		if err != nil {
+			m.logger.Error(m.ctx, "Failed to fetch order book snapshot", err, map[string]interface{}{
+				"exchange": book.exchange,
+				"symbol":   book.exchangeSymbol,
+			})
+			// The first delta after the retry delay fetches it again
+			book.retryAt = time.Now().Add(orderBookRetryDelay)
+			return
+		}
+
+		book.applySnapshot(snapshot)
+		pending := book.pending
+		book.pending = nil
+		for i, event := range pending {
+			if !book.applyDelta(event) {
+				// The snapshot is older than the buffered deltas; the next delta fetches another
+				book.synced = false
+				book.pending = pending[i:]
+				book.retryAt = time.Now().Add(orderBookRetryDelay)
+				return
+			}
+		}
+
+		m.logger.Info(m.ctx, "Order book synchronized", map[string]interface{}{
+			"exchange": book.exchange,
+			"symbol":   book.exchangeSymbol,
+			"sequence": book.sequence,
+			"resyncs":  book.resyncs,
+		})
+	}()
+}
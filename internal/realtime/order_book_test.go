@@ -0,0 +1,230 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bookFeedAdapter feeds order book snapshots the test queues. REST-seeded adapters serve them
+// from fetchOrderBookSnapshot; in-band adapters get them as stream events.
+type bookFeedAdapter struct {
+	restSeeded bool
+	snapshots  chan *bookEvent
+	fetches    atomic.Int32
+}
+
+func (a *bookFeedAdapter) subscribeMessages(symbols, channels []string) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (a *bookFeedAdapter) parseMessage(exchange string, raw []byte) (parsedMessage, error) {
+	return parsedMessage{}, nil
+}
+
+func (a *bookFeedAdapter) seedsOrderBookOverREST() bool { return a.restSeeded }
+
+func (a *bookFeedAdapter) fetchOrderBookSnapshot(ctx context.Context, client *http.Client, restURL, symbol string) (*bookEvent, error) {
+	a.fetches.Add(1)
+	select {
+	case snapshot := <-a.snapshots:
+		return snapshot, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func level(price, size string) OrderBookLevel {
+	return OrderBookLevel{Price: decimal.RequireFromString(price), Size: decimal.RequireFromString(size)}
+}
+
+func delta(first, last int64, bids, asks []OrderBookLevel) *bookEvent {
+	return &bookEvent{Symbol: "BTCUSDT", FirstSequence: first, Sequence: last, Bids: bids, Asks: asks}
+}
+
+type bookFixture struct {
+	service *MarketDataService
+	adapter *bookFeedAdapter
+	conn    *ExchangeConnection
+}
+
+func newBookFixture(t *testing.T, exchange string, restSeeded bool) *bookFixture {
+	service := NewMarketDataService(observability.NewLogger(config.ObservabilityConfig{}), MarketDataConfig{})
+	t.Cleanup(service.cancel)
+	adapter := &bookFeedAdapter{restSeeded: restSeeded, snapshots: make(chan *bookEvent, 4)}
+	return &bookFixture{
+		service: service,
+		adapter: adapter,
+		conn:    &ExchangeConnection{Name: exchange, adapter: adapter},
+	}
+}
+
+func (f *bookFixture) handle(event *bookEvent) {
+	f.service.handleBookEvent(f.conn, event)
+}
+
+func (f *bookFixture) book() (*OrderBook, error) {
+	return f.service.GetExchangeOrderBook(f.conn.Name, "BTC/USDT", 10)
+}
+
+func (f *bookFixture) waitSynced(t *testing.T, sequence int64) *OrderBook {
+	t.Helper()
+	var book *OrderBook
+	require.Eventually(t, func() bool {
+		var err error
+		book, err = f.book()
+		return err == nil && book.Sequence == sequence
+	}, time.Second, 5*time.Millisecond)
+	return book
+}
+
+func TestOrderBook_RESTSeededResync(t *testing.T) {
+	f := newBookFixture(t, "binance", true)
+
+	// Deltas before the first snapshot are buffered and replayed on top of it
+	f.adapter.snapshots <- &bookEvent{Symbol: "BTCUSDT", Snapshot: true, Sequence: 10,
+		Bids: []OrderBookLevel{level("100", "1"), level("99", "2")}, Asks: []OrderBookLevel{level("101", "1")}}
+	f.handle(delta(9, 11, []OrderBookLevel{level("100", "3")}, nil))
+	book := f.waitSynced(t, 11)
+	assert.Equal(t, "BTCUSD", book.Symbol)
+	assert.Equal(t, []OrderBookLevel{level("100", "3"), level("99", "2")}, book.Bids)
+
+	// Contiguous deltas apply directly; a zero size removes the level
+	f.handle(delta(12, 12, []OrderBookLevel{level("99", "0")}, []OrderBookLevel{level("102", "5")}))
+	book, err := f.book()
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), book.Sequence)
+	assert.Equal(t, []OrderBookLevel{level("100", "3")}, book.Bids)
+	assert.Equal(t, []OrderBookLevel{level("101", "1"), level("102", "5")}, book.Asks)
+	assert.Equal(t, int32(1), f.adapter.fetches.Load())
+
+	// Deltas already covered by the snapshot are skipped
+	f.handle(delta(11, 12, []OrderBookLevel{level("90", "1")}, nil))
+	book, err = f.book()
+	require.NoError(t, err)
+	assert.Len(t, book.Bids, 1)
+
+	// A gap takes the book offline until a fresh snapshot and the buffered delta are applied
+	f.handle(delta(15, 15, nil, []OrderBookLevel{level("101", "0")}))
+	_, err = f.book()
+	assert.ErrorIs(t, err, ErrOrderBookUnavailable)
+	f.adapter.snapshots <- &bookEvent{Symbol: "BTCUSDT", Snapshot: true, Sequence: 14,
+		Bids: []OrderBookLevel{level("100", "2")}, Asks: []OrderBookLevel{level("101", "4"), level("103", "1")}}
+	book = f.waitSynced(t, 15)
+	assert.Equal(t, []OrderBookLevel{level("100", "2")}, book.Bids)
+	assert.Equal(t, []OrderBookLevel{level("103", "1")}, book.Asks)
+	assert.Equal(t, int32(2), f.adapter.fetches.Load())
+}
+
+func TestOrderBook_StaleSnapshotRetries(t *testing.T) {
+	f := newBookFixture(t, "binance", true)
+	f.adapter.snapshots <- &bookEvent{Symbol: "BTCUSDT", Snapshot: true, Sequence: 10, Bids: []OrderBookLevel{level("100", "1")}}
+	f.handle(delta(11, 11, nil, nil))
+	f.waitSynced(t, 11)
+
+	// The snapshot predates the buffered delta, so the book stays unsynchronized
+	f.adapter.snapshots <- &bookEvent{Symbol: "BTCUSDT", Snapshot: true, Sequence: 12, Bids: []OrderBookLevel{level("100", "1")}}
+	f.handle(delta(20, 20, []OrderBookLevel{level("100", "5")}, nil))
+	require.Eventually(t, func() bool { return f.adapter.fetches.Load() == 2 && len(f.adapter.snapshots) == 0 }, time.Second, 5*time.Millisecond)
+
+	local := f.service.orderBook("binance", "BTCUSDT")
+	require.Eventually(t, func() bool {
+		local.mu.RLock()
+		defer local.mu.RUnlock()
+		return !local.resyncing
+	}, time.Second, 5*time.Millisecond)
+	_, err := f.book()
+	assert.ErrorIs(t, err, ErrOrderBookUnavailable)
+
+	// Deltas within the retry delay don't refetch
+	f.handle(delta(21, 21, nil, nil))
+	assert.Equal(t, int32(2), f.adapter.fetches.Load())
+
+	// After it, the next delta fetches a snapshot that the buffered deltas follow
+	local.mu.Lock()
+	local.retryAt = time.Time{}
+	local.mu.Unlock()
+	f.adapter.snapshots <- &bookEvent{Symbol: "BTCUSDT", Snapshot: true, Sequence: 19, Bids: []OrderBookLevel{level("100", "1")}}
+	f.handle(delta(22, 22, nil, nil))
+	book := f.waitSynced(t, 22)
+	assert.Equal(t, []OrderBookLevel{level("100", "5")}, book.Bids)
+	assert.Equal(t, int32(3), f.adapter.fetches.Load())
+}
+
+func TestOrderBook_InBandSnapshots(t *testing.T) {
+	f := newBookFixture(t, "kraken", false)
+
+	// Deltas before the stream's snapshot are dropped rather than fetched over REST
+	f.handle(delta(0, 0, []OrderBookLevel{level("100", "1")}, nil))
+	_, err := f.book()
+	assert.ErrorIs(t, err, ErrOrderBookUnavailable)
+
+	f.handle(&bookEvent{Symbol: "BTCUSDT", Snapshot: true, MaxDepth: 2,
+		Bids: []OrderBookLevel{level("99", "1"), level("98", "1")}, Asks: []OrderBookLevel{level("101", "1")}})
+	f.handle(&bookEvent{Symbol: "BTCUSDT", MaxDepth: 2, Bids: []OrderBookLevel{level("99.5", "2")}})
+	book, err := f.book()
+	require.NoError(t, err)
+	assert.Equal(t, []OrderBookLevel{level("99.5", "2"), level("99", "1")}, book.Bids, "depth-limited books drop the worst levels")
+	assert.Zero(t, f.adapter.fetches.Load())
+}
+
+func TestOrderBook_MergesSynchronizedExchanges(t *testing.T) {
+	f := newBookFixture(t, "kraken", false)
+	coinbase := &ExchangeConnection{Name: "coinbase", adapter: &bookFeedAdapter{}}
+	binance := &ExchangeConnection{Name: "binance", adapter: &bookFeedAdapter{}}
+
+	f.handle(&bookEvent{Symbol: "XBT/USDT", Snapshot: true, Asks: []OrderBookLevel{level("101", "1"), level("102", "1")}})
+	f.service.handleBookEvent(coinbase, &bookEvent{Symbol: "BTC-USDT", Snapshot: true, Asks: []OrderBookLevel{level("101", "2")}})
+	f.service.handleBookEvent(binance, &bookEvent{Symbol: "BTCUSDT", Snapshot: true, Asks: []OrderBookLevel{level("100", "1")}})
+	f.service.invalidateOrderBooks("binance")
+
+	merged, err := f.service.GetOrderBook("btc-usdt", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coinbase", "kraken"}, merged.Exchanges)
+	assert.Equal(t, []OrderBookLevel{level("101", "3"), level("102", "1")}, merged.Asks)
+
+	_, err = f.service.GetOrderBook("ETHUSDT", 10)
+	assert.ErrorIs(t, err, ErrOrderBookUnavailable)
+}
+
+func TestOrderBook_EstimateFill(t *testing.T) {
+	book := &OrderBook{
+		Symbol: "BTCUSD",
+		Bids:   []OrderBookLevel{level("99", "1"), level("98", "1")},
+		Asks:   []OrderBookLevel{level("100", "1"), level("102", "1")},
+	}
+	d := decimal.RequireFromString
+
+	estimate, err := book.EstimateFill("BUY", d("2"))
+	require.NoError(t, err)
+	assert.True(t, estimate.FullyFilled)
+	assert.True(t, estimate.AveragePrice.Equal(d("101")))
+	assert.True(t, estimate.WorstPrice.Equal(d("102")))
+	assert.True(t, estimate.SlippageBps.Equal(d("100")), "slippage %s", estimate.SlippageBps)
+
+	estimate, err = book.EstimateFill("sell", d("1.5"))
+	require.NoError(t, err)
+	assert.Equal(t, "98.67", estimate.AveragePrice.StringFixed(2))
+	assert.True(t, estimate.BestPrice.Equal(d("99")))
+
+	// Orders larger than the book fill partially
+	estimate, err = book.EstimateFill("buy", d("5"))
+	require.NoError(t, err)
+	assert.False(t, estimate.FullyFilled)
+	assert.True(t, estimate.FilledQuantity.Equal(d("2")))
+
+	_, err = book.EstimateFill("hold", d("1"))
+	assert.Error(t, err)
+	_, err = book.EstimateFill("buy", decimal.Zero)
+	assert.Error(t, err)
+	_, err = (&OrderBook{Symbol: "BTCUSD"}).EstimateFill("buy", d("1"))
+	assert.ErrorIs(t, err, ErrOrderBookUnavailable)
+}
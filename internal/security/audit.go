@@ -3,12 +3,14 @@ package security
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 )
@@ -22,6 +24,10 @@ type AuditManager struct {
 	eventProcessor    *AuditEventProcessor
 	complianceEngine  *ComplianceEngine
 	mu                sync.RWMutex
+
+	// Archived segments; exports hold archiveMu for reading so archival cannot move events mid-export
+	archiveMu sync.RWMutex
+	manifest  *AuditArchiveManifest
 }
 
 // AuditConfig contains audit configuration
@@ -36,6 +42,14 @@ type AuditConfig struct {
 	EnableTamperDetection  bool          `json:"enable_tamper_detection"`
 	MaxAuditLogSize        int64         `json:"max_audit_log_size"`
 	ArchiveThreshold       int64         `json:"archive_threshold"`
+
+	// Tiered retention: events older than HotRetention move from the queryable store to
+	// compressed segments in Archive every ArchiveInterval, encrypted with AES-256-GCM when
+	// ArchiveKey is set. Segments are deleted once they are older than RetentionPeriod.
+	HotRetention    time.Duration `json:"hot_retention"`
+	ArchiveInterval time.Duration `json:"archive_interval"`
+	Archive         AuditArchive  `json:"-"`
+	ArchiveKey      []byte        `json:"-"`
 }
 
 // AuditLevel defines the level of audit logging
@@ -197,6 +211,38 @@ func NewAuditManager(logger *observability.Logger, config *AuditConfig, encrypti
 	return am
 }
 
+// NewServiceAuditManager creates a service's audit log with the deployment's retention
+// settings. Events past the hot retention are archived to archiveDir, encrypted when
+// AUDIT_ARCHIVE_KEY is set; without a directory they are kept in memory only. Services must not
+// share an archive directory, as each keeps its own manifest there.
+func NewServiceAuditManager(logger *observability.Logger, cfg config.SecurityConfig, archiveDir string) (*AuditManager, error) {
+	auditConfig := &AuditConfig{
+		EnableAuditLogging:   true,
+		EnableIntegrityCheck: true,
+		RetentionPeriod:      cfg.AuditRetention,
+		AuditLevel:           AuditLevelStandard,
+		MaxAuditLogSize:      100 * 1024 * 1024,
+		ArchiveThreshold:     50 * 1024 * 1024,
+		HotRetention:         cfg.AuditHotRetention,
+		ArchiveInterval:      cfg.AuditArchiveInterval,
+	}
+	if archiveDir != "" {
+		archive, err := NewFileAuditArchive(archiveDir)
+		if err != nil {
+			return nil, err
+		}
+		auditConfig.Archive = archive
+	}
+	if cfg.AuditArchiveKey != "" {
+		key, err := hex.DecodeString(cfg.AuditArchiveKey)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("AUDIT_ARCHIVE_KEY must be 64 hex characters")
+		}
+		auditConfig.ArchiveKey = key
+	}
+	return NewAuditManager(logger, auditConfig, nil), nil
+}
+
 // Start starts the audit manager
 func (am *AuditManager) Start(ctx context.Context) error {
 	am.logger.Info(ctx, "Starting audit manager", map[string]interface{}{
@@ -210,6 +256,13 @@ func (am *AuditManager) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to initialize compliance standards: %w", err)
 	}
 
+	// Move events past the hot retention to the archive
+	if am.config.Archive != nil && am.config.HotRetention > 0 {
+		if err := am.startArchiver(ctx); err != nil {
+			return fmt.Errorf("failed to start audit archiver: %w", err)
+		}
+	}
+
 	return nil
 }
 
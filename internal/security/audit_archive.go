@@ -0,0 +1,524 @@
+package security
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// auditManifestName is the archive object listing the archived segments
+const auditManifestName = "manifest.json"
+
+// AuditExportFormatJSONL exports one JSON-encoded audit event per line
+const AuditExportFormatJSONL = "jsonl"
+
+var (
+	ErrAuditArchiveNotFound         = errors.New("audit archive object not found")
+	ErrAuditArchiveCorrupted        = errors.New("audit archive segment is corrupted")
+	ErrUnsupportedAuditExportFormat = errors.New("unsupported audit export format")
+)
+
+// AuditArchive stores archived audit segments and their manifest. FileAuditArchive keeps them
+// on local disk; object stores such as S3 implement the same interface.
+type AuditArchive interface {
+	Put(ctx context.Context, name string, data []byte) error
+	// Get returns ErrAuditArchiveNotFound for unknown names
+	Get(ctx context.Context, name string) ([]byte, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// AuditArchiveSegment describes an archived, contiguous run of the audit hash chain
+type AuditArchiveSegment struct {
+	Name         string    `json:"name"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Events       int       `json:"events"`
+	FirstHash    string    `json:"first_hash"`
+	LastHash     string    `json:"last_hash"`
+	PreviousHash string    `json:"previous_hash,omitempty"` // hash the first event links to
+	Checksum     string    `json:"checksum"`                // SHA-256 of the stored object
+	Encrypted    bool      `json:"encrypted"`
+	ArchivedAt   time.Time `json:"archived_at"`
+	Revision     int       `json:"revision,omitempty"` // incremented when the segment is rewritten
+}
+
+// AuditArchiveManifest indexes the archived segments in chain order
+type AuditArchiveManifest struct {
+	Segments  []AuditArchiveSegment `json:"segments"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// FileAuditArchive stores archive objects as files in a directory
+type FileAuditArchive struct {
+	dir string
+}
+
+// NewFileAuditArchive creates the archive directory if needed
+func NewFileAuditArchive(dir string) (*FileAuditArchive, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit archive directory: %w", err)
+	}
+	return &FileAuditArchive{dir: dir}, nil
+}
+
+// Put writes an object atomically
+func (fa *FileAuditArchive) Put(ctx context.Context, name string, data []byte) error {
+	path, err := fa.path(name)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write audit archive object: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write audit archive object: %w", err)
+	}
+	return nil
+}
+
+// Get reads an object
+func (fa *FileAuditArchive) Get(ctx context.Context, name string) ([]byte, error) {
+	path, err := fa.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrAuditArchiveNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit archive object: %w", err)
+	}
+	return data, nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (fa *FileAuditArchive) Delete(ctx context.Context, name string) error {
+	path, err := fa.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete audit archive object: %w", err)
+	}
+	return nil
+}
+
+func (fa *FileAuditArchive) path(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid audit archive object name %q", name)
+	}
+	return filepath.Join(fa.dir, name), nil
+}
+
+// startArchiver validates the archive settings and archives on every interval until ctx is done
+func (am *AuditManager) startArchiver(ctx context.Context) error {
+	if n := len(am.config.ArchiveKey); n != 0 && n != 32 {
+		return fmt.Errorf("archive key must be 32 bytes, got %d", n)
+	}
+	if am.config.ArchiveInterval <= 0 {
+		return fmt.Errorf("archive interval must be positive")
+	}
+
+	go func() {
+		ticker := time.NewTicker(am.config.ArchiveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := am.ArchiveEvents(ctx, now); err != nil {
+					am.logger.Error(ctx, "Failed to archive audit events", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ArchiveEvents moves events older than the hot retention into a new archive segment and
+// deletes segments that have passed the retention period. It returns the number of events
+// archived.
+func (am *AuditManager) ArchiveEvents(ctx context.Context, now time.Time) (int, error) {
+	if am.config.Archive == nil {
+		return 0, nil
+	}
+
+	am.archiveMu.Lock()
+	defer am.archiveMu.Unlock()
+
+	manifest, err := am.loadManifestLocked(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	events := am.auditStore.eventsBefore(now.Add(-am.config.HotRetention))
+	if len(events) > 0 {
+		segment, err := am.writeSegment(ctx, events, now, 0)
+		if err != nil {
+			return 0, err
+		}
+		manifest.Segments = append(manifest.Segments, *segment)
+		if err := am.saveManifestLocked(ctx, now); err != nil {
+			am.config.Archive.Delete(ctx, segment.Name)
+			manifest.Segments = manifest.Segments[:len(manifest.Segments)-1]
+			return 0, err
+		}
+		am.auditStore.removeThrough(events[len(events)-1].EventID)
+
+		am.logger.Info(ctx, "Archived audit events", map[string]interface{}{
+			"segment": segment.Name,
+			"events":  segment.Events,
+			"from":    segment.From,
+			"to":      segment.To,
+		})
+	}
+
+	if err := am.purgeArchivesLocked(ctx, now); err != nil {
+		return len(events), err
+	}
+	return len(events), nil
+}
+
+// ExportEvents streams the events logged between from and to (inclusive) to w in chain order,
+// reading archived segments where the range reaches past the hot store. Events keep their
+// hashes so the export can be checked with VerifyAuditExport. It returns the number of events
+// written.
+func (am *AuditManager) ExportEvents(ctx context.Context, from, to time.Time, format string, w io.Writer) (int, error) {
+	if format != "" && format != AuditExportFormatJSONL {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedAuditExportFormat, format)
+	}
+
+	am.archiveMu.RLock()
+	defer am.archiveMu.RUnlock()
+
+	encoder := json.NewEncoder(w)
+	written := 0
+
+	if am.config.Archive != nil {
+		manifest, err := am.loadManifestLocked(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, segment := range manifest.Segments {
+			if segment.To.Before(from) || segment.From.After(to) {
+				continue
+			}
+			events, err := am.readSegment(ctx, segment)
+			if err != nil {
+				return written, err
+			}
+			for i := range events {
+				if events[i].Timestamp.Before(from) || events[i].Timestamp.After(to) {
+					continue
+				}
+				if err := encoder.Encode(&events[i]); err != nil {
+					return written, err
+				}
+				written++
+			}
+		}
+	}
+
+	events, err := am.auditStore.GetEvents(AuditEventFilter{StartTime: &from, EndTime: &to})
+	if err != nil {
+		return written, err
+	}
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// GetArchiveManifest returns a copy of the archive manifest
+func (am *AuditManager) GetArchiveManifest(ctx context.Context) (*AuditArchiveManifest, error) {
+	if am.config.Archive == nil {
+		return &AuditArchiveManifest{}, nil
+	}
+
+	am.archiveMu.RLock()
+	defer am.archiveMu.RUnlock()
+
+	manifest, err := am.loadManifestLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := *manifest
+	result.Segments = append([]AuditArchiveSegment(nil), manifest.Segments...)
+	return &result, nil
+}
+
+// VerifyAuditExport checks the hashes and chain of a JSONL export with the store's
+// VerifyIntegrity
+func VerifyAuditExport(r io.Reader) (bool, error) {
+	store := NewAuditStore(nil, &AuditConfig{})
+	decoder := json.NewDecoder(r)
+	for {
+		var event AuditEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return false, fmt.Errorf("failed to decode audit export: %w", err)
+		}
+		store.events = append(store.events, event)
+	}
+	return store.VerifyIntegrity()
+}
+
+// loadManifestLocked reads the manifest on first use. The caller must hold am.archiveMu; the
+// manifest is cached, so concurrent readers only race on the first load and both read the
+// same object.
+func (am *AuditManager) loadManifestLocked(ctx context.Context) (*AuditArchiveManifest, error) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if am.manifest != nil {
+		return am.manifest, nil
+	}
+
+	manifest := &AuditArchiveManifest{}
+	data, err := am.config.Archive.Get(ctx, auditManifestName)
+	switch {
+	case errors.Is(err, ErrAuditArchiveNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to load audit archive manifest: %w", err)
+	default:
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode audit archive manifest: %w", err)
+		}
+	}
+
+	am.manifest = manifest
+	return manifest, nil
+}
+
+// saveManifestLocked writes the manifest. The caller must hold am.archiveMu for writing.
+func (am *AuditManager) saveManifestLocked(ctx context.Context, now time.Time) error {
+	am.manifest.UpdatedAt = now
+	data, err := json.MarshalIndent(am.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode audit archive manifest: %w", err)
+	}
+	if err := am.config.Archive.Put(ctx, auditManifestName, data); err != nil {
+		return fmt.Errorf("failed to save audit archive manifest: %w", err)
+	}
+	return nil
+}
+
+// purgeArchivesLocked deletes the oldest segments that ended before the retention period.
+// The caller must hold am.archiveMu for writing.
+func (am *AuditManager) purgeArchivesLocked(ctx context.Context, now time.Time) error {
+	if am.config.RetentionPeriod <= 0 {
+		return nil
+	}
+
+	cutoff := now.Add(-am.config.RetentionPeriod)
+	expired := 0
+	for _, segment := range am.manifest.Segments {
+		if !segment.To.Before(cutoff) {
+			break
+		}
+		expired++
+	}
+	if expired == 0 {
+		return nil
+	}
+
+	// Drop the segments from the manifest first so a failed delete leaves an orphaned object
+	// rather than a manifest entry without data
+	removed := am.manifest.Segments[:expired]
+	am.manifest.Segments = append([]AuditArchiveSegment(nil), am.manifest.Segments[expired:]...)
+	if err := am.saveManifestLocked(ctx, now); err != nil {
+		am.manifest.Segments = append(removed, am.manifest.Segments...)
+		return err
+	}
+
+	for _, segment := range removed {
+		if err := am.config.Archive.Delete(ctx, segment.Name); err != nil {
+			am.logger.Warn(ctx, "Failed to delete expired audit archive segment", map[string]interface{}{
+				"segment": segment.Name,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	am.logger.Info(ctx, "Deleted expired audit archive segments", map[string]interface{}{
+		"segments": expired,
+		"cutoff":   cutoff,
+	})
+	return nil
+}
+
+// writeSegment stores events as gzip-compressed JSONL, encrypted when an archive key is set
+func (am *AuditManager) writeSegment(ctx context.Context, events []AuditEvent, now time.Time, revision int) (*AuditArchiveSegment, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return nil, fmt.Errorf("failed to encode audit event: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress audit segment: %w", err)
+	}
+
+	first, last := events[0], events[len(events)-1]
+	name := fmt.Sprintf("audit-%s-%s", first.Timestamp.UTC().Format("20060102T150405Z"), first.EventID)
+	if revision > 0 {
+		name += fmt.Sprintf("-r%d", revision)
+	}
+	segment := &AuditArchiveSegment{
+		Name:         name + ".jsonl.gz",
+		From:         first.Timestamp,
+		To:           last.Timestamp,
+		Events:       len(events),
+		FirstHash:    first.Hash,
+		LastHash:     last.Hash,
+		PreviousHash: first.PreviousHash,
+		ArchivedAt:   now,
+		Revision:     revision,
+	}
+
+	data := buf.Bytes()
+	if len(am.config.ArchiveKey) > 0 {
+		sealed, err := sealAuditSegment(am.config.ArchiveKey, data)
+		if err != nil {
+			return nil, err
+		}
+		data = sealed
+		segment.Name += ".enc"
+		segment.Encrypted = true
+	}
+	segment.Checksum = fmt.Sprintf("%x", sha256.Sum256(data))
+
+	if err := am.config.Archive.Put(ctx, segment.Name, data); err != nil {
+		return nil, fmt.Errorf("failed to store audit segment: %w", err)
+	}
+	return segment, nil
+}
+
+// readSegment loads and decodes an archived segment, checking it against the manifest
+func (am *AuditManager) readSegment(ctx context.Context, segment AuditArchiveSegment) ([]AuditEvent, error) {
+	data, err := am.config.Archive.Get(ctx, segment.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit segment %s: %w", segment.Name, err)
+	}
+	if fmt.Sprintf("%x", sha256.Sum256(data)) != segment.Checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch for %s", ErrAuditArchiveCorrupted, segment.Name)
+	}
+
+	if segment.Encrypted {
+		if data, err = openAuditSegment(am.config.ArchiveKey, data); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrAuditArchiveCorrupted, segment.Name, err)
+		}
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrAuditArchiveCorrupted, segment.Name, err)
+	}
+	defer zr.Close()
+
+	events := make([]AuditEvent, 0, segment.Events)
+	decoder := json.NewDecoder(zr)
+	for {
+		var event AuditEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrAuditArchiveCorrupted, segment.Name, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// sealAuditSegment encrypts data with AES-256-GCM, prefixing the nonce
+func sealAuditSegment(key, data []byte) ([]byte, error) {
+	gcm, err := newAuditSegmentCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// openAuditSegment decrypts data sealed by sealAuditSegment
+func openAuditSegment(key, data []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("segment is encrypted but no archive key is configured")
+	}
+	gcm, err := newAuditSegmentCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAuditSegmentCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// eventsBefore returns the oldest events up to the first one logged at or after cutoff, so
+// the result is a contiguous start of the chain
+func (as *AuditStore) eventsBefore(cutoff time.Time) []AuditEvent {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	n := 0
+	for n < len(as.events) && as.events[n].Timestamp.Before(cutoff) {
+		n++
+	}
+	return append([]AuditEvent(nil), as.events[:n]...)
+}
+
+// removeThrough drops the events up to and including eventID from the store
+func (as *AuditStore) removeThrough(eventID string) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	for i := range as.events {
+		if as.events[i].EventID != eventID {
+			continue
+		}
+		as.events = append([]AuditEvent(nil), as.events[i+1:]...)
+		as.eventIndex = make(map[string]*AuditEvent)
+		for j := range as.events {
+			as.eventIndex[as.events[j].EventID] = &as.events[j]
+		}
+		return
+	}
+}
@@ -0,0 +1,147 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newArchivingAuditManager(t *testing.T, dir string) *AuditManager {
+	archive, err := NewFileAuditArchive(dir)
+	require.NoError(t, err)
+
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	return NewAuditManager(logger, &AuditConfig{
+		EnableAuditLogging:   true,
+		EnableIntegrityCheck: true,
+		RetentionPeriod:      30 * 24 * time.Hour,
+		ArchiveThreshold:     1 << 30,
+		HotRetention:         24 * time.Hour,
+		ArchiveInterval:      time.Hour,
+		Archive:              archive,
+		ArchiveKey:           bytes.Repeat([]byte{7}, 32),
+	}, nil)
+}
+
+func TestAuditArchive(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	am := newArchivingAuditManager(t, dir)
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, am.LogEvent(ctx, &AuditEvent{
+			Timestamp: now.Add(-10*24*time.Hour + time.Duration(i)*time.Minute),
+			EventType: AuditEventTypeTrading,
+			Action:    "archived_order",
+			Result:    AuditResultSuccess,
+			Details:   map[string]interface{}{"sequence": i},
+		}))
+	}
+	for i := 3; i > 0; i-- {
+		require.NoError(t, am.LogEvent(ctx, &AuditEvent{
+			Timestamp: now.Add(-time.Duration(i) * time.Minute),
+			EventType: AuditEventTypeTrading,
+			Action:    "hot_order",
+			Result:    AuditResultSuccess,
+		}))
+	}
+
+	archived, err := am.ArchiveEvents(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 5, archived)
+
+	hot, err := am.GetAuditEvents(ctx, AuditEventFilter{})
+	require.NoError(t, err)
+	assert.Len(t, hot, 3)
+	valid, err := am.VerifyIntegrity(ctx)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	manifest, err := am.GetArchiveManifest(ctx)
+	require.NoError(t, err)
+	require.Len(t, manifest.Segments, 1)
+	segment := manifest.Segments[0]
+	assert.Equal(t, 5, segment.Events)
+	assert.True(t, segment.Encrypted)
+	assert.Equal(t, hot[0].PreviousHash, segment.LastHash, "the hot store continues the archived chain")
+
+	stored, err := os.ReadFile(filepath.Join(dir, segment.Name))
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "archived_order")
+
+	t.Run("ExportAcrossTiers", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := am.ExportEvents(ctx, now.Add(-11*24*time.Hour), now, AuditExportFormatJSONL, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 8, n)
+		assert.Equal(t, 8, strings.Count(buf.String(), "\n"))
+
+		valid, err := VerifyAuditExport(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		tampered := strings.Replace(buf.String(), "hot_order", "hot_0rder", 1)
+		valid, err = VerifyAuditExport(strings.NewReader(tampered))
+		assert.Error(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("ExportRange", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := am.ExportEvents(ctx, now.Add(-10*24*time.Hour+time.Minute), now.Add(-10*24*time.Hour+3*time.Minute), "", &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.NotContains(t, buf.String(), "hot_order")
+
+		_, err = am.ExportEvents(ctx, now.Add(-time.Hour), now, "csv", &buf)
+		assert.ErrorIs(t, err, ErrUnsupportedAuditExportFormat)
+	})
+
+	t.Run("ManifestSurvivesRestart", func(t *testing.T) {
+		restarted := newArchivingAuditManager(t, dir)
+		var buf bytes.Buffer
+		n, err := restarted.ExportEvents(ctx, now.Add(-11*24*time.Hour), now, AuditExportFormatJSONL, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+	})
+
+	t.Run("CorruptedSegment", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, segment.Name), append(stored, 0), 0o600))
+		defer os.WriteFile(filepath.Join(dir, segment.Name), stored, 0o600)
+
+		_, err := am.ExportEvents(ctx, now.Add(-11*24*time.Hour), now, AuditExportFormatJSONL, &bytes.Buffer{})
+		assert.ErrorIs(t, err, ErrAuditArchiveCorrupted)
+	})
+
+	t.Run("ComplianceRetention", func(t *testing.T) {
+		// 25 days later the hot events are archived and the first segment is past retention
+		archived, err := am.ArchiveEvents(ctx, now.Add(25*24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 3, archived)
+
+		manifest, err := am.GetArchiveManifest(ctx)
+		require.NoError(t, err)
+		require.Len(t, manifest.Segments, 1)
+		assert.Equal(t, 3, manifest.Segments[0].Events)
+		_, err = os.Stat(filepath.Join(dir, segment.Name))
+		assert.True(t, os.IsNotExist(err))
+
+		var buf bytes.Buffer
+		n, err := am.ExportEvents(ctx, now.Add(-11*24*time.Hour), now, AuditExportFormatJSONL, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		valid, err := VerifyAuditExport(&buf)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+}
@@ -0,0 +1,115 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PseudonymizeUser replaces a user's ID with pseudonym in the hot store and in archived
+// segments, for erasure requests of records that must be kept. Changed events are re-hashed
+// and their successors relinked, so VerifyIntegrity and exports still verify. It returns the
+// number of events changed.
+func (am *AuditManager) PseudonymizeUser(ctx context.Context, userID, pseudonym uuid.UUID) (int, error) {
+	am.archiveMu.Lock()
+	defer am.archiveMu.Unlock()
+
+	// Old hash of every re-hashed event to its new hash, to relink the next event
+	rehashed := make(map[string]string)
+	changed := 0
+	pseudonymize := func(events []AuditEvent) bool {
+		modified := false
+		for i := range events {
+			event := &events[i]
+			if hash, ok := rehashed[event.PreviousHash]; ok {
+				event.PreviousHash = hash
+				modified = true
+			}
+			if event.UserID == nil || *event.UserID != userID {
+				continue
+			}
+			previous := event.Hash
+			event.UserID = &pseudonym
+			event.Hash = am.calculateEventHash(event)
+			rehashed[previous] = event.Hash
+			changed++
+			modified = true
+		}
+		return modified
+	}
+
+	if am.config.Archive != nil {
+		if err := am.pseudonymizeArchiveLocked(ctx, pseudonymize); err != nil {
+			return changed, err
+		}
+	}
+	am.auditStore.rewrite(pseudonymize)
+
+	if changed > 0 {
+		am.logger.Info(ctx, "Pseudonymized user in audit log", map[string]interface{}{
+			"pseudonym": pseudonym.String(),
+			"events":    changed,
+		})
+	}
+	return changed, nil
+}
+
+// pseudonymizeArchiveLocked rewrites the segments that pseudonymize changes under a new
+// revision; the old objects are deleted once the manifest points to the new ones. The caller
+// must hold am.archiveMu for writing.
+func (am *AuditManager) pseudonymizeArchiveLocked(ctx context.Context, pseudonymize func([]AuditEvent) bool) error {
+	manifest, err := am.loadManifestLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	segments := append([]AuditArchiveSegment(nil), manifest.Segments...)
+	var replaced []string
+	for i, segment := range segments {
+		events, err := am.readSegment(ctx, segment)
+		if err != nil {
+			return err
+		}
+		if !pseudonymize(events) {
+			continue
+		}
+		rewritten, err := am.writeSegment(ctx, events, segment.ArchivedAt, segment.Revision+1)
+		if err != nil {
+			return err
+		}
+		segments[i] = *rewritten
+		replaced = append(replaced, segment.Name)
+	}
+	if len(replaced) == 0 {
+		return nil
+	}
+
+	previous := manifest.Segments
+	manifest.Segments = segments
+	if err := am.saveManifestLocked(ctx, time.Now()); err != nil {
+		manifest.Segments = previous
+		return fmt.Errorf("failed to save pseudonymized audit archive: %w", err)
+	}
+	for _, name := range replaced {
+		if err := am.config.Archive.Delete(ctx, name); err != nil {
+			am.logger.Warn(ctx, "Failed to delete replaced audit archive segment", map[string]interface{}{
+				"segment": name,
+				"error":   err.Error(),
+			})
+		}
+	}
+	return nil
+}
+
+// rewrite lets fn modify the stored events in chain order
+func (as *AuditStore) rewrite(fn func([]AuditEvent) bool) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if !fn(as.events) || len(as.events) == 0 {
+		return
+	}
+	as.lastHash = as.events[len(as.events)-1].Hash
+}
@@ -1,6 +1,7 @@
 package security
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
 )
 
 // EncryptionManager handles all encryption operations
@@ -23,6 +25,10 @@ type EncryptionManager struct {
 	config         *EncryptionConfig
 	keyManager     *KeyManager
 	encryptionKeys map[string]*EncryptionKey
+	escrowKey      *rsa.PublicKey
+	auditor        *AuditManager
+	piiIndexKey    []byte
+	searchablePII  map[string]bool
 	mu             sync.RWMutex
 }
 
@@ -154,6 +160,33 @@ func (em *EncryptionManager) DecryptData(request *DecryptionRequest) ([]byte, er
 	}
 }
 
+// ImportKey registers a fixed AES-256 key for purpose and returns its ID. Data encrypted for
+// the purpose can be decrypted after a restart as long as the same key is imported again.
+// Imported keys are not rotated automatically.
+func (em *EncryptionManager) ImportKey(purpose string, keyData []byte) (string, error) {
+	if len(keyData) != 32 {
+		return "", fmt.Errorf("imported keys must be 32 bytes, got %d", len(keyData))
+	}
+
+	key := &EncryptionKey{
+		Algorithm:   "AES-256-GCM",
+		KeyData:     append([]byte(nil), keyData...),
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().AddDate(100, 0, 0),
+		Purpose:     purpose,
+		Status:      "active",
+		Version:     1,
+		Fingerprint: em.keyManager.generateFingerprint(keyData),
+	}
+	key.ID = fmt.Sprintf("%s_%s", purpose, key.Fingerprint)
+
+	em.keyManager.mu.Lock()
+	em.keyManager.keyStore[key.ID] = key
+	em.keyManager.mu.Unlock()
+
+	return key.ID, nil
+}
+
 // EncryptPII encrypts personally identifiable information
 func (em *EncryptionManager) EncryptPII(piiData map[string]interface{}) (map[string]interface{}, error) {
 	encryptedData := make(map[string]interface{})
@@ -185,6 +218,38 @@ func (em *EncryptionManager) EncryptPII(piiData map[string]interface{}) (map[str
 	return encryptedData, nil
 }
 
+// SetAuditor records key escrow operations and PII lookups and exports in the audit log
+func (em *EncryptionManager) SetAuditor(auditor *AuditManager) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.auditor = auditor
+}
+
+// audit records a security event in the audit log, when one is set. The operator is recorded
+// in the event's details, and as its user when it is a user ID.
+func (em *EncryptionManager) audit(ctx context.Context, operator string, event *AuditEvent) error {
+	em.mu.RLock()
+	auditor := em.auditor
+	em.mu.RUnlock()
+	if auditor == nil {
+		return nil
+	}
+
+	event.EventType = AuditEventTypeSecurity
+	event.Category = AuditCategorySecurity
+	event.ComplianceTag = "SEC"
+	if operator != "" {
+		event.Details["operator"] = operator
+		if operatorID, err := uuid.Parse(operator); err == nil {
+			event.UserID = &operatorID
+		}
+	}
+	if err := auditor.LogEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to audit %s: %w", event.Action, err)
+	}
+	return nil
+}
+
 // encryptAESGCM encrypts data using AES-256-GCM
 func (em *EncryptionManager) encryptAESGCM(data []byte, key *EncryptionKey) (*EncryptionResult, error) {
 	block, err := aes.NewCipher(key.KeyData)
@@ -418,16 +483,23 @@ func (km *KeyManager) GenerateKey(purpose, algorithm string) error {
 		return fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
 
-	// Create key metadata
+	// Create key metadata; rotated keys get the next version of their purpose and are kept
+	// under their own IDs, so data encrypted with them can still be decrypted
+	version := 1
+	for _, existing := range km.keyStore {
+		if existing.Purpose == purpose && existing.Version >= version {
+			version = existing.Version + 1
+		}
+	}
 	key := &EncryptionKey{
-		ID:          fmt.Sprintf("%s_%s_%d", purpose, algorithm, time.Now().Unix()),
+		ID:          fmt.Sprintf("%s_%s_%d", purpose, algorithm, time.Now().UnixNano()),
 		Algorithm:   algorithm,
 		KeyData:     keyData,
 		CreatedAt:   time.Now(),
 		ExpiresAt:   time.Now().Add(km.config.KeyRotationInterval),
 		Purpose:     purpose,
 		Status:      "active",
-		Version:     1,
+		Version:     version,
 		Fingerprint: km.generateFingerprint(keyData),
 	}
 
@@ -0,0 +1,39 @@
+package security
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptionManager_ImportKey(t *testing.T) {
+	logger := &observability.Logger{}
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	first := NewEncryptionManager(logger, &EncryptionConfig{Algorithm: "AES-256-GCM"})
+	keyID, err := first.ImportKey("exchange_credentials", key)
+	require.NoError(t, err)
+
+	sealed, err := first.EncryptData([]byte("secret"), "exchange_credentials")
+	require.NoError(t, err)
+	assert.Equal(t, keyID, sealed.KeyID)
+
+	// A restarted manager importing the same key decrypts data sealed before
+	restarted := NewEncryptionManager(logger, &EncryptionConfig{Algorithm: "AES-256-GCM"})
+	_, err = restarted.ImportKey("exchange_credentials", key)
+	require.NoError(t, err)
+
+	plaintext, err := restarted.DecryptData(&DecryptionRequest{
+		EncryptedData: sealed.EncryptedData,
+		KeyID:         sealed.KeyID,
+		Algorithm:     sealed.Algorithm,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	_, err = restarted.ImportKey("exchange_credentials", key[:16])
+	assert.Error(t, err)
+}
@@ -0,0 +1,410 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data owners taking part in right-to-erasure requests
+const (
+	DataOwnerConversations    = "conversations"
+	DataOwnerBehaviorProfiles = "behavior_profiles"
+	DataOwnerTradingHistory   = "trading_history"
+	DataOwnerAuditLog         = "audit_log"
+)
+
+// maxErasureAttempts is how often a data owner is retried before its task fails
+const maxErasureAttempts = 5
+
+var (
+	ErrErasureRequestNotFound = errors.New("erasure request not found")
+	ErrErasureNotConfigured   = errors.New("erasure requests are not configured")
+)
+
+// ErasureAction is what a data owner did with a user's records
+type ErasureAction string
+
+const (
+	ErasureActionDelete       ErasureAction = "delete"
+	ErasureActionPseudonymize ErasureAction = "pseudonymize" // records that must be kept, e.g. financial records
+)
+
+// ErasureStatus is the status of an erasure request or of one data owner's task
+type ErasureStatus string
+
+const (
+	ErasureStatusPending    ErasureStatus = "pending"
+	ErasureStatusInProgress ErasureStatus = "in_progress"
+	ErasureStatusCompleted  ErasureStatus = "completed"
+	ErasureStatusFailed     ErasureStatus = "failed"
+)
+
+// ErasureRequest tracks the erasure of a user's data across all data owners
+type ErasureRequest struct {
+	ID          uuid.UUID           `json:"id"`
+	UserID      uuid.UUID           `json:"user_id"`
+	Pseudonym   uuid.UUID           `json:"-"` // replaces the user ID in records that cannot be deleted
+	RequestedBy uuid.UUID           `json:"requested_by"`
+	Status      ErasureStatus       `json:"status"`
+	Tasks       []ErasureTask       `json:"tasks"`
+	Certificate *ErasureCertificate `json:"certificate,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+}
+
+// ErasureTask is one data owner's part of an erasure request
+type ErasureTask struct {
+	Owner       string        `json:"owner"`
+	Status      ErasureStatus `json:"status"`
+	Action      ErasureAction `json:"action,omitempty"`
+	Records     int           `json:"records"`
+	Attempts    int           `json:"attempts"`
+	Error       string        `json:"error,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
+
+// PendingErasure is an erasure task waiting for its data owner
+type PendingErasure struct {
+	RequestID uuid.UUID
+	UserID    uuid.UUID
+	Pseudonym uuid.UUID
+	Task      ErasureTask
+}
+
+// ErasureCertificate attests that every data owner erased or pseudonymized a user's data.
+// Signature is an HMAC-SHA256 of the certificate without the signature.
+type ErasureCertificate struct {
+	RequestID uuid.UUID     `json:"request_id"`
+	UserID    uuid.UUID     `json:"user_id"`
+	Stores    []ErasureTask `json:"stores"`
+	IssuedAt  time.Time     `json:"issued_at"`
+	Signature string        `json:"signature"`
+}
+
+// ErasureStore persists erasure requests so that every service can work on the tasks of its
+// own data owners
+type ErasureStore interface {
+	CreateErasureRequest(ctx context.Context, request *ErasureRequest) error
+	// GetErasureRequest returns ErrErasureRequestNotFound for unknown requests
+	GetErasureRequest(ctx context.Context, id uuid.UUID) (*ErasureRequest, error)
+	PendingErasures(ctx context.Context, owner string, limit int) ([]PendingErasure, error)
+	// UpdateErasureTask saves a task unless it is no longer pending
+	UpdateErasureTask(ctx context.Context, requestID uuid.UUID, task ErasureTask) error
+	// UpdateErasureRequest saves the status, certificate and completion time of a request
+	UpdateErasureRequest(ctx context.Context, request *ErasureRequest) error
+}
+
+// DataOwner erases a user's data from one store. Erasure must be idempotent, as a task can
+// be retried or run by several replicas.
+type DataOwner interface {
+	Name() string
+	EraseUserData(ctx context.Context, userID, pseudonym uuid.UUID) (ErasureAction, int, error)
+}
+
+type dataOwner struct {
+	name   string
+	action ErasureAction
+	erase  func(ctx context.Context, userID, pseudonym uuid.UUID) (int, error)
+}
+
+func (o *dataOwner) Name() string {
+	return o.name
+}
+
+func (o *dataOwner) EraseUserData(ctx context.Context, userID, pseudonym uuid.UUID) (ErasureAction, int, error) {
+	records, err := o.erase(ctx, userID, pseudonym)
+	return o.action, records, err
+}
+
+// NewDeletingDataOwner creates a data owner that deletes a user's records and returns how
+// many were deleted
+func NewDeletingDataOwner(name string, deleteFn func(ctx context.Context, userID uuid.UUID) (int, error)) DataOwner {
+	return &dataOwner{
+		name:   name,
+		action: ErasureActionDelete,
+		erase: func(ctx context.Context, userID, _ uuid.UUID) (int, error) {
+			return deleteFn(ctx, userID)
+		},
+	}
+}
+
+// NewPseudonymizingDataOwner creates a data owner for records that must be kept, which
+// replaces the user's ID with the pseudonym and returns how many records were changed
+func NewPseudonymizingDataOwner(name string, pseudonymizeFn func(ctx context.Context, userID, pseudonym uuid.UUID) (int, error)) DataOwner {
+	return &dataOwner{name: name, action: ErasureActionPseudonymize, erase: pseudonymizeFn}
+}
+
+// SetErasureStore enables erasure requests. Requests get a task for each of owners and each
+// locally registered data owner; certificates are signed with signingKey.
+func (pm *PrivacyManager) SetErasureStore(store ErasureStore, owners []string, signingKey []byte) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.erasureStore = store
+	pm.erasureOwnerNames = owners
+	pm.erasureSigningKey = signingKey
+}
+
+// RegisterDataOwner lets this service process the erasure tasks of owner
+func (pm *PrivacyManager) RegisterDataOwner(owner DataOwner) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.dataOwners[owner.Name()] = owner
+}
+
+// RequestErasure creates an erasure request for a user's data. Privacy records held by the
+// manager are deleted immediately; data owners process their tasks asynchronously.
+func (pm *PrivacyManager) RequestErasure(ctx context.Context, userID, requestedBy uuid.UUID) (*ErasureRequest, error) {
+	if !pm.config.EnableRightToErasure {
+		return nil, fmt.Errorf("right to erasure not enabled")
+	}
+
+	pm.mu.RLock()
+	store := pm.erasureStore
+	owners := make(map[string]bool)
+	for _, name := range pm.erasureOwnerNames {
+		owners[name] = true
+	}
+	for name := range pm.dataOwners {
+		owners[name] = true
+	}
+	pm.mu.RUnlock()
+
+	if store == nil {
+		return nil, ErrErasureNotConfigured
+	}
+
+	now := time.Now()
+	request := &ErasureRequest{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Pseudonym:   uuid.New(),
+		RequestedBy: requestedBy,
+		Status:      ErasureStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	names := make([]string, 0, len(owners))
+	for name := range owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request.Tasks = append(request.Tasks, ErasureTask{Owner: name, Status: ErasureStatusPending})
+	}
+
+	if err := store.CreateErasureRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to create erasure request: %w", err)
+	}
+
+	if err := pm.DeleteUserData(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	pm.logger.Info(ctx, "Erasure requested", map[string]interface{}{
+		"request_id":   request.ID.String(),
+		"user_id":      userID.String(),
+		"requested_by": requestedBy.String(),
+		"tasks":        len(request.Tasks),
+	})
+
+	return request, nil
+}
+
+// GetErasureRequest returns an erasure request with the status of each data owner
+func (pm *PrivacyManager) GetErasureRequest(ctx context.Context, id uuid.UUID) (*ErasureRequest, error) {
+	pm.mu.RLock()
+	store := pm.erasureStore
+	pm.mu.RUnlock()
+
+	if store == nil {
+		return nil, ErrErasureNotConfigured
+	}
+	return store.GetErasureRequest(ctx, id)
+}
+
+// StartErasureWorker processes the tasks of the registered data owners every interval until
+// ctx is done
+func (pm *PrivacyManager) StartErasureWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := pm.ProcessErasures(ctx); err != nil {
+					pm.logger.Error(ctx, "Failed to process erasure requests", err)
+				}
+			}
+		}
+	}()
+}
+
+// ProcessErasures runs the pending tasks of the registered data owners and completes the
+// requests whose tasks are all done
+func (pm *PrivacyManager) ProcessErasures(ctx context.Context) error {
+	pm.mu.RLock()
+	store := pm.erasureStore
+	owners := make([]DataOwner, 0, len(pm.dataOwners))
+	for _, owner := range pm.dataOwners {
+		owners = append(owners, owner)
+	}
+	pm.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+
+	for _, owner := range owners {
+		pending, err := store.PendingErasures(ctx, owner.Name(), 100)
+		if err != nil {
+			return fmt.Errorf("failed to list erasure tasks of %s: %w", owner.Name(), err)
+		}
+
+		for _, erasure := range pending {
+			task := erasure.Task
+			task.Attempts++
+
+			action, records, err := owner.EraseUserData(ctx, erasure.UserID, erasure.Pseudonym)
+			if err != nil {
+				task.Error = err.Error()
+				if task.Attempts >= maxErasureAttempts {
+					task.Status = ErasureStatusFailed
+				}
+				pm.logger.Warn(ctx, "Erasure task failed", map[string]interface{}{
+					"request_id": erasure.RequestID.String(),
+					"owner":      owner.Name(),
+					"attempts":   task.Attempts,
+					"error":      err.Error(),
+				})
+			} else {
+				now := time.Now()
+				task.Status = ErasureStatusCompleted
+				task.Action = action
+				task.Records = records
+				task.Error = ""
+				task.CompletedAt = &now
+			}
+
+			if err := store.UpdateErasureTask(ctx, erasure.RequestID, task); err != nil {
+				return fmt.Errorf("failed to update erasure task: %w", err)
+			}
+			if err := pm.finishErasureRequest(ctx, store, erasure.RequestID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// finishErasureRequest updates the status of a request from its tasks and issues the
+// certificate once every data owner has completed
+func (pm *PrivacyManager) finishErasureRequest(ctx context.Context, store ErasureStore, id uuid.UUID) error {
+	request, err := store.GetErasureRequest(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to load erasure request: %w", err)
+	}
+	if request.Status == ErasureStatusCompleted || request.Status == ErasureStatusFailed {
+		return nil
+	}
+
+	status := ErasureStatusCompleted
+	for _, task := range request.Tasks {
+		if task.Status == ErasureStatusFailed {
+			status = ErasureStatusFailed
+			break
+		}
+		if task.Status != ErasureStatusCompleted {
+			status = ErasureStatusInProgress
+		}
+	}
+	if status == request.Status {
+		return nil
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.UpdatedAt = now
+	if status == ErasureStatusCompleted || status == ErasureStatusFailed {
+		request.CompletedAt = &now
+	}
+	if status == ErasureStatusCompleted {
+		pm.mu.RLock()
+		key := pm.erasureSigningKey
+		pm.mu.RUnlock()
+
+		request.Certificate = &ErasureCertificate{
+			RequestID: request.ID,
+			UserID:    request.UserID,
+			Stores:    request.Tasks,
+			IssuedAt:  now,
+		}
+		if err := SignErasureCertificate(key, request.Certificate); err != nil {
+			return err
+		}
+	}
+
+	if err := store.UpdateErasureRequest(ctx, request); err != nil {
+		return fmt.Errorf("failed to update erasure request: %w", err)
+	}
+
+	if request.CompletedAt != nil {
+		pm.logger.Info(ctx, "Erasure request finished", map[string]interface{}{
+			"request_id": request.ID.String(),
+			"status":     string(request.Status),
+		})
+	}
+	return nil
+}
+
+// SignErasureCertificate sets the certificate's signature
+func SignErasureCertificate(key []byte, certificate *ErasureCertificate) error {
+	signature, err := erasureCertificateSignature(key, certificate)
+	if err != nil {
+		return err
+	}
+	certificate.Signature = signature
+	return nil
+}
+
+// VerifyErasureCertificate reports whether the certificate was signed with key and not
+// changed since
+func VerifyErasureCertificate(key []byte, certificate *ErasureCertificate) bool {
+	expected, err := erasureCertificateSignature(key, certificate)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(certificate.Signature))
+}
+
+func erasureCertificateSignature(key []byte, certificate *ErasureCertificate) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("erasure certificate signing key is not configured")
+	}
+
+	unsigned := *certificate
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode erasure certificate: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,166 @@
+package security
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresErasureStore implements ErasureStore using the privacy_erasure_requests and
+// privacy_erasure_tasks tables
+type postgresErasureStore struct {
+	db *database.DB
+}
+
+// NewPostgresErasureStore creates the erasure store shared by all services
+func NewPostgresErasureStore(db *database.DB) ErasureStore {
+	return &postgresErasureStore{db: db}
+}
+
+func (s *postgresErasureStore) CreateErasureRequest(ctx context.Context, request *ErasureRequest) error {
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO privacy_erasure_requests (id, user_id, pseudonym, requested_by, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, request.ID, request.UserID, request.Pseudonym, request.RequestedBy, string(request.Status), request.CreatedAt, request.UpdatedAt); err != nil {
+			return err
+		}
+		for _, task := range request.Tasks {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO privacy_erasure_tasks (request_id, owner, status)
+				VALUES ($1, $2, $3)
+			`, request.ID, task.Owner, string(task.Status)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *postgresErasureStore) GetErasureRequest(ctx context.Context, id uuid.UUID) (*ErasureRequest, error) {
+	request := &ErasureRequest{ID: id}
+	var status string
+	var certificate []byte
+	var completedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id, pseudonym, requested_by, status, certificate, created_at, updated_at, completed_at
+		FROM privacy_erasure_requests WHERE id = $1
+	`, id).Scan(&request.UserID, &request.Pseudonym, &request.RequestedBy, &status, &certificate, &request.CreatedAt, &request.UpdatedAt, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrErasureRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get erasure request: %w", err)
+	}
+	request.Status = ErasureStatus(status)
+	if completedAt.Valid {
+		request.CompletedAt = &completedAt.Time
+	}
+	if len(certificate) > 0 {
+		request.Certificate = &ErasureCertificate{}
+		if err := json.Unmarshal(certificate, request.Certificate); err != nil {
+			return nil, fmt.Errorf("failed to decode erasure certificate: %w", err)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT owner, status, COALESCE(action, ''), records, attempts, COALESCE(error, ''), completed_at
+		FROM privacy_erasure_tasks WHERE request_id = $1 ORDER BY owner
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get erasure tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task, err := scanErasureTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		request.Tasks = append(request.Tasks, task)
+	}
+	return request, rows.Err()
+}
+
+func (s *postgresErasureStore) PendingErasures(ctx context.Context, owner string, limit int) ([]PendingErasure, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.user_id, r.pseudonym, t.owner, t.status, COALESCE(t.action, ''), t.records, t.attempts, COALESCE(t.error, ''), t.completed_at
+		FROM privacy_erasure_tasks t
+		JOIN privacy_erasure_requests r ON r.id = t.request_id
+		WHERE t.owner = $1 AND t.status = 'pending'
+		ORDER BY r.created_at
+		LIMIT $2
+	`, owner, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending erasures: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []PendingErasure
+	for rows.Next() {
+		var erasure PendingErasure
+		var status, action string
+		var completedAt sql.NullTime
+		if err := rows.Scan(&erasure.RequestID, &erasure.UserID, &erasure.Pseudonym, &erasure.Task.Owner, &status, &action,
+			&erasure.Task.Records, &erasure.Task.Attempts, &erasure.Task.Error, &completedAt); err != nil {
+			return nil, err
+		}
+		erasure.Task.Status = ErasureStatus(status)
+		erasure.Task.Action = ErasureAction(action)
+		if completedAt.Valid {
+			erasure.Task.CompletedAt = &completedAt.Time
+		}
+		pending = append(pending, erasure)
+	}
+	return pending, rows.Err()
+}
+
+func (s *postgresErasureStore) UpdateErasureTask(ctx context.Context, requestID uuid.UUID, task ErasureTask) error {
+	var action interface{}
+	if task.Action != "" {
+		action = string(task.Action)
+	}
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE privacy_erasure_tasks
+		SET status = $3, action = $4, records = $5, attempts = $6, error = NULLIF($7, ''), completed_at = $8
+		WHERE request_id = $1 AND owner = $2 AND status = 'pending'
+	`, requestID, task.Owner, string(task.Status), action, task.Records, task.Attempts, task.Error, task.CompletedAt)
+	return err
+}
+
+func (s *postgresErasureStore) UpdateErasureRequest(ctx context.Context, request *ErasureRequest) error {
+	var certificate interface{}
+	if request.Certificate != nil {
+		data, err := json.Marshal(request.Certificate)
+		if err != nil {
+			return fmt.Errorf("failed to encode erasure certificate: %w", err)
+		}
+		certificate = data
+	}
+	_, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE privacy_erasure_requests
+		SET status = $2, certificate = $3, updated_at = $4, completed_at = $5
+		WHERE id = $1 AND status NOT IN ('completed', 'failed')
+	`, request.ID, string(request.Status), certificate, request.UpdatedAt, request.CompletedAt)
+	return err
+}
+
+func scanErasureTask(rows *sql.Rows) (ErasureTask, error) {
+	var task ErasureTask
+	var status, action string
+	var completedAt sql.NullTime
+	if err := rows.Scan(&task.Owner, &status, &action, &task.Records, &task.Attempts, &task.Error, &completedAt); err != nil {
+		return task, err
+	}
+	task.Status = ErasureStatus(status)
+	task.Action = ErasureAction(action)
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	return task, nil
+}
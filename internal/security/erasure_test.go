@@ -0,0 +1,227 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryErasureStore is an ErasureStore backed by a map, standing in for Postgres
+type memoryErasureStore struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]*ErasureRequest
+}
+
+func (s *memoryErasureStore) CreateErasureRequest(ctx context.Context, request *ErasureRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *request
+	stored.Tasks = append([]ErasureTask(nil), request.Tasks...)
+	s.requests[request.ID] = &stored
+	return nil
+}
+
+func (s *memoryErasureStore) GetErasureRequest(ctx context.Context, id uuid.UUID) (*ErasureRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.requests[id]
+	if !exists {
+		return nil, ErrErasureRequestNotFound
+	}
+	request := *stored
+	request.Tasks = append([]ErasureTask(nil), stored.Tasks...)
+	return &request, nil
+}
+
+func (s *memoryErasureStore) PendingErasures(ctx context.Context, owner string, limit int) ([]PendingErasure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []PendingErasure
+	for _, request := range s.requests {
+		for _, task := range request.Tasks {
+			if task.Owner == owner && task.Status == ErasureStatusPending {
+				pending = append(pending, PendingErasure{RequestID: request.ID, UserID: request.UserID, Pseudonym: request.Pseudonym, Task: task})
+			}
+		}
+	}
+	return pending, nil
+}
+
+func (s *memoryErasureStore) UpdateErasureTask(ctx context.Context, requestID uuid.UUID, task ErasureTask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.requests[requestID].Tasks {
+		if existing.Owner == task.Owner && existing.Status == ErasureStatusPending {
+			s.requests[requestID].Tasks[i] = task
+		}
+	}
+	return nil
+}
+
+func (s *memoryErasureStore) UpdateErasureRequest(ctx context.Context, request *ErasureRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.requests[request.ID]
+	stored.Status = request.Status
+	stored.Certificate = request.Certificate
+	stored.UpdatedAt = request.UpdatedAt
+	stored.CompletedAt = request.CompletedAt
+	return nil
+}
+
+func newErasureTestManager(store ErasureStore, owners []string) *PrivacyManager {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	pm := NewPrivacyManager(logger, &PrivacyConfig{EnableRightToErasure: true}, nil)
+	pm.SetErasureStore(store, owners, []byte("erasure-signing-key"))
+	return pm
+}
+
+func TestErasureRequest(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryErasureStore{requests: make(map[uuid.UUID]*ErasureRequest)}
+	userID, adminID := uuid.New(), uuid.New()
+
+	// Two services share the store, each erasing its own data
+	conversations := map[uuid.UUID]int{userID: 3}
+	aiService := newErasureTestManager(store, []string{DataOwnerConversations, DataOwnerTradingHistory})
+	aiService.RegisterDataOwner(NewDeletingDataOwner(DataOwnerConversations, func(ctx context.Context, id uuid.UUID) (int, error) {
+		deleted := conversations[id]
+		delete(conversations, id)
+		return deleted, nil
+	}))
+
+	var pseudonymized uuid.UUID
+	failures := 1
+	tradingService := newErasureTestManager(store, []string{DataOwnerConversations, DataOwnerTradingHistory})
+	tradingService.RegisterDataOwner(NewPseudonymizingDataOwner(DataOwnerTradingHistory, func(ctx context.Context, id, pseudonym uuid.UUID) (int, error) {
+		if failures > 0 {
+			failures--
+			return 0, errors.New("database unavailable")
+		}
+		pseudonymized = pseudonym
+		return 7, nil
+	}))
+
+	request, err := aiService.RequestErasure(ctx, userID, adminID)
+	require.NoError(t, err)
+	require.Len(t, request.Tasks, 2)
+	assert.Equal(t, ErasureStatusPending, request.Status)
+
+	require.NoError(t, aiService.ProcessErasures(ctx))
+	status, err := aiService.GetErasureRequest(ctx, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ErasureStatusInProgress, status.Status)
+	assert.Empty(t, conversations)
+
+	// The first attempt fails and is retried
+	require.NoError(t, tradingService.ProcessErasures(ctx))
+	status, err = tradingService.GetErasureRequest(ctx, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ErasureStatusInProgress, status.Status)
+	assert.Equal(t, "database unavailable", status.Tasks[1].Error)
+
+	require.NoError(t, tradingService.ProcessErasures(ctx))
+	status, err = tradingService.GetErasureRequest(ctx, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ErasureStatusCompleted, status.Status)
+	assert.NotNil(t, status.CompletedAt)
+	assert.NotEqual(t, uuid.Nil, pseudonymized)
+	assert.NotEqual(t, userID, pseudonymized)
+
+	tasks := status.Tasks
+	assert.Equal(t, ErasureActionDelete, tasks[0].Action)
+	assert.Equal(t, 3, tasks[0].Records)
+	assert.Equal(t, ErasureActionPseudonymize, tasks[1].Action)
+	assert.Equal(t, 2, tasks[1].Attempts)
+
+	certificate := status.Certificate
+	require.NotNil(t, certificate)
+	assert.Equal(t, userID, certificate.UserID)
+	assert.True(t, VerifyErasureCertificate([]byte("erasure-signing-key"), certificate))
+	assert.False(t, VerifyErasureCertificate([]byte("other-key"), certificate))
+	forged := *certificate
+	forged.UserID = uuid.New()
+	assert.False(t, VerifyErasureCertificate([]byte("erasure-signing-key"), &forged))
+
+	_, err = aiService.GetErasureRequest(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrErasureRequestNotFound)
+}
+
+func TestErasureRequest_FailsAfterRetries(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryErasureStore{requests: make(map[uuid.UUID]*ErasureRequest)}
+	pm := newErasureTestManager(store, nil)
+	pm.RegisterDataOwner(NewDeletingDataOwner(DataOwnerConversations, func(ctx context.Context, id uuid.UUID) (int, error) {
+		return 0, errors.New("permission denied")
+	}))
+
+	request, err := pm.RequestErasure(ctx, uuid.New(), uuid.New())
+	require.NoError(t, err)
+	for i := 0; i < maxErasureAttempts; i++ {
+		require.NoError(t, pm.ProcessErasures(ctx))
+	}
+
+	status, err := pm.GetErasureRequest(ctx, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ErasureStatusFailed, status.Status)
+	assert.Nil(t, status.Certificate)
+
+	disabled := NewPrivacyManager(pm.logger, &PrivacyConfig{EnableRightToErasure: true}, nil)
+	_, err = disabled.RequestErasure(ctx, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, ErrErasureNotConfigured)
+}
+
+func TestAuditManager_PseudonymizeUser(t *testing.T) {
+	ctx := context.Background()
+	am := newArchivingAuditManager(t, t.TempDir())
+	userID, otherID, pseudonym := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	for i, id := range []uuid.UUID{userID, otherID, userID, userID, otherID} {
+		id := id
+		require.NoError(t, am.LogEvent(ctx, &AuditEvent{
+			Timestamp: now.Add(-48*time.Hour + time.Duration(i)*time.Hour),
+			EventType: AuditEventTypeTrading,
+			UserID:    &id,
+			Action:    "order",
+			Result:    AuditResultSuccess,
+		}))
+	}
+	// The three oldest events are past the hot retention
+	archived, err := am.ArchiveEvents(ctx, now.Add(-21*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, archived)
+
+	changed, err := am.PseudonymizeUser(ctx, userID, pseudonym)
+	require.NoError(t, err)
+	assert.Equal(t, 3, changed, "two archived and one hot event")
+
+	valid, err := am.VerifyIntegrity(ctx)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	var buf bytes.Buffer
+	n, err := am.ExportEvents(ctx, now.Add(-72*time.Hour), now, AuditExportFormatJSONL, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.NotContains(t, buf.String(), userID.String())
+	assert.Contains(t, buf.String(), otherID.String())
+
+	valid, err = VerifyAuditExport(&buf)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	manifest, err := am.GetArchiveManifest(ctx)
+	require.NoError(t, err)
+	require.Len(t, manifest.Segments, 1)
+	assert.Equal(t, 1, manifest.Segments[0].Revision)
+}
@@ -0,0 +1,137 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// GeoIPResolver locates IP addresses, like a MaxMind GeoIP2 City database or web service.
+// Lookup returns nil, without an error, for addresses it cannot locate.
+type GeoIPResolver interface {
+	Lookup(ctx context.Context, ipAddress string) (*Location, error)
+}
+
+// maxCachedLocations is the cache size from which expired locations are swept out
+const maxCachedLocations = 10000
+
+// DefaultMaxMindEndpoint is the MaxMind GeoIP2 City web service
+const DefaultMaxMindEndpoint = "https://geoip.maxmind.com/geoip/v2.1/city/"
+
+// MaxMindResolver locates IP addresses with the MaxMind GeoIP2 City web service, or any
+// service answering in its format. Locations are cached, since logins come back from the
+// same addresses and lookups are billed.
+type MaxMindResolver struct {
+	endpoint   string
+	accountID  string
+	licenseKey string
+	client     *http.Client
+	cacheTTL   time.Duration
+	cache      map[string]cachedLocation
+	mu         sync.Mutex
+}
+
+type cachedLocation struct {
+	location  *Location
+	expiresAt time.Time
+}
+
+// NewMaxMindResolver creates a resolver for the web service at endpoint, authenticated with
+// a MaxMind account ID and license key. An empty endpoint uses DefaultMaxMindEndpoint.
+func NewMaxMindResolver(endpoint, accountID, licenseKey string) *MaxMindResolver {
+	if endpoint == "" {
+		endpoint = DefaultMaxMindEndpoint
+	}
+	return &MaxMindResolver{
+		endpoint:   endpoint,
+		accountID:  accountID,
+		licenseKey: licenseKey,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		cacheTTL:   24 * time.Hour,
+		cache:      make(map[string]cachedLocation),
+	}
+}
+
+// maxMindCityResponse is the part of a GeoIP2 City response the resolver reads
+type maxMindCityResponse struct {
+	Country struct {
+		ISOCode string `json:"iso_code"`
+	} `json:"country"`
+	Subdivisions []struct {
+		Names map[string]string `json:"names"`
+	} `json:"subdivisions"`
+	City struct {
+		Names map[string]string `json:"names"`
+	} `json:"city"`
+	Location struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"location"`
+}
+
+// Lookup locates a public IP address. Private and loopback addresses have no location.
+func (m *MaxMindResolver) Lookup(ctx context.Context, ipAddress string) (*Location, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	cached, ok := m.cache[ip.String()]
+	m.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.location, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.endpoint+url.PathEscape(ip.String()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create geo-IP request: %w", err)
+	}
+	req.SetBasicAuth(m.accountID, m.licenseKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("geo-IP lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var location *Location
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var body maxMindCityResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return nil, fmt.Errorf("failed to decode geo-IP response: %w", err)
+		}
+		location = &Location{
+			Country:   body.Country.ISOCode,
+			City:      body.City.Names["en"],
+			Latitude:  body.Location.Latitude,
+			Longitude: body.Location.Longitude,
+		}
+		if len(body.Subdivisions) > 0 {
+			location.Region = body.Subdivisions[0].Names["en"]
+		}
+	case http.StatusNotFound:
+		// Addresses missing from the database are cached as unlocated too
+	default:
+		return nil, fmt.Errorf("geo-IP lookup failed with status %d", resp.StatusCode)
+	}
+
+	m.mu.Lock()
+	if len(m.cache) >= maxCachedLocations {
+		for key, entry := range m.cache {
+			if time.Now().After(entry.expiresAt) {
+				delete(m.cache, key)
+			}
+		}
+	}
+	m.cache[ip.String()] = cachedLocation{location: location, expiresAt: time.Now().Add(m.cacheTTL)}
+	m.mu.Unlock()
+	return location, nil
+}
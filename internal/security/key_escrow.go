@@ -0,0 +1,425 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrKeyEscrowDisabled is returned by escrow operations when EnableKeyEscrow is off
+	ErrKeyEscrowDisabled = errors.New("key escrow is disabled")
+	// ErrEscrowKeyNotConfigured is returned by exports before an escrow public key is set
+	ErrEscrowKeyNotConfigured = errors.New("no escrow public key configured")
+	// ErrInvalidEscrowKey is returned for escrow keys that are not RSA keys in PEM, or a
+	// private key that does not match the bundle's escrow key
+	ErrInvalidEscrowKey = errors.New("invalid escrow key")
+	// ErrEscrowIntegrity is returned for bundles that were modified or are incomplete
+	ErrEscrowIntegrity = errors.New("key escrow bundle failed integrity verification")
+	// ErrEscrowKeyConflict is returned when a bundle holds a key whose ID is already used by a
+	// different key
+	ErrEscrowKeyConflict = errors.New("escrowed key conflicts with an existing key")
+)
+
+// keyEscrowFormat is the version of the escrow bundle format
+const keyEscrowFormat = 1
+
+// KeyEscrowBundle holds encryption keys wrapped for an operator's escrow key. A random bundle
+// key encrypts the keys with AES-256-GCM, authenticating the manifest with them, and is itself
+// encrypted to the escrow public key with RSA-OAEP. Only the holder of the escrow private key
+// can recover the keys.
+type KeyEscrowBundle struct {
+	Manifest   KeyEscrowManifest `json:"manifest"`
+	WrappedKey []byte            `json:"wrapped_key"`
+	Ciphertext []byte            `json:"ciphertext"`
+}
+
+// KeyEscrowManifest describes the keys in an escrow bundle without revealing them
+type KeyEscrowManifest struct {
+	BundleID             string            `json:"bundle_id"`
+	Format               int               `json:"format"`
+	CreatedAt            time.Time         `json:"created_at"`
+	ExportedBy           string            `json:"exported_by"`
+	EscrowKeyFingerprint string            `json:"escrow_key_fingerprint"`
+	PayloadDigest        string            `json:"payload_digest"` // SHA-256 of the decrypted keys
+	Keys                 []EscrowedKeyInfo `json:"keys"`
+}
+
+// EscrowedKeyInfo is the metadata of an escrowed key
+type EscrowedKeyInfo struct {
+	ID          string    `json:"id"`
+	Algorithm   string    `json:"algorithm"`
+	Purpose     string    `json:"purpose"`
+	Status      string    `json:"status"`
+	Version     int       `json:"version"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// escrowedKey is a key in the encrypted payload of a bundle
+type escrowedKey struct {
+	ID      string `json:"id"`
+	KeyData []byte `json:"key_data"`
+}
+
+// SetEscrowPublicKey sets the operator's RSA public key, in PEM, that escrow exports are
+// encrypted to
+func (em *EncryptionManager) SetEscrowPublicKey(pemData []byte) error {
+	publicKey, err := parseEscrowPublicKey(pemData)
+	if err != nil {
+		return err
+	}
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.escrowKey = publicKey
+	return nil
+}
+
+// ExportKeyEscrow exports every key that is not revoked, so data encrypted with rotated keys
+// stays decryptable, in a bundle encrypted to the escrow public key. The export is audited
+// with the operator's identity and fails if it cannot be audited.
+func (em *EncryptionManager) ExportKeyEscrow(ctx context.Context, operator string) (*KeyEscrowBundle, error) {
+	if !em.config.EnableKeyEscrow {
+		return nil, ErrKeyEscrowDisabled
+	}
+
+	em.mu.RLock()
+	escrowKey := em.escrowKey
+	em.mu.RUnlock()
+	if escrowKey == nil {
+		return nil, ErrEscrowKeyNotConfigured
+	}
+
+	manifest := KeyEscrowManifest{
+		BundleID:             uuid.New().String(),
+		Format:               keyEscrowFormat,
+		CreatedAt:            time.Now().UTC(),
+		ExportedBy:           operator,
+		EscrowKeyFingerprint: escrowKeyFingerprint(escrowKey),
+		Keys:                 []EscrowedKeyInfo{},
+	}
+	keys := []escrowedKey{}
+
+	em.keyManager.mu.RLock()
+	for _, key := range em.keyManager.keyStore {
+		if key.Status == "revoked" {
+			continue
+		}
+		manifest.Keys = append(manifest.Keys, EscrowedKeyInfo{
+			ID:          key.ID,
+			Algorithm:   key.Algorithm,
+			Purpose:     key.Purpose,
+			Status:      key.Status,
+			Version:     key.Version,
+			Fingerprint: key.Fingerprint,
+			CreatedAt:   key.CreatedAt.UTC(),
+			ExpiresAt:   key.ExpiresAt.UTC(),
+		})
+		keys = append(keys, escrowedKey{ID: key.ID, KeyData: append([]byte(nil), key.KeyData...)})
+	}
+	em.keyManager.mu.RUnlock()
+
+	sort.Slice(manifest.Keys, func(i, j int) bool { return manifest.Keys[i].ID < manifest.Keys[j].ID })
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode escrowed keys: %w", err)
+	}
+	digest := sha256.Sum256(payload)
+	manifest.PayloadDigest = hex.EncodeToString(digest[:])
+
+	bundleKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, bundleKey); err != nil {
+		return nil, fmt.Errorf("failed to generate bundle key: %w", err)
+	}
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, escrowKey, bundleKey, []byte(manifest.BundleID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap bundle key: %w", err)
+	}
+	ciphertext, err := sealEscrowPayload(bundleKey, payload, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := em.audit(ctx, operator, &AuditEvent{
+		Severity: AuditSeverityCritical,
+		Resource: "encryption_keys",
+		Action:   "key_escrow_export",
+		Result:   AuditResultSuccess,
+		Details: map[string]interface{}{
+			"bundle_id":              manifest.BundleID,
+			"key_count":              len(manifest.Keys),
+			"escrow_key_fingerprint": manifest.EscrowKeyFingerprint,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	em.logger.Info(ctx, "Exported key escrow bundle", map[string]interface{}{
+		"bundle_id": manifest.BundleID,
+		"key_count": len(manifest.Keys),
+		"operator":  operator,
+	})
+
+	return &KeyEscrowBundle{Manifest: manifest, WrappedKey: wrappedKey, Ciphertext: ciphertext}, nil
+}
+
+// ImportKeyEscrow recovers the keys of a bundle with the escrow private key, in PEM, after
+// verifying the bundle's integrity, so data encrypted before a total loss of the key store can
+// be decrypted. Keys already present are skipped. The import, and any failed verification, is
+// audited with the operator's identity.
+func (em *EncryptionManager) ImportKeyEscrow(ctx context.Context, bundle *KeyEscrowBundle, privateKeyPEM []byte, operator string) (*KeyEscrowManifest, error) {
+	if !em.config.EnableKeyEscrow {
+		return nil, ErrKeyEscrowDisabled
+	}
+	if bundle == nil {
+		return nil, fmt.Errorf("%w: no bundle", ErrEscrowIntegrity)
+	}
+
+	keys, err := em.openEscrowBundle(bundle, privateKeyPEM)
+	var imported int
+	if err == nil {
+		imported, err = em.storeEscrowedKeys(keys, bundle.Manifest)
+	}
+	if err != nil {
+		if auditErr := em.audit(ctx, operator, &AuditEvent{
+			Severity: AuditSeverityCritical,
+			Resource: "encryption_keys",
+			Action:   "key_escrow_import",
+			Result:   AuditResultFailure,
+			Details: map[string]interface{}{
+				"bundle_id": bundle.Manifest.BundleID,
+				"error":     err.Error(),
+			},
+		}); auditErr != nil {
+			em.logger.Error(ctx, "Failed to audit key escrow import", auditErr, nil)
+		}
+		return nil, err
+	}
+
+	if err := em.audit(ctx, operator, &AuditEvent{
+		Severity: AuditSeverityCritical,
+		Resource: "encryption_keys",
+		Action:   "key_escrow_import",
+		Result:   AuditResultSuccess,
+		Details: map[string]interface{}{
+			"bundle_id":   bundle.Manifest.BundleID,
+			"exported_by": bundle.Manifest.ExportedBy,
+			"key_count":   len(keys),
+			"imported":    imported,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	em.logger.Info(ctx, "Imported key escrow bundle", map[string]interface{}{
+		"bundle_id": bundle.Manifest.BundleID,
+		"key_count": len(keys),
+		"imported":  imported,
+		"operator":  operator,
+	})
+
+	manifest := bundle.Manifest
+	return &manifest, nil
+}
+
+// storeEscrowedKeys adds the keys of a verified bundle to the key store, returning how many
+// were missing. Nothing is stored if any key ID is already used by a different key.
+func (em *EncryptionManager) storeEscrowedKeys(keys []escrowedKey, manifest KeyEscrowManifest) (int, error) {
+	info := make(map[string]EscrowedKeyInfo, len(manifest.Keys))
+	for _, key := range manifest.Keys {
+		info[key.ID] = key
+	}
+
+	em.keyManager.mu.Lock()
+	defer em.keyManager.mu.Unlock()
+
+	for _, key := range keys {
+		if existing, exists := em.keyManager.keyStore[key.ID]; exists && !bytes.Equal(existing.KeyData, key.KeyData) {
+			return 0, fmt.Errorf("%w: %s", ErrEscrowKeyConflict, key.ID)
+		}
+	}
+
+	imported := 0
+	for _, key := range keys {
+		if _, exists := em.keyManager.keyStore[key.ID]; exists {
+			continue
+		}
+		meta := info[key.ID]
+		em.keyManager.keyStore[key.ID] = &EncryptionKey{
+			ID:          key.ID,
+			Algorithm:   meta.Algorithm,
+			KeyData:     key.KeyData,
+			CreatedAt:   meta.CreatedAt,
+			ExpiresAt:   meta.ExpiresAt,
+			Purpose:     meta.Purpose,
+			Status:      meta.Status,
+			Version:     meta.Version,
+			Fingerprint: meta.Fingerprint,
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// openEscrowBundle unwraps a bundle's keys and verifies them against its manifest
+func (em *EncryptionManager) openEscrowBundle(bundle *KeyEscrowBundle, privateKeyPEM []byte) ([]escrowedKey, error) {
+	if bundle.Manifest.Format != keyEscrowFormat {
+		return nil, fmt.Errorf("%w: unsupported bundle format %d", ErrEscrowIntegrity, bundle.Manifest.Format)
+	}
+	privateKey, err := parseEscrowPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if escrowKeyFingerprint(&privateKey.PublicKey) != bundle.Manifest.EscrowKeyFingerprint {
+		return nil, fmt.Errorf("%w: bundle was exported for escrow key %s", ErrInvalidEscrowKey, bundle.Manifest.EscrowKeyFingerprint)
+	}
+
+	bundleKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, bundle.WrappedKey, []byte(bundle.Manifest.BundleID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: bundle key cannot be unwrapped", ErrEscrowIntegrity)
+	}
+	payload, err := openEscrowPayload(bundleKey, bundle.Ciphertext, bundle.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(payload)
+	if hex.EncodeToString(digest[:]) != bundle.Manifest.PayloadDigest {
+		return nil, fmt.Errorf("%w: payload digest mismatch", ErrEscrowIntegrity)
+	}
+
+	var keys []escrowedKey
+	if err := json.Unmarshal(payload, &keys); err != nil {
+		return nil, fmt.Errorf("%w: payload cannot be decoded", ErrEscrowIntegrity)
+	}
+	if len(keys) != len(bundle.Manifest.Keys) {
+		return nil, fmt.Errorf("%w: manifest lists %d keys, bundle holds %d", ErrEscrowIntegrity, len(bundle.Manifest.Keys), len(keys))
+	}
+	fingerprints := make(map[string]string, len(bundle.Manifest.Keys))
+	for _, key := range bundle.Manifest.Keys {
+		fingerprints[key.ID] = key.Fingerprint
+	}
+	for _, key := range keys {
+		expected, listed := fingerprints[key.ID]
+		if !listed || em.keyManager.generateFingerprint(key.KeyData) != expected {
+			return nil, fmt.Errorf("%w: key %s does not match the manifest", ErrEscrowIntegrity, key.ID)
+		}
+	}
+	return keys, nil
+}
+
+// sealEscrowPayload encrypts the payload with the bundle key, authenticating the manifest
+func sealEscrowPayload(bundleKey, payload []byte, manifest KeyEscrowManifest) ([]byte, error) {
+	gcm, err := escrowCipher(bundleKey)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode escrow manifest: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, payload, aad), nil
+}
+
+// openEscrowPayload decrypts the payload, failing if it or the manifest was modified
+func openEscrowPayload(bundleKey, ciphertext []byte, manifest KeyEscrowManifest) ([]byte, error) {
+	gcm, err := escrowCipher(bundleKey)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode escrow manifest: %w", err)
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: ciphertext too short", ErrEscrowIntegrity)
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	payload, err := gcm.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bundle or manifest was modified", ErrEscrowIntegrity)
+	}
+	return payload, nil
+}
+
+func escrowCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+func parseEscrowPublicKey(pemData []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrInvalidEscrowKey)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEscrowKey, err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA public key", ErrInvalidEscrowKey)
+	}
+	return rsaKey, nil
+}
+
+func parseEscrowPrivateKey(pemData []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrInvalidEscrowKey)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEscrowKey, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA private key", ErrInvalidEscrowKey)
+	}
+	return rsaKey, nil
+}
+
+// escrowKeyFingerprint identifies an escrow key by the SHA-256 of its PKIX encoding
+func escrowKeyFingerprint(key *rsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(der)
+	return hex.EncodeToString(hash[:])
+}
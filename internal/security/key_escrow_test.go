@@ -0,0 +1,216 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEscrowKeyPair returns an escrow public and private key in PEM
+func newEscrowKeyPair(t *testing.T) ([]byte, []byte) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return publicPEM, privatePEM
+}
+
+func newEscrowManager(t *testing.T, auditor *AuditManager) *EncryptionManager {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	em := NewEncryptionManager(logger, &EncryptionConfig{Algorithm: "AES-256-GCM", EnableKeyEscrow: true})
+	if auditor != nil {
+		em.SetAuditor(auditor)
+	}
+	return em
+}
+
+func newEscrowAuditor() *AuditManager {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	return NewAuditManager(logger, &AuditConfig{
+		EnableAuditLogging:   true,
+		EnableIntegrityCheck: true,
+		RetentionPeriod:      24 * time.Hour,
+	}, nil)
+}
+
+func TestKeyEscrow_RecoversKeysIntoFreshManager(t *testing.T) {
+	ctx := context.Background()
+	publicPEM, privatePEM := newEscrowKeyPair(t)
+
+	source := newEscrowManager(t, nil)
+	require.NoError(t, source.SetEscrowPublicKey(publicPEM))
+	oldKeyID, err := source.ImportKey("exchange_credentials", bytes.Repeat([]byte{0x01}, 32))
+	require.NoError(t, err)
+	sealed, err := source.EncryptData([]byte("old secret"), "exchange_credentials")
+	require.NoError(t, err)
+
+	// The rotated key version stays in the bundle so older data remains readable; revoked
+	// keys are left out
+	source.keyManager.keyStore[oldKeyID].Status = "expired"
+	_, err = source.ImportKey("exchange_credentials", bytes.Repeat([]byte{0x02}, 32))
+	require.NoError(t, err)
+	revokedID, err := source.ImportKey("sessions", bytes.Repeat([]byte{0x03}, 32))
+	require.NoError(t, err)
+	source.keyManager.keyStore[revokedID].Status = "revoked"
+
+	bundle, err := source.ExportKeyEscrow(ctx, "operator-1")
+	require.NoError(t, err)
+	require.Len(t, bundle.Manifest.Keys, 2)
+	assert.Equal(t, "operator-1", bundle.Manifest.ExportedBy)
+	assert.NotContains(t, string(bundle.Ciphertext), string(bytes.Repeat([]byte{0x01}, 32)))
+
+	// The bundle survives being stored as JSON
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	var stored KeyEscrowBundle
+	require.NoError(t, json.Unmarshal(data, &stored))
+
+	recovered := newEscrowManager(t, nil)
+	manifest, err := recovered.ImportKeyEscrow(ctx, &stored, privatePEM, "operator-2")
+	require.NoError(t, err)
+	assert.Equal(t, bundle.Manifest.BundleID, manifest.BundleID)
+
+	plaintext, err := recovered.DecryptData(&DecryptionRequest{
+		EncryptedData: sealed.EncryptedData,
+		KeyID:         sealed.KeyID,
+		Algorithm:     sealed.Algorithm,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "old secret", string(plaintext))
+
+	_, err = recovered.keyManager.GetKey(revokedID)
+	assert.Error(t, err)
+
+	// Importing the same bundle again is a no-op
+	_, err = recovered.ImportKeyEscrow(ctx, &stored, privatePEM, "operator-2")
+	assert.NoError(t, err)
+}
+
+func TestKeyEscrow_RejectsModifiedBundles(t *testing.T) {
+	ctx := context.Background()
+	publicPEM, privatePEM := newEscrowKeyPair(t)
+
+	source := newEscrowManager(t, nil)
+	require.NoError(t, source.SetEscrowPublicKey(publicPEM))
+	_, err := source.ImportKey("exchange_credentials", bytes.Repeat([]byte{0x01}, 32))
+	require.NoError(t, err)
+	bundle, err := source.ExportKeyEscrow(ctx, "operator-1")
+	require.NoError(t, err)
+
+	tampered := []func(b *KeyEscrowBundle){
+		func(b *KeyEscrowBundle) { b.Manifest.ExportedBy = "someone-else" },
+		func(b *KeyEscrowBundle) { b.Manifest.Keys[0].Purpose = "sessions" },
+		func(b *KeyEscrowBundle) { b.Manifest.Keys = nil },
+		func(b *KeyEscrowBundle) { b.Ciphertext[len(b.Ciphertext)-1] ^= 0xff },
+		func(b *KeyEscrowBundle) { b.WrappedKey[0] ^= 0xff },
+	}
+	for _, tamper := range tampered {
+		copied := *bundle
+		copied.Manifest.Keys = append([]EscrowedKeyInfo(nil), bundle.Manifest.Keys...)
+		copied.Ciphertext = append([]byte(nil), bundle.Ciphertext...)
+		copied.WrappedKey = append([]byte(nil), bundle.WrappedKey...)
+		tamper(&copied)
+
+		recovered := newEscrowManager(t, nil)
+		_, err := recovered.ImportKeyEscrow(ctx, &copied, privatePEM, "operator-2")
+		assert.ErrorIs(t, err, ErrEscrowIntegrity)
+		assert.Empty(t, recovered.keyManager.keyStore)
+	}
+
+	_, otherPrivatePEM := newEscrowKeyPair(t)
+	_, err = newEscrowManager(t, nil).ImportKeyEscrow(ctx, bundle, otherPrivatePEM, "operator-2")
+	assert.ErrorIs(t, err, ErrInvalidEscrowKey)
+}
+
+func TestKeyEscrow_RejectsConflictingKeys(t *testing.T) {
+	ctx := context.Background()
+	publicPEM, privatePEM := newEscrowKeyPair(t)
+
+	source := newEscrowManager(t, nil)
+	require.NoError(t, source.SetEscrowPublicKey(publicPEM))
+	keyID, err := source.ImportKey("exchange_credentials", bytes.Repeat([]byte{0x01}, 32))
+	require.NoError(t, err)
+	bundle, err := source.ExportKeyEscrow(ctx, "operator-1")
+	require.NoError(t, err)
+
+	target := newEscrowManager(t, nil)
+	target.keyManager.keyStore[keyID] = &EncryptionKey{ID: keyID, KeyData: bytes.Repeat([]byte{0x09}, 32)}
+	_, err = target.ImportKeyEscrow(ctx, bundle, privatePEM, "operator-2")
+	assert.ErrorIs(t, err, ErrEscrowKeyConflict)
+}
+
+func TestKeyEscrow_RequiresConfiguration(t *testing.T) {
+	ctx := context.Background()
+	logger := &observability.Logger{}
+
+	disabled := NewEncryptionManager(logger, &EncryptionConfig{Algorithm: "AES-256-GCM"})
+	_, err := disabled.ExportKeyEscrow(ctx, "operator-1")
+	assert.ErrorIs(t, err, ErrKeyEscrowDisabled)
+	_, err = disabled.ImportKeyEscrow(ctx, &KeyEscrowBundle{}, nil, "operator-1")
+	assert.ErrorIs(t, err, ErrKeyEscrowDisabled)
+
+	_, err = newEscrowManager(t, nil).ExportKeyEscrow(ctx, "operator-1")
+	assert.ErrorIs(t, err, ErrEscrowKeyNotConfigured)
+
+	_, privatePEM := newEscrowKeyPair(t)
+	assert.ErrorIs(t, newEscrowManager(t, nil).SetEscrowPublicKey(privatePEM), ErrInvalidEscrowKey)
+	assert.ErrorIs(t, newEscrowManager(t, nil).SetEscrowPublicKey([]byte("not a key")), ErrInvalidEscrowKey)
+}
+
+func TestKeyEscrow_AuditsOperators(t *testing.T) {
+	ctx := context.Background()
+	publicPEM, privatePEM := newEscrowKeyPair(t)
+	auditor := newEscrowAuditor()
+	exporter := uuid.New()
+
+	source := newEscrowManager(t, auditor)
+	require.NoError(t, source.SetEscrowPublicKey(publicPEM))
+	_, err := source.ImportKey("exchange_credentials", bytes.Repeat([]byte{0x01}, 32))
+	require.NoError(t, err)
+	bundle, err := source.ExportKeyEscrow(ctx, exporter.String())
+	require.NoError(t, err)
+
+	recovered := newEscrowManager(t, auditor)
+	_, err = recovered.ImportKeyEscrow(ctx, bundle, privatePEM, "recovery-operator")
+	require.NoError(t, err)
+	bundle.Ciphertext[0] ^= 0xff
+	_, err = recovered.ImportKeyEscrow(ctx, bundle, privatePEM, "recovery-operator")
+	require.Error(t, err)
+
+	events, err := auditor.GetAuditEvents(ctx, AuditEventFilter{Resource: "encryption_keys"})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	byAction := map[string][]AuditEvent{}
+	for _, event := range events {
+		byAction[event.Action] = append(byAction[event.Action], event)
+	}
+	require.Len(t, byAction["key_escrow_export"], 1)
+	export := byAction["key_escrow_export"][0]
+	assert.Equal(t, exporter.String(), export.Details["operator"])
+	require.NotNil(t, export.UserID)
+	assert.Equal(t, exporter, *export.UserID)
+	assert.Equal(t, bundle.Manifest.BundleID, export.Details["bundle_id"])
+
+	results := []AuditResult{}
+	for _, event := range byAction["key_escrow_import"] {
+		assert.Equal(t, "recovery-operator", event.Details["operator"])
+		results = append(results, event.Result)
+	}
+	assert.ElementsMatch(t, []AuditResult{AuditResultSuccess, AuditResultFailure}, results)
+}
@@ -0,0 +1,444 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/auth"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginProtectionConfig sets when failed logins are delayed, challenged and locked out.
+// Failures are counted per account and per IP address over FailureWindow.
+type LoginProtectionConfig struct {
+	AccountMaxFailures int           // failures locking an account
+	IPMaxFailures      int           // failures, across accounts, locking an IP address
+	FailureWindow      time.Duration // how long failures count
+	LockoutDuration    time.Duration
+	DelayAfter         int // failures before logins are delayed
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	ChallengeAfter     int // account failures before a CAPTCHA is required
+	IPChallengeAfter   int // IP failures before a CAPTCHA is required
+}
+
+// DefaultLoginProtectionConfig returns the default login protection configuration
+func DefaultLoginProtectionConfig() LoginProtectionConfig {
+	return LoginProtectionConfig{
+		AccountMaxFailures: 5,
+		IPMaxFailures:      20,
+		FailureWindow:      15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+		DelayAfter:         2,
+		BaseDelay:          500 * time.Millisecond,
+		MaxDelay:           5 * time.Second,
+		ChallengeAfter:     3,
+		IPChallengeAfter:   10,
+	}
+}
+
+// LoginAttemptStore keeps failed login timestamps and lockouts, shared by every auth replica
+type LoginAttemptStore interface {
+	// AddFailure records a failure for key, forgets failures older than window and returns
+	// how many remain
+	AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error)
+	CountFailures(ctx context.Context, key string, since time.Time) (int, error)
+	ClearFailures(ctx context.Context, key string) error
+	SetLock(ctx context.Context, key string, until time.Time) error
+	// GetLock returns when key's lockout ends, or the zero time when it isn't locked
+	GetLock(ctx context.Context, key string) (time.Time, error)
+	ClearLock(ctx context.Context, key string) error
+}
+
+const (
+	loginFailuresKeyPrefix = "auth:login:failures:"
+	loginLockKeyPrefix     = "auth:login:lock:"
+)
+
+// redisLoginAttemptStore keeps failures in a sorted set per key, scored by time, and lockouts
+// in keys expiring with them
+type redisLoginAttemptStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisLoginAttemptStore creates a store sharing login failures and lockouts through Redis
+func NewRedisLoginAttemptStore(redis *database.RedisClient) LoginAttemptStore {
+	return &redisLoginAttemptStore{redis: redis}
+}
+
+func (s *redisLoginAttemptStore) AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	redisKey := loginFailuresKeyPrefix + key
+	pipe := s.redis.Client.TxPipeline()
+	pipe.ZAdd(ctx, redisKey, redis.Z{Score: float64(at.UnixNano()), Member: strconv.FormatInt(at.UnixNano(), 10)})
+	pipe.ZRemRangeByScore(ctx, redisKey, "-inf", "("+strconv.FormatInt(at.Add(-window).UnixNano(), 10))
+	count := pipe.ZCard(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(count.Val()), nil
+}
+
+func (s *redisLoginAttemptStore) CountFailures(ctx context.Context, key string, since time.Time) (int, error) {
+	count, err := s.redis.Client.ZCount(ctx, loginFailuresKeyPrefix+key, strconv.FormatInt(since.UnixNano(), 10), "+inf").Result()
+	return int(count), err
+}
+
+func (s *redisLoginAttemptStore) ClearFailures(ctx context.Context, key string) error {
+	return s.redis.Client.Del(ctx, loginFailuresKeyPrefix+key).Err()
+}
+
+func (s *redisLoginAttemptStore) SetLock(ctx context.Context, key string, until time.Time) error {
+	return s.redis.Client.Set(ctx, loginLockKeyPrefix+key, until.Unix(), time.Until(until)).Err()
+}
+
+func (s *redisLoginAttemptStore) GetLock(ctx context.Context, key string) (time.Time, error) {
+	until, err := s.redis.Client.Get(ctx, loginLockKeyPrefix+key).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(until, 0), nil
+}
+
+func (s *redisLoginAttemptStore) ClearLock(ctx context.Context, key string) error {
+	return s.redis.Client.Del(ctx, loginLockKeyPrefix+key).Err()
+}
+
+// AccountLockout describes an account locked after repeated failed logins
+type AccountLockout struct {
+	Email       string
+	UserID      uuid.UUID
+	IPAddress   string
+	Failures    int
+	LockedUntil time.Time
+}
+
+// LockoutNotifier tells account owners that their account was locked
+type LockoutNotifier interface {
+	NotifyAccountLocked(ctx context.Context, lockout AccountLockout) error
+}
+
+// LoginProtector implements auth.LoginGuard: failed logins are counted per account and per IP
+// address, delayed progressively, challenged and eventually locked out for a while
+type LoginProtector struct {
+	logger   *observability.Logger
+	config   LoginProtectionConfig
+	store    LoginAttemptStore
+	auditor  *AuditManager
+	notifier LockoutNotifier
+	now      func() time.Time
+	mu       sync.RWMutex
+}
+
+// NewLoginProtector creates a login protector counting failures in store
+func NewLoginProtector(logger *observability.Logger, config LoginProtectionConfig, store LoginAttemptStore) *LoginProtector {
+	return &LoginProtector{
+		logger: logger,
+		config: config,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// SetAuditor records failed logins, lockouts and cleared lockouts in the audit log
+func (p *LoginProtector) SetAuditor(auditor *AuditManager) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.auditor = auditor
+}
+
+// SetNotifier notifies account owners when their account is locked
+func (p *LoginProtector) SetNotifier(notifier LockoutNotifier) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notifier = notifier
+}
+
+// CheckLogin decides whether a login attempt may proceed
+func (p *LoginProtector) CheckLogin(ctx context.Context, attempt auth.LoginAttempt) (auth.LoginDecision, error) {
+	now := p.now()
+	account, ip := accountLoginKey(attempt.Email), ipLoginKey(attempt.IPAddress)
+
+	for _, key := range []string{account, ip} {
+		if key == "" {
+			continue
+		}
+		until, err := p.store.GetLock(ctx, key)
+		if err != nil {
+			return auth.LoginDecision{}, fmt.Errorf("failed to read lockout: %w", err)
+		}
+		if until.After(now) {
+			return auth.LoginDecision{
+				Action:     auth.LoginActionLocked,
+				RetryAfter: until.Sub(now),
+				Reason:     strings.SplitN(key, ":", 2)[0] + " locked",
+			}, nil
+		}
+	}
+
+	accountFailures, ipFailures, err := p.failures(ctx, account, ip, now)
+	if err != nil {
+		return auth.LoginDecision{}, err
+	}
+	return p.decide(accountFailures, ipFailures), nil
+}
+
+// RecordLoginFailure counts a failed login, locking the account or IP address once it has
+// failed too often, and returns the decision for the next attempt
+func (p *LoginProtector) RecordLoginFailure(ctx context.Context, attempt auth.LoginAttempt, reason string) (auth.LoginDecision, error) {
+	now := p.now()
+	account, ip := accountLoginKey(attempt.Email), ipLoginKey(attempt.IPAddress)
+
+	var accountFailures, ipFailures int
+	var err error
+	if account != "" {
+		if accountFailures, err = p.store.AddFailure(ctx, account, now, p.config.FailureWindow); err != nil {
+			return auth.LoginDecision{}, fmt.Errorf("failed to record login failure: %w", err)
+		}
+	}
+	if ip != "" {
+		if ipFailures, err = p.store.AddFailure(ctx, ip, now, p.config.FailureWindow); err != nil {
+			return auth.LoginDecision{}, fmt.Errorf("failed to record login failure: %w", err)
+		}
+	}
+	if attempt.UserID != nil {
+		if _, err := p.store.AddFailure(ctx, userLoginKey(*attempt.UserID), now, p.config.FailureWindow); err != nil {
+			return auth.LoginDecision{}, fmt.Errorf("failed to record login failure: %w", err)
+		}
+	}
+
+	p.audit(ctx, attempt, "login_failure", AuditResultFailure, map[string]interface{}{
+		"reason":           reason,
+		"account_failures": accountFailures,
+		"ip_failures":      ipFailures,
+	})
+
+	until := now.Add(p.config.LockoutDuration)
+	locked := false
+	if account != "" && p.config.AccountMaxFailures > 0 && accountFailures >= p.config.AccountMaxFailures {
+		if err := p.lock(ctx, account, until); err != nil {
+			return auth.LoginDecision{}, err
+		}
+		locked = true
+		p.audit(ctx, attempt, "account_locked", AuditResultDenied, map[string]interface{}{
+			"failures":     accountFailures,
+			"locked_until": until,
+		})
+		p.notifyLocked(ctx, attempt, accountFailures, until)
+	}
+	if ip != "" && p.config.IPMaxFailures > 0 && ipFailures >= p.config.IPMaxFailures {
+		if err := p.lock(ctx, ip, until); err != nil {
+			return auth.LoginDecision{}, err
+		}
+		locked = true
+		p.audit(ctx, attempt, "ip_locked", AuditResultDenied, map[string]interface{}{
+			"failures":     ipFailures,
+			"locked_until": until,
+		})
+	}
+	if locked {
+		p.logger.Warn(ctx, "Login locked out after repeated failures", map[string]interface{}{
+			"email":            attempt.Email,
+			"ip_address":       attempt.IPAddress,
+			"account_failures": accountFailures,
+			"ip_failures":      ipFailures,
+			"locked_until":     until,
+		})
+		return auth.LoginDecision{Action: auth.LoginActionLocked, RetryAfter: p.config.LockoutDuration, Reason: "too many failures"}, nil
+	}
+
+	return p.decide(accountFailures, ipFailures), nil
+}
+
+// RecordLoginSuccess resets the account's failures. The IP address keeps its failures, so an
+// attacker can't reset them by logging into an account of their own.
+func (p *LoginProtector) RecordLoginSuccess(ctx context.Context, attempt auth.LoginAttempt) error {
+	if account := accountLoginKey(attempt.Email); account != "" {
+		if err := p.store.ClearFailures(ctx, account); err != nil {
+			return fmt.Errorf("failed to reset login failures: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClearLockout lifts the lockout and forgets the failures of an account, an IP address or
+// both, on behalf of an administrator
+func (p *LoginProtector) ClearLockout(ctx context.Context, email, ipAddress, operator string) error {
+	keys := []string{accountLoginKey(email), ipLoginKey(ipAddress)}
+	if keys[0] == "" && keys[1] == "" {
+		return fmt.Errorf("an email or IP address is required")
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := p.store.ClearLock(ctx, key); err != nil {
+			return fmt.Errorf("failed to clear lockout: %w", err)
+		}
+		if err := p.store.ClearFailures(ctx, key); err != nil {
+			return fmt.Errorf("failed to reset login failures: %w", err)
+		}
+	}
+
+	p.audit(ctx, auth.LoginAttempt{Email: email, IPAddress: ipAddress}, "lockout_cleared", AuditResultSuccess, map[string]interface{}{
+		"operator": operator,
+	})
+	p.logger.Info(ctx, "Login lockout cleared", map[string]interface{}{
+		"email":      email,
+		"ip_address": ipAddress,
+		"operator":   operator,
+	})
+	return nil
+}
+
+// FailedLoginRisk rates recent failed logins for a user and an IP address against the lockout
+// thresholds: reaching either threshold within the failure window is the highest risk
+func (p *LoginProtector) FailedLoginRisk(ctx context.Context, userID *uuid.UUID, ipAddress string) (float64, error) {
+	since := p.now().Add(-p.config.FailureWindow)
+	risk := 0.0
+
+	if userID != nil && p.config.AccountMaxFailures > 0 {
+		failures, err := p.store.CountFailures(ctx, userLoginKey(*userID), since)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count login failures: %w", err)
+		}
+		risk = math.Max(risk, float64(failures)/float64(p.config.AccountMaxFailures))
+	}
+	if ip := ipLoginKey(ipAddress); ip != "" && p.config.IPMaxFailures > 0 {
+		failures, err := p.store.CountFailures(ctx, ip, since)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count login failures: %w", err)
+		}
+		risk = math.Max(risk, float64(failures)/float64(p.config.IPMaxFailures))
+	}
+	return math.Min(risk, 1), nil
+}
+
+// decide turns failure counts into a decision: a progressive delay once DelayAfter is passed,
+// with a CAPTCHA once either challenge threshold is reached
+func (p *LoginProtector) decide(accountFailures, ipFailures int) auth.LoginDecision {
+	decision := auth.LoginDecision{Action: auth.LoginActionAllow}
+
+	excess := accountFailures - p.config.DelayAfter
+	if ipFailures > accountFailures {
+		excess = ipFailures - p.config.DelayAfter
+	}
+	if excess > 0 && p.config.BaseDelay > 0 {
+		if excess > 16 {
+			excess = 16
+		}
+		delay := p.config.BaseDelay << (excess - 1)
+		if p.config.MaxDelay > 0 && delay > p.config.MaxDelay {
+			delay = p.config.MaxDelay
+		}
+		decision.Action = auth.LoginActionDelay
+		decision.Delay = delay
+		decision.Reason = "repeated failures"
+	}
+
+	if (p.config.ChallengeAfter > 0 && accountFailures >= p.config.ChallengeAfter) ||
+		(p.config.IPChallengeAfter > 0 && ipFailures >= p.config.IPChallengeAfter) {
+		decision.Action = auth.LoginActionChallenge
+		decision.Challenge = auth.LoginChallengeCaptcha
+		decision.Reason = "repeated failures"
+	}
+	return decision
+}
+
+func (p *LoginProtector) failures(ctx context.Context, account, ip string, now time.Time) (int, int, error) {
+	since := now.Add(-p.config.FailureWindow)
+	var accountFailures, ipFailures int
+	var err error
+	if account != "" {
+		if accountFailures, err = p.store.CountFailures(ctx, account, since); err != nil {
+			return 0, 0, fmt.Errorf("failed to count login failures: %w", err)
+		}
+	}
+	if ip != "" {
+		if ipFailures, err = p.store.CountFailures(ctx, ip, since); err != nil {
+			return 0, 0, fmt.Errorf("failed to count login failures: %w", err)
+		}
+	}
+	return accountFailures, ipFailures, nil
+}
+
+// lock locks key and forgets its failures, so it starts afresh once the lockout ends
+func (p *LoginProtector) lock(ctx context.Context, key string, until time.Time) error {
+	if err := p.store.SetLock(ctx, key, until); err != nil {
+		return fmt.Errorf("failed to lock out login: %w", err)
+	}
+	if err := p.store.ClearFailures(ctx, key); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
+
+func (p *LoginProtector) notifyLocked(ctx context.Context, attempt auth.LoginAttempt, failures int, until time.Time) {
+	p.mu.RLock()
+	notifier := p.notifier
+	p.mu.RUnlock()
+
+	// Only existing accounts have an owner to notify
+	if notifier == nil || attempt.UserID == nil {
+		return
+	}
+	lockout := AccountLockout{
+		Email:       attempt.Email,
+		UserID:      *attempt.UserID,
+		IPAddress:   attempt.IPAddress,
+		Failures:    failures,
+		LockedUntil: until,
+	}
+	if err := notifier.NotifyAccountLocked(ctx, lockout); err != nil {
+		p.logger.Error(ctx, "Failed to notify account owner of lockout", err, map[string]interface{}{
+			"user_id": attempt.UserID.String(),
+		})
+	}
+}
+
+// audit records a login protection event. Auditing failures are logged rather than returned:
+// they must not stop the protection itself.
+func (p *LoginProtector) audit(ctx context.Context, attempt auth.LoginAttempt, action string, result AuditResult, details map[string]interface{}) {
+	p.mu.RLock()
+	auditor := p.auditor
+	p.mu.RUnlock()
+	if auditor == nil {
+		return
+	}
+
+	details["email"] = attempt.Email
+	if err := auditor.LogAuthenticationEvent(ctx, attempt.UserID, action, result, attempt.IPAddress, attempt.UserAgent, details); err != nil {
+		p.logger.Error(ctx, "Failed to audit login protection event", err, map[string]interface{}{
+			"action": action,
+		})
+	}
+}
+
+func accountLoginKey(email string) string {
+	if email = strings.ToLower(strings.TrimSpace(email)); email == "" {
+		return ""
+	}
+	return "account:" + email
+}
+
+func ipLoginKey(ipAddress string) string {
+	if ipAddress == "" {
+		return ""
+	}
+	return "ip:" + ipAddress
+}
+
+func userLoginKey(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
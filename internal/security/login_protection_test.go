@@ -0,0 +1,250 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/auth"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLoginAttemptStore is a LoginAttemptStore in memory
+type memoryLoginAttemptStore struct {
+	failures map[string][]time.Time
+	locks    map[string]time.Time
+}
+
+func newMemoryLoginAttemptStore() *memoryLoginAttemptStore {
+	return &memoryLoginAttemptStore{failures: map[string][]time.Time{}, locks: map[string]time.Time{}}
+}
+
+func (m *memoryLoginAttemptStore) AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	var kept []time.Time
+	for _, failure := range append(m.failures[key], at) {
+		if !failure.Before(at.Add(-window)) {
+			kept = append(kept, failure)
+		}
+	}
+	m.failures[key] = kept
+	return len(kept), nil
+}
+
+func (m *memoryLoginAttemptStore) CountFailures(ctx context.Context, key string, since time.Time) (int, error) {
+	count := 0
+	for _, failure := range m.failures[key] {
+		if !failure.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryLoginAttemptStore) ClearFailures(ctx context.Context, key string) error {
+	delete(m.failures, key)
+	return nil
+}
+
+func (m *memoryLoginAttemptStore) SetLock(ctx context.Context, key string, until time.Time) error {
+	m.locks[key] = until
+	return nil
+}
+
+func (m *memoryLoginAttemptStore) GetLock(ctx context.Context, key string) (time.Time, error) {
+	return m.locks[key], nil
+}
+
+func (m *memoryLoginAttemptStore) ClearLock(ctx context.Context, key string) error {
+	delete(m.locks, key)
+	return nil
+}
+
+type recordingLockoutNotifier struct {
+	lockouts []AccountLockout
+}
+
+func (n *recordingLockoutNotifier) NotifyAccountLocked(ctx context.Context, lockout AccountLockout) error {
+	n.lockouts = append(n.lockouts, lockout)
+	return nil
+}
+
+// newTestLoginProtector returns a protector with the default configuration and a clock the
+// test moves forward
+func newTestLoginProtector() (*LoginProtector, *time.Time) {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	protector := NewLoginProtector(logger, DefaultLoginProtectionConfig(), newMemoryLoginAttemptStore())
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	protector.now = func() time.Time { return now }
+	return protector, &now
+}
+
+func TestLoginProtector_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	protector, now := newTestLoginProtector()
+	auditor := newEscrowAuditor()
+	notifier := &recordingLockoutNotifier{}
+	protector.SetAuditor(auditor)
+	protector.SetNotifier(notifier)
+
+	userID := uuid.New()
+	attempt := auth.LoginAttempt{Email: "Alice@Example.com", IPAddress: "203.0.113.7", UserID: &userID}
+
+	expected := []struct {
+		action auth.LoginAction
+		delay  time.Duration
+	}{
+		{auth.LoginActionAllow, 0},
+		{auth.LoginActionAllow, 0},
+		{auth.LoginActionChallenge, 500 * time.Millisecond},
+		{auth.LoginActionChallenge, time.Second},
+		{auth.LoginActionLocked, 0},
+	}
+	for i, want := range expected {
+		decision, err := protector.RecordLoginFailure(ctx, attempt, "invalid_password")
+		require.NoError(t, err)
+		assert.Equal(t, want.action, decision.Action, "failure %d", i+1)
+		assert.Equal(t, want.delay, decision.Delay, "failure %d", i+1)
+	}
+
+	// The account is locked whatever the email's case or the IP address
+	decision, err := protector.CheckLogin(ctx, auth.LoginAttempt{Email: "alice@example.com", IPAddress: "198.51.100.1"})
+	require.NoError(t, err)
+	assert.Equal(t, auth.LoginActionLocked, decision.Action)
+	assert.Equal(t, 15*time.Minute, decision.RetryAfter)
+
+	require.Len(t, notifier.lockouts, 1)
+	assert.Equal(t, userID, notifier.lockouts[0].UserID)
+	assert.Equal(t, 5, notifier.lockouts[0].Failures)
+	assert.Equal(t, now.Add(15*time.Minute), notifier.lockouts[0].LockedUntil)
+
+	failures, err := auditor.GetAuditEvents(ctx, AuditEventFilter{Action: "login_failure"})
+	require.NoError(t, err)
+	assert.Len(t, failures, 5)
+	locks, err := auditor.GetAuditEvents(ctx, AuditEventFilter{Action: "account_locked"})
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, &userID, locks[0].UserID)
+
+	// The lockout ends on its own, with the failures forgotten
+	*now = now.Add(16 * time.Minute)
+	decision, err = protector.CheckLogin(ctx, attempt)
+	require.NoError(t, err)
+	assert.Equal(t, auth.LoginActionAllow, decision.Action)
+}
+
+func TestLoginProtector_ThrottlesCredentialStuffingByIP(t *testing.T) {
+	ctx := context.Background()
+	protector, _ := newTestLoginProtector()
+	notifier := &recordingLockoutNotifier{}
+	protector.SetNotifier(notifier)
+
+	// One failure each against many accounts never reaches the account limit...
+	for i := 0; i < 10; i++ {
+		_, err := protector.RecordLoginFailure(ctx, auth.LoginAttempt{
+			Email:     strings.Repeat("a", i+1) + "@example.com",
+			IPAddress: "203.0.113.7",
+		}, "unknown_account")
+		require.NoError(t, err)
+	}
+
+	// ...but the IP address is challenged, then locked
+	decision, err := protector.CheckLogin(ctx, auth.LoginAttempt{Email: "new@example.com", IPAddress: "203.0.113.7"})
+	require.NoError(t, err)
+	assert.Equal(t, auth.LoginActionChallenge, decision.Action)
+	assert.Equal(t, auth.LoginChallengeCaptcha, decision.Challenge)
+	assert.Equal(t, 5*time.Second, decision.Delay, "delays are capped")
+
+	decision, err = protector.CheckLogin(ctx, auth.LoginAttempt{Email: "new@example.com", IPAddress: "198.51.100.1"})
+	require.NoError(t, err)
+	assert.Equal(t, auth.LoginActionAllow, decision.Action, "other IP addresses are not throttled")
+
+	for i := 0; i < 10; i++ {
+		decision, err = protector.RecordLoginFailure(ctx, auth.LoginAttempt{Email: "b@example.com", IPAddress: "203.0.113.7"}, "unknown_account")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, auth.LoginActionLocked, decision.Action)
+	assert.Empty(t, notifier.lockouts, "unknown accounts have no owner to notify")
+
+	// A successful login to another account doesn't reset the IP address
+	require.NoError(t, protector.RecordLoginSuccess(ctx, auth.LoginAttempt{Email: "mallory@example.com", IPAddress: "203.0.113.7"}))
+	decision, err = protector.CheckLogin(ctx, auth.LoginAttempt{Email: "mallory@example.com", IPAddress: "203.0.113.7"})
+	require.NoError(t, err)
+	assert.Equal(t, auth.LoginActionLocked, decision.Action)
+}
+
+func TestLoginProtector_ClearLockout(t *testing.T) {
+	ctx := context.Background()
+	protector, _ := newTestLoginProtector()
+	auditor := newEscrowAuditor()
+	protector.SetAuditor(auditor)
+
+	attempt := auth.LoginAttempt{Email: "alice@example.com", IPAddress: "203.0.113.7"}
+	for i := 0; i < 5; i++ {
+		_, err := protector.RecordLoginFailure(ctx, attempt, "invalid_password")
+		require.NoError(t, err)
+	}
+	decision, err := protector.CheckLogin(ctx, attempt)
+	require.NoError(t, err)
+	require.Equal(t, auth.LoginActionLocked, decision.Action)
+
+	assert.Error(t, protector.ClearLockout(ctx, "", "", "admin"))
+	require.NoError(t, protector.ClearLockout(ctx, "ALICE@example.com", "203.0.113.7", "admin"))
+
+	decision, err = protector.CheckLogin(ctx, attempt)
+	require.NoError(t, err)
+	assert.Equal(t, auth.LoginActionAllow, decision.Action)
+
+	cleared, err := auditor.GetAuditEvents(ctx, AuditEventFilter{Action: "lockout_cleared"})
+	require.NoError(t, err)
+	require.Len(t, cleared, 1)
+	assert.Equal(t, "admin", cleared[0].Details["operator"])
+}
+
+func TestLoginProtector_FailedLoginRiskShortensSessions(t *testing.T) {
+	ctx := context.Background()
+	protector, now := newTestLoginProtector()
+
+	userID := uuid.New()
+	for i := 0; i < 3; i++ {
+		_, err := protector.RecordLoginFailure(ctx, auth.LoginAttempt{Email: "alice@example.com", IPAddress: "203.0.113.7", UserID: &userID}, "invalid_password")
+		require.NoError(t, err)
+	}
+
+	risk, err := protector.FailedLoginRisk(ctx, &userID, "198.51.100.1")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, risk, 1e-9)
+	risk, err = protector.FailedLoginRisk(ctx, nil, "203.0.113.7")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.15, risk, 1e-9)
+
+	// A successful login resets the account, but the user's recent velocity still counts
+	require.NoError(t, protector.RecordLoginSuccess(ctx, auth.LoginAttempt{Email: "alice@example.com"}))
+	risk, err = protector.FailedLoginRisk(ctx, &userID, "")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, risk, 1e-9)
+
+	request := &AccessRequest{UserID: &userID, IPAddress: "198.51.100.1", Resource: "/api/dashboard", Action: "GET", Timestamp: *now}
+	engine := NewZeroTrustEngine(&observability.Logger{})
+	baseline, err := engine.evaluateThreatLevel(ctx, request)
+	require.NoError(t, err)
+
+	engine.SetLoginRiskSource(protector)
+	threatLevel, err := engine.evaluateThreatLevel(ctx, request)
+	require.NoError(t, err)
+	assert.InDelta(t, min(1.0, baseline+0.6), threatLevel, 1e-9)
+
+	decision, err := engine.EvaluateAccess(ctx, request)
+	require.NoError(t, err)
+	assert.Less(t, decision.SessionTTL, engine.calculateSessionTTL(0))
+
+	// Failures outside the window no longer count
+	*now = now.Add(time.Hour)
+	risk, err = protector.FailedLoginRisk(ctx, &userID, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, risk)
+}
@@ -0,0 +1,374 @@
+package security
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/auth"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// LoginRiskSignal is an anomaly found in a login compared to the user's login history
+type LoginRiskSignal string
+
+const (
+	LoginSignalImpossibleTravel LoginRiskSignal = "impossible_travel"
+	LoginSignalNewCountry       LoginRiskSignal = "new_country"
+	LoginSignalNewDevice        LoginRiskSignal = "new_device"
+	LoginSignalUnusualHour      LoginRiskSignal = "unusual_hour"
+)
+
+// LoginRiskConfig weighs the anomalies making up a login's risk score. The score is the sum
+// of the weights of the signals found, capped at 1.
+type LoginRiskConfig struct {
+	ImpossibleTravelWeight float64
+	NewCountryWeight       float64
+	NewDeviceWeight        float64
+	UnusualHourWeight      float64
+
+	MaxTravelSpeedKmh    float64 // faster travel between two logins is impossible
+	MinTravelDistanceKm  float64 // closer locations are geo-IP noise, not travel
+	UnusualHourMinLogins int     // logins needed before login hours are judged
+	HistorySize          int     // logins kept per user
+	MFAThreshold         float64 // scores from which MFA is required
+}
+
+// DefaultLoginRiskConfig returns the default login risk configuration
+func DefaultLoginRiskConfig() LoginRiskConfig {
+	return LoginRiskConfig{
+		ImpossibleTravelWeight: 0.6,
+		NewCountryWeight:       0.3,
+		NewDeviceWeight:        0.25,
+		UnusualHourWeight:      0.15,
+		MaxTravelSpeedKmh:      900,
+		MinTravelDistanceKm:    300,
+		UnusualHourMinLogins:   5,
+		HistorySize:            50,
+		MFAThreshold:           0.5,
+	}
+}
+
+// LoginRecord is a successful login in a user's login history
+type LoginRecord struct {
+	UserID    uuid.UUID         `json:"user_id"`
+	IPAddress string            `json:"ip_address"`
+	DeviceID  string            `json:"device_id"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Location  *Location         `json:"location,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	RiskScore float64           `json:"risk_score"`
+	Signals   []LoginRiskSignal `json:"signals,omitempty"`
+}
+
+// LoginHistoryStore keeps the most recent logins of each user, newest first
+type LoginHistoryStore interface {
+	// AddLogin records a login, keeping only the newest keep logins of the user
+	AddLogin(ctx context.Context, record LoginRecord, keep int) error
+	ListLogins(ctx context.Context, userID uuid.UUID, limit int) ([]LoginRecord, error)
+}
+
+const loginHistoryKeyPrefix = "auth:login:history:"
+
+// redisLoginHistoryStore keeps each user's logins as JSON in a capped Redis list
+type redisLoginHistoryStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisLoginHistoryStore creates a store sharing login history through Redis
+func NewRedisLoginHistoryStore(redis *database.RedisClient) LoginHistoryStore {
+	return &redisLoginHistoryStore{redis: redis}
+}
+
+func (s *redisLoginHistoryStore) AddLogin(ctx context.Context, record LoginRecord, keep int) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal login record: %w", err)
+	}
+
+	key := loginHistoryKeyPrefix + record.UserID.String()
+	pipe := s.redis.Client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	if keep > 0 {
+		pipe.LTrim(ctx, key, 0, int64(keep-1))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *redisLoginHistoryStore) ListLogins(ctx context.Context, userID uuid.UUID, limit int) ([]LoginRecord, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit - 1)
+	}
+	values, err := s.redis.Client.LRange(ctx, loginHistoryKeyPrefix+userID.String(), 0, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LoginRecord, 0, len(values))
+	for _, value := range values {
+		var record LoginRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal login record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// LoginRiskScorer scores logins against the user's login history: impossible travel since
+// the previous login, a country or device never seen before and an hour the user doesn't
+// usually log in at. It implements auth.LoginRiskAssessor for logins and
+// LoginAnomalySource for the zero-trust engine.
+type LoginRiskScorer struct {
+	logger *observability.Logger
+	config LoginRiskConfig
+	store  LoginHistoryStore
+	geoIP  GeoIPResolver
+	now    func() time.Time
+	mu     sync.RWMutex
+}
+
+// NewLoginRiskScorer creates a login risk scorer keeping login history in store
+func NewLoginRiskScorer(logger *observability.Logger, config LoginRiskConfig, store LoginHistoryStore) *LoginRiskScorer {
+	return &LoginRiskScorer{
+		logger: logger,
+		config: config,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// SetGeoIPResolver locates login IP addresses; without one, travel and country anomalies
+// are not detected
+func (s *LoginRiskScorer) SetGeoIPResolver(resolver GeoIPResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.geoIP = resolver
+}
+
+// AssessLogin scores a successful login and adds it to the user's history
+func (s *LoginRiskScorer) AssessLogin(ctx context.Context, attempt auth.LoginAttempt) (auth.LoginRiskAssessment, error) {
+	if attempt.UserID == nil {
+		return auth.LoginRiskAssessment{}, fmt.Errorf("login risk requires a user")
+	}
+
+	record, err := s.evaluate(ctx, *attempt.UserID, attempt.IPAddress, attempt.DeviceID, attempt.UserAgent, s.now())
+	if err != nil {
+		return auth.LoginRiskAssessment{}, err
+	}
+	if err := s.store.AddLogin(ctx, record, s.config.HistorySize); err != nil {
+		return auth.LoginRiskAssessment{}, fmt.Errorf("failed to record login: %w", err)
+	}
+
+	assessment := auth.LoginRiskAssessment{
+		Score:       record.RiskScore,
+		RequiresMFA: s.config.MFAThreshold > 0 && record.RiskScore >= s.config.MFAThreshold,
+	}
+	for _, signal := range record.Signals {
+		assessment.Signals = append(assessment.Signals, string(signal))
+	}
+
+	if len(record.Signals) > 0 {
+		s.logger.Warn(ctx, "Anomalous login detected", map[string]interface{}{
+			"user_id":    record.UserID.String(),
+			"ip_address": record.IPAddress,
+			"risk_score": record.RiskScore,
+			"signals":    assessment.Signals,
+		})
+	}
+	return assessment, nil
+}
+
+// LoginAnomalyRisk scores an access request like a login from the same IP address and
+// device, without adding it to the history
+func (s *LoginRiskScorer) LoginAnomalyRisk(ctx context.Context, request *AccessRequest) (float64, error) {
+	if request.UserID == nil {
+		return 0, nil
+	}
+
+	at := request.Timestamp
+	if at.IsZero() {
+		at = s.now()
+	}
+	record, err := s.evaluate(ctx, *request.UserID, request.IPAddress, request.DeviceID, request.UserAgent, at)
+	if err != nil {
+		return 0, err
+	}
+	return record.RiskScore, nil
+}
+
+// LoginHistory returns the user's most recent logins, newest first
+func (s *LoginRiskScorer) LoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]LoginRecord, error) {
+	records, err := s.store.ListLogins(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login history: %w", err)
+	}
+	return records, nil
+}
+
+// evaluate builds the record of a login and scores it against the user's history
+func (s *LoginRiskScorer) evaluate(ctx context.Context, userID uuid.UUID, ipAddress, deviceID, userAgent string, at time.Time) (LoginRecord, error) {
+	if deviceID == "" {
+		deviceID = userAgentDeviceID(userAgent)
+	}
+	record := LoginRecord{
+		UserID:    userID,
+		IPAddress: ipAddress,
+		DeviceID:  deviceID,
+		UserAgent: userAgent,
+		Timestamp: at,
+	}
+
+	s.mu.RLock()
+	geoIP := s.geoIP
+	s.mu.RUnlock()
+	if geoIP != nil && ipAddress != "" {
+		location, err := geoIP.Lookup(ctx, ipAddress)
+		if err != nil {
+			// Device and hour anomalies are still worth scoring without a location
+			s.logger.Warn(ctx, "Failed to locate login IP address", map[string]interface{}{
+				"ip_address": ipAddress,
+				"error":      err.Error(),
+			})
+		} else {
+			record.Location = location
+		}
+	}
+
+	history, err := s.store.ListLogins(ctx, userID, s.config.HistorySize)
+	if err != nil {
+		return LoginRecord{}, fmt.Errorf("failed to list login history: %w", err)
+	}
+
+	record.RiskScore, record.Signals = s.score(record, history)
+	return record, nil
+}
+
+// score weighs the anomalies of a login against the history, newest first. A user's first
+// login has nothing to be compared with and scores zero.
+func (s *LoginRiskScorer) score(record LoginRecord, history []LoginRecord) (float64, []LoginRiskSignal) {
+	if len(history) == 0 {
+		return 0, nil
+	}
+
+	var signals []LoginRiskSignal
+	score := 0.0
+	add := func(signal LoginRiskSignal, weight float64) {
+		signals = append(signals, signal)
+		score += weight
+	}
+
+	if s.impossibleTravel(record, history) {
+		add(LoginSignalImpossibleTravel, s.config.ImpossibleTravelWeight)
+	}
+	if s.newCountry(record, history) {
+		add(LoginSignalNewCountry, s.config.NewCountryWeight)
+	}
+	if s.newDevice(record, history) {
+		add(LoginSignalNewDevice, s.config.NewDeviceWeight)
+	}
+	if s.unusualHour(record, history) {
+		add(LoginSignalUnusualHour, s.config.UnusualHourWeight)
+	}
+	return math.Min(score, 1), signals
+}
+
+// impossibleTravel reports whether getting from the previous located login to this one
+// would have taken travelling faster than MaxTravelSpeedKmh
+func (s *LoginRiskScorer) impossibleTravel(record LoginRecord, history []LoginRecord) bool {
+	if !hasCoordinates(record.Location) || s.config.MaxTravelSpeedKmh <= 0 {
+		return false
+	}
+	for _, previous := range history {
+		if !hasCoordinates(previous.Location) {
+			continue
+		}
+		distance := haversineKm(previous.Location, record.Location)
+		if distance < s.config.MinTravelDistanceKm {
+			return false
+		}
+		hours := record.Timestamp.Sub(previous.Timestamp).Hours()
+		return hours <= 0 || distance/hours > s.config.MaxTravelSpeedKmh
+	}
+	return false
+}
+
+// newCountry reports whether the login comes from a country none of the located logins in
+// the history came from
+func (s *LoginRiskScorer) newCountry(record LoginRecord, history []LoginRecord) bool {
+	if record.Location == nil || record.Location.Country == "" {
+		return false
+	}
+	located := false
+	for _, previous := range history {
+		if previous.Location == nil || previous.Location.Country == "" {
+			continue
+		}
+		if previous.Location.Country == record.Location.Country {
+			return false
+		}
+		located = true
+	}
+	return located
+}
+
+func (s *LoginRiskScorer) newDevice(record LoginRecord, history []LoginRecord) bool {
+	if record.DeviceID == "" {
+		return false
+	}
+	for _, previous := range history {
+		if previous.DeviceID == record.DeviceID {
+			return false
+		}
+	}
+	return true
+}
+
+// unusualHour reports whether none of the user's logins happened within an hour, in UTC, of
+// this one. Users need UnusualHourMinLogins logins before their hours are judged.
+func (s *LoginRiskScorer) unusualHour(record LoginRecord, history []LoginRecord) bool {
+	if len(history) < s.config.UnusualHourMinLogins {
+		return false
+	}
+	hour := record.Timestamp.UTC().Hour()
+	for _, previous := range history {
+		diff := (previous.Timestamp.UTC().Hour() - hour + 24) % 24
+		if diff <= 1 || diff >= 23 {
+			return false
+		}
+	}
+	return true
+}
+
+func hasCoordinates(location *Location) bool {
+	return location != nil && (location.Latitude != 0 || location.Longitude != 0)
+}
+
+// haversineKm returns the great-circle distance between two locations in kilometres
+func haversineKm(a, b *Location) float64 {
+	const earthRadiusKm = 6371.0
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// userAgentDeviceID stands in for a device fingerprint when the client sends none
+func userAgentDeviceID(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(userAgent))
+	return "ua:" + hex.EncodeToString(hash[:8])
+}
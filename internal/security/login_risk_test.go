@@ -0,0 +1,236 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/auth"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLoginHistoryStore is a LoginHistoryStore in memory
+type memoryLoginHistoryStore struct {
+	logins map[uuid.UUID][]LoginRecord
+}
+
+func (m *memoryLoginHistoryStore) AddLogin(ctx context.Context, record LoginRecord, keep int) error {
+	logins := append([]LoginRecord{record}, m.logins[record.UserID]...)
+	if keep > 0 && len(logins) > keep {
+		logins = logins[:keep]
+	}
+	m.logins[record.UserID] = logins
+	return nil
+}
+
+func (m *memoryLoginHistoryStore) ListLogins(ctx context.Context, userID uuid.UUID, limit int) ([]LoginRecord, error) {
+	logins := m.logins[userID]
+	if limit > 0 && len(logins) > limit {
+		logins = logins[:limit]
+	}
+	return logins, nil
+}
+
+// staticGeoIPResolver locates IP addresses from a fixed table
+type staticGeoIPResolver map[string]*Location
+
+func (r staticGeoIPResolver) Lookup(ctx context.Context, ipAddress string) (*Location, error) {
+	return r[ipAddress], nil
+}
+
+var (
+	berlin = &Location{Country: "DE", City: "Berlin", Latitude: 52.52, Longitude: 13.405}
+	munich = &Location{Country: "DE", City: "Munich", Latitude: 48.137, Longitude: 11.575}
+	sydney = &Location{Country: "AU", City: "Sydney", Latitude: -33.868, Longitude: 151.209}
+)
+
+// newTestLoginRiskScorer returns a scorer with the default weights, Berlin, Munich and Sydney
+// addresses and a clock the test moves forward
+func newTestLoginRiskScorer() (*LoginRiskScorer, *time.Time) {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	scorer := NewLoginRiskScorer(logger, DefaultLoginRiskConfig(), &memoryLoginHistoryStore{logins: map[uuid.UUID][]LoginRecord{}})
+	scorer.SetGeoIPResolver(staticGeoIPResolver{
+		"198.51.100.1": berlin,
+		"198.51.100.2": munich,
+		"203.0.113.9":  sydney,
+	})
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	scorer.now = func() time.Time { return now }
+	return scorer, &now
+}
+
+func TestLoginRiskScorer_FirstLoginHasNoRisk(t *testing.T) {
+	scorer, _ := newTestLoginRiskScorer()
+	userID := uuid.New()
+
+	assessment, err := scorer.AssessLogin(context.Background(), auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", DeviceID: "laptop"})
+	require.NoError(t, err)
+	assert.Zero(t, assessment.Score)
+	assert.Empty(t, assessment.Signals)
+	assert.False(t, assessment.RequiresMFA)
+
+	history, err := scorer.LoginHistory(context.Background(), userID, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "DE", history[0].Location.Country)
+}
+
+func TestLoginRiskScorer_ScoresAnomalies(t *testing.T) {
+	ctx := context.Background()
+	scorer, now := newTestLoginRiskScorer()
+	userID := uuid.New()
+
+	login := func(ip, device string) auth.LoginRiskAssessment {
+		assessment, err := scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: ip, DeviceID: device})
+		require.NoError(t, err)
+		return assessment
+	}
+
+	login("198.51.100.1", "laptop")
+
+	// Driving to Munich the next day on the same laptop is normal
+	*now = now.Add(24 * time.Hour)
+	assert.Zero(t, login("198.51.100.2", "laptop").Score)
+
+	// A new phone is only a new device
+	*now = now.Add(time.Hour)
+	assessment := login("198.51.100.2", "phone")
+	assert.Equal(t, []string{string(LoginSignalNewDevice)}, assessment.Signals)
+	assert.InDelta(t, 0.25, assessment.Score, 1e-9)
+	assert.False(t, assessment.RequiresMFA)
+
+	// Sydney two hours after Munich is impossible, in a new country, on a new device
+	*now = now.Add(2 * time.Hour)
+	assessment = login("203.0.113.9", "tablet")
+	assert.ElementsMatch(t, []string{
+		string(LoginSignalImpossibleTravel),
+		string(LoginSignalNewCountry),
+		string(LoginSignalNewDevice),
+	}, assessment.Signals)
+	assert.Equal(t, 1.0, assessment.Score)
+	assert.True(t, assessment.RequiresMFA)
+}
+
+func TestLoginRiskScorer_UnusualHour(t *testing.T) {
+	ctx := context.Background()
+	scorer, now := newTestLoginRiskScorer()
+	userID := uuid.New()
+
+	// Five morning logins establish the user's hours
+	for i := 0; i < 5; i++ {
+		_, err := scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", DeviceID: "laptop"})
+		require.NoError(t, err)
+		*now = now.Add(24 * time.Hour)
+	}
+
+	*now = now.Add(time.Hour)
+	assessment, err := scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", DeviceID: "laptop"})
+	require.NoError(t, err)
+	assert.Empty(t, assessment.Signals, "an hour later is still usual")
+
+	*now = now.Add(-7 * time.Hour)
+	assessment, err = scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", DeviceID: "laptop"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(LoginSignalUnusualHour)}, assessment.Signals)
+	assert.InDelta(t, 0.15, assessment.Score, 1e-9)
+}
+
+func TestLoginRiskScorer_ConfigurableWeights(t *testing.T) {
+	ctx := context.Background()
+	scorer, _ := newTestLoginRiskScorer()
+	scorer.config.NewDeviceWeight = 0.8
+	userID := uuid.New()
+
+	_, err := scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", UserAgent: "Firefox"})
+	require.NoError(t, err)
+
+	// Without a fingerprint the user agent identifies the device
+	assessment, err := scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", UserAgent: "Firefox"})
+	require.NoError(t, err)
+	assert.Zero(t, assessment.Score)
+
+	assessment, err = scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", UserAgent: "Safari"})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, assessment.Score, 1e-9)
+	assert.True(t, assessment.RequiresMFA)
+}
+
+func TestLoginRiskScorer_RaisesZeroTrustRisk(t *testing.T) {
+	ctx := context.Background()
+	scorer, now := newTestLoginRiskScorer()
+	userID := uuid.New()
+
+	_, err := scorer.AssessLogin(ctx, auth.LoginAttempt{UserID: &userID, IPAddress: "198.51.100.1", DeviceID: "laptop"})
+	require.NoError(t, err)
+
+	*now = now.Add(time.Hour)
+	request := &AccessRequest{UserID: &userID, IPAddress: "203.0.113.9", DeviceID: "tablet", Resource: "/api/portfolio", Action: "GET", Timestamp: *now}
+	risk, err := scorer.LoginAnomalyRisk(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, risk)
+
+	// Scoring a request doesn't add it to the history
+	history, err := scorer.LoginHistory(ctx, userID, 0)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	engine := NewZeroTrustEngine(&observability.Logger{})
+	engine.SetLoginAnomalySource(scorer)
+	decision, err := engine.EvaluateAccess(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, decision.RiskScore)
+	assert.True(t, decision.RequiresMFA)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, engine.calculateSessionTTL(1.0), decision.SessionTTL)
+}
+
+func TestMaxMindResolver_Lookup(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "42", user)
+		assert.Equal(t, "license", password)
+
+		if r.URL.Path != "/city/203.0.113.9" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"country": {"iso_code": "AU"},
+			"subdivisions": [{"names": {"en": "New South Wales"}}],
+			"city": {"names": {"en": "Sydney"}},
+			"location": {"latitude": -33.868, "longitude": 151.209}
+		}`))
+	}))
+	defer server.Close()
+
+	resolver := NewMaxMindResolver(server.URL+"/city/", "42", "license")
+	ctx := context.Background()
+
+	location, err := resolver.Lookup(ctx, "203.0.113.9")
+	require.NoError(t, err)
+	assert.Equal(t, &Location{Country: "AU", Region: "New South Wales", City: "Sydney", Latitude: -33.868, Longitude: 151.209}, location)
+
+	// Lookups are cached
+	_, err = resolver.Lookup(ctx, "203.0.113.9")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	location, err = resolver.Lookup(ctx, "198.51.100.77")
+	require.NoError(t, err)
+	assert.Nil(t, location)
+
+	// Private addresses are never looked up
+	location, err = resolver.Lookup(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Nil(t, location)
+	assert.Equal(t, 2, requests)
+}
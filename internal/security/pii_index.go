@@ -0,0 +1,297 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPIIIndexNotConfigured is returned by searchable PII operations before an index key is set
+	ErrPIIIndexNotConfigured = errors.New("no PII index key configured")
+	// ErrNotSearchablePII is returned for lookups of fields that are not indexed
+	ErrNotSearchablePII = errors.New("field is not searchable PII")
+)
+
+// DefaultSearchablePIIFields are the PII fields indexed when no others are designated
+var DefaultSearchablePIIFields = []string{"email", "phone"}
+
+// minPIIIndexKeySize is the least size of the key that PII search hashes are computed with
+const minPIIIndexKeySize = 32
+
+// PIIIndex finds records by the keyed hash of a searchable PII field, stored in the field's
+// hash column next to its encrypted value
+type PIIIndex interface {
+	FindByPIIHash(ctx context.Context, hashColumn, hash string) ([]string, error)
+}
+
+// PIIBackfillStore reads rows whose hash column is not filled yet and fills it, so searchable
+// PII written before indexing was enabled can be looked up
+type PIIBackfillStore interface {
+	// UnindexedPII returns up to limit rows with field set and its hash column empty. Values
+	// are plaintext or the JSON of a field encrypted by EncryptPII.
+	UnindexedPII(ctx context.Context, field string, limit int) ([]PIIRow, error)
+	SetPIIHash(ctx context.Context, field, rowID, hash string) error
+}
+
+// PIIRow is a row with a PII field whose hash column is missing
+type PIIRow struct {
+	ID    string
+	Value string
+}
+
+// PIIHashColumn names the column holding the search hash of a PII field
+func PIIHashColumn(field string) string {
+	return field + "_hash"
+}
+
+// SetPIIIndexKey sets the key that search hashes of searchable PII fields are computed with
+// and designates those fields, DefaultSearchablePIIFields when none are given. The key is kept
+// apart from the rotated encryption keys: rotating them never changes a hash, but changing
+// this key invalidates every stored hash.
+func (em *EncryptionManager) SetPIIIndexKey(key []byte, searchable ...string) error {
+	if len(key) < minPIIIndexKeySize {
+		return fmt.Errorf("PII index key must be at least %d bytes, got %d", minPIIIndexKeySize, len(key))
+	}
+	if len(searchable) == 0 {
+		searchable = DefaultSearchablePIIFields
+	}
+	fields := make(map[string]bool, len(searchable))
+	for _, field := range searchable {
+		if !em.isPIIField(field) {
+			return fmt.Errorf("%w: %s is not a PII field", ErrNotSearchablePII, field)
+		}
+		fields[field] = true
+	}
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.piiIndexKey = append([]byte(nil), key...)
+	em.searchablePII = fields
+	return nil
+}
+
+// HashPII returns the search hash of a searchable PII value: an HMAC-SHA256 of the field name
+// and the value, trimmed and lowercased so lookups don't depend on how the value was typed
+func (em *EncryptionManager) HashPII(field string, value interface{}) (string, error) {
+	em.mu.RLock()
+	key, searchable := em.piiIndexKey, em.searchablePII[field]
+	em.mu.RUnlock()
+	if key == nil {
+		return "", ErrPIIIndexNotConfigured
+	}
+	if !searchable {
+		return "", fmt.Errorf("%w: %s", ErrNotSearchablePII, field)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", value)))))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// EncryptAndIndexPII encrypts PII like EncryptPII and adds the hash column of every searchable
+// field present, so records can be found by those fields without decrypting them
+func (em *EncryptionManager) EncryptAndIndexPII(piiData map[string]interface{}) (map[string]interface{}, error) {
+	if !em.hasPIIIndex() {
+		return nil, ErrPIIIndexNotConfigured
+	}
+
+	encryptedData, err := em.EncryptPII(piiData)
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range piiData {
+		if !em.isSearchablePII(field) {
+			continue
+		}
+		hash, err := em.HashPII(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index PII field %s: %w", field, err)
+		}
+		encryptedData[PIIHashColumn(field)] = hash
+	}
+	return encryptedData, nil
+}
+
+// LookupByPII finds the records whose searchable field holds value. The lookup is audited with
+// the search hash only.
+func (em *EncryptionManager) LookupByPII(ctx context.Context, index PIIIndex, field string, value interface{}) ([]string, error) {
+	hash, err := em.HashPII(field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := index.FindByPIIHash(ctx, PIIHashColumn(field), hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up PII field %s: %w", field, err)
+	}
+
+	if err := em.audit(ctx, "", &AuditEvent{
+		Severity: AuditSeverityMedium,
+		Resource: "pii",
+		Action:   "pii_lookup",
+		Result:   AuditResultSuccess,
+		Details: map[string]interface{}{
+			"field":   field,
+			"hash":    hash,
+			"matches": len(ids),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// DecryptPII reverses EncryptAndIndexPII for the data subject: encrypted fields are decrypted
+// and hash columns, which mean nothing outside this system, are dropped
+func (em *EncryptionManager) DecryptPII(encryptedData map[string]interface{}) (map[string]interface{}, error) {
+	decryptedData := make(map[string]interface{}, len(encryptedData))
+	for field, value := range encryptedData {
+		if base, isHash := strings.CutSuffix(field, "_hash"); isHash && em.isPIIField(base) {
+			continue
+		}
+		envelope, encrypted := piiEnvelope(value)
+		if !encrypted {
+			decryptedData[field] = value
+			continue
+		}
+		plaintext, err := em.decryptPIIEnvelope(envelope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt PII field %s: %w", field, err)
+		}
+		decryptedData[field] = plaintext
+	}
+	return decryptedData, nil
+}
+
+// RewrapPII re-encrypts the fields of a record encrypted with an older PII key with the active
+// one, after the keys are rotated. Hash columns are kept as they are: search hashes don't
+// depend on the encryption keys. It reports whether any field was re-encrypted.
+func (em *EncryptionManager) RewrapPII(encryptedData map[string]interface{}) (map[string]interface{}, bool, error) {
+	active, err := em.keyManager.GetActiveKey("pii")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	rewrapped := make(map[string]interface{}, len(encryptedData))
+	changed := false
+	for field, value := range encryptedData {
+		envelope, encrypted := piiEnvelope(value)
+		if !encrypted || envelope["key_id"] == active.ID {
+			rewrapped[field] = value
+			continue
+		}
+		plaintext, err := em.decryptPIIEnvelope(envelope)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decrypt PII field %s: %w", field, err)
+		}
+		reencrypted, err := em.EncryptPII(map[string]interface{}{field: plaintext})
+		if err != nil {
+			return nil, false, err
+		}
+		rewrapped[field] = reencrypted[field]
+		changed = true
+	}
+	return rewrapped, changed, nil
+}
+
+// BackfillPIIHashes fills the hash column of a searchable field for rows written before it was
+// indexed, batchSize rows at a time, and returns how many rows were filled
+func (em *EncryptionManager) BackfillPIIHashes(ctx context.Context, store PIIBackfillStore, field string, batchSize int) (int, error) {
+	if !em.isSearchablePII(field) {
+		if !em.hasPIIIndex() {
+			return 0, ErrPIIIndexNotConfigured
+		}
+		return 0, fmt.Errorf("%w: %s", ErrNotSearchablePII, field)
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	filled := 0
+	for {
+		rows, err := store.UnindexedPII(ctx, field, batchSize)
+		if err != nil {
+			return filled, fmt.Errorf("failed to read unindexed %s values: %w", field, err)
+		}
+		for _, row := range rows {
+			value := row.Value
+			var envelope map[string]interface{}
+			if json.Unmarshal([]byte(row.Value), &envelope) == nil {
+				if _, encrypted := piiEnvelope(envelope); encrypted {
+					if value, err = em.decryptPIIEnvelope(envelope); err != nil {
+						return filled, fmt.Errorf("failed to decrypt %s of row %s: %w", field, row.ID, err)
+					}
+				}
+			}
+
+			hash, err := em.HashPII(field, value)
+			if err != nil {
+				return filled, err
+			}
+			if err := store.SetPIIHash(ctx, field, row.ID, hash); err != nil {
+				return filled, fmt.Errorf("failed to set %s of row %s: %w", PIIHashColumn(field), row.ID, err)
+			}
+			filled++
+		}
+		if len(rows) < batchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return filled, err
+		}
+	}
+
+	em.logger.Info(ctx, "Backfilled PII search hashes", map[string]interface{}{
+		"field":  field,
+		"filled": filled,
+	})
+	return filled, nil
+}
+
+func (em *EncryptionManager) hasPIIIndex() bool {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	return em.piiIndexKey != nil
+}
+
+func (em *EncryptionManager) isSearchablePII(field string) bool {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	return em.searchablePII[field]
+}
+
+// decryptPIIEnvelope decrypts a field encrypted by EncryptPII
+func (em *EncryptionManager) decryptPIIEnvelope(envelope map[string]interface{}) (string, error) {
+	encoded, _ := envelope["encrypted_data"].(string)
+	keyID, _ := envelope["key_id"].(string)
+	algorithm, _ := envelope["algorithm"].(string)
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted data: %w", err)
+	}
+	plaintext, err := em.DecryptData(&DecryptionRequest{EncryptedData: ciphertext, KeyID: keyID, Algorithm: algorithm})
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// piiEnvelope reports whether value is a field encrypted by EncryptPII
+func piiEnvelope(value interface{}) (map[string]interface{}, bool) {
+	envelope, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	_, encrypted := envelope["encrypted_data"].(string)
+	return envelope, encrypted
+}
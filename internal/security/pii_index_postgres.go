@@ -0,0 +1,93 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// sqlIdentifier matches the table and column names a PostgresPIITable interpolates into queries
+var sqlIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// PostgresPIITable implements PIIIndex and PIIBackfillStore for a table with searchable PII
+// columns, each with a <field>_hash column holding its search hash
+type PostgresPIITable struct {
+	db       *database.DB
+	table    string
+	idColumn string
+}
+
+// NewPostgresPIITable creates the PII index of a table whose rows are identified by idColumn
+func NewPostgresPIITable(db *database.DB, table, idColumn string) (*PostgresPIITable, error) {
+	for _, name := range []string{table, idColumn} {
+		if !sqlIdentifier.MatchString(name) {
+			return nil, fmt.Errorf("invalid SQL identifier: %q", name)
+		}
+	}
+	return &PostgresPIITable{db: db, table: table, idColumn: idColumn}, nil
+}
+
+// FindByPIIHash returns the IDs of the rows whose hash column holds hash
+func (t *PostgresPIITable) FindByPIIHash(ctx context.Context, hashColumn, hash string) ([]string, error) {
+	if !sqlIdentifier.MatchString(hashColumn) {
+		return nil, fmt.Errorf("invalid SQL identifier: %q", hashColumn)
+	}
+
+	rows, err := t.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT %s::text FROM %s WHERE %s = $1`, t.idColumn, t.table, hashColumn,
+	), hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UnindexedPII returns rows with field set and its hash column still NULL
+func (t *PostgresPIITable) UnindexedPII(ctx context.Context, field string, limit int) ([]PIIRow, error) {
+	if !sqlIdentifier.MatchString(field) {
+		return nil, fmt.Errorf("invalid SQL identifier: %q", field)
+	}
+
+	rows, err := t.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT %s::text, %s::text FROM %s WHERE %s IS NOT NULL AND %s IS NULL ORDER BY %s LIMIT $1`,
+		t.idColumn, field, t.table, field, PIIHashColumn(field), t.idColumn,
+	), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []PIIRow
+	for rows.Next() {
+		var row PIIRow
+		if err := rows.Scan(&row.ID, &row.Value); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// SetPIIHash fills the hash column of field for a row
+func (t *PostgresPIITable) SetPIIHash(ctx context.Context, field, rowID, hash string) error {
+	if !sqlIdentifier.MatchString(field) {
+		return fmt.Errorf("invalid SQL identifier: %q", field)
+	}
+
+	_, err := t.db.ExecContext(ctx, fmt.Sprintf(
+		`UPDATE %s SET %s = $1 WHERE %s::text = $2`, t.table, PIIHashColumn(field), t.idColumn,
+	), hash, rowID)
+	return err
+}
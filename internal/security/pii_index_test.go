@@ -0,0 +1,163 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPIIEncryptionManager(t *testing.T) *EncryptionManager {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	em := NewEncryptionManager(logger, &EncryptionConfig{Algorithm: "AES-256-GCM", KeyRotationInterval: 24 * time.Hour})
+	require.NoError(t, em.generateInitialKeys())
+	require.NoError(t, em.SetPIIIndexKey(bytes.Repeat([]byte{0x11}, 32)))
+	return em
+}
+
+// memoryPIITable is a PIIBackfillStore over rows of plaintext or encrypted values
+type memoryPIITable struct {
+	values map[string]string
+	hashes map[string]string
+}
+
+func (m *memoryPIITable) UnindexedPII(ctx context.Context, field string, limit int) ([]PIIRow, error) {
+	var rows []PIIRow
+	for id, value := range m.values {
+		if _, indexed := m.hashes[id]; !indexed && len(rows) < limit {
+			rows = append(rows, PIIRow{ID: id, Value: value})
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryPIITable) SetPIIHash(ctx context.Context, field, rowID, hash string) error {
+	m.hashes[rowID] = hash
+	return nil
+}
+
+func TestEncryptAndIndexPII(t *testing.T) {
+	em := newPIIEncryptionManager(t)
+
+	encrypted, err := em.EncryptAndIndexPII(map[string]interface{}{
+		"email":     "Alice@Example.com",
+		"full_name": "Alice",
+		"plan":      "pro",
+	})
+	require.NoError(t, err)
+
+	hash, err := em.HashPII("email", " alice@example.COM ")
+	require.NoError(t, err)
+	assert.Equal(t, hash, encrypted["email_hash"], "hashes ignore case and surrounding spaces")
+	assert.NotContains(t, encrypted, "full_name_hash", "only designated fields are indexed")
+	assert.Equal(t, "pro", encrypted["plan"])
+
+	decrypted, err := em.DecryptPII(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"email": "Alice@Example.com", "full_name": "Alice", "plan": "pro"}, decrypted)
+
+	// Hashes are keyed: another index key gives other hashes
+	other := newPIIEncryptionManager(t)
+	require.NoError(t, other.SetPIIIndexKey(bytes.Repeat([]byte{0x22}, 32)))
+	otherHash, err := other.HashPII("email", "alice@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+
+	_, err = em.HashPII("full_name", "Alice")
+	assert.ErrorIs(t, err, ErrNotSearchablePII)
+	assert.ErrorIs(t, em.SetPIIIndexKey(bytes.Repeat([]byte{0x11}, 32), "plan"), ErrNotSearchablePII)
+	assert.Error(t, em.SetPIIIndexKey([]byte("short")))
+
+	unindexed := NewEncryptionManager(&observability.Logger{}, &EncryptionConfig{Algorithm: "AES-256-GCM"})
+	_, err = unindexed.EncryptAndIndexPII(map[string]interface{}{"email": "alice@example.com"})
+	assert.ErrorIs(t, err, ErrPIIIndexNotConfigured)
+}
+
+func TestPrivacyManager_PIILookupAndExport(t *testing.T) {
+	ctx := context.Background()
+	em := newPIIEncryptionManager(t)
+	auditor := newEscrowAuditor()
+	em.SetAuditor(auditor)
+
+	pm := NewPrivacyManager(em.logger, &PrivacyConfig{EnableDataPortability: true, ConsentExpirationPeriod: time.Hour}, em)
+	alice, bob := uuid.New(), uuid.New()
+	for userID, email := range map[uuid.UUID]string{alice: "alice@example.com", bob: "bob@example.com"} {
+		_, err := pm.GrantConsent(ctx, userID, []string{"essential"}, "127.0.0.1", "test")
+		require.NoError(t, err)
+		require.NoError(t, pm.ProcessPersonalData(ctx, userID, "profile", "essential", map[string]interface{}{
+			"email": email,
+			"phone": "+1 555 0100",
+		}))
+	}
+
+	found, err := pm.LookupByPII(ctx, "email", "ALICE@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{alice}, found)
+	found, err = pm.LookupByPII(ctx, "phone", "+1 555 0100")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{alice, bob}, found)
+
+	// Rotating the keys and re-encrypting changes the ciphertext but not the hashes
+	before := pm.dataProcessor.GetUserData(alice)["profile"].(map[string]interface{})
+	require.NoError(t, em.RotateKeys())
+	rewrapped, err := pm.RewrapPersonalData(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rewrapped)
+	after := pm.dataProcessor.GetUserData(alice)["profile"].(map[string]interface{})
+	assert.NotEqual(t, before["email"], after["email"])
+	assert.Equal(t, before["email_hash"], after["email_hash"])
+
+	found, err = pm.LookupByPII(ctx, "email", "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{alice}, found)
+
+	exported, err := pm.ExportUserData(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"email": "alice@example.com", "phone": "+1 555 0100"}, exported["profile"])
+
+	// The audit log holds the hashes, never the plaintext
+	events, err := auditor.GetAuditEvents(ctx, AuditEventFilter{Resource: "pii"})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	logged, err := json.Marshal(events)
+	require.NoError(t, err)
+	assert.NotContains(t, strings.ToLower(string(logged)), "alice@example.com")
+	assert.NotContains(t, string(logged), "555 0100")
+	assert.Contains(t, string(logged), fmt.Sprint(after["email_hash"]))
+}
+
+func TestBackfillPIIHashes(t *testing.T) {
+	ctx := context.Background()
+	em := newPIIEncryptionManager(t)
+
+	encrypted, err := em.EncryptPII(map[string]interface{}{"email": "carol@example.com"})
+	require.NoError(t, err)
+	envelope, err := json.Marshal(encrypted["email"])
+	require.NoError(t, err)
+
+	table := &memoryPIITable{
+		values: map[string]string{"1": "alice@example.com", "2": "Bob@Example.com", "3": string(envelope)},
+		hashes: map[string]string{},
+	}
+	filled, err := em.BackfillPIIHashes(ctx, table, "email", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, filled)
+
+	for id, email := range map[string]string{"1": "alice@example.com", "2": "bob@example.com", "3": "carol@example.com"} {
+		hash, err := em.HashPII("email", email)
+		require.NoError(t, err)
+		assert.Equal(t, hash, table.hashes[id], "row %s", id)
+	}
+
+	_, err = em.BackfillPIIHashes(ctx, table, "full_name", 10)
+	assert.ErrorIs(t, err, ErrNotSearchablePII)
+}
@@ -3,6 +3,7 @@ package security
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +21,12 @@ type PrivacyManager struct {
 	retentionManager  *RetentionManager
 	anonymizer        *DataAnonymizer
 	mu                sync.RWMutex
+
+	// Right-to-erasure requests, shared by the services owning the user's data
+	erasureStore      ErasureStore
+	erasureOwnerNames []string
+	erasureSigningKey []byte
+	dataOwners        map[string]DataOwner
 }
 
 // PrivacyConfig contains privacy configuration
@@ -162,6 +169,7 @@ func NewPrivacyManager(logger *observability.Logger, config *PrivacyConfig, encr
 		logger:            logger,
 		config:            config,
 		encryptionManager: encryptionManager,
+		dataOwners:        make(map[string]DataOwner),
 	}
 
 	// Initialize components
@@ -198,8 +206,12 @@ func (pm *PrivacyManager) ProcessPersonalData(ctx context.Context, userID uuid.U
 		return fmt.Errorf("purpose not allowed: %s", purpose)
 	}
 
-	// Encrypt PII data
-	encryptedData, err := pm.encryptionManager.EncryptPII(data)
+	// Encrypt PII data, indexing searchable fields when an index key is set
+	encrypt := pm.encryptionManager.EncryptPII
+	if pm.encryptionManager.hasPIIIndex() {
+		encrypt = pm.encryptionManager.EncryptAndIndexPII
+	}
+	encryptedData, err := encrypt(data)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt PII: %w", err)
 	}
@@ -296,22 +308,40 @@ func (pm *PrivacyManager) ExportUserData(ctx context.Context, userID uuid.UUID)
 	// Get all user data
 	userData := pm.dataProcessor.GetUserData(userID)
 
-	// Decrypt PII data for export
+	// Decrypt PII data for export; the audit log only gets the search hashes
 	decryptedData := make(map[string]interface{})
+	hashes := make(map[string]interface{})
 	for key, value := range userData {
-		if encryptedValue, ok := value.(map[string]interface{}); ok {
-			if encryptedData, exists := encryptedValue["encrypted_data"]; exists {
-				// This is encrypted PII data - decrypt it
-				// Implementation would decrypt the data here
-				decryptedData[key] = encryptedData
-			} else {
-				decryptedData[key] = value
-			}
-		} else {
+		record, ok := value.(map[string]interface{})
+		if !ok {
 			decryptedData[key] = value
+			continue
+		}
+		decrypted, err := pm.encryptionManager.DecryptPII(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", key, err)
+		}
+		decryptedData[key] = decrypted
+		for field, hash := range record {
+			if base, isHash := strings.CutSuffix(field, "_hash"); isHash && pm.encryptionManager.isPIIField(base) {
+				hashes[key+"."+field] = hash
+			}
 		}
 	}
 
+	if err := pm.encryptionManager.audit(ctx, userID.String(), &AuditEvent{
+		Severity: AuditSeverityHigh,
+		Resource: "pii",
+		Action:   "pii_export",
+		Result:   AuditResultSuccess,
+		Details: map[string]interface{}{
+			"data_types": len(userData),
+			"hashes":     hashes,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
 	pm.logger.Info(ctx, "User data exported", map[string]interface{}{
 		"user_id": userID,
 	})
@@ -319,6 +349,39 @@ func (pm *PrivacyManager) ExportUserData(ctx context.Context, userID uuid.UUID)
 	return decryptedData, nil
 }
 
+// LookupByPII finds the users with processed data whose searchable field holds value
+func (pm *PrivacyManager) LookupByPII(ctx context.Context, field string, value interface{}) ([]uuid.UUID, error) {
+	ids, err := pm.encryptionManager.LookupByPII(ctx, pm.dataProcessor, field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %q: %w", id, err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+// RewrapPersonalData re-encrypts processed personal data with the active PII key after the
+// encryption keys are rotated, leaving search hashes unchanged, and returns how many records
+// were re-encrypted
+func (pm *PrivacyManager) RewrapPersonalData(ctx context.Context) (int, error) {
+	rewrapped, err := pm.dataProcessor.RewrapRecords(pm.encryptionManager)
+	if err != nil {
+		return rewrapped, err
+	}
+
+	pm.logger.Info(ctx, "Personal data re-encrypted", map[string]interface{}{
+		"records": rewrapped,
+	})
+	return rewrapped, nil
+}
+
 // DeleteUserData deletes all user data (right to erasure)
 func (pm *PrivacyManager) DeleteUserData(ctx context.Context, userID uuid.UUID) error {
 	if !pm.config.EnableRightToErasure {
@@ -511,6 +574,48 @@ func (dp *DataProcessor) GetUserData(userID uuid.UUID) map[string]interface{} {
 	return userData
 }
 
+// FindByPIIHash returns the IDs of the users with an active record carrying hash in hashColumn
+func (dp *DataProcessor) FindByPIIHash(ctx context.Context, hashColumn, hash string) ([]string, error) {
+	dp.mu.RLock()
+	defer dp.mu.RUnlock()
+
+	seen := make(map[uuid.UUID]bool)
+	var ids []string
+	for _, record := range dp.processingRecords {
+		if record.Status != ProcessingStatusActive || seen[record.UserID] {
+			continue
+		}
+		if stored, _ := record.Metadata[hashColumn].(string); stored == hash {
+			seen[record.UserID] = true
+			ids = append(ids, record.UserID.String())
+		}
+	}
+	return ids, nil
+}
+
+// RewrapRecords re-encrypts the PII of active records encrypted with an older key and returns
+// how many records changed
+func (dp *DataProcessor) RewrapRecords(em *EncryptionManager) (int, error) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+
+	rewrapped := 0
+	for _, record := range dp.processingRecords {
+		if record.Status != ProcessingStatusActive {
+			continue
+		}
+		metadata, changed, err := em.RewrapPII(record.Metadata)
+		if err != nil {
+			return rewrapped, fmt.Errorf("failed to re-encrypt record %s: %w", record.RecordID, err)
+		}
+		if changed {
+			record.Metadata = metadata
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
+
 // DeleteUserData deletes all data for a user
 func (dp *DataProcessor) DeleteUserData(userID uuid.UUID) error {
 	dp.mu.Lock()
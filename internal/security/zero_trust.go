@@ -25,9 +25,23 @@ type ZeroTrustEngine struct {
 	policyEngine     *PolicyEngine
 	sessionManager   *ZeroTrustSessionManager
 	riskCalculator   *RiskCalculator
+	loginRisk        LoginRiskSource
+	loginAnomaly     LoginAnomalySource
 	mu               sync.RWMutex
 }
 
+// LoginRiskSource reports how much recent failed logins for a user or IP address add to the
+// risk of a session, from 0 to 1
+type LoginRiskSource interface {
+	FailedLoginRisk(ctx context.Context, userID *uuid.UUID, ipAddress string) (float64, error)
+}
+
+// LoginAnomalySource scores how unusual a request's location, device and time are for its
+// user, from 0 to 1
+type LoginAnomalySource interface {
+	LoginAnomalyRisk(ctx context.Context, request *AccessRequest) (float64, error)
+}
+
 // ZeroTrustConfig contains zero-trust configuration
 type ZeroTrustConfig struct {
 	EnableDeviceFingerprinting bool
@@ -77,12 +91,12 @@ type UserBehaviorProfile struct {
 
 // Location represents a geographical location
 type Location struct {
-	Country   string
-	Region    string
-	City      string
-	Latitude  float64
-	Longitude float64
-	IPRange   string
+	Country   string  `json:"country"`
+	Region    string  `json:"region,omitempty"`
+	City      string  `json:"city,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	IPRange   string  `json:"ip_range,omitempty"`
 }
 
 // ZeroTrustThreatDetector detects security threats
@@ -260,6 +274,21 @@ func NewZeroTrustEngine(logger *observability.Logger) *ZeroTrustEngine {
 	}
 }
 
+// SetLoginRiskSource adds recent failed login velocity to the threat level of access requests
+func (z *ZeroTrustEngine) SetLoginRiskSource(source LoginRiskSource) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.loginRisk = source
+}
+
+// SetLoginAnomalySource raises the risk score of access requests to their login anomaly
+// score, so anomalous sessions get shorter TTLs and require MFA
+func (z *ZeroTrustEngine) SetLoginAnomalySource(source LoginAnomalySource) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.loginAnomaly = source
+}
+
 // EvaluateAccess performs zero-trust access evaluation
 func (z *ZeroTrustEngine) EvaluateAccess(ctx context.Context, request *AccessRequest) (*AccessDecision, error) {
 	// 1. Device Trust Evaluation
@@ -291,6 +320,7 @@ func (z *ZeroTrustEngine) EvaluateAccess(ctx context.Context, request *AccessReq
 		Action:       request.Action,
 		Timestamp:    request.Timestamp,
 	})
+	riskScore = max(riskScore, z.evaluateLoginAnomaly(ctx, request))
 
 	// 5. Policy Evaluation
 	userID := uuid.Nil
@@ -427,9 +457,45 @@ func (z *ZeroTrustEngine) evaluateThreatLevel(ctx context.Context, request *Acce
 	// Check for active threats
 	threatLevel := z.threatDetector.EvaluateThreats(ctx, request)
 
+	// Recent failed logins against the user or from the IP address raise the threat level,
+	// shortening the session
+	z.mu.RLock()
+	loginRisk := z.loginRisk
+	z.mu.RUnlock()
+	if loginRisk != nil {
+		risk, err := loginRisk.FailedLoginRisk(ctx, request.UserID, request.IPAddress)
+		if err != nil {
+			z.logger.Warn(ctx, "Failed to get failed login risk", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			threatLevel = min(1.0, threatLevel+risk)
+		}
+	}
+
 	return threatLevel, nil
 }
 
+// evaluateLoginAnomaly scores the request against the user's login history. An unavailable
+// history adds no risk.
+func (z *ZeroTrustEngine) evaluateLoginAnomaly(ctx context.Context, request *AccessRequest) float64 {
+	z.mu.RLock()
+	loginAnomaly := z.loginAnomaly
+	z.mu.RUnlock()
+	if loginAnomaly == nil || request.UserID == nil {
+		return 0
+	}
+
+	risk, err := loginAnomaly.LoginAnomalyRisk(ctx, request)
+	if err != nil {
+		z.logger.Warn(ctx, "Failed to get login anomaly risk", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0
+	}
+	return risk
+}
+
 // generateDeviceFingerprint creates a unique device fingerprint
 func (z *ZeroTrustEngine) generateDeviceFingerprint(request *AccessRequest) string {
 	// Combine various device attributes to create fingerprint
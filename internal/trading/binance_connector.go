@@ -0,0 +1,271 @@
+package trading
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BinanceConnector places orders on Binance spot with per-user credentials through the signed
+// REST API
+type BinanceConnector struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewBinanceConnector creates a connector for the Binance REST API at baseURL, such as
+// https://api.binance.com or the testnet
+func NewBinanceConnector(baseURL string) *BinanceConnector {
+	return &BinanceConnector{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns "binance"
+func (c *BinanceConnector) Name() string {
+	return "binance"
+}
+
+// FetchBalances returns the account's non-zero spot balances
+func (c *BinanceConnector) FetchBalances(ctx context.Context, credentials ExchangeCredentials) ([]ExchangeBalance, error) {
+	var account struct {
+		Balances []struct {
+			Asset  string          `json:"asset"`
+			Free   decimal.Decimal `json:"free"`
+			Locked decimal.Decimal `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := c.signedRequest(ctx, credentials, http.MethodGet, "/api/v3/account", url.Values{}, &account); err != nil {
+		return nil, err
+	}
+
+	balances := make([]ExchangeBalance, 0)
+	for _, balance := range account.Balances {
+		if balance.Free.IsZero() && balance.Locked.IsZero() {
+			continue
+		}
+		balances = append(balances, ExchangeBalance{Asset: balance.Asset, Free: balance.Free, Locked: balance.Locked})
+	}
+	return balances, nil
+}
+
+// PlaceOrder places a market order, or a limit order that is immediately filled or cancelled,
+// and returns its volume-weighted fill. Fees are summed as reported, in whichever asset
+// Binance charged them.
+func (c *BinanceConnector) PlaceOrder(ctx context.Context, credentials ExchangeCredentials, order *BotOrder) (*BotTrade, error) {
+	params := url.Values{}
+	params.Set("symbol", binanceSymbol(order.Symbol))
+	params.Set("side", strings.ToUpper(string(order.Side)))
+	params.Set("type", "MARKET")
+	if order.Type == OrderTypeLimit {
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", "IOC")
+		params.Set("price", order.LimitPrice.String())
+	}
+	params.Set("quantity", order.Quantity.String())
+	params.Set("newOrderRespType", "FULL")
+	if order.ClientOrderID != "" {
+		params.Set("newClientOrderId", order.ClientOrderID)
+	}
+
+	var response struct {
+		OrderID             int64           `json:"orderId"`
+		ExecutedQty         decimal.Decimal `json:"executedQty"`
+		CummulativeQuoteQty decimal.Decimal `json:"cummulativeQuoteQty"`
+		TransactTime        int64           `json:"transactTime"`
+		Fills               []struct {
+			Commission decimal.Decimal `json:"commission"`
+		} `json:"fills"`
+	}
+	if err := c.signedRequest(ctx, credentials, http.MethodPost, "/api/v3/order", params, &response); err != nil {
+		return nil, err
+	}
+	if !response.ExecutedQty.IsPositive() {
+		return nil, fmt.Errorf("binance order %d was not filled", response.OrderID)
+	}
+
+	fee := decimal.Zero
+	for _, fill := range response.Fills {
+		fee = fee.Add(fill.Commission)
+	}
+
+	return &BotTrade{
+		ID:         uuid.New().String(),
+		OrderID:    strconv.FormatInt(response.OrderID, 10),
+		Symbol:     order.Symbol,
+		Side:       order.Side,
+		Quantity:   response.ExecutedQty,
+		FillPrice:  response.CummulativeQuoteQty.Div(response.ExecutedQty),
+		Fee:        fee,
+		ExecutedAt: time.UnixMilli(response.TransactTime).UTC(),
+	}, nil
+}
+
+// binanceOrder is an order as returned by the order query endpoints
+type binanceOrder struct {
+	Symbol              string          `json:"symbol"`
+	OrderID             int64           `json:"orderId"`
+	ClientOrderID       string          `json:"clientOrderId"`
+	OrigQty             decimal.Decimal `json:"origQty"`
+	ExecutedQty         decimal.Decimal `json:"executedQty"`
+	CummulativeQuoteQty decimal.Decimal `json:"cummulativeQuoteQty"`
+	Status              string          `json:"status"`
+	Side                string          `json:"side"`
+	UpdateTime          int64           `json:"updateTime"`
+}
+
+// OpenOrders returns the account's open spot orders on every symbol
+func (c *BinanceConnector) OpenOrders(ctx context.Context, credentials ExchangeCredentials) ([]ExchangeOrder, error) {
+	var orders []binanceOrder
+	if err := c.signedRequest(ctx, credentials, http.MethodGet, "/api/v3/openOrders", url.Values{}, &orders); err != nil {
+		return nil, err
+	}
+	return convertBinanceOrders(orders, ""), nil
+}
+
+const (
+	// binanceOrderPageSize is the most orders allOrders returns per request
+	binanceOrderPageSize = 1000
+	// binanceMaxOrderPages bounds the requests made for one symbol's recent orders
+	binanceMaxOrderPages = 10
+)
+
+// RecentOrders returns the symbol's orders created since the time. allOrders returns a page
+// of orders oldest first, so each later page starts after the last order ID seen. Windows
+// holding more than binanceMaxOrderPages pages fail rather than return a partial history.
+func (c *BinanceConnector) RecentOrders(ctx context.Context, credentials ExchangeCredentials, symbol string, since time.Time) ([]ExchangeOrder, error) {
+	var all []binanceOrder
+	for page := 0; page < binanceMaxOrderPages; page++ {
+		params := url.Values{}
+		params.Set("symbol", binanceSymbol(symbol))
+		if len(all) == 0 {
+			params.Set("startTime", strconv.FormatInt(since.UnixMilli(), 10))
+		} else {
+			params.Set("orderId", strconv.FormatInt(all[len(all)-1].OrderID+1, 10))
+		}
+		params.Set("limit", strconv.Itoa(binanceOrderPageSize))
+
+		var orders []binanceOrder
+		if err := c.signedRequest(ctx, credentials, http.MethodGet, "/api/v3/allOrders", params, &orders); err != nil {
+			return nil, err
+		}
+		all = append(all, orders...)
+		if len(orders) < binanceOrderPageSize {
+			return convertBinanceOrders(all, symbol), nil
+		}
+	}
+	return nil, fmt.Errorf("binance has more than %d %s orders since %s", binanceOrderPageSize*binanceMaxOrderPages,
+		binanceSymbol(symbol), since.UTC().Format(time.RFC3339))
+}
+
+// convertBinanceOrders converts Binance orders, naming them by symbol when it is known
+func convertBinanceOrders(orders []binanceOrder, symbol string) []ExchangeOrder {
+	converted := make([]ExchangeOrder, len(orders))
+	for i, order := range orders {
+		averagePrice := decimal.Zero
+		if order.ExecutedQty.IsPositive() {
+			averagePrice = order.CummulativeQuoteQty.Div(order.ExecutedQty)
+		}
+		name := symbol
+		if name == "" {
+			name = order.Symbol
+		}
+		converted[i] = ExchangeOrder{
+			OrderID:        strconv.FormatInt(order.OrderID, 10),
+			ClientOrderID:  order.ClientOrderID,
+			Symbol:         name,
+			Side:           OrderSide(strings.ToLower(order.Side)),
+			Status:         binanceOrderStatus(order.Status),
+			Quantity:       order.OrigQty,
+			FilledQuantity: order.ExecutedQty,
+			AveragePrice:   averagePrice,
+			UpdatedAt:      time.UnixMilli(order.UpdateTime).UTC(),
+		}
+	}
+	return converted
+}
+
+// binanceOrderStatus maps Binance order statuses to exchange order statuses
+func binanceOrderStatus(status string) ExchangeOrderStatus {
+	switch status {
+	case "NEW", "PARTIALLY_FILLED", "PENDING_NEW":
+		return ExchangeOrderOpen
+	case "FILLED":
+		return ExchangeOrderFilled
+	case "REJECTED":
+		return ExchangeOrderRejected
+	case "EXPIRED", "EXPIRED_IN_MATCH":
+		return ExchangeOrderExpired
+	default:
+		return ExchangeOrderCanceled
+	}
+}
+
+// signedRequest sends a request signed with the credentials and decodes the JSON response
+// into out. Binance error messages are returned; the credentials never are.
+func (c *BinanceConnector) signedRequest(ctx context.Context, credentials ExchangeCredentials, method, endpoint string, params url.Values, out interface{}) error {
+	params.Set("recvWindow", "5000")
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	mac := hmac.New(sha256.New, []byte(credentials.APISecret))
+	mac.Write([]byte(params.Encode()))
+	params.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+
+	var req *http.Request
+	var err error
+	if method == http.MethodGet {
+		req, err = http.NewRequestWithContext(ctx, method, c.baseURL+endpoint+"?"+params.Encode(), nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-MBX-APIKEY", credentials.APIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("binance request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read binance response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Msg != "" {
+			return fmt.Errorf("binance error %d: %s", apiErr.Code, apiErr.Msg)
+		}
+		return fmt.Errorf("binance returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode binance response: %w", err)
+	}
+	return nil
+}
+
+// binanceSymbol converts pairs such as "BTC/USDT" to Binance's "BTCUSDT"
+func binanceSymbol(symbol string) string {
+	return strings.ToUpper(strings.NewReplacer("/", "", "-", "", "_", "").Replace(symbol))
+}
@@ -0,0 +1,65 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// binanceOrderServer serves allOrders from a history of count orders with IDs from 1
+func binanceOrderServer(t *testing.T, count int64, queries *[]map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/allOrders", r.URL.Path)
+		query := r.URL.Query()
+		*queries = append(*queries, map[string]string{"startTime": query.Get("startTime"), "orderId": query.Get("orderId")})
+
+		first := int64(1)
+		if query.Get("orderId") != "" {
+			first, _ = strconv.ParseInt(query.Get("orderId"), 10, 64)
+		}
+		limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+		orders := make([]binanceOrder, 0)
+		for id := first; id <= count && int64(len(orders)) < limit; id++ {
+			orders = append(orders, binanceOrder{Symbol: "BTCUSDT", OrderID: id, ClientOrderID: "bot-" + strconv.FormatInt(id, 10), Status: "FILLED", Side: "BUY"})
+		}
+		_ = json.NewEncoder(w).Encode(orders)
+	}))
+}
+
+func TestBinanceConnector_RecentOrdersPagesByOrderID(t *testing.T) {
+	var queries []map[string]string
+	server := binanceOrderServer(t, 2500, &queries)
+	defer server.Close()
+
+	since := time.Now().Add(-24 * time.Hour)
+	orders, err := NewBinanceConnector(server.URL).RecentOrders(context.Background(), ExchangeCredentials{APIKey: "key", APISecret: "secret"}, "BTC/USDT", since)
+	require.NoError(t, err)
+	require.Len(t, orders, 2500)
+	assert.Equal(t, "1", orders[0].OrderID)
+	assert.Equal(t, "2500", orders[2499].OrderID)
+	assert.Equal(t, "BTC/USDT", orders[0].Symbol)
+
+	require.Len(t, queries, 3)
+	assert.Equal(t, strconv.FormatInt(since.UnixMilli(), 10), queries[0]["startTime"])
+	assert.Empty(t, queries[0]["orderId"])
+	assert.Equal(t, map[string]string{"startTime": "", "orderId": "1001"}, queries[1])
+	assert.Equal(t, map[string]string{"startTime": "", "orderId": "2001"}, queries[2])
+}
+
+func TestBinanceConnector_RecentOrdersFailsBeyondPageLimit(t *testing.T) {
+	var queries []map[string]string
+	server := binanceOrderServer(t, binanceOrderPageSize*binanceMaxOrderPages+1, &queries)
+	defer server.Close()
+
+	_, err := NewBinanceConnector(server.URL).RecentOrders(context.Background(), ExchangeCredentials{APIKey: "key", APISecret: "secret"}, "BTC/USDT", time.Now().Add(-time.Hour))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than 10000 BTCUSDT orders")
+	assert.Len(t, queries, binanceMaxOrderPages)
+}
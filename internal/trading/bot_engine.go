@@ -2,15 +2,25 @@ package trading
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/internal/web3"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+// ErrBotNotFound is returned for unknown bot IDs
+var ErrBotNotFound = errors.New("bot not found")
+
+// ErrBotNotActive is returned when pausing or resuming a bot that is not started
+var ErrBotNotActive = errors.New("bot is not active")
+
 // TradingBotEngine manages multiple trading bot instances
 type TradingBotEngine struct {
 	logger           *observability.Logger
@@ -19,6 +29,24 @@ type TradingBotEngine struct {
 	portfolioManager *PortfolioManager
 	riskManager      *BotRiskManager
 	exchangeManager  *ExchangeManager
+	priceFeed        PriceFeed
+
+	// Bots skip opening positions during the calendar's blackouts
+	calendar *web3.TradingCalendar
+
+	// Why no bot may open positions, such as maintenance; empty while openings are allowed
+	openingsHalted string
+
+	// DCA strategies restore and save their filled rungs, grid strategies their levels
+	dcaRungs DCARungStore
+	grids    GridStore
+
+	// Bots created through the API are restored from the store after a restart
+	botStore BotStore
+
+	// Event listeners notified of state changes and fills
+	listeners   []BotEventListener
+	listenersMu sync.RWMutex
 
 	// State management
 	isRunning bool
@@ -46,8 +74,21 @@ type TradingBot struct {
 	State       BotState        `json:"state"`
 	Performance *BotPerformance `json:"performance"`
 	RiskProfile *BotRiskProfile `json:"risk_profile"`
+	Mode        ExecutionMode   `json:"mode"`
+	CreatedAt   time.Time       `json:"created_at"`
+
+	// Live orders go through the owner's exchange connection, resolved per order. LastError
+	// explains why the bot was stopped, such as its connection being revoked.
+	UserID       uuid.UUID `json:"user_id,omitempty"`
+	ConnectionID uuid.UUID `json:"connection_id,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
 
 	// Runtime state
+	strategy      strategies.TradingStrategy
+	paper         *PaperAccount
+	trades        []*BotTrade
+	costBasis     map[string]*CostBasis
+	stats         BotExecutionStats
 	isActive      bool
 	lastExecution time.Time
 	errorCount    int
@@ -87,6 +128,14 @@ type BotConfig struct {
 	StrategyParams map[string]interface{} `yaml:"strategy_params"`
 	Capital        *CapitalConfig         `yaml:"capital"`
 	Enabled        bool                   `yaml:"enabled"`
+	Mode           ExecutionMode          `yaml:"mode"`  // defaults to paper
+	Paper          *PaperTradingConfig    `yaml:"paper"` // fill assumptions for paper mode
+
+	// Buys are only placed within the schedule; sells are always allowed
+	Schedule *web3.TradingSchedule `yaml:"schedule"`
+
+	// The user who created the bot through the API; bots loaded from configuration have none
+	OwnerID uuid.UUID `yaml:"-"`
 }
 
 // CapitalConfig defines capital allocation for a bot
@@ -112,9 +161,13 @@ func NewPortfolioManager(logger *observability.Logger) *PortfolioManager {
 	}
 }
 
-// ExchangeManager manages exchange connections and operations
+// ExchangeManager routes live orders to exchange connectors
 type ExchangeManager struct {
-	logger *observability.Logger
+	logger      *observability.Logger
+	connectors  map[string]ExchangeConnector
+	credentials CredentialsProvider
+	orders      LiveOrderStore
+	mu          sync.RWMutex
 }
 
 // NewExchangeManager creates a new exchange manager
@@ -124,6 +177,88 @@ func NewExchangeManager(logger *observability.Logger) *ExchangeManager {
 	}
 }
 
+// configure sets the connectors orders are routed to and the provider of their credentials
+func (em *ExchangeManager) configure(credentials CredentialsProvider, connectors []ExchangeConnector) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.credentials = credentials
+	em.connectors = connectorsByName(connectors)
+}
+
+// PlaceOrder sends a bot's order to an exchange with the credentials of the connection. With
+// a live order store the order is journaled before it is sent and updated with the outcome;
+// an order that cannot be journaled is not sent.
+func (em *ExchangeManager) PlaceOrder(ctx context.Context, botID, exchange string, connectionID uuid.UUID, order *BotOrder, marketPrice decimal.Decimal) (*BotTrade, error) {
+	em.mu.RLock()
+	connector := em.connectors[strings.ToLower(exchange)]
+	credentials := em.credentials
+	journal := em.orders
+	em.mu.RUnlock()
+
+	if connector == nil || credentials == nil {
+		return nil, fmt.Errorf("no exchange connector configured for %s", exchange)
+	}
+	if connectionID == uuid.Nil {
+		return nil, ErrNoExchangeConnection
+	}
+
+	creds, err := credentials.Credentials(ctx, connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var live *LiveOrder
+	if journal != nil {
+		live = newLiveOrder(botID, exchange, connectionID, order, marketPrice)
+		if err := journal.SaveLiveOrder(ctx, live); err != nil {
+			return nil, fmt.Errorf("failed to journal %s order: %w", exchange, err)
+		}
+		journaled := *order
+		journaled.ClientOrderID = live.ClientOrderID
+		order = &journaled
+	}
+
+	trade, err := connector.PlaceOrder(ctx, creds, order)
+	if live != nil {
+		// The outcome is journaled even when the request was canceled meanwhile
+		live.settle(trade, err)
+		if saveErr := journal.SaveLiveOrder(context.WithoutCancel(ctx), live); saveErr != nil {
+			em.logger.Error(ctx, "Failed to journal live order outcome", saveErr, map[string]interface{}{
+				"bot_id":   botID,
+				"order_id": live.ID,
+				"status":   string(live.Status),
+			})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s order failed: %w", exchange, err)
+	}
+	trade.MarketPrice = marketPrice
+	return trade, nil
+}
+
+// orderReader returns the order reader of an exchange's connector and the connection's
+// credentials
+func (em *ExchangeManager) orderReader(ctx context.Context, exchange string, connectionID uuid.UUID) (ExchangeOrderReader, ExchangeCredentials, error) {
+	em.mu.RLock()
+	connector := em.connectors[strings.ToLower(exchange)]
+	credentials := em.credentials
+	em.mu.RUnlock()
+
+	if connector == nil || credentials == nil {
+		return nil, ExchangeCredentials{}, fmt.Errorf("no exchange connector configured for %s", exchange)
+	}
+	reader, ok := connector.(ExchangeOrderReader)
+	if !ok {
+		return nil, ExchangeCredentials{}, fmt.Errorf("%s orders cannot be reconciled", exchange)
+	}
+	creds, err := credentials.Credentials(ctx, connectionID)
+	if err != nil {
+		return nil, ExchangeCredentials{}, err
+	}
+	return reader, creds, nil
+}
+
 // BotPerformance tracks bot performance metrics
 type BotPerformance struct {
 	TotalTrades   int             `json:"total_trades"`
@@ -136,6 +271,9 @@ type BotPerformance struct {
 	MaxDrawdown   decimal.Decimal `json:"max_drawdown"`
 	SharpeRatio   decimal.Decimal `json:"sharpe_ratio"`
 	LastUpdated   time.Time       `json:"last_updated"`
+
+	// Buy signals skipped outside the bot's schedule or during a blackout
+	SuppressedBySchedule int `json:"suppressed_by_schedule"`
 }
 
 // NewTradingBotEngine creates a new trading bot engine
@@ -207,24 +345,80 @@ func (tbe *TradingBotEngine) Stop(ctx context.Context) error {
 
 // RegisterBot registers a new trading bot
 func (tbe *TradingBotEngine) RegisterBot(ctx context.Context, botConfig *BotConfig, strategy BotStrategy) (*TradingBot, error) {
+	bot, err := tbe.registerBot(ctx, &BotRecord{
+		ID:        uuid.New().String(),
+		Strategy:  strategy,
+		Config:    botConfig,
+		UserID:    botConfig.OwnerID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bot.mu.RLock()
+	record := bot.record()
+	bot.mu.RUnlock()
+	tbe.saveBot(ctx, record)
+
+	return bot, nil
+}
+
+// registerBot adds a new or restored bot, named after its strategy when it has no name
+func (tbe *TradingBotEngine) registerBot(ctx context.Context, record *BotRecord) (*TradingBot, error) {
+	botConfig, strategy := record.Config, record.Strategy
+	mode, err := ParseExecutionMode(string(botConfig.Mode))
+	if err != nil {
+		return nil, err
+	}
+	botConfig.Mode = mode
+	if botConfig.Schedule != nil {
+		if err := botConfig.Schedule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if botConfig.Paper == nil {
+		botConfig.Paper = DefaultPaperTradingConfig()
+	}
+	initialBalance := decimal.Zero
+	if botConfig.Capital != nil {
+		initialBalance = botConfig.Capital.InitialBalance
+	}
+
 	tbe.mu.Lock()
 	defer tbe.mu.Unlock()
 
+	name := record.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-bot-%d", strategy, len(tbe.bots)+1)
+	}
 	bot := &TradingBot{
-		ID:          uuid.New().String(),
-		Name:        fmt.Sprintf("%s-bot-%d", strategy, len(tbe.bots)+1),
-		Strategy:    strategy,
-		Config:      botConfig,
-		State:       StateIdle,
-		Performance: &BotPerformance{LastUpdated: time.Now()},
-		RiskProfile: &BotRiskProfile{},
-		stopChan:    make(chan struct{}),
+		ID:           record.ID,
+		Name:         name,
+		Strategy:     strategy,
+		Config:       botConfig,
+		State:        StateIdle,
+		Performance:  &BotPerformance{LastUpdated: time.Now()},
+		RiskProfile:  &BotRiskProfile{},
+		Mode:         mode,
+		CreatedAt:    record.CreatedAt,
+		UserID:       record.UserID,
+		ConnectionID: record.ConnectionID,
+		paper:        newPaperAccount(botConfig.Paper, initialBalance),
+		stopChan:     make(chan struct{}),
 	}
 
 	tbe.bots[bot.ID] = bot
 
+	// The strategy's default limits apply until a template or change is assigned
+	if err := tbe.riskManager.RegisterBot(bot.ID, string(strategy), nil); err != nil {
+		delete(tbe.bots, bot.ID)
+		return nil, fmt.Errorf("failed to register bot with risk manager: %w", err)
+	}
+
 	tbe.logger.Info(ctx, "Bot registered", map[string]interface{}{
 		"bot_id":   bot.ID,
+		"mode":     string(mode),
 		"strategy": string(strategy),
 		"pairs":    botConfig.TradingPairs,
 		"exchange": botConfig.Exchange,
@@ -240,22 +434,36 @@ func (tbe *TradingBotEngine) StartBot(ctx context.Context, botID string) error {
 
 	bot, exists := tbe.bots[botID]
 	if !exists {
-		return fmt.Errorf("bot not found: %s", botID)
+		return fmt.Errorf("%w: %s", ErrBotNotFound, botID)
 	}
 
 	if bot.isActive {
 		return fmt.Errorf("bot is already active: %s", botID)
 	}
 
+	bot.mu.RLock()
+	unconnected := bot.Mode == ExecutionModeLive && bot.ConnectionID == uuid.Nil
+	bot.mu.RUnlock()
+	if unconnected {
+		return fmt.Errorf("%w: attach one to bot %s before starting it", ErrNoExchangeConnection, botID)
+	}
+
 	// Check if we can start more bots
 	activeBots := tbe.getActiveBots()
 	if len(activeBots) >= tbe.config.MaxConcurrentBots {
 		return fmt.Errorf("maximum concurrent bots reached: %d", tbe.config.MaxConcurrentBots)
 	}
 
+	bot.mu.Lock()
+	previous := bot.State
 	bot.isActive = true
 	bot.State = StateRunning
+	bot.LastError = ""
 	bot.lastExecution = time.Now()
+	bot.stopChan = make(chan struct{})
+	bot.mu.Unlock()
+
+	tbe.notifyStateChange(botID, previous, StateRunning, "started")
 
 	tbe.logger.Info(ctx, "Bot started", map[string]interface{}{
 		"bot_id":   botID,
@@ -277,16 +485,21 @@ func (tbe *TradingBotEngine) StopBot(ctx context.Context, botID string) error {
 func (tbe *TradingBotEngine) stopBot(ctx context.Context, botID string) error {
 	bot, exists := tbe.bots[botID]
 	if !exists {
-		return fmt.Errorf("bot not found: %s", botID)
+		return fmt.Errorf("%w: %s", ErrBotNotFound, botID)
 	}
 
 	if !bot.isActive {
 		return nil
 	}
 
+	bot.mu.Lock()
+	previous := bot.State
 	bot.isActive = false
 	bot.State = StateStopped
 	close(bot.stopChan)
+	bot.mu.Unlock()
+
+	tbe.notifyStateChange(botID, previous, StateStopped, "stopped")
 
 	tbe.logger.Info(ctx, "Bot stopped", map[string]interface{}{
 		"bot_id": botID,
@@ -302,7 +515,7 @@ func (tbe *TradingBotEngine) GetBot(botID string) (*TradingBot, error) {
 
 	bot, exists := tbe.bots[botID]
 	if !exists {
-		return nil, fmt.Errorf("bot not found: %s", botID)
+		return nil, fmt.Errorf("%w: %s", ErrBotNotFound, botID)
 	}
 
 	return bot, nil
@@ -364,16 +577,107 @@ func (tbe *TradingBotEngine) executeAllBots(ctx context.Context) {
 
 // executeBot executes trading logic for a single bot
 func (tbe *TradingBotEngine) executeBot(ctx context.Context, bot *TradingBot) {
+	tbe.mu.RLock()
+	priceFeed := tbe.priceFeed
+	calendar := tbe.calendar
+	openingsHalted := tbe.openingsHalted
+	tbe.mu.RUnlock()
+
 	bot.mu.Lock()
 	defer bot.mu.Unlock()
 
-	// Implementation will be added in strategy-specific files
 	tbe.logger.Debug(ctx, "Executing bot", map[string]interface{}{
 		"bot_id":   bot.ID,
+		"mode":     string(bot.Mode),
 		"strategy": string(bot.Strategy),
 	})
 
 	bot.lastExecution = time.Now()
+
+	if bot.strategy == nil || priceFeed == nil || bot.State == StatePaused {
+		return
+	}
+
+	for _, pair := range bot.Config.TradingPairs {
+		price, err := priceFeed.GetPrice(ctx, pair)
+		if err != nil {
+			tbe.logger.Warn(ctx, "No market price for bot pair", map[string]interface{}{
+				"bot_id": bot.ID,
+				"pair":   pair,
+				"error":  err.Error(),
+			})
+			continue
+		}
+		bot.paper.mark(pair, price)
+		tbe.updateDrawdown(bot)
+
+		result, err := bot.strategy.Execute(ctx, &strategies.MarketData{
+			Symbol:    pair,
+			Price:     price,
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			bot.errorCount++
+			tbe.logger.Error(ctx, "Bot strategy execution failed", err, map[string]interface{}{
+				"bot_id": bot.ID,
+				"pair":   pair,
+			})
+			tbe.setExecutionState(bot, StateError, err.Error())
+			continue
+		}
+		tbe.setExecutionState(bot, StateRunning, "recovered")
+
+		observer, _ := bot.strategy.(strategies.OrderObserver)
+		for _, signal := range strategies.Signals(result) {
+			if signal.Action == strategies.ActionHold || !signal.Amount.IsPositive() {
+				continue
+			}
+			side := OrderSideBuy
+			if signal.Action == strategies.ActionSell {
+				side = OrderSideSell
+			}
+			if side == OrderSideBuy {
+				reason := openingsHalted
+				if reason == "" {
+					reason = calendar.Suppression(ctx, bot.Config.Schedule, pair, time.Now())
+				}
+				if reason != "" {
+					bot.Performance.SuppressedBySchedule++
+					if observer != nil {
+						observer.OrderRejected(signal.ID, reason)
+					}
+					tbe.logger.Info(ctx, "Bot signal suppressed by trading schedule", map[string]interface{}{
+						"bot_id":  bot.ID,
+						"pair":    pair,
+						"reason":  reason,
+						"outcome": web3.SuppressedBySchedule,
+					})
+					continue
+				}
+			}
+			order := &BotOrder{Symbol: pair, Side: side, Quantity: signal.Amount}
+			if signal.OrderType == strategies.OrderTypeLimit {
+				order.Type = OrderTypeLimit
+				order.LimitPrice = signal.Price
+			}
+			trade, err := tbe.executeOrder(ctx, bot, order, price)
+			if err != nil {
+				tbe.logger.Warn(ctx, "Bot order failed", map[string]interface{}{
+					"bot_id": bot.ID,
+					"pair":   pair,
+					"side":   string(side),
+					"error":  err.Error(),
+				})
+				if observer != nil {
+					observer.OrderRejected(signal.ID, err.Error())
+				}
+				continue
+			}
+			if observer != nil {
+				observer.OrderFilled(signal.ID, trade.Quantity, trade.FillPrice)
+			}
+		}
+	}
 }
 
 // performanceMonitoringLoop monitors bot performance
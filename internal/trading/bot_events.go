@@ -0,0 +1,52 @@
+package trading
+
+// BotEventListener receives bot state changes and order fills from the engine. Listeners are
+// called synchronously from bot execution, sometimes while the bot is locked, so they must
+// return quickly and must not call back into the engine.
+type BotEventListener interface {
+	// BotStateChanged is called when a bot moves from one state to another
+	BotStateChanged(botID string, from, to BotState, reason string)
+	// BotOrderFilled is called for every filled bot order
+	BotOrderFilled(trade *BotTrade)
+}
+
+// AddEventListener registers a listener for bot state changes and order fills
+func (tbe *TradingBotEngine) AddEventListener(listener BotEventListener) {
+	tbe.listenersMu.Lock()
+	defer tbe.listenersMu.Unlock()
+
+	tbe.listeners = append(tbe.listeners, listener)
+}
+
+// notifyStateChange reports a state transition to all listeners
+func (tbe *TradingBotEngine) notifyStateChange(botID string, from, to BotState, reason string) {
+	tbe.listenersMu.RLock()
+	defer tbe.listenersMu.RUnlock()
+
+	for _, listener := range tbe.listeners {
+		listener.BotStateChanged(botID, from, to, reason)
+	}
+}
+
+// notifyOrderFilled reports a fill to all listeners
+func (tbe *TradingBotEngine) notifyOrderFilled(trade *BotTrade) {
+	tbe.listenersMu.RLock()
+	defer tbe.listenersMu.RUnlock()
+
+	for _, listener := range tbe.listeners {
+		listener.BotOrderFilled(trade)
+	}
+}
+
+// setExecutionState moves an active bot between the running and error states after an
+// execution attempt, notifying listeners only on transitions. Paused bots stay paused until
+// resumed. The caller must hold bot.mu.
+func (tbe *TradingBotEngine) setExecutionState(bot *TradingBot, state BotState, reason string) {
+	if !bot.isActive || bot.State == state || bot.State == StatePaused {
+		return
+	}
+
+	previous := bot.State
+	bot.State = state
+	tbe.notifyStateChange(bot.ID, previous, state, reason)
+}
@@ -0,0 +1,402 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/shopspring/decimal"
+)
+
+// SetPriceFeed sets the live price source used to fill and mark bot orders
+func (tbe *TradingBotEngine) SetPriceFeed(feed PriceFeed) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.priceFeed = feed
+}
+
+// SetTradingCalendar makes bots skip buy signals during the calendar's blackouts
+func (tbe *TradingBotEngine) SetTradingCalendar(calendar *web3.TradingCalendar) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.calendar = calendar
+}
+
+// HaltOpenings stops every bot from opening positions for the given reason until
+// ResumeOpenings is called. Sell signals still execute so positions can be closed.
+func (tbe *TradingBotEngine) HaltOpenings(reason string) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.openingsHalted = reason
+}
+
+// ResumeOpenings lets bots open positions again after HaltOpenings
+func (tbe *TradingBotEngine) ResumeOpenings() {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.openingsHalted = ""
+}
+
+// SetRiskManager sets the risk manager new bots are registered with, so their limits can be
+// managed through it
+func (tbe *TradingBotEngine) SetRiskManager(riskManager *BotRiskManager) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.riskManager = riskManager
+}
+
+// AttachStrategy binds the strategy that generates orders for a bot on each execution cycle
+func (tbe *TradingBotEngine) AttachStrategy(botID string, strategy strategies.TradingStrategy) error {
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return err
+	}
+
+	bot.mu.Lock()
+	bot.strategy = strategy
+	bot.mu.Unlock()
+
+	switch persisted := strategy.(type) {
+	case *strategies.DCAStrategy:
+		tbe.persistDCARungs(context.Background(), botID, persisted)
+	case *strategies.GridStrategy:
+		tbe.persistGrids(context.Background(), botID, persisted)
+	}
+	return nil
+}
+
+// PauseBot suspends a started bot's strategy until it is resumed. The bot keeps its slot,
+// positions and schedule; manual orders are still accepted.
+func (tbe *TradingBotEngine) PauseBot(ctx context.Context, botID string) error {
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return err
+	}
+
+	bot.mu.Lock()
+	if !bot.isActive {
+		bot.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrBotNotActive, botID)
+	}
+	previous := bot.State
+	if previous == StatePaused {
+		bot.mu.Unlock()
+		return nil
+	}
+	bot.State = StatePaused
+	bot.mu.Unlock()
+
+	tbe.notifyStateChange(botID, previous, StatePaused, "paused")
+	tbe.logger.Info(ctx, "Trading bot paused", map[string]interface{}{
+		"bot_id": botID,
+	})
+	return nil
+}
+
+// ResumeBot returns a paused bot to running
+func (tbe *TradingBotEngine) ResumeBot(ctx context.Context, botID string) error {
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return err
+	}
+
+	bot.mu.Lock()
+	if !bot.isActive {
+		bot.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrBotNotActive, botID)
+	}
+	if bot.State != StatePaused {
+		bot.mu.Unlock()
+		return nil
+	}
+	bot.State = StateRunning
+	bot.mu.Unlock()
+
+	tbe.notifyStateChange(botID, StatePaused, StateRunning, "resumed")
+	tbe.logger.Info(ctx, "Trading bot resumed", map[string]interface{}{
+		"bot_id": botID,
+	})
+	return nil
+}
+
+// SetBotMode switches a bot between paper and live execution. A running paper bot is only
+// switched to live when confirmLive is set; switching to paper is always allowed.
+func (tbe *TradingBotEngine) SetBotMode(ctx context.Context, botID string, mode ExecutionMode, confirmLive bool) error {
+	mode, err := ParseExecutionMode(string(mode))
+	if err != nil {
+		return err
+	}
+
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return err
+	}
+
+	// The change is saved once the bot is unlocked, as the store is guarded by the engine
+	var record *BotRecord
+	bot.mu.Lock()
+	defer func() {
+		bot.mu.Unlock()
+		if record != nil {
+			tbe.saveBot(ctx, record)
+		}
+	}()
+
+	if bot.Mode == mode {
+		return nil
+	}
+	if mode == ExecutionModeLive && bot.isActive && !confirmLive {
+		return ErrLiveConfirmationRequired
+	}
+
+	previous := bot.Mode
+	bot.Mode = mode
+	bot.Config.Mode = mode
+	record = bot.record()
+
+	tbe.logger.Info(ctx, "Bot execution mode changed", map[string]interface{}{
+		"bot_id":  botID,
+		"from":    string(previous),
+		"to":      string(mode),
+		"running": bot.isActive,
+	})
+
+	return nil
+}
+
+// UpdateStrategyParams replaces a bot's strategy parameters, which the caller has validated
+func (tbe *TradingBotEngine) UpdateStrategyParams(ctx context.Context, botID string, params map[string]interface{}) error {
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return err
+	}
+
+	bot.mu.Lock()
+	bot.Config.StrategyParams = params
+	running := bot.isActive
+	record := bot.record()
+	bot.mu.Unlock()
+	tbe.saveBot(ctx, record)
+
+	tbe.logger.Info(ctx, "Bot strategy parameters updated", map[string]interface{}{
+		"bot_id":  botID,
+		"running": running,
+	})
+
+	return nil
+}
+
+// SubmitOrder executes an order for a bot at the current feed price, simulated for paper bots
+// and routed to the exchange for live bots
+func (tbe *TradingBotEngine) SubmitOrder(ctx context.Context, botID string, order *BotOrder) (*BotTrade, error) {
+	if order == nil || order.Symbol == "" || !order.Quantity.IsPositive() {
+		return nil, fmt.Errorf("order requires a symbol and a positive quantity")
+	}
+	switch order.Type {
+	case "", OrderTypeMarket:
+	case OrderTypeLimit:
+		if !order.LimitPrice.IsPositive() {
+			return nil, fmt.Errorf("limit order requires a positive limit price")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported order type: %s", order.Type)
+	}
+
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return nil, err
+	}
+
+	tbe.mu.RLock()
+	feed := tbe.priceFeed
+	tbe.mu.RUnlock()
+	if feed == nil {
+		return nil, fmt.Errorf("no price feed configured")
+	}
+
+	price, err := feed.GetPrice(ctx, order.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price for %s: %w", order.Symbol, err)
+	}
+
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	return tbe.executeOrder(ctx, bot, order, price)
+}
+
+// executeOrder fills an order in the bot's execution mode and records the outcome.
+// The caller must hold bot.mu.
+func (tbe *TradingBotEngine) executeOrder(ctx context.Context, bot *TradingBot, order *BotOrder, marketPrice decimal.Decimal) (*BotTrade, error) {
+	if !order.marketable(marketPrice) {
+		return nil, fmt.Errorf("%w: %s %s at %s, market is %s", ErrOrderNotMarketable, order.Side, order.Symbol, order.LimitPrice, marketPrice)
+	}
+	bot.stats.OrdersPlaced++
+
+	var trade *BotTrade
+	var err error
+	if bot.Mode == ExecutionModeLive {
+		trade, err = tbe.exchangeManager.PlaceOrder(ctx, bot.ID, bot.Config.Exchange, bot.ConnectionID, order, marketPrice)
+	} else {
+		trade, err = bot.paper.fill(order, marketPrice)
+	}
+	if err != nil {
+		bot.stats.OrdersFailed++
+		if errors.Is(err, ErrExchangeConnectionRevoked) || errors.Is(err, ErrNoExchangeConnection) {
+			bot.LastError = err.Error()
+			tbe.setExecutionState(bot, StateError, err.Error())
+		}
+		return nil, err
+	}
+
+	trade.BotID = bot.ID
+	trade.Mode = bot.Mode
+	tbe.recordTrade(bot, trade)
+	tbe.notifyOrderFilled(trade)
+
+	tbe.logger.Info(ctx, "Bot order filled", map[string]interface{}{
+		"bot_id":       bot.ID,
+		"mode":         string(bot.Mode),
+		"symbol":       trade.Symbol,
+		"side":         string(trade.Side),
+		"quantity":     trade.Quantity.String(),
+		"fill_price":   trade.FillPrice.String(),
+		"fee":          trade.Fee.String(),
+		"realized_pnl": trade.RealizedPnL.String(),
+	})
+
+	return trade, nil
+}
+
+// recordTrade updates execution stats and performance from a fill. Closing (sell) fills count
+// as trades for win rate and profit accounting. The caller must hold bot.mu.
+func (tbe *TradingBotEngine) recordTrade(bot *TradingBot, trade *BotTrade) {
+	bot.trades = append(bot.trades, trade)
+	if len(bot.trades) > maxBotTrades {
+		bot.trades = bot.trades[len(bot.trades)-maxBotTrades:]
+	}
+
+	bot.stats.OrdersFilled++
+	bot.stats.TotalVolume = bot.stats.TotalVolume.Add(trade.FillPrice.Mul(trade.Quantity))
+	bot.stats.TotalFees = bot.stats.TotalFees.Add(trade.Fee)
+	bot.stats.TotalSlippage = bot.stats.TotalSlippage.Add(trade.FillPrice.Sub(trade.MarketPrice).Abs().Mul(trade.Quantity))
+	bot.stats.LastTradeTime = trade.ExecutedAt
+	bot.trackCostBasis(trade)
+
+	perf := bot.Performance
+	if trade.Side == OrderSideSell {
+		perf.TotalTrades++
+		if trade.RealizedPnL.IsPositive() {
+			perf.WinningTrades++
+			perf.TotalProfit = perf.TotalProfit.Add(trade.RealizedPnL)
+		} else {
+			perf.LosingTrades++
+			perf.TotalLoss = perf.TotalLoss.Add(trade.RealizedPnL.Abs())
+		}
+		perf.NetProfit = perf.TotalProfit.Sub(perf.TotalLoss)
+		perf.WinRate = decimal.NewFromInt(int64(perf.WinningTrades)).Div(decimal.NewFromInt(int64(perf.TotalTrades)))
+	}
+	tbe.updateDrawdown(bot)
+}
+
+// CostBasis is what a bot paid for the holding of one symbol. It is kept per bot and
+// combined by the bot monitor; the web3 portfolio analytics don't see bot fills.
+type CostBasis struct {
+	Symbol       string          `json:"symbol"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	TotalCost    decimal.Decimal `json:"total_cost"`
+	AverageEntry decimal.Decimal `json:"average_entry"`
+}
+
+// trackCostBasis adds a buy's cost, fee included, to the symbol's cost basis; a sell removes
+// the sold share of it at the average entry. The caller must hold bot.mu.
+func (bot *TradingBot) trackCostBasis(trade *BotTrade) {
+	if bot.costBasis == nil {
+		bot.costBasis = make(map[string]*CostBasis)
+	}
+	basis, ok := bot.costBasis[trade.Symbol]
+	if !ok {
+		basis = &CostBasis{Symbol: trade.Symbol}
+		bot.costBasis[trade.Symbol] = basis
+	}
+
+	if trade.Side == OrderSideBuy {
+		basis.Quantity = basis.Quantity.Add(trade.Quantity)
+		basis.TotalCost = basis.TotalCost.Add(trade.FillPrice.Mul(trade.Quantity)).Add(trade.Fee)
+	} else {
+		sold := decimal.Min(trade.Quantity, basis.Quantity)
+		basis.TotalCost = basis.TotalCost.Sub(basis.AverageEntry.Mul(sold))
+		basis.Quantity = basis.Quantity.Sub(sold)
+	}
+
+	if basis.Quantity.IsPositive() {
+		basis.AverageEntry = basis.TotalCost.Div(basis.Quantity)
+	} else {
+		delete(bot.costBasis, trade.Symbol)
+	}
+}
+
+// CostBasis returns the cost basis of each symbol the bot holds, by symbol
+func (bot *TradingBot) CostBasis() []CostBasis {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+
+	holdings := make([]CostBasis, 0, len(bot.costBasis))
+	for _, basis := range bot.costBasis {
+		holdings = append(holdings, *basis)
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].Symbol < holdings[j].Symbol })
+	return holdings
+}
+
+// updateDrawdown refreshes the maximum drawdown of a paper bot. The caller must hold bot.mu.
+func (tbe *TradingBotEngine) updateDrawdown(bot *TradingBot) {
+	if bot.Mode != ExecutionModePaper {
+		return
+	}
+	if drawdown := bot.paper.drawdown(); drawdown.GreaterThan(bot.Performance.MaxDrawdown) {
+		bot.Performance.MaxDrawdown = drawdown
+	}
+	bot.Performance.LastUpdated = time.Now()
+}
+
+// PaperAccount returns a snapshot of the bot's simulated balance and holdings
+func (bot *TradingBot) PaperAccount() *PaperAccount {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	if bot.paper == nil {
+		return nil
+	}
+	return bot.paper.snapshot()
+}
+
+// Trades returns the bot's most recent fills, oldest first
+func (bot *TradingBot) Trades() []*BotTrade {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	trades := make([]*BotTrade, len(bot.trades))
+	copy(trades, bot.trades)
+	return trades
+}
+
+// Grid returns the level map and metrics of each trading pair of a grid bot, or nil when the
+// bot's strategy is not a grid
+func (bot *TradingBot) Grid() []*strategies.GridSnapshot {
+	bot.mu.RLock()
+	strategy := bot.strategy
+	bot.mu.RUnlock()
+	if grid, ok := strategy.(*strategies.GridStrategy); ok {
+		return grid.Grids()
+	}
+	return nil
+}
+
+// ExecutionStats returns the bot's order execution statistics
+func (bot *TradingBot) ExecutionStats() BotExecutionStats {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+	return bot.stats
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/security"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -22,6 +23,12 @@ type BotRiskManager struct {
 	correlationMatrix map[string]map[string]decimal.Decimal
 	alertManager      *RiskAlertManager
 
+	// Limit templates and changes to bots' limits, raised limits waiting for approval
+	riskTemplates  map[string]*RiskLimitTemplate
+	limitChanges   map[string]*RiskLimitChange
+	limitListeners []RiskLimitListener
+	auditor        *security.AuditManager
+
 	// Circuit breakers
 	emergencyStop bool
 	tradingHalted map[string]bool
@@ -187,6 +194,8 @@ func NewBotRiskManager(logger *observability.Logger) *BotRiskManager {
 		correlationMatrix: make(map[string]map[string]decimal.Decimal),
 		tradingHalted:     make(map[string]bool),
 		alertManager:      NewRiskAlertManager(logger),
+		riskTemplates:     builtinRiskTemplates(),
+		limitChanges:      make(map[string]*RiskLimitChange),
 		stopChan:          make(chan struct{}),
 	}
 }
@@ -816,6 +825,11 @@ func (brm *BotRiskManager) checkRiskViolations(ctx context.Context, botID string
 	}
 }
 
+// AddAlertChannel adds a delivery channel for risk alerts, including triggered limits
+func (brm *BotRiskManager) AddAlertChannel(channel AlertChannel) {
+	brm.alertManager.AddAlertChannel(channel)
+}
+
 // GetAlertsBySeverity returns alerts by severity level
 func (brm *BotRiskManager) GetAlertsBySeverity(severity AlertSeverity) []*RiskAlert {
 	return brm.alertManager.GetAlertsBySeverity(severity)
@@ -0,0 +1,100 @@
+package trading
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BotStore persists the bots created through the API with their owners and exchange
+// connections, so a restarted service restores them under the same IDs and live bots keep
+// trading through their connections
+type BotStore interface {
+	// SaveBot inserts or replaces a bot
+	SaveBot(ctx context.Context, bot *BotRecord) error
+	ListBots(ctx context.Context) ([]*BotRecord, error)
+}
+
+// BotRecord is the persisted definition of a bot; its runtime state is not kept
+type BotRecord struct {
+	ID           string
+	Name         string
+	Strategy     BotStrategy
+	Config       *BotConfig
+	UserID       uuid.UUID
+	ConnectionID uuid.UUID
+	CreatedAt    time.Time
+}
+
+// SetBotStore persists the bots registered from now on and the changes of their connections,
+// modes and parameters
+func (tbe *TradingBotEngine) SetBotStore(store BotStore) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.botStore = store
+}
+
+// RestoreBots registers the bots of the store that are not registered yet, idle until they
+// are started, and returns them so their strategies can be attached
+func (tbe *TradingBotEngine) RestoreBots(ctx context.Context) ([]*TradingBot, error) {
+	tbe.mu.RLock()
+	store := tbe.botStore
+	tbe.mu.RUnlock()
+	if store == nil {
+		return nil, nil
+	}
+
+	records, err := store.ListBots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make([]*TradingBot, 0, len(records))
+	for _, record := range records {
+		if _, err := tbe.GetBot(record.ID); err == nil {
+			continue
+		}
+		bot, err := tbe.registerBot(ctx, record)
+		if err != nil {
+			tbe.logger.Warn(ctx, "Failed to restore bot", map[string]interface{}{
+				"bot_id": record.ID,
+				"error":  err.Error(),
+			})
+			continue
+		}
+		restored = append(restored, bot)
+	}
+	return restored, nil
+}
+
+// record returns the persisted definition of the bot; callers hold bot.mu
+func (bot *TradingBot) record() *BotRecord {
+	config := *bot.Config
+	config.OwnerID = bot.UserID
+	return &BotRecord{
+		ID:           bot.ID,
+		Name:         bot.Name,
+		Strategy:     bot.Strategy,
+		Config:       &config,
+		UserID:       bot.UserID,
+		ConnectionID: bot.ConnectionID,
+		CreatedAt:    bot.CreatedAt,
+	}
+}
+
+// saveBot persists a bot's definition taken with record, logging failures
+func (tbe *TradingBotEngine) saveBot(ctx context.Context, record *BotRecord) {
+	tbe.mu.RLock()
+	store := tbe.botStore
+	tbe.mu.RUnlock()
+	if store == nil {
+		return
+	}
+
+	if err := store.SaveBot(context.WithoutCancel(ctx), record); err != nil {
+		tbe.logger.Error(ctx, "Failed to save bot", err, map[string]interface{}{
+			"bot_id": record.ID,
+		})
+	}
+}
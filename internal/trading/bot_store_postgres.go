@@ -0,0 +1,70 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresBotStore implements BotStore using the bots table
+type postgresBotStore struct {
+	db *database.DB
+}
+
+func NewPostgresBotStore(db *database.DB) BotStore {
+	return &postgresBotStore{db: db}
+}
+
+func (s *postgresBotStore) SaveBot(ctx context.Context, bot *BotRecord) error {
+	config, err := json.Marshal(bot.Config)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecWithMetrics(ctx, `
+		INSERT INTO bots (id, name, strategy, config, user_id, connection_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name, config = EXCLUDED.config, user_id = EXCLUDED.user_id,
+			connection_id = EXCLUDED.connection_id, updated_at = EXCLUDED.updated_at
+	`, bot.ID, bot.Name, string(bot.Strategy), config, nullUUID(bot.UserID), nullUUID(bot.ConnectionID), bot.CreatedAt)
+	return err
+}
+
+func (s *postgresBotStore) ListBots(ctx context.Context) ([]*BotRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, strategy, config, user_id, connection_id, created_at
+		FROM bots
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bots := make([]*BotRecord, 0)
+	for rows.Next() {
+		var bot BotRecord
+		var strategy string
+		var config []byte
+		var userID, connectionID uuid.NullUUID
+		if err := rows.Scan(&bot.ID, &bot.Name, &strategy, &config, &userID, &connectionID, &bot.CreatedAt); err != nil {
+			return nil, err
+		}
+		bot.Config = &BotConfig{}
+		if err := json.Unmarshal(config, bot.Config); err != nil {
+			return nil, err
+		}
+		bot.Strategy = BotStrategy(strategy)
+		bot.UserID, bot.ConnectionID = userID.UUID, connectionID.UUID
+		bot.Config.OwnerID = bot.UserID
+		bots = append(bots, &bot)
+	}
+	return bots, rows.Err()
+}
+
+// nullUUID stores the nil UUID of bots without an owner or connection as NULL
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
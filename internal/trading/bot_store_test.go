@@ -0,0 +1,80 @@
+package trading
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBotStore is a BotStore kept in memory
+type memoryBotStore struct {
+	mu   sync.Mutex
+	bots map[string]BotRecord
+}
+
+func (m *memoryBotStore) SaveBot(ctx context.Context, bot *BotRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.bots == nil {
+		m.bots = make(map[string]BotRecord)
+	}
+	m.bots[bot.ID] = *bot
+	return nil
+}
+
+func (m *memoryBotStore) ListBots(ctx context.Context) ([]*BotRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bots := make([]*BotRecord, 0, len(m.bots))
+	for _, bot := range m.bots {
+		copied := bot
+		bots = append(bots, &copied)
+	}
+	return bots, nil
+}
+
+func TestBotStore_RestoresOwnerAndConnection(t *testing.T) {
+	manager, engine, _, _ := newConnectionFixture(t)
+	store := &memoryBotStore{}
+	engine.SetBotStore(store)
+	ctx := context.Background()
+	owner := uuid.New()
+	connection, _, err := manager.Connect(ctx, owner, "fake", "", testCredentials)
+	require.NoError(t, err)
+
+	bot, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake", TradingPairs: []string{"BTC/USDT"}, OwnerID: owner}, StrategyDCA)
+	require.NoError(t, err)
+	require.NoError(t, manager.AttachBot(ctx, owner, connection.ID, bot.ID, false))
+	require.NoError(t, engine.SetBotMode(ctx, bot.ID, ExecutionModeLive, false))
+
+	// A restarted engine registers the bot under its ID with its owner and connection
+	restarted := NewTradingBotEngine(observability.NewLogger(config.ObservabilityConfig{}), &BotEngineConfig{MaxConcurrentBots: 10})
+	restarted.SetBotStore(store)
+	restored, err := restarted.RestoreBots(ctx)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+	assert.Equal(t, bot.ID, restored[0].ID)
+	assert.Equal(t, bot.Name, restored[0].Name)
+	assert.Equal(t, owner, restored[0].UserID)
+	assert.Equal(t, connection.ID, restored[0].ConnectionID)
+	assert.Equal(t, ExecutionModeLive, restored[0].Mode)
+	assert.Equal(t, StateIdle, restored[0].State)
+	assert.Equal(t, []string{"BTC/USDT"}, restored[0].Config.TradingPairs)
+
+	// Bots already registered are not restored twice
+	restored, err = restarted.RestoreBots(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, restored)
+
+	// Revoking the connection detaches the persisted bot too
+	_, err = manager.RevokeConnection(ctx, owner, connection.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, store.bots[bot.ID].ConnectionID)
+	assert.Equal(t, owner, store.bots[bot.ID].UserID)
+}
@@ -0,0 +1,48 @@
+package trading
+
+import (
+	"context"
+
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// postgresDCARungStore implements DCARungStore using the dca_rungs table
+type postgresDCARungStore struct {
+	db *database.DB
+}
+
+func NewPostgresDCARungStore(db *database.DB) DCARungStore {
+	return &postgresDCARungStore{db: db}
+}
+
+func (s *postgresDCARungStore) SaveDCARung(ctx context.Context, botID string, rung strategies.DCARung) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO dca_rungs (bot_id, symbol, quantity, price, cost, multiplier, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, botID, rung.Symbol, rung.Quantity, rung.Price, rung.Cost, rung.Multiplier, rung.ExecutedAt)
+	return err
+}
+
+func (s *postgresDCARungStore) ListDCARungs(ctx context.Context, botID string) ([]strategies.DCARung, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT symbol, quantity, price, cost, multiplier, executed_at
+		FROM dca_rungs
+		WHERE bot_id = $1
+		ORDER BY executed_at
+	`, botID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rungs := make([]strategies.DCARung, 0)
+	for rows.Next() {
+		var rung strategies.DCARung
+		if err := rows.Scan(&rung.Symbol, &rung.Quantity, &rung.Price, &rung.Cost, &rung.Multiplier, &rung.ExecutedAt); err != nil {
+			return nil, err
+		}
+		rungs = append(rungs, rung)
+	}
+	return rungs, rows.Err()
+}
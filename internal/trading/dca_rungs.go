@@ -0,0 +1,77 @@
+package trading
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+)
+
+// DCARungStore persists the filled buys of DCA bots, so a bot's schedule and cost basis
+// survive its strategy being attached again
+type DCARungStore interface {
+	SaveDCARung(ctx context.Context, botID string, rung strategies.DCARung) error
+	ListDCARungs(ctx context.Context, botID string) ([]strategies.DCARung, error)
+}
+
+// SetDCARungStore persists the rungs of DCA strategies attached from now on
+func (tbe *TradingBotEngine) SetDCARungStore(store DCARungStore) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.dcaRungs = store
+}
+
+// persistDCARungs restores a DCA strategy's rungs from the store and saves its new ones
+func (tbe *TradingBotEngine) persistDCARungs(ctx context.Context, botID string, dca *strategies.DCAStrategy) {
+	tbe.mu.RLock()
+	store := tbe.dcaRungs
+	tbe.mu.RUnlock()
+	if store == nil {
+		return
+	}
+
+	rungs, err := store.ListDCARungs(ctx, botID)
+	if err != nil {
+		tbe.logger.Warn(ctx, "Failed to restore DCA rungs", map[string]interface{}{
+			"bot_id": botID,
+			"error":  err.Error(),
+		})
+	} else {
+		dca.Restore(rungs)
+	}
+
+	dca.OnRung(func(rung strategies.DCARung) {
+		if err := store.SaveDCARung(context.Background(), botID, rung); err != nil {
+			tbe.logger.Warn(context.Background(), "Failed to save DCA rung", map[string]interface{}{
+				"bot_id": botID,
+				"symbol": rung.Symbol,
+				"error":  err.Error(),
+			})
+		}
+	})
+}
+
+// DCA returns the position and next buy of each trading pair of a DCA bot, or nil when the
+// bot's strategy is not DCA
+func (bot *TradingBot) DCA() []*strategies.DCAPosition {
+	bot.mu.RLock()
+	strategy := bot.strategy
+	bot.mu.RUnlock()
+	if dca, ok := strategy.(*strategies.DCAStrategy); ok {
+		return dca.Positions()
+	}
+	return nil
+}
+
+// DCASchedule projects the next count buys of a DCA bot from now; ok is false when the bot's
+// strategy is not DCA
+func (bot *TradingBot) DCASchedule(now time.Time, count int) (buys []strategies.DCAScheduledBuy, ok bool) {
+	bot.mu.RLock()
+	strategy := bot.strategy
+	bot.mu.RUnlock()
+	dca, ok := strategy.(*strategies.DCAStrategy)
+	if !ok {
+		return nil, false
+	}
+	return dca.Schedule(now, count), true
+}
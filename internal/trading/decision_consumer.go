@@ -0,0 +1,167 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agentic-browser/internal/ai"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// DecisionExecutionChannel is the Redis channel the AI agent publishes approved decisions on
+const DecisionExecutionChannel = "ai:decisions:execute"
+
+// Decision execution errors
+var (
+	ErrUnsupportedDecision = errors.New("decision cannot be executed as a bot order")
+	ErrNoDecisionBot       = errors.New("no bot of the user trades the decision's asset")
+)
+
+// DecisionConsumer executes the decisions approved in the AI agent. Buy and sell
+// recommendations are submitted as orders of the owner's bot trading the asset, in that bot's
+// execution mode, so decisions only reach an exchange through bots the user has taken live.
+type DecisionConsumer struct {
+	logger  *observability.Logger
+	engine  *TradingBotEngine
+	redis   *database.RedisClient
+	channel string
+}
+
+// NewDecisionConsumer creates a consumer of the approved decisions published on channel,
+// DecisionExecutionChannel when empty
+func NewDecisionConsumer(logger *observability.Logger, engine *TradingBotEngine, redis *database.RedisClient, channel string) *DecisionConsumer {
+	if channel == "" {
+		channel = DecisionExecutionChannel
+	}
+	return &DecisionConsumer{logger: logger, engine: engine, redis: redis, channel: channel}
+}
+
+// Run executes published decisions until ctx is cancelled
+func (c *DecisionConsumer) Run(ctx context.Context) {
+	if c.redis == nil {
+		return
+	}
+
+	pubsub := c.redis.Client.Subscribe(ctx, c.channel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event ai.DecisionExecutionEvent
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				c.logger.Warn(ctx, "Ignoring malformed decision execution event", map[string]interface{}{
+					"error": err.Error(),
+				})
+				continue
+			}
+			// Failures are logged; the decision was already reported as submitted
+			c.Execute(ctx, event)
+		}
+	}
+}
+
+// Execute submits the order for an approved decision
+func (c *DecisionConsumer) Execute(ctx context.Context, event ai.DecisionExecutionEvent) (*BotTrade, error) {
+	fields := map[string]interface{}{
+		"execution_id": event.ExecutionID,
+		"decision_id":  event.DecisionID,
+		"user_id":      event.UserID.String(),
+	}
+
+	trade, err := c.execute(ctx, event)
+	if err != nil {
+		c.logger.Error(ctx, "Decision execution failed", err, fields)
+		return nil, err
+	}
+
+	fields["bot_id"] = trade.BotID
+	fields["trade_id"] = trade.ID
+	c.logger.Info(ctx, "Decision executed", fields)
+	return trade, nil
+}
+
+func (c *DecisionConsumer) execute(ctx context.Context, event ai.DecisionExecutionEvent) (*BotTrade, error) {
+	recommendation := event.Recommendation
+	order := &BotOrder{Quantity: recommendation.Quantity}
+	switch strings.ToLower(recommendation.Action) {
+	case "buy":
+		order.Side = OrderSideBuy
+	case "sell":
+		order.Side = OrderSideSell
+	default:
+		return nil, fmt.Errorf("%w: action %q", ErrUnsupportedDecision, recommendation.Action)
+	}
+	switch strings.ToLower(recommendation.OrderType) {
+	case "", "market":
+		order.Type = OrderTypeMarket
+	case "limit":
+		order.Type = OrderTypeLimit
+		order.LimitPrice = recommendation.Price
+	default:
+		return nil, fmt.Errorf("%w: order type %q", ErrUnsupportedDecision, recommendation.OrderType)
+	}
+
+	botID, _ := recommendation.Metadata["bot_id"].(string)
+	bot, symbol, err := c.decisionBot(event.UserID, recommendation.Asset, botID)
+	if err != nil {
+		return nil, err
+	}
+	order.Symbol = symbol
+
+	return c.engine.SubmitOrder(ctx, bot.ID, order)
+}
+
+// decisionBot returns the user's bot for an asset and the pair it trades the asset in. A bot
+// named in the recommendation is used when it belongs to the user; otherwise exactly one of
+// the user's bots must trade the asset.
+func (c *DecisionConsumer) decisionBot(userID uuid.UUID, asset, botID string) (*TradingBot, string, error) {
+	asset = strings.ToUpper(strings.TrimSpace(asset))
+	if userID == uuid.Nil || asset == "" {
+		return nil, "", fmt.Errorf("%w: decision has no owner or asset", ErrUnsupportedDecision)
+	}
+
+	var bot *TradingBot
+	var symbol string
+	for _, candidate := range c.engine.ListBots() {
+		if candidate.UserID != userID || (botID != "" && candidate.ID != botID) {
+			continue
+		}
+		candidate.mu.RLock()
+		pair := assetPair(candidate.Config.TradingPairs, asset)
+		candidate.mu.RUnlock()
+		if pair == "" {
+			continue
+		}
+		if bot != nil {
+			return nil, "", fmt.Errorf("%w: several bots trade %s, name one with bot_id", ErrUnsupportedDecision, asset)
+		}
+		bot, symbol = candidate, pair
+	}
+	if bot == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrNoDecisionBot, asset)
+	}
+	return bot, symbol, nil
+}
+
+// assetPair returns the pair trading asset as its base, or the pair equal to asset
+func assetPair(pairs []string, asset string) string {
+	for _, pair := range pairs {
+		normalized := strings.ToUpper(pair)
+		if normalized == asset || strings.SplitN(normalized, "/", 2)[0] == asset {
+			return pair
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package trading
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/ai"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedPriceFeed quotes the same price for every symbol
+type fixedPriceFeed decimal.Decimal
+
+func (f fixedPriceFeed) GetPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	return decimal.Decimal(f), nil
+}
+
+func TestDecisionConsumer_Execute(t *testing.T) {
+	ctx := context.Background()
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	engine := NewTradingBotEngine(logger, &BotEngineConfig{MaxConcurrentBots: 10})
+	engine.SetPriceFeed(fixedPriceFeed(decimal.NewFromInt(50000)))
+	consumer := NewDecisionConsumer(logger, engine, nil, "")
+
+	owner := uuid.New()
+	newBot := func(pairs ...string) *TradingBot {
+		bot, err := engine.RegisterBot(ctx, &BotConfig{
+			Exchange:     "binance",
+			TradingPairs: pairs,
+			OwnerID:      owner,
+			Capital:      &CapitalConfig{InitialBalance: decimal.NewFromInt(100000)},
+		}, StrategyDCA)
+		require.NoError(t, err)
+		return bot
+	}
+	decision := func(userID uuid.UUID, action, asset string, metadata map[string]interface{}) ai.DecisionExecutionEvent {
+		return ai.DecisionExecutionEvent{
+			ExecutionID: uuid.New().String(),
+			DecisionID:  uuid.New().String(),
+			UserID:      userID,
+			Recommendation: ai.DecisionRecommendation{
+				Action:    action,
+				Asset:     asset,
+				Quantity:  decimal.NewFromFloat(0.1),
+				OrderType: "market",
+				Metadata:  metadata,
+			},
+		}
+	}
+
+	btcBot := newBot("BTC/USDT")
+	newBot("ETH/USDT")
+
+	trade, err := consumer.Execute(ctx, decision(owner, "buy", "BTC", nil))
+	require.NoError(t, err)
+	assert.Equal(t, btcBot.ID, trade.BotID)
+	assert.Equal(t, "BTC/USDT", trade.Symbol)
+	assert.Equal(t, OrderSideBuy, trade.Side)
+	assert.Equal(t, ExecutionModePaper, trade.Mode)
+	assert.True(t, trade.Quantity.Equal(decimal.NewFromFloat(0.1)))
+
+	// Decisions only reach the owner's bots
+	_, err = consumer.Execute(ctx, decision(uuid.New(), "buy", "BTC", nil))
+	assert.ErrorIs(t, err, ErrNoDecisionBot)
+	_, err = consumer.Execute(ctx, decision(owner, "buy", "SOL", nil))
+	assert.ErrorIs(t, err, ErrNoDecisionBot)
+
+	_, err = consumer.Execute(ctx, decision(owner, "hold", "BTC", nil))
+	assert.ErrorIs(t, err, ErrUnsupportedDecision)
+
+	// A second bot trading the asset must be named
+	secondBTC := newBot("BTC/USDC")
+	_, err = consumer.Execute(ctx, decision(owner, "sell", "BTC", nil))
+	assert.ErrorIs(t, err, ErrUnsupportedDecision)
+	trade, err = consumer.Execute(ctx, decision(owner, "sell", "BTC", map[string]interface{}{"bot_id": btcBot.ID}))
+	require.NoError(t, err)
+	assert.Equal(t, btcBot.ID, trade.BotID)
+	assert.Equal(t, OrderSideSell, trade.Side)
+	assert.NotEqual(t, secondBTC.ID, trade.BotID)
+}
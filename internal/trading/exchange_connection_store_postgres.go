@@ -0,0 +1,99 @@
+package trading
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// postgresExchangeConnectionStore implements ExchangeConnectionStore using the
+// exchange_connections table
+type postgresExchangeConnectionStore struct {
+	db *database.DB
+}
+
+func NewPostgresExchangeConnectionStore(db *database.DB) ExchangeConnectionStore {
+	return &postgresExchangeConnectionStore{db: db}
+}
+
+const exchangeConnectionColumns = `id, user_id, exchange, label, key_suffix, status, created_at, validated_at, revoked_at`
+
+func (s *postgresExchangeConnectionStore) SaveConnection(ctx context.Context, connection *ExchangeConnection, credentials *SealedCredentials) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO exchange_connections (id, user_id, exchange, label, key_suffix, credentials, key_id, algorithm,
+			status, created_at, validated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, connection.ID, connection.UserID, connection.Exchange, connection.Label, connection.KeySuffix,
+		credentials.Ciphertext, credentials.KeyID, credentials.Algorithm,
+		connection.Status, connection.CreatedAt, connection.ValidatedAt)
+	return err
+}
+
+func (s *postgresExchangeConnectionStore) GetConnection(ctx context.Context, id uuid.UUID) (*ExchangeConnection, *SealedCredentials, error) {
+	connection := &ExchangeConnection{}
+	credentials := &SealedCredentials{}
+	var revokedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT `+exchangeConnectionColumns+`, credentials, key_id, algorithm
+		FROM exchange_connections
+		WHERE id = $1
+	`, id).Scan(&connection.ID, &connection.UserID, &connection.Exchange, &connection.Label, &connection.KeySuffix,
+		&connection.Status, &connection.CreatedAt, &connection.ValidatedAt, &revokedAt,
+		&credentials.Ciphertext, &credentials.KeyID, &credentials.Algorithm)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrExchangeConnectionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if revokedAt.Valid {
+		connection.RevokedAt = &revokedAt.Time
+	}
+	return connection, credentials, nil
+}
+
+func (s *postgresExchangeConnectionStore) ListConnections(ctx context.Context, userID uuid.UUID) ([]*ExchangeConnection, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+exchangeConnectionColumns+`
+		FROM exchange_connections
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	connections := make([]*ExchangeConnection, 0)
+	for rows.Next() {
+		connection := &ExchangeConnection{}
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&connection.ID, &connection.UserID, &connection.Exchange, &connection.Label, &connection.KeySuffix,
+			&connection.Status, &connection.CreatedAt, &connection.ValidatedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		if revokedAt.Valid {
+			connection.RevokedAt = &revokedAt.Time
+		}
+		connections = append(connections, connection)
+	}
+	return connections, rows.Err()
+}
+
+func (s *postgresExchangeConnectionStore) RevokeConnection(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	result, err := s.db.ExecWithMetrics(ctx, `
+		UPDATE exchange_connections SET status = $2, revoked_at = $3
+		WHERE id = $1
+	`, id, ExchangeConnectionRevoked, revokedAt)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrExchangeConnectionNotFound
+	}
+	return nil
+}
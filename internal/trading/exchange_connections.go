@@ -0,0 +1,373 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeCredentialsPurpose is the encryption key purpose exchange credentials are sealed with
+const ExchangeCredentialsPurpose = "exchange_credentials"
+
+// Exchange connection statuses
+const (
+	ExchangeConnectionActive  = "active"
+	ExchangeConnectionRevoked = "revoked"
+)
+
+var (
+	// ErrUnsupportedExchange is returned for exchanges without a connector
+	ErrUnsupportedExchange = errors.New("unsupported exchange")
+	// ErrInvalidExchangeCredentials is returned when credentials are missing or rejected by the exchange
+	ErrInvalidExchangeCredentials = errors.New("invalid exchange credentials")
+	// ErrExchangeConnectionNotFound is returned for unknown connections and those of other users
+	ErrExchangeConnectionNotFound = errors.New("exchange connection not found")
+	// ErrExchangeConnectionRevoked is returned when a revoked connection is used
+	ErrExchangeConnectionRevoked = errors.New("exchange connection revoked")
+	// ErrNoExchangeConnection is returned when a live bot has no exchange connection to trade through
+	ErrNoExchangeConnection = errors.New("live bot has no exchange connection")
+	// ErrExchangeMismatch is returned when a bot is attached to a connection for another exchange
+	ErrExchangeMismatch = errors.New("bot trades on a different exchange")
+	// ErrUnownedBot is returned when a user other than an admin attaches a bot without an owner
+	ErrUnownedBot = errors.New("bot has no owner; only an admin can attach it")
+)
+
+// ExchangeCredentials are a user's API credentials for an exchange. They are only held
+// decrypted while validating a connection or placing an order and must never be logged.
+type ExchangeCredentials struct {
+	APIKey     string `json:"api_key"`
+	APISecret  string `json:"api_secret"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// MaskedKey returns the last four characters of the API key, which is all that is shown
+func (c ExchangeCredentials) MaskedKey() string {
+	if len(c.APIKey) <= 4 {
+		return "****"
+	}
+	return "****" + c.APIKey[len(c.APIKey)-4:]
+}
+
+// ExchangeConnection is a user's exchange account. Its credentials are stored encrypted and
+// are not part of the connection.
+type ExchangeConnection struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Exchange    string     `json:"exchange"`
+	Label       string     `json:"label,omitempty"`
+	KeySuffix   string     `json:"key_suffix"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ValidatedAt time.Time  `json:"validated_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SealedCredentials are exchange credentials encrypted by the encryption manager
+type SealedCredentials struct {
+	Ciphertext []byte
+	KeyID      string
+	Algorithm  string
+}
+
+// ExchangeBalance is an asset balance reported by an exchange
+type ExchangeBalance struct {
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+}
+
+// ExchangeConnector talks to an exchange on behalf of a user with their credentials
+type ExchangeConnector interface {
+	// Name returns the exchange name bots and connections refer to
+	Name() string
+	// FetchBalances returns the account's non-zero balances, validating the credentials
+	FetchBalances(ctx context.Context, credentials ExchangeCredentials) ([]ExchangeBalance, error)
+	// PlaceOrder places a market or immediate-or-cancel limit order and returns its fill
+	PlaceOrder(ctx context.Context, credentials ExchangeCredentials, order *BotOrder) (*BotTrade, error)
+}
+
+// CredentialsProvider resolves the credentials of an exchange connection when a live bot
+// places an order, so revoked connections stop trading immediately
+type CredentialsProvider interface {
+	Credentials(ctx context.Context, connectionID uuid.UUID) (ExchangeCredentials, error)
+}
+
+// ExchangeConnectionStore persists exchange connections with their sealed credentials
+type ExchangeConnectionStore interface {
+	SaveConnection(ctx context.Context, connection *ExchangeConnection, credentials *SealedCredentials) error
+	// GetConnection returns ErrExchangeConnectionNotFound for unknown connections
+	GetConnection(ctx context.Context, id uuid.UUID) (*ExchangeConnection, *SealedCredentials, error)
+	ListConnections(ctx context.Context, userID uuid.UUID) ([]*ExchangeConnection, error)
+	RevokeConnection(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+}
+
+// ExchangeConnectionManager validates, stores and revokes users' exchange connections and
+// provides their credentials to live bots
+type ExchangeConnectionManager struct {
+	logger     *observability.Logger
+	store      ExchangeConnectionStore
+	encryption *security.EncryptionManager
+	engine     *TradingBotEngine
+	connectors map[string]ExchangeConnector
+}
+
+// NewExchangeConnectionManager creates a connection manager for the given exchanges. The
+// encryption manager must hold an active key for ExchangeCredentialsPurpose.
+func NewExchangeConnectionManager(logger *observability.Logger, store ExchangeConnectionStore, encryption *security.EncryptionManager, engine *TradingBotEngine, connectors ...ExchangeConnector) *ExchangeConnectionManager {
+	return &ExchangeConnectionManager{
+		logger:     logger,
+		store:      store,
+		encryption: encryption,
+		engine:     engine,
+		connectors: connectorsByName(connectors),
+	}
+}
+
+// connectorsByName indexes connectors by lowercase exchange name
+func connectorsByName(connectors []ExchangeConnector) map[string]ExchangeConnector {
+	byName := make(map[string]ExchangeConnector, len(connectors))
+	for _, connector := range connectors {
+		byName[strings.ToLower(connector.Name())] = connector
+	}
+	return byName
+}
+
+// Connect validates credentials with a balance request and stores them encrypted as a new
+// connection of the user, returning the balances found
+func (m *ExchangeConnectionManager) Connect(ctx context.Context, userID uuid.UUID, exchange, label string, credentials ExchangeCredentials) (*ExchangeConnection, []ExchangeBalance, error) {
+	exchange = strings.ToLower(strings.TrimSpace(exchange))
+	connector, ok := m.connectors[exchange]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedExchange, exchange)
+	}
+	if credentials.APIKey == "" || credentials.APISecret == "" {
+		return nil, nil, fmt.Errorf("%w: api key and secret are required", ErrInvalidExchangeCredentials)
+	}
+
+	balances, err := connector.FetchBalances(ctx, credentials)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s rejected the test call: %v", ErrInvalidExchangeCredentials, exchange, err)
+	}
+
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	sealed, err := m.encryption.EncryptData(plaintext, ExchangeCredentialsPurpose)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	now := time.Now().UTC()
+	connection := &ExchangeConnection{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Exchange:    exchange,
+		Label:       strings.TrimSpace(label),
+		KeySuffix:   credentials.MaskedKey(),
+		Status:      ExchangeConnectionActive,
+		CreatedAt:   now,
+		ValidatedAt: now,
+	}
+	if err := m.store.SaveConnection(ctx, connection, &SealedCredentials{
+		Ciphertext: sealed.EncryptedData,
+		KeyID:      sealed.KeyID,
+		Algorithm:  sealed.Algorithm,
+	}); err != nil {
+		return nil, nil, fmt.Errorf("failed to save exchange connection: %w", err)
+	}
+
+	m.logger.Info(ctx, "Exchange connection created", map[string]interface{}{
+		"connection_id": connection.ID.String(),
+		"user_id":       userID.String(),
+		"exchange":      exchange,
+		"key_suffix":    connection.KeySuffix,
+		"balances":      len(balances),
+	})
+
+	return connection, balances, nil
+}
+
+// ListConnections returns the user's connections, including revoked ones
+func (m *ExchangeConnectionManager) ListConnections(ctx context.Context, userID uuid.UUID) ([]*ExchangeConnection, error) {
+	return m.store.ListConnections(ctx, userID)
+}
+
+// AttachBot makes a bot trade through one of the user's active connections when live. Only
+// admins can attach bots that have no owner yet, such as those loaded from configuration.
+func (m *ExchangeConnectionManager) AttachBot(ctx context.Context, userID, connectionID uuid.UUID, botID string, admin bool) error {
+	connection, err := m.userConnection(ctx, userID, connectionID)
+	if err != nil {
+		return err
+	}
+	if connection.Status == ExchangeConnectionRevoked {
+		return ErrExchangeConnectionRevoked
+	}
+	return m.engine.AttachConnection(ctx, botID, connection, admin)
+}
+
+// RevokeConnection revokes one of the user's connections and stops the bots using it in the
+// error state, returning their IDs
+func (m *ExchangeConnectionManager) RevokeConnection(ctx context.Context, userID, connectionID uuid.UUID) ([]string, error) {
+	connection, err := m.userConnection(ctx, userID, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	if connection.Status != ExchangeConnectionRevoked {
+		if err := m.store.RevokeConnection(ctx, connectionID, time.Now().UTC()); err != nil {
+			return nil, fmt.Errorf("failed to revoke exchange connection: %w", err)
+		}
+	}
+
+	reason := fmt.Sprintf("%s: %s connection %s was revoked", ErrExchangeConnectionRevoked, connection.Exchange, connection.KeySuffix)
+	stopped := m.engine.DetachConnection(ctx, connectionID, reason)
+
+	m.logger.Info(ctx, "Exchange connection revoked", map[string]interface{}{
+		"connection_id": connectionID.String(),
+		"user_id":       userID.String(),
+		"exchange":      connection.Exchange,
+		"stopped_bots":  stopped,
+	})
+
+	return stopped, nil
+}
+
+// Credentials decrypts the credentials of an active connection
+func (m *ExchangeConnectionManager) Credentials(ctx context.Context, connectionID uuid.UUID) (ExchangeCredentials, error) {
+	var credentials ExchangeCredentials
+	connection, sealed, err := m.store.GetConnection(ctx, connectionID)
+	if err != nil {
+		return credentials, err
+	}
+	if connection.Status == ExchangeConnectionRevoked {
+		return credentials, ErrExchangeConnectionRevoked
+	}
+
+	plaintext, err := m.encryption.DecryptData(&security.DecryptionRequest{
+		EncryptedData: sealed.Ciphertext,
+		KeyID:         sealed.KeyID,
+		Algorithm:     sealed.Algorithm,
+	})
+	if err != nil {
+		return credentials, fmt.Errorf("failed to decrypt credentials of connection %s: %w", connectionID, err)
+	}
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return credentials, fmt.Errorf("failed to decode credentials of connection %s: %w", connectionID, err)
+	}
+	return credentials, nil
+}
+
+// userConnection returns a connection owned by the user, treating other users' connections
+// as not found
+func (m *ExchangeConnectionManager) userConnection(ctx context.Context, userID, connectionID uuid.UUID) (*ExchangeConnection, error) {
+	connection, _, err := m.store.GetConnection(ctx, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	if connection.UserID != userID {
+		return nil, ErrExchangeConnectionNotFound
+	}
+	return connection, nil
+}
+
+// SetExchangeConnectivity routes live bot orders to the connectors, with credentials resolved
+// through the provider for every order
+func (tbe *TradingBotEngine) SetExchangeConnectivity(credentials CredentialsProvider, connectors ...ExchangeConnector) {
+	tbe.exchangeManager.configure(credentials, connectors)
+}
+
+// AttachConnection makes a bot trade through the connection when live, assigning the bot to
+// the connection's user. Bots of other users are reported as not found; bots without an owner
+// are only claimed when claimUnowned is set.
+func (tbe *TradingBotEngine) AttachConnection(ctx context.Context, botID string, connection *ExchangeConnection, claimUnowned bool) error {
+	bot, err := tbe.GetBot(botID)
+	if err != nil {
+		return err
+	}
+
+	// The change is saved once the bot is unlocked, as the store is guarded by the engine
+	var record *BotRecord
+	bot.mu.Lock()
+	defer func() {
+		bot.mu.Unlock()
+		if record != nil {
+			tbe.saveBot(ctx, record)
+		}
+	}()
+
+	if bot.UserID == uuid.Nil && !claimUnowned {
+		return fmt.Errorf("%w: %s", ErrUnownedBot, botID)
+	}
+	if bot.UserID != uuid.Nil && bot.UserID != connection.UserID {
+		return fmt.Errorf("%w: %s", ErrBotNotFound, botID)
+	}
+	if !strings.EqualFold(bot.Config.Exchange, connection.Exchange) {
+		return fmt.Errorf("%w: bot %s trades on %q, the connection is for %q", ErrExchangeMismatch, botID, bot.Config.Exchange, connection.Exchange)
+	}
+
+	bot.UserID = connection.UserID
+	bot.ConnectionID = connection.ID
+	bot.LastError = ""
+	record = bot.record()
+
+	tbe.logger.Info(ctx, "Bot attached to exchange connection", map[string]interface{}{
+		"bot_id":        botID,
+		"connection_id": connection.ID.String(),
+		"exchange":      connection.Exchange,
+	})
+
+	return nil
+}
+
+// DetachConnection removes a connection from the bots using it, stopping running bots in the
+// error state with reason, and returns the IDs of the bots detached
+func (tbe *TradingBotEngine) DetachConnection(ctx context.Context, connectionID uuid.UUID, reason string) []string {
+	tbe.mu.Lock()
+	records := make([]*BotRecord, 0)
+	defer func() {
+		tbe.mu.Unlock()
+		for _, record := range records {
+			tbe.saveBot(ctx, record)
+		}
+	}()
+
+	detached := make([]string, 0)
+	for _, bot := range tbe.bots {
+		bot.mu.Lock()
+		if bot.ConnectionID != connectionID {
+			bot.mu.Unlock()
+			continue
+		}
+
+		bot.ConnectionID = uuid.Nil
+		bot.LastError = reason
+		records = append(records, bot.record())
+		previous, stopped := bot.State, bot.isActive
+		if stopped {
+			bot.isActive = false
+			bot.State = StateError
+			close(bot.stopChan)
+		}
+		bot.mu.Unlock()
+
+		if stopped && previous != StateError {
+			tbe.notifyStateChange(bot.ID, previous, StateError, reason)
+		}
+		detached = append(detached, bot.ID)
+
+		tbe.logger.Warn(ctx, "Bot detached from revoked exchange connection", map[string]interface{}{
+			"bot_id":        bot.ID,
+			"connection_id": connectionID.String(),
+			"stopped":       stopped,
+		})
+	}
+	return detached
+}
@@ -0,0 +1,231 @@
+package trading
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryConnectionStore keeps exchange connections and their sealed credentials in memory
+type memoryConnectionStore struct {
+	connections map[uuid.UUID]*ExchangeConnection
+	sealed      map[uuid.UUID]*SealedCredentials
+	mu          sync.Mutex
+}
+
+func newMemoryConnectionStore() *memoryConnectionStore {
+	return &memoryConnectionStore{
+		connections: make(map[uuid.UUID]*ExchangeConnection),
+		sealed:      make(map[uuid.UUID]*SealedCredentials),
+	}
+}
+
+func (m *memoryConnectionStore) SaveConnection(ctx context.Context, connection *ExchangeConnection, credentials *SealedCredentials) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *connection
+	m.connections[connection.ID] = &copied
+	m.sealed[connection.ID] = credentials
+	return nil
+}
+
+func (m *memoryConnectionStore) GetConnection(ctx context.Context, id uuid.UUID) (*ExchangeConnection, *SealedCredentials, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	connection, ok := m.connections[id]
+	if !ok {
+		return nil, nil, ErrExchangeConnectionNotFound
+	}
+	copied := *connection
+	return &copied, m.sealed[id], nil
+}
+
+func (m *memoryConnectionStore) ListConnections(ctx context.Context, userID uuid.UUID) ([]*ExchangeConnection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	connections := make([]*ExchangeConnection, 0)
+	for _, connection := range m.connections {
+		if connection.UserID == userID {
+			copied := *connection
+			connections = append(connections, &copied)
+		}
+	}
+	return connections, nil
+}
+
+func (m *memoryConnectionStore) RevokeConnection(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	connection, ok := m.connections[id]
+	if !ok {
+		return ErrExchangeConnectionNotFound
+	}
+	connection.Status = ExchangeConnectionRevoked
+	connection.RevokedAt = &revokedAt
+	return nil
+}
+
+func newConnectionFixture(t *testing.T) (*ExchangeConnectionManager, *TradingBotEngine, *fakeOrderConnector, *memoryConnectionStore) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	encryption := security.NewEncryptionManager(logger, &security.EncryptionConfig{Algorithm: "AES-256-GCM"})
+	_, err := encryption.ImportKey(ExchangeCredentialsPurpose, bytes.Repeat([]byte{0x42}, 32))
+	require.NoError(t, err)
+
+	engine := NewTradingBotEngine(logger, &BotEngineConfig{MaxConcurrentBots: 10})
+	connector := &fakeOrderConnector{balances: []ExchangeBalance{{Asset: "USDT", Free: decimal.NewFromInt(100)}}}
+	store := newMemoryConnectionStore()
+	manager := NewExchangeConnectionManager(logger, store, encryption, engine, connector)
+	engine.SetExchangeConnectivity(manager, connector)
+	return manager, engine, connector, store
+}
+
+// stateRecorder records the states bots move to
+type stateRecorder func(from, to BotState)
+
+func (r stateRecorder) BotStateChanged(botID string, from, to BotState, reason string) { r(from, to) }
+func (r stateRecorder) BotOrderFilled(trade *BotTrade)                                 {}
+
+var testCredentials = ExchangeCredentials{APIKey: "public-key-abcd", APISecret: "very-secret"}
+
+func TestExchangeConnectionManager_Connect(t *testing.T) {
+	manager, _, connector, store := newConnectionFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, _, err := manager.Connect(ctx, userID, "kraken", "", testCredentials)
+	assert.ErrorIs(t, err, ErrUnsupportedExchange)
+	_, _, err = manager.Connect(ctx, userID, "fake", "", ExchangeCredentials{APIKey: "key"})
+	assert.ErrorIs(t, err, ErrInvalidExchangeCredentials)
+
+	connector.balanceErr = errors.New("invalid api key")
+	_, _, err = manager.Connect(ctx, userID, "fake", "", testCredentials)
+	assert.ErrorIs(t, err, ErrInvalidExchangeCredentials)
+	assert.Empty(t, store.connections, "rejected credentials are not stored")
+	connector.balanceErr = nil
+
+	connection, balances, err := manager.Connect(ctx, userID, " FAKE ", " main ", testCredentials)
+	require.NoError(t, err)
+	assert.Len(t, balances, 1)
+	assert.Equal(t, "fake", connection.Exchange)
+	assert.Equal(t, "main", connection.Label)
+	assert.Equal(t, ExchangeConnectionActive, connection.Status)
+
+	// Only the key suffix is shown and the stored credentials are sealed
+	assert.Equal(t, "****abcd", connection.KeySuffix)
+	encoded, err := json.Marshal(connection)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), testCredentials.APIKey)
+	assert.NotContains(t, string(store.sealed[connection.ID].Ciphertext), testCredentials.APISecret)
+	assert.Equal(t, "****", ExchangeCredentials{APIKey: "abc"}.MaskedKey())
+
+	credentials, err := manager.Credentials(ctx, connection.ID)
+	require.NoError(t, err)
+	assert.Equal(t, testCredentials, credentials)
+
+	listed, err := manager.ListConnections(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+	listed, err = manager.ListConnections(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestExchangeConnectionManager_AttachBot(t *testing.T) {
+	manager, engine, _, _ := newConnectionFixture(t)
+	ctx := context.Background()
+	owner, other := uuid.New(), uuid.New()
+	connection, _, err := manager.Connect(ctx, owner, "fake", "", testCredentials)
+	require.NoError(t, err)
+
+	owned, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake", OwnerID: owner}, StrategyDCA)
+	require.NoError(t, err)
+	unowned, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake"}, StrategyDCA)
+	require.NoError(t, err)
+	othersBot, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake", OwnerID: other}, StrategyDCA)
+	require.NoError(t, err)
+	elsewhere, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "binance", OwnerID: owner}, StrategyDCA)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, manager.AttachBot(ctx, other, connection.ID, owned.ID, false), ErrExchangeConnectionNotFound,
+		"other users' connections are not found")
+	assert.ErrorIs(t, manager.AttachBot(ctx, owner, connection.ID, othersBot.ID, true), ErrBotNotFound,
+		"other users' bots are not found, even to admins")
+	assert.ErrorIs(t, manager.AttachBot(ctx, owner, connection.ID, elsewhere.ID, false), ErrExchangeMismatch)
+	assert.ErrorIs(t, manager.AttachBot(ctx, owner, connection.ID, "missing", false), ErrBotNotFound)
+
+	require.NoError(t, manager.AttachBot(ctx, owner, connection.ID, owned.ID, false))
+	assert.Equal(t, connection.ID, owned.ConnectionID)
+
+	// Bots loaded from configuration have no owner until an admin attaches them
+	assert.ErrorIs(t, manager.AttachBot(ctx, owner, connection.ID, unowned.ID, false), ErrUnownedBot)
+	assert.Equal(t, uuid.Nil, unowned.ConnectionID)
+	require.NoError(t, manager.AttachBot(ctx, owner, connection.ID, unowned.ID, true))
+	assert.Equal(t, owner, unowned.UserID)
+	assert.Equal(t, connection.ID, unowned.ConnectionID)
+}
+
+func TestExchangeConnectionManager_RevokeConnection(t *testing.T) {
+	manager, engine, _, _ := newConnectionFixture(t)
+	ctx := context.Background()
+	owner := uuid.New()
+	connection, _, err := manager.Connect(ctx, owner, "fake", "", testCredentials)
+	require.NoError(t, err)
+	kept, _, err := manager.Connect(ctx, owner, "fake", "", testCredentials)
+	require.NoError(t, err)
+
+	var states []BotState
+	engine.AddEventListener(stateRecorder(func(from, to BotState) { states = append(states, to) }))
+
+	running, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake", Mode: ExecutionModeLive, OwnerID: owner}, StrategyDCA)
+	require.NoError(t, err)
+	idle, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake", Mode: ExecutionModeLive, OwnerID: owner}, StrategyDCA)
+	require.NoError(t, err)
+	other, err := engine.RegisterBot(ctx, &BotConfig{Exchange: "fake", Mode: ExecutionModeLive, OwnerID: owner}, StrategyDCA)
+	require.NoError(t, err)
+	require.NoError(t, manager.AttachBot(ctx, owner, connection.ID, running.ID, false))
+	require.NoError(t, manager.AttachBot(ctx, owner, connection.ID, idle.ID, false))
+	require.NoError(t, manager.AttachBot(ctx, owner, kept.ID, other.ID, false))
+	require.NoError(t, engine.StartBot(ctx, running.ID))
+	require.NoError(t, engine.StartBot(ctx, other.ID))
+
+	_, err = manager.RevokeConnection(ctx, uuid.New(), connection.ID)
+	assert.ErrorIs(t, err, ErrExchangeConnectionNotFound)
+
+	stopped, err := manager.RevokeConnection(ctx, owner, connection.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{running.ID, idle.ID}, stopped)
+
+	assert.Equal(t, StateError, running.State)
+	assert.Contains(t, running.LastError, "****abcd was revoked")
+	assert.Equal(t, uuid.Nil, running.ConnectionID)
+	assert.Equal(t, StateIdle, idle.State, "idle bots are detached without starting")
+	assert.Equal(t, uuid.Nil, idle.ConnectionID)
+	assert.Equal(t, StateRunning, other.State)
+	assert.Equal(t, kept.ID, other.ConnectionID)
+	assert.Equal(t, []BotState{StateRunning, StateRunning, StateError}, states)
+
+	// Revoked credentials are never decrypted again, so live orders fail
+	_, err = manager.Credentials(ctx, connection.ID)
+	assert.ErrorIs(t, err, ErrExchangeConnectionRevoked)
+	_, err = manager.Credentials(ctx, kept.ID)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, manager.AttachBot(ctx, owner, connection.ID, idle.ID, false), ErrExchangeConnectionRevoked)
+	assert.ErrorIs(t, engine.StartBot(ctx, idle.ID), ErrNoExchangeConnection)
+
+	// Revoking again stops nothing
+	stopped, err = manager.RevokeConnection(ctx, owner, connection.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stopped)
+}
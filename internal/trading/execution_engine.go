@@ -22,6 +22,13 @@ type ExecutionEngine struct {
 	isRunning     bool
 	stopChan      chan struct{}
 	metrics       *ExecutionMetrics
+
+	// Progress reports of algorithmic orders, kept for reportRetention after they finish
+	priceFeed       PriceFeed
+	reports         map[string]*ExecutionReport
+	reportRetention time.Duration
+
+	observer ExecutionObserver
 }
 
 // ExecutionOrder represents an order for execution
@@ -48,6 +55,9 @@ type ExecutionOrder struct {
 	Executions      []*ChildExecution      `json:"executions"`
 	CreatedAt       time.Time              `json:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at"`
+
+	// Portfolio the order trades for; fills are attributed to it in anomaly monitoring
+	PortfolioID string `json:"portfolio_id,omitempty"`
 }
 
 // ChildExecution represents a child order execution
@@ -169,6 +179,8 @@ func NewExecutionEngine(logger *observability.Logger) *ExecutionEngine {
 		metrics: &ExecutionMetrics{
 			LastUpdated: time.Now(),
 		},
+		reports:         make(map[string]*ExecutionReport),
+		reportRetention: defaultReportRetention,
 	}
 }
 
@@ -410,6 +422,9 @@ func (ep *ExecutionPool) executeOrder(ctx context.Context, engine *ExecutionEngi
 	} else {
 		order.Status = ExecutionStatusCompleted
 	}
+	if report, ok := engine.finishReport(order, err); ok {
+		engine.notifyObserver(ctx, order, report)
+	}
 
 	return &ExecutionResult{
 		Order:    order,
@@ -424,17 +439,18 @@ func (ep *ExecutionPool) executeOrder(ctx context.Context, engine *ExecutionEngi
 func (ep *ExecutionPool) executeTWAP(ctx context.Context, engine *ExecutionEngine, order *ExecutionOrder) error {
 	// Simplified TWAP implementation
 	duration := 60 * time.Minute // Default 1 hour
-	if d, ok := order.Parameters["duration_minutes"].(int); ok {
+	if d, ok := intParameter(order.Parameters, "duration_minutes"); ok && d > 0 {
 		duration = time.Duration(d) * time.Minute
 	}
 
 	sliceCount := 10
-	if s, ok := order.Parameters["slice_count"].(int); ok {
+	if s, ok := intParameter(order.Parameters, "slice_count"); ok && s > 0 {
 		sliceCount = s
 	}
 
 	sliceSize := order.Quantity.Div(decimal.NewFromInt(int64(sliceCount)))
 	interval := duration / time.Duration(sliceCount)
+	engine.startReport(ctx, order, sliceCount, interval*time.Duration(sliceCount-1))
 
 	for i := 0; i < sliceCount; i++ {
 		// Execute slice
@@ -451,17 +467,26 @@ func (ep *ExecutionPool) executeTWAP(ctx context.Context, engine *ExecutionEngin
 			ParentID:   order.ID,
 			Venue:      "default",
 			Quantity:   sliceSize,
-			Price:      order.Price, // Simplified
+			Price:      engine.marketPrice(ctx, order), // Simplified
 			ExecutedAt: time.Now(),
 			Status:     ExecutionStatusCompleted,
 		}
 
 		order.Executions = append(order.Executions, execution)
+		order.AveragePrice = order.AveragePrice.Mul(order.FilledQuantity).Add(execution.Price.Mul(sliceSize)).
+			Div(order.FilledQuantity.Add(sliceSize))
 		order.FilledQuantity = order.FilledQuantity.Add(sliceSize)
+		engine.recordSlice(order, execution, interval)
 
 		// Wait for next slice
 		if i < sliceCount-1 {
-			time.Sleep(interval)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-engine.stopChan:
+				return fmt.Errorf("execution engine stopped after %d of %d slices", i+1, sliceCount)
+			case <-time.After(interval):
+			}
 		}
 	}
 
@@ -482,6 +507,14 @@ func (ep *ExecutionPool) executeIceberg(ctx context.Context, engine *ExecutionEn
 		visibleSize = decimal.NewFromFloat(v)
 	}
 
+	if !visibleSize.IsPositive() {
+		return fmt.Errorf("visible_size must be positive")
+	}
+
+	sliceInterval := 100 * time.Millisecond
+	slices := int(decimal.NewFromInt(1).Div(visibleSize).Ceil().IntPart())
+	engine.startReport(ctx, order, slices, sliceInterval*time.Duration(slices))
+
 	remaining := order.Quantity
 	for remaining.GreaterThan(decimal.Zero) {
 		sliceSize := order.Quantity.Mul(visibleSize)
@@ -502,9 +535,10 @@ func (ep *ExecutionPool) executeIceberg(ctx context.Context, engine *ExecutionEn
 		order.Executions = append(order.Executions, execution)
 		order.FilledQuantity = order.FilledQuantity.Add(sliceSize)
 		remaining = remaining.Sub(sliceSize)
+		engine.recordSlice(order, execution, sliceInterval)
 
 		// Small delay between slices
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(sliceInterval)
 	}
 
 	return nil
@@ -518,6 +552,8 @@ func (ep *ExecutionPool) executeSniper(ctx context.Context, engine *ExecutionEng
 
 // executeMarket executes a market order
 func (ep *ExecutionPool) executeMarket(ctx context.Context, engine *ExecutionEngine, order *ExecutionOrder) error {
+	engine.startReport(ctx, order, 1, 0)
+
 	execution := &ChildExecution{
 		ID:         uuid.New().String(),
 		ParentID:   order.ID,
@@ -531,6 +567,7 @@ func (ep *ExecutionPool) executeMarket(ctx context.Context, engine *ExecutionEng
 	order.Executions = append(order.Executions, execution)
 	order.FilledQuantity = order.Quantity
 	order.AveragePrice = order.Price
+	engine.recordSlice(order, execution, 0)
 
 	return nil
 }
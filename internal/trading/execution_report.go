@@ -0,0 +1,219 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrExecutionReportNotFound is returned for an unknown order or a report past its retention
+var ErrExecutionReportNotFound = errors.New("execution report not found")
+
+// defaultReportRetention is how long the final report of a finished order is kept
+const defaultReportRetention = 24 * time.Hour
+
+// ExecutionReport tracks the progress of an algorithmic order as its slices execute
+type ExecutionReport struct {
+	OrderID           string          `json:"order_id"`
+	AlgorithmType     AlgorithmType   `json:"algorithm_type"`
+	Symbol            string          `json:"symbol"`
+	Side              OrderSide       `json:"side"`
+	Status            ExecutionStatus `json:"status"`
+	SlicesPlanned     int             `json:"slices_planned"`
+	SlicesFilled      int             `json:"slices_filled"`
+	Quantity          decimal.Decimal `json:"quantity"`
+	FilledQuantity    decimal.Decimal `json:"filled_quantity"`
+	RemainingQuantity decimal.Decimal `json:"remaining_quantity"`
+	ArrivalPrice      decimal.Decimal `json:"arrival_price"`
+	AveragePrice      decimal.Decimal `json:"average_price"`
+	SlippageBps       decimal.Decimal `json:"slippage_bps"` // positive when filling worse than arrival
+	StartedAt         time.Time       `json:"started_at"`
+	LastFillAt        time.Time       `json:"last_fill_at,omitempty"`
+	EstimatedEnd      time.Time       `json:"estimated_end,omitempty"`
+	CompletedAt       time.Time       `json:"completed_at,omitempty"`
+	Error             string          `json:"error,omitempty"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// SetPriceFeed sets the market price source for arrival prices and simulated slice fills.
+// Without one, slices fill at the order price.
+func (ee *ExecutionEngine) SetPriceFeed(feed PriceFeed) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+	ee.priceFeed = feed
+}
+
+// ExecutionObserver is notified with the final report of every algorithmic order
+type ExecutionObserver interface {
+	OnExecutionReport(ctx context.Context, order *ExecutionOrder, report ExecutionReport)
+}
+
+// SetExecutionObserver sets the observer of finished orders, such as a fill anomaly monitor
+func (ee *ExecutionEngine) SetExecutionObserver(observer ExecutionObserver) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+	ee.observer = observer
+}
+
+// notifyObserver passes the final report of an order to the observer, if one is set
+func (ee *ExecutionEngine) notifyObserver(ctx context.Context, order *ExecutionOrder, report ExecutionReport) {
+	ee.mu.RLock()
+	observer := ee.observer
+	ee.mu.RUnlock()
+
+	if observer != nil {
+		observer.OnExecutionReport(ctx, order, report)
+	}
+}
+
+// SetReportRetention sets how long final execution reports are kept after an order finishes
+func (ee *ExecutionEngine) SetReportRetention(retention time.Duration) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+	ee.reportRetention = retention
+}
+
+// GetExecutionReport returns the current progress of an order, or its final report while
+// within the retention window
+func (ee *ExecutionEngine) GetExecutionReport(orderID string) (*ExecutionReport, error) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+
+	ee.pruneReports(time.Now())
+
+	report, exists := ee.reports[orderID]
+	if !exists {
+		return nil, ErrExecutionReportNotFound
+	}
+	copied := *report
+	return &copied, nil
+}
+
+// marketPrice returns the feed price for the order symbol, falling back to the order price
+func (ee *ExecutionEngine) marketPrice(ctx context.Context, order *ExecutionOrder) decimal.Decimal {
+	ee.mu.RLock()
+	feed := ee.priceFeed
+	ee.mu.RUnlock()
+
+	if feed != nil {
+		if price, err := feed.GetPrice(ctx, order.Symbol); err == nil && price.IsPositive() {
+			return price
+		}
+	}
+	return order.Price
+}
+
+// startReport opens the report for an order about to execute slicesPlanned slices over duration
+func (ee *ExecutionEngine) startReport(ctx context.Context, order *ExecutionOrder, slicesPlanned int, duration time.Duration) {
+	arrival := ee.marketPrice(ctx, order)
+	now := time.Now()
+
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+
+	ee.reports[order.ID] = &ExecutionReport{
+		OrderID:           order.ID,
+		AlgorithmType:     order.AlgorithmType,
+		Symbol:            order.Symbol,
+		Side:              order.Side,
+		Status:            ExecutionStatusExecuting,
+		SlicesPlanned:     slicesPlanned,
+		Quantity:          order.Quantity,
+		FilledQuantity:    decimal.Zero,
+		RemainingQuantity: order.Quantity,
+		ArrivalPrice:      arrival,
+		StartedAt:         now,
+		EstimatedEnd:      now.Add(duration),
+		UpdatedAt:         now,
+	}
+}
+
+// recordSlice adds a filled slice to the order's report and re-estimates the completion time
+// from the interval between slices
+func (ee *ExecutionEngine) recordSlice(order *ExecutionOrder, execution *ChildExecution, interval time.Duration) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+
+	report, exists := ee.reports[order.ID]
+	if !exists {
+		return
+	}
+
+	notional := report.AveragePrice.Mul(report.FilledQuantity).Add(execution.Price.Mul(execution.Quantity))
+	report.SlicesFilled++
+	report.FilledQuantity = report.FilledQuantity.Add(execution.Quantity)
+	report.RemainingQuantity = decimal.Max(report.Quantity.Sub(report.FilledQuantity), decimal.Zero)
+	if report.FilledQuantity.IsPositive() {
+		report.AveragePrice = notional.Div(report.FilledQuantity)
+	}
+	report.SlippageBps = slippageBps(report.Side, report.ArrivalPrice, report.AveragePrice)
+	report.LastFillAt = execution.ExecutedAt
+
+	remainingSlices := report.SlicesPlanned - report.SlicesFilled
+	if remainingSlices < 0 {
+		remainingSlices = 0
+	}
+	report.EstimatedEnd = execution.ExecutedAt.Add(time.Duration(remainingSlices) * interval)
+	report.UpdatedAt = time.Now()
+}
+
+// finishReport records the final status of an order and starts its retention window. A copy
+// of the final report is returned.
+func (ee *ExecutionEngine) finishReport(order *ExecutionOrder, err error) (ExecutionReport, bool) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+
+	report, exists := ee.reports[order.ID]
+	if !exists {
+		return ExecutionReport{}, false
+	}
+
+	now := time.Now()
+	report.Status = order.Status
+	report.CompletedAt = now
+	report.EstimatedEnd = time.Time{}
+	report.UpdatedAt = now
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	ee.pruneReports(now)
+	return *report, true
+}
+
+// pruneReports drops final reports older than the retention window. The caller must hold ee.mu.
+func (ee *ExecutionEngine) pruneReports(now time.Time) {
+	for orderID, report := range ee.reports {
+		if !report.CompletedAt.IsZero() && now.Sub(report.CompletedAt) > ee.reportRetention {
+			delete(ee.reports, orderID)
+		}
+	}
+}
+
+// slippageBps measures the average fill against the arrival price, positive when adverse
+func slippageBps(side OrderSide, arrival, average decimal.Decimal) decimal.Decimal {
+	if !arrival.IsPositive() || !average.IsPositive() {
+		return decimal.Zero
+	}
+	diff := average.Sub(arrival)
+	if side == OrderSideSell {
+		diff = diff.Neg()
+	}
+	return diff.Div(arrival).Mul(decimal.NewFromInt(10000))
+}
+
+// intParameter reads an integer algorithm parameter that may have been decoded from JSON
+func intParameter(parameters map[string]interface{}, name string) (int, bool) {
+	switch value := parameters[name].(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		return int(value), true
+	default:
+		return 0, false
+	}
+}
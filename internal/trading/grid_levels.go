@@ -0,0 +1,52 @@
+package trading
+
+import (
+	"context"
+
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+)
+
+// GridStore persists the level maps of grid bots, so a restarted bot resumes its grid without
+// buying again at levels that already hold inventory
+type GridStore interface {
+	// SaveGrid inserts or replaces the grid of one of the bot's trading pairs
+	SaveGrid(ctx context.Context, botID string, grid *strategies.GridSnapshot) error
+	ListGrids(ctx context.Context, botID string) ([]*strategies.GridSnapshot, error)
+}
+
+// SetGridStore persists the levels of grid strategies attached from now on
+func (tbe *TradingBotEngine) SetGridStore(store GridStore) {
+	tbe.mu.Lock()
+	defer tbe.mu.Unlock()
+	tbe.grids = store
+}
+
+// persistGrids restores a grid strategy's levels from the store and saves them as they change
+func (tbe *TradingBotEngine) persistGrids(ctx context.Context, botID string, gs *strategies.GridStrategy) {
+	tbe.mu.RLock()
+	store := tbe.grids
+	tbe.mu.RUnlock()
+	if store == nil {
+		return
+	}
+
+	grids, err := store.ListGrids(ctx, botID)
+	if err != nil {
+		tbe.logger.Warn(ctx, "Failed to restore grid levels", map[string]interface{}{
+			"bot_id": botID,
+			"error":  err.Error(),
+		})
+	} else {
+		gs.Restore(grids)
+	}
+
+	gs.OnChange(func(grid *strategies.GridSnapshot) {
+		if err := store.SaveGrid(context.Background(), botID, grid); err != nil {
+			tbe.logger.Warn(context.Background(), "Failed to save grid levels", map[string]interface{}{
+				"bot_id": botID,
+				"symbol": grid.Symbol,
+				"error":  err.Error(),
+			})
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package trading
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryGridStore keeps the latest grid of each bot and pair in memory
+type memoryGridStore struct {
+	grids map[string]map[string]*strategies.GridSnapshot
+	saves int
+	mu    sync.Mutex
+}
+
+func (m *memoryGridStore) SaveGrid(ctx context.Context, botID string, grid *strategies.GridSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.grids[botID] == nil {
+		m.grids[botID] = make(map[string]*strategies.GridSnapshot)
+	}
+	m.grids[botID][grid.Symbol] = grid
+	m.saves++
+	return nil
+}
+
+func (m *memoryGridStore) ListGrids(ctx context.Context, botID string) ([]*strategies.GridSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	grids := make([]*strategies.GridSnapshot, 0)
+	for _, grid := range m.grids[botID] {
+		grids = append(grids, grid)
+	}
+	sort.Slice(grids, func(i, j int) bool { return grids[i].Symbol < grids[j].Symbol })
+	return grids, nil
+}
+
+func gridSignals(t *testing.T, gs *strategies.GridStrategy, price int64) []*strategies.TradingSignal {
+	result, err := gs.Execute(context.Background(), &strategies.MarketData{Symbol: "BTC/USDT", Price: decimal.NewFromInt(price)})
+	require.NoError(t, err)
+	return strategies.Signals(result)
+}
+
+func TestGridStore_RestartResumesGrid(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	engine := NewTradingBotEngine(logger, &BotEngineConfig{MaxConcurrentBots: 10})
+	store := &memoryGridStore{grids: make(map[string]map[string]*strategies.GridSnapshot)}
+	engine.SetGridStore(store)
+	bot, err := engine.RegisterBot(context.Background(), &BotConfig{TradingPairs: []string{"BTC/USDT"}}, StrategyGrid)
+	require.NoError(t, err)
+
+	// Levels buy at 100, 110, 120 and 130, each selling one level up
+	gridConfig := &strategies.GridConfig{GridLevels: 5, LowerPrice: decimal.NewFromInt(100), UpperPrice: decimal.NewFromInt(140), OrderAmount: decimal.NewFromInt(120)}
+	first := strategies.NewGridStrategy(logger, gridConfig)
+	require.NoError(t, engine.AttachStrategy(bot.ID, first))
+
+	assert.Empty(t, gridSignals(t, first, 125))
+	buys := gridSignals(t, first, 118)
+	require.Len(t, buys, 1)
+	first.OrderFilled(buys[0].ID, decimal.NewFromInt(1), decimal.NewFromInt(120))
+
+	// The service stops while the next buy is in flight
+	require.Len(t, gridSignals(t, first, 108), 1)
+	assert.Positive(t, store.saves)
+
+	restarted := strategies.NewGridStrategy(logger, gridConfig)
+	require.NoError(t, engine.AttachStrategy(bot.ID, restarted))
+
+	grids := restarted.Grids()
+	require.Len(t, grids, 1)
+	levels := grids[0].Levels
+	require.Len(t, levels, 4)
+	assert.Equal(t, strategies.GridLevelBuyOpen, levels[0].State)
+	assert.Equal(t, strategies.GridLevelBuyPending, levels[1].State)
+	assert.Equal(t, strategies.GridLevelHolding, levels[2].State)
+	assert.True(t, decimal.NewFromInt(1).Equal(levels[2].Quantity))
+	assert.True(t, decimal.NewFromInt(1).Equal(grids[0].Inventory))
+
+	// Neither the held level nor the one with an order in flight buys again
+	assert.Empty(t, gridSignals(t, restarted, 108))
+	sells := gridSignals(t, restarted, 130)
+	require.Len(t, sells, 1)
+	assert.Equal(t, strategies.ActionSell, sells[0].Action)
+	assert.True(t, decimal.NewFromInt(1).Equal(sells[0].Amount))
+
+	restarted.OrderFilled(sells[0].ID, decimal.NewFromInt(1), decimal.NewFromInt(130))
+	saved, err := store.ListGrids(context.Background(), bot.ID)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, 1, saved[0].RoundTrips)
+	assert.True(t, decimal.NewFromInt(10).Equal(saved[0].GridProfit), saved[0].GridProfit.String())
+}
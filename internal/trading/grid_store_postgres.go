@@ -0,0 +1,58 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ai-agentic-browser/internal/trading/strategies"
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// postgresGridStore implements GridStore using the bot_grids table
+type postgresGridStore struct {
+	db *database.DB
+}
+
+func NewPostgresGridStore(db *database.DB) GridStore {
+	return &postgresGridStore{db: db}
+}
+
+func (s *postgresGridStore) SaveGrid(ctx context.Context, botID string, grid *strategies.GridSnapshot) error {
+	data, err := json.Marshal(grid)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecWithMetrics(ctx, `
+		INSERT INTO bot_grids (bot_id, symbol, data, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (bot_id, symbol) DO UPDATE SET data = EXCLUDED.data, updated_at = EXCLUDED.updated_at
+	`, botID, grid.Symbol, data)
+	return err
+}
+
+func (s *postgresGridStore) ListGrids(ctx context.Context, botID string) ([]*strategies.GridSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT data
+		FROM bot_grids
+		WHERE bot_id = $1
+		ORDER BY symbol
+	`, botID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grids := make([]*strategies.GridSnapshot, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var grid strategies.GridSnapshot
+		if err := json.Unmarshal(data, &grid); err != nil {
+			return nil, err
+		}
+		grids = append(grids, &grid)
+	}
+	return grids, rows.Err()
+}
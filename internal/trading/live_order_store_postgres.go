@@ -0,0 +1,64 @@
+package trading
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// postgresLiveOrderStore implements LiveOrderStore using the live_orders table
+type postgresLiveOrderStore struct {
+	db *database.DB
+}
+
+func NewPostgresLiveOrderStore(db *database.DB) LiveOrderStore {
+	return &postgresLiveOrderStore{db: db}
+}
+
+func (s *postgresLiveOrderStore) SaveLiveOrder(ctx context.Context, order *LiveOrder) error {
+	_, err := s.db.ExecWithMetrics(ctx, `
+		INSERT INTO live_orders (id, client_order_id, bot_id, connection_id, exchange, exchange_order_id, symbol, side,
+			order_type, quantity, price, status, filled_quantity, average_price, commission, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (id) DO UPDATE SET
+			exchange_order_id = EXCLUDED.exchange_order_id,
+			status = EXCLUDED.status,
+			filled_quantity = EXCLUDED.filled_quantity,
+			average_price = EXCLUDED.average_price,
+			commission = EXCLUDED.commission,
+			error = EXCLUDED.error,
+			updated_at = EXCLUDED.updated_at
+	`, order.ID, order.ClientOrderID, order.BotID, order.ConnectionID, order.Exchange, order.ExchangeOrderID,
+		order.Symbol, order.Side, order.OrderType, order.Quantity, order.Price, order.Status,
+		order.FilledQuantity, order.AveragePrice, order.TotalCommission, order.Error, order.CreatedAt, order.UpdatedAt)
+	return err
+}
+
+func (s *postgresLiveOrderStore) ListLiveOrders(ctx context.Context, since time.Time) ([]*LiveOrder, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, client_order_id, bot_id, connection_id, exchange, exchange_order_id, symbol, side,
+			order_type, quantity, price, status, filled_quantity, average_price, commission, error, created_at, updated_at
+		FROM live_orders
+		WHERE created_at >= $1
+		ORDER BY created_at
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*LiveOrder, 0)
+	for rows.Next() {
+		order := &LiveOrder{}
+		if err := rows.Scan(&order.ID, &order.ClientOrderID, &order.BotID, &order.ConnectionID, &order.Exchange,
+			&order.ExchangeOrderID, &order.Symbol, &order.Side, &order.OrderType, &order.Quantity, &order.Price,
+			&order.Status, &order.FilledQuantity, &order.AveragePrice, &order.TotalCommission, &order.Error,
+			&order.CreatedAt, &order.UpdatedAt); err != nil {
+			return nil, err
+		}
+		order.StrategyID = order.BotID
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
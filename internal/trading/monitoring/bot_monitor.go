@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ai-agentic-browser/internal/trading"
+	"github.com/ai-agentic-browser/internal/trading/strategies"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/shopspring/decimal"
 )
@@ -36,6 +37,9 @@ type TradingBotMonitor struct {
 	metricsCollector *MetricsCollector
 	alertManager     *AlertManager
 	dashboardManager *DashboardManager
+
+	// Pushed bot events
+	events *EventBus
 }
 
 // MonitoringConfig holds configuration for trading bot monitoring
@@ -58,6 +62,12 @@ type MonitoringConfig struct {
 	EnableDashboard      bool `yaml:"enable_dashboard"`
 	EnableMetricsExport  bool `yaml:"enable_metrics_export"`
 	EnableProfiling      bool `yaml:"enable_profiling"`
+
+	// EventBufferSize bounds the pending pushed events of each subscriber
+	EventBufferSize int `yaml:"event_buffer_size"`
+
+	// AlertOnOrderDiscrepancies raises an alert for every discrepancy order reconciliation finds
+	AlertOnOrderDiscrepancies bool `yaml:"alert_on_order_discrepancies"`
 }
 
 // PerformanceThresholds defines performance alert thresholds
@@ -85,6 +95,7 @@ type BotMetrics struct {
 	BotID     string    `json:"bot_id"`
 	Strategy  string    `json:"strategy"`
 	State     string    `json:"state"`
+	Mode      string    `json:"mode"`
 	Timestamp time.Time `json:"timestamp"`
 
 	// Performance metrics
@@ -93,6 +104,9 @@ type BotMetrics struct {
 	Trading     *TradingMetrics   `json:"trading"`
 	System      *BotSystemMetrics `json:"system"`
 
+	// Per trading pair metrics of grid bots
+	Grid []*GridMetrics `json:"grid,omitempty"`
+
 	// Health indicators
 	Health *HealthIndicators `json:"health"`
 	Alerts []*BotAlert       `json:"alerts"`
@@ -144,6 +158,22 @@ type TradingMetrics struct {
 	LastTradeTime    time.Time       `json:"last_trade_time"`
 }
 
+// GridMetrics tracks one trading pair of a grid bot. A round trip is a level's buy followed by
+// its sell one level up; grid profit is the price difference earned on them, before fees.
+type GridMetrics struct {
+	Symbol        string          `json:"symbol"`
+	Levels        int             `json:"levels"`
+	HoldingLevels int             `json:"holding_levels"`
+	PendingOrders int             `json:"pending_orders"`
+	RoundTrips    int             `json:"round_trips"`
+	GridProfit    decimal.Decimal `json:"grid_profit"`
+	Inventory     decimal.Decimal `json:"inventory"`
+	PriceInRange  bool            `json:"price_in_range"`
+	LastPrice     decimal.Decimal `json:"last_price"`
+	LowerPrice    decimal.Decimal `json:"lower_price"`
+	UpperPrice    decimal.Decimal `json:"upper_price"`
+}
+
 // BotSystemMetrics tracks system-level metrics for a bot
 type BotSystemMetrics struct {
 	CPUUsage          float64       `json:"cpu_usage"`
@@ -223,6 +253,9 @@ type PortfolioMetrics struct {
 	TotalBots         int                        `json:"total_bots"`
 	StrategyBreakdown map[string]decimal.Decimal `json:"strategy_breakdown"`
 	AssetAllocation   map[string]decimal.Decimal `json:"asset_allocation"`
+
+	// Holdings of all bots by symbol, with their combined cost and average entry price
+	CostBasis map[string]*trading.CostBasis `json:"cost_basis,omitempty"`
 }
 
 // SystemMetrics tracks system-level metrics
@@ -273,7 +306,7 @@ func NewTradingBotMonitor(
 		config = getDefaultMonitoringConfig()
 	}
 
-	return &TradingBotMonitor{
+	tbm := &TradingBotMonitor{
 		logger:             logger,
 		config:             config,
 		botEngine:          botEngine,
@@ -287,7 +320,20 @@ func NewTradingBotMonitor(
 		metricsCollector:   NewMetricsCollector(logger),
 		alertManager:       NewAlertManager(logger),
 		dashboardManager:   NewDashboardManager(logger),
+		events:             NewEventBus(config.EventBufferSize),
 	}
+
+	// State changes, fills, triggered risk limits and limit changes are pushed to event
+	// subscribers
+	if botEngine != nil {
+		botEngine.AddEventListener(tbm)
+	}
+	if riskManager != nil {
+		riskManager.AddAlertChannel(&riskEventChannel{bus: tbm.events})
+		riskManager.AddRiskLimitListener(tbm)
+	}
+
+	return tbm
 }
 
 // Start starts the trading bot monitor
@@ -362,6 +408,7 @@ func getDefaultMonitoringConfig() *MonitoringConfig {
 		EnableDashboard:      true,
 		EnableMetricsExport:  true,
 		EnableProfiling:      false,
+		EventBufferSize:      defaultEventBufferSize,
 	}
 }
 
@@ -480,6 +527,7 @@ func (tbm *TradingBotMonitor) collectBotMetrics(ctx context.Context, bot *tradin
 		BotID:     bot.ID,
 		Strategy:  string(bot.Strategy),
 		State:     string(bot.State),
+		Mode:      string(bot.Mode),
 		Timestamp: time.Now(),
 	}
 
@@ -497,6 +545,9 @@ func (tbm *TradingBotMonitor) collectBotMetrics(ctx context.Context, bot *tradin
 	// Collect system metrics
 	metrics.System = tbm.collectBotSystemMetrics(ctx, bot)
 
+	// Collect grid metrics
+	metrics.Grid = tbm.collectGridMetrics(bot)
+
 	// Calculate health indicators
 	metrics.Health = tbm.calculateHealthIndicators(metrics)
 
@@ -606,7 +657,27 @@ func (tbm *TradingBotMonitor) updateDashboard(ctx context.Context) {
 
 // collectPortfolioMetrics collects portfolio-level metrics
 func (tbm *TradingBotMonitor) collectPortfolioMetrics(ctx context.Context, bots []*trading.TradingBot) *PortfolioMetrics {
-	return tbm.metricsCollector.CollectPortfolioMetrics(ctx, bots)
+	metrics := tbm.metricsCollector.CollectPortfolioMetrics(ctx, bots)
+	metrics.CostBasis = collectCostBasis(bots)
+	return metrics
+}
+
+// collectCostBasis combines the cost basis of the bots' holdings by symbol
+func collectCostBasis(bots []*trading.TradingBot) map[string]*trading.CostBasis {
+	combined := make(map[string]*trading.CostBasis)
+	for _, bot := range bots {
+		for _, basis := range bot.CostBasis() {
+			total, ok := combined[basis.Symbol]
+			if !ok {
+				total = &trading.CostBasis{Symbol: basis.Symbol}
+				combined[basis.Symbol] = total
+			}
+			total.Quantity = total.Quantity.Add(basis.Quantity)
+			total.TotalCost = total.TotalCost.Add(basis.TotalCost)
+			total.AverageEntry = total.TotalCost.Div(total.Quantity)
+		}
+	}
+	return combined
 }
 
 // collectSystemMetrics collects system-level metrics
@@ -619,6 +690,39 @@ func (tbm *TradingBotMonitor) collectTradingMetrics(ctx context.Context, bot *tr
 	return tbm.metricsCollector.CollectTradingMetrics(ctx, bot)
 }
 
+// collectGridMetrics collects the metrics of each trading pair of a grid bot
+func (tbm *TradingBotMonitor) collectGridMetrics(bot *trading.TradingBot) []*GridMetrics {
+	grids := bot.Grid()
+	if grids == nil {
+		return nil
+	}
+
+	metrics := make([]*GridMetrics, 0, len(grids))
+	for _, grid := range grids {
+		gridMetrics := &GridMetrics{
+			Symbol:       grid.Symbol,
+			Levels:       len(grid.Levels),
+			RoundTrips:   grid.RoundTrips,
+			GridProfit:   grid.GridProfit,
+			Inventory:    grid.Inventory,
+			PriceInRange: grid.LastPrice.GreaterThanOrEqual(grid.LowerPrice) && grid.LastPrice.LessThanOrEqual(grid.UpperPrice),
+			LastPrice:    grid.LastPrice,
+			LowerPrice:   grid.LowerPrice,
+			UpperPrice:   grid.UpperPrice,
+		}
+		for _, level := range grid.Levels {
+			switch level.State {
+			case strategies.GridLevelHolding:
+				gridMetrics.HoldingLevels++
+			case strategies.GridLevelBuyPending, strategies.GridLevelSellPending:
+				gridMetrics.PendingOrders++
+			}
+		}
+		metrics = append(metrics, gridMetrics)
+	}
+	return metrics
+}
+
 // collectBotSystemMetrics collects system metrics for a bot
 func (tbm *TradingBotMonitor) collectBotSystemMetrics(ctx context.Context, bot *trading.TradingBot) *BotSystemMetrics {
 	return tbm.metricsCollector.CollectBotSystemMetrics(ctx, bot)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/trading"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/shopspring/decimal"
 )
@@ -116,6 +117,9 @@ type RiskDashboardMetrics struct {
 	ConcentrationRisk map[string]decimal.Decimal `json:"concentration_risk"`
 	CorrelationMatrix map[string]map[string]decimal.Decimal `json:"correlation_matrix"`
 	RiskDistribution  map[string]int             `json:"risk_distribution"`
+
+	// Applied changes of bots' risk limits, newest first
+	LimitChanges []*trading.RiskLimitChange `json:"limit_changes"`
 }
 
 // TradingActivitySummary provides trading activity summary
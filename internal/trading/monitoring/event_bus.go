@@ -0,0 +1,267 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/trading"
+)
+
+// BotEventType identifies the kind of a pushed bot event
+type BotEventType string
+
+const (
+	BotEventStarted       BotEventType = "bot.started"
+	BotEventStopped       BotEventType = "bot.stopped"
+	BotEventError         BotEventType = "bot.error"
+	BotEventRecovered     BotEventType = "bot.recovered"
+	BotEventOrderFilled   BotEventType = "order.filled"
+	BotEventRiskTriggered BotEventType = "risk.triggered"
+
+	BotEventOrderDiscrepancy  BotEventType = "order.discrepancy"
+	BotEventRiskLimitsChanged BotEventType = "risk.limits_changed"
+)
+
+// AllBots subscribes to the events of every bot
+const AllBots = "all"
+
+// defaultEventBufferSize bounds the pending events of a subscriber when none is configured
+const defaultEventBufferSize = 256
+
+// BotEvent is a bot state change, order fill, triggered risk limit, order discrepancy or change
+// of a bot's risk limits.
+// Sequence numbers are assigned per subscription and increase by one for every event matching
+// its filter, including events dropped because the subscriber fell behind, so a gap tells the
+// client it missed events.
+type BotEvent struct {
+	Sequence  uint64       `json:"sequence"`
+	Type      BotEventType `json:"type"`
+	BotID     string       `json:"bot_id,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+	Data      interface{}  `json:"data,omitempty"`
+}
+
+// BotStateChange is the payload of bot state events
+type BotStateChange struct {
+	From   trading.BotState `json:"from"`
+	To     trading.BotState `json:"to"`
+	Reason string           `json:"reason,omitempty"`
+}
+
+// EventBus fans bot events out to subscribers. Publishing never blocks: each subscriber has a
+// bounded buffer and events that do not fit are dropped for that subscriber only.
+type EventBus struct {
+	bufferSize  int
+	subscribers map[*EventSubscription]struct{}
+	mu          sync.RWMutex
+}
+
+// EventSubscription receives the events of selected bots from an EventBus
+type EventSubscription struct {
+	bus      *EventBus
+	events   chan BotEvent
+	allBots  bool
+	botIDs   map[string]bool
+	sequence uint64
+	dropped  uint64
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewEventBus creates an event bus whose subscribers buffer up to bufferSize events
+func NewEventBus(bufferSize int) *EventBus {
+	if bufferSize <= 0 {
+		bufferSize = defaultEventBufferSize
+	}
+	return &EventBus{
+		bufferSize:  bufferSize,
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// Subscribe creates a subscription to the events of the given bots. Passing no bot IDs or
+// AllBots subscribes to every bot.
+func (b *EventBus) Subscribe(botIDs ...string) *EventSubscription {
+	sub := &EventSubscription{
+		bus:    b,
+		events: make(chan BotEvent, b.bufferSize),
+		botIDs: make(map[string]bool),
+	}
+	sub.Subscribe(botIDs...)
+	if len(botIDs) == 0 {
+		sub.allBots = true
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Publish delivers an event to every matching subscriber without waiting for slow consumers
+func (b *EventBus) Publish(event BotEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		sub.deliver(event)
+	}
+}
+
+// SubscriberCount returns the number of open subscriptions
+func (b *EventBus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// Events returns the channel of delivered events. It is closed when the subscription closes.
+func (s *EventSubscription) Events() <-chan BotEvent {
+	return s.events
+}
+
+// Subscribe adds bots to the subscription; AllBots selects every bot
+func (s *EventSubscription) Subscribe(botIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, botID := range botIDs {
+		if botID == AllBots {
+			s.allBots = true
+			continue
+		}
+		if botID != "" {
+			s.botIDs[botID] = true
+		}
+	}
+}
+
+// Unsubscribe removes bots from the subscription; AllBots clears the all-bots selection
+func (s *EventSubscription) Unsubscribe(botIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, botID := range botIDs {
+		if botID == AllBots {
+			s.allBots = false
+			continue
+		}
+		delete(s.botIDs, botID)
+	}
+}
+
+// Dropped returns the number of events dropped because the subscriber's buffer was full
+func (s *EventSubscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Close removes the subscription from the bus and closes its event channel
+func (s *EventSubscription) Close() {
+	s.bus.mu.Lock()
+	delete(s.bus.subscribers, s)
+	s.bus.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
+
+// deliver sequences and queues an event if it matches the subscription, dropping it when the
+// buffer is full
+func (s *EventSubscription) deliver(event BotEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || !s.matches(event.BotID) {
+		return
+	}
+
+	s.sequence++
+	event.Sequence = s.sequence
+	select {
+	case s.events <- event:
+	default:
+		s.dropped++
+	}
+}
+
+// matches reports whether the subscription selects a bot. Events without a bot, such as
+// portfolio-wide risk limits, go to every subscriber. The caller must hold s.mu.
+func (s *EventSubscription) matches(botID string) bool {
+	return s.allBots || botID == "" || s.botIDs[botID]
+}
+
+// BotStateChanged publishes bot state transitions
+func (tbm *TradingBotMonitor) BotStateChanged(botID string, from, to trading.BotState, reason string) {
+	eventType := BotEventRecovered
+	switch to {
+	case trading.StateRunning:
+		if from != trading.StateError {
+			eventType = BotEventStarted
+		}
+	case trading.StateStopped:
+		eventType = BotEventStopped
+	case trading.StateError:
+		eventType = BotEventError
+	default:
+		return
+	}
+
+	tbm.events.Publish(BotEvent{
+		Type:  eventType,
+		BotID: botID,
+		Data:  BotStateChange{From: from, To: to, Reason: reason},
+	})
+}
+
+// BotOrderFilled publishes order fills
+func (tbm *TradingBotMonitor) BotOrderFilled(trade *trading.BotTrade) {
+	tbm.events.Publish(BotEvent{
+		Type:      BotEventOrderFilled,
+		BotID:     trade.BotID,
+		Timestamp: trade.ExecutedAt,
+		Data:      trade,
+	})
+}
+
+// SubscribeEvents subscribes to the pushed events of the given bots, or of every bot when
+// none or AllBots is given. Callers must close the subscription when done.
+func (tbm *TradingBotMonitor) SubscribeEvents(botIDs ...string) *EventSubscription {
+	return tbm.events.Subscribe(botIDs...)
+}
+
+// riskEventChannel publishes risk alerts raised by the risk manager as bot events
+type riskEventChannel struct {
+	bus *EventBus
+}
+
+func (c *riskEventChannel) SendAlert(ctx context.Context, alert *trading.RiskAlert) error {
+	// The alert manager keeps updating its alert, so subscribers get a snapshot
+	snapshot := *alert
+	c.bus.Publish(BotEvent{
+		Type:      BotEventRiskTriggered,
+		BotID:     snapshot.BotID,
+		Timestamp: snapshot.CreatedAt,
+		Data:      &snapshot,
+	})
+	return nil
+}
+
+func (c *riskEventChannel) GetType() string {
+	return "event_bus"
+}
+
+func (c *riskEventChannel) IsEnabled() bool {
+	return true
+}
@@ -0,0 +1,99 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain returns the events queued on a subscription
+func drain(sub *EventSubscription) []BotEvent {
+	var events []BotEvent
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventBus_DropsEventsBeyondTheBuffer(t *testing.T) {
+	bus := NewEventBus(2)
+	slow := bus.Subscribe()
+	defer slow.Close()
+	fast := bus.Subscribe()
+	defer fast.Close()
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-1"})
+		if i == 0 {
+			require.Len(t, drain(fast), 1)
+		}
+	}
+
+	// The slow subscriber keeps the first two events and loses the third; publishing never blocks
+	assert.Len(t, drain(slow), 2)
+	assert.Equal(t, uint64(1), slow.Dropped())
+
+	// Other subscribers are unaffected
+	assert.Len(t, drain(fast), 2)
+	assert.Equal(t, uint64(0), fast.Dropped())
+}
+
+func TestEventBus_SequenceGapsRevealDroppedEvents(t *testing.T) {
+	bus := NewEventBus(1)
+	sub := bus.Subscribe()
+	defer sub.Close()
+
+	bus.Publish(BotEvent{Type: BotEventStarted, BotID: "bot-1"})
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-1"}) // dropped
+	first := drain(sub)
+	bus.Publish(BotEvent{Type: BotEventStopped, BotID: "bot-1"})
+	second := drain(sub)
+
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.Equal(t, uint64(1), first[0].Sequence)
+	assert.Equal(t, uint64(3), second[0].Sequence, "the skipped sequence number tells the client it missed an event")
+	assert.False(t, second[0].Timestamp.IsZero())
+}
+
+func TestEventBus_SubscriptionFilters(t *testing.T) {
+	bus := NewEventBus(16)
+	sub := bus.Subscribe("bot-1")
+	defer sub.Close()
+
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-1"})
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-2"})
+	bus.Publish(BotEvent{Type: BotEventRiskLimitsChanged}) // portfolio-wide events reach everyone
+	events := drain(sub)
+	require.Len(t, events, 2)
+	assert.Equal(t, "bot-1", events[0].BotID)
+	assert.Equal(t, "", events[1].BotID)
+	// Sequences only count matching events
+	assert.Equal(t, uint64(2), events[1].Sequence)
+
+	sub.Subscribe("bot-2")
+	sub.Unsubscribe("bot-1")
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-1"})
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-2"})
+	events = drain(sub)
+	require.Len(t, events, 1)
+	assert.Equal(t, "bot-2", events[0].BotID)
+
+	sub.Subscribe(AllBots)
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-3"})
+	assert.Len(t, drain(sub), 1)
+	sub.Unsubscribe(AllBots)
+	bus.Publish(BotEvent{Type: BotEventOrderFilled, BotID: "bot-3"})
+	assert.Empty(t, drain(sub))
+
+	// Closed subscriptions leave the bus and close their channel
+	sub.Close()
+	assert.Equal(t, 0, bus.SubscriberCount())
+	_, open := <-sub.Events()
+	assert.False(t, open)
+}
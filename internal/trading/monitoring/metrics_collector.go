@@ -105,18 +105,28 @@ func (mc *MetricsCollector) CollectSystemMetrics(ctx context.Context) *SystemMet
 
 // CollectTradingMetrics collects trading execution metrics for a bot
 func (mc *MetricsCollector) CollectTradingMetrics(ctx context.Context, bot *trading.TradingBot) *TradingMetrics {
-	// Simplified trading metrics - in production this would come from actual trade data
+	stats := bot.ExecutionStats()
+
+	fillRate := decimal.Zero
+	if stats.OrdersPlaced > 0 {
+		fillRate = decimal.NewFromInt(int64(stats.OrdersFilled)).Div(decimal.NewFromInt(int64(stats.OrdersPlaced)))
+	}
+
+	// Average slippage as a fraction of traded volume
+	avgSlippage := decimal.Zero
+	if stats.TotalVolume.IsPositive() {
+		avgSlippage = stats.TotalSlippage.Div(stats.TotalVolume)
+	}
+
 	return &TradingMetrics{
-		OrdersPlaced:      100,
-		OrdersFilled:      95,
-		OrdersCancelled:   3,
-		OrdersFailed:      2,
-		FillRate:          decimal.NewFromFloat(0.95),
-		AvgExecutionTime:  500 * time.Millisecond,
-		AvgSlippage:       decimal.NewFromFloat(0.001),
-		TotalVolume:       decimal.NewFromFloat(50000),
-		TotalFees:         decimal.NewFromFloat(25),
-		LastTradeTime:     time.Now().Add(-5 * time.Minute),
+		OrdersPlaced:  stats.OrdersPlaced,
+		OrdersFilled:  stats.OrdersFilled,
+		OrdersFailed:  stats.OrdersFailed,
+		FillRate:      fillRate,
+		AvgSlippage:   avgSlippage,
+		TotalVolume:   stats.TotalVolume,
+		TotalFees:     stats.TotalFees,
+		LastTradeTime: stats.LastTradeTime,
 	}
 }
 
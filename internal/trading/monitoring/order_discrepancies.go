@@ -0,0 +1,52 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ai-agentic-browser/internal/trading"
+)
+
+// OrderDiscrepancy publishes a discrepancy found by order reconciliation and, when configured,
+// raises an alert for it
+func (tbm *TradingBotMonitor) OrderDiscrepancy(discrepancy *trading.OrderDiscrepancy) {
+	tbm.events.Publish(BotEvent{
+		Type:      BotEventOrderDiscrepancy,
+		BotID:     discrepancy.BotID,
+		Timestamp: discrepancy.DetectedAt,
+		Data:      discrepancy,
+	})
+
+	if !tbm.config.AlertOnOrderDiscrepancies {
+		return
+	}
+
+	// Missed cancels and unknown orders moved no funds, so they only warn
+	severity := AlertSeverityHigh
+	if discrepancy.Type == trading.DiscrepancyMissedCancel || discrepancy.Type == trading.DiscrepancyUnknownOrder {
+		severity = AlertSeverityWarning
+	}
+	tbm.alertManager.RaiseAlert(context.Background(), &Alert{
+		Type:     AlertTypeTrading,
+		Severity: severity,
+		Title:    "Order Discrepancy",
+		Message:  fmt.Sprintf("%s %s order %s on %s: %s", discrepancy.Type, discrepancy.Symbol, discrepancy.ClientOrderID, discrepancy.Exchange, discrepancy.Detail),
+		BotID:    discrepancy.BotID,
+		Metadata: map[string]interface{}{
+			"discrepancy":       string(discrepancy.Type),
+			"connection_id":     discrepancy.ConnectionID.String(),
+			"client_order_id":   discrepancy.ClientOrderID,
+			"exchange_order_id": discrepancy.ExchangeOrderID,
+			"local_filled":      discrepancy.LocalFilled.String(),
+			"exchange_filled":   discrepancy.ExchangeFilled.String(),
+			"repaired":          discrepancy.Repaired,
+		},
+	})
+}
+
+// RaiseAlert stores an alert raised outside the periodic threshold checks
+func (am *AlertManager) RaiseAlert(ctx context.Context, alert *Alert) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.createAlert(ctx, alert)
+}
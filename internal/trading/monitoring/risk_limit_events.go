@@ -0,0 +1,32 @@
+package monitoring
+
+import (
+	"github.com/ai-agentic-browser/internal/trading"
+)
+
+// maxDashboardLimitChanges bounds the risk limit history kept for the dashboard
+const maxDashboardLimitChanges = 50
+
+// RiskLimitsChanged publishes an applied change of a bot's risk limits and adds it to the
+// dashboard's limit history
+func (tbm *TradingBotMonitor) RiskLimitsChanged(change *trading.RiskLimitChange) {
+	tbm.events.Publish(BotEvent{
+		Type:      BotEventRiskLimitsChanged,
+		BotID:     change.BotID,
+		Timestamp: *change.AppliedAt,
+		Data:      change,
+	})
+	tbm.dashboardManager.RecordRiskLimitChange(change)
+}
+
+// RecordRiskLimitChange adds an applied risk limit change to the dashboard
+func (dm *DashboardManager) RecordRiskLimitChange(change *trading.RiskLimitChange) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	changes := append([]*trading.RiskLimitChange{change}, dm.dashboardData.RiskMetrics.LimitChanges...)
+	if len(changes) > maxDashboardLimitChanges {
+		changes = changes[:maxDashboardLimitChanges]
+	}
+	dm.dashboardData.RiskMetrics.LimitChanges = changes
+}
@@ -0,0 +1,257 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ExecutionMode selects whether a bot's orders are simulated or sent to an exchange
+type ExecutionMode string
+
+const (
+	ExecutionModePaper ExecutionMode = "paper"
+	ExecutionModeLive  ExecutionMode = "live"
+)
+
+// maxBotTrades bounds the trade history kept in memory per bot
+const maxBotTrades = 1000
+
+var (
+	// ErrInvalidExecutionMode is returned for a mode other than paper or live
+	ErrInvalidExecutionMode = errors.New("invalid execution mode")
+	// ErrLiveConfirmationRequired is returned when a running paper bot is switched to live without confirmation
+	ErrLiveConfirmationRequired = errors.New("switching a running bot from paper to live trading requires confirmation")
+	// ErrInsufficientPaperFunds is returned when a simulated order exceeds the paper balance or holdings
+	ErrInsufficientPaperFunds = errors.New("insufficient paper funds")
+	// ErrOrderNotMarketable is returned for a limit order the market price does not reach
+	ErrOrderNotMarketable = errors.New("limit order is not marketable")
+)
+
+// ParseExecutionMode parses a mode name, defaulting to paper when empty
+func ParseExecutionMode(value string) (ExecutionMode, error) {
+	switch mode := ExecutionMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ExecutionModePaper, nil
+	case ExecutionModePaper, ExecutionModeLive:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidExecutionMode, value)
+	}
+}
+
+// PaperTradingConfig holds the fill assumptions for simulated orders
+type PaperTradingConfig struct {
+	SlippageBps decimal.Decimal `yaml:"slippage_bps" json:"slippage_bps"` // adverse price move per fill in basis points
+	FeeRate     decimal.Decimal `yaml:"fee_rate" json:"fee_rate"`         // fraction of notional charged per fill
+}
+
+// DefaultPaperTradingConfig returns 10 bps slippage and a 0.1% taker fee
+func DefaultPaperTradingConfig() *PaperTradingConfig {
+	return &PaperTradingConfig{
+		SlippageBps: decimal.NewFromInt(10),
+		FeeRate:     decimal.NewFromFloat(0.001),
+	}
+}
+
+// PriceFeed provides the live prices orders are filled against
+type PriceFeed interface {
+	GetPrice(ctx context.Context, symbol string) (decimal.Decimal, error)
+}
+
+// BotOrder is an order submitted on behalf of a bot. Orders are market orders unless Type is
+// OrderTypeLimit; limit orders fill at LimitPrice or better and never rest on the book.
+type BotOrder struct {
+	Symbol     string          `json:"symbol"`
+	Side       OrderSide       `json:"side"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Type       OrderType       `json:"type,omitempty"`
+	LimitPrice decimal.Decimal `json:"limit_price,omitempty"`
+
+	// ClientOrderID identifies a journaled live order on the exchange
+	ClientOrderID string `json:"client_order_id,omitempty"`
+}
+
+// marketable reports whether the order can fill at marketPrice
+func (o *BotOrder) marketable(marketPrice decimal.Decimal) bool {
+	if o.Type != OrderTypeLimit {
+		return true
+	}
+	if o.Side == OrderSideSell {
+		return marketPrice.GreaterThanOrEqual(o.LimitPrice)
+	}
+	return marketPrice.LessThanOrEqual(o.LimitPrice)
+}
+
+// BotTrade is a filled bot order
+type BotTrade struct {
+	ID          string          `json:"id"`
+	BotID       string          `json:"bot_id"`
+	Mode        ExecutionMode   `json:"mode"`
+	OrderID     string          `json:"order_id,omitempty"`
+	Symbol      string          `json:"symbol"`
+	Side        OrderSide       `json:"side"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	MarketPrice decimal.Decimal `json:"market_price"`
+	FillPrice   decimal.Decimal `json:"fill_price"`
+	Fee         decimal.Decimal `json:"fee"`
+	RealizedPnL decimal.Decimal `json:"realized_pnl"`
+	ExecutedAt  time.Time       `json:"executed_at"`
+}
+
+// BotExecutionStats aggregates order execution for a bot
+type BotExecutionStats struct {
+	OrdersPlaced  int             `json:"orders_placed"`
+	OrdersFilled  int             `json:"orders_filled"`
+	OrdersFailed  int             `json:"orders_failed"`
+	TotalVolume   decimal.Decimal `json:"total_volume"`
+	TotalFees     decimal.Decimal `json:"total_fees"`
+	TotalSlippage decimal.Decimal `json:"total_slippage"`
+	LastTradeTime time.Time       `json:"last_trade_time"`
+}
+
+// PaperPosition is a simulated holding; the average entry includes buy fees
+type PaperPosition struct {
+	Symbol       string          `json:"symbol"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	AverageEntry decimal.Decimal `json:"average_entry"`
+	MarkPrice    decimal.Decimal `json:"mark_price"`
+}
+
+// PaperAccount tracks the simulated balance and holdings of a paper bot
+type PaperAccount struct {
+	Config     *PaperTradingConfig       `json:"config"`
+	Cash       decimal.Decimal           `json:"cash"`
+	Equity     decimal.Decimal           `json:"equity"`
+	PeakEquity decimal.Decimal           `json:"peak_equity"`
+	Positions  map[string]*PaperPosition `json:"positions"`
+}
+
+// newPaperAccount creates a paper account funded with the initial balance
+func newPaperAccount(config *PaperTradingConfig, initialBalance decimal.Decimal) *PaperAccount {
+	if config == nil {
+		config = DefaultPaperTradingConfig()
+	}
+	return &PaperAccount{
+		Config:     config,
+		Cash:       initialBalance,
+		Equity:     initialBalance,
+		PeakEquity: initialBalance,
+		Positions:  make(map[string]*PaperPosition),
+	}
+}
+
+// fill simulates an order against marketPrice with the configured slippage and fee. Slippage
+// never takes a limit order's fill past its limit price.
+func (pa *PaperAccount) fill(order *BotOrder, marketPrice decimal.Decimal) (*BotTrade, error) {
+	slippage := marketPrice.Mul(pa.Config.SlippageBps).Div(decimal.NewFromInt(10000))
+	fillPrice := marketPrice.Add(slippage)
+	if order.Side == OrderSideSell {
+		fillPrice = marketPrice.Sub(slippage)
+	}
+	if order.Type == OrderTypeLimit {
+		if order.Side == OrderSideSell {
+			fillPrice = decimal.Max(fillPrice, order.LimitPrice)
+		} else {
+			fillPrice = decimal.Min(fillPrice, order.LimitPrice)
+		}
+	}
+	notional := fillPrice.Mul(order.Quantity)
+	fee := notional.Mul(pa.Config.FeeRate)
+
+	trade := &BotTrade{
+		ID:          uuid.New().String(),
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Quantity:    order.Quantity,
+		MarketPrice: marketPrice,
+		FillPrice:   fillPrice,
+		Fee:         fee,
+		RealizedPnL: decimal.Zero,
+		ExecutedAt:  time.Now(),
+	}
+
+	position := pa.Positions[order.Symbol]
+	switch order.Side {
+	case OrderSideBuy:
+		cost := notional.Add(fee)
+		if cost.GreaterThan(pa.Cash) {
+			return nil, fmt.Errorf("%w: order costs %s, cash is %s", ErrInsufficientPaperFunds, cost, pa.Cash)
+		}
+		pa.Cash = pa.Cash.Sub(cost)
+		if position == nil {
+			position = &PaperPosition{Symbol: order.Symbol, Quantity: decimal.Zero, AverageEntry: decimal.Zero}
+			pa.Positions[order.Symbol] = position
+		}
+		totalCost := position.AverageEntry.Mul(position.Quantity).Add(cost)
+		position.Quantity = position.Quantity.Add(order.Quantity)
+		position.AverageEntry = totalCost.Div(position.Quantity)
+		position.MarkPrice = marketPrice
+	case OrderSideSell:
+		if position == nil || order.Quantity.GreaterThan(position.Quantity) {
+			return nil, fmt.Errorf("%w: cannot sell more %s than held", ErrInsufficientPaperFunds, order.Symbol)
+		}
+		pa.Cash = pa.Cash.Add(notional.Sub(fee))
+		trade.RealizedPnL = fillPrice.Sub(position.AverageEntry).Mul(order.Quantity).Sub(fee)
+		position.Quantity = position.Quantity.Sub(order.Quantity)
+		position.MarkPrice = marketPrice
+		if position.Quantity.IsZero() {
+			delete(pa.Positions, order.Symbol)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported order side: %s", order.Side)
+	}
+
+	pa.revalue()
+	return trade, nil
+}
+
+// mark updates the mark price of a holding and revalues the account
+func (pa *PaperAccount) mark(symbol string, price decimal.Decimal) {
+	if position, exists := pa.Positions[symbol]; exists {
+		position.MarkPrice = price
+		pa.revalue()
+	}
+}
+
+// revalue recomputes equity from cash and marked holdings and tracks the equity peak
+func (pa *PaperAccount) revalue() {
+	equity := pa.Cash
+	for _, position := range pa.Positions {
+		equity = equity.Add(position.Quantity.Mul(position.MarkPrice))
+	}
+	pa.Equity = equity
+	if equity.GreaterThan(pa.PeakEquity) {
+		pa.PeakEquity = equity
+	}
+}
+
+// drawdown returns the current decline from peak equity as a fraction
+func (pa *PaperAccount) drawdown() decimal.Decimal {
+	if !pa.PeakEquity.IsPositive() {
+		return decimal.Zero
+	}
+	return pa.PeakEquity.Sub(pa.Equity).Div(pa.PeakEquity)
+}
+
+// snapshot returns a copy safe to hand out while the bot keeps trading
+func (pa *PaperAccount) snapshot() *PaperAccount {
+	config := *pa.Config
+	positions := make(map[string]*PaperPosition, len(pa.Positions))
+	for symbol, position := range pa.Positions {
+		copied := *position
+		positions[symbol] = &copied
+	}
+	return &PaperAccount{
+		Config:     &config,
+		Cash:       pa.Cash,
+		Equity:     pa.Equity,
+		PeakEquity: pa.PeakEquity,
+		Positions:  positions,
+	}
+}
@@ -0,0 +1,157 @@
+package trading
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPaperBot(t *testing.T) (*TradingBotEngine, *TradingBot) {
+	engine := NewTradingBotEngine(observability.NewLogger(config.ObservabilityConfig{}), &BotEngineConfig{MaxConcurrentBots: 10})
+	bot, err := engine.RegisterBot(context.Background(), &BotConfig{
+		Exchange:     "binance",
+		TradingPairs: []string{"BTC/USDT"},
+		Capital:      &CapitalConfig{InitialBalance: decimal.NewFromInt(10000)},
+		Paper:        &PaperTradingConfig{SlippageBps: decimal.NewFromInt(10), FeeRate: decimal.RequireFromString("0.001")},
+	}, StrategyMomentum)
+	require.NoError(t, err)
+	return engine, bot
+}
+
+func submitAt(t *testing.T, engine *TradingBotEngine, bot *TradingBot, price int64, order *BotOrder) (*BotTrade, error) {
+	t.Helper()
+	engine.SetPriceFeed(fixedPriceFeed(decimal.NewFromInt(price)))
+	return engine.SubmitOrder(context.Background(), bot.ID, order)
+}
+
+func assertDecimal(t *testing.T, want string, got decimal.Decimal, field string) {
+	t.Helper()
+	assert.True(t, got.Equal(decimal.RequireFromString(want)), "%s: want %s, got %s", field, want, got)
+}
+
+func TestPaperTrading_Fills(t *testing.T) {
+	engine, bot := newPaperBot(t)
+	d := decimal.RequireFromString
+
+	// Buys fill 10 bps above the market and pay 0.1% of the notional
+	trade, err := submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideBuy, Quantity: d("10")})
+	require.NoError(t, err)
+	assert.Equal(t, ExecutionModePaper, trade.Mode)
+	assert.Equal(t, bot.ID, trade.BotID)
+	assertDecimal(t, "100.1", trade.FillPrice, "buy fill")
+	assertDecimal(t, "1.001", trade.Fee, "buy fee")
+	assert.True(t, trade.RealizedPnL.IsZero())
+
+	account := bot.PaperAccount()
+	assertDecimal(t, "8997.999", account.Cash, "cash after buy")
+	require.Contains(t, account.Positions, "BTC/USDT")
+	position := account.Positions["BTC/USDT"]
+	assertDecimal(t, "10", position.Quantity, "position")
+	assertDecimal(t, "100.2001", position.AverageEntry, "average entry with fee")
+	assertDecimal(t, "9997.999", account.Equity, "equity marked at the market")
+
+	// Sells fill 10 bps below the market; realized P&L is net of the sell fee
+	trade, err = submitAt(t, engine, bot, 110, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideSell, Quantity: d("4")})
+	require.NoError(t, err)
+	assertDecimal(t, "109.89", trade.FillPrice, "sell fill")
+	assertDecimal(t, "0.43956", trade.Fee, "sell fee")
+	assertDecimal(t, "38.32004", trade.RealizedPnL, "winning sell")
+
+	trade, err = submitAt(t, engine, bot, 90, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideSell, Quantity: d("6")})
+	require.NoError(t, err)
+	assertDecimal(t, "-62.28006", trade.RealizedPnL, "losing sell")
+
+	account = bot.PaperAccount()
+	assert.Empty(t, account.Positions, "a closed position is removed")
+	assertDecimal(t, "9976.03998", account.Cash, "cash after closing")
+	assertDecimal(t, "9976.03998", account.Equity, "equity after closing")
+	assertDecimal(t, "10097.11944", account.PeakEquity, "peak equity at 110")
+
+	stats := bot.ExecutionStats()
+	assert.Equal(t, 3, stats.OrdersPlaced)
+	assert.Equal(t, 3, stats.OrdersFilled)
+	assertDecimal(t, "1.98002", stats.TotalFees, "total fees")
+	assertDecimal(t, "1.98", stats.TotalSlippage, "total slippage")
+	assert.Len(t, bot.Trades(), 3)
+}
+
+func TestPaperTrading_WinRate(t *testing.T) {
+	engine, bot := newPaperBot(t)
+	d := decimal.RequireFromString
+
+	_, err := submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideBuy, Quantity: d("10")})
+	require.NoError(t, err)
+	assert.Zero(t, bot.Performance.TotalTrades, "opening fills are not trades")
+
+	// Selling at 110 and 120 wins; selling at 90 and at the market price of the buy loses after fees
+	for _, price := range []int64{110, 120, 90, 100} {
+		_, err := submitAt(t, engine, bot, price, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideSell, Quantity: d("2")})
+		require.NoError(t, err)
+	}
+
+	perf := bot.Performance
+	assert.Equal(t, 4, perf.TotalTrades)
+	assert.Equal(t, 2, perf.WinningTrades)
+	assert.Equal(t, 2, perf.LosingTrades)
+	assertDecimal(t, "0.5", perf.WinRate, "win rate")
+	assert.True(t, perf.NetProfit.Equal(perf.TotalProfit.Sub(perf.TotalLoss)))
+	assert.True(t, perf.MaxDrawdown.IsPositive(), "the drop to 90 is a drawdown")
+}
+
+func TestPaperTrading_RejectedOrders(t *testing.T) {
+	engine, bot := newPaperBot(t)
+	d := decimal.RequireFromString
+
+	_, err := submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideBuy, Quantity: d("100")})
+	assert.ErrorIs(t, err, ErrInsufficientPaperFunds, "costs more than the cash")
+	_, err = submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideSell, Quantity: d("1")})
+	assert.ErrorIs(t, err, ErrInsufficientPaperFunds, "sells a holding the bot does not have")
+	_, err = submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideBuy, Quantity: d("1"), Type: OrderTypeLimit, LimitPrice: d("99")})
+	assert.ErrorIs(t, err, ErrOrderNotMarketable)
+
+	stats := bot.ExecutionStats()
+	assert.Equal(t, 2, stats.OrdersPlaced, "unmarketable orders are not placed")
+	assert.Equal(t, 2, stats.OrdersFailed)
+	assertDecimal(t, "10000", bot.PaperAccount().Cash, "cash untouched")
+
+	// Slippage never takes a limit order past its limit
+	trade, err := submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideBuy, Quantity: d("1"), Type: OrderTypeLimit, LimitPrice: d("100.05")})
+	require.NoError(t, err)
+	assertDecimal(t, "100.05", trade.FillPrice, "limit buy fill")
+	trade, err = submitAt(t, engine, bot, 100, &BotOrder{Symbol: "BTC/USDT", Side: OrderSideSell, Quantity: d("1"), Type: OrderTypeLimit, LimitPrice: d("99.95")})
+	require.NoError(t, err)
+	assertDecimal(t, "99.95", trade.FillPrice, "limit sell fill")
+}
+
+func TestSetBotMode(t *testing.T) {
+	ctx := context.Background()
+	engine, bot := newPaperBot(t)
+
+	_, err := ParseExecutionMode("simulated")
+	assert.ErrorIs(t, err, ErrInvalidExecutionMode)
+	assert.ErrorIs(t, engine.SetBotMode(ctx, bot.ID, "simulated", true), ErrInvalidExecutionMode)
+
+	// An idle bot switches freely
+	require.NoError(t, engine.SetBotMode(ctx, bot.ID, ExecutionModeLive, false))
+	assert.Equal(t, ExecutionModeLive, bot.Mode)
+	require.NoError(t, engine.SetBotMode(ctx, bot.ID, ExecutionModePaper, false))
+
+	// A running paper bot only goes live with confirmation
+	require.NoError(t, engine.StartBot(ctx, bot.ID))
+	assert.ErrorIs(t, engine.SetBotMode(ctx, bot.ID, ExecutionModeLive, false), ErrLiveConfirmationRequired)
+	assert.Equal(t, ExecutionModePaper, bot.Mode)
+	assert.Equal(t, ExecutionModePaper, bot.Config.Mode)
+
+	require.NoError(t, engine.SetBotMode(ctx, bot.ID, ExecutionModeLive, true))
+	assert.Equal(t, ExecutionModeLive, bot.Mode)
+	assert.Equal(t, ExecutionModeLive, bot.Config.Mode)
+
+	// Going back to paper never needs confirmation
+	require.NoError(t, engine.SetBotMode(ctx, bot.ID, ExecutionModePaper, false))
+	assert.Equal(t, ExecutionModePaper, bot.Mode)
+}
@@ -79,6 +79,7 @@ type OptimizedPortfolio struct {
 	Constraints        *OptimizationConstraints   `json:"constraints"`
 	Objective          *OptimizationObjective     `json:"objective"`
 	Performance        *PortfolioPerformance      `json:"performance"`
+	RebalancePlan      *RebalancePlan             `json:"rebalance_plan,omitempty"`
 	LastOptimized      time.Time                  `json:"last_optimized"`
 	LastRebalanced     time.Time                  `json:"last_rebalanced"`
 	IsActive           bool                       `json:"is_active"`
@@ -260,8 +261,10 @@ func (po *PortfolioOptimizer) Stop(ctx context.Context) error {
 	return nil
 }
 
-// OptimizePortfolio optimizes a portfolio using the specified method
-func (po *PortfolioOptimizer) OptimizePortfolio(ctx context.Context, name string, assets []string, method OptimizationMethod, constraints *OptimizationConstraints, objective *OptimizationObjective) (*OptimizedPortfolio, error) {
+// OptimizePortfolio optimizes a portfolio using the specified method. When current holdings
+// are given, the portfolio also carries the cost-aware plan for rebalancing them to the ideal
+// weights.
+func (po *PortfolioOptimizer) OptimizePortfolio(ctx context.Context, name string, assets []string, method OptimizationMethod, constraints *OptimizationConstraints, objective *OptimizationObjective, holdings map[string]PortfolioHolding, costs *RebalanceCostModel) (*OptimizedPortfolio, error) {
 	po.mu.Lock()
 	defer po.mu.Unlock()
 
@@ -295,6 +298,17 @@ func (po *PortfolioOptimizer) OptimizePortfolio(ctx context.Context, name string
 	// Calculate performance metrics
 	po.calculatePerformanceMetrics(portfolio, data)
 
+	// Price the trades from current holdings to the ideal weights
+	if len(holdings) > 0 {
+		plan, err := po.planRebalance(holdings, portfolio.Weights, data.ExpectedReturns, costs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan rebalance: %w", err)
+		}
+		portfolio.RebalancePlan = plan
+		portfolio.TurnoverRate = plan.Turnover
+		portfolio.TransactionCosts = plan.EstimatedCost
+	}
+
 	// Store portfolio
 	po.portfolios[portfolio.ID] = portfolio
 
@@ -644,7 +658,7 @@ func (po *PortfolioOptimizer) performPeriodicOptimization(ctx context.Context) {
 				assets = append(assets, asset)
 			}
 
-			_, err := po.OptimizePortfolio(ctx, portfolio.Name, assets, portfolio.Method, portfolio.Constraints, portfolio.Objective)
+			_, err := po.OptimizePortfolio(ctx, portfolio.Name, assets, portfolio.Method, portfolio.Constraints, portfolio.Objective, nil, nil)
 			if err != nil {
 				po.logger.Error(ctx, "Failed to re-optimize portfolio", err)
 			}
@@ -0,0 +1,68 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/shopspring/decimal"
+)
+
+// MarketDataPriceFeed serves the latest streamed price per symbol from the market data service
+type MarketDataPriceFeed struct {
+	marketData *realtime.MarketDataService
+	prices     map[string]decimal.Decimal
+	mu         sync.RWMutex
+}
+
+// NewMarketDataPriceFeed creates a price feed over the market data service
+func NewMarketDataPriceFeed(marketData *realtime.MarketDataService) *MarketDataPriceFeed {
+	return &MarketDataPriceFeed{
+		marketData: marketData,
+		prices:     make(map[string]decimal.Decimal),
+	}
+}
+
+// Run records price updates for the given symbols until ctx is done
+func (f *MarketDataPriceFeed) Run(ctx context.Context, symbols []string) {
+	var wg sync.WaitGroup
+	for _, symbol := range symbols {
+		updates := f.marketData.Subscribe(symbol)
+		wg.Add(1)
+		go func(symbol string, updates <-chan realtime.MarketUpdate) {
+			defer wg.Done()
+			defer f.marketData.Unsubscribe(symbol, updates)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case update, ok := <-updates:
+					if !ok {
+						return
+					}
+					if update.Price.IsPositive() {
+						f.mu.Lock()
+						f.prices[update.Symbol] = update.Price
+						f.mu.Unlock()
+					}
+				}
+			}
+		}(symbol, updates)
+	}
+	wg.Wait()
+}
+
+// GetPrice returns the latest price for a symbol such as BTCUSDT, BTC/USDT or BTC-USD.
+// Prices are keyed by canonical symbol, so USD and USD stablecoin quotes share a price.
+func (f *MarketDataPriceFeed) GetPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	price, exists := f.prices[realtime.CanonicalSymbol(symbol)]
+	if !exists {
+		return decimal.Zero, fmt.Errorf("no market price available for %s", symbol)
+	}
+	return price, nil
+}
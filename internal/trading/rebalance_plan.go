@@ -0,0 +1,303 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/web3"
+	"github.com/shopspring/decimal"
+)
+
+// RebalanceRecommendation tells whether a rebalance plan is worth trading
+type RebalanceRecommendation string
+
+const (
+	RebalanceRecommendTrade   RebalanceRecommendation = "trade"
+	RebalanceRecommendNoTrade RebalanceRecommendation = "no_trade"
+)
+
+// defaultRebalanceHorizon is the period a rebalance's expected benefit is counted over when
+// the cost model does not set one
+const defaultRebalanceHorizon = 30 * 24 * time.Hour
+
+// tradingDaysPerYear annualizes the daily expected returns of optimization data
+const tradingDaysPerYear = 252
+
+// PortfolioHolding is a current position a rebalance trades from. Holdings with zero quantity
+// may be given to price assets that are only bought.
+type PortfolioHolding struct {
+	Quantity decimal.Decimal `json:"quantity"`
+	Price    decimal.Decimal `json:"price"`
+}
+
+// RebalanceCostModel prices the trades of a rebalance. Each trade goes to the venue with the
+// lowest fee plus slippage, estimated from the router's order books and falling back to the
+// venue's average slippage.
+type RebalanceCostModel struct {
+	FeeBps        map[string]decimal.Decimal `json:"fee_bps"`         // venue -> fee, overriding the venue's fee rate
+	DefaultFeeBps decimal.Decimal            `json:"default_fee_bps"` // fee without a router or a venue for the asset
+	QuoteCurrency string                     `json:"quote_currency"`  // holdings of it settle trades; defaults to USD
+	Horizon       time.Duration              `json:"horizon"`         // period the expected benefit is counted over
+	Router        *SmartOrderRouter          `json:"-"`
+}
+
+// RebalanceTrade is one trade of a rebalance plan with its estimated cost
+type RebalanceTrade struct {
+	Asset       string          `json:"asset"`
+	Symbol      string          `json:"symbol"`
+	Side        OrderSide       `json:"side"`
+	Venue       string          `json:"venue,omitempty"`
+	Quantity    decimal.Decimal `json:"quantity"` // zero when the asset has no price
+	Notional    decimal.Decimal `json:"notional"`
+	FeeBps      decimal.Decimal `json:"fee_bps"`
+	SlippageBps decimal.Decimal `json:"slippage_bps"`
+	Fee         decimal.Decimal `json:"fee"`
+	Slippage    decimal.Decimal `json:"slippage"`
+	Cost        decimal.Decimal `json:"cost"`
+}
+
+// RebalancePlan moves current holdings to target weights and weighs the cost of the trades
+// against the expected return they add over the horizon. Returns are annualized.
+type RebalancePlan struct {
+	TotalValue        decimal.Decimal            `json:"total_value"`
+	CurrentWeights    map[string]decimal.Decimal `json:"current_weights"`
+	TargetWeights     map[string]decimal.Decimal `json:"target_weights"`
+	Trades            []*RebalanceTrade          `json:"trades"`
+	Turnover          decimal.Decimal            `json:"turnover"`
+	EstimatedCost     decimal.Decimal            `json:"estimated_cost"`
+	Horizon           time.Duration              `json:"horizon"`
+	ReturnImprovement decimal.Decimal            `json:"return_improvement"`
+	ExpectedBenefit   decimal.Decimal            `json:"expected_benefit"`
+	NetBenefit        decimal.Decimal            `json:"net_benefit"`
+	BreakevenReturn   decimal.Decimal            `json:"breakeven_return"` // improvement at which the trades pay for themselves
+	Recommendation    RebalanceRecommendation    `json:"recommendation"`
+}
+
+// PlanRebalance prices moving holdings to target weights with the cost model. A nil model
+// charges the optimizer's flat transaction cost.
+func (po *PortfolioOptimizer) PlanRebalance(ctx context.Context, holdings map[string]PortfolioHolding, targets map[string]decimal.Decimal, costs *RebalanceCostModel) (*RebalancePlan, error) {
+	po.mu.RLock()
+	defer po.mu.RUnlock()
+
+	quote := costs.quoteCurrency()
+	assets := make([]string, 0, len(targets))
+	for asset := range targets {
+		if asset != quote {
+			assets = append(assets, asset)
+		}
+	}
+	for asset := range holdings {
+		if _, targeted := targets[asset]; !targeted && asset != quote {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+
+	data, err := po.prepareOptimizationData(assets, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare rebalance data: %w", err)
+	}
+	return po.planRebalance(holdings, targets, data.ExpectedReturns, costs)
+}
+
+// planRebalance builds the trades from holdings to targets and prices them. Expected returns
+// are daily, as in optimization data.
+func (po *PortfolioOptimizer) planRebalance(holdings map[string]PortfolioHolding, targets, expectedReturns map[string]decimal.Decimal, costs *RebalanceCostModel) (*RebalancePlan, error) {
+	quote := costs.quoteCurrency()
+	plan := &RebalancePlan{
+		CurrentWeights: make(map[string]decimal.Decimal, len(holdings)),
+		TargetWeights:  targets,
+		Trades:         []*RebalanceTrade{},
+		Horizon:        costs.horizon(),
+		Recommendation: RebalanceRecommendNoTrade,
+	}
+
+	values := make(map[string]decimal.Decimal, len(holdings))
+	for asset, holding := range holdings {
+		value := holding.Quantity.Mul(holding.Price)
+		if asset == quote && holding.Price.IsZero() {
+			value = holding.Quantity
+		}
+		values[asset] = value
+		plan.TotalValue = plan.TotalValue.Add(value)
+	}
+	if !plan.TotalValue.IsPositive() {
+		return nil, fmt.Errorf("holdings have no value to rebalance")
+	}
+	for asset, value := range values {
+		plan.CurrentWeights[asset] = value.Div(plan.TotalValue)
+	}
+	plan.Turnover = po.calculateTurnover(plan.CurrentWeights, targets)
+
+	assets := make([]string, 0, len(values)+len(targets))
+	for asset := range values {
+		assets = append(assets, asset)
+	}
+	for asset := range targets {
+		if _, held := values[asset]; !held {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+
+	improvement := decimal.Zero
+	for _, asset := range assets {
+		change := targets[asset].Sub(plan.CurrentWeights[asset])
+		improvement = improvement.Add(change.Mul(expectedReturns[asset]))
+		if asset == quote || change.IsZero() {
+			continue
+		}
+
+		trade := &RebalanceTrade{
+			Asset:    asset,
+			Symbol:   rebalanceSymbol(asset, quote),
+			Side:     OrderSideBuy,
+			Notional: change.Abs().Mul(plan.TotalValue),
+		}
+		if change.IsNegative() {
+			trade.Side = OrderSideSell
+		}
+		if price := holdings[asset].Price; price.IsPositive() {
+			trade.Quantity = trade.Notional.Div(price)
+		}
+		po.priceTrade(trade, costs)
+
+		plan.Trades = append(plan.Trades, trade)
+		plan.EstimatedCost = plan.EstimatedCost.Add(trade.Cost)
+	}
+
+	years := decimal.NewFromFloat(plan.Horizon.Hours() / (24 * 365))
+	plan.ReturnImprovement = improvement.Mul(decimal.NewFromInt(tradingDaysPerYear))
+	plan.ExpectedBenefit = plan.ReturnImprovement.Mul(plan.TotalValue).Mul(years)
+	plan.NetBenefit = plan.ExpectedBenefit.Sub(plan.EstimatedCost)
+	if years.IsPositive() {
+		plan.BreakevenReturn = plan.EstimatedCost.Div(plan.TotalValue).Div(years)
+	}
+	if len(plan.Trades) > 0 && plan.NetBenefit.IsPositive() {
+		plan.Recommendation = RebalanceRecommendTrade
+	}
+	return plan, nil
+}
+
+// priceTrade sets the venue, fee and slippage of a trade
+func (po *PortfolioOptimizer) priceTrade(trade *RebalanceTrade, costs *RebalanceCostModel) {
+	trade.FeeBps = po.config.TransactionCosts.Mul(decimal.NewFromInt(10000))
+	if costs != nil {
+		if costs.DefaultFeeBps.IsPositive() {
+			trade.FeeBps = costs.DefaultFeeBps
+		}
+		if costs.Router != nil {
+			if venue, feeBps, slippageBps, ok := costs.Router.cheapestVenue(trade.Symbol, trade.Side, trade.Quantity, costs.FeeBps); ok {
+				trade.Venue, trade.FeeBps, trade.SlippageBps = venue, feeBps, slippageBps
+			}
+		}
+	}
+
+	bps := decimal.NewFromInt(10000)
+	trade.Fee = trade.Notional.Mul(trade.FeeBps).Div(bps)
+	trade.Slippage = trade.Notional.Mul(trade.SlippageBps).Div(bps)
+	trade.Cost = trade.Fee.Add(trade.Slippage)
+}
+
+// cheapestVenue returns the venue with the lowest fee plus slippage for a trade, with fees
+// overridden per venue. Slippage is walked from the venue's book when quantity is known.
+func (sor *SmartOrderRouter) cheapestVenue(symbol string, side OrderSide, quantity decimal.Decimal, feeOverrides map[string]decimal.Decimal) (string, decimal.Decimal, decimal.Decimal, bool) {
+	sor.mu.RLock()
+	defer sor.mu.RUnlock()
+
+	venues := sor.getAvailableVenues(symbol)
+	sort.Slice(venues, func(i, j int) bool { return venues[i].ID < venues[j].ID })
+
+	bps := decimal.NewFromInt(10000)
+	var best string
+	var bestFee, bestSlippage decimal.Decimal
+	for _, venue := range venues {
+		feeBps := venue.FeeRate.Mul(bps)
+		if override, ok := feeOverrides[venue.ID]; ok {
+			feeBps = override
+		}
+		slippageBps := venue.AverageSlippage.Mul(bps)
+		if quantity.IsPositive() {
+			if estimate, err := sor.estimateSlippage(venue.ID, symbol, side, quantity); err == nil && estimate.FullyFilled {
+				slippageBps = estimate.SlippageBps
+			}
+		}
+		if best == "" || feeBps.Add(slippageBps).LessThan(bestFee.Add(bestSlippage)) {
+			best, bestFee, bestSlippage = venue.ID, feeBps, slippageBps
+		}
+	}
+	return best, bestFee, bestSlippage, best != ""
+}
+
+// rebalanceSymbol returns the market an asset is traded on, such as BTC/USD. Assets that
+// are already markets are returned unchanged.
+func rebalanceSymbol(asset, quote string) string {
+	if strings.Contains(asset, "/") {
+		return asset
+	}
+	return asset + "/" + quote
+}
+
+// quoteCurrency returns the currency trades are priced in
+func (m *RebalanceCostModel) quoteCurrency() string {
+	if m == nil || m.QuoteCurrency == "" {
+		return "USD"
+	}
+	return m.QuoteCurrency
+}
+
+// horizon returns the period the expected benefit of a rebalance is counted over
+func (m *RebalanceCostModel) horizon() time.Duration {
+	if m == nil || m.Horizon <= 0 {
+		return defaultRebalanceHorizon
+	}
+	return m.Horizon
+}
+
+// rebalanceCostEstimator prices web3 rebalance previews with a portfolio optimizer
+type rebalanceCostEstimator struct {
+	optimizer *PortfolioOptimizer
+	costs     *RebalanceCostModel
+}
+
+// NewRebalanceCostEstimator returns a cost estimator for web3 rebalance previews. Portfolio
+// cash is held in the cost model's quote currency.
+func NewRebalanceCostEstimator(optimizer *PortfolioOptimizer, costs *RebalanceCostModel) web3.RebalanceCostEstimator {
+	return &rebalanceCostEstimator{optimizer: optimizer, costs: costs}
+}
+
+// EstimateRebalanceCost plans the rebalance of the portfolio's holdings and cash to targets
+func (e *rebalanceCostEstimator) EstimateRebalanceCost(ctx context.Context, portfolio *web3.Portfolio, targets map[string]decimal.Decimal) (*web3.RebalanceCostEstimate, error) {
+	holdings := make(map[string]PortfolioHolding, len(portfolio.Holdings)+1)
+	for asset, holding := range portfolio.Holdings {
+		holdings[asset] = PortfolioHolding{Quantity: holding.Amount, Price: holding.CurrentPrice}
+	}
+	quote := e.costs.quoteCurrency()
+	cash := holdings[quote]
+	cash.Quantity = cash.Quantity.Add(portfolio.AvailableBalance)
+	if cash.Price.IsZero() {
+		cash.Price = decimal.NewFromInt(1)
+	}
+	holdings[quote] = cash
+
+	plan, err := e.optimizer.PlanRebalance(ctx, holdings, targets, e.costs)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &web3.RebalanceCostEstimate{
+		AssetCosts:      make(map[string]decimal.Decimal, len(plan.Trades)),
+		EstimatedCost:   plan.EstimatedCost,
+		ExpectedBenefit: plan.ExpectedBenefit,
+		NetBenefit:      plan.NetBenefit,
+		BreakevenReturn: plan.BreakevenReturn,
+		Recommendation:  string(plan.Recommendation),
+	}
+	for _, trade := range plan.Trades {
+		estimate.AssetCosts[trade.Asset] = trade.Cost
+	}
+	return estimate, nil
+}
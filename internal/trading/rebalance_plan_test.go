@@ -0,0 +1,233 @@
+package trading
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// venueBooks serves fixed order books per venue
+type venueBooks map[string]*realtime.OrderBook
+
+func (b venueBooks) GetExchangeOrderBook(exchange, symbol string, depth int) (*realtime.OrderBook, error) {
+	book, exists := b[exchange]
+	if !exists {
+		return nil, fmt.Errorf("%w: no book for %s on %s", realtime.ErrOrderBookUnavailable, symbol, exchange)
+	}
+	return book, nil
+}
+
+func levels(prices ...string) []realtime.OrderBookLevel {
+	var book []realtime.OrderBookLevel
+	for i := 0; i < len(prices); i += 2 {
+		book = append(book, realtime.OrderBookLevel{Price: decimal.RequireFromString(prices[i]), Size: decimal.RequireFromString(prices[i+1])})
+	}
+	return book
+}
+
+// newRebalanceRouter routes BTC/USD to alpha (10 bps fee, 5 bps slippage) and beta
+// (5 bps fee, 20 bps slippage); gamma is inactive and free
+func newRebalanceRouter(t *testing.T) *SmartOrderRouter {
+	router := NewSmartOrderRouter(observability.NewLogger(config.ObservabilityConfig{}))
+	for _, venue := range []*VenueInfo{
+		{ID: "alpha", IsActive: true, FeeRate: decimal.RequireFromString("0.001"), AverageSlippage: decimal.RequireFromString("0.0005"), SupportedSymbols: []string{"BTC/USD"}},
+		{ID: "beta", IsActive: true, FeeRate: decimal.RequireFromString("0.0005"), AverageSlippage: decimal.RequireFromString("0.002"), SupportedSymbols: []string{"BTC/USD"}},
+		{ID: "gamma", IsActive: false, SupportedSymbols: []string{"BTC/USD"}},
+	} {
+		require.NoError(t, router.RegisterVenue(venue))
+	}
+	return router
+}
+
+func TestSmartOrderRouter_CheapestVenue(t *testing.T) {
+	router := newRebalanceRouter(t)
+	bps := func(value string) decimal.Decimal { return decimal.RequireFromString(value) }
+
+	tests := []struct {
+		name      string
+		books     venueBooks
+		side      OrderSide
+		quantity  string
+		overrides map[string]decimal.Decimal
+		venue     string
+		fee       string
+		slippage  string
+	}{
+		{name: "average slippage without books", side: OrderSideBuy, quantity: "1", venue: "alpha", fee: "10", slippage: "5"},
+		{name: "fee override", side: OrderSideBuy, quantity: "1", overrides: map[string]decimal.Decimal{"alpha": bps("30")}, venue: "beta", fee: "5", slippage: "20"},
+		{
+			name: "slippage walked from the books",
+			books: venueBooks{
+				"alpha": {Asks: levels("100", "1", "101", "1")},
+				"beta":  {Asks: levels("100", "5")},
+			},
+			side: OrderSideBuy, quantity: "2", venue: "beta", fee: "5", slippage: "0",
+		},
+		{
+			name: "sells walk the bids",
+			books: venueBooks{
+				"alpha": {Bids: levels("100", "10")},
+				"beta":  {Bids: levels("100", "1", "90", "1")},
+			},
+			side: OrderSideSell, quantity: "2", venue: "alpha", fee: "10", slippage: "0",
+		},
+		{
+			name: "books too thin to fill fall back to average slippage",
+			books: venueBooks{
+				"alpha": {Asks: levels("100", "1")},
+				"beta":  {Asks: levels("100", "1")},
+			},
+			side: OrderSideBuy, quantity: "6", venue: "alpha", fee: "10", slippage: "5",
+		},
+		{
+			name:  "unknown quantity ignores the books",
+			books: venueBooks{"beta": {Asks: levels("100", "5")}},
+			side:  OrderSideBuy, quantity: "0", venue: "alpha", fee: "10", slippage: "5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router.SetOrderBookSource(nil)
+			if tt.books != nil {
+				router.SetOrderBookSource(tt.books)
+			}
+
+			venue, fee, slippage, ok := router.cheapestVenue("BTC/USD", tt.side, decimal.RequireFromString(tt.quantity), tt.overrides)
+			require.True(t, ok)
+			assert.Equal(t, tt.venue, venue)
+			assert.True(t, fee.Equal(bps(tt.fee)), "fee %s", fee)
+			assert.True(t, slippage.Equal(bps(tt.slippage)), "slippage %s", slippage)
+		})
+	}
+
+	_, _, _, ok := router.cheapestVenue("ETH/USD", OrderSideBuy, decimal.NewFromInt(1), nil)
+	assert.False(t, ok, "no venue trades the symbol")
+}
+
+func TestPriceTrade(t *testing.T) {
+	po := NewPortfolioOptimizer(observability.NewLogger(config.ObservabilityConfig{}))
+	router := newRebalanceRouter(t)
+
+	price := func(symbol string, costs *RebalanceCostModel) *RebalanceTrade {
+		trade := &RebalanceTrade{Symbol: symbol, Side: OrderSideBuy, Quantity: decimal.NewFromInt(1), Notional: decimal.NewFromInt(10000)}
+		po.priceTrade(trade, costs)
+		return trade
+	}
+
+	// The optimizer's flat 0.1% transaction cost without a model
+	trade := price("BTC/USD", nil)
+	assert.Empty(t, trade.Venue)
+	assert.True(t, trade.FeeBps.Equal(decimal.NewFromInt(10)))
+	assert.True(t, trade.Cost.Equal(decimal.NewFromInt(10)))
+
+	trade = price("BTC/USD", &RebalanceCostModel{DefaultFeeBps: decimal.NewFromInt(25)})
+	assert.True(t, trade.Fee.Equal(decimal.NewFromInt(25)))
+	assert.True(t, trade.Slippage.IsZero())
+
+	// The router's cheapest venue sets fee and slippage
+	trade = price("BTC/USD", &RebalanceCostModel{DefaultFeeBps: decimal.NewFromInt(25), Router: router})
+	assert.Equal(t, "alpha", trade.Venue)
+	assert.True(t, trade.Fee.Equal(decimal.NewFromInt(10)), "fee %s", trade.Fee)
+	assert.True(t, trade.Slippage.Equal(decimal.NewFromInt(5)), "slippage %s", trade.Slippage)
+	assert.True(t, trade.Cost.Equal(decimal.NewFromInt(15)))
+
+	// Assets no venue trades keep the default fee
+	trade = price("DOGE/USD", &RebalanceCostModel{DefaultFeeBps: decimal.NewFromInt(25), Router: router})
+	assert.Empty(t, trade.Venue)
+	assert.True(t, trade.Cost.Equal(decimal.NewFromInt(25)))
+}
+
+func TestPlanRebalance(t *testing.T) {
+	po := NewPortfolioOptimizer(observability.NewLogger(config.ObservabilityConfig{}))
+	d := decimal.RequireFromString
+
+	// 30% BTC, 20% ETH and 50% cash of a 100k portfolio
+	holdings := map[string]PortfolioHolding{
+		"BTC": {Quantity: d("1"), Price: d("30000")},
+		"ETH": {Quantity: d("10"), Price: d("2000")},
+		"USD": {Quantity: d("50000")},
+	}
+	targets := map[string]decimal.Decimal{"BTC": d("0.4"), "ETH": d("0.1"), "SOL": d("0.1"), "USD": d("0.4")}
+	dailyReturns := map[string]decimal.Decimal{"BTC": d("0.001"), "ETH": d("0.0005"), "SOL": d("0.002")}
+
+	plan, err := po.planRebalance(holdings, targets, dailyReturns, nil)
+	require.NoError(t, err)
+	assert.True(t, plan.TotalValue.Equal(d("100000")))
+	assert.True(t, plan.CurrentWeights["USD"].Equal(d("0.5")))
+	assert.True(t, plan.Turnover.Equal(d("0.2")), "turnover %s", plan.Turnover)
+
+	// Cash settles the trades and is not traded itself
+	require.Len(t, plan.Trades, 3)
+	want := []struct {
+		asset, symbol string
+		side          OrderSide
+		quantity      string
+	}{
+		{"BTC", "BTC/USD", OrderSideBuy, "0.3333333333333333"},
+		{"ETH", "ETH/USD", OrderSideSell, "5"},
+		{"SOL", "SOL/USD", OrderSideBuy, "0"}, // no price to size it
+	}
+	for i, trade := range plan.Trades {
+		assert.Equal(t, want[i].asset, trade.Asset)
+		assert.Equal(t, want[i].symbol, trade.Symbol)
+		assert.Equal(t, want[i].side, trade.Side)
+		assert.True(t, trade.Notional.Equal(d("10000")), "%s notional %s", trade.Asset, trade.Notional)
+		assert.True(t, trade.Quantity.Equal(d(want[i].quantity)), "%s quantity %s", trade.Asset, trade.Quantity)
+		assert.True(t, trade.Cost.Equal(d("10")), "%s cost %s", trade.Asset, trade.Cost)
+	}
+	assert.True(t, plan.EstimatedCost.Equal(d("30")))
+
+	// 0.025% a day more return is 6.3% a year, counted over the default 30 day horizon
+	years := 30.0 / 365
+	assert.Equal(t, defaultRebalanceHorizon, plan.Horizon)
+	assert.True(t, plan.ReturnImprovement.Equal(d("0.063")), "improvement %s", plan.ReturnImprovement)
+	assert.InDelta(t, 0.063*100000*years, plan.ExpectedBenefit.InexactFloat64(), 0.01)
+	assert.InDelta(t, 0.063*100000*years-30, plan.NetBenefit.InexactFloat64(), 0.01)
+	assert.InDelta(t, 30.0/100000/years, plan.BreakevenReturn.InexactFloat64(), 1e-9)
+	assert.Equal(t, RebalanceRecommendTrade, plan.Recommendation)
+
+	t.Run("costs outweighing the benefit", func(t *testing.T) {
+		plan, err := po.planRebalance(holdings, targets, dailyReturns, &RebalanceCostModel{DefaultFeeBps: d("100"), Horizon: 24 * time.Hour})
+		require.NoError(t, err)
+		assert.True(t, plan.EstimatedCost.Equal(d("300")))
+		assert.True(t, plan.NetBenefit.IsNegative())
+		assert.Equal(t, RebalanceRecommendNoTrade, plan.Recommendation)
+	})
+
+	t.Run("already at target", func(t *testing.T) {
+		current := map[string]decimal.Decimal{"BTC": d("0.3"), "ETH": d("0.2"), "USD": d("0.5")}
+		plan, err := po.planRebalance(holdings, current, dailyReturns, nil)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Trades)
+		assert.True(t, plan.EstimatedCost.IsZero())
+		assert.Equal(t, RebalanceRecommendNoTrade, plan.Recommendation)
+	})
+
+	t.Run("quote currency and market assets", func(t *testing.T) {
+		holdings := map[string]PortfolioHolding{
+			"BTC":     {Quantity: d("1"), Price: d("50000")},
+			"ETH/BTC": {Quantity: d("10"), Price: d("2500")},
+			"USDT":    {Quantity: d("25000"), Price: d("1")},
+		}
+		targets := map[string]decimal.Decimal{"BTC": d("0.4"), "ETH/BTC": d("0.5"), "USDT": d("0.1")}
+		plan, err := po.planRebalance(holdings, targets, nil, &RebalanceCostModel{QuoteCurrency: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, plan.Trades, 2)
+		assert.Equal(t, "BTC/USDT", plan.Trades[0].Symbol)
+		assert.Equal(t, OrderSideSell, plan.Trades[0].Side)
+		assert.Equal(t, "ETH/BTC", plan.Trades[1].Symbol)
+		assert.Equal(t, OrderSideBuy, plan.Trades[1].Side)
+	})
+
+	t.Run("nothing to rebalance", func(t *testing.T) {
+		_, err := po.planRebalance(map[string]PortfolioHolding{"BTC": {Quantity: d("1")}}, targets, dailyReturns, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,551 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// liveOrderPrefix starts the client order IDs of live bot orders, telling them apart from
+// orders the account owner places directly
+const liveOrderPrefix = "bot-"
+
+const (
+	defaultReconciliationInterval = 5 * time.Minute
+	defaultReconciliationLookback = 24 * time.Hour
+
+	// reconciliationGrace skips orders placed this recently, which may still be in flight
+	reconciliationGrace = time.Minute
+)
+
+// ErrNoReconciliationReport is returned before the first reconciliation has finished
+var ErrNoReconciliationReport = errors.New("no reconciliation has run yet")
+
+// LiveOrder is a live bot order, journaled before it is sent to the exchange and updated with
+// its outcome. Its status is pending until the exchange answers, then completed or failed;
+// reconciliation repairs orders whose outcome was lost.
+type LiveOrder struct {
+	ExecutionOrder
+	BotID           string    `json:"bot_id"`
+	ConnectionID    uuid.UUID `json:"connection_id"`
+	Exchange        string    `json:"exchange"`
+	ExchangeOrderID string    `json:"exchange_order_id,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// LiveOrderStore journals live bot orders
+type LiveOrderStore interface {
+	// SaveLiveOrder inserts the order or updates its outcome
+	SaveLiveOrder(ctx context.Context, order *LiveOrder) error
+	// ListLiveOrders returns the orders created since the time, oldest first
+	ListLiveOrders(ctx context.Context, since time.Time) ([]*LiveOrder, error)
+}
+
+// ExchangeOrderStatus is the state of an order as reported by an exchange
+type ExchangeOrderStatus string
+
+const (
+	ExchangeOrderOpen     ExchangeOrderStatus = "open"
+	ExchangeOrderFilled   ExchangeOrderStatus = "filled"
+	ExchangeOrderCanceled ExchangeOrderStatus = "canceled"
+	ExchangeOrderRejected ExchangeOrderStatus = "rejected"
+	ExchangeOrderExpired  ExchangeOrderStatus = "expired"
+)
+
+// ExchangeOrder is an order as reported by an exchange. Orders closed after a partial fill
+// keep their filled quantity.
+type ExchangeOrder struct {
+	OrderID        string              `json:"order_id"`
+	ClientOrderID  string              `json:"client_order_id"`
+	Symbol         string              `json:"symbol"`
+	Side           OrderSide           `json:"side"`
+	Status         ExchangeOrderStatus `json:"status"`
+	Quantity       decimal.Decimal     `json:"quantity"`
+	FilledQuantity decimal.Decimal     `json:"filled_quantity"`
+	AveragePrice   decimal.Decimal     `json:"average_price"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// ExchangeOrderReader is implemented by connectors whose orders can be reconciled
+type ExchangeOrderReader interface {
+	// OpenOrders returns the account's open orders on every symbol
+	OpenOrders(ctx context.Context, credentials ExchangeCredentials) ([]ExchangeOrder, error)
+	// RecentOrders returns the symbol's orders created since the time, filled ones included
+	RecentOrders(ctx context.Context, credentials ExchangeCredentials, symbol string, since time.Time) ([]ExchangeOrder, error)
+}
+
+// DiscrepancyType classifies a difference between the order journal and an exchange
+type DiscrepancyType string
+
+const (
+	// DiscrepancyMissedFill is an order the exchange filled that the journal has as pending or
+	// failed; the journal is repaired and the fill recorded on the bot if it still exists
+	DiscrepancyMissedFill DiscrepancyType = "missed_fill"
+	// DiscrepancyMissedCancel is a pending order the exchange closed without a fill; the
+	// journal marks it canceled
+	DiscrepancyMissedCancel DiscrepancyType = "missed_cancel"
+	// DiscrepancyPhantomFill is an order the journal has as filled that the exchange did not fill
+	DiscrepancyPhantomFill DiscrepancyType = "phantom_fill"
+	// DiscrepancyFillMismatch is a filled order whose quantities differ
+	DiscrepancyFillMismatch DiscrepancyType = "fill_mismatch"
+	// DiscrepancyUnknownOrder is a pending order the exchange does not know about; the journal
+	// marks it rejected
+	DiscrepancyUnknownOrder DiscrepancyType = "unknown_order"
+	// DiscrepancyUntrackedOrder is a bot order on the exchange that is missing from the journal
+	DiscrepancyUntrackedOrder DiscrepancyType = "untracked_order"
+)
+
+// OrderDiscrepancy is a difference found by reconciliation. Repaired is set when the journal
+// was brought in line with the exchange; other discrepancies need a look.
+type OrderDiscrepancy struct {
+	Type            DiscrepancyType     `json:"type"`
+	ConnectionID    uuid.UUID           `json:"connection_id"`
+	Exchange        string              `json:"exchange"`
+	BotID           string              `json:"bot_id,omitempty"`
+	OrderID         string              `json:"order_id,omitempty"`
+	ClientOrderID   string              `json:"client_order_id"`
+	ExchangeOrderID string              `json:"exchange_order_id,omitempty"`
+	Symbol          string              `json:"symbol"`
+	Side            OrderSide           `json:"side,omitempty"`
+	LocalStatus     ExecutionStatus     `json:"local_status,omitempty"`
+	ExchangeStatus  ExchangeOrderStatus `json:"exchange_status,omitempty"`
+	LocalFilled     decimal.Decimal     `json:"local_filled"`
+	ExchangeFilled  decimal.Decimal     `json:"exchange_filled"`
+	Repaired        bool                `json:"repaired"`
+	Detail          string              `json:"detail"`
+	DetectedAt      time.Time           `json:"detected_at"`
+}
+
+// ReconciliationReport is the outcome of one reconciliation pass. Connections that could not
+// be read are listed in Errors and their orders are not checked.
+type ReconciliationReport struct {
+	ID            string              `json:"id"`
+	Trigger       string              `json:"trigger"`
+	StartedAt     time.Time           `json:"started_at"`
+	FinishedAt    time.Time           `json:"finished_at"`
+	Since         time.Time           `json:"since"`
+	Connections   int                 `json:"connections"`
+	OrdersChecked int                 `json:"orders_checked"`
+	Repaired      int                 `json:"repaired"`
+	Discrepancies []*OrderDiscrepancy `json:"discrepancies"`
+	Errors        []string            `json:"errors"`
+}
+
+// ReconciliationListener receives the discrepancies found by each reconciliation
+type ReconciliationListener interface {
+	OrderDiscrepancy(discrepancy *OrderDiscrepancy)
+}
+
+// OrderReconciler compares the journal of live bot orders with the orders of each exchange
+// connection they were placed through, repairing the journal where the exchange's answer was
+// lost, such as when the service restarted mid-order
+type OrderReconciler struct {
+	logger    *observability.Logger
+	engine    *TradingBotEngine
+	store     LiveOrderStore
+	interval  time.Duration
+	lookback  time.Duration
+	listeners []ReconciliationListener
+	latest    *ReconciliationReport
+	mu        sync.RWMutex
+
+	// Discrepancies of the last pass; listeners only hear of new ones
+	reported map[string]bool
+}
+
+// NewOrderReconciler creates a reconciler for the orders the engine journals in store
+func NewOrderReconciler(logger *observability.Logger, engine *TradingBotEngine, store LiveOrderStore) *OrderReconciler {
+	return &OrderReconciler{
+		logger:   logger,
+		engine:   engine,
+		store:    store,
+		interval: defaultReconciliationInterval,
+		lookback: defaultReconciliationLookback,
+	}
+}
+
+// SetSchedule sets how often orders are reconciled and how far back; zero keeps the default
+func (r *OrderReconciler) SetSchedule(interval, lookback time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if interval > 0 {
+		r.interval = interval
+	}
+	if lookback > 0 {
+		r.lookback = lookback
+	}
+}
+
+// AddListener registers a listener for discrepancies. Discrepancies that are not repaired
+// show up in every report, but listeners are only notified when one is first found.
+func (r *OrderReconciler) AddListener(listener ReconciliationListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Run reconciles once at startup and then on every interval until ctx is done
+func (r *OrderReconciler) Run(ctx context.Context) {
+	r.Reconcile(ctx, "startup")
+
+	r.mu.RLock()
+	interval := r.interval
+	r.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reconcile(ctx, "interval")
+		}
+	}
+}
+
+// LatestReport returns the report of the last finished reconciliation
+func (r *OrderReconciler) LatestReport() (*ReconciliationReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.latest == nil {
+		return nil, ErrNoReconciliationReport
+	}
+	return r.latest, nil
+}
+
+// Reconcile checks the journaled orders of the lookback window against their exchanges,
+// repairs what it can and notifies listeners of every discrepancy
+func (r *OrderReconciler) Reconcile(ctx context.Context, trigger string) *ReconciliationReport {
+	r.mu.RLock()
+	lookback := r.lookback
+	r.mu.RUnlock()
+
+	now := time.Now().UTC()
+	report := &ReconciliationReport{
+		ID:            uuid.New().String(),
+		Trigger:       trigger,
+		StartedAt:     now,
+		Since:         now.Add(-lookback),
+		Discrepancies: make([]*OrderDiscrepancy, 0),
+		Errors:        make([]string, 0),
+	}
+
+	orders, err := r.store.ListLiveOrders(ctx, report.Since)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to list live orders: %v", err))
+	} else {
+		byConnection := make(map[uuid.UUID][]*LiveOrder)
+		connections := make([]uuid.UUID, 0)
+		for _, order := range orders {
+			if _, ok := byConnection[order.ConnectionID]; !ok {
+				connections = append(connections, order.ConnectionID)
+			}
+			byConnection[order.ConnectionID] = append(byConnection[order.ConnectionID], order)
+		}
+		for _, connectionID := range connections {
+			r.reconcileConnection(ctx, report, connectionID, byConnection[connectionID], now)
+		}
+	}
+	report.FinishedAt = time.Now().UTC()
+
+	reported := make(map[string]bool, len(report.Discrepancies))
+	fresh := make([]*OrderDiscrepancy, 0)
+	r.mu.Lock()
+	for _, discrepancy := range report.Discrepancies {
+		key := string(discrepancy.Type) + ":" + discrepancy.ClientOrderID
+		if !r.reported[key] {
+			fresh = append(fresh, discrepancy)
+		}
+		reported[key] = true
+	}
+	r.reported = reported
+	r.latest = report
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	for _, discrepancy := range fresh {
+		for _, listener := range listeners {
+			listener.OrderDiscrepancy(discrepancy)
+		}
+	}
+
+	fields := map[string]interface{}{
+		"trigger":        trigger,
+		"connections":    report.Connections,
+		"orders_checked": report.OrdersChecked,
+		"discrepancies":  len(report.Discrepancies),
+		"repaired":       report.Repaired,
+		"errors":         len(report.Errors),
+	}
+	if len(report.Discrepancies) > 0 || len(report.Errors) > 0 {
+		r.logger.Warn(ctx, "Order reconciliation found problems", fields)
+	} else {
+		r.logger.Info(ctx, "Order reconciliation completed", fields)
+	}
+	return report
+}
+
+// reconcileConnection compares a connection's journaled orders with the exchange's open and
+// recent orders. A connection whose orders cannot all be read is skipped, since missing
+// exchange orders would be reported as unknown.
+func (r *OrderReconciler) reconcileConnection(ctx context.Context, report *ReconciliationReport, connectionID uuid.UUID, orders []*LiveOrder, now time.Time) {
+	exchange := orders[0].Exchange
+	reader, credentials, err := r.engine.exchangeManager.orderReader(ctx, exchange, connectionID)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("connection %s: %v", connectionID, err))
+		return
+	}
+
+	remote := make(map[string]ExchangeOrder)
+	open, err := reader.OpenOrders(ctx, credentials)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("connection %s: failed to fetch open orders: %v", connectionID, err))
+		return
+	}
+	for _, order := range open {
+		remote[order.ClientOrderID] = order
+	}
+
+	symbols := make(map[string]bool)
+	for _, order := range orders {
+		symbols[order.Symbol] = true
+	}
+	for symbol := range symbols {
+		recent, err := reader.RecentOrders(ctx, credentials, symbol, report.Since)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("connection %s: failed to fetch %s orders: %v", connectionID, symbol, err))
+			return
+		}
+		for _, order := range recent {
+			remote[order.ClientOrderID] = order
+		}
+	}
+	report.Connections++
+
+	settled := now.Add(-reconciliationGrace)
+	journaled := make(map[string]bool, len(orders))
+	for _, order := range orders {
+		journaled[order.ClientOrderID] = true
+		if order.CreatedAt.After(settled) {
+			continue
+		}
+		report.OrdersChecked++
+
+		exchangeOrder, known := remote[order.ClientOrderID]
+		if discrepancy := r.compare(ctx, order, exchangeOrder, known); discrepancy != nil {
+			report.add(discrepancy)
+		}
+	}
+
+	untracked := make([]ExchangeOrder, 0)
+	for clientOrderID, order := range remote {
+		if !journaled[clientOrderID] && strings.HasPrefix(clientOrderID, liveOrderPrefix) && order.UpdatedAt.Before(settled) {
+			untracked = append(untracked, order)
+		}
+	}
+	sort.Slice(untracked, func(i, j int) bool { return untracked[i].UpdatedAt.Before(untracked[j].UpdatedAt) })
+	for _, order := range untracked {
+		report.add(&OrderDiscrepancy{
+			Type:            DiscrepancyUntrackedOrder,
+			ConnectionID:    connectionID,
+			Exchange:        exchange,
+			ClientOrderID:   order.ClientOrderID,
+			ExchangeOrderID: order.OrderID,
+			Symbol:          order.Symbol,
+			Side:            order.Side,
+			ExchangeStatus:  order.Status,
+			ExchangeFilled:  order.FilledQuantity,
+			Detail:          "the exchange has a bot order that is missing from the order journal",
+			DetectedAt:      time.Now().UTC(),
+		})
+	}
+}
+
+// compare returns the discrepancy between a journaled order and the exchange's view of it,
+// repairing the journal where the exchange's answer settles the order, or nil if they agree
+func (r *OrderReconciler) compare(ctx context.Context, order *LiveOrder, remote ExchangeOrder, known bool) *OrderDiscrepancy {
+	discrepancy := &OrderDiscrepancy{
+		ConnectionID:    order.ConnectionID,
+		Exchange:        order.Exchange,
+		BotID:           order.BotID,
+		OrderID:         order.ID,
+		ClientOrderID:   order.ClientOrderID,
+		ExchangeOrderID: order.ExchangeOrderID,
+		Symbol:          order.Symbol,
+		Side:            order.Side,
+		LocalStatus:     order.Status,
+		LocalFilled:     order.FilledQuantity,
+		DetectedAt:      time.Now().UTC(),
+	}
+	if known {
+		discrepancy.ExchangeOrderID = remote.OrderID
+		discrepancy.ExchangeStatus = remote.Status
+		discrepancy.ExchangeFilled = remote.FilledQuantity
+	}
+	pending := order.Status == ExecutionStatusPending || order.Status == ExecutionStatusExecuting
+
+	switch {
+	case !known && order.Status == ExecutionStatusCompleted:
+		discrepancy.Type = DiscrepancyPhantomFill
+		discrepancy.Detail = "the exchange has no record of an order journaled as filled"
+	case !known && pending:
+		discrepancy.Type = DiscrepancyUnknownOrder
+		discrepancy.Detail = "the exchange has no record of the order; it is marked rejected"
+		order.Status = ExecutionStatusRejected
+		discrepancy.Repaired = r.save(ctx, order)
+	case !known:
+		return nil
+	case remote.Status == ExchangeOrderOpen:
+		return nil
+	case remote.FilledQuantity.IsPositive() && order.Status != ExecutionStatusCompleted:
+		discrepancy.Type = DiscrepancyMissedFill
+		discrepancy.Detail = fmt.Sprintf("the exchange filled %s at %s", remote.FilledQuantity, remote.AveragePrice)
+		order.Status = ExecutionStatusCompleted
+		order.FilledQuantity = remote.FilledQuantity
+		order.AveragePrice = remote.AveragePrice
+		order.ExchangeOrderID = remote.OrderID
+		order.Error = ""
+		discrepancy.Repaired = r.save(ctx, order)
+		if discrepancy.Repaired && r.engine.applyReconciledFill(ctx, order, remote.UpdatedAt) {
+			discrepancy.Detail += "; the fill was recorded on the bot"
+		}
+	case remote.FilledQuantity.IsPositive():
+		if order.FilledQuantity.Equal(remote.FilledQuantity) {
+			return nil
+		}
+		discrepancy.Type = DiscrepancyFillMismatch
+		discrepancy.Detail = fmt.Sprintf("the journal has %s filled, the exchange %s", order.FilledQuantity, remote.FilledQuantity)
+	case order.Status == ExecutionStatusCompleted:
+		discrepancy.Type = DiscrepancyPhantomFill
+		discrepancy.Detail = fmt.Sprintf("the exchange %s the order without filling it", remote.Status)
+	case pending:
+		discrepancy.Type = DiscrepancyMissedCancel
+		discrepancy.Detail = fmt.Sprintf("the exchange %s the order without filling it; it is marked canceled", remote.Status)
+		order.Status = ExecutionStatusCanceled
+		discrepancy.Repaired = r.save(ctx, order)
+	default:
+		return nil
+	}
+	return discrepancy
+}
+
+// save stores a repaired order, reporting whether the repair was kept
+func (r *OrderReconciler) save(ctx context.Context, order *LiveOrder) bool {
+	order.UpdatedAt = time.Now().UTC()
+	if err := r.store.SaveLiveOrder(ctx, order); err != nil {
+		r.logger.Error(ctx, "Failed to save reconciled order", err, map[string]interface{}{
+			"order_id": order.ID,
+			"bot_id":   order.BotID,
+		})
+		return false
+	}
+	return true
+}
+
+// add appends a discrepancy to the report
+func (report *ReconciliationReport) add(discrepancy *OrderDiscrepancy) {
+	report.Discrepancies = append(report.Discrepancies, discrepancy)
+	if discrepancy.Repaired {
+		report.Repaired++
+	}
+}
+
+// SetLiveOrderStore journals live bot orders, which lets them be reconciled with the exchanges
+func (tbe *TradingBotEngine) SetLiveOrderStore(store LiveOrderStore) {
+	tbe.exchangeManager.mu.Lock()
+	defer tbe.exchangeManager.mu.Unlock()
+	tbe.exchangeManager.orders = store
+}
+
+// applyReconciledFill records a fill found by reconciliation on the bot that placed the order,
+// reporting whether the bot still exists. The exchange does not report the market price, so
+// the fill is recorded without slippage.
+func (tbe *TradingBotEngine) applyReconciledFill(ctx context.Context, order *LiveOrder, executedAt time.Time) bool {
+	bot, err := tbe.GetBot(order.BotID)
+	if err != nil {
+		return false
+	}
+
+	trade := &BotTrade{
+		ID:          uuid.New().String(),
+		BotID:       bot.ID,
+		Mode:        ExecutionModeLive,
+		OrderID:     order.ExchangeOrderID,
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Quantity:    order.FilledQuantity,
+		MarketPrice: order.AveragePrice,
+		FillPrice:   order.AveragePrice,
+		ExecutedAt:  executedAt,
+	}
+
+	bot.mu.Lock()
+	tbe.recordTrade(bot, trade)
+	bot.mu.Unlock()
+	tbe.notifyOrderFilled(trade)
+
+	tbe.logger.Warn(ctx, "Recorded bot fill found by reconciliation", map[string]interface{}{
+		"bot_id":   bot.ID,
+		"order_id": order.ID,
+		"symbol":   trade.Symbol,
+		"side":     string(trade.Side),
+		"quantity": trade.Quantity.String(),
+	})
+	return true
+}
+
+// newLiveOrder creates the journal entry of a live order before it is placed
+func newLiveOrder(botID, exchange string, connectionID uuid.UUID, order *BotOrder, marketPrice decimal.Decimal) *LiveOrder {
+	now := time.Now().UTC()
+	live := &LiveOrder{
+		ExecutionOrder: ExecutionOrder{
+			ID:             uuid.New().String(),
+			ClientOrderID:  liveOrderPrefix + strings.ReplaceAll(uuid.New().String(), "-", ""),
+			StrategyID:     botID,
+			Symbol:         order.Symbol,
+			Side:           order.Side,
+			OrderType:      OrderTypeMarket,
+			Quantity:       order.Quantity,
+			Price:          marketPrice,
+			Status:         ExecutionStatusPending,
+			ExecutionStart: now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+		BotID:        botID,
+		ConnectionID: connectionID,
+		Exchange:     strings.ToLower(exchange),
+	}
+	if order.Type == OrderTypeLimit {
+		live.OrderType = OrderTypeLimit
+		live.Price = order.LimitPrice
+		live.TimeInForce = TimeInForceIOC
+	}
+	return live
+}
+
+// settle records the exchange's answer to a journaled order
+func (order *LiveOrder) settle(trade *BotTrade, err error) {
+	now := time.Now().UTC()
+	order.ExecutionEnd = now
+	order.UpdatedAt = now
+	if err != nil {
+		order.Status = ExecutionStatusFailed
+		order.Error = err.Error()
+		return
+	}
+	order.Status = ExecutionStatusCompleted
+	order.FilledQuantity = trade.Quantity
+	order.AveragePrice = trade.FillPrice
+	order.TotalCommission = trade.Fee
+	order.ExchangeOrderID = trade.OrderID
+}
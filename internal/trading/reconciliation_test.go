@@ -0,0 +1,277 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrderConnector is an exchange whose balances and orders are set by the test
+type fakeOrderConnector struct {
+	balances   []ExchangeBalance
+	balanceErr error
+	open       []ExchangeOrder
+	recent     []ExchangeOrder
+	recentErr  error
+}
+
+func (f *fakeOrderConnector) Name() string { return "fake" }
+
+func (f *fakeOrderConnector) FetchBalances(ctx context.Context, credentials ExchangeCredentials) ([]ExchangeBalance, error) {
+	return f.balances, f.balanceErr
+}
+
+func (f *fakeOrderConnector) PlaceOrder(ctx context.Context, credentials ExchangeCredentials, order *BotOrder) (*BotTrade, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeOrderConnector) OpenOrders(ctx context.Context, credentials ExchangeCredentials) ([]ExchangeOrder, error) {
+	return f.open, nil
+}
+
+func (f *fakeOrderConnector) RecentOrders(ctx context.Context, credentials ExchangeCredentials, symbol string, since time.Time) ([]ExchangeOrder, error) {
+	if f.recentErr != nil {
+		return nil, f.recentErr
+	}
+	orders := make([]ExchangeOrder, 0)
+	for _, order := range f.recent {
+		if order.Symbol == symbol {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+// staticCredentials returns the same credentials for every connection
+type staticCredentials struct{}
+
+func (staticCredentials) Credentials(ctx context.Context, connectionID uuid.UUID) (ExchangeCredentials, error) {
+	return ExchangeCredentials{APIKey: "key", APISecret: "secret"}, nil
+}
+
+// memoryLiveOrders journals copies of live orders in memory
+type memoryLiveOrders struct {
+	orders map[string]LiveOrder
+	mu     sync.Mutex
+}
+
+func (m *memoryLiveOrders) SaveLiveOrder(ctx context.Context, order *LiveOrder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[order.ID] = *order
+	return nil
+}
+
+func (m *memoryLiveOrders) ListLiveOrders(ctx context.Context, since time.Time) ([]*LiveOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orders := make([]*LiveOrder, 0, len(m.orders))
+	for _, order := range m.orders {
+		if !order.CreatedAt.Before(since) {
+			copied := order
+			orders = append(orders, &copied)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+	return orders, nil
+}
+
+func (m *memoryLiveOrders) get(id string) LiveOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.orders[id]
+}
+
+func newReconciliationFixture() (*OrderReconciler, *TradingBotEngine, *fakeOrderConnector, *memoryLiveOrders) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	engine := NewTradingBotEngine(logger, &BotEngineConfig{MaxConcurrentBots: 10})
+	connector := &fakeOrderConnector{}
+	engine.SetExchangeConnectivity(staticCredentials{}, connector)
+	store := &memoryLiveOrders{orders: make(map[string]LiveOrder)}
+	engine.SetLiveOrderStore(store)
+	return NewOrderReconciler(logger, engine, store), engine, connector, store
+}
+
+// journal saves a live order of the connection created age ago
+func journal(store *memoryLiveOrders, connectionID uuid.UUID, botID string, status ExecutionStatus, filled string, age time.Duration) *LiveOrder {
+	created := time.Now().UTC().Add(-age)
+	order := &LiveOrder{
+		ExecutionOrder: ExecutionOrder{
+			ID:             uuid.New().String(),
+			ClientOrderID:  liveOrderPrefix + uuid.New().String(),
+			Symbol:         "BTC/USDT",
+			Side:           OrderSideBuy,
+			OrderType:      OrderTypeMarket,
+			Quantity:       decimal.RequireFromString("0.5"),
+			FilledQuantity: decimal.RequireFromString(filled),
+			Status:         status,
+			CreatedAt:      created,
+			UpdatedAt:      created,
+		},
+		BotID:        botID,
+		ConnectionID: connectionID,
+		Exchange:     "fake",
+	}
+	_ = store.SaveLiveOrder(context.Background(), order)
+	return order
+}
+
+func exchangeOrder(clientOrderID string, status ExchangeOrderStatus, filled string, updated time.Time) ExchangeOrder {
+	return ExchangeOrder{
+		OrderID:        "42",
+		ClientOrderID:  clientOrderID,
+		Symbol:         "BTC/USDT",
+		Side:           OrderSideBuy,
+		Status:         status,
+		Quantity:       decimal.RequireFromString("0.5"),
+		FilledQuantity: decimal.RequireFromString(filled),
+		AveragePrice:   decimal.NewFromInt(30000),
+		UpdatedAt:      updated,
+	}
+}
+
+func TestOrderReconciler_Compare(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         ExecutionStatus
+		filled         string
+		remoteStatus   ExchangeOrderStatus // empty when the exchange has no record
+		remoteFilled   string
+		want           DiscrepancyType // empty when the journal and exchange agree
+		repaired       bool
+		journaledAfter ExecutionStatus
+	}{
+		{name: "missed fill of pending order", status: ExecutionStatusPending, filled: "0", remoteStatus: ExchangeOrderFilled, remoteFilled: "0.5",
+			want: DiscrepancyMissedFill, repaired: true, journaledAfter: ExecutionStatusCompleted},
+		{name: "missed fill of failed order", status: ExecutionStatusFailed, filled: "0", remoteStatus: ExchangeOrderCanceled, remoteFilled: "0.2",
+			want: DiscrepancyMissedFill, repaired: true, journaledAfter: ExecutionStatusCompleted},
+		{name: "missed cancel", status: ExecutionStatusPending, filled: "0", remoteStatus: ExchangeOrderExpired, remoteFilled: "0",
+			want: DiscrepancyMissedCancel, repaired: true, journaledAfter: ExecutionStatusCanceled},
+		{name: "phantom fill of closed order", status: ExecutionStatusCompleted, filled: "0.5", remoteStatus: ExchangeOrderCanceled, remoteFilled: "0",
+			want: DiscrepancyPhantomFill, journaledAfter: ExecutionStatusCompleted},
+		{name: "phantom fill of unknown order", status: ExecutionStatusCompleted, filled: "0.5",
+			want: DiscrepancyPhantomFill, journaledAfter: ExecutionStatusCompleted},
+		{name: "fill mismatch", status: ExecutionStatusCompleted, filled: "0.5", remoteStatus: ExchangeOrderFilled, remoteFilled: "0.4",
+			want: DiscrepancyFillMismatch, journaledAfter: ExecutionStatusCompleted},
+		{name: "unknown order", status: ExecutionStatusPending, filled: "0",
+			want: DiscrepancyUnknownOrder, repaired: true, journaledAfter: ExecutionStatusRejected},
+		{name: "matching fill", status: ExecutionStatusCompleted, filled: "0.5", remoteStatus: ExchangeOrderFilled, remoteFilled: "0.5",
+			journaledAfter: ExecutionStatusCompleted},
+		{name: "open order", status: ExecutionStatusPending, filled: "0", remoteStatus: ExchangeOrderOpen, remoteFilled: "0",
+			journaledAfter: ExecutionStatusPending},
+		{name: "failed order the exchange never saw", status: ExecutionStatusFailed, filled: "0",
+			journaledAfter: ExecutionStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler, _, connector, store := newReconciliationFixture()
+			order := journal(store, uuid.New(), "gone", tt.status, tt.filled, 10*time.Minute)
+			if tt.remoteStatus != "" {
+				remote := exchangeOrder(order.ClientOrderID, tt.remoteStatus, tt.remoteFilled, time.Now().Add(-9*time.Minute))
+				if tt.remoteStatus == ExchangeOrderOpen {
+					connector.open = append(connector.open, remote)
+				}
+				connector.recent = append(connector.recent, remote)
+			}
+
+			report := reconciler.Reconcile(context.Background(), "test")
+			assert.Empty(t, report.Errors)
+			assert.Equal(t, 1, report.Connections)
+			assert.Equal(t, 1, report.OrdersChecked)
+			assert.Equal(t, tt.journaledAfter, store.get(order.ID).Status)
+
+			if tt.want == "" {
+				assert.Empty(t, report.Discrepancies)
+				return
+			}
+			require.Len(t, report.Discrepancies, 1)
+			discrepancy := report.Discrepancies[0]
+			assert.Equal(t, tt.want, discrepancy.Type)
+			assert.Equal(t, tt.repaired, discrepancy.Repaired)
+			assert.Equal(t, order.ClientOrderID, discrepancy.ClientOrderID)
+			assert.Equal(t, tt.status, discrepancy.LocalStatus)
+		})
+	}
+}
+
+func TestOrderReconciler_MissedFillIsRecordedOnBot(t *testing.T) {
+	reconciler, engine, connector, store := newReconciliationFixture()
+	bot, err := engine.RegisterBot(context.Background(), &BotConfig{TradingPairs: []string{"BTC/USDT"}, Exchange: "fake"}, StrategyDCA)
+	require.NoError(t, err)
+
+	order := journal(store, uuid.New(), bot.ID, ExecutionStatusPending, "0", 10*time.Minute)
+	connector.recent = []ExchangeOrder{exchangeOrder(order.ClientOrderID, ExchangeOrderFilled, "0.5", time.Now().Add(-9*time.Minute))}
+
+	report := reconciler.Reconcile(context.Background(), "test")
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, DiscrepancyMissedFill, report.Discrepancies[0].Type)
+	assert.Contains(t, report.Discrepancies[0].Detail, "recorded on the bot")
+	assert.Equal(t, 1, report.Repaired)
+
+	repaired := store.get(order.ID)
+	assert.True(t, decimal.RequireFromString("0.5").Equal(repaired.FilledQuantity))
+	assert.True(t, decimal.NewFromInt(30000).Equal(repaired.AveragePrice))
+	assert.Equal(t, "42", repaired.ExchangeOrderID)
+
+	bot.mu.RLock()
+	require.Len(t, bot.trades, 1)
+	assert.Equal(t, "42", bot.trades[0].OrderID)
+	assert.Equal(t, ExecutionModeLive, bot.trades[0].Mode)
+	bot.mu.RUnlock()
+
+	// The repaired journal agrees with the exchange on the next pass
+	again := reconciler.Reconcile(context.Background(), "test")
+	assert.Empty(t, again.Discrepancies)
+}
+
+func TestOrderReconciler_GraceWindowAndUntrackedOrders(t *testing.T) {
+	reconciler, _, connector, store := newReconciliationFixture()
+	connectionID := uuid.New()
+	inFlight := journal(store, connectionID, "bot", ExecutionStatusPending, "0", 10*time.Second)
+	settled := journal(store, connectionID, "bot", ExecutionStatusCompleted, "0.5", 10*time.Minute)
+
+	old := time.Now().Add(-5 * time.Minute)
+	connector.recent = []ExchangeOrder{
+		exchangeOrder(settled.ClientOrderID, ExchangeOrderFilled, "0.5", old),
+		exchangeOrder(liveOrderPrefix+"lost", ExchangeOrderFilled, "0.1", old),
+		// Bot orders still in flight and orders placed by the account owner are not reported
+		exchangeOrder(liveOrderPrefix+"new", ExchangeOrderFilled, "0.1", time.Now()),
+		exchangeOrder("manual-1", ExchangeOrderFilled, "1", old),
+	}
+
+	report := reconciler.Reconcile(context.Background(), "test")
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 1, report.OrdersChecked, "orders inside the grace window are not checked")
+	assert.Equal(t, ExecutionStatusPending, store.get(inFlight.ID).Status)
+
+	require.Len(t, report.Discrepancies, 1)
+	untracked := report.Discrepancies[0]
+	assert.Equal(t, DiscrepancyUntrackedOrder, untracked.Type)
+	assert.Equal(t, liveOrderPrefix+"lost", untracked.ClientOrderID)
+	assert.Equal(t, connectionID, untracked.ConnectionID)
+	assert.False(t, untracked.Repaired)
+}
+
+func TestOrderReconciler_SkipsConnectionsThatCannotBeRead(t *testing.T) {
+	reconciler, _, connector, store := newReconciliationFixture()
+	order := journal(store, uuid.New(), "bot", ExecutionStatusPending, "0", 10*time.Minute)
+	connector.recentErr = errors.New("binance has more than 10000 BTCUSDT orders")
+
+	report := reconciler.Reconcile(context.Background(), "test")
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "failed to fetch BTC/USDT orders")
+	assert.Zero(t, report.Connections)
+	assert.Empty(t, report.Discrepancies)
+	assert.Equal(t, ExecutionStatusPending, store.get(order.ID).Status, "unread orders are not marked rejected")
+}
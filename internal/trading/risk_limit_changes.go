@@ -0,0 +1,504 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Risk limit change errors
+var (
+	ErrRiskProfileNotFound       = errors.New("risk profile not found")
+	ErrRiskTemplateNotFound      = errors.New("risk limit template not found")
+	ErrRiskTemplateExists        = errors.New("risk limit template already exists")
+	ErrInvalidRiskLimits         = errors.New("invalid risk limits")
+	ErrRiskLimitChangeNotFound   = errors.New("risk limit change not found")
+	ErrRiskLimitChangeReviewed   = errors.New("risk limit change has already been reviewed")
+	ErrRiskLimitChangePending    = errors.New("bot already has a pending risk limit change")
+	ErrRiskLimitChangeSelfReview = errors.New("risk limit changes must be reviewed by a different user")
+)
+
+// Built-in risk limit templates
+const (
+	RiskTemplateConservative = "conservative"
+	RiskTemplateModerate     = "moderate"
+	RiskTemplateAggressive   = "aggressive"
+)
+
+// States of a risk limit change
+const (
+	RiskLimitChangePendingApproval = "pending"
+	RiskLimitChangeApplied         = "applied"
+	RiskLimitChangeRejected        = "rejected"
+)
+
+// BotRiskLimits are the limits of a bot's risk profile. Fractions are of the portfolio.
+type BotRiskLimits struct {
+	MaxPositionSize      decimal.Decimal `json:"max_position_size"`
+	StopLoss             decimal.Decimal `json:"stop_loss"`
+	TakeProfit           decimal.Decimal `json:"take_profit"`
+	MaxDrawdown          decimal.Decimal `json:"max_drawdown"`
+	MaxDailyLoss         decimal.Decimal `json:"max_daily_loss"`
+	MaxConsecutiveLosses int             `json:"max_consecutive_losses"`
+	RiskTolerance        RiskTolerance   `json:"risk_tolerance"`
+}
+
+// RiskLimitTemplate is a named set of risk limits that can be assigned to bots
+type RiskLimitTemplate struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Custom      bool          `json:"custom"`
+	Limits      BotRiskLimits `json:"limits"`
+	CreatedBy   uuid.UUID     `json:"created_by,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// RiskLimitChange is a requested change of a bot's risk limits. Changes that lower or keep
+// every limit apply at once; changes that raise any limit wait in the pending state until a
+// second user approves them.
+type RiskLimitChange struct {
+	ID          string        `json:"id"`
+	BotID       string        `json:"bot_id"`
+	Template    string        `json:"template,omitempty"`
+	Previous    BotRiskLimits `json:"previous"`
+	Requested   BotRiskLimits `json:"requested"`
+	Raised      []string      `json:"raised,omitempty"` // limits the change loosens
+	Status      string        `json:"status"`
+	RequestedBy uuid.UUID     `json:"requested_by"`
+	RequestedAt time.Time     `json:"requested_at"`
+	ReviewedBy  uuid.UUID     `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time    `json:"reviewed_at,omitempty"`
+	ReviewNote  string        `json:"review_note,omitempty"`
+	AppliedAt   *time.Time    `json:"applied_at,omitempty"`
+}
+
+// RiskLimitListener is notified whenever the effective risk limits of a bot change. Listeners
+// are called after the change is applied, without the risk manager locked.
+type RiskLimitListener interface {
+	RiskLimitsChanged(change *RiskLimitChange)
+}
+
+// builtinRiskTemplates returns the templates every risk manager starts with
+func builtinRiskTemplates() map[string]*RiskLimitTemplate {
+	now := time.Now()
+	return map[string]*RiskLimitTemplate{
+		RiskTemplateConservative: {
+			Name:        RiskTemplateConservative,
+			Description: "Small positions and tight stops for capital preservation",
+			Limits: BotRiskLimits{
+				MaxPositionSize:      decimal.NewFromFloat(0.10),
+				StopLoss:             decimal.NewFromFloat(0.05),
+				TakeProfit:           decimal.NewFromFloat(0.10),
+				MaxDrawdown:          decimal.NewFromFloat(0.08),
+				MaxDailyLoss:         decimal.NewFromFloat(0.02),
+				MaxConsecutiveLosses: 3,
+				RiskTolerance:        RiskToleranceLow,
+			},
+			CreatedAt: now,
+		},
+		RiskTemplateModerate: {
+			Name:        RiskTemplateModerate,
+			Description: "The default limits for bots without a strategy-specific profile",
+			Limits: BotRiskLimits{
+				MaxPositionSize:      decimal.NewFromFloat(0.15),
+				StopLoss:             decimal.NewFromFloat(0.10),
+				TakeProfit:           decimal.NewFromFloat(0.20),
+				MaxDrawdown:          decimal.NewFromFloat(0.12),
+				MaxDailyLoss:         decimal.NewFromFloat(0.04),
+				MaxConsecutiveLosses: 4,
+				RiskTolerance:        RiskToleranceMedium,
+			},
+			CreatedAt: now,
+		},
+		RiskTemplateAggressive: {
+			Name:        RiskTemplateAggressive,
+			Description: "Large positions and wide stops for high-conviction strategies",
+			Limits: BotRiskLimits{
+				MaxPositionSize:      decimal.NewFromFloat(0.25),
+				StopLoss:             decimal.NewFromFloat(0.15),
+				TakeProfit:           decimal.NewFromFloat(0.30),
+				MaxDrawdown:          decimal.NewFromFloat(0.20),
+				MaxDailyLoss:         decimal.NewFromFloat(0.08),
+				MaxConsecutiveLosses: 6,
+				RiskTolerance:        RiskToleranceAggressive,
+			},
+			CreatedAt: now,
+		},
+	}
+}
+
+// Validate checks that fractions are within (0, 1], take profit is positive and the risk
+// tolerance is known
+func (l BotRiskLimits) Validate() error {
+	fractions := map[string]decimal.Decimal{
+		"max_position_size": l.MaxPositionSize,
+		"stop_loss":         l.StopLoss,
+		"max_drawdown":      l.MaxDrawdown,
+		"max_daily_loss":    l.MaxDailyLoss,
+	}
+	for name, value := range fractions {
+		if !value.IsPositive() || value.GreaterThan(decimal.NewFromInt(1)) {
+			return fmt.Errorf("%w: %s must be greater than 0 and at most 1", ErrInvalidRiskLimits, name)
+		}
+	}
+	if !l.TakeProfit.IsPositive() {
+		return fmt.Errorf("%w: take_profit must be greater than 0", ErrInvalidRiskLimits)
+	}
+	if l.MaxConsecutiveLosses < 1 {
+		return fmt.Errorf("%w: max_consecutive_losses must be at least 1", ErrInvalidRiskLimits)
+	}
+	switch l.RiskTolerance {
+	case RiskToleranceLow, RiskToleranceMedium, RiskToleranceHigh, RiskToleranceAggressive:
+	default:
+		return fmt.Errorf("%w: unknown risk_tolerance %q", ErrInvalidRiskLimits, l.RiskTolerance)
+	}
+	return nil
+}
+
+// raisedLimits names the limits that are looser in next than in current. Take profit and risk
+// tolerance don't bound losses, so changing them never needs approval.
+func raisedLimits(current, next BotRiskLimits) []string {
+	raised := make([]string, 0)
+	if next.MaxPositionSize.GreaterThan(current.MaxPositionSize) {
+		raised = append(raised, "max_position_size")
+	}
+	if next.StopLoss.GreaterThan(current.StopLoss) {
+		raised = append(raised, "stop_loss")
+	}
+	if next.MaxDrawdown.GreaterThan(current.MaxDrawdown) {
+		raised = append(raised, "max_drawdown")
+	}
+	if next.MaxDailyLoss.GreaterThan(current.MaxDailyLoss) {
+		raised = append(raised, "max_daily_loss")
+	}
+	if next.MaxConsecutiveLosses > current.MaxConsecutiveLosses {
+		raised = append(raised, "max_consecutive_losses")
+	}
+	return raised
+}
+
+// limits returns the risk limits of a profile
+func (p *BotRiskProfile) limits() BotRiskLimits {
+	return BotRiskLimits{
+		MaxPositionSize:      p.MaxPositionSize,
+		StopLoss:             p.StopLoss,
+		TakeProfit:           p.TakeProfit,
+		MaxDrawdown:          p.MaxDrawdown,
+		MaxDailyLoss:         p.MaxDailyLoss,
+		MaxConsecutiveLosses: p.MaxConsecutiveLosses,
+		RiskTolerance:        p.RiskTolerance,
+	}
+}
+
+// SetAuditor records requested, approved and rejected risk limit changes in the audit log
+func (brm *BotRiskManager) SetAuditor(auditor *security.AuditManager) {
+	brm.mu.Lock()
+	defer brm.mu.Unlock()
+	brm.auditor = auditor
+}
+
+// AddRiskLimitListener registers a listener for changes of effective risk limits
+func (brm *BotRiskManager) AddRiskLimitListener(listener RiskLimitListener) {
+	brm.mu.Lock()
+	defer brm.mu.Unlock()
+	brm.limitListeners = append(brm.limitListeners, listener)
+}
+
+// GetBotRiskProfile returns a copy of a bot's risk profile
+func (brm *BotRiskManager) GetBotRiskProfile(botID string) (*BotRiskProfile, error) {
+	brm.mu.RLock()
+	defer brm.mu.RUnlock()
+
+	profile, exists := brm.botRiskProfiles[botID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRiskProfileNotFound, botID)
+	}
+	profileCopy := *profile
+	return &profileCopy, nil
+}
+
+// ListRiskTemplates returns the built-in and custom templates sorted by name
+func (brm *BotRiskManager) ListRiskTemplates() []*RiskLimitTemplate {
+	brm.mu.RLock()
+	defer brm.mu.RUnlock()
+
+	templates := make([]*RiskLimitTemplate, 0, len(brm.riskTemplates))
+	for _, template := range brm.riskTemplates {
+		templateCopy := *template
+		templates = append(templates, &templateCopy)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// CreateRiskTemplate adds a custom template. Names are case-insensitive and can't replace an
+// existing template, so bots assigned a template keep meaning the same limits.
+func (brm *BotRiskManager) CreateRiskTemplate(ctx context.Context, name, description string, limits BotRiskLimits, createdBy uuid.UUID) (*RiskLimitTemplate, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil, fmt.Errorf("%w: template name is required", ErrInvalidRiskLimits)
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	brm.mu.Lock()
+	if _, exists := brm.riskTemplates[name]; exists {
+		brm.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrRiskTemplateExists, name)
+	}
+	template := &RiskLimitTemplate{
+		Name:        name,
+		Description: description,
+		Custom:      true,
+		Limits:      limits,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}
+	brm.riskTemplates[name] = template
+	brm.mu.Unlock()
+
+	brm.logger.Info(ctx, "Risk limit template created", map[string]interface{}{
+		"template":   name,
+		"created_by": createdBy.String(),
+	})
+
+	templateCopy := *template
+	return &templateCopy, nil
+}
+
+// AssignRiskTemplate requests that a bot's limits be set to those of a template
+func (brm *BotRiskManager) AssignRiskTemplate(ctx context.Context, botID, templateName string, requestedBy uuid.UUID) (*RiskLimitChange, error) {
+	brm.mu.RLock()
+	template, exists := brm.riskTemplates[strings.ToLower(strings.TrimSpace(templateName))]
+	brm.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRiskTemplateNotFound, templateName)
+	}
+	return brm.requestLimitChange(ctx, botID, template.Name, template.Limits, requestedBy)
+}
+
+// RequestRiskLimitChange requests new limits for a bot. Lowering limits applies immediately;
+// raising any limit creates a pending change that must be approved by another user.
+func (brm *BotRiskManager) RequestRiskLimitChange(ctx context.Context, botID string, limits BotRiskLimits, requestedBy uuid.UUID) (*RiskLimitChange, error) {
+	return brm.requestLimitChange(ctx, botID, "", limits, requestedBy)
+}
+
+func (brm *BotRiskManager) requestLimitChange(ctx context.Context, botID, template string, limits BotRiskLimits, requestedBy uuid.UUID) (*RiskLimitChange, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	brm.mu.Lock()
+	profile, exists := brm.botRiskProfiles[botID]
+	if !exists {
+		brm.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrRiskProfileNotFound, botID)
+	}
+
+	now := time.Now()
+	change := &RiskLimitChange{
+		ID:          uuid.New().String(),
+		BotID:       botID,
+		Template:    template,
+		Previous:    profile.limits(),
+		Requested:   limits,
+		Raised:      raisedLimits(profile.limits(), limits),
+		RequestedBy: requestedBy,
+		RequestedAt: now,
+	}
+
+	if len(change.Raised) > 0 {
+		for _, other := range brm.limitChanges {
+			if other.BotID == botID && other.Status == RiskLimitChangePendingApproval {
+				brm.mu.Unlock()
+				return nil, fmt.Errorf("%w: %s", ErrRiskLimitChangePending, other.ID)
+			}
+		}
+		change.Status = RiskLimitChangePendingApproval
+		brm.limitChanges[change.ID] = change
+		changeCopy := *change
+		brm.mu.Unlock()
+
+		brm.logger.Info(ctx, "Risk limit increase pending approval", map[string]interface{}{
+			"change_id":    change.ID,
+			"bot_id":       botID,
+			"template":     template,
+			"raised":       change.Raised,
+			"requested_by": requestedBy.String(),
+		})
+		brm.auditLimitChange(ctx, &requestedBy, "risk_limit_change_requested", &changeCopy)
+		return &changeCopy, nil
+	}
+
+	brm.applyLimitChangeLocked(profile, change, now)
+	changeCopy := *change
+	listeners := brm.limitListeners
+	brm.mu.Unlock()
+
+	brm.logger.Info(ctx, "Risk limits lowered", map[string]interface{}{
+		"change_id":    change.ID,
+		"bot_id":       botID,
+		"template":     template,
+		"requested_by": requestedBy.String(),
+	})
+	brm.auditLimitChange(ctx, &requestedBy, "risk_limit_change_applied", &changeCopy)
+	notifyRiskLimitListeners(listeners, &changeCopy)
+	return &changeCopy, nil
+}
+
+// ApproveRiskLimitChange applies a pending change on behalf of an approver, who must not be
+// the user who requested it. Callers are responsible for checking the approver is an admin.
+func (brm *BotRiskManager) ApproveRiskLimitChange(ctx context.Context, changeID string, approver uuid.UUID, note string) (*RiskLimitChange, error) {
+	brm.mu.Lock()
+	change, err := brm.pendingChangeLocked(changeID, approver)
+	if err != nil {
+		brm.mu.Unlock()
+		return nil, err
+	}
+	profile, exists := brm.botRiskProfiles[change.BotID]
+	if !exists {
+		brm.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrRiskProfileNotFound, change.BotID)
+	}
+
+	now := time.Now()
+	change.ReviewedBy = approver
+	change.ReviewedAt = &now
+	change.ReviewNote = note
+	// Limits may have been lowered since the request, so the change is recorded against the
+	// limits it actually replaces
+	change.Previous = profile.limits()
+	brm.applyLimitChangeLocked(profile, change, now)
+	changeCopy := *change
+	listeners := brm.limitListeners
+	brm.mu.Unlock()
+
+	brm.logger.Warn(ctx, "Risk limit increase approved", map[string]interface{}{
+		"change_id":    change.ID,
+		"bot_id":       change.BotID,
+		"raised":       change.Raised,
+		"requested_by": change.RequestedBy.String(),
+		"approved_by":  approver.String(),
+	})
+	brm.auditLimitChange(ctx, &approver, "risk_limit_change_approved", &changeCopy)
+	notifyRiskLimitListeners(listeners, &changeCopy)
+	return &changeCopy, nil
+}
+
+// RejectRiskLimitChange rejects a pending change, leaving the bot's limits unchanged
+func (brm *BotRiskManager) RejectRiskLimitChange(ctx context.Context, changeID string, reviewer uuid.UUID, note string) (*RiskLimitChange, error) {
+	brm.mu.Lock()
+	change, err := brm.pendingChangeLocked(changeID, reviewer)
+	if err != nil {
+		brm.mu.Unlock()
+		return nil, err
+	}
+	now := time.Now()
+	change.Status = RiskLimitChangeRejected
+	change.ReviewedBy = reviewer
+	change.ReviewedAt = &now
+	change.ReviewNote = note
+	changeCopy := *change
+	brm.mu.Unlock()
+
+	brm.logger.Info(ctx, "Risk limit increase rejected", map[string]interface{}{
+		"change_id":   change.ID,
+		"bot_id":      change.BotID,
+		"rejected_by": reviewer.String(),
+	})
+	brm.auditLimitChange(ctx, &reviewer, "risk_limit_change_rejected", &changeCopy)
+	return &changeCopy, nil
+}
+
+// ListRiskLimitChanges returns the changes in the given status, or all when status is empty,
+// newest first
+func (brm *BotRiskManager) ListRiskLimitChanges(status string) []*RiskLimitChange {
+	brm.mu.RLock()
+	defer brm.mu.RUnlock()
+
+	changes := make([]*RiskLimitChange, 0)
+	for _, change := range brm.limitChanges {
+		if status != "" && change.Status != status {
+			continue
+		}
+		changeCopy := *change
+		changes = append(changes, &changeCopy)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].RequestedAt.After(changes[j].RequestedAt) })
+	return changes
+}
+
+// pendingChangeLocked finds a pending change that reviewer may review. The caller must hold
+// brm.mu.
+func (brm *BotRiskManager) pendingChangeLocked(changeID string, reviewer uuid.UUID) (*RiskLimitChange, error) {
+	change, exists := brm.limitChanges[changeID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRiskLimitChangeNotFound, changeID)
+	}
+	if change.Status != RiskLimitChangePendingApproval {
+		return nil, fmt.Errorf("%w: %s is %s", ErrRiskLimitChangeReviewed, changeID, change.Status)
+	}
+	if change.RequestedBy == reviewer {
+		return nil, ErrRiskLimitChangeSelfReview
+	}
+	return change, nil
+}
+
+// applyLimitChangeLocked makes a change's limits effective. The caller must hold brm.mu.
+func (brm *BotRiskManager) applyLimitChangeLocked(profile *BotRiskProfile, change *RiskLimitChange, now time.Time) {
+	limits := change.Requested
+	profile.MaxPositionSize = limits.MaxPositionSize
+	profile.StopLoss = limits.StopLoss
+	profile.TakeProfit = limits.TakeProfit
+	profile.MaxDrawdown = limits.MaxDrawdown
+	profile.MaxDailyLoss = limits.MaxDailyLoss
+	profile.MaxConsecutiveLosses = limits.MaxConsecutiveLosses
+	profile.RiskTolerance = limits.RiskTolerance
+	profile.LastUpdated = now
+
+	change.Status = RiskLimitChangeApplied
+	change.AppliedAt = &now
+	brm.limitChanges[change.ID] = change
+}
+
+// auditLimitChange records a risk limit change in the audit log
+func (brm *BotRiskManager) auditLimitChange(ctx context.Context, userID *uuid.UUID, action string, change *RiskLimitChange) {
+	brm.mu.RLock()
+	auditor := brm.auditor
+	brm.mu.RUnlock()
+	if auditor == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"change_id":    change.ID,
+		"bot_id":       change.BotID,
+		"template":     change.Template,
+		"status":       change.Status,
+		"raised":       change.Raised,
+		"previous":     change.Previous,
+		"requested":    change.Requested,
+		"requested_by": change.RequestedBy.String(),
+	}
+	if change.ReviewedAt != nil {
+		details["reviewed_by"] = change.ReviewedBy.String()
+		details["review_note"] = change.ReviewNote
+	}
+	if err := auditor.LogTradingEvent(ctx, userID, action, security.AuditResultSuccess, details); err != nil {
+		brm.logger.Error(ctx, "Failed to audit risk limit change", err, details)
+	}
+}
+
+func notifyRiskLimitListeners(listeners []RiskLimitListener, change *RiskLimitChange) {
+	for _, listener := range listeners {
+		listener.RiskLimitsChanged(change)
+	}
+}
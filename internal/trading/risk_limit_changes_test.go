@@ -0,0 +1,171 @@
+package trading
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitRecorder records the changes risk limit listeners are notified of
+type limitRecorder struct {
+	changes []*RiskLimitChange
+}
+
+func (r *limitRecorder) RiskLimitsChanged(change *RiskLimitChange) {
+	r.changes = append(r.changes, change)
+}
+
+func newRiskLimitFixture(t *testing.T) (*BotRiskManager, *security.AuditManager, *limitRecorder) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	manager := NewBotRiskManager(logger)
+	auditor := security.NewAuditManager(logger, &security.AuditConfig{
+		EnableAuditLogging: true,
+		AuditLevel:         security.AuditLevelStandard,
+		ArchiveThreshold:   1 << 30,
+	}, nil)
+	manager.SetAuditor(auditor)
+	recorder := &limitRecorder{}
+	manager.AddRiskLimitListener(recorder)
+
+	// The moderate template's limits, so the other built-in templates raise or lower all of them
+	profile := &BotRiskProfile{}
+	applyLimits(profile, builtinRiskTemplates()[RiskTemplateModerate].Limits)
+	require.NoError(t, manager.RegisterBot("bot-1", "grid", profile))
+	return manager, auditor, recorder
+}
+
+func applyLimits(profile *BotRiskProfile, limits BotRiskLimits) {
+	profile.MaxPositionSize = limits.MaxPositionSize
+	profile.StopLoss = limits.StopLoss
+	profile.TakeProfit = limits.TakeProfit
+	profile.MaxDrawdown = limits.MaxDrawdown
+	profile.MaxDailyLoss = limits.MaxDailyLoss
+	profile.MaxConsecutiveLosses = limits.MaxConsecutiveLosses
+	profile.RiskTolerance = limits.RiskTolerance
+}
+
+func auditActions(t *testing.T, auditor *security.AuditManager, userID uuid.UUID) []string {
+	events, err := auditor.GetAuditEvents(context.Background(), security.AuditEventFilter{UserID: &userID})
+	require.NoError(t, err)
+	actions := make([]string, 0, len(events))
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+func TestRiskLimitChange_LoweringAppliesImmediately(t *testing.T) {
+	manager, auditor, recorder := newRiskLimitFixture(t)
+	ctx := context.Background()
+	trader := uuid.New()
+
+	change, err := manager.AssignRiskTemplate(ctx, "bot-1", " Conservative ", trader)
+	require.NoError(t, err)
+	assert.Equal(t, RiskLimitChangeApplied, change.Status)
+	assert.Empty(t, change.Raised)
+	assert.NotNil(t, change.AppliedAt)
+	assert.Equal(t, RiskTemplateConservative, change.Template)
+
+	profile, err := manager.GetBotRiskProfile("bot-1")
+	require.NoError(t, err)
+	assert.Equal(t, builtinRiskTemplates()[RiskTemplateConservative].Limits, profile.limits())
+
+	require.Len(t, recorder.changes, 1)
+	assert.Equal(t, change.ID, recorder.changes[0].ID)
+	assert.Equal(t, []string{"risk_limit_change_applied"}, auditActions(t, auditor, trader))
+	assert.Empty(t, manager.ListRiskLimitChanges(RiskLimitChangePendingApproval))
+}
+
+func TestRiskLimitChange_RaisingWaitsForApproval(t *testing.T) {
+	manager, auditor, recorder := newRiskLimitFixture(t)
+	ctx := context.Background()
+	trader, admin := uuid.New(), uuid.New()
+	moderate := builtinRiskTemplates()[RiskTemplateModerate].Limits
+
+	// Raising only the take profit doesn't loosen a loss bound
+	requested := moderate
+	requested.TakeProfit = decimal.NewFromFloat(0.40)
+	change, err := manager.RequestRiskLimitChange(ctx, "bot-1", requested, trader)
+	require.NoError(t, err)
+	assert.Equal(t, RiskLimitChangeApplied, change.Status)
+
+	requested.MaxPositionSize = decimal.NewFromFloat(0.30)
+	requested.MaxConsecutiveLosses = 8
+	pending, err := manager.RequestRiskLimitChange(ctx, "bot-1", requested, trader)
+	require.NoError(t, err)
+	assert.Equal(t, RiskLimitChangePendingApproval, pending.Status)
+	assert.Equal(t, []string{"max_position_size", "max_consecutive_losses"}, pending.Raised)
+	assert.Nil(t, pending.AppliedAt)
+
+	profile, err := manager.GetBotRiskProfile("bot-1")
+	require.NoError(t, err)
+	assert.True(t, moderate.MaxPositionSize.Equal(profile.MaxPositionSize), "raised limits are not applied")
+	assert.Len(t, recorder.changes, 1)
+
+	_, err = manager.AssignRiskTemplate(ctx, "bot-1", RiskTemplateAggressive, trader)
+	assert.ErrorIs(t, err, ErrRiskLimitChangePending)
+
+	// The requester can't approve or reject their own change
+	_, err = manager.ApproveRiskLimitChange(ctx, pending.ID, trader, "")
+	assert.ErrorIs(t, err, ErrRiskLimitChangeSelfReview)
+	_, err = manager.RejectRiskLimitChange(ctx, pending.ID, trader, "")
+	assert.ErrorIs(t, err, ErrRiskLimitChangeSelfReview)
+
+	approved, err := manager.ApproveRiskLimitChange(ctx, pending.ID, admin, "seasonal volume")
+	require.NoError(t, err)
+	assert.Equal(t, RiskLimitChangeApplied, approved.Status)
+	assert.Equal(t, admin, approved.ReviewedBy)
+	assert.Equal(t, "seasonal volume", approved.ReviewNote)
+	assert.True(t, decimal.NewFromFloat(0.40).Equal(approved.Previous.TakeProfit), "previous limits are those replaced")
+
+	profile, err = manager.GetBotRiskProfile("bot-1")
+	require.NoError(t, err)
+	assert.Equal(t, requested, profile.limits())
+	require.Len(t, recorder.changes, 2)
+	assert.Equal(t, pending.ID, recorder.changes[1].ID)
+
+	_, err = manager.ApproveRiskLimitChange(ctx, pending.ID, admin, "")
+	assert.ErrorIs(t, err, ErrRiskLimitChangeReviewed)
+	_, err = manager.ApproveRiskLimitChange(ctx, "missing", admin, "")
+	assert.ErrorIs(t, err, ErrRiskLimitChangeNotFound)
+
+	assert.Equal(t, []string{"risk_limit_change_applied", "risk_limit_change_requested"}, auditActions(t, auditor, trader))
+	assert.Equal(t, []string{"risk_limit_change_approved"}, auditActions(t, auditor, admin))
+}
+
+func TestRiskLimitChange_Rejected(t *testing.T) {
+	manager, auditor, recorder := newRiskLimitFixture(t)
+	ctx := context.Background()
+	trader, admin := uuid.New(), uuid.New()
+
+	pending, err := manager.AssignRiskTemplate(ctx, "bot-1", RiskTemplateAggressive, trader)
+	require.NoError(t, err)
+	require.Equal(t, RiskLimitChangePendingApproval, pending.Status)
+
+	rejected, err := manager.RejectRiskLimitChange(ctx, pending.ID, admin, "too risky")
+	require.NoError(t, err)
+	assert.Equal(t, RiskLimitChangeRejected, rejected.Status)
+	assert.Equal(t, "too risky", rejected.ReviewNote)
+
+	profile, err := manager.GetBotRiskProfile("bot-1")
+	require.NoError(t, err)
+	assert.Equal(t, builtinRiskTemplates()[RiskTemplateModerate].Limits, profile.limits())
+	assert.Empty(t, recorder.changes, "rejected changes don't change effective limits")
+
+	_, err = manager.ApproveRiskLimitChange(ctx, pending.ID, admin, "")
+	assert.ErrorIs(t, err, ErrRiskLimitChangeReviewed)
+	assert.Len(t, manager.ListRiskLimitChanges(RiskLimitChangeRejected), 1)
+
+	events, err := auditor.GetAuditEvents(ctx, security.AuditEventFilter{UserID: &admin, Action: "risk_limit_change_rejected"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, pending.ID, events[0].Details["change_id"])
+	assert.Equal(t, "too risky", events[0].Details["review_note"])
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/realtime"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/shopspring/decimal"
 )
@@ -19,11 +20,17 @@ type SmartOrderRouter struct {
 	routingRules  []*RoutingRule
 	venueSelector *VenueSelector
 	metrics       *RouterMetrics
+	orderBooks    OrderBookSource
 	mu            sync.RWMutex
 	isRunning     bool
 	stopChan      chan struct{}
 }
 
+// OrderBookSource provides per-venue level-2 depth for slippage estimation
+type OrderBookSource interface {
+	GetExchangeOrderBook(exchange, symbol string, depth int) (*realtime.OrderBook, error)
+}
+
 // RouterConfig contains smart order router configuration
 type RouterConfig struct {
 	EnableSmartRouting     bool               `json:"enable_smart_routing"`
@@ -178,6 +185,9 @@ type RoutingDecision struct {
 	EstimatedLatency time.Duration      `json:"estimated_latency"`
 	ConfidenceScore  decimal.Decimal    `json:"confidence_score"`
 	DecisionTime     time.Time          `json:"decision_time"`
+
+	// EstimatedSlippage is the book-walk cost over the top of book, included in EstimatedCost
+	EstimatedSlippage decimal.Decimal `json:"estimated_slippage"`
 }
 
 // VenueAllocation represents venue allocation for an order
@@ -190,6 +200,9 @@ type VenueAllocation struct {
 	Reason     string          `json:"reason"`
 }
 
+// routerBookDepth is the number of levels per side used for venue slippage estimates
+const routerBookDepth = 500
+
 // NewSmartOrderRouter creates a new smart order router
 func NewSmartOrderRouter(logger *observability.Logger) *SmartOrderRouter {
 	config := &RouterConfig{
@@ -322,6 +335,12 @@ func (sor *SmartOrderRouter) RegisterVenue(venue *VenueInfo) error {
 	sor.mu.Lock()
 	defer sor.mu.Unlock()
 
+	sor.registerVenue(venue)
+	return nil
+}
+
+// registerVenue is RegisterVenue for callers holding sor.mu
+func (sor *SmartOrderRouter) registerVenue(venue *VenueInfo) {
 	venue.LastUpdated = time.Now()
 	sor.venues[venue.ID] = venue
 
@@ -337,8 +356,6 @@ func (sor *SmartOrderRouter) RegisterVenue(venue *VenueInfo) error {
 		"fee_rate":   venue.FeeRate.String(),
 		"latency":    venue.Latency,
 	})
-
-	return nil
 }
 
 // GetVenueInfo retrieves venue information
@@ -354,6 +371,32 @@ func (sor *SmartOrderRouter) GetVenueInfo(venueID string) (*VenueInfo, error) {
 	return venue, nil
 }
 
+// SetOrderBookSource sets the live order books used to estimate per-venue slippage
+func (sor *SmartOrderRouter) SetOrderBookSource(books OrderBookSource) {
+	sor.mu.Lock()
+	defer sor.mu.Unlock()
+	sor.orderBooks = books
+}
+
+// EstimateSlippage walks a venue's order book for a market order of quantity
+func (sor *SmartOrderRouter) EstimateSlippage(venueID, symbol string, side OrderSide, quantity decimal.Decimal) (*realtime.FillEstimate, error) {
+	sor.mu.RLock()
+	defer sor.mu.RUnlock()
+	return sor.estimateSlippage(venueID, symbol, side, quantity)
+}
+
+// estimateSlippage is EstimateSlippage for callers holding sor.mu
+func (sor *SmartOrderRouter) estimateSlippage(venueID, symbol string, side OrderSide, quantity decimal.Decimal) (*realtime.FillEstimate, error) {
+	if sor.orderBooks == nil {
+		return nil, fmt.Errorf("%w: no order book source configured", realtime.ErrOrderBookUnavailable)
+	}
+	book, err := sor.orderBooks.GetExchangeOrderBook(venueID, symbol, routerBookDepth)
+	if err != nil {
+		return nil, err
+	}
+	return book.EstimateFill(string(side), quantity)
+}
+
 // GetMetrics returns routing metrics
 func (sor *SmartOrderRouter) GetMetrics() *RouterMetrics {
 	sor.mu.RLock()
@@ -545,7 +588,12 @@ func (sor *SmartOrderRouter) selectByLowestCost(venues []*VenueInfo, order *Exec
 
 	scores := make([]venueScore, 0, len(venues))
 	for _, venue := range venues {
-		totalCost := venue.FeeRate.Add(venue.AverageSlippage)
+		// Prefer live book slippage for this order over the historical average
+		slippage := venue.AverageSlippage
+		if estimate, err := sor.estimateSlippage(venue.ID, order.Symbol, order.Side, order.Quantity); err == nil && estimate.FullyFilled {
+			slippage = estimate.SlippageBps.Div(decimal.NewFromInt(10000))
+		}
+		totalCost := venue.FeeRate.Add(slippage)
 		scores = append(scores, venueScore{venue: venue, cost: totalCost})
 	}
 
@@ -713,7 +761,7 @@ func (sor *SmartOrderRouter) executeActions(actions []*RoutingAction, decision *
 
 // calculateEstimates calculates cost and latency estimates
 func (sor *SmartOrderRouter) calculateEstimates(decision *RoutingDecision, order *ExecutionOrder) {
-	var totalCost decimal.Decimal
+	var totalCost, totalSlippage decimal.Decimal
 	var maxLatency time.Duration
 
 	for _, allocation := range decision.SelectedVenues {
@@ -727,13 +775,22 @@ func (sor *SmartOrderRouter) calculateEstimates(decision *RoutingDecision, order
 		allocationCost := notionalValue.Mul(venue.FeeRate)
 		totalCost = totalCost.Add(allocationCost)
 
+		// Add the cost of walking the venue's book beyond the best price
+		if allocation.Quantity.IsPositive() {
+			if estimate, err := sor.estimateSlippage(venue.ID, order.Symbol, order.Side, allocation.Quantity); err == nil {
+				slippage := estimate.AveragePrice.Sub(estimate.BestPrice).Abs().Mul(estimate.FilledQuantity)
+				totalSlippage = totalSlippage.Add(slippage)
+			}
+		}
+
 		// Track maximum latency
 		if venue.Latency > maxLatency {
 			maxLatency = venue.Latency
 		}
 	}
 
-	decision.EstimatedCost = totalCost
+	decision.EstimatedCost = totalCost.Add(totalSlippage)
+	decision.EstimatedSlippage = totalSlippage
 	decision.EstimatedLatency = maxLatency
 	decision.ConfidenceScore = decimal.NewFromFloat(0.85) // Simplified confidence score
 }
@@ -764,7 +821,7 @@ func (sor *SmartOrderRouter) updateRoutingMetrics(decision *RoutingDecision) {
 	sor.metrics.LastUpdated = time.Now()
 }
 
-// initializeDefaultVenues initializes default trading venues
+// initializeDefaultVenues initializes default trading venues; the caller holds sor.mu
 func (sor *SmartOrderRouter) initializeDefaultVenues() {
 	defaultVenues := []*VenueInfo{
 		{
@@ -825,7 +882,7 @@ func (sor *SmartOrderRouter) initializeDefaultVenues() {
 	}
 
 	for _, venue := range defaultVenues {
-		sor.RegisterVenue(venue)
+		sor.registerVenue(venue)
 	}
 }
 
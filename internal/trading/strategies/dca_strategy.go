@@ -3,43 +3,97 @@ package strategies
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/shopspring/decimal"
 )
 
-// DCAStrategy implements Dollar Cost Averaging trading strategy
+// DCAStrategy implements Dollar Cost Averaging trading strategy. Each trading pair buys
+// InvestmentAmount once per interval. Smart DCA, enabled by MAPeriod, scales each buy by the
+// moving average of the prices at the last MAPeriod buys over the current price, bounded by
+// MinMultiplier and MaxMultiplier, so it buys more below the average and less above it.
+//
+// The schedule advances when a buy is signalled; the rungs and cost basis of each pair only
+// count the fills reported through OrderObserver.
 type DCAStrategy struct {
-	logger         *observability.Logger
-	config         *DCAConfig
-	lastExecution  time.Time
-	totalInvested  decimal.Decimal
-	totalTokens    decimal.Decimal
-	averagePrice   decimal.Decimal
-	executionCount int
+	logger  *observability.Logger
+	config  *DCAConfig
+	pairs   map[string]*dcaPair
+	pending map[string]*dcaPair // pairs awaiting the outcome of a buy, by signal ID
+	onRung  func(DCARung)
+	mu      sync.Mutex
 }
 
 // DCAConfig holds configuration for DCA strategy
 type DCAConfig struct {
 	InvestmentAmount   decimal.Decimal `yaml:"investment_amount"`
 	Interval           time.Duration   `yaml:"interval"`
-	MaxDeviation       decimal.Decimal `yaml:"max_deviation"`
+	MaxDeviation       decimal.Decimal `yaml:"max_deviation"` // zero disables the deviation check
 	AccumulationPeriod time.Duration   `yaml:"accumulation_period"`
 	TradingPairs       []string        `yaml:"trading_pairs"`
 	Exchange           string          `yaml:"exchange"`
+
+	// Smart DCA; a zero MAPeriod buys InvestmentAmount every time
+	MAPeriod      int             `yaml:"ma_period"`
+	MinMultiplier decimal.Decimal `yaml:"min_multiplier"`
+	MaxMultiplier decimal.Decimal `yaml:"max_multiplier"`
+}
+
+// DCA schedules, which set the interval between buys
+var dcaSchedules = map[string]time.Duration{
+	"hourly": time.Hour,
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// DCARung is one filled DCA buy
+type DCARung struct {
+	Symbol     string          `json:"symbol"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Price      decimal.Decimal `json:"price"`
+	Cost       decimal.Decimal `json:"cost"`
+	Multiplier decimal.Decimal `json:"multiplier"`
+	ExecutedAt time.Time       `json:"executed_at"`
 }
 
-// DCAOrder represents a DCA buy order
-type DCAOrder struct {
-	ID             string          `json:"id"`
-	Symbol         string          `json:"symbol"`
-	Amount         decimal.Decimal `json:"amount"`
-	Price          decimal.Decimal `json:"price"`
-	Timestamp      time.Time       `json:"timestamp"`
-	Status         OrderStatus     `json:"status"`
-	ExecutedAmount decimal.Decimal `json:"executed_amount"`
-	ExecutedPrice  decimal.Decimal `json:"executed_price"`
+// DCAPosition is the accumulated position of one trading pair
+type DCAPosition struct {
+	Symbol       string          `json:"symbol"`
+	Rungs        int             `json:"rungs"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	TotalCost    decimal.Decimal `json:"total_cost"`
+	AverageEntry decimal.Decimal `json:"average_entry"`
+	LastPrice    decimal.Decimal `json:"last_price"`
+	LastBuy      time.Time       `json:"last_buy"`
+	NextBuy      time.Time       `json:"next_buy"`
+	Multiplier   decimal.Decimal `json:"multiplier"` // of the next buy at the last price
+}
+
+// DCAScheduledBuy is an upcoming buy of a DCA schedule. Amount is the quote amount at the
+// current multiplier.
+type DCAScheduledBuy struct {
+	Symbol     string          `json:"symbol"`
+	At         time.Time       `json:"at"`
+	Amount     decimal.Decimal `json:"amount"`
+	Multiplier decimal.Decimal `json:"multiplier"`
+}
+
+// dcaPair is the schedule and position of one trading pair
+type dcaPair struct {
+	symbol    string
+	rungs     int
+	quantity  decimal.Decimal
+	cost      decimal.Decimal
+	lastPrice decimal.Decimal
+	lastBuy   time.Time
+	samples   []decimal.Decimal // prices at the last buys, oldest first
+	lastFill  time.Time
+
+	// Multipliers of signalled buys, by signal ID
+	multipliers map[string]decimal.Decimal
 }
 
 // OrderStatus represents the status of an order
@@ -55,20 +109,27 @@ const (
 // NewDCAStrategy creates a new DCA strategy instance
 func NewDCAStrategy(logger *observability.Logger, config *DCAConfig) *DCAStrategy {
 	return &DCAStrategy{
-		logger:        logger,
-		config:        config,
-		totalInvested: decimal.Zero,
-		totalTokens:   decimal.Zero,
-		averagePrice:  decimal.Zero,
+		logger:  logger,
+		config:  config,
+		pairs:   make(map[string]*dcaPair),
+		pending: make(map[string]*dcaPair),
 	}
 }
 
+// pair returns the state of a trading pair, creating it on first use
+func (dca *DCAStrategy) pair(symbol string) *dcaPair {
+	pair, exists := dca.pairs[symbol]
+	if !exists {
+		pair = &dcaPair{symbol: symbol, multipliers: make(map[string]decimal.Decimal)}
+		dca.pairs[symbol] = pair
+	}
+	return pair
+}
+
 // Execute executes the DCA strategy
 func (dca *DCAStrategy) Execute(ctx context.Context, marketData *MarketData) (interface{}, error) {
-	// Check if it's time to execute
-	if !dca.shouldExecute() {
-		return nil, nil
-	}
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
 
 	// Get current price
 	currentPrice := marketData.Price
@@ -76,18 +137,33 @@ func (dca *DCAStrategy) Execute(ctx context.Context, marketData *MarketData) (in
 		return nil, fmt.Errorf("invalid market price")
 	}
 
+	pair := dca.pair(marketData.Symbol)
+	pair.lastPrice = currentPrice
+
+	// Check if it's time to execute
+	now := marketData.at()
+	if !dca.shouldExecute(pair, now) {
+		return nil, nil
+	}
+
 	// Check price deviation if we have previous executions
-	if dca.executionCount > 0 && !dca.isPriceWithinDeviation(currentPrice) {
+	if !dca.isPriceWithinDeviation(pair, currentPrice) {
 		dca.logger.Info(ctx, "Skipping DCA execution due to price deviation", map[string]interface{}{
+			"symbol":        pair.symbol,
 			"current_price": currentPrice.String(),
-			"average_price": dca.averagePrice.String(),
+			"average_price": pair.averageEntry().String(),
 			"max_deviation": dca.config.MaxDeviation.String(),
 		})
 		return nil, nil
 	}
 
 	// Calculate order amount
-	orderAmount := dca.config.InvestmentAmount
+	pair.samples = append(pair.samples, currentPrice)
+	if len(pair.samples) > dca.config.MAPeriod {
+		pair.samples = pair.samples[len(pair.samples)-dca.config.MAPeriod:]
+	}
+	multiplier := dca.multiplier(pair, currentPrice)
+	orderAmount := dca.config.InvestmentAmount.Mul(multiplier)
 	tokenAmount := orderAmount.Div(currentPrice)
 
 	// Create trading signal
@@ -98,101 +174,267 @@ func (dca *DCAStrategy) Execute(ctx context.Context, marketData *MarketData) (in
 		Amount:    tokenAmount,
 		Price:     currentPrice,
 		OrderType: OrderTypeMarket,
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Strategy:  "DCA",
 		Metadata: map[string]interface{}{
 			"investment_amount": orderAmount.String(),
-			"execution_count":   dca.executionCount + 1,
-			"average_price":     dca.averagePrice.String(),
+			"multiplier":        multiplier.String(),
+			"execution_count":   pair.rungs + 1,
+			"average_price":     pair.averageEntry().String(),
 		},
 	}
 
-	// Update internal state
-	dca.updateState(orderAmount, tokenAmount, currentPrice)
+	// The schedule advances whether or not the buy fills
+	pair.lastBuy = now
+	pair.multipliers[signal.ID] = multiplier
+	dca.pending[signal.ID] = pair
 
 	dca.logger.Info(ctx, "DCA order created", map[string]interface{}{
 		"symbol":            signal.Symbol,
 		"amount":            signal.Amount.String(),
 		"price":             signal.Price.String(),
 		"investment_amount": orderAmount.String(),
-		"execution_count":   dca.executionCount,
+		"multiplier":        multiplier.String(),
+		"execution_count":   pair.rungs + 1,
 	})
 
 	return signal, nil
 }
 
-// shouldExecute checks if it's time to execute the DCA strategy
-func (dca *DCAStrategy) shouldExecute() bool {
-	if dca.lastExecution.IsZero() {
+// shouldExecute checks if it's time to execute the DCA strategy for a pair
+func (dca *DCAStrategy) shouldExecute(pair *dcaPair, now time.Time) bool {
+	if pair.lastBuy.IsZero() {
 		return true
 	}
 
-	return time.Since(dca.lastExecution) >= dca.config.Interval
+	return now.Sub(pair.lastBuy) >= dca.config.Interval
 }
 
-// isPriceWithinDeviation checks if current price is within acceptable deviation
-func (dca *DCAStrategy) isPriceWithinDeviation(currentPrice decimal.Decimal) bool {
-	if dca.averagePrice.IsZero() {
+// isPriceWithinDeviation checks if current price is within acceptable deviation of the pair's
+// average entry
+func (dca *DCAStrategy) isPriceWithinDeviation(pair *dcaPair, currentPrice decimal.Decimal) bool {
+	averagePrice := pair.averageEntry()
+	if averagePrice.IsZero() || dca.config.MaxDeviation.IsZero() {
 		return true
 	}
 
-	deviation := currentPrice.Sub(dca.averagePrice).Div(dca.averagePrice).Abs()
+	deviation := currentPrice.Sub(averagePrice).Div(averagePrice).Abs()
 	return deviation.LessThanOrEqual(dca.config.MaxDeviation)
 }
 
-// updateState updates the internal state after execution
-func (dca *DCAStrategy) updateState(investmentAmount, tokenAmount, price decimal.Decimal) {
-	dca.totalInvested = dca.totalInvested.Add(investmentAmount)
-	dca.totalTokens = dca.totalTokens.Add(tokenAmount)
-	dca.averagePrice = dca.totalInvested.Div(dca.totalTokens)
-	dca.executionCount++
-	dca.lastExecution = time.Now()
+// multiplier scales a buy by the moving average of the sampled prices over the current price,
+// once MAPeriod prices have been sampled
+func (dca *DCAStrategy) multiplier(pair *dcaPair, currentPrice decimal.Decimal) decimal.Decimal {
+	if dca.config.MAPeriod <= 0 || len(pair.samples) < dca.config.MAPeriod {
+		return decimal.NewFromInt(1)
+	}
+
+	sum := decimal.Zero
+	for _, sample := range pair.samples {
+		sum = sum.Add(sample)
+	}
+	average := sum.Div(decimal.NewFromInt(int64(len(pair.samples))))
+
+	multiplier := average.Div(currentPrice)
+	if dca.config.MinMultiplier.IsPositive() && multiplier.LessThan(dca.config.MinMultiplier) {
+		multiplier = dca.config.MinMultiplier
+	}
+	if dca.config.MaxMultiplier.IsPositive() && multiplier.GreaterThan(dca.config.MaxMultiplier) {
+		multiplier = dca.config.MaxMultiplier
+	}
+	return multiplier.Round(4)
 }
 
-// GetPerformance returns performance metrics for the DCA strategy
-func (dca *DCAStrategy) GetPerformance() *StrategyPerformance {
-	if dca.totalTokens.IsZero() {
-		return &StrategyPerformance{
-			TotalInvested:  decimal.Zero,
-			CurrentValue:   decimal.Zero,
-			UnrealizedPnL:  decimal.Zero,
-			ROI:            decimal.Zero,
-			ExecutionCount: 0,
+// averageEntry returns the cost basis per unit of the pair's fills
+func (pair *dcaPair) averageEntry() decimal.Decimal {
+	if !pair.quantity.IsPositive() {
+		return decimal.Zero
+	}
+	return pair.cost.Div(pair.quantity)
+}
+
+// OrderFilled adds a filled buy to its pair's rungs and cost basis
+func (dca *DCAStrategy) OrderFilled(signalID string, quantity, price decimal.Decimal) {
+	dca.mu.Lock()
+	pair, exists := dca.pending[signalID]
+	if !exists {
+		dca.mu.Unlock()
+		return
+	}
+	delete(dca.pending, signalID)
+	multiplier := pair.multipliers[signalID]
+	delete(pair.multipliers, signalID)
+
+	rung := DCARung{
+		Symbol:     pair.symbol,
+		Quantity:   quantity,
+		Price:      price,
+		Cost:       quantity.Mul(price),
+		Multiplier: multiplier,
+		ExecutedAt: time.Now(),
+	}
+	pair.addRung(rung)
+	onRung := dca.onRung
+	dca.mu.Unlock()
+
+	if onRung != nil {
+		onRung(rung)
+	}
+}
+
+// OrderRejected forgets a buy that was not filled; its pair waits for the next interval
+func (dca *DCAStrategy) OrderRejected(signalID string, reason string) {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
+
+	if pair, exists := dca.pending[signalID]; exists {
+		delete(dca.pending, signalID)
+		delete(pair.multipliers, signalID)
+	}
+}
+
+// addRung adds a fill to the pair's position
+func (pair *dcaPair) addRung(rung DCARung) {
+	pair.rungs++
+	pair.quantity = pair.quantity.Add(rung.Quantity)
+	pair.cost = pair.cost.Add(rung.Cost)
+	if rung.ExecutedAt.After(pair.lastFill) {
+		pair.lastFill = rung.ExecutedAt
+	}
+}
+
+// OnRung sets a handler called with every filled buy, such as one persisting the rungs
+func (dca *DCAStrategy) OnRung(handler func(DCARung)) {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
+	dca.onRung = handler
+}
+
+// Restore rebuilds the positions and schedules of the pairs from their filled rungs, so a
+// restarted bot continues its schedule and cost basis
+func (dca *DCAStrategy) Restore(rungs []DCARung) {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
+
+	for _, rung := range rungs {
+		pair := dca.pair(rung.Symbol)
+		pair.addRung(rung)
+		if rung.ExecutedAt.After(pair.lastBuy) {
+			pair.lastBuy = rung.ExecutedAt
+		}
+		pair.samples = append(pair.samples, rung.Price)
+		if len(pair.samples) > dca.config.MAPeriod {
+			pair.samples = pair.samples[len(pair.samples)-dca.config.MAPeriod:]
 		}
 	}
+}
 
-	// Use average price as current price estimate if no current price available
-	currentPrice := dca.averagePrice
-	if currentPrice.IsZero() {
-		currentPrice = decimal.NewFromFloat(1.0) // Default fallback
+// Positions returns the position and next buy of each trading pair, ordered by symbol
+func (dca *DCAStrategy) Positions() []*DCAPosition {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
+
+	positions := make([]*DCAPosition, 0, len(dca.pairs))
+	for _, pair := range dca.pairs {
+		position := &DCAPosition{
+			Symbol:       pair.symbol,
+			Rungs:        pair.rungs,
+			Quantity:     pair.quantity,
+			TotalCost:    pair.cost,
+			AverageEntry: pair.averageEntry(),
+			LastPrice:    pair.lastPrice,
+			LastBuy:      pair.lastBuy,
+			Multiplier:   decimal.NewFromInt(1),
+		}
+		if !pair.lastBuy.IsZero() {
+			position.NextBuy = pair.lastBuy.Add(dca.config.Interval)
+		}
+		if pair.lastPrice.IsPositive() {
+			position.Multiplier = dca.multiplier(pair, pair.lastPrice)
+		}
+		positions = append(positions, position)
 	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Symbol < positions[j].Symbol
+	})
+	return positions
+}
+
+// Schedule projects the next count buys across the trading pairs from now, in time order.
+// Amounts assume the multiplier at each pair's last price holds.
+func (dca *DCAStrategy) Schedule(now time.Time, count int) []DCAScheduledBuy {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
 
-	currentValue := dca.totalTokens.Mul(currentPrice)
-	unrealizedPnL := currentValue.Sub(dca.totalInvested)
-	roi := decimal.Zero
-	if !dca.totalInvested.IsZero() {
-		roi = unrealizedPnL.Div(dca.totalInvested).Mul(decimal.NewFromInt(100))
+	if count <= 0 || dca.config.Interval <= 0 {
+		return []DCAScheduledBuy{}
 	}
 
-	return &StrategyPerformance{
-		TotalInvested:  dca.totalInvested,
-		CurrentValue:   currentValue,
-		UnrealizedPnL:  unrealizedPnL,
-		ROI:            roi,
-		AveragePrice:   dca.averagePrice,
-		TotalTokens:    dca.totalTokens,
-		ExecutionCount: dca.executionCount,
-		LastExecution:  dca.lastExecution,
+	buys := make([]DCAScheduledBuy, 0, count*len(dca.config.TradingPairs))
+	for _, symbol := range dca.config.TradingPairs {
+		multiplier := decimal.NewFromInt(1)
+		next := now
+		if pair, exists := dca.pairs[symbol]; exists {
+			if pair.lastPrice.IsPositive() {
+				multiplier = dca.multiplier(pair, pair.lastPrice)
+			}
+			if due := pair.lastBuy.Add(dca.config.Interval); !pair.lastBuy.IsZero() && due.After(now) {
+				next = due
+			}
+		}
+		for i := 0; i < count; i++ {
+			buys = append(buys, DCAScheduledBuy{
+				Symbol:     symbol,
+				At:         next.Add(time.Duration(i) * dca.config.Interval),
+				Amount:     dca.config.InvestmentAmount.Mul(multiplier),
+				Multiplier: multiplier,
+			})
+		}
 	}
+	sort.SliceStable(buys, func(i, j int) bool {
+		return buys[i].At.Before(buys[j].At)
+	})
+	if len(buys) > count {
+		buys = buys[:count]
+	}
+	return buys
+}
+
+// GetPerformance returns performance metrics for the DCA strategy, valuing each pair's
+// holdings at its last price
+func (dca *DCAStrategy) GetPerformance() *StrategyPerformance {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
+
+	performance := &StrategyPerformance{}
+	for _, pair := range dca.pairs {
+		performance.TotalInvested = performance.TotalInvested.Add(pair.cost)
+		performance.TotalTokens = performance.TotalTokens.Add(pair.quantity)
+		performance.CurrentValue = performance.CurrentValue.Add(pair.quantity.Mul(pair.lastPrice))
+		performance.ExecutionCount += pair.rungs
+		if pair.lastFill.After(performance.LastExecution) {
+			performance.LastExecution = pair.lastFill
+		}
+	}
+	if performance.TotalTokens.IsZero() {
+		return performance
+	}
+
+	performance.AveragePrice = performance.TotalInvested.Div(performance.TotalTokens)
+	performance.UnrealizedPnL = performance.CurrentValue.Sub(performance.TotalInvested)
+	if !performance.TotalInvested.IsZero() {
+		performance.ROI = performance.UnrealizedPnL.Div(performance.TotalInvested).Mul(decimal.NewFromInt(100))
+	}
+	return performance
 }
 
 // Reset resets the DCA strategy state
 func (dca *DCAStrategy) Reset() {
-	dca.totalInvested = decimal.Zero
-	dca.totalTokens = decimal.Zero
-	dca.averagePrice = decimal.Zero
-	dca.executionCount = 0
-	dca.lastExecution = time.Time{}
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
+
+	dca.pairs = make(map[string]*dcaPair)
+	dca.pending = make(map[string]*dcaPair)
 }
 
 // GetConfig returns the strategy configuration
@@ -202,6 +444,8 @@ func (dca *DCAStrategy) GetConfig() *DCAConfig {
 
 // UpdateConfig updates the strategy configuration
 func (dca *DCAStrategy) UpdateConfig(config *DCAConfig) {
+	dca.mu.Lock()
+	defer dca.mu.Unlock()
 	dca.config = config
 }
 
@@ -229,6 +473,14 @@ func (dca *DCAStrategy) Validate() error {
 		return fmt.Errorf("max deviation must be between 0 and 1")
 	}
 
+	if dca.config.MAPeriod < 0 {
+		return fmt.Errorf("moving average period must not be negative")
+	}
+
+	if dca.config.MaxMultiplier.IsPositive() && dca.config.MinMultiplier.GreaterThan(dca.config.MaxMultiplier) {
+		return fmt.Errorf("min multiplier must not exceed max multiplier")
+	}
+
 	if len(dca.config.TradingPairs) == 0 {
 		return fmt.Errorf("at least one trading pair must be specified")
 	}
@@ -247,6 +499,15 @@ type MarketData struct {
 	Change24h decimal.Decimal `json:"change_24h"`
 }
 
+// at returns the time of the market data, which is now when it carries no timestamp.
+// Strategies time their intervals and cooldowns by it so they can replay past candles.
+func (md *MarketData) at() time.Time {
+	if md.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return md.Timestamp
+}
+
 // TradingSignal represents a trading signal generated by a strategy
 type TradingSignal struct {
 	ID        string                 `json:"id"`
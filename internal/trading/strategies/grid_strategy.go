@@ -4,356 +4,412 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/shopspring/decimal"
 )
 
-// GridStrategy implements Grid Trading strategy
+// GridStrategy implements Grid Trading strategy. Each trading pair gets a ladder of evenly
+// spaced price levels; every level but the top one buys when the price falls to it and, once
+// the buy fills, sells one level up. The sell fill re-arms the buy, completing a round trip.
+//
+// Signals are limit orders at the level price. A level waits for the outcome of its order,
+// reported through OrderObserver, before acting again, so a bot that is stopped and started
+// again resumes its grid without placing any order twice. Grids saved through OnChange are
+// restored with Restore when the service restarts.
 type GridStrategy struct {
-	logger        *observability.Logger
-	config        *GridConfig
-	gridLevels    []*GridLevel
-	activeOrders  map[string]*GridOrder
-	basePrice     decimal.Decimal
-	isInitialized bool
+	logger   *observability.Logger
+	config   *GridConfig
+	grids    map[string]*grid
+	orders   map[string]*GridLevel // levels awaiting the outcome of an order, by signal ID
+	fills    int
+	filled   time.Time
+	onChange func(*GridSnapshot)
+	mu       sync.Mutex
 }
 
-// GridConfig holds configuration for Grid strategy
+// GridConfig holds configuration for Grid strategy. The grid spans UpperPrice to LowerPrice
+// when both are set, and otherwise UpperBound and LowerBound times each pair's first price.
 type GridConfig struct {
 	GridLevels   int             `yaml:"grid_levels"`
 	GridSpacing  decimal.Decimal `yaml:"grid_spacing"`
 	UpperBound   decimal.Decimal `yaml:"upper_bound"`
 	LowerBound   decimal.Decimal `yaml:"lower_bound"`
-	OrderAmount  decimal.Decimal `yaml:"order_amount"`
+	UpperPrice   decimal.Decimal `yaml:"upper_price"`
+	LowerPrice   decimal.Decimal `yaml:"lower_price"`
+	OrderAmount  decimal.Decimal `yaml:"order_amount"` // quote amount bought at each level
 	TradingPairs []string        `yaml:"trading_pairs"`
 	Exchange     string          `yaml:"exchange"`
 }
 
-// GridLevel represents a single level in the grid
+// GridLevelState is the position of a grid level in its buy and sell cycle
+type GridLevelState string
+
+const (
+	GridLevelIdle        GridLevelState = "idle"         // the price is at or below the level
+	GridLevelBuyOpen     GridLevelState = "buy_open"     // waiting for the price to fall to the level
+	GridLevelBuyPending  GridLevelState = "buy_pending"  // buy submitted, outcome not yet reported
+	GridLevelHolding     GridLevelState = "holding"      // bought, waiting for the price to reach the sell price
+	GridLevelSellPending GridLevelState = "sell_pending" // sell submitted, outcome not yet reported
+)
+
+// GridLevel is one rung of a grid, buying at BuyPrice and selling at SellPrice, the price of
+// the next level up
 type GridLevel struct {
-	ID        int             `json:"id"`
-	Price     decimal.Decimal `json:"price"`
-	BuyOrder  *GridOrder      `json:"buy_order,omitempty"`
-	SellOrder *GridOrder      `json:"sell_order,omitempty"`
-	IsActive  bool            `json:"is_active"`
-	Filled    bool            `json:"filled"`
+	Index      int             `json:"index"`
+	BuyPrice   decimal.Decimal `json:"buy_price"`
+	SellPrice  decimal.Decimal `json:"sell_price"`
+	State      GridLevelState  `json:"state"`
+	OrderID    string          `json:"order_id,omitempty"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	RoundTrips int             `json:"round_trips"`
+	Profit     decimal.Decimal `json:"profit"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+
+	// Trading pair of the level's grid, and the state restored when its pending order is rejected
+	symbol   string
+	previous GridLevelState
 }
 
-// GridOrder represents an order in the grid
-type GridOrder struct {
-	ID        string          `json:"id"`
-	Level     int             `json:"level"`
-	Symbol    string          `json:"symbol"`
-	Side      OrderSide       `json:"side"`
-	Amount    decimal.Decimal `json:"amount"`
-	Price     decimal.Decimal `json:"price"`
-	Status    OrderStatus     `json:"status"`
-	Timestamp time.Time       `json:"timestamp"`
-	FilledAt  *time.Time      `json:"filled_at,omitempty"`
+// GridSnapshot is the level map and metrics of one trading pair's grid
+type GridSnapshot struct {
+	Symbol     string          `json:"symbol"`
+	LowerPrice decimal.Decimal `json:"lower_price"`
+	UpperPrice decimal.Decimal `json:"upper_price"`
+	LastPrice  decimal.Decimal `json:"last_price"`
+	Levels     []GridLevel     `json:"levels"`
+	RoundTrips int             `json:"round_trips"`
+	GridProfit decimal.Decimal `json:"grid_profit"`
+	Inventory  decimal.Decimal `json:"inventory"`
 }
 
-// OrderSide represents the side of an order
-type OrderSide string
-
-const (
-	OrderSideBuy  OrderSide = "buy"
-	OrderSideSell OrderSide = "sell"
-)
+// grid is the ladder of one trading pair
+type grid struct {
+	symbol    string
+	lower     decimal.Decimal
+	upper     decimal.Decimal
+	lastPrice decimal.Decimal
+	levels    []*GridLevel
+}
 
 // NewGridStrategy creates a new Grid strategy instance
 func NewGridStrategy(logger *observability.Logger, config *GridConfig) *GridStrategy {
 	return &GridStrategy{
-		logger:       logger,
-		config:       config,
-		gridLevels:   make([]*GridLevel, 0),
-		activeOrders: make(map[string]*GridOrder),
+		logger: logger,
+		config: config,
+		grids:  make(map[string]*grid),
+		orders: make(map[string]*GridLevel),
 	}
 }
 
-// Initialize initializes the grid based on current market price
-func (gs *GridStrategy) Initialize(ctx context.Context, currentPrice decimal.Decimal) error {
-	if gs.isInitialized {
-		return fmt.Errorf("grid strategy already initialized")
+// newGrid lays out the levels of a trading pair around its first price
+func (gs *GridStrategy) newGrid(symbol string, currentPrice decimal.Decimal) (*grid, error) {
+	lower, upper := gs.config.LowerPrice, gs.config.UpperPrice
+	if !upper.IsPositive() {
+		lower = currentPrice.Mul(gs.config.LowerBound)
+		upper = currentPrice.Mul(gs.config.UpperBound)
 	}
-
-	gs.basePrice = currentPrice
-
-	// Calculate grid levels
-	upperPrice := currentPrice.Mul(gs.config.UpperBound)
-	lowerPrice := currentPrice.Mul(gs.config.LowerBound)
-
-	priceRange := upperPrice.Sub(lowerPrice)
-	levelSpacing := priceRange.Div(decimal.NewFromInt(int64(gs.config.GridLevels - 1)))
-
-	// Create grid levels
-	for i := 0; i < gs.config.GridLevels; i++ {
-		levelPrice := lowerPrice.Add(levelSpacing.Mul(decimal.NewFromInt(int64(i))))
-
-		level := &GridLevel{
-			ID:       i,
-			Price:    levelPrice,
-			IsActive: true,
-			Filled:   false,
-		}
-
-		gs.gridLevels = append(gs.gridLevels, level)
+	if gs.config.GridLevels < 2 || !lower.IsPositive() || !lower.LessThan(upper) {
+		return nil, fmt.Errorf("invalid grid for %s: %d levels from %s to %s", symbol, gs.config.GridLevels, lower, upper)
 	}
 
-	// Sort levels by price
-	sort.Slice(gs.gridLevels, func(i, j int) bool {
-		return gs.gridLevels[i].Price.LessThan(gs.gridLevels[j].Price)
-	})
-
-	gs.isInitialized = true
-
-	gs.logger.Info(ctx, "Grid strategy initialized", map[string]interface{}{
-		"base_price":    currentPrice.String(),
-		"upper_price":   upperPrice.String(),
-		"lower_price":   lowerPrice.String(),
-		"grid_levels":   len(gs.gridLevels),
-		"level_spacing": levelSpacing.String(),
-	})
-
-	return nil
+	step := upper.Sub(lower).Div(decimal.NewFromInt(int64(gs.config.GridLevels - 1)))
+	g := &grid{symbol: symbol, lower: lower, upper: upper, lastPrice: currentPrice}
+	for i := 0; i < gs.config.GridLevels-1; i++ {
+		g.levels = append(g.levels, &GridLevel{
+			symbol:    symbol,
+			Index:     i,
+			BuyPrice:  lower.Add(step.Mul(decimal.NewFromInt(int64(i)))),
+			SellPrice: lower.Add(step.Mul(decimal.NewFromInt(int64(i + 1)))),
+			State:     GridLevelIdle,
+		})
+	}
+	return g, nil
 }
 
-// Execute executes the Grid strategy
+// Execute executes the Grid strategy, returning the orders of the levels the price crossed.
+// Levels are saved as pending before their orders are returned.
 func (gs *GridStrategy) Execute(ctx context.Context, marketData *MarketData) (interface{}, error) {
-	if !gs.isInitialized {
-		if err := gs.Initialize(ctx, marketData.Price); err != nil {
+	gs.mu.Lock()
+	notify := func() {}
+	defer func() {
+		gs.mu.Unlock()
+		notify()
+	}()
+
+	g, exists := gs.grids[marketData.Symbol]
+	changed := !exists
+	if !exists {
+		var err error
+		if g, err = gs.newGrid(marketData.Symbol, marketData.Price); err != nil {
 			return nil, fmt.Errorf("failed to initialize grid: %w", err)
 		}
+		gs.grids[marketData.Symbol] = g
+
+		gs.logger.Info(ctx, "Grid strategy initialized", map[string]interface{}{
+			"symbol":      marketData.Symbol,
+			"base_price":  marketData.Price.String(),
+			"upper_price": g.upper.String(),
+			"lower_price": g.lower.String(),
+			"grid_levels": len(g.levels) + 1,
+		})
 	}
 
-	var signals []*TradingSignal
-	currentPrice := marketData.Price
+	now := marketData.at()
+	price := marketData.Price
+	g.lastPrice = price
 
-	// Check each grid level
-	for _, level := range gs.gridLevels {
-		if !level.IsActive {
-			continue
-		}
-
-		// Check for buy opportunities (price at or below level)
-		if currentPrice.LessThanOrEqual(level.Price) && level.BuyOrder == nil {
-			buySignal := gs.createBuyOrder(level, marketData)
-			if buySignal != nil {
-				signals = append(signals, buySignal)
+	var signals []*TradingSignal
+	for _, level := range g.levels {
+		switch level.State {
+		case GridLevelIdle:
+			// A buy only rests below the market, so a level arms once the price is above it
+			if price.GreaterThan(level.BuyPrice) {
+				level.State = GridLevelBuyOpen
+				level.UpdatedAt = now
+				changed = true
 			}
-		}
-
-		// Check for sell opportunities (price at or above level and we have inventory)
-		if currentPrice.GreaterThanOrEqual(level.Price) && level.SellOrder == nil && gs.hasInventoryAtLevel(level) {
-			sellSignal := gs.createSellOrder(level, marketData)
-			if sellSignal != nil {
-				signals = append(signals, sellSignal)
+		case GridLevelBuyOpen:
+			if price.LessThanOrEqual(level.BuyPrice) {
+				quantity := gs.config.OrderAmount.Div(level.BuyPrice)
+				signals = append(signals, gs.submit(g.symbol, level, ActionBuy, level.BuyPrice, quantity, now))
+				changed = true
+			}
+		case GridLevelHolding:
+			if price.GreaterThanOrEqual(level.SellPrice) {
+				signals = append(signals, gs.submit(g.symbol, level, ActionSell, level.SellPrice, level.Quantity, now))
+				changed = true
 			}
 		}
 	}
 
-	// Process filled orders
-	gs.processFilledOrders(ctx, currentPrice)
-
+	if changed {
+		notify = gs.changed(g)
+	}
 	return signals, nil
 }
 
-// createBuyOrder creates a buy order for a grid level
-func (gs *GridStrategy) createBuyOrder(level *GridLevel, marketData *MarketData) *TradingSignal {
-	orderID := fmt.Sprintf("grid_buy_%d_%d", level.ID, time.Now().UnixNano())
+// submit creates the limit order signal of a level and marks the level pending
+func (gs *GridStrategy) submit(symbol string, level *GridLevel, action TradingAction, price, quantity decimal.Decimal, now time.Time) *TradingSignal {
+	orderID := fmt.Sprintf("grid_%s_%s_%d_%d", symbol, action, level.Index, now.UnixNano())
 
-	order := &GridOrder{
-		ID:        orderID,
-		Level:     level.ID,
-		Symbol:    marketData.Symbol,
-		Side:      OrderSideBuy,
-		Amount:    gs.config.OrderAmount.Div(level.Price), // Calculate token amount
-		Price:     level.Price,
-		Status:    OrderStatusPending,
-		Timestamp: time.Now(),
+	level.previous = level.State
+	level.State = GridLevelBuyPending
+	if action == ActionSell {
+		level.State = GridLevelSellPending
 	}
-
-	level.BuyOrder = order
-	gs.activeOrders[orderID] = order
+	level.OrderID = orderID
+	level.UpdatedAt = now
+	gs.orders[orderID] = level
 
 	return &TradingSignal{
 		ID:        orderID,
-		Symbol:    marketData.Symbol,
-		Action:    ActionBuy,
-		Amount:    order.Amount,
-		Price:     order.Price,
+		Symbol:    symbol,
+		Action:    action,
+		Amount:    quantity,
+		Price:     price,
 		OrderType: OrderTypeLimit,
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Strategy:  "Grid",
 		Metadata: map[string]interface{}{
-			"grid_level":   level.ID,
-			"grid_price":   level.Price.String(),
+			"grid_level":   level.Index,
+			"grid_price":   price.String(),
 			"order_amount": gs.config.OrderAmount.String(),
 		},
 	}
 }
 
-// createSellOrder creates a sell order for a grid level
-func (gs *GridStrategy) createSellOrder(level *GridLevel, marketData *MarketData) *TradingSignal {
-	orderID := fmt.Sprintf("grid_sell_%d_%d", level.ID, time.Now().UnixNano())
-
-	order := &GridOrder{
-		ID:        orderID,
-		Level:     level.ID,
-		Symbol:    marketData.Symbol,
-		Side:      OrderSideSell,
-		Amount:    gs.config.OrderAmount.Div(level.Price), // Calculate token amount
-		Price:     level.Price,
-		Status:    OrderStatusPending,
-		Timestamp: time.Now(),
+// OrderFilled moves the level of a filled order on: a buy fill holds the quantity for the
+// sell one level up, and a sell fill completes the round trip and re-arms the buy
+func (gs *GridStrategy) OrderFilled(signalID string, quantity, price decimal.Decimal) {
+	gs.mu.Lock()
+	notify := func() {}
+	defer func() {
+		gs.mu.Unlock()
+		notify()
+	}()
+
+	level, exists := gs.orders[signalID]
+	if !exists {
+		return
 	}
+	delete(gs.orders, signalID)
+	level.OrderID = ""
+	level.UpdatedAt = time.Now()
+	gs.fills++
+	gs.filled = level.UpdatedAt
+
+	switch level.State {
+	case GridLevelBuyPending:
+		level.Quantity = quantity
+		level.EntryPrice = price
+		level.State = GridLevelHolding
+	case GridLevelSellPending:
+		level.Profit = level.Profit.Add(price.Sub(level.EntryPrice).Mul(quantity))
+		level.Quantity = level.Quantity.Sub(quantity)
+		if level.Quantity.IsPositive() {
+			level.State = GridLevelHolding
+			break
+		}
+		level.Quantity = decimal.Zero
+		level.EntryPrice = decimal.Zero
+		level.RoundTrips++
+		level.State = GridLevelBuyOpen
+	}
+	notify = gs.changed(gs.grids[level.symbol])
+}
 
-	level.SellOrder = order
-	gs.activeOrders[orderID] = order
-
-	return &TradingSignal{
-		ID:        orderID,
-		Symbol:    marketData.Symbol,
-		Action:    ActionSell,
-		Amount:    order.Amount,
-		Price:     order.Price,
-		OrderType: OrderTypeLimit,
-		Timestamp: time.Now(),
-		Strategy:  "Grid",
-		Metadata: map[string]interface{}{
-			"grid_level":   level.ID,
-			"grid_price":   level.Price.String(),
-			"order_amount": gs.config.OrderAmount.String(),
-		},
+// OrderRejected returns the level of an order that was not filled to its previous state, so
+// the order is retried when the price next crosses the level
+func (gs *GridStrategy) OrderRejected(signalID string, reason string) {
+	gs.mu.Lock()
+	notify := func() {}
+	defer func() {
+		gs.mu.Unlock()
+		notify()
+	}()
+
+	level, exists := gs.orders[signalID]
+	if !exists {
+		return
 	}
+	delete(gs.orders, signalID)
+	level.OrderID = ""
+	level.State = level.previous
+	level.UpdatedAt = time.Now()
+	notify = gs.changed(gs.grids[level.symbol])
 }
 
-// hasInventoryAtLevel checks if we have inventory to sell at this level
-func (gs *GridStrategy) hasInventoryAtLevel(level *GridLevel) bool {
-	// Check if there was a previous buy order that was filled
-	return level.BuyOrder != nil && level.BuyOrder.Status == OrderStatusExecuted
+// OnChange sets a handler called with a trading pair's grid whenever its levels change, such
+// as one persisting the grid
+func (gs *GridStrategy) OnChange(handler func(*GridSnapshot)) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.onChange = handler
 }
 
-// processFilledOrders processes orders that have been filled
-func (gs *GridStrategy) processFilledOrders(ctx context.Context, currentPrice decimal.Decimal) {
-	for orderID, order := range gs.activeOrders {
-		if order.Status == OrderStatusExecuted {
-			gs.handleFilledOrder(ctx, order)
-			delete(gs.activeOrders, orderID)
-		}
+// changed returns the call of the change handler with the grid's snapshot, to be made once
+// gs.mu is released. The caller must hold gs.mu.
+func (gs *GridStrategy) changed(g *grid) func() {
+	handler := gs.onChange
+	if handler == nil {
+		return func() {}
 	}
+	snapshot := g.snapshot()
+	return func() { handler(snapshot) }
 }
 
-// handleFilledOrder handles a filled order
-func (gs *GridStrategy) handleFilledOrder(ctx context.Context, order *GridOrder) {
-	level := gs.gridLevels[order.Level]
-
-	if order.Side == OrderSideBuy {
-		level.BuyOrder = order
-		level.Filled = true
-
-		gs.logger.Info(ctx, "Grid buy order filled", map[string]interface{}{
-			"order_id": order.ID,
-			"level":    order.Level,
-			"price":    order.Price.String(),
-			"amount":   order.Amount.String(),
-		})
-	} else {
-		level.SellOrder = order
-		level.Filled = false // Reset for next cycle
-		level.BuyOrder = nil // Clear buy order
-
-		gs.logger.Info(ctx, "Grid sell order filled", map[string]interface{}{
-			"order_id": order.ID,
-			"level":    order.Level,
-			"price":    order.Price.String(),
-			"amount":   order.Amount.String(),
-		})
+// Restore rebuilds the grids of the trading pairs from their saved snapshots, so a restarted
+// bot resumes its grid: levels holding inventory wait to sell instead of buying again. A level
+// saved with its order pending stays pending, since the order may have been placed before the
+// restart; it trades again once the order's outcome is reported or the strategy is reset.
+func (gs *GridStrategy) Restore(snapshots []*GridSnapshot) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, snapshot := range snapshots {
+		g := &grid{symbol: snapshot.Symbol, lower: snapshot.LowerPrice, upper: snapshot.UpperPrice, lastPrice: snapshot.LastPrice}
+		for _, saved := range snapshot.Levels {
+			level := saved
+			level.symbol = snapshot.Symbol
+			switch level.State {
+			case GridLevelBuyPending:
+				level.previous = GridLevelBuyOpen
+			case GridLevelSellPending:
+				level.previous = GridLevelHolding
+			}
+			if level.OrderID != "" {
+				gs.orders[level.OrderID] = &level
+			}
+			g.levels = append(g.levels, &level)
+		}
+		gs.grids[snapshot.Symbol] = g
 	}
 }
 
-// UpdateOrderStatus updates the status of an order
-func (gs *GridStrategy) UpdateOrderStatus(orderID string, status OrderStatus) error {
-	order, exists := gs.activeOrders[orderID]
-	if !exists {
-		return fmt.Errorf("order not found: %s", orderID)
+// snapshot returns a copy of the grid's levels with its metrics
+func (g *grid) snapshot() *GridSnapshot {
+	snapshot := &GridSnapshot{
+		Symbol:     g.symbol,
+		LowerPrice: g.lower,
+		UpperPrice: g.upper,
+		LastPrice:  g.lastPrice,
+		Levels:     make([]GridLevel, len(g.levels)),
 	}
-
-	order.Status = status
-	if status == OrderStatusExecuted {
-		now := time.Now()
-		order.FilledAt = &now
+	for i, level := range g.levels {
+		snapshot.Levels[i] = *level
+		snapshot.RoundTrips += level.RoundTrips
+		snapshot.GridProfit = snapshot.GridProfit.Add(level.Profit)
+		snapshot.Inventory = snapshot.Inventory.Add(level.Quantity)
 	}
-
-	return nil
+	return snapshot
 }
 
-// GetPerformance returns performance metrics for the Grid strategy
-func (gs *GridStrategy) GetPerformance() *StrategyPerformance {
-	totalBuyOrders := 0
-	totalSellOrders := 0
-	totalProfit := decimal.Zero
-	activeGridLevels := 0
-
-	for _, level := range gs.gridLevels {
-		if level.IsActive {
-			activeGridLevels++
-		}
+// Grids returns the level map and metrics of every trading pair's grid, ordered by symbol
+func (gs *GridStrategy) Grids() []*GridSnapshot {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
 
-		if level.BuyOrder != nil && level.BuyOrder.Status == OrderStatusExecuted {
-			totalBuyOrders++
-		}
+	snapshots := make([]*GridSnapshot, 0, len(gs.grids))
+	for _, g := range gs.grids {
+		snapshots = append(snapshots, g.snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Symbol < snapshots[j].Symbol
+	})
+	return snapshots
+}
 
-		if level.SellOrder != nil && level.SellOrder.Status == OrderStatusExecuted {
-			totalSellOrders++
-			// Calculate profit from this sell order
-			if level.BuyOrder != nil {
-				profit := level.SellOrder.Price.Sub(level.BuyOrder.Price).Mul(level.SellOrder.Amount)
-				totalProfit = totalProfit.Add(profit)
-			}
+// GetPerformance returns performance metrics for the Grid strategy. The inventory held by
+// levels is valued at each pair's last price.
+func (gs *GridStrategy) GetPerformance() *StrategyPerformance {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	invested := decimal.Zero
+	value := decimal.Zero
+	tokens := decimal.Zero
+	profit := decimal.Zero
+	levels := 0
+	for _, g := range gs.grids {
+		for _, level := range g.levels {
+			invested = invested.Add(level.EntryPrice.Mul(level.Quantity))
+			value = value.Add(g.lastPrice.Mul(level.Quantity))
+			tokens = tokens.Add(level.Quantity)
+			profit = profit.Add(level.Profit)
+			levels++
 		}
 	}
 
-	// Use base price as current price estimate
-	currentPrice := gs.basePrice
-	if currentPrice.IsZero() {
-		currentPrice = decimal.NewFromFloat(1.0) // Default fallback
+	performance := &StrategyPerformance{
+		TotalInvested:  invested,
+		CurrentValue:   value,
+		UnrealizedPnL:  value.Sub(invested),
+		TotalTokens:    tokens,
+		ExecutionCount: gs.fills,
+		LastExecution:  gs.filled,
 	}
-
-	// Calculate total capital from grid configuration
-	totalCapital := gs.config.OrderAmount.Mul(decimal.NewFromInt(int64(gs.config.GridLevels)))
-
-	// Convert to StrategyPerformance
-	return &StrategyPerformance{
-		TotalInvested:  totalCapital,
-		CurrentValue:   totalCapital.Add(totalProfit),
-		UnrealizedPnL:  totalProfit,
-		ROI:            totalProfit.Div(totalCapital).Mul(decimal.NewFromInt(100)),
-		AveragePrice:   currentPrice,
-		TotalTokens:    decimal.NewFromInt(int64(totalBuyOrders + totalSellOrders)),
-		ExecutionCount: totalBuyOrders + totalSellOrders,
-		LastExecution:  time.Now(),
+	if tokens.IsPositive() {
+		performance.AveragePrice = invested.Div(tokens)
 	}
-}
-
-// GridPerformance represents performance metrics for Grid strategy
-type GridPerformance struct {
-	ActiveLevels    int             `json:"active_levels"`
-	TotalBuyOrders  int             `json:"total_buy_orders"`
-	TotalSellOrders int             `json:"total_sell_orders"`
-	TotalProfit     decimal.Decimal `json:"total_profit"`
-	CurrentPrice    decimal.Decimal `json:"current_price"`
-	BasePrice       decimal.Decimal `json:"base_price"`
-	GridRange       string          `json:"grid_range"`
+	if capital := gs.config.OrderAmount.Mul(decimal.NewFromInt(int64(levels))); capital.IsPositive() {
+		performance.ROI = profit.Add(performance.UnrealizedPnL).Div(capital).Mul(decimal.NewFromInt(100))
+	}
+	return performance
 }
 
 // Reset resets the Grid strategy state
 func (gs *GridStrategy) Reset() {
-	gs.gridLevels = make([]*GridLevel, 0)
-	gs.activeOrders = make(map[string]*GridOrder)
-	gs.basePrice = decimal.Zero
-	gs.isInitialized = false
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	gs.grids = make(map[string]*grid)
+	gs.orders = make(map[string]*GridLevel)
+	gs.fills = 0
+	gs.filled = time.Time{}
 }
 
 // GetConfig returns the strategy configuration
@@ -377,16 +433,18 @@ func (gs *GridStrategy) Validate() error {
 		return fmt.Errorf("grid levels must be at least 3")
 	}
 
-	if gs.config.GridSpacing.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("grid spacing must be positive")
-	}
-
-	if gs.config.UpperBound.LessThanOrEqual(decimal.NewFromFloat(1.0)) {
-		return fmt.Errorf("upper bound must be greater than 1.0")
-	}
+	if gs.config.UpperPrice.IsPositive() || gs.config.LowerPrice.IsPositive() {
+		if !gs.config.LowerPrice.IsPositive() || !gs.config.LowerPrice.LessThan(gs.config.UpperPrice) {
+			return fmt.Errorf("lower price must be positive and below the upper price")
+		}
+	} else {
+		if gs.config.UpperBound.LessThanOrEqual(decimal.NewFromFloat(1.0)) {
+			return fmt.Errorf("upper bound must be greater than 1.0")
+		}
 
-	if gs.config.LowerBound.GreaterThanOrEqual(decimal.NewFromFloat(1.0)) {
-		return fmt.Errorf("lower bound must be less than 1.0")
+		if !gs.config.LowerBound.IsPositive() || gs.config.LowerBound.GreaterThanOrEqual(decimal.NewFromFloat(1.0)) {
+			return fmt.Errorf("lower bound must be between 0 and 1.0")
+		}
 	}
 
 	if gs.config.OrderAmount.LessThanOrEqual(decimal.Zero) {
@@ -204,7 +204,7 @@ func (ms *MomentumStrategy) calculateVolumeRatio() decimal.Decimal {
 // generateSignal generates trading signals based on momentum indicators
 func (ms *MomentumStrategy) generateSignal(ctx context.Context, marketData *MarketData, rsi, momentum, volumeRatio decimal.Decimal) *MomentumSignal {
 	// Don't generate signals too frequently
-	if time.Since(ms.lastSignal) < time.Minute*5 {
+	if marketData.at().Sub(ms.lastSignal) < time.Minute*5 {
 		return nil
 	}
 
@@ -336,7 +336,7 @@ func (ms *MomentumStrategy) createBuySignal(ctx context.Context, marketData *Mar
 		TakeProfit: marketData.Price.Mul(decimal.NewFromFloat(1).Add(ms.config.TakeProfit)),
 	}
 
-	ms.lastSignal = time.Now()
+	ms.lastSignal = marketData.at()
 
 	ms.logger.Info(ctx, "Momentum buy signal generated", map[string]interface{}{
 		"symbol":       signal.Symbol,
@@ -379,7 +379,7 @@ func (ms *MomentumStrategy) createSellSignal(ctx context.Context, marketData *Ma
 
 	// Close position
 	ms.currentPosition = nil
-	ms.lastSignal = time.Now()
+	ms.lastSignal = marketData.at()
 
 	ms.logger.Info(ctx, "Momentum sell signal generated", map[string]interface{}{
 		"symbol":       signal.Symbol,
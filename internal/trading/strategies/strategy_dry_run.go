@@ -0,0 +1,201 @@
+package strategies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrDryRunUnsupported is returned for strategy types without an implementation to replay
+var ErrDryRunUnsupported = errors.New("dry run is not supported for this strategy")
+
+// DryRunResult counts the signals a strategy would have generated over past market data
+type DryRunResult struct {
+	Strategy    StrategyType `json:"strategy"`
+	Symbol      string       `json:"symbol"`
+	From        time.Time    `json:"from"`
+	To          time.Time    `json:"to"`
+	Candles     int          `json:"candles"`
+	Signals     int          `json:"signals"`
+	BuySignals  int          `json:"buy_signals"`
+	SellSignals int          `json:"sell_signals"`
+}
+
+// Signals extracts the trading signals from a strategy execution result
+func Signals(result interface{}) []*TradingSignal {
+	switch value := result.(type) {
+	case *TradingSignal:
+		if value != nil {
+			return []*TradingSignal{value}
+		}
+	case []*TradingSignal:
+		return value
+	case *MomentumSignal:
+		if value != nil && value.TradingSignal != nil {
+			return []*TradingSignal{value.TradingSignal}
+		}
+	}
+	return nil
+}
+
+// DryRun validates params and replays a fresh strategy configured with them over bars of
+// symbol, oldest first, counting the signals it generates. No orders are placed; strategies
+// that track their orders are told each signal filled at its price.
+func (sm *StrategyManager) DryRun(ctx context.Context, strategyType StrategyType, params map[string]interface{}, symbol string, bars []*MarketData) (*DryRunResult, error) {
+	if err := sm.ValidateParams(strategyType, params); err != nil {
+		return nil, err
+	}
+	strategy, err := sm.NewStrategy(strategyType, params, []string{symbol})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DryRunResult{Strategy: strategyType, Symbol: symbol, Candles: len(bars)}
+	if len(bars) > 0 {
+		result.From, result.To = bars[0].Timestamp, bars[len(bars)-1].Timestamp
+	}
+	for _, bar := range bars {
+		output, err := strategy.Execute(ctx, bar)
+		if err != nil {
+			return nil, fmt.Errorf("strategy failed at %s: %w", bar.Timestamp.Format(time.RFC3339), err)
+		}
+		for _, signal := range Signals(output) {
+			switch signal.Action {
+			case ActionBuy:
+				result.BuySignals++
+			case ActionSell:
+				result.SellSignals++
+			default:
+				continue
+			}
+			result.Signals++
+			if observer, ok := strategy.(OrderObserver); ok {
+				observer.OrderFilled(signal.ID, signal.Amount, signal.Price)
+			}
+		}
+	}
+	return result, nil
+}
+
+// NewStrategy builds a strategy trading pairs from validated params, with the default
+// configuration filling the optional parameters left out. Types without an implementation
+// return ErrDryRunUnsupported.
+func (sm *StrategyManager) NewStrategy(strategyType StrategyType, params map[string]interface{}, pairs []string) (TradingStrategy, error) {
+	switch strategyType {
+	case StrategyTypeDCA:
+		config := defaultDCAConfig()
+		config.TradingPairs = pairs
+		config.InvestmentAmount = decimalParam(params, "investment_amount", config.InvestmentAmount)
+		config.Interval = durationParam(params, "interval", config.Interval)
+		if schedule, ok := params["schedule"].(string); ok {
+			config.Interval = dcaSchedules[schedule]
+		}
+		config.MaxDeviation = decimalParam(params, "max_deviation", config.MaxDeviation)
+		config.AccumulationPeriod = durationParam(params, "accumulation_period", config.AccumulationPeriod)
+		config.MAPeriod = intParam(params, "ma_period", config.MAPeriod)
+		config.MinMultiplier = decimalParam(params, "min_multiplier", config.MinMultiplier)
+		config.MaxMultiplier = decimalParam(params, "max_multiplier", config.MaxMultiplier)
+		return NewDCAStrategy(sm.logger, config), nil
+	case StrategyTypeGrid:
+		config := defaultGridConfig()
+		config.TradingPairs = pairs
+		config.GridLevels = intParam(params, "grid_levels", config.GridLevels)
+		config.GridSpacing = decimalParam(params, "grid_spacing", config.GridSpacing)
+		config.UpperBound = decimalParam(params, "upper_bound", config.UpperBound)
+		config.LowerBound = decimalParam(params, "lower_bound", config.LowerBound)
+		config.UpperPrice = decimalParam(params, "upper_price", config.UpperPrice)
+		config.LowerPrice = decimalParam(params, "lower_price", config.LowerPrice)
+		config.OrderAmount = decimalParam(params, "order_amount", config.OrderAmount)
+		return NewGridStrategy(sm.logger, config), nil
+	case StrategyTypeMomentum:
+		config := defaultMomentumConfig()
+		config.TradingPairs = pairs
+		config.MomentumPeriod = intParam(params, "momentum_period", config.MomentumPeriod)
+		config.RSIThresholdBuy = decimalParam(params, "rsi_threshold_buy", config.RSIThresholdBuy)
+		config.RSIThresholdSell = decimalParam(params, "rsi_threshold_sell", config.RSIThresholdSell)
+		config.VolumeThreshold = decimalParam(params, "volume_threshold", config.VolumeThreshold)
+		config.BreakoutThreshold = decimalParam(params, "breakout_threshold", config.BreakoutThreshold)
+		config.PositionSize = decimalParam(params, "position_size", config.PositionSize)
+		config.StopLoss = decimalParam(params, "stop_loss", config.StopLoss)
+		config.TakeProfit = decimalParam(params, "take_profit", config.TakeProfit)
+		return NewMomentumStrategy(sm.logger, config), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrDryRunUnsupported, strategyType)
+	}
+}
+
+// defaultDCAConfig returns the configuration of the default DCA strategy
+func defaultDCAConfig() *DCAConfig {
+	return &DCAConfig{
+		InvestmentAmount:   decimal.NewFromFloat(100),
+		Interval:           time.Hour,
+		MaxDeviation:       decimal.NewFromFloat(0.05),
+		AccumulationPeriod: time.Hour * 24,
+		MinMultiplier:      decimal.NewFromFloat(0.5),
+		MaxMultiplier:      decimal.NewFromFloat(2),
+		TradingPairs:       []string{"BTC/USDT", "ETH/USDT"},
+		Exchange:           "binance",
+	}
+}
+
+// defaultGridConfig returns the configuration of the default grid strategy
+func defaultGridConfig() *GridConfig {
+	return &GridConfig{
+		GridLevels:   20,
+		GridSpacing:  decimal.NewFromFloat(0.02),
+		UpperBound:   decimal.NewFromFloat(1.20),
+		LowerBound:   decimal.NewFromFloat(0.80),
+		OrderAmount:  decimal.NewFromFloat(50),
+		TradingPairs: []string{"BNB/USDT", "ADA/USDT"},
+		Exchange:     "binance",
+	}
+}
+
+// defaultMomentumConfig returns the configuration of the default momentum strategy
+func defaultMomentumConfig() *MomentumConfig {
+	return &MomentumConfig{
+		MomentumPeriod:    14,
+		RSIThresholdBuy:   decimal.NewFromFloat(30),
+		RSIThresholdSell:  decimal.NewFromFloat(70),
+		VolumeThreshold:   decimal.NewFromFloat(1.5),
+		BreakoutThreshold: decimal.NewFromFloat(0.03),
+		TradingPairs:      []string{"SOL/USDT", "AVAX/USDT"},
+		Exchange:          "coinbase",
+		PositionSize:      decimal.NewFromFloat(100),
+		StopLoss:          decimal.NewFromFloat(0.08),
+		TakeProfit:        decimal.NewFromFloat(0.15),
+	}
+}
+
+// decimalParam returns a validated numeric parameter, or fallback when it is not set
+func decimalParam(params map[string]interface{}, name string, fallback decimal.Decimal) decimal.Decimal {
+	if value, ok := paramNumber(params[name]); ok {
+		return decimal.NewFromFloat(value)
+	}
+	return fallback
+}
+
+// intParam returns a validated integer parameter, or fallback when it is not set
+func intParam(params map[string]interface{}, name string, fallback int) int {
+	if value, ok := paramNumber(params[name]); ok {
+		return int(value)
+	}
+	return fallback
+}
+
+// durationParam returns a validated duration parameter given as a string or seconds, or
+// fallback when it is not set
+func durationParam(params map[string]interface{}, name string, fallback time.Duration) time.Duration {
+	if text, ok := params[name].(string); ok {
+		if value, err := time.ParseDuration(text); err == nil {
+			return value
+		}
+	}
+	if value, ok := paramNumber(params[name]); ok {
+		return time.Duration(value * float64(time.Second))
+	}
+	return fallback
+}
@@ -27,6 +27,13 @@ type TradingStrategy interface {
 	GetPerformance() *StrategyPerformance
 }
 
+// OrderObserver is implemented by strategies that track the orders placed for their signals.
+// The engine reports the outcome of every signal it acts on by the signal's ID.
+type OrderObserver interface {
+	OrderFilled(signalID string, quantity, price decimal.Decimal)
+	OrderRejected(signalID string, reason string)
+}
+
 // StrategyType represents the type of trading strategy
 type StrategyType string
 
@@ -177,47 +184,21 @@ func (sm *StrategyManager) ResetAllStrategies() {
 // CreateDefaultStrategies creates and registers all 7 default trading strategies
 func (sm *StrategyManager) CreateDefaultStrategies() error {
 	// 1. DCA Strategy
-	dcaConfig := &DCAConfig{
-		InvestmentAmount:   decimal.NewFromFloat(100),
-		Interval:           time.Hour,
-		MaxDeviation:       decimal.NewFromFloat(0.05),
-		AccumulationPeriod: time.Hour * 24,
-		TradingPairs:       []string{"BTC/USDT", "ETH/USDT"},
-		Exchange:           "binance",
-	}
+	dcaConfig := defaultDCAConfig()
 	dcaStrategy := NewDCAStrategy(sm.logger, dcaConfig)
 	if err := sm.RegisterStrategy("dca_bot", dcaStrategy); err != nil {
 		return fmt.Errorf("failed to register DCA strategy: %w", err)
 	}
 
 	// 2. Grid Strategy
-	gridConfig := &GridConfig{
-		GridLevels:   20,
-		GridSpacing:  decimal.NewFromFloat(0.02),
-		UpperBound:   decimal.NewFromFloat(1.20),
-		LowerBound:   decimal.NewFromFloat(0.80),
-		OrderAmount:  decimal.NewFromFloat(50),
-		TradingPairs: []string{"BNB/USDT", "ADA/USDT"},
-		Exchange:     "binance",
-	}
+	gridConfig := defaultGridConfig()
 	gridStrategy := NewGridStrategy(sm.logger, gridConfig)
 	if err := sm.RegisterStrategy("grid_bot", gridStrategy); err != nil {
 		return fmt.Errorf("failed to register Grid strategy: %w", err)
 	}
 
 	// 3. Momentum Strategy
-	momentumConfig := &MomentumConfig{
-		MomentumPeriod:     14,
-		RSIThresholdBuy:    decimal.NewFromFloat(30),
-		RSIThresholdSell:   decimal.NewFromFloat(70),
-		VolumeThreshold:    decimal.NewFromFloat(1.5),
-		BreakoutThreshold:  decimal.NewFromFloat(0.03),
-		TradingPairs:       []string{"SOL/USDT", "AVAX/USDT"},
-		Exchange:           "coinbase",
-		PositionSize:       decimal.NewFromFloat(100),
-		StopLoss:           decimal.NewFromFloat(0.08),
-		TakeProfit:         decimal.NewFromFloat(0.15),
-	}
+	momentumConfig := defaultMomentumConfig()
 	momentumStrategy := NewMomentumStrategy(sm.logger, momentumConfig)
 	if err := sm.RegisterStrategy("momentum_bot", momentumStrategy); err != nil {
 		return fmt.Errorf("failed to register Momentum strategy: %w", err)
@@ -0,0 +1,412 @@
+package strategies
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/validation"
+)
+
+// ErrInvalidStrategyParams is wrapped by ParamErrors
+var ErrInvalidStrategyParams = errors.New("invalid strategy parameters")
+
+// FieldError describes why one strategy parameter is invalid. Constraint is one of the
+// validation.Constraint values.
+type FieldError struct {
+	Field      string `json:"field"`
+	Constraint string `json:"constraint,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ParamErrors lists every invalid parameter of a strategy configuration
+type ParamErrors []FieldError
+
+func (e ParamErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, field := range e {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidStrategyParams, strings.Join(messages, "; "))
+}
+
+func (e ParamErrors) Unwrap() error {
+	return ErrInvalidStrategyParams
+}
+
+// Kinds of strategy parameters. Durations are Go duration strings such as "1h" or numbers of
+// seconds.
+const (
+	ParamNumber   = "number"
+	ParamInteger  = "integer"
+	ParamDuration = "duration"
+	ParamString   = "string"
+)
+
+// ParamSpec constrains one strategy parameter. Bounds apply to numbers, integers and
+// durations in seconds; Options lists the allowed strings.
+type ParamSpec struct {
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	Required     bool     `json:"required"`
+	Min          *float64 `json:"min,omitempty"`
+	Max          *float64 `json:"max,omitempty"`
+	ExclusiveMin bool     `json:"exclusive_min,omitempty"`
+	ExclusiveMax bool     `json:"exclusive_max,omitempty"`
+	Options      []string `json:"options,omitempty"`
+}
+
+// paramConstraint checks a relation between parameters, given the numeric values of those
+// that are set and valid. Valid strings are present with a zero value.
+type paramConstraint func(values map[string]float64) *FieldError
+
+// StrategySchema is the parameter schema of a strategy type
+type StrategySchema struct {
+	Type        StrategyType `json:"type"`
+	Params      []ParamSpec  `json:"params"`
+	constraints []paramConstraint
+}
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+// positive is a number greater than zero
+func positive(name string, required bool) ParamSpec {
+	return ParamSpec{Name: name, Kind: ParamNumber, Required: required, Min: bound(0), ExclusiveMin: true}
+}
+
+// fraction is a number between zero and one, excluding zero when positive is set
+func fraction(name string, required, positive bool) ParamSpec {
+	return ParamSpec{Name: name, Kind: ParamNumber, Required: required, Min: bound(0), ExclusiveMin: positive, Max: bound(1), ExclusiveMax: true}
+}
+
+// percentile is an RSI level between 0 and 100
+func percentile(name string, required bool) ParamSpec {
+	return ParamSpec{Name: name, Kind: ParamNumber, Required: required, Min: bound(0), Max: bound(100)}
+}
+
+func period(name string, required bool, min, max float64) ParamSpec {
+	return ParamSpec{Name: name, Kind: ParamInteger, Required: required, Min: bound(min), Max: bound(max)}
+}
+
+func duration(name string, required bool) ParamSpec {
+	return ParamSpec{Name: name, Kind: ParamDuration, Required: required, Min: bound(0), ExclusiveMin: true}
+}
+
+// less requires the lower parameter to be below the higher one when both are set
+func less(lower, higher, message string) paramConstraint {
+	return func(values map[string]float64) *FieldError {
+		low, lowSet := values[lower]
+		high, highSet := values[higher]
+		if lowSet && highSet && low >= high {
+			return &FieldError{Field: higher, Constraint: validation.ConstraintRange, Message: fmt.Sprintf("must be greater than %s: %s", lower, message)}
+		}
+		return nil
+	}
+}
+
+// dcaSchedule requires the interval between DCA buys as either a schedule or an interval
+func dcaSchedule(values map[string]float64) *FieldError {
+	_, schedule := values["schedule"]
+	_, interval := values["interval"]
+	switch {
+	case schedule && interval:
+		return &FieldError{Field: "interval", Constraint: validation.ConstraintInvalid, Message: "cannot be combined with schedule"}
+	case !schedule && !interval:
+		return &FieldError{Field: "interval", Constraint: validation.ConstraintRequired, Message: "is required unless schedule is set"}
+	}
+	return nil
+}
+
+// gridRange requires a grid's range as either both bounds relative to the first price or
+// both absolute prices
+func gridRange(values map[string]float64) *FieldError {
+	_, upperPrice := values["upper_price"]
+	_, lowerPrice := values["lower_price"]
+	_, upperBound := values["upper_bound"]
+	_, lowerBound := values["lower_bound"]
+	switch {
+	case upperPrice || lowerPrice:
+		if !upperPrice {
+			return &FieldError{Field: "upper_price", Constraint: validation.ConstraintRequired, Message: "is required with lower_price"}
+		}
+		if !lowerPrice {
+			return &FieldError{Field: "lower_price", Constraint: validation.ConstraintRequired, Message: "is required with upper_price"}
+		}
+		if upperBound || lowerBound {
+			return &FieldError{Field: "upper_bound", Constraint: validation.ConstraintInvalid, Message: "cannot be combined with upper_price and lower_price"}
+		}
+	case !upperBound:
+		return &FieldError{Field: "upper_bound", Constraint: validation.ConstraintRequired, Message: "is required unless upper_price and lower_price are set"}
+	case !lowerBound:
+		return &FieldError{Field: "lower_bound", Constraint: validation.ConstraintRequired, Message: "is required unless upper_price and lower_price are set"}
+	}
+	return nil
+}
+
+// riskParams are the position sizing and exit parameters shared by directional strategies
+func riskParams() []ParamSpec {
+	return []ParamSpec{
+		positive("position_size", false),
+		fraction("stop_loss", false, true),
+		positive("take_profit", false),
+	}
+}
+
+// strategySchemas holds the parameter schema of every strategy type
+var strategySchemas = map[StrategyType]*StrategySchema{
+	StrategyTypeDCA: {
+		Params: []ParamSpec{
+			positive("investment_amount", true),
+			duration("interval", false),
+			{Name: "schedule", Kind: ParamString, Options: []string{"hourly", "daily", "weekly"}},
+			fraction("max_deviation", false, false),
+			duration("accumulation_period", false),
+			period("ma_period", false, 2, 500),
+			{Name: "min_multiplier", Kind: ParamNumber, Min: bound(0), ExclusiveMin: true, Max: bound(1)},
+			{Name: "max_multiplier", Kind: ParamNumber, Min: bound(1), Max: bound(10)},
+		},
+		constraints: []paramConstraint{
+			dcaSchedule,
+			less("interval", "accumulation_period", "the accumulation period must span several intervals"),
+		},
+	},
+	StrategyTypeGrid: {
+		Params: []ParamSpec{
+			period("grid_levels", true, 3, 200),
+			fraction("grid_spacing", false, true),
+			{Name: "upper_bound", Kind: ParamNumber, Min: bound(1), ExclusiveMin: true, Max: bound(10)},
+			fraction("lower_bound", false, true),
+			positive("upper_price", false),
+			positive("lower_price", false),
+			positive("order_amount", true),
+		},
+		constraints: []paramConstraint{
+			gridRange,
+			less("lower_price", "upper_price", "the grid needs a price range"),
+			func(values map[string]float64) *FieldError {
+				spacing, spacingSet := values["grid_spacing"]
+				upper, upperSet := values["upper_bound"]
+				lower, lowerSet := values["lower_bound"]
+				if spacingSet && upperSet && lowerSet && spacing >= upper-lower {
+					return &FieldError{Field: "grid_spacing", Constraint: validation.ConstraintRange, Message: "must be smaller than the range between lower_bound and upper_bound"}
+				}
+				return nil
+			},
+		},
+	},
+	StrategyTypeMomentum: {
+		Params: append([]ParamSpec{
+			period("momentum_period", true, 5, 200),
+			percentile("rsi_threshold_buy", true),
+			percentile("rsi_threshold_sell", true),
+			positive("volume_threshold", false),
+			fraction("breakout_threshold", false, true),
+		}, riskParams()...),
+		constraints: []paramConstraint{
+			less("rsi_threshold_buy", "rsi_threshold_sell", "buying must happen at a lower RSI than selling"),
+			less("stop_loss", "take_profit", "a take-profit below the stop-loss loses more on losers than it makes on winners"),
+		},
+	},
+	StrategyTypeMeanReversion: {
+		Params: append([]ParamSpec{
+			period("lookback_period", true, 2, 500),
+			positive("std_dev_multiplier", true),
+			percentile("rsi_oversold", false),
+			percentile("rsi_overbought", false),
+			period("bollinger_period", false, 2, 500),
+		}, riskParams()...),
+		constraints: []paramConstraint{
+			less("rsi_oversold", "rsi_overbought", "the oversold level must be below the overbought level"),
+			less("stop_loss", "take_profit", "a take-profit below the stop-loss loses more on losers than it makes on winners"),
+		},
+	},
+	StrategyTypeArbitrage: {
+		Params: []ParamSpec{
+			fraction("min_profit_threshold", true, true),
+			duration("max_execution_time", false),
+			fraction("slippage_tolerance", false, false),
+			fraction("balance_threshold", false, false),
+			positive("position_size", false),
+		},
+		constraints: []paramConstraint{
+			less("slippage_tolerance", "min_profit_threshold", "slippage would consume the minimum profit"),
+		},
+	},
+	StrategyTypeScalping: {
+		Params: []ParamSpec{
+			{Name: "timeframe", Kind: ParamString, Required: true, Options: []string{"1m", "3m", "5m", "15m"}},
+			fraction("profit_target", true, true),
+			duration("max_holding_time", false),
+			positive("volume_spike_threshold", false),
+			fraction("spread_threshold", false, false),
+			positive("position_size", false),
+			fraction("stop_loss", false, true),
+		},
+		constraints: []paramConstraint{
+			less("spread_threshold", "profit_target", "the spread would consume the profit target"),
+		},
+	},
+	StrategyTypeSwing: {
+		Params: append([]ParamSpec{
+			{Name: "timeframe", Kind: ParamString, Required: true, Options: []string{"1h", "2h", "4h", "6h", "12h", "1d"}},
+			period("ma_fast", true, 1, 200),
+			period("ma_slow", true, 2, 500),
+			period("signal_line", false, 1, 100),
+			period("rsi_period", false, 2, 100),
+		}, riskParams()...),
+		constraints: []paramConstraint{
+			less("ma_fast", "ma_slow", "the fast moving average must use fewer periods"),
+			less("stop_loss", "take_profit", "a take-profit below the stop-loss loses more on losers than it makes on winners"),
+		},
+	},
+}
+
+func init() {
+	for strategyType, schema := range strategySchemas {
+		schema.Type = strategyType
+	}
+}
+
+// Schema returns the parameter schema of a strategy type
+func (sm *StrategyManager) Schema(strategyType StrategyType) (*StrategySchema, error) {
+	schema, ok := strategySchemas[strategyType]
+	if !ok {
+		return nil, ParamErrors{{Field: "strategy", Constraint: validation.ConstraintEnum, Message: fmt.Sprintf("unknown strategy type %q", strategyType)}}
+	}
+	return schema, nil
+}
+
+// ValidateParams checks strategy parameters against the schema of their strategy type and
+// returns ParamErrors listing every invalid or unknown parameter
+func (sm *StrategyManager) ValidateParams(strategyType StrategyType, params map[string]interface{}) error {
+	schema, err := sm.Schema(strategyType)
+	if err != nil {
+		return err
+	}
+
+	var fieldErrors ParamErrors
+	values := make(map[string]float64)
+	known := make(map[string]bool, len(schema.Params))
+	for _, spec := range schema.Params {
+		known[spec.Name] = true
+		raw, set := params[spec.Name]
+		if !set || raw == nil {
+			if spec.Required {
+				fieldErrors = append(fieldErrors, FieldError{Field: spec.Name, Constraint: validation.ConstraintRequired, Message: "is required"})
+			}
+			continue
+		}
+		value, fieldErr := spec.check(raw)
+		if fieldErr != nil {
+			fieldErrors = append(fieldErrors, *fieldErr)
+			continue
+		}
+		values[spec.Name] = value
+	}
+
+	unknown := make([]string, 0)
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fieldErrors = append(fieldErrors, FieldError{Field: name, Constraint: validation.ConstraintUnknown, Message: fmt.Sprintf("is not a parameter of the %s strategy", strategyType)})
+	}
+
+	for _, constraint := range schema.constraints {
+		if fieldErr := constraint(values); fieldErr != nil {
+			fieldErrors = append(fieldErrors, *fieldErr)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// check converts a raw parameter value, returning its numeric value (seconds for durations)
+func (spec ParamSpec) check(raw interface{}) (float64, *FieldError) {
+	invalid := func(constraint, format string, args ...interface{}) (float64, *FieldError) {
+		return 0, &FieldError{Field: spec.Name, Constraint: constraint, Message: fmt.Sprintf(format, args...)}
+	}
+
+	var value float64
+	switch spec.Kind {
+	case ParamString:
+		text, ok := raw.(string)
+		if !ok {
+			return invalid(validation.ConstraintType, "must be a string")
+		}
+		if len(spec.Options) > 0 && !containsString(spec.Options, text) {
+			return invalid(validation.ConstraintEnum, "must be one of %s", strings.Join(spec.Options, ", "))
+		}
+		return 0, nil
+	case ParamDuration:
+		if text, ok := raw.(string); ok {
+			parsed, err := time.ParseDuration(text)
+			if err != nil {
+				return invalid(validation.ConstraintType, "must be a duration such as \"1h\" or a number of seconds")
+			}
+			value = parsed.Seconds()
+		} else if number, ok := paramNumber(raw); ok {
+			value = number
+		} else {
+			return invalid(validation.ConstraintType, "must be a duration such as \"1h\" or a number of seconds")
+		}
+	default:
+		number, ok := paramNumber(raw)
+		if !ok {
+			return invalid(validation.ConstraintType, "must be a number")
+		}
+		if spec.Kind == ParamInteger && number != float64(int64(number)) {
+			return invalid(validation.ConstraintType, "must be a whole number")
+		}
+		value = number
+	}
+
+	if spec.Min != nil && (value < *spec.Min || spec.ExclusiveMin && value == *spec.Min) {
+		if spec.ExclusiveMin {
+			return invalid(validation.ConstraintRange, "must be greater than %g", *spec.Min)
+		}
+		return invalid(validation.ConstraintRange, "must be at least %g", *spec.Min)
+	}
+	if spec.Max != nil && (value > *spec.Max || spec.ExclusiveMax && value == *spec.Max) {
+		if spec.ExclusiveMax {
+			return invalid(validation.ConstraintRange, "must be less than %g", *spec.Max)
+		}
+		return invalid(validation.ConstraintRange, "must be at most %g", *spec.Max)
+	}
+	return value, nil
+}
+
+// paramNumber converts the numeric types JSON and YAML decode to
+func paramNumber(raw interface{}) (float64, bool) {
+	switch value := raw.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
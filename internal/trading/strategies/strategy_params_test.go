@@ -0,0 +1,141 @@
+package strategies
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateParams(t *testing.T) {
+	sm := NewStrategyManager(observability.NewLogger(config.ObservabilityConfig{}))
+
+	grid := func(overrides map[string]interface{}) map[string]interface{} {
+		params := map[string]interface{}{
+			"grid_levels":  10,
+			"grid_spacing": 0.01,
+			"upper_bound":  1.1,
+			"lower_bound":  0.9,
+			"order_amount": 100.0,
+		}
+		for name, value := range overrides {
+			if value == nil {
+				delete(params, name)
+				continue
+			}
+			params[name] = value
+		}
+		return params
+	}
+	momentum := func(overrides map[string]interface{}) map[string]interface{} {
+		params := map[string]interface{}{
+			"momentum_period":    14,
+			"rsi_threshold_buy":  30.0,
+			"rsi_threshold_sell": 70.0,
+			"stop_loss":          0.05,
+			"take_profit":        0.1,
+		}
+		for name, value := range overrides {
+			if value == nil {
+				delete(params, name)
+				continue
+			}
+			params[name] = value
+		}
+		return params
+	}
+
+	tests := []struct {
+		name         string
+		strategyType StrategyType
+		params       map[string]interface{}
+		want         []FieldError // compared by field and constraint
+	}{
+		{"valid grid", StrategyTypeGrid, grid(nil), nil},
+		{"valid momentum", StrategyTypeMomentum, momentum(nil), nil},
+		{"valid absolute grid", StrategyTypeGrid, grid(map[string]interface{}{"upper_bound": nil, "lower_bound": nil, "upper_price": 110.0, "lower_price": 90.0}), nil},
+		{"negative grid spacing", StrategyTypeGrid, grid(map[string]interface{}{"grid_spacing": -0.01}),
+			[]FieldError{{Field: "grid_spacing", Constraint: validation.ConstraintRange}}},
+		{"zero grid spacing", StrategyTypeGrid, grid(map[string]interface{}{"grid_spacing": 0}),
+			[]FieldError{{Field: "grid_spacing", Constraint: validation.ConstraintRange}}},
+		{"grid spacing wider than the range", StrategyTypeGrid, grid(map[string]interface{}{"grid_spacing": 0.5}),
+			[]FieldError{{Field: "grid_spacing", Constraint: validation.ConstraintRange}}},
+		{"inverted price range", StrategyTypeGrid, grid(map[string]interface{}{"upper_bound": nil, "lower_bound": nil, "upper_price": 90.0, "lower_price": 110.0}),
+			[]FieldError{{Field: "upper_price", Constraint: validation.ConstraintRange}}},
+		{"take profit equal to stop loss", StrategyTypeMomentum, momentum(map[string]interface{}{"take_profit": 0.05}),
+			[]FieldError{{Field: "take_profit", Constraint: validation.ConstraintRange}}},
+		{"take profit below stop loss", StrategyTypeMomentum, momentum(map[string]interface{}{"take_profit": 0.02}),
+			[]FieldError{{Field: "take_profit", Constraint: validation.ConstraintRange}}},
+		{"missing required params", StrategyTypeMomentum, momentum(map[string]interface{}{"momentum_period": nil, "rsi_threshold_buy": nil}),
+			[]FieldError{
+				{Field: "momentum_period", Constraint: validation.ConstraintRequired},
+				{Field: "rsi_threshold_buy", Constraint: validation.ConstraintRequired},
+			}},
+		{"missing grid range", StrategyTypeGrid, grid(map[string]interface{}{"upper_bound": nil}),
+			[]FieldError{{Field: "upper_bound", Constraint: validation.ConstraintRequired}}},
+		{"period below range", StrategyTypeMomentum, momentum(map[string]interface{}{"momentum_period": 4}),
+			[]FieldError{{Field: "momentum_period", Constraint: validation.ConstraintRange}}},
+		{"period above range", StrategyTypeGrid, grid(map[string]interface{}{"grid_levels": 201}),
+			[]FieldError{{Field: "grid_levels", Constraint: validation.ConstraintRange}}},
+		{"fractional period", StrategyTypeMomentum, momentum(map[string]interface{}{"momentum_period": 14.5}),
+			[]FieldError{{Field: "momentum_period", Constraint: validation.ConstraintType}}},
+		{"wrong type", StrategyTypeGrid, grid(map[string]interface{}{"order_amount": "100"}),
+			[]FieldError{{Field: "order_amount", Constraint: validation.ConstraintType}}},
+		{"unknown param", StrategyTypeGrid, grid(map[string]interface{}{"leverage": 10}),
+			[]FieldError{{Field: "leverage", Constraint: validation.ConstraintUnknown}}},
+		{"every error is reported", StrategyTypeGrid, grid(map[string]interface{}{"grid_levels": 2, "order_amount": -1, "leverage": 10}),
+			[]FieldError{
+				{Field: "grid_levels", Constraint: validation.ConstraintRange},
+				{Field: "order_amount", Constraint: validation.ConstraintRange},
+				{Field: "leverage", Constraint: validation.ConstraintUnknown},
+			}},
+		{"unknown strategy", StrategyType("martingale"), map[string]interface{}{},
+			[]FieldError{{Field: "strategy", Constraint: validation.ConstraintEnum}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sm.ValidateParams(tt.strategyType, tt.params)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidStrategyParams))
+			var paramErrors ParamErrors
+			require.True(t, errors.As(err, &paramErrors))
+			got := make([]FieldError, len(paramErrors))
+			for i, fieldErr := range paramErrors {
+				assert.NotEmpty(t, fieldErr.Message)
+				got[i] = FieldError{Field: fieldErr.Field, Constraint: fieldErr.Constraint}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateParams_Durations(t *testing.T) {
+	sm := NewStrategyManager(observability.NewLogger(config.ObservabilityConfig{}))
+	dca := func(interval, accumulation interface{}) map[string]interface{} {
+		params := map[string]interface{}{"investment_amount": 100.0, "interval": interval}
+		if accumulation != nil {
+			params["accumulation_period"] = accumulation
+		}
+		return params
+	}
+
+	assert.NoError(t, sm.ValidateParams(StrategyTypeDCA, dca("1h", "24h")))
+	assert.NoError(t, sm.ValidateParams(StrategyTypeDCA, dca(3600, nil)), "durations may be given in seconds")
+	assert.Error(t, sm.ValidateParams(StrategyTypeDCA, dca("hourly", nil)))
+	assert.Error(t, sm.ValidateParams(StrategyTypeDCA, dca("-1h", nil)))
+	assert.Error(t, sm.ValidateParams(StrategyTypeDCA, dca("24h", "1h")), "the accumulation period must exceed the interval")
+
+	// The interval is required unless a schedule is set, but not both
+	assert.NoError(t, sm.ValidateParams(StrategyTypeDCA, map[string]interface{}{"investment_amount": 100.0, "schedule": "daily"}))
+	assert.Error(t, sm.ValidateParams(StrategyTypeDCA, map[string]interface{}{"investment_amount": 100.0}))
+	assert.Error(t, sm.ValidateParams(StrategyTypeDCA, map[string]interface{}{"investment_amount": 100.0, "schedule": "daily", "interval": "1h"}))
+}
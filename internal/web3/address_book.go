@@ -0,0 +1,245 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// How a transaction recipient was resolved
+const (
+	RecipientAddress     = "address"
+	RecipientENS         = "ens"
+	RecipientAddressBook = "address_book"
+)
+
+const maxAddressBookLabel = 64
+
+var (
+	ErrAddressBookEntryNotFound = errors.New("address book entry not found")
+	ErrAddressBookLabelExists   = errors.New("address book label already exists on this chain")
+	ErrInvalidAddressBookEntry  = errors.New("invalid address book entry")
+	ErrUnresolvedRecipient      = errors.New("recipient could not be resolved")
+	ErrENSUnavailable           = errors.New("ENS resolution is not available")
+)
+
+// AddressBookEntry is a labelled recipient address of a user. An entry is verified once a
+// transaction to its address has been confirmed.
+type AddressBookEntry struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Label      string     `json:"label"`
+	Address    string     `json:"address"`
+	ChainID    int        `json:"chain_id"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// AddressBookEntryRequest creates an address book entry
+type AddressBookEntryRequest struct {
+	Label   string `json:"label"`
+	Address string `json:"address"`
+	ChainID int    `json:"chain_id"`
+}
+
+// RecipientResolution describes how a transaction's "to" value was turned into an address,
+// so it can be shown to the user for confirmation
+type RecipientResolution struct {
+	Input   string `json:"input"`
+	Method  string `json:"method"`
+	Address string `json:"address"`
+
+	// ENS names are reverse-checked: the resolved address's primary name should be the input
+	ENSName         string `json:"ens_name,omitempty"`
+	ReverseName     string `json:"reverse_name,omitempty"`
+	ReverseVerified *bool  `json:"reverse_verified,omitempty"`
+
+	// Set when the address is in the user's address book, whichever way it was given
+	AddressBookEntryID *uuid.UUID `json:"address_book_entry_id,omitempty"`
+	Label              string     `json:"label,omitempty"`
+	Verified           *bool      `json:"verified,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ENSNameResolver resolves ENS names and verifies primary names; ENSResolver implements it
+type ENSNameResolver interface {
+	Resolve(ctx context.Context, req ENSResolveRequest) (*ENSResolveResponse, error)
+	ResolveAddress(ctx context.Context, address common.Address) (string, error)
+}
+
+// SetENSResolver replaces the ENS resolver, which otherwise uses the Ethereum mainnet provider
+func (s *Service) SetENSResolver(resolver ENSNameResolver) {
+	s.ensMu.Lock()
+	defer s.ensMu.Unlock()
+	s.ens = resolver
+}
+
+// ensNames returns the ENS resolver, creating it on first use
+func (s *Service) ensNames(ctx context.Context) (ENSNameResolver, error) {
+	s.ensMu.Lock()
+	defer s.ensMu.Unlock()
+
+	if s.ens != nil {
+		return s.ens, nil
+	}
+	if !s.config.ENSResolution {
+		return nil, fmt.Errorf("%w: disabled", ErrENSUnavailable)
+	}
+	// ENS lives on Ethereum mainnet whichever chain the transaction is on
+	client, err := s.getEthClient(ctx, 1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrENSUnavailable, err)
+	}
+	s.ens = NewENSResolver(client, s.logger)
+	return s.ens, nil
+}
+
+// AddAddressBookEntry saves a labelled address for the user
+func (s *Service) AddAddressBookEntry(ctx context.Context, userID uuid.UUID, req AddressBookEntryRequest) (*AddressBookEntry, error) {
+	label := strings.TrimSpace(req.Label)
+	switch {
+	case label == "" || len(label) > maxAddressBookLabel:
+		return nil, fmt.Errorf("%w: label must be 1 to %d characters", ErrInvalidAddressBookEntry, maxAddressBookLabel)
+	case common.IsHexAddress(label) || isENSRecipient(label):
+		return nil, fmt.Errorf("%w: label must not be an address or ENS name", ErrInvalidAddressBookEntry)
+	case !common.IsHexAddress(req.Address):
+		return nil, fmt.Errorf("%w: invalid address", ErrInvalidAddressBookEntry)
+	}
+	if !isSupportedChain(req.ChainID) {
+		return nil, fmt.Errorf("%w: unsupported chain ID: %d", ErrInvalidAddressBookEntry, req.ChainID)
+	}
+
+	now := time.Now()
+	entry := &AddressBookEntry{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Label:     label,
+		Address:   strings.ToLower(req.Address),
+		ChainID:   req.ChainID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.addressBook.Save(ctx, entry); err != nil {
+		if errors.Is(err, ErrAddressBookLabelExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save address book entry: %w", err)
+	}
+	return entry, nil
+}
+
+// ListAddressBook returns the user's address book, optionally limited to one chain
+func (s *Service) ListAddressBook(ctx context.Context, userID uuid.UUID, chainID int) ([]*AddressBookEntry, error) {
+	entries, err := s.addressBook.ListByUser(ctx, userID, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address book: %w", err)
+	}
+	if entries == nil {
+		entries = []*AddressBookEntry{}
+	}
+	return entries, nil
+}
+
+// DeleteAddressBookEntry removes one of the user's address book entries
+func (s *Service) DeleteAddressBookEntry(ctx context.Context, userID, entryID uuid.UUID) error {
+	if err := s.addressBook.Delete(ctx, userID, entryID); err != nil {
+		if errors.Is(err, ErrAddressBookEntryNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete address book entry: %w", err)
+	}
+	return nil
+}
+
+// isENSRecipient reports whether a recipient is given as an ENS name
+func isENSRecipient(input string) bool {
+	return strings.HasSuffix(strings.ToLower(input), ".eth")
+}
+
+// resolveRecipient turns a raw address, an ENS name or an address book label into the
+// recipient address of a transaction on chainID
+func (s *Service) resolveRecipient(ctx context.Context, userID uuid.UUID, chainID int, input string) (*RecipientResolution, error) {
+	input = strings.TrimSpace(input)
+	resolution := &RecipientResolution{Input: input}
+
+	switch {
+	case common.IsHexAddress(input):
+		resolution.Method = RecipientAddress
+		resolution.Address = strings.ToLower(input)
+	case isENSRecipient(input):
+		if err := s.resolveENSRecipient(ctx, resolution); err != nil {
+			return nil, err
+		}
+	default:
+		if s.addressBook == nil {
+			return nil, fmt.Errorf("%w: %q is not an address", ErrUnresolvedRecipient, input)
+		}
+		entry, err := s.addressBook.GetByLabel(ctx, userID, chainID, input)
+		if errors.Is(err, ErrAddressBookEntryNotFound) {
+			return nil, fmt.Errorf("%w: %q is not an address, ENS name or address book label on chain %d", ErrUnresolvedRecipient, input, chainID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up address book: %w", err)
+		}
+		resolution.Method = RecipientAddressBook
+		resolution.Address = entry.Address
+		resolution.applyEntry(entry)
+		return resolution, nil
+	}
+
+	// Addresses already in the address book carry their label for confirmation
+	if s.addressBook != nil {
+		if entry, err := s.addressBook.GetByAddress(ctx, userID, chainID, resolution.Address); err == nil {
+			resolution.applyEntry(entry)
+		}
+	}
+	return resolution, nil
+}
+
+// resolveENSRecipient resolves an ENS name and checks that the address's primary name
+// points back to it. A failed reverse check is reported rather than rejected, since many
+// addresses have no primary name.
+func (s *Service) resolveENSRecipient(ctx context.Context, resolution *RecipientResolution) error {
+	resolver, err := s.ensNames(ctx)
+	if err != nil {
+		return err
+	}
+	name := strings.ToLower(resolution.Input)
+	resolved, err := resolver.Resolve(ctx, ENSResolveRequest{Name: name, ResolveAddress: true})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrUnresolvedRecipient, name, err)
+	}
+	if resolved.Record == nil || resolved.Record.Address == (common.Address{}) {
+		return fmt.Errorf("%w: %s has no address", ErrUnresolvedRecipient, name)
+	}
+
+	resolution.Method = RecipientENS
+	resolution.ENSName = name
+	resolution.Address = strings.ToLower(resolved.Record.Address.Hex())
+
+	reverse, err := resolver.ResolveAddress(ctx, resolved.Record.Address)
+	verified := err == nil && strings.EqualFold(reverse, name)
+	if err == nil {
+		resolution.ReverseName = reverse
+	}
+	resolution.ReverseVerified = &verified
+	if !verified {
+		resolution.Warnings = append(resolution.Warnings, fmt.Sprintf("the primary ENS name of %s is not %s", resolution.Address, name))
+	}
+	return nil
+}
+
+func (r *RecipientResolution) applyEntry(entry *AddressBookEntry) {
+	id, verified := entry.ID, entry.Verified
+	r.AddressBookEntryID = &id
+	r.Label = entry.Label
+	r.Verified = &verified
+}
@@ -0,0 +1,231 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAddressBookRepo struct {
+	mu      sync.Mutex
+	entries []*AddressBookEntry
+}
+
+func (m *mockAddressBookRepo) Save(ctx context.Context, e *AddressBookEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.entries {
+		if existing.UserID == e.UserID && existing.ChainID == e.ChainID && strings.EqualFold(existing.Label, e.Label) {
+			return ErrAddressBookLabelExists
+		}
+	}
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *mockAddressBookRepo) ListByUser(ctx context.Context, userID uuid.UUID, chainID int) ([]*AddressBookEntry, error) {
+	return m.find(func(e *AddressBookEntry) bool {
+		return e.UserID == userID && (chainID == 0 || e.ChainID == chainID)
+	}), nil
+}
+
+func (m *mockAddressBookRepo) GetByLabel(ctx context.Context, userID uuid.UUID, chainID int, label string) (*AddressBookEntry, error) {
+	return m.first(func(e *AddressBookEntry) bool {
+		return e.UserID == userID && e.ChainID == chainID && strings.EqualFold(e.Label, label)
+	})
+}
+
+func (m *mockAddressBookRepo) GetByAddress(ctx context.Context, userID uuid.UUID, chainID int, address string) (*AddressBookEntry, error) {
+	return m.first(func(e *AddressBookEntry) bool {
+		return e.UserID == userID && e.ChainID == chainID && e.Address == strings.ToLower(address)
+	})
+}
+
+func (m *mockAddressBookRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, e := range m.entries {
+		if e.ID == id && e.UserID == userID {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return nil
+		}
+	}
+	return ErrAddressBookEntryNotFound
+}
+
+func (m *mockAddressBookRepo) MarkVerified(ctx context.Context, userID uuid.UUID, chainID int, address string, at time.Time) error {
+	for _, e := range m.find(func(e *AddressBookEntry) bool {
+		return e.UserID == userID && e.ChainID == chainID && e.Address == strings.ToLower(address)
+	}) {
+		e.Verified, e.VerifiedAt = true, &at
+	}
+	return nil
+}
+
+func (m *mockAddressBookRepo) find(match func(*AddressBookEntry) bool) []*AddressBookEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found []*AddressBookEntry
+	for _, e := range m.entries {
+		if match(e) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func (m *mockAddressBookRepo) first(match func(*AddressBookEntry) bool) (*AddressBookEntry, error) {
+	if found := m.find(match); len(found) > 0 {
+		return found[0], nil
+	}
+	return nil, ErrAddressBookEntryNotFound
+}
+
+// fakeENS resolves names from a map; primary holds each address's reverse record
+type fakeENS struct {
+	names   map[string]common.Address
+	primary map[common.Address]string
+}
+
+func (f *fakeENS) Resolve(ctx context.Context, req ENSResolveRequest) (*ENSResolveResponse, error) {
+	address, ok := f.names[req.Name]
+	if !ok {
+		return nil, errors.New("no resolver")
+	}
+	return &ENSResolveResponse{Record: &ENSRecord{Name: req.Name, Address: address}}, nil
+}
+
+func (f *fakeENS) ResolveAddress(ctx context.Context, address common.Address) (string, error) {
+	name, ok := f.primary[address]
+	if !ok {
+		return "", errors.New("no reverse record")
+	}
+	return name, nil
+}
+
+func TestAddressBook(t *testing.T) {
+	ctx := context.Background()
+	s := newServiceWithMocks()
+	s.addressBook = &mockAddressBookRepo{}
+	userID := uuid.New()
+
+	entry, err := s.AddAddressBookEntry(ctx, userID, AddressBookEntryRequest{Label: " Alice ", Address: "0x00000000000000000000000000000000000A11CE", ChainID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", entry.Label)
+	assert.Equal(t, "0x00000000000000000000000000000000000a11ce", entry.Address)
+	assert.False(t, entry.Verified)
+
+	_, err = s.AddAddressBookEntry(ctx, userID, AddressBookEntryRequest{Label: "alice", Address: entry.Address, ChainID: 1})
+	assert.ErrorIs(t, err, ErrAddressBookLabelExists)
+	_, err = s.AddAddressBookEntry(ctx, userID, AddressBookEntryRequest{Label: "alice", Address: entry.Address, ChainID: 137})
+	assert.NoError(t, err, "labels are unique per chain")
+
+	invalid := []AddressBookEntryRequest{
+		{Label: "", Address: entry.Address, ChainID: 1},
+		{Label: "bob.eth", Address: entry.Address, ChainID: 1},
+		{Label: entry.Address, Address: entry.Address, ChainID: 1},
+		{Label: "bob", Address: "0x1234", ChainID: 1},
+		{Label: "bob", Address: entry.Address, ChainID: 999},
+	}
+	for _, req := range invalid {
+		_, err := s.AddAddressBookEntry(ctx, userID, req)
+		assert.ErrorIs(t, err, ErrInvalidAddressBookEntry, "%+v", req)
+	}
+
+	entries, err := s.ListAddressBook(ctx, userID, 1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.ErrorIs(t, s.DeleteAddressBookEntry(ctx, uuid.New(), entry.ID), ErrAddressBookEntryNotFound)
+	require.NoError(t, s.DeleteAddressBookEntry(ctx, userID, entry.ID))
+	entries, err = s.ListAddressBook(ctx, userID, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCreateTransaction_ResolvesRecipient(t *testing.T) {
+	ctx := context.Background()
+	s := newServiceWithMocks()
+	book := &mockAddressBookRepo{}
+	s.addressBook = book
+	userID, walletID := uuid.New(), uuid.New()
+	s.walletRepo.(*mockWalletRepo).getByID = map[uuid.UUID]*Wallet{walletID: {ID: walletID, UserID: userID, Address: "0xabc", ChainID: 1}}
+
+	alice := common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	entry, err := s.AddAddressBookEntry(ctx, userID, AddressBookEntryRequest{Label: "Alice", Address: alice.Hex(), ChainID: 1})
+	require.NoError(t, err)
+
+	send := func(to string) (*TransactionResponse, error) {
+		return s.CreateTransaction(ctx, userID, TransactionRequest{WalletID: walletID, ToAddress: to, Value: big.NewInt(1)})
+	}
+
+	t.Run("AddressBookLabel", func(t *testing.T) {
+		resp, err := send("alice")
+		require.NoError(t, err)
+		require.NotNil(t, resp.Recipient)
+		assert.Equal(t, RecipientAddressBook, resp.Recipient.Method)
+		assert.Equal(t, entry.Address, resp.Transaction.ToAddress)
+		assert.Equal(t, "Alice", resp.Recipient.Label)
+		require.NotNil(t, resp.Recipient.Verified)
+		assert.False(t, *resp.Recipient.Verified)
+		assert.Equal(t, resp.Recipient, resp.Transaction.Metadata["recipient"])
+	})
+
+	t.Run("RawAddress", func(t *testing.T) {
+		resp, err := send(alice.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, RecipientAddress, resp.Recipient.Method)
+		assert.Equal(t, "Alice", resp.Recipient.Label, "known addresses carry their label")
+
+		resp, err = send(bob.Hex())
+		require.NoError(t, err)
+		assert.Nil(t, resp.Recipient.AddressBookEntryID)
+	})
+
+	t.Run("ENS", func(t *testing.T) {
+		_, err := send("alice.eth")
+		assert.ErrorIs(t, err, ErrENSUnavailable)
+
+		s.SetENSResolver(&fakeENS{
+			names:   map[string]common.Address{"alice.eth": alice, "bob.eth": bob},
+			primary: map[common.Address]string{alice: "alice.eth"},
+		})
+		resp, err := send("Alice.eth")
+		require.NoError(t, err)
+		assert.Equal(t, RecipientENS, resp.Recipient.Method)
+		assert.Equal(t, entry.Address, resp.Transaction.ToAddress)
+		require.NotNil(t, resp.Recipient.ReverseVerified)
+		assert.True(t, *resp.Recipient.ReverseVerified)
+		assert.Empty(t, resp.Recipient.Warnings)
+
+		resp, err = send("bob.eth")
+		require.NoError(t, err)
+		assert.False(t, *resp.Recipient.ReverseVerified)
+		assert.NotEmpty(t, resp.Recipient.Warnings)
+
+		_, err = send("nobody.eth")
+		assert.ErrorIs(t, err, ErrUnresolvedRecipient)
+	})
+
+	t.Run("UnknownLabel", func(t *testing.T) {
+		_, err := send("carol")
+		assert.ErrorIs(t, err, ErrUnresolvedRecipient)
+	})
+
+	t.Run("ConfirmationVerifiesEntry", func(t *testing.T) {
+		s.confirmTransaction(ctx, &Transaction{ID: uuid.New(), UserID: userID, ChainID: 1, ToAddress: entry.Address})
+		resp, err := send("alice")
+		require.NoError(t, err)
+		assert.True(t, *resp.Recipient.Verified)
+	})
+}
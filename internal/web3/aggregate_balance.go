@@ -0,0 +1,331 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// nativeDecimals is the precision of the native coin on every supported chain
+	nativeDecimals = 18
+	// walletPageSize is how many wallets are loaded per page when aggregating
+	walletPageSize = 100
+)
+
+// AggregateBalanceResponse is the balance of all of a user's wallets, grouped by token
+type AggregateBalanceResponse struct {
+	Tokens        []*AggregateTokenBalance `json:"tokens"`
+	TotalUSDValue float64                  `json:"total_usd_value"`
+	WalletCount   int                      `json:"wallet_count"`
+	PartialErrors []BalanceError           `json:"partial_errors"`
+	Timestamp     time.Time                `json:"timestamp"`
+}
+
+// AggregateTokenBalance is a token's balance summed over all wallets and chains
+type AggregateTokenBalance struct {
+	Symbol   string               `json:"symbol"`
+	Balance  decimal.Decimal      `json:"balance"`
+	PriceUSD float64              `json:"price_usd"`
+	USDValue float64              `json:"usd_value"`
+	Chains   []*ChainTokenBalance `json:"chains"`
+}
+
+// ChainTokenBalance is a token's balance on one chain, summed over the user's wallets there
+type ChainTokenBalance struct {
+	ChainID      int             `json:"chain_id"`
+	ChainName    string          `json:"chain_name"`
+	TokenAddress string          `json:"token_address,omitempty"` // empty for the native coin
+	Balance      decimal.Decimal `json:"balance"`
+	USDValue     float64         `json:"usd_value"`
+}
+
+// BalanceError reports balances missing from an aggregate, for a whole chain, one wallet or
+// one token of a wallet
+type BalanceError struct {
+	ChainID   int        `json:"chain_id,omitempty"`
+	ChainName string     `json:"chain_name,omitempty"`
+	WalletID  *uuid.UUID `json:"wallet_id,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	Error     string     `json:"error"`
+}
+
+// chainBalanceReader reads wallet balances from a chain
+type chainBalanceReader interface {
+	NativeBalance(ctx context.Context, chainID int, address string) (*big.Int, error)
+	TokenBalance(ctx context.Context, chainID int, token, address string) (*big.Int, error)
+}
+
+// cachedChainBalances reads balances through the service's cached RPC helpers
+type cachedChainBalances struct {
+	service *Service
+}
+
+func (c cachedChainBalances) NativeBalance(ctx context.Context, chainID int, address string) (*big.Int, error) {
+	return c.service.getNativeBalance(ctx, chainID, address)
+}
+
+func (c cachedChainBalances) TokenBalance(ctx context.Context, chainID int, token, address string) (*big.Int, error) {
+	return c.service.getERC20Balance(ctx, chainID, token, address)
+}
+
+// balanceReader returns the reader used for aggregate balances
+func (s *Service) balanceReader() chainBalanceReader {
+	if s.balances != nil {
+		return s.balances
+	}
+	return cachedChainBalances{service: s}
+}
+
+// chainHolding is an amount of one token held on one chain
+type chainHolding struct {
+	symbol       string
+	tokenAddress string
+	coinGeckoID  string
+	amount       decimal.Decimal
+}
+
+// chainResult is what reading one chain produced
+type chainResult struct {
+	chainID  int
+	holdings map[string]*chainHolding // by symbol
+	errors   []BalanceError
+}
+
+// GetAggregateBalance returns the native and common ERC-20 balances of all the user's mainnet
+// wallets, grouped by token with a per-chain breakdown and valued in USD. Testnet wallets are
+// left out. Chains are read concurrently, each within the configured timeout; chains and
+// wallets that can't be read are reported under PartialErrors instead of failing the request.
+func (s *Service) GetAggregateBalance(ctx context.Context, userID uuid.UUID) (*AggregateBalanceResponse, error) {
+	all, err := s.allWallets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	wallets := make([]*Wallet, 0, len(all))
+	for _, wallet := range all {
+		if NetworkOf(wallet.ChainID) == NetworkMainnet {
+			wallets = append(wallets, wallet)
+		}
+	}
+
+	response := &AggregateBalanceResponse{
+		Tokens:        make([]*AggregateTokenBalance, 0),
+		WalletCount:   len(wallets),
+		PartialErrors: make([]BalanceError, 0),
+		Timestamp:     time.Now(),
+	}
+
+	byChain := make(map[int][]*Wallet)
+	for _, wallet := range wallets {
+		byChain[wallet.ChainID] = append(byChain[wallet.ChainID], wallet)
+	}
+	chainIDs := make([]int, 0, len(byChain))
+	for chainID := range byChain {
+		if _, ok := s.providers[chainID]; !ok {
+			response.PartialErrors = append(response.PartialErrors, BalanceError{
+				ChainID:   chainID,
+				ChainName: SupportedChains[chainID],
+				Error:     fmt.Sprintf("no provider configured for chain ID: %d", chainID),
+			})
+			continue
+		}
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Ints(chainIDs)
+
+	results := s.readChains(ctx, s.balanceReader(), chainIDs, byChain)
+
+	// Group holdings by token across chains
+	tokens := make(map[string]*AggregateTokenBalance)
+	priceIDs := make(map[string]string) // symbol -> CoinGecko ID
+	holdingsBySymbol := make(map[string][]*ChainTokenBalance)
+	for _, result := range results {
+		response.PartialErrors = append(response.PartialErrors, result.errors...)
+		for symbol, holding := range result.holdings {
+			if holding.amount.IsZero() {
+				continue
+			}
+			token, exists := tokens[symbol]
+			if !exists {
+				token = &AggregateTokenBalance{Symbol: symbol, Balance: decimal.Zero}
+				tokens[symbol] = token
+			}
+			token.Balance = token.Balance.Add(holding.amount)
+			if holding.coinGeckoID != "" {
+				priceIDs[symbol] = holding.coinGeckoID
+			}
+			holdingsBySymbol[symbol] = append(holdingsBySymbol[symbol], &ChainTokenBalance{
+				ChainID:      result.chainID,
+				ChainName:    SupportedChains[result.chainID],
+				TokenAddress: holding.tokenAddress,
+				Balance:      holding.amount,
+			})
+		}
+	}
+
+	prices := s.aggregatePrices(ctx, priceIDs, response)
+	for symbol, token := range tokens {
+		token.Chains = holdingsBySymbol[symbol]
+		sort.Slice(token.Chains, func(i, j int) bool { return token.Chains[i].ChainID < token.Chains[j].ChainID })
+		if price, ok := prices[priceIDs[symbol]]; ok {
+			token.PriceUSD = price.Price
+			for _, chain := range token.Chains {
+				chain.USDValue, _ = chain.Balance.Mul(decimal.NewFromFloat(price.Price)).Float64()
+				token.USDValue += chain.USDValue
+			}
+		}
+		response.TotalUSDValue += token.USDValue
+		response.Tokens = append(response.Tokens, token)
+	}
+	sort.Slice(response.Tokens, func(i, j int) bool {
+		if response.Tokens[i].USDValue != response.Tokens[j].USDValue {
+			return response.Tokens[i].USDValue > response.Tokens[j].USDValue
+		}
+		return response.Tokens[i].Symbol < response.Tokens[j].Symbol
+	})
+
+	s.logger.Info(ctx, "Aggregate balance retrieved", map[string]any{
+		"user_id":        userID.String(),
+		"wallets":        len(wallets),
+		"chains":         len(chainIDs),
+		"tokens":         len(response.Tokens),
+		"partial_errors": len(response.PartialErrors),
+		"total_usd":      response.TotalUSDValue,
+	})
+
+	return response, nil
+}
+
+// allWallets loads every wallet of a user
+func (s *Service) allWallets(ctx context.Context, userID uuid.UUID) ([]*Wallet, error) {
+	var wallets []*Wallet
+	for offset := 0; ; offset += walletPageSize {
+		page, _, err := s.walletRepo.ListByUser(ctx, userID, WalletListFilter{Limit: walletPageSize, Offset: offset})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list wallets: %w", err)
+		}
+		wallets = append(wallets, page...)
+		if len(page) < walletPageSize {
+			return wallets, nil
+		}
+	}
+}
+
+// readChains reads the wallets of each chain with bounded parallelism. A chain that doesn't
+// finish within the per-chain timeout is reported as failed without waiting for it.
+func (s *Service) readChains(ctx context.Context, reader chainBalanceReader, chainIDs []int, byChain map[int][]*Wallet) []chainResult {
+	parallelism := s.config.BalanceChainParallelism
+	if parallelism <= 0 {
+		parallelism = 4
+	}
+	timeout := s.config.BalanceChainTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	results := make([]chainResult, len(chainIDs))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, chainID := range chainIDs {
+		wg.Add(1)
+		go func(i, chainID int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			chainCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan chainResult, 1)
+			go func() { done <- readChain(chainCtx, reader, chainID, byChain[chainID]) }()
+			select {
+			case results[i] = <-done:
+			case <-chainCtx.Done():
+				results[i] = chainResult{chainID: chainID, errors: []BalanceError{{
+					ChainID:   chainID,
+					ChainName: SupportedChains[chainID],
+					Error:     fmt.Sprintf("chain did not respond within %s", timeout),
+				}}}
+			}
+		}(i, chainID)
+	}
+	wg.Wait()
+	return results
+}
+
+// readChain sums the native and common ERC-20 balances of wallets on one chain
+func readChain(ctx context.Context, reader chainBalanceReader, chainID int, wallets []*Wallet) chainResult {
+	result := chainResult{chainID: chainID, holdings: make(map[string]*chainHolding)}
+	addError := func(wallet *Wallet, token string, err error) {
+		walletID := wallet.ID
+		result.errors = append(result.errors, BalanceError{
+			ChainID:   chainID,
+			ChainName: SupportedChains[chainID],
+			WalletID:  &walletID,
+			Token:     token,
+			Error:     err.Error(),
+		})
+	}
+	add := func(symbol, tokenAddress, coinGeckoID string, raw *big.Int, decimals int) {
+		holding, exists := result.holdings[symbol]
+		if !exists {
+			holding = &chainHolding{symbol: symbol, tokenAddress: tokenAddress, coinGeckoID: coinGeckoID, amount: decimal.Zero}
+			result.holdings[symbol] = holding
+		}
+		holding.amount = holding.amount.Add(decimal.NewFromBigInt(raw, int32(-decimals)))
+	}
+
+	nativeSymbol := NativeSymbolByChain[chainID]
+	if nativeSymbol == "" {
+		nativeSymbol = SupportedChains[chainID]
+	}
+	for _, wallet := range wallets {
+		native, err := reader.NativeBalance(ctx, chainID, wallet.Address)
+		if err != nil {
+			addError(wallet, nativeSymbol, err)
+		} else {
+			add(nativeSymbol, "", NativeCoinGeckoIDByChain[chainID], native, nativeDecimals)
+		}
+
+		// Listed tokens carry their decimals, so only balances are read
+		for _, token := range CommonERC20Tokens[chainID] {
+			balance, err := reader.TokenBalance(ctx, chainID, token.Address, wallet.Address)
+			if err != nil {
+				addError(wallet, token.Symbol, err)
+				continue
+			}
+			add(token.Symbol, token.Address, token.CoinGeckoID, balance, token.Decimals)
+		}
+	}
+	return result
+}
+
+// aggregatePrices prices the held tokens in USD with the GetPrices source. A failed lookup is
+// reported as a partial error and leaves the tokens unvalued.
+func (s *Service) aggregatePrices(ctx context.Context, priceIDs map[string]string, response *AggregateBalanceResponse) map[string]TokenPrice {
+	idSet := make(map[string]struct{})
+	for _, id := range priceIDs {
+		idSet[id] = struct{}{}
+	}
+	if len(idSet) == 0 {
+		return map[string]TokenPrice{}
+	}
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	prices, err := s.prices().GetPrices(ctx, "USD", ids)
+	if err != nil {
+		response.PartialErrors = append(response.PartialErrors, BalanceError{Error: fmt.Sprintf("price lookup failed: %v", err)})
+		return map[string]TokenPrice{}
+	}
+	return prices
+}
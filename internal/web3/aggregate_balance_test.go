@@ -0,0 +1,116 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChainBalances serves balances per chain; a chain in slow blocks until the read is cancelled
+type mockChainBalances struct {
+	native map[int]*big.Int
+	tokens map[int]map[string]*big.Int // by token address
+	failed map[int]bool
+	slow   map[int]bool
+}
+
+func (m *mockChainBalances) wait(ctx context.Context, chainID int) error {
+	if m.slow[chainID] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if m.failed[chainID] {
+		return errors.New("rpc unavailable")
+	}
+	return nil
+}
+
+func (m *mockChainBalances) NativeBalance(ctx context.Context, chainID int, address string) (*big.Int, error) {
+	if err := m.wait(ctx, chainID); err != nil {
+		return nil, err
+	}
+	return m.native[chainID], nil
+}
+
+func (m *mockChainBalances) TokenBalance(ctx context.Context, chainID int, token, address string) (*big.Int, error) {
+	if err := m.wait(ctx, chainID); err != nil {
+		return nil, err
+	}
+	if balance, ok := m.tokens[chainID][token]; ok {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func TestGetAggregateBalance(t *testing.T) {
+	s := newServiceWithMocks()
+	s.config.BalanceChainTimeout = 50 * time.Millisecond
+	for _, chainID := range []int{137, 42161, 10} {
+		s.providers[chainID] = &ChainProvider{ChainID: chainID}
+	}
+	userID := uuid.New()
+	s.walletRepo.(*mockWalletRepo).listResult = []*Wallet{
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 1},
+		{ID: uuid.New(), UserID: userID, Address: "0xdef", ChainID: 1},
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 42161},
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 137},
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 10},
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 56},
+	}
+	usdcMainnet := CommonERC20Tokens[1][0].Address
+	usdcArbitrum := CommonERC20Tokens[42161][0].Address
+	s.balances = &mockChainBalances{
+		native: map[int]*big.Int{1: ether(1), 42161: ether(2)},
+		tokens: map[int]map[string]*big.Int{
+			1:     {usdcMainnet: big.NewInt(100_000_000)},
+			42161: {usdcArbitrum: big.NewInt(50_000_000)},
+		},
+		failed: map[int]bool{137: true},
+		slow:   map[int]bool{10: true},
+	}
+	s.priceSource = &mockPriceSource{prices: map[string]TokenPrice{
+		"ethereum": {Price: 2000},
+		"usd-coin": {Price: 1},
+	}}
+
+	start := time.Now()
+	resp, err := s.GetAggregateBalance(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second, "a slow chain must not stall the response")
+	assert.Equal(t, 6, resp.WalletCount)
+
+	require.Len(t, resp.Tokens, 2)
+	eth, usdc := resp.Tokens[0], resp.Tokens[1]
+	assert.Equal(t, "ETH", eth.Symbol)
+	// Two mainnet wallets with 1 ETH each and 2 ETH on Arbitrum
+	assert.True(t, eth.Balance.Equal(decimal.NewFromInt(4)), eth.Balance.String())
+	require.Len(t, eth.Chains, 2)
+	assert.Equal(t, 1, eth.Chains[0].ChainID)
+	assert.True(t, eth.Chains[0].Balance.Equal(decimal.NewFromInt(2)))
+	assert.Equal(t, 8000.0, eth.USDValue)
+
+	assert.Equal(t, "USDC", usdc.Symbol)
+	assert.True(t, usdc.Balance.Equal(decimal.NewFromInt(250)), usdc.Balance.String())
+	assert.Len(t, usdc.Chains, 2)
+	assert.Equal(t, 8250.0, resp.TotalUSDValue)
+
+	failedChains := make(map[int]int)
+	for _, partial := range resp.PartialErrors {
+		failedChains[partial.ChainID]++
+	}
+	assert.Contains(t, failedChains, 56, "no provider")
+	assert.Contains(t, failedChains, 137, "failing RPC")
+	assert.Equal(t, 1, failedChains[10], "timed out chain is reported once")
+	assert.NotContains(t, failedChains, 1)
+}
@@ -0,0 +1,904 @@
+package web3
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrBridgeRouteNotFound    = errors.New("bridge route not found or expired")
+	ErrBridgeTransferNotFound = errors.New("bridge transfer not found")
+	ErrNoBridgeRoutes         = errors.New("no bridge route available")
+)
+
+// NativeTokenAddress stands for a chain's native token in bridge routes
+const NativeTokenAddress = "0x0000000000000000000000000000000000000000"
+
+// BridgeTransferStatus is where a cross-chain transfer is in its lifecycle: the source
+// transaction is signed and confirmed, the bridge relays the funds and a destination
+// transaction delivers them
+type BridgeTransferStatus string
+
+const (
+	BridgeAwaitingSource BridgeTransferStatus = "awaiting_source" // source transaction not yet broadcast
+	BridgeSourcePending  BridgeTransferStatus = "source_pending"  // source transaction awaiting confirmations
+	BridgeRelaying       BridgeTransferStatus = "relaying"        // waiting for the destination transaction
+	BridgeCompleted      BridgeTransferStatus = "completed"
+	BridgeRefunded       BridgeTransferStatus = "refunded" // the bridge returned the funds on the source chain
+	BridgeFailed         BridgeTransferStatus = "failed"
+	BridgeCancelled      BridgeTransferStatus = "cancelled" // the source transaction was never signed
+)
+
+// Terminal reports whether the transfer can no longer change
+func (s BridgeTransferStatus) Terminal() bool {
+	switch s {
+	case BridgeCompleted, BridgeRefunded, BridgeFailed, BridgeCancelled:
+		return true
+	}
+	return false
+}
+
+// BridgeQuoteRequest asks for the routes moving an amount of a token from the wallet's chain
+// to another chain
+type BridgeQuoteRequest struct {
+	WalletID  uuid.UUID `json:"wallet_id"`
+	ToChainID int       `json:"to_chain_id"`
+	FromToken string    `json:"from_token"` // empty for the native token
+	ToToken   string    `json:"to_token"`   // empty for the native token
+	Amount    *big.Int  `json:"amount"`     // in the source token's base units
+	ToAddress string    `json:"to_address"` // defaults to the wallet's address
+
+	SlippageBps int `json:"slippage_bps"` // defaults to 50
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *BridgeQuoteRequest) Validate() error {
+	var v validation.Validator
+	if r.WalletID == uuid.Nil {
+		v.Fail("wallet_id", validation.ConstraintRequired, "is required")
+	}
+	if r.ToChainID <= 0 {
+		v.Fail("to_chain_id", validation.ConstraintRequired, "is required")
+	} else if !isSupportedChain(r.ToChainID) {
+		v.Fail("to_chain_id", validation.ConstraintEnum, "is not a supported chain")
+	}
+	if r.FromToken != "" && !common.IsHexAddress(r.FromToken) {
+		v.Fail("from_token", validation.ConstraintFormat, "must be an address")
+	}
+	if r.ToToken != "" && !common.IsHexAddress(r.ToToken) {
+		v.Fail("to_token", validation.ConstraintFormat, "must be an address")
+	}
+	if r.Amount == nil || r.Amount.Sign() <= 0 {
+		v.Fail("amount", validation.ConstraintRange, "must be positive")
+	}
+	if r.ToAddress != "" && !common.IsHexAddress(r.ToAddress) {
+		v.Fail("to_address", validation.ConstraintFormat, "must be an address")
+	}
+	if r.SlippageBps < 0 || r.SlippageBps > 1000 {
+		v.Fail("slippage_bps", validation.ConstraintRange, "must be between 0 and 1000")
+	}
+	return v.Err()
+}
+
+// BridgeRouteRequest is a quote request resolved against the wallet, as sent to providers
+type BridgeRouteRequest struct {
+	FromChainID int
+	ToChainID   int
+	FromToken   string
+	ToToken     string
+	Amount      *big.Int
+	FromAddress string
+	ToAddress   string
+	SlippageBps int
+}
+
+// BridgeToken identifies a token of a route
+type BridgeToken struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals int    `json:"decimals,omitempty"`
+}
+
+// BridgeFee is a fee charged along a route. Included fees are taken from the bridged amount;
+// the others are paid on top of it.
+type BridgeFee struct {
+	Name      string          `json:"name"`
+	Token     string          `json:"token"`
+	Amount    string          `json:"amount"`
+	AmountUSD decimal.Decimal `json:"amount_usd"`
+	Included  bool            `json:"included"`
+}
+
+// BridgeTransactionRequest is the source-chain call executing a route
+type BridgeTransactionRequest struct {
+	To       string   `json:"to"`
+	Data     string   `json:"data"`
+	Value    *big.Int `json:"value"`
+	GasLimit uint64   `json:"gas_limit,omitempty"`
+}
+
+// BridgeRoute is a way of moving the funds, with its cost, duration and expected output.
+// Amounts are in the tokens' base units.
+type BridgeRoute struct {
+	ID                uuid.UUID       `json:"id"`
+	Provider          string          `json:"provider"`
+	Bridge            string          `json:"bridge"`
+	BridgeName        string          `json:"bridge_name,omitempty"`
+	WalletID          uuid.UUID       `json:"wallet_id"`
+	FromChainID       int             `json:"from_chain_id"`
+	ToChainID         int             `json:"to_chain_id"`
+	FromToken         BridgeToken     `json:"from_token"`
+	ToToken           BridgeToken     `json:"to_token"`
+	FromAddress       string          `json:"from_address"`
+	ToAddress         string          `json:"to_address"`
+	FromAmount        string          `json:"from_amount"`
+	ToAmount          string          `json:"to_amount"`
+	ToAmountMin       string          `json:"to_amount_min"`
+	Fees              []BridgeFee     `json:"fees"`
+	FeeUSD            decimal.Decimal `json:"fee_usd"`
+	GasCostUSD        decimal.Decimal `json:"gas_cost_usd"`
+	EstimatedDuration int             `json:"estimated_duration_seconds"`
+	Steps             []string        `json:"steps,omitempty"` // e.g. "swap:uniswap", "cross:stargate"
+
+	// ApprovalAddress must be allowed to spend FromAmount of an ERC-20 source token
+	ApprovalAddress string                    `json:"approval_address,omitempty"`
+	Transaction     *BridgeTransactionRequest `json:"transaction"`
+	ExpiresAt       time.Time                 `json:"expires_at"`
+}
+
+// toAmount returns the expected output for ranking routes
+func (r *BridgeRoute) toAmount() *big.Int {
+	amount, ok := new(big.Int).SetString(r.ToAmount, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return amount
+}
+
+// BridgeQuote compares the routes of every provider, best output first
+type BridgeQuote struct {
+	Routes         []BridgeRoute `json:"routes"`
+	BestRouteID    uuid.UUID     `json:"best_route_id"`
+	FastestRouteID uuid.UUID     `json:"fastest_route_id"`
+	// Providers that failed to quote; the routes of the others are still returned
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// BridgeExecuteRequest executes a quoted route
+type BridgeExecuteRequest struct {
+	RouteID uuid.UUID `json:"route_id"`
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *BridgeExecuteRequest) Validate() error {
+	var v validation.Validator
+	if r.RouteID == uuid.Nil {
+		v.Fail("route_id", validation.ConstraintRequired, "is required")
+	}
+	return v.Err()
+}
+
+// BridgeExecution is a started transfer with the transactions the wallet signs for it. ERC-20
+// transfers first approve the bridge when its allowance is too low.
+type BridgeExecution struct {
+	Transfer    *BridgeTransfer      `json:"transfer"`
+	Approval    *TransactionResponse `json:"approval,omitempty"`
+	Transaction *TransactionResponse `json:"transaction"`
+}
+
+// BridgeTransfer tracks a cross-chain transfer from its source transaction to the
+// destination transaction delivering the funds
+type BridgeTransfer struct {
+	ID                uuid.UUID            `json:"id"`
+	UserID            uuid.UUID            `json:"user_id"`
+	WalletID          uuid.UUID            `json:"wallet_id"`
+	Provider          string               `json:"provider"`
+	Bridge            string               `json:"bridge"`
+	FromChainID       int                  `json:"from_chain_id"`
+	ToChainID         int                  `json:"to_chain_id"`
+	FromToken         BridgeToken          `json:"from_token"`
+	ToToken           BridgeToken          `json:"to_token"`
+	FromAmount        string               `json:"from_amount"`
+	ToAmountMin       string               `json:"to_amount_min"`
+	ToAddress         string               `json:"to_address"`
+	EstimatedDuration int                  `json:"estimated_duration_seconds"`
+	Status            BridgeTransferStatus `json:"status"`
+	Substatus         string               `json:"substatus,omitempty"` // the provider's detail
+	Message           string               `json:"message,omitempty"`
+
+	// The source transaction, or the multisig proposal that builds it
+	SourceTransactionID *uuid.UUID `json:"source_transaction_id,omitempty"`
+	SourceProposalID    *uuid.UUID `json:"source_proposal_id,omitempty"`
+	SourceTxHash        string     `json:"source_tx_hash,omitempty"`
+	SourceBroadcastAt   *time.Time `json:"source_broadcast_at,omitempty"`
+
+	DestinationTxHash string `json:"destination_tx_hash,omitempty"`
+	ReceivedAmount    string `json:"received_amount,omitempty"`
+	ExplorerURL       string `json:"explorer_url,omitempty"`
+
+	// Stuck is set once the transfer overran its expected duration and the user was alerted
+	Stuck       bool       `json:"stuck"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// BridgeStatusUpdate is a provider's view of a transfer whose source transaction was broadcast
+type BridgeStatusUpdate struct {
+	Status            BridgeTransferStatus
+	Substatus         string
+	Message           string
+	DestinationTxHash string
+	ReceivedAmount    string
+	ExplorerURL       string
+}
+
+// BridgeProvider quotes bridge routes and tracks the transfers executing them
+type BridgeProvider interface {
+	Name() string
+	// Quote returns the provider's routes, each with the transaction executing it
+	Quote(ctx context.Context, req BridgeRouteRequest) ([]BridgeRoute, error)
+	// Status reports the progress of a transfer whose source transaction was broadcast
+	Status(ctx context.Context, transfer *BridgeTransfer) (*BridgeStatusUpdate, error)
+}
+
+// BridgeRouteCache keeps quoted routes until they are executed or expire
+type BridgeRouteCache interface {
+	Save(ctx context.Context, userID uuid.UUID, route *BridgeRoute, ttl time.Duration) error
+	// Get returns nil for routes that expired or were quoted for another user
+	Get(ctx context.Context, userID, id uuid.UUID) (*BridgeRoute, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// BridgeTransferStore persists bridge transfers
+type BridgeTransferStore interface {
+	Save(ctx context.Context, transfer *BridgeTransfer) error
+	// Get returns nil when there is none
+	Get(ctx context.Context, id uuid.UUID) (*BridgeTransfer, error)
+	// ListByUser returns the user's transfers, newest first
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*BridgeTransfer, error)
+	ListActive(ctx context.Context) ([]*BridgeTransfer, error)
+}
+
+// allowanceReader reads ERC-20 allowances
+type allowanceReader interface {
+	Allowance(ctx context.Context, chainID int, token, owner, spender string) (*big.Int, error)
+}
+
+// BridgeConfig configures bridging
+type BridgeConfig struct {
+	QuoteTTL     time.Duration // how long a quoted route can be executed
+	PollInterval time.Duration // how often active transfers are polled
+	// Transfers still active StuckAfter past their broadcast, or three times their estimated
+	// duration when that is longer, alert their owner
+	StuckAfter time.Duration
+}
+
+// BridgeManager compares bridge routes across providers, executes the chosen one through the
+// wallet's signing flow and tracks the transfer to its destination. Failed and stuck transfers
+// alert their owner.
+type BridgeManager struct {
+	service    *Service
+	providers  map[string]BridgeProvider
+	routes     BridgeRouteCache
+	transfers  BridgeTransferStore
+	allowances allowanceReader
+	alerts     *alerts.AlertService
+	logger     *observability.Logger
+	config     BridgeConfig
+	now        func() time.Time
+}
+
+// NewBridgeManager creates a manager quoting routes from the given providers
+func NewBridgeManager(service *Service, routes BridgeRouteCache, transfers BridgeTransferStore, cfg BridgeConfig, providers ...BridgeProvider) *BridgeManager {
+	if cfg.QuoteTTL <= 0 {
+		cfg.QuoteTTL = 2 * time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	if cfg.StuckAfter <= 0 {
+		cfg.StuckAfter = time.Hour
+	}
+	byName := make(map[string]BridgeProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &BridgeManager{
+		service:    service,
+		providers:  byName,
+		routes:     routes,
+		transfers:  transfers,
+		allowances: &rpcApprovalReader{service: service},
+		logger:     service.logger,
+		config:     cfg,
+		now:        time.Now,
+	}
+}
+
+// SetAlertService alerts users of failed, refunded and stuck transfers
+func (m *BridgeManager) SetAlertService(alertService *alerts.AlertService) {
+	m.alerts = alertService
+}
+
+// Start polls active transfers on the poll interval until ctx is done
+func (m *BridgeManager) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.PollTransfers(ctx)
+			}
+		}
+	}()
+}
+
+// Quote asks every provider for routes from the wallet's chain and returns them best output
+// first. Providers that fail are reported alongside the routes of the others.
+func (m *BridgeManager) Quote(ctx context.Context, userID uuid.UUID, req BridgeQuoteRequest) (*BridgeQuote, error) {
+	wallet, err := m.service.walletRepo.GetByID(ctx, req.WalletID)
+	if err != nil || wallet.UserID != userID {
+		return nil, ErrWalletNotFound
+	}
+	if wallet.ChainID == req.ToChainID {
+		var v validation.Validator
+		v.Fail("to_chain_id", validation.ConstraintInvalid, "must differ from the wallet's chain")
+		return nil, v.Err()
+	}
+
+	routeReq := BridgeRouteRequest{
+		FromChainID: wallet.ChainID,
+		ToChainID:   req.ToChainID,
+		FromToken:   bridgeTokenAddress(req.FromToken),
+		ToToken:     bridgeTokenAddress(req.ToToken),
+		Amount:      req.Amount,
+		FromAddress: common.HexToAddress(wallet.Address).Hex(),
+		ToAddress:   req.ToAddress,
+		SlippageBps: req.SlippageBps,
+	}
+	if routeReq.ToAddress == "" {
+		routeReq.ToAddress = routeReq.FromAddress
+	}
+	routeReq.ToAddress = common.HexToAddress(routeReq.ToAddress).Hex()
+	if routeReq.SlippageBps == 0 {
+		routeReq.SlippageBps = 50
+	}
+
+	type result struct {
+		provider string
+		routes   []BridgeRoute
+		err      error
+	}
+	results := make(chan result, len(m.providers))
+	var wg sync.WaitGroup
+	for name, provider := range m.providers {
+		wg.Add(1)
+		go func(name string, provider BridgeProvider) {
+			defer wg.Done()
+			routes, err := provider.Quote(ctx, routeReq)
+			results <- result{provider: name, routes: routes, err: err}
+		}(name, provider)
+	}
+	wg.Wait()
+	close(results)
+
+	quote := &BridgeQuote{Routes: []BridgeRoute{}}
+	expiresAt := m.now().Add(m.config.QuoteTTL)
+	for res := range results {
+		if res.err != nil {
+			m.logger.Warn(ctx, "Bridge provider failed to quote", map[string]interface{}{
+				"provider": res.provider,
+				"error":    res.err.Error(),
+			})
+			if quote.Errors == nil {
+				quote.Errors = make(map[string]string)
+			}
+			quote.Errors[res.provider] = res.err.Error()
+			continue
+		}
+		for _, route := range res.routes {
+			route.ID = uuid.New()
+			route.Provider = res.provider
+			route.WalletID = wallet.ID
+			route.ExpiresAt = expiresAt
+			quote.Routes = append(quote.Routes, route)
+		}
+	}
+	if len(quote.Routes) == 0 {
+		return nil, ErrNoBridgeRoutes
+	}
+
+	sort.SliceStable(quote.Routes, func(i, j int) bool {
+		return quote.Routes[i].toAmount().Cmp(quote.Routes[j].toAmount()) > 0
+	})
+	quote.BestRouteID = quote.Routes[0].ID
+	fastest := quote.Routes[0]
+	for _, route := range quote.Routes[1:] {
+		if route.EstimatedDuration < fastest.EstimatedDuration {
+			fastest = route
+		}
+	}
+	quote.FastestRouteID = fastest.ID
+
+	for i := range quote.Routes {
+		if err := m.routes.Save(ctx, userID, &quote.Routes[i], m.config.QuoteTTL); err != nil {
+			return nil, fmt.Errorf("failed to save bridge route: %w", err)
+		}
+	}
+	return quote, nil
+}
+
+// Execute starts a transfer along a quoted route. The source transactions go through the
+// wallet's signing flow like any other; multisig wallets propose them to their members.
+func (m *BridgeManager) Execute(ctx context.Context, userID uuid.UUID, req BridgeExecuteRequest) (*BridgeExecution, error) {
+	route, err := m.routes.Get(ctx, userID, req.RouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bridge route: %w", err)
+	}
+	if route == nil || !m.now().Before(route.ExpiresAt) || route.Transaction == nil {
+		return nil, ErrBridgeRouteNotFound
+	}
+	wallet, err := m.service.walletRepo.GetByID(ctx, route.WalletID)
+	if err != nil || wallet.UserID != userID {
+		return nil, ErrWalletNotFound
+	}
+	// A route is executed once; quote again to retry
+	if err := m.routes.Delete(ctx, userID, route.ID); err != nil {
+		return nil, fmt.Errorf("failed to claim bridge route: %w", err)
+	}
+
+	transfer := &BridgeTransfer{
+		ID:                uuid.New(),
+		UserID:            userID,
+		WalletID:          wallet.ID,
+		Provider:          route.Provider,
+		Bridge:            route.Bridge,
+		FromChainID:       route.FromChainID,
+		ToChainID:         route.ToChainID,
+		FromToken:         route.FromToken,
+		ToToken:           route.ToToken,
+		FromAmount:        route.FromAmount,
+		ToAmountMin:       route.ToAmountMin,
+		ToAddress:         route.ToAddress,
+		EstimatedDuration: route.EstimatedDuration,
+		Status:            BridgeAwaitingSource,
+		CreatedAt:         m.now(),
+		UpdatedAt:         m.now(),
+	}
+	metadata := map[string]interface{}{
+		"bridge_transfer_id": transfer.ID.String(),
+		"bridge":             route.Bridge,
+		"to_chain_id":        route.ToChainID,
+	}
+	execution := &BridgeExecution{Transfer: transfer}
+
+	// ERC-20 sources need the bridge approved for the amount first
+	if approval, err := m.approvalNeeded(ctx, wallet, route); err != nil {
+		return nil, err
+	} else if approval != nil {
+		approval.Metadata = map[string]interface{}{"bridge_transfer_id": transfer.ID.String(), "bridge_approval": true}
+		if execution.Approval, err = m.service.CreateTransaction(ctx, userID, *approval); err != nil {
+			return nil, fmt.Errorf("failed to create bridge approval: %w", err)
+		}
+	}
+
+	execution.Transaction, err = m.service.CreateTransaction(ctx, userID, TransactionRequest{
+		WalletID:  wallet.ID,
+		ToAddress: route.Transaction.To,
+		Value:     route.Transaction.Value,
+		Data:      route.Transaction.Data,
+		GasLimit:  route.Transaction.GasLimit,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bridge transaction: %w", err)
+	}
+	if tx := execution.Transaction.Transaction; tx != nil {
+		transfer.SourceTransactionID = &tx.ID
+		m.trackSource(transfer, tx)
+	} else if proposal := execution.Transaction.Proposal; proposal != nil {
+		transfer.SourceProposalID = &proposal.ID
+	}
+
+	if err := m.transfers.Save(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to save bridge transfer: %w", err)
+	}
+	m.logger.Info(ctx, "Bridge transfer started", map[string]interface{}{
+		"transfer_id":   transfer.ID.String(),
+		"provider":      transfer.Provider,
+		"bridge":        transfer.Bridge,
+		"from_chain_id": transfer.FromChainID,
+		"to_chain_id":   transfer.ToChainID,
+	})
+	return execution, nil
+}
+
+// approvalNeeded returns the approval transaction an ERC-20 route needs, or nil when the
+// bridge's allowance already covers the amount
+func (m *BridgeManager) approvalNeeded(ctx context.Context, wallet *Wallet, route *BridgeRoute) (*TransactionRequest, error) {
+	token := route.FromToken.Address
+	if isNativeToken(token) || route.ApprovalAddress == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Int).SetString(route.FromAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid route amount %q", route.FromAmount)
+	}
+	allowance, err := m.allowances.Allowance(ctx, wallet.ChainID, token, wallet.Address, route.ApprovalAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bridge allowance: %w", err)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil, nil
+	}
+
+	spender := common.HexToAddress(route.ApprovalAddress)
+	data := append(append([]byte{}, approveSelector...), common.LeftPadBytes(spender.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return &TransactionRequest{
+		WalletID:  wallet.ID,
+		ToAddress: common.HexToAddress(token).Hex(),
+		Value:     new(big.Int),
+		Data:      hexutil.Encode(data),
+	}, nil
+}
+
+// GetTransfer returns one of the user's transfers, polling its progress when it is active
+func (m *BridgeManager) GetTransfer(ctx context.Context, userID, id uuid.UUID) (*BridgeTransfer, error) {
+	transfer, err := m.transfers.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bridge transfer: %w", err)
+	}
+	if transfer == nil || transfer.UserID != userID {
+		return nil, ErrBridgeTransferNotFound
+	}
+	if !transfer.Status.Terminal() {
+		m.refresh(ctx, transfer)
+	}
+	return transfer, nil
+}
+
+// ListTransfers returns the user's transfers, newest first
+func (m *BridgeManager) ListTransfers(ctx context.Context, userID uuid.UUID) ([]*BridgeTransfer, error) {
+	transfers, err := m.transfers.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bridge transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// PollTransfers refreshes every active transfer and returns how many changed
+func (m *BridgeManager) PollTransfers(ctx context.Context) int {
+	transfers, err := m.transfers.ListActive(ctx)
+	if err != nil {
+		m.logger.Error(ctx, "Failed to list active bridge transfers", err)
+		return 0
+	}
+	changed := 0
+	for _, transfer := range transfers {
+		if m.refresh(ctx, transfer) {
+			changed++
+		}
+	}
+	return changed
+}
+
+// refresh advances the transfer from its source transaction and the provider's status,
+// alerting the owner when it fails or gets stuck, and reports whether it changed
+func (m *BridgeManager) refresh(ctx context.Context, transfer *BridgeTransfer) bool {
+	before := *transfer
+
+	if transfer.Status == BridgeAwaitingSource {
+		m.checkSource(ctx, transfer)
+	}
+	if transfer.SourceTxHash != "" && !transfer.Status.Terminal() {
+		if provider, ok := m.providers[transfer.Provider]; !ok {
+			m.logger.Warn(ctx, "Bridge transfer provider is not configured", map[string]interface{}{
+				"transfer_id": transfer.ID.String(),
+				"provider":    transfer.Provider,
+			})
+		} else if update, err := provider.Status(ctx, transfer); err != nil {
+			m.logger.Warn(ctx, "Failed to poll bridge transfer", map[string]interface{}{
+				"transfer_id": transfer.ID.String(),
+				"provider":    transfer.Provider,
+				"error":       err.Error(),
+			})
+		} else {
+			m.applyUpdate(transfer, update)
+		}
+	}
+	if transfer.Status.Terminal() && transfer.CompletedAt == nil {
+		completedAt := m.now()
+		transfer.CompletedAt = &completedAt
+	}
+	stuck := m.checkStuck(transfer)
+
+	changed := transfer.Status != before.Status || transfer.Substatus != before.Substatus ||
+		transfer.SourceTxHash != before.SourceTxHash || transfer.DestinationTxHash != before.DestinationTxHash ||
+		transfer.SourceTransactionID != before.SourceTransactionID || stuck
+	if !changed {
+		return false
+	}
+	transfer.UpdatedAt = m.now()
+	if err := m.transfers.Save(ctx, transfer); err != nil {
+		m.logger.Error(ctx, "Failed to save bridge transfer", err, map[string]interface{}{"transfer_id": transfer.ID.String()})
+		return false
+	}
+
+	if transfer.Status != before.Status {
+		switch transfer.Status {
+		case BridgeFailed:
+			m.alert(transfer, "Bridge transfer failed", alerts.SeverityCritical, "failed")
+		case BridgeRefunded:
+			m.alert(transfer, "Bridge transfer refunded", alerts.SeverityWarning, "refunded")
+		}
+	}
+	if stuck {
+		m.alert(transfer, "Bridge transfer stuck", alerts.SeverityWarning, "stuck")
+	}
+	return true
+}
+
+// checkSource follows the source transaction, or the proposal building it, until it is
+// broadcast or abandoned
+func (m *BridgeManager) checkSource(ctx context.Context, transfer *BridgeTransfer) {
+	if transfer.SourceTransactionID == nil && transfer.SourceProposalID != nil && m.service.multisig != nil {
+		proposal, err := m.service.multisig.store.GetProposal(ctx, *transfer.SourceProposalID)
+		if err != nil || proposal == nil {
+			return
+		}
+		switch {
+		case proposal.TransactionID != nil:
+			transfer.SourceTransactionID = proposal.TransactionID
+		case proposal.Status == ProposalRejected || proposal.Status == ProposalExpired:
+			transfer.Status = BridgeCancelled
+			transfer.Message = fmt.Sprintf("source transaction proposal %s", proposal.Status)
+			return
+		case proposal.Status == ProposalFailed:
+			transfer.Status = BridgeFailed
+			transfer.Message = "source transaction proposal failed: " + proposal.Error
+			return
+		}
+	}
+	if transfer.SourceTransactionID == nil {
+		return
+	}
+	tx, err := m.service.txRepo.GetByID(ctx, *transfer.SourceTransactionID)
+	if err != nil {
+		m.logger.Warn(ctx, "Failed to read bridge source transaction", map[string]interface{}{
+			"transfer_id": transfer.ID.String(),
+			"error":       err.Error(),
+		})
+		return
+	}
+	m.trackSource(transfer, tx)
+}
+
+// trackSource moves the transfer on from the state of its source transaction
+func (m *BridgeManager) trackSource(transfer *BridgeTransfer, tx *Transaction) {
+	switch tx.Status {
+	case TxStatusCancelled, TxStatusExpired:
+		transfer.Status = BridgeCancelled
+		transfer.Message = "source transaction was not signed"
+	case TxStatusFailed:
+		transfer.Status = BridgeFailed
+		transfer.Message = "source transaction failed"
+	case TxStatusPending, TxStatusConfirmed:
+		if tx.TxHash != "" {
+			broadcastAt := m.now()
+			transfer.SourceTxHash = tx.TxHash
+			transfer.SourceBroadcastAt = &broadcastAt
+			transfer.Status = BridgeSourcePending
+		}
+	}
+}
+
+func (m *BridgeManager) applyUpdate(transfer *BridgeTransfer, update *BridgeStatusUpdate) {
+	if update.Status != "" {
+		transfer.Status = update.Status
+	}
+	transfer.Substatus = update.Substatus
+	if update.Message != "" {
+		transfer.Message = update.Message
+	}
+	if update.DestinationTxHash != "" {
+		transfer.DestinationTxHash = update.DestinationTxHash
+	}
+	if update.ReceivedAmount != "" {
+		transfer.ReceivedAmount = update.ReceivedAmount
+	}
+	if update.ExplorerURL != "" {
+		transfer.ExplorerURL = update.ExplorerURL
+	}
+}
+
+// checkStuck flags a broadcast transfer that overran its deadline, reporting whether it
+// just became stuck
+func (m *BridgeManager) checkStuck(transfer *BridgeTransfer) bool {
+	if transfer.Stuck || transfer.Status.Terminal() || transfer.SourceBroadcastAt == nil {
+		return false
+	}
+	deadline := m.config.StuckAfter
+	if estimated := 3 * time.Duration(transfer.EstimatedDuration) * time.Second; estimated > deadline {
+		deadline = estimated
+	}
+	if m.now().Sub(*transfer.SourceBroadcastAt) < deadline {
+		return false
+	}
+	transfer.Stuck = true
+	return true
+}
+
+func (m *BridgeManager) alert(transfer *BridgeTransfer, title string, severity alerts.AlertSeverity, reason string) {
+	if m.alerts == nil {
+		return
+	}
+	token := transfer.FromToken.Symbol
+	if token == "" {
+		token = transfer.FromToken.Address
+	}
+	message := fmt.Sprintf("Bridging %s %s from %s to %s via %s is %s", transfer.FromAmount, token,
+		ChainName(transfer.FromChainID), ChainName(transfer.ToChainID), transfer.Bridge, reason)
+	if transfer.Message != "" {
+		message += ": " + transfer.Message
+	}
+
+	alert := m.alerts.CreateAlert(fmt.Sprintf("bridge_transfer:%s:%s", transfer.ID, reason), title, message, severity,
+		"bridge_transfer_"+reason, decimal.Zero, decimal.Zero, nil)
+	alert.UserID = &transfer.UserID
+	alert.Category = alerts.CategoryRiskAlerts
+	alert.Metadata["transfer_id"] = transfer.ID.String()
+	alert.Metadata["status"] = string(transfer.Status)
+	alert.Metadata["source_tx_hash"] = transfer.SourceTxHash
+	alert.Metadata["from_chain_id"] = transfer.FromChainID
+	alert.Metadata["to_chain_id"] = transfer.ToChainID
+	m.alerts.SendAlert(alert)
+}
+
+// bridgeTokenAddress normalizes a token, standing for the native token when empty
+func bridgeTokenAddress(token string) string {
+	if token == "" {
+		return NativeTokenAddress
+	}
+	return common.HexToAddress(token).Hex()
+}
+
+func isNativeToken(token string) bool {
+	return token == "" || common.HexToAddress(token) == (common.Address{}) ||
+		strings.EqualFold(token, "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+}
+
+// redisBridgeRouteCache keeps quoted routes in Redis until they expire
+type redisBridgeRouteCache struct {
+	redis *database.RedisClient
+}
+
+// NewRedisBridgeRouteCache creates a route cache shared through Redis
+func NewRedisBridgeRouteCache(redis *database.RedisClient) BridgeRouteCache {
+	return &redisBridgeRouteCache{redis: redis}
+}
+
+func bridgeRouteKey(userID, id uuid.UUID) string {
+	return "web3:bridge:route:" + userID.String() + ":" + id.String()
+}
+
+func (c *redisBridgeRouteCache) Save(ctx context.Context, userID uuid.UUID, route *BridgeRoute, ttl time.Duration) error {
+	data, err := json.Marshal(route)
+	if err != nil {
+		return err
+	}
+	return c.redis.Client.Set(ctx, bridgeRouteKey(userID, route.ID), data, ttl).Err()
+}
+
+func (c *redisBridgeRouteCache) Get(ctx context.Context, userID, id uuid.UUID) (*BridgeRoute, error) {
+	data, err := c.redis.Client.Get(ctx, bridgeRouteKey(userID, id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var route BridgeRoute
+	if err := json.Unmarshal(data, &route); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (c *redisBridgeRouteCache) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return c.redis.Client.Del(ctx, bridgeRouteKey(userID, id)).Err()
+}
+
+// postgresBridgeTransferStore keeps transfers in Postgres as JSON, with the columns they are
+// looked up by
+type postgresBridgeTransferStore struct {
+	db *database.DB
+}
+
+// NewPostgresBridgeTransferStore creates a bridge transfer store backed by Postgres
+func NewPostgresBridgeTransferStore(db *database.DB) BridgeTransferStore {
+	return &postgresBridgeTransferStore{db: db}
+}
+
+func (s *postgresBridgeTransferStore) Save(ctx context.Context, t *BridgeTransfer) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bridge transfer: %w", err)
+	}
+	query := `
+		INSERT INTO web3_bridge_transfers (id, user_id, status, data, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, data = EXCLUDED.data, updated_at = EXCLUDED.updated_at
+	`
+	_, err = s.db.ExecWithMetrics(ctx, query, t.ID, t.UserID, t.Status, data, t.CreatedAt, t.UpdatedAt)
+	return err
+}
+
+func (s *postgresBridgeTransferStore) Get(ctx context.Context, id uuid.UUID) (*BridgeTransfer, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM web3_bridge_transfers WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var transfer BridgeTransfer
+	if err := json.Unmarshal(data, &transfer); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bridge transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+func (s *postgresBridgeTransferStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*BridgeTransfer, error) {
+	return s.list(ctx, `SELECT data FROM web3_bridge_transfers WHERE user_id = $1 ORDER BY created_at DESC LIMIT 100`, userID)
+}
+
+func (s *postgresBridgeTransferStore) ListActive(ctx context.Context) ([]*BridgeTransfer, error) {
+	return s.list(ctx, `SELECT data FROM web3_bridge_transfers WHERE status IN ($1, $2, $3)`,
+		BridgeAwaitingSource, BridgeSourcePending, BridgeRelaying)
+}
+
+func (s *postgresBridgeTransferStore) list(ctx context.Context, query string, args ...any) ([]*BridgeTransfer, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*BridgeTransfer
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var transfer BridgeTransfer
+		if err := json.Unmarshal(data, &transfer); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bridge transfer: %w", err)
+		}
+		transfers = append(transfers, &transfer)
+	}
+	return transfers, rows.Err()
+}
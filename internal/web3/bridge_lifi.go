@@ -0,0 +1,260 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
+)
+
+// LiFiBridgeProvider quotes routes from the LI.FI aggregator, which compares bridges and DEXs
+// and returns the transaction executing the route it picks. The cheapest and the fastest
+// route are quoted, so users can trade cost for speed.
+type LiFiBridgeProvider struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURL    string // replaced in tests
+}
+
+// NewLiFiBridgeProvider creates a provider; the API key is optional and raises the rate limit
+func NewLiFiBridgeProvider(apiKey string) *LiFiBridgeProvider {
+	return &LiFiBridgeProvider{
+		httpClient: &http.Client{Timeout: 20 * time.Second},
+		apiKey:     apiKey,
+		baseURL:    "https://li.quest/v1",
+	}
+}
+
+func (p *LiFiBridgeProvider) Name() string {
+	return "lifi"
+}
+
+type lifiToken struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+type lifiQuote struct {
+	Tool        string `json:"tool"`
+	ToolDetails struct {
+		Name string `json:"name"`
+	} `json:"toolDetails"`
+	Action struct {
+		FromChainID int       `json:"fromChainId"`
+		ToChainID   int       `json:"toChainId"`
+		FromToken   lifiToken `json:"fromToken"`
+		ToToken     lifiToken `json:"toToken"`
+		FromAddress string    `json:"fromAddress"`
+		ToAddress   string    `json:"toAddress"`
+	} `json:"action"`
+	Estimate struct {
+		FromAmount        string  `json:"fromAmount"`
+		ToAmount          string  `json:"toAmount"`
+		ToAmountMin       string  `json:"toAmountMin"`
+		ApprovalAddress   string  `json:"approvalAddress"`
+		ExecutionDuration float64 `json:"executionDuration"`
+		FeeCosts          []struct {
+			Name      string    `json:"name"`
+			Amount    string    `json:"amount"`
+			AmountUSD string    `json:"amountUSD"`
+			Included  bool      `json:"included"`
+			Token     lifiToken `json:"token"`
+		} `json:"feeCosts"`
+		GasCosts []struct {
+			AmountUSD string `json:"amountUSD"`
+		} `json:"gasCosts"`
+	} `json:"estimate"`
+	IncludedSteps []struct {
+		Type string `json:"type"`
+		Tool string `json:"tool"`
+	} `json:"includedSteps"`
+	TransactionRequest struct {
+		To       string `json:"to"`
+		Data     string `json:"data"`
+		Value    string `json:"value"`
+		GasLimit string `json:"gasLimit"`
+	} `json:"transactionRequest"`
+}
+
+// Quote asks for the cheapest and the fastest route; when both are the same route it is
+// returned once
+func (p *LiFiBridgeProvider) Quote(ctx context.Context, req BridgeRouteRequest) ([]BridgeRoute, error) {
+	var routes []BridgeRoute
+	seen := make(map[string]bool)
+	for _, order := range []string{"CHEAPEST", "FASTEST"} {
+		params := url.Values{}
+		params.Set("fromChain", strconv.Itoa(req.FromChainID))
+		params.Set("toChain", strconv.Itoa(req.ToChainID))
+		params.Set("fromToken", req.FromToken)
+		params.Set("toToken", req.ToToken)
+		params.Set("fromAmount", req.Amount.String())
+		params.Set("fromAddress", req.FromAddress)
+		params.Set("toAddress", req.ToAddress)
+		params.Set("slippage", decimal.NewFromInt(int64(req.SlippageBps)).Div(decimal.NewFromInt(10_000)).String())
+		params.Set("order", order)
+
+		var quote lifiQuote
+		if err := p.get(ctx, "/quote?"+params.Encode(), &quote); err != nil {
+			// The other order may still find a route
+			if len(routes) > 0 {
+				break
+			}
+			return nil, err
+		}
+		key := quote.Tool + ":" + quote.Estimate.ToAmount
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		route, err := p.route(quote)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, *route)
+	}
+	return routes, nil
+}
+
+func (p *LiFiBridgeProvider) route(quote lifiQuote) (*BridgeRoute, error) {
+	value := new(big.Int)
+	if quote.TransactionRequest.Value != "" {
+		var err error
+		if value, err = hexutil.DecodeBig(quote.TransactionRequest.Value); err != nil {
+			return nil, fmt.Errorf("invalid lifi transaction value %q: %w", quote.TransactionRequest.Value, err)
+		}
+	}
+	var gasLimit uint64
+	if quote.TransactionRequest.GasLimit != "" {
+		var err error
+		if gasLimit, err = hexutil.DecodeUint64(quote.TransactionRequest.GasLimit); err != nil {
+			return nil, fmt.Errorf("invalid lifi gas limit %q: %w", quote.TransactionRequest.GasLimit, err)
+		}
+	}
+
+	route := &BridgeRoute{
+		Bridge:            quote.Tool,
+		BridgeName:        quote.ToolDetails.Name,
+		FromChainID:       quote.Action.FromChainID,
+		ToChainID:         quote.Action.ToChainID,
+		FromToken:         BridgeToken(quote.Action.FromToken),
+		ToToken:           BridgeToken(quote.Action.ToToken),
+		FromAddress:       quote.Action.FromAddress,
+		ToAddress:         quote.Action.ToAddress,
+		FromAmount:        quote.Estimate.FromAmount,
+		ToAmount:          quote.Estimate.ToAmount,
+		ToAmountMin:       quote.Estimate.ToAmountMin,
+		Fees:              []BridgeFee{},
+		EstimatedDuration: int(quote.Estimate.ExecutionDuration),
+		ApprovalAddress:   quote.Estimate.ApprovalAddress,
+		Transaction: &BridgeTransactionRequest{
+			To:       quote.TransactionRequest.To,
+			Data:     quote.TransactionRequest.Data,
+			Value:    value,
+			GasLimit: gasLimit,
+		},
+	}
+	for _, fee := range quote.Estimate.FeeCosts {
+		amountUSD, _ := decimal.NewFromString(fee.AmountUSD)
+		route.Fees = append(route.Fees, BridgeFee{
+			Name:      fee.Name,
+			Token:     fee.Token.Symbol,
+			Amount:    fee.Amount,
+			AmountUSD: amountUSD,
+			Included:  fee.Included,
+		})
+		route.FeeUSD = route.FeeUSD.Add(amountUSD)
+	}
+	for _, gas := range quote.Estimate.GasCosts {
+		amountUSD, _ := decimal.NewFromString(gas.AmountUSD)
+		route.GasCostUSD = route.GasCostUSD.Add(amountUSD)
+	}
+	for _, step := range quote.IncludedSteps {
+		route.Steps = append(route.Steps, step.Type+":"+step.Tool)
+	}
+	return route, nil
+}
+
+// Status maps LI.FI's transfer status: the source transaction is found and confirmed, the
+// destination transaction is awaited, and the transfer ends done, refunded or failed
+func (p *LiFiBridgeProvider) Status(ctx context.Context, transfer *BridgeTransfer) (*BridgeStatusUpdate, error) {
+	params := url.Values{}
+	params.Set("txHash", transfer.SourceTxHash)
+	params.Set("bridge", transfer.Bridge)
+	params.Set("fromChain", strconv.Itoa(transfer.FromChainID))
+	params.Set("toChain", strconv.Itoa(transfer.ToChainID))
+
+	var body struct {
+		Status           string `json:"status"`
+		Substatus        string `json:"substatus"`
+		SubstatusMessage string `json:"substatusMessage"`
+		Receiving        struct {
+			TxHash string `json:"txHash"`
+			Amount string `json:"amount"`
+		} `json:"receiving"`
+		ExplorerLink string `json:"lifiExplorerLink"`
+	}
+	if err := p.get(ctx, "/status?"+params.Encode(), &body); err != nil {
+		return nil, err
+	}
+
+	update := &BridgeStatusUpdate{
+		Substatus:         strings.ToLower(body.Substatus),
+		Message:           body.SubstatusMessage,
+		DestinationTxHash: body.Receiving.TxHash,
+		ReceivedAmount:    body.Receiving.Amount,
+		ExplorerURL:       body.ExplorerLink,
+	}
+	switch body.Status {
+	case "DONE":
+		update.Status = BridgeCompleted
+		if body.Substatus == "REFUNDED" {
+			update.Status = BridgeRefunded
+		}
+	case "FAILED", "INVALID":
+		update.Status = BridgeFailed
+	case "PENDING":
+		update.Status = BridgeRelaying
+		if body.Substatus == "WAIT_SOURCE_CONFIRMATIONS" {
+			update.Status = BridgeSourcePending
+		}
+	default: // NOT_FOUND: the source transaction isn't indexed yet
+		update.Status = BridgeSourcePending
+	}
+	return update, nil
+}
+
+func (p *LiFiBridgeProvider) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("x-lifi-api-key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("lifi error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid lifi response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryBridgeRouteCache struct {
+	mu     sync.Mutex
+	routes map[[2]uuid.UUID]BridgeRoute
+}
+
+func (c *memoryBridgeRouteCache) Save(ctx context.Context, userID uuid.UUID, route *BridgeRoute, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.routes[[2]uuid.UUID{userID, route.ID}] = *route
+	return nil
+}
+
+func (c *memoryBridgeRouteCache) Get(ctx context.Context, userID, id uuid.UUID) (*BridgeRoute, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	route, ok := c.routes[[2]uuid.UUID{userID, id}]
+	if !ok {
+		return nil, nil
+	}
+	return &route, nil
+}
+
+func (c *memoryBridgeRouteCache) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.routes, [2]uuid.UUID{userID, id})
+	return nil
+}
+
+type memoryBridgeTransferStore struct {
+	mu        sync.Mutex
+	transfers map[uuid.UUID]BridgeTransfer
+}
+
+func (s *memoryBridgeTransferStore) Save(ctx context.Context, transfer *BridgeTransfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transfers[transfer.ID] = *transfer
+	return nil
+}
+
+func (s *memoryBridgeTransferStore) Get(ctx context.Context, id uuid.UUID) (*BridgeTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfer, ok := s.transfers[id]
+	if !ok {
+		return nil, nil
+	}
+	return &transfer, nil
+}
+
+func (s *memoryBridgeTransferStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*BridgeTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var transfers []*BridgeTransfer
+	for _, transfer := range s.transfers {
+		transfer := transfer
+		if transfer.UserID == userID {
+			transfers = append(transfers, &transfer)
+		}
+	}
+	return transfers, nil
+}
+
+func (s *memoryBridgeTransferStore) ListActive(ctx context.Context) ([]*BridgeTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var transfers []*BridgeTransfer
+	for _, transfer := range s.transfers {
+		transfer := transfer
+		if !transfer.Status.Terminal() {
+			transfers = append(transfers, &transfer)
+		}
+	}
+	return transfers, nil
+}
+
+type fakeBridgeProvider struct {
+	name     string
+	routes   []BridgeRoute
+	quoteErr error
+	status   BridgeStatusUpdate
+	requests []BridgeRouteRequest
+}
+
+func (p *fakeBridgeProvider) Name() string { return p.name }
+
+func (p *fakeBridgeProvider) Quote(ctx context.Context, req BridgeRouteRequest) ([]BridgeRoute, error) {
+	p.requests = append(p.requests, req)
+	return p.routes, p.quoteErr
+}
+
+func (p *fakeBridgeProvider) Status(ctx context.Context, transfer *BridgeTransfer) (*BridgeStatusUpdate, error) {
+	update := p.status
+	return &update, nil
+}
+
+type fakeAllowances struct {
+	allowance *big.Int
+}
+
+func (f fakeAllowances) Allowance(ctx context.Context, chainID int, token, owner, spender string) (*big.Int, error) {
+	return f.allowance, nil
+}
+
+// bridgeTxRepo reads back the status and hash the signing queue recorded
+type bridgeTxRepo struct {
+	*statusTxRepo
+}
+
+func (r bridgeTxRepo) GetByID(ctx context.Context, id uuid.UUID) (*Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status, ok := r.statuses[id]
+	if !ok {
+		status = TxStatusAwaitingSignature
+	}
+	return &Transaction{ID: id, Status: status, TxHash: r.hashes[id]}, nil
+}
+
+type bridgeFixture struct {
+	*signingFixture
+	manager   *BridgeManager
+	provider  *fakeBridgeProvider
+	routes    *memoryBridgeRouteCache
+	transfers *memoryBridgeTransferStore
+	alerts    *alerts.AlertService
+}
+
+const bridgeContract = "0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE"
+
+func newBridgeFixture(t *testing.T) *bridgeFixture {
+	t.Helper()
+	sf := newSigningFixture(t)
+	sf.service.txRepo = bridgeTxRepo{sf.txRepo}
+
+	f := &bridgeFixture{
+		signingFixture: sf,
+		provider: &fakeBridgeProvider{name: "fake", routes: []BridgeRoute{{
+			Bridge:            "stargate",
+			FromChainID:       1,
+			ToChainID:         137,
+			FromToken:         BridgeToken{Address: NativeTokenAddress, Symbol: "ETH", Decimals: 18},
+			ToToken:           BridgeToken{Address: NativeTokenAddress, Symbol: "POL", Decimals: 18},
+			FromAmount:        "1000000000000000000",
+			ToAmount:          "990000000000000000",
+			ToAmountMin:       "985000000000000000",
+			FeeUSD:            decimal.NewFromInt(3),
+			EstimatedDuration: 600,
+			Transaction:       &BridgeTransactionRequest{To: bridgeContract, Data: "0xabcdef", Value: big.NewInt(1_000_000_000_000_000_000), GasLimit: 250_000},
+		}}},
+		routes:    &memoryBridgeRouteCache{routes: make(map[[2]uuid.UUID]BridgeRoute)},
+		transfers: &memoryBridgeTransferStore{transfers: make(map[uuid.UUID]BridgeTransfer)},
+		alerts:    alerts.NewAlertService(sf.service.logger, alerts.NewAlertConfig(config.AlertsConfig{})),
+	}
+	f.manager = NewBridgeManager(sf.service, f.routes, f.transfers, BridgeConfig{StuckAfter: time.Hour}, f.provider)
+	f.manager.allowances = fakeAllowances{allowance: new(big.Int)}
+	f.manager.now = func() time.Time { return f.now }
+	f.manager.SetAlertService(f.alerts)
+	return f
+}
+
+func (f *bridgeFixture) quote(t *testing.T) *BridgeQuote {
+	t.Helper()
+	quote, err := f.manager.Quote(context.Background(), f.wallet.UserID, BridgeQuoteRequest{
+		WalletID:  f.wallet.ID,
+		ToChainID: 137,
+		Amount:    big.NewInt(1_000_000_000_000_000_000),
+	})
+	require.NoError(t, err)
+	return quote
+}
+
+// broadcast signs and submits the transfer's source transaction
+func (f *bridgeFixture) broadcast(t *testing.T, execution *BridgeExecution) {
+	t.Helper()
+	request := execution.Transaction.SigningRequest
+	_, err := f.queue.Submit(context.Background(), f.wallet.UserID, request.ID, f.sign(t, f.key, request, nil))
+	require.NoError(t, err)
+}
+
+func TestBridgeQuote_ComparesProviders(t *testing.T) {
+	f := newBridgeFixture(t)
+	slow := f.provider.routes[0]
+	slow.Bridge, slow.ToAmount, slow.EstimatedDuration = "hop", "995000000000000000", 1800
+	f.provider.routes = append(f.provider.routes, slow)
+	failing := &fakeBridgeProvider{name: "down", quoteErr: errors.New("unavailable")}
+	f.manager = NewBridgeManager(f.service, f.routes, f.transfers, BridgeConfig{QuoteTTL: time.Minute}, f.provider, failing)
+	f.manager.now = func() time.Time { return f.now }
+
+	quote := f.quote(t)
+	require.Len(t, quote.Routes, 2)
+	assert.Equal(t, "hop", quote.Routes[0].Bridge)
+	assert.Equal(t, quote.Routes[0].ID, quote.BestRouteID)
+	assert.Equal(t, quote.Routes[1].ID, quote.FastestRouteID)
+	assert.Equal(t, "unavailable", quote.Errors["down"])
+	assert.Equal(t, f.now.Add(time.Minute), quote.Routes[0].ExpiresAt)
+
+	// Routes leave from the wallet's chain and arrive at its address by default
+	req := f.provider.requests[0]
+	assert.Equal(t, 1, req.FromChainID)
+	assert.Equal(t, NativeTokenAddress, req.FromToken)
+	assert.Equal(t, common.HexToAddress(f.wallet.Address).Hex(), req.ToAddress)
+	assert.Equal(t, 50, req.SlippageBps)
+
+	cached, err := f.routes.Get(context.Background(), f.wallet.UserID, quote.BestRouteID)
+	require.NoError(t, err)
+	assert.NotNil(t, cached)
+}
+
+func TestBridgeQuote_Rejects(t *testing.T) {
+	f := newBridgeFixture(t)
+	ctx := context.Background()
+
+	_, err := f.manager.Quote(ctx, f.wallet.UserID, BridgeQuoteRequest{WalletID: f.wallet.ID, ToChainID: 1, Amount: big.NewInt(1)})
+	var fieldErrors validation.FieldErrors
+	assert.ErrorAs(t, err, &fieldErrors)
+
+	_, err = f.manager.Quote(ctx, uuid.New(), BridgeQuoteRequest{WalletID: f.wallet.ID, ToChainID: 137, Amount: big.NewInt(1)})
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+
+	f.provider.routes = nil
+	_, err = f.manager.Quote(ctx, f.wallet.UserID, BridgeQuoteRequest{WalletID: f.wallet.ID, ToChainID: 137, Amount: big.NewInt(1)})
+	assert.ErrorIs(t, err, ErrNoBridgeRoutes)
+}
+
+func TestBridgeExecute_TracksLifecycle(t *testing.T) {
+	f := newBridgeFixture(t)
+	ctx := context.Background()
+	quote := f.quote(t)
+
+	execution, err := f.manager.Execute(ctx, f.wallet.UserID, BridgeExecuteRequest{RouteID: quote.BestRouteID})
+	require.NoError(t, err)
+	assert.Nil(t, execution.Approval, "native transfers need no approval")
+	require.NotNil(t, execution.Transaction.SigningRequest)
+	assert.Equal(t, common.HexToAddress(bridgeContract).Hex(), execution.Transaction.SigningRequest.Transaction.To)
+	assert.Equal(t, uint64(250_000), execution.Transaction.SigningRequest.Transaction.GasLimit)
+	transfer := execution.Transfer
+	assert.Equal(t, BridgeAwaitingSource, transfer.Status)
+	assert.Equal(t, execution.Transaction.Transaction.ID, *transfer.SourceTransactionID)
+
+	// Routes are executed once
+	_, err = f.manager.Execute(ctx, f.wallet.UserID, BridgeExecuteRequest{RouteID: quote.BestRouteID})
+	assert.ErrorIs(t, err, ErrBridgeRouteNotFound)
+
+	assert.Equal(t, 0, f.manager.PollTransfers(ctx))
+	f.broadcast(t, execution)
+	f.provider.status = BridgeStatusUpdate{Status: BridgeRelaying, Substatus: "wait_destination_transaction"}
+	assert.Equal(t, 1, f.manager.PollTransfers(ctx))
+
+	transfer, err = f.manager.GetTransfer(ctx, f.wallet.UserID, transfer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BridgeRelaying, transfer.Status)
+	assert.NotEmpty(t, transfer.SourceTxHash)
+	assert.Equal(t, f.now, *transfer.SourceBroadcastAt)
+
+	f.provider.status = BridgeStatusUpdate{Status: BridgeCompleted, DestinationTxHash: "0xdest", ReceivedAmount: "989000000000000000"}
+	transfer, err = f.manager.GetTransfer(ctx, f.wallet.UserID, transfer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BridgeCompleted, transfer.Status)
+	assert.Equal(t, "0xdest", transfer.DestinationTxHash)
+	assert.NotNil(t, transfer.CompletedAt)
+	assert.Empty(t, f.alerts.GetAlerts(10))
+
+	_, err = f.manager.GetTransfer(ctx, uuid.New(), transfer.ID)
+	assert.ErrorIs(t, err, ErrBridgeTransferNotFound)
+}
+
+func TestBridgeExecute_ApprovesERC20Source(t *testing.T) {
+	f := newBridgeFixture(t)
+	ctx := context.Background()
+	usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	route := &f.provider.routes[0]
+	route.FromToken = BridgeToken{Address: usdc, Symbol: "USDC", Decimals: 6}
+	route.FromAmount = "250000000"
+	route.ApprovalAddress = bridgeContract
+	route.Transaction.Value = new(big.Int)
+
+	execution, err := f.manager.Execute(ctx, f.wallet.UserID, BridgeExecuteRequest{RouteID: f.quote(t).BestRouteID})
+	require.NoError(t, err)
+	require.NotNil(t, execution.Approval)
+	approval := execution.Approval.SigningRequest.Transaction
+	assert.Equal(t, usdc, approval.To)
+	data := hexutil.MustDecode(approval.Data)
+	assert.Equal(t, approveSelector, data[:4])
+	assert.Equal(t, common.HexToAddress(bridgeContract), common.BytesToAddress(data[4:36]))
+	assert.Equal(t, big.NewInt(250_000_000), new(big.Int).SetBytes(data[36:]))
+
+	// An allowance covering the amount needs no approval
+	f.manager.allowances = fakeAllowances{allowance: big.NewInt(250_000_000)}
+	execution, err = f.manager.Execute(ctx, f.wallet.UserID, BridgeExecuteRequest{RouteID: f.quote(t).BestRouteID})
+	require.NoError(t, err)
+	assert.Nil(t, execution.Approval)
+}
+
+func TestBridgeTransfer_AlertsWhenStuckOrFailed(t *testing.T) {
+	f := newBridgeFixture(t)
+	ctx := context.Background()
+	execution, err := f.manager.Execute(ctx, f.wallet.UserID, BridgeExecuteRequest{RouteID: f.quote(t).BestRouteID})
+	require.NoError(t, err)
+	f.broadcast(t, execution)
+	f.provider.status = BridgeStatusUpdate{Status: BridgeRelaying}
+	f.manager.PollTransfers(ctx)
+
+	// Stuck past the deadline: an hour, as three times the 10 minute estimate is shorter
+	f.now = f.now.Add(59 * time.Minute)
+	f.manager.PollTransfers(ctx)
+	assert.Empty(t, f.alerts.GetAlerts(10))
+	f.now = f.now.Add(2 * time.Minute)
+	assert.Equal(t, 1, f.manager.PollTransfers(ctx))
+	transfer, err := f.manager.GetTransfer(ctx, f.wallet.UserID, execution.Transfer.ID)
+	require.NoError(t, err)
+	assert.True(t, transfer.Stuck)
+	require.Len(t, f.alerts.GetAlerts(10), 1)
+	assert.Equal(t, 0, f.manager.PollTransfers(ctx), "stuck transfers alert once")
+
+	f.provider.status = BridgeStatusUpdate{Status: BridgeFailed, Message: "destination reverted"}
+	f.manager.PollTransfers(ctx)
+	sent := f.alerts.GetAlerts(10)
+	require.Len(t, sent, 2)
+	var failed *alerts.Alert
+	for i := range sent {
+		if sent[i].Severity == alerts.SeverityCritical {
+			failed = &sent[i]
+		}
+	}
+	require.NotNil(t, failed)
+	assert.Equal(t, f.wallet.UserID, *failed.UserID)
+	assert.Contains(t, failed.Message, "destination reverted")
+}
+
+func TestBridgeTransfer_CancelledWhenNotSigned(t *testing.T) {
+	f := newBridgeFixture(t)
+	ctx := context.Background()
+	execution, err := f.manager.Execute(ctx, f.wallet.UserID, BridgeExecuteRequest{RouteID: f.quote(t).BestRouteID})
+	require.NoError(t, err)
+
+	_, err = f.queue.Cancel(ctx, f.wallet.UserID, execution.Transaction.SigningRequest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.manager.PollTransfers(ctx))
+
+	transfer, err := f.manager.GetTransfer(ctx, f.wallet.UserID, execution.Transfer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BridgeCancelled, transfer.Status)
+	assert.Empty(t, f.alerts.GetAlerts(10))
+}
+
+func TestLiFiBridgeProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("x-lifi-api-key"))
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/quote":
+			assert.Equal(t, "1", query.Get("fromChain"))
+			assert.Equal(t, "137", query.Get("toChain"))
+			assert.Equal(t, "0.005", query.Get("slippage"))
+			tool, amount := "stargate", "990"
+			if query.Get("order") == "FASTEST" {
+				tool, amount = "across", "985"
+			}
+			w.Write([]byte(`{
+				"tool": "` + tool + `", "toolDetails": {"name": "Bridge"},
+				"action": {"fromChainId": 1, "toChainId": 137,
+					"fromToken": {"address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "symbol": "USDC", "decimals": 6},
+					"toToken": {"address": "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", "symbol": "USDC", "decimals": 6}},
+				"estimate": {"fromAmount": "1000", "toAmount": "` + amount + `", "toAmountMin": "980",
+					"approvalAddress": "0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE", "executionDuration": 120.5,
+					"feeCosts": [{"name": "LP fee", "amount": "5", "amountUSD": "0.005", "included": true, "token": {"symbol": "USDC"}}],
+					"gasCosts": [{"amountUSD": "1.25"}]},
+				"includedSteps": [{"type": "cross", "tool": "` + tool + `"}],
+				"transactionRequest": {"to": "0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE", "data": "0xabcd", "value": "0x0", "gasLimit": "0x3d090"}
+			}`))
+		case "/status":
+			assert.Equal(t, "0xsource", query.Get("txHash"))
+			w.Write([]byte(`{"status": "DONE", "substatus": "REFUNDED", "receiving": {"txHash": "0xrefund"}}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewLiFiBridgeProvider("key")
+	provider.baseURL = server.URL
+
+	routes, err := provider.Quote(context.Background(), BridgeRouteRequest{
+		FromChainID: 1, ToChainID: 137, FromToken: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		ToToken: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", Amount: big.NewInt(1000), SlippageBps: 50,
+	})
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	route := routes[0]
+	assert.Equal(t, "stargate", route.Bridge)
+	assert.Equal(t, "990", route.ToAmount)
+	assert.Equal(t, 120, route.EstimatedDuration)
+	assert.True(t, decimal.RequireFromString("0.005").Equal(route.FeeUSD))
+	assert.True(t, decimal.RequireFromString("1.25").Equal(route.GasCostUSD))
+	assert.Equal(t, []string{"cross:stargate"}, route.Steps)
+	assert.Equal(t, uint64(250_000), route.Transaction.GasLimit)
+	assert.Equal(t, 0, route.Transaction.Value.Sign())
+
+	update, err := provider.Status(context.Background(), &BridgeTransfer{SourceTxHash: "0xsource", Bridge: "stargate", FromChainID: 1, ToChainID: 137})
+	require.NoError(t, err)
+	assert.Equal(t, BridgeRefunded, update.Status)
+	assert.Equal(t, "0xrefund", update.DestinationTxHash)
+}
@@ -13,6 +13,11 @@ import (
 	"github.com/ai-agentic-browser/pkg/database"
 )
 
+// PriceSource provides market prices keyed by CoinGecko token ID
+type PriceSource interface {
+	GetPrices(ctx context.Context, currency string, tokenIds []string) (map[string]TokenPrice, error)
+}
+
 // CoinGeckoClient fetches market prices with Redis caching and simple rate limiting.
 type CoinGeckoClient struct {
 	httpClient *http.Client
@@ -3,6 +3,7 @@ package web3
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
@@ -16,6 +17,10 @@ type DeFiProtocolManager struct {
 	protocols map[string]*DeFiProtocol
 	positions map[uuid.UUID]*DeFiPosition
 	config    DeFiConfig
+
+	sources     map[string]YieldSource
+	sourceStats map[string]*YieldSourceStats
+	mu          sync.RWMutex
 }
 
 // DeFiConfig holds configuration for DeFi operations
@@ -108,6 +113,18 @@ type DeFiPosition struct {
 	CreatedAt       time.Time              `json:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at"`
 	Metadata        map[string]interface{} `json:"metadata"`
+
+	// Liquidity pool entry data for LP analytics, zero when unknown
+	EntryAmountA decimal.Decimal `json:"entry_amount_a"`
+	EntryAmountB decimal.Decimal `json:"entry_amount_b"`
+	EntryPriceA  decimal.Decimal `json:"entry_price_a"` // USD
+	EntryPriceB  decimal.Decimal `json:"entry_price_b"` // USD
+	PriceLower   decimal.Decimal `json:"price_lower"`   // concentrated range in token B per token A, zero for full range
+	PriceUpper   decimal.Decimal `json:"price_upper"`
+	FeesEarned   decimal.Decimal `json:"fees_earned"` // USD
+
+	// Network of the wallet holding the position; empty means mainnet
+	Network string `json:"network,omitempty"`
 }
 
 // PositionType represents different types of DeFi positions
@@ -161,6 +178,9 @@ func NewDeFiProtocolManager(logger *observability.Logger) *DeFiProtocolManager {
 		protocols: make(map[string]*DeFiProtocol),
 		positions: make(map[uuid.UUID]*DeFiPosition),
 		config:    config,
+
+		sources:     make(map[string]YieldSource),
+		sourceStats: make(map[string]*YieldSourceStats),
 	}
 
 	// Initialize supported protocols
@@ -285,11 +305,21 @@ func (d *DeFiProtocolManager) initializePools() {
 
 // GetProtocols returns all available protocols
 func (d *DeFiProtocolManager) GetProtocols() map[string]*DeFiProtocol {
-	return d.protocols
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	protocols := make(map[string]*DeFiProtocol, len(d.protocols))
+	for id, protocol := range d.protocols {
+		protocols[id] = protocol
+	}
+	return protocols
 }
 
 // GetProtocol returns a specific protocol by ID
 func (d *DeFiProtocolManager) GetProtocol(protocolID string) (*DeFiProtocol, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
 	protocol, exists := d.protocols[protocolID]
 	if !exists {
 		return nil, fmt.Errorf("protocol not found: %s", protocolID)
@@ -297,8 +327,43 @@ func (d *DeFiProtocolManager) GetProtocol(protocolID string) (*DeFiProtocol, err
 	return protocol, nil
 }
 
+// TrackPosition records a position opened through a protocol interaction, replacing any
+// earlier copy with the same ID
+func (d *DeFiProtocolManager) TrackPosition(position *DeFiPosition) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tracked := *position
+	d.positions[position.ID] = &tracked
+}
+
+// GetUserPositions returns copies of the positions tracked by the manager for a user
+func (d *DeFiProtocolManager) GetUserPositions(userID uuid.UUID) []*DeFiPosition {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	positions := make([]*DeFiPosition, 0)
+	for _, position := range d.positions {
+		if position.UserID == userID {
+			copied := *position
+			positions = append(positions, &copied)
+		}
+	}
+	return positions
+}
+
 // GetBestYieldOpportunities finds the best yield opportunities based on criteria
 func (d *DeFiProtocolManager) GetBestYieldOpportunities(ctx context.Context, minAPY decimal.Decimal, maxRisk RiskLevel) ([]*YieldOpportunity, error) {
+	return d.GetYieldOpportunities(ctx, YieldOpportunityQuery{MinAPY: minAPY, MaxRisk: maxRisk})
+}
+
+// GetYieldOpportunities finds the yield opportunities matching the query, best APY first.
+// Pools of protocols with a live source are only included while their last fetch succeeded.
+func (d *DeFiProtocolManager) GetYieldOpportunities(ctx context.Context, query YieldOpportunityQuery) ([]*YieldOpportunity, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now()
 	var opportunities []*YieldOpportunity
 
 	for _, protocol := range d.protocols {
@@ -306,19 +371,32 @@ func (d *DeFiProtocolManager) GetBestYieldOpportunities(ctx context.Context, min
 			continue
 		}
 
+		source := YieldSourceStatic
+		if _, live := d.sources[protocol.ID]; live {
+			stats := d.sourceStats[protocol.ID]
+			if stats == nil || stats.LastSuccess == nil || stats.LastError != "" {
+				continue
+			}
+			source = YieldSourceLive
+		}
+
 		// Check protocol risk level
 		protocolRisk := d.getRiskLevelFromScore(protocol.RiskScore)
-		if d.isRiskHigher(protocolRisk, maxRisk) {
+		if d.isRiskHigher(protocolRisk, query.MaxRisk) {
 			continue
 		}
 
 		for _, pool := range protocol.Pools {
-			if !pool.IsActive || pool.APY.LessThan(minAPY) {
+			if !pool.IsActive || pool.APY.LessThan(query.MinAPY) {
 				continue
 			}
 
 			// Check pool risk level
-			if d.isRiskHigher(pool.RiskLevel, maxRisk) {
+			if d.isRiskHigher(pool.RiskLevel, query.MaxRisk) {
+				continue
+			}
+
+			if query.MaxAge > 0 && now.Sub(pool.LastUpdated) > query.MaxAge {
 				continue
 			}
 
@@ -334,6 +412,9 @@ func (d *DeFiProtocolManager) GetBestYieldOpportunities(ctx context.Context, min
 				TokenB:          pool.TokenB,
 				Fees:            protocol.Fees,
 				ImpermanentLoss: pool.ImpermanentLoss,
+				Source:          source,
+				UpdatedAt:       pool.LastUpdated,
+				AgeSeconds:      int64(now.Sub(pool.LastUpdated).Seconds()),
 			}
 
 			opportunities = append(opportunities, opportunity)
@@ -365,6 +446,17 @@ type YieldOpportunity struct {
 	TokenB          string          `json:"token_b"`
 	Fees            decimal.Decimal `json:"fees"`
 	ImpermanentLoss decimal.Decimal `json:"impermanent_loss"`
+
+	Source     string    `json:"source"`      // "live" or "static"
+	UpdatedAt  time.Time `json:"updated_at"`  // when the pool data was fetched
+	AgeSeconds int64     `json:"age_seconds"` // data age when the opportunity was listed
+}
+
+// YieldOpportunityQuery filters yield opportunities
+type YieldOpportunityQuery struct {
+	MinAPY  decimal.Decimal
+	MaxRisk RiskLevel
+	MaxAge  time.Duration // exclude pools whose data is older, zero for no limit
 }
 
 // getRiskLevelFromScore converts risk score to risk level
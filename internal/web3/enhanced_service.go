@@ -2,6 +2,8 @@ package web3
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -15,10 +17,14 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrWalletAccessDenied is returned for a wallet of another user where its existence is not hidden
+var ErrWalletAccessDenied = errors.New("wallet belongs to another user")
+
 // EnhancedService provides advanced Web3 and cryptocurrency functionality
 type EnhancedService struct {
 	db           *database.DB
 	redis        *database.RedisClient
+	walletRepo   WalletRepository
 	config       config.Web3Config
 	logger       *observability.Logger
 	clients      map[int]*ethclient.Client
@@ -26,6 +32,8 @@ type EnhancedService struct {
 	ipfsService  *IPFSService
 	ensResolver  *ENSResolver
 	defiManager  *DeFiProtocolManager
+	screener     *TransactionScreener
+	multisig     *MultisigCoordinator
 }
 
 // EnhancedTransactionRequest represents an enhanced transaction request
@@ -37,6 +45,16 @@ type EnhancedTransactionRequest struct {
 	GasStrategy GasStrategy            `json:"gas_strategy,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	SimulateTx  bool                   `json:"simulate_tx,omitempty"`
+	// SimulateFirst aborts with a SimulationError instead of creating a reverting transaction
+	SimulateFirst bool `json:"simulate_first,omitempty"`
+
+	// EIP-1559 fees; unset fees are suggested from FeeTier when it is given
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+	FeeTier              FeeTier  `json:"fee_tier,omitempty"`
+
+	// AcknowledgeRisk lets a transaction that screening warned about proceed
+	AcknowledgeRisk bool `json:"acknowledge_risk,omitempty"`
 }
 
 // TransactionSimulation represents a transaction simulation result
@@ -48,6 +66,11 @@ type TransactionSimulation struct {
 	Revert        string                 `json:"revert,omitempty"`
 	Traces        []string               `json:"traces,omitempty"`
 	StateChanges  map[string]interface{} `json:"state_changes,omitempty"`
+
+	// Decoded revert and the sender's balance changes where they can be determined
+	RevertReason   string          `json:"revert_reason,omitempty"`
+	RevertData     string          `json:"revert_data,omitempty"`
+	BalanceChanges []BalanceChange `json:"balance_changes,omitempty"`
 }
 
 // NewEnhancedService creates a new enhanced Web3 service
@@ -112,6 +135,7 @@ func NewEnhancedService(db *database.DB, redis *database.RedisClient, cfg config
 	return &EnhancedService{
 		db:           db,
 		redis:        redis,
+		walletRepo:   NewPostgresWalletRepository(db),
 		config:       cfg,
 		logger:       logger,
 		clients:      clients,
@@ -122,6 +146,17 @@ func NewEnhancedService(db *database.DB, redis *database.RedisClient, cfg config
 	}, nil
 }
 
+// SetTransactionScreener screens the recipients of enhanced transactions before they are created
+func (s *EnhancedService) SetTransactionScreener(screener *TransactionScreener) {
+	s.screener = screener
+}
+
+// SetMultisigCoordinator makes enhanced transactions of multisig wallets wait for their
+// members' approvals like other transactions
+func (s *EnhancedService) SetMultisigCoordinator(coordinator *MultisigCoordinator) {
+	s.multisig = coordinator
+}
+
 // GetClients returns the map of blockchain clients
 func (s *EnhancedService) GetClients() map[int]*ethclient.Client {
 	return s.clients
@@ -132,13 +167,29 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("enhanced-web3-service").Start(ctx, "web3.CreateEnhancedTransaction")
 	defer span.End()
 
+	// Multisig wallets only move funds once their members approve; the approved proposal
+	// builds the transaction
+	if s.multisig != nil {
+		resp, err := s.multisig.proposeForWallet(ctx, userID, TransactionRequest{
+			WalletID:             req.WalletID,
+			ToAddress:            req.ToAddress,
+			Value:                req.Value,
+			Data:                 req.Data,
+			Metadata:             req.Metadata,
+			MaxFeePerGas:         req.MaxFeePerGas,
+			MaxPriorityFeePerGas: req.MaxPriorityFeePerGas,
+			FeeTier:              req.FeeTier,
+			AcknowledgeRisk:      req.AcknowledgeRisk,
+		})
+		if err != nil || resp != nil {
+			return resp, err
+		}
+	}
+
 	// Get wallet
-	wallet, err := s.getWalletByID(ctx, req.WalletID)
+	wallet, err := s.userWallet(ctx, userID, req.WalletID)
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
-	}
-	if wallet.UserID != userID {
-		return nil, fmt.Errorf("wallet does not belong to user")
+		return nil, err
 	}
 
 	// Get client for the chain
@@ -161,6 +212,21 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 		toAddress = resolveResp.Record.Address.Hex()
 	}
 
+	// Known scam and sanctioned recipients are blocked, risky ones need acknowledging
+	var screening *TransactionScreening
+	if s.screener != nil {
+		screening, err = s.screener.CheckTransaction(ctx, userID, TransactionScreeningRequest{
+			ChainID:   wallet.ChainID,
+			From:      wallet.Address,
+			ToAddress: toAddress,
+			Value:     req.Value,
+			Data:      req.Data,
+		}, req.AcknowledgeRisk)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Prepare transaction call message for gas estimation
 	callMsg := ethereum.CallMsg{
 		From:  common.HexToAddress(wallet.Address),
@@ -170,6 +236,18 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 	}
 	*callMsg.To = common.HexToAddress(toAddress)
 
+	// Simulate before estimating gas, which fails without a reason for reverting calls
+	var simulation *TransactionSimulation
+	if req.SimulateFirst {
+		simulation, err = SimulateCall(ctx, client, client.Client(), callMsg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate transaction: %w", err)
+		}
+		if !simulation.Success {
+			return nil, &SimulationError{Simulation: simulation}
+		}
+	}
+
 	// Get gas strategy
 	gasStrategy := req.GasStrategy
 	if gasStrategy == "" {
@@ -182,10 +260,39 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 		return nil, fmt.Errorf("failed to estimate gas: %w", err)
 	}
 
+	// Explicit fees win over the gas strategy; a fee tier fills the fees left unset
+	fees := TransactionFees{
+		GasPrice:             gasEstimate.GasPrice,
+		MaxFeePerGas:         req.MaxFeePerGas,
+		MaxPriorityFeePerGas: req.MaxPriorityFeePerGas,
+	}
+	var feeEstimate *FeeEstimate
+	if req.FeeTier != "" && (fees.MaxFeePerGas == nil || fees.MaxPriorityFeePerGas == nil) {
+		tier, err := ParseFeeTier(string(req.FeeTier))
+		if err != nil {
+			return nil, err
+		}
+		feeEstimate, err = EstimateChainFees(ctx, client, wallet.ChainID, FeeTxTypeAuto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate fees: %w", err)
+		}
+		// The tier replaces the gas strategy's price
+		fees.GasPrice = nil
+		if fees, err = feeEstimate.Apply(tier, fees); err != nil {
+			return nil, err
+		}
+	} else {
+		if fees.MaxFeePerGas == nil {
+			fees.MaxFeePerGas = gasEstimate.MaxFeePerGas
+		}
+		if fees.MaxPriorityFeePerGas == nil {
+			fees.MaxPriorityFeePerGas = gasEstimate.MaxPriorityFeePerGas
+		}
+	}
+
 	// Simulate transaction if requested
-	var simulation *TransactionSimulation
-	if req.SimulateTx {
-		simulation, err = s.simulateTransaction(ctx, client, callMsg)
+	if req.SimulateTx && simulation == nil {
+		simulation, err = SimulateCall(ctx, client, client.Client(), callMsg)
 		if err != nil {
 			s.logger.Warn(ctx, "Transaction simulation failed", map[string]interface{}{
 				"error": err.Error(),
@@ -204,12 +311,16 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 		ToAddress:       toAddress,
 		Value:           req.Value,
 		GasUsed:         gasEstimate.GasLimit,
-		GasPrice:        gasEstimate.GasPrice,
+		GasPrice:        fees.GasPrice,
 		Status:          TxStatusPending,
 		TransactionType: "enhanced_transfer",
 		Metadata:        req.Metadata,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
+
+		MaxFeePerGas:         fees.MaxFeePerGas,
+		MaxPriorityFeePerGas: fees.MaxPriorityFeePerGas,
+		FeeTier:              req.FeeTier,
 	}
 
 	// Add gas estimation and simulation to metadata
@@ -217,9 +328,15 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 		transaction.Metadata = make(map[string]interface{})
 	}
 	transaction.Metadata["gas_estimate"] = gasEstimate
+	if feeEstimate != nil {
+		transaction.Metadata["fee_estimate"] = feeEstimate
+	}
 	if simulation != nil {
 		transaction.Metadata["simulation"] = simulation
 	}
+	if screening != nil {
+		transaction.Metadata["screening"] = screening
+	}
 
 	// Save transaction to database
 	if err := s.saveTransaction(ctx, transaction); err != nil {
@@ -235,6 +352,7 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 		Transaction: transaction,
 		TxHash:      transaction.TxHash,
 		Status:      string(transaction.Status),
+		Screening:   screening,
 	}
 
 	s.logger.Info(ctx, "Enhanced transaction created", map[string]interface{}{
@@ -249,57 +367,19 @@ func (s *EnhancedService) CreateEnhancedTransaction(ctx context.Context, userID
 	return response, nil
 }
 
-// simulateTransaction simulates a transaction to check for potential failures
-func (s *EnhancedService) simulateTransaction(ctx context.Context, client *ethclient.Client, callMsg ethereum.CallMsg) (*TransactionSimulation, error) {
-	// Call the contract to simulate execution
-	result, err := client.CallContract(ctx, callMsg, nil)
-	if err != nil {
-		return &TransactionSimulation{
-			Success: false,
-			Revert:  err.Error(),
-		}, nil
+// userWallet returns a wallet of the user from the wallet repository
+func (s *EnhancedService) userWallet(ctx context.Context, userID, walletID uuid.UUID) (*Wallet, error) {
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrWalletNotFound, walletID)
 	}
-
-	// Estimate gas for the call
-	gasUsed, err := client.EstimateGas(ctx, callMsg)
 	if err != nil {
-		return &TransactionSimulation{
-			Success: false,
-			Revert:  err.Error(),
-		}, nil
+		return nil, err
 	}
-
-	// Get current gas price
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	if err != nil {
-		gasPrice = big.NewInt(20000000000) // 20 gwei default
+	if wallet.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", ErrWalletAccessDenied, walletID)
 	}
-
-	estimatedCost := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasUsed)))
-
-	return &TransactionSimulation{
-		Success:       true,
-		GasUsed:       gasUsed,
-		GasPrice:      gasPrice,
-		EstimatedCost: estimatedCost,
-		StateChanges:  map[string]interface{}{"result": fmt.Sprintf("0x%x", result)},
-	}, nil
-}
-
-// Helper method to get wallet by ID (placeholder - would be implemented in the original service)
-func (s *EnhancedService) getWalletByID(ctx context.Context, walletID uuid.UUID) (*Wallet, error) {
-	// This would query the database for the wallet
-	// For now, return a mock wallet
-	return &Wallet{
-		ID:         walletID,
-		UserID:     uuid.New(),
-		Address:    "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b",
-		ChainID:    1,
-		WalletType: "metamask",
-		IsPrimary:  true,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}, nil
+	return wallet, nil
 }
 
 // Helper method to save transaction (placeholder - would be implemented in the original service)
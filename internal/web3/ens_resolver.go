@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
@@ -17,6 +18,7 @@ type ENSResolver struct {
 	client *ethclient.Client
 	logger *observability.Logger
 	cache  map[string]*ENSRecord
+	mu     sync.Mutex // guards cache
 }
 
 // ENSRecord represents an ENS record with metadata
@@ -236,6 +238,9 @@ func (r *ENSResolver) isValidENSName(name string) bool {
 
 // getCachedRecord retrieves a cached ENS record
 func (r *ENSResolver) getCachedRecord(name string) *ENSRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	record, exists := r.cache[name]
 	if !exists {
 		return nil
@@ -252,6 +257,8 @@ func (r *ENSResolver) getCachedRecord(name string) *ENSRecord {
 
 // cacheRecord caches an ENS record
 func (r *ENSResolver) cacheRecord(name string, record *ENSRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.cache[name] = record
 }
 
@@ -287,11 +294,15 @@ func (r *ENSResolver) contentHashToURL(contentHash string) (string, error) {
 
 // ClearCache clears the ENS resolution cache
 func (r *ENSResolver) ClearCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.cache = make(map[string]*ENSRecord)
 }
 
 // GetCacheStats returns statistics about the cache
 func (r *ENSResolver) GetCacheStats() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return map[string]interface{}{
 		"entries": len(r.cache),
 	}
@@ -16,7 +16,9 @@ import (
 // Minimal ERC-20 ABI with only required functions
 const erc20ABIJSON = `[
   {"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"type":"function"},
-  {"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"}
+  {"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+  {"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"},
+  {"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"}
 ]`
 
 var parsedERC20ABI abi.ABI
@@ -152,17 +154,31 @@ func (s *Service) getERC20Balance(ctx context.Context, chainID int, tokenAddr, w
 	return bal, nil
 }
 
+func nativeBalanceCacheKey(chainID int, walletAddr string) string {
+	return fmt.Sprintf("balance:native:%d:%s", chainID, strings.ToLower(walletAddr))
+}
+
+// cachedNativeBalance returns the native balance from cache only, without touching the chain
+func (s *Service) cachedNativeBalance(ctx context.Context, chainID int, walletAddr string) (*big.Int, bool) {
+	if s.redis == nil {
+		return nil, false
+	}
+	data, found, err := s.redis.GetLayered(ctx, nativeBalanceCacheKey(chainID, walletAddr))
+	if err != nil || !found {
+		return nil, false
+	}
+	v, ok := data.(string)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Int).SetString(v, 10)
+}
+
 // getNativeBalance reads and caches native coin balance in L1
 func (s *Service) getNativeBalance(ctx context.Context, chainID int, walletAddr string) (*big.Int, error) {
-	key := fmt.Sprintf("balance:native:%d:%s", chainID, strings.ToLower(walletAddr))
-	if s.redis != nil {
-		if data, found, err := s.redis.GetLayered(ctx, key); err == nil && found {
-			if v, ok := data.(string); ok {
-				if bi, ok2 := new(big.Int).SetString(v, 10); ok2 {
-					return bi, nil
-				}
-			}
-		}
+	key := nativeBalanceCacheKey(chainID, walletAddr)
+	if bi, ok := s.cachedNativeBalance(ctx, chainID, walletAddr); ok {
+		return bi, nil
 	}
 	client, err := s.getEthClient(ctx, chainID)
 	if err != nil {
@@ -0,0 +1,338 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FeeTier selects how quickly a transaction should confirm
+type FeeTier string
+
+const (
+	FeeTierSlow     FeeTier = "slow"
+	FeeTierStandard FeeTier = "standard"
+	FeeTierFast     FeeTier = "fast"
+)
+
+// FeeTxType selects the fee model of a transaction
+type FeeTxType string
+
+const (
+	FeeTxTypeAuto    FeeTxType = ""
+	FeeTxTypeEIP1559 FeeTxType = "eip1559"
+	FeeTxTypeLegacy  FeeTxType = "legacy"
+)
+
+// Fee estimation errors
+var (
+	ErrInvalidFeeTier   = errors.New("invalid fee tier")
+	ErrInvalidFeeTxType = errors.New("invalid fee transaction type")
+)
+
+// feeHistoryBlocks is the number of recent blocks sampled for priority fees
+const feeHistoryBlocks = 20
+
+// feeTierSettings holds the per-tier parameters of fee suggestions
+var feeTierSettings = map[FeeTier]struct {
+	percentile      float64 // priority fee percentile of recent blocks
+	baseFeePercent  int64   // base fee headroom in max fee per gas
+	gasPricePercent int64   // share of the suggested gas price on legacy chains
+	blocks          int64   // blocks expected until confirmation
+}{
+	FeeTierSlow:     {percentile: 10, baseFeePercent: 125, gasPricePercent: 90, blocks: 10},
+	FeeTierStandard: {percentile: 50, baseFeePercent: 150, gasPricePercent: 100, blocks: 3},
+	FeeTierFast:     {percentile: 90, baseFeePercent: 200, gasPricePercent: 125, blocks: 1},
+}
+
+// feeTiers lists the tiers in ascending speed; priority fee percentiles follow the same order
+var feeTiers = []FeeTier{FeeTierSlow, FeeTierStandard, FeeTierFast}
+
+// chainBlockTimes holds the typical block interval of supported chains
+var chainBlockTimes = map[int]time.Duration{
+	1:     12 * time.Second,
+	10:    2 * time.Second,
+	56:    3 * time.Second,
+	137:   2 * time.Second,
+	250:   time.Second,
+	42161: 250 * time.Millisecond,
+	43114: 2 * time.Second,
+	8453:  2 * time.Second,
+}
+
+// FeeDataSource is the part of a chain client used for fee estimation. *ethclient.Client
+// implements it.
+type FeeDataSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// FeeSuggestion is the fee to pay for one tier. EIP-1559 suggestions set the max fee fields;
+// legacy suggestions set the gas price.
+type FeeSuggestion struct {
+	MaxFeePerGas          *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas  *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+	GasPrice              *big.Int `json:"gas_price,omitempty"`
+	EstimatedConfirmation string   `json:"estimated_confirmation"`
+	EstimatedSeconds      float64  `json:"estimated_seconds"`
+}
+
+// FeeEstimate holds fee suggestions for a chain at slow, standard and fast tiers
+type FeeEstimate struct {
+	ChainID     int            `json:"chain_id"`
+	TxType      FeeTxType      `json:"tx_type"`
+	BaseFee     *big.Int       `json:"base_fee,omitempty"`
+	GasPrice    *big.Int       `json:"gas_price"`
+	BlockNumber uint64         `json:"block_number"`
+	Slow        *FeeSuggestion `json:"slow"`
+	Standard    *FeeSuggestion `json:"standard"`
+	Fast        *FeeSuggestion `json:"fast"`
+	EstimatedAt time.Time      `json:"estimated_at"`
+}
+
+// TransactionFees are the fee fields of a transaction
+type TransactionFees struct {
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// ParseFeeTier validates a fee tier
+func ParseFeeTier(tier string) (FeeTier, error) {
+	switch t := FeeTier(strings.ToLower(strings.TrimSpace(tier))); t {
+	case FeeTierSlow, FeeTierStandard, FeeTierFast:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected slow, standard or fast", ErrInvalidFeeTier, tier)
+	}
+}
+
+// ParseFeeTxType validates a fee transaction type, where empty and "auto" pick the chain's
+// native model
+func ParseFeeTxType(txType string) (FeeTxType, error) {
+	switch t := strings.ToLower(strings.TrimSpace(txType)); t {
+	case "", "auto":
+		return FeeTxTypeAuto, nil
+	case "eip1559", "eip-1559", "2":
+		return FeeTxTypeEIP1559, nil
+	case "legacy", "0":
+		return FeeTxTypeLegacy, nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected eip1559 or legacy", ErrInvalidFeeTxType, txType)
+	}
+}
+
+// Tier returns the suggestion of a tier
+func (e *FeeEstimate) Tier(tier FeeTier) (*FeeSuggestion, error) {
+	switch tier {
+	case FeeTierSlow:
+		return e.Slow, nil
+	case FeeTierStandard:
+		return e.Standard, nil
+	case FeeTierFast:
+		return e.Fast, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFeeTier, tier)
+	}
+}
+
+// Apply fills the fee fields left unset in explicit with the suggestion of a tier. Explicit
+// values always win; a legacy gas price is only suggested when no EIP-1559 fee was given.
+func (e *FeeEstimate) Apply(tier FeeTier, explicit TransactionFees) (TransactionFees, error) {
+	suggestion, err := e.Tier(tier)
+	if err != nil {
+		return explicit, err
+	}
+
+	fees := explicit
+	if e.TxType == FeeTxTypeEIP1559 {
+		if fees.MaxPriorityFeePerGas == nil {
+			fees.MaxPriorityFeePerGas = suggestion.MaxPriorityFeePerGas
+		}
+		if fees.MaxFeePerGas == nil {
+			fees.MaxFeePerGas = suggestion.MaxFeePerGas
+			// A larger explicit tip still has to fit under the max fee
+			if fees.MaxPriorityFeePerGas.Cmp(fees.MaxFeePerGas) > 0 {
+				fees.MaxFeePerGas = new(big.Int).Add(e.BaseFee, fees.MaxPriorityFeePerGas)
+			}
+		}
+		// A suggested tip never exceeds an explicit max fee
+		if explicit.MaxPriorityFeePerGas == nil && fees.MaxPriorityFeePerGas.Cmp(fees.MaxFeePerGas) > 0 {
+			fees.MaxPriorityFeePerGas = new(big.Int).Set(fees.MaxFeePerGas)
+		}
+		return fees, nil
+	}
+
+	if fees.GasPrice == nil && fees.MaxFeePerGas == nil && fees.MaxPriorityFeePerGas == nil {
+		fees.GasPrice = suggestion.GasPrice
+	}
+	return fees, nil
+}
+
+// EstimateFees suggests fees for a chain at slow, standard and fast tiers. EIP-1559 chains get
+// max and priority fees derived from the latest base fee and recent priority fee percentiles;
+// chains without a base fee, or requests for legacy transactions, get gas prices instead.
+func (s *Service) EstimateFees(ctx context.Context, chainID int, txType FeeTxType) (*FeeEstimate, error) {
+	client, err := s.getFeeDataSource(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return EstimateChainFees(ctx, client, chainID, txType)
+}
+
+// resolveTransactionFees fills the unset fees of a transaction from a fee tier. Without a tier,
+// or when both EIP-1559 fees are explicit, the explicit fees are returned unchanged.
+func (s *Service) resolveTransactionFees(ctx context.Context, chainID int, tier FeeTier, explicit TransactionFees) (TransactionFees, *FeeEstimate, error) {
+	if tier == "" || (explicit.MaxFeePerGas != nil && explicit.MaxPriorityFeePerGas != nil) {
+		return explicit, nil, nil
+	}
+	tier, err := ParseFeeTier(string(tier))
+	if err != nil {
+		return explicit, nil, err
+	}
+
+	estimate, err := s.EstimateFees(ctx, chainID, FeeTxTypeAuto)
+	if err != nil {
+		return explicit, nil, fmt.Errorf("failed to estimate fees: %w", err)
+	}
+	fees, err := estimate.Apply(tier, explicit)
+	return fees, estimate, err
+}
+
+// getFeeDataSource returns the chain client used for fee estimation
+func (s *Service) getFeeDataSource(ctx context.Context, chainID int) (FeeDataSource, error) {
+	if provider, ok := s.providers[chainID]; ok && provider.Client != nil {
+		if source, ok := provider.Client.(FeeDataSource); ok {
+			return source, nil
+		}
+	}
+	return s.getEthClient(ctx, chainID)
+}
+
+// EstimateChainFees suggests fees at each tier using a chain client
+func EstimateChainFees(ctx context.Context, client FeeDataSource, chainID int, txType FeeTxType) (*FeeEstimate, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	estimate := &FeeEstimate{
+		ChainID:     chainID,
+		TxType:      FeeTxTypeLegacy,
+		GasPrice:    gasPrice,
+		BlockNumber: header.Number.Uint64(),
+		EstimatedAt: time.Now(),
+	}
+
+	// Chains without a base fee do not support EIP-1559
+	if header.BaseFee != nil && txType != FeeTxTypeLegacy {
+		estimate.TxType = FeeTxTypeEIP1559
+		estimate.BaseFee = header.BaseFee
+
+		tips, err := priorityFeePercentiles(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		for i, tier := range feeTiers {
+			settings := feeTierSettings[tier]
+			maxFee := percentOf(header.BaseFee, settings.baseFeePercent)
+			maxFee.Add(maxFee, tips[i])
+			estimate.setTier(tier, &FeeSuggestion{
+				MaxFeePerGas:         maxFee,
+				MaxPriorityFeePerGas: tips[i],
+			})
+		}
+	} else {
+		for _, tier := range feeTiers {
+			estimate.setTier(tier, &FeeSuggestion{
+				GasPrice: percentOf(gasPrice, feeTierSettings[tier].gasPricePercent),
+			})
+		}
+	}
+
+	blockTime, ok := chainBlockTimes[chainID]
+	if !ok {
+		blockTime = chainBlockTimes[1]
+	}
+	for _, tier := range feeTiers {
+		suggestion, _ := estimate.Tier(tier)
+		wait := blockTime * time.Duration(feeTierSettings[tier].blocks)
+		suggestion.EstimatedConfirmation = wait.String()
+		suggestion.EstimatedSeconds = wait.Seconds()
+	}
+
+	return estimate, nil
+}
+
+// priorityFeePercentiles returns the average priority fee of recent blocks at each tier's
+// percentile, in tier order. Without usable history the node's suggested tip is scaled instead.
+func priorityFeePercentiles(ctx context.Context, client FeeDataSource) ([]*big.Int, error) {
+	percentiles := make([]float64, len(feeTiers))
+	for i, tier := range feeTiers {
+		percentiles[i] = feeTierSettings[tier].percentile
+	}
+
+	tips := make([]*big.Int, len(feeTiers))
+	history, err := client.FeeHistory(ctx, feeHistoryBlocks, nil, percentiles)
+	if err == nil && history != nil {
+		for i := range feeTiers {
+			total, count := big.NewInt(0), int64(0)
+			for _, rewards := range history.Reward {
+				if i < len(rewards) && rewards[i] != nil {
+					total.Add(total, rewards[i])
+					count++
+				}
+			}
+			if count == 0 {
+				break
+			}
+			tips[i] = total.Div(total, big.NewInt(count))
+		}
+	}
+
+	if tips[len(tips)-1] == nil {
+		tipCap, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get priority fee: %w", err)
+		}
+		tips = []*big.Int{percentOf(tipCap, 80), new(big.Int).Set(tipCap), percentOf(tipCap, 150)}
+	}
+
+	// Faster tiers never pay a smaller tip than slower ones
+	for i := 1; i < len(tips); i++ {
+		if tips[i].Cmp(tips[i-1]) < 0 {
+			tips[i] = new(big.Int).Set(tips[i-1])
+		}
+	}
+	return tips, nil
+}
+
+// setTier stores the suggestion of a tier
+func (e *FeeEstimate) setTier(tier FeeTier, suggestion *FeeSuggestion) {
+	switch tier {
+	case FeeTierSlow:
+		e.Slow = suggestion
+	case FeeTierStandard:
+		e.Standard = suggestion
+	case FeeTierFast:
+		e.Fast = suggestion
+	}
+}
+
+// percentOf returns percent of value as a new integer
+func percentOf(value *big.Int, percent int64) *big.Int {
+	result := new(big.Int).Mul(value, big.NewInt(percent))
+	return result.Div(result, big.NewInt(100))
+}
@@ -0,0 +1,181 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+type mockFeeSource struct {
+	baseFee  *big.Int
+	gasPrice *big.Int
+	tipCap   *big.Int
+	rewards  [][]*big.Int
+}
+
+func (m *mockFeeSource) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(100), BaseFee: m.baseFee}, nil
+}
+func (m *mockFeeSource) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return m.gasPrice, nil
+}
+func (m *mockFeeSource) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return m.tipCap, nil
+}
+func (m *mockFeeSource) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{Reward: m.rewards}, nil
+}
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000))
+}
+
+func TestEstimateFees(t *testing.T) {
+	t.Run("EIP1559", func(t *testing.T) {
+		source := &mockFeeSource{
+			baseFee:  gwei(20),
+			gasPrice: gwei(22),
+			rewards: [][]*big.Int{
+				{gwei(1), gwei(2), gwei(4)},
+				{gwei(1), gwei(2), gwei(6)},
+			},
+		}
+
+		estimate, err := EstimateChainFees(context.Background(), source, 1, FeeTxTypeAuto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if estimate.TxType != FeeTxTypeEIP1559 || estimate.BaseFee.Cmp(gwei(20)) != 0 {
+			t.Fatalf("expected EIP-1559 estimate with base fee, got %+v", estimate)
+		}
+
+		// Priority fees average the sampled percentiles; max fees add base fee headroom
+		expected := map[FeeTier][2]*big.Int{
+			FeeTierSlow:     {gwei(1), gwei(26)},
+			FeeTierStandard: {gwei(2), gwei(32)},
+			FeeTierFast:     {gwei(5), gwei(45)},
+		}
+		for tier, fees := range expected {
+			suggestion, _ := estimate.Tier(tier)
+			if suggestion.MaxPriorityFeePerGas.Cmp(fees[0]) != 0 || suggestion.MaxFeePerGas.Cmp(fees[1]) != 0 {
+				t.Errorf("%s: expected tip %s and max fee %s, got %s and %s", tier,
+					fees[0], fees[1], suggestion.MaxPriorityFeePerGas, suggestion.MaxFeePerGas)
+			}
+			if suggestion.GasPrice != nil {
+				t.Errorf("%s: expected no legacy gas price", tier)
+			}
+		}
+		if estimate.Fast.EstimatedSeconds != 12 || estimate.Slow.EstimatedSeconds != 120 {
+			t.Errorf("expected confirmation times from the mainnet block time, got %v and %v",
+				estimate.Fast.EstimatedSeconds, estimate.Slow.EstimatedSeconds)
+		}
+	})
+
+	t.Run("TipCapFallback", func(t *testing.T) {
+		source := &mockFeeSource{baseFee: gwei(10), gasPrice: gwei(12), tipCap: gwei(2)}
+
+		estimate, err := EstimateChainFees(context.Background(), source, 137, FeeTxTypeAuto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if estimate.Standard.MaxPriorityFeePerGas.Cmp(gwei(2)) != 0 {
+			t.Errorf("expected the suggested tip without fee history, got %s", estimate.Standard.MaxPriorityFeePerGas)
+		}
+		if estimate.Slow.MaxPriorityFeePerGas.Cmp(estimate.Fast.MaxPriorityFeePerGas) >= 0 {
+			t.Errorf("expected slow tip below fast tip")
+		}
+	})
+
+	t.Run("LegacyFallback", func(t *testing.T) {
+		source := &mockFeeSource{gasPrice: gwei(5)}
+
+		estimate, err := EstimateChainFees(context.Background(), source, 56, FeeTxTypeEIP1559)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if estimate.TxType != FeeTxTypeLegacy || estimate.BaseFee != nil {
+			t.Fatalf("expected legacy estimate on a chain without base fee, got %s", estimate.TxType)
+		}
+		if estimate.Standard.GasPrice.Cmp(gwei(5)) != 0 || estimate.Standard.MaxFeePerGas != nil {
+			t.Errorf("expected legacy gas price only, got %+v", estimate.Standard)
+		}
+		if estimate.Fast.GasPrice.Cmp(estimate.Slow.GasPrice) <= 0 {
+			t.Errorf("expected fast gas price above slow")
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		if _, err := ParseFeeTier("urgent"); !errors.Is(err, ErrInvalidFeeTier) {
+			t.Errorf("expected ErrInvalidFeeTier, got %v", err)
+		}
+		if _, err := ParseFeeTxType("type-3"); !errors.Is(err, ErrInvalidFeeTxType) {
+			t.Errorf("expected ErrInvalidFeeTxType, got %v", err)
+		}
+	})
+}
+
+func TestCreateTransaction_FeeTier(t *testing.T) {
+	s := newServiceWithMocks()
+	s.providers[1].Client = &mockFeeSource{
+		baseFee:  gwei(20),
+		gasPrice: gwei(22),
+		rewards:  [][]*big.Int{{gwei(1), gwei(2), gwei(4)}},
+	}
+	mw := s.walletRepo.(*mockWalletRepo)
+	walletID := uuid.New()
+	userID := uuid.New()
+	mw.getByID = map[uuid.UUID]*Wallet{walletID: {ID: walletID, UserID: userID, Address: "0xabc", ChainID: 1}}
+
+	t.Run("PopulatesUnsetFees", func(t *testing.T) {
+		resp, err := s.CreateTransaction(context.Background(), userID, TransactionRequest{
+			WalletID:             walletID,
+			ToAddress:            "0x000000000000000000000000000000000000dEaD",
+			Value:                big.NewInt(1),
+			FeeTier:              FeeTierFast,
+			MaxPriorityFeePerGas: gwei(3),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tx := resp.Transaction
+		if tx.MaxPriorityFeePerGas.Cmp(gwei(3)) != 0 {
+			t.Errorf("expected explicit priority fee to be kept, got %s", tx.MaxPriorityFeePerGas)
+		}
+		if tx.MaxFeePerGas.Cmp(gwei(44)) != 0 {
+			t.Errorf("expected fast max fee, got %s", tx.MaxFeePerGas)
+		}
+		if _, ok := tx.Metadata["fee_estimate"]; !ok {
+			t.Errorf("expected fee estimate in metadata")
+		}
+	})
+
+	t.Run("InvalidTier", func(t *testing.T) {
+		_, err := s.CreateTransaction(context.Background(), userID, TransactionRequest{
+			WalletID:  walletID,
+			ToAddress: "0x000000000000000000000000000000000000dEaD",
+			FeeTier:   "urgent",
+		})
+		if !errors.Is(err, ErrInvalidFeeTier) {
+			t.Fatalf("expected ErrInvalidFeeTier, got %v", err)
+		}
+	})
+
+	t.Run("NoTier", func(t *testing.T) {
+		resp, err := s.CreateTransaction(context.Background(), userID, TransactionRequest{
+			WalletID:  walletID,
+			ToAddress: "0x000000000000000000000000000000000000dEaD",
+			GasPrice:  gwei(7),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Transaction.MaxFeePerGas != nil || resp.Transaction.GasPrice.Cmp(gwei(7)) != 0 {
+			t.Errorf("expected explicit fees only without a tier")
+		}
+	})
+}
@@ -0,0 +1,354 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+)
+
+// Kill switch triggers
+const (
+	KillSwitchTriggerStaleData   = "stale_market_data"
+	KillSwitchTriggerDailyLoss   = "daily_loss"
+	KillSwitchTriggerManual      = "manual"
+	KillSwitchTriggerUnavailable = "state_unavailable"
+)
+
+// CloseReasonKillSwitch is recorded on positions flattened by the kill switch
+const CloseReasonKillSwitch = "kill_switch"
+
+// killSwitchKey is the Redis key holding the kill switch state
+const killSwitchKey = "web3:trading:killswitch"
+
+var (
+	// ErrTradingHalted is returned when a position would be opened while the kill switch is tripped
+	ErrTradingHalted = errors.New("autonomous trading is halted by the kill switch")
+	// ErrKillSwitchNotTripped is returned when resetting a kill switch that is armed
+	ErrKillSwitchNotTripped = errors.New("kill switch is not tripped")
+	// ErrKillSwitchReasonRequired is returned when a reset has no reason to audit
+	ErrKillSwitchReasonRequired = errors.New("a reason is required to reset the kill switch")
+)
+
+// KillSwitchConfig sets the automatic triggers of the kill switch. A zero threshold disables
+// its trigger.
+type KillSwitchConfig struct {
+	StaleDataAfter time.Duration // halt while trading when no market data arrived for this long
+	MaxDailyLoss   float64       // halt when a portfolio lost this share of its value today
+	FlattenOnTrip  bool          // close all open positions whenever the kill switch trips
+	CheckInterval  time.Duration
+}
+
+// KillSwitchState is the persisted state of the kill switch. Once tripped it stays tripped,
+// across restarts, until an operator resets it.
+type KillSwitchState struct {
+	Tripped         bool       `json:"tripped"`
+	Trigger         string     `json:"trigger,omitempty"`
+	Reason          string     `json:"reason,omitempty"`
+	TrippedAt       *time.Time `json:"tripped_at,omitempty"`
+	TrippedBy       *uuid.UUID `json:"tripped_by,omitempty"`
+	ClosedPositions int        `json:"closed_positions,omitempty"`
+	ResetAt         *time.Time `json:"reset_at,omitempty"`
+	ResetBy         *uuid.UUID `json:"reset_by,omitempty"`
+	ResetReason     string     `json:"reset_reason,omitempty"`
+}
+
+// KillSwitchStore persists the kill switch state
+type KillSwitchStore interface {
+	// LoadKillSwitch returns the saved state, or nil when none was saved
+	LoadKillSwitch(ctx context.Context) (*KillSwitchState, error)
+	SaveKillSwitch(ctx context.Context, state KillSwitchState) error
+}
+
+// redisKillSwitchStore keeps the kill switch state in Redis without expiry
+type redisKillSwitchStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisKillSwitchStore creates a store sharing the kill switch state through Redis
+func NewRedisKillSwitchStore(redis *database.RedisClient) KillSwitchStore {
+	return &redisKillSwitchStore{redis: redis}
+}
+
+func (s *redisKillSwitchStore) LoadKillSwitch(ctx context.Context) (*KillSwitchState, error) {
+	data, err := s.redis.Client.Get(ctx, killSwitchKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state KillSwitchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid kill switch state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *redisKillSwitchStore) SaveKillSwitch(ctx context.Context, state KillSwitchState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.redis.Client.Set(ctx, killSwitchKey, data, 0).Err()
+}
+
+// EnableKillSwitch configures the kill switch and restores its persisted state. If the state
+// cannot be loaded, trading is halted until an operator resets the kill switch.
+func (t *TradingEngine) EnableKillSwitch(ctx context.Context, cfg KillSwitchConfig, store KillSwitchStore, auditor *security.AuditManager) error {
+	t.mu.Lock()
+	t.killSwitch = cfg
+	t.killSwitchStore = store
+	t.auditor = auditor
+	// Market data gets one full staleness window after startup
+	t.lastMarketData = time.Now()
+	t.mu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+
+	state, err := store.LoadKillSwitch(ctx)
+	if err != nil {
+		now := time.Now()
+		t.mu.Lock()
+		t.killSwitchState = KillSwitchState{
+			Tripped:   true,
+			Trigger:   KillSwitchTriggerUnavailable,
+			Reason:    "kill switch state could not be loaded: " + err.Error(),
+			TrippedAt: &now,
+		}
+		t.mu.Unlock()
+		return fmt.Errorf("failed to load kill switch state, trading is halted: %w", err)
+	}
+	if state == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	t.killSwitchState = *state
+	t.mu.Unlock()
+
+	if state.Tripped {
+		t.logger.Warn(ctx, "Autonomous trading is halted until the kill switch is reset", map[string]interface{}{
+			"trigger":    state.Trigger,
+			"reason":     state.Reason,
+			"tripped_at": state.TrippedAt,
+		})
+	}
+	return nil
+}
+
+// KillSwitchStatus returns the current kill switch state
+func (t *TradingEngine) KillSwitchStatus() KillSwitchState {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.killSwitchState
+}
+
+// TradingHalted reports whether the kill switch is tripped
+func (t *TradingEngine) TradingHalted() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.killSwitchState.Tripped
+}
+
+// MonitorKillSwitch evaluates the automatic kill switch triggers until ctx is done
+func (t *TradingEngine) MonitorKillSwitch(ctx context.Context) {
+	t.mu.RLock()
+	interval := t.killSwitch.CheckInterval
+	t.mu.RUnlock()
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := t.CheckKillSwitch(ctx); err != nil {
+				t.logger.Error(ctx, "Kill switch check failed", err)
+			}
+		}
+	}
+}
+
+// CheckKillSwitch trips the kill switch when market data went stale while the engine is
+// running, or a portfolio's daily loss reached the configured share of its value. It reports
+// whether trading is halted.
+func (t *TradingEngine) CheckKillSwitch(ctx context.Context) (bool, error) {
+	t.mu.RLock()
+	if t.killSwitchState.Tripped {
+		t.mu.RUnlock()
+		return true, nil
+	}
+	cfg := t.killSwitch
+	sinceMarketData := time.Since(t.lastMarketData)
+	running := t.isRunning
+
+	lossReason := ""
+	if cfg.MaxDailyLoss > 0 {
+		limit := decimal.NewFromFloat(cfg.MaxDailyLoss)
+		for _, portfolio := range t.portfolios {
+			if !portfolio.TotalValue.IsPositive() || !portfolio.DailyPnL.IsNegative() {
+				continue
+			}
+			if loss := portfolio.DailyPnL.Neg().Div(portfolio.TotalValue); loss.GreaterThanOrEqual(limit) {
+				lossReason = fmt.Sprintf("portfolio %s lost %s%% of its value today, limit %s%%",
+					portfolio.ID, loss.Mul(decimal.NewFromInt(100)).StringFixed(2), limit.Mul(decimal.NewFromInt(100)).String())
+				break
+			}
+		}
+	}
+	t.mu.RUnlock()
+
+	switch {
+	case running && cfg.StaleDataAfter > 0 && sinceMarketData > cfg.StaleDataAfter:
+		reason := fmt.Sprintf("no market data for %s, limit %s", sinceMarketData.Round(time.Second), cfg.StaleDataAfter)
+		_, err := t.TripKillSwitch(ctx, KillSwitchTriggerStaleData, reason, nil, false)
+		return true, err
+	case lossReason != "":
+		_, err := t.TripKillSwitch(ctx, KillSwitchTriggerDailyLoss, lossReason, nil, false)
+		return true, err
+	}
+	return false, nil
+}
+
+// TripKillSwitch halts autonomous trading: no new positions are opened until the kill switch
+// is reset. With flatten, or when configured to flatten on trip, every open position is
+// closed. Tripping a tripped kill switch keeps the original trigger but may still flatten.
+// The error reports a failure to persist the state; trading is halted regardless.
+func (t *TradingEngine) TripKillSwitch(ctx context.Context, trigger, reason string, userID *uuid.UUID, flatten bool) (KillSwitchState, error) {
+	t.mu.Lock()
+	alreadyTripped := t.killSwitchState.Tripped
+	flatten = flatten || t.killSwitch.FlattenOnTrip
+	if alreadyTripped && !flatten {
+		state := t.killSwitchState
+		t.mu.Unlock()
+		return state, nil
+	}
+
+	if !alreadyTripped {
+		now := time.Now()
+		t.killSwitchState = KillSwitchState{
+			Tripped:   true,
+			Trigger:   trigger,
+			Reason:    reason,
+			TrippedAt: &now,
+			TrippedBy: userID,
+		}
+	}
+	closed := 0
+	if flatten {
+		for _, position := range t.activePositions {
+			if position.Status != PositionStatusOpen {
+				continue
+			}
+			t.closePositionLocked(ctx, position, CloseReasonKillSwitch)
+			closed++
+		}
+		t.killSwitchState.ClosedPositions += closed
+	}
+	state := t.killSwitchState
+	store, auditor := t.killSwitchStore, t.auditor
+	t.mu.Unlock()
+
+	details := map[string]interface{}{
+		"trigger":          trigger,
+		"reason":           reason,
+		"closed_positions": closed,
+		"already_tripped":  alreadyTripped,
+	}
+	t.logger.Warn(ctx, "Kill switch tripped, autonomous trading halted", details)
+
+	var err error
+	if store != nil {
+		if err = store.SaveKillSwitch(ctx, state); err != nil {
+			err = fmt.Errorf("kill switch tripped but its state was not persisted: %w", err)
+		}
+	}
+	t.auditKillSwitch(ctx, auditor, userID, "kill_switch_trip", err, details)
+	return state, err
+}
+
+// ResetKillSwitch re-arms a tripped kill switch so autonomous trading resumes. The reset is
+// persisted before it takes effect and is audit-logged with the operator and reason.
+func (t *TradingEngine) ResetKillSwitch(ctx context.Context, userID uuid.UUID, reason string) (KillSwitchState, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return KillSwitchState{}, ErrKillSwitchReasonRequired
+	}
+
+	t.mu.Lock()
+	if !t.killSwitchState.Tripped {
+		state := t.killSwitchState
+		t.mu.Unlock()
+		return state, ErrKillSwitchNotTripped
+	}
+
+	now := time.Now()
+	previous := t.killSwitchState
+	state := previous
+	state.Tripped = false
+	state.ResetAt = &now
+	state.ResetBy = &userID
+	state.ResetReason = reason
+
+	details := map[string]interface{}{
+		"trigger":      previous.Trigger,
+		"trip_reason":  previous.Reason,
+		"tripped_at":   previous.TrippedAt,
+		"reset_reason": reason,
+	}
+
+	// Persist under the lock so a concurrent trip cannot be overwritten by the reset
+	if t.killSwitchStore != nil {
+		if err := t.killSwitchStore.SaveKillSwitch(ctx, state); err != nil {
+			auditor := t.auditor
+			t.mu.Unlock()
+			err = fmt.Errorf("failed to persist kill switch reset: %w", err)
+			t.auditKillSwitch(ctx, auditor, &userID, "kill_switch_reset", err, details)
+			return previous, err
+		}
+	}
+	t.killSwitchState = state
+	// Market data gets a full staleness window before the switch can trip again
+	t.lastMarketData = now
+	auditor := t.auditor
+	t.mu.Unlock()
+
+	t.logger.Warn(ctx, "Kill switch reset, autonomous trading resumed", map[string]interface{}{
+		"user_id": userID.String(),
+		"reason":  reason,
+	})
+	t.auditKillSwitch(ctx, auditor, &userID, "kill_switch_reset", nil, details)
+	return state, nil
+}
+
+// auditKillSwitch records a kill switch change in the audit log
+func (t *TradingEngine) auditKillSwitch(ctx context.Context, auditor *security.AuditManager, userID *uuid.UUID, action string, failure error, details map[string]interface{}) {
+	if auditor == nil {
+		return
+	}
+	result := security.AuditResultSuccess
+	if failure != nil {
+		result = security.AuditResultError
+		details["error"] = failure.Error()
+	}
+	if err := auditor.LogTradingEvent(ctx, userID, action, result, details); err != nil {
+		t.logger.Error(ctx, "Failed to audit kill switch change", err, details)
+	}
+}
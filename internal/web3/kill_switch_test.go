@@ -0,0 +1,149 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryKillSwitchStore struct {
+	state   *KillSwitchState
+	loadErr error
+	saveErr error
+}
+
+func (s *memoryKillSwitchStore) LoadKillSwitch(ctx context.Context) (*KillSwitchState, error) {
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	return s.state, nil
+}
+
+func (s *memoryKillSwitchStore) SaveKillSwitch(ctx context.Context, state KillSwitchState) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.state = &state
+	return nil
+}
+
+func TestKillSwitch(t *testing.T) {
+	ctx := context.Background()
+	operator := uuid.New()
+
+	t.Run("ManualTripHaltsAndFlattens", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		store := &memoryKillSwitchStore{}
+		require.NoError(t, engine.EnableKillSwitch(ctx, KillSwitchConfig{}, store, nil))
+		portfolio, position := openTestPosition(t, engine, ethSignal())
+
+		state, err := engine.TripKillSwitch(ctx, KillSwitchTriggerManual, "exchange outage", &operator, true)
+		require.NoError(t, err)
+		assert.True(t, state.Tripped)
+		assert.Equal(t, 1, state.ClosedPositions)
+		assert.Equal(t, PositionStatusClosed, position.Status)
+		assert.Equal(t, CloseReasonKillSwitch, position.CloseReason)
+		assert.True(t, engine.TradingHalted())
+		require.NotNil(t, store.state)
+		assert.True(t, store.state.Tripped)
+
+		_, err = engine.executeTrade(ctx, portfolio, ethSignal(), decimal.NewFromInt(1))
+		assert.ErrorIs(t, err, ErrTradingHalted)
+	})
+
+	t.Run("PersistedStateSurvivesRestart", func(t *testing.T) {
+		store := &memoryKillSwitchStore{}
+		first := newProtectionTestEngine()
+		require.NoError(t, first.EnableKillSwitch(ctx, KillSwitchConfig{}, store, nil))
+		_, err := first.TripKillSwitch(ctx, KillSwitchTriggerManual, "halt", &operator, false)
+		require.NoError(t, err)
+
+		restarted := newProtectionTestEngine()
+		require.NoError(t, restarted.EnableKillSwitch(ctx, KillSwitchConfig{}, store, nil))
+		assert.True(t, restarted.TradingHalted())
+		assert.Equal(t, KillSwitchTriggerManual, restarted.KillSwitchStatus().Trigger)
+	})
+
+	t.Run("UnreadableStateHalts", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		err := engine.EnableKillSwitch(ctx, KillSwitchConfig{}, &memoryKillSwitchStore{loadErr: errors.New("redis down")}, nil)
+		assert.Error(t, err)
+		assert.True(t, engine.TradingHalted())
+		assert.Equal(t, KillSwitchTriggerUnavailable, engine.KillSwitchStatus().Trigger)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		store := &memoryKillSwitchStore{}
+		require.NoError(t, engine.EnableKillSwitch(ctx, KillSwitchConfig{}, store, nil))
+
+		_, err := engine.ResetKillSwitch(ctx, operator, "armed")
+		assert.ErrorIs(t, err, ErrKillSwitchNotTripped)
+
+		_, err = engine.TripKillSwitch(ctx, KillSwitchTriggerManual, "halt", nil, false)
+		require.NoError(t, err)
+		_, err = engine.ResetKillSwitch(ctx, operator, "  ")
+		assert.ErrorIs(t, err, ErrKillSwitchReasonRequired)
+
+		store.saveErr = errors.New("redis down")
+		_, err = engine.ResetKillSwitch(ctx, operator, "feed restored")
+		assert.Error(t, err)
+		assert.True(t, engine.TradingHalted(), "a reset that was not persisted must not resume trading")
+
+		store.saveErr = nil
+		state, err := engine.ResetKillSwitch(ctx, operator, "feed restored")
+		require.NoError(t, err)
+		assert.False(t, state.Tripped)
+		assert.Equal(t, "feed restored", state.ResetReason)
+		assert.Equal(t, operator, *state.ResetBy)
+		assert.False(t, store.state.Tripped)
+		assert.False(t, engine.TradingHalted())
+	})
+
+	t.Run("DailyLossTrigger", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		require.NoError(t, engine.EnableKillSwitch(ctx, KillSwitchConfig{MaxDailyLoss: 0.1}, &memoryKillSwitchStore{}, nil))
+		portfolio, _ := openTestPosition(t, engine, ethSignal())
+		portfolio.TotalValue = decimal.NewFromInt(10000)
+
+		portfolio.DailyPnL = decimal.NewFromInt(-500)
+		halted, err := engine.CheckKillSwitch(ctx)
+		require.NoError(t, err)
+		assert.False(t, halted)
+
+		portfolio.DailyPnL = decimal.NewFromInt(-1000)
+		halted, err = engine.CheckKillSwitch(ctx)
+		require.NoError(t, err)
+		assert.True(t, halted)
+		assert.Equal(t, KillSwitchTriggerDailyLoss, engine.KillSwitchStatus().Trigger)
+	})
+
+	t.Run("StaleDataTrigger", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		require.NoError(t, engine.EnableKillSwitch(ctx, KillSwitchConfig{StaleDataAfter: time.Minute}, &memoryKillSwitchStore{}, nil))
+		engine.lastMarketData = time.Now().Add(-2 * time.Minute)
+
+		// Stale data only matters while the engine trades
+		halted, err := engine.CheckKillSwitch(ctx)
+		require.NoError(t, err)
+		assert.False(t, halted)
+
+		engine.isRunning = true
+		engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2000))
+		halted, err = engine.CheckKillSwitch(ctx)
+		require.NoError(t, err)
+		assert.False(t, halted, "fresh prices keep the kill switch armed")
+
+		engine.lastMarketData = time.Now().Add(-2 * time.Minute)
+		halted, err = engine.CheckKillSwitch(ctx)
+		require.NoError(t, err)
+		assert.True(t, halted)
+		assert.Equal(t, KillSwitchTriggerStaleData, engine.KillSwitchStatus().Trigger)
+	})
+}
@@ -0,0 +1,279 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrDeFiPositionNotFound is returned for unknown positions and other users' positions
+	ErrDeFiPositionNotFound = errors.New("defi position not found")
+	// ErrNotLiquidityPosition is returned when LP analytics are requested for a non-LP position
+	ErrNotLiquidityPosition = errors.New("position is not a liquidity pool position")
+)
+
+// Liquidity pool models used by LP analytics
+const (
+	LPPoolConstantProduct = "constant_product"
+	LPPoolConcentrated    = "concentrated"
+)
+
+// lpPrecision is the number of decimal places kept by intermediate LP calculations
+const lpPrecision = 24
+
+// LPAnalytics compares a liquidity pool position with holding its entry tokens. Metrics that
+// cannot be computed from the available data are omitted and explained in Warnings.
+type LPAnalytics struct {
+	PositionID uuid.UUID `json:"position_id"`
+	PoolType   string    `json:"pool_type"`
+	TokenA     string    `json:"token_a"`
+	TokenB     string    `json:"token_b"`
+	InRange    *bool     `json:"in_range,omitempty"` // concentrated positions only
+
+	CurrentPriceA  *decimal.Decimal `json:"current_price_a,omitempty"` // USD
+	CurrentPriceB  *decimal.Decimal `json:"current_price_b,omitempty"` // USD
+	CurrentAmountA decimal.Decimal  `json:"current_amount_a"`
+	CurrentAmountB decimal.Decimal  `json:"current_amount_b"`
+	CurrentValue   decimal.Decimal  `json:"current_value_usd"`
+
+	EntryValue             *decimal.Decimal `json:"entry_value_usd,omitempty"`
+	HODLValue              *decimal.Decimal `json:"hodl_value_usd,omitempty"`
+	ImpermanentLoss        *decimal.Decimal `json:"impermanent_loss_usd,omitempty"` // LP value minus HODL value, never positive
+	ImpermanentLossPercent *decimal.Decimal `json:"impermanent_loss_percent,omitempty"`
+	FeesEarned             decimal.Decimal  `json:"fees_earned_usd"`
+	FeesEstimated          bool             `json:"fees_estimated"`            // estimated from APY rather than reported
+	NetPnL                 *decimal.Decimal `json:"net_pnl_usd,omitempty"`     // current value plus fees minus entry value
+	NetVsHODL              *decimal.Decimal `json:"net_vs_hodl_usd,omitempty"` // impermanent loss plus fees
+
+	Complete     bool      `json:"complete"`
+	Warnings     []string  `json:"warnings,omitempty"`
+	CalculatedAt time.Time `json:"calculated_at"`
+}
+
+// GetDeFiPositionAnalytics returns LP analytics for one of a user's liquidity pool positions
+func (s *Service) GetDeFiPositionAnalytics(ctx context.Context, userID, positionID uuid.UUID) (*LPAnalytics, error) {
+	positions, _, err := s.collectDeFiPositions(ctx, userID, DeFiPositionFilter{IncludeClosed: true})
+	if err != nil {
+		return nil, err
+	}
+	position, exists := positions[positionID]
+	if !exists {
+		return nil, ErrDeFiPositionNotFound
+	}
+	if !isLiquidityPosition(position) {
+		return nil, fmt.Errorf("%w: %s", ErrNotLiquidityPosition, defiPositionType(position))
+	}
+
+	prices := s.priceDeFiPositions(ctx, positions)
+	return analyzeLPPosition(position, prices, time.Now()), nil
+}
+
+// isLiquidityPosition reports whether a position provides liquidity to a two-token pool
+func isLiquidityPosition(p *DeFiPosition) bool {
+	if p.Type == PositionTypeLiquidity {
+		return true
+	}
+	positionType := strings.ToLower(p.PositionType)
+	return strings.HasPrefix(positionType, "liquidity") || positionType == "lp"
+}
+
+// analyzeLPPosition computes LP analytics from the position's entry data and current prices.
+// Current token amounts follow from the pool model: constant product (x*y=k) for full-range
+// positions and Uniswap v3 style liquidity for positions with a price range.
+func analyzeLPPosition(p *DeFiPosition, prices map[string]TokenPrice, now time.Time) *LPAnalytics {
+	a := &LPAnalytics{
+		PositionID:     p.ID,
+		PoolType:       LPPoolConstantProduct,
+		TokenA:         p.TokenA,
+		TokenB:         p.TokenB,
+		CurrentAmountA: p.AmountA,
+		CurrentAmountB: p.AmountB,
+		CurrentValue:   valueDeFiPosition(p, prices),
+		CalculatedAt:   now,
+	}
+	warn := func(format string, args ...interface{}) {
+		a.Warnings = append(a.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	concentrated := p.PriceLower.IsPositive() || p.PriceUpper.IsPositive()
+	if concentrated {
+		a.PoolType = LPPoolConcentrated
+		if !p.PriceLower.IsPositive() || !p.PriceUpper.GreaterThan(p.PriceLower) {
+			warn("invalid price range %s-%s", p.PriceLower, p.PriceUpper)
+			concentrated = false
+		}
+	}
+
+	priceA, okA := symbolPrice(p.TokenA, prices)
+	priceB, okB := symbolPrice(p.TokenB, prices)
+	if okA {
+		a.CurrentPriceA = &priceA
+	} else {
+		warn("no current price for token %q", p.TokenA)
+	}
+	if okB {
+		a.CurrentPriceB = &priceB
+	} else {
+		warn("no current price for token %q", p.TokenB)
+	}
+
+	hasEntryAmounts := p.EntryAmountA.IsPositive() || p.EntryAmountB.IsPositive()
+	hasEntryPrices := p.EntryPriceA.IsPositive() && p.EntryPriceB.IsPositive()
+	if !hasEntryAmounts {
+		warn("entry amounts unknown, impermanent loss and net PnL unavailable")
+	} else if hasEntryPrices {
+		entryValue := p.EntryAmountA.Mul(p.EntryPriceA).Add(p.EntryAmountB.Mul(p.EntryPriceB)).Round(8)
+		a.EntryValue = &entryValue
+	} else {
+		warn("entry prices unknown, net PnL unavailable")
+	}
+
+	// Entry pool price of token A in token B
+	var entryPrice decimal.Decimal
+	switch {
+	case hasEntryPrices:
+		entryPrice = p.EntryPriceA.DivRound(p.EntryPriceB, lpPrecision)
+	case !concentrated && p.EntryAmountA.IsPositive() && p.EntryAmountB.IsPositive():
+		// A constant product pool holds equal value of both tokens
+		entryPrice = p.EntryAmountB.DivRound(p.EntryAmountA, lpPrecision)
+	}
+
+	if hasEntryAmounts && okA && okB && priceB.IsPositive() {
+		currentPrice := priceA.DivRound(priceB, lpPrecision)
+		var amountA, amountB decimal.Decimal
+		var modeled bool
+		if concentrated {
+			if entryPrice.IsPositive() {
+				amountA, amountB, modeled = concentratedAmounts(p, entryPrice, currentPrice)
+				inRange := currentPrice.GreaterThanOrEqual(p.PriceLower) && currentPrice.LessThanOrEqual(p.PriceUpper)
+				a.InRange = &inRange
+			} else {
+				warn("entry prices unknown, liquidity of the concentrated position cannot be derived")
+			}
+		} else {
+			amountA, amountB, modeled = constantProductAmounts(p, currentPrice)
+		}
+
+		if modeled {
+			a.CurrentAmountA = amountA.Round(18)
+			a.CurrentAmountB = amountB.Round(18)
+			a.CurrentValue = amountA.Mul(priceA).Add(amountB.Mul(priceB)).Round(8)
+
+			hodl := p.EntryAmountA.Mul(priceA).Add(p.EntryAmountB.Mul(priceB)).Round(8)
+			loss := a.CurrentValue.Sub(hodl)
+			if loss.IsPositive() {
+				// Rounding only, providing liquidity never beats holding before fees
+				loss = decimal.Zero
+			}
+			a.HODLValue = &hodl
+			a.ImpermanentLoss = &loss
+			if hodl.IsPositive() {
+				percent := loss.DivRound(hodl, lpPrecision).Mul(decimal.NewFromInt(100)).Round(4)
+				a.ImpermanentLossPercent = &percent
+			}
+		} else if !concentrated || entryPrice.IsPositive() {
+			warn("entry amounts do not define pool liquidity, impermanent loss unavailable")
+		}
+	}
+
+	switch {
+	case p.FeesEarned.IsPositive():
+		a.FeesEarned = p.FeesEarned
+	case p.Rewards.IsPositive():
+		a.FeesEarned = p.Rewards
+	case p.APY.IsPositive():
+		a.FeesEarned = accruedDeFiYield(p, a.CurrentValue, now)
+		a.FeesEstimated = true
+		warn("fees estimated from APY")
+	default:
+		warn("no fee data, fees assumed zero")
+	}
+
+	if a.EntryValue != nil {
+		netPnL := a.CurrentValue.Add(a.FeesEarned).Sub(*a.EntryValue).Round(8)
+		a.NetPnL = &netPnL
+	}
+	if a.ImpermanentLoss != nil {
+		netVsHODL := a.ImpermanentLoss.Add(a.FeesEarned).Round(8)
+		a.NetVsHODL = &netVsHODL
+	}
+
+	a.Complete = len(a.Warnings) == 0
+	return a
+}
+
+// constantProductAmounts returns the token amounts of a full-range position at price, keeping
+// the entry liquidity L = sqrt(x*y) constant
+func constantProductAmounts(p *DeFiPosition, price decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+	if !p.EntryAmountA.IsPositive() || !p.EntryAmountB.IsPositive() {
+		return decimal.Zero, decimal.Zero, false
+	}
+	liquidity := decimalSqrt(p.EntryAmountA.Mul(p.EntryAmountB))
+	sqrtPrice := decimalSqrt(price)
+	return liquidity.DivRound(sqrtPrice, lpPrecision), liquidity.Mul(sqrtPrice), true
+}
+
+// concentratedAmounts returns the token amounts of a position with a price range at price,
+// with the liquidity derived from its entry amounts at the entry price
+func concentratedAmounts(p *DeFiPosition, entryPrice, price decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+	sqrtLower, sqrtUpper := decimalSqrt(p.PriceLower), decimalSqrt(p.PriceUpper)
+	clamp := func(sqrtPrice decimal.Decimal) decimal.Decimal {
+		return decimal.Max(sqrtLower, decimal.Min(sqrtUpper, sqrtPrice))
+	}
+
+	// Liquidity provided by each token at the entry price; the binding side defines the position
+	sqrtEntry := clamp(decimalSqrt(entryPrice))
+	var liquidity decimal.Decimal
+	fromA, fromB := decimal.Zero, decimal.Zero
+	if sqrtEntry.LessThan(sqrtUpper) && p.EntryAmountA.IsPositive() {
+		fromA = p.EntryAmountA.Mul(sqrtEntry).Mul(sqrtUpper).DivRound(sqrtUpper.Sub(sqrtEntry), lpPrecision)
+	}
+	if sqrtEntry.GreaterThan(sqrtLower) && p.EntryAmountB.IsPositive() {
+		fromB = p.EntryAmountB.DivRound(sqrtEntry.Sub(sqrtLower), lpPrecision)
+	}
+	switch {
+	case fromA.IsPositive() && fromB.IsPositive():
+		liquidity = decimal.Min(fromA, fromB)
+	case fromA.IsPositive():
+		liquidity = fromA
+	default:
+		liquidity = fromB
+	}
+	if !liquidity.IsPositive() {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	sqrtPrice := clamp(decimalSqrt(price))
+	amountA := liquidity.Mul(sqrtUpper.Sub(sqrtPrice)).DivRound(sqrtPrice.Mul(sqrtUpper), lpPrecision)
+	amountB := liquidity.Mul(sqrtPrice.Sub(sqrtLower))
+	return amountA, amountB, true
+}
+
+// decimalSqrt returns the square root of d using Newton's method
+func decimalSqrt(d decimal.Decimal) decimal.Decimal {
+	if !d.IsPositive() {
+		return decimal.Zero
+	}
+
+	x := decimal.NewFromFloat(math.Sqrt(d.InexactFloat64()))
+	if !x.IsPositive() {
+		x = d
+	}
+	two := decimal.NewFromInt(2)
+	epsilon := decimal.New(1, -lpPrecision)
+	for i := 0; i < 100; i++ {
+		next := x.Add(d.DivRound(x, lpPrecision+4)).DivRound(two, lpPrecision+4)
+		if next.Sub(x).Abs().LessThan(epsilon) {
+			return next.Round(lpPrecision)
+		}
+		x = next
+	}
+	return x.Round(lpPrecision)
+}
@@ -0,0 +1,164 @@
+package web3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ethUSDCPosition() *DeFiPosition {
+	return &DeFiPosition{
+		ID:           uuid.New(),
+		Type:         PositionTypeLiquidity,
+		TokenA:       "ETH",
+		TokenB:       "USDC",
+		EntryAmountA: decimal.NewFromInt(1),
+		EntryAmountB: decimal.NewFromInt(2000),
+		EntryPriceA:  decimal.NewFromInt(2000),
+		EntryPriceB:  decimal.NewFromInt(1),
+		FeesEarned:   decimal.NewFromInt(150),
+		Status:       PositionStatusOpen,
+		CreatedAt:    time.Now().Add(-30 * 24 * time.Hour),
+	}
+}
+
+func ethPrices(eth float64) map[string]TokenPrice {
+	return map[string]TokenPrice{
+		"ethereum": {Price: eth},
+		"usd-coin": {Price: 1},
+	}
+}
+
+func assertDecimal(t *testing.T, expected string, actual *decimal.Decimal) {
+	t.Helper()
+	require.NotNil(t, actual)
+	assert.Equal(t, expected, actual.StringFixed(2))
+}
+
+func TestAnalyzeLPPosition(t *testing.T) {
+	now := time.Now()
+
+	t.Run("ConstantProduct", func(t *testing.T) {
+		// A 4x price move costs 20% against holding
+		analytics := analyzeLPPosition(ethUSDCPosition(), ethPrices(8000), now)
+		assert.Equal(t, LPPoolConstantProduct, analytics.PoolType)
+		assert.True(t, analytics.Complete, "%v", analytics.Warnings)
+		assert.Equal(t, "0.5000", analytics.CurrentAmountA.StringFixed(4))
+		assert.Equal(t, "4000.00", analytics.CurrentAmountB.StringFixed(2))
+		assert.Equal(t, "8000.00", analytics.CurrentValue.StringFixed(2))
+		assertDecimal(t, "4000.00", analytics.EntryValue)
+		assertDecimal(t, "10000.00", analytics.HODLValue)
+		assertDecimal(t, "-2000.00", analytics.ImpermanentLoss)
+		assertDecimal(t, "-20.00", analytics.ImpermanentLossPercent)
+		assertDecimal(t, "4150.00", analytics.NetPnL)
+		assertDecimal(t, "-1850.00", analytics.NetVsHODL)
+		assert.False(t, analytics.FeesEstimated)
+	})
+
+	t.Run("Concentrated", func(t *testing.T) {
+		position := ethUSDCPosition()
+		position.PriceLower = decimal.NewFromInt(1000)
+		position.PriceUpper = decimal.NewFromInt(4000)
+
+		unchanged := analyzeLPPosition(position, ethPrices(2000), now)
+		assert.Equal(t, LPPoolConcentrated, unchanged.PoolType)
+		require.NotNil(t, unchanged.InRange)
+		assert.True(t, *unchanged.InRange)
+		assert.Equal(t, "1.0000", unchanged.CurrentAmountA.StringFixed(4))
+		assert.Equal(t, "2000.00", unchanged.CurrentAmountB.StringFixed(2))
+		assertDecimal(t, "0.00", unchanged.ImpermanentLoss)
+
+		// Above the range the position is entirely in token B
+		above := analyzeLPPosition(position, ethPrices(8000), now)
+		require.NotNil(t, above.InRange)
+		assert.False(t, *above.InRange)
+		assert.True(t, above.CurrentAmountA.IsZero())
+		assert.Equal(t, "4828.43", above.CurrentAmountB.StringFixed(2))
+		assertDecimal(t, "-5171.57", above.ImpermanentLoss)
+	})
+
+	t.Run("MissingEntryData", func(t *testing.T) {
+		position := ethUSDCPosition()
+		position.EntryAmountA, position.EntryAmountB = decimal.Zero, decimal.Zero
+		position.AmountA, position.AmountB = decimal.NewFromInt(1), decimal.NewFromInt(2500)
+		position.FeesEarned = decimal.Zero
+		position.APY = decimal.NewFromInt(10)
+
+		analytics := analyzeLPPosition(position, ethPrices(2500), now)
+		assert.False(t, analytics.Complete)
+		assert.Len(t, analytics.Warnings, 2)
+		assert.Nil(t, analytics.ImpermanentLoss)
+		assert.Nil(t, analytics.NetPnL)
+		assert.Equal(t, "5000.00", analytics.CurrentValue.StringFixed(2), "recorded amounts are valued when entry data is missing")
+		assert.True(t, analytics.FeesEstimated)
+		assert.True(t, analytics.FeesEarned.IsPositive())
+	})
+
+	t.Run("MissingEntryPrices", func(t *testing.T) {
+		position := ethUSDCPosition()
+		position.EntryPriceA, position.EntryPriceB = decimal.Zero, decimal.Zero
+
+		// The entry pool price follows from the amounts of a constant product position
+		analytics := analyzeLPPosition(position, ethPrices(8000), now)
+		assert.False(t, analytics.Complete)
+		assertDecimal(t, "-2000.00", analytics.ImpermanentLoss)
+		assert.Nil(t, analytics.EntryValue)
+		assert.Nil(t, analytics.NetPnL)
+
+		position.PriceLower = decimal.NewFromInt(1000)
+		position.PriceUpper = decimal.NewFromInt(4000)
+		analytics = analyzeLPPosition(position, ethPrices(8000), now)
+		assert.Nil(t, analytics.ImpermanentLoss)
+		assert.NotEmpty(t, analytics.Warnings)
+	})
+
+	t.Run("MissingPrices", func(t *testing.T) {
+		analytics := analyzeLPPosition(ethUSDCPosition(), map[string]TokenPrice{}, now)
+		assert.False(t, analytics.Complete)
+		assert.Nil(t, analytics.CurrentPriceA)
+		assert.Nil(t, analytics.ImpermanentLoss)
+		assertDecimal(t, "4000.00", analytics.EntryValue)
+	})
+}
+
+func TestDecimalSqrt(t *testing.T) {
+	assert.Equal(t, "44.721359549995793928", decimalSqrt(decimal.NewFromInt(2000)).StringFixed(18))
+	assert.True(t, decimalSqrt(decimal.NewFromInt(16)).Equal(decimal.NewFromInt(4)))
+	assert.True(t, decimalSqrt(decimal.Zero).IsZero())
+}
+
+func TestGetDeFiPositionAnalytics(t *testing.T) {
+	s := newServiceWithMocks()
+	userID := uuid.New()
+	lp := ethUSDCPosition()
+	lp.UserID = userID
+	lp.IsActive = true
+	lending := &DeFiPosition{ID: uuid.New(), UserID: userID, ProtocolName: "aave", PositionType: "lending", TokenSymbol: "USDC", IsActive: true}
+	s.positionRepo = &mockPositionRepo{positions: []*DeFiPosition{lp, lending}}
+	s.priceSource = &mockPriceSource{prices: ethPrices(8000)}
+
+	analytics, err := s.GetDeFiPositionAnalytics(context.Background(), userID, lp.ID)
+	require.NoError(t, err)
+	assertDecimal(t, "-2000.00", analytics.ImpermanentLoss)
+
+	_, err = s.GetDeFiPositionAnalytics(context.Background(), userID, lending.ID)
+	assert.ErrorIs(t, err, ErrNotLiquidityPosition)
+	_, err = s.GetDeFiPositionAnalytics(context.Background(), userID, uuid.New())
+	assert.ErrorIs(t, err, ErrDeFiPositionNotFound)
+
+	resp, err := s.ListDeFiPositions(context.Background(), userID, DeFiPositionFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 2, resp.Count)
+	for _, summary := range resp.Positions {
+		if summary.ID == lp.ID {
+			require.NotNil(t, summary.Analytics)
+		} else {
+			assert.Nil(t, summary.Analytics)
+		}
+	}
+}
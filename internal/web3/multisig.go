@@ -0,0 +1,961 @@
+package web3
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrMultisigPolicyNotFound = errors.New("multisig policy not found")
+	ErrProposalNotFound       = errors.New("proposal not found")
+	ErrProposalClosed         = errors.New("proposal is no longer pending")
+	ErrNotProposalMember      = errors.New("user is not a member of the portfolio's multisig")
+	ErrAlreadyVoted           = errors.New("member already voted on the proposal")
+	ErrInvalidSafeSignature   = errors.New("invalid safe signature")
+	ErrSafeUnavailable        = errors.New("safe transaction service is not configured")
+	ErrNotSafe                = errors.New("wallet is not a safe")
+	// ErrProposalConflict means the proposal changed since it was read
+	ErrProposalConflict = errors.New("proposal was modified concurrently")
+)
+
+// TransactionPendingApproval is the status of transactions created on a multisig wallet,
+// which wait as proposals for the members' approvals
+const TransactionPendingApproval = "pending_approval"
+
+const multisigAuditPrefix = "multisig_"
+
+// ProposalStatus is where a transaction proposal is in its lifecycle
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalExecuted ProposalStatus = "executed" // built for signing, or submitted to the Safe
+	ProposalRejected ProposalStatus = "rejected" // too many rejections to reach the threshold
+	ProposalExpired  ProposalStatus = "expired"
+	ProposalFailed   ProposalStatus = "failed" // approved, but building or submitting failed
+)
+
+// MultisigPolicy makes transactions of a portfolio's treasury wallet need Threshold of the
+// members' approvals. Safe treasuries are Safe contracts whose owners sign the approved
+// transaction; other wallets sign it through the portfolio owner's signing flow.
+type MultisigPolicy struct {
+	PortfolioID uuid.UUID   `json:"portfolio_id"`
+	OwnerID     uuid.UUID   `json:"owner_id"`
+	WalletID    uuid.UUID   `json:"wallet_id"`
+	ChainID     int         `json:"chain_id"`
+	Address     string      `json:"address"`
+	Safe        bool        `json:"safe"`
+	Members     []uuid.UUID `json:"members"`
+	Threshold   int         `json:"threshold"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func (p *MultisigPolicy) isMember(userID uuid.UUID) bool {
+	for _, member := range p.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// MultisigPolicyRequest configures a portfolio's multisig; the owner is always a member
+type MultisigPolicyRequest struct {
+	WalletID  uuid.UUID   `json:"wallet_id"`
+	Members   []uuid.UUID `json:"members"`
+	Threshold int         `json:"threshold"`
+	Safe      bool        `json:"safe"`
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *MultisigPolicyRequest) Validate() error {
+	var v validation.Validator
+	if r.WalletID == uuid.Nil {
+		v.Fail("wallet_id", validation.ConstraintRequired, "is required")
+	}
+	seen := make(map[uuid.UUID]bool, len(r.Members))
+	for _, member := range r.Members {
+		if member == uuid.Nil || seen[member] {
+			v.Fail("members", validation.ConstraintFormat, "must be distinct user IDs")
+			break
+		}
+		seen[member] = true
+	}
+	if r.Threshold < 1 {
+		v.Fail("threshold", validation.ConstraintRange, "must be at least 1")
+	}
+	return v.Err()
+}
+
+// ProposalVote is a member's approval or rejection. Safe approvals carry the member's
+// signature of the Safe transaction hash and the Safe owner that made it.
+type ProposalVote struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Approve   bool      `json:"approve"`
+	Reason    string    `json:"reason,omitempty"`
+	Signer    string    `json:"signer,omitempty"`
+	Signature string    `json:"signature,omitempty"`
+	VotedAt   time.Time `json:"voted_at"`
+}
+
+// SafeProposal is the Safe transaction an approved proposal submits
+type SafeProposal struct {
+	Address    string   `json:"address"`
+	Nonce      uint64   `json:"nonce"`
+	SafeTxHash string   `json:"safe_tx_hash"`
+	Owners     []string `json:"owners"`
+}
+
+// TransactionProposal is a transaction waiting for the approval of a multisig's members.
+// Members and threshold are those of the policy when it was proposed.
+type TransactionProposal struct {
+	ID          uuid.UUID             `json:"id"`
+	PortfolioID uuid.UUID             `json:"portfolio_id"`
+	WalletID    uuid.UUID             `json:"wallet_id"`
+	ChainID     int                   `json:"chain_id"`
+	ProposerID  uuid.UUID             `json:"proposer_id"`
+	Status      ProposalStatus        `json:"status"`
+	Request     TransactionRequest    `json:"request"`
+	Recipient   *RecipientResolution  `json:"recipient,omitempty"`
+	Screening   *TransactionScreening `json:"screening,omitempty"`
+	Members     []uuid.UUID           `json:"members"`
+	Threshold   int                   `json:"threshold"`
+	Votes       []ProposalVote        `json:"votes"`
+	Approvals   int                   `json:"approvals"`
+	Rejections  int                   `json:"rejections"`
+	Safe        *SafeProposal         `json:"safe,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	ExpiresAt   time.Time             `json:"expires_at"`
+
+	// Set once executed: the built transaction and its signing request
+	TransactionID    *uuid.UUID `json:"transaction_id,omitempty"`
+	SigningRequestID *uuid.UUID `json:"signing_request_id,omitempty"`
+
+	// Version increases with every update, guarding concurrent votes
+	Version int `json:"version"`
+}
+
+// tally counts the votes and reports whether the outcome is decided
+func (p *TransactionProposal) tally() {
+	p.Approvals, p.Rejections = 0, 0
+	for _, vote := range p.Votes {
+		if vote.Approve {
+			p.Approvals++
+		} else {
+			p.Rejections++
+		}
+	}
+}
+
+func (p *TransactionProposal) vote(userID uuid.UUID) *ProposalVote {
+	for i := range p.Votes {
+		if p.Votes[i].UserID == userID {
+			return &p.Votes[i]
+		}
+	}
+	return nil
+}
+
+func (p *TransactionProposal) isMember(userID uuid.UUID) bool {
+	for _, member := range p.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ProposalRequest proposes a transaction from a portfolio's multisig wallet
+type ProposalRequest struct {
+	PortfolioID uuid.UUID `json:"portfolio_id"`
+	TransactionRequest
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *ProposalRequest) Validate() error {
+	var v validation.Validator
+	if r.PortfolioID == uuid.Nil {
+		v.Fail("portfolio_id", validation.ConstraintRequired, "is required")
+	}
+	to := r.ToAddress
+	if to == "" {
+		to = r.To
+	}
+	v.Required("to_address", to)
+	if r.Value != nil && r.Value.Sign() < 0 {
+		v.Fail("value", validation.ConstraintFormat, "must not be negative")
+	}
+	return v.Err()
+}
+
+// ProposalVoteRequest approves or rejects a proposal; approvals of Safe proposals carry the
+// member's signature of the Safe transaction hash
+type ProposalVoteRequest struct {
+	Signature string `json:"signature,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// MultisigStore keeps multisig policies and proposals
+type MultisigStore interface {
+	SavePolicy(ctx context.Context, policy *MultisigPolicy) error
+	// GetPolicy and GetPolicyByWallet return nil when there is none
+	GetPolicy(ctx context.Context, portfolioID uuid.UUID) (*MultisigPolicy, error)
+	GetPolicyByWallet(ctx context.Context, walletID uuid.UUID) (*MultisigPolicy, error)
+
+	CreateProposal(ctx context.Context, proposal *TransactionProposal) error
+	// UpdateProposal saves the proposal if it is still at its version, then increments it;
+	// otherwise it returns ErrProposalConflict
+	UpdateProposal(ctx context.Context, proposal *TransactionProposal) error
+	// GetProposal returns nil when there is none
+	GetProposal(ctx context.Context, id uuid.UUID) (*TransactionProposal, error)
+	// ListProposals returns the proposals the user is a member of, newest first
+	ListProposals(ctx context.Context, userID uuid.UUID, filter ProposalFilter) ([]*TransactionProposal, error)
+	ListPendingProposals(ctx context.Context) ([]*TransactionProposal, error)
+}
+
+// ProposalFilter narrows proposal listings; zero values match everything
+type ProposalFilter struct {
+	PortfolioID uuid.UUID
+	Status      ProposalStatus
+}
+
+// MultisigConfig configures multisig coordination
+type MultisigConfig struct {
+	ProposalTTL   time.Duration // how long members have to reach the threshold
+	SweepInterval time.Duration // how often expired proposals are closed
+}
+
+// portfolioSource looks up portfolios to check who owns them
+type portfolioSource interface {
+	GetPortfolio(portfolioID uuid.UUID) (*Portfolio, error)
+}
+
+// MultisigCoordinator gathers the approvals of a shared portfolio's members before its
+// transactions are built. Transactions created on a multisig wallet become proposals; once
+// enough members approve, the transaction is submitted to the Safe transaction service with
+// the members' signatures, or built and handed to the owner's wallet for signing. Every
+// action is audit-logged.
+type MultisigCoordinator struct {
+	service    *Service
+	portfolios portfolioSource
+	store      MultisigStore
+	safe       SafeTransactionService
+	auditor    *security.AuditManager
+	logger     *observability.Logger
+	config     MultisigConfig
+	now        func() time.Time
+}
+
+// NewMultisigCoordinator creates a coordinator for the portfolios' multisig wallets
+func NewMultisigCoordinator(service *Service, portfolios portfolioSource, store MultisigStore, cfg MultisigConfig) *MultisigCoordinator {
+	if cfg.ProposalTTL <= 0 {
+		cfg.ProposalTTL = 72 * time.Hour
+	}
+	return &MultisigCoordinator{
+		service:    service,
+		portfolios: portfolios,
+		store:      store,
+		logger:     service.logger,
+		config:     cfg,
+		now:        time.Now,
+	}
+}
+
+// SetAuditor records policy changes and proposal votes in the audit log
+func (c *MultisigCoordinator) SetAuditor(auditor *security.AuditManager) {
+	c.auditor = auditor
+}
+
+// SetSafeTransactionService enables Safe treasuries, whose approved transactions are
+// submitted to the Safe transaction service
+func (c *MultisigCoordinator) SetSafeTransactionService(safe SafeTransactionService) {
+	c.safe = safe
+}
+
+// Start closes expired proposals on the sweep interval until ctx is done
+func (c *MultisigCoordinator) Start(ctx context.Context) {
+	interval := c.config.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.ExpireStale(ctx)
+			}
+		}
+	}()
+}
+
+// SetPolicy makes the portfolio's treasury wallet require the members' approvals. Only the
+// portfolio owner may change it; proposals already pending keep the policy they started with.
+func (c *MultisigCoordinator) SetPolicy(ctx context.Context, userID, portfolioID uuid.UUID, req MultisigPolicyRequest) (*MultisigPolicy, error) {
+	portfolio, err := c.portfolios.GetPortfolio(portfolioID)
+	if err != nil || portfolio.UserID != userID {
+		return nil, ErrPortfolioNotFound
+	}
+	wallet, err := c.service.walletRepo.GetByID(ctx, req.WalletID)
+	if err != nil || wallet.UserID != userID {
+		return nil, ErrWalletNotFound
+	}
+	// Only Safe contracts can hold the treasury without a key; other wallets must sign
+	if !req.Safe && wallet.WatchOnly {
+		return nil, fmt.Errorf("%w: %s", ErrWatchOnlyWallet, wallet.Address)
+	}
+	if req.Safe {
+		if c.safe == nil {
+			return nil, ErrSafeUnavailable
+		}
+		if _, err := c.safe.GetSafe(ctx, wallet.ChainID, wallet.Address); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotSafe, err)
+		}
+	}
+
+	members := []uuid.UUID{userID}
+	for _, member := range req.Members {
+		if member != userID {
+			members = append(members, member)
+		}
+	}
+	if req.Threshold > len(members) {
+		var v validation.Validator
+		v.Fail("threshold", validation.ConstraintRange, "must not exceed the number of members")
+		return nil, v.Err()
+	}
+
+	now := c.now()
+	policy := &MultisigPolicy{
+		PortfolioID: portfolioID,
+		OwnerID:     userID,
+		WalletID:    wallet.ID,
+		ChainID:     wallet.ChainID,
+		Address:     wallet.Address,
+		Safe:        req.Safe,
+		Members:     members,
+		Threshold:   req.Threshold,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if existing, err := c.store.GetPolicy(ctx, portfolioID); err != nil {
+		return nil, fmt.Errorf("failed to get multisig policy: %w", err)
+	} else if existing != nil {
+		policy.CreatedAt = existing.CreatedAt
+	}
+	if err := c.store.SavePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save multisig policy: %w", err)
+	}
+
+	c.audit(ctx, userID, "policy_updated", security.AuditResultSuccess, map[string]interface{}{
+		"portfolio_id": portfolioID.String(),
+		"wallet_id":    wallet.ID.String(),
+		"members":      len(members),
+		"threshold":    policy.Threshold,
+		"safe":         policy.Safe,
+	})
+	return policy, nil
+}
+
+// GetPolicy returns the portfolio's multisig policy to its members
+func (c *MultisigCoordinator) GetPolicy(ctx context.Context, userID, portfolioID uuid.UUID) (*MultisigPolicy, error) {
+	policy, err := c.store.GetPolicy(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get multisig policy: %w", err)
+	}
+	if policy == nil || !policy.isMember(userID) {
+		return nil, ErrMultisigPolicyNotFound
+	}
+	return policy, nil
+}
+
+// policyForWallet returns the multisig policy governing the wallet, or nil
+func (c *MultisigCoordinator) policyForWallet(ctx context.Context, walletID uuid.UUID) (*MultisigPolicy, error) {
+	policy, err := c.store.GetPolicyByWallet(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get multisig policy: %w", err)
+	}
+	return policy, nil
+}
+
+// proposeForWallet turns a transaction from a multisig wallet into a proposal, returning nil
+// for wallets no policy governs. Users outside the multisig don't learn the wallet exists.
+func (c *MultisigCoordinator) proposeForWallet(ctx context.Context, userID uuid.UUID, req TransactionRequest) (*TransactionResponse, error) {
+	policy, err := c.policyForWallet(ctx, req.WalletID)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	if !policy.isMember(userID) {
+		return nil, ErrWalletNotFound
+	}
+	proposal, err := c.propose(ctx, userID, policy, req)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionResponse{
+		Status:    TransactionPendingApproval,
+		Message:   fmt.Sprintf("Transaction needs %d approvals", proposal.Threshold),
+		Recipient: proposal.Recipient,
+		Screening: proposal.Screening,
+		Proposal:  proposal,
+	}, nil
+}
+
+// Propose opens a proposal for a transaction from the portfolio's multisig wallet. Any
+// member may propose; the recipient is resolved and screened as for direct transactions.
+func (c *MultisigCoordinator) Propose(ctx context.Context, userID uuid.UUID, req ProposalRequest) (*TransactionProposal, error) {
+	policy, err := c.GetPolicy(ctx, userID, req.PortfolioID)
+	if err != nil {
+		return nil, err
+	}
+	return c.propose(ctx, userID, policy, req.TransactionRequest)
+}
+
+func (c *MultisigCoordinator) propose(ctx context.Context, userID uuid.UUID, policy *MultisigPolicy, req TransactionRequest) (*TransactionProposal, error) {
+	if !policy.isMember(userID) {
+		return nil, ErrNotProposalMember
+	}
+	req.WalletID = policy.WalletID
+	if req.Value == nil {
+		req.Value = new(big.Int)
+	}
+
+	to := req.ToAddress
+	if to == "" {
+		to = req.To
+	}
+	recipient, err := c.service.resolveRecipient(ctx, userID, policy.ChainID, to)
+	if err != nil {
+		return nil, err
+	}
+	req.ToAddress, req.To = recipient.Address, ""
+
+	var screening *TransactionScreening
+	if c.service.screener != nil {
+		screening, err = c.service.screener.CheckTransaction(ctx, userID, TransactionScreeningRequest{
+			ChainID:   policy.ChainID,
+			From:      policy.Address,
+			ToAddress: recipient.Address,
+			Value:     req.Value,
+			Data:      req.Data,
+		}, req.AcknowledgeRisk)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := c.now()
+	proposal := &TransactionProposal{
+		ID:          uuid.New(),
+		PortfolioID: policy.PortfolioID,
+		WalletID:    policy.WalletID,
+		ChainID:     policy.ChainID,
+		ProposerID:  userID,
+		Status:      ProposalPending,
+		Request:     req,
+		Recipient:   recipient,
+		Screening:   screening,
+		Members:     policy.Members,
+		Threshold:   policy.Threshold,
+		Votes:       []ProposalVote{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   now.Add(c.config.ProposalTTL),
+	}
+
+	// Safe owners sign the Safe transaction hash, which fixes the Safe's nonce now
+	if policy.Safe {
+		if c.safe == nil {
+			return nil, ErrSafeUnavailable
+		}
+		info, err := c.safe.GetSafe(ctx, policy.ChainID, policy.Address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read safe: %w", err)
+		}
+		safeTx := SafeTransactionData{
+			To:    common.HexToAddress(req.ToAddress),
+			Value: req.Value,
+			Data:  common.FromHex(req.Data),
+			Nonce: info.Nonce,
+		}
+		proposal.Safe = &SafeProposal{
+			Address:    common.HexToAddress(policy.Address).Hex(),
+			Nonce:      info.Nonce,
+			SafeTxHash: SafeTransactionHash(policy.ChainID, common.HexToAddress(policy.Address), safeTx).Hex(),
+			Owners:     info.Owners,
+		}
+	}
+
+	if err := c.store.CreateProposal(ctx, proposal); err != nil {
+		return nil, fmt.Errorf("failed to save proposal: %w", err)
+	}
+	c.auditProposal(ctx, userID, proposal, "proposal_created", security.AuditResultSuccess, nil)
+	return proposal, nil
+}
+
+// ListProposals returns the proposals of the user's multisigs, newest first
+func (c *MultisigCoordinator) ListProposals(ctx context.Context, userID uuid.UUID, filter ProposalFilter) ([]*TransactionProposal, error) {
+	proposals, err := c.store.ListProposals(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proposals: %w", err)
+	}
+	matched := make([]*TransactionProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		c.expireIfStale(ctx, proposal)
+		if filter.Status == "" || proposal.Status == filter.Status {
+			matched = append(matched, proposal)
+		}
+	}
+	return matched, nil
+}
+
+// GetProposal returns a proposal to the members of its multisig
+func (c *MultisigCoordinator) GetProposal(ctx context.Context, userID, id uuid.UUID) (*TransactionProposal, error) {
+	proposal, err := c.store.GetProposal(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal: %w", err)
+	}
+	if proposal == nil || !proposal.isMember(userID) {
+		return nil, ErrProposalNotFound
+	}
+	c.expireIfStale(ctx, proposal)
+	return proposal, nil
+}
+
+// Approve records the member's approval; the approval that reaches the threshold executes
+// the proposal. Safe proposals need the member's signature of the Safe transaction hash, made
+// by a Safe owner.
+func (c *MultisigCoordinator) Approve(ctx context.Context, userID, id uuid.UUID, req ProposalVoteRequest) (*TransactionProposal, error) {
+	return c.castVote(ctx, userID, id, func(proposal *TransactionProposal) (*ProposalVote, error) {
+		vote := &ProposalVote{UserID: userID, Approve: true}
+		if proposal.Safe != nil {
+			signer, err := c.verifySafeSignature(proposal, req.Signature)
+			if err != nil {
+				return nil, err
+			}
+			vote.Signer, vote.Signature = signer, req.Signature
+		}
+		return vote, nil
+	})
+}
+
+// Reject records the member's rejection; the proposal is rejected once the remaining
+// members can no longer reach the threshold
+func (c *MultisigCoordinator) Reject(ctx context.Context, userID, id uuid.UUID, req ProposalVoteRequest) (*TransactionProposal, error) {
+	return c.castVote(ctx, userID, id, func(*TransactionProposal) (*ProposalVote, error) {
+		return &ProposalVote{UserID: userID, Approve: false, Reason: req.Reason}, nil
+	})
+}
+
+// castVote adds the member's vote and settles the outcome, retrying when another member
+// voted at the same time. Only the update that decides the proposal executes it.
+func (c *MultisigCoordinator) castVote(ctx context.Context, userID, id uuid.UUID, makeVote func(*TransactionProposal) (*ProposalVote, error)) (*TransactionProposal, error) {
+	for attempt := 0; ; attempt++ {
+		proposal, err := c.GetProposal(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal.Status != ProposalPending {
+			return nil, fmt.Errorf("%w: %s", ErrProposalClosed, proposal.Status)
+		}
+		if proposal.vote(userID) != nil {
+			return nil, ErrAlreadyVoted
+		}
+		vote, err := makeVote(proposal)
+		if err != nil {
+			c.auditProposal(ctx, userID, proposal, "proposal_vote", security.AuditResultDenied, map[string]interface{}{"error": err.Error()})
+			return nil, err
+		}
+		vote.VotedAt = c.now()
+		proposal.Votes = append(proposal.Votes, *vote)
+		proposal.tally()
+		proposal.UpdatedAt = vote.VotedAt
+
+		approved := proposal.Approvals >= proposal.Threshold
+		if !approved && len(proposal.Members)-proposal.Rejections < proposal.Threshold {
+			proposal.Status = ProposalRejected
+		}
+
+		if err := c.store.UpdateProposal(ctx, proposal); err != nil {
+			if errors.Is(err, ErrProposalConflict) && attempt < 3 {
+				continue
+			}
+			return nil, fmt.Errorf("failed to save proposal: %w", err)
+		}
+
+		action := "proposal_approved"
+		if !vote.Approve {
+			action = "proposal_rejected"
+		}
+		c.auditProposal(ctx, userID, proposal, action, security.AuditResultSuccess, map[string]interface{}{
+			"approvals":  proposal.Approvals,
+			"rejections": proposal.Rejections,
+		})
+		if proposal.Status == ProposalRejected {
+			c.auditProposal(ctx, userID, proposal, "proposal_closed", security.AuditResultSuccess, map[string]interface{}{"status": string(ProposalRejected)})
+		}
+		if approved {
+			c.execute(ctx, userID, proposal)
+		}
+		return proposal, nil
+	}
+}
+
+// execute submits an approved proposal. Safe proposals go to the Safe transaction service
+// with the approvers' signatures; other proposals are built like a transaction the owner
+// created, and handed to their wallet for signing.
+func (c *MultisigCoordinator) execute(ctx context.Context, userID uuid.UUID, proposal *TransactionProposal) {
+	var err error
+	if proposal.Safe != nil {
+		err = c.submitToSafe(ctx, proposal)
+	} else {
+		err = c.buildTransaction(ctx, proposal)
+	}
+
+	if err != nil {
+		proposal.Status = ProposalFailed
+		proposal.Error = err.Error()
+	} else {
+		proposal.Status = ProposalExecuted
+	}
+	proposal.UpdatedAt = c.now()
+	if saveErr := c.store.UpdateProposal(ctx, proposal); saveErr != nil {
+		c.logger.Error(ctx, "Failed to save executed proposal", saveErr, map[string]interface{}{"proposal_id": proposal.ID.String()})
+	}
+
+	details := map[string]interface{}{}
+	result := security.AuditResultSuccess
+	if err != nil {
+		result = security.AuditResultFailure
+		details["error"] = err.Error()
+	}
+	if proposal.TransactionID != nil {
+		details["transaction_id"] = proposal.TransactionID.String()
+	}
+	if proposal.Safe != nil {
+		details["safe_tx_hash"] = proposal.Safe.SafeTxHash
+	}
+	c.auditProposal(ctx, userID, proposal, "proposal_executed", result, details)
+}
+
+func (c *MultisigCoordinator) buildTransaction(ctx context.Context, proposal *TransactionProposal) error {
+	policy, err := c.store.GetPolicy(ctx, proposal.PortfolioID)
+	if err != nil {
+		return fmt.Errorf("get multisig policy: %w", err)
+	}
+	if policy == nil {
+		return ErrMultisigPolicyNotFound
+	}
+	resp, err := c.service.createTransaction(ctx, policy.OwnerID, proposal.Request, false)
+	if err != nil {
+		return err
+	}
+	proposal.TransactionID = &resp.Transaction.ID
+	if resp.SigningRequest != nil {
+		proposal.SigningRequestID = &resp.SigningRequest.ID
+	}
+	return nil
+}
+
+func (c *MultisigCoordinator) submitToSafe(ctx context.Context, proposal *TransactionProposal) error {
+	if c.safe == nil {
+		return ErrSafeUnavailable
+	}
+	var signatures []ProposalVote
+	for _, vote := range proposal.Votes {
+		if vote.Approve {
+			signatures = append(signatures, vote)
+		}
+	}
+	value := proposal.Request.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	safeTx := SafeTransactionData{
+		To:    common.HexToAddress(proposal.Request.ToAddress),
+		Value: value,
+		Data:  common.FromHex(proposal.Request.Data),
+		Nonce: proposal.Safe.Nonce,
+	}
+	first := signatures[0]
+	if err := c.safe.ProposeTransaction(ctx, proposal.ChainID, proposal.Safe.Address, safeTx, proposal.Safe.SafeTxHash, first.Signer, first.Signature); err != nil {
+		return fmt.Errorf("propose safe transaction: %w", err)
+	}
+	for _, vote := range signatures[1:] {
+		if err := c.safe.ConfirmTransaction(ctx, proposal.ChainID, proposal.Safe.SafeTxHash, vote.Signature); err != nil {
+			return fmt.Errorf("confirm safe transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// verifySafeSignature checks the signature is a Safe owner's, not yet used by another member
+func (c *MultisigCoordinator) verifySafeSignature(proposal *TransactionProposal, signature string) (string, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(signature))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSafeSignature, err)
+	}
+	signer, err := RecoverSafeSigner(common.HexToHash(proposal.Safe.SafeTxHash), raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSafeSignature, err)
+	}
+	owner := false
+	for _, address := range proposal.Safe.Owners {
+		if common.HexToAddress(address) == signer {
+			owner = true
+			break
+		}
+	}
+	if !owner {
+		return "", fmt.Errorf("%w: %s is not an owner of the safe", ErrInvalidSafeSignature, signer.Hex())
+	}
+	for _, vote := range proposal.Votes {
+		if vote.Signer != "" && common.HexToAddress(vote.Signer) == signer {
+			return "", fmt.Errorf("%w: %s already signed", ErrInvalidSafeSignature, signer.Hex())
+		}
+	}
+	return signer.Hex(), nil
+}
+
+// ExpireStale closes pending proposals past their expiry and returns how many it closed
+func (c *MultisigCoordinator) ExpireStale(ctx context.Context) int {
+	proposals, err := c.store.ListPendingProposals(ctx)
+	if err != nil {
+		c.logger.Error(ctx, "Failed to list pending proposals", err)
+		return 0
+	}
+	expired := 0
+	for _, proposal := range proposals {
+		if c.expireIfStale(ctx, proposal) {
+			expired++
+		}
+	}
+	return expired
+}
+
+// expireIfStale closes a pending proposal past its expiry, reporting whether it did
+func (c *MultisigCoordinator) expireIfStale(ctx context.Context, proposal *TransactionProposal) bool {
+	if proposal.Status != ProposalPending || c.now().Before(proposal.ExpiresAt) {
+		return false
+	}
+	proposal.Status = ProposalExpired
+	proposal.UpdatedAt = c.now()
+	if err := c.store.UpdateProposal(ctx, proposal); err != nil {
+		if !errors.Is(err, ErrProposalConflict) {
+			c.logger.Error(ctx, "Failed to expire proposal", err, map[string]interface{}{"proposal_id": proposal.ID.String()})
+		}
+		return false
+	}
+	c.auditProposal(ctx, proposal.ProposerID, proposal, "proposal_expired", security.AuditResultSuccess, nil)
+	return true
+}
+
+func (c *MultisigCoordinator) auditProposal(ctx context.Context, userID uuid.UUID, proposal *TransactionProposal, action string, result security.AuditResult, extra map[string]interface{}) {
+	details := map[string]interface{}{
+		"proposal_id":  proposal.ID.String(),
+		"portfolio_id": proposal.PortfolioID.String(),
+		"wallet_id":    proposal.WalletID.String(),
+		"chain_id":     proposal.ChainID,
+		"to":           proposal.Request.ToAddress,
+		"status":       string(proposal.Status),
+	}
+	if proposal.Request.Value != nil {
+		details["value"] = proposal.Request.Value.String()
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+	c.audit(ctx, userID, action, result, details)
+}
+
+func (c *MultisigCoordinator) audit(ctx context.Context, userID uuid.UUID, action string, result security.AuditResult, details map[string]interface{}) {
+	if c.auditor == nil {
+		return
+	}
+	if err := c.auditor.LogTradingEvent(ctx, &userID, multisigAuditPrefix+action, result, details); err != nil {
+		c.logger.Error(ctx, "Failed to audit multisig action", err, details)
+	}
+}
+
+// postgresMultisigStore keeps policies and proposals in Postgres. Proposals are stored as
+// JSON with the columns they are looked up by.
+type postgresMultisigStore struct {
+	db *database.DB
+}
+
+// NewPostgresMultisigStore creates a multisig store backed by Postgres
+func NewPostgresMultisigStore(db *database.DB) MultisigStore {
+	return &postgresMultisigStore{db: db}
+}
+
+const multisigPolicyColumns = `portfolio_id, owner_id, wallet_id, chain_id, address, safe, members, threshold, created_at, updated_at`
+
+func (s *postgresMultisigStore) SavePolicy(ctx context.Context, p *MultisigPolicy) error {
+	query := `
+		INSERT INTO web3_multisig_policies (` + multisigPolicyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (portfolio_id) DO UPDATE SET
+			wallet_id = EXCLUDED.wallet_id, chain_id = EXCLUDED.chain_id, address = EXCLUDED.address,
+			safe = EXCLUDED.safe, members = EXCLUDED.members, threshold = EXCLUDED.threshold,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.db.ExecWithMetrics(ctx, query, p.PortfolioID, p.OwnerID, p.WalletID, p.ChainID, strings.ToLower(p.Address), p.Safe,
+		pq.Array(uuidStrings(p.Members)), p.Threshold, p.CreatedAt, p.UpdatedAt)
+	return err
+}
+
+func (s *postgresMultisigStore) GetPolicy(ctx context.Context, portfolioID uuid.UUID) (*MultisigPolicy, error) {
+	return s.getPolicy(ctx, `SELECT `+multisigPolicyColumns+` FROM web3_multisig_policies WHERE portfolio_id = $1`, portfolioID)
+}
+
+func (s *postgresMultisigStore) GetPolicyByWallet(ctx context.Context, walletID uuid.UUID) (*MultisigPolicy, error) {
+	return s.getPolicy(ctx, `SELECT `+multisigPolicyColumns+` FROM web3_multisig_policies WHERE wallet_id = $1 LIMIT 1`, walletID)
+}
+
+func (s *postgresMultisigStore) getPolicy(ctx context.Context, query string, arg any) (*MultisigPolicy, error) {
+	p := &MultisigPolicy{}
+	var members []string
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(&p.PortfolioID, &p.OwnerID, &p.WalletID, &p.ChainID, &p.Address, &p.Safe,
+		pq.Array(&members), &p.Threshold, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		id, err := uuid.Parse(member)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multisig member %q: %w", member, err)
+		}
+		p.Members = append(p.Members, id)
+	}
+	return p, nil
+}
+
+func (s *postgresMultisigStore) CreateProposal(ctx context.Context, p *TransactionProposal) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal proposal: %w", err)
+	}
+	query := `
+		INSERT INTO web3_multisig_proposals (id, portfolio_id, members, status, expires_at, version, data, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = s.db.ExecWithMetrics(ctx, query, p.ID, p.PortfolioID, pq.Array(uuidStrings(p.Members)), p.Status, p.ExpiresAt, p.Version, data, p.CreatedAt, p.UpdatedAt)
+	return err
+}
+
+func (s *postgresMultisigStore) UpdateProposal(ctx context.Context, p *TransactionProposal) error {
+	expected := p.Version
+	p.Version++
+	data, err := json.Marshal(p)
+	if err != nil {
+		p.Version = expected
+		return fmt.Errorf("failed to marshal proposal: %w", err)
+	}
+	query := `
+		UPDATE web3_multisig_proposals SET status = $1, version = $2, data = $3, updated_at = $4
+		WHERE id = $5 AND version = $6
+	`
+	result, err := s.db.ExecWithMetrics(ctx, query, p.Status, p.Version, data, p.UpdatedAt, p.ID, expected)
+	if err != nil {
+		p.Version = expected
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		p.Version = expected
+		return ErrProposalConflict
+	}
+	return nil
+}
+
+func (s *postgresMultisigStore) GetProposal(ctx context.Context, id uuid.UUID) (*TransactionProposal, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM web3_multisig_proposals WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProposal(data)
+}
+
+func (s *postgresMultisigStore) ListProposals(ctx context.Context, userID uuid.UUID, filter ProposalFilter) ([]*TransactionProposal, error) {
+	query := `SELECT data FROM web3_multisig_proposals WHERE $1 = ANY(members)`
+	args := []any{userID.String()}
+	if filter.PortfolioID != uuid.Nil {
+		args = append(args, filter.PortfolioID)
+		query += fmt.Sprintf(` AND portfolio_id = $%d`, len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+	query += ` ORDER BY created_at DESC LIMIT 200`
+	return s.list(ctx, query, args...)
+}
+
+func (s *postgresMultisigStore) ListPendingProposals(ctx context.Context) ([]*TransactionProposal, error) {
+	return s.list(ctx, `SELECT data FROM web3_multisig_proposals WHERE status = $1`, ProposalPending)
+}
+
+func (s *postgresMultisigStore) list(ctx context.Context, query string, args ...any) ([]*TransactionProposal, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var proposals []*TransactionProposal
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		proposal, err := unmarshalProposal(data)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, rows.Err()
+}
+
+func unmarshalProposal(data []byte) (*TransactionProposal, error) {
+	var proposal TransactionProposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal: %w", err)
+	}
+	return &proposal, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
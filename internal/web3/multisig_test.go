@@ -0,0 +1,448 @@
+package web3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMultisigStore keeps proposals as JSON, like the Postgres store
+type memoryMultisigStore struct {
+	mu        sync.Mutex
+	policies  map[uuid.UUID]MultisigPolicy
+	proposals map[uuid.UUID][]byte
+}
+
+func newMemoryMultisigStore() *memoryMultisigStore {
+	return &memoryMultisigStore{policies: make(map[uuid.UUID]MultisigPolicy), proposals: make(map[uuid.UUID][]byte)}
+}
+
+func (m *memoryMultisigStore) SavePolicy(ctx context.Context, policy *MultisigPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policies[policy.PortfolioID] = *policy
+	return nil
+}
+
+func (m *memoryMultisigStore) GetPolicy(ctx context.Context, portfolioID uuid.UUID) (*MultisigPolicy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	policy, ok := m.policies[portfolioID]
+	if !ok {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func (m *memoryMultisigStore) GetPolicyByWallet(ctx context.Context, walletID uuid.UUID) (*MultisigPolicy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, policy := range m.policies {
+		if policy.WalletID == walletID {
+			return &policy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryMultisigStore) CreateProposal(ctx context.Context, proposal *TransactionProposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, err := json.Marshal(proposal)
+	m.proposals[proposal.ID] = data
+	return err
+}
+
+func (m *memoryMultisigStore) UpdateProposal(ctx context.Context, proposal *TransactionProposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, err := unmarshalProposal(m.proposals[proposal.ID])
+	if err != nil {
+		return err
+	}
+	if stored.Version != proposal.Version {
+		return ErrProposalConflict
+	}
+	proposal.Version++
+	data, err := json.Marshal(proposal)
+	m.proposals[proposal.ID] = data
+	return err
+}
+
+func (m *memoryMultisigStore) GetProposal(ctx context.Context, id uuid.UUID) (*TransactionProposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.proposals[id]
+	if !ok {
+		return nil, nil
+	}
+	return unmarshalProposal(data)
+}
+
+func (m *memoryMultisigStore) list(match func(*TransactionProposal) bool) ([]*TransactionProposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var proposals []*TransactionProposal
+	for _, data := range m.proposals {
+		proposal, err := unmarshalProposal(data)
+		if err != nil {
+			return nil, err
+		}
+		if match(proposal) {
+			proposals = append(proposals, proposal)
+		}
+	}
+	sort.Slice(proposals, func(i, j int) bool { return proposals[i].CreatedAt.After(proposals[j].CreatedAt) })
+	return proposals, nil
+}
+
+func (m *memoryMultisigStore) ListProposals(ctx context.Context, userID uuid.UUID, filter ProposalFilter) ([]*TransactionProposal, error) {
+	return m.list(func(p *TransactionProposal) bool {
+		return p.isMember(userID) && (filter.PortfolioID == uuid.Nil || p.PortfolioID == filter.PortfolioID)
+	})
+}
+
+func (m *memoryMultisigStore) ListPendingProposals(ctx context.Context) ([]*TransactionProposal, error) {
+	return m.list(func(p *TransactionProposal) bool { return p.Status == ProposalPending })
+}
+
+type fakePortfolios map[uuid.UUID]*Portfolio
+
+func (f fakePortfolios) GetPortfolio(portfolioID uuid.UUID) (*Portfolio, error) {
+	if portfolio, ok := f[portfolioID]; ok {
+		return portfolio, nil
+	}
+	return nil, ErrPortfolioNotFound
+}
+
+type safeProposeCall struct {
+	safe, safeTxHash, sender, signature string
+	tx                                  SafeTransactionData
+}
+
+type fakeSafeService struct {
+	info     SafeInfo
+	proposed []safeProposeCall
+	confirms []string
+}
+
+func (f *fakeSafeService) GetSafe(ctx context.Context, chainID int, address string) (*SafeInfo, error) {
+	info := f.info
+	return &info, nil
+}
+
+func (f *fakeSafeService) ProposeTransaction(ctx context.Context, chainID int, safe string, tx SafeTransactionData, safeTxHash, sender, signature string) error {
+	f.proposed = append(f.proposed, safeProposeCall{safe: safe, safeTxHash: safeTxHash, sender: sender, signature: signature, tx: tx})
+	return nil
+}
+
+func (f *fakeSafeService) ConfirmTransaction(ctx context.Context, chainID int, safeTxHash, signature string) error {
+	f.confirms = append(f.confirms, signature)
+	return nil
+}
+
+type multisigFixture struct {
+	*signingFixture
+	coordinator *MultisigCoordinator
+	store       *memoryMultisigStore
+	portfolio   *Portfolio
+	owner       uuid.UUID
+	members     []uuid.UUID
+}
+
+// newMultisigFixture makes the signing fixture's wallet the treasury of a portfolio shared
+// by its owner and two members, needing two approvals
+func newMultisigFixture(t *testing.T) *multisigFixture {
+	t.Helper()
+	sf := newSigningFixture(t)
+	f := &multisigFixture{
+		signingFixture: sf,
+		store:          newMemoryMultisigStore(),
+		owner:          sf.wallet.UserID,
+		members:        []uuid.UUID{uuid.New(), uuid.New()},
+	}
+	f.portfolio = &Portfolio{ID: uuid.New(), UserID: f.owner, Name: "Treasury"}
+	f.coordinator = NewMultisigCoordinator(sf.service, fakePortfolios{f.portfolio.ID: f.portfolio}, f.store, MultisigConfig{ProposalTTL: 24 * time.Hour})
+	f.coordinator.now = func() time.Time { return f.now }
+	sf.service.SetMultisigCoordinator(f.coordinator)
+
+	_, err := f.coordinator.SetPolicy(context.Background(), f.owner, f.portfolio.ID, MultisigPolicyRequest{
+		WalletID:  sf.wallet.ID,
+		Members:   f.members,
+		Threshold: 2,
+	})
+	require.NoError(t, err)
+	return f
+}
+
+func (f *multisigFixture) propose(t *testing.T, proposer uuid.UUID) *TransactionProposal {
+	t.Helper()
+	resp, err := f.service.CreateTransaction(context.Background(), proposer, TransactionRequest{
+		WalletID:  f.wallet.ID,
+		ToAddress: "0x000000000000000000000000000000000000dEaD",
+		Value:     big.NewInt(1_000_000_000_000_000),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Proposal)
+	return resp.Proposal
+}
+
+func TestMultisig_TransactionBecomesProposal(t *testing.T) {
+	f := newMultisigFixture(t)
+	proposal := f.propose(t, f.members[0])
+
+	assert.Equal(t, ProposalPending, proposal.Status)
+	assert.Equal(t, f.portfolio.ID, proposal.PortfolioID)
+	assert.Equal(t, f.members[0], proposal.ProposerID)
+	assert.Equal(t, 2, proposal.Threshold)
+	assert.ElementsMatch(t, append([]uuid.UUID{f.owner}, f.members...), proposal.Members)
+	assert.Equal(t, f.now.Add(24*time.Hour), proposal.ExpiresAt)
+	assert.Nil(t, proposal.TransactionID)
+
+	// Nothing is built until the members approve
+	pending, err := f.queue.List(context.Background(), f.owner, "")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestMultisig_EnhancedTransactionBecomesProposal(t *testing.T) {
+	f := newMultisigFixture(t)
+	enhanced := &EnhancedService{walletRepo: f.service.walletRepo}
+	enhanced.SetMultisigCoordinator(f.coordinator)
+	req := EnhancedTransactionRequest{
+		WalletID:  f.wallet.ID,
+		ToAddress: "0x000000000000000000000000000000000000dEaD",
+		Value:     big.NewInt(1_000_000_000_000_000),
+	}
+
+	// The wallet's owner can't bypass the other members' approvals
+	resp, err := enhanced.CreateEnhancedTransaction(context.Background(), f.owner, req)
+	require.NoError(t, err)
+	assert.Equal(t, TransactionPendingApproval, resp.Status)
+	require.NotNil(t, resp.Proposal)
+	assert.Equal(t, f.owner, resp.Proposal.ProposerID)
+	assert.Nil(t, resp.Transaction)
+
+	_, err = enhanced.CreateEnhancedTransaction(context.Background(), uuid.New(), req)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+func TestMultisig_ThresholdBuildsTransaction(t *testing.T) {
+	f := newMultisigFixture(t)
+	ctx := context.Background()
+	proposal := f.propose(t, f.members[0])
+
+	proposal, err := f.coordinator.Approve(ctx, f.members[0], proposal.ID, ProposalVoteRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, ProposalPending, proposal.Status)
+	assert.Equal(t, 1, proposal.Approvals)
+
+	_, err = f.coordinator.Approve(ctx, f.members[0], proposal.ID, ProposalVoteRequest{})
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+
+	proposal, err = f.coordinator.Approve(ctx, f.members[1], proposal.ID, ProposalVoteRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, ProposalExecuted, proposal.Status)
+	assert.Equal(t, 2, proposal.Approvals)
+	require.NotNil(t, proposal.TransactionID)
+	require.NotNil(t, proposal.SigningRequestID)
+
+	// The owner's wallet signs the built transaction
+	request, err := f.queue.Get(ctx, f.owner, *proposal.SigningRequestID)
+	require.NoError(t, err)
+	assert.Equal(t, *proposal.TransactionID, request.TransactionID)
+	assert.Equal(t, "1000000000000000", request.Transaction.Value)
+
+	stored, err := f.coordinator.GetProposal(ctx, f.owner, proposal.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ProposalExecuted, stored.Status)
+
+	_, err = f.coordinator.Reject(ctx, f.owner, proposal.ID, ProposalVoteRequest{})
+	assert.ErrorIs(t, err, ErrProposalClosed)
+}
+
+func TestMultisig_RejectedOnceThresholdUnreachable(t *testing.T) {
+	f := newMultisigFixture(t)
+	ctx := context.Background()
+	proposal := f.propose(t, f.owner)
+
+	proposal, err := f.coordinator.Reject(ctx, f.members[0], proposal.ID, ProposalVoteRequest{Reason: "too large"})
+	require.NoError(t, err)
+	assert.Equal(t, ProposalPending, proposal.Status)
+
+	proposal, err = f.coordinator.Reject(ctx, f.members[1], proposal.ID, ProposalVoteRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, ProposalRejected, proposal.Status)
+	assert.Equal(t, 2, proposal.Rejections)
+	assert.Equal(t, "too large", proposal.Votes[0].Reason)
+	assert.Nil(t, proposal.TransactionID)
+}
+
+func TestMultisig_OnlyMembersSeeProposals(t *testing.T) {
+	f := newMultisigFixture(t)
+	ctx := context.Background()
+	outsider := uuid.New()
+	proposal := f.propose(t, f.owner)
+
+	_, err := f.coordinator.GetProposal(ctx, outsider, proposal.ID)
+	assert.ErrorIs(t, err, ErrProposalNotFound)
+	_, err = f.coordinator.Approve(ctx, outsider, proposal.ID, ProposalVoteRequest{})
+	assert.ErrorIs(t, err, ErrProposalNotFound)
+	_, err = f.service.CreateTransaction(ctx, outsider, TransactionRequest{WalletID: f.wallet.ID, ToAddress: "0x000000000000000000000000000000000000dEaD"})
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+
+	listed, err := f.coordinator.ListProposals(ctx, f.members[1], ProposalFilter{PortfolioID: f.portfolio.ID})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	listed, err = f.coordinator.ListProposals(ctx, outsider, ProposalFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestMultisig_ProposalsExpire(t *testing.T) {
+	f := newMultisigFixture(t)
+	ctx := context.Background()
+	proposal := f.propose(t, f.owner)
+
+	assert.Equal(t, 0, f.coordinator.ExpireStale(ctx))
+	f.now = f.now.Add(25 * time.Hour)
+	assert.Equal(t, 1, f.coordinator.ExpireStale(ctx))
+
+	_, err := f.coordinator.Approve(ctx, f.owner, proposal.ID, ProposalVoteRequest{})
+	assert.ErrorIs(t, err, ErrProposalClosed)
+	stored, err := f.coordinator.GetProposal(ctx, f.owner, proposal.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ProposalExpired, stored.Status)
+}
+
+func TestMultisig_SetPolicy(t *testing.T) {
+	f := newMultisigFixture(t)
+	ctx := context.Background()
+
+	_, err := f.coordinator.SetPolicy(ctx, f.members[0], f.portfolio.ID, MultisigPolicyRequest{WalletID: f.wallet.ID, Threshold: 1})
+	assert.ErrorIs(t, err, ErrPortfolioNotFound)
+
+	_, err = f.coordinator.SetPolicy(ctx, f.owner, f.portfolio.ID, MultisigPolicyRequest{WalletID: f.wallet.ID, Members: f.members, Threshold: 4})
+	var fieldErrors validation.FieldErrors
+	assert.ErrorAs(t, err, &fieldErrors)
+
+	// Safe treasuries need the Safe transaction service
+	_, err = f.coordinator.SetPolicy(ctx, f.owner, f.portfolio.ID, MultisigPolicyRequest{WalletID: f.wallet.ID, Threshold: 1, Safe: true})
+	assert.ErrorIs(t, err, ErrSafeUnavailable)
+
+	policy, err := f.coordinator.GetPolicy(ctx, f.members[1], f.portfolio.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, policy.Threshold)
+	_, err = f.coordinator.GetPolicy(ctx, uuid.New(), f.portfolio.ID)
+	assert.ErrorIs(t, err, ErrMultisigPolicyNotFound)
+}
+
+func TestMultisigPolicyRequest_Validate(t *testing.T) {
+	member := uuid.New()
+	assert.NoError(t, (&MultisigPolicyRequest{WalletID: uuid.New(), Members: []uuid.UUID{member}, Threshold: 2}).Validate())
+	assert.Error(t, (&MultisigPolicyRequest{Members: []uuid.UUID{member}, Threshold: 1}).Validate())
+	assert.Error(t, (&MultisigPolicyRequest{WalletID: uuid.New(), Members: []uuid.UUID{member, member}, Threshold: 1}).Validate())
+	assert.Error(t, (&MultisigPolicyRequest{WalletID: uuid.New(), Threshold: 0}).Validate())
+}
+
+// signSafe signs a Safe transaction hash as a Safe owner's wallet would
+func signSafe(t *testing.T, key *ecdsa.PrivateKey, safeTxHash string) string {
+	t.Helper()
+	sig, err := crypto.Sign(common.HexToHash(safeTxHash).Bytes(), key)
+	require.NoError(t, err)
+	sig[64] += 27
+	return hexutil.Encode(sig)
+}
+
+func TestMultisig_SafeCollectsOwnerSignatures(t *testing.T) {
+	f := newMultisigFixture(t)
+	ctx := context.Background()
+
+	ownerKeys := make([]*ecdsa.PrivateKey, 2)
+	owners := make([]string, 2)
+	for i := range ownerKeys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		ownerKeys[i], owners[i] = key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+	safeAddress := "0x5afE000000000000000000000000000000005aFe"
+	safeWallet := &Wallet{ID: uuid.New(), UserID: f.owner, Address: safeAddress, ChainID: 1, WatchOnly: true}
+	f.service.walletRepo.(*mockWalletRepo).getByID[safeWallet.ID] = safeWallet
+	safe := &fakeSafeService{info: SafeInfo{Address: safeAddress, Nonce: 3, Threshold: 2, Owners: owners}}
+	f.coordinator.SetSafeTransactionService(safe)
+
+	_, err := f.coordinator.SetPolicy(ctx, f.owner, f.portfolio.ID, MultisigPolicyRequest{WalletID: safeWallet.ID, Members: f.members, Threshold: 2, Safe: true})
+	require.NoError(t, err)
+
+	// Safe treasuries are watch-only, but their transactions still become proposals
+	proposal, err := f.coordinator.Propose(ctx, f.members[0], ProposalRequest{
+		PortfolioID:        f.portfolio.ID,
+		TransactionRequest: TransactionRequest{ToAddress: "0x000000000000000000000000000000000000dEaD", Value: big.NewInt(5)},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, proposal.Safe)
+	assert.Equal(t, uint64(3), proposal.Safe.Nonce)
+	expectedHash := SafeTransactionHash(1, common.HexToAddress(safeAddress), SafeTransactionData{
+		To: common.HexToAddress("0x000000000000000000000000000000000000dEaD"), Value: big.NewInt(5), Nonce: 3,
+	})
+	assert.Equal(t, expectedHash.Hex(), proposal.Safe.SafeTxHash)
+
+	// Approvals need a Safe owner's signature of the hash, each owner signing once
+	_, err = f.coordinator.Approve(ctx, f.members[0], proposal.ID, ProposalVoteRequest{})
+	assert.ErrorIs(t, err, ErrInvalidSafeSignature)
+	stranger, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = f.coordinator.Approve(ctx, f.members[0], proposal.ID, ProposalVoteRequest{Signature: signSafe(t, stranger, proposal.Safe.SafeTxHash)})
+	assert.ErrorIs(t, err, ErrInvalidSafeSignature)
+
+	first := signSafe(t, ownerKeys[0], proposal.Safe.SafeTxHash)
+	proposal, err = f.coordinator.Approve(ctx, f.members[0], proposal.ID, ProposalVoteRequest{Signature: first})
+	require.NoError(t, err)
+	assert.Equal(t, owners[0], proposal.Votes[0].Signer)
+	_, err = f.coordinator.Approve(ctx, f.members[1], proposal.ID, ProposalVoteRequest{Signature: first})
+	assert.ErrorIs(t, err, ErrInvalidSafeSignature)
+	assert.Empty(t, safe.proposed)
+
+	second := signSafe(t, ownerKeys[1], proposal.Safe.SafeTxHash)
+	proposal, err = f.coordinator.Approve(ctx, f.members[1], proposal.ID, ProposalVoteRequest{Signature: second})
+	require.NoError(t, err)
+	assert.Equal(t, ProposalExecuted, proposal.Status)
+
+	require.Len(t, safe.proposed, 1)
+	assert.Equal(t, proposal.Safe.SafeTxHash, safe.proposed[0].safeTxHash)
+	assert.Equal(t, owners[0], safe.proposed[0].sender)
+	assert.Equal(t, first, safe.proposed[0].signature)
+	assert.Equal(t, uint64(3), safe.proposed[0].tx.Nonce)
+	assert.Equal(t, []string{second}, safe.confirms)
+}
+
+func TestRecoverSafeSigner_EthSign(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hash := crypto.Keccak256Hash([]byte("safe tx"))
+
+	sig, err := crypto.Sign(accounts.TextHash(hash.Bytes()), key)
+	require.NoError(t, err)
+	sig[64] += 31
+	signer, err := RecoverSafeSigner(hash, sig)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	sig[64] = 1
+	_, err = RecoverSafeSigner(hash, sig)
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package web3
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Networks a chain interaction runs on
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+)
+
+// ErrCodeTestnetUnavailable is the API error code of testnet requests on chains without a
+// configured testnet RPC
+const ErrCodeTestnetUnavailable = "TESTNET_UNAVAILABLE"
+
+var (
+	// ErrInvalidNetwork is returned for unknown networks and for requests whose network
+	// doesn't match the wallet or chain they target
+	ErrInvalidNetwork = errors.New("invalid network")
+	// ErrTestnetUnavailable is returned for testnet requests on chains that have no testnet
+	// or no testnet RPC configured
+	ErrTestnetUnavailable = errors.New("testnet unavailable")
+)
+
+// TestnetChain is the testnet of a supported chain and the setting its RPC is read from
+type TestnetChain struct {
+	ChainID int    `json:"chain_id"`
+	Name    string `json:"name"`
+	RPCEnv  string `json:"-"`
+}
+
+// Testnets maps supported chains to their testnets
+var Testnets = map[int]TestnetChain{
+	1:     {ChainID: 11155111, Name: "sepolia", RPCEnv: "SEPOLIA_RPC_URL"},
+	137:   {ChainID: 80002, Name: "polygon-amoy", RPCEnv: "POLYGON_AMOY_RPC_URL"},
+	56:    {ChainID: 97, Name: "bsc-testnet", RPCEnv: "BSC_TESTNET_RPC_URL"},
+	42161: {ChainID: 421614, Name: "arbitrum-sepolia", RPCEnv: "ARBITRUM_SEPOLIA_RPC_URL"},
+	10:    {ChainID: 11155420, Name: "optimism-sepolia", RPCEnv: "OPTIMISM_SEPOLIA_RPC_URL"},
+	8453:  {ChainID: 84532, Name: "base-sepolia", RPCEnv: "BASE_SEPOLIA_RPC_URL"},
+}
+
+// ChainNetworks describes a supported chain and the networks it can be used on with the
+// configured RPCs
+type ChainNetworks struct {
+	ChainID  int           `json:"chain_id"`
+	Name     string        `json:"name"`
+	Networks []string      `json:"networks"`
+	Testnet  *TestnetChain `json:"testnet,omitempty"`
+}
+
+// mainnetOf returns the mainnet chain of a testnet chain ID
+func mainnetOf(chainID int) (int, bool) {
+	for mainnet, testnet := range Testnets {
+		if testnet.ChainID == chainID {
+			return mainnet, true
+		}
+	}
+	return 0, false
+}
+
+// NetworkOf returns the network a chain ID belongs to
+func NetworkOf(chainID int) string {
+	if _, ok := mainnetOf(chainID); ok {
+		return NetworkTestnet
+	}
+	return NetworkMainnet
+}
+
+// ChainName returns the name of a supported chain or testnet
+func ChainName(chainID int) string {
+	if mainnet, ok := mainnetOf(chainID); ok {
+		return Testnets[mainnet].Name
+	}
+	return SupportedChains[chainID]
+}
+
+// isSupportedChain reports whether a chain ID is a supported chain or the testnet of one
+func isSupportedChain(chainID int) bool {
+	if _, ok := SupportedChains[chainID]; ok {
+		return true
+	}
+	_, ok := mainnetOf(chainID)
+	return ok
+}
+
+// normalizeNetwork validates a requested network; empty selects none
+func normalizeNetwork(network string) (string, error) {
+	switch network = strings.ToLower(strings.TrimSpace(network)); network {
+	case "", NetworkMainnet, NetworkTestnet:
+		return network, nil
+	default:
+		return "", fmt.Errorf("%w: %q, use %q or %q", ErrInvalidNetwork, network, NetworkMainnet, NetworkTestnet)
+	}
+}
+
+// resolveChain returns the chain ID a request on chainID targets on the network. Testnet
+// requests on a mainnet chain go to its testnet, which needs a configured RPC. Without a
+// network the chain ID is used as given.
+func (s *Service) resolveChain(chainID int, network string) (int, error) {
+	network, err := normalizeNetwork(network)
+	if err != nil {
+		return 0, err
+	}
+	if !isSupportedChain(chainID) {
+		return 0, fmt.Errorf("unsupported chain ID: %d", chainID)
+	}
+
+	switch {
+	case network == "" || network == NetworkOf(chainID):
+	case network == NetworkMainnet:
+		return 0, fmt.Errorf("%w: chain ID %d is the %s testnet", ErrInvalidNetwork, chainID, ChainName(chainID))
+	default:
+		testnet, ok := Testnets[chainID]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s has no supported testnet", ErrTestnetUnavailable, SupportedChains[chainID])
+		}
+		chainID = testnet.ChainID
+	}
+
+	if NetworkOf(chainID) == NetworkTestnet {
+		if _, err := s.provider(chainID); err != nil {
+			return 0, err
+		}
+	}
+	return chainID, nil
+}
+
+// provider returns the provider of a chain. Testnets without one report how to configure it.
+func (s *Service) provider(chainID int) (*ChainProvider, error) {
+	if provider, ok := s.providers[chainID]; ok {
+		return provider, nil
+	}
+	if mainnet, ok := mainnetOf(chainID); ok {
+		testnet := Testnets[mainnet]
+		return nil, fmt.Errorf("%w: no RPC configured for %s (chain ID %d); set %s to enable testnet mode",
+			ErrTestnetUnavailable, testnet.Name, testnet.ChainID, testnet.RPCEnv)
+	}
+	return nil, fmt.Errorf("no provider configured for chain ID: %d", chainID)
+}
+
+// checkWalletNetwork rejects requests whose network doesn't match the wallet's
+func checkWalletNetwork(wallet *Wallet, network string) error {
+	network, err := normalizeNetwork(network)
+	if err != nil {
+		return err
+	}
+	if network != "" && network != NetworkOf(wallet.ChainID) {
+		return fmt.Errorf("%w: wallet %s is on %s", ErrInvalidNetwork, wallet.Address, NetworkOf(wallet.ChainID))
+	}
+	return nil
+}
+
+// SupportedNetworks lists the supported chains with the networks their configured RPCs
+// serve. Mainnet is always listed; testnet only when its RPC is configured.
+func (s *Service) SupportedNetworks() []ChainNetworks {
+	chains := make([]ChainNetworks, 0, len(SupportedChains))
+	for chainID, name := range SupportedChains {
+		chain := ChainNetworks{ChainID: chainID, Name: name, Networks: []string{NetworkMainnet}}
+		if testnet, ok := Testnets[chainID]; ok {
+			chain.Testnet = &testnet
+			if _, configured := s.providers[testnet.ChainID]; configured {
+				chain.Networks = append(chain.Networks, NetworkTestnet)
+			}
+		}
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ChainID < chains[j].ChainID })
+	return chains
+}
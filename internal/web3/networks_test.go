@@ -0,0 +1,109 @@
+package web3
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveChain(t *testing.T) {
+	s := newServiceWithMocks()
+	sepolia := Testnets[1].ChainID
+	s.providers[sepolia] = &ChainProvider{ChainID: sepolia, RpcURL: "http://sepolia"}
+
+	chainID, err := s.resolveChain(1, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, chainID)
+
+	chainID, err = s.resolveChain(1, "testnet")
+	require.NoError(t, err)
+	assert.Equal(t, sepolia, chainID)
+
+	chainID, err = s.resolveChain(sepolia, "")
+	require.NoError(t, err)
+	assert.Equal(t, sepolia, chainID)
+
+	t.Run("NoTestnetRPC", func(t *testing.T) {
+		_, err := s.resolveChain(137, NetworkTestnet)
+		assert.ErrorIs(t, err, ErrTestnetUnavailable)
+		assert.Contains(t, err.Error(), "POLYGON_AMOY_RPC_URL")
+	})
+
+	t.Run("NoTestnet", func(t *testing.T) {
+		_, err := s.resolveChain(250, NetworkTestnet)
+		assert.ErrorIs(t, err, ErrTestnetUnavailable)
+	})
+
+	t.Run("InvalidNetwork", func(t *testing.T) {
+		_, err := s.resolveChain(1, "devnet")
+		assert.ErrorIs(t, err, ErrInvalidNetwork)
+		_, err = s.resolveChain(sepolia, NetworkMainnet)
+		assert.ErrorIs(t, err, ErrInvalidNetwork)
+	})
+}
+
+func TestConnectWallet_Testnet(t *testing.T) {
+	s := newServiceWithMocks()
+	userID := uuid.New()
+
+	_, err := s.ConnectWallet(context.Background(), userID, WalletConnectRequest{Address: "0xabc", ChainID: 1, Network: NetworkTestnet})
+	assert.ErrorIs(t, err, ErrTestnetUnavailable)
+
+	sepolia := Testnets[1].ChainID
+	s.providers[sepolia] = &ChainProvider{ChainID: sepolia, RpcURL: "http://sepolia"}
+	resp, err := s.ConnectWallet(context.Background(), userID, WalletConnectRequest{Address: "0xabc", ChainID: 1, Network: NetworkTestnet})
+	require.NoError(t, err)
+	assert.Equal(t, sepolia, resp.Wallet.ChainID)
+	assert.Equal(t, NetworkTestnet, resp.Wallet.Network)
+}
+
+func TestCreateTransaction_NetworkMismatch(t *testing.T) {
+	s := newServiceWithMocks()
+	userID := uuid.New()
+	wallet := &Wallet{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 1}
+	s.walletRepo.(*mockWalletRepo).getByID = map[uuid.UUID]*Wallet{wallet.ID: wallet}
+
+	_, err := s.CreateTransaction(context.Background(), userID, TransactionRequest{WalletID: wallet.ID, ToAddress: "0x0000000000000000000000000000000000000001", Network: NetworkTestnet})
+	assert.ErrorIs(t, err, ErrInvalidNetwork)
+}
+
+func TestGetAggregateBalance_ExcludesTestnet(t *testing.T) {
+	s := newServiceWithMocks()
+	s.config.BalanceChainTimeout = 50 * time.Millisecond
+	sepolia := Testnets[1].ChainID
+	s.providers[sepolia] = &ChainProvider{ChainID: sepolia}
+	userID := uuid.New()
+	s.walletRepo.(*mockWalletRepo).listResult = []*Wallet{
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 1},
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: sepolia},
+	}
+	s.balances = &mockChainBalances{native: map[int]*big.Int{1: ether(1), sepolia: ether(100)}}
+	s.priceSource = &mockPriceSource{prices: map[string]TokenPrice{"ethereum": {Price: 2000}}}
+
+	resp, err := s.GetAggregateBalance(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.WalletCount)
+	require.Len(t, resp.Tokens, 1)
+	require.Len(t, resp.Tokens[0].Chains, 1)
+	assert.Equal(t, 1, resp.Tokens[0].Chains[0].ChainID)
+	assert.Equal(t, 2000.0, resp.TotalUSDValue)
+}
+
+func TestSupportedNetworks(t *testing.T) {
+	s := newServiceWithMocks()
+	base := Testnets[8453].ChainID
+	s.providers[base] = &ChainProvider{ChainID: base}
+
+	networks := make(map[int][]string)
+	for _, chain := range s.SupportedNetworks() {
+		networks[chain.ChainID] = chain.Networks
+	}
+	assert.Equal(t, []string{NetworkMainnet, NetworkTestnet}, networks[8453])
+	assert.Equal(t, []string{NetworkMainnet}, networks[1])
+	assert.Equal(t, []string{NetworkMainnet}, networks[250])
+}
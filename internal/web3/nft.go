@@ -0,0 +1,477 @@
+package web3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// NFT token standards
+const (
+	NFTStandardERC721  = "ERC721"
+	NFTStandardERC1155 = "ERC1155"
+)
+
+const (
+	defaultNFTPageSize = 50
+	maxNFTPageSize     = 100
+	// maxNFTWallets bounds how many of a user's wallets on a chain are listed
+	maxNFTWallets = 100
+	// maxNFTCollectionPages bounds how many pages of collections are valued per wallet
+	maxNFTCollectionPages = 10
+	// nftLookupConcurrency bounds concurrent metadata and floor price lookups
+	nftLookupConcurrency = 5
+
+	// Collection metadata rarely changes, floor prices do
+	nftMetadataTTL   = 24 * time.Hour
+	nftFloorPriceTTL = 10 * time.Minute
+)
+
+var (
+	ErrNFTIndexerUnavailable = errors.New("NFT tracking is not configured")
+	ErrUnsupportedNFTChain   = errors.New("NFT tracking is not supported on this chain")
+	ErrNFTCollectionNotFound = errors.New("NFT collection not found")
+	ErrInvalidNFTContract    = errors.New("invalid NFT contract address")
+	ErrInvalidNFTCursor      = errors.New("invalid NFT cursor")
+)
+
+// NFTToken is a token held by one of a user's wallets. Name and image are only resolved
+// when token metadata is requested.
+type NFTToken struct {
+	Contract string `json:"contract"`
+	TokenID  string `json:"token_id"`
+	Standard string `json:"standard,omitempty"`
+	Balance  int64  `json:"balance"` // always 1 for ERC-721
+	Owner    string `json:"owner"`
+	Name     string `json:"name,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// NFTTokenPage is one page of a wallet's tokens as returned by an indexer
+type NFTTokenPage struct {
+	Tokens      []*NFTToken
+	NextPageKey string // empty on the last page
+}
+
+// NFTCollectionHolding is how many tokens of a collection a wallet holds
+type NFTCollectionHolding struct {
+	Contract string
+	Name     string
+	Standard string
+	Count    int64
+	IsSpam   bool
+}
+
+// NFTCollectionHoldingsPage is one page of a wallet's collections as returned by an indexer
+type NFTCollectionHoldingsPage struct {
+	Collections []*NFTCollectionHolding
+	NextPageKey string
+}
+
+// NFTCollection is the metadata of an NFT contract
+type NFTCollection struct {
+	Address     string         `json:"address"`
+	ChainID     int            `json:"chain_id"`
+	Name        string         `json:"name"`
+	Symbol      string         `json:"symbol,omitempty"`
+	Standard    string         `json:"standard"`
+	TotalSupply string         `json:"total_supply,omitempty"`
+	Description string         `json:"description,omitempty"`
+	ImageURL    string         `json:"image_url,omitempty"`
+	ExternalURL string         `json:"external_url,omitempty"`
+	FloorPrice  *NFTFloorPrice `json:"floor_price,omitempty"`
+}
+
+// NFTFloorPrice is the lowest listed price of a collection
+type NFTFloorPrice struct {
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"` // e.g. "ETH"
+	PriceUSD    decimal.Decimal `json:"price_usd"`
+	Marketplace string          `json:"marketplace,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// NFTIndexer enumerates NFT holdings and collection metadata. Wallets can hold thousands of
+// tokens, so listings are paginated with opaque page keys.
+type NFTIndexer interface {
+	ListTokens(ctx context.Context, chainID int, owner, pageKey string, pageSize int, withMetadata bool) (*NFTTokenPage, error)
+	ListCollections(ctx context.Context, chainID int, owner, pageKey string) (*NFTCollectionHoldingsPage, error)
+	// GetCollection returns ErrNFTCollectionNotFound when the address is not an NFT contract
+	GetCollection(ctx context.Context, chainID int, contract string) (*NFTCollection, error)
+}
+
+// NFTFloorPriceSource provides collection floor prices. It returns nil without an error when
+// a collection has no known floor.
+type NFTFloorPriceSource interface {
+	GetFloorPrice(ctx context.Context, chainID int, contract string) (*NFTFloorPrice, error)
+}
+
+// NFTQuery selects a page of a user's NFT holdings
+type NFTQuery struct {
+	Cursor       string // NextCursor of the previous page, empty for the first page
+	Limit        int
+	WithMetadata bool // resolve token names and images
+}
+
+// NFTHoldingsResponse is a page of a user's NFT holdings on one chain
+type NFTHoldingsResponse struct {
+	ChainID     int                       `json:"chain_id"`
+	Tokens      []*NFTToken               `json:"tokens"`
+	Collections map[string]*NFTCollection `json:"collections"` // metadata of the collections on this page
+	Count       int                       `json:"count"`
+	NextCursor  string                    `json:"next_cursor,omitempty"`
+	Portfolio   *NFTPortfolioSummary      `json:"portfolio,omitempty"` // first page only
+	Timestamp   time.Time                 `json:"timestamp"`
+}
+
+// NFTPortfolioSummary values a user's NFT holdings at collection floor prices
+type NFTPortfolioSummary struct {
+	Collections         []*NFTCollectionValue `json:"collections"`
+	TotalTokens         int64                 `json:"total_tokens"`
+	EstimatedValueUSD   decimal.Decimal       `json:"estimated_value_usd"`
+	UnpricedCollections int                   `json:"unpriced_collections"`
+	SpamCollections     int                   `json:"spam_collections"`
+	Complete            bool                  `json:"complete"` // false when a wallet had too many collections to value
+}
+
+// NFTCollectionValue is the estimated value of the tokens held of one collection
+type NFTCollectionValue struct {
+	Contract   string          `json:"contract"`
+	Name       string          `json:"name"`
+	Count      int64           `json:"count"`
+	FloorPrice *NFTFloorPrice  `json:"floor_price,omitempty"`
+	ValueUSD   decimal.Decimal `json:"value_usd"`
+}
+
+// nftCursor is the position of a holdings listing: a wallet and the indexer page within it
+type nftCursor struct {
+	Wallet  string `json:"w"`
+	PageKey string `json:"k,omitempty"`
+}
+
+func encodeNFTCursor(c nftCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeNFTCursor(s string) (nftCursor, error) {
+	var c nftCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Wallet == "" {
+		return c, ErrInvalidNFTCursor
+	}
+	return c, nil
+}
+
+// SetNFTIndexer attaches the indexer used to enumerate NFT holdings
+func (s *Service) SetNFTIndexer(indexer NFTIndexer) {
+	s.nftIndexer = indexer
+}
+
+// SetNFTFloorPriceSource attaches the source of collection floor prices
+func (s *Service) SetNFTFloorPriceSource(source NFTFloorPriceSource) {
+	s.nftFloorPrices = source
+}
+
+// GetNFTs returns a page of the NFTs held by the user's wallets on a chain. Tokens are listed
+// wallet by wallet; only the collections on the page have their metadata resolved. The first
+// page also values the whole portfolio at collection floor prices.
+func (s *Service) GetNFTs(ctx context.Context, userID uuid.UUID, chainID int, query NFTQuery) (*NFTHoldingsResponse, error) {
+	if s.nftIndexer == nil {
+		return nil, ErrNFTIndexerUnavailable
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultNFTPageSize
+	}
+	query.Limit = min(query.Limit, maxNFTPageSize)
+
+	wallets, err := s.nftWallets(ctx, userID, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, pageKey := 0, ""
+	if query.Cursor != "" {
+		cursor, err := decodeNFTCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.SearchStrings(wallets, cursor.Wallet)
+		if start == len(wallets) || wallets[start] != cursor.Wallet {
+			return nil, ErrInvalidNFTCursor
+		}
+		pageKey = cursor.PageKey
+	}
+
+	response := &NFTHoldingsResponse{
+		ChainID:     chainID,
+		Tokens:      []*NFTToken{},
+		Collections: map[string]*NFTCollection{},
+		Timestamp:   time.Now(),
+	}
+	for i := start; i < len(wallets); i++ {
+		if len(response.Tokens) >= query.Limit {
+			response.NextCursor = encodeNFTCursor(nftCursor{Wallet: wallets[i]})
+			break
+		}
+		page, err := s.nftIndexer.ListTokens(ctx, chainID, wallets[i], pageKey, query.Limit-len(response.Tokens), query.WithMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list NFTs of %s: %w", wallets[i], err)
+		}
+		for _, token := range page.Tokens {
+			token.Contract = strings.ToLower(token.Contract)
+			token.Owner = wallets[i]
+		}
+		response.Tokens = append(response.Tokens, page.Tokens...)
+		pageKey = ""
+		if page.NextPageKey != "" {
+			response.NextCursor = encodeNFTCursor(nftCursor{Wallet: wallets[i], PageKey: page.NextPageKey})
+			break
+		}
+	}
+	response.Count = len(response.Tokens)
+
+	contracts := make([]string, 0)
+	for _, token := range response.Tokens {
+		if _, seen := response.Collections[token.Contract]; !seen {
+			response.Collections[token.Contract] = nil
+			contracts = append(contracts, token.Contract)
+		}
+	}
+	collections := s.nftCollections(ctx, chainID, contracts)
+	for _, contract := range contracts {
+		if collection := collections[contract]; collection != nil {
+			response.Collections[contract] = collection
+		} else {
+			delete(response.Collections, contract)
+		}
+	}
+
+	if query.Cursor == "" {
+		response.Portfolio, err = s.nftPortfolio(ctx, chainID, wallets)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// GetNFTCollection returns the metadata and floor price of an NFT contract
+func (s *Service) GetNFTCollection(ctx context.Context, chainID int, address string) (*NFTCollection, error) {
+	if s.nftIndexer == nil {
+		return nil, ErrNFTIndexerUnavailable
+	}
+	if !common.IsHexAddress(address) {
+		return nil, ErrInvalidNFTContract
+	}
+	collection, err := s.nftCollection(ctx, chainID, strings.ToLower(address))
+	if err != nil {
+		return nil, err
+	}
+	result := *collection
+	result.FloorPrice = s.nftFloorPrice(ctx, chainID, result.Address)
+	return &result, nil
+}
+
+// nftWallets returns the sorted, distinct addresses of the user's wallets on a chain
+func (s *Service) nftWallets(ctx context.Context, userID uuid.UUID, chainID int) ([]string, error) {
+	wallets, _, err := s.walletRepo.ListByUser(ctx, userID, WalletListFilter{ChainID: chainID, Limit: maxNFTWallets})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	seen := make(map[string]struct{}, len(wallets))
+	addresses := make([]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		address := strings.ToLower(wallet.Address)
+		if _, dup := seen[address]; dup {
+			continue
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// nftPortfolio values every collection held by the wallets at its floor price
+func (s *Service) nftPortfolio(ctx context.Context, chainID int, wallets []string) (*NFTPortfolioSummary, error) {
+	summary := &NFTPortfolioSummary{
+		Collections:       []*NFTCollectionValue{},
+		EstimatedValueUSD: decimal.Zero,
+		Complete:          true,
+	}
+	byContract := make(map[string]*NFTCollectionValue)
+	spam := make(map[string]struct{})
+	for _, wallet := range wallets {
+		pageKey := ""
+		for pages := 0; ; pages++ {
+			if pages == maxNFTCollectionPages {
+				summary.Complete = false
+				break
+			}
+			page, err := s.nftIndexer.ListCollections(ctx, chainID, wallet, pageKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list NFT collections of %s: %w", wallet, err)
+			}
+			for _, holding := range page.Collections {
+				contract := strings.ToLower(holding.Contract)
+				if holding.IsSpam {
+					spam[contract] = struct{}{}
+					continue
+				}
+				value, exists := byContract[contract]
+				if !exists {
+					value = &NFTCollectionValue{Contract: contract, Name: holding.Name, ValueUSD: decimal.Zero}
+					byContract[contract] = value
+					summary.Collections = append(summary.Collections, value)
+				}
+				value.Count += holding.Count
+				summary.TotalTokens += holding.Count
+			}
+			if page.NextPageKey == "" {
+				break
+			}
+			pageKey = page.NextPageKey
+		}
+	}
+	summary.SpamCollections = len(spam)
+
+	floors := make([]*NFTFloorPrice, len(summary.Collections))
+	s.forEachNFTLookup(len(summary.Collections), func(i int) {
+		floors[i] = s.nftFloorPrice(ctx, chainID, summary.Collections[i].Contract)
+	})
+	for i, value := range summary.Collections {
+		value.FloorPrice = floors[i]
+		if floors[i] == nil || !floors[i].PriceUSD.IsPositive() {
+			summary.UnpricedCollections++
+			continue
+		}
+		value.ValueUSD = floors[i].PriceUSD.Mul(decimal.NewFromInt(value.Count))
+		summary.EstimatedValueUSD = summary.EstimatedValueUSD.Add(value.ValueUSD)
+	}
+	sort.SliceStable(summary.Collections, func(i, j int) bool {
+		return summary.Collections[i].ValueUSD.GreaterThan(summary.Collections[j].ValueUSD)
+	})
+	return summary, nil
+}
+
+// nftCollections resolves the metadata of several collections, skipping those that fail
+func (s *Service) nftCollections(ctx context.Context, chainID int, contracts []string) map[string]*NFTCollection {
+	resolved := make([]*NFTCollection, len(contracts))
+	s.forEachNFTLookup(len(contracts), func(i int) {
+		collection, err := s.nftCollection(ctx, chainID, contracts[i])
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to resolve NFT collection", map[string]any{"error": err.Error(), "contract": contracts[i], "chain_id": chainID})
+			return
+		}
+		resolved[i] = collection
+	})
+
+	collections := make(map[string]*NFTCollection, len(contracts))
+	for i, contract := range contracts {
+		collections[contract] = resolved[i]
+	}
+	return collections
+}
+
+// nftCollection returns collection metadata, cached in Redis
+func (s *Service) nftCollection(ctx context.Context, chainID int, contract string) (*NFTCollection, error) {
+	key := fmt.Sprintf("nft:collection:%d:%s", chainID, contract)
+	if s.redis != nil {
+		if data, err := s.redis.GetString(ctx, key); err == nil {
+			var cached NFTCollection
+			if json.Unmarshal([]byte(data), &cached) == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	collection, err := s.nftIndexer.GetCollection(ctx, chainID, contract)
+	if err != nil {
+		return nil, err
+	}
+	collection.Address = contract
+	collection.ChainID = chainID
+	collection.FloorPrice = nil
+	if s.redis != nil {
+		if data, err := json.Marshal(collection); err == nil {
+			_ = s.redis.SetWithExpiry(ctx, key, string(data), nftMetadataTTL)
+		}
+	}
+	return collection, nil
+}
+
+// nftFloorPrice returns a collection's floor price valued in USD, or nil when it is unknown.
+// Collections without a floor are cached too, so spam does not hit the source repeatedly.
+func (s *Service) nftFloorPrice(ctx context.Context, chainID int, contract string) *NFTFloorPrice {
+	if s.nftFloorPrices == nil {
+		return nil
+	}
+	key := fmt.Sprintf("nft:floor:%d:%s", chainID, contract)
+	if s.redis != nil {
+		if data, err := s.redis.GetString(ctx, key); err == nil {
+			var cached *NFTFloorPrice
+			if json.Unmarshal([]byte(data), &cached) == nil {
+				return cached
+			}
+		}
+	}
+
+	floor, err := s.nftFloorPrices.GetFloorPrice(ctx, chainID, contract)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to fetch NFT floor price", map[string]any{"error": err.Error(), "contract": contract, "chain_id": chainID})
+		return nil
+	}
+	if floor != nil && floor.PriceUSD.IsZero() {
+		if usd, ok := symbolPrice(floor.Currency, s.nftCurrencyPrices(ctx, floor.Currency)); ok {
+			floor.PriceUSD = floor.Price.Mul(usd)
+		}
+	}
+	// A floor that could not be valued is retried on the next request
+	if s.redis != nil && (floor == nil || floor.PriceUSD.IsPositive()) {
+		if data, err := json.Marshal(floor); err == nil {
+			_ = s.redis.SetWithExpiry(ctx, key, string(data), nftFloorPriceTTL)
+		}
+	}
+	return floor
+}
+
+// nftCurrencyPrices prices the currency a floor is quoted in
+func (s *Service) nftCurrencyPrices(ctx context.Context, currency string) map[string]TokenPrice {
+	id, ok := CoinGeckoIDBySymbol[strings.ToUpper(currency)]
+	if !ok {
+		return nil
+	}
+	prices, err := s.prices().GetPrices(ctx, "USD", []string{id})
+	if err != nil {
+		s.logger.Warn(ctx, "Price fetch failed", map[string]any{"error": err.Error()})
+		return nil
+	}
+	return prices
+}
+
+// forEachNFTLookup runs lookup for 0..n-1 with bounded concurrency
+func (s *Service) forEachNFTLookup(n int, lookup func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, nftLookupConcurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			lookup(i)
+		}(i)
+	}
+	wg.Wait()
+}
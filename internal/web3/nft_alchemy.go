@@ -0,0 +1,252 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// alchemyNetworks maps chain IDs to Alchemy network names
+var alchemyNetworks = map[int]string{
+	1:     "eth-mainnet",
+	137:   "polygon-mainnet",
+	42161: "arb-mainnet",
+	10:    "opt-mainnet",
+}
+
+// AlchemyNFTIndexer reads NFT holdings, collection metadata and floor prices from the
+// Alchemy NFT API. It implements both NFTIndexer and NFTFloorPriceSource.
+type AlchemyNFTIndexer struct {
+	httpClient *http.Client
+	apiKey     string
+	// baseURL returns the API root of a network; replaced in tests
+	baseURL func(network string) string
+}
+
+func NewAlchemyNFTIndexer(apiKey string) *AlchemyNFTIndexer {
+	return &AlchemyNFTIndexer{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		apiKey:     apiKey,
+		baseURL: func(network string) string {
+			return fmt.Sprintf("https://%s.g.alchemy.com", network)
+		},
+	}
+}
+
+// get calls an NFT API method and decodes its JSON response into out
+func (a *AlchemyNFTIndexer) get(ctx context.Context, chainID int, method string, params url.Values, out interface{}) error {
+	network, ok := alchemyNetworks[chainID]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrUnsupportedNFTChain, chainID)
+	}
+	endpoint := fmt.Sprintf("%s/nft/v3/%s/%s?%s", a.baseURL(network), a.apiKey, method, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alchemy %s error: status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid alchemy %s response: %w", method, err)
+	}
+	return nil
+}
+
+// alchemyContract is the contract metadata embedded in several NFT API responses
+type alchemyContract struct {
+	Address         string `json:"address"`
+	Name            string `json:"name"`
+	Symbol          string `json:"symbol"`
+	TotalSupply     string `json:"totalSupply"`
+	TokenType       string `json:"tokenType"`
+	IsSpam          bool   `json:"isSpam"`
+	OpenSeaMetadata struct {
+		CollectionName string `json:"collectionName"`
+		Description    string `json:"description"`
+		ImageURL       string `json:"imageUrl"`
+		ExternalURL    string `json:"externalUrl"`
+	} `json:"openSeaMetadata"`
+}
+
+// name prefers the collection name over the contract name
+func (c alchemyContract) name() string {
+	if c.OpenSeaMetadata.CollectionName != "" {
+		return c.OpenSeaMetadata.CollectionName
+	}
+	return c.Name
+}
+
+func (a *AlchemyNFTIndexer) ListTokens(ctx context.Context, chainID int, owner, pageKey string, pageSize int, withMetadata bool) (*NFTTokenPage, error) {
+	params := url.Values{
+		"owner":        {owner},
+		"withMetadata": {strconv.FormatBool(withMetadata)},
+		"pageSize":     {strconv.Itoa(pageSize)},
+	}
+	if pageKey != "" {
+		params.Set("pageKey", pageKey)
+	}
+
+	var data struct {
+		OwnedNFTs []struct {
+			// Without metadata only the contract address is returned
+			ContractAddress string          `json:"contractAddress"`
+			Contract        alchemyContract `json:"contract"`
+			TokenID         string          `json:"tokenId"`
+			TokenType       string          `json:"tokenType"`
+			Balance         string          `json:"balance"`
+			Name            string          `json:"name"`
+			Image           struct {
+				CachedURL   string `json:"cachedUrl"`
+				OriginalURL string `json:"originalUrl"`
+			} `json:"image"`
+		} `json:"ownedNfts"`
+		PageKey string `json:"pageKey"`
+	}
+	if err := a.get(ctx, chainID, "getNFTsForOwner", params, &data); err != nil {
+		return nil, err
+	}
+
+	page := &NFTTokenPage{Tokens: make([]*NFTToken, 0, len(data.OwnedNFTs)), NextPageKey: data.PageKey}
+	for _, raw := range data.OwnedNFTs {
+		token := &NFTToken{
+			Contract: raw.ContractAddress,
+			TokenID:  raw.TokenID,
+			Standard: alchemyStandard(raw.TokenType),
+			Balance:  1,
+			Name:     raw.Name,
+			ImageURL: raw.Image.CachedURL,
+		}
+		if token.Contract == "" {
+			token.Contract = raw.Contract.Address
+		}
+		if token.Standard == "" {
+			token.Standard = alchemyStandard(raw.Contract.TokenType)
+		}
+		if token.ImageURL == "" {
+			token.ImageURL = raw.Image.OriginalURL
+		}
+		if balance, err := strconv.ParseInt(raw.Balance, 10, 64); err == nil {
+			token.Balance = balance
+		}
+		page.Tokens = append(page.Tokens, token)
+	}
+	return page, nil
+}
+
+func (a *AlchemyNFTIndexer) ListCollections(ctx context.Context, chainID int, owner, pageKey string) (*NFTCollectionHoldingsPage, error) {
+	params := url.Values{"owner": {owner}, "pageSize": {"100"}}
+	if pageKey != "" {
+		params.Set("pageKey", pageKey)
+	}
+
+	var data struct {
+		Contracts []struct {
+			alchemyContract
+			TotalBalance string `json:"totalBalance"`
+		} `json:"contracts"`
+		PageKey string `json:"pageKey"`
+	}
+	if err := a.get(ctx, chainID, "getContractsForOwner", params, &data); err != nil {
+		return nil, err
+	}
+
+	page := &NFTCollectionHoldingsPage{Collections: make([]*NFTCollectionHolding, 0, len(data.Contracts)), NextPageKey: data.PageKey}
+	for _, raw := range data.Contracts {
+		count, err := strconv.ParseInt(raw.TotalBalance, 10, 64)
+		if err != nil {
+			count = 0
+		}
+		page.Collections = append(page.Collections, &NFTCollectionHolding{
+			Contract: raw.Address,
+			Name:     raw.name(),
+			Standard: alchemyStandard(raw.TokenType),
+			Count:    count,
+			IsSpam:   raw.IsSpam,
+		})
+	}
+	return page, nil
+}
+
+func (a *AlchemyNFTIndexer) GetCollection(ctx context.Context, chainID int, contract string) (*NFTCollection, error) {
+	var data alchemyContract
+	if err := a.get(ctx, chainID, "getContractMetadata", url.Values{"contractAddress": {contract}}, &data); err != nil {
+		return nil, err
+	}
+	standard := alchemyStandard(data.TokenType)
+	if standard == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNFTCollectionNotFound, contract)
+	}
+	return &NFTCollection{
+		Address:     strings.ToLower(contract),
+		ChainID:     chainID,
+		Name:        data.name(),
+		Symbol:      data.Symbol,
+		Standard:    standard,
+		TotalSupply: data.TotalSupply,
+		Description: data.OpenSeaMetadata.Description,
+		ImageURL:    data.OpenSeaMetadata.ImageURL,
+		ExternalURL: data.OpenSeaMetadata.ExternalURL,
+	}, nil
+}
+
+// GetFloorPrice returns the lowest floor across the marketplaces Alchemy tracks. Floor
+// prices are only available on Ethereum mainnet.
+func (a *AlchemyNFTIndexer) GetFloorPrice(ctx context.Context, chainID int, contract string) (*NFTFloorPrice, error) {
+	if chainID != 1 {
+		return nil, nil
+	}
+	var data map[string]struct {
+		FloorPrice    *float64  `json:"floorPrice"`
+		PriceCurrency string    `json:"priceCurrency"`
+		RetrievedAt   time.Time `json:"retrievedAt"`
+		Error         string    `json:"error"`
+	}
+	if err := a.get(ctx, chainID, "getFloorPrice", url.Values{"contractAddress": {contract}}, &data); err != nil {
+		return nil, err
+	}
+
+	var floor *NFTFloorPrice
+	for marketplace, quote := range data {
+		if quote.Error != "" || quote.FloorPrice == nil || *quote.FloorPrice <= 0 {
+			continue
+		}
+		price := decimal.NewFromFloat(*quote.FloorPrice)
+		if floor == nil || price.LessThan(floor.Price) {
+			floor = &NFTFloorPrice{
+				Price:       price,
+				Currency:    quote.PriceCurrency,
+				Marketplace: marketplace,
+				UpdatedAt:   quote.RetrievedAt,
+			}
+		}
+	}
+	return floor, nil
+}
+
+// alchemyStandard maps an Alchemy token type to a token standard, or "" for non-NFT contracts
+func alchemyStandard(tokenType string) string {
+	switch strings.ToUpper(tokenType) {
+	case "ERC721":
+		return NFTStandardERC721
+	case "ERC1155":
+		return NFTStandardERC1155
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNFTIndexer serves each wallet's tokens in pages keyed by the token offset
+type fakeNFTIndexer struct {
+	mu          sync.Mutex
+	tokens      map[string][]*NFTToken
+	collections map[string]*NFTCollection
+	lookups     map[string]int
+}
+
+func (f *fakeNFTIndexer) ListTokens(ctx context.Context, chainID int, owner, pageKey string, pageSize int, withMetadata bool) (*NFTTokenPage, error) {
+	tokens := f.tokens[owner]
+	start := 0
+	if pageKey != "" {
+		start, _ = strconv.Atoi(pageKey)
+	}
+	end := min(start+pageSize, len(tokens))
+	page := &NFTTokenPage{}
+	for _, token := range tokens[start:end] {
+		copied := *token
+		page.Tokens = append(page.Tokens, &copied)
+	}
+	if end < len(tokens) {
+		page.NextPageKey = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (f *fakeNFTIndexer) ListCollections(ctx context.Context, chainID int, owner, pageKey string) (*NFTCollectionHoldingsPage, error) {
+	counts := make(map[string]int64)
+	for _, token := range f.tokens[owner] {
+		counts[token.Contract] += token.Balance
+	}
+	page := &NFTCollectionHoldingsPage{}
+	for contract, count := range counts {
+		holding := &NFTCollectionHolding{Contract: contract, Count: count}
+		if collection, ok := f.collections[contract]; ok {
+			holding.Name = collection.Name
+		} else {
+			holding.IsSpam = true
+		}
+		page.Collections = append(page.Collections, holding)
+	}
+	return page, nil
+}
+
+func (f *fakeNFTIndexer) GetCollection(ctx context.Context, chainID int, contract string) (*NFTCollection, error) {
+	f.mu.Lock()
+	f.lookups[contract]++
+	f.mu.Unlock()
+	collection, ok := f.collections[contract]
+	if !ok {
+		return nil, ErrNFTCollectionNotFound
+	}
+	copied := *collection
+	return &copied, nil
+}
+
+type fakeFloorPrices map[string]decimal.Decimal
+
+func (f fakeFloorPrices) GetFloorPrice(ctx context.Context, chainID int, contract string) (*NFTFloorPrice, error) {
+	price, ok := f[contract]
+	if !ok {
+		return nil, nil
+	}
+	return &NFTFloorPrice{Price: price, Currency: "ETH"}, nil
+}
+
+func nftTokens(contract string, n int) []*NFTToken {
+	tokens := make([]*NFTToken, n)
+	for i := range tokens {
+		tokens[i] = &NFTToken{Contract: contract, TokenID: strconv.Itoa(i), Standard: NFTStandardERC721, Balance: 1}
+	}
+	return tokens
+}
+
+func TestGetNFTs(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	const punks, apes, spam = "0x00000000000000000000000000000000000000a1", "0x00000000000000000000000000000000000000a2", "0x00000000000000000000000000000000000000ff"
+
+	indexer := &fakeNFTIndexer{
+		tokens: map[string][]*NFTToken{
+			"0xaaa": append(nftTokens(punks, 3), nftTokens(spam, 1)...),
+			"0xbbb": nftTokens(apes, 4),
+		},
+		collections: map[string]*NFTCollection{
+			punks: {Name: "Punks", Standard: NFTStandardERC721},
+			apes:  {Name: "Apes", Standard: NFTStandardERC721},
+		},
+		lookups: map[string]int{},
+	}
+	s := newServiceWithMocks()
+	s.walletRepo.(*mockWalletRepo).listResult = []*Wallet{
+		{ID: uuid.New(), UserID: userID, Address: "0xBBB", ChainID: 1},
+		{ID: uuid.New(), UserID: userID, Address: "0xaaa", ChainID: 1},
+		{ID: uuid.New(), UserID: userID, Address: "0xccc", ChainID: 137},
+	}
+	s.priceSource = &mockPriceSource{prices: map[string]TokenPrice{"ethereum": {Price: 2000}}}
+	s.SetNFTFloorPriceSource(fakeFloorPrices{punks: decimal.NewFromInt(40), apes: decimal.NewFromInt(10)})
+
+	_, err := s.GetNFTs(ctx, userID, 1, NFTQuery{})
+	assert.ErrorIs(t, err, ErrNFTIndexerUnavailable)
+	s.SetNFTIndexer(indexer)
+
+	// Pages walk the wallets in address order and span wallet boundaries
+	first, err := s.GetNFTs(ctx, userID, 1, NFTQuery{Limit: 3})
+	require.NoError(t, err)
+	require.Equal(t, 3, first.Count)
+	assert.Equal(t, "0xaaa", first.Tokens[0].Owner)
+	assert.NotEmpty(t, first.NextCursor)
+	assert.Equal(t, "Punks", first.Collections[punks].Name)
+	assert.Len(t, first.Collections, 1)
+	assert.Zero(t, indexer.lookups[apes], "only the collections on the page are resolved")
+
+	require.NotNil(t, first.Portfolio)
+	assert.True(t, first.Portfolio.Complete)
+	assert.Equal(t, int64(7), first.Portfolio.TotalTokens)
+	assert.Equal(t, 1, first.Portfolio.SpamCollections)
+	assert.Equal(t, "320000", first.Portfolio.EstimatedValueUSD.String(), "3 x 40 ETH + 4 x 10 ETH at $2000")
+	require.Len(t, first.Portfolio.Collections, 2)
+	assert.Equal(t, punks, first.Portfolio.Collections[0].Contract)
+	assert.Equal(t, "80000", first.Portfolio.Collections[0].FloorPrice.PriceUSD.String())
+
+	second, err := s.GetNFTs(ctx, userID, 1, NFTQuery{Cursor: first.NextCursor, Limit: 3})
+	require.NoError(t, err)
+	require.Equal(t, 3, second.Count)
+	assert.Nil(t, second.Portfolio)
+	assert.Equal(t, "0xaaa", second.Tokens[0].Owner)
+	assert.Equal(t, "0xbbb", second.Tokens[1].Owner)
+	assert.NotContains(t, second.Collections, spam, "collections without metadata are omitted")
+	assert.Contains(t, second.Collections, apes)
+
+	var all []*NFTToken
+	all = append(append(all, first.Tokens...), second.Tokens...)
+	for cursor := second.NextCursor; cursor != ""; {
+		page, err := s.GetNFTs(ctx, userID, 1, NFTQuery{Cursor: cursor, Limit: 3})
+		require.NoError(t, err)
+		all = append(all, page.Tokens...)
+		cursor = page.NextCursor
+	}
+	assert.Len(t, all, 8)
+
+	_, err = s.GetNFTs(ctx, userID, 1, NFTQuery{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidNFTCursor)
+	_, err = s.GetNFTs(ctx, userID, 1, NFTQuery{Cursor: encodeNFTCursor(nftCursor{Wallet: "0xddd"})})
+	assert.ErrorIs(t, err, ErrInvalidNFTCursor)
+
+	collection, err := s.GetNFTCollection(ctx, 1, "0x00000000000000000000000000000000000000A2")
+	require.NoError(t, err)
+	assert.Equal(t, apes, collection.Address)
+	require.NotNil(t, collection.FloorPrice)
+	assert.Equal(t, "20000", collection.FloorPrice.PriceUSD.String())
+
+	_, err = s.GetNFTCollection(ctx, 1, "0x1234")
+	assert.ErrorIs(t, err, ErrInvalidNFTContract)
+	_, err = s.GetNFTCollection(ctx, 1, "0x00000000000000000000000000000000000000b0")
+	assert.ErrorIs(t, err, ErrNFTCollectionNotFound)
+}
+
+func TestAlchemyNFTIndexer(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/eth-mainnet/nft/v3/test-key/")
+		query := r.URL.Query()
+		var body interface{}
+		switch r.URL.Path[len("/eth-mainnet/nft/v3/test-key/"):] {
+		case "getNFTsForOwner":
+			assert.Equal(t, "0xowner", query.Get("owner"))
+			assert.Equal(t, "2", query.Get("pageSize"))
+			body = map[string]interface{}{
+				"ownedNfts": []map[string]interface{}{
+					{"contractAddress": "0xAAA", "tokenId": "1", "balance": "1"},
+					{"contract": map[string]string{"address": "0xBBB", "tokenType": "ERC1155"}, "tokenId": "7", "tokenType": "ERC1155", "balance": "3", "name": "Sword", "image": map[string]string{"cachedUrl": "https://img/7.png"}},
+				},
+				"pageKey": "next",
+			}
+		case "getContractsForOwner":
+			body = map[string]interface{}{"contracts": []map[string]interface{}{
+				{"address": "0xAAA", "name": "AAA", "tokenType": "ERC721", "totalBalance": "2", "openSeaMetadata": map[string]string{"collectionName": "Triple A"}},
+				{"address": "0xFFF", "tokenType": "ERC1155", "totalBalance": "500", "isSpam": true},
+			}}
+		case "getContractMetadata":
+			if query.Get("contractAddress") == "0xnone" {
+				body = map[string]string{"address": "0xnone", "tokenType": "NOT_A_CONTRACT"}
+				break
+			}
+			body = map[string]interface{}{"address": "0xAAA", "name": "AAA", "symbol": "AAA", "tokenType": "ERC721", "totalSupply": "10000", "openSeaMetadata": map[string]string{"collectionName": "Triple A", "imageUrl": "https://img/aaa.png"}}
+		case "getFloorPrice":
+			body = map[string]interface{}{
+				"openSea":   map[string]interface{}{"floorPrice": 1.5, "priceCurrency": "ETH", "retrievedAt": "2024-01-01T00:00:00Z"},
+				"looksRare": map[string]interface{}{"floorPrice": 1.4, "priceCurrency": "ETH", "retrievedAt": "2024-01-01T00:00:00Z"},
+				"blur":      map[string]interface{}{"error": "unavailable"},
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	indexer := NewAlchemyNFTIndexer("test-key")
+	indexer.baseURL = func(network string) string { return fmt.Sprintf("%s/%s", server.URL, network) }
+
+	page, err := indexer.ListTokens(ctx, 1, "0xowner", "", 2, true)
+	require.NoError(t, err)
+	require.Len(t, page.Tokens, 2)
+	assert.Equal(t, "next", page.NextPageKey)
+	assert.Equal(t, "0xAAA", page.Tokens[0].Contract)
+	assert.Equal(t, "0xBBB", page.Tokens[1].Contract)
+	assert.Equal(t, NFTStandardERC1155, page.Tokens[1].Standard)
+	assert.Equal(t, int64(3), page.Tokens[1].Balance)
+	assert.Equal(t, "https://img/7.png", page.Tokens[1].ImageURL)
+
+	holdings, err := indexer.ListCollections(ctx, 1, "0xowner", "")
+	require.NoError(t, err)
+	require.Len(t, holdings.Collections, 2)
+	assert.Equal(t, "Triple A", holdings.Collections[0].Name)
+	assert.True(t, holdings.Collections[1].IsSpam)
+
+	collection, err := indexer.GetCollection(ctx, 1, "0xAAA")
+	require.NoError(t, err)
+	assert.Equal(t, "Triple A", collection.Name)
+	assert.Equal(t, "10000", collection.TotalSupply)
+	_, err = indexer.GetCollection(ctx, 1, "0xnone")
+	assert.ErrorIs(t, err, ErrNFTCollectionNotFound)
+
+	floor, err := indexer.GetFloorPrice(ctx, 1, "0xAAA")
+	require.NoError(t, err)
+	require.NotNil(t, floor)
+	assert.Equal(t, "1.4", floor.Price.String())
+	assert.Equal(t, "looksRare", floor.Marketplace)
+
+	floor, err = indexer.GetFloorPrice(ctx, 137, "0xAAA")
+	require.NoError(t, err)
+	assert.Nil(t, floor)
+	_, err = indexer.ListTokens(ctx, 56, "0xowner", "", 2, false)
+	assert.ErrorIs(t, err, ErrUnsupportedNFTChain)
+}
@@ -2,7 +2,9 @@ package web3
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/observability"
@@ -16,7 +18,13 @@ type PortfolioRebalancer struct {
 	tradingEngine  *TradingEngine
 	defiManager    *DeFiProtocolManager
 	rebalanceRules map[uuid.UUID]*RebalanceStrategy
+	history        map[uuid.UUID][]*RebalanceStrategy  // superseded strategies, newest first
+	executions     map[uuid.UUID][]*RebalanceExecution // rebalance runs, newest first
+	running        map[uuid.UUID]bool
+	costs          RebalanceCostEstimator
+	strategies     RebalanceStrategyStore
 	config         RebalancerConfig
+	mu             sync.RWMutex
 }
 
 // RebalancerConfig holds configuration for portfolio rebalancing
@@ -44,8 +52,29 @@ type RebalanceStrategy struct {
 	LastRebalance     time.Time                  `json:"last_rebalance"`
 	CreatedAt         time.Time                  `json:"created_at"`
 	Metadata          map[string]interface{}     `json:"metadata"`
+
+	// Schedule rebalances the strategy automatically; nil means manual only
+	Schedule *RebalanceSchedule `json:"schedule,omitempty"`
 }
 
+// RebalanceStrategyDetails is the active strategy of a portfolio with its schedule and history
+type RebalanceStrategyDetails struct {
+	*RebalanceStrategy
+	DriftThreshold decimal.Decimal      `json:"drift_threshold"`
+	NextEvaluation time.Time            `json:"next_evaluation"`
+	History        []*RebalanceStrategy `json:"history"`
+
+	// Executions lists recent manual and scheduled rebalance runs, newest first
+	Executions []*RebalanceExecution `json:"executions"`
+}
+
+// Rebalancing errors
+var (
+	ErrRebalanceStrategyNotFound = errors.New("no rebalance strategy found")
+	ErrRebalanceInProgress       = errors.New("rebalance already in progress")
+	ErrInvalidRebalanceStrategy  = errors.New("invalid rebalance strategy")
+)
+
 // RebalanceType represents different rebalancing strategies
 type RebalanceType string
 
@@ -131,6 +160,9 @@ func NewPortfolioRebalancer(
 		tradingEngine:  tradingEngine,
 		defiManager:    defiManager,
 		rebalanceRules: make(map[uuid.UUID]*RebalanceStrategy),
+		history:        make(map[uuid.UUID][]*RebalanceStrategy),
+		executions:     make(map[uuid.UUID][]*RebalanceExecution),
+		running:        make(map[uuid.UUID]bool),
 		config:         config,
 	}
 }
@@ -151,7 +183,7 @@ func (r *PortfolioRebalancer) CreateRebalanceStrategy(
 	}
 
 	if !totalAllocation.Equal(decimal.NewFromInt(1)) {
-		return nil, fmt.Errorf("target allocations must sum to 100%%, got %s", totalAllocation.Mul(decimal.NewFromInt(100)).String())
+		return nil, fmt.Errorf("%w: target allocations must sum to 100%%, got %s", ErrInvalidRebalanceStrategy, totalAllocation.Mul(decimal.NewFromInt(100)).String())
 	}
 
 	strategy := &RebalanceStrategy{
@@ -168,7 +200,32 @@ func (r *PortfolioRebalancer) CreateRebalanceStrategy(
 		Metadata:          make(map[string]interface{}),
 	}
 
+	// The new strategy is saved before the one it replaces is retired, so a failed save never
+	// leaves the portfolio without an active strategy
+	if err := r.saveStrategy(ctx, strategy); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	previous, exists := r.rebalanceRules[portfolioID]
+	var retired RebalanceStrategy
+	if exists {
+		retired = *previous
+		retired.IsActive = false
+	}
+	r.mu.RUnlock()
+	if exists {
+		if err := r.saveStrategy(ctx, &retired); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	if previous, exists := r.rebalanceRules[portfolioID]; exists {
+		previous.IsActive = false
+		r.history[portfolioID] = append([]*RebalanceStrategy{previous}, r.history[portfolioID]...)
+	}
 	r.rebalanceRules[portfolioID] = strategy
+	r.mu.Unlock()
 
 	r.logger.Info(ctx, "Rebalance strategy created", map[string]interface{}{
 		"strategy_id":   strategy.ID.String(),
@@ -180,6 +237,70 @@ func (r *PortfolioRebalancer) CreateRebalanceStrategy(
 	return strategy, nil
 }
 
+// GetRebalanceStrategy returns the active strategy of a portfolio along with previously replaced
+// ones. With a strategy store, the history is read from the store.
+func (r *PortfolioRebalancer) GetRebalanceStrategy(ctx context.Context, portfolioID uuid.UUID) (*RebalanceStrategyDetails, error) {
+	var strategy *RebalanceStrategy
+	var history []*RebalanceStrategy
+	if store := r.strategyStore(); store != nil {
+		stored, err := store.ListByPortfolio(ctx, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rebalance strategies: %w", err)
+		}
+		strategy, history = r.adoptStoredStrategies(portfolioID, stored)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if strategy == nil {
+		var exists bool
+		if strategy, exists = r.rebalanceRules[portfolioID]; !exists {
+			return nil, fmt.Errorf("%w for portfolio: %s", ErrRebalanceStrategyNotFound, portfolioID.String())
+		}
+		history = make([]*RebalanceStrategy, len(r.history[portfolioID]))
+		copy(history, r.history[portfolioID])
+	}
+	executions := make([]*RebalanceExecution, len(r.executions[portfolioID]))
+	copy(executions, r.executions[portfolioID])
+
+	return &RebalanceStrategyDetails{
+		RebalanceStrategy: strategy,
+		DriftThreshold:    r.driftThreshold(strategy),
+		NextEvaluation:    r.nextEvaluation(strategy),
+		History:           history,
+		Executions:        executions,
+	}, nil
+}
+
+// driftThreshold returns the drift trigger threshold of a strategy, preferring a drift schedule's
+// threshold and falling back to the rebalancer default
+func (r *PortfolioRebalancer) driftThreshold(strategy *RebalanceStrategy) decimal.Decimal {
+	if schedule := strategy.Schedule; schedule != nil && schedule.Frequency == ScheduleFrequencyDrift && schedule.DriftThreshold != nil {
+		return *schedule.DriftThreshold
+	}
+	for _, trigger := range strategy.TriggerConditions {
+		if trigger.Type == TriggerTypeDrift {
+			return trigger.Threshold
+		}
+	}
+	return r.config.DriftThreshold
+}
+
+// nextEvaluation returns when the strategy is next due to be evaluated
+func (r *PortfolioRebalancer) nextEvaluation(strategy *RebalanceStrategy) time.Time {
+	last := strategy.LastRebalance
+	if last.IsZero() {
+		last = strategy.CreatedAt
+	}
+	if strategy.Schedule != nil {
+		if next := strategy.Schedule.NextRun(last); !next.IsZero() {
+			return next
+		}
+	}
+	return last.Add(r.config.RebalanceInterval)
+}
+
 // getDefaultTriggers returns default triggers for a strategy type
 func (r *PortfolioRebalancer) getDefaultTriggers(strategyType RebalanceType) []RebalanceTrigger {
 	switch strategyType {
@@ -216,65 +337,153 @@ func (r *PortfolioRebalancer) getDefaultTriggers(strategyType RebalanceType) []R
 	}
 }
 
-// RebalancePortfolio performs portfolio rebalancing
-func (r *PortfolioRebalancer) RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID) error {
+// RebalancePortfolio rebalances a portfolio on request when its strategy's trigger conditions
+// are met, and records the run as a manual execution
+func (r *PortfolioRebalancer) RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID) (*RebalanceExecution, error) {
+	return r.rebalance(ctx, portfolioID, RebalanceTriggerManual, nil, false)
+}
+
+// PreviewRebalance returns the actions a manual rebalance would take without executing or
+// recording them, priced by the cost estimator when one is set
+func (r *PortfolioRebalancer) PreviewRebalance(ctx context.Context, portfolioID uuid.UUID) (*RebalanceExecution, error) {
+	return r.rebalance(ctx, portfolioID, RebalanceTriggerManual, nil, true)
+}
+
+// rebalance runs a rebalance of a portfolio. Manual runs evaluate the strategy's trigger
+// conditions; scheduled and drift runs pass the reasons they were started for.
+func (r *PortfolioRebalancer) rebalance(ctx context.Context, portfolioID uuid.UUID, trigger RebalanceExecutionTrigger, reasons []string, dryRun bool) (*RebalanceExecution, error) {
+	r.mu.RLock()
 	strategy, exists := r.rebalanceRules[portfolioID]
+	r.mu.RUnlock()
 	if !exists {
-		return fmt.Errorf("no rebalance strategy found for portfolio: %s", portfolioID.String())
+		return nil, fmt.Errorf("%w for portfolio: %s", ErrRebalanceStrategyNotFound, portfolioID.String())
 	}
 
 	if !strategy.IsActive {
-		return fmt.Errorf("rebalance strategy is not active")
+		return nil, fmt.Errorf("rebalance strategy is not active")
 	}
 
 	// Get current portfolio
 	portfolio, err := r.tradingEngine.GetPortfolio(portfolioID)
 	if err != nil {
-		return fmt.Errorf("failed to get portfolio: %w", err)
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	// Calculate current allocations
+	currentAllocations, err := r.tradingEngine.GetCurrentAllocations(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations: %w", err)
+	}
+
+	if !dryRun {
+		r.mu.Lock()
+		if r.running[portfolioID] {
+			r.mu.Unlock()
+			return nil, fmt.Errorf("%w for portfolio: %s", ErrRebalanceInProgress, portfolioID.String())
+		}
+		r.running[portfolioID] = true
+		r.mu.Unlock()
+
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, portfolioID)
+			r.mu.Unlock()
+		}()
+	}
+
+	execution := &RebalanceExecution{
+		ID:          uuid.New(),
+		PortfolioID: portfolioID,
+		StrategyID:  strategy.ID,
+		Trigger:     trigger,
+		DryRun:      dryRun,
+		Reasons:     reasons,
+		Allocations: currentAllocations,
+		Actions:     []*RebalanceAction{},
+		StartedAt:   time.Now(),
 	}
 
 	// Check if rebalancing is needed
-	shouldRebalance, triggers := r.shouldRebalance(ctx, portfolio, strategy)
-	if !shouldRebalance {
-		return nil
+	if trigger == RebalanceTriggerManual {
+		shouldRebalance, triggers := r.shouldRebalance(ctx, portfolio, strategy, currentAllocations)
+		execution.Reasons = triggers
+		if !shouldRebalance {
+			execution.Status = RebalanceStatusSkipped
+			if dryRun {
+				execution.Status = RebalanceStatusPreview
+			}
+			execution.CompletedAt = time.Now()
+			if !dryRun {
+				r.mu.Lock()
+				r.recordExecutionLocked(execution)
+				r.mu.Unlock()
+			}
+			return execution, nil
+		}
+	}
+
+	// Generate rebalance actions
+	execution.Actions = r.generateRebalanceActions(ctx, portfolio, strategy, currentAllocations)
+
+	if dryRun {
+		r.estimateCosts(ctx, portfolio, strategy, execution)
+		execution.Status = RebalanceStatusPreview
+		execution.CompletedAt = time.Now()
+		return execution, nil
 	}
 
 	r.logger.Info(ctx, "Starting portfolio rebalance", map[string]interface{}{
 		"portfolio_id": portfolioID.String(),
-		"triggers":     triggers,
+		"trigger":      string(trigger),
+		"triggers":     execution.Reasons,
 	})
 
-	// Calculate current allocations
-	currentAllocations := r.calculateCurrentAllocations(portfolio)
-
-	// Generate rebalance actions
-	actions := r.generateRebalanceActions(ctx, portfolio, strategy, currentAllocations)
-
 	// Execute rebalance actions
-	for _, action := range actions {
+	for _, action := range execution.Actions {
 		if err := r.executeRebalanceAction(ctx, action); err != nil {
 			r.logger.Error(ctx, "Failed to execute rebalance action", err)
+			execution.FailedActions++
 			continue
 		}
 	}
 
+	switch {
+	case execution.FailedActions == 0:
+		execution.Status = RebalanceStatusCompleted
+	case execution.FailedActions == len(execution.Actions):
+		execution.Status = RebalanceStatusFailed
+	default:
+		execution.Status = RebalanceStatusPartial
+	}
+	execution.CompletedAt = time.Now()
+
 	// Update last rebalance time
-	strategy.LastRebalance = time.Now()
+	r.mu.Lock()
+	strategy.LastRebalance = execution.CompletedAt
+	r.recordExecutionLocked(execution)
+	updated := *strategy
+	r.mu.Unlock()
+
+	// The trades are done, so a failed save only loses the schedule's last run time
+	if err := r.saveStrategy(ctx, &updated); err != nil {
+		r.logger.Error(ctx, "Failed to save rebalance time", err, map[string]interface{}{
+			"portfolio_id": portfolioID.String(),
+		})
+	}
 
 	r.logger.Info(ctx, "Portfolio rebalance completed", map[string]interface{}{
 		"portfolio_id":     portfolioID.String(),
-		"actions_executed": len(actions),
+		"trigger":          string(trigger),
+		"actions_executed": len(execution.Actions) - execution.FailedActions,
 	})
 
-	return nil
+	return execution, nil
 }
 
 // shouldRebalance determines if portfolio should be rebalanced
-func (r *PortfolioRebalancer) shouldRebalance(ctx context.Context, portfolio *Portfolio, strategy *RebalanceStrategy) (bool, []string) {
+func (r *PortfolioRebalancer) shouldRebalance(ctx context.Context, portfolio *Portfolio, strategy *RebalanceStrategy, currentAllocations map[string]decimal.Decimal) (bool, []string) {
 	var triggeredConditions []string
 
-	currentAllocations := r.calculateCurrentAllocations(portfolio)
-
 	for _, trigger := range strategy.TriggerConditions {
 		triggered := false
 
@@ -319,18 +528,6 @@ func (r *PortfolioRebalancer) shouldRebalance(ctx context.Context, portfolio *Po
 	return false, triggeredConditions
 }
 
-// calculateCurrentAllocations calculates current portfolio allocations
-func (r *PortfolioRebalancer) calculateCurrentAllocations(portfolio *Portfolio) map[string]decimal.Decimal {
-	allocations := make(map[string]decimal.Decimal)
-
-	for asset, holding := range portfolio.Holdings {
-		allocation := holding.Value.Div(portfolio.TotalValue)
-		allocations[asset] = allocation
-	}
-
-	return allocations
-}
-
 // calculateMaxDrift calculates maximum drift from target allocations
 func (r *PortfolioRebalancer) calculateMaxDrift(current, target map[string]decimal.Decimal) decimal.Decimal {
 	maxDrift := decimal.Zero
@@ -0,0 +1,259 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Close reasons recorded when a protective order triggers
+const (
+	CloseReasonStopLoss   = "stop_loss_hit"
+	CloseReasonTakeProfit = "take_profit_hit"
+)
+
+var (
+	// ErrPositionNotFound is returned when a position is not open in the engine
+	ErrPositionNotFound = errors.New("position not found")
+	// ErrInvalidProtection is returned for inconsistent stop-loss or take-profit thresholds
+	ErrInvalidProtection = errors.New("invalid position protection")
+)
+
+// quoteSuffixes are stripped from market symbols such as ETHUSDT to match position token symbols
+var quoteSuffixes = []string{"USDT", "USDC", "BUSD", "USD"}
+
+// PositionProtection sets the stop-loss, take-profit and scale-out levels of a position. Each
+// threshold is either an absolute price or a fraction of the entry price (0.05 = 5%); omitted
+// thresholds and rules are cleared.
+type PositionProtection struct {
+	StopLoss          *decimal.Decimal `json:"stop_loss,omitempty"`
+	StopLossPercent   *decimal.Decimal `json:"stop_loss_percent,omitempty"`
+	TakeProfit        *decimal.Decimal `json:"take_profit,omitempty"`
+	TakeProfitPercent *decimal.Decimal `json:"take_profit_percent,omitempty"`
+	ScaleOut          []ScaleOutRule   `json:"scale_out,omitempty"`
+}
+
+// resolve converts the protection into absolute prices for a long position entered at entryPrice
+func (p PositionProtection) resolve(entryPrice decimal.Decimal) (stopLoss, takeProfit *decimal.Decimal, err error) {
+	if p.StopLoss != nil && p.StopLossPercent != nil {
+		return nil, nil, fmt.Errorf("%w: set either stop_loss or stop_loss_percent", ErrInvalidProtection)
+	}
+	if p.TakeProfit != nil && p.TakeProfitPercent != nil {
+		return nil, nil, fmt.Errorf("%w: set either take_profit or take_profit_percent", ErrInvalidProtection)
+	}
+
+	one := decimal.NewFromInt(1)
+	switch {
+	case p.StopLoss != nil:
+		if !p.StopLoss.IsPositive() || p.StopLoss.GreaterThanOrEqual(entryPrice) {
+			return nil, nil, fmt.Errorf("%w: stop_loss must be positive and below the entry price", ErrInvalidProtection)
+		}
+		price := *p.StopLoss
+		stopLoss = &price
+	case p.StopLossPercent != nil:
+		if !p.StopLossPercent.IsPositive() || p.StopLossPercent.GreaterThanOrEqual(one) {
+			return nil, nil, fmt.Errorf("%w: stop_loss_percent must be between 0 and 1", ErrInvalidProtection)
+		}
+		price := entryPrice.Mul(one.Sub(*p.StopLossPercent))
+		stopLoss = &price
+	}
+
+	switch {
+	case p.TakeProfit != nil:
+		if p.TakeProfit.LessThanOrEqual(entryPrice) {
+			return nil, nil, fmt.Errorf("%w: take_profit must be above the entry price", ErrInvalidProtection)
+		}
+		price := *p.TakeProfit
+		takeProfit = &price
+	case p.TakeProfitPercent != nil:
+		if !p.TakeProfitPercent.IsPositive() {
+			return nil, nil, fmt.Errorf("%w: take_profit_percent must be positive", ErrInvalidProtection)
+		}
+		price := entryPrice.Mul(one.Add(*p.TakeProfitPercent))
+		takeProfit = &price
+	}
+
+	return stopLoss, takeProfit, nil
+}
+
+// signalProtection builds the protection requested by a signal, falling back to the portfolio
+// risk profile when the engine enforces stop-loss or take-profit and the signal sets none
+func (t *TradingEngine) signalProtection(portfolio *Portfolio, signal *TradingSignal) PositionProtection {
+	protection := PositionProtection{
+		StopLoss:          signal.StopLoss,
+		StopLossPercent:   signal.StopLossPercent,
+		TakeProfit:        signal.TakeProfit,
+		TakeProfitPercent: signal.TakeProfitPercent,
+	}
+
+	if t.config.EnableStopLoss && protection.StopLoss == nil && protection.StopLossPercent == nil &&
+		portfolio.RiskProfile.StopLossPercentage.IsPositive() {
+		percent := portfolio.RiskProfile.StopLossPercentage
+		protection.StopLossPercent = &percent
+	}
+	if t.config.EnableTakeProfit && protection.TakeProfit == nil && protection.TakeProfitPercent == nil &&
+		portfolio.RiskProfile.TakeProfitPercentage.IsPositive() {
+		percent := portfolio.RiskProfile.TakeProfitPercentage
+		protection.TakeProfitPercent = &percent
+	}
+
+	return protection
+}
+
+// UpdatePositionProtection replaces the stop-loss and take-profit of an open position.
+// Percentages are applied to the entry price; a threshold the current price has already
+// crossed is rejected instead of closing the position immediately.
+func (t *TradingEngine) UpdatePositionProtection(ctx context.Context, positionID uuid.UUID, protection PositionProtection) (*Position, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	position, exists := t.activePositions[positionID.String()]
+	if !exists || position.Status != PositionStatusOpen {
+		return nil, fmt.Errorf("%w: %s", ErrPositionNotFound, positionID.String())
+	}
+
+	stopLoss, takeProfit, err := protection.resolve(position.EntryPrice)
+	if err != nil {
+		return nil, err
+	}
+	if stopLoss != nil && position.CurrentPrice.LessThanOrEqual(*stopLoss) {
+		return nil, fmt.Errorf("%w: stop-loss is at or above the current price %s", ErrInvalidProtection, position.CurrentPrice)
+	}
+	if takeProfit != nil && position.CurrentPrice.GreaterThanOrEqual(*takeProfit) {
+		return nil, fmt.Errorf("%w: take-profit is at or below the current price %s", ErrInvalidProtection, position.CurrentPrice)
+	}
+	scaleOut, err := resolveScaleOut(protection.ScaleOut, position.EntryPrice)
+	if err != nil {
+		return nil, err
+	}
+	if len(scaleOut) > 0 && position.CurrentPrice.GreaterThanOrEqual(scaleOut[0].Price) {
+		return nil, fmt.Errorf("%w: scale-out level %s is at or below the current price %s", ErrInvalidProtection, scaleOut[0].Price, position.CurrentPrice)
+	}
+
+	position.StopLoss = stopLoss
+	position.TakeProfit = takeProfit
+	position.ScaleOut = scaleOut
+	position.UpdatedAt = time.Now()
+
+	t.logger.Info(ctx, "Position protection updated", map[string]interface{}{
+		"position_id":     positionID.String(),
+		"stop_loss":       decimalString(stopLoss),
+		"take_profit":     decimalString(takeProfit),
+		"scale_out_rules": len(scaleOut),
+	})
+
+	snapshot := *position
+	return &snapshot, nil
+}
+
+// ApplyPrice marks every open position in symbol to price and closes those whose stop-loss or
+// take-profit has been reached, or scales out of those whose scale-out levels have. A price
+// that gaps past a threshold still triggers it, and the position is closed at the observed
+// price. Evaluation and closing happen under the engine lock, so a position is closed at most
+// once. The closed positions are returned.
+func (t *TradingEngine) ApplyPrice(ctx context.Context, symbol string, price decimal.Decimal) []*Position {
+	if !price.IsPositive() {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastMarketData = time.Now()
+
+	var closed []*Position
+	marked := make(map[uuid.UUID]bool)
+	for _, position := range t.activePositions {
+		if position.Status != PositionStatusOpen || !positionMatchesSymbol(position, symbol) {
+			continue
+		}
+
+		marked[position.PortfolioID] = true
+		position.CurrentPrice = price
+		position.UnrealizedPnL = position.Amount.Mul(price.Sub(position.EntryPrice))
+
+		reason := ""
+		switch {
+		case position.StopLoss != nil && price.LessThanOrEqual(*position.StopLoss):
+			reason = CloseReasonStopLoss
+		case position.TakeProfit != nil && price.GreaterThanOrEqual(*position.TakeProfit):
+			reason = CloseReasonTakeProfit
+		}
+		if reason == "" {
+			if t.applyScaleOutLocked(ctx, position, price) {
+				closed = append(closed, position)
+			}
+			continue
+		}
+
+		t.closePositionLocked(ctx, position, reason)
+		closed = append(closed, position)
+	}
+	t.emitValuationsLocked(marked, t.lastMarketData)
+
+	return closed
+}
+
+// MonitorPrices feeds live prices for the given market symbols into ApplyPrice until ctx is done
+func (t *TradingEngine) MonitorPrices(ctx context.Context, marketData *realtime.MarketDataService, symbols []string) {
+	var wg sync.WaitGroup
+	for _, symbol := range symbols {
+		updates := marketData.Subscribe(symbol)
+		wg.Add(1)
+		go func(symbol string, updates <-chan realtime.MarketUpdate) {
+			defer wg.Done()
+			defer marketData.Unsubscribe(symbol, updates)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case update, ok := <-updates:
+					if !ok {
+						return
+					}
+					for _, position := range t.ApplyPrice(ctx, update.Symbol, update.Price) {
+						t.logger.Info(ctx, "Protective order triggered", map[string]interface{}{
+							"position_id": position.ID.String(),
+							"symbol":      update.Symbol,
+							"price":       update.Price.String(),
+							"reason":      position.CloseReason,
+						})
+					}
+				}
+			}
+		}(symbol, updates)
+	}
+	wg.Wait()
+}
+
+// positionMatchesSymbol reports whether a market symbol such as ETHUSDT or ETH-USD quotes the position token
+func positionMatchesSymbol(position *Position, symbol string) bool {
+	symbol = strings.ToUpper(strings.NewReplacer("-", "", "/", "").Replace(symbol))
+	token := strings.ToUpper(position.TokenSymbol)
+	if token == "" {
+		return false
+	}
+	if token == symbol {
+		return true
+	}
+	for _, quote := range quoteSuffixes {
+		if strings.TrimSuffix(symbol, quote) == token && strings.HasSuffix(symbol, quote) {
+			return true
+		}
+	}
+	return false
+}
+
+func decimalString(value *decimal.Decimal) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
@@ -0,0 +1,182 @@
+package web3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestPosition(t *testing.T, engine *TradingEngine, signal *TradingSignal) (*Portfolio, *Position) {
+	t.Helper()
+	portfolio, err := engine.CreatePortfolio(context.Background(), uuid.New(), "Protection", decimal.NewFromInt(10000), RiskProfile{
+		Level:                "moderate",
+		StopLossPercentage:   decimal.NewFromFloat(0.1),
+		TakeProfitPercentage: decimal.NewFromFloat(0.2),
+	})
+	require.NoError(t, err)
+
+	position, err := engine.executeTrade(context.Background(), portfolio, signal, decimal.NewFromInt(1))
+	require.NoError(t, err)
+	engine.updatePortfolioAfterTrade(context.Background(), portfolio, position)
+	return portfolio, position
+}
+
+func ethSignal() *TradingSignal {
+	return &TradingSignal{
+		ID:          uuid.New(),
+		Action:      ActionBuy,
+		TokenOut:    "ETH",
+		AmountIn:    decimal.NewFromInt(1),
+		ExpectedOut: decimal.NewFromInt(2000),
+		Metadata:    map[string]interface{}{},
+	}
+}
+
+func newProtectionTestEngine() *TradingEngine {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	clients := make(map[int]*ethclient.Client)
+	return NewTradingEngine(clients, logger, NewRiskAssessmentService(clients, logger))
+}
+
+func TestPositionProtection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RiskProfileDefaults", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		_, position := openTestPosition(t, engine, ethSignal())
+		require.NotNil(t, position.StopLoss)
+		require.NotNil(t, position.TakeProfit)
+		assert.True(t, position.StopLoss.Equal(decimal.NewFromInt(1800)))
+		assert.True(t, position.TakeProfit.Equal(decimal.NewFromInt(2400)))
+	})
+
+	t.Run("StopLossGapTriggersOnce", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		portfolio, position := openTestPosition(t, engine, ethSignal())
+
+		assert.Empty(t, engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(1900)))
+
+		// Price gaps well past the stop-loss
+		closed := engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(1500))
+		require.Len(t, closed, 1)
+		assert.Equal(t, position.ID, closed[0].ID)
+		assert.Equal(t, PositionStatusClosed, position.Status)
+		assert.Equal(t, CloseReasonStopLoss, position.CloseReason)
+		assert.True(t, position.RealizedPnL.Equal(decimal.NewFromInt(-500)))
+		assert.NotContains(t, portfolio.ActivePositions, position.ID)
+
+		assert.Empty(t, engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(1400)), "closed positions must not trigger again")
+		assert.ErrorIs(t, engine.ClosePosition(ctx, position.ID, "manual"), ErrPositionNotFound)
+	})
+
+	t.Run("TakeProfit", func(t *testing.T) {
+		signal := ethSignal()
+		takeProfit := decimal.NewFromInt(2100)
+		signal.TakeProfit = &takeProfit
+		engine := newProtectionTestEngine()
+		_, position := openTestPosition(t, engine, signal)
+
+		closed := engine.ApplyPrice(ctx, "ETH-USD", decimal.NewFromInt(2150))
+		require.Len(t, closed, 1)
+		assert.Equal(t, CloseReasonTakeProfit, position.CloseReason)
+	})
+
+	t.Run("UpdateProtection", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		_, position := openTestPosition(t, engine, ethSignal())
+
+		percent := decimal.NewFromFloat(0.05)
+		updated, err := engine.UpdatePositionProtection(ctx, position.ID, PositionProtection{StopLossPercent: &percent})
+		require.NoError(t, err)
+		assert.True(t, updated.StopLoss.Equal(decimal.NewFromInt(1900)))
+		assert.Nil(t, updated.TakeProfit, "omitted thresholds are cleared")
+
+		invalid := decimal.NewFromInt(2500)
+		_, err = engine.UpdatePositionProtection(ctx, position.ID, PositionProtection{StopLoss: &invalid})
+		assert.ErrorIs(t, err, ErrInvalidProtection)
+
+		_, err = engine.UpdatePositionProtection(ctx, uuid.New(), PositionProtection{})
+		assert.ErrorIs(t, err, ErrPositionNotFound)
+	})
+
+	t.Run("PartialClose", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		portfolio, position := openTestPosition(t, engine, ethSignal())
+		engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2100))
+
+		half := decimal.NewFromFloat(0.5)
+		reduced, err := engine.ReducePosition(ctx, position.ID, PartialClose{Percentage: &half}, "trim")
+		require.NoError(t, err)
+		assert.Equal(t, PositionStatusOpen, reduced.Status)
+		assert.True(t, reduced.Amount.Equal(half))
+		assert.True(t, reduced.RealizedPnL.Equal(decimal.NewFromInt(50)))
+		assert.True(t, portfolio.TotalPnL.Equal(decimal.NewFromInt(50)))
+
+		engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2200))
+		require.NoError(t, engine.ClosePosition(ctx, position.ID, "manual"))
+		assert.True(t, position.RealizedPnL.Equal(decimal.NewFromInt(150)), "the final close adds to partial P&L")
+
+		require.Len(t, position.Events, 3)
+		assert.Equal(t, PositionEventReduced, position.Events[1].Type)
+		assert.True(t, position.Events[1].Price.Equal(decimal.NewFromInt(2100)))
+		assert.Equal(t, PositionEventClosed, position.Events[2].Type)
+		assert.True(t, position.Events[2].RealizedPnL.Equal(decimal.NewFromInt(100)))
+
+		trades, err := engine.GetTradeHistory(portfolio.ID)
+		require.NoError(t, err)
+		require.Len(t, trades, 3)
+		assert.True(t, trades[1].Quantity.Equal(half.Div(decimal.NewFromInt(2000))), "tokens bought with the amount at entry")
+		assert.True(t, trades[1].RealizedPnL.Equal(decimal.NewFromInt(50)))
+
+		zero := decimal.Zero
+		_, openPosition := openTestPosition(t, engine, ethSignal())
+		_, err = engine.ReducePosition(ctx, openPosition.ID, PartialClose{Quantity: &zero}, "trim")
+		assert.ErrorIs(t, err, ErrInvalidCloseSize)
+		_, err = engine.ReducePosition(ctx, openPosition.ID, PartialClose{Quantity: &half, Percentage: &half}, "trim")
+		assert.ErrorIs(t, err, ErrInvalidCloseSize)
+	})
+
+	t.Run("ScaleOut", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		_, position := openTestPosition(t, engine, ethSignal())
+
+		_, err := engine.UpdatePositionProtection(ctx, position.ID, PositionProtection{ScaleOut: []ScaleOutRule{
+			{GainPercent: decimal.NewFromFloat(0.1), ClosePercent: decimal.NewFromFloat(0.25)},
+			{GainPercent: decimal.NewFromFloat(0.05), ClosePercent: decimal.NewFromFloat(0.5)},
+		}})
+		require.NoError(t, err)
+		require.Len(t, position.ScaleOut, 2)
+		assert.True(t, position.ScaleOut[0].Price.Equal(decimal.NewFromInt(2100)), "levels are sorted nearest first")
+
+		assert.Empty(t, engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2150)))
+		assert.True(t, position.Amount.Equal(decimal.NewFromFloat(0.5)))
+		assert.True(t, position.RealizedPnL.Equal(decimal.NewFromInt(75)))
+
+		assert.Empty(t, engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2150)), "rules trigger once")
+		assert.True(t, position.Amount.Equal(decimal.NewFromFloat(0.5)))
+
+		assert.Empty(t, engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2250)))
+		assert.True(t, position.Amount.Equal(decimal.NewFromFloat(0.25)))
+		require.Len(t, position.Events, 3)
+		assert.Equal(t, CloseReasonScaleOut, position.Events[2].Reason)
+		assert.True(t, position.Events[2].Price.Equal(decimal.NewFromInt(2250)))
+
+		_, err = engine.UpdatePositionProtection(ctx, position.ID, PositionProtection{ScaleOut: []ScaleOutRule{
+			{GainPercent: decimal.NewFromFloat(0.2), ClosePercent: decimal.NewFromFloat(0.6)},
+			{GainPercent: decimal.NewFromFloat(0.3), ClosePercent: decimal.NewFromFloat(0.6)},
+		}})
+		assert.ErrorIs(t, err, ErrInvalidProtection, "rules may not close more than the position")
+
+		_, err = engine.UpdatePositionProtection(ctx, position.ID, PositionProtection{ScaleOut: []ScaleOutRule{
+			{GainPercent: decimal.NewFromFloat(0.1), ClosePercent: decimal.NewFromFloat(0.5)},
+		}})
+		assert.ErrorIs(t, err, ErrInvalidProtection, "levels the price has reached are rejected")
+	})
+}
@@ -0,0 +1,190 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CloseReasonScaleOut is recorded when a scale-out rule reduces or closes a position
+const CloseReasonScaleOut = "scale_out"
+
+// ErrInvalidCloseSize is returned for partial closes without a positive quantity or with a
+// percentage outside (0, 1]
+var ErrInvalidCloseSize = errors.New("invalid close size")
+
+// Position event types
+const (
+	PositionEventOpened  = "opened"
+	PositionEventReduced = "reduced"
+	PositionEventClosed  = "closed"
+)
+
+// PositionEvent is one entry of a position's history. Reductions and the final close carry
+// the price they were filled at and the P&L they realized.
+type PositionEvent struct {
+	Type            string          `json:"type"`
+	Quantity        decimal.Decimal `json:"quantity"`
+	Price           decimal.Decimal `json:"price"`
+	RealizedPnL     decimal.Decimal `json:"realized_pnl"`
+	RemainingAmount decimal.Decimal `json:"remaining_amount"`
+	Reason          string          `json:"reason,omitempty"`
+	At              time.Time       `json:"at"`
+}
+
+// ScaleOutRule closes a fraction of a position's initial amount once the price gains
+// GainPercent over the entry price (0.05 = 5%). Each rule triggers once.
+type ScaleOutRule struct {
+	GainPercent  decimal.Decimal `json:"gain_percent"`
+	ClosePercent decimal.Decimal `json:"close_percent"`
+	Price        decimal.Decimal `json:"price"` // trigger price resolved from the entry price
+	Triggered    bool            `json:"triggered"`
+}
+
+// PartialClose sizes a partial close either by quantity or by a fraction of the open amount
+// (0.5 = 50%). Sizes reaching the open amount close the position.
+type PartialClose struct {
+	Quantity   *decimal.Decimal `json:"quantity,omitempty"`
+	Percentage *decimal.Decimal `json:"percentage,omitempty"`
+}
+
+// resolveScaleOut validates scale-out rules and resolves their trigger prices for a long
+// position entered at entryPrice, sorted from the nearest level
+func resolveScaleOut(rules []ScaleOutRule, entryPrice decimal.Decimal) ([]ScaleOutRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	one := decimal.NewFromInt(1)
+	resolved := make([]ScaleOutRule, len(rules))
+	total := decimal.Zero
+	for i, rule := range rules {
+		if !rule.GainPercent.IsPositive() {
+			return nil, fmt.Errorf("%w: scale_out gain_percent must be positive", ErrInvalidProtection)
+		}
+		if !rule.ClosePercent.IsPositive() || rule.ClosePercent.GreaterThan(one) {
+			return nil, fmt.Errorf("%w: scale_out close_percent must be between 0 and 1", ErrInvalidProtection)
+		}
+		total = total.Add(rule.ClosePercent)
+		resolved[i] = ScaleOutRule{
+			GainPercent:  rule.GainPercent,
+			ClosePercent: rule.ClosePercent,
+			Price:        entryPrice.Mul(one.Add(rule.GainPercent)),
+		}
+	}
+	if total.GreaterThan(one) {
+		return nil, fmt.Errorf("%w: scale_out rules close more than the whole position", ErrInvalidProtection)
+	}
+
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].GainPercent.LessThan(resolved[j].GainPercent) })
+	for i := 1; i < len(resolved); i++ {
+		if resolved[i].GainPercent.Equal(resolved[i-1].GainPercent) {
+			return nil, fmt.Errorf("%w: scale_out levels must be distinct", ErrInvalidProtection)
+		}
+	}
+	return resolved, nil
+}
+
+// ReducePosition closes part of an open position at its current price, realizing the P&L of
+// the closed quantity. A size reaching the open amount closes the position.
+func (t *TradingEngine) ReducePosition(ctx context.Context, positionID uuid.UUID, size PartialClose, reason string) (*Position, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	position, exists := t.activePositions[positionID.String()]
+	if !exists || position.Status != PositionStatusOpen {
+		return nil, fmt.Errorf("%w: %s", ErrPositionNotFound, positionID.String())
+	}
+
+	var quantity decimal.Decimal
+	switch {
+	case size.Quantity != nil && size.Percentage != nil:
+		return nil, fmt.Errorf("%w: set either quantity or percentage", ErrInvalidCloseSize)
+	case size.Quantity != nil:
+		if !size.Quantity.IsPositive() {
+			return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidCloseSize)
+		}
+		quantity = *size.Quantity
+	case size.Percentage != nil:
+		if !size.Percentage.IsPositive() || size.Percentage.GreaterThan(decimal.NewFromInt(1)) {
+			return nil, fmt.Errorf("%w: percentage must be between 0 and 1", ErrInvalidCloseSize)
+		}
+		quantity = position.Amount.Mul(*size.Percentage)
+	default:
+		return nil, fmt.Errorf("%w: set quantity or percentage", ErrInvalidCloseSize)
+	}
+
+	t.reducePositionLocked(ctx, position, quantity, reason)
+
+	snapshot := *position
+	return &snapshot, nil
+}
+
+// reducePositionLocked closes quantity of a position at its current price and releases the
+// funds to the portfolio, closing the position when quantity reaches its amount. It reports
+// whether the position was closed. The caller must hold t.mu.
+func (t *TradingEngine) reducePositionLocked(ctx context.Context, position *Position, quantity decimal.Decimal, reason string) bool {
+	if quantity.GreaterThanOrEqual(position.Amount) {
+		t.closePositionLocked(ctx, position, reason)
+		return true
+	}
+
+	now := time.Now()
+	realizedPnL := quantity.Mul(position.CurrentPrice.Sub(position.EntryPrice))
+	position.Amount = position.Amount.Sub(quantity)
+	position.RealizedPnL = position.RealizedPnL.Add(realizedPnL)
+	position.UnrealizedPnL = position.Amount.Mul(position.CurrentPrice.Sub(position.EntryPrice))
+	position.UpdatedAt = now
+	position.Events = append(position.Events, PositionEvent{
+		Type:            PositionEventReduced,
+		Quantity:        quantity,
+		Price:           position.CurrentPrice,
+		RealizedPnL:     realizedPnL,
+		RemainingAmount: position.Amount,
+		Reason:          reason,
+		At:              now,
+	})
+	t.recordTradeLocked(position, TradeSideSell, quantity, position.CurrentPrice, realizedPnL, now, nil)
+
+	if portfolio := t.portfolios[position.PortfolioID]; portfolio != nil {
+		portfolio.AvailableBalance = portfolio.AvailableBalance.Add(quantity)
+		portfolio.InvestedAmount = portfolio.InvestedAmount.Sub(quantity)
+		portfolio.TotalPnL = portfolio.TotalPnL.Add(realizedPnL)
+	}
+
+	t.logger.Info(ctx, "Position reduced", map[string]interface{}{
+		"position_id":  position.ID.String(),
+		"quantity":     quantity.String(),
+		"remaining":    position.Amount.String(),
+		"realized_pnl": realizedPnL.String(),
+		"reason":       reason,
+	})
+	return false
+}
+
+// applyScaleOutLocked triggers the scale-out rules of a position whose levels price has
+// reached, nearest first, and reports whether they closed the position. The caller must
+// hold t.mu.
+func (t *TradingEngine) applyScaleOutLocked(ctx context.Context, position *Position, price decimal.Decimal) bool {
+	initial := position.InitialAmount
+	if !initial.IsPositive() {
+		initial = position.Amount
+	}
+
+	for i := range position.ScaleOut {
+		rule := &position.ScaleOut[i]
+		if rule.Triggered || price.LessThan(rule.Price) {
+			continue
+		}
+		rule.Triggered = true
+		if t.reducePositionLocked(ctx, position, initial.Mul(rule.ClosePercent), CloseReasonScaleOut) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidPositionSizeRequest is returned when a position cannot be sized from the given inputs
+var ErrInvalidPositionSizeRequest = errors.New("invalid position size request")
+
+const (
+	// defaultAnnualVolatility is assumed for assets without a volatility estimate
+	defaultAnnualVolatility = 0.8
+	// stopDistanceVolatilityMultiple places the assumed stop two daily standard deviations away
+	stopDistanceVolatilityMultiple = 2.0
+	minStopDistance                = 0.01
+	maxStopDistance                = 0.5
+)
+
+// Reasons a recommended position size was capped
+const (
+	PositionLimitRiskBudget      = "risk_budget"
+	PositionLimitMaxRiskExposure = "max_risk_exposure"
+	PositionLimitConcentration   = "concentration"
+	PositionLimitMaxPosition     = "max_position_size"
+	PositionLimitAvailableCash   = "available_cash"
+)
+
+// PositionSizeRequest holds the inputs for sizing a new position
+type PositionSizeRequest struct {
+	Asset           string          `json:"asset"`
+	Price           decimal.Decimal `json:"price"`
+	PortfolioValue  decimal.Decimal `json:"portfolio_value"`
+	AvailableCash   decimal.Decimal `json:"available_cash"`    // zero when unknown
+	MaxPositionSize decimal.Decimal `json:"max_position_size"` // notional cap, zero for none
+	MaxRiskExposure float64         `json:"max_risk_exposure"` // share of the portfolio that may be lost, zero for none
+	Volatility      float64         `json:"volatility"`        // annualised, zero when unknown
+	RiskTolerance   float64         `json:"risk_tolerance"`    // 0 (conservative) to 1 (aggressive)
+	StopDistance    float64         `json:"stop_distance"`     // share of the price to the stop loss, derived from volatility when zero
+	SizeAdjustment  float64         `json:"size_adjustment"`   // learned multiplier for the user, 1 when zero
+}
+
+// PositionSizeRecommendation is a recommended position size with the inputs it was based on
+type PositionSizeRecommendation struct {
+	Asset            string              `json:"asset"`
+	Quantity         decimal.Decimal     `json:"quantity"`
+	Notional         decimal.Decimal     `json:"notional"`
+	PortfolioPercent float64             `json:"portfolio_percent"`
+	RiskAmount       decimal.Decimal     `json:"risk_amount"` // expected loss if the stop is hit
+	RiskPerTrade     float64             `json:"risk_per_trade"`
+	StopDistance     float64             `json:"stop_distance"`
+	LimitedBy        string              `json:"limited_by"`
+	Inputs           PositionSizeRequest `json:"inputs"`
+}
+
+// RecommendPositionSize sizes a position so that hitting a volatility-based stop loses a share
+// of the portfolio set by the risk tolerance. The size is then capped by the request's maximum
+// risk exposure and position size, a tolerance-based concentration limit and the available cash.
+func (r *RiskAssessmentService) RecommendPositionSize(ctx context.Context, req PositionSizeRequest) (*PositionSizeRecommendation, error) {
+	if !req.Price.IsPositive() {
+		return nil, fmt.Errorf("%w: price of %s is required", ErrInvalidPositionSizeRequest, req.Asset)
+	}
+	if !req.PortfolioValue.IsPositive() {
+		return nil, fmt.Errorf("%w: portfolio value is required", ErrInvalidPositionSizeRequest)
+	}
+	if req.MaxRiskExposure < 0 || req.Volatility < 0 || req.StopDistance < 0 || req.SizeAdjustment < 0 {
+		return nil, fmt.Errorf("%w: risk inputs must not be negative", ErrInvalidPositionSizeRequest)
+	}
+
+	tolerance := math.Max(0, math.Min(1, req.RiskTolerance))
+	adjustment := req.SizeAdjustment
+	if adjustment == 0 {
+		adjustment = 1
+	}
+
+	recommendation := &PositionSizeRecommendation{
+		Asset:        req.Asset,
+		RiskPerTrade: 0.005 + 0.025*tolerance, // 0.5% to 3% of the portfolio
+		StopDistance: req.StopDistance,
+		LimitedBy:    PositionLimitRiskBudget,
+		Inputs:       req,
+	}
+	if req.MaxRiskExposure > 0 && req.MaxRiskExposure < recommendation.RiskPerTrade {
+		recommendation.RiskPerTrade = req.MaxRiskExposure
+		recommendation.LimitedBy = PositionLimitMaxRiskExposure
+	}
+	if recommendation.StopDistance == 0 {
+		volatility := req.Volatility
+		if volatility == 0 {
+			volatility = defaultAnnualVolatility
+		}
+		recommendation.StopDistance = stopDistanceVolatilityMultiple * volatility / math.Sqrt(365)
+	}
+	recommendation.StopDistance = math.Max(minStopDistance, math.Min(maxStopDistance, recommendation.StopDistance))
+
+	riskBudget := req.PortfolioValue.Mul(decimal.NewFromFloat(recommendation.RiskPerTrade * adjustment))
+	notional := riskBudget.Div(decimal.NewFromFloat(recommendation.StopDistance))
+
+	capAt := func(limit decimal.Decimal, reason string) {
+		if limit.IsPositive() && notional.GreaterThan(limit) {
+			notional = limit
+			recommendation.LimitedBy = reason
+		}
+	}
+	// A single position takes 5% to 25% of the portfolio at most
+	capAt(req.PortfolioValue.Mul(decimal.NewFromFloat(0.05+0.20*tolerance)), PositionLimitConcentration)
+	capAt(req.MaxPositionSize, PositionLimitMaxPosition)
+	capAt(req.AvailableCash, PositionLimitAvailableCash)
+
+	recommendation.Quantity = notional.Div(req.Price).RoundDown(8)
+	recommendation.Notional = recommendation.Quantity.Mul(req.Price).Round(2)
+	recommendation.RiskAmount = recommendation.Notional.Mul(decimal.NewFromFloat(recommendation.StopDistance)).Round(2)
+	recommendation.PortfolioPercent = recommendation.Notional.Div(req.PortfolioValue).Mul(decimal.NewFromInt(100)).Round(2).InexactFloat64()
+
+	r.logger.Debug(ctx, "Position size recommended", map[string]interface{}{
+		"asset":             req.Asset,
+		"quantity":          recommendation.Quantity.String(),
+		"portfolio_percent": recommendation.PortfolioPercent,
+		"limited_by":        recommendation.LimitedBy,
+	})
+
+	return recommendation, nil
+}
@@ -0,0 +1,61 @@
+package web3
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// Recommendations of a rebalance cost estimate
+const (
+	RebalanceRecommendTrade   = "trade"
+	RebalanceRecommendNoTrade = "no_trade"
+)
+
+// RebalanceCostEstimate weighs what trading a rebalance would cost against its expected benefit
+type RebalanceCostEstimate struct {
+	AssetCosts      map[string]decimal.Decimal `json:"asset_costs"` // fees and slippage per traded asset
+	EstimatedCost   decimal.Decimal            `json:"estimated_cost"`
+	ExpectedBenefit decimal.Decimal            `json:"expected_benefit"`
+	NetBenefit      decimal.Decimal            `json:"net_benefit"`
+	BreakevenReturn decimal.Decimal            `json:"breakeven_return"` // annualized return uplift that pays for the trades
+	Recommendation  string                     `json:"recommendation"`
+}
+
+// RebalanceCostEstimator prices rebalancing a portfolio to target allocations
+type RebalanceCostEstimator interface {
+	EstimateRebalanceCost(ctx context.Context, portfolio *Portfolio, targets map[string]decimal.Decimal) (*RebalanceCostEstimate, error)
+}
+
+// SetCostEstimator prices the actions of rebalance previews and recommends whether they are
+// worth trading
+func (r *PortfolioRebalancer) SetCostEstimator(estimator RebalanceCostEstimator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.costs = estimator
+}
+
+// estimateCosts prices a preview's actions. Previews are left unpriced when no estimator is
+// set or it fails.
+func (r *PortfolioRebalancer) estimateCosts(ctx context.Context, portfolio *Portfolio, strategy *RebalanceStrategy, execution *RebalanceExecution) {
+	r.mu.RLock()
+	estimator := r.costs
+	r.mu.RUnlock()
+	if estimator == nil || len(execution.Actions) == 0 {
+		return
+	}
+
+	estimate, err := estimator.EstimateRebalanceCost(ctx, portfolio, strategy.TargetAllocations)
+	if err != nil {
+		r.logger.Warn(ctx, "Failed to estimate rebalance costs", map[string]interface{}{
+			"portfolio_id": portfolio.ID.String(),
+			"error":        err.Error(),
+		})
+		return
+	}
+
+	for _, action := range execution.Actions {
+		action.ExpectedCost = estimate.AssetCosts[action.ToAsset]
+	}
+	execution.Costs = estimate
+}
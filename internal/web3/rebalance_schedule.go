@@ -0,0 +1,353 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidRebalanceSchedule is returned for schedules with a missing or malformed setting
+var ErrInvalidRebalanceSchedule = errors.New("invalid rebalance schedule")
+
+// ScheduleFrequency selects when a strategy is rebalanced automatically
+type ScheduleFrequency string
+
+const (
+	ScheduleFrequencyManual   ScheduleFrequency = "manual"   // Only through the execute endpoint
+	ScheduleFrequencyInterval ScheduleFrequency = "interval" // Every fixed interval
+	ScheduleFrequencyDaily    ScheduleFrequency = "daily"    // Once a day at a UTC time
+	ScheduleFrequencyWeekly   ScheduleFrequency = "weekly"   // Once a week on a weekday at a UTC time
+	ScheduleFrequencyDrift    ScheduleFrequency = "drift"    // Whenever allocations drift past a threshold
+)
+
+// RebalanceSchedule configures automatic rebalancing of a strategy
+type RebalanceSchedule struct {
+	Frequency ScheduleFrequency `json:"frequency"`
+	// Interval between runs for interval schedules, e.g. "6h"
+	Interval string `json:"interval,omitempty"`
+	// TimeOfDay is the UTC run time of daily and weekly schedules as HH:MM, default 00:00
+	TimeOfDay string `json:"time_of_day,omitempty"`
+	// Weekday of weekly schedules, e.g. "monday" (default)
+	Weekday string `json:"weekday,omitempty"`
+	// DriftThreshold overrides the strategy's drift trigger for drift schedules
+	DriftThreshold *decimal.Decimal `json:"drift_threshold,omitempty"`
+}
+
+// RebalanceExecutionTrigger records what started a rebalance execution
+type RebalanceExecutionTrigger string
+
+const (
+	RebalanceTriggerManual    RebalanceExecutionTrigger = "manual"
+	RebalanceTriggerScheduled RebalanceExecutionTrigger = "scheduled"
+	RebalanceTriggerDrift     RebalanceExecutionTrigger = "drift"
+)
+
+// Rebalance execution outcomes
+const (
+	RebalanceStatusCompleted = "completed"
+	RebalanceStatusPartial   = "partial"
+	RebalanceStatusFailed    = "failed"
+	RebalanceStatusSkipped   = "skipped"
+	RebalanceStatusPreview   = "preview"
+)
+
+// maxRebalanceExecutions bounds the execution history kept per portfolio
+const maxRebalanceExecutions = 100
+
+// RebalanceExecution is the record of one rebalance run, or of a dry-run preview
+type RebalanceExecution struct {
+	ID            uuid.UUID                  `json:"id"`
+	PortfolioID   uuid.UUID                  `json:"portfolio_id"`
+	StrategyID    uuid.UUID                  `json:"strategy_id"`
+	Trigger       RebalanceExecutionTrigger  `json:"trigger"`
+	DryRun        bool                       `json:"dry_run"`
+	Status        string                     `json:"status"`
+	Reasons       []string                   `json:"reasons"`
+	Allocations   map[string]decimal.Decimal `json:"allocations"`
+	Actions       []*RebalanceAction         `json:"actions"`
+	FailedActions int                        `json:"failed_actions"`
+	StartedAt     time.Time                  `json:"started_at"`
+	CompletedAt   time.Time                  `json:"completed_at"`
+
+	// Costs prices the actions of a preview against the benefit of trading them
+	Costs *RebalanceCostEstimate `json:"costs,omitempty"`
+}
+
+// Validate checks the schedule settings required by its frequency
+func (s *RebalanceSchedule) Validate() error {
+	switch s.Frequency {
+	case "", ScheduleFrequencyManual:
+		return nil
+	case ScheduleFrequencyInterval:
+		interval, err := time.ParseDuration(s.Interval)
+		if err != nil || interval < time.Minute {
+			return fmt.Errorf("%w: interval must be a duration of at least 1m, got %q", ErrInvalidRebalanceSchedule, s.Interval)
+		}
+	case ScheduleFrequencyWeekly:
+		if _, err := s.weekday(); err != nil {
+			return err
+		}
+		fallthrough
+	case ScheduleFrequencyDaily:
+		if _, _, err := s.clock(); err != nil {
+			return err
+		}
+	case ScheduleFrequencyDrift:
+		if s.DriftThreshold != nil && (!s.DriftThreshold.IsPositive() || s.DriftThreshold.GreaterThanOrEqual(decimal.NewFromInt(1))) {
+			return fmt.Errorf("%w: drift_threshold must be between 0 and 1", ErrInvalidRebalanceSchedule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown frequency %q", ErrInvalidRebalanceSchedule, s.Frequency)
+	}
+	return nil
+}
+
+// NextRun returns the first run of a time-based schedule after since. Drift and manual
+// schedules have no fixed run time and return the zero time.
+func (s *RebalanceSchedule) NextRun(since time.Time) time.Time {
+	since = since.UTC()
+
+	switch s.Frequency {
+	case ScheduleFrequencyInterval:
+		interval, err := time.ParseDuration(s.Interval)
+		if err != nil || interval <= 0 {
+			return time.Time{}
+		}
+		return since.Add(interval)
+	case ScheduleFrequencyDaily, ScheduleFrequencyWeekly:
+		hour, minute, err := s.clock()
+		if err != nil {
+			return time.Time{}
+		}
+		next := time.Date(since.Year(), since.Month(), since.Day(), hour, minute, 0, 0, time.UTC)
+		if s.Frequency == ScheduleFrequencyWeekly {
+			weekday, err := s.weekday()
+			if err != nil {
+				return time.Time{}
+			}
+			next = next.AddDate(0, 0, (int(weekday)-int(next.Weekday())+7)%7)
+			if !next.After(since) {
+				next = next.AddDate(0, 0, 7)
+			}
+			return next
+		}
+		if !next.After(since) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	default:
+		return time.Time{}
+	}
+}
+
+// clock parses the HH:MM run time, defaulting to midnight
+func (s *RebalanceSchedule) clock() (int, int, error) {
+	if s.TimeOfDay == "" {
+		return 0, 0, nil
+	}
+	parsed, err := time.Parse("15:04", s.TimeOfDay)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: time_of_day must be HH:MM, got %q", ErrInvalidRebalanceSchedule, s.TimeOfDay)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+// weekday parses the weekly run day, defaulting to Monday
+func (s *RebalanceSchedule) weekday() (time.Weekday, error) {
+	if s.Weekday == "" {
+		return time.Monday, nil
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(s.Weekday, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown weekday %q", ErrInvalidRebalanceSchedule, s.Weekday)
+}
+
+// SetRebalanceSchedule sets how the active strategy of a portfolio is rebalanced
+// automatically. A nil schedule or manual frequency turns automatic rebalancing off.
+func (r *PortfolioRebalancer) SetRebalanceSchedule(ctx context.Context, portfolioID uuid.UUID, schedule *RebalanceSchedule) (*RebalanceStrategy, error) {
+	if schedule != nil {
+		if err := schedule.Validate(); err != nil {
+			return nil, err
+		}
+		if schedule.Frequency == "" {
+			schedule.Frequency = ScheduleFrequencyManual
+		}
+	}
+
+	r.mu.RLock()
+	strategy, exists := r.rebalanceRules[portfolioID]
+	var updated RebalanceStrategy
+	if exists {
+		updated = *strategy
+		updated.Schedule = schedule
+	}
+	r.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w for portfolio: %s", ErrRebalanceStrategyNotFound, portfolioID.String())
+	}
+	if err := r.saveStrategy(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	strategy.Schedule = schedule
+	r.mu.Unlock()
+
+	frequency := ScheduleFrequencyManual
+	if schedule != nil {
+		frequency = schedule.Frequency
+	}
+	r.logger.Info(ctx, "Rebalance schedule updated", map[string]interface{}{
+		"strategy_id":  strategy.ID.String(),
+		"portfolio_id": portfolioID.String(),
+		"frequency":    string(frequency),
+	})
+
+	return strategy, nil
+}
+
+// StartScheduler evaluates scheduled strategies every interval until ctx is cancelled,
+// executing those that are due
+func (r *PortfolioRebalancer) StartScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				r.RunScheduled(ctx, now)
+			}
+		}
+	}()
+
+	r.logger.Info(ctx, "Rebalance scheduler started", map[string]interface{}{
+		"interval": interval.String(),
+	})
+}
+
+// RunScheduled executes every active strategy whose schedule is due at now and returns the
+// executions made
+func (r *PortfolioRebalancer) RunScheduled(ctx context.Context, now time.Time) []*RebalanceExecution {
+	type dueStrategy struct {
+		portfolioID   uuid.UUID
+		schedule      RebalanceSchedule
+		lastRun       time.Time
+		lastRebalance time.Time
+		threshold     decimal.Decimal
+	}
+
+	r.mu.RLock()
+	var candidates []dueStrategy
+	for portfolioID, strategy := range r.rebalanceRules {
+		if !strategy.IsActive || strategy.Schedule == nil {
+			continue
+		}
+		lastRun := strategy.LastRebalance
+		if lastRun.IsZero() {
+			lastRun = strategy.CreatedAt
+		}
+		candidates = append(candidates, dueStrategy{
+			portfolioID:   portfolioID,
+			schedule:      *strategy.Schedule,
+			lastRun:       lastRun,
+			lastRebalance: strategy.LastRebalance,
+			threshold:     r.driftThreshold(strategy),
+		})
+	}
+	cooldown := r.config.RebalanceInterval
+	r.mu.RUnlock()
+
+	var executions []*RebalanceExecution
+	for _, candidate := range candidates {
+		var trigger RebalanceExecutionTrigger
+		var reasons []string
+
+		switch candidate.schedule.Frequency {
+		case ScheduleFrequencyInterval, ScheduleFrequencyDaily, ScheduleFrequencyWeekly:
+			next := candidate.schedule.NextRun(candidate.lastRun)
+			if next.IsZero() || next.After(now) {
+				continue
+			}
+			trigger = RebalanceTriggerScheduled
+			reasons = []string{fmt.Sprintf("schedule: %s run due at %s", candidate.schedule.Frequency, next.Format(time.RFC3339))}
+
+		case ScheduleFrequencyDrift:
+			// Drift persists until trades settle, so drift runs are spaced by the rebalance interval
+			if !candidate.lastRebalance.IsZero() && now.Sub(candidate.lastRebalance) < cooldown {
+				continue
+			}
+			threshold := candidate.threshold
+			if candidate.schedule.DriftThreshold != nil {
+				threshold = *candidate.schedule.DriftThreshold
+			}
+			drift, err := r.currentDrift(candidate.portfolioID)
+			if err != nil {
+				r.logger.Error(ctx, "Failed to evaluate rebalance drift", err, map[string]interface{}{
+					"portfolio_id": candidate.portfolioID.String(),
+				})
+				continue
+			}
+			if !drift.GreaterThan(threshold) {
+				continue
+			}
+			trigger = RebalanceTriggerDrift
+			reasons = []string{fmt.Sprintf("drift: %s exceeds %s", drift.String(), threshold.String())}
+
+		default:
+			continue
+		}
+
+		execution, err := r.rebalance(ctx, candidate.portfolioID, trigger, reasons, false)
+		if err != nil {
+			r.logger.Error(ctx, "Scheduled rebalance failed", err, map[string]interface{}{
+				"portfolio_id": candidate.portfolioID.String(),
+				"trigger":      string(trigger),
+			})
+			continue
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions
+}
+
+// currentDrift returns the largest deviation of a portfolio's allocations from its targets
+func (r *PortfolioRebalancer) currentDrift(portfolioID uuid.UUID) (decimal.Decimal, error) {
+	r.mu.RLock()
+	strategy, exists := r.rebalanceRules[portfolioID]
+	r.mu.RUnlock()
+	if !exists {
+		return decimal.Zero, fmt.Errorf("%w for portfolio: %s", ErrRebalanceStrategyNotFound, portfolioID.String())
+	}
+
+	allocations, err := r.tradingEngine.GetCurrentAllocations(portfolioID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return r.calculateMaxDrift(allocations, strategy.TargetAllocations), nil
+}
+
+// recordExecutionLocked adds an execution to the portfolio's history, newest first. The
+// caller must hold r.mu.
+func (r *PortfolioRebalancer) recordExecutionLocked(execution *RebalanceExecution) {
+	executions := append([]*RebalanceExecution{execution}, r.executions[execution.PortfolioID]...)
+	if len(executions) > maxRebalanceExecutions {
+		executions = executions[:maxRebalanceExecutions]
+	}
+	r.executions[execution.PortfolioID] = executions
+}
@@ -0,0 +1,154 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/google/uuid"
+)
+
+// RebalanceStrategyStore persists rebalance strategies, active and superseded
+type RebalanceStrategyStore interface {
+	// Save inserts or replaces a strategy
+	Save(ctx context.Context, strategy *RebalanceStrategy) error
+	// ListByPortfolio returns a portfolio's strategies, newest first
+	ListByPortfolio(ctx context.Context, portfolioID uuid.UUID) ([]*RebalanceStrategy, error)
+	// ListActive returns the active strategies of every portfolio, newest first
+	ListActive(ctx context.Context) ([]*RebalanceStrategy, error)
+}
+
+// SetStrategyStore persists strategies as they are created, rescheduled and executed. Without
+// a store strategies live only in memory.
+func (r *PortfolioRebalancer) SetStrategyStore(store RebalanceStrategyStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategies = store
+}
+
+// LoadStrategies restores the active strategy of every portfolio from the store, so scheduled
+// rebalancing resumes after a restart. Strategies already in memory are kept.
+func (r *PortfolioRebalancer) LoadStrategies(ctx context.Context) error {
+	store := r.strategyStore()
+	if store == nil {
+		return nil
+	}
+
+	strategies, err := store.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load rebalance strategies: %w", err)
+	}
+
+	r.mu.Lock()
+	restored := 0
+	for _, strategy := range strategies {
+		if _, exists := r.rebalanceRules[strategy.PortfolioID]; !exists {
+			r.rebalanceRules[strategy.PortfolioID] = strategy
+			restored++
+		}
+	}
+	r.mu.Unlock()
+
+	r.logger.Info(ctx, "Rebalance strategies loaded", map[string]interface{}{
+		"strategies": restored,
+	})
+	return nil
+}
+
+// strategyStore returns the strategy store, or nil when strategies are not persisted
+func (r *PortfolioRebalancer) strategyStore() RebalanceStrategyStore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.strategies
+}
+
+// saveStrategy persists a strategy when a store is set
+func (r *PortfolioRebalancer) saveStrategy(ctx context.Context, strategy *RebalanceStrategy) error {
+	store := r.strategyStore()
+	if store == nil {
+		return nil
+	}
+	if err := store.Save(ctx, strategy); err != nil {
+		return fmt.Errorf("failed to save rebalance strategy: %w", err)
+	}
+	return nil
+}
+
+// adoptStoredStrategies splits a portfolio's stored strategies into its active strategy and
+// history. The strategy in memory stays active; without one the newest stored active strategy
+// is taken up, e.g. after a restart.
+func (r *PortfolioRebalancer) adoptStoredStrategies(portfolioID uuid.UUID, stored []*RebalanceStrategy) (*RebalanceStrategy, []*RebalanceStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active, exists := r.rebalanceRules[portfolioID]
+	history := make([]*RebalanceStrategy, 0, len(stored))
+	for _, strategy := range stored {
+		switch {
+		case exists && strategy.ID == active.ID:
+			// already active in memory
+		case !exists && strategy.IsActive:
+			active, exists = strategy, true
+			r.rebalanceRules[portfolioID] = strategy
+		default:
+			history = append(history, strategy)
+		}
+	}
+	return active, history
+}
+
+// postgresRebalanceStrategyStore keeps strategies in Postgres as JSON, with the columns they
+// are looked up by
+type postgresRebalanceStrategyStore struct {
+	db *database.DB
+}
+
+// NewPostgresRebalanceStrategyStore creates a rebalance strategy store backed by Postgres
+func NewPostgresRebalanceStrategyStore(db *database.DB) RebalanceStrategyStore {
+	return &postgresRebalanceStrategyStore{db: db}
+}
+
+func (s *postgresRebalanceStrategyStore) Save(ctx context.Context, strategy *RebalanceStrategy) error {
+	data, err := json.Marshal(strategy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rebalance strategy: %w", err)
+	}
+	query := `
+		INSERT INTO rebalance_strategies (id, portfolio_id, is_active, data, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (id) DO UPDATE SET is_active = EXCLUDED.is_active, data = EXCLUDED.data, updated_at = NOW()
+	`
+	_, err = s.db.ExecWithMetrics(ctx, query, strategy.ID, strategy.PortfolioID, strategy.IsActive, data, strategy.CreatedAt)
+	return err
+}
+
+func (s *postgresRebalanceStrategyStore) ListByPortfolio(ctx context.Context, portfolioID uuid.UUID) ([]*RebalanceStrategy, error) {
+	return s.list(ctx, `SELECT data FROM rebalance_strategies WHERE portfolio_id = $1 ORDER BY created_at DESC`, portfolioID)
+}
+
+func (s *postgresRebalanceStrategyStore) ListActive(ctx context.Context) ([]*RebalanceStrategy, error) {
+	return s.list(ctx, `SELECT data FROM rebalance_strategies WHERE is_active ORDER BY created_at DESC`)
+}
+
+func (s *postgresRebalanceStrategyStore) list(ctx context.Context, query string, args ...any) ([]*RebalanceStrategy, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var strategies []*RebalanceStrategy
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var strategy RebalanceStrategy
+		if err := json.Unmarshal(data, &strategy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rebalance strategy: %w", err)
+		}
+		strategies = append(strategies, &strategy)
+	}
+	return strategies, rows.Err()
+}
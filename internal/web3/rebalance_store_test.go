@@ -0,0 +1,170 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRebalanceStore keeps strategies in memory, as copies, like a database would
+type memoryRebalanceStore struct {
+	mu         sync.Mutex
+	strategies map[uuid.UUID]RebalanceStrategy
+	err        error
+}
+
+func newMemoryRebalanceStore() *memoryRebalanceStore {
+	return &memoryRebalanceStore{strategies: make(map[uuid.UUID]RebalanceStrategy)}
+}
+
+func (s *memoryRebalanceStore) Save(ctx context.Context, strategy *RebalanceStrategy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.strategies[strategy.ID] = *strategy
+	return nil
+}
+
+func (s *memoryRebalanceStore) ListByPortfolio(ctx context.Context, portfolioID uuid.UUID) ([]*RebalanceStrategy, error) {
+	return s.list(func(strategy RebalanceStrategy) bool { return strategy.PortfolioID == portfolioID })
+}
+
+func (s *memoryRebalanceStore) ListActive(ctx context.Context) ([]*RebalanceStrategy, error) {
+	return s.list(func(strategy RebalanceStrategy) bool { return strategy.IsActive })
+}
+
+func (s *memoryRebalanceStore) list(match func(RebalanceStrategy) bool) ([]*RebalanceStrategy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	var strategies []*RebalanceStrategy
+	for _, strategy := range s.strategies {
+		if match(strategy) {
+			strategy := strategy
+			strategies = append(strategies, &strategy)
+		}
+	}
+	sort.Slice(strategies, func(i, j int) bool { return strategies[i].CreatedAt.After(strategies[j].CreatedAt) })
+	return strategies, nil
+}
+
+func (s *memoryRebalanceStore) get(id uuid.UUID) RebalanceStrategy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.strategies[id]
+}
+
+func newStoredRebalancer(store RebalanceStrategyStore) (*PortfolioRebalancer, *TradingEngine) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	clients := make(map[int]*ethclient.Client)
+	engine := NewTradingEngine(clients, logger, NewRiskAssessmentService(clients, logger))
+	rebalancer := NewPortfolioRebalancer(logger, engine, NewDeFiProtocolManager(logger))
+	rebalancer.SetStrategyStore(store)
+	return rebalancer, engine
+}
+
+func TestRebalanceStrategyStore(t *testing.T) {
+	ctx := context.Background()
+	halfETH := map[string]decimal.Decimal{"ETH": decimal.NewFromFloat(0.5), "USDC": decimal.NewFromFloat(0.5)}
+
+	t.Run("PersistsAndRestores", func(t *testing.T) {
+		store := newMemoryRebalanceStore()
+		rebalancer, engine := newStoredRebalancer(store)
+		portfolio, err := engine.CreatePortfolio(ctx, uuid.New(), "Stored", decimal.NewFromInt(1000), RiskProfile{Level: "moderate"})
+		require.NoError(t, err)
+		portfolio.Holdings["ETH"] = &Holding{TokenSymbol: "ETH", Amount: decimal.NewFromInt(4), CurrentPrice: decimal.NewFromInt(1000)}
+
+		first, err := rebalancer.CreateRebalanceStrategy(ctx, portfolio.ID, "All ETH", RebalanceTypeFixed,
+			map[string]decimal.Decimal{"ETH": decimal.NewFromInt(1)})
+		require.NoError(t, err)
+		second, err := rebalancer.CreateRebalanceStrategy(ctx, portfolio.ID, "Half ETH", RebalanceTypeMomentum, halfETH)
+		require.NoError(t, err)
+		assert.False(t, store.get(first.ID).IsActive, "the replaced strategy is saved as retired")
+		assert.True(t, store.get(second.ID).IsActive)
+
+		schedule := &RebalanceSchedule{Frequency: ScheduleFrequencyDaily, TimeOfDay: "06:00"}
+		_, err = rebalancer.SetRebalanceSchedule(ctx, portfolio.ID, schedule)
+		require.NoError(t, err)
+		assert.Equal(t, schedule, store.get(second.ID).Schedule)
+
+		execution, err := rebalancer.RebalancePortfolio(ctx, portfolio.ID)
+		require.NoError(t, err)
+		require.Equal(t, RebalanceStatusCompleted, execution.Status)
+		assert.Equal(t, execution.CompletedAt, store.get(second.ID).LastRebalance)
+
+		// A new rebalancer on the same store picks the strategy up again
+		restarted, _ := newStoredRebalancer(store)
+		require.NoError(t, restarted.LoadStrategies(ctx))
+		details, err := restarted.GetRebalanceStrategy(ctx, portfolio.ID)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, details.ID)
+		assert.Equal(t, schedule, details.Schedule)
+		assert.Equal(t, execution.CompletedAt, details.LastRebalance)
+		if assert.Len(t, details.History, 1) {
+			assert.Equal(t, first.ID, details.History[0].ID)
+			assert.False(t, details.History[0].IsActive)
+		}
+
+		// Strategies are also found without loading them first
+		unloaded, _ := newStoredRebalancer(store)
+		details, err = unloaded.GetRebalanceStrategy(ctx, portfolio.ID)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, details.ID)
+		_, err = unloaded.SetRebalanceSchedule(ctx, portfolio.ID, nil)
+		assert.NoError(t, err, "a strategy found by Get is active in memory")
+
+		_, err = restarted.GetRebalanceStrategy(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrRebalanceStrategyNotFound)
+	})
+
+	t.Run("StoreFailures", func(t *testing.T) {
+		store := newMemoryRebalanceStore()
+		rebalancer, _ := newStoredRebalancer(store)
+		portfolioID := uuid.New()
+		strategy, err := rebalancer.CreateRebalanceStrategy(ctx, portfolioID, "Half ETH", RebalanceTypeFixed, halfETH)
+		require.NoError(t, err)
+
+		saveFailed := errors.New("database unavailable")
+		store.err = saveFailed
+
+		_, err = rebalancer.CreateRebalanceStrategy(ctx, portfolioID, "Replacement", RebalanceTypeFixed, halfETH)
+		assert.ErrorIs(t, err, saveFailed)
+		_, err = rebalancer.SetRebalanceSchedule(ctx, portfolioID, &RebalanceSchedule{Frequency: ScheduleFrequencyDrift})
+		assert.ErrorIs(t, err, saveFailed)
+		assert.Nil(t, strategy.Schedule, "a schedule that was not saved is not applied")
+
+		_, err = rebalancer.GetRebalanceStrategy(ctx, portfolioID)
+		assert.ErrorIs(t, err, saveFailed)
+		assert.NotErrorIs(t, err, ErrRebalanceStrategyNotFound)
+		assert.Error(t, rebalancer.LoadStrategies(ctx))
+
+		store.err = nil
+		details, err := rebalancer.GetRebalanceStrategy(ctx, portfolioID)
+		require.NoError(t, err)
+		assert.Equal(t, strategy.ID, details.ID, "the failed replacement never became active")
+		assert.Empty(t, details.History)
+	})
+
+	t.Run("InvalidStrategy", func(t *testing.T) {
+		store := newMemoryRebalanceStore()
+		rebalancer, _ := newStoredRebalancer(store)
+		_, err := rebalancer.CreateRebalanceStrategy(ctx, uuid.New(), "Short", RebalanceTypeFixed,
+			map[string]decimal.Decimal{"ETH": decimal.NewFromFloat(0.9)})
+		assert.ErrorIs(t, err, ErrInvalidRebalanceStrategy)
+		assert.Empty(t, store.strategies)
+	})
+}
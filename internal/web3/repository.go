@@ -19,8 +19,12 @@ type Pagination struct {
 type WalletListFilter struct {
 	ChainID   int  // 0 means all
 	IsPrimary *bool
+	WatchOnly *bool
+	Network   string // mainnet|testnet, empty means all
 	Page      int
 	PageSize  int
+	Limit     int // takes precedence over Page/PageSize when set
+	Offset    int
 }
 
 // TransactionListFilter defines filters for listing transactions
@@ -28,12 +32,20 @@ type TransactionListFilter struct {
 	WalletID   uuid.UUID
 	ChainID    int    // 0 means all
 	Status     string // optional: pending|confirmed|failed
+	Network    string // optional: mainnet|testnet
 	FromTime   *time.Time
 	ToTime     *time.Time
 	Page       int
 	PageSize   int
 }
 
+// DeFiPositionFilter defines filters for listing DeFi positions
+type DeFiPositionFilter struct {
+	Protocol      string // optional protocol name, case-insensitive
+	IncludeClosed bool
+	Network       string // mainnet|testnet; ListDeFiPositions defaults to mainnet
+}
+
 // WalletRepository abstracts wallet persistence
 // Small, focused interface to enable mocking and clean separation
 // of domain and data access.
@@ -46,6 +58,14 @@ type WalletRepository interface {
 	SetPrimary(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) error
 }
 
+// WatchOnlyRepository persists what was last observed on chain for watch-only wallets
+type WatchOnlyRepository interface {
+	ListWatchOnly(ctx context.Context) ([]*Wallet, error)
+	// GetSnapshot returns nil without error for wallets that were never polled
+	GetSnapshot(ctx context.Context, walletID uuid.UUID) (*WatchSnapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot *WatchSnapshot) error
+}
+
 // TransactionRepository abstracts transaction persistence
 type TransactionRepository interface {
 	Save(ctx context.Context, t *Transaction) error
@@ -53,5 +73,25 @@ type TransactionRepository interface {
 	ListByUser(ctx context.Context, userID uuid.UUID, filter TransactionListFilter) ([]*Transaction, Pagination, error)
 	ListByWallet(ctx context.Context, walletID uuid.UUID, filter TransactionListFilter) ([]*Transaction, Pagination, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	// MarkBroadcast records the hash of an externally signed transaction and marks it pending
+	MarkBroadcast(ctx context.Context, id uuid.UUID, txHash string) error
 }
 
+// DeFiPositionRepository abstracts persisted DeFi position reads
+type DeFiPositionRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, filter DeFiPositionFilter) ([]*DeFiPosition, error)
+}
+
+// AddressBookRepository abstracts address book persistence. Labels are unique per user and
+// chain, case-insensitively.
+type AddressBookRepository interface {
+	// Save inserts an entry, or returns ErrAddressBookLabelExists
+	Save(ctx context.Context, e *AddressBookEntry) error
+	ListByUser(ctx context.Context, userID uuid.UUID, chainID int) ([]*AddressBookEntry, error)
+	// GetByLabel and GetByAddress return ErrAddressBookEntryNotFound when nothing matches
+	GetByLabel(ctx context.Context, userID uuid.UUID, chainID int, label string) (*AddressBookEntry, error)
+	GetByAddress(ctx context.Context, userID uuid.UUID, chainID int, address string) (*AddressBookEntry, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// MarkVerified flags the user's entries for the address as verified
+	MarkVerified(ctx context.Context, userID uuid.UUID, chainID int, address string, at time.Time) error
+}
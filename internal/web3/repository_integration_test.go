@@ -2,6 +2,7 @@ package web3
 
 import (
 	"context"
+	"math/big"
 	"testing"
 	"time"
 
@@ -73,7 +74,12 @@ func TestRepositoryIntegration(t *testing.T) {
 		transaction_type VARCHAR(50),
 		metadata JSONB,
 		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		network VARCHAR(16) NOT NULL DEFAULT 'mainnet',
+		data TEXT NOT NULL DEFAULT '',
+		max_fee_per_gas NUMERIC(78, 0),
+		max_priority_fee_per_gas NUMERIC(78, 0),
+		fee_tier VARCHAR(16) NOT NULL DEFAULT ''
 	);`)
 	require.NoError(t, err)
 
@@ -110,9 +116,17 @@ func TestRepositoryIntegration(t *testing.T) {
 
 	// Transactions
 	txID := uuid.New()
-	tran := &Transaction{ID: txID, UserID: userID, WalletID: walletID, TxHash: "0xhash", ChainID: 1, FromAddress: "0xabc", ToAddress: "0xdef", Value: nil, Status: TxStatusPending, TransactionType: "transfer", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	tran := &Transaction{ID: txID, UserID: userID, WalletID: walletID, TxHash: "0xhash", ChainID: 1, FromAddress: "0xabc", ToAddress: "0xdef", Value: nil, Status: TxStatusPending, TransactionType: "transfer", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		MaxFeePerGas: big.NewInt(32_000_000_000), MaxPriorityFeePerGas: big.NewInt(2_000_000_000), FeeTier: FeeTierFast}
 	require.NoError(t, txRepo.Save(ctx, tran))
 
+	// Fees are kept with the transaction
+	savedTx, err := txRepo.GetByID(ctx, txID)
+	require.NoError(t, err)
+	require.Equal(t, "32000000000", savedTx.MaxFeePerGas.String())
+	require.Equal(t, "2000000000", savedTx.MaxPriorityFeePerGas.String())
+	require.Equal(t, FeeTierFast, savedTx.FeeTier)
+
 	// ListByUser
 	trs, pg2, err := txRepo.ListByUser(ctx, userID, TransactionListFilter{Page: 1, PageSize: 10})
 	require.NoError(t, err)
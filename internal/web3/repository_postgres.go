@@ -6,11 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
 	"github.com/ai-agentic-browser/pkg/database"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // postgresWalletRepository implements WalletRepository using Postgres
@@ -24,21 +26,21 @@ func NewPostgresWalletRepository(db *database.DB) WalletRepository {
 
 func (r *postgresWalletRepository) Save(ctx context.Context, w *Wallet) error {
 	query := `
-		INSERT INTO web3_wallets (id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO web3_wallets (id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at, watch_only, network)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (user_id, address, chain_id) DO UPDATE SET
 		  wallet_type = EXCLUDED.wallet_type,
 		  updated_at = EXCLUDED.updated_at
 	`
-	_, err := r.db.ExecWithMetrics(ctx, query, w.ID, w.UserID, w.Address, w.ChainID, w.WalletType, w.IsPrimary, w.CreatedAt, w.UpdatedAt)
+	_, err := r.db.ExecWithMetrics(ctx, query, w.ID, w.UserID, w.Address, w.ChainID, w.WalletType, w.IsPrimary, w.CreatedAt, w.UpdatedAt, w.WatchOnly, NetworkOf(w.ChainID))
 	return err
 }
 
 func (r *postgresWalletRepository) GetByID(ctx context.Context, id uuid.UUID) (*Wallet, error) {
-	query := `SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at FROM web3_wallets WHERE id = $1`
+	query := `SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at, watch_only, network FROM web3_wallets WHERE id = $1`
 	row := r.db.QueryRowContext(ctx, query, id)
 	w := &Wallet{}
-	if err := row.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt); err != nil {
+	if err := row.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt, &w.WatchOnly, &w.Network); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("wallet not found: %w", err)
 		}
@@ -48,10 +50,10 @@ func (r *postgresWalletRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 }
 
 func (r *postgresWalletRepository) GetByAddress(ctx context.Context, userID uuid.UUID, address string, chainID int) (*Wallet, error) {
-	query := `SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at FROM web3_wallets WHERE user_id = $1 AND address = $2 AND chain_id = $3`
+	query := `SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at, watch_only, network FROM web3_wallets WHERE user_id = $1 AND address = $2 AND chain_id = $3`
 	row := r.db.QueryRowContext(ctx, query, userID, strings.ToLower(address), chainID)
 	w := &Wallet{}
-	if err := row.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt); err != nil {
+	if err := row.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt, &w.WatchOnly, &w.Network); err != nil {
 		return nil, err
 	}
 	return w, nil
@@ -102,6 +104,16 @@ func (r *postgresWalletRepository) ListByUser(ctx context.Context, userID uuid.U
 		args = append(args, *filter.IsPrimary)
 		argPos++
 	}
+	if filter.WatchOnly != nil {
+		where = append(where, fmt.Sprintf("watch_only = $%d", argPos))
+		args = append(args, *filter.WatchOnly)
+		argPos++
+	}
+	if filter.Network != "" {
+		where = append(where, fmt.Sprintf("network = $%d", argPos))
+		args = append(args, filter.Network)
+		argPos++
+	}
 
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM web3_wallets WHERE %s", strings.Join(where, " AND "))
 	var total int
@@ -110,8 +122,11 @@ func (r *postgresWalletRepository) ListByUser(ctx context.Context, userID uuid.U
 	}
 
 	limit, offset := paginate(filter.Page, filter.PageSize)
+	if filter.Limit > 0 {
+		limit, offset = filter.Limit, filter.Offset
+	}
 	listQuery := fmt.Sprintf(`
-		SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at
+		SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at, watch_only, network
 		FROM web3_wallets
 		WHERE %s
 		ORDER BY created_at DESC
@@ -127,11 +142,14 @@ func (r *postgresWalletRepository) ListByUser(ctx context.Context, userID uuid.U
 	var result []*Wallet
 	for rows.Next() {
 		w := &Wallet{}
-		if err := rows.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		if err := rows.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt, &w.WatchOnly, &w.Network); err != nil {
 			return nil, Pagination{}, err
 		}
 		result = append(result, w)
 	}
+	if filter.Limit > 0 {
+		return result, buildPagination(total, offset/limit+1, limit), nil
+	}
 	return result, buildPagination(total, filter.Page, filter.PageSize), nil
 }
 
@@ -152,6 +170,68 @@ func (r *postgresWalletRepository) SetPrimary(ctx context.Context, userID uuid.U
 	return tx.Commit()
 }
 
+// postgresWatchOnlyRepository implements WatchOnlyRepository using Postgres
+type postgresWatchOnlyRepository struct {
+	db *database.DB
+}
+
+func NewPostgresWatchOnlyRepository(db *database.DB) WatchOnlyRepository {
+	return &postgresWatchOnlyRepository{db: db}
+}
+
+func (r *postgresWatchOnlyRepository) ListWatchOnly(ctx context.Context) ([]*Wallet, error) {
+	query := `SELECT id, user_id, address, chain_id, wallet_type, is_primary, created_at, updated_at, watch_only, network FROM web3_wallets WHERE watch_only`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*Wallet
+	for rows.Next() {
+		w := &Wallet{}
+		if err := rows.Scan(&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.WalletType, &w.IsPrimary, &w.CreatedAt, &w.UpdatedAt, &w.WatchOnly, &w.Network); err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+	return result, rows.Err()
+}
+
+func (r *postgresWatchOnlyRepository) GetSnapshot(ctx context.Context, walletID uuid.UUID) (*WatchSnapshot, error) {
+	query := `SELECT wallet_id, nonce, balances, checked_at FROM web3_watch_snapshots WHERE wallet_id = $1`
+	snapshot := &WatchSnapshot{}
+	var balances []byte
+	err := r.db.QueryRowContext(ctx, query, walletID).Scan(&snapshot.WalletID, &snapshot.Nonce, &balances, &snapshot.CheckedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(balances, &snapshot.Balances); err != nil {
+		return nil, fmt.Errorf("decode watch snapshot balances: %w", err)
+	}
+	return snapshot, nil
+}
+
+func (r *postgresWatchOnlyRepository) SaveSnapshot(ctx context.Context, snapshot *WatchSnapshot) error {
+	balances, err := json.Marshal(snapshot.Balances)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO web3_watch_snapshots (wallet_id, nonce, balances, checked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_id) DO UPDATE SET
+		  nonce = EXCLUDED.nonce,
+		  balances = EXCLUDED.balances,
+		  checked_at = EXCLUDED.checked_at
+	`
+	_, err = r.db.ExecWithMetrics(ctx, query, snapshot.WalletID, snapshot.Nonce, balances, snapshot.CheckedAt)
+	return err
+}
+
 // postgresTransactionRepository implements TransactionRepository using Postgres
 type postgresTransactionRepository struct {
 	db *database.DB
@@ -161,26 +241,26 @@ func NewPostgresTransactionRepository(db *database.DB) TransactionRepository {
 	return &postgresTransactionRepository{db: db}
 }
 
+const transactionColumns = `id, user_id, wallet_id, tx_hash, chain_id, from_address, to_address, value, gas_used, gas_price,
+	status, block_number, transaction_type, metadata, created_at, updated_at, network, data,
+	max_fee_per_gas, max_priority_fee_per_gas, fee_tier`
+
 func (r *postgresTransactionRepository) Save(ctx context.Context, t *Transaction) error {
 	metadataJSON, _ := jsonMarshalSafe(t.Metadata)
 	query := `
-		INSERT INTO web3_transactions (
-		  id, user_id, wallet_id, tx_hash, chain_id, from_address, to_address, value, gas_used, gas_price,
-		  status, block_number, transaction_type, metadata, created_at, updated_at
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		INSERT INTO web3_transactions (` + transactionColumns + `)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21)
 	`
 	_, err := r.db.ExecWithMetrics(ctx, query,
 		t.ID, t.UserID, t.WalletID, t.TxHash, t.ChainID, t.FromAddress, t.ToAddress, t.Value,
 		t.GasUsed, t.GasPrice, t.Status, t.BlockNumber, t.TransactionType, metadataJSON, t.CreatedAt, t.UpdatedAt,
+		NetworkOf(t.ChainID), t.Data, nullWei(t.MaxFeePerGas), nullWei(t.MaxPriorityFeePerGas), string(t.FeeTier),
 	)
 	return err
 }
 
 func (r *postgresTransactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*Transaction, error) {
-	query := `
-		SELECT id, user_id, wallet_id, tx_hash, chain_id, from_address, to_address, value, gas_used, gas_price,
-		       status, block_number, transaction_type, metadata, created_at, updated_at
-		FROM web3_transactions WHERE id = $1`
+	query := `SELECT ` + transactionColumns + ` FROM web3_transactions WHERE id = $1`
 	row := r.db.QueryRowContext(ctx, query, id)
 	return scanTransaction(row)
 }
@@ -224,6 +304,11 @@ func (r *postgresTransactionRepository) list(ctx context.Context, scope *listSco
 		args = append(args, filter.Status)
 		pos++
 	}
+	if filter.Network != "" {
+		where = append(where, fmt.Sprintf("network = $%d", pos))
+		args = append(args, filter.Network)
+		pos++
+	}
 	if filter.FromTime != nil {
 		where = append(where, fmt.Sprintf("created_at >= $%d", pos))
 		args = append(args, *filter.FromTime)
@@ -247,8 +332,7 @@ func (r *postgresTransactionRepository) list(ctx context.Context, scope *listSco
 
 	limit, offset := paginate(filter.Page, filter.PageSize)
 	listQuery := fmt.Sprintf(`
-		SELECT id, user_id, wallet_id, tx_hash, chain_id, from_address, to_address, value, gas_used, gas_price,
-		       status, block_number, transaction_type, metadata, created_at, updated_at
+		SELECT `+transactionColumns+`
 		FROM web3_transactions
 		WHERE %s
 		ORDER BY created_at DESC
@@ -277,12 +361,96 @@ func (r *postgresTransactionRepository) UpdateStatus(ctx context.Context, id uui
 	return err
 }
 
+func (r *postgresTransactionRepository) MarkBroadcast(ctx context.Context, id uuid.UUID, txHash string) error {
+	query := "UPDATE web3_transactions SET tx_hash = $1, status = $2, updated_at = $3 WHERE id = $4"
+	_, err := r.db.ExecWithMetrics(ctx, query, txHash, TxStatusPending, time.Now(), id)
+	return err
+}
+
+// postgresDeFiPositionRepository implements DeFiPositionRepository using Postgres
+type postgresDeFiPositionRepository struct {
+	db *database.DB
+}
+
+func NewPostgresDeFiPositionRepository(db *database.DB) DeFiPositionRepository {
+	return &postgresDeFiPositionRepository{db: db}
+}
+
+func (r *postgresDeFiPositionRepository) ListByUser(ctx context.Context, userID uuid.UUID, filter DeFiPositionFilter) ([]*DeFiPosition, error) {
+	where := []string{"user_id = $1"}
+	args := []any{userID}
+	if filter.Protocol != "" {
+		args = append(args, strings.ToLower(filter.Protocol))
+		where = append(where, fmt.Sprintf("LOWER(protocol_name) = $%d", len(args)))
+	}
+	if filter.Network != "" {
+		args = append(args, filter.Network)
+		where = append(where, fmt.Sprintf("network = $%d", len(args)))
+	}
+	if !filter.IncludeClosed {
+		where = append(where, "is_active = true")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, wallet_id, protocol_name, position_type, token_symbol, amount, usd_value, apy,
+		       is_active, created_at, updated_at, token_a, token_b, amount_a, amount_b, entry_amount_a,
+		       entry_amount_b, entry_price_a, entry_price_b, price_lower, price_upper, fees_earned, network
+		FROM defi_positions
+		WHERE %s
+		ORDER BY created_at DESC
+	`, strings.Join(where, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*DeFiPosition
+	for rows.Next() {
+		p := &DeFiPosition{}
+		var tokenSymbol, tokenA, tokenB sql.NullString
+		var amount, usdValue, apy decimal.NullDecimal
+		var amountA, amountB, entryAmountA, entryAmountB, entryPriceA, entryPriceB decimal.NullDecimal
+		var priceLower, priceUpper, feesEarned decimal.NullDecimal
+		if err := rows.Scan(&p.ID, &p.UserID, &p.WalletID, &p.ProtocolName, &p.PositionType, &tokenSymbol,
+			&amount, &usdValue, &apy, &p.IsActive, &p.CreatedAt, &p.UpdatedAt, &tokenA, &tokenB, &amountA, &amountB,
+			&entryAmountA, &entryAmountB, &entryPriceA, &entryPriceB, &priceLower, &priceUpper, &feesEarned, &p.Network); err != nil {
+			return nil, err
+		}
+		p.TokenSymbol = tokenSymbol.String
+		p.Amount = amount.Decimal
+		p.USDValue = usdValue.Decimal
+		p.APY = apy.Decimal
+		p.TokenA = tokenA.String
+		p.TokenB = tokenB.String
+		p.AmountA = amountA.Decimal
+		p.AmountB = amountB.Decimal
+		p.EntryAmountA = entryAmountA.Decimal
+		p.EntryAmountB = entryAmountB.Decimal
+		p.EntryPriceA = entryPriceA.Decimal
+		p.EntryPriceB = entryPriceB.Decimal
+		p.PriceLower = priceLower.Decimal
+		p.PriceUpper = priceUpper.Decimal
+		p.FeesEarned = feesEarned.Decimal
+		if p.IsActive {
+			p.Status = PositionStatusOpen
+		} else {
+			p.Status = PositionStatusClosed
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
 // Helpers
 func scanTransaction(scanner interface{ Scan(dest ...any) error }) (*Transaction, error) {
 	t := &Transaction{}
 	var metadataRaw []byte
+	var maxFee, maxPriorityFee sql.NullString
 	if err := scanner.Scan(&t.ID, &t.UserID, &t.WalletID, &t.TxHash, &t.ChainID, &t.FromAddress, &t.ToAddress, &t.Value,
-		&t.GasUsed, &t.GasPrice, &t.Status, &t.BlockNumber, &t.TransactionType, &metadataRaw, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		&t.GasUsed, &t.GasPrice, &t.Status, &t.BlockNumber, &t.TransactionType, &metadataRaw, &t.CreatedAt, &t.UpdatedAt, &t.Network, &t.Data,
+		&maxFee, &maxPriorityFee, &t.FeeTier); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("transaction not found: %w", err)
 		}
@@ -291,9 +459,31 @@ func scanTransaction(scanner interface{ Scan(dest ...any) error }) (*Transaction
 	if len(metadataRaw) > 0 {
 		_ = jsonUnmarshalSafe(metadataRaw, &t.Metadata)
 	}
+	t.MaxFeePerGas = parseWei(maxFee)
+	t.MaxPriorityFeePerGas = parseWei(maxPriorityFee)
 	return t, nil
 }
 
+// nullWei stores a wei amount as an exact decimal string, or NULL when it is unset
+func nullWei(v *big.Int) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: v.String(), Valid: true}
+}
+
+// parseWei reads a wei amount stored by nullWei, returning nil for NULL
+func parseWei(v sql.NullString) *big.Int {
+	if !v.Valid {
+		return nil
+	}
+	wei, ok := new(big.Int).SetString(v.String, 10)
+	if !ok {
+		return nil
+	}
+	return wei
+}
+
 func jsonMarshalSafe(v any) ([]byte, error) {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -308,3 +498,106 @@ func jsonUnmarshalSafe(b []byte, v any) error {
 	}
 	return json.Unmarshal(b, v)
 }
+
+// postgresAddressBookRepository implements AddressBookRepository using Postgres
+type postgresAddressBookRepository struct {
+	db *database.DB
+}
+
+func NewPostgresAddressBookRepository(db *database.DB) AddressBookRepository {
+	return &postgresAddressBookRepository{db: db}
+}
+
+const addressBookColumns = `id, user_id, label, address, chain_id, verified, verified_at, created_at, updated_at`
+
+func (r *postgresAddressBookRepository) Save(ctx context.Context, e *AddressBookEntry) error {
+	query := `
+		INSERT INTO web3_address_book (` + addressBookColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT DO NOTHING
+	`
+	result, err := r.db.ExecWithMetrics(ctx, query, e.ID, e.UserID, e.Label, e.Address, e.ChainID, e.Verified, e.VerifiedAt, e.CreatedAt, e.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrAddressBookLabelExists
+	}
+	return nil
+}
+
+func (r *postgresAddressBookRepository) ListByUser(ctx context.Context, userID uuid.UUID, chainID int) ([]*AddressBookEntry, error) {
+	query := `SELECT ` + addressBookColumns + ` FROM web3_address_book WHERE user_id = $1`
+	args := []any{userID}
+	if chainID != 0 {
+		query += ` AND chain_id = $2`
+		args = append(args, chainID)
+	}
+	query += ` ORDER BY LOWER(label), chain_id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AddressBookEntry
+	for rows.Next() {
+		e, err := scanAddressBookEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *postgresAddressBookRepository) GetByLabel(ctx context.Context, userID uuid.UUID, chainID int, label string) (*AddressBookEntry, error) {
+	query := `SELECT ` + addressBookColumns + ` FROM web3_address_book WHERE user_id = $1 AND chain_id = $2 AND LOWER(label) = LOWER($3)`
+	return r.getOne(ctx, query, userID, chainID, label)
+}
+
+func (r *postgresAddressBookRepository) GetByAddress(ctx context.Context, userID uuid.UUID, chainID int, address string) (*AddressBookEntry, error) {
+	query := `SELECT ` + addressBookColumns + ` FROM web3_address_book WHERE user_id = $1 AND chain_id = $2 AND address = $3 ORDER BY created_at LIMIT 1`
+	return r.getOne(ctx, query, userID, chainID, strings.ToLower(address))
+}
+
+func (r *postgresAddressBookRepository) getOne(ctx context.Context, query string, args ...any) (*AddressBookEntry, error) {
+	e, err := scanAddressBookEntry(r.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAddressBookEntryNotFound
+	}
+	return e, err
+}
+
+func (r *postgresAddressBookRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecWithMetrics(ctx, `DELETE FROM web3_address_book WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrAddressBookEntryNotFound
+	}
+	return nil
+}
+
+func (r *postgresAddressBookRepository) MarkVerified(ctx context.Context, userID uuid.UUID, chainID int, address string, at time.Time) error {
+	query := `
+		UPDATE web3_address_book SET verified = true, verified_at = $4, updated_at = $4
+		WHERE user_id = $1 AND chain_id = $2 AND address = $3 AND NOT verified
+	`
+	_, err := r.db.ExecWithMetrics(ctx, query, userID, chainID, strings.ToLower(address), at)
+	return err
+}
+
+func scanAddressBookEntry(row interface{ Scan(dest ...any) error }) (*AddressBookEntry, error) {
+	e := &AddressBookEntry{}
+	var verifiedAt sql.NullTime
+	if err := row.Scan(&e.ID, &e.UserID, &e.Label, &e.Address, &e.ChainID, &e.Verified, &verifiedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if verifiedAt.Valid {
+		e.VerifiedAt = &verifiedAt.Time
+	}
+	return e, nil
+}
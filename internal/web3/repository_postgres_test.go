@@ -0,0 +1,22 @@
+package web3
+
+import (
+	"database/sql"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeiColumns(t *testing.T) {
+	// Amounts beyond int64 are stored exactly
+	wei, ok := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	assert.True(t, ok)
+	stored := nullWei(wei)
+	assert.True(t, stored.Valid)
+	assert.Equal(t, 0, wei.Cmp(parseWei(stored)))
+
+	assert.False(t, nullWei(nil).Valid, "unset fees are stored as NULL")
+	assert.Nil(t, parseWei(sql.NullString{}))
+	assert.Nil(t, parseWei(sql.NullString{String: "1.5", Valid: true}))
+}
@@ -0,0 +1,107 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RiskLevels are the levels of a portfolio risk profile
+var RiskLevels = []string{"conservative", "moderate", "aggressive"}
+
+// Gas limits a transaction may set; 21000 is the cost of a plain transfer
+const (
+	minGasLimit = 21000
+	maxGasLimit = 30_000_000
+)
+
+var (
+	hexDataPattern = regexp.MustCompile(`^0x([0-9a-fA-F]{2})*$`)
+	hundred        = decimal.NewFromInt(100)
+)
+
+// CreatePortfolioRequest is the body of a portfolio creation request. InitialBalance is a
+// decimal string such as "10000.50".
+type CreatePortfolioRequest struct {
+	Name           string      `json:"name"`
+	InitialBalance string      `json:"initial_balance"`
+	RiskProfile    RiskProfile `json:"risk_profile"`
+}
+
+// Validate checks the request and returns its initial balance, or validation.FieldErrors
+// listing every invalid field
+func (r *CreatePortfolioRequest) Validate() (decimal.Decimal, error) {
+	var v validation.Validator
+	v.Required("name", r.Name)
+	initialBalance := v.Decimal("initial_balance", r.InitialBalance)
+	if r.InitialBalance != "" {
+		v.Positive("initial_balance", initialBalance)
+	}
+	v.Merge("risk_profile.", r.RiskProfile.Validate())
+	return initialBalance, v.Err()
+}
+
+// Validate checks that the level is known and the percentages lie between 0 and 100
+func (p RiskProfile) Validate() error {
+	var v validation.Validator
+	v.OneOf("level", p.Level, RiskLevels...)
+	percentages := []struct {
+		field string
+		value decimal.Decimal
+	}{
+		{"max_position_size", p.MaxPositionSize},
+		{"max_daily_loss", p.MaxDailyLoss},
+		{"stop_loss_percentage", p.StopLossPercentage},
+		{"take_profit_percentage", p.TakeProfitPercentage},
+	}
+	for _, percentage := range percentages {
+		if percentage.value.IsNegative() || percentage.value.GreaterThan(hundred) {
+			v.Fail(percentage.field, validation.ConstraintRange, "must be between 0 and 100")
+		}
+	}
+	return v.Err()
+}
+
+// Validate checks the fields a client sets on a transaction request and returns
+// validation.FieldErrors listing every invalid one
+func (r *TransactionRequest) Validate() error {
+	var v validation.Validator
+	if r.WalletID == uuid.Nil {
+		v.Fail("wallet_id", validation.ConstraintRequired, "is required")
+	}
+	if r.To == "" && r.ToAddress == "" {
+		v.Fail("to_address", validation.ConstraintRequired, "to_address or to is required")
+	}
+	amounts := []struct {
+		field string
+		value *big.Int
+	}{
+		{"value", r.Value},
+		{"gas_price", r.GasPrice},
+		{"max_fee_per_gas", r.MaxFeePerGas},
+		{"max_priority_fee_per_gas", r.MaxPriorityFeePerGas},
+	}
+	for _, amount := range amounts {
+		if amount.value != nil && amount.value.Sign() < 0 {
+			v.Fail(amount.field, validation.ConstraintRange, "must not be negative")
+		}
+	}
+	if r.Data != "" && !hexDataPattern.MatchString(r.Data) {
+		v.Fail("data", validation.ConstraintFormat, "must be 0x-prefixed hex bytes")
+	}
+	if r.GasLimit != 0 && (r.GasLimit < minGasLimit || r.GasLimit > maxGasLimit) {
+		v.Fail("gas_limit", validation.ConstraintRange, fmt.Sprintf("must be between %d and %d", minGasLimit, maxGasLimit))
+	}
+	if r.MaxFeePerGas != nil && r.MaxPriorityFeePerGas != nil && r.MaxPriorityFeePerGas.Cmp(r.MaxFeePerGas) > 0 {
+		v.Fail("max_priority_fee_per_gas", validation.ConstraintRange, "must not exceed max_fee_per_gas")
+	}
+	v.OneOf("fee_tier", string(r.FeeTier), string(FeeTierSlow), string(FeeTierStandard), string(FeeTierFast))
+	if r.ChainID < 0 {
+		v.Fail("chain_id", validation.ConstraintRange, "must not be negative")
+	}
+	return v.Err()
+}
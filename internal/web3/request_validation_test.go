@@ -0,0 +1,61 @@
+package web3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	var fieldErrors validation.FieldErrors
+	require.ErrorAs(t, err, &fieldErrors)
+	fields := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		fields[i] = fieldError.Field
+	}
+	return fields
+}
+
+func TestTransactionRequestValidate(t *testing.T) {
+	valid := TransactionRequest{
+		WalletID:  uuid.New(),
+		ToAddress: "0x742d35Cc6634C0532925a3b844Bc454e4438f44e",
+		Value:     big.NewInt(1e15),
+		Data:      "0xa9059cbb",
+		GasLimit:  65000,
+		FeeTier:   FeeTierFast,
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := TransactionRequest{
+		Value:                big.NewInt(-1),
+		Data:                 "transfer",
+		GasLimit:             100,
+		MaxFeePerGas:         big.NewInt(10),
+		MaxPriorityFeePerGas: big.NewInt(20),
+		FeeTier:              "turbo",
+	}
+	assert.Equal(t, []string{"wallet_id", "to_address", "value", "data", "gas_limit", "max_priority_fee_per_gas", "fee_tier"}, invalidFields(t, invalid.Validate()))
+}
+
+func TestCreatePortfolioRequestValidate(t *testing.T) {
+	request := CreatePortfolioRequest{Name: "Core", InitialBalance: "10000.50", RiskProfile: RiskProfile{Level: "moderate", MaxPositionSize: decimal.NewFromInt(10)}}
+	balance, err := request.Validate()
+	require.NoError(t, err)
+	assert.True(t, balance.Equal(decimal.RequireFromString("10000.50")))
+
+	request = CreatePortfolioRequest{InitialBalance: "ten thousand", RiskProfile: RiskProfile{Level: "yolo", StopLossPercentage: decimal.NewFromInt(150)}}
+	_, err = request.Validate()
+	assert.ErrorIs(t, err, validation.ErrInvalidRequest)
+	assert.Equal(t, []string{"name", "initial_balance", "risk_profile.level", "risk_profile.stop_loss_percentage"}, invalidFields(t, err))
+
+	request = CreatePortfolioRequest{Name: "Core", InitialBalance: "-5"}
+	_, err = request.Validate()
+	assert.Equal(t, []string{"initial_balance"}, invalidFields(t, err))
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRiskAssessmentService(t *testing.T) {
@@ -425,3 +427,81 @@ func TestCacheManagement(t *testing.T) {
 		assert.Nil(t, cached, "Expired cache entry should return nil")
 	})
 }
+
+func TestRecommendPositionSize(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	service := NewRiskAssessmentService(nil, logger)
+	ctx := context.Background()
+
+	t.Run("CappedByMaxPositionSize", func(t *testing.T) {
+		sizing, err := service.RecommendPositionSize(ctx, PositionSizeRequest{
+			Asset:           "BTC",
+			Price:           decimal.NewFromInt(50000),
+			PortfolioValue:  decimal.NewFromInt(100000),
+			MaxPositionSize: decimal.NewFromInt(1000),
+			MaxRiskExposure: 0.05,
+			Volatility:      0.3,
+			RiskTolerance:   0.6,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, PositionLimitMaxPosition, sizing.LimitedBy)
+		assert.True(t, decimal.NewFromFloat(0.02).Equal(sizing.Quantity), sizing.Quantity.String())
+		assert.True(t, decimal.NewFromInt(1000).Equal(sizing.Notional), sizing.Notional.String())
+		assert.Equal(t, 1.0, sizing.PortfolioPercent)
+		assert.InDelta(t, 0.02, sizing.RiskPerTrade, 1e-9)
+		assert.InDelta(t, 0.0314, sizing.StopDistance, 0.0001)
+		assert.Equal(t, "BTC", sizing.Inputs.Asset)
+	})
+
+	t.Run("RiskExposureLimitsRiskBudget", func(t *testing.T) {
+		sizing, err := service.RecommendPositionSize(ctx, PositionSizeRequest{
+			Asset:           "ETH",
+			Price:           decimal.NewFromInt(50000),
+			PortfolioValue:  decimal.NewFromInt(100000),
+			MaxRiskExposure: 0.005,
+			RiskTolerance:   0.2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, PositionLimitMaxRiskExposure, sizing.LimitedBy)
+		// Without a volatility estimate the default applies
+		assert.InDelta(t, 5970.3, sizing.Notional.InexactFloat64(), 1)
+		assert.InDelta(t, 500, sizing.RiskAmount.InexactFloat64(), 1)
+	})
+
+	t.Run("AdjustmentAndCash", func(t *testing.T) {
+		request := PositionSizeRequest{
+			Asset:          "BTC",
+			Price:          decimal.NewFromInt(50000),
+			PortfolioValue: decimal.NewFromInt(100000),
+			Volatility:     0.3,
+			RiskTolerance:  1,
+		}
+		full, err := service.RecommendPositionSize(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, PositionLimitConcentration, full.LimitedBy)
+		assert.Equal(t, 25.0, full.PortfolioPercent)
+
+		request.SizeAdjustment = 0.25
+		adjusted, err := service.RecommendPositionSize(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, PositionLimitRiskBudget, adjusted.LimitedBy)
+		assert.True(t, adjusted.Notional.LessThan(full.Notional))
+
+		request.AvailableCash = decimal.NewFromInt(2000)
+		capped, err := service.RecommendPositionSize(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, PositionLimitAvailableCash, capped.LimitedBy)
+		assert.True(t, decimal.NewFromInt(2000).Equal(capped.Notional), capped.Notional.String())
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		_, err := service.RecommendPositionSize(ctx, PositionSizeRequest{Asset: "BTC", PortfolioValue: decimal.NewFromInt(1000)})
+		assert.ErrorIs(t, err, ErrInvalidPositionSizeRequest)
+		_, err = service.RecommendPositionSize(ctx, PositionSizeRequest{Asset: "BTC", Price: decimal.NewFromInt(1)})
+		assert.ErrorIs(t, err, ErrInvalidPositionSizeRequest)
+		_, err = service.RecommendPositionSize(ctx, PositionSizeRequest{
+			Asset: "BTC", Price: decimal.NewFromInt(1), PortfolioValue: decimal.NewFromInt(1000), Volatility: -1,
+		})
+		assert.ErrorIs(t, err, ErrInvalidPositionSizeRequest)
+	})
+}
@@ -0,0 +1,234 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	safeDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash     = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+// SafeInfo is the state of a Safe contract that proposals are made against
+type SafeInfo struct {
+	Address   string   `json:"address"`
+	Nonce     uint64   `json:"nonce"`
+	Threshold int      `json:"threshold"`
+	Owners    []string `json:"owners"`
+}
+
+// SafeTransactionData is a Safe call. Proposals never refund gas, so the gas fields of the
+// Safe transaction are always zero and the executor pays.
+type SafeTransactionData struct {
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+	Nonce uint64
+}
+
+// SafeTransactionHash returns the EIP-712 hash Safe owners sign to approve the transaction
+func SafeTransactionHash(chainID int, safe common.Address, tx SafeTransactionData) common.Hash {
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	domain := crypto.Keccak256Hash(
+		safeDomainTypeHash.Bytes(),
+		math.U256Bytes(big.NewInt(int64(chainID))),
+		common.LeftPadBytes(safe.Bytes(), 32),
+	)
+	zero := make([]byte, 32)
+	structHash := crypto.Keccak256Hash(
+		safeTxTypeHash.Bytes(),
+		common.LeftPadBytes(tx.To.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(value)),
+		crypto.Keccak256(tx.Data),
+		zero, // operation: call
+		zero, // safeTxGas
+		zero, // baseGas
+		zero, // gasPrice
+		zero, // gasToken
+		zero, // refundReceiver
+		math.U256Bytes(new(big.Int).SetUint64(tx.Nonce)),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.Bytes(), structHash.Bytes())
+}
+
+// RecoverSafeSigner returns the owner that signed a Safe transaction hash. Safe accepts
+// signatures of the hash itself (v 27/28) and eth_sign signatures of it (v 31/32).
+func RecoverSafeSigner(safeTxHash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes", crypto.SignatureLength)
+	}
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+
+	digest := safeTxHash.Bytes()
+	switch v := sig[64]; {
+	case v == 27 || v == 28:
+		sig[64] = v - 27
+	case v == 31 || v == 32:
+		digest = accounts.TextHash(safeTxHash.Bytes())
+		sig[64] = v - 31
+	default:
+		return common.Address{}, fmt.Errorf("unsupported signature type v=%d", v)
+	}
+
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// SafeTransactionService collects Safe owners' signatures off-chain until a transaction can
+// be executed
+type SafeTransactionService interface {
+	GetSafe(ctx context.Context, chainID int, address string) (*SafeInfo, error)
+	// ProposeTransaction registers the transaction with the signature of the owner proposing it
+	ProposeTransaction(ctx context.Context, chainID int, safe string, tx SafeTransactionData, safeTxHash, sender, signature string) error
+	// ConfirmTransaction adds another owner's signature to a registered transaction
+	ConfirmTransaction(ctx context.Context, chainID int, safeTxHash, signature string) error
+}
+
+// safeTransactionServiceURLs are the hosted transaction services of the chains Safe supports
+var safeTransactionServiceURLs = map[int]string{
+	1:        "https://safe-transaction-mainnet.safe.global",
+	10:       "https://safe-transaction-optimism.safe.global",
+	56:       "https://safe-transaction-bsc.safe.global",
+	100:      "https://safe-transaction-gnosis-chain.safe.global",
+	137:      "https://safe-transaction-polygon.safe.global",
+	8453:     "https://safe-transaction-base.safe.global",
+	42161:    "https://safe-transaction-arbitrum.safe.global",
+	11155111: "https://safe-transaction-sepolia.safe.global",
+}
+
+// HTTPSafeTransactionService talks to Safe's hosted transaction service
+type HTTPSafeTransactionService struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURLs   map[int]string // replaced in tests
+}
+
+// NewHTTPSafeTransactionService creates a client for the hosted transaction services; the API
+// key is optional and raises the rate limit
+func NewHTTPSafeTransactionService(apiKey string) *HTTPSafeTransactionService {
+	return &HTTPSafeTransactionService{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		apiKey:     apiKey,
+		baseURLs:   safeTransactionServiceURLs,
+	}
+}
+
+func (s *HTTPSafeTransactionService) GetSafe(ctx context.Context, chainID int, address string) (*SafeInfo, error) {
+	var body struct {
+		Address   string      `json:"address"`
+		Nonce     json.Number `json:"nonce"`
+		Threshold int         `json:"threshold"`
+		Owners    []string    `json:"owners"`
+	}
+	path := "/api/v1/safes/" + common.HexToAddress(address).Hex() + "/"
+	if err := s.do(ctx, chainID, http.MethodGet, path, nil, &body); err != nil {
+		return nil, err
+	}
+	nonce, err := body.Nonce.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid safe nonce %q: %w", body.Nonce, err)
+	}
+	return &SafeInfo{Address: body.Address, Nonce: uint64(nonce), Threshold: body.Threshold, Owners: body.Owners}, nil
+}
+
+func (s *HTTPSafeTransactionService) ProposeTransaction(ctx context.Context, chainID int, safe string, tx SafeTransactionData, safeTxHash, sender, signature string) error {
+	value := "0"
+	if tx.Value != nil {
+		value = tx.Value.String()
+	}
+	var data *string
+	if len(tx.Data) > 0 {
+		encoded := hexutil.Encode(tx.Data)
+		data = &encoded
+	}
+	zeroAddress := common.Address{}.Hex()
+	payload := map[string]interface{}{
+		"to":                      tx.To.Hex(),
+		"value":                   value,
+		"data":                    data,
+		"operation":               0,
+		"safeTxGas":               "0",
+		"baseGas":                 "0",
+		"gasPrice":                "0",
+		"gasToken":                zeroAddress,
+		"refundReceiver":          zeroAddress,
+		"nonce":                   tx.Nonce,
+		"contractTransactionHash": safeTxHash,
+		"sender":                  common.HexToAddress(sender).Hex(),
+		"signature":               signature,
+		"origin":                  "ai-agentic-browser",
+	}
+	path := "/api/v1/safes/" + common.HexToAddress(safe).Hex() + "/multisig-transactions/"
+	return s.do(ctx, chainID, http.MethodPost, path, payload, nil)
+}
+
+func (s *HTTPSafeTransactionService) ConfirmTransaction(ctx context.Context, chainID int, safeTxHash, signature string) error {
+	path := "/api/v1/multisig-transactions/" + safeTxHash + "/confirmations/"
+	return s.do(ctx, chainID, http.MethodPost, path, map[string]string{"signature": signature}, nil)
+}
+
+func (s *HTTPSafeTransactionService) do(ctx context.Context, chainID int, method, path string, payload, out interface{}) error {
+	baseURL, ok := s.baseURLs[chainID]
+	if !ok {
+		return fmt.Errorf("safe transaction service does not support chain %d", chainID)
+	}
+
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("safe transaction service error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid safe transaction service response: %w", err)
+	}
+	return nil
+}
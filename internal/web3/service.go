@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/database"
 	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -23,6 +26,32 @@ type Service struct {
 	providers  map[int]*ChainProvider
 	walletRepo WalletRepository
 	txRepo     TransactionRepository
+
+	positionRepo DeFiPositionRepository
+	defiManager  *DeFiProtocolManager
+	priceSource  PriceSource
+
+	nftIndexer     NFTIndexer
+	nftFloorPrices NFTFloorPriceSource
+
+	addressBook AddressBookRepository
+	ens         ENSNameResolver
+	ensMu       sync.Mutex
+
+	// Reads balances for aggregation; nil uses the cached RPC helpers
+	balances chainBalanceReader
+
+	// Screens recipients before transactions are created; nil disables screening
+	screener *TransactionScreener
+
+	// Hands transactions to the user's wallet for signing; nil simulates the broadcast
+	signingQueue *SigningQueue
+
+	// Turns transactions of multisig wallets into proposals; nil disables multisig
+	multisig *MultisigCoordinator
+
+	// Describes the tokens in balance, transaction and DeFi responses
+	tokens *TokenRegistry
 }
 
 // ChainProvider represents a blockchain provider
@@ -36,32 +65,74 @@ type ChainProvider struct {
 func NewService(db *database.DB, redis *database.RedisClient, cfg config.Web3Config, logger *observability.Logger) *Service {
 	providers := make(map[int]*ChainProvider)
 
-	// Initialize providers for supported chains
-	if cfg.EthereumRPC != "" {
-		providers[1] = &ChainProvider{ChainID: 1, RpcURL: cfg.EthereumRPC}
-	}
-	if cfg.PolygonRPC != "" {
-		providers[137] = &ChainProvider{ChainID: 137, RpcURL: cfg.PolygonRPC}
-	}
-	if cfg.ArbitrumRPC != "" {
-		providers[42161] = &ChainProvider{ChainID: 42161, RpcURL: cfg.ArbitrumRPC}
-	}
-	if cfg.OptimismRPC != "" {
-		providers[10] = &ChainProvider{ChainID: 10, RpcURL: cfg.OptimismRPC}
+	// Initialize providers for supported chains and the testnets with an RPC configured
+	rpcs := map[int]string{
+		1:     cfg.EthereumRPC,
+		137:   cfg.PolygonRPC,
+		56:    cfg.BSCMainnetRPC,
+		42161: cfg.ArbitrumRPC,
+		10:    cfg.OptimismRPC,
+		8453:  cfg.BaseRPC,
+
+		Testnets[1].ChainID:     cfg.SepoliaRPC,
+		Testnets[137].ChainID:   cfg.PolygonAmoyRPC,
+		Testnets[56].ChainID:    cfg.BSCTestnetRPC,
+		Testnets[42161].ChainID: cfg.ArbitrumSepoliaRPC,
+		Testnets[10].ChainID:    cfg.OptimismSepoliaRPC,
+		Testnets[8453].ChainID:  cfg.BaseSepoliaRPC,
+	}
+	for chainID, rpcURL := range rpcs {
+		if rpcURL != "" {
+			providers[chainID] = &ChainProvider{ChainID: chainID, RpcURL: rpcURL}
+		}
 	}
 
 	walletRepo := NewPostgresWalletRepository(db)
 	txRepo := NewPostgresTransactionRepository(db)
 
-	return &Service{
-		db:         db,
-		redis:      redis,
-		config:     cfg,
-		logger:     logger,
-		providers:  providers,
-		walletRepo: walletRepo,
-		txRepo:     txRepo,
+	s := &Service{
+		db:           db,
+		redis:        redis,
+		config:       cfg,
+		logger:       logger,
+		providers:    providers,
+		walletRepo:   walletRepo,
+		txRepo:       txRepo,
+		positionRepo: NewPostgresDeFiPositionRepository(db),
+		priceSource:  NewCoinGeckoClient(redis),
+		addressBook:  NewPostgresAddressBookRepository(db),
+	}
+	s.tokens = NewTokenRegistry(s)
+	return s
+}
+
+// SetDeFiManager attaches the DeFi protocol manager used for live position data
+func (s *Service) SetDeFiManager(manager *DeFiProtocolManager) {
+	s.defiManager = manager
+}
+
+// SetTransactionScreener screens the recipients of transactions before they are created
+func (s *Service) SetTransactionScreener(screener *TransactionScreener) {
+	s.screener = screener
+}
+
+// SetSigningQueue sends created transactions to the user's wallet to sign instead of
+// simulating their broadcast
+func (s *Service) SetSigningQueue(queue *SigningQueue) {
+	s.signingQueue = queue
+}
+
+// SetMultisigCoordinator makes transactions of multisig wallets wait for their members' approvals
+func (s *Service) SetMultisigCoordinator(coordinator *MultisigCoordinator) {
+	s.multisig = coordinator
+}
+
+// prices returns the price source shared by all valuation paths
+func (s *Service) prices() PriceSource {
+	if s.priceSource == nil {
+		s.priceSource = NewCoinGeckoClient(s.redis)
 	}
+	return s.priceSource
 }
 
 // ConnectWallet connects a cryptocurrency wallet
@@ -73,12 +144,16 @@ func (s *Service) ConnectWallet(ctx context.Context, userID uuid.UUID, req Walle
 	if req.Address == "" || len(req.Address) < 4 {
 		return nil, fmt.Errorf("invalid address")
 	}
-	if _, exists := SupportedChains[req.ChainID]; !exists {
-		return nil, fmt.Errorf("unsupported chain ID: %d", req.ChainID)
+	if req.WatchOnly && !common.IsHexAddress(req.Address) {
+		return nil, fmt.Errorf("invalid address")
+	}
+	chainID, err := s.resolveChain(req.ChainID, req.Network)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if wallet already exists
-	existingWallet, err := s.walletRepo.GetByAddress(ctx, userID, req.Address, req.ChainID)
+	existingWallet, err := s.walletRepo.GetByAddress(ctx, userID, req.Address, chainID)
 	if err == nil && existingWallet != nil {
 		return &WalletConnectResponse{Wallet: existingWallet, Message: "Wallet already connected"}, nil
 	}
@@ -88,18 +163,23 @@ func (s *Service) ConnectWallet(ctx context.Context, userID uuid.UUID, req Walle
 		ID:         uuid.New(),
 		UserID:     userID,
 		Address:    strings.ToLower(req.Address),
-		ChainID:    req.ChainID,
+		ChainID:    chainID,
 		WalletType: req.WalletType,
 		IsPrimary:  false,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
+		WatchOnly:  req.WatchOnly,
+		Network:    NetworkOf(chainID),
+	}
+	if wallet.WatchOnly && wallet.WalletType == "" {
+		wallet.WalletType = WalletTypeWatchOnly
 	}
 
-	// Check if this is the user's first wallet
+	// Check if this is the user's first wallet; a watch-only wallet can't be the primary one
 	walletCount, err := s.walletRepo.CountByUser(ctx, userID)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to get user wallet count", err)
-	} else if walletCount == 0 {
+	} else if walletCount == 0 && !wallet.WatchOnly {
 		wallet.IsPrimary = true
 	}
 
@@ -113,13 +193,30 @@ func (s *Service) ConnectWallet(ctx context.Context, userID uuid.UUID, req Walle
 		"wallet_id":   wallet.ID.String(),
 		"user_id":     userID.String(),
 		"address":     wallet.Address,
-		"chain_id":    req.ChainID,
+		"chain_id":    chainID,
+		"network":     wallet.Network,
 		"wallet_type": req.WalletType,
+		"watch_only":  wallet.WatchOnly,
 	})
 
 	return &WalletConnectResponse{Wallet: wallet, Message: "Wallet connected successfully"}, nil
 }
 
+// signingWallet returns the user's wallet that is about to sign, rejecting watch-only wallets
+func (s *Service) signingWallet(ctx context.Context, userID, walletID uuid.UUID) (*Wallet, error) {
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet not found: %w", err)
+	}
+	if wallet.UserID != userID {
+		return nil, fmt.Errorf("wallet does not belong to user")
+	}
+	if wallet.WatchOnly {
+		return nil, fmt.Errorf("%w: %s", ErrWatchOnlyWallet, wallet.Address)
+	}
+	return wallet, nil
+}
+
 // GetBalance retrieves wallet balance information
 func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID, req BalanceRequest) (*BalanceResponse, error) {
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("web3-service").Start(ctx, "web3.GetBalance")
@@ -137,19 +234,26 @@ func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID, req BalanceR
 		if wallet.UserID != userID {
 			return nil, fmt.Errorf("wallet does not belong to user")
 		}
+		if err := checkWalletNetwork(wallet, req.Network); err != nil {
+			return nil, err
+		}
 		address = wallet.Address
 		chainID = wallet.ChainID
 	} else if req.Address != "" && req.ChainID != 0 {
+		resolved, err := s.resolveChain(req.ChainID, req.Network)
+		if err != nil {
+			return nil, err
+		}
 		address = req.Address
-		chainID = req.ChainID
+		chainID = resolved
 	} else {
 		return nil, fmt.Errorf("either wallet_id or address+chain_id must be provided")
 	}
 
 	// Get provider for chain
-	provider, exists := s.providers[chainID]
-	if !exists {
-		return nil, fmt.Errorf("no provider configured for chain ID: %d", chainID)
+	provider, err := s.provider(chainID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch native balance
@@ -159,44 +263,41 @@ func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID, req BalanceR
 		nativeBalance = big.NewInt(0)
 	}
 
-	// Fetch common ERC-20 token balances with caching
+	// Fetch common ERC-20 token balances with caching; their metadata comes from the token
+	// registry so amounts use each token's own decimals
 	var tokenBalances []TokenBalance
-	if tokens, ok := CommonERC20Tokens[chainID]; ok {
-		for _, t := range tokens {
-			decimals, derr := s.getERC20Decimals(ctx, chainID, t.Address)
-			if derr != nil {
-				s.logger.Warn(ctx, "Failed to read token decimals", map[string]any{"error": derr.Error(), "token": t.Symbol, "address": t.Address, "chain_id": chainID})
-				continue
-			}
-			bal, berr := s.getERC20Balance(ctx, chainID, t.Address, address)
-			if berr != nil {
-				s.logger.Warn(ctx, "Failed to read token balance", map[string]any{"error": berr.Error(), "token": t.Symbol, "address": t.Address, "chain_id": chainID})
-				continue
-			}
-			tokenBalances = append(tokenBalances, TokenBalance{
-				TokenAddress: t.Address,
-				TokenSymbol:  t.Symbol,
-				TokenName:    t.Name,
-				Balance:      bal,
-				Decimals:     decimals,
-				USDValue:     0, // priced elsewhere
-			})
+	for _, t := range CommonERC20Tokens[chainID] {
+		token, terr := s.Tokens().Resolve(ctx, chainID, t.Address)
+		if terr != nil {
+			s.logger.Warn(ctx, "Failed to resolve token", map[string]any{"error": terr.Error(), "token": t.Symbol, "address": t.Address, "chain_id": chainID})
+			continue
 		}
-	}
+		bal, berr := s.getERC20Balance(ctx, chainID, t.Address, address)
+		if berr != nil {
+			s.logger.Warn(ctx, "Failed to read token balance", map[string]any{"error": berr.Error(), "token": t.Symbol, "address": t.Address, "chain_id": chainID})
+			continue
+		}
+		tokenBalances = append(tokenBalances, TokenBalance{
+			TokenAddress: token.Address,
+			TokenSymbol:  token.Symbol,
+			TokenName:    token.Name,
+			Balance:      bal,
+			Decimals:     token.Decimals,
+			USDValue:     0, // priced below
+			Token:        token,
+		})
+	}
+	native, _ := nativeToken(chainID)
 
 	// Price native and tokens via CoinGecko (USD)
-	cg := NewCoinGeckoClient(s.redis)
-	nativeID := NativeCoinGeckoIDByChain[chainID]
+	cg := s.prices()
 	priceIDs := []string{}
-	if nativeID != "" {
-		priceIDs = append(priceIDs, nativeID)
+	if native != nil && native.CoinGeckoID != "" {
+		priceIDs = append(priceIDs, native.CoinGeckoID)
 	}
 	for _, t := range tokenBalances {
-		// look up CG id from CommonERC20Tokens map by address
-		for _, meta := range CommonERC20Tokens[chainID] {
-			if strings.EqualFold(meta.Address, t.TokenAddress) && meta.CoinGeckoID != "" {
-				priceIDs = append(priceIDs, meta.CoinGeckoID)
-			}
+		if t.Token.CoinGeckoID != "" {
+			priceIDs = append(priceIDs, t.Token.CoinGeckoID)
 		}
 	}
 	prices := map[string]TokenPrice{}
@@ -210,37 +311,19 @@ func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID, req BalanceR
 
 	// Compute USD values
 	totalUSD := 0.0
-	// native
-	if nativeID != "" {
-		if pt, ok := prices[nativeID]; ok {
-			// native decimals are 18 for ETH; Polygon MATIC also 18 (commonly). Keep it simple: 18.
-			usd := new(big.Float).Quo(new(big.Float).SetInt(nativeBalance), new(big.Float).SetFloat64(1e18))
-			v, _ := new(big.Float).Mul(usd, big.NewFloat(pt.Price)).Float64()
+	if native != nil {
+		if pt, ok := prices[native.CoinGeckoID]; ok {
+			v, _ := native.Amount(nativeBalance).Mul(decimal.NewFromFloat(pt.Price)).Float64()
 			totalUSD += v
 		}
 	}
-	// tokens
 	for i := range tokenBalances {
-		metaID := ""
-		for _, meta := range CommonERC20Tokens[chainID] {
-			if strings.EqualFold(meta.Address, tokenBalances[i].TokenAddress) {
-				metaID = meta.CoinGeckoID
-				break
-			}
-		}
-		if metaID == "" {
-			continue
-		}
-		pt, ok := prices[metaID]
-		if !ok {
+		token := tokenBalances[i].Token
+		pt, ok := prices[token.CoinGeckoID]
+		if token.CoinGeckoID == "" || !ok {
 			continue
 		}
-		decPow := new(big.Float).SetFloat64(1.0)
-		for j := 0; j < tokenBalances[i].Decimals; j++ {
-			decPow = new(big.Float).Mul(decPow, big.NewFloat(10))
-		}
-		amt := new(big.Float).Quo(new(big.Float).SetInt(tokenBalances[i].Balance), decPow)
-		v, _ := new(big.Float).Mul(amt, big.NewFloat(pt.Price)).Float64()
+		v, _ := token.Amount(tokenBalances[i].Balance).Mul(decimal.NewFromFloat(pt.Price)).Float64()
 		tokenBalances[i].USDValue = v
 		totalUSD += v
 	}
@@ -249,13 +332,15 @@ func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID, req BalanceR
 		Address:       address,
 		ChainID:       chainID,
 		NativeBalance: nativeBalance,
+		NativeToken:   native,
 		TokenBalances: tokenBalances,
 		TotalUSDValue: totalUSD,
 		Metadata: map[string]any{
 			"provider":   provider.RpcURL,
-			"chain_name": SupportedChains[chainID],
+			"chain_name": ChainName(chainID),
 			"timestamp":  time.Now(),
 		},
+		Network: NetworkOf(chainID),
 	}
 
 	s.logger.Info(ctx, "Balance retrieved", map[string]any{
@@ -273,18 +358,70 @@ func (s *Service) CreateTransaction(ctx context.Context, userID uuid.UUID, req T
 	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("web3-service").Start(ctx, "web3.CreateTransaction")
 	defer span.End()
 
+	return s.createTransaction(ctx, userID, req, true)
+}
+
+// createTransaction builds the transaction; with checkMultisig set, transactions of a
+// multisig wallet become proposals instead, which build it once approved
+func (s *Service) createTransaction(ctx context.Context, userID uuid.UUID, req TransactionRequest, checkMultisig bool) (*TransactionResponse, error) {
+	// Safe treasuries are watch-only, so the policy is checked before the signing wallet
+	if checkMultisig && s.multisig != nil {
+		if resp, err := s.multisig.proposeForWallet(ctx, userID, req); err != nil || resp != nil {
+			return resp, err
+		}
+	}
+
 	// Get wallet
-	wallet, err := s.walletRepo.GetByID(ctx, req.WalletID)
+	wallet, err := s.signingWallet(ctx, userID, req.WalletID)
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
+		return nil, err
 	}
-	if wallet.UserID != userID {
-		return nil, fmt.Errorf("wallet does not belong to user")
+
+	if err := checkWalletNetwork(wallet, req.Network); err != nil {
+		return nil, err
 	}
 
 	// Validate chain support
-	if _, exists := s.providers[wallet.ChainID]; !exists {
-		return nil, fmt.Errorf("no provider configured for chain ID: %d", wallet.ChainID)
+	if _, err := s.provider(wallet.ChainID); err != nil {
+		return nil, err
+	}
+
+	// The recipient may be a raw address, an ENS name or an address book label
+	var recipient *RecipientResolution
+	to := req.ToAddress
+	if to == "" {
+		to = req.To
+	}
+	if to != "" {
+		recipient, err = s.resolveRecipient(ctx, userID, wallet.ChainID, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Known scam and sanctioned recipients are blocked, risky ones need acknowledging
+	var screening *TransactionScreening
+	if s.screener != nil && recipient != nil {
+		screening, err = s.screener.CheckTransaction(ctx, userID, TransactionScreeningRequest{
+			ChainID:   wallet.ChainID,
+			From:      wallet.Address,
+			ToAddress: recipient.Address,
+			Value:     req.Value,
+			Data:      req.Data,
+		}, req.AcknowledgeRisk)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Suggest fees the caller left unset when a fee tier is requested
+	fees, feeEstimate, err := s.resolveTransactionFees(ctx, wallet.ChainID, req.FeeTier, TransactionFees{
+		GasPrice:             req.GasPrice,
+		MaxFeePerGas:         req.MaxFeePerGas,
+		MaxPriorityFeePerGas: req.MaxPriorityFeePerGas,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Create transaction record
@@ -297,11 +434,41 @@ func (s *Service) CreateTransaction(ctx context.Context, userID uuid.UUID, req T
 		FromAddress:     wallet.Address,
 		ToAddress:       req.ToAddress,
 		Value:           req.Value,
+		Data:            req.Data,
 		Status:          TxStatusPending,
+		GasLimit:        req.GasLimit,
+		GasPrice:        fees.GasPrice,
 		TransactionType: "transfer",
 		Metadata:        req.Metadata,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
+
+		MaxFeePerGas:         fees.MaxFeePerGas,
+		MaxPriorityFeePerGas: fees.MaxPriorityFeePerGas,
+		FeeTier:              req.FeeTier,
+		Network:              NetworkOf(wallet.ChainID),
+	}
+	if feeEstimate != nil {
+		if transaction.Metadata == nil {
+			transaction.Metadata = make(map[string]interface{})
+		}
+		transaction.Metadata["fee_estimate"] = feeEstimate
+	}
+	if recipient != nil {
+		transaction.ToAddress = recipient.Address
+		if transaction.Metadata == nil {
+			transaction.Metadata = make(map[string]interface{})
+		}
+		transaction.Metadata["recipient"] = recipient
+	}
+	if screening != nil {
+		transaction.Metadata["screening"] = screening
+	}
+
+	// The user's wallet signs the transaction; it has no hash until it is broadcast
+	if s.signingQueue != nil {
+		transaction.Status = TxStatusAwaitingSignature
+		transaction.TxHash = ""
 	}
 
 	// Save transaction to database
@@ -310,22 +477,40 @@ func (s *Service) CreateTransaction(ctx context.Context, userID uuid.UUID, req T
 		return nil, fmt.Errorf("failed to save transaction: %w", err)
 	}
 
-	// In a real implementation, this would broadcast the transaction to the network
-	// For demo purposes, we'll simulate a successful transaction
-	go s.simulateTransactionConfirmation(context.Background(), transaction)
+	var signingRequest *SigningRequest
+	if s.signingQueue != nil {
+		signingRequest, err = s.signingQueue.Enqueue(ctx, wallet, transaction, req.Data)
+		if err != nil {
+			transaction.Status = TxStatusFailed
+			if updateErr := s.txRepo.UpdateStatus(ctx, transaction.ID, TxStatusFailed); updateErr != nil {
+				s.logger.Error(ctx, "Failed to update transaction status", updateErr)
+			}
+			return nil, fmt.Errorf("failed to create signing request: %w", err)
+		}
+	} else {
+		// In a real implementation, this would broadcast the transaction to the network
+		// For demo purposes, we'll simulate a successful transaction
+		go s.simulateTransactionConfirmation(context.Background(), transaction)
+	}
+
+	s.describeTransactions(ctx, []*Transaction{transaction})
 
 	response := &TransactionResponse{
-		Transaction: transaction,
-		TxHash:      transaction.TxHash,
-		Status:      string(transaction.Status),
+		Transaction:    transaction,
+		TxHash:         transaction.TxHash,
+		Status:         string(transaction.Status),
+		Recipient:      recipient,
+		Screening:      screening,
+		SigningRequest: signingRequest,
 	}
 
 	s.logger.Info(ctx, "Transaction created", map[string]any{
 		"tx_id":    transaction.ID.String(),
 		"tx_hash":  transaction.TxHash,
 		"from":     transaction.FromAddress,
-		"to":       req.ToAddress,
+		"to":       transaction.ToAddress,
 		"chain_id": wallet.ChainID,
+		"network":  transaction.Network,
 	})
 
 	return response, nil
@@ -347,8 +532,7 @@ func (s *Service) GetPrices(ctx context.Context, req PriceRequest) (*PriceRespon
 		ids = []string{strings.ToLower(req.Token)}
 	}
 
-	cg := NewCoinGeckoClient(s.redis)
-	prices, err := cg.GetPrices(ctx, currency, ids)
+	prices, err := s.prices().GetPrices(ctx, currency, ids)
 	if err != nil {
 		s.logger.Error(ctx, "CoinGecko price fetch failed", err)
 		return nil, fmt.Errorf("failed to fetch prices: %w", err)
@@ -368,15 +552,205 @@ func (s *Service) GetPrices(ctx context.Context, req PriceRequest) (*PriceRespon
 	return response, nil
 }
 
-// ListWallets returns user's wallets with filters and pagination
-func (s *Service) ListWallets(ctx context.Context, userID uuid.UUID, filter WalletListFilter) ([]*Wallet, Pagination, error) {
-	if filter.Page <= 0 {
-		filter.Page = 1
+// ListWallets returns user's wallets with filters and pagination. Each wallet carries
+// its native balance when one is cached in Redis; no RPC calls are made.
+func (s *Service) ListWallets(ctx context.Context, userID uuid.UUID, filter WalletListFilter) (*WalletListResponse, error) {
+	if filter.Limit > 0 || filter.Offset > 0 {
+		if filter.Limit <= 0 || filter.Limit > 100 {
+			filter.Limit = 20
+		}
+		if filter.Offset < 0 {
+			filter.Offset = 0
+		}
+	} else {
+		if filter.Page <= 0 {
+			filter.Page = 1
+		}
+		if filter.PageSize <= 0 || filter.PageSize > 100 {
+			filter.PageSize = 20
+		}
+		filter.Limit = filter.PageSize
+		filter.Offset = (filter.Page - 1) * filter.PageSize
 	}
-	if filter.PageSize <= 0 || filter.PageSize > 100 {
-		filter.PageSize = 20
+
+	network, err := normalizeNetwork(filter.Network)
+	if err != nil {
+		return nil, err
+	}
+	filter.Network = network
+
+	wallets, pagination, err := s.walletRepo.ListByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	summaries := make([]*WalletSummary, 0, len(wallets))
+	for _, w := range wallets {
+		summary := &WalletSummary{Wallet: w}
+		if bal, ok := s.cachedNativeBalance(ctx, w.ChainID, w.Address); ok {
+			summary.NativeBalance = bal
+			summary.BalanceCached = true
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return &WalletListResponse{
+		Wallets:    summaries,
+		Total:      pagination.TotalItems,
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+		Pagination: pagination,
+	}, nil
+}
+
+// ListDeFiPositions aggregates a user's DeFi positions from the protocol manager and
+// persisted records, valuing them in USD with the same price source as GetPrices.
+// Liquidity pool positions carry LP analytics. Only positions on the filter's network are
+// listed and totalled, mainnet unless the filter selects testnet.
+func (s *Service) ListDeFiPositions(ctx context.Context, userID uuid.UUID, filter DeFiPositionFilter) (*DeFiPositionsResponse, error) {
+	ctx, span := observability.SpanFromContext(ctx).TracerProvider().Tracer("web3-service").Start(ctx, "web3.ListDeFiPositions")
+	defer span.End()
+
+	network, err := normalizeNetwork(filter.Network)
+	if err != nil {
+		return nil, err
+	}
+	if network == "" {
+		network = NetworkMainnet
+	}
+	filter.Network = network
+
+	merged, sources, err := s.collectDeFiPositions(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	prices := s.priceDeFiPositions(ctx, merged)
+	chains := s.positionChains(ctx, merged)
+
+	response := &DeFiPositionsResponse{
+		Positions:         make([]*DeFiPositionSummary, 0, len(merged)),
+		TotalValueUSD:     decimal.Zero,
+		TotalAccruedYield: decimal.Zero,
+		Timestamp:         time.Now(),
+	}
+	for id, p := range merged {
+		summary := &DeFiPositionSummary{
+			ID:           p.ID,
+			WalletID:     p.WalletID,
+			Protocol:     p.ProtocolName,
+			PositionType: defiPositionType(p),
+			Asset:        defiPositionAsset(p),
+			Amount:       p.Amount,
+			ValueUSD:     valueDeFiPosition(p, prices),
+			APY:          p.APY,
+			IsActive:     isDeFiPositionOpen(p),
+			Source:       sources[id],
+			CreatedAt:    p.CreatedAt,
+			UpdatedAt:    p.UpdatedAt,
+			Network:      defiPositionNetwork(p),
+		}
+		summary.AccruedYield = accruedDeFiYield(p, summary.ValueUSD, response.Timestamp)
+		if chainID, ok := chains[p.WalletID]; ok {
+			summary.Tokens = s.Tokens().positionTokens(ctx, chainID, p)
+		}
+		if isLiquidityPosition(p) {
+			summary.Analytics = analyzeLPPosition(p, prices, response.Timestamp)
+		}
+
+		response.Positions = append(response.Positions, summary)
+		response.TotalValueUSD = response.TotalValueUSD.Add(summary.ValueUSD)
+		response.TotalAccruedYield = response.TotalAccruedYield.Add(summary.AccruedYield)
+	}
+	sort.Slice(response.Positions, func(i, j int) bool {
+		return response.Positions[i].ValueUSD.GreaterThan(response.Positions[j].ValueUSD)
+	})
+	response.Count = len(response.Positions)
+
+	return response, nil
+}
+
+// collectDeFiPositions merges a user's stored and live DeFi positions matching the filter and
+// records where each came from. Live positions take precedence over stored copies.
+func (s *Service) collectDeFiPositions(ctx context.Context, userID uuid.UUID, filter DeFiPositionFilter) (map[uuid.UUID]*DeFiPosition, map[uuid.UUID]string, error) {
+	merged := make(map[uuid.UUID]*DeFiPosition)
+	sources := make(map[uuid.UUID]string)
+	if s.positionRepo != nil {
+		stored, err := s.positionRepo.ListByUser(ctx, userID, filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load DeFi positions: %w", err)
+		}
+		for _, p := range stored {
+			merged[p.ID] = p
+			sources[p.ID] = "stored"
+		}
+	}
+	if s.defiManager != nil {
+		for _, p := range s.defiManager.GetUserPositions(userID) {
+			if filter.Protocol != "" && !strings.EqualFold(p.ProtocolName, filter.Protocol) && !strings.EqualFold(p.ProtocolID, filter.Protocol) {
+				continue
+			}
+			if !filter.IncludeClosed && !isDeFiPositionOpen(p) {
+				continue
+			}
+			if filter.Network != "" && defiPositionNetwork(p) != filter.Network {
+				continue
+			}
+			merged[p.ID] = p
+			sources[p.ID] = "live"
+		}
+	}
+	return merged, sources, nil
+}
+
+// positionChains maps the wallets holding the positions to their chains; positions of wallets
+// that can't be loaded are listed without token metadata
+func (s *Service) positionChains(ctx context.Context, positions map[uuid.UUID]*DeFiPosition) map[uuid.UUID]int {
+	chains := make(map[uuid.UUID]int)
+	for _, p := range positions {
+		if _, seen := chains[p.WalletID]; seen || p.WalletID == uuid.Nil {
+			continue
+		}
+		wallet, err := s.walletRepo.GetByID(ctx, p.WalletID)
+		if err != nil || wallet == nil {
+			continue
+		}
+		chains[p.WalletID] = wallet.ChainID
+	}
+	return chains
+}
+
+// defiPositionNetwork returns the network of a DeFi position; untagged positions are mainnet
+func defiPositionNetwork(p *DeFiPosition) string {
+	if p.Network == "" {
+		return NetworkMainnet
+	}
+	return p.Network
+}
+
+// priceDeFiPositions prices every asset referenced by the positions in a single request. A
+// failed request yields no prices so positions fall back to their stored values.
+func (s *Service) priceDeFiPositions(ctx context.Context, positions map[uuid.UUID]*DeFiPosition) map[string]TokenPrice {
+	idSet := make(map[string]struct{})
+	for _, p := range positions {
+		for _, symbol := range []string{p.TokenSymbol, p.TokenA, p.TokenB} {
+			if id, ok := CoinGeckoIDBySymbol[strings.ToUpper(symbol)]; ok {
+				idSet[id] = struct{}{}
+			}
+		}
 	}
-	return s.walletRepo.ListByUser(ctx, userID, filter)
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	prices := map[string]TokenPrice{}
+	if len(ids) > 0 {
+		if p, err := s.prices().GetPrices(ctx, "USD", ids); err == nil {
+			prices = p
+		} else {
+			s.logger.Warn(ctx, "Price fetch failed, using stored position values", map[string]any{"error": err.Error()})
+		}
+	}
+	return prices
 }
 
 // ListTransactions returns user's transactions with filters and pagination
@@ -387,7 +761,30 @@ func (s *Service) ListTransactions(ctx context.Context, userID uuid.UUID, filter
 	if filter.PageSize <= 0 || filter.PageSize > 100 {
 		filter.PageSize = 20
 	}
-	return s.txRepo.ListByUser(ctx, userID, filter)
+	network, err := normalizeNetwork(filter.Network)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	filter.Network = network
+	transactions, pagination, err := s.txRepo.ListByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	s.describeTransactions(ctx, transactions)
+	return transactions, pagination, nil
+}
+
+// describeTransactions attaches the asset each transaction sends; transactions whose token
+// can't be resolved are returned without one
+func (s *Service) describeTransactions(ctx context.Context, transactions []*Transaction) {
+	for _, tx := range transactions {
+		asset, err := s.Tokens().DescribeTransaction(ctx, tx)
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to describe transaction asset", map[string]any{"error": err.Error(), "tx_id": tx.ID.String(), "chain_id": tx.ChainID})
+			continue
+		}
+		tx.Asset = asset
+	}
 }
 
 // InteractWithDeFiProtocol interacts with DeFi protocols
@@ -396,12 +793,12 @@ func (s *Service) InteractWithDeFiProtocol(ctx context.Context, userID uuid.UUID
 	defer span.End()
 
 	// Get wallet
-	wallet, err := s.walletRepo.GetByID(ctx, req.WalletID)
+	wallet, err := s.signingWallet(ctx, userID, req.WalletID)
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
+		return nil, err
 	}
-	if wallet.UserID != userID {
-		return nil, fmt.Errorf("wallet does not belong to user")
+	if err := checkWalletNetwork(wallet, req.Network); err != nil {
+		return nil, err
 	}
 
 	// For demo purposes, simulate DeFi interaction
@@ -432,6 +829,14 @@ func (s *Service) InteractWithDeFiProtocol(ctx context.Context, userID uuid.UUID
 		}, nil
 	}
 
+	// Positions opened here are listed as live alongside the stored ones
+	if position != nil {
+		position.Network = NetworkOf(wallet.ChainID)
+		if s.defiManager != nil {
+			s.defiManager.TrackPosition(position)
+		}
+	}
+
 	response := &DeFiProtocolResponse{
 		Success:  true,
 		TxHash:   txHash,
@@ -439,6 +844,7 @@ func (s *Service) InteractWithDeFiProtocol(ctx context.Context, userID uuid.UUID
 		Metadata: map[string]any{
 			"protocol":  req.Protocol,
 			"action":    req.Action,
+			"network":   NetworkOf(wallet.ChainID),
 			"timestamp": time.Now(),
 		},
 	}
@@ -460,9 +866,21 @@ func (s *Service) simulateTransactionConfirmation(ctx context.Context, tx *Trans
 	// Simulate network delay
 	time.Sleep(5 * time.Second)
 
+	s.confirmTransaction(ctx, tx)
+}
+
+// confirmTransaction records a transaction as confirmed and verifies the address book
+// entries of its recipient
+func (s *Service) confirmTransaction(ctx context.Context, tx *Transaction) {
 	// Update transaction status in DB
 	_ = s.txRepo.UpdateStatus(ctx, tx.ID, TxStatusConfirmed)
 
+	if s.addressBook != nil && tx.ToAddress != "" {
+		if err := s.addressBook.MarkVerified(ctx, tx.UserID, tx.ChainID, tx.ToAddress, time.Now()); err != nil {
+			s.logger.Warn(ctx, "Failed to verify address book entry", map[string]any{"error": err.Error(), "tx_hash": tx.TxHash})
+		}
+	}
+
 	s.logger.Info(ctx, "Transaction confirmed", map[string]any{
 		"tx_hash": tx.TxHash,
 	})
@@ -531,3 +949,72 @@ func (s *Service) simulateCompoundInteraction(ctx context.Context, wallet *Walle
 
 	return txHash, position, nil
 }
+
+// DeFi position valuation helpers
+
+func isDeFiPositionOpen(p *DeFiPosition) bool {
+	if p.Status != "" {
+		return p.Status == PositionStatusOpen
+	}
+	return p.IsActive
+}
+
+func defiPositionType(p *DeFiPosition) string {
+	if p.PositionType != "" {
+		return p.PositionType
+	}
+	return string(p.Type)
+}
+
+func defiPositionAsset(p *DeFiPosition) string {
+	if p.TokenSymbol != "" {
+		return p.TokenSymbol
+	}
+	if p.TokenA != "" && p.TokenB != "" {
+		return p.TokenA + "-" + p.TokenB
+	}
+	return p.TokenA
+}
+
+func symbolPrice(symbol string, prices map[string]TokenPrice) (decimal.Decimal, bool) {
+	id, ok := CoinGeckoIDBySymbol[strings.ToUpper(symbol)]
+	if !ok {
+		return decimal.Zero, false
+	}
+	pt, ok := prices[id]
+	if !ok {
+		return decimal.Zero, false
+	}
+	return decimal.NewFromFloat(pt.Price), true
+}
+
+// valueDeFiPosition prices a position from live market data, falling back to the
+// last recorded valuation when an asset has no price
+func valueDeFiPosition(p *DeFiPosition, prices map[string]TokenPrice) decimal.Decimal {
+	if !p.AmountA.IsZero() || !p.AmountB.IsZero() {
+		priceA, okA := symbolPrice(p.TokenA, prices)
+		priceB, okB := symbolPrice(p.TokenB, prices)
+		if okA && okB {
+			return p.AmountA.Mul(priceA).Add(p.AmountB.Mul(priceB))
+		}
+	} else if price, ok := symbolPrice(p.TokenSymbol, prices); ok {
+		return p.Amount.Mul(price)
+	}
+	if !p.CurrentValue.IsZero() {
+		return p.CurrentValue
+	}
+	return p.USDValue
+}
+
+// accruedDeFiYield returns recorded rewards, or estimates them from APY (in percent)
+// over the position lifetime when the protocol does not report rewards
+func accruedDeFiYield(p *DeFiPosition, valueUSD decimal.Decimal, now time.Time) decimal.Decimal {
+	if p.Rewards.IsPositive() {
+		return p.Rewards
+	}
+	if p.APY.IsZero() || p.CreatedAt.IsZero() || now.Before(p.CreatedAt) {
+		return decimal.Zero
+	}
+	years := decimal.NewFromFloat(now.Sub(p.CreatedAt).Hours() / (24 * 365))
+	return valueUSD.Mul(p.APY).Div(decimal.NewFromInt(100)).Mul(years).Round(8)
+}
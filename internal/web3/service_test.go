@@ -2,6 +2,7 @@ package web3
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"math/big"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type mockWalletRepo struct {
@@ -25,7 +27,7 @@ func (m *mockWalletRepo) GetByID(ctx context.Context, id uuid.UUID) (*Wallet, er
 	if w, ok := m.getByID[id]; ok {
 		return w, nil
 	}
-	return nil, fmt.Errorf("not found")
+	return nil, fmt.Errorf("not found: %w", sql.ErrNoRows)
 }
 func (m *mockWalletRepo) GetByAddress(ctx context.Context, userID uuid.UUID, address string, chainID int) (*Wallet, error) {
 	key := address
@@ -38,7 +40,19 @@ func (m *mockWalletRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int
 	return m.countByUser, nil
 }
 func (m *mockWalletRepo) ListByUser(ctx context.Context, userID uuid.UUID, filter WalletListFilter) ([]*Wallet, Pagination, error) {
-	return m.listResult, Pagination{Page: 1, PageSize: len(m.listResult), TotalItems: len(m.listResult), TotalPages: 1}, nil
+	matched := make([]*Wallet, 0, len(m.listResult))
+	for _, w := range m.listResult {
+		if filter.ChainID == 0 || w.ChainID == filter.ChainID {
+			matched = append(matched, w)
+		}
+	}
+	page := matched
+	if filter.Limit > 0 {
+		start := min(filter.Offset, len(matched))
+		end := min(start+filter.Limit, len(matched))
+		page = matched[start:end]
+	}
+	return page, Pagination{Page: 1, PageSize: len(page), TotalItems: len(matched), TotalPages: 1}, nil
 }
 func (m *mockWalletRepo) SetPrimary(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) error {
 	return nil
@@ -60,6 +74,9 @@ func (m *mockTxRepo) ListByWallet(ctx context.Context, walletID uuid.UUID, filte
 	return m.list, Pagination{Page: 1, PageSize: len(m.list), TotalItems: len(m.list), TotalPages: 1}, nil
 }
 func (m *mockTxRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error { return nil }
+func (m *mockTxRepo) MarkBroadcast(ctx context.Context, id uuid.UUID, txHash string) error {
+	return nil
+}
 
 // construct service with mocks
 func newServiceWithMocks() *Service {
@@ -92,12 +109,77 @@ func TestListWallets(t *testing.T) {
 	mw := s.walletRepo.(*mockWalletRepo)
 	mw.listResult = []*Wallet{{ID: uuid.New(), UserID: uuid.New(), Address: "0xabc", ChainID: 1, WalletType: "metamask", CreatedAt: time.Now(), UpdatedAt: time.Now()}}
 
-	ws, pg, err := s.ListWallets(context.Background(), uuid.New(), WalletListFilter{Page: 1, PageSize: 10})
+	resp, err := s.ListWallets(context.Background(), uuid.New(), WalletListFilter{Page: 1, PageSize: 10})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(ws) != 1 || pg.TotalItems != 1 {
-		t.Fatalf("expected 1 wallet, got %d", len(ws))
+	if len(resp.Wallets) != 1 || resp.Total != 1 {
+		t.Fatalf("expected 1 wallet, got %d", len(resp.Wallets))
+	}
+}
+
+func TestListWallets_Empty(t *testing.T) {
+	s := newServiceWithMocks()
+
+	resp, err := s.ListWallets(context.Background(), uuid.New(), WalletListFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Wallets == nil || len(resp.Wallets) != 0 || resp.Total != 0 {
+		t.Fatalf("expected empty wallet list, got %+v", resp)
+	}
+	if resp.Limit != 20 || resp.Offset != 0 {
+		t.Fatalf("expected default limit 20 offset 0, got %d/%d", resp.Limit, resp.Offset)
+	}
+}
+
+func TestListWallets_MultipleChains(t *testing.T) {
+	s := newServiceWithMocks()
+	mw := s.walletRepo.(*mockWalletRepo)
+	userID := uuid.New()
+	mw.listResult = []*Wallet{
+		{ID: uuid.New(), UserID: userID, Address: "0xabc", ChainID: 1, WalletType: "metamask", CreatedAt: time.Now()},
+		{ID: uuid.New(), UserID: userID, Address: "0xdef", ChainID: 137, WalletType: "metamask", CreatedAt: time.Now()},
+		{ID: uuid.New(), UserID: userID, Address: "0x123", ChainID: 137, WalletType: "walletconnect", CreatedAt: time.Now()},
+	}
+
+	resp, err := s.ListWallets(context.Background(), userID, WalletListFilter{ChainID: 137})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Wallets) != 2 || resp.Total != 2 {
+		t.Fatalf("expected 2 polygon wallets, got %d", len(resp.Wallets))
+	}
+	for _, w := range resp.Wallets {
+		if w.ChainID != 137 {
+			t.Fatalf("unexpected chain %d in filtered listing", w.ChainID)
+		}
+	}
+
+	resp, err = s.ListWallets(context.Background(), userID, WalletListFilter{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Wallets) != 1 || resp.Total != 3 || resp.Offset != 2 {
+		t.Fatalf("expected last page with 1 of 3 wallets, got %d of %d", len(resp.Wallets), resp.Total)
+	}
+}
+
+func TestListWallets_CacheMissFallback(t *testing.T) {
+	// No Redis configured behaves like a cache miss: wallets are still listed, without balances
+	s := newServiceWithMocks()
+	mw := s.walletRepo.(*mockWalletRepo)
+	mw.listResult = []*Wallet{{ID: uuid.New(), UserID: uuid.New(), Address: "0xabc", ChainID: 1, WalletType: "metamask", CreatedAt: time.Now()}}
+
+	resp, err := s.ListWallets(context.Background(), uuid.New(), WalletListFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Wallets) != 1 {
+		t.Fatalf("expected 1 wallet, got %d", len(resp.Wallets))
+	}
+	if resp.Wallets[0].BalanceCached || resp.Wallets[0].NativeBalance != nil {
+		t.Fatalf("expected no cached balance on cache miss")
 	}
 }
 
@@ -108,7 +190,7 @@ func TestCreateTransaction_Persists(t *testing.T) {
 	userID := uuid.New()
 	mw.getByID = map[uuid.UUID]*Wallet{walletID: {ID: walletID, UserID: userID, Address: "0xabc", ChainID: 1}}
 
-	_, err := s.CreateTransaction(context.Background(), userID, TransactionRequest{WalletID: walletID, ToAddress: "0xdef", Value: big.NewInt(1)})
+	_, err := s.CreateTransaction(context.Background(), userID, TransactionRequest{WalletID: walletID, ToAddress: "0x000000000000000000000000000000000000dEaD", Value: big.NewInt(1)})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -127,3 +209,120 @@ func TestListTransactions(t *testing.T) {
 		t.Fatalf("expected 1 transaction")
 	}
 }
+
+type mockPositionRepo struct {
+	positions []*DeFiPosition
+}
+
+func (m *mockPositionRepo) ListByUser(ctx context.Context, userID uuid.UUID, filter DeFiPositionFilter) ([]*DeFiPosition, error) {
+	var result []*DeFiPosition
+	for _, p := range m.positions {
+		if filter.Protocol != "" && p.ProtocolName != filter.Protocol {
+			continue
+		}
+		if !filter.IncludeClosed && !p.IsActive {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+type mockPriceSource struct {
+	prices map[string]TokenPrice
+	err    error
+}
+
+func (m *mockPriceSource) GetPrices(ctx context.Context, currency string, tokenIds []string) (map[string]TokenPrice, error) {
+	return m.prices, m.err
+}
+
+func TestListDeFiPositions_AggregatesSources(t *testing.T) {
+	s := newServiceWithMocks()
+	userID := uuid.New()
+	s.positionRepo = &mockPositionRepo{positions: []*DeFiPosition{
+		{ID: uuid.New(), UserID: userID, ProtocolName: "aave", PositionType: "lending", TokenSymbol: "USDC", Amount: decimal.NewFromInt(1000), USDValue: decimal.NewFromInt(1000), APY: decimal.NewFromInt(5), IsActive: true, CreatedAt: time.Now().Add(-365 * 24 * time.Hour)},
+		{ID: uuid.New(), UserID: userID, ProtocolName: "compound", PositionType: "lending", TokenSymbol: "DAI", Amount: decimal.NewFromInt(50), USDValue: decimal.NewFromInt(50), IsActive: false, CreatedAt: time.Now()},
+	}}
+	s.defiManager = NewDeFiProtocolManager(s.logger)
+	live := &DeFiPosition{ID: uuid.New(), UserID: userID, ProtocolName: "uniswap", Type: PositionTypeLiquidity, TokenA: "ETH", TokenB: "USDC", AmountA: decimal.NewFromInt(1), AmountB: decimal.NewFromInt(2000), Rewards: decimal.NewFromInt(12), Status: PositionStatusOpen, CreatedAt: time.Now()}
+	s.defiManager.TrackPosition(live)
+	s.priceSource = &mockPriceSource{prices: map[string]TokenPrice{
+		"ethereum": {Price: 2000},
+		"usd-coin": {Price: 1},
+	}}
+
+	resp, err := s.ListDeFiPositions(context.Background(), userID, DeFiPositionFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Count != 2 {
+		t.Fatalf("expected 2 open positions, got %d", resp.Count)
+	}
+	// LP: 1 ETH * 2000 + 2000 USDC * 1; lending: 1000 USDC * 1
+	if !resp.TotalValueUSD.Equal(decimal.NewFromInt(5000)) {
+		t.Fatalf("expected total value 5000, got %s", resp.TotalValueUSD)
+	}
+	if resp.Positions[0].Source != "live" || !resp.Positions[0].AccruedYield.Equal(decimal.NewFromInt(12)) {
+		t.Fatalf("expected live LP position first with reported rewards, got %+v", resp.Positions[0])
+	}
+	// One year at 5% APY on 1000 USD
+	if !resp.Positions[1].AccruedYield.Round(0).Equal(decimal.NewFromInt(50)) {
+		t.Fatalf("expected ~50 accrued yield, got %s", resp.Positions[1].AccruedYield)
+	}
+
+	resp, err = s.ListDeFiPositions(context.Background(), userID, DeFiPositionFilter{Protocol: "compound", IncludeClosed: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Count != 1 || resp.Positions[0].Protocol != "compound" || resp.Positions[0].IsActive {
+		t.Fatalf("expected the closed compound position only, got %+v", resp.Positions)
+	}
+}
+
+func TestInteractWithDeFiProtocol_TracksPosition(t *testing.T) {
+	s := newServiceWithMocks()
+	userID := uuid.New()
+	wallet := &Wallet{ID: uuid.New(), UserID: userID, ChainID: 1}
+	s.walletRepo = &mockWalletRepo{getByID: map[uuid.UUID]*Wallet{wallet.ID: wallet}}
+	s.positionRepo = &mockPositionRepo{}
+	s.priceSource = &mockPriceSource{prices: map[string]TokenPrice{}}
+	s.defiManager = NewDeFiProtocolManager(s.logger)
+
+	interaction, err := s.InteractWithDeFiProtocol(context.Background(), userID, DeFiProtocolRequest{WalletID: wallet.ID, Protocol: "aave", Action: "deposit", Amount: decimal.NewFromInt(100)})
+	if err != nil || !interaction.Success {
+		t.Fatalf("unexpected interaction failure: %v %+v", err, interaction)
+	}
+
+	resp, err := s.ListDeFiPositions(context.Background(), userID, DeFiPositionFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	position, ok := interaction.Position.(*DeFiPosition)
+	if !ok {
+		t.Fatalf("expected the opened position, got %T", interaction.Position)
+	}
+	if resp.Count != 1 || resp.Positions[0].ID != position.ID || resp.Positions[0].Source != "live" {
+		t.Fatalf("expected the aave position as live, got %+v", resp.Positions)
+	}
+	if others := s.defiManager.GetUserPositions(uuid.New()); len(others) != 0 {
+		t.Fatalf("expected no positions for other users, got %d", len(others))
+	}
+}
+
+func TestListDeFiPositions_PriceFallback(t *testing.T) {
+	s := newServiceWithMocks()
+	userID := uuid.New()
+	s.positionRepo = &mockPositionRepo{positions: []*DeFiPosition{
+		{ID: uuid.New(), UserID: userID, ProtocolName: "aave", PositionType: "lending", TokenSymbol: "USDC", Amount: decimal.NewFromInt(1000), USDValue: decimal.NewFromInt(990), IsActive: true, CreatedAt: time.Now()},
+	}}
+	s.priceSource = &mockPriceSource{err: fmt.Errorf("rate limited")}
+
+	resp, err := s.ListDeFiPositions(context.Background(), userID, DeFiPositionFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.TotalValueUSD.Equal(decimal.NewFromInt(990)) {
+		t.Fatalf("expected stored valuation when prices are unavailable, got %s", resp.TotalValueUSD)
+	}
+}
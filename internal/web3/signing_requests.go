@@ -0,0 +1,648 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrSigningRequestNotFound   = errors.New("signing request not found")
+	ErrSigningRequestClosed     = errors.New("signing request is no longer pending")
+	ErrInvalidSignedTransaction = errors.New("invalid signed transaction")
+	// ErrBroadcastFailed means the network refused a signed transaction; the request stays pending
+	ErrBroadcastFailed = errors.New("broadcast failed")
+)
+
+// SigningRequestStatus is where a signing request is in its lifecycle
+type SigningRequestStatus string
+
+const (
+	SigningRequestPending   SigningRequestStatus = "pending"   // waiting for the user's wallet
+	SigningRequestBroadcast SigningRequestStatus = "broadcast" // signed and sent to the network
+	SigningRequestRejected  SigningRequestStatus = "rejected"  // the user declined in their wallet
+	SigningRequestCancelled SigningRequestStatus = "cancelled"
+	SigningRequestExpired   SigningRequestStatus = "expired"
+)
+
+// SigningChannel is how a signing request reaches the user's wallet
+type SigningChannel string
+
+const (
+	// SigningChannelQueue requests are fetched from GET /web3/signing-requests by the browser
+	// extension or hardware wallet integration and signed payloads posted back
+	SigningChannelQueue SigningChannel = "queue"
+	// SigningChannelWalletConnect requests are pushed to the wallet app of a WalletConnect session
+	SigningChannelWalletConnect SigningChannel = "walletconnect"
+)
+
+const signingAuditPrefix = "signing_request_"
+
+// UnsignedTransaction is a transaction built for an external wallet to sign. Amounts are
+// decimal strings; SigningHash is the hash hardware wallets sign and Raw the unsigned
+// transaction encoding.
+type UnsignedTransaction struct {
+	ChainID              int    `json:"chain_id"`
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+	Value                string `json:"value"`
+	Data                 string `json:"data,omitempty"`
+	Nonce                uint64 `json:"nonce"`
+	GasLimit             uint64 `json:"gas_limit"`
+	GasPrice             string `json:"gas_price,omitempty"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty"`
+	SigningHash          string `json:"signing_hash"`
+	Raw                  string `json:"raw"`
+}
+
+// SigningRequest asks the user's wallet to sign a transaction the service built
+type SigningRequest struct {
+	ID            uuid.UUID            `json:"id"`
+	UserID        uuid.UUID            `json:"user_id"`
+	WalletID      uuid.UUID            `json:"wallet_id"`
+	TransactionID uuid.UUID            `json:"transaction_id"`
+	Channel       SigningChannel       `json:"channel"`
+	Transaction   UnsignedTransaction  `json:"transaction"`
+	Status        SigningRequestStatus `json:"status"`
+	TxHash        string               `json:"tx_hash,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	ExpiresAt     time.Time            `json:"expires_at"`
+
+	// The WalletConnect session and JSON-RPC request the request was pushed with
+	WalletConnectTopic     string `json:"walletconnect_topic,omitempty"`
+	WalletConnectRequestID int64  `json:"walletconnect_request_id,omitempty"`
+}
+
+// SubmitSignatureRequest returns the signed transaction for a signing request
+type SubmitSignatureRequest struct {
+	SignedTransaction string `json:"signed_transaction"`
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *SubmitSignatureRequest) Validate() error {
+	var v validation.Validator
+	if v.Required("signed_transaction", r.SignedTransaction) && !strings.HasPrefix(strings.TrimSpace(r.SignedTransaction), "0x") {
+		v.Fail("signed_transaction", validation.ConstraintFormat, "must be 0x-prefixed hex")
+	}
+	return v.Err()
+}
+
+// SigningRequestStore keeps signing requests, shared by every web3 service replica
+type SigningRequestStore interface {
+	Save(ctx context.Context, request *SigningRequest) error
+	// Get returns the request, or nil
+	Get(ctx context.Context, id uuid.UUID) (*SigningRequest, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*SigningRequest, error)
+	ListPending(ctx context.Context) ([]*SigningRequest, error)
+}
+
+// SigningConfig configures external signing
+type SigningConfig struct {
+	RequestTTL    time.Duration // how long the user has to sign
+	SweepInterval time.Duration // how often expired requests are closed
+}
+
+// signingChain reads what building a transaction needs and broadcasts signed ones
+type signingChain interface {
+	PendingNonce(ctx context.Context, chainID int, address string) (uint64, error)
+	EstimateGas(ctx context.Context, chainID int, msg ethereum.CallMsg) (uint64, error)
+	SuggestGasPrice(ctx context.Context, chainID int) (*big.Int, error)
+	Broadcast(ctx context.Context, chainID int, tx *types.Transaction) error
+}
+
+// rpcSigningChain reaches chains through the service's providers
+type rpcSigningChain struct {
+	service *Service
+}
+
+func (r rpcSigningChain) PendingNonce(ctx context.Context, chainID int, address string) (uint64, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
+	return client.PendingNonceAt(ctx, common.HexToAddress(address))
+}
+
+func (r rpcSigningChain) EstimateGas(ctx context.Context, chainID int, msg ethereum.CallMsg) (uint64, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
+	return client.EstimateGas(ctx, msg)
+}
+
+func (r rpcSigningChain) SuggestGasPrice(ctx context.Context, chainID int) (*big.Int, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return client.SuggestGasPrice(ctx)
+}
+
+func (r rpcSigningChain) Broadcast(ctx context.Context, chainID int, tx *types.Transaction) error {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return err
+	}
+	return client.SendTransaction(ctx, tx)
+}
+
+// SigningQueue hands transactions to wallets whose keys the service doesn't hold. It builds
+// the unsigned transaction, pushes it to the wallet app of a WalletConnect session or queues
+// it for the browser extension or hardware wallet integration, and broadcasts the signed
+// transaction that comes back. Requests expire and can be cancelled; every step is
+// audit-logged.
+type SigningQueue struct {
+	service       *Service
+	store         SigningRequestStore
+	chain         signingChain
+	walletConnect *WalletConnectManager
+	auditor       *security.AuditManager
+	logger        *observability.Logger
+	config        SigningConfig
+	now           func() time.Time
+}
+
+// NewSigningQueue creates a signing queue building and broadcasting transactions through
+// the service's providers
+func NewSigningQueue(service *Service, store SigningRequestStore, cfg SigningConfig) *SigningQueue {
+	if cfg.RequestTTL <= 0 {
+		cfg.RequestTTL = 15 * time.Minute
+	}
+	return &SigningQueue{
+		service: service,
+		store:   store,
+		chain:   rpcSigningChain{service: service},
+		logger:  service.logger,
+		config:  cfg,
+		now:     time.Now,
+	}
+}
+
+// SetAuditor records signing requests and their outcome in the audit log
+func (q *SigningQueue) SetAuditor(auditor *security.AuditManager) {
+	q.auditor = auditor
+}
+
+// SetWalletConnect pushes requests of wallets with an active WalletConnect session to the
+// wallet app and takes the signatures it returns
+func (q *SigningQueue) SetWalletConnect(manager *WalletConnectManager) {
+	q.walletConnect = manager
+	manager.onResponse = q.handleWalletConnectResponse
+}
+
+// Start closes expired requests on the sweep interval until ctx is done
+func (q *SigningQueue) Start(ctx context.Context) {
+	interval := q.config.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				q.ExpireStale(ctx)
+			}
+		}
+	}()
+}
+
+// Enqueue builds the unsigned transaction for a transaction record awaiting signature and
+// sends it to the wallet
+func (q *SigningQueue) Enqueue(ctx context.Context, wallet *Wallet, tx *Transaction, data string) (*SigningRequest, error) {
+	unsigned, err := q.buildTransaction(ctx, wallet, tx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.now()
+	request := &SigningRequest{
+		ID:            uuid.New(),
+		UserID:        tx.UserID,
+		WalletID:      wallet.ID,
+		TransactionID: tx.ID,
+		Channel:       SigningChannelQueue,
+		Transaction:   *unsigned,
+		Status:        SigningRequestPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ExpiresAt:     now.Add(q.config.RequestTTL),
+	}
+
+	// A wallet paired over WalletConnect gets the request in its app; otherwise, or if the
+	// push fails, the request stays in the queue, where the user can still pick it up
+	if q.walletConnect != nil {
+		topic, requestID, err := q.walletConnect.RequestSignature(ctx, wallet, unsigned, q.config.RequestTTL)
+		switch {
+		case err == nil:
+			request.Channel = SigningChannelWalletConnect
+			request.WalletConnectTopic = topic
+			request.WalletConnectRequestID = requestID
+		case !errors.Is(err, ErrNoWalletConnectSession):
+			q.logger.Warn(ctx, "Failed to push signing request over WalletConnect", map[string]interface{}{
+				"wallet_id": wallet.ID.String(),
+				"error":     err.Error(),
+			})
+		}
+	}
+
+	if err := q.store.Save(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to save signing request: %w", err)
+	}
+	q.audit(ctx, request, "created", security.AuditResultSuccess, nil)
+	return request, nil
+}
+
+// buildTransaction fills in the nonce, gas limit and fees the record leaves unset
+func (q *SigningQueue) buildTransaction(ctx context.Context, wallet *Wallet, tx *Transaction, data string) (*UnsignedTransaction, error) {
+	from, to := common.HexToAddress(wallet.Address), common.HexToAddress(tx.ToAddress)
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	calldata := common.FromHex(data)
+
+	nonce, err := q.chain.PendingNonce(ctx, wallet.ChainID, wallet.Address)
+	if err != nil {
+		return nil, fmt.Errorf("read nonce: %w", err)
+	}
+	gasLimit := tx.GasLimit
+	if gasLimit == 0 {
+		if gasLimit, err = q.chain.EstimateGas(ctx, wallet.ChainID, ethereum.CallMsg{From: from, To: &to, Value: value, Data: calldata}); err != nil {
+			return nil, fmt.Errorf("estimate gas: %w", err)
+		}
+	}
+
+	chainID := big.NewInt(int64(wallet.ChainID))
+	var unsignedTx *types.Transaction
+	unsigned := &UnsignedTransaction{
+		ChainID:  wallet.ChainID,
+		From:     from.Hex(),
+		To:       to.Hex(),
+		Value:    value.String(),
+		Nonce:    nonce,
+		GasLimit: gasLimit,
+	}
+	if len(calldata) > 0 {
+		unsigned.Data = hexutil.Encode(calldata)
+	}
+	if tx.MaxFeePerGas != nil {
+		tip := tx.MaxPriorityFeePerGas
+		if tip == nil {
+			tip = new(big.Int)
+		}
+		unsignedTx = types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: tip, GasFeeCap: tx.MaxFeePerGas, Gas: gasLimit, To: &to, Value: value, Data: calldata})
+		unsigned.MaxFeePerGas, unsigned.MaxPriorityFeePerGas = tx.MaxFeePerGas.String(), tip.String()
+	} else {
+		gasPrice := tx.GasPrice
+		if gasPrice == nil {
+			if gasPrice, err = q.chain.SuggestGasPrice(ctx, wallet.ChainID); err != nil {
+				return nil, fmt.Errorf("suggest gas price: %w", err)
+			}
+		}
+		unsignedTx = types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: gasLimit, To: &to, Value: value, Data: calldata})
+		unsigned.GasPrice = gasPrice.String()
+	}
+
+	raw, err := unsignedTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("encode transaction: %w", err)
+	}
+	unsigned.Raw = hexutil.Encode(raw)
+	unsigned.SigningHash = types.LatestSignerForChainID(chainID).Hash(unsignedTx).Hex()
+	return unsigned, nil
+}
+
+// List returns the user's signing requests, newest first, optionally with one status
+func (q *SigningQueue) List(ctx context.Context, userID uuid.UUID, status SigningRequestStatus) ([]*SigningRequest, error) {
+	requests, err := q.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing requests: %w", err)
+	}
+	matched := make([]*SigningRequest, 0, len(requests))
+	for _, request := range requests {
+		q.expireIfStale(ctx, request)
+		if status == "" || request.Status == status {
+			matched = append(matched, request)
+		}
+	}
+	return matched, nil
+}
+
+// Get returns one of the user's signing requests
+func (q *SigningQueue) Get(ctx context.Context, userID, id uuid.UUID) (*SigningRequest, error) {
+	request, err := q.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing request: %w", err)
+	}
+	if request == nil || request.UserID != userID {
+		return nil, ErrSigningRequestNotFound
+	}
+	q.expireIfStale(ctx, request)
+	return request, nil
+}
+
+// Submit broadcasts the signed transaction the user's wallet returned for a pending request.
+// The transaction must be signed by the request's wallet and send the requested value and
+// data to the requested recipient on the requested chain; wallets may adjust the nonce, gas
+// and fees. A broadcast failure leaves the request pending so the user can sign again.
+func (q *SigningQueue) Submit(ctx context.Context, userID, id uuid.UUID, signed string) (*SigningRequest, error) {
+	request, err := q.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != SigningRequestPending {
+		return nil, fmt.Errorf("%w: %s", ErrSigningRequestClosed, request.Status)
+	}
+
+	tx, err := q.verifySigned(request, signed)
+	if err != nil {
+		q.audit(ctx, request, "rejected_signature", security.AuditResultDenied, map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := q.chain.Broadcast(ctx, request.Transaction.ChainID, tx); err != nil {
+		request.Error = err.Error()
+		request.UpdatedAt = q.now()
+		if saveErr := q.store.Save(ctx, request); saveErr != nil {
+			q.logger.Error(ctx, "Failed to save signing request", saveErr)
+		}
+		q.audit(ctx, request, "broadcast", security.AuditResultFailure, map[string]interface{}{"error": err.Error()})
+		return nil, fmt.Errorf("%w: %v", ErrBroadcastFailed, err)
+	}
+
+	request.Status = SigningRequestBroadcast
+	request.TxHash = tx.Hash().Hex()
+	request.Error = ""
+	request.UpdatedAt = q.now()
+	if err := q.store.Save(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to save signing request: %w", err)
+	}
+	if err := q.service.txRepo.MarkBroadcast(ctx, request.TransactionID, request.TxHash); err != nil {
+		q.logger.Error(ctx, "Failed to record broadcast transaction", err, map[string]interface{}{
+			"transaction_id": request.TransactionID.String(),
+			"tx_hash":        request.TxHash,
+		})
+	}
+	q.audit(ctx, request, "broadcast", security.AuditResultSuccess, map[string]interface{}{"tx_hash": request.TxHash})
+	return request, nil
+}
+
+// verifySigned decodes a signed transaction and checks it against the request
+func (q *SigningQueue) verifySigned(request *SigningRequest, signed string) (*types.Transaction, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(signed))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignedTransaction, err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignedTransaction, err)
+	}
+
+	expected := request.Transaction
+	// Transactions without replay protection could be replayed on other chains
+	if !tx.Protected() || tx.ChainId().Cmp(big.NewInt(int64(expected.ChainID))) != 0 {
+		return nil, fmt.Errorf("%w: not signed for chain %d", ErrInvalidSignedTransaction, expected.ChainID)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(int64(expected.ChainID))), tx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignedTransaction, err)
+	}
+	if sender != common.HexToAddress(expected.From) {
+		return nil, fmt.Errorf("%w: signed by %s, not %s", ErrInvalidSignedTransaction, sender.Hex(), expected.From)
+	}
+	if tx.To() == nil || *tx.To() != common.HexToAddress(expected.To) {
+		return nil, fmt.Errorf("%w: recipient differs from the request", ErrInvalidSignedTransaction)
+	}
+	if tx.Value().String() != expected.Value {
+		return nil, fmt.Errorf("%w: value differs from the request", ErrInvalidSignedTransaction)
+	}
+	if hexutil.Encode(tx.Data()) != hexutil.Encode(common.FromHex(expected.Data)) {
+		return nil, fmt.Errorf("%w: data differs from the request", ErrInvalidSignedTransaction)
+	}
+	return tx, nil
+}
+
+// Cancel withdraws a pending request; its transaction is marked cancelled
+func (q *SigningQueue) Cancel(ctx context.Context, userID, id uuid.UUID) (*SigningRequest, error) {
+	request, err := q.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != SigningRequestPending {
+		return nil, fmt.Errorf("%w: %s", ErrSigningRequestClosed, request.Status)
+	}
+	if err := q.close(ctx, request, SigningRequestCancelled, TxStatusCancelled, ""); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ExpireStale closes pending requests past their expiry and returns how many it closed
+func (q *SigningQueue) ExpireStale(ctx context.Context) int {
+	requests, err := q.store.ListPending(ctx)
+	if err != nil {
+		q.logger.Error(ctx, "Failed to list pending signing requests", err)
+		return 0
+	}
+	expired := 0
+	for _, request := range requests {
+		if q.expireIfStale(ctx, request) {
+			expired++
+		}
+	}
+	return expired
+}
+
+// expireIfStale closes a pending request past its expiry, reporting whether it did
+func (q *SigningQueue) expireIfStale(ctx context.Context, request *SigningRequest) bool {
+	if request.Status != SigningRequestPending || q.now().Before(request.ExpiresAt) {
+		return false
+	}
+	if err := q.close(ctx, request, SigningRequestExpired, TxStatusExpired, ""); err != nil {
+		q.logger.Error(ctx, "Failed to expire signing request", err, map[string]interface{}{
+			"request_id": request.ID.String(),
+		})
+		return false
+	}
+	return true
+}
+
+// close ends a pending request without a broadcast and updates its transaction's status
+func (q *SigningQueue) close(ctx context.Context, request *SigningRequest, status SigningRequestStatus, txStatus, reason string) error {
+	request.Status = status
+	request.Error = reason
+	request.UpdatedAt = q.now()
+	if err := q.store.Save(ctx, request); err != nil {
+		return fmt.Errorf("failed to save signing request: %w", err)
+	}
+	if err := q.service.txRepo.UpdateStatus(ctx, request.TransactionID, txStatus); err != nil {
+		q.logger.Error(ctx, "Failed to update transaction status", err, map[string]interface{}{
+			"transaction_id": request.TransactionID.String(),
+			"status":         txStatus,
+		})
+	}
+	var details map[string]interface{}
+	if reason != "" {
+		details = map[string]interface{}{"reason": reason}
+	}
+	q.audit(ctx, request, string(status), security.AuditResultSuccess, details)
+	return nil
+}
+
+// handleWalletConnectResponse takes the wallet app's answer to a pushed request: the signed
+// transaction, or the user's rejection
+func (q *SigningQueue) handleWalletConnectResponse(ctx context.Context, topic string, requestID int64, signed string, rejection error) error {
+	requests, err := q.store.ListPending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pending signing requests: %w", err)
+	}
+	for _, request := range requests {
+		if request.WalletConnectTopic != topic || request.WalletConnectRequestID != requestID {
+			continue
+		}
+		if rejection != nil {
+			return q.close(ctx, request, SigningRequestRejected, TxStatusFailed, rejection.Error())
+		}
+		_, err := q.Submit(ctx, request.UserID, request.ID, signed)
+		return err
+	}
+	return ErrSigningRequestNotFound
+}
+
+func (q *SigningQueue) audit(ctx context.Context, request *SigningRequest, action string, result security.AuditResult, extra map[string]interface{}) {
+	if q.auditor == nil {
+		return
+	}
+	details := map[string]interface{}{
+		"request_id":     request.ID.String(),
+		"transaction_id": request.TransactionID.String(),
+		"wallet_id":      request.WalletID.String(),
+		"chain_id":       request.Transaction.ChainID,
+		"channel":        string(request.Channel),
+		"to":             request.Transaction.To,
+		"value":          request.Transaction.Value,
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+	if err := q.auditor.LogTradingEvent(ctx, &request.UserID, signingAuditPrefix+action, result, details); err != nil {
+		q.logger.Error(ctx, "Failed to audit signing request", err, details)
+	}
+}
+
+const (
+	signingPendingKey = "web3:signing:pending"
+	// signingRetention is how long closed requests stay listed after they expire
+	signingRetention = 7 * 24 * time.Hour
+)
+
+// redisSigningRequestStore keeps signing requests in Redis, indexed by user and by pending
+// status, for a week past their expiry
+type redisSigningRequestStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisSigningRequestStore creates a signing request store shared through Redis
+func NewRedisSigningRequestStore(redis *database.RedisClient) SigningRequestStore {
+	return &redisSigningRequestStore{redis: redis}
+}
+
+func signingRequestKey(id uuid.UUID) string {
+	return "web3:signing:request:" + id.String()
+}
+
+func signingUserKey(userID uuid.UUID) string {
+	return "web3:signing:user:" + userID.String()
+}
+
+func (s *redisSigningRequestStore) Save(ctx context.Context, request *SigningRequest) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signing request: %w", err)
+	}
+	ttl := time.Until(request.ExpiresAt) + signingRetention
+
+	pipe := s.redis.Client.TxPipeline()
+	pipe.Set(ctx, signingRequestKey(request.ID), data, ttl)
+	pipe.ZAdd(ctx, signingUserKey(request.UserID), redis.Z{Score: float64(request.CreatedAt.UnixNano()), Member: request.ID.String()})
+	pipe.Expire(ctx, signingUserKey(request.UserID), ttl)
+	if request.Status == SigningRequestPending {
+		pipe.SAdd(ctx, signingPendingKey, request.ID.String())
+	} else {
+		pipe.SRem(ctx, signingPendingKey, request.ID.String())
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *redisSigningRequestStore) Get(ctx context.Context, id uuid.UUID) (*SigningRequest, error) {
+	data, err := s.redis.Client.Get(ctx, signingRequestKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var request SigningRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signing request: %w", err)
+	}
+	return &request, nil
+}
+
+// load reads the requests with the given IDs, skipping those that expired out of Redis
+func (s *redisSigningRequestStore) load(ctx context.Context, ids []string) ([]*SigningRequest, error) {
+	requests := make([]*SigningRequest, 0, len(ids))
+	for _, raw := range ids {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			continue
+		}
+		request, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if request != nil {
+			requests = append(requests, request)
+		}
+	}
+	return requests, nil
+}
+
+func (s *redisSigningRequestStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*SigningRequest, error) {
+	ids, err := s.redis.Client.ZRevRange(ctx, signingUserKey(userID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return s.load(ctx, ids)
+}
+
+func (s *redisSigningRequestStore) ListPending(ctx context.Context) ([]*SigningRequest, error) {
+	ids, err := s.redis.Client.SMembers(ctx, signingPendingKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	return s.load(ctx, ids)
+}
@@ -0,0 +1,337 @@
+package web3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySigningStore struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]SigningRequest
+}
+
+func newMemorySigningStore() *memorySigningStore {
+	return &memorySigningStore{requests: make(map[uuid.UUID]SigningRequest)}
+}
+
+func (m *memorySigningStore) Save(ctx context.Context, request *SigningRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[request.ID] = *request
+	return nil
+}
+
+func (m *memorySigningStore) Get(ctx context.Context, id uuid.UUID) (*SigningRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	request, ok := m.requests[id]
+	if !ok {
+		return nil, nil
+	}
+	return &request, nil
+}
+
+func (m *memorySigningStore) list(match func(*SigningRequest) bool) []*SigningRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var requests []*SigningRequest
+	for _, request := range m.requests {
+		request := request
+		if match(&request) {
+			requests = append(requests, &request)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].CreatedAt.After(requests[j].CreatedAt) })
+	return requests
+}
+
+func (m *memorySigningStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*SigningRequest, error) {
+	return m.list(func(r *SigningRequest) bool { return r.UserID == userID }), nil
+}
+
+func (m *memorySigningStore) ListPending(ctx context.Context) ([]*SigningRequest, error) {
+	return m.list(func(r *SigningRequest) bool { return r.Status == SigningRequestPending }), nil
+}
+
+type fakeSigningChain struct {
+	nonce        uint64
+	gas          uint64
+	gasPrice     *big.Int
+	broadcastErr error
+	broadcast    []*types.Transaction
+}
+
+func (f *fakeSigningChain) PendingNonce(ctx context.Context, chainID int, address string) (uint64, error) {
+	return f.nonce, nil
+}
+
+func (f *fakeSigningChain) EstimateGas(ctx context.Context, chainID int, msg ethereum.CallMsg) (uint64, error) {
+	return f.gas, nil
+}
+
+func (f *fakeSigningChain) SuggestGasPrice(ctx context.Context, chainID int) (*big.Int, error) {
+	return f.gasPrice, nil
+}
+
+func (f *fakeSigningChain) Broadcast(ctx context.Context, chainID int, tx *types.Transaction) error {
+	if f.broadcastErr != nil {
+		return f.broadcastErr
+	}
+	f.broadcast = append(f.broadcast, tx)
+	return nil
+}
+
+// statusTxRepo records the status changes of transactions
+type statusTxRepo struct {
+	mockTxRepo
+	mu       sync.Mutex
+	statuses map[uuid.UUID]string
+	hashes   map[uuid.UUID]string
+}
+
+func (r *statusTxRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[id] = status
+	return nil
+}
+
+func (r *statusTxRepo) MarkBroadcast(ctx context.Context, id uuid.UUID, txHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[id] = TxStatusPending
+	r.hashes[id] = txHash
+	return nil
+}
+
+type signingFixture struct {
+	service *Service
+	queue   *SigningQueue
+	store   *memorySigningStore
+	chain   *fakeSigningChain
+	txRepo  *statusTxRepo
+	key     *ecdsa.PrivateKey
+	wallet  *Wallet
+	now     time.Time
+}
+
+func newSigningFixture(t *testing.T) *signingFixture {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	s := newServiceWithMocks()
+	txRepo := &statusTxRepo{statuses: make(map[uuid.UUID]string), hashes: make(map[uuid.UUID]string)}
+	s.txRepo = txRepo
+	wallet := &Wallet{ID: uuid.New(), UserID: uuid.New(), Address: crypto.PubkeyToAddress(key.PublicKey).Hex(), ChainID: 1, WalletType: "ledger"}
+	s.walletRepo.(*mockWalletRepo).getByID = map[uuid.UUID]*Wallet{wallet.ID: wallet}
+
+	f := &signingFixture{
+		service: s,
+		store:   newMemorySigningStore(),
+		chain:   &fakeSigningChain{nonce: 7, gas: 21000, gasPrice: big.NewInt(30_000_000_000)},
+		txRepo:  txRepo,
+		key:     key,
+		wallet:  wallet,
+		now:     time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.queue = NewSigningQueue(s, f.store, SigningConfig{RequestTTL: 10 * time.Minute})
+	f.queue.chain = f.chain
+	f.queue.now = func() time.Time { return f.now }
+	s.SetSigningQueue(f.queue)
+	return f
+}
+
+func (f *signingFixture) createTransaction(t *testing.T) *TransactionResponse {
+	t.Helper()
+	resp, err := f.service.CreateTransaction(context.Background(), f.wallet.UserID, TransactionRequest{
+		WalletID:  f.wallet.ID,
+		ToAddress: "0x000000000000000000000000000000000000dEaD",
+		Value:     big.NewInt(1_000_000_000_000_000),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.SigningRequest)
+	return resp
+}
+
+// sign signs the request's transaction as the wallet would, applying changes to it first
+func (f *signingFixture) sign(t *testing.T, key *ecdsa.PrivateKey, request *SigningRequest, change func(*types.LegacyTx)) string {
+	t.Helper()
+	unsigned := request.Transaction
+	value, _ := new(big.Int).SetString(unsigned.Value, 10)
+	gasPrice, _ := new(big.Int).SetString(unsigned.GasPrice, 10)
+	to := common.HexToAddress(unsigned.To)
+	legacy := &types.LegacyTx{Nonce: unsigned.Nonce, GasPrice: gasPrice, Gas: unsigned.GasLimit, To: &to, Value: value, Data: common.FromHex(unsigned.Data)}
+	if change != nil {
+		change(legacy)
+	}
+	signed, err := types.SignTx(types.NewTx(legacy), types.LatestSignerForChainID(big.NewInt(int64(unsigned.ChainID))), key)
+	require.NoError(t, err)
+	raw, err := signed.MarshalBinary()
+	require.NoError(t, err)
+	return hexutil.Encode(raw)
+}
+
+func TestCreateTransaction_AwaitsSignature(t *testing.T) {
+	f := newSigningFixture(t)
+	resp := f.createTransaction(t)
+
+	assert.Equal(t, TxStatusAwaitingSignature, resp.Status)
+	assert.Empty(t, resp.TxHash)
+	request := resp.SigningRequest
+	assert.Equal(t, SigningRequestPending, request.Status)
+	assert.Equal(t, SigningChannelQueue, request.Channel)
+	assert.Equal(t, resp.Transaction.ID, request.TransactionID)
+	assert.Equal(t, f.now.Add(10*time.Minute), request.ExpiresAt)
+
+	unsigned := request.Transaction
+	assert.Equal(t, uint64(7), unsigned.Nonce)
+	assert.Equal(t, uint64(21000), unsigned.GasLimit)
+	assert.Equal(t, "30000000000", unsigned.GasPrice)
+	assert.Equal(t, "1000000000000000", unsigned.Value)
+	assert.NotEmpty(t, unsigned.SigningHash)
+
+	// The signing hash is what a hardware wallet signs for the raw unsigned transaction
+	var tx types.Transaction
+	require.NoError(t, tx.UnmarshalBinary(common.FromHex(unsigned.Raw)))
+	assert.Equal(t, unsigned.SigningHash, types.LatestSignerForChainID(big.NewInt(1)).Hash(&tx).Hex())
+
+	listed, err := f.queue.List(context.Background(), f.wallet.UserID, SigningRequestPending)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, request.ID, listed[0].ID)
+}
+
+func TestSigningQueue_Submit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("BroadcastsSignedTransaction", func(t *testing.T) {
+		f := newSigningFixture(t)
+		request := f.createTransaction(t).SigningRequest
+		// Wallets may pick their own fees
+		signed := f.sign(t, f.key, request, func(tx *types.LegacyTx) { tx.GasPrice = big.NewInt(40_000_000_000) })
+
+		result, err := f.queue.Submit(ctx, f.wallet.UserID, request.ID, signed)
+		require.NoError(t, err)
+		assert.Equal(t, SigningRequestBroadcast, result.Status)
+		require.Len(t, f.chain.broadcast, 1)
+		assert.Equal(t, f.chain.broadcast[0].Hash().Hex(), result.TxHash)
+		assert.Equal(t, TxStatusPending, f.txRepo.statuses[request.TransactionID])
+		assert.Equal(t, result.TxHash, f.txRepo.hashes[request.TransactionID])
+
+		_, err = f.queue.Submit(ctx, f.wallet.UserID, request.ID, signed)
+		assert.ErrorIs(t, err, ErrSigningRequestClosed)
+	})
+
+	t.Run("RejectsMismatchedTransactions", func(t *testing.T) {
+		f := newSigningFixture(t)
+		request := f.createTransaction(t).SigningRequest
+		otherKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		attacker := common.HexToAddress("0x00000000000000000000000000000000000bad00")
+
+		cases := map[string]string{
+			"WrongSigner":    f.sign(t, otherKey, request, nil),
+			"WrongRecipient": f.sign(t, f.key, request, func(tx *types.LegacyTx) { tx.To = &attacker }),
+			"WrongValue":     f.sign(t, f.key, request, func(tx *types.LegacyTx) { tx.Value = big.NewInt(5) }),
+			"WrongData":      f.sign(t, f.key, request, func(tx *types.LegacyTx) { tx.Data = []byte{0x01} }),
+			"Garbage":        "0xdeadbeef",
+		}
+		for name, signed := range cases {
+			t.Run(name, func(t *testing.T) {
+				_, err := f.queue.Submit(ctx, f.wallet.UserID, request.ID, signed)
+				assert.ErrorIs(t, err, ErrInvalidSignedTransaction)
+			})
+		}
+
+		unprotected, err := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(1), Gas: 21000, To: &attacker}), types.HomesteadSigner{}, f.key)
+		require.NoError(t, err)
+		raw, err := unprotected.MarshalBinary()
+		require.NoError(t, err)
+		_, err = f.queue.Submit(ctx, f.wallet.UserID, request.ID, hexutil.Encode(raw))
+		assert.ErrorIs(t, err, ErrInvalidSignedTransaction)
+
+		assert.Empty(t, f.chain.broadcast)
+		stored, err := f.queue.Get(ctx, f.wallet.UserID, request.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SigningRequestPending, stored.Status)
+	})
+
+	t.Run("BroadcastFailureKeepsRequestPending", func(t *testing.T) {
+		f := newSigningFixture(t)
+		request := f.createTransaction(t).SigningRequest
+		f.chain.broadcastErr = errors.New("nonce too low")
+
+		_, err := f.queue.Submit(ctx, f.wallet.UserID, request.ID, f.sign(t, f.key, request, nil))
+		assert.ErrorIs(t, err, ErrBroadcastFailed)
+		stored, err := f.queue.Get(ctx, f.wallet.UserID, request.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SigningRequestPending, stored.Status)
+		assert.Contains(t, stored.Error, "nonce too low")
+	})
+
+	t.Run("OtherUsersRequest", func(t *testing.T) {
+		f := newSigningFixture(t)
+		request := f.createTransaction(t).SigningRequest
+		_, err := f.queue.Submit(ctx, uuid.New(), request.ID, f.sign(t, f.key, request, nil))
+		assert.ErrorIs(t, err, ErrSigningRequestNotFound)
+	})
+}
+
+func TestSigningQueue_Cancel(t *testing.T) {
+	ctx := context.Background()
+	f := newSigningFixture(t)
+	request := f.createTransaction(t).SigningRequest
+
+	cancelled, err := f.queue.Cancel(ctx, f.wallet.UserID, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SigningRequestCancelled, cancelled.Status)
+	assert.Equal(t, TxStatusCancelled, f.txRepo.statuses[request.TransactionID])
+
+	_, err = f.queue.Cancel(ctx, f.wallet.UserID, request.ID)
+	assert.ErrorIs(t, err, ErrSigningRequestClosed)
+	_, err = f.queue.Submit(ctx, f.wallet.UserID, request.ID, f.sign(t, f.key, request, nil))
+	assert.ErrorIs(t, err, ErrSigningRequestClosed)
+}
+
+func TestSigningQueue_Expiry(t *testing.T) {
+	ctx := context.Background()
+	f := newSigningFixture(t)
+	first := f.createTransaction(t).SigningRequest
+	f.now = f.now.Add(5 * time.Minute)
+	second := f.createTransaction(t).SigningRequest
+
+	f.now = f.now.Add(6 * time.Minute)
+	assert.Equal(t, 1, f.queue.ExpireStale(ctx))
+	assert.Equal(t, TxStatusExpired, f.txRepo.statuses[first.TransactionID])
+
+	pending, err := f.queue.List(ctx, f.wallet.UserID, SigningRequestPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+
+	_, err = f.queue.Submit(ctx, f.wallet.UserID, first.ID, f.sign(t, f.key, first, nil))
+	assert.ErrorIs(t, err, ErrSigningRequestClosed)
+
+	// Reads close requests the sweeper hasn't reached yet
+	f.now = f.now.Add(5 * time.Minute)
+	stored, err := f.queue.Get(ctx, f.wallet.UserID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SigningRequestExpired, stored.Status)
+	assert.Equal(t, TxStatusExpired, f.txRepo.statuses[second.TransactionID])
+}
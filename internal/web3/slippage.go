@@ -0,0 +1,63 @@
+package web3
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/shopspring/decimal"
+)
+
+// ErrSlippageTooHigh is returned when the order book cannot absorb a trade within the
+// configured slippage tolerance
+var ErrSlippageTooHigh = errors.New("estimated slippage exceeds tolerance")
+
+// slippageBookDepth is the number of levels per side used for slippage estimates
+const slippageBookDepth = 500
+
+// OrderBookSource provides level-2 depth for slippage estimation
+type OrderBookSource interface {
+	GetOrderBook(symbol string, depth int) (*realtime.OrderBook, error)
+}
+
+// SetOrderBookSource sets the order books used to estimate trade slippage
+func (t *TradingEngine) SetOrderBookSource(books OrderBookSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.orderBooks = books
+}
+
+// EstimateSlippage walks the merged order book for a market symbol such as ETHUSDT and returns
+// the expected average fill of a buy or sell of quantity
+func (t *TradingEngine) EstimateSlippage(symbol string, action TradingAction, quantity decimal.Decimal) (*realtime.FillEstimate, error) {
+	t.mu.RLock()
+	books := t.orderBooks
+	t.mu.RUnlock()
+	if books == nil {
+		return nil, fmt.Errorf("%w: no order book source configured", realtime.ErrOrderBookUnavailable)
+	}
+
+	book, err := books.GetOrderBook(symbol, slippageBookDepth)
+	if err != nil {
+		return nil, err
+	}
+	return book.EstimateFill(string(action), quantity)
+}
+
+// CheckSlippage estimates slippage and rejects trades the book cannot fill within
+// TradingConfig.SlippageTolerance. The estimate is returned alongside ErrSlippageTooHigh.
+func (t *TradingEngine) CheckSlippage(symbol string, action TradingAction, quantity decimal.Decimal) (*realtime.FillEstimate, error) {
+	estimate, err := t.EstimateSlippage(symbol, action, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	tolerance := t.config.SlippageTolerance.Mul(decimal.NewFromInt(10000))
+	if !estimate.FullyFilled {
+		return estimate, fmt.Errorf("%w: book depth fills %s of %s", ErrSlippageTooHigh, estimate.FilledQuantity, quantity)
+	}
+	if estimate.SlippageBps.GreaterThan(tolerance) {
+		return estimate, fmt.Errorf("%w: %s bps over %s bps", ErrSlippageTooHigh, estimate.SlippageBps.StringFixed(2), tolerance.StringFixed(2))
+	}
+	return estimate, nil
+}
@@ -0,0 +1,90 @@
+package web3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/realtime"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticBook serves the same order book for every symbol
+type staticBook struct {
+	asks []realtime.OrderBookLevel
+}
+
+func (b staticBook) GetOrderBook(symbol string, depth int) (*realtime.OrderBook, error) {
+	return &realtime.OrderBook{Symbol: symbol, Asks: b.asks}, nil
+}
+
+func asks(levels ...int64) []realtime.OrderBookLevel {
+	book := make([]realtime.OrderBookLevel, 0, len(levels)/2)
+	for i := 0; i < len(levels); i += 2 {
+		book = append(book, realtime.OrderBookLevel{
+			Price: decimal.NewFromInt(levels[i]),
+			Size:  decimal.NewFromInt(levels[i+1]).Div(decimal.NewFromInt(100)),
+		})
+	}
+	return book
+}
+
+func TestExecuteTrade_SlippageTolerance(t *testing.T) {
+	ctx := context.Background()
+
+	// $1000 of ETH at 2000 walks the asks for half a token against the default 50 bps tolerance
+	tests := []struct {
+		name     string
+		books    OrderBookSource
+		slippage string // empty when the trade carries no estimate
+		rejected bool
+	}{
+		{name: "no order books", slippage: ""},
+		{name: "deep book", books: staticBook{asks: asks(2000, 100)}, slippage: "0"},
+		{name: "within tolerance", books: staticBook{asks: asks(2000, 40, 2010, 100)}, slippage: "10"},
+		{name: "beyond tolerance", books: staticBook{asks: asks(2000, 25, 2040, 100)}, rejected: true},
+		{name: "book too thin", books: staticBook{asks: asks(2000, 10)}, rejected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newProtectionTestEngine()
+			if tt.books != nil {
+				engine.SetOrderBookSource(tt.books)
+			}
+			portfolio, err := engine.CreatePortfolio(ctx, uuid.New(), "slippage", decimal.NewFromInt(10000), RiskProfile{Level: "moderate"})
+			require.NoError(t, err)
+
+			position, err := engine.executeTrade(ctx, portfolio, ethSignal(), decimal.NewFromInt(1000))
+			trades, historyErr := engine.GetTradeHistory(portfolio.ID)
+			require.NoError(t, historyErr)
+			if tt.rejected {
+				assert.ErrorIs(t, err, ErrSlippageTooHigh)
+				assert.Nil(t, position)
+				assert.Empty(t, trades)
+				assert.Empty(t, portfolio.ActivePositions)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, trades, 1)
+			if tt.slippage == "" {
+				assert.Nil(t, trades[0].SlippageBps)
+				return
+			}
+			require.NotNil(t, trades[0].SlippageBps)
+			assert.Equal(t, tt.slippage, trades[0].SlippageBps.StringFixed(0))
+		})
+	}
+}
+
+func TestCheckSlippage_ReturnsEstimate(t *testing.T) {
+	engine := newProtectionTestEngine()
+	engine.SetOrderBookSource(staticBook{asks: asks(2000, 25, 2040, 100)})
+
+	estimate, err := engine.CheckSlippage("ETH-USD", ActionBuy, decimal.NewFromFloat(0.5))
+	assert.ErrorIs(t, err, ErrSlippageTooHigh)
+	require.NotNil(t, estimate, "rejections carry the estimate")
+	assert.True(t, estimate.AveragePrice.Equal(decimal.NewFromInt(2020)), "average %s", estimate.AveragePrice)
+}
@@ -0,0 +1,304 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Trade history entries that move tokens without trading them
+const (
+	// TradeSideTransfer moves tokens between the owner's own wallets; it is never a disposal
+	TradeSideTransfer = "transfer"
+	// TradeSideReceive acquires tokens without a trade, such as an airdrop or a deposit from
+	// an outside wallet. Its price is the cost basis per unit when known.
+	TradeSideReceive = "receive"
+)
+
+// Holding periods of realized gains
+const (
+	HoldingPeriodShortTerm = "short_term"
+	HoldingPeriodLongTerm  = "long_term"
+)
+
+// LotMethod selects which tax lots a sale consumes first
+type LotMethod string
+
+const (
+	LotMethodFIFO LotMethod = "FIFO" // oldest lot first
+	LotMethodLIFO LotMethod = "LIFO" // newest lot first
+	LotMethodHIFO LotMethod = "HIFO" // highest cost basis first
+)
+
+var (
+	// ErrInvalidLotMethod is returned for lot methods other than FIFO, LIFO or HIFO
+	ErrInvalidLotMethod = errors.New("invalid lot method")
+	// ErrInvalidTransfer is returned for transfers without a symbol, a positive quantity or
+	// a destination
+	ErrInvalidTransfer = errors.New("invalid transfer")
+)
+
+// ParseLotMethod validates a lot method, defaulting to FIFO when empty
+func ParseLotMethod(method string) (LotMethod, error) {
+	switch LotMethod(strings.ToUpper(strings.TrimSpace(method))) {
+	case "", LotMethodFIFO:
+		return LotMethodFIFO, nil
+	case LotMethodLIFO:
+		return LotMethodLIFO, nil
+	case LotMethodHIFO:
+		return LotMethodHIFO, nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected FIFO, LIFO or HIFO", ErrInvalidLotMethod, method)
+	}
+}
+
+// AssetTransfer records tokens moving into a portfolio's wallets without a trade. Internal
+// transfers come from another of the owner's wallets and keep their original lots; other
+// transfers open a new lot at CostBasis, which is flagged as missing when not given.
+type AssetTransfer struct {
+	Symbol      string           `json:"symbol"`
+	Quantity    decimal.Decimal  `json:"quantity"`
+	FromAddress string           `json:"from_address"`
+	ToAddress   string           `json:"to_address"`
+	CostBasis   *decimal.Decimal `json:"cost_basis,omitempty"` // per unit
+	ExecutedAt  time.Time        `json:"executed_at"`
+	Internal    bool             `json:"internal"`
+}
+
+// TaxLot is a quantity of a token acquired at one time and cost. CostBasis is per unit and
+// includes the acquisition fees.
+type TaxLot struct {
+	ID               uuid.UUID       `json:"id"` // the acquiring trade
+	Symbol           string          `json:"symbol"`
+	AcquiredAt       time.Time       `json:"acquired_at"`
+	Source           string          `json:"source"` // buy or receive
+	Quantity         decimal.Decimal `json:"quantity"`
+	Remaining        decimal.Decimal `json:"remaining"`
+	CostBasis        decimal.Decimal `json:"cost_basis"`
+	CostBasisMissing bool            `json:"cost_basis_missing"`
+}
+
+// LotDisposal is the part of a sale matched against one lot. A sale larger than the open lots
+// leaves a disposal without a lot, whose cost basis is missing. Gains of disposals with a
+// missing cost basis are not computed.
+type LotDisposal struct {
+	TradeID          uuid.UUID       `json:"trade_id"`
+	LotID            *uuid.UUID      `json:"lot_id,omitempty"`
+	Symbol           string          `json:"symbol"`
+	AcquiredAt       *time.Time      `json:"acquired_at,omitempty"`
+	DisposedAt       time.Time       `json:"disposed_at"`
+	Quantity         decimal.Decimal `json:"quantity"`
+	Proceeds         decimal.Decimal `json:"proceeds"`   // net of fees
+	CostBasis        decimal.Decimal `json:"cost_basis"` // total for the quantity
+	Gain             decimal.Decimal `json:"gain"`
+	HoldingPeriod    string          `json:"holding_period,omitempty"`
+	CostBasisMissing bool            `json:"cost_basis_missing"`
+}
+
+// LotLedger is the result of matching a trade history against tax lots
+type LotLedger struct {
+	Method    LotMethod     `json:"method"`
+	Lots      []*TaxLot     `json:"lots"` // lots with a remaining quantity, oldest first
+	Disposals []LotDisposal `json:"disposals"`
+	Transfers int           `json:"transfers"` // internal transfers, which dispose nothing
+}
+
+// MatchTaxLots replays trades, oldest first, opening a lot for every buy and receipt and
+// consuming lots for every sell in the order of method. Internal transfers are skipped.
+func MatchTaxLots(trades []TradeRecord, method LotMethod) *LotLedger {
+	ledger := &LotLedger{Method: method, Lots: make([]*TaxLot, 0), Disposals: make([]LotDisposal, 0)}
+	open := make(map[string][]*TaxLot)
+
+	ordered := make([]TradeRecord, len(trades))
+	copy(ordered, trades)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].ExecutedAt.Before(ordered[j].ExecutedAt)
+	})
+
+	for _, trade := range ordered {
+		symbol := strings.ToUpper(trade.Symbol)
+		switch trade.Side {
+		case TradeSideTransfer:
+			ledger.Transfers++
+		case TradeSideBuy, TradeSideReceive:
+			if !trade.Quantity.IsPositive() {
+				continue
+			}
+			lot := &TaxLot{
+				ID:               trade.ID,
+				Symbol:           symbol,
+				AcquiredAt:       trade.ExecutedAt,
+				Source:           trade.Side,
+				Quantity:         trade.Quantity,
+				Remaining:        trade.Quantity,
+				CostBasis:        trade.Price.Add(trade.Fees.Div(trade.Quantity)),
+				CostBasisMissing: trade.CostBasisMissing,
+			}
+			open[symbol] = append(open[symbol], lot)
+		case TradeSideSell:
+			if !trade.Quantity.IsPositive() {
+				continue
+			}
+			lots := open[symbol]
+			sortLots(lots, method)
+			ledger.Disposals = append(ledger.Disposals, disposeLots(trade, symbol, lots)...)
+			open[symbol] = remainingLots(lots)
+		}
+	}
+
+	for _, lots := range open {
+		ledger.Lots = append(ledger.Lots, lots...)
+	}
+	sort.SliceStable(ledger.Lots, func(i, j int) bool {
+		return ledger.Lots[i].AcquiredAt.Before(ledger.Lots[j].AcquiredAt)
+	})
+	return ledger
+}
+
+// disposeLots matches a sale against lots in their order. Proceeds net of the sale's fees are
+// shared across the matched lots by quantity.
+func disposeLots(trade TradeRecord, symbol string, lots []*TaxLot) []LotDisposal {
+	disposals := make([]LotDisposal, 0, 1)
+	unitProceeds := trade.Price.Sub(trade.Fees.Div(trade.Quantity))
+	remaining := trade.Quantity
+
+	for _, lot := range lots {
+		if !remaining.IsPositive() {
+			break
+		}
+		if !lot.Remaining.IsPositive() {
+			continue
+		}
+		quantity := decimal.Min(remaining, lot.Remaining)
+		lot.Remaining = lot.Remaining.Sub(quantity)
+		remaining = remaining.Sub(quantity)
+
+		lotID, acquiredAt := lot.ID, lot.AcquiredAt
+		disposal := LotDisposal{
+			TradeID:          trade.ID,
+			LotID:            &lotID,
+			Symbol:           symbol,
+			AcquiredAt:       &acquiredAt,
+			DisposedAt:       trade.ExecutedAt,
+			Quantity:         quantity,
+			Proceeds:         unitProceeds.Mul(quantity),
+			HoldingPeriod:    holdingPeriod(lot.AcquiredAt, trade.ExecutedAt),
+			CostBasisMissing: lot.CostBasisMissing,
+		}
+		if !lot.CostBasisMissing {
+			disposal.CostBasis = lot.CostBasis.Mul(quantity)
+			disposal.Gain = disposal.Proceeds.Sub(disposal.CostBasis)
+		}
+		disposals = append(disposals, disposal)
+	}
+
+	// Selling more than the lots hold leaves quantity of unknown origin
+	if remaining.IsPositive() {
+		disposals = append(disposals, LotDisposal{
+			TradeID:          trade.ID,
+			Symbol:           symbol,
+			DisposedAt:       trade.ExecutedAt,
+			Quantity:         remaining,
+			Proceeds:         unitProceeds.Mul(remaining),
+			CostBasisMissing: true,
+		})
+	}
+	return disposals
+}
+
+// sortLots orders lots in the order a sale consumes them
+func sortLots(lots []*TaxLot, method LotMethod) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		switch method {
+		case LotMethodLIFO:
+			return lots[i].AcquiredAt.After(lots[j].AcquiredAt)
+		case LotMethodHIFO:
+			return lots[i].CostBasis.GreaterThan(lots[j].CostBasis)
+		default:
+			return lots[i].AcquiredAt.Before(lots[j].AcquiredAt)
+		}
+	})
+}
+
+func remainingLots(lots []*TaxLot) []*TaxLot {
+	kept := lots[:0]
+	for _, lot := range lots {
+		if lot.Remaining.IsPositive() {
+			kept = append(kept, lot)
+		}
+	}
+	return kept
+}
+
+// holdingPeriod is long term for lots held more than a year
+func holdingPeriod(acquiredAt, disposedAt time.Time) string {
+	if disposedAt.After(acquiredAt.AddDate(1, 0, 0)) {
+		return HoldingPeriodLongTerm
+	}
+	return HoldingPeriodShortTerm
+}
+
+// RecordTransfer adds tokens moved into the portfolio's wallets to its trade history. Internal
+// transfers are recorded as transfers, which keep the lots they came from; anything else is a
+// receipt opening a new lot.
+func (t *TradingEngine) RecordTransfer(ctx context.Context, portfolioID uuid.UUID, transfer AssetTransfer) (*TradeRecord, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(transfer.Symbol))
+	if symbol == "" || !transfer.Quantity.IsPositive() || strings.TrimSpace(transfer.ToAddress) == "" {
+		return nil, fmt.Errorf("%w: symbol, positive quantity and to_address are required", ErrInvalidTransfer)
+	}
+	if transfer.CostBasis != nil && transfer.CostBasis.IsNegative() {
+		return nil, fmt.Errorf("%w: cost basis must not be negative", ErrInvalidTransfer)
+	}
+	if transfer.ExecutedAt.IsZero() {
+		transfer.ExecutedAt = time.Now()
+	}
+
+	trade := TradeRecord{
+		ID:          uuid.New(),
+		PortfolioID: portfolioID,
+		Symbol:      symbol,
+		Side:        TradeSideReceive,
+		Quantity:    transfer.Quantity,
+		Fees:        decimal.Zero,
+		RealizedPnL: decimal.Zero,
+		ExecutedAt:  transfer.ExecutedAt,
+		FromAddress: transfer.FromAddress,
+		ToAddress:   transfer.ToAddress,
+	}
+	switch {
+	case transfer.Internal:
+		trade.Side = TradeSideTransfer
+	case transfer.CostBasis != nil:
+		trade.Price = *transfer.CostBasis
+	default:
+		trade.CostBasisMissing = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.portfolios[portfolioID]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioID.String())
+	}
+	t.trades[portfolioID] = append(t.trades[portfolioID], trade)
+	return &trade, nil
+}
+
+// OwnsAddress reports whether address is one of the user's wallets on any chain
+func (s *Service) OwnsAddress(ctx context.Context, userID uuid.UUID, address string) (bool, error) {
+	wallets, err := s.allWallets(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, wallet := range wallets {
+		if strings.EqualFold(wallet.Address, strings.TrimSpace(address)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
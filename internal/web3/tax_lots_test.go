@@ -0,0 +1,153 @@
+package web3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lotTrade(side string, quantity, price int64, executedAt time.Time) TradeRecord {
+	return TradeRecord{
+		ID:         uuid.New(),
+		Symbol:     "ETH",
+		Side:       side,
+		Quantity:   decimal.NewFromInt(quantity),
+		Price:      decimal.NewFromInt(price),
+		ExecutedAt: executedAt,
+	}
+}
+
+func TestMatchTaxLots(t *testing.T) {
+	start := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	trades := []TradeRecord{
+		lotTrade(TradeSideBuy, 1, 1000, start),
+		lotTrade(TradeSideBuy, 1, 3000, start.AddDate(0, 6, 0)),
+		lotTrade(TradeSideBuy, 1, 2000, start.AddDate(0, 9, 0)),
+		lotTrade(TradeSideTransfer, 3, 0, start.AddDate(0, 10, 0)),
+		lotTrade(TradeSideSell, 2, 2500, start.AddDate(1, 1, 0)),
+	}
+
+	cases := map[LotMethod]struct {
+		costBasis []int64
+		period    []string
+	}{
+		LotMethodFIFO: {[]int64{1000, 3000}, []string{HoldingPeriodLongTerm, HoldingPeriodShortTerm}},
+		LotMethodLIFO: {[]int64{2000, 3000}, []string{HoldingPeriodShortTerm, HoldingPeriodShortTerm}},
+		LotMethodHIFO: {[]int64{3000, 2000}, []string{HoldingPeriodShortTerm, HoldingPeriodShortTerm}},
+	}
+	for method, expected := range cases {
+		t.Run(string(method), func(t *testing.T) {
+			ledger := MatchTaxLots(trades, method)
+
+			require.Len(t, ledger.Disposals, 2)
+			for i, disposal := range ledger.Disposals {
+				assert.True(t, disposal.CostBasis.Equal(decimal.NewFromInt(expected.costBasis[i])), "cost basis %s", disposal.CostBasis)
+				assert.True(t, disposal.Gain.Equal(decimal.NewFromInt(2500-expected.costBasis[i])))
+				assert.Equal(t, expected.period[i], disposal.HoldingPeriod)
+			}
+			require.Len(t, ledger.Lots, 1)
+			assert.True(t, ledger.Lots[0].Remaining.Equal(decimal.NewFromInt(1)))
+			assert.Equal(t, 1, ledger.Transfers)
+		})
+	}
+}
+
+func TestMatchTaxLots_MissingCostBasis(t *testing.T) {
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	airdrop := lotTrade(TradeSideReceive, 1, 0, start)
+	airdrop.CostBasisMissing = true
+	buy := lotTrade(TradeSideBuy, 1, 100, start.Add(time.Hour))
+	buy.Fees = decimal.NewFromInt(2)
+	sell := lotTrade(TradeSideSell, 3, 150, start.Add(2*time.Hour))
+	sell.Fees = decimal.NewFromInt(3)
+
+	ledger := MatchTaxLots([]TradeRecord{sell, buy, airdrop}, LotMethodFIFO)
+
+	require.Len(t, ledger.Disposals, 3)
+	assert.True(t, ledger.Disposals[0].CostBasisMissing)
+	assert.True(t, ledger.Disposals[0].Gain.IsZero())
+	assert.True(t, ledger.Disposals[0].Proceeds.Equal(decimal.NewFromInt(149)))
+
+	assert.False(t, ledger.Disposals[1].CostBasisMissing)
+	assert.True(t, ledger.Disposals[1].Gain.Equal(decimal.NewFromInt(47)), "fees adjust basis and proceeds")
+
+	// Selling more than was acquired is flagged instead of assuming a zero basis
+	assert.True(t, ledger.Disposals[2].CostBasisMissing)
+	assert.Nil(t, ledger.Disposals[2].LotID)
+	assert.Empty(t, ledger.Lots)
+}
+
+func TestMatchTaxLots_EngineTrades(t *testing.T) {
+	ctx := context.Background()
+	engine := newProtectionTestEngine()
+	portfolio, err := engine.CreatePortfolio(ctx, uuid.New(), "lots", decimal.NewFromInt(10000), RiskProfile{Level: "moderate"})
+	require.NoError(t, err)
+
+	// $1000 of ETH at 2000 buys half a token; half the position is sold at 2100, the rest at 2200
+	position, err := engine.executeTrade(ctx, portfolio, ethSignal(), decimal.NewFromInt(1000))
+	require.NoError(t, err)
+	engine.updatePortfolioAfterTrade(ctx, portfolio, position)
+	engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2100))
+	half := decimal.NewFromFloat(0.5)
+	_, err = engine.ReducePosition(ctx, position.ID, PartialClose{Percentage: &half}, "trim")
+	require.NoError(t, err)
+	engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2200))
+	require.NoError(t, engine.ClosePosition(ctx, position.ID, "manual"))
+
+	trades, err := engine.GetTradeHistory(portfolio.ID)
+	require.NoError(t, err)
+	require.Len(t, trades, 3)
+	assert.True(t, trades[0].Quantity.Equal(half), "quantity %s", trades[0].Quantity)
+
+	ledger := MatchTaxLots(trades, LotMethodFIFO)
+	require.Len(t, ledger.Disposals, 2)
+	quarter := decimal.NewFromFloat(0.25)
+	for i, gain := range []int64{25, 50} {
+		disposal := ledger.Disposals[i]
+		assert.True(t, disposal.Quantity.Equal(quarter), "quantity %s", disposal.Quantity)
+		assert.True(t, disposal.CostBasis.Equal(decimal.NewFromInt(500)), "cost basis %s", disposal.CostBasis)
+		assert.True(t, disposal.Gain.Equal(decimal.NewFromInt(gain)), "gain %s", disposal.Gain)
+		assert.False(t, disposal.CostBasisMissing)
+	}
+	assert.Empty(t, ledger.Lots, "the sales consume the whole lot")
+}
+
+func TestRecordTransfer(t *testing.T) {
+	ctx := context.Background()
+	engine := newProtectionTestEngine()
+	portfolio, err := engine.CreatePortfolio(ctx, uuid.New(), "lots", decimal.NewFromInt(1000), RiskProfile{Level: "moderate"})
+	require.NoError(t, err)
+
+	basis := decimal.NewFromInt(5)
+	received, err := engine.RecordTransfer(ctx, portfolio.ID, AssetTransfer{Symbol: "op", Quantity: decimal.NewFromInt(10), ToAddress: "0xabc", CostBasis: &basis})
+	require.NoError(t, err)
+	assert.Equal(t, TradeSideReceive, received.Side)
+	assert.Equal(t, "OP", received.Symbol)
+
+	airdrop, err := engine.RecordTransfer(ctx, portfolio.ID, AssetTransfer{Symbol: "ARB", Quantity: decimal.NewFromInt(100), ToAddress: "0xabc"})
+	require.NoError(t, err)
+	assert.True(t, airdrop.CostBasisMissing)
+
+	moved, err := engine.RecordTransfer(ctx, portfolio.ID, AssetTransfer{Symbol: "OP", Quantity: decimal.NewFromInt(10), FromAddress: "0xdef", ToAddress: "0xabc", Internal: true})
+	require.NoError(t, err)
+	assert.Equal(t, TradeSideTransfer, moved.Side)
+
+	_, err = engine.RecordTransfer(ctx, portfolio.ID, AssetTransfer{Symbol: "OP", Quantity: decimal.Zero, ToAddress: "0xabc"})
+	assert.ErrorIs(t, err, ErrInvalidTransfer)
+	_, err = engine.RecordTransfer(ctx, uuid.New(), AssetTransfer{Symbol: "OP", Quantity: decimal.NewFromInt(1), ToAddress: "0xabc"})
+	assert.Error(t, err)
+
+	trades, err := engine.GetTradeHistory(portfolio.ID)
+	require.NoError(t, err)
+	ledger := MatchTaxLots(trades, LotMethodFIFO)
+	assert.Len(t, ledger.Lots, 2)
+	assert.Equal(t, 1, ledger.Transfers)
+
+	_, err = ParseLotMethod("average")
+	assert.ErrorIs(t, err, ErrInvalidLotMethod)
+}
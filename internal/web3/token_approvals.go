@@ -0,0 +1,607 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+)
+
+// ErrWalletNotFound is returned for wallets that don't exist or belong to another user
+var ErrWalletNotFound = errors.New("wallet not found")
+
+// ApprovalRisk rates an outstanding token approval
+type ApprovalRisk string
+
+const (
+	ApprovalRiskLow      ApprovalRisk = "low"
+	ApprovalRiskMedium   ApprovalRisk = "medium"   // unlimited, or to an unverified or new contract
+	ApprovalRiskHigh     ApprovalRisk = "high"     // unlimited to an unverified or new contract
+	ApprovalRiskCritical ApprovalRisk = "critical" // to a denylisted or sanctioned spender
+)
+
+// approvalRiskRank orders risks for sorting and alert thresholds
+var approvalRiskRank = map[ApprovalRisk]int{
+	ApprovalRiskLow:      0,
+	ApprovalRiskMedium:   1,
+	ApprovalRiskHigh:     2,
+	ApprovalRiskCritical: 3,
+}
+
+// KnownSpenders are widely used spender contracts whose allowances are read for the common
+// tokens of a chain even without an approval event in the scanned blocks. Contracts that are
+// not deployed on a chain simply report no allowance.
+var KnownSpenders = map[string]string{
+	"0x000000000022D473030F116dDEE9F6B43aC78BA3": "Uniswap Permit2",
+	"0xE592427A0AEce92De3Edee1F18E0157C05861564": "Uniswap V3 SwapRouter",
+	"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D": "Uniswap V2 Router",
+	"0x1111111254EEB25477B68fb85Ed929f73A960582": "1inch Aggregation Router V5",
+}
+
+// TokenApproval is an outstanding ERC-20 allowance of a wallet. Allowance is the raw amount.
+type TokenApproval struct {
+	Token       string       `json:"token"`
+	TokenSymbol string       `json:"token_symbol,omitempty"`
+	Spender     string       `json:"spender"`
+	SpenderName string       `json:"spender_name,omitempty"`
+	Allowance   string       `json:"allowance"`
+	Unlimited   bool         `json:"unlimited"`
+	ApprovedAt  *time.Time   `json:"approved_at,omitempty"`
+	LastUsed    *time.Time   `json:"last_used,omitempty"`
+	Risk        ApprovalRisk `json:"risk"`
+	RiskReasons []string     `json:"risk_reasons,omitempty"`
+}
+
+// WalletApprovals are a wallet's outstanding approvals, riskiest first
+type WalletApprovals struct {
+	WalletID  uuid.UUID       `json:"wallet_id"`
+	Address   string          `json:"address"`
+	ChainID   int             `json:"chain_id"`
+	Approvals []TokenApproval `json:"approvals"`
+	ScannedAt time.Time       `json:"scanned_at"`
+	Cached    bool            `json:"cached,omitempty"`
+}
+
+// RevokeApprovalRequest asks for the transaction resetting a token allowance to zero
+type RevokeApprovalRequest struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	Token    string    `json:"token"`
+	Spender  string    `json:"spender"`
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *RevokeApprovalRequest) Validate() error {
+	var v validation.Validator
+	if r.WalletID == uuid.Nil {
+		v.Fail("wallet_id", validation.ConstraintRequired, "is required")
+	}
+	if v.Required("token", r.Token) && !common.IsHexAddress(r.Token) {
+		v.Fail("token", validation.ConstraintFormat, "must be an address")
+	}
+	if v.Required("spender", r.Spender) && !common.IsHexAddress(r.Spender) {
+		v.Fail("spender", validation.ConstraintFormat, "must be an address")
+	}
+	return v.Err()
+}
+
+// RevokeApprovalResponse is the unsigned approval-reset transaction for the user to sign
+type RevokeApprovalResponse struct {
+	ChainID   int    `json:"chain_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Data      string `json:"data"`
+	Value     string `json:"value"`
+	Allowance string `json:"allowance,omitempty"` // the allowance being revoked, when it could be read
+}
+
+// ApprovalConfig configures approval scanning
+type ApprovalConfig struct {
+	CacheTTL       time.Duration // how long a wallet scan is reused
+	LookbackBlocks uint64        // blocks scanned for approval events
+	AlertMinRisk   ApprovalRisk  // approvals this risky alert their owner; empty disables alerts
+}
+
+// approvalEvent is the latest Approval event of a token and spender
+type approvalEvent struct {
+	Token   string
+	Spender string
+	Time    time.Time
+}
+
+// approvalReader reads approvals from chain
+type approvalReader interface {
+	ApprovalEvents(ctx context.Context, chainID int, owner string) ([]approvalEvent, error)
+	Allowance(ctx context.Context, chainID int, token, owner, spender string) (*big.Int, error)
+	// LastSpent returns when the spender last moved the owner's tokens, or nil
+	LastSpent(ctx context.Context, chainID int, token, owner, spender string) (*time.Time, error)
+}
+
+// ApprovalCache keeps wallet approval scans, shared by every web3 service replica
+type ApprovalCache interface {
+	// Get returns an unexpired scan, or nil
+	Get(ctx context.Context, walletID uuid.UUID) (*WalletApprovals, error)
+	Save(ctx context.Context, approvals *WalletApprovals, ttl time.Duration) error
+}
+
+// ApprovalManager enumerates the token approvals of users' wallets, rates their risk and
+// builds the transactions revoking them. High-risk approvals found by a scan alert the
+// wallet's owner.
+type ApprovalManager struct {
+	service  *Service
+	reader   approvalReader
+	cache    ApprovalCache
+	screener *TransactionScreener
+	alerts   *alerts.AlertService
+	logger   *observability.Logger
+	config   ApprovalConfig
+	now      func() time.Time
+}
+
+// NewApprovalManager creates a manager reading approvals through the service's providers
+func NewApprovalManager(service *Service, cache ApprovalCache, cfg ApprovalConfig) *ApprovalManager {
+	if cfg.LookbackBlocks == 0 {
+		cfg.LookbackBlocks = 200_000
+	}
+	return &ApprovalManager{
+		service: service,
+		reader:  &rpcApprovalReader{service: service, lookbackBlocks: cfg.LookbackBlocks},
+		cache:   cache,
+		logger:  service.logger,
+		config:  cfg,
+		now:     time.Now,
+	}
+}
+
+// SetScreener rates spenders with transaction screening: denylisted and sanctioned spenders
+// are critical, unverified and recently deployed contracts raise the risk
+func (m *ApprovalManager) SetScreener(screener *TransactionScreener) {
+	m.screener = screener
+}
+
+// SetAlertService alerts wallet owners of approvals at or above the configured risk
+func (m *ApprovalManager) SetAlertService(alertService *alerts.AlertService) {
+	m.alerts = alertService
+}
+
+// ListApprovals returns the outstanding approvals of a user's wallet, scanning the chain
+// when there is no cached scan or refresh is set
+func (m *ApprovalManager) ListApprovals(ctx context.Context, userID, walletID uuid.UUID, refresh bool) (*WalletApprovals, error) {
+	wallet, err := m.service.walletRepo.GetByID(ctx, walletID)
+	if err != nil || wallet.UserID != userID {
+		return nil, ErrWalletNotFound
+	}
+
+	if !refresh && m.cache != nil {
+		cached, err := m.cache.Get(ctx, walletID)
+		if err != nil {
+			m.logger.Warn(ctx, "Failed to read cached approvals", map[string]interface{}{
+				"wallet_id": walletID.String(),
+				"error":     err.Error(),
+			})
+		} else if cached != nil {
+			cached.Cached = true
+			return cached, nil
+		}
+	}
+
+	approvals, err := m.scan(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+	if m.cache != nil && m.config.CacheTTL > 0 {
+		if err := m.cache.Save(ctx, approvals, m.config.CacheTTL); err != nil {
+			m.logger.Warn(ctx, "Failed to cache approvals", map[string]interface{}{
+				"wallet_id": walletID.String(),
+				"error":     err.Error(),
+			})
+		}
+	}
+	m.alertRisky(wallet, approvals.Approvals)
+	return approvals, nil
+}
+
+// BuildRevoke builds the transaction setting the spender's allowance of the token to zero.
+// Watch-only wallets can't sign it and are rejected.
+func (m *ApprovalManager) BuildRevoke(ctx context.Context, userID uuid.UUID, req RevokeApprovalRequest) (*RevokeApprovalResponse, error) {
+	wallet, err := m.service.signingWallet(ctx, userID, req.WalletID)
+	if err != nil {
+		if errors.Is(err, ErrWatchOnlyWallet) {
+			return nil, err
+		}
+		return nil, ErrWalletNotFound
+	}
+
+	token, spender := common.HexToAddress(req.Token), common.HexToAddress(req.Spender)
+	data := append(append([]byte{}, approveSelector...), common.LeftPadBytes(spender.Bytes(), 32)...)
+	data = append(data, make([]byte, 32)...)
+
+	resp := &RevokeApprovalResponse{
+		ChainID: wallet.ChainID,
+		From:    common.HexToAddress(wallet.Address).Hex(),
+		To:      token.Hex(),
+		Data:    hexutil.Encode(data),
+		Value:   "0",
+	}
+	// The allowance is informational; an unreachable RPC doesn't stop the user revoking
+	allowance, err := m.reader.Allowance(ctx, wallet.ChainID, token.Hex(), wallet.Address, spender.Hex())
+	if err != nil {
+		m.logger.Warn(ctx, "Failed to read allowance to revoke", map[string]interface{}{
+			"wallet_id": wallet.ID.String(),
+			"token":     token.Hex(),
+			"spender":   spender.Hex(),
+			"error":     err.Error(),
+		})
+	} else {
+		resp.Allowance = allowance.String()
+	}
+	return resp, nil
+}
+
+// scan reads the wallet's allowances for every token and spender seen in its approval events
+// and for the known spenders of the chain's common tokens. A failed event scan falls back to
+// the known spenders.
+func (m *ApprovalManager) scan(ctx context.Context, wallet *Wallet) (*WalletApprovals, error) {
+	owner := common.HexToAddress(wallet.Address).Hex()
+	type pair struct{ token, spender common.Address }
+	approvedAt := make(map[pair]*time.Time)
+
+	events, err := m.reader.ApprovalEvents(ctx, wallet.ChainID, owner)
+	if err != nil {
+		m.logger.Warn(ctx, "Failed to scan approval events", map[string]interface{}{
+			"wallet_id": wallet.ID.String(),
+			"chain_id":  wallet.ChainID,
+			"error":     err.Error(),
+		})
+	}
+	for _, event := range events {
+		at := event.Time
+		approvedAt[pair{common.HexToAddress(event.Token), common.HexToAddress(event.Spender)}] = &at
+	}
+	symbols := make(map[common.Address]string)
+	for _, token := range CommonERC20Tokens[wallet.ChainID] {
+		symbols[common.HexToAddress(token.Address)] = token.Symbol
+		for spender := range KnownSpenders {
+			key := pair{common.HexToAddress(token.Address), common.HexToAddress(spender)}
+			if _, ok := approvedAt[key]; !ok {
+				approvedAt[key] = nil
+			}
+		}
+	}
+
+	result := &WalletApprovals{WalletID: wallet.ID, Address: owner, ChainID: wallet.ChainID, Approvals: []TokenApproval{}, ScannedAt: m.now()}
+	for key, at := range approvedAt {
+		allowance, err := m.reader.Allowance(ctx, wallet.ChainID, key.token.Hex(), owner, key.spender.Hex())
+		if err != nil {
+			m.logger.Warn(ctx, "Failed to read allowance", map[string]interface{}{
+				"wallet_id": wallet.ID.String(),
+				"token":     key.token.Hex(),
+				"spender":   key.spender.Hex(),
+				"error":     err.Error(),
+			})
+			continue
+		}
+		if allowance.Sign() == 0 {
+			continue
+		}
+
+		approval := TokenApproval{
+			Token:       key.token.Hex(),
+			TokenSymbol: symbols[key.token],
+			Spender:     key.spender.Hex(),
+			SpenderName: KnownSpenders[key.spender.Hex()],
+			Allowance:   allowance.String(),
+			Unlimited:   allowance.Cmp(unlimitedAllowance) >= 0,
+			ApprovedAt:  at,
+		}
+		if lastUsed, err := m.reader.LastSpent(ctx, wallet.ChainID, approval.Token, owner, approval.Spender); err == nil {
+			approval.LastUsed = lastUsed
+		}
+		m.assess(ctx, wallet.ChainID, &approval)
+		result.Approvals = append(result.Approvals, approval)
+	}
+
+	sort.Slice(result.Approvals, func(i, j int) bool {
+		a, b := result.Approvals[i], result.Approvals[j]
+		if approvalRiskRank[a.Risk] != approvalRiskRank[b.Risk] {
+			return approvalRiskRank[a.Risk] > approvalRiskRank[b.Risk]
+		}
+		if a.Token != b.Token {
+			return a.Token < b.Token
+		}
+		return a.Spender < b.Spender
+	})
+	return result, nil
+}
+
+// assess rates an approval: unlimited allowances are medium risk, raised to high when the
+// spender is an unverified or recently deployed contract; denylisted or sanctioned spenders
+// are critical whatever the allowance
+func (m *ApprovalManager) assess(ctx context.Context, chainID int, approval *TokenApproval) {
+	approval.Risk = ApprovalRiskLow
+	if approval.Unlimited {
+		approval.Risk = ApprovalRiskMedium
+		approval.RiskReasons = append(approval.RiskReasons, "Unlimited allowance")
+	}
+	if m.screener == nil || approval.SpenderName != "" {
+		return
+	}
+
+	screening, err := m.screener.ScreenAddress(ctx, chainID, approval.Spender)
+	if err != nil {
+		m.logger.Warn(ctx, "Failed to screen approval spender", map[string]interface{}{
+			"spender": approval.Spender,
+			"error":   err.Error(),
+		})
+		return
+	}
+	riskySpender := false
+	for _, finding := range screening.Findings {
+		switch {
+		case finding.Severity == ScreeningSeverityCritical:
+			approval.Risk = ApprovalRiskCritical
+			approval.RiskReasons = append(approval.RiskReasons, finding.Description)
+		case finding.Category == ScreeningCategoryUnverifiedContract, finding.Category == ScreeningCategoryNewContract:
+			riskySpender = true
+			approval.RiskReasons = append(approval.RiskReasons, finding.Description)
+		}
+	}
+	if approval.Risk == ApprovalRiskCritical || !riskySpender {
+		return
+	}
+	if approval.Unlimited {
+		approval.Risk = ApprovalRiskHigh
+	} else {
+		approval.Risk = ApprovalRiskMedium
+	}
+}
+
+// alertRisky alerts the wallet's owner of approvals at or above the alert risk. Each
+// approval has its own rule, so the alert service's cooldown mutes repeated scans.
+func (m *ApprovalManager) alertRisky(wallet *Wallet, approvals []TokenApproval) {
+	if m.alerts == nil || m.config.AlertMinRisk == "" {
+		return
+	}
+	for _, approval := range approvals {
+		if approvalRiskRank[approval.Risk] < approvalRiskRank[m.config.AlertMinRisk] {
+			continue
+		}
+		token := approval.TokenSymbol
+		if token == "" {
+			token = approval.Token
+		}
+		amount := "an allowance"
+		if approval.Unlimited {
+			amount = "an unlimited allowance"
+		}
+		message := fmt.Sprintf("Wallet %s granted %s %s of %s on %s: %s", wallet.Address, approval.Spender, amount,
+			token, ChainName(wallet.ChainID), strings.Join(approval.RiskReasons, "; "))
+		severity := alerts.SeverityWarning
+		if approval.Risk == ApprovalRiskCritical {
+			severity = alerts.SeverityCritical
+		}
+
+		alert := m.alerts.CreateAlert(fmt.Sprintf("token_approval:%s:%s:%s", wallet.ID, approval.Token, approval.Spender), "Risky token approval",
+			message, severity, "token_approval_risk", decimal.NewFromInt(int64(approvalRiskRank[approval.Risk])),
+			decimal.NewFromInt(int64(approvalRiskRank[m.config.AlertMinRisk])), nil)
+		alert.UserID = &wallet.UserID
+		alert.Category = alerts.CategoryRiskAlerts
+		alert.Metadata["wallet_id"] = wallet.ID.String()
+		alert.Metadata["chain_id"] = wallet.ChainID
+		alert.Metadata["token"] = approval.Token
+		alert.Metadata["spender"] = approval.Spender
+		alert.Metadata["risk"] = string(approval.Risk)
+		m.alerts.SendAlert(alert)
+	}
+}
+
+var (
+	approvalTopic     = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	transferTopic     = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	allowanceSelector = []byte{0xdd, 0x62, 0xed, 0x3e} // allowance(address,address)
+)
+
+const (
+	// approvalLogChunk is the block range of one eth_getLogs call, within common RPC limits
+	approvalLogChunk = 10_000
+	// maxSpendChecks bounds the transfers whose transaction is fetched to find the last spend
+	maxSpendChecks = 20
+)
+
+// rpcApprovalReader reads approvals through the service's chain providers
+type rpcApprovalReader struct {
+	service        *Service
+	lookbackBlocks uint64
+}
+
+// filterRecentLogs returns the logs of the query over the lookback window, oldest first
+func (r *rpcApprovalReader) filterRecentLogs(ctx context.Context, chainID int, query ethereum.FilterQuery) ([]types.Log, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read block number: %w", err)
+	}
+	from := uint64(0)
+	if latest > r.lookbackBlocks {
+		from = latest - r.lookbackBlocks
+	}
+
+	var logs []types.Log
+	for start := from; start <= latest; start += approvalLogChunk {
+		end := start + approvalLogChunk - 1
+		if end > latest {
+			end = latest
+		}
+		query.FromBlock, query.ToBlock = new(big.Int).SetUint64(start), new(big.Int).SetUint64(end)
+		chunk, err := client.FilterLogs(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("filter logs %d-%d: %w", start, end, err)
+		}
+		logs = append(logs, chunk...)
+	}
+	return logs, nil
+}
+
+// blockTime reads the timestamp of a block, caching it in times
+func (r *rpcApprovalReader) blockTime(ctx context.Context, chainID int, number uint64, times map[uint64]time.Time) (time.Time, error) {
+	if at, ok := times[number]; ok {
+		return at, nil
+	}
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read block %d: %w", number, err)
+	}
+	times[number] = time.Unix(int64(header.Time), 0)
+	return times[number], nil
+}
+
+// ApprovalEvents returns the latest ERC-20 Approval event of each token and spender. NFT
+// Approval events, which index the token ID, are skipped.
+func (r *rpcApprovalReader) ApprovalEvents(ctx context.Context, chainID int, owner string) ([]approvalEvent, error) {
+	logs, err := r.filterRecentLogs(ctx, chainID, ethereum.FilterQuery{
+		Topics: [][]common.Hash{{approvalTopic}, {common.BytesToHash(common.HexToAddress(owner).Bytes())}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[[2]common.Address]types.Log)
+	for _, log := range logs {
+		if len(log.Topics) != 3 || len(log.Data) != 32 {
+			continue
+		}
+		latest[[2]common.Address{log.Address, common.BytesToAddress(log.Topics[2].Bytes())}] = log
+	}
+
+	times := make(map[uint64]time.Time)
+	events := make([]approvalEvent, 0, len(latest))
+	for key, log := range latest {
+		at, err := r.blockTime(ctx, chainID, log.BlockNumber, times)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, approvalEvent{Token: key[0].Hex(), Spender: key[1].Hex(), Time: at})
+	}
+	return events, nil
+}
+
+func (r *rpcApprovalReader) Allowance(ctx context.Context, chainID int, token, owner, spender string) (*big.Int, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	data := append(append([]byte{}, allowanceSelector...), common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32)...)
+
+	to := common.HexToAddress(token)
+	res, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call allowance failed: %w", err)
+	}
+	if len(res) == 0 {
+		// Not a token contract, or no contract at all
+		return new(big.Int), nil
+	}
+	if len(res) < 32 {
+		return nil, fmt.Errorf("unexpected allowance output")
+	}
+	return new(big.Int).SetBytes(res[:32]), nil
+}
+
+// LastSpent finds the latest transfer out of the owner's wallet made by the spender: sent by
+// it, as drainers and relayers do, or through it, as when the owner swaps through a router
+func (r *rpcApprovalReader) LastSpent(ctx context.Context, chainID int, token, owner, spender string) (*time.Time, error) {
+	logs, err := r.filterRecentLogs(ctx, chainID, ethereum.FilterQuery{
+		Addresses: []common.Address{common.HexToAddress(token)},
+		Topics:    [][]common.Hash{{transferTopic}, {common.BytesToHash(common.HexToAddress(owner).Bytes())}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	spenderAddress := common.HexToAddress(spender)
+	times := make(map[uint64]time.Time)
+	for i, checked := len(logs)-1, 0; i >= 0 && checked < maxSpendChecks; i, checked = i-1, checked+1 {
+		tx, _, err := client.TransactionByHash(ctx, logs[i].TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("read transaction %s: %w", logs[i].TxHash.Hex(), err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			continue
+		}
+		if sender != spenderAddress && (tx.To() == nil || *tx.To() != spenderAddress) {
+			continue
+		}
+		at, err := r.blockTime(ctx, chainID, logs[i].BlockNumber, times)
+		if err != nil {
+			return nil, err
+		}
+		return &at, nil
+	}
+	return nil, nil
+}
+
+// redisApprovalCache keeps wallet approval scans in Redis
+type redisApprovalCache struct {
+	redis *database.RedisClient
+}
+
+// NewRedisApprovalCache creates an approval cache backed by Redis
+func NewRedisApprovalCache(redis *database.RedisClient) ApprovalCache {
+	return &redisApprovalCache{redis: redis}
+}
+
+func approvalCacheKey(walletID uuid.UUID) string {
+	return "web3:approvals:" + walletID.String()
+}
+
+func (c *redisApprovalCache) Get(ctx context.Context, walletID uuid.UUID) (*WalletApprovals, error) {
+	data, err := c.redis.Client.Get(ctx, approvalCacheKey(walletID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var approvals WalletApprovals
+	if err := json.Unmarshal(data, &approvals); err != nil {
+		return nil, err
+	}
+	return &approvals, nil
+}
+
+func (c *redisApprovalCache) Save(ctx context.Context, approvals *WalletApprovals, ttl time.Duration) error {
+	data, err := json.Marshal(approvals)
+	if err != nil {
+		return err
+	}
+	return c.redis.Client.Set(ctx, approvalCacheKey(approvals.WalletID), data, ttl).Err()
+}
@@ -0,0 +1,175 @@
+package web3
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeApprovalReader serves fixed approval events and allowances keyed by token and spender
+type fakeApprovalReader struct {
+	events     []approvalEvent
+	allowances map[[2]common.Address]*big.Int
+	lastSpent  map[[2]common.Address]time.Time
+	scans      int
+}
+
+func (f *fakeApprovalReader) ApprovalEvents(ctx context.Context, chainID int, owner string) ([]approvalEvent, error) {
+	f.scans++
+	return f.events, nil
+}
+
+func (f *fakeApprovalReader) Allowance(ctx context.Context, chainID int, token, owner, spender string) (*big.Int, error) {
+	if allowance, ok := f.allowances[[2]common.Address{common.HexToAddress(token), common.HexToAddress(spender)}]; ok {
+		return allowance, nil
+	}
+	return new(big.Int), nil
+}
+
+func (f *fakeApprovalReader) LastSpent(ctx context.Context, chainID int, token, owner, spender string) (*time.Time, error) {
+	if at, ok := f.lastSpent[[2]common.Address{common.HexToAddress(token), common.HexToAddress(spender)}]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+// memoryApprovalCache is an ApprovalCache in memory; scans never expire
+type memoryApprovalCache map[uuid.UUID]WalletApprovals
+
+func (m memoryApprovalCache) Get(ctx context.Context, walletID uuid.UUID) (*WalletApprovals, error) {
+	approvals, ok := m[walletID]
+	if !ok {
+		return nil, nil
+	}
+	return &approvals, nil
+}
+
+func (m memoryApprovalCache) Save(ctx context.Context, approvals *WalletApprovals, ttl time.Duration) error {
+	m[approvals.WalletID] = *approvals
+	return nil
+}
+
+func TestApprovalManager_ListApprovals(t *testing.T) {
+	ctx := context.Background()
+	s := newServiceWithMocks()
+	wallet := &Wallet{ID: uuid.New(), UserID: uuid.New(), Address: walletAddress.Hex(), ChainID: 1}
+	s.walletRepo.(*mockWalletRepo).getByID = map[uuid.UUID]*Wallet{wallet.ID: wallet}
+
+	usdc := common.HexToAddress(CommonERC20Tokens[1][0].Address)
+	usdt := common.HexToAddress(CommonERC20Tokens[1][1].Address)
+	permit2 := common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+	unlimited := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	approvedAt := time.Date(2026, 5, 3, 13, 0, 0, 0, time.UTC)
+	spentAt := time.Date(2026, 5, 3, 14, 0, 0, 0, time.UTC)
+
+	reader := &fakeApprovalReader{
+		events: []approvalEvent{
+			{Token: usdc.Hex(), Spender: newContract.Hex(), Time: approvedAt},
+			{Token: tokenAddress.Hex(), Spender: sanctionedAddress.Hex(), Time: approvedAt},
+			{Token: tokenAddress.Hex(), Spender: drainerAddress.Hex(), Time: approvedAt}, // since revoked
+		},
+		allowances: map[[2]common.Address]*big.Int{
+			{usdc, newContract}:               unlimited,
+			{tokenAddress, sanctionedAddress}: big.NewInt(5),
+			{usdt, permit2}:                   big.NewInt(1_000_000),
+		},
+		lastSpent: map[[2]common.Address]time.Time{{usdt, permit2}: spentAt},
+	}
+	cache := memoryApprovalCache{}
+	screener, _, _ := newTestTransactionScreener()
+	alertService := alerts.NewAlertService(s.logger, alerts.NewAlertConfig(config.AlertsConfig{}))
+
+	manager := NewApprovalManager(s, cache, ApprovalConfig{CacheTTL: time.Hour, AlertMinRisk: ApprovalRiskHigh})
+	manager.reader = reader
+	manager.SetScreener(screener)
+	manager.SetAlertService(alertService)
+
+	approvals, err := manager.ListApprovals(ctx, wallet.UserID, wallet.ID, false)
+	require.NoError(t, err)
+	assert.False(t, approvals.Cached)
+	require.Len(t, approvals.Approvals, 3)
+
+	sanctioned, risky, router := approvals.Approvals[0], approvals.Approvals[1], approvals.Approvals[2]
+	assert.Equal(t, sanctionedAddress.Hex(), sanctioned.Spender)
+	assert.Equal(t, ApprovalRiskCritical, sanctioned.Risk)
+	assert.Equal(t, "5", sanctioned.Allowance)
+
+	// Unlimited allowance to an unverified, day-old contract
+	assert.Equal(t, newContract.Hex(), risky.Spender)
+	assert.Equal(t, "USDC", risky.TokenSymbol)
+	assert.True(t, risky.Unlimited)
+	assert.Equal(t, ApprovalRiskHigh, risky.Risk)
+	assert.Len(t, risky.RiskReasons, 3)
+	assert.Equal(t, &approvedAt, risky.ApprovedAt)
+
+	// Known spenders are read without an approval event
+	assert.Equal(t, "Uniswap Permit2", router.SpenderName)
+	assert.Equal(t, ApprovalRiskLow, router.Risk)
+	assert.Nil(t, router.ApprovedAt)
+	assert.Equal(t, &spentAt, router.LastUsed)
+
+	sent := alertService.GetAlerts(10)
+	require.Len(t, sent, 2)
+	for _, alert := range sent {
+		assert.Equal(t, wallet.UserID, *alert.UserID)
+		assert.Equal(t, alerts.CategoryRiskAlerts, alert.Category)
+	}
+
+	// The scan is cached until refreshed
+	approvals, err = manager.ListApprovals(ctx, wallet.UserID, wallet.ID, false)
+	require.NoError(t, err)
+	assert.True(t, approvals.Cached)
+	assert.Len(t, approvals.Approvals, 3)
+	assert.Equal(t, 1, reader.scans)
+
+	delete(reader.allowances, [2]common.Address{tokenAddress, sanctionedAddress})
+	approvals, err = manager.ListApprovals(ctx, wallet.UserID, wallet.ID, true)
+	require.NoError(t, err)
+	assert.False(t, approvals.Cached)
+	assert.Len(t, approvals.Approvals, 2)
+	assert.Equal(t, 2, reader.scans)
+
+	_, err = manager.ListApprovals(ctx, uuid.New(), wallet.ID, false)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+func TestApprovalManager_BuildRevoke(t *testing.T) {
+	ctx := context.Background()
+	s := newServiceWithMocks()
+	wallet := &Wallet{ID: uuid.New(), UserID: uuid.New(), Address: walletAddress.Hex(), ChainID: 1}
+	watched := &Wallet{ID: uuid.New(), UserID: wallet.UserID, Address: walletAddress.Hex(), ChainID: 1, WatchOnly: true}
+	s.walletRepo.(*mockWalletRepo).getByID = map[uuid.UUID]*Wallet{wallet.ID: wallet, watched.ID: watched}
+
+	manager := NewApprovalManager(s, memoryApprovalCache{}, ApprovalConfig{})
+	manager.reader = &fakeApprovalReader{allowances: map[[2]common.Address]*big.Int{{tokenAddress, drainerAddress}: big.NewInt(42)}}
+
+	req := RevokeApprovalRequest{WalletID: wallet.ID, Token: tokenAddress.Hex(), Spender: drainerAddress.Hex()}
+	require.NoError(t, req.Validate())
+	resp, err := manager.BuildRevoke(ctx, wallet.UserID, req)
+	require.NoError(t, err)
+	assert.Equal(t, tokenAddress.Hex(), resp.To)
+	assert.Equal(t, walletAddress.Hex(), resp.From)
+	assert.Equal(t, "0", resp.Value)
+	assert.Equal(t, "42", resp.Allowance)
+	assert.Equal(t, approvalCalldata(approveSelector, drainerAddress, new(big.Int)), resp.Data)
+
+	// The revocation decodes as an approval of nothing
+	approval := decodeApproval(resp.Data)
+	require.NotNil(t, approval)
+	assert.Zero(t, approval.amount.Sign())
+
+	_, err = manager.BuildRevoke(ctx, wallet.UserID, RevokeApprovalRequest{WalletID: watched.ID, Token: tokenAddress.Hex(), Spender: drainerAddress.Hex()})
+	assert.ErrorIs(t, err, ErrWatchOnlyWallet)
+	_, err = manager.BuildRevoke(ctx, uuid.New(), req)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+
+	assert.Error(t, (&RevokeApprovalRequest{WalletID: wallet.ID, Token: "USDC"}).Validate())
+}
@@ -0,0 +1,391 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidTokenAddress   = errors.New("invalid token address")
+	ErrUnsupportedTokenChain = errors.New("token lookups are not supported on this chain")
+	// ErrTokenNotFound is returned for addresses without an ERC-20 contract
+	ErrTokenNotFound = errors.New("token contract not found")
+)
+
+// TokenMetadata describes a token on one chain. Tokens on the curated list and native coins are
+// verified; anything else is described by its own contract, so its name and symbol can't be
+// trusted.
+type TokenMetadata struct {
+	ChainID     int    `json:"chain_id"`
+	Address     string `json:"address"` // NativeTokenAddress for the native coin
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Decimals    int    `json:"decimals"`
+	LogoURL     string `json:"logo_url,omitempty"`
+	CoinGeckoID string `json:"coingecko_id,omitempty"`
+	Native      bool   `json:"native,omitempty"`
+	Verified    bool   `json:"verified"`
+
+	// Impersonates is the address of the verified token whose symbol an unverified token copies
+	Impersonates string `json:"impersonates,omitempty"`
+}
+
+// Amount converts an amount in the token's smallest unit to whole tokens
+func (t *TokenMetadata) Amount(raw *big.Int) decimal.Decimal {
+	if raw == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(raw, int32(-t.Decimals))
+}
+
+// tokenContractReader reads what an ERC-20 contract reports about itself
+type tokenContractReader interface {
+	TokenMetadata(ctx context.Context, chainID int, address string) (name, symbol string, decimals int, err error)
+}
+
+// rpcTokenReader reads token contracts over the chain's RPC
+type rpcTokenReader struct {
+	service *Service
+}
+
+func (r rpcTokenReader) TokenMetadata(ctx context.Context, chainID int, address string) (string, string, int, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return "", "", 0, err
+	}
+	contract := common.HexToAddress(address)
+	code, err := client.CodeAt(ctx, contract, nil)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("read token code: %w", err)
+	}
+	if len(code) == 0 {
+		return "", "", 0, fmt.Errorf("%w: no contract at %s", ErrTokenNotFound, contract.Hex())
+	}
+	// A contract without decimals isn't an ERC-20 token
+	decimals, err := r.service.getERC20Decimals(ctx, chainID, address)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("%w: %s: %v", ErrTokenNotFound, contract.Hex(), err)
+	}
+	return callTokenString(ctx, client, contract, "name"), callTokenString(ctx, client, contract, "symbol"), decimals, nil
+}
+
+// callTokenString reads the name or symbol of a token, accepting the bytes32 some early tokens
+// return; tokens without one get an empty string
+func callTokenString(ctx context.Context, client *ethclient.Client, contract common.Address, method string) string {
+	callData, err := parsedERC20ABI.Pack(method)
+	if err != nil {
+		return ""
+	}
+	res, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: callData}, nil)
+	if err != nil {
+		return ""
+	}
+	if out, err := parsedERC20ABI.Unpack(method, res); err == nil && len(out) == 1 {
+		if value, ok := out[0].(string); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	if len(res) == 32 {
+		return strings.TrimSpace(strings.TrimRight(string(res), "\x00"))
+	}
+	return ""
+}
+
+// trustWalletChains names the supported chains in the Trust Wallet assets repository, which
+// hosts the logos of listed tokens
+var trustWalletChains = map[int]string{
+	1:     "ethereum",
+	137:   "polygon",
+	56:    "smartchain",
+	43114: "avalanchec",
+	250:   "fantom",
+	42161: "arbitrum",
+	10:    "optimism",
+	8453:  "base",
+}
+
+const trustWalletAssetsURL = "https://raw.githubusercontent.com/trustwallet/assets/master/blockchains/"
+
+type tokenKey struct {
+	chainID int
+	address common.Address
+}
+
+// TokenRegistry resolves token contracts to their metadata. Native coins and the tokens of
+// CommonERC20Tokens are verified and served without RPC calls; other contracts are read
+// on-chain once, cached, and flagged unverified.
+type TokenRegistry struct {
+	contracts tokenContractReader
+
+	mu    sync.RWMutex
+	cache map[tokenKey]*TokenMetadata
+}
+
+// NewTokenRegistry creates a registry reading unlisted tokens through the service's RPC
+// providers
+func NewTokenRegistry(service *Service) *TokenRegistry {
+	return &TokenRegistry{
+		contracts: rpcTokenReader{service: service},
+		cache:     make(map[tokenKey]*TokenMetadata),
+	}
+}
+
+// Tokens returns the registry describing tokens in balance, transaction and DeFi responses
+func (s *Service) Tokens() *TokenRegistry {
+	if s.tokens == nil {
+		s.tokens = NewTokenRegistry(s)
+	}
+	return s.tokens
+}
+
+// Resolve returns the metadata of a token contract, or of the native coin for
+// NativeTokenAddress
+func (r *TokenRegistry) Resolve(ctx context.Context, chainID int, address string) (*TokenMetadata, error) {
+	if !isSupportedChain(chainID) {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedTokenChain, chainID)
+	}
+	if !common.IsHexAddress(address) {
+		return nil, ErrInvalidTokenAddress
+	}
+	if token, ok := r.Lookup(chainID, address); ok {
+		return token, nil
+	}
+
+	name, symbol, decimals, err := r.contracts.TokenMetadata(ctx, chainID, address)
+	if err != nil {
+		return nil, err
+	}
+	token := &TokenMetadata{
+		ChainID:  chainID,
+		Address:  common.HexToAddress(address).Hex(),
+		Name:     name,
+		Symbol:   symbol,
+		Decimals: decimals,
+	}
+	if verified, ok := r.BySymbol(chainID, symbol); ok {
+		token.Impersonates = verified.Address
+	}
+
+	r.mu.Lock()
+	r.cache[tokenKey{chainID, common.HexToAddress(address)}] = token
+	r.mu.Unlock()
+	copied := *token
+	return &copied, nil
+}
+
+// Lookup returns a token's metadata when it is verified or already resolved, without
+// reading the chain
+func (r *TokenRegistry) Lookup(chainID int, address string) (*TokenMetadata, bool) {
+	contract := common.HexToAddress(address)
+	if contract == common.HexToAddress(NativeTokenAddress) {
+		return nativeToken(chainID)
+	}
+	for _, token := range CommonERC20Tokens[chainID] {
+		if common.HexToAddress(token.Address) == contract {
+			return curatedToken(chainID, token), true
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	token, ok := r.cache[tokenKey{chainID, contract}]
+	if !ok {
+		return nil, false
+	}
+	copied := *token
+	return &copied, true
+}
+
+// BySymbol returns the verified token or native coin with a symbol on a chain. Unverified
+// tokens are never matched, since anyone can deploy a token under any symbol.
+func (r *TokenRegistry) BySymbol(chainID int, symbol string) (*TokenMetadata, bool) {
+	if symbol == "" {
+		return nil, false
+	}
+	if native, ok := nativeToken(chainID); ok && strings.EqualFold(native.Symbol, symbol) {
+		return native, true
+	}
+	for _, token := range CommonERC20Tokens[chainID] {
+		if strings.EqualFold(token.Symbol, symbol) {
+			return curatedToken(chainID, token), true
+		}
+	}
+	return nil, false
+}
+
+// Decimals returns the precision amounts of a token are expressed in
+func (r *TokenRegistry) Decimals(ctx context.Context, chainID int, address string) (int, error) {
+	token, err := r.Resolve(ctx, chainID, address)
+	if err != nil {
+		return 0, err
+	}
+	return token.Decimals, nil
+}
+
+// nativeToken describes the native coin of a supported chain or testnet; testnet coins have
+// no market price
+func nativeToken(chainID int) (*TokenMetadata, bool) {
+	mainnet, testnet := mainnetOf(chainID)
+	if !testnet {
+		mainnet = chainID
+	}
+	symbol, ok := NativeSymbolByChain[mainnet]
+	if !ok {
+		return nil, false
+	}
+	token := &TokenMetadata{
+		ChainID:  chainID,
+		Address:  NativeTokenAddress,
+		Name:     NativeNameByChain[mainnet],
+		Symbol:   symbol,
+		Decimals: nativeDecimals,
+		Native:   true,
+		Verified: true,
+	}
+	if !testnet {
+		token.CoinGeckoID = NativeCoinGeckoIDByChain[chainID]
+		if assets, ok := trustWalletChains[chainID]; ok {
+			token.LogoURL = trustWalletAssetsURL + assets + "/info/logo.png"
+		}
+	}
+	return token, true
+}
+
+func curatedToken(chainID int, token ERC20Token) *TokenMetadata {
+	address := common.HexToAddress(token.Address).Hex()
+	metadata := &TokenMetadata{
+		ChainID:     chainID,
+		Address:     address,
+		Name:        token.Name,
+		Symbol:      token.Symbol,
+		Decimals:    token.Decimals,
+		CoinGeckoID: token.CoinGeckoID,
+		Verified:    true,
+	}
+	if assets, ok := trustWalletChains[chainID]; ok {
+		metadata.LogoURL = trustWalletAssetsURL + assets + "/assets/" + address + "/logo.png"
+	}
+	return metadata
+}
+
+// positionTokens describes the assets of a DeFi position. Positions name their assets by
+// symbol, which is only matched against verified tokens; contract addresses are resolved.
+func (r *TokenRegistry) positionTokens(ctx context.Context, chainID int, p *DeFiPosition) []*TokenMetadata {
+	assets := []string{p.TokenA, p.TokenB}
+	if p.TokenA == "" && p.TokenB == "" {
+		assets = strings.Split(p.TokenSymbol, "-")
+	}
+	var tokens []*TokenMetadata
+	for _, asset := range assets {
+		asset = strings.TrimSpace(asset)
+		if asset == "" {
+			continue
+		}
+		if common.IsHexAddress(asset) {
+			if token, err := r.Resolve(ctx, chainID, asset); err == nil {
+				tokens = append(tokens, token)
+				continue
+			}
+		}
+		if token, ok := r.BySymbol(chainID, asset); ok {
+			tokens = append(tokens, token)
+			continue
+		}
+		tokens = append(tokens, &TokenMetadata{ChainID: chainID, Symbol: asset})
+	}
+	return tokens
+}
+
+// ERC-20 transfer selectors
+var (
+	transferSelector     = []byte{0xa9, 0x05, 0x9c, 0xbb} // transfer(address,uint256)
+	transferFromSelector = []byte{0x23, 0xb8, 0x72, 0xdd} // transferFrom(address,address,uint256)
+)
+
+// Asset methods
+const (
+	AssetMethodTransfer = "transfer"
+	AssetMethodApprove  = "approve"
+)
+
+// TransactionAsset is the coin or token a transaction moves or approves, with its amount in
+// whole units
+type TransactionAsset struct {
+	Token        *TokenMetadata  `json:"token"`
+	Method       string          `json:"method"`
+	RawAmount    string          `json:"raw_amount"`
+	Amount       decimal.Decimal `json:"amount"`
+	Counterparty string          `json:"counterparty"` // recipient, or spender of an approval
+}
+
+// DescribeTransaction works out the asset a transaction sends from its value and calldata. It
+// returns nil for contract calls other than ERC-20 transfers and approvals.
+func (r *TokenRegistry) DescribeTransaction(ctx context.Context, tx *Transaction) (*TransactionAsset, error) {
+	to := tx.ToAddress
+	if to == "" {
+		to = tx.To
+	}
+	if !common.IsHexAddress(to) {
+		return nil, nil
+	}
+
+	calldata := common.FromHex(tx.Data)
+	if len(calldata) == 0 {
+		native, ok := nativeToken(tx.ChainID)
+		if !ok {
+			return nil, nil
+		}
+		value := tx.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		return &TransactionAsset{
+			Token:        native,
+			Method:       AssetMethodTransfer,
+			RawAmount:    value.String(),
+			Amount:       native.Amount(value),
+			Counterparty: common.HexToAddress(to).Hex(),
+		}, nil
+	}
+
+	method, counterparty, amount := decodeTokenCall(calldata)
+	if method == "" {
+		return nil, nil
+	}
+	token, err := r.Resolve(ctx, tx.ChainID, to)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionAsset{
+		Token:        token,
+		Method:       method,
+		RawAmount:    amount.String(),
+		Amount:       token.Amount(amount),
+		Counterparty: counterparty.Hex(),
+	}, nil
+}
+
+// decodeTokenCall decodes ERC-20 transfer, transferFrom and approve calldata, returning an
+// empty method for anything else
+func decodeTokenCall(calldata []byte) (string, common.Address, *big.Int) {
+	switch {
+	case len(calldata) == 4+64 && string(calldata[:4]) == string(transferSelector):
+		return AssetMethodTransfer, common.BytesToAddress(calldata[4:36]), new(big.Int).SetBytes(calldata[36:68])
+	case len(calldata) == 4+96 && string(calldata[:4]) == string(transferFromSelector):
+		return AssetMethodTransfer, common.BytesToAddress(calldata[36:68]), new(big.Int).SetBytes(calldata[68:100])
+	}
+	if approval := decodeApproval(hexutil.Encode(calldata)); approval != nil && !approval.allTokens {
+		return AssetMethodApprove, approval.spender, approval.amount
+	}
+	return "", common.Address{}, nil
+}
@@ -0,0 +1,227 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTokenContract struct {
+	name, symbol string
+	decimals     int
+}
+
+// fakeTokenContracts serves token contracts by address and counts the reads
+type fakeTokenContracts struct {
+	tokens map[common.Address]fakeTokenContract
+	reads  int
+}
+
+func (f *fakeTokenContracts) TokenMetadata(ctx context.Context, chainID int, address string) (string, string, int, error) {
+	f.reads++
+	token, ok := f.tokens[common.HexToAddress(address)]
+	if !ok {
+		return "", "", 0, fmt.Errorf("%w: no contract at %s", ErrTokenNotFound, address)
+	}
+	return token.name, token.symbol, token.decimals, nil
+}
+
+const (
+	spoofedUSDC = "0x1111111111111111111111111111111111111111"
+	memeToken   = "0x2222222222222222222222222222222222222222"
+)
+
+func newTokenRegistryFixture() (*Service, *fakeTokenContracts) {
+	s := newServiceWithMocks()
+	contracts := &fakeTokenContracts{tokens: map[common.Address]fakeTokenContract{
+		common.HexToAddress(spoofedUSDC): {name: "USD Coin", symbol: "USDC", decimals: 18},
+		common.HexToAddress(memeToken):   {name: "Pepe", symbol: "PEPE", decimals: 9},
+	}}
+	s.Tokens().contracts = contracts
+	return s, contracts
+}
+
+func tokenCall(selector []byte, args ...[]byte) string {
+	data := append([]byte{}, selector...)
+	for _, arg := range args {
+		data = append(data, common.LeftPadBytes(arg, 32)...)
+	}
+	return hexutil.Encode(data)
+}
+
+func TestTokenRegistry_Resolve(t *testing.T) {
+	s, contracts := newTokenRegistryFixture()
+	tokens := s.Tokens()
+	ctx := context.Background()
+
+	// Listed tokens are verified without reading the contract
+	usdc, err := tokens.Resolve(ctx, 1, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	require.NoError(t, err)
+	assert.True(t, usdc.Verified)
+	assert.Equal(t, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", usdc.Address)
+	assert.Equal(t, 6, usdc.Decimals)
+	assert.Equal(t, "usd-coin", usdc.CoinGeckoID)
+	assert.Contains(t, usdc.LogoURL, "/ethereum/assets/0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48/")
+
+	native, err := tokens.Resolve(ctx, 137, NativeTokenAddress)
+	require.NoError(t, err)
+	assert.True(t, native.Native)
+	assert.True(t, native.Verified)
+	assert.Equal(t, "MATIC", native.Symbol)
+	assert.Equal(t, 18, native.Decimals)
+
+	// Testnet coins are verified but have no price
+	sepolia, err := tokens.Resolve(ctx, Testnets[1].ChainID, NativeTokenAddress)
+	require.NoError(t, err)
+	assert.Equal(t, "ETH", sepolia.Symbol)
+	assert.Empty(t, sepolia.CoinGeckoID)
+	assert.Zero(t, contracts.reads)
+
+	// Other contracts are read once and flagged unverified
+	meme, err := tokens.Resolve(ctx, 1, memeToken)
+	require.NoError(t, err)
+	assert.False(t, meme.Verified)
+	assert.Equal(t, "PEPE", meme.Symbol)
+	assert.Equal(t, 9, meme.Decimals)
+	assert.Empty(t, meme.Impersonates)
+	_, err = tokens.Resolve(ctx, 1, memeToken)
+	require.NoError(t, err)
+	assert.Equal(t, 1, contracts.reads)
+
+	// An unlisted token copying a listed symbol points at the real one
+	spoofed, err := tokens.Resolve(ctx, 1, spoofedUSDC)
+	require.NoError(t, err)
+	assert.False(t, spoofed.Verified)
+	assert.Equal(t, usdc.Address, spoofed.Impersonates)
+	assert.Empty(t, spoofed.CoinGeckoID)
+
+	_, err = tokens.Resolve(ctx, 1, "0x3333333333333333333333333333333333333333")
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+	_, err = tokens.Resolve(ctx, 1, "usdc")
+	assert.ErrorIs(t, err, ErrInvalidTokenAddress)
+	_, err = tokens.Resolve(ctx, 999, memeToken)
+	assert.ErrorIs(t, err, ErrUnsupportedTokenChain)
+}
+
+func TestTokenRegistry_BySymbolMatchesVerifiedOnly(t *testing.T) {
+	s, _ := newTokenRegistryFixture()
+	tokens := s.Tokens()
+	_, err := tokens.Resolve(context.Background(), 1, memeToken)
+	require.NoError(t, err)
+
+	usdt, ok := tokens.BySymbol(42161, "usdt")
+	require.True(t, ok)
+	assert.Equal(t, "0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9", usdt.Address)
+	eth, ok := tokens.BySymbol(10, "ETH")
+	require.True(t, ok)
+	assert.True(t, eth.Native)
+
+	_, ok = tokens.BySymbol(1, "PEPE")
+	assert.False(t, ok, "resolved unverified tokens are not matched by symbol")
+}
+
+func TestTokenRegistry_DescribeTransaction(t *testing.T) {
+	s, _ := newTokenRegistryFixture()
+	tokens := s.Tokens()
+	ctx := context.Background()
+	usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	recipient := common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa")
+	spender := common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+	native, err := tokens.DescribeTransaction(ctx, &Transaction{ChainID: 1, ToAddress: recipient.Hex(), Value: big.NewInt(1_500_000_000_000_000_000)})
+	require.NoError(t, err)
+	assert.True(t, native.Token.Native)
+	assert.Equal(t, AssetMethodTransfer, native.Method)
+	assert.True(t, decimal.RequireFromString("1.5").Equal(native.Amount), native.Amount.String())
+	assert.Equal(t, recipient.Hex(), native.Counterparty)
+
+	// Token amounts use the token's decimals
+	transfer, err := tokens.DescribeTransaction(ctx, &Transaction{ChainID: 1, ToAddress: usdc,
+		Data: tokenCall(transferSelector, recipient.Bytes(), big.NewInt(2_500_000).Bytes())})
+	require.NoError(t, err)
+	assert.Equal(t, "USDC", transfer.Token.Symbol)
+	assert.Equal(t, "2500000", transfer.RawAmount)
+	assert.True(t, decimal.RequireFromString("2.5").Equal(transfer.Amount), transfer.Amount.String())
+	assert.Equal(t, recipient.Hex(), transfer.Counterparty)
+
+	transferFrom, err := tokens.DescribeTransaction(ctx, &Transaction{ChainID: 1, ToAddress: memeToken,
+		Data: tokenCall(transferFromSelector, spender.Bytes(), recipient.Bytes(), big.NewInt(3_000_000_000).Bytes())})
+	require.NoError(t, err)
+	assert.False(t, transferFrom.Token.Verified)
+	assert.True(t, decimal.NewFromInt(3).Equal(transferFrom.Amount))
+	assert.Equal(t, recipient.Hex(), transferFrom.Counterparty)
+
+	approval, err := tokens.DescribeTransaction(ctx, &Transaction{ChainID: 1, ToAddress: usdc,
+		Data: tokenCall(approveSelector, spender.Bytes(), big.NewInt(100_000_000).Bytes())})
+	require.NoError(t, err)
+	assert.Equal(t, AssetMethodApprove, approval.Method)
+	assert.True(t, decimal.NewFromInt(100).Equal(approval.Amount))
+	assert.Equal(t, spender.Hex(), approval.Counterparty)
+
+	other, err := tokens.DescribeTransaction(ctx, &Transaction{ChainID: 1, ToAddress: usdc, Data: "0xdeadbeef"})
+	require.NoError(t, err)
+	assert.Nil(t, other)
+}
+
+func TestListTransactions_DescribesAssets(t *testing.T) {
+	s, _ := newTokenRegistryFixture()
+	recipient := common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa")
+	s.txRepo = &mockTxRepo{list: []*Transaction{
+		{ID: uuid.New(), ChainID: 137, ToAddress: "0xc2132D05D31c914a87C6611C10748AEb04B58e8F",
+			Data: tokenCall(transferSelector, recipient.Bytes(), big.NewInt(10_000_000).Bytes())},
+		// Transfers of tokens that can't be resolved are listed without an asset
+		{ID: uuid.New(), ChainID: 1, ToAddress: "0x3333333333333333333333333333333333333333",
+			Data: tokenCall(transferSelector, recipient.Bytes(), big.NewInt(1).Bytes())},
+	}}
+
+	transactions, _, err := s.ListTransactions(context.Background(), uuid.New(), TransactionListFilter{})
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	require.NotNil(t, transactions[0].Asset)
+	assert.Equal(t, "USDT", transactions[0].Asset.Token.Symbol)
+	assert.True(t, transactions[0].Asset.Token.Verified)
+	assert.True(t, decimal.NewFromInt(10).Equal(transactions[0].Asset.Amount))
+	assert.Nil(t, transactions[1].Asset)
+}
+
+func TestListDeFiPositions_DescribesTokens(t *testing.T) {
+	s, _ := newTokenRegistryFixture()
+	userID := uuid.New()
+	wallet := &Wallet{ID: uuid.New(), UserID: userID, ChainID: 1}
+	s.walletRepo = &mockWalletRepo{getByID: map[uuid.UUID]*Wallet{wallet.ID: wallet}}
+	s.priceSource = &mockPriceSource{prices: map[string]TokenPrice{}}
+	s.positionRepo = &mockPositionRepo{positions: []*DeFiPosition{
+		{ID: uuid.New(), UserID: userID, WalletID: wallet.ID, ProtocolName: "uniswap", TokenSymbol: "ETH-USDC", Amount: decimal.NewFromInt(1), USDValue: decimal.NewFromInt(3), IsActive: true, CreatedAt: time.Now()},
+		{ID: uuid.New(), UserID: userID, WalletID: wallet.ID, ProtocolName: "curve", TokenA: memeToken, TokenB: "DAI", Amount: decimal.NewFromInt(1), USDValue: decimal.NewFromInt(2), IsActive: true, CreatedAt: time.Now()},
+		{ID: uuid.New(), UserID: userID, WalletID: uuid.New(), ProtocolName: "aave", TokenSymbol: "USDC", Amount: decimal.NewFromInt(1), USDValue: decimal.NewFromInt(1), IsActive: true, CreatedAt: time.Now()},
+	}}
+
+	resp, err := s.ListDeFiPositions(context.Background(), userID, DeFiPositionFilter{})
+	require.NoError(t, err)
+	require.Len(t, resp.Positions, 3)
+
+	lp := resp.Positions[0].Tokens
+	require.Len(t, lp, 2)
+	assert.True(t, lp[0].Native)
+	assert.Equal(t, "USDC", lp[1].Symbol)
+	assert.True(t, lp[1].Verified)
+
+	curve := resp.Positions[1].Tokens
+	require.Len(t, curve, 2)
+	assert.Equal(t, "PEPE", curve[0].Symbol)
+	assert.False(t, curve[0].Verified)
+	assert.Equal(t, "DAI", curve[1].Symbol)
+	assert.False(t, curve[1].Verified, "symbols off the curated list are unverified")
+	assert.Empty(t, curve[1].Address)
+
+	assert.Empty(t, resp.Positions[2].Tokens, "positions of unknown wallets have no chain to resolve on")
+}
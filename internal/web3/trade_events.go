@@ -0,0 +1,150 @@
+package web3
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Trade event types
+const (
+	TradeEventFill      = "fill"      // a position was opened, reduced or closed
+	TradeEventValuation = "valuation" // a portfolio was marked to market
+)
+
+// tradeEventBuffer is the number of events queued for the listener before new ones are dropped
+const tradeEventBuffer = 1024
+
+// TradeEvent reports a fill or a valuation of a portfolio to a TradeEventListener
+type TradeEvent struct {
+	Type        string    `json:"type"`
+	PortfolioID uuid.UUID `json:"portfolio_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Timestamp   time.Time `json:"timestamp"`
+
+	// Fills; the order ID is the trade record ID
+	OrderID     string           `json:"order_id,omitempty"`
+	PositionID  uuid.UUID        `json:"position_id,omitempty"`
+	Symbol      string           `json:"symbol,omitempty"`
+	Side        string           `json:"side,omitempty"`
+	Quantity    decimal.Decimal  `json:"quantity"`
+	Price       decimal.Decimal  `json:"price"`
+	Notional    decimal.Decimal  `json:"notional"`
+	SlippageBps *decimal.Decimal `json:"slippage_bps,omitempty"` // adverse fill against the expected price
+
+	// Valuations; drawdown is the share of value lost since the portfolio's peak
+	Value    decimal.Decimal `json:"value"`
+	Peak     decimal.Decimal `json:"peak"`
+	Drawdown decimal.Decimal `json:"drawdown"`
+}
+
+// TradeEventListener receives the trading engine's fills and valuations
+type TradeEventListener interface {
+	OnTradeEvent(ctx context.Context, event TradeEvent)
+}
+
+// PublishTradeEvents delivers fills and valuations to listener until ctx is done. Events are
+// queued while the engine lock is held and delivered in order on this goroutine; they are
+// only produced while it runs.
+func (t *TradingEngine) PublishTradeEvents(ctx context.Context, listener TradeEventListener) {
+	events := make(chan TradeEvent, tradeEventBuffer)
+
+	t.mu.Lock()
+	t.tradeEvents = events
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		t.tradeEvents = nil
+		t.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			listener.OnTradeEvent(ctx, event)
+		}
+	}
+}
+
+// emitTradeEventLocked queues an event for the listener, dropping it when the queue is full.
+// The caller must hold t.mu.
+func (t *TradingEngine) emitTradeEventLocked(event TradeEvent) {
+	if t.tradeEvents == nil {
+		return
+	}
+	select {
+	case t.tradeEvents <- event:
+	default:
+		t.logger.Warn(context.Background(), "Trade event queue is full, dropping event", map[string]interface{}{
+			"type":         event.Type,
+			"portfolio_id": event.PortfolioID.String(),
+		})
+	}
+}
+
+// emitFillLocked reports a recorded trade. The caller must hold t.mu.
+func (t *TradingEngine) emitFillLocked(position *Position, trade TradeRecord) {
+	t.emitTradeEventLocked(TradeEvent{
+		Type:        TradeEventFill,
+		PortfolioID: trade.PortfolioID,
+		UserID:      position.UserID,
+		Timestamp:   trade.ExecutedAt,
+		OrderID:     trade.ID.String(),
+		PositionID:  trade.PositionID,
+		Symbol:      trade.Symbol,
+		Side:        trade.Side,
+		Quantity:    trade.Quantity,
+		Price:       trade.Price,
+		Notional:    trade.Quantity.Mul(trade.Price),
+		SlippageBps: trade.SlippageBps,
+	})
+}
+
+// emitValuationsLocked marks the given portfolios to market and reports their drawdown from
+// peak. Equity is cash plus cost basis plus realized and unrealized P&L, so opening or closing
+// a position does not move it. The caller must hold t.mu.
+func (t *TradingEngine) emitValuationsLocked(portfolioIDs map[uuid.UUID]bool, at time.Time) {
+	if t.tradeEvents == nil {
+		return
+	}
+	for portfolioID := range portfolioIDs {
+		portfolio, exists := t.portfolios[portfolioID]
+		if !exists {
+			continue
+		}
+
+		value := portfolio.AvailableBalance.Add(portfolio.InvestedAmount)
+		for _, trade := range t.trades[portfolioID] {
+			value = value.Add(trade.RealizedPnL)
+		}
+		for _, positionID := range portfolio.ActivePositions {
+			if position, exists := t.activePositions[positionID.String()]; exists {
+				value = value.Add(position.UnrealizedPnL)
+			}
+		}
+		if !value.IsPositive() {
+			continue
+		}
+
+		peak, exists := t.equityPeaks[portfolioID]
+		if !exists || value.GreaterThan(peak) {
+			peak = value
+			t.equityPeaks[portfolioID] = peak
+		}
+
+		t.emitTradeEventLocked(TradeEvent{
+			Type:        TradeEventValuation,
+			PortfolioID: portfolioID,
+			UserID:      portfolio.UserID,
+			Timestamp:   at,
+			Value:       value,
+			Peak:        peak,
+			Drawdown:    peak.Sub(value).Div(peak),
+		})
+	}
+}
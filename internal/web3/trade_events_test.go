@@ -0,0 +1,76 @@
+package web3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type channelTradeListener chan TradeEvent
+
+func (c channelTradeListener) OnTradeEvent(ctx context.Context, event TradeEvent) {
+	c <- event
+}
+
+func TestPublishTradeEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine := newProtectionTestEngine()
+	events := make(channelTradeListener, 16)
+	go engine.PublishTradeEvents(ctx, events)
+	require.Eventually(t, func() bool {
+		engine.mu.RLock()
+		defer engine.mu.RUnlock()
+		return engine.tradeEvents != nil
+	}, time.Second, time.Millisecond)
+
+	next := func() TradeEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("no trade event")
+			return TradeEvent{}
+		}
+	}
+
+	portfolio, position := openTestPosition(t, engine, ethSignal())
+	fill := next()
+	assert.Equal(t, TradeEventFill, fill.Type)
+	assert.Equal(t, portfolio.ID, fill.PortfolioID)
+	assert.Equal(t, portfolio.UserID, fill.UserID)
+	assert.Equal(t, position.ID, fill.PositionID)
+	assert.Equal(t, TradeSideBuy, fill.Side)
+	assert.NotEmpty(t, fill.OrderID)
+	assert.True(t, fill.Notional.Equal(position.Amount), "the notional is the position's size")
+	assert.Nil(t, fill.SlippageBps, "no order book source is configured")
+
+	engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(2100))
+	peak := next()
+	assert.Equal(t, TradeEventValuation, peak.Type)
+	assert.True(t, peak.Value.Equal(decimal.NewFromInt(10100)), peak.Value.String())
+	assert.True(t, peak.Drawdown.IsZero())
+
+	// The stop-loss closes the position; realized P&L keeps the valuation continuous
+	engine.ApplyPrice(ctx, "ETHUSDT", decimal.NewFromInt(1500))
+	sell := next()
+	assert.Equal(t, TradeEventFill, sell.Type)
+	assert.Equal(t, TradeSideSell, sell.Side)
+	valuation := next()
+	assert.Equal(t, TradeEventValuation, valuation.Type)
+	assert.True(t, valuation.Value.Equal(decimal.NewFromInt(9500)), valuation.Value.String())
+	assert.True(t, valuation.Peak.Equal(decimal.NewFromInt(10100)))
+	assert.InDelta(t, 600.0/10100, valuation.Drawdown.InexactFloat64(), 1e-9)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		engine.mu.RLock()
+		defer engine.mu.RUnlock()
+		return engine.tradeEvents == nil
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Trade sides recorded in a portfolio's trade history
+const (
+	TradeSideBuy  = "buy"
+	TradeSideSell = "sell"
+)
+
+// TradeRecord is one executed trade in a portfolio's history. Opening a position is recorded
+// as a buy; closing or reducing it is recorded as a sell carrying the realized P&L. Tokens
+// moved in without a trade are recorded as transfers or receipts.
+type TradeRecord struct {
+	ID          uuid.UUID       `json:"id"`
+	PortfolioID uuid.UUID       `json:"portfolio_id"`
+	PositionID  uuid.UUID       `json:"position_id"`
+	Symbol      string          `json:"symbol"`
+	Side        string          `json:"side"`
+	Quantity    decimal.Decimal `json:"quantity"` // tokens
+	Price       decimal.Decimal `json:"price"`
+	Fees        decimal.Decimal `json:"fees"`
+	RealizedPnL decimal.Decimal `json:"realized_pnl"`
+	ExecutedAt  time.Time       `json:"executed_at"`
+
+	// Estimated from the order book when one is available for the symbol
+	SlippageBps *decimal.Decimal `json:"slippage_bps,omitempty"`
+
+	// Set on transfers and receipts, which have no position
+	FromAddress      string `json:"from_address,omitempty"`
+	ToAddress        string `json:"to_address,omitempty"`
+	CostBasisMissing bool   `json:"cost_basis_missing,omitempty"`
+}
+
+// GetTradeHistory returns the trades executed in a portfolio, oldest first
+func (t *TradingEngine) GetTradeHistory(portfolioID uuid.UUID) ([]TradeRecord, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, exists := t.portfolios[portfolioID]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioID.String())
+	}
+
+	trades := make([]TradeRecord, len(t.trades[portfolioID]))
+	copy(trades, t.trades[portfolioID])
+	return trades, nil
+}
+
+// recordTradeLocked appends a trade of a position to its portfolio's history and reports it to
+// the trade event listener. Positions are sized in the quote currency, so the amount traded is
+// recorded as the tokens it bought at the entry price, the quantity tax lots are matched in.
+// Simulated executions carry no fees. The caller must hold t.mu.
+func (t *TradingEngine) recordTradeLocked(position *Position, side string, amount, price, realizedPnL decimal.Decimal, executedAt time.Time, slippageBps *decimal.Decimal) {
+	quantity := amount
+	if position.EntryPrice.IsPositive() {
+		quantity = amount.Div(position.EntryPrice)
+	}
+	trade := TradeRecord{
+		ID:          uuid.New(),
+		PortfolioID: position.PortfolioID,
+		PositionID:  position.ID,
+		Symbol:      position.TokenSymbol,
+		Side:        side,
+		Quantity:    quantity,
+		Price:       price,
+		Fees:        decimal.Zero,
+		RealizedPnL: realizedPnL,
+		ExecutedAt:  executedAt,
+		SlippageBps: slippageBps,
+	}
+	t.trades[position.PortfolioID] = append(t.trades[position.PortfolioID], trade)
+	t.emitFillLocked(position, trade)
+}
+
+// PseudonymizeUser replaces a user's ID with pseudonym on their portfolios and open positions,
+// for erasure requests: trade history is a financial record and is kept. It returns the number
+// of records changed.
+func (t *TradingEngine) PseudonymizeUser(ctx context.Context, userID, pseudonym uuid.UUID) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changed := 0
+	for _, portfolio := range t.portfolios {
+		if portfolio.UserID == userID {
+			portfolio.UserID = pseudonym
+			changed++
+		}
+	}
+	for _, position := range t.activePositions {
+		if position.UserID == userID {
+			position.UserID = pseudonym
+			changed++
+		}
+	}
+	return changed, nil
+}
@@ -2,16 +2,26 @@ package web3
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/security"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+var (
+	// ErrPortfolioNotFound is returned for an unknown portfolio ID
+	ErrPortfolioNotFound = errors.New("portfolio not found")
+	// ErrInsufficientBalance is returned when a trade needs more than the portfolio's available balance
+	ErrInsufficientBalance = errors.New("insufficient available balance")
+)
+
 // TradingEngine provides autonomous trading capabilities
 type TradingEngine struct {
 	clients         map[int]*ethclient.Client
@@ -21,9 +31,29 @@ type TradingEngine struct {
 	activePositions map[string]*Position
 	portfolios      map[uuid.UUID]*Portfolio
 	config          TradingConfig
+	orderBooks      OrderBookSource
+	trades          map[uuid.UUID][]TradeRecord
 	isRunning       bool
 	stopChan        chan struct{}
 	mu              sync.RWMutex
+
+	killSwitch      KillSwitchConfig
+	killSwitchState KillSwitchState
+	killSwitchStore KillSwitchStore
+	auditor         *security.AuditManager
+	lastMarketData  time.Time
+
+	// Fills and valuations are queued for the listener while PublishTradeEvents runs
+	tradeEvents chan TradeEvent
+	equityPeaks map[uuid.UUID]decimal.Decimal
+
+	// Strategies open positions only within their schedule and outside blackouts
+	calendar        *TradingCalendar
+	schedules       map[string]*TradingSchedule
+	strategyMetrics map[string]*StrategyMetrics
+
+	// Why no strategy may open positions, such as maintenance; empty while openings are allowed
+	openingsHalted string
 }
 
 // TradingConfig holds configuration for the trading engine
@@ -57,20 +87,22 @@ type TradingStrategy interface {
 
 // TradingSignal represents a trading signal from a strategy
 type TradingSignal struct {
-	ID           uuid.UUID              `json:"id"`
-	StrategyName string                 `json:"strategy_name"`
-	Action       TradingAction          `json:"action"`
-	TokenIn      string                 `json:"token_in"`
-	TokenOut     string                 `json:"token_out"`
-	AmountIn     decimal.Decimal        `json:"amount_in"`
-	ExpectedOut  decimal.Decimal        `json:"expected_out"`
-	Confidence   float64                `json:"confidence"`
-	Urgency      SignalUrgency          `json:"urgency"`
-	ValidUntil   time.Time              `json:"valid_until"`
-	StopLoss     *decimal.Decimal       `json:"stop_loss,omitempty"`
-	TakeProfit   *decimal.Decimal       `json:"take_profit,omitempty"`
-	Metadata     map[string]interface{} `json:"metadata"`
-	CreatedAt    time.Time              `json:"created_at"`
+	ID                uuid.UUID              `json:"id"`
+	StrategyName      string                 `json:"strategy_name"`
+	Action            TradingAction          `json:"action"`
+	TokenIn           string                 `json:"token_in"`
+	TokenOut          string                 `json:"token_out"`
+	AmountIn          decimal.Decimal        `json:"amount_in"`
+	ExpectedOut       decimal.Decimal        `json:"expected_out"`
+	Confidence        float64                `json:"confidence"`
+	Urgency           SignalUrgency          `json:"urgency"`
+	ValidUntil        time.Time              `json:"valid_until"`
+	StopLoss          *decimal.Decimal       `json:"stop_loss,omitempty"`
+	TakeProfit        *decimal.Decimal       `json:"take_profit,omitempty"`
+	StopLossPercent   *decimal.Decimal       `json:"stop_loss_percent,omitempty"`   // fraction below entry
+	TakeProfitPercent *decimal.Decimal       `json:"take_profit_percent,omitempty"` // fraction above entry
+	Metadata          map[string]interface{} `json:"metadata"`
+	CreatedAt         time.Time              `json:"created_at"`
 }
 
 // TradingAction represents the type of trading action
@@ -99,6 +131,7 @@ const (
 type Position struct {
 	ID            uuid.UUID              `json:"id"`
 	UserID        uuid.UUID              `json:"user_id"`
+	PortfolioID   uuid.UUID              `json:"portfolio_id"`
 	StrategyName  string                 `json:"strategy_name"`
 	TokenAddress  string                 `json:"token_address"`
 	TokenSymbol   string                 `json:"token_symbol"`
@@ -113,7 +146,13 @@ type Position struct {
 	OpenedAt      time.Time              `json:"opened_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
 	ClosedAt      *time.Time             `json:"closed_at,omitempty"`
+	CloseReason   string                 `json:"close_reason,omitempty"`
 	Metadata      map[string]interface{} `json:"metadata"`
+
+	// InitialAmount is the amount opened; partial closes reduce Amount
+	InitialAmount decimal.Decimal `json:"initial_amount"`
+	ScaleOut      []ScaleOutRule  `json:"scale_out,omitempty"`
+	Events        []PositionEvent `json:"events"`
 }
 
 // PositionStatus represents the status of a position
@@ -226,6 +265,10 @@ func NewTradingEngine(
 		strategies:      make(map[string]TradingStrategy),
 		activePositions: make(map[string]*Position),
 		portfolios:      make(map[uuid.UUID]*Portfolio),
+		trades:          make(map[uuid.UUID][]TradeRecord),
+		equityPeaks:     make(map[uuid.UUID]decimal.Decimal),
+		schedules:       make(map[string]*TradingSchedule),
+		strategyMetrics: make(map[string]*StrategyMetrics),
 		config:          config,
 		stopChan:        make(chan struct{}),
 	}
@@ -353,6 +396,10 @@ func (t *TradingEngine) rebalancingLoop(ctx context.Context) {
 // executeTrading executes trading strategies
 func (t *TradingEngine) executeTrading(ctx context.Context) {
 	t.mu.RLock()
+	if t.killSwitchState.Tripped {
+		t.mu.RUnlock()
+		return
+	}
 	portfolios := make([]*Portfolio, 0, len(t.portfolios))
 	for _, portfolio := range t.portfolios {
 		portfolios = append(portfolios, portfolio)
@@ -420,6 +467,11 @@ func (t *TradingEngine) executeTrading(ctx context.Context) {
 
 // executeSignal executes a trading signal
 func (t *TradingEngine) executeSignal(ctx context.Context, portfolio *Portfolio, signal *TradingSignal) error {
+	// Closing positions stays allowed outside trading hours and during blackouts
+	if t.suppressedBySchedule(ctx, portfolio, signal) {
+		return nil
+	}
+
 	// Perform risk assessment
 	if err := t.assessSignalRisk(ctx, portfolio, signal); err != nil {
 		return fmt.Errorf("signal risk assessment failed: %w", err)
@@ -446,6 +498,10 @@ func (t *TradingEngine) executeSignal(ctx context.Context, portfolio *Portfolio,
 	// Update portfolio
 	t.updatePortfolioAfterTrade(ctx, portfolio, position)
 
+	t.mu.Lock()
+	t.strategyMetricsLocked(signal.StrategyName).SignalsExecuted++
+	t.mu.Unlock()
+
 	t.logger.Info(ctx, "Signal executed successfully", map[string]interface{}{
 		"signal_id":    signal.ID.String(),
 		"position_id":  position.ID.String(),
@@ -508,7 +564,7 @@ func (t *TradingEngine) checkPortfolioLimits(ctx context.Context, portfolio *Por
 
 	// Check available balance
 	if signal.AmountIn.GreaterThan(portfolio.AvailableBalance) {
-		return fmt.Errorf("insufficient available balance")
+		return ErrInsufficientBalance
 	}
 
 	return nil
@@ -516,34 +572,70 @@ func (t *TradingEngine) checkPortfolioLimits(ctx context.Context, portfolio *Por
 
 // executeTrade executes the actual trade
 func (t *TradingEngine) executeTrade(ctx context.Context, portfolio *Portfolio, signal *TradingSignal, positionSize decimal.Decimal) (*Position, error) {
+	entryPrice := signal.ExpectedOut.Div(signal.AmountIn) // Price per token
+	stopLoss, takeProfit, err := t.signalProtection(portfolio, signal).resolve(entryPrice)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create position
 	position := &Position{
 		ID:            uuid.New(),
 		UserID:        portfolio.UserID,
+		PortfolioID:   portfolio.ID,
 		StrategyName:  signal.StrategyName,
 		TokenAddress:  signal.TokenOut,
 		TokenSymbol:   signal.TokenOut, // Simplified - would resolve symbol
 		Amount:        positionSize,
-		EntryPrice:    signal.ExpectedOut.Div(signal.AmountIn), // Price per token
-		CurrentPrice:  signal.ExpectedOut.Div(signal.AmountIn),
+		EntryPrice:    entryPrice,
+		CurrentPrice:  entryPrice,
 		UnrealizedPnL: decimal.Zero,
 		RealizedPnL:   decimal.Zero,
-		StopLoss:      signal.StopLoss,
-		TakeProfit:    signal.TakeProfit,
+		StopLoss:      stopLoss,
+		TakeProfit:    takeProfit,
 		Status:        PositionStatusPending,
 		OpenedAt:      time.Now(),
 		UpdatedAt:     time.Now(),
 		Metadata:      signal.Metadata,
+		InitialAmount: positionSize,
 	}
 
 	// In a real implementation, this would interact with DEX contracts
 	// For now, simulate successful execution
 	position.Status = PositionStatusOpen
 
+	// Fills are simulated at the signal price; the order book estimates what a live fill would
+	// slip, and trades it cannot absorb within the slippage tolerance are rejected. Positions
+	// are sized in the quote currency, so the book is walked for the tokens bought.
+	var slippage *decimal.Decimal
+	quantity := positionSize
+	if entryPrice.IsPositive() {
+		quantity = positionSize.Div(entryPrice)
+	}
+	estimate, err := t.CheckSlippage(position.TokenSymbol+"-USD", signal.Action, quantity)
+	switch {
+	case errors.Is(err, ErrSlippageTooHigh):
+		return nil, err
+	case err == nil:
+		slippage = &estimate.SlippageBps
+	}
+
 	// Store position
 	t.mu.Lock()
+	if t.killSwitchState.Tripped {
+		t.mu.Unlock()
+		return nil, ErrTradingHalted
+	}
 	t.activePositions[position.ID.String()] = position
 	portfolio.ActivePositions = append(portfolio.ActivePositions, position.ID)
+	position.Events = append(position.Events, PositionEvent{
+		Type:            PositionEventOpened,
+		Quantity:        position.Amount,
+		Price:           position.EntryPrice,
+		RemainingAmount: position.Amount,
+		At:              position.OpenedAt,
+	})
+	t.recordTradeLocked(position, TradeSideBuy, position.Amount, position.EntryPrice, decimal.Zero, position.OpenedAt, slippage)
 	t.mu.Unlock()
 
 	return position, nil
@@ -626,6 +718,9 @@ func (t *TradingEngine) rebalancePortfolio(ctx context.Context, portfolio *Portf
 
 	// Simple rebalancing: if we have significant losses, reduce position sizes
 	if portfolio.DailyPnL.IsNegative() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
 		for _, positionID := range portfolio.ActivePositions {
 			position, exists := t.activePositions[positionID.String()]
 			if !exists {
@@ -636,6 +731,9 @@ func (t *TradingEngine) rebalancePortfolio(ctx context.Context, portfolio *Portf
 			reductionAmount := position.Amount.Mul(decimal.NewFromFloat(0.1))
 			position.Amount = position.Amount.Sub(reductionAmount)
 			portfolio.AvailableBalance = portfolio.AvailableBalance.Add(reductionAmount)
+
+			realizedPnL := position.CurrentPrice.Sub(position.EntryPrice).Mul(reductionAmount)
+			t.recordTradeLocked(position, TradeSideSell, reductionAmount, position.CurrentPrice, realizedPnL, time.Now(), nil)
 		}
 	}
 
@@ -649,12 +747,39 @@ func (t *TradingEngine) GetPortfolio(portfolioID uuid.UUID) (*Portfolio, error)
 
 	portfolio, exists := t.portfolios[portfolioID]
 	if !exists {
-		return nil, fmt.Errorf("portfolio not found: %s", portfolioID.String())
+		return nil, fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioID.String())
 	}
 
 	return portfolio, nil
 }
 
+// ListPortfolios returns all portfolios managed by the engine
+func (t *TradingEngine) ListPortfolios() []*Portfolio {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	portfolios := make([]*Portfolio, 0, len(t.portfolios))
+	for _, portfolio := range t.portfolios {
+		portfolios = append(portfolios, portfolio)
+	}
+	return portfolios
+}
+
+// ListUserPortfolios returns the portfolios owned by a user, ordered by name
+func (t *TradingEngine) ListUserPortfolios(ctx context.Context, userID uuid.UUID) ([]*Portfolio, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	portfolios := make([]*Portfolio, 0)
+	for _, portfolio := range t.portfolios {
+		if portfolio.UserID == userID {
+			portfolios = append(portfolios, portfolio)
+		}
+	}
+	sort.Slice(portfolios, func(i, j int) bool { return portfolios[i].Name < portfolios[j].Name })
+	return portfolios, nil
+}
+
 // isStrategyAllowed checks if a strategy is allowed for a portfolio
 func (t *TradingEngine) isStrategyAllowed(portfolio *Portfolio, strategyName string) bool {
 	if len(portfolio.TradingStrategies) == 0 {
@@ -708,7 +833,7 @@ func (t *TradingEngine) UpdatePortfolioValue(ctx context.Context, portfolioID uu
 
 	portfolio, exists := t.portfolios[portfolioID]
 	if !exists {
-		return fmt.Errorf("portfolio not found: %s", portfolioID.String())
+		return fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioID.String())
 	}
 
 	// Calculate total value from holdings
@@ -734,7 +859,7 @@ func (t *TradingEngine) GetActivePositions(portfolioID uuid.UUID) ([]*Position,
 
 	portfolio, exists := t.portfolios[portfolioID]
 	if !exists {
-		return nil, fmt.Errorf("portfolio not found: %s", portfolioID.String())
+		return nil, fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioID.String())
 	}
 
 	var positions []*Position
@@ -747,6 +872,35 @@ func (t *TradingEngine) GetActivePositions(portfolioID uuid.UUID) ([]*Position,
 	return positions, nil
 }
 
+// GetCurrentAllocations returns the share of a portfolio's total value held in each asset,
+// valuing holdings at their current price. The available balance counts towards the total.
+func (t *TradingEngine) GetCurrentAllocations(portfolioID uuid.UUID) (map[string]decimal.Decimal, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	portfolio, exists := t.portfolios[portfolioID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioID.String())
+	}
+
+	total := portfolio.AvailableBalance
+	values := make(map[string]decimal.Decimal, len(portfolio.Holdings))
+	for asset, holding := range portfolio.Holdings {
+		value := holding.Amount.Mul(holding.CurrentPrice)
+		values[asset] = value
+		total = total.Add(value)
+	}
+
+	allocations := make(map[string]decimal.Decimal, len(values))
+	if !total.IsPositive() {
+		return allocations, nil
+	}
+	for asset, value := range values {
+		allocations[asset] = value.Div(total)
+	}
+	return allocations, nil
+}
+
 // ClosePosition closes a trading position
 func (t *TradingEngine) ClosePosition(ctx context.Context, positionID uuid.UUID, reason string) error {
 	t.mu.Lock()
@@ -754,27 +908,45 @@ func (t *TradingEngine) ClosePosition(ctx context.Context, positionID uuid.UUID,
 
 	position, exists := t.activePositions[positionID.String()]
 	if !exists {
-		return fmt.Errorf("position not found: %s", positionID.String())
+		return fmt.Errorf("%w: %s", ErrPositionNotFound, positionID.String())
 	}
 
+	t.closePositionLocked(ctx, position, reason)
+	return nil
+}
+
+// closePositionLocked realizes the position P&L and releases its funds to the portfolio.
+// The caller must hold t.mu.
+func (t *TradingEngine) closePositionLocked(ctx context.Context, position *Position, reason string) {
 	// Update position status
 	position.Status = PositionStatusClosed
 	now := time.Now()
 	position.ClosedAt = &now
 	position.UpdatedAt = now
-
-	// Calculate realized P&L
-	position.RealizedPnL = position.UnrealizedPnL
+	position.CloseReason = reason
+
+	// Realize the P&L of the remaining amount on top of earlier partial closes
+	realizedPnL := position.UnrealizedPnL
+	position.RealizedPnL = position.RealizedPnL.Add(realizedPnL)
+	position.Events = append(position.Events, PositionEvent{
+		Type:        PositionEventClosed,
+		Quantity:    position.Amount,
+		Price:       position.CurrentPrice,
+		RealizedPnL: realizedPnL,
+		Reason:      reason,
+		At:          now,
+	})
+	t.recordTradeLocked(position, TradeSideSell, position.Amount, position.CurrentPrice, realizedPnL, now, nil)
 
 	// Remove from active positions
-	delete(t.activePositions, positionID.String())
+	delete(t.activePositions, position.ID.String())
 
 	// Update portfolio
-	portfolio := t.portfolios[position.UserID]
+	portfolio := t.portfolios[position.PortfolioID]
 	if portfolio != nil {
 		// Remove from active positions list
 		for i, activeID := range portfolio.ActivePositions {
-			if activeID == positionID {
+			if activeID == position.ID {
 				portfolio.ActivePositions = append(portfolio.ActivePositions[:i], portfolio.ActivePositions[i+1:]...)
 				break
 			}
@@ -783,14 +955,12 @@ func (t *TradingEngine) ClosePosition(ctx context.Context, positionID uuid.UUID,
 		// Add back to available balance
 		portfolio.AvailableBalance = portfolio.AvailableBalance.Add(position.Amount)
 		portfolio.InvestedAmount = portfolio.InvestedAmount.Sub(position.Amount)
-		portfolio.TotalPnL = portfolio.TotalPnL.Add(position.RealizedPnL)
+		portfolio.TotalPnL = portfolio.TotalPnL.Add(realizedPnL)
 	}
 
 	t.logger.Info(ctx, "Position closed", map[string]interface{}{
-		"position_id":  positionID.String(),
+		"position_id":  position.ID.String(),
 		"realized_pnl": position.RealizedPnL.String(),
 		"reason":       reason,
 	})
-
-	return nil
 }
@@ -3,6 +3,7 @@ package web3
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/observability"
@@ -69,6 +70,50 @@ func TestTradingEngine(t *testing.T) {
 		err = engine.UpdatePortfolioValue(context.Background(), portfolio.ID)
 		assert.NoError(t, err)
 	})
+
+	t.Run("TradeHistory", func(t *testing.T) {
+		portfolio, position := openTestPosition(t, engine, ethSignal())
+		engine.ApplyPrice(context.Background(), "ETH", decimal.NewFromInt(1700))
+
+		trades, err := engine.GetTradeHistory(portfolio.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, trades, 2) {
+			assert.Equal(t, TradeSideBuy, trades[0].Side)
+			assert.Equal(t, position.ID, trades[0].PositionID)
+			assert.True(t, trades[0].Price.Equal(decimal.NewFromInt(2000)))
+			assert.Equal(t, TradeSideSell, trades[1].Side)
+			assert.True(t, trades[1].Price.Equal(decimal.NewFromInt(1700)))
+			assert.True(t, trades[1].RealizedPnL.Equal(decimal.NewFromInt(-300)))
+
+			// Quantities are in tokens, not the quote currency the position is sized in
+			tokens := position.Amount.Div(decimal.NewFromInt(2000))
+			assert.True(t, trades[0].Quantity.Equal(tokens), "bought %s, want %s", trades[0].Quantity, tokens)
+			assert.True(t, trades[1].Quantity.Equal(tokens), "sold %s, want %s", trades[1].Quantity, tokens)
+		}
+
+		_, err = engine.GetTradeHistory(uuid.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("PseudonymizeUser", func(t *testing.T) {
+		engine := newProtectionTestEngine()
+		portfolio, position := openTestPosition(t, engine, ethSignal())
+		userID, pseudonym := portfolio.UserID, uuid.New()
+
+		changed, err := engine.PseudonymizeUser(context.Background(), userID, pseudonym)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, changed, "the portfolio and its open position")
+		assert.Equal(t, pseudonym, portfolio.UserID)
+		assert.Equal(t, pseudonym, position.UserID)
+
+		trades, err := engine.GetTradeHistory(portfolio.ID)
+		assert.NoError(t, err)
+		assert.Len(t, trades, 1, "trade history is kept")
+
+		changed, err = engine.PseudonymizeUser(context.Background(), userID, pseudonym)
+		assert.NoError(t, err)
+		assert.Zero(t, changed)
+	})
 }
 
 func TestTradingStrategies(t *testing.T) {
@@ -282,6 +327,30 @@ func TestPortfolioRebalancer(t *testing.T) {
 		assert.True(t, strategy.IsActive)
 	})
 
+	t.Run("GetRebalanceStrategy", func(t *testing.T) {
+		portfolioID := uuid.New()
+		_, err := rebalancer.GetRebalanceStrategy(context.Background(), portfolioID)
+		assert.Error(t, err)
+
+		first, err := rebalancer.CreateRebalanceStrategy(context.Background(), portfolioID, "Initial",
+			RebalanceTypeFixed, map[string]decimal.Decimal{"ETH": decimal.NewFromInt(1)})
+		assert.NoError(t, err)
+		second, err := rebalancer.CreateRebalanceStrategy(context.Background(), portfolioID, "Replacement",
+			RebalanceTypeDynamic, map[string]decimal.Decimal{"ETH": decimal.NewFromFloat(0.5), "USDC": decimal.NewFromFloat(0.5)})
+		assert.NoError(t, err)
+
+		details, err := rebalancer.GetRebalanceStrategy(context.Background(), portfolioID)
+		assert.NoError(t, err)
+		assert.Equal(t, second.ID, details.ID)
+		assert.True(t, details.IsActive)
+		assert.True(t, decimal.NewFromFloat(0.03).Equal(details.DriftThreshold))
+		assert.Equal(t, second.CreatedAt.Add(6*time.Hour), details.NextEvaluation)
+		if assert.Len(t, details.History, 1) {
+			assert.Equal(t, first.ID, details.History[0].ID)
+			assert.False(t, details.History[0].IsActive)
+		}
+	})
+
 	t.Run("InvalidAllocations", func(t *testing.T) {
 		portfolioID := uuid.New()
 		invalidAllocations := map[string]decimal.Decimal{
@@ -302,6 +371,128 @@ func TestPortfolioRebalancer(t *testing.T) {
 		assert.Contains(t, err.Error(), "must sum to 100%")
 	})
 
+	t.Run("ScheduleNextRun", func(t *testing.T) {
+		since := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC) // Wednesday
+
+		daily := RebalanceSchedule{Frequency: ScheduleFrequencyDaily, TimeOfDay: "09:30"}
+		assert.NoError(t, daily.Validate())
+		assert.Equal(t, time.Date(2024, 3, 7, 9, 30, 0, 0, time.UTC), daily.NextRun(since))
+
+		weekly := RebalanceSchedule{Frequency: ScheduleFrequencyWeekly, Weekday: "Friday", TimeOfDay: "12:00"}
+		assert.NoError(t, weekly.Validate())
+		assert.Equal(t, time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC), weekly.NextRun(since))
+
+		interval := RebalanceSchedule{Frequency: ScheduleFrequencyInterval, Interval: "6h"}
+		assert.Equal(t, since.Add(6*time.Hour), interval.NextRun(since))
+		assert.True(t, (&RebalanceSchedule{Frequency: ScheduleFrequencyDrift}).NextRun(since).IsZero())
+
+		for _, invalid := range []RebalanceSchedule{
+			{Frequency: "hourly"},
+			{Frequency: ScheduleFrequencyInterval, Interval: "10s"},
+			{Frequency: ScheduleFrequencyDaily, TimeOfDay: "25:00"},
+			{Frequency: ScheduleFrequencyWeekly, Weekday: "someday"},
+		} {
+			assert.ErrorIs(t, invalid.Validate(), ErrInvalidRebalanceSchedule)
+		}
+	})
+
+	t.Run("ScheduledRebalance", func(t *testing.T) {
+		ctx := context.Background()
+		portfolio, err := tradingEngine.CreatePortfolio(ctx, uuid.New(), "Scheduled", decimal.NewFromInt(1000), RiskProfile{Level: "moderate"})
+		assert.NoError(t, err)
+		portfolio.Holdings["ETH"] = &Holding{TokenSymbol: "ETH", Amount: decimal.NewFromInt(4), CurrentPrice: decimal.NewFromInt(1000)}
+
+		_, err = rebalancer.CreateRebalanceStrategy(ctx, portfolio.ID, "Half ETH", RebalanceTypeMomentum,
+			map[string]decimal.Decimal{"ETH": decimal.NewFromFloat(0.5), "USDC": decimal.NewFromFloat(0.5)})
+		assert.NoError(t, err)
+
+		allocations, err := tradingEngine.GetCurrentAllocations(portfolio.ID)
+		assert.NoError(t, err)
+		assert.True(t, allocations["ETH"].Equal(decimal.NewFromFloat(0.8)))
+
+		preview, err := rebalancer.PreviewRebalance(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, RebalanceStatusPreview, preview.Status)
+		assert.True(t, preview.DryRun)
+		assert.Len(t, preview.Actions, 2)
+
+		_, err = rebalancer.SetRebalanceSchedule(ctx, portfolio.ID, &RebalanceSchedule{Frequency: ScheduleFrequencyDaily, TimeOfDay: "00:00"})
+		assert.NoError(t, err)
+		details, err := rebalancer.GetRebalanceStrategy(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, details.Executions, "previews are not recorded")
+
+		now := time.Now()
+		assert.Empty(t, rebalancer.RunScheduled(ctx, now), "not due before the next daily run")
+		executions := rebalancer.RunScheduled(ctx, details.NextEvaluation)
+		if assert.Len(t, executions, 1) {
+			assert.Equal(t, RebalanceTriggerScheduled, executions[0].Trigger)
+			assert.Equal(t, RebalanceStatusCompleted, executions[0].Status)
+		}
+
+		_, err = rebalancer.SetRebalanceSchedule(ctx, portfolio.ID, &RebalanceSchedule{Frequency: ScheduleFrequencyDrift})
+		assert.NoError(t, err)
+		assert.Empty(t, rebalancer.RunScheduled(ctx, now.Add(time.Hour)), "drift runs wait for the rebalance interval")
+		executions = rebalancer.RunScheduled(ctx, now.Add(7*24*time.Hour))
+		if assert.Len(t, executions, 1) {
+			assert.Equal(t, RebalanceTriggerDrift, executions[0].Trigger)
+		}
+
+		manual, err := rebalancer.RebalancePortfolio(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, RebalanceTriggerManual, manual.Trigger)
+
+		details, err = rebalancer.GetRebalanceStrategy(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, details.Executions, 3) {
+			assert.Equal(t, RebalanceTriggerManual, details.Executions[0].Trigger)
+			assert.Equal(t, RebalanceTriggerDrift, details.Executions[1].Trigger)
+			assert.Equal(t, RebalanceTriggerScheduled, details.Executions[2].Trigger)
+		}
+
+		_, err = rebalancer.PreviewRebalance(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrRebalanceStrategyNotFound)
+	})
+
+	t.Run("PreviewCosts", func(t *testing.T) {
+		ctx := context.Background()
+		portfolio, err := tradingEngine.CreatePortfolio(ctx, uuid.New(), "Priced", decimal.NewFromInt(1000), RiskProfile{Level: "moderate"})
+		assert.NoError(t, err)
+		portfolio.Holdings["ETH"] = &Holding{TokenSymbol: "ETH", Amount: decimal.NewFromInt(4), CurrentPrice: decimal.NewFromInt(1000)}
+
+		_, err = rebalancer.CreateRebalanceStrategy(ctx, portfolio.ID, "Half ETH", RebalanceTypeMomentum,
+			map[string]decimal.Decimal{"ETH": decimal.NewFromFloat(0.5), "USDC": decimal.NewFromFloat(0.5)})
+		assert.NoError(t, err)
+
+		preview, err := rebalancer.PreviewRebalance(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, preview.Costs, "previews are unpriced without an estimator")
+
+		estimator := &fixedCostEstimator{estimate: &RebalanceCostEstimate{
+			AssetCosts:     map[string]decimal.Decimal{"ETH": decimal.NewFromInt(3), "USDC": decimal.NewFromInt(1)},
+			EstimatedCost:  decimal.NewFromInt(4),
+			NetBenefit:     decimal.NewFromInt(-4),
+			Recommendation: RebalanceRecommendNoTrade,
+		}}
+		rebalancer.SetCostEstimator(estimator)
+		defer rebalancer.SetCostEstimator(nil)
+
+		preview, err = rebalancer.PreviewRebalance(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, preview.Costs) {
+			assert.Equal(t, RebalanceRecommendNoTrade, preview.Costs.Recommendation)
+			assert.True(t, preview.Costs.EstimatedCost.Equal(decimal.NewFromInt(4)))
+		}
+		for _, action := range preview.Actions {
+			assert.True(t, action.ExpectedCost.Equal(estimator.estimate.AssetCosts[action.ToAsset]), action.ToAsset)
+		}
+		assert.Equal(t, portfolio.ID, estimator.portfolioID)
+
+		executed, err := rebalancer.RebalancePortfolio(ctx, portfolio.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, executed.Costs, "only previews are priced")
+	})
+
 	t.Run("RebalanceTypes", func(t *testing.T) {
 		types := []RebalanceType{
 			RebalanceTypeFixed,
@@ -405,3 +596,14 @@ func TestRiskProfiles(t *testing.T) {
 		assert.Contains(t, profile.AllowedStrategies, "momentum")
 	})
 }
+
+// fixedCostEstimator returns the same cost estimate for every rebalance
+type fixedCostEstimator struct {
+	estimate    *RebalanceCostEstimate
+	portfolioID uuid.UUID
+}
+
+func (e *fixedCostEstimator) EstimateRebalanceCost(ctx context.Context, portfolio *Portfolio, targets map[string]decimal.Decimal) (*RebalanceCostEstimate, error) {
+	e.portfolioID = portfolio.ID
+	return e.estimate, nil
+}
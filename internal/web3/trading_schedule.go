@@ -0,0 +1,557 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+)
+
+// SuppressedBySchedule is the metric and log reason of signals skipped because new positions
+// were not allowed by a trading schedule or blackout
+const SuppressedBySchedule = "suppressed_by_schedule"
+
+const (
+	// blackoutsKey is the Redis hash holding blackouts by ID
+	blackoutsKey = "web3:trading:blackouts"
+	// blackoutRefreshInterval bounds how long a blackout created by another service goes unseen
+	blackoutRefreshInterval = 30 * time.Second
+	// maxBlackoutDuration bounds a single blackout so a typo can't halt trading for years
+	maxBlackoutDuration = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidTradingSchedule is returned for schedules with unknown days, times or timezones
+	ErrInvalidTradingSchedule = errors.New("invalid trading schedule")
+	// ErrInvalidBlackout is returned for blackouts with an empty, past or too long time range
+	ErrInvalidBlackout = errors.New("invalid blackout")
+	// ErrBlackoutNotFound is returned when deleting an unknown blackout
+	ErrBlackoutNotFound = errors.New("blackout not found")
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// TradingWindow allows trading between Start and End ("HH:MM", End may be "24:00") on Days
+// ("mon" to "sun", every day when empty). A window ending before it starts runs past midnight
+// into the next day.
+type TradingWindow struct {
+	Days  []string `json:"days,omitempty" yaml:"days"`
+	Start string   `json:"start" yaml:"start"`
+	End   string   `json:"end" yaml:"end"`
+}
+
+// TradingSchedule restricts when a strategy may open positions to weekly windows in a timezone.
+// A nil schedule or one without windows allows trading at any time.
+type TradingSchedule struct {
+	Timezone string          `json:"timezone,omitempty" yaml:"timezone"` // IANA name, defaults to UTC
+	Windows  []TradingWindow `json:"windows" yaml:"windows"`
+}
+
+// Validate checks the timezone, days and times of the schedule
+func (s *TradingSchedule) Validate() error {
+	_, _, err := s.parse()
+	return err
+}
+
+// Allows reports whether positions may be opened at t. Invalid schedules allow nothing.
+func (s *TradingSchedule) Allows(t time.Time) bool {
+	if s == nil || len(s.Windows) == 0 {
+		return true
+	}
+	location, windows, err := s.parse()
+	if err != nil {
+		return false
+	}
+
+	local := t.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+	for _, window := range windows {
+		if window.start < window.end {
+			if window.days[today] && minute >= window.start && minute < window.end {
+				return true
+			}
+			continue
+		}
+		// Overnight windows belong to the day they start on
+		if (window.days[today] && minute >= window.start) || (window.days[yesterday] && minute < window.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsedWindow is a trading window in minutes since midnight
+type parsedWindow struct {
+	days       [7]bool
+	start, end int
+}
+
+func (s *TradingSchedule) parse() (*time.Location, []parsedWindow, error) {
+	location := time.UTC
+	if s.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidTradingSchedule, s.Timezone)
+		}
+	}
+
+	windows := make([]parsedWindow, 0, len(s.Windows))
+	for _, window := range s.Windows {
+		var parsed parsedWindow
+		for _, day := range window.Days {
+			name := strings.ToLower(strings.TrimSpace(day))
+			if len(name) > 3 {
+				name = name[:3] // monday, tuesday, ...
+			}
+			weekday, ok := weekdays[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: unknown day %q", ErrInvalidTradingSchedule, day)
+			}
+			parsed.days[weekday] = true
+		}
+		if len(window.Days) == 0 {
+			parsed.days = [7]bool{true, true, true, true, true, true, true}
+		}
+
+		var err error
+		if parsed.start, err = parseClock(window.Start); err != nil {
+			return nil, nil, err
+		}
+		if parsed.end, err = parseClock(window.End); err != nil {
+			return nil, nil, err
+		}
+		if parsed.start == parsed.end || parsed.start == 24*60 {
+			return nil, nil, fmt.Errorf("%w: window %s-%s is empty", ErrInvalidTradingSchedule, window.Start, window.End)
+		}
+		windows = append(windows, parsed)
+	}
+	return location, windows, nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight, accepting "24:00" as the end of day
+func parseClock(clock string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(strings.TrimSpace(clock), "%d:%d", &hour, &minute); err != nil ||
+		hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidTradingSchedule, clock)
+	}
+	return hour*60 + minute, nil
+}
+
+// Blackout stops all strategies from opening positions between Start and End, in every
+// symbol or only in Symbols. Positions may still be closed.
+type Blackout struct {
+	ID        uuid.UUID `json:"id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Symbols   []string  `json:"symbols,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateBlackoutRequest schedules a blackout, such as around an FOMC or CPI release
+type CreateBlackoutRequest struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Symbols []string  `json:"symbols,omitempty"`
+	Reason  string    `json:"reason"`
+}
+
+// Covers reports whether the blackout applies to symbol at t. A blackout symbol matches pairs
+// in any notation, so BTC covers BTC/USDT and BTC-USD, and BTC/USDT covers BTCUSDT.
+func (b *Blackout) Covers(symbol string, t time.Time) bool {
+	if t.Before(b.Start) || !t.Before(b.End) {
+		return false
+	}
+	if len(b.Symbols) == 0 {
+		return true
+	}
+	for _, scoped := range b.Symbols {
+		if symbolMatches(scoped, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+func symbolMatches(scoped, symbol string) bool {
+	compact := strings.NewReplacer("-", "", "/", "", "_", "")
+	scoped = strings.ToUpper(strings.TrimSpace(scoped))
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if compact.Replace(scoped) == compact.Replace(symbol) {
+		return true
+	}
+	base, _, separated := strings.Cut(strings.NewReplacer("-", "/", "_", "/").Replace(symbol), "/")
+	return separated && base == scoped
+}
+
+// BlackoutStore persists blackouts so every trading service honors them
+type BlackoutStore interface {
+	SaveBlackout(ctx context.Context, blackout Blackout) error
+	ListBlackouts(ctx context.Context) ([]Blackout, error)
+	DeleteBlackout(ctx context.Context, id uuid.UUID) error
+}
+
+// redisBlackoutStore keeps blackouts in a Redis hash shared by the web3 and trading bot services
+type redisBlackoutStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisBlackoutStore creates a store sharing blackouts through Redis
+func NewRedisBlackoutStore(redis *database.RedisClient) BlackoutStore {
+	return &redisBlackoutStore{redis: redis}
+}
+
+func (s *redisBlackoutStore) SaveBlackout(ctx context.Context, blackout Blackout) error {
+	data, err := json.Marshal(blackout)
+	if err != nil {
+		return err
+	}
+	return s.redis.Client.HSet(ctx, blackoutsKey, blackout.ID.String(), data).Err()
+}
+
+func (s *redisBlackoutStore) ListBlackouts(ctx context.Context) ([]Blackout, error) {
+	entries, err := s.redis.Client.HGetAll(ctx, blackoutsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	blackouts := make([]Blackout, 0, len(entries))
+	for id, data := range entries {
+		var blackout Blackout
+		if err := json.Unmarshal([]byte(data), &blackout); err != nil {
+			return nil, fmt.Errorf("invalid blackout %s: %w", id, err)
+		}
+		blackouts = append(blackouts, blackout)
+	}
+	return blackouts, nil
+}
+
+func (s *redisBlackoutStore) DeleteBlackout(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.redis.Client.HDel(ctx, blackoutsKey, id.String()).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: %s", ErrBlackoutNotFound, id)
+	}
+	return nil
+}
+
+// TradingCalendar decides whether a strategy may open positions from its schedule and the
+// blackouts in the store. Blackouts are cached and reloaded every 30 seconds, so blackouts
+// created through another service take effect within that delay.
+type TradingCalendar struct {
+	logger *observability.Logger
+	store  BlackoutStore
+
+	mu        sync.Mutex
+	blackouts []Blackout
+	loadedAt  time.Time
+}
+
+// NewTradingCalendar creates a trading calendar over the blackout store
+func NewTradingCalendar(logger *observability.Logger, store BlackoutStore) *TradingCalendar {
+	return &TradingCalendar{logger: logger, store: store}
+}
+
+// CreateBlackout validates and stores a blackout. Ended blackouts are pruned from the store.
+func (c *TradingCalendar) CreateBlackout(ctx context.Context, userID uuid.UUID, req CreateBlackoutRequest) (*Blackout, error) {
+	now := time.Now()
+	switch {
+	case req.Start.IsZero() || req.End.IsZero():
+		return nil, fmt.Errorf("%w: start and end are required", ErrInvalidBlackout)
+	case !req.End.After(req.Start):
+		return nil, fmt.Errorf("%w: end must be after start", ErrInvalidBlackout)
+	case !req.End.After(now):
+		return nil, fmt.Errorf("%w: end is in the past", ErrInvalidBlackout)
+	case req.End.Sub(req.Start) > maxBlackoutDuration:
+		return nil, fmt.Errorf("%w: blackouts may last at most %s", ErrInvalidBlackout, maxBlackoutDuration)
+	}
+
+	symbols := make([]string, 0, len(req.Symbols))
+	for _, symbol := range req.Symbols {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	blackout := &Blackout{
+		ID:        uuid.New(),
+		Start:     req.Start.UTC(),
+		End:       req.End.UTC(),
+		Symbols:   symbols,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: userID,
+		CreatedAt: now.UTC(),
+	}
+	if err := c.store.SaveBlackout(ctx, *blackout); err != nil {
+		return nil, fmt.Errorf("failed to save blackout: %w", err)
+	}
+
+	c.logger.Info(ctx, "Trading blackout scheduled", map[string]interface{}{
+		"blackout_id": blackout.ID.String(),
+		"start":       blackout.Start,
+		"end":         blackout.End,
+		"symbols":     blackout.Symbols,
+		"reason":      blackout.Reason,
+		"created_by":  userID.String(),
+	})
+
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+	return blackout, nil
+}
+
+// ListBlackouts returns the active and upcoming blackouts ordered by start
+func (c *TradingCalendar) ListBlackouts(ctx context.Context) ([]Blackout, error) {
+	blackouts, err := c.store.ListBlackouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	current := blackouts[:0]
+	for _, blackout := range blackouts {
+		if blackout.End.After(now) {
+			current = append(current, blackout)
+			continue
+		}
+		if err := c.store.DeleteBlackout(ctx, blackout.ID); err != nil && !errors.Is(err, ErrBlackoutNotFound) {
+			c.logger.Warn(ctx, "Failed to prune ended blackout", map[string]interface{}{
+				"blackout_id": blackout.ID.String(),
+				"error":       err.Error(),
+			})
+		}
+	}
+	sort.Slice(current, func(i, j int) bool {
+		return current[i].Start.Before(current[j].Start)
+	})
+	return current, nil
+}
+
+// DeleteBlackout cancels a blackout
+func (c *TradingCalendar) DeleteBlackout(ctx context.Context, id uuid.UUID) error {
+	if err := c.store.DeleteBlackout(ctx, id); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+	return nil
+}
+
+// Suppression returns why a strategy with the schedule may not open a position in symbol at t,
+// or "" when it may. A nil calendar only applies the schedule.
+func (c *TradingCalendar) Suppression(ctx context.Context, schedule *TradingSchedule, symbol string, t time.Time) string {
+	if !schedule.Allows(t) {
+		return "outside trading hours"
+	}
+	if c == nil {
+		return ""
+	}
+	for _, blackout := range c.current(ctx) {
+		if blackout.Covers(symbol, t) {
+			if blackout.Reason != "" {
+				return "blackout: " + blackout.Reason
+			}
+			return "blackout"
+		}
+	}
+	return ""
+}
+
+// current returns the cached blackouts, reloading them when stale. A failed reload keeps the
+// previous blackouts until the next refresh.
+func (c *TradingCalendar) current(ctx context.Context) []Blackout {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.loadedAt) < blackoutRefreshInterval {
+		return c.blackouts
+	}
+	c.loadedAt = time.Now()
+	blackouts, err := c.store.ListBlackouts(ctx)
+	if err != nil {
+		c.logger.Warn(ctx, "Failed to reload trading blackouts", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return c.blackouts
+	}
+	c.blackouts = blackouts
+	return c.blackouts
+}
+
+// ErrTradingStrategyNotFound is returned for an unknown trading engine strategy
+var ErrTradingStrategyNotFound = errors.New("trading strategy not found")
+
+// StrategyMetrics counts what happened to a strategy's signals
+type StrategyMetrics struct {
+	SignalsExecuted      int        `json:"signals_executed"`
+	SuppressedBySchedule int        `json:"suppressed_by_schedule"`
+	LastSuppressedAt     *time.Time `json:"last_suppressed_at,omitempty"`
+	LastSuppression      string     `json:"last_suppression,omitempty"`
+}
+
+// StrategyStatus describes a trading engine strategy, its schedule and its signal metrics
+type StrategyStatus struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Enabled     bool             `json:"enabled"`
+	RiskLevel   RiskLevel        `json:"risk_level"`
+	Schedule    *TradingSchedule `json:"schedule,omitempty"`
+	Metrics     StrategyMetrics  `json:"metrics"`
+}
+
+// SetTradingCalendar makes strategies skip opening positions during the calendar's blackouts
+func (t *TradingEngine) SetTradingCalendar(calendar *TradingCalendar) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calendar = calendar
+}
+
+// HaltOpenings stops every strategy from opening positions for the given reason until
+// ResumeOpenings is called. Signals that close positions still execute.
+func (t *TradingEngine) HaltOpenings(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.openingsHalted = reason
+}
+
+// ResumeOpenings lets strategies open positions again after HaltOpenings
+func (t *TradingEngine) ResumeOpenings() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.openingsHalted = ""
+}
+
+// SetStrategySchedule restricts when a strategy, named by its key such as "momentum" or its
+// own name, may open positions. A nil schedule lets it trade at any time.
+func (t *TradingEngine) SetStrategySchedule(name string, schedule *TradingSchedule) error {
+	if schedule != nil {
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	strategy, ok := t.strategies[name]
+	if !ok {
+		for _, candidate := range t.strategies {
+			if candidate.GetName() == name {
+				strategy, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTradingStrategyNotFound, name)
+	}
+
+	// Signals carry the strategy's own name
+	if schedule == nil {
+		delete(t.schedules, strategy.GetName())
+		return nil
+	}
+	t.schedules[strategy.GetName()] = schedule
+	return nil
+}
+
+// ListStrategies returns the strategies with their schedules and signal metrics, ordered by name
+func (t *TradingEngine) ListStrategies() []StrategyStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	statuses := make([]StrategyStatus, 0, len(t.strategies))
+	for _, strategy := range t.strategies {
+		name := strategy.GetName()
+		status := StrategyStatus{
+			Name:        name,
+			Description: strategy.GetDescription(),
+			Enabled:     strategy.IsEnabled(),
+			RiskLevel:   strategy.GetRiskLevel(),
+			Schedule:    t.schedules[name],
+		}
+		if metrics, ok := t.strategyMetrics[name]; ok {
+			status.Metrics = *metrics
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// opensPosition reports whether executing a signal with the action opens a new position
+func opensPosition(action TradingAction) bool {
+	return action == ActionBuy || action == ActionSwap || action == ActionStake
+}
+
+// suppressedBySchedule reports whether the signal must be skipped because its strategy may not
+// open positions now, counting and logging the skip
+func (t *TradingEngine) suppressedBySchedule(ctx context.Context, portfolio *Portfolio, signal *TradingSignal) bool {
+	if !opensPosition(signal.Action) {
+		return false
+	}
+
+	t.mu.RLock()
+	calendar := t.calendar
+	schedule := t.schedules[signal.StrategyName]
+	reason := t.openingsHalted
+	t.mu.RUnlock()
+
+	now := time.Now()
+	if reason == "" {
+		reason = calendar.Suppression(ctx, schedule, signal.TokenOut, now)
+	}
+	if reason == "" {
+		return false
+	}
+
+	t.mu.Lock()
+	metrics := t.strategyMetricsLocked(signal.StrategyName)
+	metrics.SuppressedBySchedule++
+	metrics.LastSuppressedAt = &now
+	metrics.LastSuppression = reason
+	t.mu.Unlock()
+
+	t.logger.Info(ctx, "Signal suppressed by trading schedule", map[string]interface{}{
+		"signal_id":    signal.ID.String(),
+		"strategy":     signal.StrategyName,
+		"portfolio_id": portfolio.ID.String(),
+		"action":       string(signal.Action),
+		"token":        signal.TokenOut,
+		"reason":       reason,
+		"outcome":      SuppressedBySchedule,
+	})
+	return true
+}
+
+func (t *TradingEngine) strategyMetricsLocked(name string) *StrategyMetrics {
+	metrics, ok := t.strategyMetrics[name]
+	if !ok {
+		metrics = &StrategyMetrics{}
+		t.strategyMetrics[name] = metrics
+	}
+	return metrics
+}
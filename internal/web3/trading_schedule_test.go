@@ -0,0 +1,207 @@
+package web3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryBlackoutStore struct {
+	blackouts map[uuid.UUID]Blackout
+}
+
+func (s *memoryBlackoutStore) SaveBlackout(ctx context.Context, blackout Blackout) error {
+	s.blackouts[blackout.ID] = blackout
+	return nil
+}
+
+func (s *memoryBlackoutStore) ListBlackouts(ctx context.Context) ([]Blackout, error) {
+	blackouts := make([]Blackout, 0, len(s.blackouts))
+	for _, blackout := range s.blackouts {
+		blackouts = append(blackouts, blackout)
+	}
+	return blackouts, nil
+}
+
+func (s *memoryBlackoutStore) DeleteBlackout(ctx context.Context, id uuid.UUID) error {
+	if _, ok := s.blackouts[id]; !ok {
+		return ErrBlackoutNotFound
+	}
+	delete(s.blackouts, id)
+	return nil
+}
+
+func TestTradingSchedule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	t.Run("WeeklyWindowsInTimezone", func(t *testing.T) {
+		schedule := &TradingSchedule{
+			Timezone: "America/New_York",
+			Windows:  []TradingWindow{{Days: []string{"mon", "Tuesday", "wed", "thu", "fri"}, Start: "09:30", End: "16:00"}},
+		}
+		require.NoError(t, schedule.Validate())
+
+		// Wednesday 2024-03-13
+		assert.True(t, schedule.Allows(time.Date(2024, 3, 13, 9, 30, 0, 0, newYork)))
+		assert.True(t, schedule.Allows(time.Date(2024, 3, 13, 19, 0, 0, 0, time.UTC)))
+		assert.False(t, schedule.Allows(time.Date(2024, 3, 13, 16, 0, 0, 0, newYork)))
+		assert.False(t, schedule.Allows(time.Date(2024, 3, 16, 12, 0, 0, 0, newYork)))
+	})
+
+	t.Run("OvernightWindowBelongsToStartDay", func(t *testing.T) {
+		schedule := &TradingSchedule{Windows: []TradingWindow{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}}}
+
+		assert.True(t, schedule.Allows(time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC)))
+		assert.True(t, schedule.Allows(time.Date(2024, 3, 16, 1, 59, 0, 0, time.UTC)))
+		assert.False(t, schedule.Allows(time.Date(2024, 3, 16, 23, 0, 0, 0, time.UTC)))
+		assert.False(t, schedule.Allows(time.Date(2024, 3, 15, 1, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, schedule := range []*TradingSchedule{
+			{Timezone: "Mars/Olympus", Windows: []TradingWindow{{Start: "00:00", End: "24:00"}}},
+			{Windows: []TradingWindow{{Days: []string{"someday"}, Start: "09:00", End: "17:00"}}},
+			{Windows: []TradingWindow{{Start: "9am", End: "17:00"}}},
+			{Windows: []TradingWindow{{Start: "10:00", End: "10:00"}}},
+		} {
+			assert.ErrorIs(t, schedule.Validate(), ErrInvalidTradingSchedule)
+			assert.False(t, schedule.Allows(time.Now()))
+		}
+		var unrestricted *TradingSchedule
+		assert.True(t, unrestricted.Allows(time.Now()))
+	})
+}
+
+func TestBlackoutCovers(t *testing.T) {
+	now := time.Now()
+	blackout := &Blackout{Start: now.Add(-time.Hour), End: now.Add(time.Hour), Symbols: []string{"BTC", "ETH/USDT"}}
+
+	assert.True(t, blackout.Covers("BTC/USDT", now))
+	assert.True(t, blackout.Covers("btc-usd", now))
+	assert.True(t, blackout.Covers("ETHUSDT", now))
+	assert.False(t, blackout.Covers("ETH/BTC", now))
+	assert.False(t, blackout.Covers("SOL/USDT", now))
+	assert.False(t, blackout.Covers("BTC/USDT", now.Add(time.Hour)))
+
+	blackout.Symbols = nil
+	assert.True(t, blackout.Covers("SOL/USDT", now))
+}
+
+func TestTradingEngineSchedule(t *testing.T) {
+	ctx := context.Background()
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	operator := uuid.New()
+
+	newEngine := func() (*TradingEngine, *TradingCalendar) {
+		engine := newProtectionTestEngine()
+		calendar := NewTradingCalendar(logger, &memoryBlackoutStore{blackouts: map[uuid.UUID]Blackout{}})
+		engine.SetTradingCalendar(calendar)
+		return engine, calendar
+	}
+	momentumSignal := func(action TradingAction) *TradingSignal {
+		signal := ethSignal()
+		signal.StrategyName = "momentum_strategy"
+		signal.Action = action
+		return signal
+	}
+	suppressed := func(engine *TradingEngine) int {
+		for _, status := range engine.ListStrategies() {
+			if status.Name == "momentum_strategy" {
+				return status.Metrics.SuppressedBySchedule
+			}
+		}
+		return -1
+	}
+
+	t.Run("BlackoutSuppressesOpeningOnly", func(t *testing.T) {
+		engine, calendar := newEngine()
+		portfolio, _ := openTestPosition(t, engine, ethSignal())
+
+		_, err := calendar.CreateBlackout(ctx, operator, CreateBlackoutRequest{
+			Start:   time.Now().Add(-time.Minute),
+			End:     time.Now().Add(time.Hour),
+			Symbols: []string{"ETH"},
+			Reason:  "FOMC",
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, engine.executeSignal(ctx, portfolio, momentumSignal(ActionBuy)))
+		assert.Len(t, portfolio.ActivePositions, 1)
+		assert.Equal(t, 1, suppressed(engine))
+
+		assert.False(t, engine.suppressedBySchedule(ctx, portfolio, momentumSignal(ActionSell)))
+		assert.Equal(t, 1, suppressed(engine))
+
+		statuses := engine.ListStrategies()
+		require.Len(t, statuses, 3)
+		assert.Equal(t, "arbitrage_strategy", statuses[0].Name)
+	})
+
+	t.Run("StrategyOutsideTradingHours", func(t *testing.T) {
+		engine, _ := newEngine()
+		portfolio, _ := openTestPosition(t, engine, ethSignal())
+
+		// A one minute window a few hours from now is closed now
+		opens := time.Now().UTC().Add(3 * time.Hour)
+		schedule := &TradingSchedule{Windows: []TradingWindow{{
+			Start: opens.Format("15:04"),
+			End:   opens.Add(time.Minute).Format("15:04"),
+		}}}
+		require.NoError(t, engine.SetStrategySchedule("momentum", schedule))
+
+		assert.True(t, engine.suppressedBySchedule(ctx, portfolio, momentumSignal(ActionBuy)))
+		assert.Equal(t, 1, suppressed(engine))
+
+		require.NoError(t, engine.SetStrategySchedule("momentum", nil))
+		assert.False(t, engine.suppressedBySchedule(ctx, portfolio, momentumSignal(ActionBuy)))
+
+		assert.ErrorIs(t, engine.SetStrategySchedule("unknown", schedule), ErrTradingStrategyNotFound)
+		assert.ErrorIs(t, engine.SetStrategySchedule("momentum", &TradingSchedule{Timezone: "Nowhere"}), ErrInvalidTradingSchedule)
+	})
+
+	t.Run("HaltedOpenings", func(t *testing.T) {
+		engine, _ := newEngine()
+		portfolio, _ := openTestPosition(t, engine, ethSignal())
+
+		engine.HaltOpenings("maintenance")
+		assert.True(t, engine.suppressedBySchedule(ctx, portfolio, momentumSignal(ActionBuy)))
+		assert.False(t, engine.suppressedBySchedule(ctx, portfolio, momentumSignal(ActionSell)))
+		assert.Equal(t, 1, suppressed(engine))
+
+		engine.ResumeOpenings()
+		assert.False(t, engine.suppressedBySchedule(ctx, portfolio, momentumSignal(ActionBuy)))
+	})
+
+	t.Run("BlackoutLifecycle", func(t *testing.T) {
+		_, calendar := newEngine()
+
+		_, err := calendar.CreateBlackout(ctx, operator, CreateBlackoutRequest{Start: time.Now(), End: time.Now().Add(-time.Hour)})
+		assert.ErrorIs(t, err, ErrInvalidBlackout)
+		_, err = calendar.CreateBlackout(ctx, operator, CreateBlackoutRequest{Start: time.Now(), End: time.Now().Add(60 * 24 * time.Hour)})
+		assert.ErrorIs(t, err, ErrInvalidBlackout)
+
+		blackout, err := calendar.CreateBlackout(ctx, operator, CreateBlackoutRequest{
+			Start:  time.Now().Add(time.Hour),
+			End:    time.Now().Add(2 * time.Hour),
+			Reason: "CPI release",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, calendar.Suppression(ctx, nil, "BTC/USDT", time.Now()))
+		assert.Equal(t, "blackout: CPI release", calendar.Suppression(ctx, nil, "BTC/USDT", time.Now().Add(90*time.Minute)))
+
+		blackouts, err := calendar.ListBlackouts(ctx)
+		require.NoError(t, err)
+		require.Len(t, blackouts, 1)
+
+		require.NoError(t, calendar.DeleteBlackout(ctx, blackout.ID))
+		assert.Empty(t, calendar.Suppression(ctx, nil, "BTC/USDT", time.Now().Add(90*time.Minute)))
+		assert.ErrorIs(t, calendar.DeleteBlackout(ctx, blackout.ID), ErrBlackoutNotFound)
+	})
+}
@@ -0,0 +1,754 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/security"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ErrCodeTransactionBlocked is the API error code of transactions to a denied address
+	ErrCodeTransactionBlocked = "TRANSACTION_BLOCKED"
+	// ErrCodeRiskNotAcknowledged is the API error code of risky transactions sent without
+	// "acknowledge_risk": true
+	ErrCodeRiskNotAcknowledged = "RISK_ACKNOWLEDGEMENT_REQUIRED"
+)
+
+var (
+	ErrTransactionBlocked     = errors.New("transaction blocked by security screening")
+	ErrRiskNotAcknowledged    = errors.New("transaction risk must be acknowledged")
+	ErrInvalidScreeningEntry  = errors.New("invalid screening list entry")
+	ErrScreeningEntryNotFound = errors.New("screening list entry not found")
+)
+
+// ScreeningVerdict is what screening decided about a transaction
+type ScreeningVerdict string
+
+const (
+	ScreeningAllow ScreeningVerdict = "allow"
+	ScreeningWarn  ScreeningVerdict = "warn"  // proceeds only when the risk is acknowledged
+	ScreeningBlock ScreeningVerdict = "block" // never proceeds
+)
+
+// ScreeningSeverity rates a screening finding. Critical findings block the transaction,
+// medium and high ones require the risk to be acknowledged and low ones are informational.
+type ScreeningSeverity string
+
+const (
+	ScreeningSeverityLow      ScreeningSeverity = "low"
+	ScreeningSeverityMedium   ScreeningSeverity = "medium"
+	ScreeningSeverityHigh     ScreeningSeverity = "high"
+	ScreeningSeverityCritical ScreeningSeverity = "critical"
+)
+
+// Screening finding categories besides those reported by screening providers
+const (
+	ScreeningCategoryDenylisted         = "denylisted"
+	ScreeningCategoryUnverifiedContract = "unverified_contract"
+	ScreeningCategoryNewContract        = "recently_deployed_contract"
+	ScreeningCategoryUnlimitedApproval  = "unlimited_approval"
+	ScreeningCategoryApprovalForAll     = "approval_for_all"
+	ScreeningCategoryUnavailable        = "screening_unavailable"
+)
+
+// Screening finding sources besides screening providers
+const (
+	ScreeningSourceDenylist  = "denylist"
+	ScreeningSourceContract  = "contract_heuristics"
+	ScreeningSourceCalldata  = "calldata"
+	screeningAuditAction     = "transaction_screening"
+	screeningListAuditAction = "screening_list_change"
+)
+
+// ScreeningFinding is a reason for concern about an address or a transaction
+type ScreeningFinding struct {
+	Address     string            `json:"address"`
+	Source      string            `json:"source"`
+	Category    string            `json:"category"`
+	Severity    ScreeningSeverity `json:"severity"`
+	Description string            `json:"description"`
+}
+
+// AddressScreening is the screening of one address on one chain
+type AddressScreening struct {
+	ChainID     int                `json:"chain_id"`
+	Address     string             `json:"address"`
+	Allowlisted bool               `json:"allowlisted,omitempty"`
+	Findings    []ScreeningFinding `json:"findings,omitempty"`
+	ScreenedAt  time.Time          `json:"screened_at"`
+	Cached      bool               `json:"cached,omitempty"`
+}
+
+// TransactionScreening is the screening of a transaction's recipient and, for token
+// approvals, of the approved spender
+type TransactionScreening struct {
+	ChainID      int                `json:"chain_id"`
+	ToAddress    string             `json:"to_address"`
+	Spender      string             `json:"spender,omitempty"`
+	Verdict      ScreeningVerdict   `json:"verdict"`
+	Findings     []ScreeningFinding `json:"findings,omitempty"`
+	Acknowledged bool               `json:"acknowledged,omitempty"`
+	ScreenedAt   time.Time          `json:"screened_at"`
+}
+
+// ScreeningError stops a transaction that screening blocked or that carries a risk the
+// user did not acknowledge
+type ScreeningError struct {
+	Screening *TransactionScreening
+}
+
+func (e *ScreeningError) Error() string {
+	for _, finding := range e.Screening.Findings {
+		if e.Screening.Verdict == ScreeningBlock && finding.Severity == ScreeningSeverityCritical {
+			return fmt.Sprintf("%s: %s", ErrTransactionBlocked, finding.Description)
+		}
+	}
+	if e.Screening.Verdict == ScreeningBlock {
+		return ErrTransactionBlocked.Error()
+	}
+	return fmt.Sprintf("%s: set acknowledge_risk to proceed", ErrRiskNotAcknowledged)
+}
+
+func (e *ScreeningError) Unwrap() error {
+	if e.Screening.Verdict == ScreeningBlock {
+		return ErrTransactionBlocked
+	}
+	return ErrRiskNotAcknowledged
+}
+
+// ScreeningListType is the operator list an address is on
+type ScreeningListType string
+
+const (
+	ScreeningDenylist  ScreeningListType = "deny"
+	ScreeningAllowlist ScreeningListType = "allow"
+)
+
+// ScreeningListEntry puts an address on the operator's deny or allow list. Chain ID 0 applies
+// the entry to every chain.
+type ScreeningListEntry struct {
+	ChainID   int               `json:"chain_id"`
+	Address   string            `json:"address"`
+	List      ScreeningListType `json:"list"`
+	Category  string            `json:"category,omitempty"`
+	Reason    string            `json:"reason,omitempty"`
+	AddedBy   string            `json:"added_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ScreeningStore keeps the deny and allow lists and cached address screenings, shared by
+// every web3 service replica
+type ScreeningStore interface {
+	// GetListEntry returns the entry for the address on exactly chainID, or nil
+	GetListEntry(ctx context.Context, chainID int, address string) (*ScreeningListEntry, error)
+	SaveListEntry(ctx context.Context, entry ScreeningListEntry) error
+	DeleteListEntry(ctx context.Context, chainID int, address string) error
+	ListEntries(ctx context.Context) ([]ScreeningListEntry, error)
+
+	// GetCachedScreening returns an unexpired screening, or nil
+	GetCachedScreening(ctx context.Context, chainID int, address string) (*AddressScreening, error)
+	CacheScreening(ctx context.Context, screening AddressScreening, ttl time.Duration) error
+	DeleteCachedScreenings(ctx context.Context, address string) error
+}
+
+// ScreeningProvider screens addresses against an external intelligence service, like the
+// Chainalysis sanctions API. Addresses without findings return none.
+type ScreeningProvider interface {
+	Name() string
+	ScreenAddress(ctx context.Context, chainID int, address string) ([]ScreeningFinding, error)
+}
+
+// ContractInfoSource describes the contract at an address, like a block explorer, returning
+// nil for addresses that are not contracts. The contract heuristics read whether its source
+// is verified and when it was created.
+type ContractInfoSource interface {
+	ContractInfo(ctx context.Context, chainID int, address string) (*ContractInfo, error)
+}
+
+// TransactionScreeningConfig configures transaction screening
+type TransactionScreeningConfig struct {
+	CacheTTL       time.Duration // how long address screenings are reused
+	NewContractAge time.Duration // contracts deployed more recently are flagged
+}
+
+// DefaultTransactionScreeningConfig returns the default screening configuration
+func DefaultTransactionScreeningConfig() TransactionScreeningConfig {
+	return TransactionScreeningConfig{
+		CacheTTL:       time.Hour,
+		NewContractAge: 7 * 24 * time.Hour,
+	}
+}
+
+// TransactionScreeningRequest describes a transaction about to be created
+type TransactionScreeningRequest struct {
+	ChainID   int
+	From      string
+	ToAddress string
+	Value     *big.Int
+	Data      string
+}
+
+// TransactionScreener screens transaction recipients against the operator's deny and allow
+// lists, external screening providers and contract heuristics, and token approvals for
+// draining patterns. Address screenings are cached and every decision is audit-logged.
+type TransactionScreener struct {
+	logger    *observability.Logger
+	config    TransactionScreeningConfig
+	store     ScreeningStore
+	providers []ScreeningProvider
+	contracts ContractInfoSource
+	auditor   *security.AuditManager
+	now       func() time.Time
+	mu        sync.RWMutex
+}
+
+// NewTransactionScreener creates a screener keeping its lists and cache in store
+func NewTransactionScreener(logger *observability.Logger, config TransactionScreeningConfig, store ScreeningStore) *TransactionScreener {
+	return &TransactionScreener{
+		logger: logger,
+		config: config,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// AddProvider screens addresses with an external provider too
+func (s *TransactionScreener) AddProvider(provider ScreeningProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providers = append(s.providers, provider)
+}
+
+// SetContractInfoSource enables the unverified and recently deployed contract heuristics
+func (s *TransactionScreener) SetContractInfoSource(source ContractInfoSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contracts = source
+}
+
+// SetAuditor records screening decisions and list changes in the audit log
+func (s *TransactionScreener) SetAuditor(auditor *security.AuditManager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auditor = auditor
+}
+
+// CheckTransaction screens a transaction and decides whether it may be created. Blocked
+// transactions, and risky ones without acknowledgeRisk, return a ScreeningError carrying the
+// screening. The decision is audit-logged either way.
+func (s *TransactionScreener) CheckTransaction(ctx context.Context, userID uuid.UUID, req TransactionScreeningRequest, acknowledgeRisk bool) (*TransactionScreening, error) {
+	screening, err := s.ScreenTransaction(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var decision error
+	switch screening.Verdict {
+	case ScreeningBlock:
+		decision = &ScreeningError{Screening: screening}
+	case ScreeningWarn:
+		if acknowledgeRisk {
+			screening.Acknowledged = true
+		} else {
+			decision = &ScreeningError{Screening: screening}
+		}
+	}
+	s.auditScreening(ctx, userID, screening, decision)
+
+	if decision != nil {
+		s.logger.Warn(ctx, "Transaction stopped by screening", map[string]interface{}{
+			"user_id":  userID.String(),
+			"chain_id": screening.ChainID,
+			"to":       screening.ToAddress,
+			"verdict":  string(screening.Verdict),
+			"findings": len(screening.Findings),
+		})
+		return screening, decision
+	}
+	return screening, nil
+}
+
+// ScreenTransaction screens a transaction's recipient and, for token approvals, the spender
+// without deciding on it
+func (s *TransactionScreener) ScreenTransaction(ctx context.Context, req TransactionScreeningRequest) (*TransactionScreening, error) {
+	if !common.IsHexAddress(req.ToAddress) {
+		return nil, fmt.Errorf("%w: %q is not an address", ErrUnresolvedRecipient, req.ToAddress)
+	}
+
+	screening := &TransactionScreening{
+		ChainID:    req.ChainID,
+		ToAddress:  common.HexToAddress(req.ToAddress).Hex(),
+		ScreenedAt: s.now(),
+	}
+
+	recipient, err := s.ScreenAddress(ctx, req.ChainID, req.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	screening.Findings = append(screening.Findings, recipient.Findings...)
+
+	// Approvals hand the spender control of the user's tokens, so the spender is screened too
+	approval := decodeApproval(req.Data)
+	if approval != nil {
+		screening.Spender = approval.spender.Hex()
+		screening.Findings = append(screening.Findings, approval.findings(screening.ToAddress)...)
+		if approval.spender != common.HexToAddress(req.ToAddress) {
+			spender, err := s.ScreenAddress(ctx, req.ChainID, screening.Spender)
+			if err != nil {
+				return nil, err
+			}
+			screening.Findings = append(screening.Findings, spender.Findings...)
+		}
+	}
+
+	screening.Verdict = screeningVerdict(screening.Findings)
+	return screening, nil
+}
+
+// ScreenAddress screens an address against the lists, providers and contract heuristics,
+// reusing a cached screening when there is one. Allowlisted addresses skip the providers
+// and heuristics; denylisted addresses are never cached, so removing them takes effect at once.
+func (s *TransactionScreener) ScreenAddress(ctx context.Context, chainID int, address string) (*AddressScreening, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: %q is not an address", ErrUnresolvedRecipient, address)
+	}
+	address = common.HexToAddress(address).Hex()
+
+	entry, err := s.listEntry(ctx, chainID, address)
+	if err != nil {
+		return nil, err
+	}
+	screening := &AddressScreening{ChainID: chainID, Address: address, ScreenedAt: s.now()}
+	if entry != nil {
+		if entry.List == ScreeningAllowlist {
+			screening.Allowlisted = true
+			return screening, nil
+		}
+		category := entry.Category
+		if category == "" {
+			category = ScreeningCategoryDenylisted
+		}
+		description := "Address is on the denylist"
+		if entry.Reason != "" {
+			description = fmt.Sprintf("Address is on the denylist: %s", entry.Reason)
+		}
+		screening.Findings = append(screening.Findings, ScreeningFinding{
+			Address:     address,
+			Source:      ScreeningSourceDenylist,
+			Category:    category,
+			Severity:    ScreeningSeverityCritical,
+			Description: description,
+		})
+		return screening, nil
+	}
+
+	cached, err := s.store.GetCachedScreening(ctx, chainID, address)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to read cached address screening", map[string]interface{}{
+			"address": address,
+			"error":   err.Error(),
+		})
+	} else if cached != nil {
+		cached.Cached = true
+		return cached, nil
+	}
+
+	s.mu.RLock()
+	providers, contracts := s.providers, s.contracts
+	s.mu.RUnlock()
+
+	// A provider that can't be reached leaves the user to decide, and the result isn't cached
+	complete := true
+	for _, provider := range providers {
+		findings, err := provider.ScreenAddress(ctx, chainID, address)
+		if err != nil {
+			complete = false
+			s.logger.Warn(ctx, "Screening provider failed", map[string]interface{}{
+				"provider": provider.Name(),
+				"address":  address,
+				"error":    err.Error(),
+			})
+			screening.Findings = append(screening.Findings, ScreeningFinding{
+				Address:     address,
+				Source:      provider.Name(),
+				Category:    ScreeningCategoryUnavailable,
+				Severity:    ScreeningSeverityMedium,
+				Description: fmt.Sprintf("Address could not be screened by %s", provider.Name()),
+			})
+			continue
+		}
+		screening.Findings = append(screening.Findings, findings...)
+	}
+
+	if contracts != nil {
+		info, err := contracts.ContractInfo(ctx, chainID, address)
+		if err != nil {
+			complete = false
+			s.logger.Warn(ctx, "Failed to get contract info for screening", map[string]interface{}{
+				"address": address,
+				"error":   err.Error(),
+			})
+		} else {
+			screening.Findings = append(screening.Findings, s.contractFindings(address, info)...)
+		}
+	}
+
+	if complete && s.config.CacheTTL > 0 {
+		if err := s.store.CacheScreening(ctx, *screening, s.config.CacheTTL); err != nil {
+			s.logger.Warn(ctx, "Failed to cache address screening", map[string]interface{}{
+				"address": address,
+				"error":   err.Error(),
+			})
+		}
+	}
+	return screening, nil
+}
+
+// AddListEntry puts an address on the deny or allow list on behalf of operator
+func (s *TransactionScreener) AddListEntry(ctx context.Context, entry ScreeningListEntry, operator string) (*ScreeningListEntry, error) {
+	if !common.IsHexAddress(entry.Address) {
+		return nil, fmt.Errorf("%w: %q is not an address", ErrInvalidScreeningEntry, entry.Address)
+	}
+	if entry.List != ScreeningDenylist && entry.List != ScreeningAllowlist {
+		return nil, fmt.Errorf("%w: list must be %q or %q", ErrInvalidScreeningEntry, ScreeningDenylist, ScreeningAllowlist)
+	}
+	if entry.ChainID < 0 {
+		return nil, fmt.Errorf("%w: invalid chain ID", ErrInvalidScreeningEntry)
+	}
+	entry.Address = common.HexToAddress(entry.Address).Hex()
+	entry.AddedBy = operator
+	entry.CreatedAt = s.now()
+
+	if err := s.store.SaveListEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to save screening list entry: %w", err)
+	}
+	s.invalidate(ctx, entry.Address)
+	s.auditListChange(ctx, "add", entry, operator)
+	return &entry, nil
+}
+
+// RemoveListEntry takes an address off the lists of a chain on behalf of operator
+func (s *TransactionScreener) RemoveListEntry(ctx context.Context, chainID int, address, operator string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%w: %q is not an address", ErrInvalidScreeningEntry, address)
+	}
+	address = common.HexToAddress(address).Hex()
+
+	entry, err := s.store.GetListEntry(ctx, chainID, address)
+	if err != nil {
+		return fmt.Errorf("failed to get screening list entry: %w", err)
+	}
+	if entry == nil {
+		return fmt.Errorf("%w: %s is not listed on chain %d", ErrScreeningEntryNotFound, address, chainID)
+	}
+	if err := s.store.DeleteListEntry(ctx, chainID, address); err != nil {
+		return fmt.Errorf("failed to delete screening list entry: %w", err)
+	}
+	s.invalidate(ctx, address)
+	s.auditListChange(ctx, "remove", *entry, operator)
+	return nil
+}
+
+// ListEntries returns the deny and allow list entries
+func (s *TransactionScreener) ListEntries(ctx context.Context) ([]ScreeningListEntry, error) {
+	entries, err := s.store.ListEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list screening entries: %w", err)
+	}
+	return entries, nil
+}
+
+// listEntry returns the chain's entry for the address, falling back to the all-chain entry
+func (s *TransactionScreener) listEntry(ctx context.Context, chainID int, address string) (*ScreeningListEntry, error) {
+	entry, err := s.store.GetListEntry(ctx, chainID, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screening lists: %w", err)
+	}
+	if entry == nil && chainID != 0 {
+		if entry, err = s.store.GetListEntry(ctx, 0, address); err != nil {
+			return nil, fmt.Errorf("failed to read screening lists: %w", err)
+		}
+	}
+	return entry, nil
+}
+
+// invalidate drops the cached screenings of an address whose listing changed
+func (s *TransactionScreener) invalidate(ctx context.Context, address string) {
+	if err := s.store.DeleteCachedScreenings(ctx, address); err != nil {
+		s.logger.Warn(ctx, "Failed to invalidate cached address screenings", map[string]interface{}{
+			"address": address,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// contractFindings applies the contract heuristics: unverified source and recent deployment
+func (s *TransactionScreener) contractFindings(address string, info *ContractInfo) []ScreeningFinding {
+	if info == nil {
+		return nil
+	}
+
+	var findings []ScreeningFinding
+	if !info.IsVerified {
+		findings = append(findings, ScreeningFinding{
+			Address:     address,
+			Source:      ScreeningSourceContract,
+			Category:    ScreeningCategoryUnverifiedContract,
+			Severity:    ScreeningSeverityMedium,
+			Description: "Contract source code is not verified",
+		})
+	}
+	if !info.CreatedAt.IsZero() && s.config.NewContractAge > 0 && s.now().Sub(info.CreatedAt) < s.config.NewContractAge {
+		findings = append(findings, ScreeningFinding{
+			Address:     address,
+			Source:      ScreeningSourceContract,
+			Category:    ScreeningCategoryNewContract,
+			Severity:    ScreeningSeverityHigh,
+			Description: fmt.Sprintf("Contract was deployed %s ago", s.now().Sub(info.CreatedAt).Round(time.Minute)),
+		})
+	}
+	return findings
+}
+
+func (s *TransactionScreener) auditScreening(ctx context.Context, userID uuid.UUID, screening *TransactionScreening, decision error) {
+	s.mu.RLock()
+	auditor := s.auditor
+	s.mu.RUnlock()
+	if auditor == nil {
+		return
+	}
+
+	result := security.AuditResultSuccess
+	if decision != nil {
+		result = security.AuditResultDenied
+	}
+	categories := make([]string, 0, len(screening.Findings))
+	for _, finding := range screening.Findings {
+		categories = append(categories, finding.Category)
+	}
+	details := map[string]interface{}{
+		"chain_id":     screening.ChainID,
+		"to_address":   screening.ToAddress,
+		"verdict":      string(screening.Verdict),
+		"categories":   categories,
+		"acknowledged": screening.Acknowledged,
+	}
+	if screening.Spender != "" {
+		details["spender"] = screening.Spender
+	}
+	if err := auditor.LogTradingEvent(ctx, &userID, screeningAuditAction, result, details); err != nil {
+		s.logger.Error(ctx, "Failed to audit transaction screening", err, details)
+	}
+}
+
+func (s *TransactionScreener) auditListChange(ctx context.Context, change string, entry ScreeningListEntry, operator string) {
+	s.mu.RLock()
+	auditor := s.auditor
+	s.mu.RUnlock()
+	if auditor == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"change":   change,
+		"list":     string(entry.List),
+		"chain_id": entry.ChainID,
+		"address":  entry.Address,
+		"category": entry.Category,
+		"reason":   entry.Reason,
+		"operator": operator,
+	}
+	if err := auditor.LogSecurityEvent(ctx, security.AuditEventTypeConfiguration, screeningListAuditAction, security.AuditSeverityMedium, details); err != nil {
+		s.logger.Error(ctx, "Failed to audit screening list change", err, details)
+	}
+}
+
+// screeningVerdict blocks on critical findings and warns on medium and high ones
+func screeningVerdict(findings []ScreeningFinding) ScreeningVerdict {
+	verdict := ScreeningAllow
+	for _, finding := range findings {
+		switch finding.Severity {
+		case ScreeningSeverityCritical:
+			return ScreeningBlock
+		case ScreeningSeverityHigh, ScreeningSeverityMedium:
+			verdict = ScreeningWarn
+		}
+	}
+	return verdict
+}
+
+// Token approval selectors
+var (
+	approveSelector           = []byte{0x09, 0x5e, 0xa7, 0xb3} // approve(address,uint256)
+	increaseAllowanceSelector = []byte{0x39, 0x50, 0x93, 0x51} // increaseAllowance(address,uint256)
+	setApprovalForAllSelector = []byte{0xa2, 0x2c, 0xb4, 0x65} // setApprovalForAll(address,bool)
+)
+
+// unlimitedAllowance is the allowance from which an approval is treated as unlimited; wallets
+// approve 2^256-1, but anything this large is never meant to be spent down
+var unlimitedAllowance = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// tokenApproval is a decoded ERC-20 or NFT approval
+type tokenApproval struct {
+	spender   common.Address
+	amount    *big.Int // nil for setApprovalForAll
+	allTokens bool
+}
+
+// decodeApproval decodes approve, increaseAllowance and setApprovalForAll calldata, returning
+// nil for anything else, including revoking approvals for all
+func decodeApproval(data string) *tokenApproval {
+	calldata := common.FromHex(data)
+	if len(calldata) != 4+64 {
+		return nil
+	}
+	selector, spender, arg := calldata[:4], common.BytesToAddress(calldata[4:36]), new(big.Int).SetBytes(calldata[36:68])
+
+	switch {
+	case string(selector) == string(approveSelector), string(selector) == string(increaseAllowanceSelector):
+		return &tokenApproval{spender: spender, amount: arg}
+	case string(selector) == string(setApprovalForAllSelector):
+		if arg.Sign() == 0 {
+			return nil
+		}
+		return &tokenApproval{spender: spender, allTokens: true}
+	}
+	return nil
+}
+
+// findings flags approvals that let the spender drain the token at will
+func (a *tokenApproval) findings(token string) []ScreeningFinding {
+	switch {
+	case a.allTokens:
+		return []ScreeningFinding{{
+			Address:     a.spender.Hex(),
+			Source:      ScreeningSourceCalldata,
+			Category:    ScreeningCategoryApprovalForAll,
+			Severity:    ScreeningSeverityMedium,
+			Description: fmt.Sprintf("Transaction lets %s transfer every token of collection %s", a.spender.Hex(), token),
+		}}
+	case a.amount.Cmp(unlimitedAllowance) >= 0:
+		return []ScreeningFinding{{
+			Address:     a.spender.Hex(),
+			Source:      ScreeningSourceCalldata,
+			Category:    ScreeningCategoryUnlimitedApproval,
+			Severity:    ScreeningSeverityMedium,
+			Description: fmt.Sprintf("Transaction grants %s an unlimited allowance of token %s", a.spender.Hex(), token),
+		}}
+	}
+	return nil
+}
+
+const (
+	screeningListKey        = "web3:screening:lists"
+	screeningCacheKeyPrefix = "web3:screening:cache:"
+)
+
+// redisScreeningStore keeps list entries in a hash keyed by chain and address, and cached
+// screenings in expiring keys
+type redisScreeningStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisScreeningStore creates a screening store shared through Redis
+func NewRedisScreeningStore(redis *database.RedisClient) ScreeningStore {
+	return &redisScreeningStore{redis: redis}
+}
+
+func screeningField(chainID int, address string) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(address))
+}
+
+func (s *redisScreeningStore) GetListEntry(ctx context.Context, chainID int, address string) (*ScreeningListEntry, error) {
+	data, err := s.redis.Client.HGet(ctx, screeningListKey, screeningField(chainID, address)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry ScreeningListEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal screening list entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func (s *redisScreeningStore) SaveListEntry(ctx context.Context, entry ScreeningListEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal screening list entry: %w", err)
+	}
+	return s.redis.Client.HSet(ctx, screeningListKey, screeningField(entry.ChainID, entry.Address), data).Err()
+}
+
+func (s *redisScreeningStore) DeleteListEntry(ctx context.Context, chainID int, address string) error {
+	return s.redis.Client.HDel(ctx, screeningListKey, screeningField(chainID, address)).Err()
+}
+
+func (s *redisScreeningStore) ListEntries(ctx context.Context) ([]ScreeningListEntry, error) {
+	values, err := s.redis.Client.HVals(ctx, screeningListKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ScreeningListEntry, 0, len(values))
+	for _, value := range values {
+		var entry ScreeningListEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal screening list entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *redisScreeningStore) GetCachedScreening(ctx context.Context, chainID int, address string) (*AddressScreening, error) {
+	data, err := s.redis.Client.Get(ctx, screeningCacheKeyPrefix+screeningField(chainID, address)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var screening AddressScreening
+	if err := json.Unmarshal(data, &screening); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal address screening: %w", err)
+	}
+	return &screening, nil
+}
+
+func (s *redisScreeningStore) CacheScreening(ctx context.Context, screening AddressScreening, ttl time.Duration) error {
+	data, err := json.Marshal(screening)
+	if err != nil {
+		return fmt.Errorf("failed to marshal address screening: %w", err)
+	}
+	return s.redis.Client.Set(ctx, screeningCacheKeyPrefix+screeningField(screening.ChainID, screening.Address), data, ttl).Err()
+}
+
+func (s *redisScreeningStore) DeleteCachedScreenings(ctx context.Context, address string) error {
+	// The address may be cached on any chain
+	iter := s.redis.Client.Scan(ctx, 0, screeningCacheKeyPrefix+"*:"+strings.ToLower(address), 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.redis.Client.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,190 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChainalysisScreeningProvider screens addresses with the Chainalysis sanctions screening
+// API. Sanctioned addresses are critical findings that block transactions.
+type ChainalysisScreeningProvider struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURL    string // replaced in tests
+}
+
+// NewChainalysisScreeningProvider creates a provider authenticated with a Chainalysis API key
+func NewChainalysisScreeningProvider(apiKey string) *ChainalysisScreeningProvider {
+	return &ChainalysisScreeningProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		apiKey:     apiKey,
+		baseURL:    "https://public.chainalysis.com",
+	}
+}
+
+func (c *ChainalysisScreeningProvider) Name() string {
+	return "chainalysis"
+}
+
+// ScreenAddress returns a finding for each identification of the address. Identifications
+// are not chain specific.
+func (c *ChainalysisScreeningProvider) ScreenAddress(ctx context.Context, chainID int, address string) ([]ScreeningFinding, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/address/"+url.PathEscape(address), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("chainalysis error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var body struct {
+		Identifications []struct {
+			Category    string `json:"category"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"identifications"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid chainalysis response: %w", err)
+	}
+
+	findings := make([]ScreeningFinding, 0, len(body.Identifications))
+	for _, identification := range body.Identifications {
+		category := strings.ToLower(identification.Category)
+		if category == "" {
+			category = "sanctions"
+		}
+		description := identification.Name
+		if description == "" {
+			description = identification.Description
+		}
+		findings = append(findings, ScreeningFinding{
+			Address:     address,
+			Source:      c.Name(),
+			Category:    category,
+			Severity:    ScreeningSeverityCritical,
+			Description: description,
+		})
+	}
+	return findings, nil
+}
+
+// EtherscanContractSource reads contract creation and source verification from the Etherscan
+// V2 API, which covers every chain Etherscan indexes with one key
+type EtherscanContractSource struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURL    string // replaced in tests
+}
+
+// NewEtherscanContractSource creates a contract info source authenticated with an Etherscan
+// API key
+func NewEtherscanContractSource(apiKey string) *EtherscanContractSource {
+	return &EtherscanContractSource{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		apiKey:     apiKey,
+		baseURL:    "https://api.etherscan.io/v2/api",
+	}
+}
+
+// etherscanResponse is the envelope of Etherscan API responses; result is an error message
+// when status is "0" with data
+type etherscanResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// call calls an Etherscan contract module action, returning nil when it found no data
+func (e *EtherscanContractSource) call(ctx context.Context, chainID int, action string, params url.Values) (json.RawMessage, error) {
+	params.Set("chainid", strconv.Itoa(chainID))
+	params.Set("module", "contract")
+	params.Set("action", action)
+	params.Set("apikey", e.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("etherscan %s error: status %d", action, resp.StatusCode)
+	}
+
+	var body etherscanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid etherscan %s response: %w", action, err)
+	}
+	if body.Status != "1" {
+		if strings.HasPrefix(strings.ToLower(body.Message), "no data found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("etherscan %s error: %s: %s", action, body.Message, strings.Trim(string(body.Result), `"`))
+	}
+	return body.Result, nil
+}
+
+// ContractInfo reads who created the contract at the address and when, and whether its
+// source is verified. Addresses without a creation transaction are not contracts.
+func (e *EtherscanContractSource) ContractInfo(ctx context.Context, chainID int, address string) (*ContractInfo, error) {
+	result, err := e.call(ctx, chainID, "getcontractcreation", url.Values{"contractaddresses": {address}})
+	if err != nil {
+		return nil, err
+	}
+	var creations []struct {
+		ContractCreator string `json:"contractCreator"`
+		Timestamp       string `json:"timestamp"`
+	}
+	if result != nil {
+		if err := json.Unmarshal(result, &creations); err != nil {
+			return nil, fmt.Errorf("invalid etherscan contract creation: %w", err)
+		}
+	}
+	if len(creations) == 0 {
+		return nil, nil
+	}
+
+	info := &ContractInfo{Address: address, ChainID: chainID, Creator: creations[0].ContractCreator}
+	if seconds, err := strconv.ParseInt(creations[0].Timestamp, 10, 64); err == nil {
+		info.CreatedAt = time.Unix(seconds, 0)
+	}
+
+	result, err = e.call(ctx, chainID, "getsourcecode", url.Values{"address": {address}})
+	if err != nil {
+		return nil, err
+	}
+	var sources []struct {
+		SourceCode   string `json:"SourceCode"`
+		ContractName string `json:"ContractName"`
+	}
+	if result != nil {
+		if err := json.Unmarshal(result, &sources); err != nil {
+			return nil, fmt.Errorf("invalid etherscan source code: %w", err)
+		}
+	}
+	if len(sources) > 0 {
+		info.IsVerified = sources[0].SourceCode != ""
+		info.Name = sources[0].ContractName
+	}
+	return info, nil
+}
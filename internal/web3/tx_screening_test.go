@@ -0,0 +1,339 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryScreeningStore is a ScreeningStore in memory; cached screenings never expire
+type memoryScreeningStore struct {
+	entries map[string]ScreeningListEntry
+	cache   map[string]AddressScreening
+}
+
+func newMemoryScreeningStore() *memoryScreeningStore {
+	return &memoryScreeningStore{entries: map[string]ScreeningListEntry{}, cache: map[string]AddressScreening{}}
+}
+
+func (m *memoryScreeningStore) GetListEntry(ctx context.Context, chainID int, address string) (*ScreeningListEntry, error) {
+	entry, ok := m.entries[screeningField(chainID, address)]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (m *memoryScreeningStore) SaveListEntry(ctx context.Context, entry ScreeningListEntry) error {
+	m.entries[screeningField(entry.ChainID, entry.Address)] = entry
+	return nil
+}
+
+func (m *memoryScreeningStore) DeleteListEntry(ctx context.Context, chainID int, address string) error {
+	delete(m.entries, screeningField(chainID, address))
+	return nil
+}
+
+func (m *memoryScreeningStore) ListEntries(ctx context.Context) ([]ScreeningListEntry, error) {
+	entries := make([]ScreeningListEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (m *memoryScreeningStore) GetCachedScreening(ctx context.Context, chainID int, address string) (*AddressScreening, error) {
+	screening, ok := m.cache[screeningField(chainID, address)]
+	if !ok {
+		return nil, nil
+	}
+	return &screening, nil
+}
+
+func (m *memoryScreeningStore) CacheScreening(ctx context.Context, screening AddressScreening, ttl time.Duration) error {
+	m.cache[screeningField(screening.ChainID, screening.Address)] = screening
+	return nil
+}
+
+func (m *memoryScreeningStore) DeleteCachedScreenings(ctx context.Context, address string) error {
+	for key, screening := range m.cache {
+		if screening.Address == address {
+			delete(m.cache, key)
+		}
+	}
+	return nil
+}
+
+// fakeScreeningProvider reports fixed findings per address, or fails
+type fakeScreeningProvider struct {
+	findings map[common.Address][]ScreeningFinding
+	err      error
+	calls    int
+}
+
+func (f *fakeScreeningProvider) Name() string { return "fake" }
+
+func (f *fakeScreeningProvider) ScreenAddress(ctx context.Context, chainID int, address string) ([]ScreeningFinding, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.findings[common.HexToAddress(address)], nil
+}
+
+// staticContractSource describes contracts from a fixed table
+type staticContractSource map[common.Address]*ContractInfo
+
+func (s staticContractSource) ContractInfo(ctx context.Context, chainID int, address string) (*ContractInfo, error) {
+	return s[common.HexToAddress(address)], nil
+}
+
+var (
+	screeningUser     = uuid.New()
+	sanctionedAddress = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	drainerAddress    = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	newContract       = common.HexToAddress("0x3333333333333333333333333333333333333333")
+	tokenAddress      = common.HexToAddress("0x4444444444444444444444444444444444444444")
+	walletAddress     = common.HexToAddress("0x5555555555555555555555555555555555555555")
+)
+
+func newTestTransactionScreener() (*TransactionScreener, *memoryScreeningStore, *fakeScreeningProvider) {
+	logger := observability.NewLogger(config.ObservabilityConfig{LogLevel: "error"})
+	store := newMemoryScreeningStore()
+	screener := NewTransactionScreener(logger, DefaultTransactionScreeningConfig(), store)
+	screener.now = func() time.Time { return time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC) }
+
+	provider := &fakeScreeningProvider{findings: map[common.Address][]ScreeningFinding{
+		sanctionedAddress: {{Address: sanctionedAddress.Hex(), Source: "fake", Category: "sanctions", Severity: ScreeningSeverityCritical, Description: "SANCTIONS: OFAC SDN"}},
+	}}
+	screener.AddProvider(provider)
+	screener.SetContractInfoSource(staticContractSource{
+		newContract:  {Address: newContract.Hex(), CreatedAt: time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)},
+		tokenAddress: {Address: tokenAddress.Hex(), IsVerified: true, CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	return screener, store, provider
+}
+
+// approvalCalldata encodes a call of selector with the spender and amount arguments
+func approvalCalldata(selector []byte, spender common.Address, amount *big.Int) string {
+	data := append([]byte{}, selector...)
+	data = append(data, common.LeftPadBytes(spender.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return hexutil.Encode(data)
+}
+
+func TestTransactionScreener_AllowsCleanTransfers(t *testing.T) {
+	screener, _, _ := newTestTransactionScreener()
+
+	screening, err := screener.CheckTransaction(context.Background(), screeningUser, TransactionScreeningRequest{ChainID: 1, ToAddress: walletAddress.Hex(), Value: big.NewInt(1)}, false)
+	require.NoError(t, err)
+	assert.Equal(t, ScreeningAllow, screening.Verdict)
+	assert.Empty(t, screening.Findings)
+}
+
+func TestTransactionScreener_BlocksSanctionedAddresses(t *testing.T) {
+	ctx := context.Background()
+	screener, _, provider := newTestTransactionScreener()
+	req := TransactionScreeningRequest{ChainID: 1, ToAddress: sanctionedAddress.Hex()}
+
+	// Acknowledging the risk never unblocks a transaction
+	screening, err := screener.CheckTransaction(ctx, screeningUser, req, true)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTransactionBlocked))
+	assert.Contains(t, err.Error(), "OFAC SDN")
+	assert.Equal(t, ScreeningBlock, screening.Verdict)
+	assert.False(t, screening.Acknowledged)
+
+	var screeningErr *ScreeningError
+	require.True(t, errors.As(err, &screeningErr))
+	assert.Equal(t, screening, screeningErr.Screening)
+
+	// The address screening is cached
+	_, err = screener.CheckTransaction(ctx, screeningUser, req, false)
+	assert.True(t, errors.Is(err, ErrTransactionBlocked))
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestTransactionScreener_RiskyContractsNeedAcknowledgement(t *testing.T) {
+	ctx := context.Background()
+	screener, _, _ := newTestTransactionScreener()
+	req := TransactionScreeningRequest{ChainID: 1, ToAddress: newContract.Hex(), Data: "0xd0e30db0"}
+
+	screening, err := screener.CheckTransaction(ctx, screeningUser, req, false)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRiskNotAcknowledged))
+	assert.Equal(t, ScreeningWarn, screening.Verdict)
+	categories := make([]string, 0, len(screening.Findings))
+	for _, finding := range screening.Findings {
+		categories = append(categories, finding.Category)
+	}
+	assert.ElementsMatch(t, []string{ScreeningCategoryUnverifiedContract, ScreeningCategoryNewContract}, categories)
+
+	screening, err = screener.CheckTransaction(ctx, screeningUser, req, true)
+	require.NoError(t, err)
+	assert.True(t, screening.Acknowledged)
+}
+
+func TestTransactionScreener_Approvals(t *testing.T) {
+	ctx := context.Background()
+	screener, _, _ := newTestTransactionScreener()
+	unlimited := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	// A bounded allowance for a clean spender is fine
+	screening, err := screener.CheckTransaction(ctx, screeningUser, TransactionScreeningRequest{
+		ChainID: 1, ToAddress: tokenAddress.Hex(), Data: approvalCalldata(approveSelector, walletAddress, big.NewInt(1000)),
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, walletAddress.Hex(), screening.Spender)
+	assert.Equal(t, ScreeningAllow, screening.Verdict)
+
+	// An unlimited allowance needs acknowledgement
+	screening, err = screener.CheckTransaction(ctx, screeningUser, TransactionScreeningRequest{
+		ChainID: 1, ToAddress: tokenAddress.Hex(), Data: approvalCalldata(approveSelector, walletAddress, unlimited),
+	}, false)
+	assert.True(t, errors.Is(err, ErrRiskNotAcknowledged))
+	require.Len(t, screening.Findings, 1)
+	assert.Equal(t, ScreeningCategoryUnlimitedApproval, screening.Findings[0].Category)
+
+	screening, err = screener.CheckTransaction(ctx, screeningUser, TransactionScreeningRequest{
+		ChainID: 1, ToAddress: tokenAddress.Hex(), Data: approvalCalldata(setApprovalForAllSelector, walletAddress, big.NewInt(1)),
+	}, false)
+	assert.True(t, errors.Is(err, ErrRiskNotAcknowledged))
+	assert.Equal(t, ScreeningCategoryApprovalForAll, screening.Findings[0].Category)
+
+	// Approving a denylisted spender is blocked even though the token is clean
+	_, err = screener.AddListEntry(ctx, ScreeningListEntry{Address: drainerAddress.Hex(), List: ScreeningDenylist, Category: "drainer"}, "admin")
+	require.NoError(t, err)
+	screening, err = screener.CheckTransaction(ctx, screeningUser, TransactionScreeningRequest{
+		ChainID: 1, ToAddress: tokenAddress.Hex(), Data: approvalCalldata(approveSelector, drainerAddress, big.NewInt(1)),
+	}, true)
+	assert.True(t, errors.Is(err, ErrTransactionBlocked))
+	assert.Equal(t, "drainer", screening.Findings[0].Category)
+}
+
+func TestTransactionScreener_Lists(t *testing.T) {
+	ctx := context.Background()
+	screener, store, provider := newTestTransactionScreener()
+
+	_, err := screener.AddListEntry(ctx, ScreeningListEntry{Address: "not an address", List: ScreeningDenylist}, "admin")
+	assert.True(t, errors.Is(err, ErrInvalidScreeningEntry))
+	_, err = screener.AddListEntry(ctx, ScreeningListEntry{Address: walletAddress.Hex(), List: "maybe"}, "admin")
+	assert.True(t, errors.Is(err, ErrInvalidScreeningEntry))
+
+	// Screening the wallet caches it; denying it on every chain invalidates the cache
+	_, err = screener.ScreenAddress(ctx, 137, walletAddress.Hex())
+	require.NoError(t, err)
+	require.Len(t, store.cache, 1)
+
+	entry, err := screener.AddListEntry(ctx, ScreeningListEntry{Address: walletAddress.Hex(), List: ScreeningDenylist, Reason: "phishing"}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", entry.AddedBy)
+	assert.Empty(t, store.cache)
+
+	screening, err := screener.ScreenAddress(ctx, 137, walletAddress.Hex())
+	require.NoError(t, err)
+	require.Len(t, screening.Findings, 1)
+	assert.Equal(t, "Address is on the denylist: phishing", screening.Findings[0].Description)
+
+	// A chain's allow entry overrides the all-chain deny entry and skips the providers
+	_, err = screener.AddListEntry(ctx, ScreeningListEntry{ChainID: 137, Address: walletAddress.Hex(), List: ScreeningAllowlist}, "admin")
+	require.NoError(t, err)
+	screening, err = screener.ScreenAddress(ctx, 137, walletAddress.Hex())
+	require.NoError(t, err)
+	assert.True(t, screening.Allowlisted)
+	assert.Empty(t, screening.Findings)
+
+	calls := provider.calls
+	_, err = screener.AddListEntry(ctx, ScreeningListEntry{Address: sanctionedAddress.Hex(), List: ScreeningAllowlist}, "admin")
+	require.NoError(t, err)
+	_, err = screener.CheckTransaction(ctx, screeningUser, TransactionScreeningRequest{ChainID: 1, ToAddress: sanctionedAddress.Hex()}, false)
+	require.NoError(t, err)
+	assert.Equal(t, calls, provider.calls)
+
+	require.NoError(t, screener.RemoveListEntry(ctx, 0, walletAddress.Hex(), "admin"))
+	err = screener.RemoveListEntry(ctx, 0, walletAddress.Hex(), "admin")
+	assert.True(t, errors.Is(err, ErrScreeningEntryNotFound))
+
+	entries, err := screener.ListEntries(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestTransactionScreener_ProviderFailureWarns(t *testing.T) {
+	ctx := context.Background()
+	screener, store, provider := newTestTransactionScreener()
+	provider.err = errors.New("connection refused")
+
+	screening, err := screener.CheckTransaction(ctx, screeningUser, TransactionScreeningRequest{ChainID: 1, ToAddress: walletAddress.Hex()}, false)
+	assert.True(t, errors.Is(err, ErrRiskNotAcknowledged))
+	require.Len(t, screening.Findings, 1)
+	assert.Equal(t, ScreeningCategoryUnavailable, screening.Findings[0].Category)
+	assert.Empty(t, store.cache, "incomplete screenings are not cached")
+}
+
+func TestChainalysisScreeningProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("X-API-Key"))
+		if r.URL.Path == "/api/v1/address/"+sanctionedAddress.Hex() {
+			w.Write([]byte(`{"identifications": [{"category": "sanctions", "name": "SANCTIONS: OFAC SDN Test"}]}`))
+			return
+		}
+		w.Write([]byte(`{"identifications": []}`))
+	}))
+	defer server.Close()
+
+	provider := NewChainalysisScreeningProvider("key")
+	provider.baseURL = server.URL
+
+	findings, err := provider.ScreenAddress(context.Background(), 1, sanctionedAddress.Hex())
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, ScreeningSeverityCritical, findings[0].Severity)
+	assert.Equal(t, "SANCTIONS: OFAC SDN Test", findings[0].Description)
+
+	findings, err = provider.ScreenAddress(context.Background(), 1, walletAddress.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestEtherscanContractSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "8453", query.Get("chainid"))
+		switch {
+		case query.Get("action") == "getcontractcreation" && query.Get("contractaddresses") == newContract.Hex():
+			w.Write([]byte(`{"status": "1", "message": "OK", "result": [{"contractCreator": "0xabc", "timestamp": "1777723200"}]}`))
+		case query.Get("action") == "getcontractcreation":
+			w.Write([]byte(`{"status": "0", "message": "No data found", "result": []}`))
+		case query.Get("action") == "getsourcecode":
+			w.Write([]byte(`{"status": "1", "message": "OK", "result": [{"SourceCode": "", "ContractName": ""}]}`))
+		}
+	}))
+	defer server.Close()
+
+	source := NewEtherscanContractSource("key")
+	source.baseURL = server.URL
+
+	info, err := source.ContractInfo(context.Background(), 8453, newContract.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.False(t, info.IsVerified)
+	assert.Equal(t, "0xabc", info.Creator)
+	assert.Equal(t, time.Unix(1777723200, 0), info.CreatedAt)
+
+	info, err = source.ContractInfo(context.Background(), 8453, walletAddress.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, info)
+}
@@ -0,0 +1,367 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+// Simulation errors
+var (
+	ErrSimulationFailed  = errors.New("transaction simulation failed")
+	ErrInvalidSimulation = errors.New("invalid simulation request")
+)
+
+// SimulationError carries the failed simulation of a transaction that was not submitted
+type SimulationError struct {
+	Simulation *TransactionSimulation
+}
+
+func (e *SimulationError) Error() string {
+	if e.Simulation.RevertReason != "" {
+		return fmt.Sprintf("%s: %s", ErrSimulationFailed, e.Simulation.RevertReason)
+	}
+	return fmt.Sprintf("%s: %s", ErrSimulationFailed, e.Simulation.Revert)
+}
+
+func (e *SimulationError) Unwrap() error {
+	return ErrSimulationFailed
+}
+
+// SimulationRequest describes a transaction to simulate. The sender and chain come from the
+// wallet when WalletID is set, otherwise from From and ChainID.
+type SimulationRequest struct {
+	WalletID  uuid.UUID `json:"wallet_id,omitempty"`
+	ChainID   int       `json:"chain_id,omitempty"`
+	From      string    `json:"from,omitempty"`
+	ToAddress string    `json:"to_address"`
+	Value     *big.Int  `json:"value,omitempty"`
+	Data      string    `json:"data,omitempty"`
+}
+
+// BalanceChange is the change of the sender's native or token balance caused by a transaction.
+// Token is empty for the chain's native currency.
+type BalanceChange struct {
+	Token  string   `json:"token,omitempty"`
+	Amount *big.Int `json:"amount"`
+}
+
+// SimulationBackend is the part of a chain client used to simulate transactions.
+// *ethclient.Client implements it.
+type SimulationBackend interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// CallTracer runs raw RPC calls such as debug_traceCall. *rpc.Client implements it.
+type CallTracer interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// defaultSimulationGasPrice is used when the chain does not suggest a gas price
+var defaultSimulationGasPrice = big.NewInt(20000000000) // 20 gwei
+
+var (
+	erc20TransferSelector     = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	erc20TransferFromSelector = crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+	erc20TransferTopic        = common.BytesToHash(crypto.Keccak256([]byte("Transfer(address,address,uint256)")))
+)
+
+// knownCustomErrors names widely used custom errors by selector
+var knownCustomErrors = func() map[string]string {
+	signatures := []string{
+		"ERC20InsufficientBalance(address,uint256,uint256)",
+		"ERC20InsufficientAllowance(address,uint256,uint256)",
+		"ERC20InvalidReceiver(address)",
+		"ERC20InvalidSender(address)",
+		"SafeERC20FailedOperation(address)",
+		"OwnableUnauthorizedAccount(address)",
+		"EnforcedPause()",
+		"ReentrancyGuardReentrantCall()",
+	}
+	errs := make(map[string]string, len(signatures))
+	for _, signature := range signatures {
+		errs[string(crypto.Keccak256([]byte(signature))[:4])] = signature
+	}
+	return errs
+}()
+
+// SimulateTransaction simulates a transaction against the latest block of its chain without
+// submitting it, reporting whether it reverts and why, the gas it needs and the sender's
+// balance changes where they can be determined
+func (s *EnhancedService) SimulateTransaction(ctx context.Context, userID uuid.UUID, req SimulationRequest) (*TransactionSimulation, error) {
+	chainID, from := req.ChainID, req.From
+	if req.WalletID != uuid.Nil {
+		wallet, err := s.userWallet(ctx, userID, req.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		chainID, from = wallet.ChainID, wallet.Address
+	}
+	if !common.IsHexAddress(from) || !common.IsHexAddress(req.ToAddress) {
+		return nil, fmt.Errorf("%w: from and to_address must be hex addresses", ErrInvalidSimulation)
+	}
+
+	client, exists := s.clients[chainID]
+	if !exists {
+		return nil, fmt.Errorf("no client configured for chain ID: %d", chainID)
+	}
+
+	to := common.HexToAddress(req.ToAddress)
+	callMsg := ethereum.CallMsg{
+		From:  common.HexToAddress(from),
+		To:    &to,
+		Value: req.Value,
+		Data:  common.FromHex(req.Data),
+	}
+	return SimulateCall(ctx, client, client.Client(), callMsg)
+}
+
+// SimulateCall executes a call with eth_call and estimates its gas. When a tracer is given,
+// debug_traceCall supplies nested calls and token transfers; nodes without the debug namespace
+// fall back to decoding ERC-20 transfers from the calldata.
+func SimulateCall(ctx context.Context, backend SimulationBackend, tracer CallTracer, msg ethereum.CallMsg) (*TransactionSimulation, error) {
+	simulation := &TransactionSimulation{Success: true}
+
+	var trace *callFrame
+	if tracer != nil {
+		trace = traceCall(ctx, tracer, msg)
+	}
+
+	result, err := backend.CallContract(ctx, msg, nil)
+	if err == nil {
+		simulation.GasUsed, err = backend.EstimateGas(ctx, msg)
+	}
+	if err != nil {
+		simulation.Success = false
+		simulation.Revert = err.Error()
+		simulation.RevertData, simulation.RevertReason = decodeRevert(err, trace)
+		if trace != nil {
+			simulation.GasUsed = uint64(trace.GasUsed)
+		}
+	} else {
+		simulation.StateChanges = map[string]interface{}{"result": hexutil.Encode(result)}
+	}
+
+	gasPrice, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		gasPrice = defaultSimulationGasPrice
+	}
+	simulation.GasPrice = gasPrice
+	simulation.EstimatedCost = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(simulation.GasUsed))
+
+	if simulation.Success {
+		if trace != nil {
+			simulation.Traces = trace.summaries(nil, 0)
+			simulation.BalanceChanges = trace.balanceChanges(msg.From)
+		} else {
+			simulation.BalanceChanges = calldataBalanceChanges(msg)
+		}
+	}
+
+	return simulation, nil
+}
+
+// callFrame is a frame of the callTracer output of debug_traceCall
+type callFrame struct {
+	Type         string         `json:"type"`
+	From         common.Address `json:"from"`
+	To           common.Address `json:"to"`
+	Value        *hexutil.Big   `json:"value"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output"`
+	Error        string         `json:"error"`
+	RevertReason string         `json:"revertReason"`
+	Calls        []callFrame    `json:"calls"`
+	Logs         []struct {
+		Address common.Address `json:"address"`
+		Topics  []common.Hash  `json:"topics"`
+		Data    hexutil.Bytes  `json:"data"`
+	} `json:"logs"`
+}
+
+// traceCall runs debug_traceCall with the call tracer, returning nil when the node does not
+// support it
+func traceCall(ctx context.Context, tracer CallTracer, msg ethereum.CallMsg) *callFrame {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+
+	var frame callFrame
+	config := map[string]interface{}{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]interface{}{"withLog": true},
+	}
+	if err := tracer.CallContext(ctx, &frame, "debug_traceCall", arg, "latest", config); err != nil {
+		return nil
+	}
+	return &frame
+}
+
+// summaries describes the frame and its nested calls, one line per call
+func (f *callFrame) summaries(lines []string, depth int) []string {
+	line := fmt.Sprintf("%s%s %s -> %s", strings.Repeat("  ", depth), f.Type, f.From.Hex(), f.To.Hex())
+	if f.Error != "" {
+		line += " (" + f.Error + ")"
+	}
+	lines = append(lines, line)
+	for i := range f.Calls {
+		lines = f.Calls[i].summaries(lines, depth+1)
+	}
+	return lines
+}
+
+// balanceChanges sums the native value and ERC-20 transfers into and out of an account across
+// all frames that did not revert
+func (f *callFrame) balanceChanges(account common.Address) []BalanceChange {
+	totals := make(map[string]*big.Int)
+	var order []string
+	add := func(token string, amount *big.Int) {
+		if _, ok := totals[token]; !ok {
+			totals[token] = new(big.Int)
+			order = append(order, token)
+		}
+		totals[token].Add(totals[token], amount)
+	}
+
+	var walk func(frame *callFrame)
+	walk = func(frame *callFrame) {
+		if frame.Error != "" {
+			return
+		}
+		if frame.Value != nil && frame.Value.ToInt().Sign() > 0 && frame.Type != "DELEGATECALL" {
+			value := frame.Value.ToInt()
+			if frame.From == account {
+				add("", new(big.Int).Neg(value))
+			}
+			if frame.To == account {
+				add("", value)
+			}
+		}
+		for _, log := range frame.Logs {
+			if len(log.Topics) != 3 || log.Topics[0] != erc20TransferTopic || len(log.Data) != 32 {
+				continue
+			}
+			amount := new(big.Int).SetBytes(log.Data)
+			token := log.Address.Hex()
+			if common.BytesToAddress(log.Topics[1].Bytes()) == account {
+				add(token, new(big.Int).Neg(amount))
+			}
+			if common.BytesToAddress(log.Topics[2].Bytes()) == account {
+				add(token, amount)
+			}
+		}
+		for i := range frame.Calls {
+			walk(&frame.Calls[i])
+		}
+	}
+	walk(f)
+
+	return collectBalanceChanges(totals, order)
+}
+
+// calldataBalanceChanges derives the sender's balance changes from the call itself: the native
+// value sent and ERC-20 transfer or transferFrom amounts
+func calldataBalanceChanges(msg ethereum.CallMsg) []BalanceChange {
+	totals := make(map[string]*big.Int)
+	var order []string
+	if msg.Value != nil && msg.Value.Sign() > 0 {
+		totals[""] = new(big.Int).Neg(msg.Value)
+		order = append(order, "")
+	}
+
+	if msg.To != nil && len(msg.Data) >= 4 {
+		token := msg.To.Hex()
+		var sender, recipient common.Address
+		var amount *big.Int
+		switch {
+		case bytes.Equal(msg.Data[:4], erc20TransferSelector) && len(msg.Data) == 4+64:
+			sender = msg.From
+			recipient = common.BytesToAddress(msg.Data[4:36])
+			amount = new(big.Int).SetBytes(msg.Data[36:68])
+		case bytes.Equal(msg.Data[:4], erc20TransferFromSelector) && len(msg.Data) == 4+96:
+			sender = common.BytesToAddress(msg.Data[4:36])
+			recipient = common.BytesToAddress(msg.Data[36:68])
+			amount = new(big.Int).SetBytes(msg.Data[68:100])
+		}
+		if amount != nil && sender != recipient {
+			change := new(big.Int)
+			if sender == msg.From {
+				change.Neg(amount)
+			} else if recipient == msg.From {
+				change.Set(amount)
+			}
+			if change.Sign() != 0 {
+				totals[token] = change
+				order = append(order, token)
+			}
+		}
+	}
+
+	return collectBalanceChanges(totals, order)
+}
+
+// collectBalanceChanges lists the non-zero totals in first-seen order
+func collectBalanceChanges(totals map[string]*big.Int, order []string) []BalanceChange {
+	var changes []BalanceChange
+	for _, token := range order {
+		if totals[token].Sign() != 0 {
+			changes = append(changes, BalanceChange{Token: token, Amount: totals[token]})
+		}
+	}
+	return changes
+}
+
+// decodeRevert extracts the revert data of a failed call from the RPC error or the trace and
+// decodes Error(string), Panic(uint256) and well-known custom errors
+func decodeRevert(err error, trace *callFrame) (string, string) {
+	var data []byte
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if encoded, ok := dataErr.ErrorData().(string); ok {
+			data, _ = hexutil.Decode(encoded)
+		}
+	}
+	if len(data) == 0 && trace != nil {
+		data = trace.Output
+	}
+
+	if len(data) >= 4 {
+		if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+			return hexutil.Encode(data), reason
+		}
+		if signature, ok := knownCustomErrors[string(data[:4])]; ok {
+			return hexutil.Encode(data), signature
+		}
+		return hexutil.Encode(data), fmt.Sprintf("custom error %s", hexutil.Encode(data[:4]))
+	}
+
+	if trace != nil && trace.RevertReason != "" {
+		return "", trace.RevertReason
+	}
+	// Nodes without revert data still report the reason in the message
+	if reason, found := strings.CutPrefix(err.Error(), "execution reverted: "); found {
+		return "", reason
+	}
+	return "", ""
+}
@@ -0,0 +1,188 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+type mockSimulationBackend struct {
+	callErr error
+	gas     uint64
+}
+
+func (m *mockSimulationBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if m.callErr != nil {
+		return nil, m.callErr
+	}
+	return []byte{0x01}, nil
+}
+func (m *mockSimulationBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return m.gas, nil
+}
+func (m *mockSimulationBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(10), nil
+}
+
+// mockCallTracer answers debug_traceCall with a fixed callTracer result
+type mockCallTracer struct {
+	result string
+}
+
+func (m *mockCallTracer) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if m.result == "" {
+		return errors.New("the method debug_traceCall does not exist/is not available")
+	}
+	return json.Unmarshal([]byte(m.result), result)
+}
+
+// revertError mimics the RPC error of a reverted eth_call
+type revertError struct {
+	data string
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorCode() int         { return 3 }
+func (e *revertError) ErrorData() interface{} { return e.data }
+
+func revertData(t *testing.T, reason string) string {
+	typ, _ := abi.NewType("string", "", nil)
+	packed, err := abi.Arguments{{Type: typ}}.Pack(reason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(append(crypto.Keccak256([]byte("Error(string)"))[:4], packed...))
+}
+
+func TestSimulateCall(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	recipient := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	transferData := append(append([]byte{}, erc20TransferSelector...), common.LeftPadBytes(recipient.Bytes(), 32)...)
+	transferData = append(transferData, common.LeftPadBytes(big.NewInt(500).Bytes(), 32)...)
+	msg := ethereum.CallMsg{From: sender, To: &token, Data: transferData}
+
+	t.Run("DecodesRevertReason", func(t *testing.T) {
+		backend := &mockSimulationBackend{callErr: &revertError{data: revertData(t, "insufficient balance")}}
+
+		simulation, err := SimulateCall(context.Background(), backend, nil, msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if simulation.Success {
+			t.Fatal("expected failed simulation")
+		}
+		if simulation.RevertReason != "insufficient balance" || simulation.RevertData == "" {
+			t.Errorf("expected decoded revert reason, got %q", simulation.RevertReason)
+		}
+		if len(simulation.BalanceChanges) != 0 {
+			t.Errorf("expected no balance changes for a reverted call")
+		}
+
+		simErr := &SimulationError{Simulation: simulation}
+		if !errors.Is(simErr, ErrSimulationFailed) {
+			t.Errorf("expected SimulationError to wrap ErrSimulationFailed")
+		}
+	})
+
+	t.Run("DecodesCustomErrorFromTrace", func(t *testing.T) {
+		selector := crypto.Keccak256([]byte("ERC20InsufficientBalance(address,uint256,uint256)"))[:4]
+		backend := &mockSimulationBackend{callErr: errors.New("execution reverted")}
+		tracer := &mockCallTracer{result: `{"type":"CALL","gasUsed":"0x5208","error":"execution reverted","output":"` +
+			hexutil.Encode(selector) + `"}`}
+
+		simulation, _ := SimulateCall(context.Background(), backend, tracer, msg)
+		if simulation.RevertReason != "ERC20InsufficientBalance(address,uint256,uint256)" {
+			t.Errorf("expected known custom error, got %q", simulation.RevertReason)
+		}
+		if simulation.GasUsed != 21000 {
+			t.Errorf("expected gas used from trace, got %d", simulation.GasUsed)
+		}
+	})
+
+	t.Run("BalanceChangesFromCalldata", func(t *testing.T) {
+		backend := &mockSimulationBackend{gas: 50000}
+
+		simulation, err := SimulateCall(context.Background(), backend, &mockCallTracer{}, msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !simulation.Success || simulation.GasUsed != 50000 || simulation.EstimatedCost.Int64() != 500000 {
+			t.Fatalf("unexpected simulation: %+v", simulation)
+		}
+		if len(simulation.BalanceChanges) != 1 || simulation.BalanceChanges[0].Token != token.Hex() ||
+			simulation.BalanceChanges[0].Amount.Int64() != -500 {
+			t.Errorf("expected -500 token balance change, got %+v", simulation.BalanceChanges)
+		}
+	})
+
+	t.Run("BalanceChangesFromTrace", func(t *testing.T) {
+		backend := &mockSimulationBackend{gas: 120000}
+		transferTopic := erc20TransferTopic.Hex()
+		trace := `{"type":"CALL","from":"` + sender.Hex() + `","to":"` + recipient.Hex() + `","value":"0x64","gasUsed":"0x1d4c0",
+			"calls":[
+				{"type":"CALL","from":"` + recipient.Hex() + `","to":"` + token.Hex() + `","gasUsed":"0x100",
+				 "logs":[{"address":"` + token.Hex() + `","topics":["` + transferTopic + `","` +
+			common.BytesToHash(recipient.Bytes()).Hex() + `","` + common.BytesToHash(sender.Bytes()).Hex() + `"],
+				 "data":"` + hexutil.Encode(common.LeftPadBytes(big.NewInt(900).Bytes(), 32)) + `"}]},
+				{"type":"CALL","from":"` + recipient.Hex() + `","to":"` + token.Hex() + `","error":"execution reverted",
+				 "logs":[{"address":"` + token.Hex() + `","topics":["` + transferTopic + `","` +
+			common.BytesToHash(recipient.Bytes()).Hex() + `","` + common.BytesToHash(sender.Bytes()).Hex() + `"],
+				 "data":"` + hexutil.Encode(common.LeftPadBytes(big.NewInt(5).Bytes(), 32)) + `"}]}
+			]}`
+
+		simulation, err := SimulateCall(context.Background(), backend, &mockCallTracer{result: trace}, ethereum.CallMsg{
+			From: sender, To: &recipient, Value: big.NewInt(100),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(simulation.Traces) != 3 {
+			t.Errorf("expected a trace line per call, got %v", simulation.Traces)
+		}
+		if len(simulation.BalanceChanges) != 2 {
+			t.Fatalf("expected native and token changes, got %+v", simulation.BalanceChanges)
+		}
+		if simulation.BalanceChanges[0].Token != "" || simulation.BalanceChanges[0].Amount.Int64() != -100 {
+			t.Errorf("expected -100 native change, got %+v", simulation.BalanceChanges[0])
+		}
+		// Transfers in reverted frames are ignored
+		if simulation.BalanceChanges[1].Token != token.Hex() || simulation.BalanceChanges[1].Amount.Int64() != 900 {
+			t.Errorf("expected +900 token change, got %+v", simulation.BalanceChanges[1])
+		}
+	})
+}
+
+func TestSimulateTransaction_WalletLookup(t *testing.T) {
+	owner := uuid.New()
+	wallet := &Wallet{ID: uuid.New(), UserID: owner, ChainID: 8453, Address: "0x1111111111111111111111111111111111111111"}
+	s := &EnhancedService{walletRepo: &mockWalletRepo{getByID: map[uuid.UUID]*Wallet{wallet.ID: wallet}}}
+	req := SimulationRequest{WalletID: wallet.ID, ToAddress: "0x2222222222222222222222222222222222222222"}
+
+	_, err := s.SimulateTransaction(context.Background(), owner, SimulationRequest{WalletID: uuid.New(), ToAddress: req.ToAddress})
+	if !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("expected ErrWalletNotFound, got %v", err)
+	}
+
+	_, err = s.SimulateTransaction(context.Background(), uuid.New(), req)
+	if !errors.Is(err, ErrWalletAccessDenied) {
+		t.Errorf("expected ErrWalletAccessDenied, got %v", err)
+	}
+
+	// The owner's wallet supplies the chain and sender
+	_, err = s.SimulateTransaction(context.Background(), owner, req)
+	if err == nil || !strings.Contains(err.Error(), "chain ID: 8453") {
+		t.Errorf("expected the wallet's chain to be used, got %v", err)
+	}
+}
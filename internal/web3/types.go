@@ -13,6 +13,12 @@ const (
 	TxStatusPending   = "pending"
 	TxStatusConfirmed = "confirmed"
 	TxStatusFailed    = "failed"
+
+	// Transactions of wallets whose keys the service doesn't hold wait for the user's wallet
+	// to sign them, and are cancelled or expire if it doesn't
+	TxStatusAwaitingSignature = "awaiting_signature"
+	TxStatusCancelled         = "cancelled"
+	TxStatusExpired           = "expired"
 )
 
 // Supported blockchain networks
@@ -24,6 +30,7 @@ var SupportedChains = map[int]string{
 	250:   "fantom",
 	42161: "arbitrum",
 	10:    "optimism",
+	8453:  "base",
 }
 
 // Wallet represents a cryptocurrency wallet
@@ -41,6 +48,12 @@ type Wallet struct {
 	CreatedAt  time.Time              `json:"created_at"`
 	UpdatedAt  time.Time              `json:"updated_at"`
 	Metadata   map[string]interface{} `json:"metadata"`
+
+	// Watch-only wallets are monitored by address and can't sign
+	WatchOnly bool `json:"watch_only"`
+
+	// Network is mainnet or testnet, following ChainID
+	Network string `json:"network"`
 }
 
 // Transaction represents a blockchain transaction
@@ -66,6 +79,17 @@ type Transaction struct {
 	CreatedAt       time.Time              `json:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at"`
 	Metadata        map[string]interface{} `json:"metadata"`
+
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+	FeeTier              FeeTier  `json:"fee_tier,omitempty"`
+
+	// Network is the network of the wallet that sent the transaction
+	Network string `json:"network"`
+
+	// Asset is the coin or token the transaction sends or approves, described by the token
+	// registry; nil for other contract calls
+	Asset *TransactionAsset `json:"asset,omitempty"`
 }
 
 // WalletConnectRequest represents a wallet connection request
@@ -75,6 +99,12 @@ type WalletConnectRequest struct {
 	ChainID    int                    `json:"chain_id"`
 	UserID     uuid.UUID              `json:"user_id"`
 	Metadata   map[string]interface{} `json:"metadata"`
+
+	// WatchOnly adds the address for monitoring only, without signing capability
+	WatchOnly bool `json:"watch_only"`
+
+	// Network "testnet" connects the wallet on the chain's testnet
+	Network string `json:"network,omitempty"`
 }
 
 // WalletConnectResponse represents a wallet connection response
@@ -93,6 +123,60 @@ type BalanceRequest struct {
 	Address  string    `json:"address"`
 	ChainID  int       `json:"chain_id"`
 	Token    string    `json:"token,omitempty"`
+
+	// Aggregate returns the balances of all the user's mainnet wallets across chains instead
+	Aggregate bool `json:"aggregate,omitempty"`
+
+	// Network "testnet" reads the address on the chain's testnet
+	Network string `json:"network,omitempty"`
+}
+
+// WalletSummary is a wallet enriched with its last cached native balance
+type WalletSummary struct {
+	*Wallet
+	NativeBalance *big.Int `json:"native_balance,omitempty"`
+	BalanceCached bool     `json:"balance_cached"`
+}
+
+// WalletListResponse represents a paginated wallet listing
+type WalletListResponse struct {
+	Wallets    []*WalletSummary `json:"wallets"`
+	Total      int              `json:"total"`
+	Limit      int              `json:"limit"`
+	Offset     int              `json:"offset"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+// DeFiPositionSummary is a DeFi position valued in USD for listings
+type DeFiPositionSummary struct {
+	ID           uuid.UUID       `json:"id"`
+	WalletID     uuid.UUID       `json:"wallet_id"`
+	Protocol     string          `json:"protocol"`
+	PositionType string          `json:"position_type"`
+	Asset        string          `json:"asset"`
+	Amount       decimal.Decimal `json:"amount"`
+	ValueUSD     decimal.Decimal `json:"value_usd"`
+	AccruedYield decimal.Decimal `json:"accrued_yield"`
+	APY          decimal.Decimal `json:"apy"`
+	IsActive     bool            `json:"is_active"`
+	Source       string          `json:"source"` // "live" or "stored"
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+
+	Analytics *LPAnalytics `json:"analytics,omitempty"` // liquidity pool positions only
+	Network   string       `json:"network"`
+
+	// Tokens describes the position's assets; assets matching no verified token are unverified
+	Tokens []*TokenMetadata `json:"tokens,omitempty"`
+}
+
+// DeFiPositionsResponse represents an aggregated DeFi position listing
+type DeFiPositionsResponse struct {
+	Positions         []*DeFiPositionSummary `json:"positions"`
+	Count             int                    `json:"count"`
+	TotalValueUSD     decimal.Decimal        `json:"total_value_usd"`
+	TotalAccruedYield decimal.Decimal        `json:"total_accrued_yield"`
+	Timestamp         time.Time              `json:"timestamp"`
 }
 
 // BalanceResponse represents a balance query response
@@ -104,9 +188,11 @@ type BalanceResponse struct {
 	Symbol        string                 `json:"symbol"`
 	Decimals      int                    `json:"decimals"`
 	NativeBalance *big.Int               `json:"native_balance"`
+	NativeToken   *TokenMetadata         `json:"native_token,omitempty"`
 	TokenBalances []TokenBalance         `json:"token_balances"`
 	TotalUSDValue float64                `json:"total_usd_value"`
 	Metadata      map[string]interface{} `json:"metadata"`
+	Network       string                 `json:"network"`
 }
 
 // TokenBalance represents a token balance
@@ -117,6 +203,8 @@ type TokenBalance struct {
 	Balance      *big.Int `json:"balance"`
 	Decimals     int      `json:"decimals"`
 	USDValue     float64  `json:"usd_value"`
+
+	Token *TokenMetadata `json:"token,omitempty"`
 }
 
 // TransactionRequest represents a transaction creation request
@@ -131,6 +219,17 @@ type TransactionRequest struct {
 	GasPrice  *big.Int               `json:"gas_price"`
 	ChainID   int                    `json:"chain_id"`
 	Metadata  map[string]interface{} `json:"metadata"`
+
+	// EIP-1559 fees; unset fees are suggested from FeeTier when it is given
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+	FeeTier              FeeTier  `json:"fee_tier,omitempty"`
+
+	// Network must match the wallet's network when set
+	Network string `json:"network,omitempty"`
+
+	// AcknowledgeRisk lets a transaction that screening warned about proceed
+	AcknowledgeRisk bool `json:"acknowledge_risk,omitempty"`
 }
 
 // TransactionResponse represents a transaction creation response
@@ -142,6 +241,13 @@ type TransactionResponse struct {
 	Status        string       `json:"status"`
 	Success       bool         `json:"success"`
 	Message       string       `json:"message"`
+
+	Recipient *RecipientResolution  `json:"recipient,omitempty"` // how the "to" value was resolved
+	Screening *TransactionScreening `json:"screening,omitempty"`
+	// SigningRequest is set while the transaction awaits the wallet's signature
+	SigningRequest *SigningRequest `json:"signing_request,omitempty"`
+	// Proposal is set when the wallet is a multisig and the transaction awaits approvals
+	Proposal *TransactionProposal `json:"proposal,omitempty"`
 }
 
 // PriceRequest represents a price query request
@@ -269,6 +375,7 @@ type DeFiProtocolRequest struct {
 	Amount   decimal.Decimal        `json:"amount"`
 	Token    string                 `json:"token"`
 	Metadata map[string]interface{} `json:"metadata"`
+	Network  string                 `json:"network,omitempty"` // must match the wallet's network when set
 }
 
 // DeFiProtocolResponse represents a DeFi protocol response
@@ -0,0 +1,883 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/mr-tron/base58"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// WalletTypeWalletConnect is the wallet type of wallets connected through a WalletConnect session
+const WalletTypeWalletConnect = "walletconnect"
+
+var (
+	ErrWalletConnectSessionNotFound = errors.New("walletconnect session not found")
+	// ErrNoWalletConnectSession means the wallet isn't paired over WalletConnect
+	ErrNoWalletConnectSession      = errors.New("no active walletconnect session for wallet")
+	ErrInvalidWalletConnectMessage = errors.New("invalid walletconnect message")
+	ErrWalletConnectChain          = errors.New("chain unavailable for walletconnect")
+)
+
+// WalletConnect v2 relay message tags, which tell the relay what a message is
+const (
+	wcTagSessionPropose        = 1100
+	wcTagSessionSettleResponse = 1103
+	wcTagSessionRequest        = 1108
+	wcTagSessionEventResponse  = 1111
+	wcTagSessionDelete         = 1112
+	wcTagSessionDeleteResponse = 1113
+	wcTagSessionPingResponse   = 1115
+)
+
+// wcSigningMethods are the methods the service asks wallets to support
+var wcSigningMethods = []string{"eth_signTransaction", "eth_sendTransaction", "personal_sign", "eth_signTypedData_v4"}
+
+// WalletConnectMetadata describes an app to the other side of a session
+type WalletConnectMetadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Icons       []string `json:"icons"`
+}
+
+// WalletConnectConfig configures WalletConnect v2 sessions
+type WalletConnectConfig struct {
+	Metadata   WalletConnectMetadata
+	PairingTTL time.Duration // how long a pairing URI can be scanned
+}
+
+// WalletConnectSessionStatus is proposed until the wallet app settles the session
+type WalletConnectSessionStatus string
+
+const (
+	WalletConnectProposed WalletConnectSessionStatus = "proposed"
+	WalletConnectActive   WalletConnectSessionStatus = "active"
+)
+
+// WalletConnectSession is a pairing with a user's wallet app and the session settled over it.
+// The keys are secret; Public strips them before sessions leave the service.
+type WalletConnectSession struct {
+	UserID       uuid.UUID                  `json:"user_id"`
+	Status       WalletConnectSessionStatus `json:"status"`
+	PairingTopic string                     `json:"pairing_topic"`
+	PairingURI   string                     `json:"pairing_uri,omitempty"`
+	SessionTopic string                     `json:"session_topic,omitempty"`
+	ChainIDs     []int                      `json:"chain_ids"`
+	Accounts     []string                   `json:"accounts,omitempty"` // CAIP-10 eip155 accounts
+	WalletIDs    []uuid.UUID                `json:"wallet_ids,omitempty"`
+	Peer         *WalletConnectMetadata     `json:"peer,omitempty"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	ExpiresAt    time.Time                  `json:"expires_at"`
+
+	ProposalID    int64  `json:"proposal_id,omitempty"`
+	PairingSymKey string `json:"pairing_sym_key,omitempty"`
+	PrivateKey    string `json:"private_key,omitempty"` // X25519 key agreed with the wallet
+	SessionSymKey string `json:"session_sym_key,omitempty"`
+}
+
+// Public returns the session without its keys; the pairing URI is kept until it's used
+func (s *WalletConnectSession) Public() *WalletConnectSession {
+	public := *s
+	public.ProposalID = 0
+	public.PairingSymKey, public.PrivateKey, public.SessionSymKey = "", "", ""
+	if public.Status != WalletConnectProposed {
+		public.PairingURI = ""
+	}
+	return &public
+}
+
+// hasAccount reports whether the session controls the address on the chain
+func (s *WalletConnectSession) hasAccount(chainID int, address string) bool {
+	account := fmt.Sprintf("eip155:%d:%s", chainID, strings.ToLower(address))
+	for _, a := range s.Accounts {
+		if strings.ToLower(a) == account {
+			return true
+		}
+	}
+	return false
+}
+
+// WalletConnectPairingRequest asks for a pairing URI for sessions on the given chains
+type WalletConnectPairingRequest struct {
+	ChainIDs []int `json:"chain_ids"`
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *WalletConnectPairingRequest) Validate() error {
+	var v validation.Validator
+	if len(r.ChainIDs) == 0 {
+		v.Fail("chain_ids", validation.ConstraintRequired, "is required")
+	}
+	return v.Err()
+}
+
+// WalletConnectMessageRequest forwards a message the relay delivered on a session topic
+type WalletConnectMessageRequest struct {
+	Topic   string `json:"topic"`
+	Message string `json:"message"`
+}
+
+// Validate checks the request and returns validation.FieldErrors listing every invalid field
+func (r *WalletConnectMessageRequest) Validate() error {
+	var v validation.Validator
+	v.Required("topic", r.Topic)
+	v.Required("message", r.Message)
+	return v.Err()
+}
+
+// WalletConnectStore keeps sessions, found by either their pairing or session topic
+type WalletConnectStore interface {
+	Save(ctx context.Context, session *WalletConnectSession) error
+	// Get returns the session on the topic, or nil
+	Get(ctx context.Context, topic string) (*WalletConnectSession, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*WalletConnectSession, error)
+	Delete(ctx context.Context, session *WalletConnectSession) error
+}
+
+// WalletConnectRelay publishes encrypted messages to a topic on the WalletConnect relay
+type WalletConnectRelay interface {
+	Publish(ctx context.Context, topic, message string, tag int, ttl time.Duration) error
+}
+
+// WalletConnectManager runs the dapp side of WalletConnect v2 sessions. It generates pairing
+// URIs, keeps the session keys and pushes signing requests to the user's wallet app through
+// the relay. The browser holds the relay subscription and forwards what arrives on a
+// session's topics to HandleMessage, so keys never leave the service.
+type WalletConnectManager struct {
+	service *Service
+	store   WalletConnectStore
+	relay   WalletConnectRelay
+	logger  *observability.Logger
+	config  WalletConnectConfig
+	now     func() time.Time
+
+	// onResponse takes the wallet app's answer to a signing request
+	onResponse func(ctx context.Context, topic string, requestID int64, signed string, rejection error) error
+}
+
+// NewWalletConnectManager creates a manager publishing through the relay
+func NewWalletConnectManager(service *Service, store WalletConnectStore, relay WalletConnectRelay, cfg WalletConnectConfig) *WalletConnectManager {
+	if cfg.PairingTTL <= 0 {
+		cfg.PairingTTL = 5 * time.Minute
+	}
+	return &WalletConnectManager{
+		service: service,
+		store:   store,
+		relay:   relay,
+		logger:  service.logger,
+		config:  cfg,
+		now:     time.Now,
+	}
+}
+
+// wcMessage is a WalletConnect JSON-RPC request or response
+type wcMessage struct {
+	ID      int64           `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *wcError        `json:"error,omitempty"`
+}
+
+type wcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *wcError) Error() string {
+	return fmt.Sprintf("wallet rejected the request: %s (code %d)", e.Message, e.Code)
+}
+
+type wcRelayProtocol struct {
+	Protocol string `json:"protocol"`
+}
+
+type wcNamespace struct {
+	Chains   []string `json:"chains,omitempty"`
+	Accounts []string `json:"accounts,omitempty"`
+	Methods  []string `json:"methods"`
+	Events   []string `json:"events"`
+}
+
+type wcParticipant struct {
+	PublicKey string                `json:"publicKey"`
+	Metadata  WalletConnectMetadata `json:"metadata"`
+}
+
+// CreatePairing starts a session on the given chains and returns the pairing URI the user
+// scans with their wallet app
+func (m *WalletConnectManager) CreatePairing(ctx context.Context, userID uuid.UUID, chainIDs []int) (*WalletConnectSession, error) {
+	if len(chainIDs) == 0 {
+		return nil, fmt.Errorf("at least one chain is required")
+	}
+	chains := make([]string, 0, len(chainIDs))
+	for _, chainID := range chainIDs {
+		if _, err := m.service.provider(chainID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrWalletConnectChain, err)
+		}
+		chains = append(chains, "eip155:"+strconv.Itoa(chainID))
+	}
+
+	symKey, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	topic, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := randomBytes(curve25519.ScalarSize)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+
+	now := m.now()
+	expiresAt := now.Add(m.config.PairingTTL)
+	session := &WalletConnectSession{
+		UserID:        userID,
+		Status:        WalletConnectProposed,
+		PairingTopic:  hex.EncodeToString(topic),
+		ChainIDs:      chainIDs,
+		CreatedAt:     now,
+		ExpiresAt:     expiresAt,
+		ProposalID:    wcMessageID(now),
+		PairingSymKey: hex.EncodeToString(symKey),
+		PrivateKey:    hex.EncodeToString(privateKey),
+	}
+	session.PairingURI = fmt.Sprintf("wc:%s@2?relay-protocol=irn&symKey=%s&expiryTimestamp=%d",
+		session.PairingTopic, session.PairingSymKey, expiresAt.Unix())
+
+	proposal := map[string]interface{}{
+		"requiredNamespaces": map[string]wcNamespace{},
+		"optionalNamespaces": map[string]wcNamespace{
+			"eip155": {Chains: chains, Methods: wcSigningMethods, Events: []string{"chainChanged", "accountsChanged"}},
+		},
+		"relays":          []wcRelayProtocol{{Protocol: "irn"}},
+		"proposer":        wcParticipant{PublicKey: hex.EncodeToString(publicKey), Metadata: m.config.Metadata},
+		"expiryTimestamp": expiresAt.Unix(),
+	}
+	if err := m.publishRequest(ctx, session.PairingTopic, symKey, session.ProposalID, "wc_sessionPropose", proposal, wcTagSessionPropose, m.config.PairingTTL); err != nil {
+		return nil, err
+	}
+	if err := m.store.Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save walletconnect session: %w", err)
+	}
+	return session.Public(), nil
+}
+
+// HandleMessage processes an encrypted message the relay delivered on one of the user's
+// session topics and returns the session as it stands afterwards, or nil once deleted
+func (m *WalletConnectManager) HandleMessage(ctx context.Context, userID uuid.UUID, topic, envelope string) (*WalletConnectSession, error) {
+	session, err := m.store.Get(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get walletconnect session: %w", err)
+	}
+	if session == nil || session.UserID != userID {
+		return nil, ErrWalletConnectSessionNotFound
+	}
+
+	onPairing := topic == session.PairingTopic
+	key := session.SessionSymKey
+	if onPairing {
+		key = session.PairingSymKey
+	}
+	symKey, err := hex.DecodeString(key)
+	if err != nil || len(symKey) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("%w: no key for topic", ErrInvalidWalletConnectMessage)
+	}
+	plaintext, err := wcDecrypt(symKey, envelope)
+	if err != nil {
+		return nil, err
+	}
+	var msg wcMessage
+	if err := json.Unmarshal(plaintext, &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWalletConnectMessage, err)
+	}
+
+	switch {
+	case onPairing && msg.Method == "" && msg.ID == session.ProposalID:
+		return m.handleProposalResponse(ctx, session, &msg)
+	case onPairing:
+		// Pairing pings and deletes need no action
+		return session.Public(), nil
+	case msg.Method == "wc_sessionSettle":
+		return m.handleSettle(ctx, session, &msg, symKey)
+	case msg.Method == "wc_sessionDelete":
+		if err := m.respond(ctx, topic, symKey, msg.ID, true, wcTagSessionDeleteResponse); err != nil {
+			return nil, err
+		}
+		if err := m.store.Delete(ctx, session); err != nil {
+			return nil, fmt.Errorf("failed to delete walletconnect session: %w", err)
+		}
+		return nil, nil
+	case msg.Method == "wc_sessionPing":
+		return session.Public(), m.respond(ctx, topic, symKey, msg.ID, true, wcTagSessionPingResponse)
+	case msg.Method == "wc_sessionEvent":
+		return session.Public(), m.respond(ctx, topic, symKey, msg.ID, true, wcTagSessionEventResponse)
+	case msg.Method == "":
+		return session.Public(), m.handleRequestResponse(ctx, topic, &msg)
+	}
+	return session.Public(), nil
+}
+
+// handleProposalResponse derives the session key from the key the wallet app answered with.
+// The wallet settles the session on the topic the key hashes to.
+func (m *WalletConnectManager) handleProposalResponse(ctx context.Context, session *WalletConnectSession, msg *wcMessage) (*WalletConnectSession, error) {
+	// The user declined to connect in their wallet app
+	if msg.Error != nil {
+		if err := m.store.Delete(ctx, session); err != nil {
+			return nil, fmt.Errorf("failed to delete walletconnect session: %w", err)
+		}
+		return nil, nil
+	}
+	var result struct {
+		ResponderPublicKey string `json:"responderPublicKey"`
+	}
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWalletConnectMessage, err)
+	}
+	peerKey, err := hex.DecodeString(result.ResponderPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: responder key: %v", ErrInvalidWalletConnectMessage, err)
+	}
+	privateKey, err := hex.DecodeString(session.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: private key: %v", ErrInvalidWalletConnectMessage, err)
+	}
+	symKey, err := wcDeriveSymKey(privateKey, peerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	session.SessionSymKey = hex.EncodeToString(symKey)
+	session.SessionTopic = wcTopic(symKey)
+	if err := m.store.Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save walletconnect session: %w", err)
+	}
+	return session.Public(), nil
+}
+
+// handleSettle activates the session and connects its accounts as WalletConnect wallets
+func (m *WalletConnectManager) handleSettle(ctx context.Context, session *WalletConnectSession, msg *wcMessage, symKey []byte) (*WalletConnectSession, error) {
+	var params struct {
+		Namespaces map[string]wcNamespace `json:"namespaces"`
+		Controller wcParticipant          `json:"controller"`
+		Expiry     int64                  `json:"expiry"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWalletConnectMessage, err)
+	}
+
+	session.Accounts = session.Accounts[:0]
+	session.WalletIDs = session.WalletIDs[:0]
+	for _, account := range params.Namespaces["eip155"].Accounts {
+		parts := strings.Split(account, ":")
+		if len(parts) != 3 || !common.IsHexAddress(parts[2]) {
+			continue
+		}
+		chainID, err := strconv.Atoi(parts[1])
+		if err != nil || !containsChain(session.ChainIDs, chainID) {
+			continue
+		}
+		connected, err := m.service.ConnectWallet(ctx, session.UserID, WalletConnectRequest{
+			WalletType: WalletTypeWalletConnect,
+			Address:    parts[2],
+			ChainID:    chainID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect wallet %s: %w", account, err)
+		}
+		session.Accounts = append(session.Accounts, strings.ToLower(account))
+		session.WalletIDs = append(session.WalletIDs, connected.Wallet.ID)
+	}
+	if len(session.Accounts) == 0 {
+		return nil, fmt.Errorf("%w: session has no accounts on the requested chains", ErrInvalidWalletConnectMessage)
+	}
+
+	metadata := params.Controller.Metadata
+	session.Peer = &metadata
+	session.Status = WalletConnectActive
+	session.ExpiresAt = time.Unix(params.Expiry, 0)
+	session.ProposalID = 0
+	session.PairingSymKey = ""
+	if err := m.store.Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save walletconnect session: %w", err)
+	}
+	if err := m.respond(ctx, session.SessionTopic, symKey, msg.ID, true, wcTagSessionSettleResponse); err != nil {
+		return nil, err
+	}
+
+	m.logger.Info(ctx, "WalletConnect session settled", map[string]interface{}{
+		"user_id":  session.UserID.String(),
+		"accounts": session.Accounts,
+		"peer":     metadata.Name,
+	})
+	return session.Public(), nil
+}
+
+// handleRequestResponse passes the wallet app's answer to a signing request on
+func (m *WalletConnectManager) handleRequestResponse(ctx context.Context, topic string, msg *wcMessage) error {
+	if m.onResponse == nil {
+		return nil
+	}
+	if msg.Error != nil {
+		return m.onResponse(ctx, topic, msg.ID, "", msg.Error)
+	}
+	var signed string
+	if err := json.Unmarshal(msg.Result, &signed); err != nil {
+		return fmt.Errorf("%w: result is not a signed transaction", ErrInvalidWalletConnectMessage)
+	}
+	return m.onResponse(ctx, topic, msg.ID, signed, nil)
+}
+
+// RequestSignature pushes a transaction to the wallet app of the user's session controlling
+// the wallet and returns the session topic and request ID the answer will come back with
+func (m *WalletConnectManager) RequestSignature(ctx context.Context, wallet *Wallet, tx *UnsignedTransaction, ttl time.Duration) (string, int64, error) {
+	session, err := m.activeSession(ctx, wallet)
+	if err != nil {
+		return "", 0, err
+	}
+	symKey, err := hex.DecodeString(session.SessionSymKey)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: no session key", ErrInvalidWalletConnectMessage)
+	}
+
+	params := map[string]string{
+		"from":  tx.From,
+		"to":    tx.To,
+		"value": decimalToHex(tx.Value),
+		"nonce": hexutil.EncodeUint64(tx.Nonce),
+		"gas":   hexutil.EncodeUint64(tx.GasLimit),
+	}
+	if tx.Data != "" {
+		params["data"] = tx.Data
+	}
+	if tx.MaxFeePerGas != "" {
+		params["maxFeePerGas"] = decimalToHex(tx.MaxFeePerGas)
+		params["maxPriorityFeePerGas"] = decimalToHex(tx.MaxPriorityFeePerGas)
+	} else {
+		params["gasPrice"] = decimalToHex(tx.GasPrice)
+	}
+	request := map[string]interface{}{
+		"request": map[string]interface{}{"method": "eth_signTransaction", "params": []interface{}{params}},
+		"chainId": "eip155:" + strconv.Itoa(tx.ChainID),
+	}
+
+	// The relay keeps requests for at least five minutes
+	if ttl < 5*time.Minute {
+		ttl = 5 * time.Minute
+	}
+	id := wcMessageID(m.now())
+	if err := m.publishRequest(ctx, session.SessionTopic, symKey, id, "wc_sessionRequest", request, wcTagSessionRequest, ttl); err != nil {
+		return "", 0, err
+	}
+	return session.SessionTopic, id, nil
+}
+
+// activeSession finds the user's settled session controlling the wallet
+func (m *WalletConnectManager) activeSession(ctx context.Context, wallet *Wallet) (*WalletConnectSession, error) {
+	sessions, err := m.store.ListByUser(ctx, wallet.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list walletconnect sessions: %w", err)
+	}
+	now := m.now()
+	for _, session := range sessions {
+		if session.Status == WalletConnectActive && now.Before(session.ExpiresAt) && session.hasAccount(wallet.ChainID, wallet.Address) {
+			return session, nil
+		}
+	}
+	return nil, ErrNoWalletConnectSession
+}
+
+// ListSessions returns the user's sessions, without their keys
+func (m *WalletConnectManager) ListSessions(ctx context.Context, userID uuid.UUID) ([]*WalletConnectSession, error) {
+	sessions, err := m.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list walletconnect sessions: %w", err)
+	}
+	public := make([]*WalletConnectSession, 0, len(sessions))
+	for _, session := range sessions {
+		public = append(public, session.Public())
+	}
+	return public, nil
+}
+
+// Disconnect tells the wallet app the session is over and forgets it. The wallets it
+// connected stay; their transactions are signed through the queue from then on.
+func (m *WalletConnectManager) Disconnect(ctx context.Context, userID uuid.UUID, topic string) error {
+	session, err := m.store.Get(ctx, topic)
+	if err != nil {
+		return fmt.Errorf("failed to get walletconnect session: %w", err)
+	}
+	if session == nil || session.UserID != userID {
+		return ErrWalletConnectSessionNotFound
+	}
+	if session.Status == WalletConnectActive {
+		symKey, err := hex.DecodeString(session.SessionSymKey)
+		if err == nil {
+			reason := map[string]interface{}{"code": 6000, "message": "User disconnected."}
+			if err := m.publishRequest(ctx, session.SessionTopic, symKey, wcMessageID(m.now()), "wc_sessionDelete", reason, wcTagSessionDelete, 24*time.Hour); err != nil {
+				m.logger.Warn(ctx, "Failed to notify wallet of disconnect", map[string]interface{}{"error": err.Error()})
+			}
+		}
+	}
+	if err := m.store.Delete(ctx, session); err != nil {
+		return fmt.Errorf("failed to delete walletconnect session: %w", err)
+	}
+	return nil
+}
+
+func (m *WalletConnectManager) publishRequest(ctx context.Context, topic string, symKey []byte, id int64, method string, params interface{}, tag int, ttl time.Duration) error {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", method, err)
+	}
+	return m.publish(ctx, topic, symKey, wcMessage{ID: id, JSONRPC: "2.0", Method: method, Params: encoded}, tag, ttl)
+}
+
+func (m *WalletConnectManager) respond(ctx context.Context, topic string, symKey []byte, id int64, result interface{}, tag int) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	return m.publish(ctx, topic, symKey, wcMessage{ID: id, JSONRPC: "2.0", Result: encoded}, tag, 5*time.Minute)
+}
+
+func (m *WalletConnectManager) publish(ctx context.Context, topic string, symKey []byte, msg wcMessage, tag int, ttl time.Duration) error {
+	plaintext, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+	envelope, err := wcEncrypt(symKey, plaintext)
+	if err != nil {
+		return err
+	}
+	if err := m.relay.Publish(ctx, topic, envelope, tag, ttl); err != nil {
+		return fmt.Errorf("walletconnect relay: %w", err)
+	}
+	return nil
+}
+
+// wcDeriveSymKey agrees on the session key: HKDF-SHA256 over the X25519 shared secret
+func wcDeriveSymKey(privateKey, peerPublicKey []byte) ([]byte, error) {
+	shared, err := curve25519.X25519(privateKey, peerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key agreement: %v", ErrInvalidWalletConnectMessage, err)
+	}
+	symKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, nil, nil), symKey); err != nil {
+		return nil, fmt.Errorf("derive session key: %w", err)
+	}
+	return symKey, nil
+}
+
+// wcTopic is the topic of a session: the SHA-256 of its key
+func wcTopic(symKey []byte) string {
+	sum := sha256.Sum256(symKey)
+	return hex.EncodeToString(sum[:])
+}
+
+// wcEncrypt seals a message in a type 0 envelope: the type byte, the nonce and the
+// ChaCha20-Poly1305 ciphertext, base64 encoded
+func wcEncrypt(symKey, plaintext []byte) (string, error) {
+	aead, err := chacha20poly1305.New(symKey)
+	if err != nil {
+		return "", fmt.Errorf("walletconnect cipher: %w", err)
+	}
+	iv, err := randomBytes(chacha20poly1305.NonceSize)
+	if err != nil {
+		return "", err
+	}
+	envelope := append([]byte{0}, iv...)
+	envelope = aead.Seal(envelope, iv, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+func wcDecrypt(symKey []byte, envelope string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWalletConnectMessage, err)
+	}
+	if len(raw) < 1+chacha20poly1305.NonceSize || raw[0] != 0 {
+		return nil, fmt.Errorf("%w: unsupported envelope", ErrInvalidWalletConnectMessage)
+	}
+	aead, err := chacha20poly1305.New(symKey)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect cipher: %w", err)
+	}
+	iv := raw[1 : 1+chacha20poly1305.NonceSize]
+	plaintext, err := aead.Open(nil, iv, raw[1+chacha20poly1305.NonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWalletConnectMessage, err)
+	}
+	return plaintext, nil
+}
+
+// wcMessageID returns a JSON-RPC ID the way WalletConnect clients do: the time in
+// microseconds with random low digits
+func wcMessageID(now time.Time) int64 {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000))
+	if err != nil {
+		return now.UnixMilli() * 1000
+	}
+	return now.UnixMilli()*1000 + n.Int64()
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate random bytes: %w", err)
+	}
+	return b, nil
+}
+
+// decimalToHex converts a decimal amount to a JSON-RPC quantity
+func decimalToHex(value string) string {
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		n = new(big.Int)
+	}
+	return hexutil.EncodeBig(n)
+}
+
+func containsChain(chainIDs []int, chainID int) bool {
+	for _, id := range chainIDs {
+		if id == chainID {
+			return true
+		}
+	}
+	return false
+}
+
+// httpWalletConnectRelay publishes over the relay's HTTP JSON-RPC endpoint, authenticated
+// with a did:key JWT signed by a key generated at startup
+type httpWalletConnectRelay struct {
+	relayURL  string
+	projectID string
+	key       ed25519.PrivateKey
+	client    *http.Client
+}
+
+// NewHTTPWalletConnectRelay creates a relay client for the project
+func NewHTTPWalletConnectRelay(relayURL, projectID string) (WalletConnectRelay, error) {
+	if relayURL == "" {
+		relayURL = "https://relay.walletconnect.org"
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate relay key: %w", err)
+	}
+	return &httpWalletConnectRelay{
+		relayURL:  strings.TrimRight(relayURL, "/"),
+		projectID: projectID,
+		key:       key,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (r *httpWalletConnectRelay) Publish(ctx context.Context, topic, message string, tag int, ttl time.Duration) error {
+	token, err := r.authToken(time.Now())
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"id":      wcMessageID(time.Now()),
+		"jsonrpc": "2.0",
+		"method":  "irn_publish",
+		"params": map[string]interface{}{
+			"topic":   topic,
+			"message": message,
+			"ttl":     int64(ttl.Seconds()),
+			"tag":     tag,
+			"prompt":  tag == wcTagSessionRequest,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/rpc?projectId=%s&auth=%s", r.relayURL, url.QueryEscape(r.projectID), url.QueryEscape(token))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("relay returned status %d", resp.StatusCode)
+	}
+	var result wcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode relay response: %w", err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("relay error: %s", result.Error.Message)
+	}
+	return nil
+}
+
+// authToken signs the relay's EdDSA JWT, issued by the did:key of the client key
+func (r *httpWalletConnectRelay) authToken(now time.Time) (string, error) {
+	publicKey := r.key.Public().(ed25519.PublicKey)
+	// did:key identifiers are the base58btc multibase of the ed25519 multicodec and key
+	issuer := "did:key:z" + base58.Encode(append([]byte{0xed, 0x01}, publicKey...))
+	subject, err := randomBytes(32)
+	if err != nil {
+		return "", err
+	}
+
+	encode := func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	}
+	header, err := encode(map[string]string{"alg": "EdDSA", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := encode(map[string]interface{}{
+		"iss": issuer,
+		"sub": hex.EncodeToString(subject),
+		"aud": r.relayURL,
+		"iat": now.Unix(),
+		"exp": now.Add(24 * time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + claims
+	signature := ed25519.Sign(r.key, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// redisWalletConnectStore keeps sessions in Redis until they expire, keyed by pairing topic
+// with the session topic pointing at it
+type redisWalletConnectStore struct {
+	redis *database.RedisClient
+}
+
+// NewRedisWalletConnectStore creates a session store shared through Redis
+func NewRedisWalletConnectStore(redis *database.RedisClient) WalletConnectStore {
+	return &redisWalletConnectStore{redis: redis}
+}
+
+func walletConnectSessionKey(pairingTopic string) string {
+	return "web3:walletconnect:session:" + pairingTopic
+}
+
+func walletConnectTopicKey(sessionTopic string) string {
+	return "web3:walletconnect:topic:" + sessionTopic
+}
+
+func walletConnectUserKey(userID uuid.UUID) string {
+	return "web3:walletconnect:user:" + userID.String()
+}
+
+func (s *redisWalletConnectStore) Save(ctx context.Context, session *WalletConnectSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal walletconnect session: %w", err)
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return s.Delete(ctx, session)
+	}
+
+	pipe := s.redis.Client.TxPipeline()
+	pipe.Set(ctx, walletConnectSessionKey(session.PairingTopic), data, ttl)
+	if session.SessionTopic != "" {
+		pipe.Set(ctx, walletConnectTopicKey(session.SessionTopic), session.PairingTopic, ttl)
+	}
+	pipe.SAdd(ctx, walletConnectUserKey(session.UserID), session.PairingTopic)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *redisWalletConnectStore) Get(ctx context.Context, topic string) (*WalletConnectSession, error) {
+	pairingTopic, err := s.redis.Client.Get(ctx, walletConnectTopicKey(topic)).Result()
+	if err == redis.Nil {
+		pairingTopic = topic
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := s.redis.Client.Get(ctx, walletConnectSessionKey(pairingTopic)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session WalletConnectSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal walletconnect session: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *redisWalletConnectStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*WalletConnectSession, error) {
+	topics, err := s.redis.Client.SMembers(ctx, walletConnectUserKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*WalletConnectSession, 0, len(topics))
+	for _, topic := range topics {
+		session, err := s.Get(ctx, topic)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			// Expired out of Redis
+			s.redis.Client.SRem(ctx, walletConnectUserKey(userID), topic)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (s *redisWalletConnectStore) Delete(ctx context.Context, session *WalletConnectSession) error {
+	pipe := s.redis.Client.TxPipeline()
+	pipe.Del(ctx, walletConnectSessionKey(session.PairingTopic))
+	if session.SessionTopic != "" {
+		pipe.Del(ctx, walletConnectTopicKey(session.SessionTopic))
+	}
+	pipe.SRem(ctx, walletConnectUserKey(session.UserID), session.PairingTopic)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,317 @@
+package web3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+)
+
+type memoryWalletConnectStore struct {
+	mu       sync.Mutex
+	sessions map[string]WalletConnectSession
+}
+
+func newMemoryWalletConnectStore() *memoryWalletConnectStore {
+	return &memoryWalletConnectStore{sessions: make(map[string]WalletConnectSession)}
+}
+
+func (m *memoryWalletConnectStore) Save(ctx context.Context, session *WalletConnectSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.PairingTopic] = *session
+	return nil
+}
+
+func (m *memoryWalletConnectStore) Get(ctx context.Context, topic string) (*WalletConnectSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, session := range m.sessions {
+		if session.PairingTopic == topic || (session.SessionTopic != "" && session.SessionTopic == topic) {
+			return &session, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryWalletConnectStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*WalletConnectSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []*WalletConnectSession
+	for _, session := range m.sessions {
+		session := session
+		if session.UserID == userID {
+			sessions = append(sessions, &session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memoryWalletConnectStore) Delete(ctx context.Context, session *WalletConnectSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, session.PairingTopic)
+	return nil
+}
+
+type publishedMessage struct {
+	topic   string
+	message string
+	tag     int
+}
+
+type fakeRelay struct {
+	published []publishedMessage
+}
+
+func (f *fakeRelay) Publish(ctx context.Context, topic, message string, tag int, ttl time.Duration) error {
+	f.published = append(f.published, publishedMessage{topic: topic, message: message, tag: tag})
+	return nil
+}
+
+func (f *fakeRelay) last() publishedMessage {
+	return f.published[len(f.published)-1]
+}
+
+// fakeWalletApp plays the wallet side of a WalletConnect session
+type fakeWalletApp struct {
+	t            *testing.T
+	pairingTopic string
+	pairingKey   []byte
+	sessionKey   []byte
+	sessionTopic string
+}
+
+func (w *fakeWalletApp) decrypt(key []byte, envelope string) wcMessage {
+	w.t.Helper()
+	plaintext, err := wcDecrypt(key, envelope)
+	require.NoError(w.t, err)
+	var msg wcMessage
+	require.NoError(w.t, json.Unmarshal(plaintext, &msg))
+	return msg
+}
+
+func (w *fakeWalletApp) encrypt(key []byte, msg wcMessage) string {
+	w.t.Helper()
+	plaintext, err := json.Marshal(msg)
+	require.NoError(w.t, err)
+	envelope, err := wcEncrypt(key, plaintext)
+	require.NoError(w.t, err)
+	return envelope
+}
+
+// approve answers the session proposal and returns the response to deliver on the pairing topic
+func (w *fakeWalletApp) approve(proposal wcMessage) string {
+	w.t.Helper()
+	var params struct {
+		Proposer wcParticipant `json:"proposer"`
+	}
+	require.NoError(w.t, json.Unmarshal(proposal.Params, &params))
+	proposerKey, err := hex.DecodeString(params.Proposer.PublicKey)
+	require.NoError(w.t, err)
+
+	privateKey, err := randomBytes(curve25519.ScalarSize)
+	require.NoError(w.t, err)
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	require.NoError(w.t, err)
+	w.sessionKey, err = wcDeriveSymKey(privateKey, proposerKey)
+	require.NoError(w.t, err)
+	w.sessionTopic = wcTopic(w.sessionKey)
+
+	result, err := json.Marshal(map[string]interface{}{
+		"relay":              wcRelayProtocol{Protocol: "irn"},
+		"responderPublicKey": hex.EncodeToString(publicKey),
+	})
+	require.NoError(w.t, err)
+	return w.encrypt(w.pairingKey, wcMessage{ID: proposal.ID, JSONRPC: "2.0", Result: result})
+}
+
+func (w *fakeWalletApp) request(id int64, method string, params interface{}) string {
+	w.t.Helper()
+	encoded, err := json.Marshal(params)
+	require.NoError(w.t, err)
+	return w.encrypt(w.sessionKey, wcMessage{ID: id, JSONRPC: "2.0", Method: method, Params: encoded})
+}
+
+func TestWalletConnect_SessionAndSigning(t *testing.T) {
+	ctx := context.Background()
+	f := newSigningFixture(t)
+	relay := &fakeRelay{}
+	store := newMemoryWalletConnectStore()
+	manager := NewWalletConnectManager(f.service, store, relay, WalletConnectConfig{
+		Metadata: WalletConnectMetadata{Name: "AI Agentic Crypto Browser", URL: "https://app.example"},
+	})
+	manager.now = func() time.Time { return f.now }
+	f.queue.SetWalletConnect(manager)
+	userID := f.wallet.UserID
+
+	// The user scans the pairing URI, which carries the topic and key of the proposal
+	pairing, err := manager.CreatePairing(ctx, userID, []int{1})
+	require.NoError(t, err)
+	assert.Equal(t, WalletConnectProposed, pairing.Status)
+	assert.Empty(t, pairing.PrivateKey)
+	assert.Empty(t, pairing.PairingSymKey)
+
+	uri, err := url.Parse(pairing.PairingURI)
+	require.NoError(t, err)
+	assert.Equal(t, "wc", uri.Scheme)
+	assert.Equal(t, pairing.PairingTopic+"@2", uri.Opaque)
+	assert.Equal(t, "irn", uri.Query().Get("relay-protocol"))
+	wallet := &fakeWalletApp{t: t, pairingTopic: pairing.PairingTopic}
+	wallet.pairingKey, err = hex.DecodeString(uri.Query().Get("symKey"))
+	require.NoError(t, err)
+
+	require.Len(t, relay.published, 1)
+	assert.Equal(t, pairing.PairingTopic, relay.last().topic)
+	assert.Equal(t, wcTagSessionPropose, relay.last().tag)
+	proposal := wallet.decrypt(wallet.pairingKey, relay.last().message)
+	assert.Equal(t, "wc_sessionPropose", proposal.Method)
+
+	// The wallet approves and settles the session on the topic of the agreed key
+	session, err := manager.HandleMessage(ctx, userID, pairing.PairingTopic, wallet.approve(proposal))
+	require.NoError(t, err)
+	assert.Equal(t, wallet.sessionTopic, session.SessionTopic)
+
+	account := "eip155:1:" + f.wallet.Address
+	settle := wallet.request(1001, "wc_sessionSettle", map[string]interface{}{
+		"relay":      wcRelayProtocol{Protocol: "irn"},
+		"namespaces": map[string]wcNamespace{"eip155": {Accounts: []string{account, "eip155:137:" + f.wallet.Address}, Methods: wcSigningMethods}},
+		"controller": wcParticipant{PublicKey: "00", Metadata: WalletConnectMetadata{Name: "Test Wallet"}},
+		"expiry":     f.now.Add(7 * 24 * time.Hour).Unix(),
+	})
+	session, err = manager.HandleMessage(ctx, userID, wallet.sessionTopic, settle)
+	require.NoError(t, err)
+	assert.Equal(t, WalletConnectActive, session.Status)
+	assert.Equal(t, []string{strings.ToLower(account)}, session.Accounts, "accounts on unrequested chains are ignored")
+	assert.Equal(t, "Test Wallet", session.Peer.Name)
+	assert.Empty(t, session.PairingURI)
+	assert.Empty(t, session.SessionSymKey)
+	assert.Equal(t, wcTagSessionSettleResponse, relay.last().tag)
+	assert.Equal(t, int64(1001), wallet.decrypt(wallet.sessionKey, relay.last().message).ID)
+
+	sessions, err := manager.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Empty(t, sessions[0].SessionSymKey)
+
+	// Transactions of the session's wallet are pushed to the wallet app
+	request := f.createTransaction(t).SigningRequest
+	assert.Equal(t, SigningChannelWalletConnect, request.Channel)
+	assert.Equal(t, wallet.sessionTopic, request.WalletConnectTopic)
+	pushed := relay.last()
+	assert.Equal(t, wcTagSessionRequest, pushed.tag)
+	sessionRequest := wallet.decrypt(wallet.sessionKey, pushed.message)
+	assert.Equal(t, "wc_sessionRequest", sessionRequest.Method)
+	assert.Equal(t, request.WalletConnectRequestID, sessionRequest.ID)
+	var params struct {
+		Request struct {
+			Method string              `json:"method"`
+			Params []map[string]string `json:"params"`
+		} `json:"request"`
+		ChainID string `json:"chainId"`
+	}
+	require.NoError(t, json.Unmarshal(sessionRequest.Params, &params))
+	assert.Equal(t, "eth_signTransaction", params.Request.Method)
+	assert.Equal(t, "eip155:1", params.ChainID)
+	assert.Equal(t, "0x7", params.Request.Params[0]["nonce"])
+	assert.Equal(t, "0x38d7ea4c68000", params.Request.Params[0]["value"])
+
+	// Its signature is broadcast like one posted to the queue
+	signed, err := json.Marshal(f.sign(t, f.key, request, nil))
+	require.NoError(t, err)
+	response := wallet.encrypt(wallet.sessionKey, wcMessage{ID: sessionRequest.ID, JSONRPC: "2.0", Result: signed})
+	_, err = manager.HandleMessage(ctx, userID, wallet.sessionTopic, response)
+	require.NoError(t, err)
+	stored, err := f.queue.Get(ctx, userID, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SigningRequestBroadcast, stored.Status)
+	require.Len(t, f.chain.broadcast, 1)
+
+	// A request the user rejects in the wallet app fails its transaction
+	request = f.createTransaction(t).SigningRequest
+	rejection := wallet.encrypt(wallet.sessionKey, wcMessage{ID: request.WalletConnectRequestID, JSONRPC: "2.0", Error: &wcError{Code: 5000, Message: "User rejected."}})
+	_, err = manager.HandleMessage(ctx, userID, wallet.sessionTopic, rejection)
+	require.NoError(t, err)
+	stored, err = f.queue.Get(ctx, userID, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SigningRequestRejected, stored.Status)
+	assert.Equal(t, TxStatusFailed, f.txRepo.statuses[request.TransactionID])
+
+	// Other users can't use the session
+	_, err = manager.HandleMessage(ctx, uuid.New(), wallet.sessionTopic, response)
+	assert.ErrorIs(t, err, ErrWalletConnectSessionNotFound)
+
+	// The wallet app ending the session sends requests back to the queue
+	session, err = manager.HandleMessage(ctx, userID, wallet.sessionTopic, wallet.request(1002, "wc_sessionDelete", map[string]interface{}{"code": 6000, "message": "User disconnected."}))
+	require.NoError(t, err)
+	assert.Nil(t, session)
+	assert.Equal(t, wcTagSessionDeleteResponse, relay.last().tag)
+	request = f.createTransaction(t).SigningRequest
+	assert.Equal(t, SigningChannelQueue, request.Channel)
+}
+
+func TestWalletConnect_RejectedProposalAndDisconnect(t *testing.T) {
+	ctx := context.Background()
+	f := newSigningFixture(t)
+	relay := &fakeRelay{}
+	store := newMemoryWalletConnectStore()
+	manager := NewWalletConnectManager(f.service, store, relay, WalletConnectConfig{})
+	userID := f.wallet.UserID
+
+	_, err := manager.CreatePairing(ctx, userID, []int{424242})
+	assert.ErrorIs(t, err, ErrWalletConnectChain)
+
+	pairing, err := manager.CreatePairing(ctx, userID, []int{1})
+	require.NoError(t, err)
+	uri, err := url.Parse(pairing.PairingURI)
+	require.NoError(t, err)
+	wallet := &fakeWalletApp{t: t, pairingTopic: pairing.PairingTopic}
+	wallet.pairingKey, err = hex.DecodeString(uri.Query().Get("symKey"))
+	require.NoError(t, err)
+	proposal := wallet.decrypt(wallet.pairingKey, relay.last().message)
+
+	_, err = manager.HandleMessage(ctx, userID, pairing.PairingTopic, "AAAA")
+	assert.ErrorIs(t, err, ErrInvalidWalletConnectMessage)
+
+	declined := wallet.encrypt(wallet.pairingKey, wcMessage{ID: proposal.ID, JSONRPC: "2.0", Error: &wcError{Code: 5000, Message: "User rejected."}})
+	session, err := manager.HandleMessage(ctx, userID, pairing.PairingTopic, declined)
+	require.NoError(t, err)
+	assert.Nil(t, session)
+	sessions, err := manager.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	pairing, err = manager.CreatePairing(ctx, userID, []int{1})
+	require.NoError(t, err)
+	assert.ErrorIs(t, manager.Disconnect(ctx, uuid.New(), pairing.PairingTopic), ErrWalletConnectSessionNotFound)
+	require.NoError(t, manager.Disconnect(ctx, userID, pairing.PairingTopic))
+	sessions, err = manager.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestWalletConnectRelay_AuthToken(t *testing.T) {
+	relay, err := NewHTTPWalletConnectRelay("", "project")
+	require.NoError(t, err)
+	token, err := relay.(*httpWalletConnectRelay).authToken(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(claims, &decoded))
+	assert.True(t, strings.HasPrefix(decoded["iss"].(string), "did:key:z6Mk"), "ed25519 did:key identifiers start with z6Mk")
+	assert.Equal(t, "https://relay.walletconnect.org", decoded["aud"])
+}
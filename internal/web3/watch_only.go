@@ -0,0 +1,267 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// WalletTypeWatchOnly is the wallet type of watch-only wallets connected without one
+	WalletTypeWatchOnly = "watch_only"
+	// ErrCodeWatchOnlyWallet is the API error code of signing requests on watch-only wallets
+	ErrCodeWatchOnlyWallet = "WATCH_ONLY_WALLET"
+	// nativeAsset is the balance key of the chain's native coin in watch snapshots
+	nativeAsset = "native"
+)
+
+// ErrWatchOnlyWallet is returned when a watch-only wallet is asked to sign
+var ErrWatchOnlyWallet = errors.New("watch-only wallet cannot sign transactions")
+
+// WatchSnapshot is the on-chain state of a watch-only wallet as of its last poll
+type WatchSnapshot struct {
+	WalletID  uuid.UUID         `json:"wallet_id"`
+	Nonce     uint64            `json:"nonce"`
+	Balances  map[string]string `json:"balances"` // raw amounts by asset symbol
+	CheckedAt time.Time         `json:"checked_at"`
+}
+
+// WalletWatchConfig configures watch-only wallet polling
+type WalletWatchConfig struct {
+	PollInterval    time.Duration
+	ChangeThreshold float64 // alert when a balance changes by more than this share of it
+}
+
+// walletStateReader reads the on-chain state of an address
+type walletStateReader interface {
+	NativeBalance(ctx context.Context, chainID int, address string) (*big.Int, error)
+	TokenBalance(ctx context.Context, chainID int, token, address string) (*big.Int, error)
+	Nonce(ctx context.Context, chainID int, address string) (uint64, error)
+}
+
+// rpcWalletState reads wallet state through the service's chain providers
+type rpcWalletState struct {
+	service *Service
+}
+
+func (r rpcWalletState) NativeBalance(ctx context.Context, chainID int, address string) (*big.Int, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return client.BalanceAt(ctx, common.HexToAddress(address), nil)
+}
+
+func (r rpcWalletState) TokenBalance(ctx context.Context, chainID int, token, address string) (*big.Int, error) {
+	return r.service.getERC20Balance(ctx, chainID, token, address)
+}
+
+func (r rpcWalletState) Nonce(ctx context.Context, chainID int, address string) (uint64, error) {
+	client, err := r.service.getEthClient(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
+	return client.NonceAt(ctx, common.HexToAddress(address), nil)
+}
+
+// WalletWatcher polls the native and major ERC-20 balances of watch-only wallets and alerts
+// their owners when a balance moves by more than the threshold or the wallet sends a
+// transaction. The first poll of a wallet only records its state.
+type WalletWatcher struct {
+	repo   WatchOnlyRepository
+	state  walletStateReader
+	alerts *alerts.AlertService
+	logger *observability.Logger
+	config WalletWatchConfig
+}
+
+// NewWalletWatcher creates a watcher reading chain state through the service's providers
+func NewWalletWatcher(service *Service, repo WatchOnlyRepository, alertService *alerts.AlertService, cfg WalletWatchConfig) *WalletWatcher {
+	return &WalletWatcher{
+		repo:   repo,
+		state:  rpcWalletState{service: service},
+		alerts: alertService,
+		logger: service.logger,
+		config: cfg,
+	}
+}
+
+// Start polls watch-only wallets on the configured interval until ctx is done
+func (w *WalletWatcher) Start(ctx context.Context) {
+	interval := w.config.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	go func() {
+		w.Poll(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.Poll(ctx)
+			}
+		}
+	}()
+
+	w.logger.Info(ctx, "Watch-only wallet poller started", map[string]interface{}{
+		"interval": interval.String(),
+	})
+}
+
+// Poll checks every watch-only wallet once and returns the number of alerts sent
+func (w *WalletWatcher) Poll(ctx context.Context) int {
+	wallets, err := w.repo.ListWatchOnly(ctx)
+	if err != nil {
+		w.logger.Error(ctx, "Failed to list watch-only wallets", err)
+		return 0
+	}
+
+	sent := 0
+	for _, wallet := range wallets {
+		n, err := w.checkWallet(ctx, wallet)
+		if err != nil {
+			w.logger.Warn(ctx, "Failed to poll watch-only wallet", map[string]interface{}{
+				"wallet_id": wallet.ID.String(),
+				"chain_id":  wallet.ChainID,
+				"error":     err.Error(),
+			})
+		}
+		sent += n
+	}
+	return sent
+}
+
+// checkWallet reads a wallet's current state, alerts on changes since the last snapshot and
+// stores the new one. Token balances that can't be read keep their previous value.
+func (w *WalletWatcher) checkWallet(ctx context.Context, wallet *Wallet) (int, error) {
+	previous, err := w.repo.GetSnapshot(ctx, wallet.ID)
+	if err != nil {
+		return 0, fmt.Errorf("load snapshot: %w", err)
+	}
+
+	nonce, err := w.state.Nonce(ctx, wallet.ChainID, wallet.Address)
+	if err != nil {
+		return 0, fmt.Errorf("read nonce: %w", err)
+	}
+	native, err := w.state.NativeBalance(ctx, wallet.ChainID, wallet.Address)
+	if err != nil {
+		return 0, fmt.Errorf("read native balance: %w", err)
+	}
+
+	current := &WatchSnapshot{
+		WalletID:  wallet.ID,
+		Nonce:     nonce,
+		Balances:  map[string]string{nativeAsset: native.String()},
+		CheckedAt: time.Now(),
+	}
+	for _, token := range CommonERC20Tokens[wallet.ChainID] {
+		balance, err := w.state.TokenBalance(ctx, wallet.ChainID, token.Address, wallet.Address)
+		if err != nil {
+			if previous != nil && previous.Balances[token.Symbol] != "" {
+				current.Balances[token.Symbol] = previous.Balances[token.Symbol]
+			}
+			continue
+		}
+		current.Balances[token.Symbol] = balance.String()
+	}
+
+	sent := 0
+	if previous != nil {
+		if current.Nonce > previous.Nonce {
+			w.alertOutgoing(wallet, previous.Nonce, current.Nonce)
+			sent++
+		}
+		for asset, raw := range current.Balances {
+			before, ok := previous.Balances[asset]
+			if !ok {
+				continue
+			}
+			if w.alertBalanceChange(wallet, asset, before, raw) {
+				sent++
+			}
+		}
+	}
+
+	if err := w.repo.SaveSnapshot(ctx, current); err != nil {
+		return sent, fmt.Errorf("save snapshot: %w", err)
+	}
+	return sent, nil
+}
+
+// alertOutgoing reports transactions sent from a watch-only wallet, detected by its nonce
+func (w *WalletWatcher) alertOutgoing(wallet *Wallet, before, after uint64) {
+	count := after - before
+	message := fmt.Sprintf("%d outgoing transaction(s) sent from watch-only wallet %s on %s",
+		count, wallet.Address, ChainName(wallet.ChainID))
+
+	// Each new nonce gets its own rule so repeated outgoing alerts are never in cooldown
+	alert := w.alerts.CreateAlert(fmt.Sprintf("watch_only_tx:%s:%d", wallet.ID, after), "Watch-only wallet outgoing transaction",
+		message, alerts.SeverityCritical, "watch_only_outgoing_tx", decimal.NewFromInt(int64(count)), decimal.Zero, nil)
+	alert.UserID = &wallet.UserID
+	alert.Category = alerts.CategoryRiskAlerts
+	alert.Metadata["wallet_id"] = wallet.ID.String()
+	alert.Metadata["address"] = wallet.Address
+	alert.Metadata["chain_id"] = wallet.ChainID
+	alert.Metadata["nonce"] = after
+	w.alerts.SendAlert(alert)
+}
+
+// alertBalanceChange alerts when an asset balance moved by more than the threshold and reports
+// whether it did. Any movement away from zero counts.
+func (w *WalletWatcher) alertBalanceChange(wallet *Wallet, asset, before, after string) bool {
+	previous, err := decimal.NewFromString(before)
+	if err != nil {
+		return false
+	}
+	current, err := decimal.NewFromString(after)
+	if err != nil || current.Equal(previous) {
+		return false
+	}
+
+	threshold := decimal.NewFromFloat(w.config.ChangeThreshold)
+	change := decimal.NewFromInt(1)
+	if !previous.IsZero() {
+		change = current.Sub(previous).Abs().Div(previous)
+		if change.LessThanOrEqual(threshold) {
+			return false
+		}
+	}
+
+	symbol := asset
+	if asset == nativeAsset {
+		symbol = "native balance"
+	}
+	direction, severity := "increased", alerts.SeverityInfo
+	if current.LessThan(previous) {
+		direction, severity = "decreased", alerts.SeverityWarning
+	}
+	message := fmt.Sprintf("Watch-only wallet %s %s %s by %s%% on %s", wallet.Address, symbol, direction,
+		change.Mul(decimal.NewFromInt(100)).StringFixed(2), ChainName(wallet.ChainID))
+
+	alert := w.alerts.CreateAlert(fmt.Sprintf("watch_only_balance:%s:%s", wallet.ID, asset), "Watch-only wallet balance change",
+		message, severity, "watch_only_balance_change", change, threshold, nil)
+	alert.UserID = &wallet.UserID
+	alert.Category = alerts.CategoryRiskAlerts
+	alert.Metadata["wallet_id"] = wallet.ID.String()
+	alert.Metadata["address"] = wallet.Address
+	alert.Metadata["chain_id"] = wallet.ChainID
+	alert.Metadata["asset"] = asset
+	alert.Metadata["previous_balance"] = before
+	alert.Metadata["current_balance"] = after
+	w.alerts.SendAlert(alert)
+	return true
+}
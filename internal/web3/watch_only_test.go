@@ -0,0 +1,119 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/alerts"
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWatchOnlyRepo struct {
+	wallets   []*Wallet
+	snapshots map[uuid.UUID]*WatchSnapshot
+}
+
+func (m *mockWatchOnlyRepo) ListWatchOnly(ctx context.Context) ([]*Wallet, error) {
+	return m.wallets, nil
+}
+
+func (m *mockWatchOnlyRepo) GetSnapshot(ctx context.Context, walletID uuid.UUID) (*WatchSnapshot, error) {
+	return m.snapshots[walletID], nil
+}
+
+func (m *mockWatchOnlyRepo) SaveSnapshot(ctx context.Context, snapshot *WatchSnapshot) error {
+	m.snapshots[snapshot.WalletID] = snapshot
+	return nil
+}
+
+// mockWalletState serves balances by asset symbol, "native" for the native coin
+type mockWalletState struct {
+	nonce    uint64
+	balances map[string]int64
+}
+
+func (m *mockWalletState) NativeBalance(ctx context.Context, chainID int, address string) (*big.Int, error) {
+	return big.NewInt(m.balances[nativeAsset]), nil
+}
+
+func (m *mockWalletState) TokenBalance(ctx context.Context, chainID int, token, address string) (*big.Int, error) {
+	for _, meta := range CommonERC20Tokens[chainID] {
+		if meta.Address == token {
+			if balance, ok := m.balances[meta.Symbol]; ok {
+				return big.NewInt(balance), nil
+			}
+		}
+	}
+	return nil, errors.New("rpc unavailable")
+}
+
+func (m *mockWalletState) Nonce(ctx context.Context, chainID int, address string) (uint64, error) {
+	return m.nonce, nil
+}
+
+func TestWalletWatcher_Poll(t *testing.T) {
+	ctx := context.Background()
+	s := newServiceWithMocks()
+	alertService := alerts.NewAlertService(s.logger, alerts.NewAlertConfig(config.AlertsConfig{}))
+	wallet := &Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0x00000000219ab540356cbb839cbe05303d7705fa", ChainID: 1, WatchOnly: true}
+	repo := &mockWatchOnlyRepo{wallets: []*Wallet{wallet}, snapshots: make(map[uuid.UUID]*WatchSnapshot)}
+	state := &mockWalletState{nonce: 4, balances: map[string]int64{nativeAsset: 1000, "USDC": 500, "USDT": 0}}
+
+	watcher := NewWalletWatcher(s, repo, alertService, WalletWatchConfig{PollInterval: time.Minute, ChangeThreshold: 0.05})
+	watcher.state = state
+
+	// The first poll records a baseline without alerting
+	assert.Equal(t, 0, watcher.Poll(ctx))
+	require.NotNil(t, repo.snapshots[wallet.ID])
+	assert.Equal(t, "1000", repo.snapshots[wallet.ID].Balances[nativeAsset])
+
+	// Changes within the threshold stay quiet; WETH can't be read and keeps no value
+	state.balances[nativeAsset] = 980
+	assert.Equal(t, 0, watcher.Poll(ctx))
+
+	// USDC drops by 40%, USDT moves off zero and the nonce reveals an outgoing transaction
+	state.nonce = 5
+	state.balances["USDC"] = 300
+	state.balances["USDT"] = 10
+	assert.Equal(t, 3, watcher.Poll(ctx))
+
+	sent := alertService.GetAlerts(10)
+	require.Len(t, sent, 3)
+	bySeverity := make(map[alerts.AlertSeverity]int)
+	for _, alert := range sent {
+		require.NotNil(t, alert.UserID)
+		assert.Equal(t, wallet.UserID, *alert.UserID)
+		bySeverity[alert.Severity]++
+	}
+	assert.Equal(t, 1, bySeverity[alerts.SeverityCritical], "outgoing transaction")
+	assert.Equal(t, 1, bySeverity[alerts.SeverityWarning], "USDC decrease")
+	assert.Equal(t, 1, bySeverity[alerts.SeverityInfo], "USDT increase")
+}
+
+func TestWatchOnlyWallet_RejectsSigning(t *testing.T) {
+	ctx := context.Background()
+	s := newServiceWithMocks()
+	userID := uuid.New()
+
+	resp, err := s.ConnectWallet(ctx, userID, WalletConnectRequest{Address: "0x00000000219ab540356cbb839cbe05303d7705fa", ChainID: 1, WatchOnly: true})
+	require.NoError(t, err)
+	assert.True(t, resp.Wallet.WatchOnly)
+	assert.False(t, resp.Wallet.IsPrimary)
+	assert.Equal(t, WalletTypeWatchOnly, resp.Wallet.WalletType)
+
+	_, err = s.ConnectWallet(ctx, userID, WalletConnectRequest{Address: "cold-storage", ChainID: 1, WatchOnly: true})
+	assert.Error(t, err)
+
+	s.walletRepo.(*mockWalletRepo).getByID = map[uuid.UUID]*Wallet{resp.Wallet.ID: resp.Wallet}
+	_, err = s.CreateTransaction(ctx, userID, TransactionRequest{WalletID: resp.Wallet.ID, ToAddress: "0x000000000000000000000000000000000000dEaD", Value: big.NewInt(1)})
+	assert.ErrorIs(t, err, ErrWatchOnlyWallet)
+
+	_, err = s.InteractWithDeFiProtocol(ctx, userID, DeFiProtocolRequest{WalletID: resp.Wallet.ID, Protocol: "aave", Action: "deposit"})
+	assert.ErrorIs(t, err, ErrWatchOnlyWallet)
+}
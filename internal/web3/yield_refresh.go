@@ -0,0 +1,189 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Sources of yield opportunity data
+const (
+	YieldSourceLive   = "live"
+	YieldSourceStatic = "static"
+)
+
+// YieldSource fetches the live market data of one DeFi protocol
+type YieldSource interface {
+	// ProtocolID is the ID of the protocol the source refreshes, e.g. "aave"
+	ProtocolID() string
+	FetchYields(ctx context.Context) (*ProtocolYields, error)
+}
+
+// ProtocolYields is the market data of a protocol as fetched from its source
+type ProtocolYields struct {
+	TVL   decimal.Decimal
+	Pools []*LiquidityPool
+}
+
+// YieldSourceStats tracks the fetches of a protocol's yield source
+type YieldSourceStats struct {
+	ProtocolID   string     `json:"protocol_id"`
+	Fetches      int64      `json:"fetches"`
+	Failures     int64      `json:"failures"`
+	Pools        int        `json:"pools"`
+	LastAttempt  *time.Time `json:"last_attempt,omitempty"`
+	LastSuccess  *time.Time `json:"last_success,omitempty"`
+	LastError    string     `json:"last_error,omitempty"` // error of the latest fetch, empty after a success
+	LastDuration float64    `json:"last_duration_seconds"`
+}
+
+// SetYieldSources registers live sources for protocols. Pools of those protocols are only
+// offered as opportunities after a successful fetch, and are withheld while fetches fail.
+func (d *DeFiProtocolManager) SetYieldSources(sources ...YieldSource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, source := range sources {
+		id := source.ProtocolID()
+		d.sources[id] = source
+		if _, exists := d.sourceStats[id]; !exists {
+			d.sourceStats[id] = &YieldSourceStats{ProtocolID: id}
+		}
+	}
+}
+
+// YieldSourceStats returns the fetch statistics of every registered yield source
+func (d *DeFiProtocolManager) YieldSourceStats() map[string]YieldSourceStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := make(map[string]YieldSourceStats, len(d.sourceStats))
+	for id, sourceStats := range d.sourceStats {
+		stats[id] = *sourceStats
+	}
+	return stats
+}
+
+// StartYieldRefresher refreshes the registered yield sources immediately and then every
+// interval until ctx is done
+func (d *DeFiProtocolManager) StartYieldRefresher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	go func() {
+		d.refreshAndLog(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.refreshAndLog(ctx)
+			}
+		}
+	}()
+
+	d.logger.Info(ctx, "DeFi yield refresher started", map[string]interface{}{
+		"interval": interval.String(),
+	})
+}
+
+func (d *DeFiProtocolManager) refreshAndLog(ctx context.Context) {
+	if err := d.RefreshYields(ctx); err != nil && ctx.Err() == nil {
+		d.logger.Warn(ctx, "DeFi yield refresh incomplete", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// RefreshYields fetches every registered source concurrently and replaces the pools of the
+// protocols fetched successfully. It returns the errors of the sources that failed.
+func (d *DeFiProtocolManager) RefreshYields(ctx context.Context) error {
+	d.mu.RLock()
+	sources := make([]YieldSource, 0, len(d.sources))
+	for _, source := range d.sources {
+		sources = append(sources, source)
+	}
+	d.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(sources))
+	)
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source YieldSource) {
+			defer wg.Done()
+			errs[i] = d.refreshSource(ctx, source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// refreshSource fetches one source and applies the result
+func (d *DeFiProtocolManager) refreshSource(ctx context.Context, source YieldSource) error {
+	id := source.ProtocolID()
+	started := time.Now()
+	yields, err := source.FetchYields(ctx)
+	if err == nil && len(yields.Pools) == 0 {
+		err = errors.New("no pools returned")
+	}
+	finished := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.sourceStats[id]
+	stats.Fetches++
+	stats.LastAttempt = &finished
+	stats.LastDuration = finished.Sub(started).Seconds()
+	if err != nil {
+		stats.Failures++
+		stats.LastError = err.Error()
+		return fmt.Errorf("%s: %w", id, err)
+	}
+
+	protocol, exists := d.protocols[id]
+	if !exists {
+		err = fmt.Errorf("protocol not found: %s", id)
+		stats.Failures++
+		stats.LastError = err.Error()
+		return err
+	}
+
+	// Replace the protocol rather than mutating it, readers may still hold the old one
+	updated := *protocol
+	updated.Pools = make(map[string]*LiquidityPool, len(yields.Pools))
+	weightedAPY, totalLiquidity := decimal.Zero, decimal.Zero
+	for _, pool := range yields.Pools {
+		pool.ProtocolID = id
+		pool.LastUpdated = finished
+		updated.Pools[pool.ID] = pool
+		weightedAPY = weightedAPY.Add(pool.APY.Mul(pool.TotalLiquidity))
+		totalLiquidity = totalLiquidity.Add(pool.TotalLiquidity)
+	}
+	if totalLiquidity.IsPositive() {
+		updated.APY = weightedAPY.Div(totalLiquidity).Round(6)
+	}
+	updated.TVL = yields.TVL
+	if !updated.TVL.IsPositive() {
+		updated.TVL = totalLiquidity
+	}
+	updated.LastUpdated = finished
+	d.protocols[id] = &updated
+
+	stats.Pools = len(updated.Pools)
+	stats.LastSuccess = &finished
+	stats.LastError = ""
+	return nil
+}
@@ -0,0 +1,190 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeYieldSource struct {
+	protocolID string
+	yields     *ProtocolYields
+	err        error
+}
+
+func (s *fakeYieldSource) ProtocolID() string {
+	return s.protocolID
+}
+
+func (s *fakeYieldSource) FetchYields(ctx context.Context) (*ProtocolYields, error) {
+	return s.yields, s.err
+}
+
+func opportunityPools(opportunities []*YieldOpportunity) map[string]*YieldOpportunity {
+	pools := make(map[string]*YieldOpportunity)
+	for _, opportunity := range opportunities {
+		pools[opportunity.PoolID] = opportunity
+	}
+	return pools
+}
+
+func TestYieldRefresh(t *testing.T) {
+	ctx := context.Background()
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	query := YieldOpportunityQuery{MinAPY: decimal.Zero, MaxRisk: RiskLevelHigh}
+
+	aave := &fakeYieldSource{protocolID: "aave", yields: &ProtocolYields{Pools: []*LiquidityPool{
+		{ID: "aave_usdc", Name: "USDC", TokenA: "USDC", TotalLiquidity: decimal.NewFromInt(3000), APY: decimal.NewFromFloat(0.04), RiskLevel: RiskLevelLow, IsActive: true},
+		{ID: "aave_weth", Name: "WETH", TokenA: "WETH", TotalLiquidity: decimal.NewFromInt(1000), APY: decimal.NewFromFloat(0.08), RiskLevel: RiskLevelLow, IsActive: true},
+	}}}
+	compound := &fakeYieldSource{protocolID: "compound", err: errors.New("subgraph error: status 502")}
+
+	manager := NewDeFiProtocolManager(logger)
+	manager.SetYieldSources(aave, compound)
+
+	// Live protocols are withheld until their first successful fetch
+	opportunities, err := manager.GetYieldOpportunities(ctx, query)
+	require.NoError(t, err)
+	pools := opportunityPools(opportunities)
+	assert.NotContains(t, pools, "aave_eth")
+	assert.NotContains(t, pools, "compound_usdc")
+	require.Contains(t, pools, "uniswap_v3_usdc_eth")
+	assert.Equal(t, YieldSourceStatic, pools["uniswap_v3_usdc_eth"].Source)
+
+	err = manager.RefreshYields(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compound")
+
+	aaveProtocol, err := manager.GetProtocol("aave")
+	require.NoError(t, err)
+	assert.Len(t, aaveProtocol.Pools, 2)
+	assert.True(t, aaveProtocol.APY.Equal(decimal.NewFromFloat(0.05)), "TVL-weighted APY, got %s", aaveProtocol.APY)
+	assert.True(t, aaveProtocol.TVL.Equal(decimal.NewFromInt(4000)))
+
+	opportunities, err = manager.GetYieldOpportunities(ctx, query)
+	require.NoError(t, err)
+	pools = opportunityPools(opportunities)
+	require.Contains(t, pools, "aave_usdc")
+	assert.Equal(t, YieldSourceLive, pools["aave_usdc"].Source)
+	assert.WithinDuration(t, time.Now(), pools["aave_usdc"].UpdatedAt, time.Minute)
+	assert.NotContains(t, pools, "aave_eth", "fetched pools replace the built-in ones")
+	assert.NotContains(t, pools, "compound_usdc", "failed sources are excluded")
+
+	stats := manager.YieldSourceStats()
+	assert.Equal(t, int64(1), stats["aave"].Fetches)
+	assert.Equal(t, 2, stats["aave"].Pools)
+	assert.Empty(t, stats["aave"].LastError)
+	assert.Equal(t, int64(1), stats["compound"].Failures)
+	assert.Nil(t, stats["compound"].LastSuccess)
+
+	// A failed refresh withholds the protocol's previous data
+	aave.err = errors.New("timeout")
+	require.Error(t, manager.RefreshYields(ctx))
+	opportunities, err = manager.GetYieldOpportunities(ctx, query)
+	require.NoError(t, err)
+	assert.NotContains(t, opportunityPools(opportunities), "aave_usdc")
+	assert.Equal(t, "timeout", manager.YieldSourceStats()["aave"].LastError)
+
+	aave.err = nil
+	compound.err, compound.yields = nil, &ProtocolYields{Pools: []*LiquidityPool{
+		{ID: "compound_dai", Name: "DAI", TokenA: "DAI", TotalLiquidity: decimal.NewFromInt(500), APY: decimal.NewFromFloat(0.03), RiskLevel: RiskLevelLow, IsActive: true},
+	}}
+	require.NoError(t, manager.RefreshYields(ctx))
+
+	// max_age drops pools whose data is older than the limit
+	manager.mu.Lock()
+	manager.protocols["compound"].Pools["compound_dai"].LastUpdated = time.Now().Add(-time.Hour)
+	manager.mu.Unlock()
+	query.MaxAge = 10 * time.Minute
+	opportunities, err = manager.GetYieldOpportunities(ctx, query)
+	require.NoError(t, err)
+	pools = opportunityPools(opportunities)
+	assert.Contains(t, pools, "aave_usdc")
+	assert.NotContains(t, pools, "compound_dai")
+}
+
+func TestSubgraphYieldSources(t *testing.T) {
+	ctx := context.Background()
+	yesterday := time.Now().UTC().Truncate(24 * time.Hour).Add(-24 * time.Hour).Unix()
+	today := time.Now().UTC().Truncate(24 * time.Hour).Unix()
+
+	t.Run("UniswapV3", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Variables map[string]interface{} `json:"variables"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, float64(5), req.Variables["first"])
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"pools": []map[string]interface{}{{
+				"id":                     "0x88E6",
+				"feeTier":                "500",
+				"totalValueLockedUSD":    "3650000",
+				"totalValueLockedToken0": "1825000",
+				"totalValueLockedToken1": "600",
+				"token0":                 map[string]string{"symbol": "USDC"},
+				"token1":                 map[string]string{"symbol": "WETH"},
+				"poolDayData": []map[string]interface{}{
+					{"date": today, "volumeUSD": "10", "feesUSD": "1"},
+					{"date": yesterday, "volumeUSD": "2000000", "feesUSD": "1000"},
+				},
+			}}}})
+		}))
+		defer server.Close()
+
+		yields, err := NewUniswapV3Source(server.URL, 5).FetchYields(ctx)
+		require.NoError(t, err)
+		require.Len(t, yields.Pools, 1)
+		pool := yields.Pools[0]
+		assert.Equal(t, "uniswap_v3_0x88e6", pool.ID)
+		assert.Equal(t, "USDC/WETH 0.05%", pool.Name)
+		assert.True(t, pool.APY.Equal(decimal.NewFromFloat(0.1)), "got %s", pool.APY)
+		assert.True(t, pool.Fees24h.Equal(decimal.NewFromInt(1000)))
+		assert.True(t, yields.TVL.Equal(decimal.NewFromInt(3650000)))
+	})
+
+	t.Run("Lending", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"markets": []map[string]interface{}{{
+				"id":                  "0xABC",
+				"name":                "Aave Ethereum USDC",
+				"totalValueLockedUSD": "2500000",
+				"inputTokenBalance":   "2500000000000",
+				"inputToken":          map[string]interface{}{"symbol": "USDC", "decimals": 6},
+				"rates": []map[string]string{
+					{"rate": "5.1", "side": "BORROWER", "type": "VARIABLE"},
+					{"rate": "3.2", "side": "LENDER", "type": "VARIABLE"},
+				},
+			}}}})
+		}))
+		defer server.Close()
+
+		yields, err := NewLendingSubgraphSource("aave", server.URL, 0).FetchYields(ctx)
+		require.NoError(t, err)
+		require.Len(t, yields.Pools, 1)
+		pool := yields.Pools[0]
+		assert.Equal(t, "aave_0xabc", pool.ID)
+		assert.True(t, pool.APY.Equal(decimal.NewFromFloat(0.032)))
+		assert.True(t, pool.ReserveA.Equal(decimal.NewFromInt(2500000)))
+	})
+
+	t.Run("GraphQLErrors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "indexer unavailable"}}})
+		}))
+		defer server.Close()
+
+		_, err := NewLendingSubgraphSource("compound", server.URL, 0).FetchYields(ctx)
+		assert.ErrorContains(t, err, "indexer unavailable")
+	})
+}
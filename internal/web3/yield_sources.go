@@ -0,0 +1,265 @@
+package web3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// defaultSubgraphPools is how many of the largest pools a source fetches by default
+	defaultSubgraphPools = 20
+	// minUniswapPoolTVL skips dust pools whose fee APY is meaningless
+	minUniswapPoolTVL = "1000000"
+)
+
+// subgraphClient runs GraphQL queries against a subgraph endpoint
+type subgraphClient struct {
+	httpClient *http.Client
+	url        string
+}
+
+func newSubgraphClient(url string) *subgraphClient {
+	return &subgraphClient{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		url:        url,
+	}
+}
+
+// query runs a GraphQL query and decodes its data into out
+func (c *subgraphClient) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("subgraph error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid subgraph response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, graphErr := range result.Errors {
+			messages[i] = graphErr.Message
+		}
+		return fmt.Errorf("subgraph error: %s", strings.Join(messages, "; "))
+	}
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		return errors.New("subgraph returned no data")
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// UniswapV3Source fetches the largest Uniswap V3 pools from the Uniswap V3 subgraph. Pool APY
+// is the fee income of the last complete days, annualised against current liquidity.
+type UniswapV3Source struct {
+	client *subgraphClient
+	pools  int
+}
+
+// NewUniswapV3Source creates a source for the "uniswap_v3" protocol
+func NewUniswapV3Source(subgraphURL string, pools int) *UniswapV3Source {
+	if pools <= 0 {
+		pools = defaultSubgraphPools
+	}
+	return &UniswapV3Source{client: newSubgraphClient(subgraphURL), pools: pools}
+}
+
+const uniswapV3PoolsQuery = `query($first: Int!, $minTVL: BigDecimal!) {
+  pools(first: $first, orderBy: totalValueLockedUSD, orderDirection: desc, where: {totalValueLockedUSD_gt: $minTVL}) {
+    id
+    feeTier
+    totalValueLockedUSD
+    totalValueLockedToken0
+    totalValueLockedToken1
+    token0 { symbol }
+    token1 { symbol }
+    poolDayData(first: 8, orderBy: date, orderDirection: desc) { date volumeUSD feesUSD }
+  }
+}`
+
+func (s *UniswapV3Source) ProtocolID() string {
+	return "uniswap_v3"
+}
+
+func (s *UniswapV3Source) FetchYields(ctx context.Context) (*ProtocolYields, error) {
+	var data struct {
+		Pools []struct {
+			ID                     string                  `json:"id"`
+			FeeTier                string                  `json:"feeTier"`
+			TotalValueLockedUSD    decimal.Decimal         `json:"totalValueLockedUSD"`
+			TotalValueLockedToken0 decimal.Decimal         `json:"totalValueLockedToken0"`
+			TotalValueLockedToken1 decimal.Decimal         `json:"totalValueLockedToken1"`
+			Token0                 struct{ Symbol string } `json:"token0"`
+			Token1                 struct{ Symbol string } `json:"token1"`
+			PoolDayData            []struct {
+				Date      int64           `json:"date"`
+				VolumeUSD decimal.Decimal `json:"volumeUSD"`
+				FeesUSD   decimal.Decimal `json:"feesUSD"`
+			} `json:"poolDayData"`
+		} `json:"pools"`
+	}
+	err := s.client.query(ctx, uniswapV3PoolsQuery, map[string]interface{}{
+		"first":  s.pools,
+		"minTVL": minUniswapPoolTVL,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour).Unix()
+	yields := &ProtocolYields{}
+	for _, raw := range data.Pools {
+		pool := &LiquidityPool{
+			ID:             "uniswap_v3_" + strings.ToLower(raw.ID),
+			Name:           fmt.Sprintf("%s/%s %s%%", raw.Token0.Symbol, raw.Token1.Symbol, feeTierPercent(raw.FeeTier)),
+			TokenA:         raw.Token0.Symbol,
+			TokenB:         raw.Token1.Symbol,
+			ReserveA:       raw.TotalValueLockedToken0,
+			ReserveB:       raw.TotalValueLockedToken1,
+			TotalLiquidity: raw.TotalValueLockedUSD,
+			RiskLevel:      RiskLevelMedium,
+			IsActive:       true,
+		}
+
+		// The current day is incomplete and would understate fees
+		days, fees := 0, decimal.Zero
+		for _, day := range raw.PoolDayData {
+			if day.Date >= today {
+				continue
+			}
+			if days == 0 {
+				pool.Volume24h = day.VolumeUSD
+				pool.Fees24h = day.FeesUSD
+			}
+			fees = fees.Add(day.FeesUSD)
+			days++
+		}
+		if days > 0 && pool.TotalLiquidity.IsPositive() {
+			pool.APY = fees.Div(decimal.NewFromInt(int64(days))).Mul(decimal.NewFromInt(365)).Div(pool.TotalLiquidity).Round(6)
+		}
+
+		yields.Pools = append(yields.Pools, pool)
+		yields.TVL = yields.TVL.Add(pool.TotalLiquidity)
+	}
+	return yields, nil
+}
+
+// feeTierPercent formats a Uniswap fee tier in hundredths of a basis point as a percentage
+func feeTierPercent(feeTier string) string {
+	tier, err := decimal.NewFromString(feeTier)
+	if err != nil {
+		return feeTier
+	}
+	return tier.Div(decimal.NewFromInt(10000)).String()
+}
+
+// LendingSubgraphSource fetches the supply markets of a lending protocol from a subgraph
+// following the Messari lending schema, such as those of Aave and Compound. Market APY is
+// the variable supply rate.
+type LendingSubgraphSource struct {
+	protocolID string
+	client     *subgraphClient
+	markets    int
+}
+
+// NewLendingSubgraphSource creates a source for a lending protocol, e.g. "aave" or "compound"
+func NewLendingSubgraphSource(protocolID, subgraphURL string, markets int) *LendingSubgraphSource {
+	if markets <= 0 {
+		markets = defaultSubgraphPools
+	}
+	return &LendingSubgraphSource{protocolID: protocolID, client: newSubgraphClient(subgraphURL), markets: markets}
+}
+
+const lendingMarketsQuery = `query($first: Int!) {
+  markets(first: $first, orderBy: totalValueLockedUSD, orderDirection: desc, where: {isActive: true}) {
+    id
+    name
+    totalValueLockedUSD
+    inputTokenBalance
+    inputToken { symbol decimals }
+    rates { rate side type }
+  }
+}`
+
+func (s *LendingSubgraphSource) ProtocolID() string {
+	return s.protocolID
+}
+
+func (s *LendingSubgraphSource) FetchYields(ctx context.Context) (*ProtocolYields, error) {
+	var data struct {
+		Markets []struct {
+			ID                  string          `json:"id"`
+			Name                string          `json:"name"`
+			TotalValueLockedUSD decimal.Decimal `json:"totalValueLockedUSD"`
+			InputTokenBalance   decimal.Decimal `json:"inputTokenBalance"`
+			InputToken          struct {
+				Symbol   string `json:"symbol"`
+				Decimals int32  `json:"decimals"`
+			} `json:"inputToken"`
+			Rates []struct {
+				Rate decimal.Decimal `json:"rate"` // percent
+				Side string          `json:"side"`
+				Type string          `json:"type"`
+			} `json:"rates"`
+		} `json:"markets"`
+	}
+	if err := s.client.query(ctx, lendingMarketsQuery, map[string]interface{}{"first": s.markets}, &data); err != nil {
+		return nil, err
+	}
+
+	yields := &ProtocolYields{}
+	for _, market := range data.Markets {
+		if !market.TotalValueLockedUSD.IsPositive() {
+			continue
+		}
+		pool := &LiquidityPool{
+			ID:             s.protocolID + "_" + strings.ToLower(market.ID),
+			Name:           market.Name,
+			TokenA:         market.InputToken.Symbol,
+			ReserveA:       market.InputTokenBalance.Shift(-market.InputToken.Decimals),
+			TotalLiquidity: market.TotalValueLockedUSD,
+			RiskLevel:      RiskLevelLow,
+			IsActive:       true,
+		}
+		for _, rate := range market.Rates {
+			if rate.Side == "LENDER" && rate.Type == "VARIABLE" {
+				pool.APY = rate.Rate.Div(decimal.NewFromInt(100)).Round(6)
+				break
+			}
+		}
+
+		yields.Pools = append(yields.Pools, pool)
+		yields.TVL = yields.TVL.Add(pool.TotalLiquidity)
+	}
+	return yields, nil
+}
@@ -0,0 +1,15 @@
+-- Browser Session Status Migration
+-- Migration 007: Track session lifecycle status and last activity for session listings
+
+ALTER TABLE browser_sessions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'closed', 'terminated'));
+ALTER TABLE browser_sessions ADD COLUMN IF NOT EXISTS termination_reason VARCHAR(100);
+ALTER TABLE browser_sessions ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+-- Backfill existing sessions
+UPDATE browser_sessions SET status = 'closed' WHERE is_active = false AND status = 'active';
+UPDATE browser_sessions SET last_activity_at = updated_at WHERE last_activity_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_browser_sessions_user_status ON browser_sessions(user_id, status);
+CREATE INDEX IF NOT EXISTS idx_browser_sessions_last_activity ON browser_sessions(last_activity_at);
+CREATE INDEX IF NOT EXISTS idx_browser_tabs_session_id ON browser_tabs(session_id);
@@ -0,0 +1,14 @@
+-- Portfolio Value History Migration
+-- Migration 009: Periodic portfolio value, PnL and allocation snapshots for history charts
+
+CREATE TABLE IF NOT EXISTS portfolio_value_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    portfolio_id UUID NOT NULL,
+    total_value NUMERIC(36, 18) NOT NULL,
+    total_pnl NUMERIC(36, 18) NOT NULL DEFAULT 0,
+    allocations JSONB NOT NULL DEFAULT '{}',
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_value_snapshots_portfolio_recorded ON portfolio_value_snapshots(portfolio_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_portfolio_value_snapshots_recorded ON portfolio_value_snapshots(recorded_at);
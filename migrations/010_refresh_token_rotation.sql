@@ -0,0 +1,12 @@
+-- Refresh Token Rotation Migration
+-- Migration 010: Group rotated refresh tokens into session families so reuse can be detected
+
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP WITH TIME ZONE;
+
+-- Existing sessions each start their own family
+UPDATE user_sessions SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE user_sessions ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_refresh_token_hash ON user_sessions(refresh_token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_family_id ON user_sessions(family_id);
@@ -0,0 +1,18 @@
+-- ML Model Versions Migration
+-- Migration 011: Keep the trained state of every model version so serving can be switched or rolled back
+
+CREATE TABLE IF NOT EXISTS ml_model_versions (
+    model_id VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    training_size INTEGER NOT NULL DEFAULT 0,
+    metrics JSONB,
+    state BYTEA NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    activated_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (model_id, version)
+);
+
+-- At most one serving version per model
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ml_model_versions_active ON ml_model_versions(model_id) WHERE is_active;
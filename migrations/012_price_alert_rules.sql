@@ -0,0 +1,21 @@
+-- Price Alert Rules Migration
+-- Migration 012: User-defined alerts on live market prices
+
+CREATE TABLE IF NOT EXISTS price_alert_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    condition VARCHAR(20) NOT NULL,
+    threshold NUMERIC(36, 18) NOT NULL,
+    window_seconds INTEGER NOT NULL DEFAULT 0,
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    repeating BOOLEAN NOT NULL DEFAULT FALSE,
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    trigger_count INTEGER NOT NULL DEFAULT 0,
+    last_triggered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_alert_rules_user ON price_alert_rules(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_price_alert_rules_active ON price_alert_rules(symbol) WHERE is_active;
@@ -0,0 +1,31 @@
+-- Scheduled Crypto Reports Migration
+-- Migration 013: Recurring coin analysis reports and their generated output
+
+CREATE TABLE IF NOT EXISTS crypto_report_schedules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbols TEXT[] NOT NULL,
+    cron VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL CHECK (format IN ('markdown', 'json')),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS crypto_reports (
+    id UUID PRIMARY KEY,
+    schedule_id UUID REFERENCES crypto_report_schedules(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_crypto_report_schedules_user ON crypto_report_schedules(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_crypto_reports_user_generated ON crypto_reports(user_id, generated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_crypto_reports_user_symbol ON crypto_reports(user_id, symbol, generated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_crypto_reports_generated ON crypto_reports(generated_at);
@@ -0,0 +1,14 @@
+-- DeFi LP Position Entry Data Migration
+-- Migration 015: Pool tokens, entry amounts and prices, price ranges and fees for LP analytics
+
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS token_a VARCHAR(20);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS token_b VARCHAR(20);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS amount_a NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS amount_b NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS entry_amount_a NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS entry_amount_b NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS entry_price_a NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS entry_price_b NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS price_lower NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS price_upper NUMERIC(36, 18);
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS fees_earned NUMERIC(36, 18);
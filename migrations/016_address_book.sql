@@ -0,0 +1,17 @@
+-- Address Book Migration
+-- Migration 016: Per-user address book of labelled transaction recipients
+
+CREATE TABLE IF NOT EXISTS web3_address_book (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(64) NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    chain_id INTEGER NOT NULL,
+    verified BOOLEAN NOT NULL DEFAULT false,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_web3_address_book_label ON web3_address_book(user_id, chain_id, LOWER(label));
+CREATE INDEX IF NOT EXISTS idx_web3_address_book_address ON web3_address_book(user_id, chain_id, address);
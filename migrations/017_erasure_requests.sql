@@ -0,0 +1,29 @@
+-- Erasure Requests Migration
+-- Migration 017: Right-to-erasure requests and the per-store tasks of each request
+
+-- user_id deliberately has no foreign key: the request and its certificate outlive the user
+CREATE TABLE IF NOT EXISTS privacy_erasure_requests (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    pseudonym UUID NOT NULL,
+    requested_by UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    certificate JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS privacy_erasure_tasks (
+    request_id UUID NOT NULL REFERENCES privacy_erasure_requests(id) ON DELETE CASCADE,
+    owner VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    action VARCHAR(20),
+    records INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (request_id, owner)
+);
+
+CREATE INDEX IF NOT EXISTS idx_privacy_erasure_tasks_pending ON privacy_erasure_tasks(owner) WHERE status = 'pending';
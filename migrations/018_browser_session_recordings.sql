@@ -0,0 +1,23 @@
+-- Browser Session Recording Migration
+-- Migration 018: Timeline of steps with screenshot thumbnails for recorded browser sessions
+
+ALTER TABLE browser_sessions ADD COLUMN IF NOT EXISTS record BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE browser_sessions ADD COLUMN IF NOT EXISTS recording_truncated BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS browser_session_recording_steps (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES browser_sessions(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    step_type VARCHAR(20) NOT NULL CHECK (step_type IN ('navigate', 'interact', 'extract')),
+    url TEXT,
+    title TEXT,
+    action JSONB,
+    data JSONB,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    thumbnail BYTEA,
+    thumbnail_bytes INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (session_id, sequence)
+);
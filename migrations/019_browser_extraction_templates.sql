@@ -0,0 +1,14 @@
+-- Browser Extraction Templates Migration
+-- Migration 019: Saved CSS/XPath field templates for structured extraction
+
+CREATE TABLE IF NOT EXISTS browser_extraction_templates (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    fields JSONB NOT NULL,
+    pagination JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_browser_extraction_templates_user ON browser_extraction_templates(user_id, name);
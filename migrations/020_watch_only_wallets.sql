@@ -0,0 +1,13 @@
+-- Watch-Only Wallets Migration
+-- Migration 020: Watch-only wallets and the last balances observed for them
+
+ALTER TABLE web3_wallets ADD COLUMN IF NOT EXISTS watch_only BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_web3_wallets_watch_only ON web3_wallets(watch_only) WHERE watch_only;
+
+CREATE TABLE IF NOT EXISTS web3_watch_snapshots (
+    wallet_id UUID PRIMARY KEY REFERENCES web3_wallets(id) ON DELETE CASCADE,
+    nonce BIGINT NOT NULL DEFAULT 0,
+    balances JSONB NOT NULL DEFAULT '{}',
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
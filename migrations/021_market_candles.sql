@@ -0,0 +1,16 @@
+-- Market Candles Migration
+-- Migration 021: Cache of closed historical OHLCV candles fetched from exchange REST APIs
+
+CREATE TABLE IF NOT EXISTS market_candles (
+    exchange VARCHAR(32) NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    candle_interval VARCHAR(8) NOT NULL,
+    open_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    close_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    open NUMERIC(36, 18) NOT NULL,
+    high NUMERIC(36, 18) NOT NULL,
+    low NUMERIC(36, 18) NOT NULL,
+    close NUMERIC(36, 18) NOT NULL,
+    volume NUMERIC(36, 18) NOT NULL,
+    PRIMARY KEY (exchange, symbol, candle_interval, open_time)
+);
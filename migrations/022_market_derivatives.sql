@@ -0,0 +1,15 @@
+-- Market Derivatives Migration
+-- Migration 022: Funding rate and open interest samples of perpetual futures
+
+CREATE TABLE IF NOT EXISTS market_derivatives_snapshots (
+    exchange VARCHAR(32) NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    observed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    funding_rate NUMERIC(20, 10) NOT NULL,
+    mark_price NUMERIC(36, 18) NOT NULL,
+    open_interest NUMERIC(36, 18) NOT NULL,
+    next_funding_time TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (exchange, symbol, observed_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_derivatives_symbol_time ON market_derivatives_snapshots(symbol, observed_at);
@@ -0,0 +1,7 @@
+-- User Roles Migration
+-- Migration 023: Role of each user (viewer, trader or admin), embedded in access tokens
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'trader';
+
+-- Existing users keep the access they had before roles were introduced
+UPDATE users SET role = 'trader' WHERE role NOT IN ('viewer', 'trader', 'admin');
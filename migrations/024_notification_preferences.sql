@@ -0,0 +1,14 @@
+-- Notification Preferences Migration
+-- Migration 024: Per-user alert destinations, quiet hours and category toggles
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    telegram_chat_id VARCHAR(64) NOT NULL DEFAULT '',
+    webhook_url TEXT NOT NULL DEFAULT '',
+    quiet_start VARCHAR(5),
+    quiet_end VARCHAR(5),
+    quiet_timezone VARCHAR(64),
+    categories JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
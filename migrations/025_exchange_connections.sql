@@ -0,0 +1,19 @@
+-- Exchange Connections Migration
+-- Migration 025: Per-user exchange API credentials for trading bots, encrypted at rest
+
+CREATE TABLE IF NOT EXISTS exchange_connections (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exchange VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    key_suffix VARCHAR(16) NOT NULL,
+    credentials BYTEA NOT NULL,
+    key_id VARCHAR(100) NOT NULL,
+    algorithm VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    validated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_connections_user ON exchange_connections(user_id, created_at DESC);
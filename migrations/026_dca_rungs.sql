@@ -0,0 +1,15 @@
+-- DCA Rungs Migration
+-- Migration 026: Filled buys of DCA trading bots, restoring their schedule and cost basis
+
+CREATE TABLE IF NOT EXISTS dca_rungs (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id VARCHAR(64) NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    quantity NUMERIC(36, 18) NOT NULL,
+    price NUMERIC(36, 18) NOT NULL,
+    cost NUMERIC(36, 18) NOT NULL,
+    multiplier NUMERIC(10, 4) NOT NULL DEFAULT 1,
+    executed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dca_rungs_bot ON dca_rungs(bot_id, executed_at);
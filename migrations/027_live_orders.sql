@@ -0,0 +1,26 @@
+-- Live Orders Migration
+-- Migration 027: Journal of live bot orders, reconciled against the exchanges
+
+CREATE TABLE IF NOT EXISTS live_orders (
+    id VARCHAR(64) PRIMARY KEY,
+    client_order_id VARCHAR(64) NOT NULL UNIQUE,
+    bot_id VARCHAR(64) NOT NULL,
+    connection_id UUID NOT NULL REFERENCES exchange_connections(id),
+    exchange VARCHAR(50) NOT NULL,
+    exchange_order_id VARCHAR(64) NOT NULL DEFAULT '',
+    symbol VARCHAR(32) NOT NULL,
+    side VARCHAR(10) NOT NULL,
+    order_type VARCHAR(20) NOT NULL,
+    quantity NUMERIC(36, 18) NOT NULL,
+    price NUMERIC(36, 18) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    filled_quantity NUMERIC(36, 18) NOT NULL DEFAULT 0,
+    average_price NUMERIC(36, 18) NOT NULL DEFAULT 0,
+    commission NUMERIC(36, 18) NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_live_orders_created ON live_orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_live_orders_bot ON live_orders(bot_id, created_at);
@@ -0,0 +1,21 @@
+-- Testnet Networks Migration
+-- Migration 028: Tag wallets, transactions and DeFi positions with their network
+
+ALTER TABLE web3_wallets ADD COLUMN IF NOT EXISTS network VARCHAR(16) NOT NULL DEFAULT 'mainnet';
+ALTER TABLE web3_transactions ADD COLUMN IF NOT EXISTS network VARCHAR(16) NOT NULL DEFAULT 'mainnet';
+ALTER TABLE defi_positions ADD COLUMN IF NOT EXISTS network VARCHAR(16) NOT NULL DEFAULT 'mainnet';
+
+-- Sepolia, Polygon Amoy, BSC testnet, Arbitrum Sepolia, OP Sepolia and Base Sepolia
+UPDATE web3_wallets SET network = 'testnet'
+WHERE chain_id IN (11155111, 80002, 97, 421614, 11155420, 84532);
+
+UPDATE web3_transactions SET network = 'testnet'
+WHERE chain_id IN (11155111, 80002, 97, 421614, 11155420, 84532);
+
+UPDATE defi_positions p SET network = w.network
+FROM web3_wallets w
+WHERE p.wallet_id = w.id AND w.network <> p.network;
+
+CREATE INDEX IF NOT EXISTS idx_web3_wallets_network ON web3_wallets(user_id, network);
+CREATE INDEX IF NOT EXISTS idx_web3_transactions_network ON web3_transactions(user_id, network);
+CREATE INDEX IF NOT EXISTS idx_defi_positions_network ON defi_positions(user_id, network);
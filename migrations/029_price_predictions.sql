@@ -0,0 +1,22 @@
+-- Price Prediction Evaluation Migration
+-- Migration 029: Served price predictions, resolved against realized prices
+
+CREATE TABLE IF NOT EXISTS price_predictions (
+    id UUID PRIMARY KEY,
+    model_id VARCHAR(100) NOT NULL,
+    model_version INTEGER NOT NULL DEFAULT 0,
+    symbol VARCHAR(20) NOT NULL,
+    timeframe VARCHAR(10) NOT NULL DEFAULT '',
+    horizon INTEGER NOT NULL,
+    base_price DOUBLE PRECISION NOT NULL,
+    predicted_price DOUBLE PRECISION NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    predicted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    target_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved', 'expired')),
+    actual_price DOUBLE PRECISION,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_predictions_due ON price_predictions(target_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_price_predictions_model ON price_predictions(model_id, predicted_at);
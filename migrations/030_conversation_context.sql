@@ -0,0 +1,8 @@
+-- Conversation Context Migration
+-- Migration 030: Rolling summaries of older messages and cumulative token usage per conversation
+
+ALTER TABLE ai_conversations ADD COLUMN IF NOT EXISTS summary TEXT;
+ALTER TABLE ai_conversations ADD COLUMN IF NOT EXISTS summarized_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE ai_conversations ADD COLUMN IF NOT EXISTS prompt_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE ai_conversations ADD COLUMN IF NOT EXISTS completion_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE ai_conversations ADD COLUMN IF NOT EXISTS estimated_cost_usd NUMERIC(14, 6) NOT NULL DEFAULT 0;
@@ -0,0 +1,23 @@
+-- AI Usage Accounting Migration
+-- Migration 031: Hourly provider usage per user, endpoint and model, and per-user monthly budgets
+
+CREATE TABLE IF NOT EXISTS ai_usage_hourly (
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    endpoint VARCHAR(255) NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    model VARCHAR(128) NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(16, 6) NOT NULL DEFAULT 0,
+    PRIMARY KEY (hour, user_id, endpoint, provider, model)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_hourly_user_hour ON ai_usage_hourly(user_id, hour);
+
+CREATE TABLE IF NOT EXISTS ai_usage_budgets (
+    user_id VARCHAR(64) PRIMARY KEY,
+    monthly_limit_usd NUMERIC(12, 2) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,26 @@
+-- Document Embeddings Migration
+-- Migration 032: Analyzed documents with pgvector embeddings of their text chunks for semantic search
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS ai_documents (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    mime_type VARCHAR(128) NOT NULL DEFAULT '',
+    sha256 VARCHAR(64) NOT NULL DEFAULT '',
+    chunk_count INTEGER NOT NULL DEFAULT 0,
+    -- Vectors are only comparable within one embedding model, and models differ in dimensions
+    embedding_model VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_documents_user ON ai_documents(user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS ai_document_chunks (
+    document_id UUID NOT NULL REFERENCES ai_documents(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    embedding vector NOT NULL,
+    PRIMARY KEY (document_id, chunk_index)
+);
@@ -0,0 +1,14 @@
+-- Alert Escalations Migration
+-- Migration 033: Active alert escalations, so paging resumes after a service restart
+
+CREATE TABLE IF NOT EXISTS alert_escalations (
+    alert_id VARCHAR(64) PRIMARY KEY,
+    policy_id VARCHAR(64) NOT NULL,
+    next_step INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_step_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    alert JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_escalations_next_step_at ON alert_escalations(next_step_at);
@@ -0,0 +1,7 @@
+-- PII Search Hashes Migration
+-- Migration 034: Keyed search hashes of searchable PII, so users can be found by email once the
+-- address is stored encrypted. Existing rows are filled by EncryptionManager.BackfillPIIHashes.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_users_email_hash ON users(email_hash);
@@ -0,0 +1,11 @@
+-- External Signing Migration
+-- Migration 035: Transactions of hardware and WalletConnect wallets wait for the user's wallet
+-- to sign them; those the user doesn't sign in time are cancelled or expire.
+
+ALTER TABLE web3_transactions DROP CONSTRAINT IF EXISTS web3_transactions_status_check;
+ALTER TABLE web3_transactions ADD CONSTRAINT web3_transactions_status_check
+    CHECK (status IN ('pending', 'confirmed', 'failed', 'awaiting_signature', 'cancelled', 'expired'));
+
+-- Transactions awaiting a signature have no hash yet
+CREATE INDEX IF NOT EXISTS idx_web3_transactions_awaiting_signature
+    ON web3_transactions(user_id) WHERE status = 'awaiting_signature';
@@ -0,0 +1,35 @@
+-- Multisig Proposals Migration
+-- Migration 036: Shared portfolios can require several members to approve transactions of
+-- their treasury wallet; transactions wait as proposals until enough members approve.
+
+CREATE TABLE IF NOT EXISTS web3_multisig_policies (
+    portfolio_id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES web3_wallets(id) ON DELETE CASCADE,
+    chain_id INTEGER NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    safe BOOLEAN NOT NULL DEFAULT FALSE,
+    members TEXT[] NOT NULL,
+    threshold INTEGER NOT NULL CHECK (threshold >= 1),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_web3_multisig_policies_wallet ON web3_multisig_policies(wallet_id);
+
+-- Proposals are stored whole as JSON, with the columns they are looked up by
+CREATE TABLE IF NOT EXISTS web3_multisig_proposals (
+    id UUID PRIMARY KEY,
+    portfolio_id UUID NOT NULL REFERENCES web3_multisig_policies(portfolio_id) ON DELETE CASCADE,
+    members TEXT[] NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'executed', 'rejected', 'expired', 'failed')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_web3_multisig_proposals_members ON web3_multisig_proposals USING GIN(members);
+CREATE INDEX IF NOT EXISTS idx_web3_multisig_proposals_pending
+    ON web3_multisig_proposals(expires_at) WHERE status = 'pending';
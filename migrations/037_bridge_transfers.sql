@@ -0,0 +1,17 @@
+-- Bridge Transfers Migration
+-- Migration 037: Cross-chain transfers are tracked from their source transaction to the
+-- destination transaction delivering the funds.
+
+-- Transfers are stored whole as JSON, with the columns they are looked up by
+CREATE TABLE IF NOT EXISTS web3_bridge_transfers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('awaiting_source', 'source_pending', 'relaying', 'completed', 'refunded', 'failed', 'cancelled')),
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_web3_bridge_transfers_user ON web3_bridge_transfers(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_web3_bridge_transfers_active
+    ON web3_bridge_transfers(status) WHERE status IN ('awaiting_source', 'source_pending', 'relaying');
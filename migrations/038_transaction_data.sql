@@ -0,0 +1,4 @@
+-- Transaction Data Migration
+-- Migration 038: Keep transaction calldata so token transfers and approvals can be described
+
+ALTER TABLE web3_transactions ADD COLUMN IF NOT EXISTS data TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Bot Grids Migration
+-- Migration 039: Level maps of grid trading bots, restoring their levels and inventory
+
+CREATE TABLE IF NOT EXISTS bot_grids (
+    bot_id VARCHAR(64) NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    data JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bot_id, symbol)
+);
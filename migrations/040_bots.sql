@@ -0,0 +1,15 @@
+-- Bots Migration
+-- Migration 040: Trading bots created through the API, restored with their owners and exchange connections
+
+CREATE TABLE IF NOT EXISTS bots (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    strategy VARCHAR(50) NOT NULL,
+    config JSONB NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    connection_id UUID REFERENCES exchange_connections(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bots_user ON bots(user_id);
@@ -0,0 +1,16 @@
+-- Dashboard Shares Migration
+-- Migration 041: Public share links to analytics dashboards, kept revoked and counted across restarts
+
+CREATE TABLE IF NOT EXISTS dashboard_shares (
+    share_id UUID PRIMARY KEY,
+    dashboard_id VARCHAR(100) NOT NULL,
+    scope JSONB NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    view_count BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboard_shares_dashboard ON dashboard_shares(dashboard_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Transaction Fees Migration
+-- Migration 042: Keep the EIP-1559 fees a transaction was created with and the fee tier they came from
+
+ALTER TABLE web3_transactions ADD COLUMN IF NOT EXISTS max_fee_per_gas NUMERIC(78, 0);
+ALTER TABLE web3_transactions ADD COLUMN IF NOT EXISTS max_priority_fee_per_gas NUMERIC(78, 0);
+ALTER TABLE web3_transactions ADD COLUMN IF NOT EXISTS fee_tier VARCHAR(16) NOT NULL DEFAULT '';
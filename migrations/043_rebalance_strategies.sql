@@ -0,0 +1,16 @@
+-- Rebalance Strategies Migration
+-- Migration 043: Keep portfolio rebalance strategies, with their schedules and the strategies
+-- they replaced, across restarts
+
+-- Strategies are stored whole as JSON, with the columns they are looked up by
+CREATE TABLE IF NOT EXISTS rebalance_strategies (
+    id UUID PRIMARY KEY,
+    portfolio_id UUID NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_strategies_portfolio ON rebalance_strategies(portfolio_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rebalance_strategies_active ON rebalance_strategies(created_at DESC) WHERE is_active;
@@ -252,9 +252,7 @@ func (r *RedisClient) SetLayered(ctx context.Context, key string, value interfac
 func (r *RedisClient) GetLayered(ctx context.Context, key string) (interface{}, bool, error) {
 	start := time.Now()
 
-	layers := []string{"l1:", "l2:", "l3:"}
-
-	for _, prefix := range layers {
+	for _, prefix := range cacheLayerPrefixes {
 		fullKey := prefix + key
 		result := r.Get(ctx, fullKey)
 
@@ -469,6 +467,58 @@ func (r *RedisClient) DeleteKeys(ctx context.Context, keys ...string) error {
 	return err
 }
 
+// cacheLayerPrefixes are the key prefixes SetLayered stores each layer under
+var cacheLayerPrefixes = []string{"l1:", "l2:", "l3:"}
+
+// DeleteLayered deletes a key from every cache layer
+func (r *RedisClient) DeleteLayered(ctx context.Context, key string) error {
+	keys := make([]string, 0, len(cacheLayerPrefixes))
+	for _, prefix := range cacheLayerPrefixes {
+		keys = append(keys, prefix+key)
+	}
+	return r.DeleteKeys(ctx, keys...)
+}
+
+// DeleteLayeredPattern deletes the keys matching a Redis glob pattern from every cache
+// layer and returns how many were deleted
+func (r *RedisClient) DeleteLayeredPattern(ctx context.Context, pattern string) (int64, error) {
+	const batchSize = 500
+
+	var deleted int64
+	for _, prefix := range cacheLayerPrefixes {
+		batch := make([]string, 0, batchSize)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			n, err := r.Del(ctx, batch...).Result()
+			deleted += n
+			batch = batch[:0]
+			return err
+		}
+
+		iter := r.Scan(ctx, 0, prefix+pattern, batchSize).Iterator()
+		for iter.Next(ctx) {
+			if batch = append(batch, iter.Val()); len(batch) == batchSize {
+				if err := flush(); err != nil {
+					return deleted, err
+				}
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return deleted, err
+		}
+		if err := flush(); err != nil {
+			return deleted, err
+		}
+	}
+
+	r.metrics.mu.Lock()
+	r.metrics.DeleteCount += deleted
+	r.metrics.mu.Unlock()
+	return deleted, nil
+}
+
 // Exists checks if a key exists with metrics
 func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
 	start := time.Now()
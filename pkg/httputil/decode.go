@@ -0,0 +1,199 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agentic-browser/pkg/validation"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidBody is wrapped by errors of bodies that are not valid JSON
+var ErrInvalidBody = errors.New("invalid request body")
+
+// unknownFieldPrefix starts the error encoding/json returns for unknown fields
+const unknownFieldPrefix = "json: unknown field "
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	decimalType     = reflect.TypeOf(decimal.Decimal{})
+)
+
+// BodyDecoder decodes JSON request bodies. A strict decoder rejects unknown fields, so a typo
+// such as "symbl" fails the request instead of being ignored.
+type BodyDecoder struct {
+	Strict bool
+}
+
+// Decode decodes the JSON body of r into v. Values of the wrong type and, when strict, unknown
+// fields are returned as validation.FieldErrors; bodies that are not JSON wrap ErrInvalidBody.
+func (d BodyDecoder) Decode(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if d.Strict {
+		decoder.DisallowUnknownFields()
+	}
+
+	err = decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return validation.FieldErrors{{
+			Field:      typeErr.Field,
+			Constraint: validation.ConstraintType,
+			Message:    "must be " + jsonTypeName(typeErr.Type),
+		}}
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		if unquoteErr != nil {
+			field = strings.TrimPrefix(err.Error(), unknownFieldPrefix)
+		}
+		return validation.FieldErrors{{Field: field, Constraint: validation.ConstraintUnknown, Message: "is not a known field"}}
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: body is empty", ErrInvalidBody)
+	case !errors.As(err, &syntaxErr):
+		// Errors of types decoding themselves, such as decimals, don't name their field
+		if field, fieldType := failingField(body, reflect.TypeOf(v), ""); field != "" {
+			if fieldType == decimalType {
+				return validation.FieldErrors{{Field: field, Constraint: validation.ConstraintDecimal, Message: "must be a decimal number such as \"1000.50\""}}
+			}
+			return validation.FieldErrors{{Field: field, Constraint: validation.ConstraintInvalid, Message: err.Error()}}
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+}
+
+// failingField walks raw alongside t and returns the path and type of the first value that
+// fails to decode on its own, or an empty path when none does
+func failingField(raw json.RawMessage, t reflect.Type, path string) (string, reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+			return path, t
+		}
+		return "", nil
+	}
+
+	join := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) != nil {
+			return "", nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				// Fields of embedded structs are promoted to this object
+				if failed, failedType := failingField(raw, field.Type, path); failed != "" {
+					return failed, failedType
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			for key, value := range fields {
+				if strings.EqualFold(key, name) {
+					if failed, failedType := failingField(value, field.Type, join(name)); failed != "" {
+						return failed, failedType
+					}
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return "", nil
+		}
+		for i, item := range items {
+			if failed, failedType := failingField(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); failed != "" {
+				return failed, failedType
+			}
+		}
+	case reflect.Map:
+		var entries map[string]json.RawMessage
+		if json.Unmarshal(raw, &entries) != nil {
+			return "", nil
+		}
+		for key, value := range entries {
+			if failed, failedType := failingField(value, t.Elem(), join(key)); failed != "" {
+				return failed, failedType
+			}
+		}
+	}
+	return "", nil
+}
+
+// WriteRequestError responds to a request body that failed to decode or validate: 422 listing
+// the invalid fields of validation.FieldErrors, and 400 otherwise
+func WriteRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	var fieldErrors validation.FieldErrors
+	if errors.As(err, &fieldErrors) {
+		writeErrorDetail(w, http.StatusUnprocessableEntity, ErrorDetail{
+			Code:      CodeValidationFailed,
+			Message:   "Request validation failed",
+			RequestID: RequestID(r),
+			Fields:    fieldErrors,
+		})
+		return
+	}
+
+	message := "Invalid request body"
+	if detail := strings.TrimPrefix(err.Error(), ErrInvalidBody.Error()); detail != err.Error() {
+		message += detail
+	}
+	WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, message)
+}
+
+// jsonTypeName names the JSON type of values decoded into t
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "a different type"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	return "a " + t.String()
+}
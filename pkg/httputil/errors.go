@@ -0,0 +1,134 @@
+// Package httputil writes the JSON error responses shared by the HTTP services
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/ai-agentic-browser/pkg/validation"
+)
+
+// ErrorCode is a stable, machine-readable identifier of what went wrong
+type ErrorCode string
+
+// Error codes for the common cases. Specific codes such as PORTFOLIO_NOT_FOUND refine the
+// generic code of their status.
+const (
+	CodeInvalidRequest     ErrorCode = "INVALID_REQUEST"   // malformed body, form or query
+	CodeValidationFailed   ErrorCode = "VALIDATION_FAILED" // well-formed but invalid fields
+	CodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	CodeForbidden          ErrorCode = "FORBIDDEN"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodeExpired            ErrorCode = "EXPIRED"
+	CodePayloadTooLarge    ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeUnprocessable      ErrorCode = "UNPROCESSABLE"
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeBudgetExceeded     ErrorCode = "BUDGET_EXCEEDED"
+	CodeInsufficientFunds  ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeUpstreamFailure    ErrorCode = "UPSTREAM_FAILURE"
+	CodeAIUpstreamFailure  ErrorCode = "AI_UPSTREAM_FAILURE"
+	CodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	CodeCircuitOpen        ErrorCode = "CIRCUIT_OPEN" // the gateway stopped proxying to a failing service
+	CodeMaintenance        ErrorCode = "MAINTENANCE"
+	CodeInternal           ErrorCode = "INTERNAL_ERROR"
+
+	CodePortfolioNotFound ErrorCode = "PORTFOLIO_NOT_FOUND"
+	CodePositionNotFound  ErrorCode = "POSITION_NOT_FOUND"
+	CodeStrategyNotFound  ErrorCode = "STRATEGY_NOT_FOUND"
+	CodeAlertNotFound     ErrorCode = "ALERT_NOT_FOUND"
+)
+
+// ErrorResponse is the body of every error response:
+// {"error": {"code": "PORTFOLIO_NOT_FOUND", "message": "...", "request_id": "..."}}
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes an error. RequestID matches the request_id of the request's log
+// entries, so users can report it. Fields lists the invalid fields of a request that failed
+// validation.
+type ErrorDetail struct {
+	Code      ErrorCode              `json:"code"`
+	Message   string                 `json:"message"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Fields    validation.FieldErrors `json:"fields,omitempty"`
+}
+
+// requestIDHeader is set by the tracing middleware on the request and the response
+const requestIDHeader = "X-Request-ID"
+
+// WriteError writes an error response with the given status and code
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code ErrorCode, message string) {
+	writeErrorDetail(w, status, ErrorDetail{
+		Code:      code,
+		Message:   message,
+		RequestID: RequestID(r),
+	})
+}
+
+func writeErrorDetail(w http.ResponseWriter, status int, detail ErrorDetail) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: detail})
+}
+
+// Error writes an error response with the generic code of its status. It replaces http.Error;
+// message is shown to clients and must not contain internal error details.
+func Error(w http.ResponseWriter, r *http.Request, message string, status int) {
+	WriteError(w, r, status, CodeForStatus(status), message)
+}
+
+// InternalError logs err and responds 500 with message, keeping err's details out of the
+// response. The request ID in the response finds the log entry.
+func InternalError(w http.ResponseWriter, r *http.Request, logger *observability.Logger, message string, err error) {
+	logger.Error(r.Context(), message, err, map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+	WriteError(w, r, http.StatusInternalServerError, CodeInternal, message)
+}
+
+// RequestID returns the ID of the request, or an empty string when it has none
+func RequestID(r *http.Request) string {
+	if requestID := observability.RequestIDFromContext(r.Context()); requestID != "" {
+		return requestID
+	}
+	return r.Header.Get(requestIDHeader)
+}
+
+// CodeForStatus returns the generic error code of an HTTP status
+func CodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeValidationFailed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusPaymentRequired:
+		return CodeBudgetExceeded
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeExpired
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return CodeUpstreamFailure
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeValidationFailed
+}
@@ -0,0 +1,341 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/redis/go-redis/v9"
+)
+
+// APIKeyHeader carries the API key of programmatic requests
+const APIKeyHeader = "X-API-Key"
+
+// API key scopes. A write scope also grants the matching read scope.
+const (
+	ScopeTradingRead  = "trading:read"
+	ScopeTradingWrite = "trading:write"
+	ScopeAIInvoke     = "ai:invoke"
+)
+
+// APIKeyScopes lists every scope a key can be granted
+var APIKeyScopes = []string{ScopeTradingRead, ScopeTradingWrite, ScopeAIInvoke}
+
+// apiKeyScopeRoles is the lowest role that may hold each scope
+var apiKeyScopeRoles = map[string]string{
+	ScopeTradingRead:  RoleViewer,
+	ScopeTradingWrite: RoleTrader,
+	ScopeAIInvoke:     RoleViewer,
+}
+
+const (
+	APIKeyIDKey     ContextKey = "api_key_id"
+	APIKeyScopesKey ContextKey = "api_key_scopes"
+)
+
+const (
+	// apiKeyCacheTTL is how long a resolved key is cached in Redis
+	apiKeyCacheTTL = 5 * time.Minute
+	// apiKeyTouchInterval throttles last-used updates per key
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrAPIKeyNotFound is returned by an APIKeyStore for unknown and revoked keys
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey is returned for unknown, expired and revoked keys
+	ErrInvalidAPIKey = errors.New("invalid api key")
+)
+
+// APIKey is the identity an API key resolves to. Role is the current role of the key's owner.
+type APIKey struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyStore looks up API keys by the hash of their secret
+type APIKeyStore interface {
+	// FindAPIKey returns the unrevoked key with the given hash, or ErrAPIKeyNotFound
+	FindAPIKey(ctx context.Context, keyHash string) (*APIKey, error)
+	// TouchAPIKey records when a key was last used
+	TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error
+}
+
+// HashAPIKey returns the hash API keys are stored and looked up by. Keys are random, so an
+// unsalted SHA-256 is enough.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// ValidAPIKeyScope reports whether scope is a known API key scope
+func ValidAPIKeyScope(scope string) bool {
+	for _, known := range APIKeyScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScopeRole returns the lowest role allowed to grant scope to a key
+func APIKeyScopeRole(scope string) string {
+	return apiKeyScopeRoles[scope]
+}
+
+// MethodScope requires readScope for GET, HEAD and OPTIONS requests and writeScope otherwise
+func MethodScope(readScope, writeScope string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return readScope
+		}
+		return writeScope
+	}
+}
+
+// StaticScope requires the same scope for every request
+func StaticScope(scope string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return scope
+	}
+}
+
+// GetAPIKeyID returns the ID of the API key a request was authenticated with
+func GetAPIKeyID(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(APIKeyIDKey).(string)
+	return keyID, ok
+}
+
+// APIKeyAuthenticator authenticates requests carrying an X-API-Key header. Resolved keys are
+// cached in Redis; revoking a key puts it on the revocation list, which is checked on every
+// request so revocation applies across replicas immediately. Each key has its own rate limit,
+// separate from the per-user limits of JWT sessions.
+type APIKeyAuthenticator struct {
+	store       APIKeyStore
+	redis       *database.RedisClient
+	logger      *observability.Logger
+	revocations *TokenRevocationList
+	limiter     *RateLimiter
+	limit       int
+
+	mu          sync.Mutex
+	lastTouched map[string]time.Time
+}
+
+// NewAPIKeyAuthenticator creates an authenticator. redis may be nil, in which case every
+// request is resolved through the store and limits are enforced per replica.
+func NewAPIKeyAuthenticator(store APIKeyStore, redis *database.RedisClient, logger *observability.Logger, cfg config.RateLimitConfig) *APIKeyAuthenticator {
+	limiter := NewRateLimiter(redis, logger, config.RateLimitConfig{
+		RequestsPerMinute: cfg.APIKeyRequestsPerMinute,
+		Burst:             cfg.Burst,
+	}, "")
+	limiter.SetKeyPrefix("ratelimit:apikey:")
+
+	return &APIKeyAuthenticator{
+		store:       store,
+		redis:       redis,
+		logger:      logger,
+		revocations: NewTokenRevocationList(redis),
+		limiter:     limiter,
+		limit:       cfg.APIKeyRequestsPerMinute,
+		lastTouched: make(map[string]time.Time),
+	}
+}
+
+// Middleware authenticates requests with an X-API-Key header and requires the key to hold
+// the scope scopeFor returns. On success the key's user ID and their role are stored under
+// UserIDKey and UserRoleKey, so handlers and role checks work as for JWTs; a key whose owner
+// has no known role gets the lowest one. Requests without the header are passed to
+// fallback, usually the JWT middleware; without a fallback they are rejected.
+func (a *APIKeyAuthenticator) Middleware(fallback func(http.Handler) http.Handler, scopeFor func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		var unauthenticated http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httputil.Error(w, r, "API key required", http.StatusUnauthorized)
+		})
+		if fallback != nil {
+			unauthenticated = fallback(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := r.Header.Get(APIKeyHeader)
+			if secret == "" {
+				unauthenticated.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := a.Authenticate(r.Context(), secret)
+			if err != nil {
+				if errors.Is(err, ErrInvalidAPIKey) {
+					httputil.Error(w, r, "Invalid API key", http.StatusUnauthorized)
+					return
+				}
+				a.logger.Error(r.Context(), "Failed to verify API key", err)
+				httputil.Error(w, r, "Failed to verify API key", http.StatusInternalServerError)
+				return
+			}
+
+			role := key.Role
+			if !ValidRole(role) {
+				role = RoleViewer
+			}
+			if scope := scopeFor(r); scope != "" {
+				if !hasAPIKeyScope(key.Scopes, scope) {
+					httputil.Error(w, r, "API key lacks the "+scope+" scope", http.StatusForbidden)
+					return
+				}
+				// Keys keep their scopes when their owner is demoted
+				if required := APIKeyScopeRole(scope); !RoleIncludes(role, required) {
+					forbidRole(w, r.WithContext(context.WithValue(r.Context(), UserRoleKey, role)), required)
+					return
+				}
+			}
+
+			if a.limit > 0 {
+				decision := a.limiter.take(r.Context(), RateLimitClassDefault, "key:"+key.ID, a.limit)
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.limit))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.remaining))
+				if !decision.allowed {
+					seconds := int((decision.retryAfter + time.Second - 1) / time.Second)
+					if seconds < 1 {
+						seconds = 1
+					}
+					w.Header().Set("Retry-After", strconv.Itoa(seconds))
+					httputil.Error(w, r, "Rate limit exceeded", http.StatusTooManyRequests)
+					return
+				}
+			}
+
+			a.touch(key.ID)
+
+			ctx := context.WithValue(r.Context(), UserIDKey, key.UserID)
+			ctx = context.WithValue(ctx, UserRoleKey, role)
+			ctx = context.WithValue(ctx, APIKeyIDKey, key.ID)
+			ctx = context.WithValue(ctx, APIKeyScopesKey, key.Scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Authenticate resolves an API key secret, returning ErrInvalidAPIKey for unknown, expired
+// and revoked keys
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, secret string) (*APIKey, error) {
+	keyHash := HashAPIKey(secret)
+
+	key, cached := a.cachedKey(ctx, keyHash)
+	if !cached {
+		var err error
+		key, err = a.store.FindAPIKey(ctx, keyHash)
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		if err != nil {
+			return nil, err
+		}
+		a.cacheKey(ctx, keyHash, key)
+	}
+
+	if key.ExpiresAt != nil && !time.Now().Before(*key.ExpiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+	// A revoked key may still be cached; if the list cannot be checked the key is accepted,
+	// as for JWTs
+	if revoked, err := a.revocations.IsAPIKeyRevoked(ctx, key.ID); err == nil && revoked {
+		return nil, ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+func (a *APIKeyAuthenticator) cachedKey(ctx context.Context, keyHash string) (*APIKey, bool) {
+	if a.redis == nil {
+		return nil, false
+	}
+	data, err := a.redis.Get(ctx, "auth:apikey:"+keyHash).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			a.logger.Warn(ctx, "API key cache unavailable", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return nil, false
+	}
+
+	var key APIKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, false
+	}
+	return &key, true
+}
+
+func (a *APIKeyAuthenticator) cacheKey(ctx context.Context, keyHash string, key *APIKey) {
+	if a.redis == nil {
+		return
+	}
+	ttl := apiKeyCacheTTL
+	if key.ExpiresAt != nil {
+		if untilExpiry := time.Until(*key.ExpiresAt); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return
+	}
+	a.redis.SetWithExpiry(ctx, "auth:apikey:"+keyHash, data, ttl)
+}
+
+// touch records the key as used, at most once per apiKeyTouchInterval per replica
+func (a *APIKeyAuthenticator) touch(keyID string) {
+	now := time.Now()
+
+	a.mu.Lock()
+	if now.Sub(a.lastTouched[keyID]) < apiKeyTouchInterval {
+		a.mu.Unlock()
+		return
+	}
+	a.lastTouched[keyID] = now
+	a.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.store.TouchAPIKey(ctx, keyID, now); err != nil {
+			a.logger.Warn(ctx, "Failed to record API key use", map[string]interface{}{
+				"api_key_id": keyID,
+				"error":      err.Error(),
+			})
+		}
+	}()
+}
+
+// hasAPIKeyScope reports whether scopes grant required, counting a write scope as granting
+// the matching read scope
+func hasAPIKeyScope(scopes []string, required string) bool {
+	writeScope := ""
+	if resource, found := strings.CutSuffix(required, ":read"); found {
+		writeScope = resource + ":write"
+	}
+	for _, scope := range scopes {
+		if scope == required || (writeScope != "" && scope == writeScope) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAPIKeyStore resolves API keys from a map of secrets
+type memoryAPIKeyStore map[string]*APIKey
+
+func (m memoryAPIKeyStore) FindAPIKey(ctx context.Context, keyHash string) (*APIKey, error) {
+	for secret, key := range m {
+		if HashAPIKey(secret) == keyHash {
+			return key, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (m memoryAPIKeyStore) TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	return nil
+}
+
+func TestAPIKeyAuthenticator_AppliesOwnerRole(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	store := memoryAPIKeyStore{
+		"viewer-key": {ID: "k1", UserID: "u1", Role: RoleViewer, Scopes: []string{ScopeTradingWrite}},
+		"trader-key": {ID: "k2", UserID: "u2", Role: RoleTrader, Scopes: []string{ScopeTradingWrite}},
+		"legacy-key": {ID: "k3", UserID: "u3", Scopes: []string{ScopeTradingRead}},
+	}
+	authenticator := NewAPIKeyAuthenticator(store, nil, logger, config.RateLimitConfig{})
+	policy, err := NewRoutePolicy(config.DefaultRoutePolicy)
+	require.NoError(t, err)
+
+	var role string
+	handler := authenticator.Middleware(nil, MethodScope(ScopeTradingRead, ScopeTradingWrite))(
+		policy.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role = GetUserRole(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	send := func(method, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/web3/trading/orders", nil)
+		req.Header.Set(APIKeyHeader, secret)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A viewer's key is denied trader routes, even with a write scope
+	denied := send(http.MethodPost, "viewer-key")
+	assert.Equal(t, http.StatusForbidden, denied.Code)
+	assert.Contains(t, denied.Body.String(), `requires role \"trader\", you have \"viewer\"`)
+
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "viewer-key").Code)
+	assert.Equal(t, RoleViewer, role)
+	require.Equal(t, http.StatusOK, send(http.MethodPost, "trader-key").Code)
+	assert.Equal(t, RoleTrader, role)
+
+	// Owners without a known role get the lowest one rather than the legacy trader role
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "legacy-key").Code)
+	assert.Equal(t, RoleViewer, role)
+
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "unknown-key").Code)
+}
+
+func TestAPIKeyAuthenticator_ScopeBeyondOwnerRole(t *testing.T) {
+	logger := observability.NewLogger(config.ObservabilityConfig{})
+	store := memoryAPIKeyStore{
+		"viewer-key": {ID: "k1", UserID: "u1", Role: RoleViewer, Scopes: []string{ScopeTradingWrite, ScopeAIInvoke}},
+	}
+	authenticator := NewAPIKeyAuthenticator(store, nil, logger, config.RateLimitConfig{})
+
+	// Routes outside the role policy still can't be written by a demoted owner's key
+	handler := authenticator.Middleware(nil, MethodScope(ScopeTradingRead, ScopeTradingWrite))(okHandler)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/trading-bots", nil)
+	req.Header.Set(APIKeyHeader, "viewer-key")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	handler = authenticator.Middleware(nil, StaticScope(ScopeAIInvoke))(okHandler)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, RoleTrader, APIKeyScopeRole(ScopeTradingWrite))
+	assert.True(t, RoleIncludes(RoleAdmin, RoleTrader))
+	assert.False(t, RoleIncludes(RoleViewer, RoleTrader))
+	assert.False(t, RoleIncludes("", RoleViewer))
+}
@@ -8,24 +8,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ai-agentic-browser/internal/config"
 	"github.com/ai-agentic-browser/pkg/database"
 	"github.com/ai-agentic-browser/pkg/observability"
 )
 
 // CacheMiddleware provides intelligent HTTP response caching
 type CacheMiddleware struct {
-	redis  *database.RedisClient
-	logger *observability.Logger
-	config *CacheConfig
-	stats  *CacheStats
-	mu     sync.RWMutex
+	redis    *database.RedisClient
+	logger   *observability.Logger
+	config   *CacheConfig
+	stats    *CacheStats
+	exporter *observability.PrometheusExporter
+	routes   []routeTTL
+	admins   *AdminAuthorizer
+	mu       sync.RWMutex
 }
 
+// routeTTL is a compiled config.RouteTTL
+type routeTTL struct {
+	pattern string
+	re      *regexp.Regexp
+	ttl     time.Duration
+}
+
+// defaultRoute labels the statistics of requests that match no configured route
+const defaultRoute = "default"
+
 // CacheConfig contains caching configuration
 type CacheConfig struct {
 	DefaultTTL       time.Duration
@@ -44,9 +59,17 @@ type CacheStats struct {
 	Sets      int64
 	Errors    int64
 	TotalSize int64
+	Routes    map[string]*RouteCacheStats
 	mu        sync.RWMutex
 }
 
+// RouteCacheStats tracks caching performance of one route pattern
+type RouteCacheStats struct {
+	Hits     int64
+	Misses   int64
+	Bypasses int64
+}
+
 // CachedResponse represents a cached HTTP response
 type CachedResponse struct {
 	StatusCode int                 `json:"status_code"`
@@ -74,17 +97,72 @@ func NewCacheMiddleware(redis *database.RedisClient, logger *observability.Logge
 		CacheableStatus:  []int{200, 201, 202, 203, 204, 300, 301, 302, 304, 404, 410},
 		CacheableMethods: []string{"GET", "HEAD"},
 		ExcludePaths:     []string{"/health", "/metrics", "/auth/"},
-		VaryHeaders:      []string{"Accept", "Accept-Encoding", "Authorization"},
+		VaryHeaders:      []string{"Accept", "Accept-Encoding", "Authorization", APIKeyHeader},
 	}
 
 	return &CacheMiddleware{
 		redis:  redis,
 		logger: logger,
 		config: config,
-		stats:  &CacheStats{},
+		stats:  &CacheStats{Routes: make(map[string]*RouteCacheStats)},
 	}
 }
 
+// SetRouteTTLs sets the default TTL and the per-route TTLs, checked in order
+func (cm *CacheMiddleware) SetRouteTTLs(defaultTTL time.Duration, routes []config.RouteTTL) error {
+	compiled := make([]routeTTL, 0, len(routes))
+	for _, route := range routes {
+		expr, isRegex := strings.CutPrefix(route.Pattern, "regex:")
+		if !isRegex {
+			expr = "^" + strings.ReplaceAll(strings.ReplaceAll(regexp.QuoteMeta(route.Pattern), `\*`, ".*"), `\?`, ".") + "$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid cache route pattern %q: %w", route.Pattern, err)
+		}
+		compiled = append(compiled, routeTTL{pattern: route.Pattern, re: re, ttl: route.TTL})
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if defaultTTL > 0 {
+		cm.config.DefaultTTL = defaultTTL
+	}
+	cm.routes = compiled
+	return nil
+}
+
+// SetAdminAuthorizer lets administrators skip cached responses with "Cache-Control: no-cache".
+// The fresh response replaces the cached one. Other clients' no-cache requests are served
+// from the cache as usual.
+func (cm *CacheMiddleware) SetAdminAuthorizer(admins *AdminAuthorizer) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.admins = admins
+}
+
+// Invalidate deletes the cached responses of the request paths matching a Redis glob
+// pattern, e.g. "/ai/models/*", and returns how many entries were deleted
+func (cm *CacheMiddleware) Invalidate(ctx context.Context, pattern string) (int64, error) {
+	deleted, err := cm.redis.DeleteLayeredPattern(ctx, "cache:"+pattern+":*")
+	if err != nil {
+		return deleted, fmt.Errorf("failed to invalidate cache: %w", err)
+	}
+
+	cm.logger.Info(ctx, "Cache invalidated", map[string]interface{}{
+		"pattern": pattern,
+		"deleted": deleted,
+	})
+	return deleted, nil
+}
+
+// SetPrometheusExporter feeds cache hits, misses and the hit ratio into Prometheus collectors
+func (cm *CacheMiddleware) SetPrometheusExporter(exporter *observability.PrometheusExporter) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.exporter = exporter
+}
+
 // Middleware returns the caching middleware function
 func (cm *CacheMiddleware) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -101,13 +179,23 @@ func (cm *CacheMiddleware) Middleware() func(http.Handler) http.Handler {
 				return
 			}
 
+			// Skip caching for routes with caching disabled
+			route, ttl := cm.routeTTL(r.URL.Path)
+			if ttl <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Generate cache key
 			cacheKey := cm.generateCacheKey(r)
 
-			// Try to serve from cache
-			if cached, found := cm.getFromCache(r.Context(), cacheKey); found {
+			// Try to serve from cache unless an administrator asked for a fresh response
+			bypass := cm.isBypassRequest(r)
+			if bypass {
+				w.Header().Set("X-Cache", "BYPASS")
+			} else if cached, found := cm.getFromCache(r.Context(), cacheKey); found {
 				cm.serveCachedResponse(w, cached)
-				cm.updateStats("hit")
+				cm.updateStats("hit", route)
 				return
 			}
 
@@ -128,7 +216,7 @@ func (cm *CacheMiddleware) Middleware() func(http.Handler) http.Handler {
 					Headers:    rw.headers,
 					Body:       rw.body.Bytes(),
 					CreatedAt:  time.Now(),
-					TTL:        cm.config.DefaultTTL,
+					TTL:        ttl,
 					Size:       int64(len(rw.body.Bytes())),
 				}
 
@@ -137,18 +225,48 @@ func (cm *CacheMiddleware) Middleware() func(http.Handler) http.Handler {
 						"cache_key": cacheKey,
 						"path":      r.URL.Path,
 					})
-					cm.updateStats("error")
+					cm.updateStats("error", route)
 				} else {
-					cm.updateStats("set")
+					cm.updateStats("set", route)
 				}
 			}
 
-			cm.updateStats("miss")
+			if bypass {
+				cm.updateStats("bypass", route)
+			} else {
+				cm.updateStats("miss", route)
+			}
 		})
 	}
 }
 
-// generateCacheKey creates a unique cache key for the request
+// routeTTL returns the first route pattern matching path and its TTL
+func (cm *CacheMiddleware) routeTTL(path string) (string, time.Duration) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	for _, route := range cm.routes {
+		if route.re.MatchString(path) {
+			return route.pattern, route.ttl
+		}
+	}
+	return defaultRoute, cm.config.DefaultTTL
+}
+
+// isBypassRequest reports whether an administrator asked to skip the cache
+func (cm *CacheMiddleware) isBypassRequest(r *http.Request) bool {
+	if !strings.Contains(strings.ToLower(r.Header.Get("Cache-Control")), "no-cache") {
+		return false
+	}
+
+	cm.mu.RLock()
+	admins := cm.admins
+	cm.mu.RUnlock()
+	return admins.IsAdmin(r)
+}
+
+// generateCacheKey creates a unique cache key for the request. The path stays readable in
+// the key so entries can be invalidated by path pattern.
 func (cm *CacheMiddleware) generateCacheKey(r *http.Request) string {
 	h := md5.New()
 
@@ -164,7 +282,7 @@ func (cm *CacheMiddleware) generateCacheKey(r *http.Request) string {
 		}
 	}
 
-	return "cache:" + hex.EncodeToString(h.Sum(nil))
+	return "cache:" + r.URL.Path + ":" + hex.EncodeToString(h.Sum(nil))
 }
 
 // getFromCache retrieves a cached response
@@ -187,7 +305,7 @@ func (cm *CacheMiddleware) getFromCache(ctx context.Context, key string) (*Cache
 
 	// Check if cache entry is still valid
 	if time.Since(cached.CreatedAt) > cached.TTL {
-		cm.redis.DeleteKeys(ctx, key)
+		cm.redis.DeleteLayered(ctx, key)
 		return nil, false
 	}
 
@@ -206,8 +324,12 @@ func (cm *CacheMiddleware) setCache(ctx context.Context, key string, cached *Cac
 		return fmt.Errorf("failed to marshal cached response: %w", err)
 	}
 
-	// Use L2 cache for most responses
-	return cm.redis.SetLayered(ctx, key, string(data), database.L2Cache)
+	// Use L2 cache for most responses; its 15 minute expiry would cut longer TTLs short
+	layer := database.L2Cache
+	if cached.TTL > 15*time.Minute {
+		layer = database.L3Cache
+	}
+	return cm.redis.SetLayered(ctx, key, string(data), layer)
 }
 
 // serveCachedResponse serves a cached response
@@ -260,20 +382,39 @@ func (cm *CacheMiddleware) isExcludedPath(path string) bool {
 }
 
 // updateStats updates cache statistics
-func (cm *CacheMiddleware) updateStats(operation string) {
+func (cm *CacheMiddleware) updateStats(operation, route string) {
 	cm.stats.mu.Lock()
 	defer cm.stats.mu.Unlock()
 
+	routeStats, exists := cm.stats.Routes[route]
+	if !exists {
+		routeStats = &RouteCacheStats{}
+		cm.stats.Routes[route] = routeStats
+	}
+
 	switch operation {
 	case "hit":
 		cm.stats.Hits++
+		routeStats.Hits++
 	case "miss":
 		cm.stats.Misses++
+		routeStats.Misses++
+	case "bypass":
+		routeStats.Bypasses++
 	case "set":
 		cm.stats.Sets++
 	case "error":
 		cm.stats.Errors++
 	}
+
+	cm.mu.RLock()
+	exporter := cm.exporter
+	cm.mu.RUnlock()
+
+	exporter.RecordCacheOperation(operation)
+	if total := cm.stats.Hits + cm.stats.Misses; total > 0 {
+		exporter.SetCacheStats(float64(cm.stats.Hits)/float64(total), cm.stats.TotalSize)
+	}
 }
 
 // GetStats returns current cache statistics
@@ -287,6 +428,28 @@ func (cm *CacheMiddleware) GetStats() map[string]interface{} {
 		hitRate = float64(cm.stats.Hits) / float64(total) * 100
 	}
 
+	cm.mu.RLock()
+	ttls := map[string]time.Duration{defaultRoute: cm.config.DefaultTTL}
+	for _, route := range cm.routes {
+		ttls[route.pattern] = route.ttl
+	}
+	cm.mu.RUnlock()
+
+	routes := make(map[string]interface{}, len(cm.stats.Routes))
+	for pattern, stats := range cm.stats.Routes {
+		routeHitRate := float64(0)
+		if routeTotal := stats.Hits + stats.Misses; routeTotal > 0 {
+			routeHitRate = float64(stats.Hits) / float64(routeTotal) * 100
+		}
+		routes[pattern] = map[string]interface{}{
+			"hits":     stats.Hits,
+			"misses":   stats.Misses,
+			"bypasses": stats.Bypasses,
+			"hit_rate": routeHitRate,
+			"ttl":      ttls[pattern].String(),
+		}
+	}
+
 	return map[string]interface{}{
 		"hits":       cm.stats.Hits,
 		"misses":     cm.stats.Misses,
@@ -294,6 +457,7 @@ func (cm *CacheMiddleware) GetStats() map[string]interface{} {
 		"errors":     cm.stats.Errors,
 		"hit_rate":   hitRate,
 		"total_size": cm.stats.TotalSize,
+		"routes":     routes,
 	}
 }
 
@@ -314,6 +478,12 @@ func (rw *cacheResponseWriter) WriteHeader(statusCode int) {
 	rw.ResponseWriter.WriteHeader(statusCode)
 }
 
+func (rw *cacheResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func (rw *responseWriter) Header() http.Header {
 	return rw.ResponseWriter.Header()
 }
@@ -0,0 +1,316 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a POST safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	idempotencyStatusProcessing = "processing"
+	idempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyMiddleware replays the stored response when a request is retried with the same
+// Idempotency-Key instead of executing it again. Keys are scoped to the authenticated user,
+// method and path. Reusing a key with a different request body, or while the first request
+// is still in flight, is rejected with 409 Conflict.
+type IdempotencyMiddleware struct {
+	records idempotencyStore
+	logger  *observability.Logger
+	config  *IdempotencyConfig
+}
+
+// idempotencyStore holds idempotency records by key. Get returns nil when the key has expired
+// or been released.
+type idempotencyStore interface {
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// redisIdempotencyStore keeps idempotency records in Redis so every instance shares them
+type redisIdempotencyStore struct {
+	redis *database.RedisClient
+}
+
+// IdempotencyConfig contains idempotency configuration
+type IdempotencyConfig struct {
+	TTL          time.Duration // how long completed responses are replayed
+	LockTTL      time.Duration // how long an in-flight request holds its key
+	KeyPrefix    string
+	MaxKeyLength int
+	MaxBodyBytes int64
+	ReplayHeader string
+}
+
+// idempotencyRecord is the state stored in Redis for a key
+type idempotencyRecord struct {
+	Status      string              `json:"status"`
+	Fingerprint string              `json:"fingerprint"`
+	StatusCode  int                 `json:"status_code,omitempty"`
+	Headers     map[string][]string `json:"headers,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// idempotencyResponseWriter passes the response through while capturing it for storage
+type idempotencyResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	headers    http.Header
+	body       bytes.Buffer
+}
+
+// NewIdempotencyMiddleware creates a new idempotency middleware
+func NewIdempotencyMiddleware(redis *database.RedisClient, logger *observability.Logger) *IdempotencyMiddleware {
+	if redis == nil {
+		return newIdempotencyMiddleware(nil, logger)
+	}
+	return newIdempotencyMiddleware(&redisIdempotencyStore{redis: redis}, logger)
+}
+
+func newIdempotencyMiddleware(records idempotencyStore, logger *observability.Logger) *IdempotencyMiddleware {
+	config := &IdempotencyConfig{
+		TTL:          24 * time.Hour,
+		LockTTL:      time.Minute,
+		KeyPrefix:    "idempotency:",
+		MaxKeyLength: 255,
+		MaxBodyBytes: 1 << 20, // 1MB
+		ReplayHeader: "Idempotent-Replayed",
+	}
+
+	return &IdempotencyMiddleware{
+		records: records,
+		logger:  logger,
+		config:  config,
+	}
+}
+
+// Middleware returns the idempotency middleware function. Requests without an Idempotency-Key
+// header, and all requests when no Redis client is configured, pass straight through.
+func (im *IdempotencyMiddleware) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if im == nil || im.records == nil || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > im.config.MaxKeyLength {
+				httputil.Error(w, r, "Idempotency-Key is too long", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, im.config.MaxBodyBytes+1))
+			if err != nil {
+				httputil.Error(w, r, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if int64(len(body)) > im.config.MaxBodyBytes {
+				httputil.Error(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := r.Context()
+			storageKey := im.storageKey(r, key)
+			fingerprint := requestFingerprint(body)
+
+			reserved, err := im.reserve(ctx, storageKey, fingerprint)
+			if err != nil {
+				// Fail open: an unavailable store must not block transactions
+				im.logger.Error(ctx, "Failed to reserve idempotency key", err, map[string]interface{}{
+					"path": r.URL.Path,
+				})
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !reserved {
+				im.handleDuplicate(w, r, storageKey, fingerprint)
+				return
+			}
+
+			rw := &idempotencyResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+
+			// Server errors release the key so the client can safely retry
+			if rw.status() >= http.StatusInternalServerError {
+				if err := im.records.Delete(context.WithoutCancel(ctx), storageKey); err != nil {
+					im.logger.Error(ctx, "Failed to release idempotency key", err, nil)
+				}
+				return
+			}
+
+			record := &idempotencyRecord{
+				Status:      idempotencyStatusCompleted,
+				Fingerprint: fingerprint,
+				StatusCode:  rw.status(),
+				Headers:     rw.capturedHeaders(),
+				Body:        rw.body.Bytes(),
+				CreatedAt:   time.Now(),
+			}
+			if err := im.store(context.WithoutCancel(ctx), storageKey, record, im.config.TTL); err != nil {
+				im.logger.Error(ctx, "Failed to store idempotent response", err, map[string]interface{}{
+					"path": r.URL.Path,
+				})
+			}
+		})
+	}
+}
+
+// handleDuplicate replays the stored response or rejects a conflicting reuse of the key
+func (im *IdempotencyMiddleware) handleDuplicate(w http.ResponseWriter, r *http.Request, storageKey, fingerprint string) {
+	record, err := im.load(r.Context(), storageKey)
+	if err != nil {
+		im.logger.Error(r.Context(), "Failed to load idempotency record", err, nil)
+		httputil.Error(w, r, "Failed to check idempotency key", http.StatusInternalServerError)
+		return
+	}
+	if record == nil {
+		// The first request failed and released the key between our reserve and load
+		httputil.Error(w, r, "A request with this Idempotency-Key was just retried, try again", http.StatusConflict)
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		httputil.Error(w, r, "Idempotency-Key was already used with a different request body", http.StatusConflict)
+		return
+	}
+	if record.Status != idempotencyStatusCompleted {
+		httputil.Error(w, r, "A request with this Idempotency-Key is still being processed", http.StatusConflict)
+		return
+	}
+
+	for name, values := range record.Headers {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(im.config.ReplayHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// storageKey scopes the client key to the user, method and path
+func (im *IdempotencyMiddleware) storageKey(r *http.Request, key string) string {
+	userID, _ := GetUserID(r.Context())
+	h := sha256.New()
+	h.Write([]byte(userID))
+	h.Write([]byte{0})
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return im.config.KeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// reserve claims the key for an in-flight request; false means the key is already in use
+func (im *IdempotencyMiddleware) reserve(ctx context.Context, storageKey, fingerprint string) (bool, error) {
+	data, err := json.Marshal(&idempotencyRecord{
+		Status:      idempotencyStatusProcessing,
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return im.records.SetNX(ctx, storageKey, data, im.config.LockTTL)
+}
+
+// store saves the completed response for replay
+func (im *IdempotencyMiddleware) store(ctx context.Context, storageKey string, record *idempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return im.records.Set(ctx, storageKey, data, ttl)
+}
+
+// load returns the record for a key, or nil if it has expired or been released
+func (im *IdempotencyMiddleware) load(ctx context.Context, storageKey string) (*idempotencyRecord, error) {
+	data, err := im.records.Get(ctx, storageKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *redisIdempotencyStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.redis.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (s *redisIdempotencyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.redis.Client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *redisIdempotencyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.redis.SetWithExpiry(ctx, key, value, ttl)
+}
+
+func (s *redisIdempotencyStore) Delete(ctx context.Context, key string) error {
+	return s.redis.DeleteKeys(ctx, key)
+}
+
+// requestFingerprint identifies a request body so a reused key with a different body is detected
+func requestFingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyResponseWriter implementation
+func (rw *idempotencyResponseWriter) WriteHeader(statusCode int) {
+	if rw.statusCode == 0 {
+		rw.statusCode = statusCode
+		rw.headers = rw.ResponseWriter.Header().Clone()
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.body.Write(data)
+	return rw.ResponseWriter.Write(data)
+}
+
+func (rw *idempotencyResponseWriter) status() int {
+	if rw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rw.statusCode
+}
+
+func (rw *idempotencyResponseWriter) capturedHeaders() map[string][]string {
+	if rw.headers == nil {
+		return rw.ResponseWriter.Header().Clone()
+	}
+	return rw.headers
+}
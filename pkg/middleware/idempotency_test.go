@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyStore is an in-memory idempotencyStore; TTLs are ignored
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string][]byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string][]byte)}
+}
+
+func (s *memoryIdempotencyStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.records[key]; exists {
+		return false, nil
+	}
+	s.records[key] = value
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *memoryIdempotencyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = value
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// idempotencyFixture wraps a handler that counts executions and answers with status
+type idempotencyFixture struct {
+	store   *memoryIdempotencyStore
+	handler http.Handler
+	calls   atomic.Int32
+	status  atomic.Int32
+	release chan struct{} // when set, the handler blocks until it is closed
+}
+
+func newIdempotencyFixture() *idempotencyFixture {
+	f := &idempotencyFixture{store: newMemoryIdempotencyStore()}
+	f.status.Store(http.StatusCreated)
+	im := newIdempotencyMiddleware(f.store, observability.NewLogger(config.ObservabilityConfig{}))
+	f.handler = im.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := f.calls.Add(1)
+		if f.release != nil {
+			<-f.release
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Order", fmt.Sprintf("order-%d", n))
+		w.WriteHeader(int(f.status.Load()))
+		fmt.Fprintf(w, `{"order":%d,"request":%s}`, n, body)
+	}))
+	return f
+}
+
+func (f *idempotencyFixture) post(key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysCompletedResponse(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	first := f.post("key-1", `{"qty":1}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	replay := f.post("key-1", `{"qty":1}`)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "order-1", replay.Header().Get("X-Order"))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, int32(1), f.calls.Load(), "a replay must not execute the handler again")
+
+	// Requests without a key, or with a new one, always execute
+	f.post("", `{"qty":1}`)
+	f.post("key-2", `{"qty":1}`)
+	assert.Equal(t, int32(3), f.calls.Load())
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	require.Equal(t, http.StatusCreated, f.post("key-1", `{"qty":1}`).Code)
+
+	rec := f.post("key-1", `{"qty":2}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "different request body")
+	assert.Equal(t, int32(1), f.calls.Load())
+}
+
+func TestIdempotency_RejectsWhileInFlight(t *testing.T) {
+	f := newIdempotencyFixture()
+	f.release = make(chan struct{})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- f.post("key-1", `{"qty":1}`) }()
+	require.Eventually(t, func() bool { return f.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	rec := f.post("key-1", `{"qty":1}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "still being processed")
+
+	close(f.release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+	assert.Equal(t, int32(1), f.calls.Load())
+}
+
+func TestIdempotency_ReleasesKeyAfterServerError(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	f.status.Store(http.StatusBadGateway)
+	require.Equal(t, http.StatusBadGateway, f.post("key-1", `{"qty":1}`).Code)
+	assert.Zero(t, f.store.len(), "a server error must not leave the key reserved")
+
+	// The retry executes and its response is the one replayed from then on
+	f.status.Store(http.StatusCreated)
+	retry := f.post("key-1", `{"qty":1}`)
+	require.Equal(t, http.StatusCreated, retry.Code)
+	assert.Empty(t, retry.Header().Get("Idempotent-Replayed"))
+
+	replay := f.post("key-1", `{"qty":1}`)
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, retry.Body.String(), replay.Body.String())
+	assert.Equal(t, int32(2), f.calls.Load())
+
+	// Client errors are final and replayed like successes
+	f.status.Store(http.StatusUnprocessableEntity)
+	require.Equal(t, http.StatusUnprocessableEntity, f.post("key-2", `{"qty":0}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, f.post("key-2", `{"qty":0}`).Code)
+	assert.Equal(t, int32(3), f.calls.Load())
+}
+
+func TestIdempotency_KeysAreScopedToUser(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	post := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(`{"qty":1}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		rec := httptest.NewRecorder()
+		f.handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	post("user-1")
+	rec := post("user-2")
+	assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), f.calls.Load())
+}
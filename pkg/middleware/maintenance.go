@@ -0,0 +1,400 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/redis/go-redis/v9"
+)
+
+// HealthStatusMaintenance is reported by health endpoints while maintenance mode is enabled.
+// It is served with 200 so orchestrators keep the pods running.
+const HealthStatusMaintenance = "maintenance"
+
+// MaintenanceHeader marks 503 responses sent because of maintenance mode rather than a failure
+const MaintenanceHeader = "X-Maintenance"
+
+// MaintenanceState describes whether maintenance mode is enabled, shared by every service
+// through Redis
+type MaintenanceState struct {
+	Enabled       bool      `json:"enabled"`
+	Reason        string    `json:"reason,omitempty"`
+	EnabledBy     string    `json:"enabled_by,omitempty"`
+	EnabledAt     time.Time `json:"enabled_at,omitempty"`
+	DrainDeadline time.Time `json:"drain_deadline,omitempty"`
+	RetryAfter    int       `json:"retry_after_seconds,omitempty"`
+}
+
+// MaintenanceDrainStatus counts the requests and streams still running in a service
+type MaintenanceDrainStatus struct {
+	InFlight int  `json:"in_flight"`
+	Streams  int  `json:"streams"`
+	Drained  bool `json:"drained"`
+}
+
+// MaintenanceMode rejects new non-essential requests with 503 while maintenance is enabled and
+// drains the ones already running. Requests finish on their own; WebSocket and SSE streams are
+// cancelled through their request context once the drain deadline passes. Changes made through
+// one service apply to it immediately and reach the others when they next read Redis.
+type MaintenanceMode struct {
+	redis     *database.RedisClient
+	logger    *observability.Logger
+	config    config.MaintenanceConfig
+	key       string
+	essential []string
+
+	mu         sync.Mutex
+	state      MaintenanceState
+	inFlight   int
+	streams    map[int]context.CancelFunc
+	nextStream int
+	drainTimer *time.Timer
+	listeners  []func(MaintenanceState)
+}
+
+// NewMaintenanceMode creates maintenance mode shared through redis, which may be nil to keep
+// it local to the process
+func NewMaintenanceMode(redis *database.RedisClient, logger *observability.Logger, cfg config.MaintenanceConfig) *MaintenanceMode {
+	return &MaintenanceMode{
+		redis:     redis,
+		logger:    logger,
+		config:    cfg,
+		key:       "system:maintenance",
+		essential: cfg.EssentialPaths,
+		streams:   make(map[int]context.CancelFunc),
+	}
+}
+
+// OnChange registers a function called, without locks held, whenever maintenance mode is
+// enabled or disabled
+func (m *MaintenanceMode) OnChange(fn func(MaintenanceState)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Enable starts maintenance mode, draining streams for drainTimeout or the configured timeout
+// when it is zero
+func (m *MaintenanceMode) Enable(ctx context.Context, reason, enabledBy string, drainTimeout time.Duration) (MaintenanceState, error) {
+	if drainTimeout <= 0 {
+		drainTimeout = m.config.DrainTimeout
+	}
+	now := time.Now()
+	state := MaintenanceState{
+		Enabled:       true,
+		Reason:        reason,
+		EnabledBy:     enabledBy,
+		EnabledAt:     now,
+		DrainDeadline: now.Add(drainTimeout),
+		RetryAfter:    int(m.config.RetryAfter.Seconds()),
+	}
+	if err := m.save(ctx, state); err != nil {
+		return MaintenanceState{}, err
+	}
+	m.apply(ctx, state)
+	return state, nil
+}
+
+// Disable ends maintenance mode
+func (m *MaintenanceMode) Disable(ctx context.Context) error {
+	if err := m.save(ctx, MaintenanceState{}); err != nil {
+		return err
+	}
+	m.apply(ctx, MaintenanceState{})
+	return nil
+}
+
+// State returns the maintenance state last seen by this service
+func (m *MaintenanceMode) State() MaintenanceState {
+	if m == nil {
+		return MaintenanceState{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// Enabled reports whether maintenance mode is enabled
+func (m *MaintenanceMode) Enabled() bool {
+	return m.State().Enabled
+}
+
+// DrainStatus counts the requests and streams this service is still draining
+func (m *MaintenanceMode) DrainStatus() MaintenanceDrainStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return MaintenanceDrainStatus{
+		InFlight: m.inFlight,
+		Streams:  len(m.streams),
+		Drained:  m.inFlight == 0 && len(m.streams) == 0,
+	}
+}
+
+// Run follows maintenance changes made through other services until ctx is done
+func (m *MaintenanceMode) Run(ctx context.Context) {
+	if m.redis == nil {
+		return
+	}
+
+	interval := m.config.SyncInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *MaintenanceMode) sync(ctx context.Context) {
+	data, err := m.redis.Client.Get(ctx, m.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		m.apply(ctx, MaintenanceState{})
+		return
+	}
+	if err != nil {
+		m.logger.Warn(ctx, "Failed to read maintenance state", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var state MaintenanceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		m.logger.Warn(ctx, "Ignoring malformed maintenance state", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	m.apply(ctx, state)
+}
+
+func (m *MaintenanceMode) save(ctx context.Context, state MaintenanceState) error {
+	if m.redis == nil {
+		return nil
+	}
+	if !state.Enabled {
+		if err := m.redis.Client.Del(ctx, m.key).Err(); err != nil {
+			return fmt.Errorf("failed to clear maintenance state: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := m.redis.Client.Set(ctx, m.key, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to store maintenance state: %w", err)
+	}
+	return nil
+}
+
+// apply switches to state, scheduling the end of the drain and notifying listeners when
+// maintenance is enabled, disabled or restarted
+func (m *MaintenanceMode) apply(ctx context.Context, state MaintenanceState) {
+	m.mu.Lock()
+	changed := state.Enabled != m.state.Enabled || !state.EnabledAt.Equal(m.state.EnabledAt)
+	m.state = state
+	if !changed {
+		m.mu.Unlock()
+		return
+	}
+
+	if m.drainTimer != nil {
+		m.drainTimer.Stop()
+		m.drainTimer = nil
+	}
+	if state.Enabled {
+		m.drainTimer = time.AfterFunc(time.Until(state.DrainDeadline), m.closeStreams)
+	}
+	listeners := append([]func(MaintenanceState){}, m.listeners...)
+	m.mu.Unlock()
+
+	if state.Enabled {
+		m.logger.Info(ctx, "Maintenance mode enabled", map[string]interface{}{
+			"reason":         state.Reason,
+			"enabled_by":     state.EnabledBy,
+			"drain_deadline": state.DrainDeadline,
+		})
+	} else {
+		m.logger.Info(ctx, "Maintenance mode disabled")
+	}
+	for _, listener := range listeners {
+		listener(state)
+	}
+}
+
+// closeStreams cancels the streams still open when the drain deadline passes
+func (m *MaintenanceMode) closeStreams() {
+	m.mu.Lock()
+	streams := m.streams
+	m.streams = make(map[int]context.CancelFunc)
+	m.mu.Unlock()
+
+	for _, cancel := range streams {
+		cancel()
+	}
+	if len(streams) > 0 {
+		m.logger.Info(context.Background(), "Closed streams at the maintenance drain deadline", map[string]interface{}{
+			"streams": len(streams),
+		})
+	}
+}
+
+// Middleware answers new non-essential requests with 503 and Retry-After during maintenance and
+// tracks the rest so they can be drained. Health checks, the maintenance routes and the
+// configured essential paths are always served.
+func (m *MaintenanceMode) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := m.State()
+			if state.Enabled && !m.isEssential(r.URL.Path) {
+				writeMaintenance(w, r, state)
+				return
+			}
+
+			if !isStreamRequest(r) {
+				m.mu.Lock()
+				m.inFlight++
+				m.mu.Unlock()
+				defer func() {
+					m.mu.Lock()
+					m.inFlight--
+					m.mu.Unlock()
+				}()
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			m.mu.Lock()
+			id := m.nextStream
+			m.nextStream++
+			m.streams[id] = cancel
+			m.mu.Unlock()
+			defer func() {
+				m.mu.Lock()
+				delete(m.streams, id)
+				m.mu.Unlock()
+			}()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (m *MaintenanceMode) isEssential(requestPath string) bool {
+	if requestPath == "/health" || strings.HasSuffix(requestPath, "/health") || strings.HasPrefix(requestPath, "/admin/maintenance") {
+		return true
+	}
+	for _, pattern := range m.essential {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(requestPath, pattern) {
+			return true
+		}
+		if matched, _ := path.Match(pattern, requestPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isStreamRequest reports whether the request opens a WebSocket or server-sent events stream
+func isStreamRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeMaintenance(w http.ResponseWriter, r *http.Request, state MaintenanceState) {
+	w.Header().Set(MaintenanceHeader, "true")
+	if state.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(state.RetryAfter))
+	}
+	message := "The service is undergoing maintenance"
+	if state.Reason != "" {
+		message += ": " + state.Reason
+	}
+	httputil.WriteError(w, r, http.StatusServiceUnavailable, httputil.CodeMaintenance, message)
+}
+
+// enableMaintenanceRequest is the body of POST /admin/maintenance/enable. DrainTimeout is a
+// duration such as "90s" and defaults to the configured timeout.
+type enableMaintenanceRequest struct {
+	Reason       string `json:"reason"`
+	DrainTimeout string `json:"drain_timeout"`
+}
+
+// EnableHandler handles POST /admin/maintenance/enable. It must run behind admin authentication.
+func (m *MaintenanceMode) EnableHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req enableMaintenanceRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				httputil.WriteError(w, r, http.StatusBadRequest, httputil.CodeInvalidRequest, "Invalid request body")
+				return
+			}
+		}
+		var drainTimeout time.Duration
+		if req.DrainTimeout != "" {
+			var err error
+			if drainTimeout, err = time.ParseDuration(req.DrainTimeout); err != nil || drainTimeout <= 0 {
+				httputil.Error(w, r, "drain_timeout must be a positive duration", http.StatusBadRequest)
+				return
+			}
+		}
+
+		userID, _ := GetUserID(r.Context())
+		if _, err := m.Enable(r.Context(), req.Reason, userID, drainTimeout); err != nil {
+			m.logger.Error(r.Context(), "Failed to enable maintenance mode", err)
+			httputil.Error(w, r, "Failed to enable maintenance mode", http.StatusInternalServerError)
+			return
+		}
+		m.writeStatus(w)
+	}
+}
+
+// DisableHandler handles POST /admin/maintenance/disable. It must run behind admin
+// authentication.
+func (m *MaintenanceMode) DisableHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Disable(r.Context()); err != nil {
+			m.logger.Error(r.Context(), "Failed to disable maintenance mode", err)
+			httputil.Error(w, r, "Failed to disable maintenance mode", http.StatusInternalServerError)
+			return
+		}
+		m.writeStatus(w)
+	}
+}
+
+// StatusHandler handles GET /admin/maintenance
+func (m *MaintenanceMode) StatusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.writeStatus(w)
+	}
+}
+
+func (m *MaintenanceMode) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"maintenance": m.State(),
+		"drain":       m.DrainStatus(),
+	})
+}
@@ -1,20 +1,24 @@
 package middleware
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/httputil"
 	"github.com/ai-agentic-browser/pkg/observability"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/time/rate"
 )
 
 // ContextKey is a type for context keys to avoid collisions
@@ -47,6 +51,7 @@ func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, Retry-After, X-Request-ID")
 
 			// Handle preflight requests
 			if r.Method == "OPTIONS" {
@@ -76,6 +81,12 @@ func Tracing(serviceName string) func(http.Handler) http.Handler {
 			)
 			defer span.End()
 
+			// Every log entry and error response of the request carries its ID
+			requestID := requestIDFor(r, span)
+			r.Header.Set(RequestIDHeader, requestID)
+			w.Header().Set(RequestIDHeader, requestID)
+			ctx = observability.WithRequestID(ctx, requestID)
+
 			// Create a response writer wrapper to capture status code
 			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
@@ -95,6 +106,30 @@ func Tracing(serviceName string) func(http.Handler) http.Handler {
 	}
 }
 
+// RequestIDHeader carries the request ID between the gateway, the services and clients
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDFor keeps a request ID set upstream, such as by the gateway, and otherwise uses the
+// trace ID so the request can also be found in traces
+func requestIDFor(r *http.Request, span trace.Span) string {
+	if requestID := r.Header.Get(RequestIDHeader); requestID != "" && len(requestID) <= 128 && isPrintableASCII(requestID) {
+		return requestID
+	}
+	if span.SpanContext().HasTraceID() {
+		return span.SpanContext().TraceID().String()
+	}
+	return uuid.New().String()
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -106,6 +141,22 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implements http.Flusher so streaming handlers keep working behind the wrapper
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker so WebSocket upgrades work behind the wrapper
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
 // Logging middleware for request/response logging
 func Logging(logger *observability.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -138,36 +189,73 @@ func Logging(logger *observability.Logger) func(http.Handler) http.Handler {
 	}
 }
 
-// RateLimit middleware for rate limiting requests
-func RateLimit(cfg config.RateLimitConfig) func(http.Handler) http.Handler {
-	limiter := rate.NewLimiter(rate.Limit(cfg.RequestsPerMinute)/60, cfg.Burst)
-
+// Metrics middleware records request latency and status in the performance monitor. Requests
+// are labelled by their matched route pattern so path parameters don't inflate cardinality.
+func Metrics(monitor *observability.PerformanceMonitor) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !limiter.Allow() {
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
-				return
-			}
-			next.ServeHTTP(w, r)
+			start := time.Now()
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(wrapped, r)
+
+			monitor.RecordRequest(&observability.RequestMetrics{
+				Path:       routePattern(r),
+				Method:     r.Method,
+				StatusCode: wrapped.statusCode,
+				Duration:   time.Since(start),
+				UserAgent:  r.UserAgent(),
+				IP:         r.RemoteAddr,
+				Timestamp:  start,
+			})
 		})
 	}
 }
 
+// routePattern returns the ServeMux or gorilla/mux route that matched the request
+func routePattern(r *http.Request) string {
+	if r.Pattern != "" {
+		// ServeMux patterns may carry a method prefix such as "GET /health"
+		if _, path, found := strings.Cut(r.Pattern, " "); found {
+			return path
+		}
+		return r.Pattern
+	}
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
+
+// RateLimit middleware for rate limiting requests per client within this process. Use
+// NewRateLimiter to share limits across replicas through Redis.
+func RateLimit(cfg config.RateLimitConfig) func(http.Handler) http.Handler {
+	return NewRateLimiter(nil, nil, cfg, "").Middleware()
+}
+
 // JWT middleware for authentication
 func JWT(jwtSecret string) func(http.Handler) http.Handler {
+	return JWTWithRevocation(jwtSecret, nil)
+}
+
+// JWTWithRevocation is JWT that also rejects tokens on the revocation list. If the list
+// cannot be checked the token is accepted, so a Redis outage does not log everyone out.
+func JWTWithRevocation(jwtSecret string, revocations *TokenRevocationList) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				httputil.Error(w, r, "Authorization header required", http.StatusUnauthorized)
 				return
 			}
 
 			// Check for Bearer token format
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 			if tokenString == authHeader {
-				http.Error(w, "Bearer token required", http.StatusUnauthorized)
+				httputil.Error(w, r, "Bearer token required", http.StatusUnauthorized)
 				return
 			}
 
@@ -180,24 +268,35 @@ func JWT(jwtSecret string) func(http.Handler) http.Handler {
 			})
 
 			if err != nil {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				httputil.Error(w, r, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
 			if !token.Valid {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				httputil.Error(w, r, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
 			// Extract claims
 			if claims, ok := token.Claims.(jwt.MapClaims); ok {
 				ctx := r.Context()
+				if revocations != nil {
+					jti, _ := claims["jti"].(string)
+					sessionID, _ := claims["sid"].(string)
+					if revoked, err := revocations.IsRevoked(ctx, jti, sessionID); err == nil && revoked {
+						httputil.Error(w, r, "Token has been revoked", http.StatusUnauthorized)
+						return
+					}
+				}
 				if userID, exists := claims["user_id"]; exists {
 					ctx = context.WithValue(ctx, UserIDKey, userID)
 				}
 				if email, exists := claims["email"]; exists {
 					ctx = context.WithValue(ctx, UserEmailKey, email)
 				}
+				if role, exists := claims["role"]; exists {
+					ctx = context.WithValue(ctx, UserRoleKey, role)
+				}
 				r = r.WithContext(ctx)
 			}
 
@@ -212,8 +311,17 @@ func Recovery(logger *observability.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					logger.Error(r.Context(), "Panic recovered", fmt.Errorf("%v", err))
-					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					// Tracing may run inside Recovery, so the request ID is taken from the header
+					// it shares with the request
+					ctx := r.Context()
+					if requestID := httputil.RequestID(r); requestID != "" {
+						ctx = observability.WithRequestID(ctx, requestID)
+					}
+					logger.Error(ctx, "Panic recovered", fmt.Errorf("%v", err), map[string]interface{}{
+						"method": r.Method,
+						"path":   r.URL.Path,
+					})
+					httputil.WriteError(w, r, http.StatusInternalServerError, httputil.CodeInternal, "Internal server error")
 				}
 			}()
 			next.ServeHTTP(w, r)
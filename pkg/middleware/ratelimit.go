@@ -0,0 +1,410 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/database"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/ai-agentic-browser/pkg/observability"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/time/rate"
+)
+
+// Rate limit classes. Requests fall into the class of the longest matching route set with
+// SetRouteClass, otherwise into RateLimitClassRead for GET and HEAD and RateLimitClassDefault
+// for everything else.
+const (
+	RateLimitClassDefault = "default"
+	RateLimitClassRead    = "read"
+	RateLimitClassAIHeavy = "ai-heavy"
+)
+
+const (
+	rateLimitWindow      = time.Minute
+	rateLimitIdleTimeout = 10 * time.Minute
+)
+
+// RateLimiter limits requests per client and route class. In per-user mode clients are keyed
+// by the JWT user_id, falling back to the client IP for anonymous requests. Counts are kept
+// in Redis in fixed one-minute windows so limits hold across replicas; without Redis, or when
+// it is unavailable, each replica enforces the limits with in-process token buckets.
+type RateLimiter struct {
+	redis     *database.RedisClient
+	logger    *observability.Logger
+	jwtSecret string
+	perUser   bool
+	burst     int
+	keyPrefix string
+
+	// trustedProxies may set X-Forwarded-For and X-Real-IP; other clients are keyed by
+	// RemoteAddr
+	trustedProxies []*net.IPNet
+
+	mu        sync.Mutex
+	limits    map[string]int // class -> requests per minute
+	routes    []rateLimitRoute
+	local     map[string]*localLimiter
+	lastSweep time.Time
+}
+
+// rateLimitRoute assigns requests matching a method and path prefix to a class
+type rateLimitRoute struct {
+	method string
+	prefix string
+	class  string
+}
+
+// localLimiter is an in-process token bucket for one client and class
+type localLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimitDecision is the outcome of counting one request
+type rateLimitDecision struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	retryAfter time.Duration
+}
+
+// NewRateLimiter creates a rate limiter from the service configuration. redis may be nil, and
+// jwtSecret is used to identify users before the JWT middleware runs.
+func NewRateLimiter(redis *database.RedisClient, logger *observability.Logger, cfg config.RateLimitConfig, jwtSecret string) *RateLimiter {
+	rl := &RateLimiter{
+		redis:     redis,
+		logger:    logger,
+		jwtSecret: jwtSecret,
+		perUser:   cfg.PerUser,
+		burst:     cfg.Burst,
+		keyPrefix: "ratelimit:",
+		limits: map[string]int{
+			RateLimitClassDefault: cfg.RequestsPerMinute,
+			RateLimitClassRead:    cfg.RequestsPerMinute,
+			RateLimitClassAIHeavy: cfg.RequestsPerMinute,
+		},
+		local:          make(map[string]*localLimiter),
+		trustedProxies: parseTrustedProxies(cfg.TrustedProxies, logger),
+	}
+	if cfg.ReadRequestsPerMinute > 0 {
+		rl.limits[RateLimitClassRead] = cfg.ReadRequestsPerMinute
+	}
+	if cfg.AIHeavyRequestsPerMinute > 0 {
+		rl.limits[RateLimitClassAIHeavy] = cfg.AIHeavyRequestsPerMinute
+	}
+	return rl
+}
+
+// SetKeyPrefix scopes the Redis counters, so services sharing a Redis instance can keep
+// separate limits
+func (rl *RateLimiter) SetKeyPrefix(prefix string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.keyPrefix = prefix
+}
+
+// SetClassLimit sets the requests per minute allowed for a class, defining it if needed
+func (rl *RateLimiter) SetClassLimit(class string, requestsPerMinute int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limits[class] = requestsPerMinute
+}
+
+// SetRouteClass assigns routes to a class. Each route is a path prefix, optionally preceded by
+// a method as in ServeMux patterns, e.g. "POST /ai/analyze" or "/ai/chat".
+func (rl *RateLimiter) SetRouteClass(class string, routes ...string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	for _, route := range routes {
+		method, prefix, found := strings.Cut(route, " ")
+		if !found {
+			method, prefix = "", route
+		}
+		rl.routes = append(rl.routes, rateLimitRoute{method: method, prefix: prefix, class: class})
+	}
+	// Longest prefix first so the most specific route wins
+	sort.SliceStable(rl.routes, func(i, j int) bool {
+		return len(rl.routes[i].prefix) > len(rl.routes[j].prefix)
+	})
+}
+
+// Middleware returns the rate limiting middleware function. Every response carries
+// X-RateLimit-Limit and X-RateLimit-Remaining; rejected requests also carry Retry-After.
+func (rl *RateLimiter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class, limit := rl.classify(r)
+			if limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			decision := rl.take(r.Context(), class, rl.clientKey(r), limit)
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.remaining))
+			if !decision.allowed {
+				seconds := int((decision.retryAfter + time.Second - 1) / time.Second)
+				if seconds < 1 {
+					seconds = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				httputil.Error(w, r, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// classify returns the class of a request and its limit
+func (rl *RateLimiter) classify(r *http.Request) (string, int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	class := RateLimitClassDefault
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		class = RateLimitClassRead
+	}
+	for _, route := range rl.routes {
+		if (route.method == "" || route.method == r.Method) && strings.HasPrefix(r.URL.Path, route.prefix) {
+			class = route.class
+			break
+		}
+	}
+	return class, rl.limits[class]
+}
+
+// clientKey identifies the client a request is counted against
+func (rl *RateLimiter) clientKey(r *http.Request) string {
+	if rl.perUser {
+		if userID := rl.userID(r); userID != "" {
+			return "user:" + userID
+		}
+	}
+	return "ip:" + rl.clientIP(r)
+}
+
+// userID returns the authenticated user from the request context, or from a valid bearer
+// token when the JWT middleware has not run yet
+func (rl *RateLimiter) userID(r *http.Request) string {
+	if userID, ok := GetUserID(r.Context()); ok && userID != "" {
+		return userID
+	}
+	if rl.jwtSecret == "" {
+		return ""
+	}
+
+	tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || tokenString == "" {
+		return ""
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(rl.jwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["user_id"] == nil {
+		return ""
+	}
+	return fmt.Sprint(claims["user_id"])
+}
+
+// take counts a request against the client's limit for a class
+func (rl *RateLimiter) take(ctx context.Context, class, client string, limit int) rateLimitDecision {
+	if rl.redis != nil {
+		decision, err := rl.takeRedis(ctx, class, client, limit)
+		if err == nil {
+			return decision
+		}
+		// Fall back to per-replica limits rather than failing requests
+		if rl.logger != nil {
+			rl.logger.Error(ctx, "Rate limit store unavailable", err, map[string]interface{}{
+				"class": class,
+			})
+		}
+	}
+	return rl.takeLocal(class, client, limit)
+}
+
+// takeRedis counts the request in the client's current one-minute window
+func (rl *RateLimiter) takeRedis(ctx context.Context, class, client string, limit int) (rateLimitDecision, error) {
+	rl.mu.Lock()
+	prefix := rl.keyPrefix
+	rl.mu.Unlock()
+
+	now := time.Now()
+	windowStart := now.Truncate(rateLimitWindow)
+	key := fmt.Sprintf("%s%s:%s:%d", prefix, class, client, windowStart.Unix())
+
+	pipe := rl.redis.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, rateLimitWindow+time.Second)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return rateLimitDecision{}, err
+	}
+
+	count := int(incr.Val())
+	decision := rateLimitDecision{
+		allowed:   count <= limit,
+		limit:     limit,
+		remaining: limit - count,
+	}
+	if decision.remaining < 0 {
+		decision.remaining = 0
+	}
+	if !decision.allowed {
+		decision.retryAfter = windowStart.Add(rateLimitWindow).Sub(now)
+	}
+	return decision, nil
+}
+
+// takeLocal counts the request against an in-process token bucket
+func (rl *RateLimiter) takeLocal(class, client string, limit int) rateLimitDecision {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.sweepLocal(now)
+
+	burst := rl.burst
+	if burst <= 0 || burst > limit {
+		burst = limit
+	}
+
+	key := class + ":" + client
+	entry, exists := rl.local[key]
+	if !exists {
+		entry = &localLimiter{limiter: rate.NewLimiter(rate.Limit(limit)/60, burst)}
+		rl.local[key] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return rateLimitDecision{limit: limit, retryAfter: delay}
+	}
+
+	remaining := int(entry.limiter.TokensAt(now))
+	if remaining < 0 {
+		remaining = 0
+	}
+	return rateLimitDecision{allowed: true, limit: limit, remaining: remaining}
+}
+
+// sweepLocal drops token buckets of clients idle for a while. The caller must hold rl.mu.
+func (rl *RateLimiter) sweepLocal(now time.Time) {
+	if now.Sub(rl.lastSweep) < rateLimitWindow {
+		return
+	}
+	rl.lastSweep = now
+	for key, entry := range rl.local {
+		if now.Sub(entry.lastSeen) > rateLimitIdleTimeout {
+			delete(rl.local, key)
+		}
+	}
+}
+
+// clientIP returns the originating client address. Forwarding headers are only believed for
+// requests from a trusted proxy, and X-Forwarded-For is read from the right, skipping further
+// trusted proxies, so a client can't pick the address it is counted against.
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !rl.trustedProxy(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := forwardedIP(hops[i])
+			if hop == "" {
+				break
+			}
+			if !rl.trustedProxy(hop) {
+				return hop
+			}
+		}
+	}
+	if xri := forwardedIP(r.Header.Get("X-Real-IP")); xri != "" {
+		return xri
+	}
+	return remote
+}
+
+// trustedProxy reports whether ip belongs to a configured trusted proxy
+func (rl *RateLimiter) trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range rl.trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedIP returns the address of a forwarding header entry, dropping a port, or "" when it
+// isn't an IP address
+func forwardedIP(value string) string {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	if net.ParseIP(value) == nil {
+		return ""
+	}
+	return value
+}
+
+// parseTrustedProxies parses IP addresses and CIDR ranges, skipping invalid entries
+func parseTrustedProxies(entries []string, logger *observability.Logger) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 128
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			if logger != nil {
+				logger.Warn(context.Background(), "Ignoring invalid trusted proxy", map[string]interface{}{
+					"proxy": entry,
+				})
+			}
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
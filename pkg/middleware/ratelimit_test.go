@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_ClientKey(t *testing.T) {
+	limiter := NewRateLimiter(nil, nil, config.RateLimitConfig{
+		RequestsPerMinute: 10,
+		PerUser:           true,
+		TrustedProxies:    []string{"10.0.0.0/8", "192.168.1.5", "not-a-proxy"},
+	}, "")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		userID     string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "ip:203.0.113.7"},
+		{"spoofed forwarded for", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "", "ip:203.0.113.7"},
+		{"spoofed real ip", "203.0.113.7:5000", map[string]string{"X-Real-IP": "1.2.3.4"}, "", "ip:203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "198.51.100.9"}, "", "ip:198.51.100.9"},
+		{"trusted proxy with port", "192.168.1.5:80", map[string]string{"X-Forwarded-For": "198.51.100.9:41000"}, "", "ip:198.51.100.9"},
+		// The client's own entries come first; the nearest untrusted hop is the client
+		{"client prepends entries", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.9.9.9"}, "", "ip:198.51.100.9"},
+		{"trusted proxy real ip", "10.1.2.3:5000", map[string]string{"X-Real-IP": "198.51.100.9"}, "", "ip:198.51.100.9"},
+		{"trusted proxy invalid header", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "unknown"}, "", "ip:10.1.2.3"},
+		{"authenticated user", "203.0.113.7:5000", nil, "user-1", "user:user-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), UserIDKey, tt.userID))
+			}
+			assert.Equal(t, tt.want, limiter.clientKey(req))
+		})
+	}
+}
+
+func TestRateLimiter_RejectsOverLimit(t *testing.T) {
+	limiter := NewRateLimiter(nil, nil, config.RateLimitConfig{RequestsPerMinute: 60, Burst: 2}, "")
+	limiter.SetClassLimit(RateLimitClassAIHeavy, 1)
+	limiter.SetRouteClass(RateLimitClassAIHeavy, "POST /ai/analyze")
+	handler := limiter.Middleware()(okHandler)
+
+	send := func(method, path, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send(http.MethodPost, "/orders", "203.0.113.7:5000", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "60", first.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/orders", "203.0.113.7:5001", "").Code)
+
+	// A new forwarded address doesn't give an untrusted client a fresh bucket
+	rejected := send(http.MethodPost, "/orders", "203.0.113.7:5002", "1.2.3.4")
+	require.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Equal(t, "0", rejected.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "1", rejected.Header().Get("Retry-After"))
+	assert.Contains(t, rejected.Body.String(), "Rate limit exceeded")
+
+	// Other clients and classes are counted separately
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/orders", "198.51.100.9:5000", "").Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/orders", "203.0.113.7:5000", "").Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/ai/analyze", "198.51.100.9:5000", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodPost, "/ai/analyze", "198.51.100.9:5000", "").Code)
+
+	// Classes without a limit are not counted
+	limiter.SetClassLimit(RateLimitClassDefault, 0)
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/orders", "203.0.113.7:5000", "").Code)
+}
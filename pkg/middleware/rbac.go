@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/httputil"
+)
+
+// Roles a user can hold, each allowed everything the roles before it are
+const (
+	RoleViewer = "viewer"
+	RoleTrader = "trader"
+	RoleAdmin  = "admin"
+)
+
+// UserRoleKey stores the role claim of the access token
+const UserRoleKey ContextKey = "user_role"
+
+// legacyRole is assumed for tokens issued before roles were added
+const legacyRole = RoleTrader
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleTrader: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is a known role
+func ValidRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// GetUserRole returns the role of the authenticated user, which is trader when their token
+// carries none
+func GetUserRole(ctx context.Context) string {
+	if role, ok := ctx.Value(UserRoleKey).(string); ok && ValidRole(role) {
+		return role
+	}
+	return legacyRole
+}
+
+// HasRole reports whether the authenticated user holds role or a higher one
+func HasRole(ctx context.Context, role string) bool {
+	return RoleIncludes(GetUserRole(ctx), role)
+}
+
+// RoleIncludes reports whether role is required or a higher role. Unknown roles include none.
+func RoleIncludes(role, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// RequireRole rejects requests from users below role with 403 naming the missing role. It
+// must run after JWT authentication.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasRole(r.Context(), role) {
+				forbidRole(w, r, role)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forbidRole(w http.ResponseWriter, r *http.Request, role string) {
+	httputil.Error(w, r, fmt.Sprintf("Forbidden: requires role %q, you have %q", role, GetUserRole(r.Context())), http.StatusForbidden)
+}
+
+// RoutePolicy requires the role configured for the most specific matching route pattern.
+// Patterns follow net/http ServeMux rules, so "POST /web3/trading/" covers every POST below
+// it unless a longer pattern overrides it. Routes without a pattern are left open.
+type RoutePolicy struct {
+	routes *http.ServeMux
+	roles  map[string]string
+	admins *AdminAuthorizer
+}
+
+// NewRoutePolicy builds a policy from route roles, rejecting unknown roles and patterns the
+// ServeMux rejects
+func NewRoutePolicy(routes []config.RouteRole) (policy *RoutePolicy, err error) {
+	policy = &RoutePolicy{routes: http.NewServeMux(), roles: make(map[string]string, len(routes))}
+	for _, route := range routes {
+		if !ValidRole(route.Role) {
+			return nil, fmt.Errorf("invalid role %q for route %q", route.Role, route.Pattern)
+		}
+		if err := policy.register(route.Pattern); err != nil {
+			return nil, err
+		}
+		policy.roles[route.Pattern] = route.Role
+	}
+	return policy, nil
+}
+
+// register adds pattern to the route matcher. ServeMux panics on invalid or conflicting
+// patterns; that is turned into an error so bad configuration fails startup cleanly.
+func (p *RoutePolicy) register(pattern string) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("invalid route pattern %q: %v", pattern, recovered)
+		}
+	}()
+	if _, exists := p.roles[pattern]; !exists {
+		p.routes.Handle(pattern, http.NotFoundHandler())
+	}
+	return nil
+}
+
+// SetAdminAuthorizer treats the users configured as administrators as admins whatever the
+// role in their token
+func (p *RoutePolicy) SetAdminAuthorizer(admins *AdminAuthorizer) {
+	p.admins = admins
+}
+
+// RequiredRole returns the role the request needs, or "" when no route matches
+func (p *RoutePolicy) RequiredRole(r *http.Request) string {
+	_, pattern := p.routes.Handler(r)
+	return p.roles[pattern]
+}
+
+// Middleware enforces the policy. It must run after JWT authentication.
+func (p *RoutePolicy) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := p.RequiredRole(r)
+			if role != "" && !HasRole(r.Context(), role) && !p.configuredAdmin(r) {
+				forbidRole(w, r, role)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (p *RoutePolicy) configuredAdmin(r *http.Request) bool {
+	if p.admins == nil {
+		return false
+	}
+	userID, _ := GetUserID(r.Context())
+	email, _ := GetUserEmail(r.Context())
+	return p.admins.isAdmin(userID, email)
+}
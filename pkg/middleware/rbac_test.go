@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-agentic-browser/internal/config"
+	"github.com/ai-agentic-browser/pkg/httputil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveAs sends a request through handler as a user with the role, which is omitted when empty
+func serveAs(handler http.Handler, method, path, role string, values map[ContextKey]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	ctx := req.Context()
+	if role != "" {
+		ctx = context.WithValue(ctx, UserRoleKey, role)
+	}
+	for key, value := range values {
+		ctx = context.WithValue(ctx, key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(ctx))
+	return rec
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestRoutePolicy_MostSpecificPatternWins(t *testing.T) {
+	policy, err := NewRoutePolicy(config.DefaultRoutePolicy)
+	require.NoError(t, err)
+	handler := policy.Middleware()(okHandler)
+
+	tests := []struct {
+		method, path, role string
+		required           string
+		status             int
+	}{
+		{"POST", "/web3/trading/portfolio/p1/stop", RoleTrader, RoleAdmin, http.StatusForbidden},
+		{"POST", "/web3/trading/portfolio/p1/stop", RoleAdmin, RoleAdmin, http.StatusOK},
+		{"POST", "/web3/trading/orders", RoleTrader, RoleTrader, http.StatusOK},
+		{"POST", "/web3/trading/orders", RoleViewer, RoleTrader, http.StatusForbidden},
+		{"GET", "/web3/trading/orders", RoleViewer, "", http.StatusOK},
+		// Tokens without a role are treated as traders
+		{"DELETE", "/web3/trading/positions/1", "", RoleTrader, http.StatusOK},
+		{"POST", "/web3/trading/killswitch", "", RoleAdmin, http.StatusForbidden},
+		{"POST", "/admin/users", RoleAdmin, RoleAdmin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path+" as "+tt.role, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			assert.Equal(t, tt.required, policy.RequiredRole(req))
+			assert.Equal(t, tt.status, serveAs(handler, tt.method, tt.path, tt.role, nil).Code)
+		})
+	}
+}
+
+func TestRoutePolicy_ForbiddenNamesMissingRole(t *testing.T) {
+	policy, err := NewRoutePolicy(config.DefaultRoutePolicy)
+	require.NoError(t, err)
+
+	rec := serveAs(policy.Middleware()(okHandler), "POST", "/web3/trading/killswitch", RoleTrader, nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	var body httputil.ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, httputil.CodeForbidden, body.Error.Code)
+	assert.Equal(t, `Forbidden: requires role "admin", you have "trader"`, body.Error.Message)
+}
+
+func TestRoutePolicy_ConfiguredAdmins(t *testing.T) {
+	policy, err := NewRoutePolicy(config.DefaultRoutePolicy)
+	require.NoError(t, err)
+	policy.SetAdminAuthorizer(NewAdminAuthorizer("secret", nil, []string{"ops@example.com", " 42 "}))
+	handler := policy.Middleware()(okHandler)
+
+	assert.Equal(t, http.StatusOK, serveAs(handler, "POST", "/web3/trading/killswitch", RoleViewer,
+		map[ContextKey]string{UserEmailKey: "OPS@example.com"}).Code)
+	assert.Equal(t, http.StatusOK, serveAs(handler, "POST", "/web3/trading/killswitch", RoleViewer,
+		map[ContextKey]string{UserIDKey: "42"}).Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(handler, "POST", "/web3/trading/killswitch", RoleTrader,
+		map[ContextKey]string{UserIDKey: "7", UserEmailKey: "trader@example.com"}).Code)
+}
+
+func TestNewRoutePolicy_RejectsBadConfiguration(t *testing.T) {
+	_, err := NewRoutePolicy([]config.RouteRole{{Pattern: "POST /web3/trading/", Role: "owner"}})
+	assert.ErrorContains(t, err, `invalid role "owner"`)
+
+	_, err = NewRoutePolicy([]config.RouteRole{{Pattern: "POST /web3/{id", Role: RoleAdmin}})
+	assert.ErrorContains(t, err, `invalid route pattern "POST /web3/{id"`)
+
+	// ServeMux rejects patterns that conflict with an earlier one
+	_, err = NewRoutePolicy([]config.RouteRole{
+		{Pattern: "POST /web3/{id}/stop", Role: RoleAdmin},
+		{Pattern: "POST /web3/portfolio/{action}", Role: RoleTrader},
+	})
+	assert.ErrorContains(t, err, "invalid route pattern")
+
+	// A repeated pattern takes the later role
+	policy, err := NewRoutePolicy([]config.RouteRole{
+		{Pattern: "POST /web3/trading/", Role: RoleTrader},
+		{Pattern: "POST /web3/trading/", Role: RoleAdmin},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, policy.RequiredRole(httptest.NewRequest("POST", "/web3/trading/x", nil)))
+}
+
+func TestRequireRole(t *testing.T) {
+	handler := RequireRole(RoleTrader)(okHandler)
+
+	assert.Equal(t, http.StatusOK, serveAs(handler, "GET", "/", RoleAdmin, nil).Code)
+	assert.Equal(t, http.StatusOK, serveAs(handler, "GET", "/", RoleTrader, nil).Code)
+	assert.Equal(t, http.StatusOK, serveAs(handler, "GET", "/", "", nil).Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(handler, "GET", "/", RoleViewer, nil).Code)
+	// Unknown roles in a token fall back to the legacy role
+	assert.Equal(t, http.StatusOK, serveAs(handler, "GET", "/", "superuser", nil).Code)
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// TokenRevocationList records revoked access tokens in Redis so they are rejected before they
+// expire. Tokens are revoked individually by their jti claim, or all at once for a login
+// session by their sid claim. Entries expire with the tokens they revoke.
+type TokenRevocationList struct {
+	entries   revocationStore
+	keyPrefix string
+}
+
+// revocationStore holds revocation entries, which expire after their TTL
+type revocationStore interface {
+	Set(ctx context.Context, key string, ttl time.Duration) error
+	Count(ctx context.Context, keys ...string) (int64, error)
+}
+
+// redisRevocationStore keeps revocation entries in Redis so every instance shares them
+type redisRevocationStore struct {
+	redis *database.RedisClient
+}
+
+// NewTokenRevocationList creates a revocation list stored in Redis
+func NewTokenRevocationList(redis *database.RedisClient) *TokenRevocationList {
+	if redis == nil {
+		return newTokenRevocationList(nil)
+	}
+	return newTokenRevocationList(&redisRevocationStore{redis: redis})
+}
+
+func newTokenRevocationList(entries revocationStore) *TokenRevocationList {
+	return &TokenRevocationList{
+		entries:   entries,
+		keyPrefix: "auth:revoked:",
+	}
+}
+
+// RevokeToken revokes the access token with the given jti until it expires
+func (l *TokenRevocationList) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return l.revoke(ctx, "jti:"+jti, time.Until(expiresAt))
+}
+
+// RevokeSession revokes every access token issued to a login session. ttl should cover the
+// lifetime of the longest-lived token the session may hold.
+func (l *TokenRevocationList) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	return l.revoke(ctx, "sid:"+sessionID, ttl)
+}
+
+// IsRevoked reports whether a token has been revoked by its jti or session
+func (l *TokenRevocationList) IsRevoked(ctx context.Context, jti, sessionID string) (bool, error) {
+	if l == nil || l.entries == nil {
+		return false, nil
+	}
+
+	var keys []string
+	if jti != "" {
+		keys = append(keys, l.keyPrefix+"jti:"+jti)
+	}
+	if sessionID != "" {
+		keys = append(keys, l.keyPrefix+"sid:"+sessionID)
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	count, err := l.entries.Count(ctx, keys...)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// RevokeAPIKey rejects an API key that may still be cached by an APIKeyAuthenticator. The
+// key must also be revoked in its store; the entry only needs to outlive cached copies.
+func (l *TokenRevocationList) RevokeAPIKey(ctx context.Context, keyID string) error {
+	return l.revoke(ctx, "apikey:"+keyID, 2*apiKeyCacheTTL)
+}
+
+// IsAPIKeyRevoked reports whether an API key has been revoked recently
+func (l *TokenRevocationList) IsAPIKeyRevoked(ctx context.Context, keyID string) (bool, error) {
+	if l == nil || l.entries == nil {
+		return false, nil
+	}
+	count, err := l.entries.Count(ctx, l.keyPrefix+"apikey:"+keyID)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (l *TokenRevocationList) revoke(ctx context.Context, key string, ttl time.Duration) error {
+	if l == nil || l.entries == nil || ttl <= 0 {
+		// Nothing to store: no list configured, or the token has already expired
+		return nil
+	}
+	return l.entries.Set(ctx, l.keyPrefix+key, ttl)
+}
+
+func (s *redisRevocationStore) Set(ctx context.Context, key string, ttl time.Duration) error {
+	return s.redis.SetWithExpiry(ctx, key, "1", ttl)
+}
+
+func (s *redisRevocationStore) Count(ctx context.Context, keys ...string) (int64, error) {
+	return s.redis.Client.Exists(ctx, keys...).Result()
+}
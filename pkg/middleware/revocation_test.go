@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRevocationStore is an in-memory revocationStore; TTLs are recorded but not enforced
+type memoryRevocationStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Duration
+	err     error
+}
+
+func newMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{entries: make(map[string]time.Duration)}
+}
+
+func (s *memoryRevocationStore) Set(ctx context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = ttl
+	return nil
+}
+
+func (s *memoryRevocationStore) Count(ctx context.Context, keys ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	var count int64
+	for _, key := range keys {
+		if _, exists := s.entries[key]; exists {
+			count++
+		}
+	}
+	return count, nil
+}
+
+const revocationTestSecret = "test-secret"
+
+func signToken(t *testing.T, jti, sessionID string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"jti":     jti,
+		"sid":     sessionID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(revocationTestSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTWithRevocation(t *testing.T) {
+	store := newMemoryRevocationStore()
+	revocations := newTokenRevocationList(store)
+	handler := JWTWithRevocation(revocationTestSecret, revocations)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	ctx := context.Background()
+
+	first, second := signToken(t, "jti-1", "session-1"), signToken(t, "jti-2", "session-1")
+	other := signToken(t, "jti-3", "session-2")
+	assert.Equal(t, http.StatusNoContent, do(first))
+
+	// Revoking one token leaves the rest of its session working
+	require.NoError(t, revocations.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour)))
+	assert.Equal(t, http.StatusUnauthorized, do(first))
+	assert.Equal(t, http.StatusNoContent, do(second))
+
+	// Revoking the session rejects every token issued to it
+	require.NoError(t, revocations.RevokeSession(ctx, "session-1", time.Hour))
+	assert.Equal(t, http.StatusUnauthorized, do(second))
+	assert.Equal(t, http.StatusNoContent, do(other))
+	assert.Equal(t, time.Hour, store.entries["auth:revoked:sid:session-1"])
+
+	// Expired tokens need no entry
+	require.NoError(t, revocations.RevokeToken(ctx, "jti-3", time.Now().Add(-time.Minute)))
+	assert.NotContains(t, store.entries, "auth:revoked:jti:jti-3")
+
+	// A revocation list that cannot be read lets tokens through
+	store.err = errors.New("redis unavailable")
+	assert.Equal(t, http.StatusNoContent, do(second))
+}
@@ -12,12 +12,16 @@ import (
 
 // ModelManager manages multiple ML models
 type ModelManager struct {
-	models    map[string]Model
-	configs   map[string]*ModelConfig
-	logger    *observability.Logger
-	mu        sync.RWMutex
-	registry  *ModelRegistry
-	scheduler *TrainingScheduler
+	models         map[string]Model
+	configs        map[string]*ModelConfig
+	logger         *observability.Logger
+	mu             sync.RWMutex
+	registry       *ModelRegistry
+	scheduler      *TrainingScheduler
+	versions       map[string][]*ModelVersion
+	activeVersions map[string]int
+	trainingLocks  map[string]*sync.Mutex
+	versionStore   ModelVersionStore
 }
 
 // ModelRegistry keeps track of available models
@@ -62,11 +66,14 @@ func NewModelManager(logger *observability.Logger) *ModelManager {
 	}
 
 	manager := &ModelManager{
-		models:    make(map[string]Model),
-		configs:   make(map[string]*ModelConfig),
-		logger:    logger,
-		registry:  registry,
-		scheduler: scheduler,
+		models:         make(map[string]Model),
+		configs:        make(map[string]*ModelConfig),
+		logger:         logger,
+		registry:       registry,
+		scheduler:      scheduler,
+		versions:       make(map[string][]*ModelVersion),
+		activeVersions: make(map[string]int),
+		trainingLocks:  make(map[string]*sync.Mutex),
 	}
 
 	// Start the training scheduler
@@ -75,17 +82,19 @@ func NewModelManager(logger *observability.Logger) *ModelManager {
 	return manager
 }
 
-// RegisterModel registers a new model
+// RegisterModel registers a new model. Versioned models start with their current state as the
+// initial version.
 func (m *ModelManager) RegisterModel(id string, model Model, config *ModelConfig) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	if _, exists := m.models[id]; exists {
+		m.mu.Unlock()
 		return fmt.Errorf("model with ID %s already exists", id)
 	}
 
 	m.models[id] = model
 	m.configs[id] = config
+	m.trainingLocks[id] = &sync.Mutex{}
+	m.mu.Unlock()
 
 	// Register in registry
 	info := model.GetInfo()
@@ -97,7 +106,20 @@ func (m *ModelManager) RegisterModel(id string, model Model, config *ModelConfig
 		"model_type": string(info.Type),
 	})
 
-	return nil
+	if _, ok := model.(VersionedModel); !ok {
+		return nil
+	}
+
+	ctx := context.Background()
+	version, err := m.newVersion(ctx, id, model, VersionSourceInitial, nil)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.activeVersions[id] = version.Version
+	m.mu.Unlock()
+
+	return m.syncVersions(ctx, id)
 }
 
 // GetModel retrieves a model by ID
@@ -107,7 +129,7 @@ func (m *ModelManager) GetModel(id string) (Model, error) {
 
 	model, exists := m.models[id]
 	if !exists {
-		return nil, fmt.Errorf("model with ID %s not found", id)
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, id)
 	}
 
 	return model, nil
@@ -145,8 +167,17 @@ func (m *ModelManager) Predict(ctx context.Context, modelID string, features map
 	return prediction, nil
 }
 
-// TrainModel trains a specific model
+// TrainModel trains a specific model. Versioned models are trained on a copy that becomes a new
+// active version once training completes, so predictions are served by the previous version
+// meanwhile.
 func (m *ModelManager) TrainModel(ctx context.Context, modelID string, data TrainingData) error {
+	lock, err := m.trainingLock(modelID)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
 	model, err := m.GetModel(modelID)
 	if err != nil {
 		return err
@@ -157,6 +188,23 @@ func (m *ModelManager) TrainModel(ctx context.Context, modelID string, data Trai
 		"training_size": len(data.Features),
 	})
 
+	if versioned, ok := model.(VersionedModel); ok {
+		version, err := m.trainVersion(ctx, modelID, versioned, data)
+		if err != nil {
+			m.logger.Error(ctx, "Model training failed", err, map[string]interface{}{
+				"model_id": modelID,
+			})
+			return err
+		}
+
+		m.logger.Info(ctx, "Model training completed", map[string]interface{}{
+			"model_id":      modelID,
+			"version":       version.Version,
+			"training_size": version.TrainingSize,
+		})
+		return nil
+	}
+
 	err = model.Train(ctx, data)
 	if err != nil {
 		m.logger.Error(ctx, "Model training failed", err, map[string]interface{}{
@@ -234,14 +282,27 @@ func (m *ModelManager) ScheduleTraining(modelID, schedule, dataSource string, co
 	return nil
 }
 
-// ProvideFeedback provides feedback on a prediction for model improvement
+// ProvideFeedback provides feedback on a prediction for model improvement. Versioned models are
+// updated on a copy of the serving model; the stored versions keep their trained state.
 func (m *ModelManager) ProvideFeedback(ctx context.Context, modelID string, feedback *PredictionFeedback) error {
+	lock, err := m.trainingLock(modelID)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
 	model, err := m.GetModel(modelID)
 	if err != nil {
 		return err
 	}
 
-	err = model.UpdateWeights(ctx, feedback)
+	updated := model
+	if versioned, ok := model.(VersionedModel); ok {
+		updated = versioned.Clone()
+	}
+
+	err = updated.UpdateWeights(ctx, feedback)
 	if err != nil {
 		m.logger.Error(ctx, "Failed to update model weights", err, map[string]interface{}{
 			"model_id":      modelID,
@@ -250,6 +311,13 @@ func (m *ModelManager) ProvideFeedback(ctx context.Context, modelID string, feed
 		return err
 	}
 
+	if updated != model {
+		m.mu.Lock()
+		m.models[modelID] = updated
+		m.mu.Unlock()
+		m.registry.update(modelID, updated.GetInfo())
+	}
+
 	m.logger.Info(ctx, "Model feedback processed", map[string]interface{}{
 		"model_id":      modelID,
 		"prediction_id": feedback.PredictionID,
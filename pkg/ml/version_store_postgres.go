@@ -0,0 +1,91 @@
+package ml
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/ai-agentic-browser/pkg/database"
+)
+
+// postgresVersionStore implements ModelVersionStore using the ml_model_versions table
+type postgresVersionStore struct {
+	db *database.DB
+}
+
+func NewPostgresVersionStore(db *database.DB) ModelVersionStore {
+	return &postgresVersionStore{db: db}
+}
+
+func (s *postgresVersionStore) SaveVersion(ctx context.Context, version *ModelVersion) error {
+	var metrics interface{}
+	if version.Metrics != nil {
+		encoded, err := json.Marshal(version.Metrics)
+		if err != nil {
+			return err
+		}
+		metrics = encoded
+	}
+
+	query := `
+		INSERT INTO ml_model_versions (model_id, version, source, training_size, metrics, state, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (model_id, version) DO NOTHING
+	`
+	_, err := s.db.ExecWithMetrics(ctx, query, version.ModelID, version.Version, version.Source,
+		version.TrainingSize, metrics, version.State, version.CreatedAt)
+	return err
+}
+
+func (s *postgresVersionStore) ListVersions(ctx context.Context, modelID string) ([]*ModelVersion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT version, source, training_size, metrics, state, is_active, created_at
+		FROM ml_model_versions
+		WHERE model_id = $1
+		ORDER BY version
+	`, modelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*ModelVersion
+	for rows.Next() {
+		version := &ModelVersion{ModelID: modelID}
+		var metrics []byte
+		if err := rows.Scan(&version.Version, &version.Source, &version.TrainingSize, &metrics,
+			&version.State, &version.Active, &version.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(metrics) > 0 {
+			version.Metrics = &ModelMetrics{}
+			if err := json.Unmarshal(metrics, version.Metrics); err != nil {
+				return nil, err
+			}
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+func (s *postgresVersionStore) SetActiveVersion(ctx context.Context, modelID string, version int) error {
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE ml_model_versions SET is_active = FALSE
+			WHERE model_id = $1 AND version <> $2 AND is_active
+		`, modelID, version); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, `
+			UPDATE ml_model_versions SET is_active = TRUE, activated_at = NOW()
+			WHERE model_id = $1 AND version = $2
+		`, modelID, version)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return ErrVersionNotFound
+		}
+		return nil
+	})
+}
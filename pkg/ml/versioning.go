@@ -0,0 +1,423 @@
+package ml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrModelNotFound      = errors.New("model not found")
+	ErrVersionNotFound    = errors.New("model version not found")
+	ErrModelNotVersioned  = errors.New("model does not support versioning")
+	ErrNoPreviousVersion  = errors.New("no previous model version to roll back to")
+	ErrVersionStoreFailed = errors.New("failed to persist model version")
+)
+
+// Sources of a model version
+const (
+	VersionSourceInitial  = "initial"
+	VersionSourceTraining = "training"
+)
+
+// VersionedModel is implemented by models whose state can be copied and serialized. Training such
+// a model runs on a copy and produces a new version, so predictions keep using the active version
+// until the new one is swapped in.
+type VersionedModel interface {
+	Model
+
+	// Clone returns an independent copy of the model
+	Clone() Model
+
+	// MarshalState serializes the trained state of the model
+	MarshalState() ([]byte, error)
+
+	// UnmarshalState replaces the trained state of the model
+	UnmarshalState(data []byte) error
+}
+
+// ModelVersion is the trained state of a model produced by one training run
+type ModelVersion struct {
+	ModelID      string        `json:"model_id"`
+	Version      int           `json:"version"`
+	Source       string        `json:"source"`
+	TrainingSize int           `json:"training_size"`
+	Metrics      *ModelMetrics `json:"metrics,omitempty"`
+	Active       bool          `json:"active"`
+	CreatedAt    time.Time     `json:"created_at"`
+	State        []byte        `json:"-"`
+}
+
+// VersionedModelInfo is the status of a model together with its version history
+type VersionedModelInfo struct {
+	*ModelInfo
+	ActiveVersion int            `json:"active_version,omitempty"`
+	Versions      []ModelVersion `json:"versions,omitempty"`
+}
+
+// ModelVersionStore persists model versions so the history and active version survive restarts
+type ModelVersionStore interface {
+	SaveVersion(ctx context.Context, version *ModelVersion) error
+	// ListVersions returns the versions of a model ordered by version number
+	ListVersions(ctx context.Context, modelID string) ([]*ModelVersion, error)
+	SetActiveVersion(ctx context.Context, modelID string, version int) error
+}
+
+// SetVersionStore persists the version history of registered models in store. Models with stored
+// history are restored to their stored active version; others have their current versions saved.
+func (m *ModelManager) SetVersionStore(ctx context.Context, store ModelVersionStore) error {
+	m.mu.Lock()
+	m.versionStore = store
+	ids := make([]string, 0, len(m.versions))
+	for id := range m.versions {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := m.syncVersions(ctx, id); err != nil {
+			return fmt.Errorf("model %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// ListVersionedModels returns the status of all registered models with their versions
+func (m *ModelManager) ListVersionedModels() map[string]*VersionedModelInfo {
+	models := m.registry.list()
+
+	result := make(map[string]*VersionedModelInfo, len(models))
+	for id, info := range models {
+		versions, active := m.ModelVersions(id)
+		result[id] = &VersionedModelInfo{
+			ModelInfo:     info,
+			ActiveVersion: active,
+			Versions:      versions,
+		}
+	}
+	return result
+}
+
+// ModelVersions returns the versions of a model and the active version number
+func (m *ModelManager) ModelVersions(modelID string) ([]ModelVersion, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	active := m.activeVersions[modelID]
+	versions := make([]ModelVersion, 0, len(m.versions[modelID]))
+	for _, version := range m.versions[modelID] {
+		copied := *version
+		copied.State = nil
+		copied.Active = version.Version == active
+		versions = append(versions, copied)
+	}
+	return versions, active
+}
+
+// ActivateVersion switches the serving model to a stored version
+func (m *ModelManager) ActivateVersion(ctx context.Context, modelID string, version int) (*ModelVersion, error) {
+	lock, err := m.trainingLock(modelID)
+	if err != nil {
+		return nil, err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	return m.activateVersion(ctx, modelID, version)
+}
+
+// Rollback switches the serving model to the newest version older than the active one
+func (m *ModelManager) Rollback(ctx context.Context, modelID string) (*ModelVersion, error) {
+	lock, err := m.trainingLock(modelID)
+	if err != nil {
+		return nil, err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	m.mu.RLock()
+	active := m.activeVersions[modelID]
+	previous := 0
+	for _, version := range m.versions[modelID] {
+		if version.Version < active && version.Version > previous {
+			previous = version.Version
+		}
+	}
+	m.mu.RUnlock()
+
+	if previous == 0 {
+		return nil, fmt.Errorf("%w: model %s is at version %d", ErrNoPreviousVersion, modelID, active)
+	}
+	return m.activateVersion(ctx, modelID, previous)
+}
+
+// activateVersion restores a version onto a copy of the serving model and swaps it in. The
+// caller holds the training lock of the model.
+func (m *ModelManager) activateVersion(ctx context.Context, modelID string, number int) (*ModelVersion, error) {
+	current, err := m.GetModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	versioned, ok := current.(VersionedModel)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotVersioned, modelID)
+	}
+
+	version := m.findVersion(modelID, number)
+	if version == nil {
+		return nil, fmt.Errorf("%w: %s version %d", ErrVersionNotFound, modelID, number)
+	}
+
+	restored, err := restoreVersion(versioned, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if store := m.getVersionStore(); store != nil {
+		if err := store.SetActiveVersion(ctx, modelID, number); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrVersionStoreFailed, err)
+		}
+	}
+
+	m.swapModel(modelID, restored, number)
+
+	m.logger.Info(ctx, "Model version activated", map[string]interface{}{
+		"model_id": modelID,
+		"version":  number,
+	})
+
+	activated := *version
+	activated.State = nil
+	activated.Active = true
+	return &activated, nil
+}
+
+// trainVersion trains a copy of a versioned model and activates the result as a new version
+func (m *ModelManager) trainVersion(ctx context.Context, modelID string, current VersionedModel, data TrainingData) (*ModelVersion, error) {
+	candidate := current.Clone()
+	if err := candidate.Train(ctx, data); err != nil {
+		return nil, err
+	}
+
+	metrics, err := candidate.Evaluate(ctx, data)
+	if err != nil {
+		m.logger.Warn(ctx, "Model evaluation after training failed", map[string]interface{}{
+			"model_id": modelID,
+			"error":    err.Error(),
+		})
+	}
+
+	version, err := m.newVersion(ctx, modelID, candidate, VersionSourceTraining, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	if store := m.getVersionStore(); store != nil {
+		if err := store.SetActiveVersion(ctx, modelID, version.Version); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrVersionStoreFailed, err)
+		}
+	}
+
+	m.swapModel(modelID, candidate, version.Version)
+	return version, nil
+}
+
+// newVersion snapshots a model as the next version of modelID and persists it when a store is set
+func (m *ModelManager) newVersion(ctx context.Context, modelID string, model Model, source string, metrics *ModelMetrics) (*ModelVersion, error) {
+	versioned, ok := model.(VersionedModel)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotVersioned, modelID)
+	}
+	state, err := versioned.MarshalState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot model %s: %w", modelID, err)
+	}
+
+	m.mu.Lock()
+	number := 1
+	if versions := m.versions[modelID]; len(versions) > 0 {
+		number = versions[len(versions)-1].Version + 1
+	}
+	version := &ModelVersion{
+		ModelID:      modelID,
+		Version:      number,
+		Source:       source,
+		TrainingSize: model.GetInfo().TrainingSize,
+		Metrics:      metrics,
+		CreatedAt:    time.Now(),
+		State:        state,
+	}
+	m.versions[modelID] = append(m.versions[modelID], version)
+	store := m.versionStore
+	m.mu.Unlock()
+
+	if store != nil {
+		if err := store.SaveVersion(ctx, version); err != nil {
+			m.dropVersion(modelID, number)
+			return nil, fmt.Errorf("%w: %v", ErrVersionStoreFailed, err)
+		}
+	}
+
+	return version, nil
+}
+
+// syncVersions loads the stored history of a model, restoring its stored active version, or
+// saves the in-memory history when nothing has been stored yet
+func (m *ModelManager) syncVersions(ctx context.Context, modelID string) error {
+	lock, err := m.trainingLock(modelID)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	store := m.getVersionStore()
+	if store == nil {
+		return nil
+	}
+
+	stored, err := store.ListVersions(ctx, modelID)
+	if err != nil {
+		return err
+	}
+
+	if len(stored) == 0 {
+		m.mu.RLock()
+		versions := append([]*ModelVersion(nil), m.versions[modelID]...)
+		active := m.activeVersions[modelID]
+		m.mu.RUnlock()
+
+		for _, version := range versions {
+			if err := store.SaveVersion(ctx, version); err != nil {
+				return err
+			}
+		}
+		if active > 0 {
+			return store.SetActiveVersion(ctx, modelID, active)
+		}
+		return nil
+	}
+
+	var active *ModelVersion
+	for _, version := range stored {
+		if version.Active {
+			active = version
+		}
+	}
+	if active == nil {
+		active = stored[len(stored)-1]
+	}
+
+	current, err := m.GetModel(modelID)
+	if err != nil {
+		return err
+	}
+	versioned, ok := current.(VersionedModel)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrModelNotVersioned, modelID)
+	}
+	restored, err := restoreVersion(versioned, active)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.versions[modelID] = stored
+	m.mu.Unlock()
+	m.swapModel(modelID, restored, active.Version)
+
+	m.logger.Info(ctx, "Model versions restored", map[string]interface{}{
+		"model_id":       modelID,
+		"versions":       len(stored),
+		"active_version": active.Version,
+	})
+	return nil
+}
+
+// swapModel replaces the serving model and records its active version
+func (m *ModelManager) swapModel(modelID string, model Model, version int) {
+	m.mu.Lock()
+	m.models[modelID] = model
+	m.activeVersions[modelID] = version
+	m.mu.Unlock()
+
+	m.registry.update(modelID, model.GetInfo())
+}
+
+func (m *ModelManager) findVersion(modelID string, number int) *ModelVersion {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, version := range m.versions[modelID] {
+		if version.Version == number {
+			return version
+		}
+	}
+	return nil
+}
+
+func (m *ModelManager) dropVersion(modelID string, number int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	versions := m.versions[modelID]
+	for i, version := range versions {
+		if version.Version == number {
+			m.versions[modelID] = append(versions[:i], versions[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *ModelManager) getVersionStore() ModelVersionStore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.versionStore
+}
+
+// trainingLock returns the lock serializing training, feedback and activation of a model
+func (m *ModelManager) trainingLock(modelID string) (*sync.Mutex, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lock, exists := m.trainingLocks[modelID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, modelID)
+	}
+	return lock, nil
+}
+
+// restoreVersion returns a copy of model with the state of version
+func restoreVersion(model VersionedModel, version *ModelVersion) (Model, error) {
+	restored, ok := model.Clone().(VersionedModel)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotVersioned, version.ModelID)
+	}
+	if err := restored.UnmarshalState(version.State); err != nil {
+		return nil, fmt.Errorf("failed to restore %s version %d: %w", version.ModelID, version.Version, err)
+	}
+	return restored, nil
+}
+
+// Clone returns a copy of the model info that can be updated independently
+func (i *ModelInfo) Clone() *ModelInfo {
+	clone := *i
+	clone.Features = append([]string(nil), i.Features...)
+	if i.OutputSchema != nil {
+		clone.OutputSchema = make(map[string]interface{}, len(i.OutputSchema))
+		for key, value := range i.OutputSchema {
+			clone.OutputSchema[key] = value
+		}
+	}
+	if i.Metadata != nil {
+		clone.Metadata = make(map[string]interface{}, len(i.Metadata))
+		for key, value := range i.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+	return &clone
+}
@@ -30,6 +30,7 @@ type LogEntry struct {
 	Service   string                 `json:"service"`
 	TraceID   string                 `json:"trace_id,omitempty"`
 	SpanID    string                 `json:"span_id,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
 	Fields    map[string]interface{} `json:"fields,omitempty"`
 	Error     string                 `json:"error,omitempty"`
 }
@@ -93,6 +94,7 @@ func (l *Logger) log(ctx context.Context, level LogLevel, message string, err er
 		entry.TraceID = span.SpanContext().TraceID().String()
 		entry.SpanID = span.SpanContext().SpanID().String()
 	}
+	entry.RequestID = RequestIDFromContext(ctx)
 
 	// Add error if present
 	if err != nil {
@@ -123,6 +125,15 @@ func (l *Logger) output(entry LogEntry) {
 		}
 	} else {
 		// Simple text format
+		if entry.RequestID != "" {
+			fmt.Printf("[%s] %s %s: %s request_id=%s\n",
+				entry.Timestamp,
+				entry.Level,
+				entry.Service,
+				entry.Message,
+				entry.RequestID)
+			return
+		}
 		fmt.Printf("[%s] %s %s: %s\n",
 			entry.Timestamp,
 			entry.Level,
@@ -131,6 +142,19 @@ func (l *Logger) output(entry LogEntry) {
 	}
 }
 
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose log entries carry the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
 // shouldLog determines if a message should be logged based on the configured level
 func (l *Logger) shouldLog(level LogLevel) bool {
 	levels := map[LogLevel]int{
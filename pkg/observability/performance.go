@@ -13,6 +13,7 @@ type PerformanceMonitor struct {
 	logger   *Logger
 	metrics  *PerformanceMetrics
 	config   *PerformanceConfig
+	exporter *PrometheusExporter
 	stopChan chan struct{}
 	mu       sync.RWMutex
 }
@@ -108,6 +109,20 @@ func NewPerformanceMonitor(logger *Logger) *PerformanceMonitor {
 	return pm
 }
 
+// SetPrometheusExporter feeds recorded request, database and cache stats into Prometheus collectors
+func (pm *PerformanceMonitor) SetPrometheusExporter(exporter *PrometheusExporter) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.exporter = exporter
+}
+
+// prometheusExporter returns the configured exporter, or nil
+func (pm *PerformanceMonitor) prometheusExporter() *PrometheusExporter {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.exporter
+}
+
 // startMonitoring begins performance data collection
 func (pm *PerformanceMonitor) startMonitoring() {
 	ticker := time.NewTicker(pm.config.CollectionInterval)
@@ -181,6 +196,8 @@ func (pm *PerformanceMonitor) estimateCPUUsage() float64 {
 
 // RecordRequest records metrics for an HTTP request
 func (pm *PerformanceMonitor) RecordRequest(metrics *RequestMetrics) {
+	pm.prometheusExporter().ObserveHTTPRequest(metrics.Method, metrics.Path, metrics.StatusCode, metrics.Duration)
+
 	pm.metrics.mu.Lock()
 	defer pm.metrics.mu.Unlock()
 
@@ -243,9 +260,11 @@ func (pm *PerformanceMonitor) RecordDatabaseMetrics(connections int64, queryTime
 	}
 
 	pm.metrics.DBSlowQueries = slowQueries
+
+	pm.prometheusExporter().SetDatabaseStats(pm.metrics.DBQueryTime, slowQueries)
 }
 
-// RecordCacheMetrics records cache performance metrics
+// RecordCacheMetrics records cache performance metrics; hitRate is a percentage
 func (pm *PerformanceMonitor) RecordCacheMetrics(hitRate float64, size int64, evictions int64) {
 	pm.metrics.mu.Lock()
 	defer pm.metrics.mu.Unlock()
@@ -253,6 +272,8 @@ func (pm *PerformanceMonitor) RecordCacheMetrics(hitRate float64, size int64, ev
 	pm.metrics.CacheHitRate = hitRate
 	pm.metrics.CacheSize = size
 	pm.metrics.CacheEvictions = evictions
+
+	pm.prometheusExporter().SetCacheStats(hitRate/100, size)
 }
 
 // SetCustomMetric sets a custom performance metric
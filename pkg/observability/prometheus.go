@@ -0,0 +1,172 @@
+package observability
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PrometheusExporter registers native Prometheus collectors for a service and serves them
+// in the Prometheus text format. All recording methods are no-ops on a nil exporter.
+type PrometheusExporter struct {
+	registry *prometheus.Registry
+
+	httpRequestsTotal      *prometheus.CounterVec
+	httpRequestDuration    *prometheus.HistogramVec
+	aiRequestsTotal        *prometheus.CounterVec
+	modelInferenceDuration *prometheus.HistogramVec
+	cacheOperationsTotal   *prometheus.CounterVec
+	cacheHitRatio          prometheus.Gauge
+	cacheSizeBytes         prometheus.Gauge
+	dbQueryDuration        prometheus.Gauge
+	dbSlowQueries          prometheus.Gauge
+}
+
+// NewPrometheusExporter creates an exporter whose metrics carry a service label
+func NewPrometheusExporter(serviceName string) *PrometheusExporter {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	factory := prometheus.WrapRegistererWith(prometheus.Labels{"service": serviceName}, registry)
+
+	e := &PrometheusExporter{
+		registry: registry,
+		httpRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"method", "route"}),
+		aiRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ai_requests_total",
+			Help: "Total number of AI requests by request type",
+		}, []string{"type", "status"}),
+		modelInferenceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "model_inference_duration_seconds",
+			Help:    "Model inference duration in seconds",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+		}, []string{"model"}),
+		cacheOperationsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_operations_total",
+			Help: "Total number of HTTP cache operations by result",
+		}, []string{"result"}),
+		cacheHitRatio: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cache_hit_ratio",
+			Help: "Fraction of cache lookups served from the cache",
+		}),
+		cacheSizeBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cache_size_bytes",
+			Help: "Total size of cached responses in bytes",
+		}),
+		dbQueryDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "db_query_duration_seconds",
+			Help: "Moving average database query duration in seconds",
+		}),
+		dbSlowQueries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "db_slow_queries",
+			Help: "Number of slow database queries",
+		}),
+	}
+
+	factory.MustRegister(
+		e.httpRequestsTotal,
+		e.httpRequestDuration,
+		e.aiRequestsTotal,
+		e.modelInferenceDuration,
+		e.cacheOperationsTotal,
+		e.cacheHitRatio,
+		e.cacheSizeBytes,
+		e.dbQueryDuration,
+		e.dbSlowQueries,
+	)
+
+	return e
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func (e *PrometheusExporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exports the connection pool stats of a database under the given name
+func (e *PrometheusExporter) RegisterDB(name string, db *sql.DB) error {
+	if e == nil || db == nil {
+		return nil
+	}
+	return e.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterCollector registers an additional service-specific collector
+func (e *PrometheusExporter) RegisterCollector(collector prometheus.Collector) error {
+	if e == nil {
+		return nil
+	}
+	return e.registry.Register(collector)
+}
+
+// ObserveHTTPRequest records an HTTP request; route should be the matched route pattern
+// rather than the raw path to keep label cardinality bounded
+func (e *PrometheusExporter) ObserveHTTPRequest(method, route string, statusCode int, duration time.Duration) {
+	if e == nil {
+		return
+	}
+	e.httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(statusCode)).Inc()
+	e.httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// RecordAIRequest counts an AI request of the given type
+func (e *PrometheusExporter) RecordAIRequest(requestType string, success bool) {
+	if e == nil {
+		return
+	}
+	status := "success"
+	if !success {
+		status = "error"
+	}
+	e.aiRequestsTotal.WithLabelValues(requestType, status).Inc()
+}
+
+// ObserveModelInference records the duration of a model prediction
+func (e *PrometheusExporter) ObserveModelInference(model string, duration time.Duration) {
+	if e == nil {
+		return
+	}
+	e.modelInferenceDuration.WithLabelValues(model).Observe(duration.Seconds())
+}
+
+// RecordCacheOperation counts a cache hit, miss, set or error
+func (e *PrometheusExporter) RecordCacheOperation(result string) {
+	if e == nil {
+		return
+	}
+	e.cacheOperationsTotal.WithLabelValues(result).Inc()
+}
+
+// SetCacheStats updates the cache hit ratio (0-1) and cache size
+func (e *PrometheusExporter) SetCacheStats(hitRatio float64, sizeBytes int64) {
+	if e == nil {
+		return
+	}
+	e.cacheHitRatio.Set(hitRatio)
+	e.cacheSizeBytes.Set(float64(sizeBytes))
+}
+
+// SetDatabaseStats updates the average query duration and slow query count
+func (e *PrometheusExporter) SetDatabaseStats(avgQueryTime time.Duration, slowQueries int64) {
+	if e == nil {
+		return
+	}
+	e.dbQueryDuration.Set(avgQueryTime.Seconds())
+	e.dbSlowQueries.Set(float64(slowQueries))
+}
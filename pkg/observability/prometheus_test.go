@@ -0,0 +1,61 @@
+package observability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, e *PrometheusExporter) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestPrometheusExporter_RecordAIRequest(t *testing.T) {
+	e := NewPrometheusExporter("ai-agent")
+
+	e.RecordAIRequest("chat", true)
+	e.RecordAIRequest("chat", true)
+	e.RecordAIRequest("chat", false)
+	e.RecordAIRequest("price_prediction", false)
+
+	metrics := scrape(t, e)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai-agent",status="success",type="chat"} 2`)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai-agent",status="error",type="chat"} 1`)
+	assert.Contains(t, metrics, `ai_requests_total{service="ai-agent",status="error",type="price_prediction"} 1`)
+	assert.NotContains(t, metrics, `status="success",type="price_prediction"`)
+}
+
+func TestPrometheusExporter_RecordsServiceMetrics(t *testing.T) {
+	e := NewPrometheusExporter("web3")
+
+	e.ObserveHTTPRequest(http.MethodGet, "/health", http.StatusOK, 20*time.Millisecond)
+	e.ObserveHTTPRequest(http.MethodPost, "/trades", http.StatusBadRequest, time.Millisecond)
+	e.RecordCacheOperation("hit")
+	e.SetCacheStats(0.75, 2048)
+
+	metrics := scrape(t, e)
+	assert.Contains(t, metrics, `http_requests_total{method="GET",route="/health",service="web3",status="200"} 1`)
+	assert.Contains(t, metrics, `http_requests_total{method="POST",route="/trades",service="web3",status="400"} 1`)
+	assert.Contains(t, metrics, `cache_operations_total{result="hit",service="web3"} 1`)
+	assert.Contains(t, metrics, `cache_hit_ratio{service="web3"} 0.75`)
+}
+
+func TestPrometheusExporter_NilIsNoop(t *testing.T) {
+	var e *PrometheusExporter
+	assert.NotPanics(t, func() {
+		e.RecordAIRequest("chat", false)
+		e.ObserveHTTPRequest(http.MethodGet, "/", http.StatusOK, time.Millisecond)
+		e.ObserveModelInference("model", time.Millisecond)
+		e.RecordCacheOperation("miss")
+		e.SetCacheStats(1, 1)
+		e.SetDatabaseStats(time.Millisecond, 1)
+	})
+}
@@ -0,0 +1,154 @@
+// Package validation checks request fields and reports every invalid one
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidRequest is wrapped by FieldErrors
+var ErrInvalidRequest = errors.New("invalid request")
+
+// Constraints a field can violate
+const (
+	ConstraintRequired = "required"
+	ConstraintEnum     = "enum"
+	ConstraintRange    = "range"
+	ConstraintDecimal  = "decimal"
+	ConstraintFormat   = "format"
+	ConstraintType     = "type"
+	ConstraintUnknown  = "unknown_field"
+	ConstraintInvalid  = "invalid"
+)
+
+// FieldError describes why one field of a request is invalid. Nested fields are dotted, such
+// as "capital.initial_balance".
+type FieldError struct {
+	Field      string `json:"field"`
+	Constraint string `json:"constraint"`
+	Message    string `json:"message"`
+}
+
+// FieldErrors lists every invalid field of a request
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, field := range e {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidRequest, strings.Join(messages, "; "))
+}
+
+func (e FieldErrors) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// Validator collects field errors. Its checks skip fields that are already invalid, so each
+// field reports its first violation only.
+type Validator struct {
+	errors FieldErrors
+	failed map[string]bool
+}
+
+// Fail records that field violates constraint
+func (v *Validator) Fail(field, constraint, message string) {
+	if v.failed[field] {
+		return
+	}
+	if v.failed == nil {
+		v.failed = make(map[string]bool)
+	}
+	v.failed[field] = true
+	v.errors = append(v.errors, FieldError{Field: field, Constraint: constraint, Message: message})
+}
+
+// Merge records the field errors of err with their fields prefixed, or err itself as invalid
+// when it lists no fields
+func (v *Validator) Merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var fieldErrors FieldErrors
+	if !errors.As(err, &fieldErrors) {
+		v.Fail(strings.TrimSuffix(prefix, "."), ConstraintInvalid, err.Error())
+		return
+	}
+	for _, fieldError := range fieldErrors {
+		v.Fail(prefix+fieldError.Field, fieldError.Constraint, fieldError.Message)
+	}
+}
+
+// Required checks that value is not empty
+func (v *Validator) Required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Fail(field, ConstraintRequired, "is required")
+		return false
+	}
+	return true
+}
+
+// OneOf checks that a non-empty value is one of allowed
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, option := range allowed {
+		if value == option {
+			return
+		}
+	}
+	v.Fail(field, ConstraintEnum, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")))
+}
+
+// IntRange checks that min <= value <= max
+func (v *Validator) IntRange(field string, value, min, max int) {
+	if value < min || value > max {
+		v.Fail(field, ConstraintRange, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
+
+// FloatRange checks that min <= value <= max
+func (v *Validator) FloatRange(field string, value, min, max float64) {
+	if value < min || value > max {
+		v.Fail(field, ConstraintRange, fmt.Sprintf("must be between %g and %g", min, max))
+	}
+}
+
+// Positive checks that value is greater than zero
+func (v *Validator) Positive(field string, value decimal.Decimal) {
+	if !value.IsPositive() {
+		v.Fail(field, ConstraintRange, "must be positive")
+	}
+}
+
+// NotNegative checks that value is zero or more
+func (v *Validator) NotNegative(field string, value decimal.Decimal) {
+	if value.IsNegative() {
+		v.Fail(field, ConstraintRange, "must not be negative")
+	}
+}
+
+// Decimal parses a required decimal string such as "1000.50"
+func (v *Validator) Decimal(field, value string) decimal.Decimal {
+	if !v.Required(field, value) {
+		return decimal.Zero
+	}
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		v.Fail(field, ConstraintDecimal, "must be a decimal number such as \"1000.50\"")
+		return decimal.Zero
+	}
+	return parsed
+}
+
+// Err returns the collected field errors, or nil when every field is valid
+func (v *Validator) Err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}